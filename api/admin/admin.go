@@ -0,0 +1,333 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	health "github.com/inference-gateway/inference-gateway/api/health"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	config "github.com/inference-gateway/inference-gateway/config"
+	mcp "github.com/inference-gateway/inference-gateway/internal/mcp"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	client "github.com/inference-gateway/inference-gateway/providers/client"
+	core "github.com/inference-gateway/inference-gateway/providers/core"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	transformers "github.com/inference-gateway/inference-gateway/providers/transformers"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// probeTimeout bounds the model listing of a provider connectivity check
+const probeTimeout = 10 * time.Second
+
+// Options are the gateway components the admin API reports on
+type Options struct {
+	Health   *health.State
+	Registry *registry.ReloadableRegistry
+	Client   client.Client
+	// MCPClient is nil when no MCP servers are configured
+	MCPClient mcp.MCPClientInterface
+	// Tenants are refreshed when a provider token is rotated, nil without
+	// multi-tenancy
+	Tenants *tenants.Store
+}
+
+// Admin serves the introspection and provider toggle endpoints of /admin
+type Admin struct {
+	logger  logger.Logger
+	monitor *Monitor
+	opts    Options
+	current atomic.Pointer[config.Config]
+}
+
+// New creates the admin API reporting cfg and the state collected by monitor
+func New(cfg config.Config, logger logger.Logger, monitor *Monitor, opts Options) *Admin {
+	a := &Admin{logger: logger, monitor: monitor, opts: opts}
+	a.Reload(cfg)
+	return a
+}
+
+// Reload replaces the configuration reported by GET /admin/config
+func (a *Admin) Reload(cfg config.Config) {
+	a.current.Store(&cfg)
+}
+
+// Status is the body of GET /admin/status
+type Status struct {
+	Status            string            `json:"status"`
+	StartedAt         time.Time         `json:"started_at"`
+	UptimeSeconds     int64             `json:"uptime_seconds"`
+	Draining          bool              `json:"draining"`
+	InFlight          int               `json:"in_flight"`
+	ActiveStreams     int               `json:"active_streams"`
+	Checks            map[string]string `json:"checks,omitempty"`
+	DisabledProviders []types.Provider  `json:"disabled_providers"`
+}
+
+// StatusHandler implements GET /admin/status
+func (a *Admin) StatusHandler(c *gin.Context) {
+	status := Status{
+		Status:            "ok",
+		StartedAt:         a.monitor.Started(),
+		UptimeSeconds:     int64(time.Since(a.monitor.Started()).Seconds()),
+		Draining:          a.opts.Health.Draining(),
+		InFlight:          a.opts.Health.InFlight(),
+		ActiveStreams:     a.monitor.ActiveStreams(),
+		DisabledProviders: a.opts.Registry.Disabled(),
+	}
+	if failed := a.opts.Health.Ready(); len(failed) > 0 {
+		status.Status = "unavailable"
+		status.Checks = make(map[string]string, len(failed))
+		for name, err := range failed {
+			status.Checks[name] = err.Error()
+		}
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// ConfigHandler implements GET /admin/config, answering the current
+// configuration without secrets
+func (a *Admin) ConfigHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, Redact(*a.current.Load()))
+}
+
+// Provider is an entry of GET /admin/providers
+type Provider struct {
+	ID      types.Provider `json:"id"`
+	Name    string         `json:"name"`
+	URL     string         `json:"url"`
+	Enabled bool           `json:"enabled"`
+	// Health is the last connectivity check, nil until the provider is checked
+	Health *ProviderCheck `json:"health"`
+}
+
+// ProvidersHandler implements GET /admin/providers. With ?probe=true the
+// enabled providers are checked again before answering.
+func (a *Admin) ProvidersHandler(c *gin.Context) {
+	providers := a.opts.Registry.GetProviders()
+	if probe, _ := strconv.ParseBool(c.Query("probe")); probe {
+		a.probe(c.Request.Context(), providers)
+	}
+
+	ids := make([]types.Provider, 0, len(providers))
+	for id := range providers {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	entries := make([]Provider, 0, len(ids))
+	for _, id := range ids {
+		entries = append(entries, a.provider(id, providers[id]))
+	}
+	c.JSON(http.StatusOK, gin.H{"providers": entries})
+}
+
+// EnableProviderHandler implements POST /admin/providers/:id/enable
+func (a *Admin) EnableProviderHandler(c *gin.Context) {
+	a.setEnabled(c, true)
+}
+
+// DisableProviderHandler implements POST /admin/providers/:id/disable.
+// Requests to the provider fail with provider_disabled until it is enabled
+// again or the gateway restarts; requests in flight complete.
+func (a *Admin) DisableProviderHandler(c *gin.Context) {
+	a.setEnabled(c, false)
+}
+
+func (a *Admin) setEnabled(c *gin.Context, enabled bool) {
+	id := types.Provider(c.Param("id"))
+	providerCfg, ok := a.opts.Registry.GetProviders()[id]
+	if !ok {
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, "Provider not configured: "+string(id))
+		return
+	}
+	a.opts.Registry.SetEnabled(id, enabled)
+	a.logger.Info("provider toggled through the admin api", "provider", id, "enabled", enabled)
+	c.JSON(http.StatusOK, a.provider(id, providerCfg))
+}
+
+// TokenRequest is the body of POST /admin/providers/:id/token
+type TokenRequest struct {
+	Token string `json:"token"`
+}
+
+// RotateTokenHandler implements POST /admin/providers/:id/token. The new API
+// key is checked by listing the provider's models with it, and replaces the
+// current one only when the provider accepts it; requests in flight complete
+// with the key they started with. The key is kept over configuration reloads
+// until the configured key of the provider changes.
+func (a *Admin) RotateTokenHandler(c *gin.Context) {
+	id := types.Provider(c.Param("id"))
+	providerCfg, ok := a.opts.Registry.GetProviders()[id]
+	if !ok {
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, "Provider not configured: "+string(id))
+		return
+	}
+	var req TokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Token) == "" {
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Request body must be a JSON object with a non-empty token")
+		return
+	}
+
+	models, err := VerifyToken(c.Request.Context(), a.opts.Registry.WithToken(id, req.Token), a.opts.Client, id)
+	if err != nil {
+		a.logger.Warn("provider rejected the rotated token, keeping the current one", "provider", id, "error", err.Error())
+		errcodes.ProviderJSON(c, err)
+		return
+	}
+	if err := a.opts.Registry.SetToken(id, req.Token); err != nil {
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, err.Error())
+		return
+	}
+	if a.opts.Tenants != nil {
+		a.opts.Tenants.Reload()
+	}
+	a.monitor.RecordProviderCheck(id, models, nil)
+	a.logger.Info("provider token rotated through the admin api", "provider", id, "models", models)
+	c.JSON(http.StatusOK, a.provider(id, providerCfg))
+}
+
+func (a *Admin) provider(id types.Provider, providerCfg *registry.ProviderConfig) Provider {
+	entry := Provider{ID: id, Enabled: a.opts.Registry.Enabled(id)}
+	if providerCfg != nil {
+		entry.Name = providerCfg.Name
+		entry.URL = redactURL(providerCfg.URL)
+	}
+	if check, ok := a.monitor.ProviderCheck(id); ok {
+		entry.Health = &check
+	}
+	return entry
+}
+
+// probe checks the connectivity of the enabled providers concurrently by
+// listing their models
+func (a *Admin) probe(ctx context.Context, providers map[types.Provider]*registry.ProviderConfig) {
+	var wg sync.WaitGroup
+	for id := range providers {
+		if !a.opts.Registry.Enabled(id) {
+			continue
+		}
+		wg.Go(func() {
+			models, err := Probe(ctx, a.opts.Registry, a.opts.Client, id)
+			a.monitor.RecordProviderCheck(id, models, err)
+		})
+	}
+	wg.Wait()
+}
+
+// Probe lists the models of a provider to check its connectivity, returning
+// the number of models it serves
+func Probe(ctx context.Context, reg registry.ProviderRegistry, c client.Client, id types.Provider) (int, error) {
+	provider, err := reg.BuildProvider(id, c)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	response, err := provider.ListModels(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return len(response.Data), nil
+}
+
+// VerifyToken lists the models of a provider with the API key it is built
+// with by reg, returning the number of models it serves. Unlike Probe, the
+// provider is called directly rather than through the gateway's /proxy
+// route, which authenticates with the current key.
+func VerifyToken(ctx context.Context, reg registry.ProviderRegistry, c client.Client, id types.Provider) (int, error) {
+	providerCfg, ok := reg.GetProviders()[id]
+	if !ok {
+		return 0, fmt.Errorf("provider %s not found", id)
+	}
+	provider, err := reg.BuildProvider(id, c)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(provider.GetURL(), "/")+providerCfg.Endpoints.Models, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if err := core.ApplyAuth(req, provider); err != nil {
+		return 0, err
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, &core.HTTPError{StatusCode: resp.StatusCode, Message: string(body), Header: resp.Header}
+	}
+
+	transformer := transformers.NewListModelsTransformer(id)
+	if err := json.Unmarshal(body, transformer); err != nil {
+		return 0, fmt.Errorf("failed to decode the models of provider %s: %w", id, err)
+	}
+	return len(transformer.Transform().Data), nil
+}
+
+// MCPServer is an entry of GET /admin/mcp
+type MCPServer struct {
+	URL    string           `json:"url"`
+	Status mcp.ServerStatus `json:"status"`
+	Tools  int              `json:"tools"`
+}
+
+// MCPHandler implements GET /admin/mcp
+func (a *Admin) MCPHandler(c *gin.Context) {
+	cfg := a.current.Load()
+	if a.opts.MCPClient == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": cfg.MCP != nil && cfg.MCP.Enable, "initialized": false, "servers": []MCPServer{}})
+		return
+	}
+
+	statuses := a.opts.MCPClient.GetAllServerStatuses()
+	urls := slices.Clone(a.opts.MCPClient.GetServers())
+	slices.Sort(urls)
+	servers := make([]MCPServer, 0, len(urls))
+	for _, serverURL := range urls {
+		server := MCPServer{URL: redactURL(serverURL), Status: mcp.ServerStatusUnknown}
+		if status, ok := statuses[serverURL]; ok {
+			server.Status = status
+		}
+		if tools, err := a.opts.MCPClient.GetServerTools(serverURL); err == nil {
+			server.Tools = len(tools)
+		}
+		servers = append(servers, server)
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": true, "initialized": a.opts.MCPClient.IsInitialized(), "servers": servers})
+}
+
+// ErrorsHandler implements GET /admin/errors, answering the most recent
+// failed requests first. ?limit= caps the number of records.
+func (a *Admin) ErrorsHandler(c *gin.Context) {
+	records := a.monitor.RecentErrors()
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 0 {
+			errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "limit must be a non-negative integer")
+			return
+		}
+		records = records[:min(limit, len(records))]
+	}
+	c.JSON(http.StatusOK, gin.H{"errors": records})
+}
@@ -0,0 +1,208 @@
+// Package admin implements the operator API under /admin: runtime
+// introspection of the gateway (redacted configuration, provider health, MCP
+// servers, active streams, recent errors) and toggling providers on and off.
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	core "github.com/inference-gateway/inference-gateway/providers/core"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// Provider check statuses
+const (
+	CheckOK          = "ok"
+	CheckUnavailable = "unavailable"
+	// CheckUnauthorized is a check the provider answered 401 or 403
+	CheckUnauthorized = "unauthorized"
+)
+
+// Model load states
+const (
+	// ModelLoading is a model whose first load has not answered yet
+	ModelLoading = "loading"
+	// ModelLoaded is a model whose last load or keep-alive succeeded
+	ModelLoaded = "loaded"
+	// ModelLoadFailed is a model whose last load or keep-alive failed
+	ModelLoadFailed = "failed"
+)
+
+// ErrorRecord describes a failed request
+type ErrorRecord struct {
+	Time    time.Time `json:"time"`
+	Method  string    `json:"method"`
+	Path    string    `json:"path"`
+	Status  int       `json:"status"`
+	Code    string    `json:"code,omitempty"`
+	Message string    `json:"message,omitempty"`
+	Tenant  string    `json:"tenant,omitempty"`
+}
+
+// ProviderCheck is the outcome of the last connectivity check of a provider
+type ProviderCheck struct {
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Models    int       `json:"models"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// ModelLoad is the load state of a model the gateway keeps loaded on its
+// provider
+type ModelLoad struct {
+	Model string
+	State string
+	Error string
+	// LoadedAt is when the model last answered, zero until it does
+	LoadedAt time.Time
+	// LoadDuration is how long the provider took to load the model on the
+	// last load or keep-alive
+	LoadDuration time.Duration
+}
+
+// Monitor collects the runtime state reported by the admin API: the streams
+// in progress, the most recent failed requests, the last provider checks and
+// the load state of the pre-warmed models
+type Monitor struct {
+	started time.Time
+	streams atomic.Int64
+
+	mu     sync.Mutex
+	errors []ErrorRecord
+	// next is the ring position the next error is written to
+	next   int
+	checks map[types.Provider]ProviderCheck
+	loads  map[types.Provider]map[string]ModelLoad
+}
+
+// NewMonitor creates a monitor keeping the errorsSize most recent errors
+func NewMonitor(errorsSize int) *Monitor {
+	return &Monitor{
+		started: time.Now(),
+		errors:  make([]ErrorRecord, 0, max(errorsSize, 0)),
+		checks:  make(map[types.Provider]ProviderCheck),
+		loads:   make(map[types.Provider]map[string]ModelLoad),
+	}
+}
+
+// Started returns when the monitor, and so the gateway, started
+func (m *Monitor) Started() time.Time {
+	return m.started
+}
+
+// StreamStarted records a streaming response in progress; the returned
+// function records its end
+func (m *Monitor) StreamStarted() func() {
+	m.streams.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(func() { m.streams.Add(-1) })
+	}
+}
+
+// ActiveStreams returns the number of streaming responses in progress
+func (m *Monitor) ActiveStreams() int {
+	return int(m.streams.Load())
+}
+
+// RecordError records a failed request, evicting the oldest record once the
+// monitor holds its maximum
+func (m *Monitor) RecordError(record ErrorRecord) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case cap(m.errors) == 0:
+	case len(m.errors) < cap(m.errors):
+		m.errors = append(m.errors, record)
+	default:
+		m.errors[m.next] = record
+	}
+	if cap(m.errors) > 0 {
+		m.next = (m.next + 1) % cap(m.errors)
+	}
+}
+
+// RecentErrors returns the recorded errors, most recent first
+func (m *Monitor) RecentErrors() []ErrorRecord {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	records := make([]ErrorRecord, 0, len(m.errors))
+	for i := range len(m.errors) {
+		records = append(records, m.errors[(m.next-1-i+2*len(m.errors))%len(m.errors)])
+	}
+	return records
+}
+
+// RecordProviderCheck records the outcome of a connectivity check of a
+// provider, which listed models unless err is set
+func (m *Monitor) RecordProviderCheck(providerID types.Provider, models int, err error) {
+	check := ProviderCheck{Status: CheckOK, Models: models, CheckedAt: time.Now()}
+	if err != nil {
+		check = ProviderCheck{Status: CheckUnavailable, Error: err.Error(), CheckedAt: check.CheckedAt}
+		var httpErr *core.HTTPError
+		if errors.As(err, &httpErr) && (httpErr.StatusCode == http.StatusUnauthorized || httpErr.StatusCode == http.StatusForbidden) {
+			check.Status = CheckUnauthorized
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checks[providerID] = check
+}
+
+// ProviderCheck returns the last check of a provider, if it was checked
+func (m *Monitor) ProviderCheck(providerID types.Provider) (ProviderCheck, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	check, ok := m.checks[providerID]
+	return check, ok
+}
+
+// RecordModelLoading records a model about to be loaded on a provider, unless
+// its load state is already known
+func (m *Monitor) RecordModelLoading(providerID types.Provider, model string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.loads[providerID] == nil {
+		m.loads[providerID] = make(map[string]ModelLoad)
+	}
+	if _, ok := m.loads[providerID][model]; !ok {
+		m.loads[providerID][model] = ModelLoad{Model: model, State: ModelLoading}
+	}
+}
+
+// RecordModelLoad records the outcome of a load or keep-alive of a model on a
+// provider, which took loadDuration to load it unless err is set
+func (m *Monitor) RecordModelLoad(providerID types.Provider, model string, loadDuration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.loads[providerID] == nil {
+		m.loads[providerID] = make(map[string]ModelLoad)
+	}
+	load := m.loads[providerID][model]
+	load.Model = model
+	if err != nil {
+		load.State, load.Error = ModelLoadFailed, err.Error()
+	} else {
+		load = ModelLoad{Model: model, State: ModelLoaded, LoadedAt: time.Now(), LoadDuration: loadDuration}
+	}
+	m.loads[providerID][model] = load
+}
+
+// ModelLoads returns the load state of the models kept loaded on a provider,
+// by model name
+func (m *Monitor) ModelLoads(providerID types.Provider) []ModelLoad {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	loads := make([]ModelLoad, 0, len(m.loads[providerID]))
+	for _, load := range m.loads[providerID] {
+		loads = append(loads, load)
+	}
+	slices.SortFunc(loads, func(a, b ModelLoad) int { return strings.Compare(a.Model, b.Model) })
+	return loads
+}
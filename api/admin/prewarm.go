@@ -0,0 +1,106 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	client "github.com/inference-gateway/inference-gateway/providers/client"
+	core "github.com/inference-gateway/inference-gateway/providers/core"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// loadTimeout bounds the load of a model, which takes minutes for large
+// models read from a slow disk
+const loadTimeout = 5 * time.Minute
+
+// WarmModels loads models into the memory of the Ollama backend of provider
+// id, or keeps them loaded, by generating with each of them without a prompt.
+// Ollama is asked to keep them loaded for keepAlive, indefinitely when it is
+// negative. The models are loaded one after the other, so they do not compete
+// for the backend's memory, and the outcomes are recorded in monitor.
+func WarmModels(ctx context.Context, log logger.Logger, reg registry.ProviderRegistry, c client.Client, monitor *Monitor, id types.Provider, models []string, keepAlive time.Duration) {
+	for _, model := range models {
+		monitor.RecordModelLoading(id, model)
+	}
+	for _, model := range models {
+		loadDuration, err := warmModel(ctx, reg, c, id, model, keepAlive)
+		if ctx.Err() != nil {
+			return
+		}
+		monitor.RecordModelLoad(id, model, loadDuration, err)
+		if err != nil {
+			log.Warn("failed to load model", "provider", id, "model", model, "error", err.Error())
+		} else {
+			log.Debug("model loaded", "provider", id, "model", model, "load_duration", loadDuration.String())
+		}
+	}
+}
+
+// warmModel generates with model on the Ollama backend of provider id,
+// returning how long the backend took to load it
+func warmModel(ctx context.Context, reg registry.ProviderRegistry, c client.Client, id types.Provider, model string, keepAlive time.Duration) (time.Duration, error) {
+	provider, err := reg.BuildProvider(id, c)
+	if err != nil {
+		return 0, err
+	}
+	// The native API is served at the root of the backend, not under the
+	// /v1 of its OpenAI-compatible one
+	base, err := url.Parse(provider.GetURL())
+	if err != nil {
+		return 0, err
+	}
+	if base.Scheme == "" || base.Host == "" {
+		return 0, fmt.Errorf("provider url %q has no scheme or host", provider.GetURL())
+	}
+
+	seconds := int64(-1)
+	if keepAlive >= 0 {
+		seconds = int64(keepAlive.Seconds())
+	}
+	body, err := json.Marshal(map[string]any{"model": model, "keep_alive": seconds, "stream": false})
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, loadTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base.Scheme+"://"+base.Host+"/api/generate", bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	if err := core.ApplyAuth(req, provider); err != nil {
+		return 0, err
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, &core.HTTPError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data)), Header: resp.Header}
+	}
+
+	// Ollama reports the load duration in nanoseconds, a few milliseconds
+	// when the model was still loaded
+	var generated struct {
+		LoadDuration int64 `json:"load_duration"`
+	}
+	if err := json.Unmarshal(data, &generated); err != nil {
+		return 0, fmt.Errorf("parse generate response: %w", err)
+	}
+	return time.Duration(generated.LoadDuration), nil
+}
@@ -0,0 +1,85 @@
+package admin
+
+import (
+	"net/http"
+	"slices"
+
+	gin "github.com/gin-gonic/gin"
+
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// API key statuses of GET /v1/providers
+const (
+	AuthValid     = "valid"
+	AuthInvalid   = "invalid"
+	AuthMissing   = "missing"
+	AuthNone      = "none"
+	AuthUnchecked = "unchecked"
+)
+
+// ListProvidersHandler implements GET /v1/providers, listing the providers
+// the tenant of the request may use with the status of their API key, their
+// last connectivity check and the load state of their pre-warmed models.
+// Unlike GET /admin/providers it never checks the providers itself: they are
+// checked at startup and every PROVIDER_CHECK_INTERVAL.
+func ListProvidersHandler(reg *registry.ReloadableRegistry, monitor *Monitor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		providers := tenants.Registry(c.Request.Context(), reg).GetProviders()
+		ids := make([]types.Provider, 0, len(providers))
+		for id := range providers {
+			ids = append(ids, id)
+		}
+		slices.Sort(ids)
+
+		data := make([]types.ProviderStatus, 0, len(ids))
+		for _, id := range ids {
+			providerCfg := providers[id]
+			status := types.ProviderStatus{
+				ID:      id,
+				Name:    providerCfg.Name,
+				URL:     redactURL(providerCfg.URL),
+				Enabled: reg.Enabled(id),
+				Auth:    AuthUnchecked,
+			}
+			if check, ok := monitor.ProviderCheck(id); ok {
+				status.Health = &types.ProviderHealthCheck{Status: check.Status, Models: check.Models, CheckedAt: check.CheckedAt}
+				if check.Error != "" {
+					status.Health.Error = &check.Error
+				}
+				switch check.Status {
+				case CheckOK:
+					status.Auth = AuthValid
+				case CheckUnauthorized:
+					status.Auth = AuthInvalid
+				}
+			}
+			if loads := monitor.ModelLoads(id); len(loads) > 0 {
+				models := make([]types.ProviderModelLoad, 0, len(loads))
+				for _, load := range loads {
+					model := types.ProviderModelLoad{Model: load.Model, State: load.State}
+					if load.Error != "" {
+						model.Error = &load.Error
+					}
+					if !load.LoadedAt.IsZero() {
+						loadedAt, ms := load.LoadedAt, load.LoadDuration.Milliseconds()
+						model.LoadedAt, model.LoadDurationMs = &loadedAt, &ms
+					}
+					models = append(models, model)
+				}
+				status.Models = &models
+			}
+			switch {
+			case providerCfg.AuthType == constants.AuthTypeNone:
+				status.Auth = AuthNone
+			case providerCfg.Token == "":
+				status.Auth = AuthMissing
+			}
+			data = append(data, status)
+		}
+		c.JSON(http.StatusOK, types.ListProvidersResponse{Object: "list", Data: data})
+	}
+}
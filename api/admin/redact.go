@@ -0,0 +1,118 @@
+package admin
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	config "github.com/inference-gateway/inference-gateway/config"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// Redacted replaces the value of a secret setting
+const Redacted = "[REDACTED]"
+
+// ProviderSettings is the redacted configuration of a provider
+type ProviderSettings struct {
+	Name         string   `json:"name"`
+	URL          string   `json:"url"`
+	AuthType     string   `json:"auth_type,omitempty"`
+	Token        string   `json:"token,omitempty"`
+	ExtraHeaders []string `json:"extra_headers,omitempty"`
+}
+
+// Settings is the redacted configuration reported by GET /admin/config
+type Settings struct {
+	// Env holds every setting by environment variable name
+	Env       map[string]any                      `json:"env"`
+	Providers map[types.Provider]ProviderSettings `json:"providers"`
+}
+
+// Redact returns the settings of cfg without secrets: settings tagged as
+// secrets, provider API keys and the values of extra provider headers are
+// replaced, and credentials are removed from URLs
+func Redact(cfg config.Config) Settings {
+	settings := Settings{
+		Env:       make(map[string]any),
+		Providers: make(map[types.Provider]ProviderSettings, len(cfg.Providers)),
+	}
+	redactStruct(settings.Env, "", reflect.ValueOf(cfg))
+
+	for id, provider := range cfg.Providers {
+		if provider == nil {
+			continue
+		}
+		p := ProviderSettings{Name: provider.Name, URL: redactURL(provider.URL), AuthType: provider.AuthType}
+		if provider.Token != "" {
+			p.Token = Redacted
+		}
+		for header := range provider.ExtraHeaders {
+			p.ExtraHeaders = append(p.ExtraHeaders, header)
+		}
+		settings.Providers[id] = p
+	}
+	return settings
+}
+
+// redactStruct adds the settings of the config struct v to env, following
+// the env tags that nest structs under a prefix
+func redactStruct(env map[string]any, prefix string, v reflect.Value) {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup("env")
+		if !ok {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		value := v.Field(i)
+
+		if name == "" {
+			if value.Kind() == reflect.Pointer {
+				if value.IsNil() {
+					continue
+				}
+				value = value.Elem()
+			}
+			if value.Kind() == reflect.Struct {
+				redactStruct(env, prefix+envPrefix(options), value)
+			}
+			continue
+		}
+
+		key := prefix + strings.TrimSpace(name)
+		switch {
+		case field.Tag.Get("type") == "secret":
+			env[key] = ""
+			if !value.IsZero() {
+				env[key] = Redacted
+			}
+		case value.Type() == reflect.TypeFor[time.Duration]():
+			env[key] = time.Duration(value.Int()).String()
+		case value.Kind() == reflect.String && strings.Contains(value.String(), "://"):
+			env[key] = redactURL(value.String())
+		default:
+			env[key] = value.Interface()
+		}
+	}
+}
+
+// envPrefix returns the prefix=... option of an env tag
+func envPrefix(options string) string {
+	for option := range strings.SplitSeq(options, ",") {
+		if prefix, ok := strings.CutPrefix(strings.TrimSpace(option), "prefix="); ok {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// redactURL removes the password of a URL, leaving other values unchanged
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil {
+		return raw
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,71 @@
+package agentjobs
+
+import (
+	"errors"
+	"net/http"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+)
+
+// CreateHandler implements POST /v1/agent/jobs
+func (m *Manager) CreateHandler(c *gin.Context) {
+	var req JobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to decode request")
+		return
+	}
+	j, err := m.Create(req, owner(c), c.Request.Header, c.Request.URL.RawQuery)
+	switch {
+	case errors.Is(err, errInvalid):
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, err.Error())
+		return
+	case errors.Is(err, errQueueFull):
+		errcodes.JSON(c, http.StatusServiceUnavailable, errcodes.InternalError, "Too many jobs are waiting; retry later")
+		return
+	case err != nil:
+		m.logger.Error("failed to create agent job", err)
+		errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to create job")
+		return
+	}
+	m.logger.Info("agent job created", "job", j.ID, "model", j.Model, "max_iterations", j.MaxIterations)
+	c.JSON(http.StatusOK, j)
+}
+
+// GetHandler implements GET /v1/agent/jobs/:id, polled by clients until the
+// job is over
+func (m *Manager) GetHandler(c *gin.Context) {
+	j, ok := m.Get(c.Param("id"), owner(c))
+	if !ok {
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, "Job not found")
+		return
+	}
+	c.JSON(http.StatusOK, j)
+}
+
+// ResultHandler implements GET /v1/agent/jobs/:id/result
+func (m *Manager) ResultHandler(c *gin.Context) {
+	result, ok := m.Result(c.Param("id"), owner(c))
+	if !ok {
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, "Job not found")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// CancelHandler implements POST /v1/agent/jobs/:id/cancel
+func (m *Manager) CancelHandler(c *gin.Context) {
+	j, ok := m.Cancel(c.Param("id"), owner(c))
+	if !ok {
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, "Job not found")
+		return
+	}
+	c.JSON(http.StatusOK, j)
+}
+
+// owner identifies the caller of c, see tenants.Owner
+func owner(c *gin.Context) string {
+	return tenants.Owner(c.Request.Context(), c.Request.Header)
+}
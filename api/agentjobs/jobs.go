@@ -0,0 +1,589 @@
+// Package agentjobs runs long agent tasks in the background: a job answers a
+// chat completion request with the MCP agent loop server-side, one model turn
+// at a time through the gateway's own chat completions handler, persisting
+// the conversation after every turn. Unlike the in-request loop it is bound
+// by its own iteration limit rather than AGENT_MAX_ITERATIONS and the
+// client's HTTP timeout.
+package agentjobs
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	dispatch "github.com/inference-gateway/inference-gateway/api/dispatch"
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	health "github.com/inference-gateway/inference-gateway/api/health"
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	records "github.com/inference-gateway/inference-gateway/api/records"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// Job statuses
+const (
+	StatusQueued     = "queued"
+	StatusInProgress = "in_progress"
+	StatusCancelling = "cancelling"
+	StatusCancelled  = "cancelled"
+	StatusFailed     = "failed"
+	StatusCompleted  = "completed"
+)
+
+// queueSize bounds the number of jobs waiting for a worker
+const queueSize = 1024
+
+var (
+	// errInvalid is returned for job requests that cannot run
+	errInvalid = errors.New("invalid request")
+	// errQueueFull is returned when too many jobs are waiting for a worker
+	errQueueFull = errors.New("job queue is full")
+)
+
+// ToolLister lists the MCP tools a job may call, see
+// mcp.MCPClientInterface
+type ToolLister interface {
+	GetAllChatCompletionTools() []types.ChatCompletionTool
+}
+
+// ToolExecutor executes the tool calls of the model, see mcp.Agent
+type ToolExecutor interface {
+	ExecuteTools(ctx context.Context, toolCalls []types.ChatCompletionMessageToolCall) ([]types.Message, error)
+}
+
+// Options configures a Manager
+type Options struct {
+	Dir     string
+	Workers int
+	// MaxIterations caps the model turns of a job, and is the limit of jobs
+	// setting none
+	MaxIterations int
+}
+
+// JobRequest is the body of POST /v1/agent/jobs: a chat completion request
+// and the MCP tools its agent loop may call
+type JobRequest struct {
+	Request types.CreateChatCompletionRequest `json:"request"`
+	// Tools names the MCP tools the job may call, all of them when empty
+	Tools []string `json:"tools,omitempty"`
+	// MaxIterations bounds the model turns of the job, AGENT_JOBS_MAX_ITERATIONS
+	// when zero
+	MaxIterations int               `json:"max_iterations,omitempty"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+}
+
+// JobError describes why a job failed
+type JobError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Usage counts the tokens of every model turn of a job
+type Usage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+}
+
+// Job is the status of an agent job as returned by GET /v1/agent/jobs/:id
+type Job struct {
+	ID            string            `json:"id"`
+	Object        string            `json:"object"`
+	CreatedAt     int64             `json:"created_at"`
+	Model         string            `json:"model"`
+	Status        string            `json:"status"`
+	Tools         []string          `json:"tools"`
+	MaxIterations int               `json:"max_iterations"`
+	Iterations    int               `json:"iterations"`
+	ToolCalls     int               `json:"tool_calls"`
+	StartedAt     *int64            `json:"started_at"`
+	CancelledAt   *int64            `json:"cancelled_at"`
+	FailedAt      *int64            `json:"failed_at"`
+	CompletedAt   *int64            `json:"completed_at"`
+	LastError     *JobError         `json:"last_error"`
+	Usage         *Usage            `json:"usage"`
+	Metadata      map[string]string `json:"metadata"`
+}
+
+// Result is the conversation of a job as returned by
+// GET /v1/agent/jobs/:id/result. Messages grow with every model turn, so an
+// unfinished job shows its progress; Completion is the final answer of the
+// model once the job completed.
+type Result struct {
+	Object     string                              `json:"object"`
+	JobID      string                              `json:"job_id"`
+	Status     string                              `json:"status"`
+	Messages   []types.Message                     `json:"messages"`
+	Completion *types.CreateChatCompletionResponse `json:"completion"`
+}
+
+// record is the persisted state of a job. Owner identifies the caller that
+// created it, see tenants.Owner.
+type record struct {
+	Job
+	Owner string `json:"owner"`
+	// Request is the chat completion request of the next model turn, its
+	// messages the conversation so far
+	Request    types.CreateChatCompletionRequest   `json:"request"`
+	Completion *types.CreateChatCompletionResponse `json:"completion,omitempty"`
+}
+
+// job is a record with the in-memory state of its execution. The caller's
+// headers are never persisted, so jobs do not survive a restart.
+type job struct {
+	record
+	header http.Header
+	query  string
+	cancel context.CancelFunc
+}
+
+// Manager keeps agent jobs in memory, persisted in a directory, and runs
+// them in a background worker pool
+type Manager struct {
+	dispatcher    dispatch.Dispatcher
+	tools         ToolLister
+	executor      ToolExecutor
+	records       *records.Store[record]
+	logger        logger.Logger
+	workers       int
+	maxIterations int
+	now           func() time.Time
+	queue         chan string
+
+	mu       sync.Mutex
+	jobs     map[string]*job
+	draining bool
+	running  int
+	idle     chan struct{}
+}
+
+// NewManager creates a manager keeping jobs in opts.Dir. Jobs left active by
+// a previous run of the gateway are failed, because the credentials they
+// were created with were not persisted; their conversation so far is kept.
+func NewManager(dispatcher dispatch.Dispatcher, tools ToolLister, executor ToolExecutor, logger logger.Logger, opts Options) (*Manager, error) {
+	if dispatcher == nil {
+		return nil, errors.New("agent jobs dispatcher is required")
+	}
+	if tools == nil || executor == nil {
+		return nil, errors.New("agent jobs require MCP_ENABLE")
+	}
+	if opts.Workers < 1 {
+		return nil, fmt.Errorf("AGENT_JOBS_WORKERS must be positive, got %d", opts.Workers)
+	}
+	if opts.MaxIterations < 1 {
+		return nil, fmt.Errorf("AGENT_JOBS_MAX_ITERATIONS must be positive, got %d", opts.MaxIterations)
+	}
+	recs, err := records.New[record](opts.Dir, "agent job", logger)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manager{
+		dispatcher:    dispatcher,
+		tools:         tools,
+		executor:      executor,
+		records:       recs,
+		logger:        logger,
+		workers:       opts.Workers,
+		maxIterations: opts.MaxIterations,
+		now:           time.Now,
+		queue:         make(chan string, queueSize),
+		jobs:          make(map[string]*job),
+		idle:          make(chan struct{}),
+	}
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// load reads the persisted jobs
+func (m *Manager) load() error {
+	return m.records.Load(func(rec record) {
+		j := &job{record: rec}
+		m.jobs[rec.ID] = j
+
+		switch j.Status {
+		case StatusQueued, StatusInProgress, StatusCancelling:
+			j.Status = StatusFailed
+			j.FailedAt = m.timestamp()
+			j.LastError = &JobError{Code: "server_error", Message: "The gateway restarted while the job was active. Create the job again."}
+			m.records.WriteLogged(j.ID, j.record)
+		}
+	})
+}
+
+// Start runs the workers until ctx is done. Jobs in flight when ctx ends are
+// left active and failed on the next start; use Drain first to let them
+// finish.
+func (m *Manager) Start(ctx context.Context) {
+	for range m.workers {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-m.queue:
+					m.process(ctx, id)
+				}
+			}
+		}()
+	}
+}
+
+// Drain stops the workers from taking queued jobs and waits until the
+// running ones finish or ctx is done. Jobs still queued are left active and
+// failed on the next start. The turns of running jobs are admitted by the
+// gateway while it drains.
+func (m *Manager) Drain(ctx context.Context) error {
+	m.mu.Lock()
+	if !m.draining {
+		m.draining = true
+		m.signalIdle()
+	}
+	m.mu.Unlock()
+
+	select {
+	case <-m.idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Running returns the number of jobs being run
+func (m *Manager) Running() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.running
+}
+
+// signalIdle wakes up Drain once no job is running. m.mu must be held.
+func (m *Manager) signalIdle() {
+	if !m.draining || m.running > 0 {
+		return
+	}
+	select {
+	case <-m.idle:
+	default:
+		close(m.idle)
+	}
+}
+
+// Create queues a job of owner answering req with the caller's header and
+// query
+func (m *Manager) Create(req JobRequest, owner string, header http.Header, query string) (Job, error) {
+	switch {
+	case req.Request.Model == "":
+		return Job{}, fmt.Errorf("%w: request.model is required", errInvalid)
+	case len(req.Request.Messages) == 0:
+		return Job{}, fmt.Errorf("%w: request.messages must not be empty", errInvalid)
+	case req.Request.Stream != nil && *req.Request.Stream:
+		return Job{}, fmt.Errorf("%w: request.stream is not supported, poll the job instead", errInvalid)
+	case req.Request.Tools != nil:
+		return Job{}, fmt.Errorf("%w: request.tools is not supported, jobs only call MCP tools, named in tools", errInvalid)
+	case req.MaxIterations < 0 || req.MaxIterations > m.maxIterations:
+		return Job{}, fmt.Errorf("%w: max_iterations must be between 1 and %d", errInvalid, m.maxIterations)
+	}
+	available := m.tools.GetAllChatCompletionTools()
+	for _, name := range req.Tools {
+		if !slices.ContainsFunc(available, func(tool types.ChatCompletionTool) bool { return sameTool(tool.Function.Name, name) }) {
+			return Job{}, fmt.Errorf("%w: unknown MCP tool %q", errInvalid, name)
+		}
+	}
+
+	tools := req.Tools
+	if tools == nil {
+		tools = []string{}
+	}
+	j := &job{
+		record: record{
+			Job: Job{
+				ID:            newID("job_"),
+				Object:        "agent.job",
+				CreatedAt:     m.now().Unix(),
+				Model:         req.Request.Model,
+				Status:        StatusQueued,
+				Tools:         tools,
+				MaxIterations: cmp.Or(req.MaxIterations, m.maxIterations),
+				Metadata:      metadata(req.Metadata),
+			},
+			Owner:   owner,
+			Request: req.Request,
+		},
+		header: header.Clone(),
+		query:  query,
+	}
+	// The job runs the agent loop itself, one turn per request
+	j.header.Set(middlewares.MCPBypassHeader, "true")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.records.Write(j.ID, j.record); err != nil {
+		return Job{}, err
+	}
+	select {
+	case m.queue <- j.ID:
+	default:
+		_ = m.records.Remove(j.ID)
+		return Job{}, errQueueFull
+	}
+	m.jobs[j.ID] = j
+	return j.Job, nil
+}
+
+// Get returns job id. Here as in Result and Cancel, the jobs of other owners
+// are not found.
+func (m *Manager) Get(id, owner string) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.owned(id, owner)
+	if !ok {
+		return Job{}, false
+	}
+	return j.Job, true
+}
+
+// Result returns the conversation of job id so far
+func (m *Manager) Result(id, owner string) (Result, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.owned(id, owner)
+	if !ok {
+		return Result{}, false
+	}
+	return Result{
+		Object:     "agent.job.result",
+		JobID:      j.ID,
+		Status:     j.Status,
+		Messages:   slices.Clone(j.Request.Messages),
+		Completion: j.Completion,
+	}, true
+}
+
+// Cancel cancels job id if it is still active
+func (m *Manager) Cancel(id, owner string) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.owned(id, owner)
+	if !ok {
+		return Job{}, false
+	}
+	if j.Status == StatusQueued || j.Status == StatusInProgress {
+		j.Status = StatusCancelling
+		if j.cancel != nil {
+			j.cancel()
+		}
+		m.records.WriteLogged(j.ID, j.record)
+	}
+	return j.Job, true
+}
+
+// owned returns job id unless it belongs to another owner. The caller must
+// hold m.mu.
+func (m *Manager) owned(id, owner string) (*job, bool) {
+	j, ok := m.jobs[id]
+	if !ok || j.Owner != owner {
+		return nil, false
+	}
+	return j, true
+}
+
+// process executes a queued job until the model stops calling tools, the job
+// reaches its iteration limit or is cancelled
+func (m *Manager) process(ctx context.Context, id string) {
+	m.mu.Lock()
+	j, ok := m.jobs[id]
+	if !ok || m.draining {
+		m.mu.Unlock()
+		return
+	}
+	if j.Status == StatusCancelling {
+		m.finish(j, StatusCancelled, nil)
+		m.mu.Unlock()
+		return
+	}
+	m.running++
+	defer func() {
+		m.mu.Lock()
+		m.running--
+		m.signalIdle()
+		m.mu.Unlock()
+	}()
+	jobCtx, cancel := context.WithCancel(health.Admit(ctx))
+	defer cancel()
+	j.cancel = cancel
+	j.Status = StatusInProgress
+	j.StartedAt = m.timestamp()
+	m.records.WriteLogged(j.ID, j.record)
+	m.mu.Unlock()
+
+	m.logger.Debug("running agent job", "job", id, "model", j.Model, "max_iterations", j.MaxIterations)
+	status, jobErr := m.run(jobCtx, j)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if ctx.Err() != nil {
+		return
+	}
+	if jobCtx.Err() != nil {
+		status, jobErr = StatusCancelled, nil
+	}
+	m.finish(j, status, jobErr)
+}
+
+// run executes the model turns of j, persisting the conversation after each
+// of them, and returns the status the job ends with
+func (m *Manager) run(ctx context.Context, j *job) (string, *JobError) {
+	tools := m.allowedTools(j.Tools)
+	for {
+		m.mu.Lock()
+		if j.Iterations >= j.MaxIterations {
+			m.mu.Unlock()
+			return StatusFailed, &JobError{Code: "max_iterations_exceeded", Message: fmt.Sprintf("The model was still calling tools after %d turns.", j.MaxIterations)}
+		}
+		req := j.Request
+		req.Messages = slices.Clone(j.Request.Messages)
+		m.mu.Unlock()
+
+		if len(tools) > 0 {
+			req.Tools = &tools
+		}
+		body, err := json.Marshal(req)
+		if err != nil {
+			m.logger.Error("failed to build agent job request", err, "job", j.ID)
+			return StatusFailed, &JobError{Code: "server_error", Message: "The job request could not be built."}
+		}
+		status, resp := m.dispatcher.Dispatch(ctx, j.header, j.query, body)
+		if ctx.Err() != nil {
+			return StatusCancelled, nil
+		}
+		if status >= http.StatusBadRequest {
+			return StatusFailed, jobError(status, resp)
+		}
+		var completion types.CreateChatCompletionResponse
+		if err := json.Unmarshal(resp, &completion); err != nil || len(completion.Choices) == 0 {
+			return StatusFailed, &JobError{Code: "server_error", Message: "The model returned an invalid response."}
+		}
+
+		message := completion.Choices[0].Message
+		var calls []types.ChatCompletionMessageToolCall
+		if message.ToolCalls != nil {
+			calls = *message.ToolCalls
+		}
+		var results []types.Message
+		if len(calls) > 0 {
+			results, err = m.executor.ExecuteTools(ctx, calls)
+			if ctx.Err() != nil {
+				return StatusCancelled, nil
+			}
+			if err != nil {
+				m.logger.Error("failed to execute agent job tool calls", err, "job", j.ID)
+				return StatusFailed, &JobError{Code: "tool_error", Message: "The tool calls of the model could not be executed."}
+			}
+		}
+
+		m.mu.Lock()
+		j.Iterations++
+		j.ToolCalls += len(calls)
+		j.addUsage(completion.Usage)
+		j.Request.Messages = append(j.Request.Messages, message)
+		j.Request.Messages = append(j.Request.Messages, results...)
+		if len(calls) == 0 {
+			j.Completion = &completion
+		}
+		m.records.WriteLogged(j.ID, j.record)
+		m.mu.Unlock()
+
+		m.logger.Debug("agent job turn done", "job", j.ID, "iteration", j.Iterations, "tool_calls", len(calls))
+		if len(calls) == 0 {
+			return StatusCompleted, nil
+		}
+	}
+}
+
+// allowedTools returns the MCP tools named in names, all of them when names
+// is empty
+func (m *Manager) allowedTools(names []string) []types.ChatCompletionTool {
+	tools := m.tools.GetAllChatCompletionTools()
+	if len(names) == 0 {
+		return tools
+	}
+	return slices.DeleteFunc(tools, func(tool types.ChatCompletionTool) bool {
+		return !slices.ContainsFunc(names, func(name string) bool { return sameTool(tool.Function.Name, name) })
+	})
+}
+
+// finish moves j to status. The caller must hold m.mu.
+func (m *Manager) finish(j *job, status string, jobErr *JobError) {
+	j.Status = status
+	j.LastError = jobErr
+	j.cancel = nil
+	switch status {
+	case StatusCompleted:
+		j.CompletedAt = m.timestamp()
+	case StatusCancelled:
+		j.CancelledAt = m.timestamp()
+	case StatusFailed:
+		j.FailedAt = m.timestamp()
+	}
+	m.records.WriteLogged(j.ID, j.record)
+	m.logger.Info("agent job finished", "job", j.ID, "status", status, "iterations", j.Iterations, "tool_calls", j.ToolCalls)
+}
+
+// addUsage adds the usage of a model turn to the job's
+func (j *job) addUsage(usage *types.CompletionUsage) {
+	if usage == nil {
+		return
+	}
+	if j.Usage == nil {
+		j.Usage = &Usage{}
+	}
+	j.Usage.PromptTokens += usage.PromptTokens
+	j.Usage.CompletionTokens += usage.CompletionTokens
+	j.Usage.TotalTokens += usage.TotalTokens
+}
+
+// sameTool reports whether name names the MCP tool offered to the model as
+// toolName, with or without its "mcp_" prefix
+func sameTool(toolName, name string) bool {
+	return strings.TrimPrefix(toolName, "mcp_") == strings.TrimPrefix(name, "mcp_")
+}
+
+// jobError describes the error response a model turn got
+func jobError(status int, body []byte) *JobError {
+	var resp errcodes.Response
+	_ = json.Unmarshal(body, &resp)
+	code := "server_error"
+	if status == http.StatusTooManyRequests {
+		code = "rate_limit_exceeded"
+	}
+	return &JobError{Code: code, Message: cmp.Or(resp.Error, fmt.Sprintf("The chat completion request failed with status %d.", status))}
+}
+
+func metadata(m map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return m
+}
+
+func newID(prefix string) string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return prefix + hex.EncodeToString(b)
+}
+
+func (m *Manager) timestamp() *int64 {
+	now := m.now().Unix()
+	return &now
+}
@@ -0,0 +1,307 @@
+package agentjobs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	health "github.com/inference-gateway/inference-gateway/api/health"
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// dispatcherFunc adapts a function to Dispatcher
+type dispatcherFunc func(ctx context.Context, header http.Header, query string, body []byte) (int, []byte)
+
+func (f dispatcherFunc) Dispatch(ctx context.Context, header http.Header, query string, body []byte) (int, []byte) {
+	return f(ctx, header, query, body)
+}
+
+// fakeTools offers read_file and write_file, answering every call with the
+// contents of a file
+type fakeTools struct{}
+
+func (fakeTools) GetAllChatCompletionTools() []types.ChatCompletionTool {
+	return []types.ChatCompletionTool{
+		{Type: types.Function, Function: types.FunctionObject{Name: "mcp_read_file"}},
+		{Type: types.Function, Function: types.FunctionObject{Name: "mcp_write_file"}},
+	}
+}
+
+func (fakeTools) ExecuteTools(ctx context.Context, toolCalls []types.ChatCompletionMessageToolCall) ([]types.Message, error) {
+	results := make([]types.Message, 0, len(toolCalls))
+	for _, call := range toolCalls {
+		msg := types.Message{Role: types.Tool, ToolCallID: &call.ID}
+		if err := msg.Content.FromMessageContent0("file contents"); err != nil {
+			return nil, err
+		}
+		results = append(results, msg)
+	}
+	return results, nil
+}
+
+// dispatched records a chat completion request of a job
+type dispatched struct {
+	header   http.Header
+	req      types.CreateChatCompletionRequest
+	admitted bool
+}
+
+// newTestManager returns a started manager whose model reads a file before
+// answering. The model "loop" never stops calling tools, "limited" is rate
+// limited and "slow" blocks after its tool call until the job is cancelled.
+func newTestManager(t *testing.T, dir string) (*Manager, *gin.Engine, chan dispatched) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	requests := make(chan dispatched, 100)
+	dispatcher := dispatcherFunc(func(ctx context.Context, header http.Header, query string, body []byte) (int, []byte) {
+		var req types.CreateChatCompletionRequest
+		require.NoError(t, json.Unmarshal(body, &req))
+		requests <- dispatched{header: header, req: req, admitted: health.Admitted(ctx)}
+		last := req.Messages[len(req.Messages)-1]
+		switch {
+		case req.Model == "limited":
+			resp, _ := json.Marshal(errcodes.UpstreamRateLimited.Response("Rate limited by the provider"))
+			return http.StatusTooManyRequests, resp
+		case req.Model == "slow" && last.Role == types.Tool:
+			<-ctx.Done()
+			return http.StatusInternalServerError, nil
+		}
+
+		message := map[string]any{"role": "assistant", "content": "The file says: " + last.TextContent()}
+		if last.Role == types.User || req.Model == "loop" {
+			message = map[string]any{"role": "assistant", "content": "", "tool_calls": []map[string]any{{
+				"id":       "call_1",
+				"type":     "function",
+				"function": map[string]any{"name": "mcp_read_file", "arguments": `{"path":"notes.txt"}`},
+			}}}
+		}
+		resp, _ := json.Marshal(map[string]any{
+			"id":      "chatcmpl-1",
+			"object":  "chat.completion",
+			"model":   req.Model,
+			"choices": []map[string]any{{"index": 0, "finish_reason": "stop", "message": message}},
+			"usage":   map[string]any{"prompt_tokens": 10, "completion_tokens": 2, "total_tokens": 12},
+		})
+		return http.StatusOK, resp
+	})
+
+	m, err := NewManager(dispatcher, fakeTools{}, fakeTools{}, logger.NewNoopLogger(), Options{Dir: dir, Workers: 2, MaxIterations: 20})
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	m.Start(ctx)
+
+	r := gin.New()
+	r.POST("/v1/agent/jobs", m.CreateHandler)
+	r.GET("/v1/agent/jobs/:id", m.GetHandler)
+	r.GET("/v1/agent/jobs/:id/result", m.ResultHandler)
+	r.POST("/v1/agent/jobs/:id/cancel", m.CancelHandler)
+	return m, r, requests
+}
+
+// user is the owner of the jobs created by do
+var user = tenants.Owner(context.Background(), http.Header{"Authorization": {"Bearer user-token"}})
+
+func do(t *testing.T, r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	return doAs(t, r, "Bearer user-token", method, path, body)
+}
+
+// doAs serves a request carrying the Authorization header authorization
+func doAs(t *testing.T, r *gin.Engine, authorization, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", authorization)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decode[T any](t *testing.T, w *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &v), w.Body.String())
+	return v
+}
+
+func createJob(t *testing.T, r *gin.Engine, body string) Job {
+	t.Helper()
+	w := do(t, r, http.MethodPost, "/v1/agent/jobs", body)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	return decode[Job](t, w)
+}
+
+func waitForJob(t *testing.T, m *Manager, id string, done func(Job) bool) Job {
+	t.Helper()
+	var j Job
+	require.Eventually(t, func() bool {
+		j, _ = m.Get(id, user)
+		return done(j)
+	}, 5*time.Second, 10*time.Millisecond)
+	return j
+}
+
+func hasStatus(status string) func(Job) bool {
+	return func(j Job) bool { return j.Status == status }
+}
+
+func TestJob(t *testing.T) {
+	dir := t.TempDir()
+	m, r, requests := newTestManager(t, dir)
+
+	j := createJob(t, r, `{"request":{"model":"openai/gpt-4o","messages":[{"role":"user","content":"Summarize notes.txt"}]},"tools":["read_file"],"metadata":{"user":"42"}}`)
+	assert.Equal(t, StatusQueued, j.Status)
+	assert.Equal(t, 20, j.MaxIterations, "AGENT_JOBS_MAX_ITERATIONS by default")
+	assert.Equal(t, map[string]string{"user": "42"}, j.Metadata)
+
+	j = waitForJob(t, m, j.ID, hasStatus(StatusCompleted))
+	assert.Equal(t, 2, j.Iterations)
+	assert.Equal(t, 1, j.ToolCalls)
+	assert.Equal(t, &Usage{PromptTokens: 20, CompletionTokens: 4, TotalTokens: 24}, j.Usage)
+	assert.NotNil(t, j.CompletedAt)
+
+	first := <-requests
+	assert.Equal(t, "Bearer user-token", first.header.Get("Authorization"))
+	assert.NotEmpty(t, first.header.Get(middlewares.MCPBypassHeader), "the job runs the agent loop, not the MCP middleware")
+	require.NotNil(t, first.req.Tools)
+	require.Len(t, *first.req.Tools, 1)
+	assert.Equal(t, "mcp_read_file", (*first.req.Tools)[0].Function.Name)
+
+	w := do(t, r, http.MethodGet, "/v1/agent/jobs/"+j.ID+"/result", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	result := decode[Result](t, w)
+	assert.Equal(t, StatusCompleted, result.Status)
+	require.Len(t, result.Messages, 4)
+	assert.Equal(t, types.Tool, result.Messages[2].Role)
+	require.NotNil(t, result.Completion)
+	assert.Equal(t, "The file says: file contents", result.Completion.Choices[0].Message.TextContent())
+
+	_, err := os.Stat(filepath.Join(dir, j.ID+".json"))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, do(t, r, http.MethodGet, "/v1/agent/jobs/job_unknown", "").Code)
+}
+
+func TestJob_Failures(t *testing.T) {
+	m, r, _ := newTestManager(t, t.TempDir())
+
+	j := createJob(t, r, `{"request":{"model":"loop","messages":[{"role":"user","content":"hi"}]},"max_iterations":3}`)
+	j = waitForJob(t, m, j.ID, hasStatus(StatusFailed))
+	assert.Equal(t, 3, j.Iterations)
+	require.NotNil(t, j.LastError)
+	assert.Equal(t, "max_iterations_exceeded", j.LastError.Code)
+	result, _ := m.Result(j.ID, user)
+	assert.Len(t, result.Messages, 7, "the conversation so far is kept")
+	assert.Nil(t, result.Completion)
+
+	j = createJob(t, r, `{"request":{"model":"limited","messages":[{"role":"user","content":"hi"}]}}`)
+	j = waitForJob(t, m, j.ID, hasStatus(StatusFailed))
+	assert.Equal(t, &JobError{Code: "rate_limit_exceeded", Message: "Rate limited by the provider"}, j.LastError)
+}
+
+func TestJob_Cancel(t *testing.T) {
+	m, r, _ := newTestManager(t, t.TempDir())
+
+	j := createJob(t, r, `{"request":{"model":"slow","messages":[{"role":"user","content":"hi"}]}}`)
+	waitForJob(t, m, j.ID, func(j Job) bool { return j.Iterations == 1 })
+
+	w := do(t, r, http.MethodPost, "/v1/agent/jobs/"+j.ID+"/cancel", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, StatusCancelling, decode[Job](t, w).Status)
+	j = waitForJob(t, m, j.ID, hasStatus(StatusCancelled))
+	assert.NotNil(t, j.CancelledAt)
+	assert.Nil(t, j.LastError)
+}
+
+func TestJob_Owner(t *testing.T) {
+	m, r, _ := newTestManager(t, t.TempDir())
+
+	j := createJob(t, r, `{"request":{"model":"slow","messages":[{"role":"user","content":"hi"}]}}`)
+	waitForJob(t, m, j.ID, func(j Job) bool { return j.Iterations == 1 })
+
+	const other = "Bearer other-token"
+	assert.Equal(t, http.StatusNotFound, doAs(t, r, other, http.MethodGet, "/v1/agent/jobs/"+j.ID, "").Code)
+	assert.Equal(t, http.StatusNotFound, doAs(t, r, other, http.MethodGet, "/v1/agent/jobs/"+j.ID+"/result", "").Code)
+	assert.Equal(t, http.StatusNotFound, doAs(t, r, other, http.MethodPost, "/v1/agent/jobs/"+j.ID+"/cancel", "").Code)
+
+	j, _ = m.Get(j.ID, user)
+	assert.Equal(t, StatusInProgress, j.Status, "the job was not cancelled")
+	_, ok := m.Cancel(j.ID, user)
+	require.True(t, ok)
+}
+
+func TestManager_Drain(t *testing.T) {
+	m, r, requests := newTestManager(t, t.TempDir())
+
+	j := createJob(t, r, `{"request":{"model":"slow","messages":[{"role":"user","content":"hi"}]}}`)
+	waitForJob(t, m, j.ID, func(j Job) bool { return j.Iterations == 1 })
+	assert.True(t, (<-requests).admitted, "the turns of a job are admitted while the gateway drains")
+	assert.Equal(t, 1, m.Running())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, m.Drain(ctx), context.DeadlineExceeded, "the running job is waited for")
+
+	queued := createJob(t, r, `{"request":{"model":"openai/gpt-4o","messages":[{"role":"user","content":"hi"}]}}`)
+	_, ok := m.Cancel(j.ID, user)
+	require.True(t, ok)
+	assert.NoError(t, m.Drain(context.Background()))
+	assert.Equal(t, 0, m.Running())
+
+	time.Sleep(20 * time.Millisecond)
+	queued, _ = m.Get(queued.ID, user)
+	assert.Equal(t, StatusQueued, queued.Status, "no job is taken while draining")
+}
+
+func TestRequestValidation(t *testing.T) {
+	_, r, _ := newTestManager(t, t.TempDir())
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "without model", body: `{"request":{"messages":[{"role":"user","content":"hi"}]}}`},
+		{name: "without messages", body: `{"request":{"model":"openai/gpt-4o","messages":[]}}`},
+		{name: "streamed", body: `{"request":{"model":"openai/gpt-4o","stream":true,"messages":[{"role":"user","content":"hi"}]}}`},
+		{name: "client tools", body: `{"request":{"model":"openai/gpt-4o","tools":[],"messages":[{"role":"user","content":"hi"}]}}`},
+		{name: "unknown tool", body: `{"request":{"model":"openai/gpt-4o","messages":[{"role":"user","content":"hi"}]},"tools":["delete_file"]}`},
+		{name: "max_iterations above the cap", body: `{"request":{"model":"openai/gpt-4o","messages":[{"role":"user","content":"hi"}]},"max_iterations":21}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(t, r, http.MethodPost, "/v1/agent/jobs", tt.body)
+			assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestNewManager_FailsActiveJobs(t *testing.T) {
+	dir := t.TempDir()
+	m, r, _ := newTestManager(t, dir)
+	j := createJob(t, r, `{"request":{"model":"slow","messages":[{"role":"user","content":"hi"}]}}`)
+	waitForJob(t, m, j.ID, func(j Job) bool { return j.Iterations == 1 })
+
+	restarted, err := NewManager(dispatcherFunc(nil), fakeTools{}, fakeTools{}, logger.NewNoopLogger(), Options{Dir: dir, Workers: 1, MaxIterations: 20})
+	require.NoError(t, err)
+	j, ok := restarted.Get(j.ID, user)
+	require.True(t, ok)
+	assert.Equal(t, StatusFailed, j.Status)
+	require.NotNil(t, j.LastError)
+	result, _ := restarted.Result(j.ID, user)
+	assert.Len(t, result.Messages, 3)
+}
@@ -0,0 +1,187 @@
+// Package apispec serves the OpenAPI spec of the endpoints the gateway
+// registers, from which client teams can generate SDKs, and a Swagger UI for
+// it.
+package apispec
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+const (
+	// SpecPath is the path of the OpenAPI spec
+	SpecPath = "/openapi.json"
+	// DocsPath is the path of the Swagger UI
+	DocsPath = "/docs"
+)
+
+// document is openapi.yaml as JSON without the settings used to generate the
+// gateway, generated by task generate
+//
+//go:embed openapi.json
+var document []byte
+
+// methods are the methods given an operation in the spec when the route is not
+// documented, leaving out those registered by the catch-all proxy route
+var methods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// Build returns the spec of routes. A route takes the operation openapi.yaml
+// documents for its path, with or without the /v1 prefix, and a minimal one
+// naming its path parameters otherwise, so the spec lists exactly the
+// endpoints the gateway serves with the features enabled. Paths are absolute,
+// relative to the gateway's URL.
+func Build(routes gin.RoutesInfo) ([]byte, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(document, &doc); err != nil {
+		return nil, err
+	}
+	documented, _ := doc["paths"].(map[string]any)
+
+	paths := make(map[string]any)
+	for _, route := range routes {
+		path := specPath(route.Path)
+		if path == SpecPath || path == DocsPath {
+			continue
+		}
+		documentedItem := documentedPath(documented, path)
+		method := strings.ToLower(route.Method)
+
+		item, ok := paths[path].(map[string]any)
+		if !ok {
+			item = make(map[string]any)
+			for key, value := range documentedItem {
+				if !isMethod(key) {
+					item[key] = value
+				}
+			}
+			paths[path] = item
+		}
+		if op, ok := documentedItem[method]; ok {
+			item[method] = op
+		} else if methods[route.Method] {
+			item[method] = undocumentedOperation(route.Method, path)
+		}
+	}
+
+	doc["paths"] = paths
+	doc["servers"] = []any{map[string]any{"url": "/", "description": "This gateway"}}
+	return json.Marshal(doc)
+}
+
+// Register builds the spec of the routes registered on r and serves it at
+// SpecPath, with the Swagger UI at DocsPath
+func Register(r *gin.Engine) error {
+	spec, err := Build(r.Routes())
+	if err != nil {
+		return err
+	}
+	r.GET(SpecPath, func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", spec)
+	})
+	r.GET(DocsPath, func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUI))
+	})
+	return nil
+}
+
+// specPath converts the parameters of a Gin route path, :name and *name, to
+// the {name} of OpenAPI
+func specPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// documentedPath returns the path item documented for path in paths, which
+// omit the /v1 prefix of the versioned endpoints
+func documentedPath(paths map[string]any, path string) map[string]any {
+	if item, ok := paths[path].(map[string]any); ok {
+		return item
+	}
+	if rest, ok := strings.CutPrefix(path, "/v1"); ok {
+		if item, ok := paths[rest].(map[string]any); ok {
+			return item
+		}
+	}
+	return nil
+}
+
+// isMethod reports whether key of a path item is an operation
+func isMethod(key string) bool {
+	switch key {
+	case "get", "put", "post", "delete", "options", "head", "patch", "trace":
+		return true
+	}
+	return false
+}
+
+// undocumentedOperation returns the operation of a route openapi.yaml does not
+// document: its ID, path parameters and a response of any type
+func undocumentedOperation(method, path string) map[string]any {
+	id := strings.ToLower(method)
+	var parameters []any
+	for segment := range strings.SplitSeq(path, "/") {
+		name, isParam := strings.CutPrefix(segment, "{")
+		name = strings.TrimSuffix(name, "}")
+		for part := range strings.SplitSeq(name, "_") {
+			if part != "" {
+				id += strings.ToUpper(part[:1]) + part[1:]
+			}
+		}
+		if isParam {
+			parameters = append(parameters, map[string]any{
+				"name":     name,
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]any{"type": "string"},
+			})
+		}
+	}
+
+	op := map[string]any{
+		"operationId": id,
+		"summary":     method + " " + path,
+		"responses": map[string]any{
+			"default": map[string]any{
+				"description": "Response of the endpoint",
+				"content":     map[string]any{"application/json": map[string]any{"schema": map[string]any{}}},
+			},
+		},
+	}
+	if parameters != nil {
+		op["parameters"] = parameters
+	}
+	return op
+}
+
+// swaggerUI is the page of the Swagger UI, loading its assets from unpkg
+const swaggerUI = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Inference Gateway API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "` + SpecPath + `", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
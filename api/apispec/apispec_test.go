@@ -0,0 +1,58 @@
+package apispec
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+)
+
+func TestRegister(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	noop := func(*gin.Context) {}
+	r := gin.New()
+	r.GET("/health", noop)
+	r.Any("/proxy/:provider/*path", noop)
+	r.GET("/v1/models", noop)
+	r.POST("/v1/threads/:thread_id/runs/:run_id/cancel", noop)
+	require.NoError(t, Register(r))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, SpecPath, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var spec struct {
+		Servers []map[string]string       `json:"servers"`
+		Paths   map[string]map[string]any `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &spec))
+
+	assert.Equal(t, "/", spec.Servers[0]["url"])
+	assert.ElementsMatch(t, []string{"/health", "/proxy/{provider}/{path}", "/v1/models", "/v1/threads/{thread_id}/runs/{run_id}/cancel"}, keys(spec.Paths),
+		"only the registered routes are listed, not the spec and docs themselves")
+	assert.Equal(t, "listModels", spec.Paths["/v1/models"]["get"].(map[string]any)["operationId"], "documented without the /v1 prefix")
+	assert.Contains(t, spec.Paths["/health"], "get")
+	assert.Contains(t, spec.Paths["/proxy/{provider}/{path}"], "patch", "documented methods of the proxy route are kept")
+	assert.Contains(t, spec.Paths["/proxy/{provider}/{path}"], "parameters", "with the parameters of the path")
+	assert.NotContains(t, spec.Paths["/proxy/{provider}/{path}"], "trace")
+
+	cancel := spec.Paths["/v1/threads/{thread_id}/runs/{run_id}/cancel"]["post"].(map[string]any)
+	assert.Equal(t, "postV1ThreadsThreadIdRunsRunIdCancel", cancel["operationId"])
+	assert.Len(t, cancel["parameters"], 2)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, DocsPath, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `url: "/openapi.json"`)
+}
+
+func keys[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	backoff "github.com/inference-gateway/inference-gateway/providers/backoff"
+	core "github.com/inference-gateway/inference-gateway/providers/core"
+	routing "github.com/inference-gateway/inference-gateway/providers/routing"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// statusOverloaded is Anthropic's non-standard overloaded status
+const statusOverloaded = 529
+
+// observeBackoff records how long model of providerID asked to be left
+// alone when err is a throttled provider response carrying Retry-After or
+// rate limit reset headers, capped by PROVIDER_BACKOFF_MAX
+func (router *RouterImpl) observeBackoff(providerID types.Provider, model string, err error) {
+	limit := router.cfg().ProviderBackoffMax
+	var httpErr *core.HTTPError
+	if limit <= 0 || !errors.As(err, &httpErr) {
+		return
+	}
+	switch httpErr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable, statusOverloaded:
+	default:
+		return
+	}
+	wait, ok := backoff.RetryAfter(httpErr.Header, time.Now())
+	if !ok || wait <= 0 {
+		return
+	}
+	wait = min(wait, limit)
+	router.logger.Warn("provider asked to back off", "provider", providerID, "model", model, "status", httpErr.StatusCode, "retry_after", wait)
+	router.backoff.Observe(string(providerID), model, wait)
+}
+
+// backingOff returns how long model of providerID is still backing off,
+// zero when it can be called or PROVIDER_BACKOFF_MAX is 0
+func (router *RouterImpl) backingOff(providerID types.Provider, model string) time.Duration {
+	if router.cfg().ProviderBackoffMax <= 0 {
+		return 0
+	}
+	return router.backoff.Remaining(string(providerID), model)
+}
+
+// deploymentAvailable reports whether a routed deployment is not backing
+// off, so logical model aliases prefer the deployments that can serve them
+func (router *RouterImpl) deploymentAvailable(d routing.Deployment) bool {
+	return router.backingOff(types.Provider(d.Provider), d.Model) == 0
+}
@@ -0,0 +1,190 @@
+// Package batch serves POST /v1/chat/completions/batch, running many chat
+// completion requests in one call for offline evaluation and bulk workloads.
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+// itemPath is the route every batch item is dispatched to
+const itemPath = "/v1/chat/completions"
+
+// Request is the body of POST /v1/chat/completions/batch. Every item is a
+// regular chat completion request; streaming items are rejected.
+type Request struct {
+	Requests []json.RawMessage `json:"requests"`
+}
+
+// Result is the outcome of one item. Exactly one of Response and Error is set,
+// holding the body the item would have gotten from /v1/chat/completions.
+type Result struct {
+	Index    int             `json:"index"`
+	Status   int             `json:"status"`
+	Response json.RawMessage `json:"response,omitempty"`
+	Error    json.RawMessage `json:"error,omitempty"`
+}
+
+// Response is the body of a batch, holding one result per item in request
+// order
+type Response struct {
+	Object string   `json:"object"`
+	Data   []Result `json:"data"`
+}
+
+// Runner dispatches the items of a batch through the gateway's own handler
+type Runner struct {
+	next        http.Handler
+	logger      logger.Logger
+	maxItems    int
+	concurrency int
+}
+
+// NewRunner creates a runner dispatching items to next, normally the gateway
+// router, so every item passes the same middlewares (authentication, tenants,
+// request limits, hooks, prompts, MCP) and handler as a single request. At most
+// concurrency items run at a time.
+func NewRunner(next http.Handler, logger logger.Logger, maxItems, concurrency int) (*Runner, error) {
+	if next == nil {
+		return nil, fmt.Errorf("batch handler is required")
+	}
+	if maxItems < 1 || concurrency < 1 {
+		return nil, fmt.Errorf("batch max items and concurrency must be positive, got %d and %d", maxItems, concurrency)
+	}
+	return &Runner{
+		next:        next,
+		logger:      logger,
+		maxItems:    maxItems,
+		concurrency: concurrency,
+	}, nil
+}
+
+// Handler implements POST /v1/chat/completions/batch. It answers 200 once
+// every item has finished; failed items carry their own status and error.
+func (r *Runner) Handler(c *gin.Context) {
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.logger.Error("failed to decode batch request", err)
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to decode request")
+		return
+	}
+	if len(req.Requests) == 0 {
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Batch contains no requests")
+		return
+	}
+	if len(req.Requests) > r.maxItems {
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.RequestLimitExceeded, fmt.Sprintf("Batch contains %d requests, the maximum is %d", len(req.Requests), r.maxItems))
+		return
+	}
+
+	r.logger.Debug("running batch", "items", len(req.Requests), "concurrency", r.concurrency)
+
+	results := make([]Result, len(req.Requests))
+	sem := make(chan struct{}, r.concurrency)
+	var wg sync.WaitGroup
+	for i, item := range req.Requests {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = r.run(c.Request, i, item)
+		}()
+	}
+	wg.Wait()
+
+	c.JSON(http.StatusOK, Response{Object: "list", Data: results})
+}
+
+// run dispatches one item as a POST /v1/chat/completions request carrying the
+// headers and query of the batch request
+func (r *Runner) run(batchReq *http.Request, index int, item json.RawMessage) Result {
+	var peek struct {
+		Stream bool `json:"stream"`
+	}
+	if err := json.Unmarshal(item, &peek); err != nil {
+		return failure(index, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to decode request")
+	}
+	if peek.Stream {
+		return failure(index, http.StatusBadRequest, errcodes.InvalidRequest, "Streaming is not supported in batches")
+	}
+
+	status, body := r.Dispatch(batchReq.Context(), batchReq.Header, batchReq.URL.RawQuery, item)
+	if !json.Valid(body) {
+		r.logger.Warn("batch item returned a non-json body", "index", index, "status", status)
+		return failure(index, http.StatusBadGateway, errcodes.InternalError, "Item returned an invalid response")
+	}
+	result := Result{Index: index, Status: status}
+	if status >= http.StatusBadRequest {
+		result.Error = body
+	} else {
+		result.Response = body
+	}
+	return result
+}
+
+// Dispatch runs one chat completion request through the gateway handler with
+// the header and query of the request it belongs to, returning the status and
+// body it was answered with
+func (r *Runner) Dispatch(ctx context.Context, header http.Header, query string, body []byte) (int, []byte) {
+	itemReq, err := http.NewRequestWithContext(ctx, http.MethodPost, itemPath, bytes.NewReader(body))
+	if err != nil {
+		resp, _ := json.Marshal(errcodes.InternalError.Response("Failed to create request"))
+		return http.StatusInternalServerError, resp
+	}
+	itemReq.URL.RawQuery = query
+	itemReq.Header = header.Clone()
+	itemReq.Header.Set("Content-Type", "application/json")
+	itemReq.Header.Del("Content-Length")
+	itemReq.Header.Del("Accept-Encoding")
+
+	w := &recorder{header: make(http.Header)}
+	r.next.ServeHTTP(w, itemReq)
+	return w.statusCode(), w.body.Bytes()
+}
+
+// failure builds the result of an item that failed outside of the item handler
+func failure(index, status int, code errcodes.Code, message string) Result {
+	body, _ := json.Marshal(code.Response(message))
+	return Result{Index: index, Status: status, Error: body}
+}
+
+// recorder is an in-memory http.ResponseWriter collecting an item's response
+type recorder struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
+
+func (w *recorder) Header() http.Header {
+	return w.header
+}
+
+func (w *recorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+func (w *recorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *recorder) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
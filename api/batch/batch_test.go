@@ -0,0 +1,101 @@
+package batch
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+func TestRunner(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var running, peak atomic.Int32
+	r := gin.New()
+	r.POST("/v1/chat/completions", func(c *gin.Context) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		var req struct {
+			Model string `json:"model"`
+		}
+		require.NoError(t, c.ShouldBindJSON(&req))
+		if req.Model == "forbidden" {
+			errcodes.JSON(c, http.StatusForbidden, errcodes.ModelNotAllowed, "Model not allowed")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"model": req.Model, "tenant": c.GetHeader("X-Tenant-ID"), "provider": c.Query("provider")})
+	})
+
+	runner, err := NewRunner(r, logger.NewNoopLogger(), 5, 2)
+	require.NoError(t, err)
+	r.POST("/v1/chat/completions/batch", runner.Handler)
+
+	serve := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions/batch?provider=openai", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "acme")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("results are returned in order with per-item errors", func(t *testing.T) {
+		w := serve(`{"requests":[{"model":"a"},{"model":"forbidden"},{"model":"b","stream":true},{"model":"c"},{"model":"d"}]}`)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Data, 5)
+
+		for i, result := range resp.Data {
+			assert.Equal(t, i, result.Index)
+		}
+		assert.Equal(t, http.StatusOK, resp.Data[0].Status)
+		assert.JSONEq(t, `{"model":"a","tenant":"acme","provider":"openai"}`, string(resp.Data[0].Response), "items carry the headers and query of the batch")
+		assert.Equal(t, http.StatusForbidden, resp.Data[1].Status)
+		assert.Contains(t, string(resp.Data[1].Error), errcodes.ModelNotAllowed.ID)
+		assert.Nil(t, resp.Data[1].Response)
+		assert.Equal(t, http.StatusBadRequest, resp.Data[2].Status)
+		assert.Contains(t, string(resp.Data[2].Error), "Streaming is not supported")
+		assert.JSONEq(t, `{"model":"d","tenant":"acme","provider":"openai"}`, string(resp.Data[4].Response))
+
+		assert.LessOrEqual(t, peak.Load(), int32(2), "concurrency is limited")
+	})
+
+	t.Run("too many items", func(t *testing.T) {
+		w := serve(`{"requests":[{},{},{},{},{},{}]}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), errcodes.RequestLimitExceeded.ID)
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		w := serve(`{"requests":[]}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), errcodes.InvalidRequest.ID)
+	})
+}
+
+func TestNewRunner_Invalid(t *testing.T) {
+	_, err := NewRunner(nil, logger.NewNoopLogger(), 1, 1)
+	assert.Error(t, err)
+	_, err = NewRunner(gin.New(), logger.NewNoopLogger(), 1, 0)
+	assert.Error(t, err)
+}
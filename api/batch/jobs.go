@@ -0,0 +1,796 @@
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	files "github.com/inference-gateway/inference-gateway/api/files"
+	queue "github.com/inference-gateway/inference-gateway/api/queue"
+	records "github.com/inference-gateway/inference-gateway/api/records"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+// Job statuses of the OpenAI Batch API
+const (
+	StatusValidating = "validating"
+	StatusFailed     = "failed"
+	StatusInProgress = "in_progress"
+	StatusFinalizing = "finalizing"
+	StatusCompleted  = "completed"
+	StatusExpired    = "expired"
+	StatusCancelling = "cancelling"
+	StatusCancelled  = "cancelled"
+)
+
+const (
+	// completionWindow is the only completion window of the Batch API
+	completionWindow = "24h"
+	// completionWindowDuration is how long a job may run before it expires
+	completionWindowDuration = 24 * time.Hour
+	// queueSize bounds the number of jobs waiting for a worker
+	queueSize = 1024
+)
+
+var (
+	// errInvalidJob is returned for job requests the Batch API rejects
+	errInvalidJob = errors.New("invalid batch")
+	// errQueueFull is returned when too many jobs are waiting for a worker
+	errQueueFull = errors.New("batch queue is full")
+)
+
+// JobRequest is the JSON body of POST /v1/batches
+type JobRequest struct {
+	InputFileID      string            `json:"input_file_id"`
+	Endpoint         string            `json:"endpoint"`
+	CompletionWindow string            `json:"completion_window"`
+	Metadata         map[string]string `json:"metadata,omitempty"`
+}
+
+// RequestCounts counts the requests of a job by outcome
+type RequestCounts struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+}
+
+// JobError describes why a job or one of its requests failed
+type JobError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Line    *int   `json:"line,omitempty"`
+}
+
+// JobErrors lists the validation errors of a failed job
+type JobErrors struct {
+	Object string     `json:"object"`
+	Data   []JobError `json:"data"`
+}
+
+// Job is a batch in the shape of the OpenAI Batch API object
+type Job struct {
+	ID               string            `json:"id"`
+	Object           string            `json:"object"`
+	Endpoint         string            `json:"endpoint"`
+	Errors           *JobErrors        `json:"errors"`
+	InputFileID      string            `json:"input_file_id"`
+	CompletionWindow string            `json:"completion_window"`
+	Status           string            `json:"status"`
+	OutputFileID     string            `json:"output_file_id,omitempty"`
+	ErrorFileID      string            `json:"error_file_id,omitempty"`
+	CreatedAt        int64             `json:"created_at"`
+	InProgressAt     *int64            `json:"in_progress_at,omitempty"`
+	ExpiresAt        int64             `json:"expires_at"`
+	FinalizingAt     *int64            `json:"finalizing_at,omitempty"`
+	CompletedAt      *int64            `json:"completed_at,omitempty"`
+	FailedAt         *int64            `json:"failed_at,omitempty"`
+	ExpiredAt        *int64            `json:"expired_at,omitempty"`
+	CancellingAt     *int64            `json:"cancelling_at,omitempty"`
+	CancelledAt      *int64            `json:"cancelled_at,omitempty"`
+	RequestCounts    RequestCounts     `json:"request_counts"`
+	Metadata         map[string]string `json:"metadata,omitempty"`
+}
+
+// JobList is the body of GET /v1/batches
+type JobList struct {
+	Object  string `json:"object"`
+	Data    []Job  `json:"data"`
+	HasMore bool   `json:"has_more"`
+}
+
+// inputLine is one line of a batch input file
+type inputLine struct {
+	CustomID string          `json:"custom_id"`
+	Method   string          `json:"method"`
+	URL      string          `json:"url"`
+	Body     json.RawMessage `json:"body"`
+}
+
+// outputLine is one line of a batch output or error file
+type outputLine struct {
+	ID       string          `json:"id"`
+	CustomID string          `json:"custom_id"`
+	Response *outputResponse `json:"response"`
+	Error    *JobError       `json:"error"`
+}
+
+// outputResponse is the answer a request of a job got
+type outputResponse struct {
+	StatusCode int             `json:"status_code"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// record is the persisted state of a job. Credentials are never persisted;
+// HadCredentials records that the job was created with some, so it cannot be
+// resumed after a restart. Owner identifies the caller that created the job,
+// see tenants.Owner.
+type record struct {
+	Job
+	Owner          string      `json:"owner"`
+	Header         http.Header `json:"header,omitempty"`
+	Query          string      `json:"query,omitempty"`
+	HadCredentials bool        `json:"had_credentials,omitempty"`
+}
+
+// job is a record with its in-memory state
+type job struct {
+	record
+	header http.Header
+	cancel context.CancelFunc
+}
+
+// credentialHeaders are dropped from persisted job headers
+var credentialHeaders = []string{"Authorization", "Cookie", "X-Admin-Token"}
+
+// Manager runs Batch API jobs in a background worker pool. Job state is
+// persisted in a directory so status and results survive restarts; partial
+// results are appended to the job's .output and .errors files as requests
+// finish, so a resumed job skips the requests already done.
+type Manager struct {
+	runner  *Runner
+	files   files.Store
+	dir     string
+	records *records.Store[record]
+	logger  logger.Logger
+	workers int
+	now     func() time.Time
+	queue   chan string
+
+	mu   sync.Mutex
+	jobs map[string]*job
+}
+
+// NewManager creates a manager keeping job state in dir and reading and
+// writing job files in store. Jobs left unfinished by a previous run are
+// queued again, except those created with credentials, which are failed
+// because the credentials were not persisted.
+func NewManager(runner *Runner, store files.Store, dir string, workers int, logger logger.Logger) (*Manager, error) {
+	if runner == nil || store == nil {
+		return nil, errors.New("batch runner and file store are required")
+	}
+	if workers < 1 {
+		return nil, fmt.Errorf("batch workers must be positive, got %d", workers)
+	}
+	recs, err := records.New[record](dir, "batch", logger)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manager{
+		runner:  runner,
+		files:   store,
+		dir:     dir,
+		records: recs,
+		logger:  logger,
+		workers: workers,
+		now:     time.Now,
+		queue:   make(chan string, queueSize),
+		jobs:    make(map[string]*job),
+	}
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// load reads the persisted jobs and queues the unfinished ones
+func (m *Manager) load() error {
+	return m.records.Load(func(rec record) {
+		j := &job{record: rec, header: rec.Header}
+		m.jobs[rec.ID] = j
+
+		switch rec.Status {
+		case StatusValidating, StatusInProgress, StatusFinalizing, StatusCancelling:
+			if rec.HadCredentials {
+				m.fail(j, JobError{Code: "gateway_restarted", Message: "The gateway restarted while the batch was running. Create the batch again."})
+				return
+			}
+			select {
+			case m.queue <- rec.ID:
+				m.logger.Info("resuming batch", "batch", rec.ID, "status", rec.Status)
+			default:
+				m.fail(j, JobError{Code: "queue_full", Message: "Too many batches were unfinished when the gateway restarted. Create the batch again."})
+			}
+		}
+	})
+}
+
+// Start runs the workers until ctx is done. Requests in flight when ctx ends
+// are not recorded, so their jobs resume from them on the next start.
+func (m *Manager) Start(ctx context.Context) {
+	for range m.workers {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-m.queue:
+					m.process(ctx, id)
+				}
+			}
+		}()
+	}
+}
+
+// Create validates req, registers a job reading its input from the file
+// InputFileID and queues it
+func (m *Manager) Create(ctx context.Context, req JobRequest, header http.Header, query string) (Job, error) {
+	if req.Endpoint != itemPath {
+		return Job{}, fmt.Errorf("%w: endpoint must be %s", errInvalidJob, itemPath)
+	}
+	if req.CompletionWindow != completionWindow {
+		return Job{}, fmt.Errorf("%w: completion_window must be %s", errInvalidJob, completionWindow)
+	}
+	owner := tenants.Owner(ctx, header)
+	if _, err := files.GetOwned(ctx, m.files, req.InputFileID, owner); err != nil {
+		return Job{}, fmt.Errorf("input file %s: %w", req.InputFileID, err)
+	}
+
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return Job{}, err
+	}
+	now := m.now()
+	persisted := header.Clone()
+	hadCredentials := false
+	for _, name := range credentialHeaders {
+		if persisted.Get(name) != "" {
+			hadCredentials = true
+		}
+		persisted.Del(name)
+	}
+
+	j := &job{
+		record: record{
+			Job: Job{
+				ID:               "batch_" + hex.EncodeToString(b),
+				Object:           "batch",
+				Endpoint:         req.Endpoint,
+				InputFileID:      req.InputFileID,
+				CompletionWindow: req.CompletionWindow,
+				Status:           StatusValidating,
+				CreatedAt:        now.Unix(),
+				ExpiresAt:        now.Add(completionWindowDuration).Unix(),
+				Metadata:         req.Metadata,
+			},
+			Owner:          owner,
+			Header:         persisted,
+			Query:          query,
+			HadCredentials: hadCredentials,
+		},
+		header: header.Clone(),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.records.Write(j.ID, j.record); err != nil {
+		return Job{}, err
+	}
+	select {
+	case m.queue <- j.ID:
+	default:
+		m.records.Remove(j.ID)
+		return Job{}, errQueueFull
+	}
+	m.jobs[j.ID] = j
+	return j.Job, nil
+}
+
+// Get returns job id, unless it was created by another owner
+func (m *Manager) Get(id, owner string) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	if !ok || j.Owner != owner {
+		return Job{}, false
+	}
+	return j.Job, true
+}
+
+// List returns the jobs created by owner, newest first
+func (m *Manager) List(owner string) []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	jobs := make([]Job, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		if j.Owner == owner {
+			jobs = append(jobs, j.Job)
+		}
+	}
+	slices.SortFunc(jobs, func(a, b Job) int {
+		return cmp.Or(cmp.Compare(b.CreatedAt, a.CreatedAt), strings.Compare(a.ID, b.ID))
+	})
+	return jobs
+}
+
+// Cancel stops job id, unless it was created by another owner. Requests
+// already finished keep their results.
+func (m *Manager) Cancel(id, owner string) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	if !ok || j.Owner != owner {
+		return Job{}, false
+	}
+	switch j.Status {
+	case StatusValidating, StatusInProgress:
+		j.Status = StatusCancelling
+		j.CancellingAt = m.timestamp()
+		if j.cancel != nil {
+			j.cancel()
+		}
+		m.records.WriteLogged(j.ID, j.record)
+	}
+	return j.Job, true
+}
+
+// process runs job id from validation to completion
+func (m *Manager) process(ctx context.Context, id string) {
+	m.mu.Lock()
+	j, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	j.cancel = cancel
+	status := j.Status
+	m.mu.Unlock()
+
+	if status == StatusCancelling {
+		m.finish(j, StatusCancelled)
+		return
+	}
+
+	lines, validationErrs, err := m.readInput(ctx, j.InputFileID)
+	if err != nil {
+		m.logger.Error("failed to read batch input", err, "batch", id)
+		m.fail(j, JobError{Code: "input_unreadable", Message: "The input file could not be read."})
+		return
+	}
+	if len(validationErrs) > 0 {
+		m.fail(j, validationErrs...)
+		return
+	}
+
+	done, err := m.finished(id)
+	if err != nil {
+		m.logger.Error("failed to read partial batch results", err, "batch", id)
+		m.fail(j, JobError{Code: "results_unreadable", Message: "The partial results of the batch could not be read."})
+		return
+	}
+
+	m.mu.Lock()
+	if j.Status == StatusValidating {
+		j.Status = StatusInProgress
+		j.InProgressAt = m.timestamp()
+	}
+	j.RequestCounts.Total = len(lines)
+	m.records.WriteLogged(j.ID, j.record)
+	m.mu.Unlock()
+
+	m.logger.Info("running batch", "batch", id, "requests", len(lines), "done", len(done))
+
+	sem := make(chan struct{}, m.runner.concurrency)
+	var wg sync.WaitGroup
+	expired := false
+	// Job items wait behind interactive requests when providers are saturated
+	header := j.header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	if header.Get(queue.PriorityHeader) == "" {
+		header.Set(queue.PriorityHeader, queue.PriorityBatch)
+	}
+	dispatchCtx := queue.WithHold(jobCtx)
+	for _, line := range lines {
+		if _, ok := done[line.CustomID]; ok {
+			continue
+		}
+		if m.now().Unix() >= j.ExpiresAt {
+			expired = true
+			break
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-jobCtx.Done():
+		}
+		if jobCtx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			status, body := m.runner.Dispatch(dispatchCtx, header, j.Query, line.Body)
+			if jobCtx.Err() != nil {
+				return
+			}
+			m.saveResult(j, line.CustomID, status, body)
+		}()
+	}
+	wg.Wait()
+
+	switch {
+	case ctx.Err() != nil:
+		return
+	case jobCtx.Err() != nil:
+		m.finish(j, StatusCancelled)
+	case expired:
+		m.finish(j, StatusExpired)
+	default:
+		m.finish(j, StatusCompleted)
+	}
+}
+
+// readInput parses and validates the input file of a job
+func (m *Manager) readInput(ctx context.Context, fileID string) ([]inputLine, []JobError, error) {
+	f, err := m.files.Open(ctx, fileID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	var lines []inputLine
+	var errs []JobError
+	seen := make(map[string]struct{})
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		lineErr := func(code, message string) {
+			errs = append(errs, JobError{Code: code, Message: message, Line: &n})
+		}
+
+		var line inputLine
+		if err := json.Unmarshal(raw, &line); err != nil {
+			lineErr("invalid_json_line", "The line is not valid JSON.")
+			continue
+		}
+		var peek struct {
+			Stream bool `json:"stream"`
+		}
+		switch {
+		case line.CustomID == "":
+			lineErr("missing_custom_id", "The custom_id of the request is missing.")
+		case line.Method != http.MethodPost:
+			lineErr("invalid_method", "The method of the request must be POST.")
+		case line.URL != itemPath:
+			lineErr("invalid_url", fmt.Sprintf("The url of the request must be %s.", itemPath))
+		case json.Unmarshal(line.Body, &peek) != nil:
+			lineErr("invalid_body", "The body of the request must be a JSON object.")
+		case peek.Stream:
+			lineErr("invalid_body", "Streaming is not supported in batches.")
+		default:
+			if _, dup := seen[line.CustomID]; dup {
+				lineErr("duplicate_custom_id", "The custom_id of the request is not unique.")
+				continue
+			}
+			seen[line.CustomID] = struct{}{}
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	if len(lines) == 0 && len(errs) == 0 {
+		errs = append(errs, JobError{Code: "empty_file", Message: "The input file contains no requests."})
+	}
+	return lines, errs, nil
+}
+
+// finished returns the custom IDs of the requests of job id that already have
+// a result, restoring the request counts from them
+func (m *Manager) finished(id string) (map[string]struct{}, error) {
+	done := make(map[string]struct{})
+	var counts RequestCounts
+	for path, counter := range map[string]*int{m.partialPath(id, "output"): &counts.Completed, m.partialPath(id, "errors"): &counts.Failed} {
+		f, err := os.Open(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		dec := json.NewDecoder(f)
+		for {
+			var line outputLine
+			if err := dec.Decode(&line); err != nil {
+				f.Close()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, err
+			}
+			done[line.CustomID] = struct{}{}
+			*counter++
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j := m.jobs[id]
+	j.RequestCounts.Completed = counts.Completed
+	j.RequestCounts.Failed = counts.Failed
+	return done, nil
+}
+
+// saveResult appends the result of one request to the partial results of j
+func (m *Manager) saveResult(j *job, customID string, status int, body []byte) {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	line := outputLine{ID: "batch_req_" + hex.EncodeToString(b), CustomID: customID}
+	if !json.Valid(body) {
+		body, _ = json.Marshal(errcodes.InternalError.Response("Request returned an invalid response"))
+	}
+	line.Response = &outputResponse{StatusCode: status, Body: body}
+	kind := "output"
+	if status >= http.StatusBadRequest {
+		kind = "errors"
+		line.Error = &JobError{Code: "request_failed", Message: fmt.Sprintf("The request failed with status %d.", status)}
+	}
+	data, err := json.Marshal(line)
+	if err != nil {
+		m.logger.Error("failed to encode batch result", err, "batch", j.ID)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, err := os.OpenFile(m.partialPath(j.ID, kind), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		m.logger.Error("failed to write batch result", err, "batch", j.ID)
+		return
+	}
+	_, err = f.Write(append(data, '\n'))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		m.logger.Error("failed to write batch result", err, "batch", j.ID)
+		return
+	}
+	if kind == "output" {
+		j.RequestCounts.Completed++
+	} else {
+		j.RequestCounts.Failed++
+	}
+	m.records.WriteLogged(j.ID, j.record)
+}
+
+// finish publishes the partial results of j as its output and error files
+// and moves it to status
+func (m *Manager) finish(j *job, status string) {
+	m.mu.Lock()
+	j.Status = StatusFinalizing
+	j.FinalizingAt = m.timestamp()
+	m.records.WriteLogged(j.ID, j.record)
+	m.mu.Unlock()
+
+	outputID, err := m.publish(j, "output")
+	var errorID string
+	if err == nil {
+		errorID, err = m.publish(j, "errors")
+	}
+	if err != nil {
+		m.logger.Error("failed to store batch results", err, "batch", j.ID)
+		m.fail(j, JobError{Code: "results_unwritable", Message: "The results of the batch could not be stored."})
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j.OutputFileID = outputID
+	j.ErrorFileID = errorID
+	j.Status = status
+	switch status {
+	case StatusCompleted:
+		j.CompletedAt = m.timestamp()
+	case StatusExpired:
+		j.ExpiredAt = m.timestamp()
+	case StatusCancelled:
+		j.CancelledAt = m.timestamp()
+	}
+	m.records.WriteLogged(j.ID, j.record)
+	m.logger.Info("batch finished", "batch", j.ID, "status", status, "completed", j.RequestCounts.Completed, "failed", j.RequestCounts.Failed)
+}
+
+// publish moves a partial results file into the file store, returning its
+// file ID or "" when there are no such results. The file belongs to the owner
+// of j.
+func (m *Manager) publish(j *job, kind string) (string, error) {
+	id := j.ID
+	path := m.partialPath(id, kind)
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	file, err := m.files.Create(context.Background(), id+"_"+kind+".jsonl", files.PurposeBatchOutput, j.Owner, f)
+	f.Close()
+	if err != nil {
+		return "", err
+	}
+	if err := os.Remove(path); err != nil {
+		m.logger.Warn("failed to remove partial batch results", "batch", id, "path", path, "error", err.Error())
+	}
+	return file.ID, nil
+}
+
+// fail moves j to the failed status with errs
+func (m *Manager) fail(j *job, errs ...JobError) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j.Status = StatusFailed
+	j.FailedAt = m.timestamp()
+	j.Errors = &JobErrors{Object: "list", Data: errs}
+	m.records.WriteLogged(j.ID, j.record)
+	m.logger.Warn("batch failed", "batch", j.ID, "errors", len(errs), "first_error", errs[0].Code)
+}
+
+func (m *Manager) timestamp() *int64 {
+	now := m.now().Unix()
+	return &now
+}
+
+func (m *Manager) partialPath(id, kind string) string {
+	return filepath.Join(m.dir, id+"."+kind+".jsonl")
+}
+
+// CreateHandler implements POST /v1/batches. Besides the OpenAI JSON body
+// referencing an uploaded input_file_id, it accepts a multipart form carrying
+// the JSONL input in its file field together with the endpoint and
+// completion_window fields.
+func (m *Manager) CreateHandler(c *gin.Context) {
+	var req JobRequest
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		upload, err := c.FormFile("file")
+		if err != nil {
+			errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "The multipart form has no file field")
+			return
+		}
+		content, err := upload.Open()
+		if err != nil {
+			errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to read the uploaded file")
+			return
+		}
+		defer content.Close()
+		file, err := m.files.Create(c.Request.Context(), upload.Filename, files.PurposeBatch, owner(c), content)
+		if err != nil {
+			m.logger.Error("failed to store batch input", err)
+			errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to store the uploaded file")
+			return
+		}
+		req = JobRequest{
+			InputFileID:      file.ID,
+			Endpoint:         cmp.Or(c.PostForm("endpoint"), itemPath),
+			CompletionWindow: cmp.Or(c.PostForm("completion_window"), completionWindow),
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to decode request")
+		return
+	}
+
+	j, err := m.Create(c.Request.Context(), req, c.Request.Header, c.Request.URL.RawQuery)
+	switch {
+	case errors.Is(err, files.ErrNotFound):
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, err.Error())
+	case errors.Is(err, errQueueFull):
+		errcodes.JSON(c, http.StatusServiceUnavailable, errcodes.InternalError, "Too many batches are waiting; retry later")
+	case errors.Is(err, errInvalidJob):
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, err.Error())
+	case err != nil:
+		m.logger.Error("failed to create batch", err)
+		errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to create batch")
+	default:
+		m.logger.Info("batch created", "batch", j.ID, "input_file_id", j.InputFileID)
+		c.JSON(http.StatusOK, j)
+	}
+}
+
+// GetHandler implements GET /v1/batches/:id. Batches created by another
+// caller are reported as not found.
+func (m *Manager) GetHandler(c *gin.Context) {
+	j, ok := m.Get(c.Param("id"), owner(c))
+	if !ok {
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, "Batch not found")
+		return
+	}
+	c.JSON(http.StatusOK, j)
+}
+
+// ListHandler implements GET /v1/batches, listing the caller's batches
+func (m *Manager) ListHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, JobList{Object: "list", Data: m.List(owner(c))})
+}
+
+// CancelHandler implements POST /v1/batches/:id/cancel
+func (m *Manager) CancelHandler(c *gin.Context) {
+	j, ok := m.Cancel(c.Param("id"), owner(c))
+	if !ok {
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, "Batch not found")
+		return
+	}
+	c.JSON(http.StatusOK, j)
+}
+
+// OutputHandler implements GET /v1/batches/:id/output and
+// GET /v1/batches/:id/errors, serving the JSONL output or error file of a
+// finished job
+func (m *Manager) OutputHandler(c *gin.Context) {
+	j, ok := m.Get(c.Param("id"), owner(c))
+	if !ok {
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, "Batch not found")
+		return
+	}
+	fileID := j.OutputFileID
+	if strings.HasSuffix(c.Request.URL.Path, "/errors") {
+		fileID = j.ErrorFileID
+	}
+	if fileID == "" {
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, "The batch has no such results")
+		return
+	}
+
+	content, err := m.files.Open(c.Request.Context(), fileID)
+	if err != nil {
+		m.logger.Error("failed to open batch results", err, "batch", j.ID, "file", fileID)
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, "The batch results are no longer available")
+		return
+	}
+	defer content.Close()
+	c.Header("Content-Type", "application/jsonl")
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, content); err != nil {
+		m.logger.Error("failed to send batch results", err, "batch", j.ID)
+	}
+}
+
+// owner identifies the caller of c, see tenants.Owner
+func owner(c *gin.Context) string {
+	return tenants.Owner(c.Request.Context(), c.Request.Header)
+}
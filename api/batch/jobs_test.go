@@ -0,0 +1,317 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	files "github.com/inference-gateway/inference-gateway/api/files"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+// newTestManager returns a manager whose chat handler echoes the model,
+// failing requests for the model "forbidden" and blocking on "slow" until
+// release is closed
+func newTestManager(t *testing.T, dir string, release chan struct{}) (*Manager, *gin.Engine, files.Store) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.POST("/v1/chat/completions", func(c *gin.Context) {
+		var req struct {
+			Model string `json:"model"`
+		}
+		require.NoError(t, c.ShouldBindJSON(&req))
+		switch req.Model {
+		case "forbidden":
+			errcodes.JSON(c, http.StatusForbidden, errcodes.ModelNotAllowed, "Model not allowed")
+			return
+		case "slow":
+			select {
+			case <-release:
+			case <-c.Request.Context().Done():
+				return
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{"model": req.Model, "tenant": c.GetHeader("X-Tenant-ID")})
+	})
+
+	runner, err := NewRunner(r, logger.NewNoopLogger(), 10, 2)
+	require.NoError(t, err)
+	store, err := files.NewDiskStore(filepath.Join(dir, "files"))
+	require.NoError(t, err)
+	m, err := NewManager(runner, store, filepath.Join(dir, "batches"), 1, logger.NewNoopLogger())
+	require.NoError(t, err)
+
+	r.POST("/v1/batches", m.CreateHandler)
+	r.GET("/v1/batches", m.ListHandler)
+	r.GET("/v1/batches/:id", m.GetHandler)
+	r.POST("/v1/batches/:id/cancel", m.CancelHandler)
+	r.GET("/v1/batches/:id/output", m.OutputHandler)
+	r.GET("/v1/batches/:id/errors", m.OutputHandler)
+	return m, r, store
+}
+
+// anonymous is the owner of the batches created without credentials
+var anonymous = tenants.Owner(context.Background(), http.Header{})
+
+func inputFile(models ...string) string {
+	var b strings.Builder
+	for i, model := range models {
+		line, _ := json.Marshal(map[string]any{
+			"custom_id": "req-" + string(rune('a'+i)),
+			"method":    "POST",
+			"url":       "/v1/chat/completions",
+			"body":      map[string]any{"model": model},
+		})
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func upload(t *testing.T, r *gin.Engine, content string) Job {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "input.jsonl")
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, form.WriteField("completion_window", "24h"))
+	require.NoError(t, form.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/batches", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("X-Tenant-ID", "acme")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var j Job
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &j))
+	return j
+}
+
+func waitFor(t *testing.T, m *Manager, id string, status string) Job {
+	t.Helper()
+	var j Job
+	require.Eventually(t, func() bool {
+		j, _ = m.Get(id, anonymous)
+		return j.Status == status
+	}, 5*time.Second, 10*time.Millisecond)
+	return j
+}
+
+func get(t *testing.T, r *gin.Engine, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestManager(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m, r, _ := newTestManager(t, t.TempDir(), nil)
+	m.Start(ctx)
+
+	created := upload(t, r, inputFile("a", "forbidden", "b"))
+	assert.Equal(t, "batch", created.Object)
+	assert.Equal(t, StatusValidating, created.Status)
+	assert.Equal(t, "/v1/chat/completions", created.Endpoint)
+
+	j := waitFor(t, m, created.ID, StatusCompleted)
+	assert.Equal(t, RequestCounts{Total: 3, Completed: 2, Failed: 1}, j.RequestCounts)
+	assert.NotEmpty(t, j.OutputFileID)
+	assert.NotEmpty(t, j.ErrorFileID)
+	assert.NotNil(t, j.CompletedAt)
+
+	w := get(t, r, "/v1/batches/"+j.ID+"/output")
+	require.Equal(t, http.StatusOK, w.Code)
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, 2)
+	outputs := make(map[string]outputLine)
+	for _, raw := range lines {
+		var line outputLine
+		require.NoError(t, json.Unmarshal([]byte(raw), &line))
+		outputs[line.CustomID] = line
+	}
+	require.Contains(t, outputs, "req-a")
+	assert.Equal(t, http.StatusOK, outputs["req-a"].Response.StatusCode)
+	assert.JSONEq(t, `{"model":"a","tenant":"acme"}`, string(outputs["req-a"].Response.Body), "requests carry the headers of the batch")
+
+	w = get(t, r, "/v1/batches/"+j.ID+"/errors")
+	require.Equal(t, http.StatusOK, w.Code)
+	var failed outputLine
+	require.NoError(t, json.Unmarshal(bytes.TrimSpace(w.Body.Bytes()), &failed))
+	assert.Equal(t, "req-b", failed.CustomID)
+	assert.Equal(t, http.StatusForbidden, failed.Response.StatusCode)
+	assert.Equal(t, "request_failed", failed.Error.Code)
+
+	w = get(t, r, "/v1/batches")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list JobList
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, j.ID, list.Data[0].ID)
+
+	assert.Equal(t, http.StatusNotFound, get(t, r, "/v1/batches/batch_unknown").Code)
+}
+
+func TestManager_Owner(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m, r, _ := newTestManager(t, t.TempDir(), nil)
+	m.Start(ctx)
+
+	created := upload(t, r, inputFile("a"))
+	waitFor(t, m, created.ID, StatusCompleted)
+
+	other := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer other")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	assert.Equal(t, http.StatusNotFound, other(http.MethodGet, "/v1/batches/"+created.ID).Code)
+	assert.Equal(t, http.StatusNotFound, other(http.MethodGet, "/v1/batches/"+created.ID+"/output").Code)
+	assert.Equal(t, http.StatusNotFound, other(http.MethodPost, "/v1/batches/"+created.ID+"/cancel").Code)
+
+	w := other(http.MethodGet, "/v1/batches")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list JobList
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Empty(t, list.Data, "batches of other callers are not listed")
+
+	assert.Equal(t, http.StatusOK, get(t, r, "/v1/batches/"+created.ID).Code)
+}
+
+func TestManager_InvalidInput(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m, r, _ := newTestManager(t, t.TempDir(), nil)
+	m.Start(ctx)
+
+	input := inputFile("a") + `{"custom_id":"req-a","method":"POST","url":"/v1/chat/completions","body":{}}` + "\n" +
+		`{"custom_id":"req-x","method":"GET","url":"/v1/chat/completions","body":{}}` + "\n" +
+		`{"custom_id":"req-y","method":"POST","url":"/v1/chat/completions","body":{"stream":true}}` + "\n" +
+		"not json\n"
+	created := upload(t, r, input)
+
+	j := waitFor(t, m, created.ID, StatusFailed)
+	require.NotNil(t, j.Errors)
+	var codes []string
+	for _, e := range j.Errors.Data {
+		codes = append(codes, e.Code)
+	}
+	assert.Equal(t, []string{"duplicate_custom_id", "invalid_method", "invalid_body", "invalid_json_line"}, codes)
+	assert.Equal(t, 2, *j.Errors.Data[0].Line)
+}
+
+func TestManager_CreateValidation(t *testing.T) {
+	_, r, _ := newTestManager(t, t.TempDir(), nil)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/batches", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusNotFound, post(`{"input_file_id":"file-00","endpoint":"/v1/chat/completions","completion_window":"24h"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, post(`{"input_file_id":"file-00","endpoint":"/v1/embeddings","completion_window":"24h"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, post(`{"input_file_id":"file-00","endpoint":"/v1/chat/completions","completion_window":"1h"}`).Code)
+}
+
+func TestManager_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	release := make(chan struct{})
+	m, r, _ := newTestManager(t, t.TempDir(), release)
+	m.Start(ctx)
+
+	created := upload(t, r, inputFile("a", "slow", "slow", "slow"))
+	require.Eventually(t, func() bool {
+		j, _ := m.Get(created.ID, anonymous)
+		return j.RequestCounts.Completed == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/batches/"+created.ID+"/cancel", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	j := waitFor(t, m, created.ID, StatusCancelled)
+	assert.Equal(t, 1, j.RequestCounts.Completed, "finished requests keep their results")
+	assert.Equal(t, 0, j.RequestCounts.Failed, "requests interrupted by the cancellation are not recorded")
+	assert.NotEmpty(t, j.OutputFileID)
+}
+
+func TestManager_Resume(t *testing.T) {
+	dir := t.TempDir()
+	release := make(chan struct{})
+	ctx, stop := context.WithCancel(context.Background())
+	m, r, _ := newTestManager(t, dir, release)
+	m.Start(ctx)
+
+	created := upload(t, r, inputFile("a", "slow"))
+	require.Eventually(t, func() bool {
+		j, _ := m.Get(created.ID, anonymous)
+		return j.RequestCounts.Completed == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	stop()
+
+	close(release)
+	resumed, _, store := newTestManager(t, dir, release)
+	j, ok := resumed.Get(created.ID, anonymous)
+	require.True(t, ok)
+	assert.Equal(t, StatusInProgress, j.Status)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	resumed.Start(ctx)
+	j = waitFor(t, resumed, created.ID, StatusCompleted)
+	assert.Equal(t, RequestCounts{Total: 2, Completed: 2}, j.RequestCounts)
+
+	content, err := store.Open(ctx, j.OutputFileID)
+	require.NoError(t, err)
+	defer content.Close()
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(data), "\n"), "the request finished before the restart is not run again")
+}
+
+func TestManager_ResumeWithCredentials(t *testing.T) {
+	dir := t.TempDir()
+	m, _, _ := newTestManager(t, dir, nil)
+	j := &job{record: record{Job: Job{ID: "batch_1", Object: "batch", Status: StatusInProgress}, HadCredentials: true}}
+	m.mu.Lock()
+	require.NoError(t, m.records.Write(j.ID, j.record))
+	m.mu.Unlock()
+
+	resumed, _, _ := newTestManager(t, dir, nil)
+	got, ok := resumed.Get("batch_1", "")
+	require.True(t, ok)
+	assert.Equal(t, StatusFailed, got.Status)
+	assert.Equal(t, "gateway_restarted", got.Errors.Data[0].Code)
+	_, err := os.Stat(filepath.Join(dir, "batches", "batch_1.json"))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,178 @@
+// Package broadcast fans an in-progress streaming response out to additional
+// SSE subscribers, so observers can attach to a running generation without
+// restarting it.
+package broadcast
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+// subscriberQueue is the number of live chunks buffered per subscriber on top
+// of the replayed history. Subscribers that fall further behind are dropped
+// so they never slow down the originating stream.
+const subscriberQueue = 256
+
+// ErrNotFound is returned when subscribing to a stream that is not in progress
+var ErrNotFound = errors.New("stream not found")
+
+// Hub tracks the streams currently in progress
+type Hub struct {
+	mu           sync.Mutex
+	streams      map[string]*Stream
+	replaySize   int
+	writeTimeout time.Duration
+}
+
+// NewHub creates a hub that replays up to replaySize of the most recent chunks
+// to late subscribers. writeTimeout extends the write deadline of subscriber
+// connections before every chunk, as for regular streaming responses.
+func NewHub(replaySize int, writeTimeout time.Duration) *Hub {
+	return &Hub{
+		streams:      make(map[string]*Stream),
+		replaySize:   max(replaySize, 0),
+		writeTimeout: writeTimeout,
+	}
+}
+
+// Open registers a new stream of owner, the caller of the originating request
+// (tenants.Owner), under a random ID. The caller must Close it once the
+// originating response is complete.
+func (h *Hub) Open(owner string) (*Stream, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	s := &Stream{
+		id:          "strm_" + hex.EncodeToString(b),
+		hub:         h,
+		owner:       owner,
+		subscribers: make(map[chan []byte]struct{}),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.streams[s.id] = s
+	return s, nil
+}
+
+// Subscribe attaches to the stream with the given ID. The returned channel
+// first yields the replayed history, then live chunks, and is closed when the
+// stream ends or the subscriber falls too far behind. cancel detaches the
+// subscriber and must be called once done. Streams of another owner are not
+// found.
+func (h *Hub) Subscribe(id, owner string) (<-chan []byte, func(), error) {
+	h.mu.Lock()
+	s, ok := h.streams[id]
+	h.mu.Unlock()
+	if !ok || s.owner != owner {
+		return nil, nil, ErrNotFound
+	}
+	return s.subscribe()
+}
+
+// Len returns the number of streams in progress
+func (h *Hub) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.streams)
+}
+
+// Stream is a single in-progress response being broadcast
+type Stream struct {
+	id    string
+	hub   *Hub
+	owner string
+
+	mu          sync.Mutex
+	history     [][]byte
+	subscribers map[chan []byte]struct{}
+	closed      bool
+}
+
+// ID returns the identifier subscribers use to attach to the stream
+func (s *Stream) ID() string {
+	return s.id
+}
+
+// Publish sends chunk to every subscriber and records it for late ones. It
+// never blocks: subscribers whose queue is full are disconnected.
+func (s *Stream) Publish(chunk []byte) {
+	if len(chunk) == 0 {
+		return
+	}
+	chunk = append([]byte(nil), chunk...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if s.hub.replaySize > 0 {
+		if len(s.history) == s.hub.replaySize {
+			s.history = append(s.history[:0], s.history[1:]...)
+		}
+		s.history = append(s.history, chunk)
+	}
+	for ch := range s.subscribers {
+		select {
+		case ch <- chunk:
+		default:
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribers returns the number of attached subscribers
+func (s *Stream) Subscribers() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subscribers)
+}
+
+// Close ends the stream for all subscribers and unregisters it from the hub
+func (s *Stream) Close() {
+	s.hub.mu.Lock()
+	delete(s.hub.streams, s.id)
+	s.hub.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	for ch := range s.subscribers {
+		delete(s.subscribers, ch)
+		close(ch)
+	}
+	s.history = nil
+}
+
+func (s *Stream) subscribe() (<-chan []byte, func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, nil, ErrNotFound
+	}
+
+	ch := make(chan []byte, len(s.history)+subscriberQueue)
+	for _, chunk := range s.history {
+		ch <- chunk
+	}
+	s.subscribers[ch] = struct{}{}
+
+	cancel := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.subscribers[ch]; ok {
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+	return ch, cancel, nil
+}
@@ -0,0 +1,87 @@
+package broadcast
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+)
+
+func drain(ch <-chan []byte) []string {
+	var chunks []string
+	for {
+		select {
+		case chunk, ok := <-ch:
+			if !ok {
+				return chunks
+			}
+			chunks = append(chunks, string(chunk))
+		default:
+			return chunks
+		}
+	}
+}
+
+func TestStream_ReplayAndLive(t *testing.T) {
+	hub := NewHub(2, 0)
+	stream, err := hub.Open("owner")
+	require.NoError(t, err)
+	assert.Equal(t, 1, hub.Len())
+
+	stream.Publish([]byte("a"))
+	stream.Publish([]byte("b"))
+	stream.Publish([]byte("c"))
+
+	_, _, err = hub.Subscribe(stream.ID(), "other")
+	assert.ErrorIs(t, err, ErrNotFound, "streams of other owners are not found")
+	ch, cancel, err := hub.Subscribe(stream.ID(), "owner")
+	require.NoError(t, err)
+	defer cancel()
+	assert.Equal(t, 1, stream.Subscribers())
+	assert.Equal(t, []string{"b", "c"}, drain(ch), "only the most recent chunks are replayed")
+
+	stream.Publish([]byte("d"))
+	assert.Equal(t, []string{"d"}, drain(ch))
+
+	stream.Close()
+	_, ok := <-ch
+	assert.False(t, ok, "closing the stream ends every subscription")
+	assert.Equal(t, 0, hub.Len())
+
+	_, _, err = hub.Subscribe(stream.ID(), "owner")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestStream_Cancel(t *testing.T) {
+	hub := NewHub(0, 0)
+	stream, err := hub.Open("owner")
+	require.NoError(t, err)
+	defer stream.Close()
+
+	ch, cancel, err := hub.Subscribe(stream.ID(), "owner")
+	require.NoError(t, err)
+	cancel()
+	cancel()
+	assert.Equal(t, 0, stream.Subscribers())
+	_, ok := <-ch
+	assert.False(t, ok)
+
+	stream.Publish([]byte("a"))
+}
+
+func TestStream_DropsSlowSubscribers(t *testing.T) {
+	hub := NewHub(0, 0)
+	stream, err := hub.Open("owner")
+	require.NoError(t, err)
+	defer stream.Close()
+
+	ch, cancel, err := hub.Subscribe(stream.ID(), "owner")
+	require.NoError(t, err)
+	defer cancel()
+
+	for range subscriberQueue + 1 {
+		stream.Publish([]byte("x"))
+	}
+	assert.Equal(t, 0, stream.Subscribers())
+	assert.Len(t, drain(ch), subscriberQueue)
+}
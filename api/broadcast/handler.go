@@ -0,0 +1,57 @@
+package broadcast
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+)
+
+// SubscribeHandler implements GET /v1/streams/:id/subscribe. It relays the
+// stream's replayed history followed by its live chunks as server-sent events
+// until the originating response completes or the subscriber disconnects.
+// Only the caller of the originating request may subscribe.
+func (h *Hub) SubscribeHandler(c *gin.Context) {
+	ch, cancel, err := h.Subscribe(c.Param("id"), tenants.Owner(c.Request.Context(), c.Request.Header))
+	if err != nil {
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, err.Error())
+		return
+	}
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case chunk, ok := <-ch:
+			if !ok {
+				return false
+			}
+			h.resetWriteDeadline(c.Writer)
+			if _, err := w.Write(chunk); err != nil {
+				return false
+			}
+			if flusher, ok := w.(http.Flusher); ok {
+				flusher.Flush()
+			}
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+func (h *Hub) resetWriteDeadline(w http.ResponseWriter) {
+	var deadline time.Time
+	if h.writeTimeout > 0 {
+		deadline = time.Now().Add(h.writeTimeout)
+	}
+	_ = http.NewResponseController(w).SetWriteDeadline(deadline)
+}
@@ -0,0 +1,399 @@
+// Package budgets keeps the estimated spend of chat completions per tenant,
+// API key and calendar month (UTC), priced at the community rates of their
+// models. It answers GET /v1/usage and holds the tenants and API keys to the
+// monthly budgets of the tenants file.
+package budgets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	core "github.com/inference-gateway/inference-gateway/providers/core"
+)
+
+// monthLayout formats the months spend is accounted under
+const monthLayout = "2006-01"
+
+// saveInterval is how often the spend is saved to the ledger file while it
+// changes
+const saveInterval = 10 * time.Second
+
+// Budget states
+const (
+	StateOK      = "ok"
+	StateWarning = "warning"
+	StateBlocked = "blocked"
+)
+
+// Usage is the token usage of one chat completion
+type Usage struct {
+	Provider         string
+	Model            string
+	PromptTokens     int64
+	CompletionTokens int64
+	CachedTokens     int64
+}
+
+// Spend is the usage of a tenant, or of one of its models, in a month.
+// Requests of models without community pricing in USD are counted in
+// UnpricedRequests and cost nothing.
+type Spend struct {
+	Requests         int64   `json:"requests"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+	UnpricedRequests int64   `json:"unpriced_requests"`
+}
+
+func (s *Spend) add(u Usage, cost float64, priced bool) {
+	s.Requests++
+	s.PromptTokens += u.PromptTokens
+	s.CompletionTokens += u.CompletionTokens
+	s.CostUSD += cost
+	if !priced {
+		s.UnpricedRequests++
+	}
+}
+
+// tenantSpend is the spend of a tenant in a month, in total and per
+// provider/model
+type tenantSpend struct {
+	Total  Spend             `json:"total"`
+	Models map[string]*Spend `json:"models"`
+}
+
+// spendRate sums a spend over the last hour in one bucket per minute
+type spendRate struct {
+	minutes [60]int64
+	costs   [60]float64
+}
+
+func (r *spendRate) add(now time.Time, cost float64) {
+	minute := now.Unix() / 60
+	i := minute % 60
+	if r.minutes[i] != minute {
+		r.minutes[i], r.costs[i] = minute, 0
+	}
+	r.costs[i] += cost
+}
+
+func (r *spendRate) total(now time.Time) float64 {
+	minute := now.Unix() / 60
+	var total float64
+	for i, m := range r.minutes {
+		if minute-m < 60 {
+			total += r.costs[i]
+		}
+	}
+	return total
+}
+
+// Status is the state of the budget of a tenant or API key in the current
+// month
+type Status struct {
+	tenants.Budget
+	SpentUSD     float64 `json:"spent_usd"`
+	RemainingUSD float64 `json:"remaining_usd"`
+	State        string  `json:"status"`
+}
+
+// Report is the body of GET /v1/usage
+type Report struct {
+	Object string `json:"object"`
+	Tenant string `json:"tenant,omitempty"`
+	Month  string `json:"month"`
+	Spend
+	Models       map[string]Spend `json:"models"`
+	Budget       *Status          `json:"budget,omitempty"`
+	APIKeyBudget *Status          `json:"api_key_budget,omitempty"`
+}
+
+// ledgerFile is the content of the ledger file, the spend per month
+type ledgerFile struct {
+	Tenants map[string]map[string]*tenantSpend `json:"tenants"`
+	APIKeys map[string]map[string]*Spend       `json:"api_keys"`
+}
+
+// Ledger accounts the spend of the requests of every tenant, requests
+// without a tenant under "", and of every API key. With a path, it is saved
+// to that JSON file while Start runs and loaded back by NewLedger.
+type Ledger struct {
+	path   string
+	store  *tenants.Store
+	logger logger.Logger
+	now    func() time.Time
+
+	mu     sync.Mutex
+	months map[string]map[string]*tenantSpend
+	// keys is the spend of the API keys per month, keyed by
+	// tenants.APIKeyID
+	keys  map[string]map[string]*Spend
+	dirty bool
+	// rates are the spend rates of the providers, across tenants
+	rates map[string]*spendRate
+}
+
+// NewLedger creates a ledger holding the tenants of store to their budgets,
+// loading the spend saved at path when it exists
+func NewLedger(path string, store *tenants.Store, logger logger.Logger) (*Ledger, error) {
+	if store == nil {
+		return nil, errors.New("tenant store is required")
+	}
+	l := &Ledger{
+		path:   path,
+		store:  store,
+		logger: logger,
+		now:    time.Now,
+		months: make(map[string]map[string]*tenantSpend),
+		keys:   make(map[string]map[string]*Spend),
+		rates:  make(map[string]*spendRate),
+	}
+	if path == "" {
+		return l, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read usage file: %w", err)
+	}
+	var file ledgerFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse usage file: %w", err)
+	}
+	if file.Tenants != nil {
+		l.months = file.Tenants
+	}
+	if file.APIKeys != nil {
+		l.keys = file.APIKeys
+	}
+	return l, nil
+}
+
+// month returns the current month. The caller must hold l.mu.
+func (l *Ledger) month() string {
+	return l.now().UTC().Format(monthLayout)
+}
+
+// Record adds u to the spend of tenant and of the API key identified by key,
+// when not empty, this month and returns its cost, false when its model is
+// not priced
+func (l *Ledger) Record(tenant, key string, u Usage) (float64, bool) {
+	cost, priced := core.RequestCost(u.Provider, u.Model, u.PromptTokens, u.CompletionTokens, u.CachedTokens)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	month := l.month()
+	if l.months[month] == nil {
+		l.months[month] = make(map[string]*tenantSpend)
+	}
+	spend := l.months[month][tenant]
+	if spend == nil {
+		spend = &tenantSpend{Models: make(map[string]*Spend)}
+		l.months[month][tenant] = spend
+	}
+	model := u.Provider + "/" + u.Model
+	if spend.Models[model] == nil {
+		spend.Models[model] = &Spend{}
+	}
+	spend.Total.add(u, cost, priced)
+	spend.Models[model].add(u, cost, priced)
+	if key != "" {
+		if l.keys[month] == nil {
+			l.keys[month] = make(map[string]*Spend)
+		}
+		if l.keys[month][key] == nil {
+			l.keys[month][key] = &Spend{}
+		}
+		l.keys[month][key].add(u, cost, priced)
+	}
+	l.dirty = true
+	if priced {
+		if l.rates[u.Provider] == nil {
+			l.rates[u.Provider] = &spendRate{}
+		}
+		l.rates[u.Provider].add(l.now(), cost)
+	}
+	return cost, priced
+}
+
+// SpendRate returns the spend on provider over the last hour in USD, across
+// tenants. It is not saved, so it starts over on restart.
+func (l *Ledger) SpendRate(provider string) float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rate := l.rates[provider]
+	if rate == nil {
+		return 0
+	}
+	return rate.total(l.now())
+}
+
+// Status returns the state of the budget of tenant this month, false when
+// the tenant has no budget
+func (l *Ledger) Status(tenant string) (Status, bool) {
+	budget, ok := l.store.Budget(tenant)
+	if !ok {
+		return Status{}, false
+	}
+	l.mu.Lock()
+	var spent float64
+	if spend := l.months[l.month()][tenant]; spend != nil {
+		spent = spend.Total.CostUSD
+	}
+	l.mu.Unlock()
+	return newStatus(budget, spent), true
+}
+
+// KeyStatus returns the state of the budget of the API key identified by key
+// this month, false when the key has no budget
+func (l *Ledger) KeyStatus(key string) (Status, bool) {
+	budget, ok := l.store.KeyBudget(key)
+	if !ok {
+		return Status{}, false
+	}
+	l.mu.Lock()
+	var spent float64
+	if spend := l.keys[l.month()][key]; spend != nil {
+		spent = spend.CostUSD
+	}
+	l.mu.Unlock()
+	return newStatus(budget, spent), true
+}
+
+// newStatus returns the state of budget once spent USD are spent
+func newStatus(budget tenants.Budget, spent float64) Status {
+	status := Status{Budget: budget, SpentUSD: spent, RemainingUSD: max(0, budget.MonthlyUSD-spent), State: StateOK}
+	switch percent := spent / budget.MonthlyUSD * 100; {
+	case percent >= budget.BlockPercent:
+		status.State = StateBlocked
+	case percent >= budget.WarnPercent:
+		status.State = StateWarning
+	}
+	return status
+}
+
+// Report returns the spend of tenant in month, the current one when empty,
+// with the budget of the API key identified by key
+func (l *Ledger) Report(tenant, key, month string) Report {
+	l.mu.Lock()
+	current := l.month()
+	if month == "" {
+		month = current
+	}
+	report := Report{Object: "usage", Tenant: tenant, Month: month, Models: make(map[string]Spend)}
+	if spend := l.months[month][tenant]; spend != nil {
+		report.Spend = spend.Total
+		for model, s := range spend.Models {
+			report.Models[model] = *s
+		}
+	}
+	l.mu.Unlock()
+
+	if month == current {
+		if status, ok := l.Status(tenant); ok {
+			report.Budget = &status
+		}
+		if status, ok := l.KeyStatus(key); ok {
+			report.APIKeyBudget = &status
+		}
+	}
+	return report
+}
+
+// UsageHandler answers GET /v1/usage with the spend of the caller's tenant,
+// in the month of ?month=YYYY-MM or the current one, and the budget of the
+// caller's API key
+func (l *Ledger) UsageHandler(c *gin.Context) {
+	month := c.Query("month")
+	if month != "" {
+		if _, err := time.Parse(monthLayout, month); err != nil {
+			errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Invalid month, expected YYYY-MM")
+			return
+		}
+	}
+	c.JSON(http.StatusOK, l.Report(tenants.FromContext(c.Request.Context()), tenants.APIKeyID(c.Request.Header), month))
+}
+
+// Start saves the spend to the ledger file every few seconds while it
+// changes, until ctx is done. Call Save once more on shutdown.
+func (l *Ledger) Start(ctx context.Context) {
+	if l.path == "" {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(saveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := l.Save(); err != nil {
+					l.logger.Error("failed to save usage", err, "path", l.path)
+				}
+			}
+		}
+	}()
+}
+
+// Save writes the spend to the ledger file when it changed since the last
+// save
+func (l *Ledger) Save() error {
+	l.mu.Lock()
+	if !l.dirty || l.path == "" {
+		l.mu.Unlock()
+		return nil
+	}
+	file := ledgerFile{
+		Tenants: make(map[string]map[string]*tenantSpend, len(l.months)),
+		APIKeys: make(map[string]map[string]*Spend, len(l.keys)),
+	}
+	for month, spend := range l.months {
+		file.Tenants[month] = make(map[string]*tenantSpend, len(spend))
+		for tenant, s := range spend {
+			copied := &tenantSpend{Total: s.Total, Models: make(map[string]*Spend, len(s.Models))}
+			for model, m := range s.Models {
+				spend := *m
+				copied.Models[model] = &spend
+			}
+			file.Tenants[month][tenant] = copied
+		}
+	}
+	for month, spend := range l.keys {
+		file.APIKeys[month] = make(map[string]*Spend, len(spend))
+		for key, s := range spend {
+			copied := *s
+			file.APIKeys[month][key] = &copied
+		}
+	}
+	l.dirty = false
+	l.mu.Unlock()
+
+	data, err := json.Marshal(file)
+	if err == nil {
+		tmp := l.path + ".tmp"
+		if err = os.WriteFile(tmp, data, 0o640); err == nil {
+			err = os.Rename(tmp, l.path)
+		}
+	}
+	if err != nil {
+		l.mu.Lock()
+		l.dirty = true
+		l.mu.Unlock()
+	}
+	return err
+}
@@ -0,0 +1,126 @@
+package budgets
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// keyID identifies the budgeted API key of newStore
+var keyID = tenants.APIKeyID(http.Header{"X-Api-Key": {"sk-acme"}})
+
+func newStore(t *testing.T) *tenants.Store {
+	t.Helper()
+	base := registry.NewReloadableRegistry(map[types.Provider]*registry.ProviderConfig{}, logger.NewNoopLogger())
+	store, err := tenants.NewStore(&tenants.Config{
+		Tenants: map[string]tenants.Tenant{
+			"acme": {Budget: &tenants.Budget{MonthlyUSD: 1}},
+		},
+		APIKeys: map[string]tenants.APIKey{keyID: {Budget: &tenants.Budget{MonthlyUSD: 0.5}}},
+	}, base, logger.NewNoopLogger())
+	require.NoError(t, err)
+	return store
+}
+
+func TestLedger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	ledger, err := NewLedger(path, newStore(t), logger.NewNoopLogger())
+	require.NoError(t, err)
+	now := time.Date(2026, 10, 31, 23, 0, 0, 0, time.UTC)
+	ledger.now = func() time.Time { return now }
+
+	// 100k prompt and 10k completion tokens of gpt-4o cost 0.35 USD
+	usage := Usage{Provider: "openai", Model: "gpt-4o", PromptTokens: 100000, CompletionTokens: 10000}
+	cost, ok := ledger.Record("acme", "", usage)
+	require.True(t, ok)
+	assert.InDelta(t, 0.35, cost, 1e-9)
+	_, ok = ledger.Record("acme", "", Usage{Provider: "openai", Model: "gpt-4o", PromptTokens: 100000, CompletionTokens: 10000, CachedTokens: 100000})
+	require.True(t, ok, "cached tokens are billed at the cache read rate")
+
+	status, ok := ledger.Status("acme")
+	require.True(t, ok)
+	assert.InDelta(t, 0.575, status.SpentUSD, 1e-9)
+	assert.Equal(t, StateOK, status.State)
+	_, ok = ledger.Status("")
+	assert.False(t, ok, "requests without tenant have no budget")
+
+	t.Run("spend survives a restart", func(t *testing.T) {
+		require.NoError(t, ledger.Save())
+		restarted, err := NewLedger(path, newStore(t), logger.NewNoopLogger())
+		require.NoError(t, err)
+		restarted.now = ledger.now
+		report := restarted.Report("acme", "", "")
+		assert.Equal(t, "2026-10", report.Month)
+		assert.Equal(t, int64(2), report.Requests)
+		assert.InDelta(t, 0.575, report.CostUSD, 1e-9)
+		assert.Equal(t, int64(200000), report.Models["openai/gpt-4o"].PromptTokens)
+	})
+
+	t.Run("budgets start over every month", func(t *testing.T) {
+		ledger.Record("acme", "", Usage{Provider: "openai", Model: "gpt-4o", PromptTokens: 200000, CompletionTokens: 20000})
+		status, _ := ledger.Status("acme")
+		assert.Equal(t, StateBlocked, status.State)
+
+		now = now.Add(2 * time.Hour)
+		status, _ = ledger.Status("acme")
+		assert.Equal(t, StateOK, status.State)
+		assert.Zero(t, status.SpentUSD)
+		previous := ledger.Report("acme", "", "2026-10")
+		assert.Equal(t, int64(3), previous.Requests)
+		assert.Nil(t, previous.Budget, "budgets are only reported for the current month")
+	})
+}
+
+func TestLedger_KeyBudget(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	ledger, err := NewLedger(path, newStore(t), logger.NewNoopLogger())
+	require.NoError(t, err)
+
+	_, ok := ledger.KeyStatus(keyID)
+	require.True(t, ok)
+	usage := Usage{Provider: "openai", Model: "gpt-4o", PromptTokens: 100000, CompletionTokens: 10000}
+	ledger.Record("acme", keyID, usage)
+	ledger.Record("", keyID, usage)
+	status, _ := ledger.KeyStatus(keyID)
+	assert.InDelta(t, 0.7, status.SpentUSD, 1e-9, "the spend of a key adds up across tenants")
+	assert.Equal(t, StateBlocked, status.State)
+	acme, _ := ledger.Status("acme")
+	assert.InDelta(t, 0.35, acme.SpentUSD, 1e-9)
+
+	require.NoError(t, ledger.Save())
+	restarted, err := NewLedger(path, newStore(t), logger.NewNoopLogger())
+	require.NoError(t, err)
+	report := restarted.Report("acme", keyID, "")
+	require.NotNil(t, report.APIKeyBudget)
+	assert.InDelta(t, 0.7, report.APIKeyBudget.SpentUSD, 1e-9, "the spend of keys survives a restart")
+	assert.Nil(t, restarted.Report("acme", "", "").APIKeyBudget)
+}
+
+func TestLedger_SpendRate(t *testing.T) {
+	ledger, err := NewLedger("", newStore(t), logger.NewNoopLogger())
+	require.NoError(t, err)
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	ledger.now = func() time.Time { return now }
+
+	usage := Usage{Provider: "openai", Model: "gpt-4o", PromptTokens: 100000, CompletionTokens: 10000}
+	ledger.Record("acme", "", usage)
+	now = now.Add(30 * time.Minute)
+	ledger.Record("", "", usage)
+	ledger.Record("", "", Usage{Provider: "ollama", Model: "llama3", PromptTokens: 1000})
+	assert.InDelta(t, 0.7, ledger.SpendRate("openai"), 1e-9, "across tenants")
+	assert.Zero(t, ledger.SpendRate("ollama"), "unpriced models spend nothing")
+
+	now = now.Add(45 * time.Minute)
+	assert.InDelta(t, 0.35, ledger.SpendRate("openai"), 1e-9, "spend older than an hour is left out")
+	now = now.Add(time.Hour)
+	assert.Zero(t, ledger.SpendRate("openai"))
+}
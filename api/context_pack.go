@@ -0,0 +1,332 @@
+package api
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	tokenizer "github.com/inference-gateway/inference-gateway/internal/tokenizer"
+	core "github.com/inference-gateway/inference-gateway/providers/core"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// Context packing strategies for documents that do not fit the remaining budget
+const (
+	PackStrategyTruncate  = "truncate"
+	PackStrategyDrop      = "drop"
+	PackStrategySummarize = "summarize"
+)
+
+// Document packing outcomes reported per document
+const (
+	PackStatusIncluded   = "included"
+	PackStatusTruncated  = "truncated"
+	PackStatusSummarized = "summarized"
+	PackStatusDropped    = "dropped"
+)
+
+const (
+	// defaultPackReserveTokens is left free for the completion when the request
+	// does not set reserve_tokens
+	defaultPackReserveTokens = 1024
+	// minPackFragmentTokens is the smallest remaining budget worth filling with a
+	// truncated or summarized document; below it the document is dropped
+	minPackFragmentTokens = 32
+	// packMessageOverheadTokens approximates the per-message framing tokens chat
+	// templates add around the content
+	packMessageOverheadTokens = 4
+	// charsPerToken is the first guess of the characters kept when
+	// truncating to a token budget
+	charsPerToken = 4
+)
+
+// ContextPackDocument is a document offered for packing. Higher priority
+// documents are packed first; equal priorities keep their request order.
+type ContextPackDocument struct {
+	ID       string `json:"id"`
+	Title    string `json:"title,omitempty"`
+	Content  string `json:"content"`
+	Priority int    `json:"priority,omitempty"`
+}
+
+// ContextPackRequest is the request body of POST /v1/context/pack
+type ContextPackRequest struct {
+	Model            string                `json:"model"`
+	Documents        []ContextPackDocument `json:"documents"`
+	System           string                `json:"system,omitempty"`
+	Query            string                `json:"query,omitempty"`
+	Strategy         string                `json:"strategy,omitempty"`
+	ReserveTokens    *int                  `json:"reserve_tokens,omitempty"`
+	MaxContextTokens *int                  `json:"max_context_tokens,omitempty"`
+}
+
+// ContextPackDocumentResult reports how a single document was packed
+type ContextPackDocumentResult struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Tokens int    `json:"tokens"`
+}
+
+// ContextPackResponse is the response body of POST /v1/context/pack. Token
+// counts are estimates for models without an exact tokenizer, so
+// reserve_tokens doubles as a safety margin.
+type ContextPackResponse struct {
+	Model         string                      `json:"model"`
+	ContextWindow int                         `json:"context_window"`
+	BudgetTokens  int                         `json:"budget_tokens"`
+	UsedTokens    int                         `json:"used_tokens"`
+	Messages      []types.Message             `json:"messages"`
+	Documents     []ContextPackDocumentResult `json:"documents"`
+}
+
+// ContextPackHandler implements POST /v1/context/pack. It packs documents into a
+// messages array that fits the target model's context window: the system prompt
+// and packed documents go into a system message and the query into a trailing
+// user message. Documents that do not fit are truncated, dropped or summarized
+// by the target model according to the requested strategy.
+func (router *RouterImpl) ContextPackHandler(c *gin.Context) {
+	var req ContextPackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		router.log(c).Error("failed to decode request", err)
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to decode request")
+		return
+	}
+	if err := validateContextPackRequest(&req); err != nil {
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, err.Error())
+		return
+	}
+
+	chatReq := types.CreateChatCompletionRequest{Model: req.Model}
+	provider, providerID, ok := router.resolveChatProvider(c, &chatReq)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), router.cfg().Server.ReadTimeout)
+	defer cancel()
+
+	contextWindow := 0
+	if req.MaxContextTokens != nil {
+		contextWindow = *req.MaxContextTokens
+	} else {
+		contextWindow = router.lookupContextWindow(ctx, provider, providerID, chatReq.Model)
+	}
+	if contextWindow <= 0 {
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.ContextWindowExceeded, "Context window is unknown for this model. Please set max_context_tokens.")
+		return
+	}
+
+	counter := router.tokenizers.ForModel(req.Model)
+	fixed := counter.Count(req.System) + counter.Count(req.Query) + 2*packMessageOverheadTokens
+	budget := contextWindow - *req.ReserveTokens - fixed
+	if budget <= 0 {
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.ContextWindowExceeded, "Context window is too small for the system prompt, query and reserved tokens")
+		return
+	}
+
+	docs := slices.Clone(req.Documents)
+	slices.SortStableFunc(docs, func(a, b ContextPackDocument) int {
+		return cmp.Compare(b.Priority, a.Priority)
+	})
+
+	remaining := budget
+	var packed []string
+	results := make([]ContextPackDocumentResult, 0, len(docs))
+	for _, doc := range docs {
+		block := formatPackedDocument(doc, doc.Content)
+		tokens := counter.Count(block)
+		if tokens <= remaining {
+			packed = append(packed, block)
+			remaining -= tokens
+			results = append(results, ContextPackDocumentResult{ID: doc.ID, Status: PackStatusIncluded, Tokens: tokens})
+			continue
+		}
+
+		available := remaining - counter.Count(formatPackedDocument(doc, ""))
+		if req.Strategy == PackStrategyDrop || available < minPackFragmentTokens {
+			results = append(results, ContextPackDocumentResult{ID: doc.ID, Status: PackStatusDropped})
+			continue
+		}
+
+		status := PackStatusTruncated
+		content := truncateToTokens(counter, doc.Content, available)
+		if req.Strategy == PackStrategySummarize {
+			summary, err := router.summarizeDocument(ctx, provider, chatReq.Model, doc, available)
+			if err != nil {
+				router.log(c).Error("failed to summarize document, falling back to truncation", err, "provider", providerID, "document", doc.ID)
+			} else {
+				status = PackStatusSummarized
+				content = truncateToTokens(counter, summary, available)
+			}
+		}
+
+		block = formatPackedDocument(doc, content)
+		tokens = counter.Count(block)
+		packed = append(packed, block)
+		remaining -= tokens
+		results = append(results, ContextPackDocumentResult{ID: doc.ID, Status: status, Tokens: tokens})
+	}
+
+	var messages []types.Message
+	systemContent := strings.TrimSpace(strings.Join(append([]string{req.System}, packed...), "\n\n"))
+	if systemContent != "" {
+		msg, err := newTextMessage(types.System, systemContent)
+		if err != nil {
+			router.log(c).Error("failed to build system message", err)
+			errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to build messages")
+			return
+		}
+		messages = append(messages, msg)
+	}
+	if req.Query != "" {
+		msg, err := newTextMessage(types.User, req.Query)
+		if err != nil {
+			router.log(c).Error("failed to build user message", err)
+			errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to build messages")
+			return
+		}
+		messages = append(messages, msg)
+	}
+
+	c.JSON(http.StatusOK, ContextPackResponse{
+		Model:         req.Model,
+		ContextWindow: contextWindow,
+		BudgetTokens:  budget,
+		UsedTokens:    contextWindow - *req.ReserveTokens - remaining,
+		Messages:      messages,
+		Documents:     results,
+	})
+}
+
+// validateContextPackRequest checks the request and applies defaults for the
+// strategy and reserved completion tokens.
+func validateContextPackRequest(req *ContextPackRequest) error {
+	if req.Model == "" {
+		return fmt.Errorf("model is required")
+	}
+	if len(req.Documents) == 0 {
+		return fmt.Errorf("at least one document is required")
+	}
+	for i, doc := range req.Documents {
+		if doc.ID == "" {
+			return fmt.Errorf("documents[%d].id is required", i)
+		}
+	}
+	switch req.Strategy {
+	case "":
+		req.Strategy = PackStrategyTruncate
+	case PackStrategyTruncate, PackStrategyDrop, PackStrategySummarize:
+	default:
+		return fmt.Errorf("unsupported strategy %q, expected one of %s, %s, %s", req.Strategy, PackStrategyTruncate, PackStrategyDrop, PackStrategySummarize)
+	}
+	if req.ReserveTokens == nil {
+		reserve := defaultPackReserveTokens
+		req.ReserveTokens = &reserve
+	} else if *req.ReserveTokens < 0 {
+		return fmt.Errorf("reserve_tokens must not be negative")
+	}
+	if req.MaxContextTokens != nil && *req.MaxContextTokens <= 0 {
+		return fmt.Errorf("max_context_tokens must be positive")
+	}
+	return nil
+}
+
+// lookupContextWindow finds the context window of model through the provider's
+// model listing, including the runtime lookup for local providers. It returns 0
+// when the window is unknown.
+func (router *RouterImpl) lookupContextWindow(ctx context.Context, provider core.IProvider, providerID types.Provider, model string) int {
+	response, err := router.listProviderModels(ctx, providerID, provider)
+	if err != nil {
+		router.logger.Error("failed to list models", err, "provider", providerID)
+		return 0
+	}
+
+	id := string(providerID) + "/" + model
+	for _, m := range response.Data {
+		if m.ID != id && m.ID != model {
+			continue
+		}
+		models := []types.Model{m}
+		router.resolveContextWindows(ctx, models)
+		if models[0].ContextWindow != nil {
+			return models[0].ContextWindow.Tokens
+		}
+		return 0
+	}
+	return 0
+}
+
+// summarizeDocument asks the target model for a summary of doc that fits in
+// maxTokens.
+func (router *RouterImpl) summarizeDocument(ctx context.Context, provider core.IProvider, model string, doc ContextPackDocument, maxTokens int) (string, error) {
+	system, err := newTextMessage(types.System, fmt.Sprintf("Summarize the following document in at most %d words. Keep facts, names and figures; do not add information.", maxTokens*3/4))
+	if err != nil {
+		return "", err
+	}
+	user, err := newTextMessage(types.User, doc.Content)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := provider.ChatCompletions(ctx, types.CreateChatCompletionRequest{
+		Model:     model,
+		Messages:  []types.Message{system, user},
+		MaxTokens: &maxTokens,
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(response.Choices) == 0 {
+		return "", fmt.Errorf("summary response has no choices")
+	}
+	summary := strings.TrimSpace(response.Choices[0].Message.TextContent())
+	if summary == "" {
+		return "", fmt.Errorf("summary response is empty")
+	}
+	return summary, nil
+}
+
+func newTextMessage(role types.MessageRole, text string) (types.Message, error) {
+	msg := types.Message{Role: role}
+	err := msg.Content.FromMessageContent0(text)
+	return msg, err
+}
+
+// formatPackedDocument renders a document as a delimited block so the model can
+// attribute content to its source.
+func formatPackedDocument(doc ContextPackDocument, content string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<document id=%q", doc.ID)
+	if doc.Title != "" {
+		fmt.Fprintf(&b, " title=%q", doc.Title)
+	}
+	b.WriteString(">\n")
+	b.WriteString(content)
+	b.WriteString("\n</document>")
+	return b.String()
+}
+
+// truncateToTokens cuts s to at most tokens tokens of t on a rune boundary,
+// marking the cut with an ellipsis. The cut starts from the character
+// estimate and shrinks until t agrees.
+func truncateToTokens(t tokenizer.Tokenizer, s string, tokens int) string {
+	if t.Count(s) <= tokens {
+		return s
+	}
+	runes := []rune(s)
+	for limit := min(tokens*charsPerToken-1, len(runes)); limit > 0; {
+		cut := strings.TrimRightFunc(string(runes[:limit]), func(r rune) bool { return r == ' ' || r == '\n' }) + "…"
+		n := t.Count(cut)
+		if n <= tokens {
+			return cut
+		}
+		limit = min(limit-1, limit*tokens/n)
+	}
+	return ""
+}
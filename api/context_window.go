@@ -1,14 +1,11 @@
 package api
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"math"
 	"net/http"
-	"net/url"
 	"strconv"
 	"strings"
 	"sync"
@@ -136,41 +133,15 @@ func (router *RouterImpl) fetchOllamaContextWindow(ctx context.Context, provider
 	return 0, fmt.Errorf("ollama show returned no context length for %s", name)
 }
 
-// runtimeAPICall performs a request against the provider's server root and
-// decodes the JSON response into out. Runtime admin APIs like llama.cpp /props
-// and Ollama /api/show live at the server root, outside the OpenAI-compatible
-// path prefix (e.g. /v1) of the provider base URL.
+// runtimeAPICall performs a request against the provider's server root, see
+// runtimeRequest, and decodes the JSON response into out
 func (router *RouterImpl) runtimeAPICall(ctx context.Context, providerID types.Provider, method, path string, body []byte, out any) error {
 	provider, err := router.registry.BuildProvider(providerID, router.client)
 	if err != nil {
 		return err
 	}
 
-	base, err := url.Parse(provider.GetURL())
-	if err != nil {
-		return err
-	}
-	if base.Scheme == "" || base.Host == "" {
-		return fmt.Errorf("provider url %q has no scheme or host", provider.GetURL())
-	}
-
-	var reader io.Reader
-	if body != nil {
-		reader = bytes.NewReader(body)
-	}
-	req, err := http.NewRequestWithContext(ctx, method, base.Scheme+"://"+base.Host+path, reader)
-	if err != nil {
-		return err
-	}
-	req.Header.Set("Accept", "application/json")
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
-	if err := applyProviderAuth(req, provider); err != nil {
-		return err
-	}
-
-	resp, err := router.client.Do(req)
+	resp, err := router.runtimeRequest(ctx, provider, method, path, body)
 	if err != nil {
 		return err
 	}
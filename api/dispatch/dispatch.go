@@ -0,0 +1,15 @@
+// Package dispatch declares how the gateway's background work, such as
+// queued requests, batches, shadow traffic, evaluations, threads and agent
+// jobs, runs chat completion requests through the gateway itself.
+package dispatch
+
+import (
+	"context"
+	"net/http"
+)
+
+// Dispatcher runs a chat completion request through the gateway, see
+// batch.Runner.Dispatch. It returns the status and body of the response.
+type Dispatcher interface {
+	Dispatch(ctx context.Context, header http.Header, query string, body []byte) (int, []byte)
+}
@@ -0,0 +1,368 @@
+// Package errcodes is the catalog of stable error codes the gateway returns in
+// its error bodies. A published code never changes meaning; new failure modes
+// get new codes. Codes are grouped by range:
+//
+//	IG-1xxx provider configuration
+//	IG-2xxx authentication and access
+//	IG-3xxx invalid requests
+//	IG-4xxx upstream providers
+//	IG-5xxx gateway internals
+//	IG-6xxx MCP tools
+package errcodes
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+// ContextKey is the gin context key holding the code of a failed request, so
+// the logger middleware can log it with the request
+const ContextKey = "error_code"
+
+// DocsPath is the route serving the guidance of a code
+const DocsPath = "/v1/errors/"
+
+// Code describes one entry of the catalog
+type Code struct {
+	// ID is the stable identifier, e.g. IG-1001
+	ID string `json:"code"`
+	// Name is the stable machine-readable name, e.g. provider_token_missing
+	Name string `json:"name"`
+	// Status is the HTTP status the code is usually returned with
+	Status int `json:"status"`
+	// Description explains what went wrong
+	Description string `json:"description"`
+	// Remediation tells the caller or operator how to fix it
+	Remediation string `json:"remediation"`
+	// Retryable reports whether the same request may succeed when retried
+	// later
+	Retryable bool `json:"retryable"`
+}
+
+// Response is the JSON body of a gateway error
+type Response struct {
+	Error     string `json:"error"`
+	Code      string `json:"code,omitempty"`
+	CodeName  string `json:"code_name,omitempty"`
+	DocsURL   string `json:"docs_url,omitempty"`
+	Retryable bool   `json:"retryable"`
+	// ProviderStatus is the HTTP status answered by the provider, for
+	// upstream errors
+	ProviderStatus int `json:"provider_status,omitempty"`
+	// ProviderError is the provider's error body, verbatim when it is JSON
+	ProviderError json.RawMessage `json:"provider_error,omitempty"`
+}
+
+var catalog []Code
+
+func register(c Code) Code {
+	catalog = append(catalog, c)
+	return c
+}
+
+// Provider configuration
+var (
+	ProviderTokenMissing = register(Code{
+		ID: "IG-1001", Name: "provider_token_missing", Status: http.StatusBadRequest,
+		Description: "The provider requires an API key but none is configured on the gateway.",
+		Remediation: "Set the provider's API key environment variable (e.g. OPENAI_API_KEY) and restart the gateway.",
+	})
+	ProviderNotFound = register(Code{
+		ID: "IG-1002", Name: "provider_not_found", Status: http.StatusBadRequest,
+		Description: "The requested provider is unknown or not supported.",
+		Remediation: "Check the provider ID against the list of supported providers, or use the provider/model format with a supported provider.",
+	})
+	ProviderUnresolved = register(Code{
+		ID: "IG-1003", Name: "provider_unresolved", Status: http.StatusBadRequest,
+		Description: "The gateway could not determine which provider serves the requested model.",
+		Remediation: "Prefix the model with its provider (e.g. openai/gpt-4o) or pass the ?provider= query parameter.",
+	})
+	ProviderAuthUnsupported = register(Code{
+		ID: "IG-1004", Name: "provider_auth_unsupported", Status: http.StatusUnprocessableEntity,
+		Description: "The provider is configured with an authentication type the gateway cannot apply.",
+		Remediation: "Fix the provider's auth type in the gateway configuration.",
+	})
+	ProviderFeatureUnsupported = register(Code{
+		ID: "IG-1005", Name: "provider_feature_unsupported", Status: http.StatusBadRequest,
+		Description: "The provider does not support the requested API.",
+		Remediation: "Use a provider that supports this API, or call the OpenAI-compatible /v1/chat/completions endpoint instead.",
+	})
+	ProviderDisabled = register(Code{
+		ID: "IG-1006", Name: "provider_disabled", Status: http.StatusServiceUnavailable,
+		Description: "The provider was disabled at runtime by an operator through the admin API.",
+		Remediation: "Use another provider, or ask the operator to enable it again with POST /admin/providers/{id}/enable.",
+	})
+)
+
+// Authentication and access
+var (
+	Unauthorized = register(Code{
+		ID: "IG-2001", Name: "unauthorized", Status: http.StatusUnauthorized,
+		Description: "The request carries no valid credentials.",
+		Remediation: "Send a valid, unexpired bearer token in the Authorization header.",
+	})
+	FeatureDisabled = register(Code{
+		ID: "IG-2002", Name: "feature_disabled", Status: http.StatusForbidden,
+		Description: "The requested endpoint is disabled on this gateway.",
+		Remediation: "Ask the operator to enable the feature (e.g. MCP_EXPOSE or TELEMETRY_METRICS_PUSH_ENABLE).",
+	})
+	ModelNotAllowed = register(Code{
+		ID: "IG-2003", Name: "model_not_allowed", Status: http.StatusForbidden,
+		Description: "The model is not in the gateway's ALLOWED_MODELS list, or not allowed by the MODEL_POLICY_PATH entry of the caller's role or of the provider.",
+		Remediation: "Use one of the models returned by GET /v1/models, or ask the operator to allow the model.",
+	})
+	ModelDisallowed = register(Code{
+		ID: "IG-2004", Name: "model_disallowed", Status: http.StatusForbidden,
+		Description: "The model is in the gateway's DISALLOWED_MODELS list, or denied by the MODEL_POLICY_PATH entry of the caller's role or of the provider.",
+		Remediation: "Use a different model; GET /v1/models lists the available ones.",
+	})
+	TenantUnknown = register(Code{
+		ID: "IG-2005", Name: "tenant_unknown", Status: http.StatusForbidden,
+		Description: "The request names a tenant that is not configured or none at all, or its token carries no tenant claim.",
+		Remediation: "Send the tenant ID assigned by the operator; the operator can check TENANTS_CONFIG_PATH.",
+	})
+	TenantRateLimited = register(Code{
+		ID: "IG-2006", Name: "tenant_rate_limited", Status: http.StatusTooManyRequests,
+		Description: "The tenant exceeded its requests_per_minute limit.",
+		Remediation: "Retry after the number of seconds in the Retry-After header, or ask the operator to raise the tenant's limit.",
+		Retryable:   true,
+	})
+	TenantModelNotAllowed = register(Code{
+		ID: "IG-2007", Name: "tenant_model_not_allowed", Status: http.StatusForbidden,
+		Description: "The model is not in the allowed_models of the request's tenant.",
+		Remediation: "Use one of the models returned by GET /v1/models for the tenant, or ask the operator to allow the model.",
+	})
+	ProxyPathNotAllowed = register(Code{
+		ID: "IG-2008", Name: "proxy_path_not_allowed", Status: http.StatusForbidden,
+		Description: "PROXY_STRICT is enabled and the proxied provider path matches none of the PROXY_ALLOWED_PATHS patterns.",
+		Remediation: "Use a gateway endpoint such as POST /v1/chat/completions, or ask the operator to allow the path.",
+	})
+	TenantBudgetExceeded = register(Code{
+		ID: "IG-2009", Name: "tenant_budget_exceeded", Status: http.StatusTooManyRequests,
+		Description: "The estimated spend of the tenant this month reached the block_percent of its monthly budget.",
+		Remediation: "Wait for the next calendar month (UTC) or ask the operator to raise the tenant's budget; GET /v1/usage reports the spend.",
+	})
+	APIKeyBudgetExceeded = register(Code{
+		ID: "IG-2010", Name: "api_key_budget_exceeded", Status: http.StatusTooManyRequests,
+		Description: "The estimated spend of the API key this month reached the block_percent of its monthly budget.",
+		Remediation: "Wait for the next calendar month (UTC) or ask the operator to raise the key's budget; GET /v1/usage reports the spend.",
+	})
+)
+
+// Invalid requests
+var (
+	InvalidRequest = register(Code{
+		ID: "IG-3001", Name: "invalid_request", Status: http.StatusBadRequest,
+		Description: "The request body could not be read or decoded.",
+		Remediation: "Send a well-formed JSON body matching the endpoint's schema.",
+	})
+	RequestTooLarge = register(Code{
+		ID: "IG-3002", Name: "request_too_large", Status: http.StatusRequestEntityTooLarge,
+		Description: "The request body exceeds the gateway's size limit.",
+		Remediation: "Send a smaller body or ask the operator to raise SERVER_MAX_REQUEST_BYTES.",
+	})
+	RequestLimitExceeded = register(Code{
+		ID: "IG-3003", Name: "request_limit_exceeded", Status: http.StatusBadRequest,
+		Description: "The request exceeds a configured limit such as the number of messages, prompt length or max_tokens.",
+		Remediation: "Reduce the value named in the limit field of the error below the max it reports.",
+	})
+	UnsupportedMediaType = register(Code{
+		ID: "IG-3004", Name: "unsupported_media_type", Status: http.StatusUnsupportedMediaType,
+		Description: "The request Content-Type is not accepted by this endpoint.",
+		Remediation: "Send the body with one of the content types documented for the endpoint.",
+	})
+	InvalidSafetySettings = register(Code{
+		ID: "IG-3005", Name: "invalid_safety_settings", Status: http.StatusBadRequest,
+		Description: "The safety_settings of the request are invalid.",
+		Remediation: "Use the levels off, low, medium or high and the categories harassment, hate, sexual or dangerous.",
+	})
+	SafetyBlocked = register(Code{
+		ID: "IG-3006", Name: "safety_blocked", Status: http.StatusBadRequest,
+		Description: "The request input was rejected by its safety_settings.",
+		Remediation: "Rephrase the input or lower the level of the categories listed in the error.",
+	})
+	ContextWindowExceeded = register(Code{
+		ID: "IG-3007", Name: "context_window_exceeded", Status: http.StatusBadRequest,
+		Description: "The model's context window is unknown or too small for the request.",
+		Remediation: "Set max_context_tokens explicitly or reduce the system prompt, query and reserved tokens.",
+	})
+	HookRejected = register(Code{
+		ID: "IG-3008", Name: "hook_rejected", Status: http.StatusBadRequest,
+		Description: "A configured request hook rejected the request.",
+		Remediation: "Fix the request according to the message; the operator can inspect HOOKS_CONFIG_PATH for the rule.",
+	})
+	RouteNotFound = register(Code{
+		ID: "IG-3009", Name: "route_not_found", Status: http.StatusNotFound,
+		Description: "No endpoint exists at the requested path.",
+		Remediation: "Check the method and path against the gateway's API reference.",
+	})
+	ResourceNotFound = register(Code{
+		ID: "IG-3010", Name: "resource_not_found", Status: http.StatusNotFound,
+		Description: "The referenced resource does not exist or has expired.",
+		Remediation: "Check the identifier in the path; streams can only be joined while they are running.",
+	})
+)
+
+// Upstream providers
+var (
+	UpstreamUnreachable = register(Code{
+		ID: "IG-4001", Name: "upstream_unreachable", Status: http.StatusBadGateway,
+		Description: "The gateway could not connect to the provider.",
+		Remediation: "Check the provider URL and network connectivity from the gateway, then retry.",
+		Retryable:   true,
+	})
+	UpstreamTimeout = register(Code{
+		ID: "IG-4002", Name: "upstream_timeout", Status: http.StatusGatewayTimeout,
+		Description: "The provider did not answer before the gateway timed out.",
+		Remediation: "Retry, use a smaller request, or ask the operator to raise SERVER_READ_TIMEOUT.",
+		Retryable:   true,
+	})
+	UpstreamError = register(Code{
+		ID: "IG-4003", Name: "upstream_error", Status: http.StatusBadGateway,
+		Description: "The provider rejected the request or failed to process it.",
+		Remediation: "Read the provider's message in the error; the HTTP status mirrors the provider's.",
+	})
+	UpstreamRateLimited = register(Code{
+		ID: "IG-4004", Name: "upstream_rate_limited", Status: http.StatusTooManyRequests,
+		Description: "The provider rate limited the request.",
+		Remediation: "Back off and retry later, or raise the quota of the provider account.",
+		Retryable:   true,
+	})
+	UpstreamAuthFailed = register(Code{
+		ID: "IG-4005", Name: "upstream_auth_failed", Status: http.StatusUnauthorized,
+		Description: "The provider rejected the gateway's credentials.",
+		Remediation: "Check that the provider API key configured on the gateway is valid and has access to the model.",
+	})
+	ModerationFailed = register(Code{
+		ID: "IG-4006", Name: "moderation_failed", Status: http.StatusBadGateway,
+		Description: "The moderation pre-check required by safety_settings failed.",
+		Remediation: "Retry; the operator should check the OpenAI provider and SAFETY_MODERATION_MODEL.",
+	})
+	ModelListFailed = register(Code{
+		ID: "IG-4007", Name: "model_list_failed", Status: http.StatusBadGateway,
+		Description: "The provider's models could not be listed.",
+		Remediation: "Retry; the operator should check the provider's availability.",
+		Retryable:   true,
+	})
+	StructuredOutputInvalid = register(Code{
+		ID: "IG-4008", Name: "structured_output_invalid", Status: http.StatusUnprocessableEntity,
+		Description: "The model's response did not match the requested json_schema response_format, even after repair attempts.",
+		Remediation: "Simplify the schema, make the instructions more explicit, or use a model with native structured output support.",
+	})
+	UpstreamContentFiltered = register(Code{
+		ID: "IG-4009", Name: "upstream_content_filtered", Status: http.StatusBadRequest,
+		Description: "The provider refused the request or its response under its content policy.",
+		Remediation: "Rephrase the input; the provider_error field carries the provider's filter details.",
+	})
+	UpstreamInvalidRequest = register(Code{
+		ID: "IG-4010", Name: "upstream_invalid_request", Status: http.StatusBadRequest,
+		Description: "The provider rejected the request as invalid, e.g. an unsupported parameter.",
+		Remediation: "Fix the request according to the provider's message; the provider_error field carries its details.",
+	})
+	UpstreamContextLengthExceeded = register(Code{
+		ID: "IG-4011", Name: "upstream_context_length_exceeded", Status: http.StatusBadRequest,
+		Description: "The provider rejected the request because it exceeds the model's context window.",
+		Remediation: "Shorten the messages or lower max_tokens, or use a model with a larger context window.",
+	})
+	UpstreamModelNotFound = register(Code{
+		ID: "IG-4012", Name: "upstream_model_not_found", Status: http.StatusNotFound,
+		Description: "The provider does not know the requested model, or the gateway's account has no access to it.",
+		Remediation: "Use one of the models returned by GET /v1/models.",
+	})
+	UpstreamQuotaExceeded = register(Code{
+		ID: "IG-4013", Name: "upstream_quota_exceeded", Status: http.StatusTooManyRequests,
+		Description: "The provider account ran out of quota or credit.",
+		Remediation: "Ask the operator to top up or raise the quota of the provider account; retrying does not help until then.",
+	})
+	UpstreamUnavailable = register(Code{
+		ID: "IG-4014", Name: "upstream_unavailable", Status: http.StatusServiceUnavailable,
+		Description: "The provider is overloaded or failed internally.",
+		Remediation: "Retry with backoff, or use another provider.",
+		Retryable:   true,
+	})
+)
+
+// Gateway internals
+var (
+	InternalError = register(Code{
+		ID: "IG-5001", Name: "internal_error", Status: http.StatusInternalServerError,
+		Description: "The gateway failed to process the request.",
+		Remediation: "Retry; if it persists, report the request time and error code to the operator.",
+	})
+	GatewayDraining = register(Code{
+		ID: "IG-5002", Name: "gateway_draining", Status: http.StatusServiceUnavailable,
+		Description: "The gateway instance is shutting down and no longer accepts new requests.",
+		Remediation: "Retry the request; load balancers route it to another instance once /health/ready fails.",
+		Retryable:   true,
+	})
+	GatewayAtCapacity = register(Code{
+		ID: "IG-5003", Name: "gateway_at_capacity", Status: http.StatusServiceUnavailable,
+		Description: "The gateway is already running as many MCP agent loops as AGENT_MAX_CONCURRENT allows.",
+		Remediation: "Retry the request later, or raise AGENT_MAX_CONCURRENT.",
+		Retryable:   true,
+	})
+)
+
+// MCP tools
+var (
+	MCPToolsFailed = register(Code{
+		ID: "IG-6001", Name: "mcp_tools_failed", Status: http.StatusInternalServerError,
+		Description: "Orchestrating MCP tool calls for the request failed.",
+		Remediation: "Retry; the operator should check the MCP servers' status.",
+	})
+	MCPPromptFailed = register(Code{
+		ID: "IG-6002", Name: "mcp_prompt_failed", Status: http.StatusBadRequest,
+		Description: "The MCP prompt referenced by mcp_prompt could not be rendered.",
+		Remediation: "Check the prompt name and its arguments against GET /v1/mcp/prompts.",
+	})
+)
+
+// Response builds the error body for message
+func (c Code) Response(message string) Response {
+	return Response{
+		Error:     message,
+		Code:      c.ID,
+		CodeName:  c.Name,
+		DocsURL:   DocsPath + c.ID,
+		Retryable: c.Retryable,
+	}
+}
+
+// JSON writes the error body for message with status and records the code
+// for the request log
+func JSON(c *gin.Context, status int, code Code, message string) {
+	c.Set(ContextKey, code.ID)
+	c.JSON(status, code.Response(message))
+}
+
+// AbortJSON is JSON for middlewares, also stopping the handler chain
+func AbortJSON(c *gin.Context, status int, code Code, message string) {
+	c.Set(ContextKey, code.ID)
+	c.AbortWithStatusJSON(status, code.Response(message))
+}
+
+// ForUpstreamStatus returns the code of a provider error with status, when
+// nothing else is known about it
+func ForUpstreamStatus(status int) Code {
+	return classifyUpstream(status, upstreamDetails{})
+}
+
+// Lookup finds a code by ID or name, case-insensitively
+func Lookup(key string) (Code, bool) {
+	i := slices.IndexFunc(catalog, func(c Code) bool {
+		return strings.EqualFold(c.ID, key) || strings.EqualFold(c.Name, key)
+	})
+	if i < 0 {
+		return Code{}, false
+	}
+	return catalog[i], true
+}
+
+// All returns the catalog ordered by ID
+func All() []Code {
+	return slices.Clone(catalog)
+}
@@ -0,0 +1,80 @@
+package errcodes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+)
+
+// TestCatalog guards the stability rules of the catalog: IDs and names are
+// unique, well-formed and every entry carries guidance.
+func TestCatalog(t *testing.T) {
+	idPattern := regexp.MustCompile(`^IG-[1-6]\d{3}$`)
+	namePattern := regexp.MustCompile(`^[a-z0-9_]+$`)
+	ids := map[string]bool{}
+	names := map[string]bool{}
+
+	for _, code := range All() {
+		assert.Regexp(t, idPattern, code.ID)
+		assert.Regexp(t, namePattern, code.Name)
+		assert.False(t, ids[code.ID], "duplicate code %s", code.ID)
+		assert.False(t, names[code.Name], "duplicate name %s", code.Name)
+		assert.NotZero(t, code.Status, code.ID)
+		assert.NotEmpty(t, code.Description, code.ID)
+		assert.NotEmpty(t, code.Remediation, code.ID)
+		ids[code.ID] = true
+		names[code.Name] = true
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for _, key := range []string{"IG-2003", "ig-2003", "model_not_allowed"} {
+		code, ok := Lookup(key)
+		require.True(t, ok, key)
+		assert.Equal(t, ModelNotAllowed, code)
+	}
+	_, ok := Lookup("IG-9999")
+	assert.False(t, ok)
+}
+
+func TestForUpstreamStatus(t *testing.T) {
+	assert.Equal(t, UpstreamRateLimited, ForUpstreamStatus(http.StatusTooManyRequests))
+	assert.Equal(t, UpstreamAuthFailed, ForUpstreamStatus(http.StatusForbidden))
+	assert.Equal(t, UpstreamTimeout, ForUpstreamStatus(http.StatusGatewayTimeout))
+	assert.Equal(t, UpstreamUnavailable, ForUpstreamStatus(http.StatusInternalServerError))
+	assert.Equal(t, UpstreamError, ForUpstreamStatus(http.StatusConflict))
+}
+
+func TestHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/v1/errors", ListHandler)
+	r.GET("/v1/errors/:code", GetHandler)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/errors/IG-1001", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var code Code
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &code))
+	assert.Equal(t, ProviderTokenMissing, code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/errors/IG-9999", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error":"Unknown error code: IG-9999","code":"IG-3010","code_name":"resource_not_found","docs_url":"/v1/errors/IG-3010","retryable":false}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/errors", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Data []Code `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, All(), list.Data)
+}
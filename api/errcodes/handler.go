@@ -0,0 +1,23 @@
+package errcodes
+
+import (
+	"net/http"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+// ListHandler implements GET /v1/errors
+func ListHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"object": "list", "data": All()})
+}
+
+// GetHandler implements GET /v1/errors/:code, returning the description and
+// remediation guidance of a code. The code can be given by ID or name.
+func GetHandler(c *gin.Context) {
+	code, ok := Lookup(c.Param("code"))
+	if !ok {
+		JSON(c, http.StatusNotFound, ResourceNotFound, "Unknown error code: "+c.Param("code"))
+		return
+	}
+	c.JSON(http.StatusOK, code)
+}
@@ -0,0 +1,219 @@
+package errcodes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+
+	backoff "github.com/inference-gateway/inference-gateway/providers/backoff"
+	core "github.com/inference-gateway/inference-gateway/providers/core"
+)
+
+// statusOverloaded is the non-standard status Anthropic answers when its API
+// is overloaded
+const statusOverloaded = 529
+
+// maxProviderMessage bounds the message taken from a provider body that is
+// not JSON, e.g. the HTML error page of a proxy in front of it
+const maxProviderMessage = 512
+
+// Upstream maps a provider error response into the catalog, returning the
+// status the gateway answers with and the error body. The provider's message,
+// status and body are kept so clients can tell its failures apart.
+func Upstream(status int, body []byte) (int, Response) {
+	details := parseUpstream(body)
+	code := classifyUpstream(status, details)
+
+	message := details.message
+	if message == "" {
+		message = strings.TrimSpace(string(body))
+		if len(message) > maxProviderMessage {
+			message = message[:maxProviderMessage] + "..."
+		}
+	}
+	if message == "" {
+		message = "Provider answered " + http.StatusText(status)
+	}
+
+	resp := code.Response(message)
+	resp.ProviderStatus = status
+	if trimmed := strings.TrimSpace(string(body)); json.Valid([]byte(trimmed)) {
+		resp.ProviderError = json.RawMessage(trimmed)
+	} else if trimmed != "" {
+		resp.ProviderError, _ = json.Marshal(trimmed)
+	}
+
+	// Statuses only some providers use are answered with the code's
+	if status < http.StatusBadRequest || status > 599 || status == statusOverloaded {
+		status = code.Status
+	}
+	return status, resp
+}
+
+// ForProviderError returns the status and error body of an error returned by
+// a provider call. Provider responses are mapped with Upstream; an
+// unreachable provider is reported as such, and any other error as an
+// invalid request carrying its message.
+func ForProviderError(err error) (int, Response) {
+	var httpErr *core.HTTPError
+	if errors.As(err, &httpErr) {
+		return Upstream(httpErr.StatusCode, []byte(httpErr.Message))
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout, UpstreamTimeout.Response("Request timed out")
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return http.StatusBadGateway, UpstreamUnreachable.Response("Failed to reach provider")
+	}
+	return http.StatusBadRequest, UpstreamError.Response(err.Error())
+}
+
+// ProviderJSON writes the error body of a failed provider call, see
+// ForProviderError, with the provider's Retry-After and rate limit headers
+func ProviderJSON(c *gin.Context, err error) {
+	status, resp := ForProviderError(err)
+	SetProviderHeaders(c, err)
+	c.Set(ContextKey, resp.Code)
+	c.JSON(status, resp)
+}
+
+// SetProviderHeaders passes the Retry-After and rate limit headers of a
+// failed provider call on to the client, so it knows when to retry
+func SetProviderHeaders(c *gin.Context, err error) {
+	var httpErr *core.HTTPError
+	if !errors.As(err, &httpErr) {
+		return
+	}
+	for name, values := range backoff.Propagated(httpErr.Header, time.Now()) {
+		c.Writer.Header()[name] = values
+	}
+}
+
+// upstreamDetails is what a provider error body says about the failure
+type upstreamDetails struct {
+	message string
+	// kinds are the machine-readable type, code and status strings of the
+	// body, lowercased
+	kinds []string
+}
+
+// parseUpstream reads the error body shapes of the supported providers:
+//
+//	OpenAI and compatibles  {"error": {"message", "type", "code"}}
+//	Anthropic               {"type": "error", "error": {"type", "message"}}
+//	Google                  {"error": {"code", "message", "status"}}, or a list of them
+//	Ollama                  {"error": "message"}
+//	Cohere                  {"message": "..."}
+func parseUpstream(body []byte) upstreamDetails {
+	var details upstreamDetails
+	trimmed := strings.TrimSpace(string(body))
+	if strings.HasPrefix(trimmed, "[") {
+		var list []json.RawMessage
+		if json.Unmarshal([]byte(trimmed), &list) != nil || len(list) == 0 {
+			return details
+		}
+		trimmed = string(list[0])
+	}
+
+	var envelope struct {
+		Error   json.RawMessage `json:"error"`
+		Message string          `json:"message"`
+		Type    string          `json:"type"`
+		Code    any             `json:"code"`
+	}
+	if json.Unmarshal([]byte(trimmed), &envelope) != nil {
+		return details
+	}
+	details.message = envelope.Message
+	if envelope.Type != "error" {
+		details.addKind(envelope.Type)
+	}
+	details.addKind(envelope.Code)
+
+	var message string
+	if json.Unmarshal(envelope.Error, &message) == nil {
+		details.message = message
+		return details
+	}
+	var inner struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+		Code    any    `json:"code"`
+		Status  string `json:"status"`
+	}
+	if json.Unmarshal(envelope.Error, &inner) == nil {
+		if inner.Message != "" {
+			details.message = inner.Message
+		}
+		details.addKind(inner.Type)
+		details.addKind(inner.Code)
+		details.addKind(inner.Status)
+	}
+	return details
+}
+
+func (d *upstreamDetails) addKind(v any) {
+	if s, ok := v.(string); ok && s != "" {
+		d.kinds = append(d.kinds, strings.ToLower(s))
+	}
+}
+
+func (d upstreamDetails) hasKind(substrings ...string) bool {
+	for _, kind := range d.kinds {
+		for _, s := range substrings {
+			if strings.Contains(kind, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// classifyUpstream picks the code of a provider error. What the body says
+// wins over the status, since providers disagree on the status of e.g. a
+// content filter or an exhausted quota.
+func classifyUpstream(status int, d upstreamDetails) Code {
+	message := strings.ToLower(d.message)
+	switch {
+	case d.hasKind("content_filter", "content_policy", "safety") ||
+		strings.Contains(message, "content management policy"):
+		return UpstreamContentFiltered
+	case d.hasKind("context_length") ||
+		strings.Contains(message, "context length") || strings.Contains(message, "prompt is too long"):
+		return UpstreamContextLengthExceeded
+	case d.hasKind("insufficient_quota", "billing") || status == http.StatusPaymentRequired:
+		return UpstreamQuotaExceeded
+	case d.hasKind("rate_limit", "resource_exhausted"):
+		return UpstreamRateLimited
+	case d.hasKind("overloaded", "unavailable"):
+		return UpstreamUnavailable
+	case d.hasKind("authentication", "permission", "unauthenticated", "invalid_api_key"):
+		return UpstreamAuthFailed
+	case d.hasKind("model_not_found", "not_found"):
+		return UpstreamModelNotFound
+	}
+
+	switch status {
+	case http.StatusTooManyRequests:
+		return UpstreamRateLimited
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return UpstreamAuthFailed
+	case http.StatusGatewayTimeout, http.StatusRequestTimeout:
+		return UpstreamTimeout
+	case http.StatusNotFound:
+		return UpstreamModelNotFound
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return UpstreamInvalidRequest
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, statusOverloaded:
+		return UpstreamUnavailable
+	default:
+		return UpstreamError
+	}
+}
@@ -0,0 +1,140 @@
+package errcodes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+
+	core "github.com/inference-gateway/inference-gateway/providers/core"
+)
+
+func TestUpstream(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         int
+		body           string
+		expectedStatus int
+		expectedCode   Code
+		expectedError  string
+	}{
+		{
+			name:           "OpenAI rate limit",
+			status:         http.StatusTooManyRequests,
+			body:           `{"error":{"message":"Rate limit reached for gpt-4o","type":"requests","code":"rate_limit_exceeded"}}`,
+			expectedStatus: http.StatusTooManyRequests,
+			expectedCode:   UpstreamRateLimited,
+			expectedError:  "Rate limit reached for gpt-4o",
+		},
+		{
+			name:           "OpenAI exhausted quota is not a rate limit",
+			status:         http.StatusTooManyRequests,
+			body:           `{"error":{"message":"You exceeded your current quota","type":"insufficient_quota","code":"insufficient_quota"}}`,
+			expectedStatus: http.StatusTooManyRequests,
+			expectedCode:   UpstreamQuotaExceeded,
+			expectedError:  "You exceeded your current quota",
+		},
+		{
+			name:           "OpenAI context length",
+			status:         http.StatusBadRequest,
+			body:           `{"error":{"message":"This model's maximum context length is 8192 tokens","type":"invalid_request_error","code":"context_length_exceeded"}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   UpstreamContextLengthExceeded,
+			expectedError:  "This model's maximum context length is 8192 tokens",
+		},
+		{
+			name:           "Azure content filter",
+			status:         http.StatusBadRequest,
+			body:           `{"error":{"message":"The response was filtered due to the prompt triggering Azure OpenAI's content management policy.","code":"content_filter","innererror":{"code":"ResponsibleAIPolicyViolation"}}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   UpstreamContentFiltered,
+			expectedError:  "The response was filtered due to the prompt triggering Azure OpenAI's content management policy.",
+		},
+		{
+			name:           "Anthropic overloaded is answered with a standard status",
+			status:         529,
+			body:           `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedCode:   UpstreamUnavailable,
+			expectedError:  "Overloaded",
+		},
+		{
+			name:           "Anthropic authentication",
+			status:         http.StatusUnauthorized,
+			body:           `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`,
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   UpstreamAuthFailed,
+			expectedError:  "invalid x-api-key",
+		},
+		{
+			name:           "Google list of errors",
+			status:         http.StatusTooManyRequests,
+			body:           `[{"error":{"code":429,"message":"Resource has been exhausted","status":"RESOURCE_EXHAUSTED"}}]`,
+			expectedStatus: http.StatusTooManyRequests,
+			expectedCode:   UpstreamRateLimited,
+			expectedError:  "Resource has been exhausted",
+		},
+		{
+			name:           "Ollama string error",
+			status:         http.StatusNotFound,
+			body:           `{"error":"model \"llama9\" not found, try pulling it first"}`,
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   UpstreamModelNotFound,
+			expectedError:  `model "llama9" not found, try pulling it first`,
+		},
+		{
+			name:           "Cohere message",
+			status:         http.StatusBadRequest,
+			body:           `{"message":"invalid request: message must not be empty"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   UpstreamInvalidRequest,
+			expectedError:  "invalid request: message must not be empty",
+		},
+		{
+			name:           "Body that is not JSON",
+			status:         http.StatusBadGateway,
+			body:           "<html>bad gateway</html>\n",
+			expectedStatus: http.StatusBadGateway,
+			expectedCode:   UpstreamUnavailable,
+			expectedError:  "<html>bad gateway</html>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := Upstream(tt.status, []byte(tt.body))
+			assert.Equal(t, tt.expectedStatus, status)
+			assert.Equal(t, tt.expectedCode.ID, resp.Code)
+			assert.Equal(t, tt.expectedCode.Retryable, resp.Retryable)
+			assert.Equal(t, tt.expectedError, resp.Error)
+			assert.Equal(t, tt.status, resp.ProviderStatus)
+			assert.NotEmpty(t, resp.ProviderError)
+		})
+	}
+}
+
+func TestForProviderError(t *testing.T) {
+	status, resp := ForProviderError(fmt.Errorf("agent turn: %w", &core.HTTPError{StatusCode: http.StatusTooManyRequests, Message: `{"error":{"message":"slow down"}}`}))
+	assert.Equal(t, http.StatusTooManyRequests, status)
+	assert.Equal(t, UpstreamRateLimited.ID, resp.Code)
+	assert.True(t, resp.Retryable)
+	assert.JSONEq(t, `{"error":{"message":"slow down"}}`, string(resp.ProviderError))
+
+	status, resp = ForProviderError(&url.Error{Op: "Post", URL: "http://provider", Err: errors.New("connection refused")})
+	assert.Equal(t, http.StatusBadGateway, status)
+	assert.Equal(t, UpstreamUnreachable.ID, resp.Code)
+
+	status, resp = ForProviderError(context.DeadlineExceeded)
+	assert.Equal(t, http.StatusGatewayTimeout, status)
+	assert.Equal(t, UpstreamTimeout.ID, resp.Code)
+
+	status, resp = ForProviderError(errors.New("failed to marshal request"))
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, UpstreamError.ID, resp.Code)
+	assert.Equal(t, "failed to marshal request", resp.Error)
+	assert.Empty(t, resp.ProviderStatus)
+}
@@ -0,0 +1,347 @@
+// Package eval scores a sample of the chat completion responses in the
+// background, to detect quality regressions of a provider or model. Scores
+// are between 0 and 1 and exported per provider, model and evaluator: the
+// mean of the pass/fail evaluators is a rate, e.g. the refusal rate.
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	dispatch "github.com/inference-gateway/inference-gateway/api/dispatch"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	otel "github.com/inference-gateway/inference-gateway/otel"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// Evaluator names, the evaluator attribute of the score metric
+const (
+	// Latency is 1 when the response took at most the latency threshold
+	Latency = "latency"
+	// Refusal is 1 when the model refused to answer
+	Refusal = "refusal"
+	// JSONValid is 1 when a response requested as JSON is valid JSON. Only
+	// requests with a json_object or json_schema response_format are scored
+	JSONValid = "json_valid"
+	// Judge is the grade of the judge model, from 0.1 to 1
+	Judge = "judge"
+)
+
+// Options configures an Evaluator
+type Options struct {
+	Percent          int
+	Concurrency      int
+	LatencyThreshold time.Duration
+	// JudgeModel grades the responses when set
+	JudgeModel   string
+	JudgeTimeout time.Duration
+}
+
+// Sample is a successful chat completion to evaluate
+type Sample struct {
+	Provider string
+	Model    string
+	// Header of the request, the judge request is sent with it
+	Header   http.Header
+	Request  []byte
+	Response []byte
+	Stream   bool
+	// Streamed replaces Response for streams collected as they were written
+	Streamed *StreamCollector
+	Latency  time.Duration
+}
+
+// Score is the result of an evaluator
+type Score struct {
+	Evaluator string
+	Value     float64
+}
+
+// Evaluator scores sampled responses and records the scores
+type Evaluator struct {
+	dispatcher dispatch.Dispatcher
+	telemetry  otel.OpenTelemetry
+	logger     logger.Logger
+	opts       Options
+	// slots holds a token per evaluation in flight
+	slots chan struct{}
+	wg    sync.WaitGroup
+	// sample reports whether a response is evaluated, at opts.Percent
+	sample func() bool
+}
+
+type judgeKey struct{}
+
+// IsJudge reports whether ctx belongs to a judge request, which is never
+// evaluated itself
+func IsJudge(ctx context.Context) bool {
+	judge, _ := ctx.Value(judgeKey{}).(bool)
+	return judge
+}
+
+// New creates an Evaluator recording its scores with telemetry, which may be
+// nil when telemetry is disabled: the scores are then only logged. The
+// dispatcher sends the judge requests and is only needed with a judge model.
+func New(dispatcher dispatch.Dispatcher, telemetry otel.OpenTelemetry, logger logger.Logger, opts Options) (*Evaluator, error) {
+	if opts.Percent < 0 || opts.Percent > 100 {
+		return nil, fmt.Errorf("EVAL_PERCENT must be between 0 and 100, got %d", opts.Percent)
+	}
+	if opts.Concurrency < 1 {
+		return nil, fmt.Errorf("EVAL_CONCURRENCY must be at least 1, got %d", opts.Concurrency)
+	}
+	if opts.JudgeModel != "" && !strings.Contains(opts.JudgeModel, "/") {
+		return nil, fmt.Errorf("EVAL_JUDGE_MODEL must be in provider/model form, got %q", opts.JudgeModel)
+	}
+	percent := opts.Percent
+	return &Evaluator{
+		dispatcher: dispatcher,
+		telemetry:  telemetry,
+		logger:     logger,
+		opts:       opts,
+		slots:      make(chan struct{}, opts.Concurrency),
+		sample:     func() bool { return rand.IntN(100) < percent },
+	}, nil
+}
+
+// Sampled reports whether the response of a new request should be evaluated
+func (e *Evaluator) Sampled() bool {
+	return e.sample()
+}
+
+// Evaluate scores s in the background. It returns false, and drops s, when
+// EVAL_CONCURRENCY evaluations are already in flight.
+func (e *Evaluator) Evaluate(s Sample) bool {
+	select {
+	case e.slots <- struct{}{}:
+	default:
+		e.logger.Debug("evaluations saturated, response not evaluated", "provider", s.Provider, "model", s.Model)
+		return false
+	}
+
+	e.wg.Go(func() {
+		defer func() { <-e.slots }()
+		scores := e.Scores(s)
+		fields := []any{"provider", s.Provider, "model", s.Model, "latency_ms", s.Latency.Milliseconds()}
+		for _, score := range scores {
+			if e.telemetry != nil {
+				e.telemetry.RecordEvaluation(context.Background(), s.Provider, s.Model, score.Evaluator, score.Value)
+			}
+			fields = append(fields, score.Evaluator, score.Value)
+		}
+		e.logger.Debug("response evaluated", fields...)
+	})
+	return true
+}
+
+// Wait blocks until the evaluations in flight are done
+func (e *Evaluator) Wait() {
+	e.wg.Wait()
+}
+
+// Scores runs the evaluators that apply to s
+func (e *Evaluator) Scores(s Sample) []Score {
+	var scores []Score
+	if e.opts.LatencyThreshold > 0 {
+		scores = append(scores, Score{Latency, pass(s.Latency <= e.opts.LatencyThreshold)})
+	}
+
+	resp := parseResponse(s.Response, s.Stream)
+	if s.Streamed != nil {
+		resp = s.Streamed.response()
+	}
+	if resp.toolCalls {
+		// Tool calls carry no answer to grade
+		return scores
+	}
+	scores = append(scores, Score{Refusal, pass(resp.refused())})
+
+	var req struct {
+		Messages       []types.Message `json:"messages"`
+		ResponseFormat *struct {
+			Type string `json:"type"`
+		} `json:"response_format"`
+	}
+	if err := json.Unmarshal(s.Request, &req); err != nil {
+		return scores
+	}
+	if req.ResponseFormat != nil && (req.ResponseFormat.Type == "json_object" || req.ResponseFormat.Type == "json_schema") {
+		scores = append(scores, Score{JSONValid, pass(json.Valid([]byte(strings.TrimSpace(resp.content))))})
+	}
+
+	if e.opts.JudgeModel != "" && e.dispatcher != nil {
+		if grade, ok := e.judge(s, req.Messages, resp.content); ok {
+			scores = append(scores, Score{Judge, grade})
+		}
+	}
+	return scores
+}
+
+func pass(ok bool) float64 {
+	if ok {
+		return 1
+	}
+	return 0
+}
+
+// response is what the evaluators read of a chat completion response
+type response struct {
+	content      string
+	refusal      string
+	finishReason string
+	toolCalls    bool
+}
+
+// refusalPattern matches the usual openings of a refusal, in the first
+// sentence of a response
+var refusalPattern = regexp.MustCompile(`(?i)^\W*(i'?m sorry|i am sorry|sorry|i apologi[sz]e|unfortunately)?[, ]*(but )?(i|as an ai[^,.]*, i) (can(no|')t|cannot|am (not able|unable)|'m (not able|unable)|won'?t|will not|must decline|do not feel comfortable)\b`)
+
+// refused reports whether the model refused to answer: an explicit refusal,
+// a content filter stop, or an answer opening with a refusal
+func (r response) refused() bool {
+	if r.refusal != "" || r.finishReason == string(types.ContentFilter) {
+		return true
+	}
+	first := r.content
+	if i := strings.IndexAny(first, ".!\n"); i >= 0 {
+		first = first[:i]
+	}
+	return refusalPattern.MatchString(strings.ReplaceAll(first, "’", "'"))
+}
+
+// parseResponse reads a chat completion response, accumulating the deltas
+// of a stream
+func parseResponse(body []byte, stream bool) response {
+	var resp response
+	if !stream {
+		var completion struct {
+			Choices []struct {
+				FinishReason string `json:"finish_reason"`
+				Message      struct {
+					Content   *string           `json:"content"`
+					Refusal   *string           `json:"refusal"`
+					ToolCalls []json.RawMessage `json:"tool_calls"`
+				} `json:"message"`
+			} `json:"choices"`
+		}
+		if json.Unmarshal(body, &completion) != nil || len(completion.Choices) == 0 {
+			return resp
+		}
+		choice := completion.Choices[0]
+		if choice.Message.Content != nil {
+			resp.content = *choice.Message.Content
+		}
+		if choice.Message.Refusal != nil {
+			resp.refusal = *choice.Message.Refusal
+		}
+		resp.finishReason = choice.FinishReason
+		resp.toolCalls = len(choice.Message.ToolCalls) > 0
+		return resp
+	}
+
+	var collector StreamCollector
+	for line := range strings.SplitSeq(string(body), "\n") {
+		if data, found := strings.CutPrefix(strings.TrimSpace(line), "data: "); found {
+			collector.Add([]byte(data))
+		}
+	}
+	return collector.response()
+}
+
+// StreamCollector accumulates what the evaluators read of a streamed chat
+// completion one event at a time, so the stream itself is not kept. The zero
+// value is ready to use.
+type StreamCollector struct {
+	content, refusal strings.Builder
+	finishReason     string
+	toolCalls        bool
+}
+
+// Add accumulates the data of one event of the stream
+func (s *StreamCollector) Add(data []byte) {
+	var chunk types.CreateChatCompletionStreamResponse
+	if string(data) == "[DONE]" || json.Unmarshal(data, &chunk) != nil || len(chunk.Choices) == 0 {
+		return
+	}
+	choice := chunk.Choices[0]
+	s.content.WriteString(choice.Delta.Content)
+	if choice.Delta.Refusal != nil {
+		s.refusal.WriteString(*choice.Delta.Refusal)
+	}
+	if choice.Delta.ToolCalls != nil {
+		s.toolCalls = true
+	}
+	if choice.FinishReason != "" {
+		s.finishReason = string(choice.FinishReason)
+	}
+}
+
+func (s *StreamCollector) response() response {
+	return response{
+		content:      s.content.String(),
+		refusal:      s.refusal.String(),
+		finishReason: s.finishReason,
+		toolCalls:    s.toolCalls,
+	}
+}
+
+// judgePrompt asks the judge model for a grade of the last exchange
+const judgePrompt = `You grade the answers of an AI assistant. Rate how helpful, correct and relevant the assistant's answer is to the user's request, from 1 (useless or wrong) to 10 (excellent). Reply with the number only.
+
+<request>
+%s
+</request>
+
+<answer>
+%s
+</answer>`
+
+var gradePattern = regexp.MustCompile(`\b(10|[1-9])\b`)
+
+// judge asks the judge model to grade the answer to the last user message.
+// ok is false when the judge fails or replies without a grade.
+func (e *Evaluator) judge(s Sample, messages []types.Message, answer string) (float64, bool) {
+	var prompt string
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == types.User {
+			prompt = messages[i].TextContent()
+			break
+		}
+	}
+	if strings.TrimSpace(prompt) == "" || strings.TrimSpace(answer) == "" {
+		return 0, false
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"model":       e.opts.JudgeModel,
+		"temperature": 0,
+		"messages": []map[string]string{
+			{"role": "user", "content": fmt.Sprintf(judgePrompt, prompt, answer)},
+		},
+	})
+	if err != nil {
+		return 0, false
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), judgeKey{}, true), e.opts.JudgeTimeout)
+	defer cancel()
+	status, respBody := e.dispatcher.Dispatch(ctx, s.Header, "", body)
+	if status != http.StatusOK {
+		e.logger.Debug("judge request failed", "judge_model", e.opts.JudgeModel, "status", status)
+		return 0, false
+	}
+	grade := gradePattern.FindString(parseResponse(respBody, false).content)
+	if grade == "" {
+		e.logger.Debug("judge replied without a grade", "judge_model", e.opts.JudgeModel)
+		return 0, false
+	}
+	n, _ := strconv.Atoi(grade)
+	return float64(n) / 10, true
+}
@@ -0,0 +1,160 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+// judgeFunc dispatches judge requests to a function
+type judgeFunc func(body []byte) (int, []byte)
+
+func (f judgeFunc) Dispatch(ctx context.Context, _ http.Header, _ string, body []byte) (int, []byte) {
+	if !IsJudge(ctx) {
+		return http.StatusInternalServerError, nil
+	}
+	return f(body)
+}
+
+func completion(content string) []byte {
+	b, _ := json.Marshal(map[string]any{
+		"choices": []map[string]any{{"finish_reason": "stop", "message": map[string]any{"role": "assistant", "content": content}}},
+	})
+	return b
+}
+
+func scoresOf(scores []Score) map[string]float64 {
+	m := make(map[string]float64)
+	for _, s := range scores {
+		m[s.Evaluator] = s.Value
+	}
+	return m
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(nil, nil, logger.NewNoopLogger(), Options{Percent: 101, Concurrency: 1})
+	assert.ErrorContains(t, err, "EVAL_PERCENT")
+	_, err = New(nil, nil, logger.NewNoopLogger(), Options{Percent: 5})
+	assert.ErrorContains(t, err, "EVAL_CONCURRENCY")
+	_, err = New(nil, nil, logger.NewNoopLogger(), Options{Percent: 5, Concurrency: 1, JudgeModel: "gpt-4o-mini"})
+	assert.ErrorContains(t, err, "provider/model")
+}
+
+func TestScores(t *testing.T) {
+	e, err := New(nil, nil, logger.NewNoopLogger(), Options{Percent: 100, Concurrency: 1, LatencyThreshold: time.Second})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		sample Sample
+		want   map[string]float64
+	}{
+		{
+			name:   "answer",
+			sample: Sample{Request: []byte(`{"model":"openai/gpt-4o"}`), Response: completion("The capital of France is Paris."), Latency: 100 * time.Millisecond},
+			want:   map[string]float64{Latency: 1, Refusal: 0},
+		},
+		{
+			name:   "slow refusal",
+			sample: Sample{Request: []byte(`{"model":"openai/gpt-4o"}`), Response: completion("I’m sorry, but I can't help with that."), Latency: 2 * time.Second},
+			want:   map[string]float64{Latency: 0, Refusal: 1},
+		},
+		{
+			name:   "refusal as an AI",
+			sample: Sample{Request: []byte(`{}`), Response: completion("As an AI language model, I cannot browse the internet.")},
+			want:   map[string]float64{Latency: 1, Refusal: 1},
+		},
+		{
+			name:   "apology that is not a refusal",
+			sample: Sample{Request: []byte(`{}`), Response: completion("Sorry for the confusion. Here is the corrected code.")},
+			want:   map[string]float64{Latency: 1, Refusal: 0},
+		},
+		{
+			name:   "valid JSON",
+			sample: Sample{Request: []byte(`{"response_format":{"type":"json_object"}}`), Response: completion(` {"city":"Paris"} `)},
+			want:   map[string]float64{Latency: 1, Refusal: 0, JSONValid: 1},
+		},
+		{
+			name:   "invalid JSON",
+			sample: Sample{Request: []byte(`{"response_format":{"type":"json_schema"}}`), Response: completion("```json\n{\"city\":\"Paris\"}\n```")},
+			want:   map[string]float64{Latency: 1, Refusal: 0, JSONValid: 0},
+		},
+		{
+			name: "streamed refusal",
+			sample: Sample{
+				Request:  []byte(`{"stream":true}`),
+				Response: []byte("data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"I cannot \"}}]}\n\ndata: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"do that.\"},\"finish_reason\":\"stop\"}]}\n\ndata: [DONE]\n\n"),
+				Stream:   true,
+			},
+			want: map[string]float64{Latency: 1, Refusal: 1},
+		},
+		{
+			name:   "content filter",
+			sample: Sample{Request: []byte(`{}`), Response: []byte(`{"choices":[{"finish_reason":"content_filter","message":{"content":""}}]}`)},
+			want:   map[string]float64{Latency: 1, Refusal: 1},
+		},
+		{
+			name:   "tool calls",
+			sample: Sample{Request: []byte(`{}`), Response: []byte(`{"choices":[{"finish_reason":"tool_calls","message":{"tool_calls":[{"id":"1","type":"function","function":{"name":"f","arguments":"{}"}}]}}]}`)},
+			want:   map[string]float64{Latency: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, scoresOf(e.Scores(tt.sample)))
+		})
+	}
+}
+
+func TestJudge(t *testing.T) {
+	var judged string
+	dispatcher := judgeFunc(func(body []byte) (int, []byte) {
+		judged = string(body)
+		return http.StatusOK, completion("8")
+	})
+	e, err := New(dispatcher, nil, logger.NewNoopLogger(), Options{Percent: 100, Concurrency: 1, JudgeModel: "groq/llama-3.1-8b-instant", JudgeTimeout: time.Second})
+	require.NoError(t, err)
+
+	sample := Sample{
+		Request:  []byte(`{"messages":[{"role":"user","content":"What is the capital of France?"}]}`),
+		Response: completion("Paris."),
+	}
+	assert.Equal(t, map[string]float64{Refusal: 0, Judge: 0.8}, scoresOf(e.Scores(sample)))
+	assert.Contains(t, judged, `"model":"groq/llama-3.1-8b-instant"`)
+	assert.Contains(t, judged, "What is the capital of France?")
+	assert.Contains(t, judged, "Paris.")
+
+	e.dispatcher = judgeFunc(func([]byte) (int, []byte) { return http.StatusOK, completion("Good answer") })
+	assert.NotContains(t, scoresOf(e.Scores(sample)), Judge, "replies without a grade are not scored")
+	e.dispatcher = judgeFunc(func([]byte) (int, []byte) { return http.StatusBadGateway, nil })
+	assert.NotContains(t, scoresOf(e.Scores(sample)), Judge, "failed judge requests are not scored")
+}
+
+func TestEvaluateSaturated(t *testing.T) {
+	release := make(chan struct{})
+	dispatcher := judgeFunc(func([]byte) (int, []byte) {
+		<-release
+		return http.StatusOK, completion("5")
+	})
+	e, err := New(dispatcher, nil, logger.NewNoopLogger(), Options{Percent: 100, Concurrency: 1, JudgeModel: "groq/llama-3.1-8b-instant", JudgeTimeout: time.Second})
+	require.NoError(t, err)
+
+	sample := Sample{
+		Request:  []byte(`{"messages":[{"role":"user","content":"` + strings.Repeat("hi ", 3) + `"}]}`),
+		Response: completion("Hello!"),
+	}
+	assert.True(t, e.Evaluate(sample))
+	assert.False(t, e.Evaluate(sample), "EVAL_CONCURRENCY evaluations are already in flight")
+	close(release)
+	e.Wait()
+	assert.True(t, e.Evaluate(sample))
+	e.Wait()
+}
@@ -0,0 +1,285 @@
+// Package files stores uploaded files and the files generated by the gateway,
+// such as Batch API results, and serves the OpenAI-compatible /v1/files API.
+package files
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	config "github.com/inference-gateway/inference-gateway/config"
+	sigv4 "github.com/inference-gateway/inference-gateway/internal/sigv4"
+)
+
+// Backends selectable with FILES_BACKEND
+const (
+	BackendDisk = "disk"
+	BackendS3   = "s3"
+)
+
+// Purposes of the files created by the gateway itself
+const (
+	PurposeBatch       = "batch"
+	PurposeBatchOutput = "batch_output"
+)
+
+// ErrNotFound is returned when a file ID is not in the store
+var ErrNotFound = errors.New("file not found")
+
+// File describes a stored file in the shape of the OpenAI Files API. Owner,
+// which the API does not answer, identifies the caller the file belongs to
+// (tenants.Owner).
+type File struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	Bytes     int64  `json:"bytes"`
+	CreatedAt int64  `json:"created_at"`
+	Filename  string `json:"filename"`
+	Purpose   string `json:"purpose"`
+	Owner     string `json:"-"`
+}
+
+// meta is the stored description of a file
+type meta struct {
+	File
+	Owner string `json:"owner"`
+}
+
+func encodeMeta(file File) ([]byte, error) {
+	return json.Marshal(meta{File: file, Owner: file.Owner})
+}
+
+func decodeMeta(data []byte) (File, error) {
+	var m meta
+	if err := json.Unmarshal(data, &m); err != nil {
+		return File{}, err
+	}
+	m.File.Owner = m.Owner
+	return m.File, nil
+}
+
+// Store persists files
+type Store interface {
+	// Create stores content under a new ID, owned by owner
+	Create(ctx context.Context, filename, purpose, owner string, content io.Reader) (File, error)
+	// Get returns the description of file id
+	Get(ctx context.Context, id string) (File, error)
+	// Open returns the content of file id. The caller must close it.
+	Open(ctx context.Context, id string) (io.ReadCloser, error)
+	// List returns the files with purpose, or every file when purpose is
+	// empty, oldest first
+	List(ctx context.Context, purpose string) ([]File, error)
+	// Delete removes file id
+	Delete(ctx context.Context, id string) error
+}
+
+// GetOwned returns the description of file id when it belongs to owner, and
+// ErrNotFound otherwise, so callers cannot tell the files of others exist
+func GetOwned(ctx context.Context, store Store, id, owner string) (File, error) {
+	file, err := store.Get(ctx, id)
+	if err != nil {
+		return File{}, err
+	}
+	if file.Owner != owner {
+		return File{}, ErrNotFound
+	}
+	return file, nil
+}
+
+// NewStore creates the store selected by FILES_BACKEND
+func NewStore(cfg config.Config) (Store, error) {
+	switch cfg.FilesBackend {
+	case BackendDisk, "":
+		return NewDiskStore(cfg.FilesDir)
+	case BackendS3:
+		creds, err := sigv4.CredentialsFromEnv("s3 files backend")
+		if err != nil {
+			return nil, err
+		}
+		if cfg.FilesS3Bucket == "" || cfg.AwsRegion == "" {
+			return nil, fmt.Errorf("FILES_S3_BUCKET and AWS_REGION are required by the s3 files backend")
+		}
+		return NewS3Store(&http.Client{}, S3Config{
+			Bucket:   cfg.FilesS3Bucket,
+			Prefix:   cfg.FilesS3Prefix,
+			Region:   cfg.AwsRegion,
+			Endpoint: cfg.FilesS3Endpoint,
+		}, creds)
+	default:
+		return nil, fmt.Errorf("unknown files backend %q, expected %s or %s", cfg.FilesBackend, BackendDisk, BackendS3)
+	}
+}
+
+// NewID returns a random file ID
+func NewID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "file-" + hex.EncodeToString(b), nil
+}
+
+// DiskStore is a Store keeping every file as two entries of a local
+// directory: the content and a JSON description next to it
+type DiskStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewDiskStore creates a store in dir, creating the directory if needed
+func NewDiskStore(dir string) (*DiskStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create files directory: %w", err)
+	}
+	return &DiskStore{dir: dir}, nil
+}
+
+func (s *DiskStore) Create(_ context.Context, filename, purpose, owner string, content io.Reader) (File, error) {
+	id, err := NewID()
+	if err != nil {
+		return File{}, err
+	}
+
+	f, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return File{}, err
+	}
+	defer os.Remove(f.Name())
+	n, err := io.Copy(f, content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return File{}, fmt.Errorf("write file: %w", err)
+	}
+
+	file := File{
+		ID:        id,
+		Object:    "file",
+		Bytes:     n,
+		CreatedAt: time.Now().Unix(),
+		Filename:  filepath.Base(filename),
+		Purpose:   purpose,
+		Owner:     owner,
+	}
+	meta, err := encodeMeta(file)
+	if err != nil {
+		return File{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Rename(f.Name(), s.contentPath(id)); err != nil {
+		return File{}, fmt.Errorf("write file: %w", err)
+	}
+	if err := os.WriteFile(s.metaPath(id), meta, 0o640); err != nil {
+		os.Remove(s.contentPath(id))
+		return File{}, fmt.Errorf("write file: %w", err)
+	}
+	return file, nil
+}
+
+func (s *DiskStore) Get(_ context.Context, id string) (File, error) {
+	if !validID(id) {
+		return File{}, ErrNotFound
+	}
+	data, err := os.ReadFile(s.metaPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return File{}, ErrNotFound
+	}
+	if err != nil {
+		return File{}, err
+	}
+	file, err := decodeMeta(data)
+	if err != nil {
+		return File{}, fmt.Errorf("read file %s: %w", id, err)
+	}
+	return file, nil
+}
+
+func (s *DiskStore) Open(ctx context.Context, id string) (io.ReadCloser, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(s.contentPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (s *DiskStore) List(ctx context.Context, purpose string) ([]File, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]File, 0)
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || !validID(id) {
+			continue
+		}
+		file, err := s.Get(ctx, id)
+		if err != nil {
+			continue
+		}
+		if purpose == "" || file.Purpose == purpose {
+			files = append(files, file)
+		}
+	}
+	slices.SortFunc(files, func(a, b File) int {
+		return cmp.Compare(a.CreatedAt, b.CreatedAt)
+	})
+	return files, nil
+}
+
+func (s *DiskStore) Delete(_ context.Context, id string) error {
+	if !validID(id) {
+		return ErrNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.metaPath(id)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if err := os.Remove(s.contentPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *DiskStore) contentPath(id string) string {
+	return filepath.Join(s.dir, id)
+}
+
+func (s *DiskStore) metaPath(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// validID reports whether id has the shape of NewID, so IDs taken from
+// requests cannot escape the store directory
+func validID(id string) bool {
+	hexPart, ok := strings.CutPrefix(id, "file-")
+	if !ok || hexPart == "" {
+		return false
+	}
+	_, err := hex.DecodeString(hexPart)
+	return err == nil
+}
+
+var _ Store = (*DiskStore)(nil)
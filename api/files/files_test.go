@@ -0,0 +1,52 @@
+package files
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+)
+
+func TestDiskStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewDiskStore(t.TempDir())
+	require.NoError(t, err)
+
+	file, err := store.Create(ctx, "../input.jsonl", PurposeBatch, "owner", strings.NewReader("line\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "file", file.Object)
+	assert.Equal(t, int64(5), file.Bytes)
+	assert.Equal(t, "input.jsonl", file.Filename, "directories are stripped from file names")
+
+	got, err := store.Get(ctx, file.ID)
+	require.NoError(t, err)
+	assert.Equal(t, file, got, "the owner is stored with the file")
+	_, err = GetOwned(ctx, store, file.ID, "other")
+	assert.ErrorIs(t, err, ErrNotFound, "files of other owners are not found")
+
+	content, err := store.Open(ctx, file.ID)
+	require.NoError(t, err)
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+	require.NoError(t, content.Close())
+	assert.Equal(t, "line\n", string(data))
+
+	_, err = store.Create(ctx, "output.jsonl", PurposeBatchOutput, "owner", strings.NewReader("{}"))
+	require.NoError(t, err)
+	list, err := store.List(ctx, PurposeBatch)
+	require.NoError(t, err)
+	assert.Equal(t, []File{file}, list)
+	list, err = store.List(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+
+	require.NoError(t, store.Delete(ctx, file.ID))
+	_, err = store.Get(ctx, file.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, store.Delete(ctx, file.ID), ErrNotFound)
+	_, err = store.Open(ctx, "file-../../etc/passwd")
+	assert.ErrorIs(t, err, ErrNotFound, "ids cannot escape the store directory")
+}
@@ -0,0 +1,173 @@
+package files
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+// uploadPurposes are the purposes clients may upload files with. Files with
+// the gateway's own purposes, such as batch_output, cannot be uploaded.
+var uploadPurposes = []string{PurposeBatch, "assistants", "user_data", "vision"}
+
+// List is the body of GET /v1/files
+type List struct {
+	Object string `json:"object"`
+	Data   []File `json:"data"`
+}
+
+// Deleted is the body of DELETE /v1/files/:id
+type Deleted struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Deleted bool   `json:"deleted"`
+}
+
+// API serves the OpenAI-compatible /v1/files endpoints
+type API struct {
+	store  Store
+	logger logger.Logger
+}
+
+// NewAPI creates the Files API on top of store
+func NewAPI(store Store, logger logger.Logger) (*API, error) {
+	if store == nil {
+		return nil, errors.New("file store is required")
+	}
+	return &API{store: store, logger: logger}, nil
+}
+
+// UploadHandler implements POST /v1/files, storing the file field of a
+// multipart form with the purpose field. The upload is bounded by
+// FILES_MAX_UPLOAD_BYTES, enforced by the request limits middleware.
+func (a *API) UploadHandler(c *gin.Context) {
+	purpose := c.PostForm("purpose")
+	if !slices.Contains(uploadPurposes, purpose) {
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, fmt.Sprintf("purpose must be one of %v", uploadPurposes))
+		return
+	}
+	upload, err := c.FormFile("file")
+	if err != nil {
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "The multipart form has no file field")
+		return
+	}
+	content, err := upload.Open()
+	if err != nil {
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to read the uploaded file")
+		return
+	}
+	defer content.Close()
+
+	ctx := c.Request.Context()
+	file, err := a.store.Create(ctx, upload.Filename, purpose, tenants.Owner(ctx, c.Request.Header), content)
+	if err != nil {
+		a.logger.Error("failed to store uploaded file", err, "purpose", purpose)
+		errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to store the uploaded file")
+		return
+	}
+	a.logger.Info("file uploaded", "file", file.ID, "purpose", purpose, "bytes", file.Bytes)
+	c.JSON(http.StatusOK, file)
+}
+
+// ListHandler implements GET /v1/files, listing the caller's files,
+// optionally filtered by the purpose query parameter
+func (a *API) ListHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	list, err := a.store.List(ctx, c.Query("purpose"))
+	if err != nil {
+		a.logger.Error("failed to list files", err)
+		errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to list files")
+		return
+	}
+	owner := tenants.Owner(ctx, c.Request.Header)
+	list = slices.DeleteFunc(list, func(file File) bool { return file.Owner != owner })
+	c.JSON(http.StatusOK, List{Object: "list", Data: list})
+}
+
+// GetHandler implements GET /v1/files/:id. The files of other callers are
+// not found, here as in the other handlers.
+func (a *API) GetHandler(c *gin.Context) {
+	file, err := a.owned(c)
+	if err != nil {
+		a.storeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, file)
+}
+
+// ContentHandler implements GET /v1/files/:id/content
+func (a *API) ContentHandler(c *gin.Context) {
+	file, err := a.owned(c)
+	if err != nil {
+		a.storeError(c, err)
+		return
+	}
+	content, err := a.store.Open(c.Request.Context(), file.ID)
+	if err != nil {
+		a.storeError(c, err)
+		return
+	}
+	defer content.Close()
+
+	c.Header("Content-Type", ContentType(file.Filename, nil))
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.Filename}))
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, content); err != nil {
+		a.logger.Error("failed to send file content", err, "file", file.ID)
+	}
+}
+
+// DeleteHandler implements DELETE /v1/files/:id
+func (a *API) DeleteHandler(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := a.owned(c); err != nil {
+		a.storeError(c, err)
+		return
+	}
+	if err := a.store.Delete(c.Request.Context(), id); err != nil {
+		a.storeError(c, err)
+		return
+	}
+	a.logger.Info("file deleted", "file", id)
+	c.JSON(http.StatusOK, Deleted{ID: id, Object: "file", Deleted: true})
+}
+
+// owned returns the file of the id parameter if it belongs to the caller
+func (a *API) owned(c *gin.Context) (File, error) {
+	ctx := c.Request.Context()
+	return GetOwned(ctx, a.store, c.Param("id"), tenants.Owner(ctx, c.Request.Header))
+}
+
+func (a *API) storeError(c *gin.Context, err error) {
+	if errors.Is(err, ErrNotFound) {
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, "File not found")
+		return
+	}
+	a.logger.Error("file store request failed", err, "file", c.Param("id"))
+	errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to read the file")
+}
+
+// ContentType guesses the media type of a file from its name, then from the
+// first bytes of its content when given
+func ContentType(filename string, head []byte) string {
+	if t := mime.TypeByExtension(filepath.Ext(filename)); t != "" {
+		return t
+	}
+	if filepath.Ext(filename) == ".jsonl" {
+		return "application/jsonl"
+	}
+	if head != nil {
+		return http.DetectContentType(head)
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,187 @@
+package files
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func newTestAPI(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	store, err := NewDiskStore(t.TempDir())
+	require.NoError(t, err)
+	a, err := NewAPI(store, logger.NewNoopLogger())
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.POST("/v1/files", a.UploadHandler)
+	r.GET("/v1/files", a.ListHandler)
+	r.GET("/v1/files/:id", a.GetHandler)
+	r.GET("/v1/files/:id/content", a.ContentHandler)
+	r.DELETE("/v1/files/:id", a.DeleteHandler)
+	return r
+}
+
+func uploadFile(r *gin.Engine, purpose, filename, content string) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("file", filename)
+	_, _ = part.Write([]byte(content))
+	_ = form.WriteField("purpose", purpose)
+	_ = form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/files", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func serve(r *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	return serveAs(r, method, path, "")
+}
+
+// serveAs serves a request carrying the Authorization header authorization
+func serveAs(r *gin.Engine, method, path, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAPI(t *testing.T) {
+	r := newTestAPI(t)
+
+	w := uploadFile(r, PurposeBatch, "input.jsonl", "{}\n")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var file File
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &file))
+	assert.Equal(t, "input.jsonl", file.Filename)
+	assert.Equal(t, PurposeBatch, file.Purpose)
+	require.Equal(t, http.StatusOK, uploadFile(r, "vision", "cat.png", "png").Code)
+
+	w = serve(r, http.MethodGet, "/v1/files?purpose=batch")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list List
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, List{Object: "list", Data: []File{file}}, list)
+
+	w = serve(r, http.MethodGet, "/v1/files/"+file.ID)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, mustJSON(t, file), w.Body.String())
+
+	w = serve(r, http.MethodGet, "/v1/files/"+file.ID+"/content")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "{}\n", w.Body.String())
+	assert.Equal(t, "application/jsonl", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename=input.jsonl`, w.Header().Get("Content-Disposition"))
+
+	w = serve(r, http.MethodDelete, "/v1/files/"+file.ID)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":"`+file.ID+`","object":"file","deleted":true}`, w.Body.String())
+	assert.Equal(t, http.StatusNotFound, serve(r, http.MethodGet, "/v1/files/"+file.ID).Code)
+	assert.Equal(t, http.StatusNotFound, serve(r, http.MethodDelete, "/v1/files/"+file.ID).Code)
+}
+
+func TestAPI_Owner(t *testing.T) {
+	r := newTestAPI(t)
+
+	w := uploadFile(r, PurposeBatch, "input.jsonl", "{}\n")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var file File
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &file))
+
+	const other = "Bearer other"
+	assert.Equal(t, http.StatusNotFound, serveAs(r, http.MethodGet, "/v1/files/"+file.ID, other).Code)
+	assert.Equal(t, http.StatusNotFound, serveAs(r, http.MethodGet, "/v1/files/"+file.ID+"/content", other).Code)
+	assert.Equal(t, http.StatusNotFound, serveAs(r, http.MethodDelete, "/v1/files/"+file.ID, other).Code)
+
+	w = serveAs(r, http.MethodGet, "/v1/files", other)
+	require.Equal(t, http.StatusOK, w.Code)
+	var list List
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Empty(t, list.Data, "files of other callers are not listed")
+
+	assert.Equal(t, http.StatusOK, serve(r, http.MethodGet, "/v1/files/"+file.ID).Code, "the file was not deleted")
+}
+
+func TestAPI_OwnerSubject(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store, err := NewDiskStore(t.TempDir())
+	require.NoError(t, err)
+	a, err := NewAPI(store, logger.NewNoopLogger())
+	require.NoError(t, err)
+
+	// The subject stands for the one the auth middleware verified, the
+	// tokens carry none of it, like tokens passed in the request body
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), types.TenantContextKey, "acme")
+		ctx = context.WithValue(ctx, types.AuthSubjectContextKey, c.GetHeader("X-Test-Subject"))
+		c.Request = c.Request.WithContext(ctx)
+	})
+	r.POST("/v1/files", a.UploadHandler)
+	r.GET("/v1/files/:id", a.GetHandler)
+	as := func(method, path, subject, authorization string, body io.Reader, contentType string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, body)
+		req.Header.Set("X-Test-Subject", subject)
+		req.Header.Set("Authorization", authorization)
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("file", "input.jsonl")
+	_, _ = part.Write([]byte("{}\n"))
+	_ = form.WriteField("purpose", PurposeBatch)
+	_ = form.Close()
+	w := as(http.MethodPost, "/v1/files", "alice", "Bearer alice-token-1", &body, form.FormDataContentType())
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var file File
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &file))
+
+	assert.Equal(t, http.StatusOK, as(http.MethodGet, "/v1/files/"+file.ID, "alice", "Bearer alice-token-2", nil, "").Code, "a refreshed token keeps access")
+	assert.Equal(t, http.StatusNotFound, as(http.MethodGet, "/v1/files/"+file.ID, "bob", "Bearer alice-token-1", nil, "").Code, "another subject of the tenant gets a 404")
+	assert.Equal(t, http.StatusNotFound, as(http.MethodGet, "/v1/files/"+file.ID, "bob", "", nil, "").Code, "another subject without a credential header gets a 404")
+}
+
+func TestAPI_UploadValidation(t *testing.T) {
+	r := newTestAPI(t)
+
+	assert.Equal(t, http.StatusBadRequest, uploadFile(r, "", "input.jsonl", "{}").Code)
+	assert.Equal(t, http.StatusBadRequest, uploadFile(r, PurposeBatchOutput, "output.jsonl", "{}").Code, "gateway purposes cannot be uploaded")
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/files", bytes.NewBufferString("purpose=batch"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(b)
+}
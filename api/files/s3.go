@@ -0,0 +1,244 @@
+package files
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	sigv4 "github.com/inference-gateway/inference-gateway/internal/sigv4"
+)
+
+// S3Config locates the bucket of an S3Store
+type S3Config struct {
+	Bucket string
+	// Prefix is prepended to every object key
+	Prefix string
+	Region string
+	// Endpoint of an S3-compatible store, addressed path-style. The regional
+	// AWS endpoint is used, virtual-hosted style, when empty.
+	Endpoint string
+}
+
+// S3Store is a Store keeping every file as two objects of an S3 bucket: the
+// content and a JSON description next to it
+type S3Store struct {
+	client *http.Client
+	signer *sigv4.Signer
+	base   string
+	prefix string
+}
+
+// NewS3Store creates a store in the bucket of cfg, signing requests with creds
+func NewS3Store(client *http.Client, cfg S3Config, creds sigv4.Credentials) (*S3Store, error) {
+	if cfg.Bucket == "" || cfg.Region == "" {
+		return nil, fmt.Errorf("an S3 bucket and region are required")
+	}
+	base := fmt.Sprintf("https://%s.s3.%s.amazonaws.com", cfg.Bucket, cfg.Region)
+	if cfg.Endpoint != "" {
+		base = strings.TrimSuffix(cfg.Endpoint, "/") + "/" + cfg.Bucket
+	}
+	return &S3Store{
+		client: client,
+		signer: &sigv4.Signer{Credentials: creds, Region: cfg.Region, Service: "s3"},
+		base:   base,
+		prefix: cfg.Prefix,
+	}, nil
+}
+
+func (s *S3Store) Create(ctx context.Context, filename, purpose, owner string, content io.Reader) (File, error) {
+	id, err := NewID()
+	if err != nil {
+		return File{}, err
+	}
+	// S3 needs the length of the object up front
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return File{}, fmt.Errorf("read file: %w", err)
+	}
+
+	file := File{
+		ID:        id,
+		Object:    "file",
+		Bytes:     int64(len(data)),
+		CreatedAt: time.Now().Unix(),
+		Filename:  path.Base(filename),
+		Purpose:   purpose,
+		Owner:     owner,
+	}
+	meta, err := encodeMeta(file)
+	if err != nil {
+		return File{}, err
+	}
+
+	if err := s.put(ctx, s.key(id), data); err != nil {
+		return File{}, fmt.Errorf("write file: %w", err)
+	}
+	// The description is written last: a file is only listed once complete
+	if err := s.put(ctx, s.key(id)+".json", meta); err != nil {
+		_ = s.remove(ctx, s.key(id))
+		return File{}, fmt.Errorf("write file: %w", err)
+	}
+	return file, nil
+}
+
+func (s *S3Store) Get(ctx context.Context, id string) (File, error) {
+	if !validID(id) {
+		return File{}, ErrNotFound
+	}
+	body, err := s.get(ctx, s.key(id)+".json")
+	if err != nil {
+		return File{}, err
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return File{}, fmt.Errorf("read file %s: %w", id, err)
+	}
+	file, err := decodeMeta(data)
+	if err != nil {
+		return File{}, fmt.Errorf("read file %s: %w", id, err)
+	}
+	return file, nil
+}
+
+func (s *S3Store) Open(ctx context.Context, id string) (io.ReadCloser, error) {
+	if !validID(id) {
+		return nil, ErrNotFound
+	}
+	return s.get(ctx, s.key(id))
+}
+
+func (s *S3Store) List(ctx context.Context, purpose string) ([]File, error) {
+	var ids []string
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {s.prefix + "file-"}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := s.do(ctx, http.MethodGet, s.base+"/?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Contents []struct {
+				Key string `xml:"Key"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decode bucket listing: %w", err)
+		}
+		for _, object := range page.Contents {
+			name, ok := strings.CutSuffix(strings.TrimPrefix(object.Key, s.prefix), ".json")
+			if ok && validID(name) {
+				ids = append(ids, name)
+			}
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			break
+		}
+		token = page.NextContinuationToken
+	}
+
+	files := make([]File, 0, len(ids))
+	for _, id := range ids {
+		file, err := s.Get(ctx, id)
+		if err != nil {
+			continue
+		}
+		if purpose == "" || file.Purpose == purpose {
+			files = append(files, file)
+		}
+	}
+	slices.SortFunc(files, func(a, b File) int {
+		return cmp.Compare(a.CreatedAt, b.CreatedAt)
+	})
+	return files, nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, id string) error {
+	// S3 deletes of missing keys succeed, so look the file up first
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	if err := s.remove(ctx, s.key(id)+".json"); err != nil {
+		return err
+	}
+	return s.remove(ctx, s.key(id))
+}
+
+func (s *S3Store) key(id string) string {
+	return s.prefix + id
+}
+
+func (s *S3Store) objectURL(key string) string {
+	return s.base + "/" + (&url.URL{Path: key}).EscapedPath()
+}
+
+func (s *S3Store) put(ctx context.Context, key string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, s.objectURL(key), data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3Store) get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.objectURL(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *S3Store) remove(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do sends a signed request, mapping 404 to ErrNotFound and other non-2xx
+// statuses to an error carrying the S3 error body
+func (s *S3Store) do(ctx context.Context, method, rawURL string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	payloadHash := sigv4.HashPayload(body)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	s.signer.Sign(req, payloadHash)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("s3 %s %s returned status %d: %s", method, req.URL.Path, resp.StatusCode, msg)
+	}
+	return resp, nil
+}
+
+var _ Store = (*S3Store)(nil)
@@ -0,0 +1,115 @@
+package files
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	sigv4 "github.com/inference-gateway/inference-gateway/internal/sigv4"
+)
+
+// fakeS3 serves the object and ListObjectsV2 calls of a single path-style
+// bucket, returning listings one key per page
+func fakeS3(t *testing.T, bucket string) *httptest.Server {
+	var mu sync.Mutex
+	objects := make(map[string][]byte)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"), "requests are signed")
+
+		key, ok := strings.CutPrefix(r.URL.Path, "/"+bucket+"/")
+		switch {
+		case r.URL.Path == "/"+bucket+"/" && r.URL.Query().Get("list-type") == "2":
+			var keys []string
+			for k := range objects {
+				if strings.HasPrefix(k, r.URL.Query().Get("prefix")) {
+					keys = append(keys, k)
+				}
+			}
+			slices.Sort(keys)
+			start, _ := strconv.Atoi(r.URL.Query().Get("continuation-token"))
+			type content struct{ Key string }
+			page := struct {
+				XMLName               xml.Name `xml:"ListBucketResult"`
+				Contents              []content
+				IsTruncated           bool
+				NextContinuationToken string `xml:",omitempty"`
+			}{}
+			if start < len(keys) {
+				page.Contents = []content{{Key: keys[start]}}
+			}
+			if start+1 < len(keys) {
+				page.IsTruncated = true
+				page.NextContinuationToken = strconv.Itoa(start + 1)
+			}
+			require.NoError(t, xml.NewEncoder(w).Encode(page))
+		case !ok:
+			w.WriteHeader(http.StatusBadRequest)
+		case r.Method == http.MethodPut:
+			data, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Equal(t, sigv4.HashPayload(data), r.Header.Get("X-Amz-Content-Sha256"))
+			objects[key] = data
+		case r.Method == http.MethodGet:
+			data, found := objects[key]
+			if !found {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte("<Error><Code>NoSuchKey</Code></Error>"))
+				return
+			}
+			_, _ = w.Write(data)
+		case r.Method == http.MethodDelete:
+			delete(objects, key)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+}
+
+func TestS3Store(t *testing.T) {
+	ctx := context.Background()
+	srv := fakeS3(t, "files")
+	defer srv.Close()
+	store, err := NewS3Store(srv.Client(), S3Config{Bucket: "files", Prefix: "gateway/", Region: "eu-west-1", Endpoint: srv.URL}, sigv4.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"})
+	require.NoError(t, err)
+
+	file, err := store.Create(ctx, "dir/input.jsonl", PurposeBatch, "owner", strings.NewReader("line\n"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), file.Bytes)
+	assert.Equal(t, "input.jsonl", file.Filename)
+
+	got, err := store.Get(ctx, file.ID)
+	require.NoError(t, err)
+	assert.Equal(t, file, got, "the owner is stored with the file")
+
+	content, err := store.Open(ctx, file.ID)
+	require.NoError(t, err)
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+	require.NoError(t, content.Close())
+	assert.Equal(t, "line\n", string(data))
+
+	_, err = store.Create(ctx, "output.jsonl", PurposeBatchOutput, "owner", strings.NewReader("{}"))
+	require.NoError(t, err)
+	list, err := store.List(ctx, PurposeBatch)
+	require.NoError(t, err)
+	assert.Equal(t, []File{file}, list)
+	list, err = store.List(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, list, 2, "listings are followed across pages")
+
+	require.NoError(t, store.Delete(ctx, file.ID))
+	_, err = store.Open(ctx, file.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, store.Delete(ctx, file.ID), ErrNotFound)
+}
@@ -0,0 +1,200 @@
+// Package health tracks whether the gateway is ready to serve traffic and
+// drains in-flight requests on shutdown, backing the /health/ready probe.
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+// ErrDraining is reported by the readiness probe once shutdown has started
+var ErrDraining = errors.New("gateway is draining")
+
+// Check reports nil when a dependency is ready
+type Check func() error
+
+// Report is the body of the readiness probe
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// State is the readiness and drain state of the gateway
+type State struct {
+	mu       sync.Mutex
+	checks   map[string]Check
+	draining bool
+	inflight int
+	streams  int
+	idle     chan struct{}
+}
+
+type admittedKey struct{}
+
+// Admit returns ctx marking the requests made with it as part of work accepted
+// before the drain, such as the turns of a running agent job, so they are
+// still admitted while draining
+func Admit(ctx context.Context) context.Context {
+	return context.WithValue(ctx, admittedKey{}, true)
+}
+
+// Admitted reports whether ctx was marked with Admit
+func Admitted(ctx context.Context) bool {
+	admitted, _ := ctx.Value(admittedKey{}).(bool)
+	return admitted
+}
+
+// NewState creates a state that is ready until checks are added
+func NewState() *State {
+	return &State{
+		checks: make(map[string]Check),
+		idle:   make(chan struct{}),
+	}
+}
+
+// AddCheck registers a readiness check evaluated on every probe, replacing any
+// check with the same name
+func (s *State) AddCheck(name string, check Check) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checks[name] = check
+}
+
+// Set records the outcome of a one-off check such as startup validation
+func (s *State) Set(name string, err error) {
+	s.AddCheck(name, func() error { return err })
+}
+
+// Ready evaluates every check, returning the failures by name
+func (s *State) Ready() map[string]error {
+	s.mu.Lock()
+	checks := make(map[string]Check, len(s.checks))
+	for name, check := range s.checks {
+		checks[name] = check
+	}
+	draining := s.draining
+	s.mu.Unlock()
+
+	failed := make(map[string]error)
+	if draining {
+		failed["shutdown"] = ErrDraining
+	}
+	for name, check := range checks {
+		if err := check(); err != nil {
+			failed[name] = err
+		}
+	}
+	return failed
+}
+
+// Begin records the start of the request of ctx. It returns false once
+// draining has started, in which case the request must be rejected and End
+// not called; requests whose ctx was marked with Admit are still admitted.
+func (s *State) Begin(ctx context.Context) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.draining && !Admitted(ctx) {
+		return false
+	}
+	s.inflight++
+	return true
+}
+
+// End records the end of a request started with Begin
+func (s *State) End() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inflight--
+	s.signalIdle()
+}
+
+// BeginStream records that a request started with Begin answers with an
+// event stream, until EndStream is called
+func (s *State) BeginStream() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.streams++
+}
+
+// EndStream records the end of a stream started with BeginStream
+func (s *State) EndStream() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.streams--
+}
+
+// signalIdle wakes up Wait once draining has started and no request is in
+// flight. s.mu must be held.
+func (s *State) signalIdle() {
+	if !s.draining || s.inflight > 0 {
+		return
+	}
+	select {
+	case <-s.idle:
+	default:
+		close(s.idle)
+	}
+}
+
+// Drain stops admitting requests. It is safe to call more than once.
+func (s *State) Drain() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.draining {
+		return
+	}
+	s.draining = true
+	s.signalIdle()
+}
+
+// Draining reports whether Drain has been called
+func (s *State) Draining() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.draining
+}
+
+// InFlight returns the number of requests in progress
+func (s *State) InFlight() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inflight
+}
+
+// Streams returns the number of event streams in progress
+func (s *State) Streams() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.streams
+}
+
+// Wait blocks until every in-flight request has finished after Drain, or ctx
+// is done
+func (s *State) Wait(ctx context.Context) error {
+	select {
+	case <-s.idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ReadyHandler implements GET /health/ready. It answers 503 with the failing
+// checks while a dependency is not ready or the gateway is draining.
+func (s *State) ReadyHandler(c *gin.Context) {
+	failed := s.Ready()
+	if len(failed) == 0 {
+		c.JSON(http.StatusOK, Report{Status: "ok"})
+		return
+	}
+
+	report := Report{Status: "unavailable", Checks: make(map[string]string, len(failed))}
+	for name, err := range failed {
+		report.Checks[name] = err.Error()
+	}
+	c.JSON(http.StatusServiceUnavailable, report)
+}
@@ -0,0 +1,104 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+)
+
+func probe(t *testing.T, s *State) (int, Report) {
+	t.Helper()
+	r := gin.New()
+	r.GET("/health/ready", s.ReadyHandler)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+	var report Report
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	return w.Code, report
+}
+
+func TestReadyHandler(t *testing.T) {
+	s := NewState()
+	code, report := probe(t, s)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, Report{Status: "ok"}, report)
+
+	initialized := false
+	s.AddCheck("mcp", func() error {
+		if !initialized {
+			return errors.New("not initialized")
+		}
+		return nil
+	})
+	s.Set("providers", errors.New("no provider is reachable"))
+	code, report = probe(t, s)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, Report{Status: "unavailable", Checks: map[string]string{
+		"mcp":       "not initialized",
+		"providers": "no provider is reachable",
+	}}, report)
+
+	initialized = true
+	s.Set("providers", nil)
+	code, _ = probe(t, s)
+	assert.Equal(t, http.StatusOK, code)
+
+	s.Drain()
+	code, report = probe(t, s)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, map[string]string{"shutdown": ErrDraining.Error()}, report.Checks)
+}
+
+func TestState_DrainWaitsForInFlight(t *testing.T) {
+	s := NewState()
+	require.True(t, s.Begin(context.Background()))
+	require.True(t, s.Begin(context.Background()))
+
+	s.Drain()
+	s.Drain()
+	assert.True(t, s.Draining())
+	assert.False(t, s.Begin(context.Background()), "no request is admitted while draining")
+	assert.Equal(t, 2, s.InFlight())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, s.Wait(ctx), context.DeadlineExceeded)
+
+	s.End()
+	s.End()
+	assert.NoError(t, s.Wait(context.Background()))
+}
+
+func TestState_DrainWhenIdle(t *testing.T) {
+	s := NewState()
+	s.Drain()
+	assert.NoError(t, s.Wait(context.Background()))
+}
+
+func TestState_AdmittedWhileDraining(t *testing.T) {
+	s := NewState()
+	s.Drain()
+	require.NoError(t, s.Wait(context.Background()))
+
+	ctx := Admit(context.Background())
+	assert.True(t, Admitted(ctx))
+	assert.False(t, Admitted(context.Background()))
+	require.True(t, s.Begin(ctx), "work accepted before the drain is still admitted")
+	s.BeginStream()
+	assert.Equal(t, 1, s.InFlight())
+	assert.Equal(t, 1, s.Streams())
+
+	s.EndStream()
+	s.End()
+	assert.Equal(t, 0, s.Streams())
+	assert.NoError(t, s.Wait(context.Background()))
+}
@@ -0,0 +1,272 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+func init() {
+	Register("system_prompt", newSystemPromptHook)
+	Register("strip_fields", newStripFieldsHook)
+	Register("webhook", newWebhookHook)
+}
+
+// systemPromptHook injects a system message into chat requests. In prepend mode
+// the message is added in front of the conversation; in replace mode any system
+// messages sent by the client are dropped first.
+type systemPromptHook struct {
+	content string
+	replace bool
+}
+
+func newSystemPromptHook(options map[string]any) (Hook, error) {
+	content, err := stringOption(options, "content")
+	if err != nil {
+		return nil, err
+	}
+	if content == "" {
+		return nil, fmt.Errorf("option content is required")
+	}
+	mode, err := stringOption(options, "mode")
+	if err != nil {
+		return nil, err
+	}
+	switch mode {
+	case "", "prepend":
+		return &systemPromptHook{content: content}, nil
+	case "replace":
+		return &systemPromptHook{content: content, replace: true}, nil
+	default:
+		return nil, fmt.Errorf("unsupported mode %q, expected prepend or replace", mode)
+	}
+}
+
+func (h *systemPromptHook) TransformRequest(_ context.Context, _ string, body []byte) ([]byte, error) {
+	doc, err := decodeObject(body)
+	if err != nil {
+		return nil, err
+	}
+	messages, ok := doc["messages"].([]any)
+	if !ok {
+		return body, nil
+	}
+
+	result := make([]any, 0, len(messages)+1)
+	result = append(result, map[string]any{"role": "system", "content": h.content})
+	for _, m := range messages {
+		if msg, ok := m.(map[string]any); ok && h.replace && msg["role"] == "system" {
+			continue
+		}
+		result = append(result, m)
+	}
+	doc["messages"] = result
+	return json.Marshal(doc)
+}
+
+func (h *systemPromptHook) TransformResponse(_ context.Context, _ string, body []byte) ([]byte, error) {
+	return body, nil
+}
+
+// stripFieldsHook removes fields from request and response bodies. Fields are
+// dot-separated paths; when a path crosses an array the remainder is applied to
+// every element, so "choices.logprobs" strips logprobs from every choice.
+type stripFieldsHook struct {
+	request  []string
+	response []string
+}
+
+func newStripFieldsHook(options map[string]any) (Hook, error) {
+	request, err := stringSliceOption(options, "request")
+	if err != nil {
+		return nil, err
+	}
+	response, err := stringSliceOption(options, "response")
+	if err != nil {
+		return nil, err
+	}
+	if len(request) == 0 && len(response) == 0 {
+		return nil, fmt.Errorf("at least one of options request or response is required")
+	}
+	return &stripFieldsHook{request: request, response: response}, nil
+}
+
+func (h *stripFieldsHook) TransformRequest(_ context.Context, _ string, body []byte) ([]byte, error) {
+	return stripFields(body, h.request)
+}
+
+func (h *stripFieldsHook) TransformResponse(_ context.Context, _ string, body []byte) ([]byte, error) {
+	return stripFields(body, h.response)
+}
+
+func stripFields(body []byte, fields []string) ([]byte, error) {
+	if len(fields) == 0 {
+		return body, nil
+	}
+	doc, err := decodeObject(body)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range fields {
+		deletePath(doc, strings.Split(field, "."))
+	}
+	return json.Marshal(doc)
+}
+
+func deletePath(node any, path []string) {
+	switch v := node.(type) {
+	case map[string]any:
+		if len(path) == 1 {
+			delete(v, path[0])
+			return
+		}
+		deletePath(v[path[0]], path[1:])
+	case []any:
+		for _, elem := range v {
+			deletePath(elem, path)
+		}
+	}
+}
+
+// webhookHook delegates transformation to an external HTTP service. The body is
+// POSTed with X-Hook-Stage (request or response) and X-Hook-Path headers; a 200
+// reply replaces the body, a 204 leaves it unchanged and a 4xx rejects it.
+type webhookHook struct {
+	url    string
+	client *http.Client
+	stages map[string]bool
+}
+
+func newWebhookHook(options map[string]any) (Hook, error) {
+	url, err := stringOption(options, "url")
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("option url must be an http or https url")
+	}
+
+	timeout := 5 * time.Second
+	raw, err := stringOption(options, "timeout")
+	if err != nil {
+		return nil, err
+	}
+	if raw != "" {
+		if timeout, err = time.ParseDuration(raw); err != nil {
+			return nil, fmt.Errorf("invalid option timeout: %w", err)
+		}
+	}
+
+	stages, err := stringSliceOption(options, "stages")
+	if err != nil {
+		return nil, err
+	}
+	if len(stages) == 0 {
+		stages = []string{"request", "response"}
+	}
+	enabled := make(map[string]bool, len(stages))
+	for _, stage := range stages {
+		if stage != "request" && stage != "response" {
+			return nil, fmt.Errorf("unsupported stage %q, expected request or response", stage)
+		}
+		enabled[stage] = true
+	}
+
+	return &webhookHook{url: url, client: &http.Client{Timeout: timeout}, stages: enabled}, nil
+}
+
+func (h *webhookHook) TransformRequest(ctx context.Context, path string, body []byte) ([]byte, error) {
+	return h.call(ctx, "request", path, body)
+}
+
+func (h *webhookHook) TransformResponse(ctx context.Context, path string, body []byte) ([]byte, error) {
+	return h.call(ctx, "response", path, body)
+}
+
+func (h *webhookHook) call(ctx context.Context, stage, path string, body []byte) ([]byte, error) {
+	if !h.stages[stage] {
+		return body, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Hook-Stage", stage)
+	req.Header.Set("X-Hook-Path", path)
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("webhook call: %w", err)
+	}
+	defer resp.Body.Close()
+
+	payload, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("webhook read: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNoContent:
+		return body, nil
+	case resp.StatusCode == http.StatusOK:
+		if !json.Valid(payload) {
+			return nil, fmt.Errorf("webhook returned invalid json")
+		}
+		return payload, nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return nil, fmt.Errorf("%w: %s", ErrRejected, strings.TrimSpace(string(payload)))
+	default:
+		return nil, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+}
+
+// decodeObject decodes a JSON object keeping numbers as json.Number so that
+// re-encoding does not lose precision.
+func decodeObject(body []byte) (map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode body: %w", err)
+	}
+	return doc, nil
+}
+
+func stringOption(options map[string]any, key string) (string, error) {
+	v, ok := options[key]
+	if !ok || v == nil {
+		return "", nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("option %s must be a string", key)
+	}
+	return s, nil
+}
+
+func stringSliceOption(options map[string]any, key string) ([]string, error) {
+	v, ok := options[key]
+	if !ok || v == nil {
+		return nil, nil
+	}
+	items, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("option %s must be a list of strings", key)
+	}
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("option %s must be a list of strings", key)
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
@@ -0,0 +1,164 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// ErrRejected is wrapped by hooks that refuse a request or response outright,
+// e.g. because it violates an organisation policy. The middleware answers such
+// errors with 400 instead of 500.
+var ErrRejected = errors.New("rejected by hook")
+
+// Hook mutates the JSON body of a request before it is routed to a provider and
+// of a response before it is returned to the client. Returning the body
+// unchanged is a valid no-op for either direction.
+type Hook interface {
+	TransformRequest(ctx context.Context, path string, body []byte) ([]byte, error)
+	TransformResponse(ctx context.Context, path string, body []byte) ([]byte, error)
+}
+
+// Factory builds a Hook from the options given for it in the hooks config file.
+type Factory func(options map[string]any) (Hook, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]Factory)
+)
+
+// Register makes a hook available under name to the hooks config file. It is
+// meant to be called from an init function, so operators can compile their own
+// hooks into the gateway. Registering the same name twice panics.
+func Register(name string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	if factory == nil {
+		panic("hooks: Register factory is nil for " + name)
+	}
+	if _, dup := factories[name]; dup {
+		panic("hooks: Register called twice for " + name)
+	}
+	factories[name] = factory
+}
+
+// Registered returns the names of all registered hooks, for startup logging.
+func Registered() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	return slices.Sorted(maps.Keys(factories))
+}
+
+// HookConfig is the on-disk shape of a single hook in a route's chain.
+type HookConfig struct {
+	Name    string         `yaml:"name"`
+	Options map[string]any `yaml:"options"`
+}
+
+// Config is the on-disk hooks file: request path -> ordered hook chain.
+type Config struct {
+	Routes map[string][]HookConfig `yaml:"routes"`
+}
+
+// LoadConfig reads and parses the hooks YAML file at path.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read hooks config: %w", err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse hooks config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// Pipeline holds the hook chain configured for each route. Request hooks run in
+// the configured order; response hooks run in reverse so that a hook wrapping
+// a request sees the response last-in, first-out.
+type Pipeline struct {
+	routes map[string][]Hook
+}
+
+// NewPipeline builds the hook chains from a parsed config, failing on unknown
+// hook names or invalid options rather than serving traffic with a broken chain.
+func NewPipeline(cfg *Config) (*Pipeline, error) {
+	if cfg == nil || len(cfg.Routes) == 0 {
+		return nil, fmt.Errorf("hooks config declares no routes")
+	}
+
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	routes := make(map[string][]Hook, len(cfg.Routes))
+	for route, chain := range cfg.Routes {
+		path := normalizePath(route)
+		if path == "" || !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("route %q: path must start with /", route)
+		}
+		if len(chain) == 0 {
+			return nil, fmt.Errorf("route %q: at least one hook is required", route)
+		}
+		for i, hc := range chain {
+			factory, ok := factories[hc.Name]
+			if !ok {
+				return nil, fmt.Errorf("route %q hook %d: unknown hook %q", route, i, hc.Name)
+			}
+			hook, err := factory(hc.Options)
+			if err != nil {
+				return nil, fmt.Errorf("route %q hook %d (%s): %w", route, i, hc.Name, err)
+			}
+			routes[path] = append(routes[path], hook)
+		}
+	}
+	return &Pipeline{routes: routes}, nil
+}
+
+// Routes returns the paths that have a hook chain, for startup logging.
+func (p *Pipeline) Routes() []string {
+	return slices.Sorted(maps.Keys(p.routes))
+}
+
+// Handles reports whether path has a hook chain.
+func (p *Pipeline) Handles(path string) bool {
+	_, ok := p.routes[normalizePath(path)]
+	return ok
+}
+
+// TransformRequest runs the request chain for path over body.
+func (p *Pipeline) TransformRequest(ctx context.Context, path string, body []byte) ([]byte, error) {
+	var err error
+	for _, hook := range p.routes[normalizePath(path)] {
+		if body, err = hook.TransformRequest(ctx, path, body); err != nil {
+			return nil, err
+		}
+	}
+	return body, nil
+}
+
+// TransformResponse runs the response chain for path over body.
+func (p *Pipeline) TransformResponse(ctx context.Context, path string, body []byte) ([]byte, error) {
+	chain := p.routes[normalizePath(path)]
+	var err error
+	for i := len(chain) - 1; i >= 0; i-- {
+		if body, err = chain[i].TransformResponse(ctx, path, body); err != nil {
+			return nil, err
+		}
+	}
+	return body, nil
+}
+
+func normalizePath(path string) string {
+	path = strings.TrimSpace(path)
+	if len(path) > 1 {
+		path = strings.TrimRight(path, "/")
+	}
+	return path
+}
@@ -0,0 +1,159 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+)
+
+func pipelineFor(t *testing.T, chain ...HookConfig) *Pipeline {
+	t.Helper()
+	p, err := NewPipeline(&Config{Routes: map[string][]HookConfig{"/v1/chat/completions": chain}})
+	require.NoError(t, err)
+	return p
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hooks.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+routes:
+  /v1/chat/completions/:
+    - name: system_prompt
+      options:
+        content: be brief
+`), 0o600))
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	p, err := NewPipeline(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/v1/chat/completions"}, p.Routes())
+	assert.True(t, p.Handles("/v1/chat/completions"))
+	assert.False(t, p.Handles("/v1/models"))
+}
+
+func TestNewPipeline_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *Config
+	}{
+		{name: "No routes", cfg: &Config{}},
+		{name: "Relative path", cfg: &Config{Routes: map[string][]HookConfig{"v1/chat": {{Name: "system_prompt", Options: map[string]any{"content": "x"}}}}}},
+		{name: "Empty chain", cfg: &Config{Routes: map[string][]HookConfig{"/v1/chat/completions": {}}}},
+		{name: "Unknown hook", cfg: &Config{Routes: map[string][]HookConfig{"/v1/chat/completions": {{Name: "nope"}}}}},
+		{name: "Invalid options", cfg: &Config{Routes: map[string][]HookConfig{"/v1/chat/completions": {{Name: "system_prompt"}}}}},
+		{name: "Invalid webhook url", cfg: &Config{Routes: map[string][]HookConfig{"/v1/chat/completions": {{Name: "webhook", Options: map[string]any{"url": "ftp://x"}}}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPipeline(tt.cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSystemPromptHook(t *testing.T) {
+	body := []byte(`{"model":"openai/gpt-4o","max_tokens":12345678901234567,"messages":[{"role":"system","content":"client"},{"role":"user","content":"hi"}]}`)
+
+	p := pipelineFor(t, HookConfig{Name: "system_prompt", Options: map[string]any{"content": "org"}})
+	out, err := p.TransformRequest(context.Background(), "/v1/chat/completions", body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"model":"openai/gpt-4o","max_tokens":12345678901234567,"messages":[{"role":"system","content":"org"},{"role":"system","content":"client"},{"role":"user","content":"hi"}]}`, string(out))
+
+	p = pipelineFor(t, HookConfig{Name: "system_prompt", Options: map[string]any{"content": "org", "mode": "replace"}})
+	out, err = p.TransformRequest(context.Background(), "/v1/chat/completions", body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"model":"openai/gpt-4o","max_tokens":12345678901234567,"messages":[{"role":"system","content":"org"},{"role":"user","content":"hi"}]}`, string(out))
+}
+
+func TestStripFieldsHook(t *testing.T) {
+	p := pipelineFor(t, HookConfig{Name: "strip_fields", Options: map[string]any{
+		"request":  []any{"user"},
+		"response": []any{"system_fingerprint", "choices.logprobs"},
+	}})
+
+	out, err := p.TransformRequest(context.Background(), "/v1/chat/completions", []byte(`{"model":"m","user":"alice"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"model":"m"}`, string(out))
+
+	out, err = p.TransformResponse(context.Background(), "/v1/chat/completions",
+		[]byte(`{"id":"1","system_fingerprint":"fp","choices":[{"index":0,"logprobs":{}},{"index":1,"logprobs":null}]}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"1","choices":[{"index":0},{"index":1}]}`, string(out))
+}
+
+func TestWebhookHook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "/v1/chat/completions", r.Header.Get("X-Hook-Path"))
+		switch {
+		case r.Header.Get("X-Hook-Stage") == "response":
+			w.WriteHeader(http.StatusNoContent)
+		case string(body) == `{"model":"blocked"}`:
+			http.Error(w, "model not allowed", http.StatusForbidden)
+		default:
+			_, _ = w.Write([]byte(`{"model":"rewritten"}`))
+		}
+	}))
+	defer server.Close()
+
+	p := pipelineFor(t, HookConfig{Name: "webhook", Options: map[string]any{"url": server.URL, "timeout": "1s"}})
+
+	out, err := p.TransformRequest(context.Background(), "/v1/chat/completions", []byte(`{"model":"m"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"model":"rewritten"}`, string(out))
+
+	out, err = p.TransformResponse(context.Background(), "/v1/chat/completions", []byte(`{"id":"1"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"1"}`, string(out))
+
+	_, err = p.TransformRequest(context.Background(), "/v1/chat/completions", []byte(`{"model":"blocked"}`))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrRejected))
+	assert.Contains(t, err.Error(), "model not allowed")
+}
+
+type recordingHook struct {
+	name  string
+	trace *[]string
+}
+
+func (h *recordingHook) TransformRequest(_ context.Context, _ string, body []byte) ([]byte, error) {
+	*h.trace = append(*h.trace, "request:"+h.name)
+	return body, nil
+}
+
+func (h *recordingHook) TransformResponse(_ context.Context, _ string, body []byte) ([]byte, error) {
+	*h.trace = append(*h.trace, "response:"+h.name)
+	return body, nil
+}
+
+func TestRegister_ChainOrder(t *testing.T) {
+	var trace []string
+	Register("test_recording", func(options map[string]any) (Hook, error) {
+		name, _ := options["name"].(string)
+		return &recordingHook{name: name, trace: &trace}, nil
+	})
+	assert.Contains(t, Registered(), "test_recording")
+	assert.Panics(t, func() { Register("test_recording", nil) })
+
+	p := pipelineFor(t,
+		HookConfig{Name: "test_recording", Options: map[string]any{"name": "a"}},
+		HookConfig{Name: "test_recording", Options: map[string]any{"name": "b"}},
+	)
+	_, err := p.TransformRequest(context.Background(), "/v1/chat/completions", []byte(`{}`))
+	require.NoError(t, err)
+	_, err = p.TransformResponse(context.Background(), "/v1/chat/completions", []byte(`{}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"request:a", "request:b", "response:b", "response:a"}, trace)
+}
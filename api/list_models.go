@@ -0,0 +1,150 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	gin "github.com/gin-gonic/gin"
+
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	core "github.com/inference-gateway/inference-gateway/providers/core"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// maxModelsPageSize bounds the limit of a GET /v1/models page
+const maxModelsPageSize = 1000
+
+// listProviderModels lists the models of provider, cached per tenant for
+// MODELS_CACHE_TTL and served stale for MODELS_CACHE_MAX_STALE more
+func (router *RouterImpl) listProviderModels(ctx context.Context, providerID types.Provider, provider core.IProvider) (types.ListModelsResponse, error) {
+	key := tenants.FromContext(ctx) + "/" + string(providerID)
+	return router.models.Get(ctx, key, router.cfg().ModelsCacheTtl, router.cfg().ModelsCacheMaxStale, func(ctx context.Context) (types.ListModelsResponse, error) {
+		ctx, cancel := context.WithTimeout(ctx, router.cfg().Server.ReadTimeout)
+		defer cancel()
+		return provider.ListModels(ctx)
+	})
+}
+
+// modelsQuery holds the filtering and pagination parameters of GET /v1/models
+type modelsQuery struct {
+	ownedBy      string
+	capabilities []types.ListModelsParamsCapability
+	limit        int
+	after        string
+}
+
+// parseModelsQuery reads the owned_by, capability, limit and after
+// parameters. Unknown capabilities and limits out of range are rejected.
+func parseModelsQuery(c *gin.Context) (modelsQuery, error) {
+	q := modelsQuery{
+		ownedBy: c.Query("owned_by"),
+		after:   c.Query("after"),
+	}
+	for _, part := range strings.Split(c.Query("capability"), ",") {
+		capability := types.ListModelsParamsCapability(strings.TrimSpace(part))
+		if capability == "" {
+			continue
+		}
+		if !capability.Valid() {
+			return q, fmt.Errorf("unknown capability value %q", capability)
+		}
+		if !slices.Contains(q.capabilities, capability) {
+			q.capabilities = append(q.capabilities, capability)
+		}
+	}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxModelsPageSize {
+			return q, fmt.Errorf("limit must be between 1 and %d", maxModelsPageSize)
+		}
+		q.limit = limit
+	}
+	return q, nil
+}
+
+// paginated reports whether the client asked for a page
+func (q modelsQuery) paginated() bool {
+	return q.limit > 0 || q.after != ""
+}
+
+// filter keeps the models owned by ownedBy that support every capability
+func (q modelsQuery) filter(models []types.Model) []types.Model {
+	if q.ownedBy == "" && len(q.capabilities) == 0 {
+		return models
+	}
+	filtered := make([]types.Model, 0, len(models))
+	for _, model := range models {
+		if q.ownedBy != "" && model.OwnedBy != q.ownedBy {
+			continue
+		}
+		if len(q.capabilities) > 0 && !supportsCapabilities(model, q.capabilities) {
+			continue
+		}
+		filtered = append(filtered, model)
+	}
+	return filtered
+}
+
+// supportsCapabilities reports whether model is known to support every one
+// of capabilities
+func supportsCapabilities(model types.Model, capabilities []types.ListModelsParamsCapability) bool {
+	caps := model.Capabilities
+	if caps == nil {
+		found, ok := core.LookupCapabilities(model.ID)
+		if !ok {
+			return false
+		}
+		caps = &found
+	}
+	for _, capability := range capabilities {
+		var supported bool
+		switch capability {
+		case types.ListModelsParamsCapabilityJSONMode:
+			supported = caps.SupportsJSONMode
+		case types.ListModelsParamsCapabilityReasoning:
+			supported = caps.SupportsReasoning
+		case types.ListModelsParamsCapabilityTools:
+			supported = caps.SupportsTools
+		case types.ListModelsParamsCapabilityVision:
+			supported = caps.SupportsVision
+		}
+		if !supported {
+			return false
+		}
+	}
+	return true
+}
+
+// paginate cuts resp down to the requested page: the models sorted by ID
+// after the after cursor, at most limit of them
+func (q modelsQuery) paginate(resp *types.ListModelsResponse) {
+	if !q.paginated() {
+		return
+	}
+	models := slices.SortedFunc(slices.Values(resp.Data), func(a, b types.Model) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	if q.after != "" {
+		start, _ := slices.BinarySearchFunc(models, q.after, func(m types.Model, id string) int {
+			if m.ID <= id {
+				return -1
+			}
+			return 1
+		})
+		models = models[start:]
+	}
+	hasMore := q.limit > 0 && len(models) > q.limit
+	if hasMore {
+		models = models[:q.limit]
+	}
+
+	resp.Data = models
+	resp.HasMore = &hasMore
+	if len(models) > 0 {
+		resp.FirstID = &models[0].ID
+		resp.LastID = &models[len(models)-1].ID
+	}
+}
@@ -0,0 +1,215 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	mcp "github.com/inference-gateway/inference-gateway/internal/mcp"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// mcpExposed reports whether the MCP endpoints are exposed, answering the
+// request when they are not
+func (router *RouterImpl) mcpExposed(c *gin.Context) bool {
+	if !router.cfg().MCP.Expose {
+		router.log(c).Error("mcp endpoint access attempted but not exposed", nil, "path", c.Request.URL.Path)
+		errcodes.JSON(c, http.StatusForbidden, errcodes.FeatureDisabled, "mcp endpoints are not exposed")
+		return false
+	}
+	return true
+}
+
+// mcpServers returns the servers of the MCP client in URL order, none before
+// it is initialized
+func (router *RouterImpl) mcpServers() []string {
+	if router.mcpClient == nil || !router.mcpClient.IsInitialized() {
+		return nil
+	}
+	return slices.Sorted(slices.Values(router.mcpClient.GetServers()))
+}
+
+// ListMCPResourcesHandler implements GET /v1/mcp/resources, listing the
+// resources of every MCP server. Servers failing to list theirs, such as
+// servers without resources, are left out.
+func (router *RouterImpl) ListMCPResourcesHandler(c *gin.Context) {
+	if !router.mcpExposed(c) {
+		return
+	}
+
+	resources := make([]types.MCPResource, 0)
+	for _, serverURL := range router.mcpServers() {
+		serverResources, err := router.mcpClient.ListResources(c.Request.Context(), serverURL)
+		if err != nil {
+			router.log(c).Error("failed to list resources of mcp server", err, "server", serverURL)
+			continue
+		}
+		for _, resource := range serverResources {
+			resources = append(resources, types.MCPResource{
+				URI:         resource.URI,
+				Name:        resource.Name,
+				Description: resource.Description,
+				MimeType:    resource.MimeType,
+				Server:      serverURL,
+			})
+		}
+	}
+
+	c.JSON(http.StatusOK, types.ListMCPResourcesResponse{Object: "list", Data: resources})
+}
+
+// ReadMCPResourceHandler implements GET /v1/mcp/resources/read. Without the
+// server query parameter the resource is read from the first server listing
+// it.
+func (router *RouterImpl) ReadMCPResourceHandler(c *gin.Context) {
+	if !router.mcpExposed(c) {
+		return
+	}
+
+	var params types.ReadMCPResourceParams
+	if err := c.ShouldBindQuery(&params); err != nil || params.URI == "" {
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "The uri query parameter is required")
+		return
+	}
+	if router.mcpClient == nil || !router.mcpClient.IsInitialized() {
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, fmt.Sprintf("MCP resource %s not found", params.URI))
+		return
+	}
+
+	ctx := c.Request.Context()
+	var serverURL string
+	if params.Server != nil && *params.Server != "" {
+		serverURL = *params.Server
+	} else {
+		var err error
+		if serverURL, err = mcp.FindResourceServer(ctx, router.mcpClient, params.URI); err != nil {
+			errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, fmt.Sprintf("MCP resource %s not found", params.URI))
+			return
+		}
+	}
+
+	result, err := router.mcpClient.ReadResource(ctx, serverURL, params.URI)
+	if err != nil {
+		if errors.Is(err, mcp.ErrServerNotFound) {
+			errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, fmt.Sprintf("MCP server %s not found", serverURL))
+			return
+		}
+		router.log(c).Error("failed to read mcp resource", err, "server", serverURL, "uri", params.URI)
+		errcodes.JSON(c, http.StatusBadGateway, errcodes.UpstreamError, "Failed to read MCP resource")
+		return
+	}
+
+	contents := make([]types.MCPResourceContents, 0, len(result.Contents))
+	for _, item := range result.Contents {
+		var content struct {
+			URI      string  `json:"uri"`
+			MimeType *string `json:"mimeType"`
+			Text     *string `json:"text"`
+			Blob     *string `json:"blob"`
+		}
+		data, err := item.MarshalJSON()
+		if err == nil {
+			err = json.Unmarshal(data, &content)
+		}
+		if err != nil {
+			router.log(c).Error("failed to decode mcp resource contents", err, "server", serverURL, "uri", params.URI)
+			continue
+		}
+		contents = append(contents, types.MCPResourceContents{
+			URI:      content.URI,
+			MimeType: content.MimeType,
+			Text:     content.Text,
+			Blob:     content.Blob,
+		})
+	}
+
+	c.JSON(http.StatusOK, types.ReadMCPResourceResponse{Contents: contents})
+}
+
+// ListMCPPromptsHandler implements GET /v1/mcp/prompts, listing the prompts
+// of every MCP server. Servers failing to list theirs, such as servers
+// without prompts, are left out.
+func (router *RouterImpl) ListMCPPromptsHandler(c *gin.Context) {
+	if !router.mcpExposed(c) {
+		return
+	}
+
+	prompts := make([]types.MCPPrompt, 0)
+	for _, serverURL := range router.mcpServers() {
+		serverPrompts, err := router.mcpClient.ListPrompts(c.Request.Context(), serverURL)
+		if err != nil {
+			router.log(c).Error("failed to list prompts of mcp server", err, "server", serverURL)
+			continue
+		}
+		for _, prompt := range serverPrompts {
+			p := types.MCPPrompt{
+				Name:        prompt.Name,
+				Description: prompt.Description,
+				Server:      serverURL,
+			}
+			if prompt.Arguments != nil {
+				arguments := make([]types.MCPPromptArgument, 0, len(*prompt.Arguments))
+				for _, argument := range *prompt.Arguments {
+					arguments = append(arguments, types.MCPPromptArgument{
+						Name:        argument.Name,
+						Description: argument.Description,
+						Required:    argument.Required,
+					})
+				}
+				p.Arguments = &arguments
+			}
+			prompts = append(prompts, p)
+		}
+	}
+
+	c.JSON(http.StatusOK, types.ListMCPPromptsResponse{Object: "list", Data: prompts})
+}
+
+// GetMCPPromptHandler implements POST /v1/mcp/prompts/:name, rendering the
+// prompt into the chat messages an mcp_prompt reference expands to
+func (router *RouterImpl) GetMCPPromptHandler(c *gin.Context) {
+	if !router.mcpExposed(c) {
+		return
+	}
+
+	var req types.GetMCPPromptRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Invalid request body")
+			return
+		}
+	}
+	var arguments map[string]string
+	if req.Arguments != nil {
+		arguments = *req.Arguments
+	}
+
+	name := c.Param("name")
+	if router.mcpClient == nil || !router.mcpClient.IsInitialized() {
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, fmt.Sprintf("MCP prompt %s not found", name))
+		return
+	}
+	result, err := mcp.RenderPrompt(c.Request.Context(), router.mcpClient, name, arguments)
+	if err != nil {
+		if errors.Is(err, mcp.ErrPromptNotFound) {
+			errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, fmt.Sprintf("MCP prompt %s not found", name))
+			return
+		}
+		router.log(c).Error("failed to render mcp prompt", err, "prompt", name)
+		errcodes.JSON(c, http.StatusBadGateway, errcodes.UpstreamError, "Failed to render MCP prompt")
+		return
+	}
+	messages, err := result.ChatMessages()
+	if err != nil {
+		router.log(c).Error("failed to convert mcp prompt messages", err, "prompt", name)
+		errcodes.JSON(c, http.StatusBadGateway, errcodes.UpstreamError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, types.GetMCPPromptResponse{Description: result.Description, Messages: messages})
+}
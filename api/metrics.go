@@ -9,6 +9,8 @@ import (
 	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
 )
 
 // maxMetricsBodyBytes caps the decoded OTLP push payload size.
@@ -23,14 +25,14 @@ const (
 // It lets clients that bypass the gateway's inference path (e.g. subscription
 // clients driving Claude Code directly) push their usage metrics.
 func (router *RouterImpl) MetricsIngestionHandler(c *gin.Context) {
-	if !router.cfg.Telemetry.Enable || !router.cfg.Telemetry.MetricsPushEnable {
-		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Metrics push is not enabled"})
+	if !router.cfg().Telemetry.Enable || !router.cfg().Telemetry.MetricsPushEnable {
+		errcodes.JSON(c, http.StatusForbidden, errcodes.FeatureDisabled, "Metrics push is not enabled")
 		return
 	}
 
 	contentType := c.ContentType()
 	if contentType != contentTypeProtobuf && contentType != contentTypeJSON {
-		c.JSON(http.StatusUnsupportedMediaType, ErrorResponse{Error: "Content-Type must be application/x-protobuf or application/json"})
+		errcodes.JSON(c, http.StatusUnsupportedMediaType, errcodes.UnsupportedMediaType, "Content-Type must be application/x-protobuf or application/json")
 		return
 	}
 
@@ -38,7 +40,7 @@ func (router *RouterImpl) MetricsIngestionHandler(c *gin.Context) {
 	if c.GetHeader("Content-Encoding") == "gzip" {
 		gz, err := gzip.NewReader(reader)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid gzip payload"})
+			errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Invalid gzip payload")
 			return
 		}
 		defer gz.Close()
@@ -47,11 +49,11 @@ func (router *RouterImpl) MetricsIngestionHandler(c *gin.Context) {
 
 	body, err := io.ReadAll(io.LimitReader(reader, maxMetricsBodyBytes+1))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Failed to read request body"})
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to read request body")
 		return
 	}
 	if len(body) > maxMetricsBodyBytes {
-		c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: "Payload exceeds 4 MiB limit"})
+		errcodes.JSON(c, http.StatusRequestEntityTooLarge, errcodes.RequestTooLarge, "Payload exceeds 4 MiB limit")
 		return
 	}
 
@@ -62,7 +64,7 @@ func (router *RouterImpl) MetricsIngestionHandler(c *gin.Context) {
 		err = protojson.Unmarshal(body, req)
 	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Failed to decode OTLP payload"})
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to decode OTLP payload")
 		return
 	}
 
@@ -76,14 +78,14 @@ func (router *RouterImpl) MetricsIngestionHandler(c *gin.Context) {
 		}
 	}
 
-	router.logger.Debug("otlp metrics push ingested",
+	router.log(c).Debug("otlp metrics push ingested",
 		"accepted_data_points", result.AcceptedDataPoints,
 		"rejected_data_points", result.RejectedDataPoints)
 
 	if contentType == contentTypeProtobuf {
 		payload, err := proto.Marshal(resp)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to encode response"})
+			errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to encode response")
 			return
 		}
 		c.Data(http.StatusOK, contentTypeProtobuf, payload)
@@ -92,7 +94,7 @@ func (router *RouterImpl) MetricsIngestionHandler(c *gin.Context) {
 
 	payload, err := protojson.Marshal(resp)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to encode response"})
+		errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to encode response")
 		return
 	}
 	c.Data(http.StatusOK, contentTypeJSON, payload)
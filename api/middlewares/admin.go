@@ -0,0 +1,56 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+// AdminTokenHeader carries the AUTH_ADMIN_TOKEN on /admin requests
+const AdminTokenHeader = "X-Admin-Token"
+
+// AdminPathPrefix prefixes the routes of the admin API
+const AdminPathPrefix = "/admin/"
+
+// AdminAuthenticator defines the interface for the admin API authentication middleware
+type AdminAuthenticator interface {
+	Middleware() gin.HandlerFunc
+}
+
+// AdminAuthenticatorImpl guards the admin API with a static token
+type AdminAuthenticatorImpl struct {
+	logger logger.Logger
+	token  []byte
+}
+
+// NewAdminAuthMiddleware creates a new admin authentication middleware instance.
+// It fails when AUTH_ADMIN_TOKEN is unset; callers only mount the admin API
+// when a token is configured.
+func NewAdminAuthMiddleware(logger logger.Logger, cfg config.Config) (AdminAuthenticator, error) {
+	if cfg.Auth == nil || cfg.Auth.AdminToken == "" {
+		return nil, errors.New("admin token is not configured")
+	}
+	return &AdminAuthenticatorImpl{
+		logger: logger,
+		token:  []byte(cfg.Auth.AdminToken),
+	}, nil
+}
+
+// Middleware returns the admin authentication middleware handler
+func (a *AdminAuthenticatorImpl) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := []byte(c.GetHeader(AdminTokenHeader))
+		if subtle.ConstantTimeCompare(provided, a.token) != 1 {
+			a.logger.Debug("rejected admin request", "path", c.Request.URL.Path)
+			errcodes.AbortJSON(c, http.StatusUnauthorized, errcodes.Unauthorized, "unauthorized: invalid admin token")
+			return
+		}
+		c.Next()
+	}
+}
@@ -1,12 +1,17 @@
 package middlewares
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"strings"
 
 	oidcV3 "github.com/coreos/go-oidc/v3/oidc"
 	gin "github.com/gin-gonic/gin"
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
 	config "github.com/inference-gateway/inference-gateway/config"
 	logger "github.com/inference-gateway/inference-gateway/logger"
 	types "github.com/inference-gateway/inference-gateway/providers/types"
@@ -17,10 +22,16 @@ type OIDCAuthenticator interface {
 }
 
 type OIDCAuthenticatorImpl struct {
-	logger   logger.Logger
-	verifier *oidcV3.IDTokenVerifier
+	logger         logger.Logger
+	verifier       *oidcV3.IDTokenVerifier
+	tokenCookie    string
+	tokenBodyField string
+	maxBodyBytes   int
 }
 
+// errMissingToken is returned by ExtractAuthToken when no token source is present
+var errMissingToken = errors.New("missing bearer token")
+
 type OIDCAuthenticatorNoop struct{}
 
 // NewOIDCAuthenticatorMiddleware creates a new OIDCAuthenticator instance
@@ -39,8 +50,11 @@ func NewOIDCAuthenticatorMiddleware(logger logger.Logger, cfg config.Config) (OI
 	}
 
 	return &OIDCAuthenticatorImpl{
-		logger:   logger,
-		verifier: provider.Verifier(oidcConfig),
+		logger:         logger,
+		verifier:       provider.Verifier(oidcConfig),
+		tokenCookie:    cfg.Auth.TokenCookie,
+		tokenBodyField: cfg.Auth.TokenBodyField,
+		maxBodyBytes:   cfg.Server.MaxRequestBytes,
 	}, nil
 }
 
@@ -54,29 +68,96 @@ func (a *OIDCAuthenticatorNoop) Middleware() gin.HandlerFunc {
 // Middleware implementation of the OIDCAuthenticator interface
 func (a *OIDCAuthenticatorImpl) Middleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if c.Request.URL.Path == "/health" {
+		if isPublicPath(c.Request.URL.Path) {
 			c.Next()
 			return
 		}
 
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		token, err := ExtractAuthToken(c, a.tokenCookie, a.tokenBodyField, a.maxBodyBytes)
+		if errors.Is(err, ErrRequestBodyTooLarge) {
+			errcodes.JSON(c, http.StatusRequestEntityTooLarge, errcodes.RequestTooLarge, "Request body too large")
+			c.Abort()
+			return
+		}
+		if err != nil {
+			a.logger.Debug("no usable bearer token on request", "error", err.Error())
+			errcodes.JSON(c, http.StatusUnauthorized, errcodes.Unauthorized, "unauthorized: "+err.Error())
 			c.Abort()
 			return
 		}
 
-		token := strings.TrimPrefix(authHeader, "Bearer ")
-		if _, err := a.verifier.Verify(c.Request.Context(), token); err != nil {
+		idToken, err := a.verifier.Verify(c.Request.Context(), token)
+		if err != nil {
 			a.logger.Error("failed to verify id token", err)
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			errcodes.JSON(c, http.StatusUnauthorized, errcodes.Unauthorized, "unauthorized: invalid or expired token")
+			c.Abort()
+			return
+		}
+
+		var claims map[string]any
+		if err := idToken.Claims(&claims); err != nil {
+			a.logger.Error("failed to decode id token claims", err)
+			errcodes.JSON(c, http.StatusUnauthorized, errcodes.Unauthorized, "unauthorized: invalid token claims")
 			c.Abort()
 			return
 		}
 
 		ctx := context.WithValue(c.Request.Context(), types.AuthTokenContextKey, token)
+		ctx = context.WithValue(ctx, types.AuthSubjectContextKey, idToken.Subject)
+		ctx = context.WithValue(ctx, types.AuthClaimsContextKey, claims)
 		c.Request = c.Request.WithContext(ctx)
 
 		c.Next()
 	}
 }
+
+// ExtractAuthToken returns the bearer token of the request. The Authorization
+// header wins; browser clients that cannot set it may instead send the token in
+// the cookie named cookieName or in the top-level JSON body field bodyField.
+// Empty names disable the respective fallback. A token read from the body is
+// removed from it so it is never forwarded upstream.
+func ExtractAuthToken(c *gin.Context, cookieName, bodyField string, maxBodyBytes int) (string, error) {
+	if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+		token := strings.TrimSpace(strings.TrimPrefix(authHeader, "Bearer "))
+		if token == "" {
+			return "", errMissingToken
+		}
+		return token, nil
+	}
+
+	if cookieName != "" {
+		if cookie, err := c.Request.Cookie(cookieName); err == nil && cookie.Value != "" {
+			return cookie.Value, nil
+		}
+	}
+
+	if bodyField == "" || c.Request.Body == nil || !strings.HasPrefix(c.ContentType(), "application/json") {
+		return "", errMissingToken
+	}
+
+	body, err := ReadBody(c.Request.Body, maxBodyBytes)
+	if err != nil {
+		return "", err
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var payload map[string]any
+	if err := dec.Decode(&payload); err != nil {
+		return "", errMissingToken
+	}
+	token, ok := payload[bodyField].(string)
+	if !ok || token == "" {
+		return "", errMissingToken
+	}
+
+	delete(payload, bodyField)
+	stripped, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(stripped))
+	c.Request.ContentLength = int64(len(stripped))
+	return token, nil
+}
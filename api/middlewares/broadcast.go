@@ -0,0 +1,124 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	gin "github.com/gin-gonic/gin"
+
+	broadcast "github.com/inference-gateway/inference-gateway/api/broadcast"
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+// StreamIDHeader carries the ID under which a streaming response is broadcast
+const StreamIDHeader = "X-Stream-Id"
+
+// StreamBroadcaster defines the interface for the stream fan-out middleware
+type StreamBroadcaster interface {
+	Middleware() gin.HandlerFunc
+}
+
+// StreamBroadcasterImpl publishes streaming chat responses to a broadcast hub
+type StreamBroadcasterImpl struct {
+	logger          logger.Logger
+	hub             *broadcast.Hub
+	maxRequestBytes int
+}
+
+// NewStreamBroadcasterMiddleware creates a new stream fan-out middleware
+// instance publishing to hub
+func NewStreamBroadcasterMiddleware(logger logger.Logger, cfg config.Config, hub *broadcast.Hub) (StreamBroadcaster, error) {
+	if hub == nil {
+		return nil, errors.New("broadcast hub is required")
+	}
+
+	var maxRequestBytes int
+	if cfg.Server != nil {
+		maxRequestBytes = cfg.Server.MaxRequestBytes
+	}
+
+	return &StreamBroadcasterImpl{
+		logger:          logger,
+		hub:             hub,
+		maxRequestBytes: maxRequestBytes,
+	}, nil
+}
+
+// Middleware returns the stream fan-out middleware handler. Streaming chat
+// requests are registered with the hub and their ID is returned in the
+// X-Stream-Id header; every chunk written to the client, including those of
+// MCP agent streams, is also published to the stream's subscribers.
+func (m *StreamBroadcasterImpl) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if path != ChatCompletionsPath && path != OllamaChatPath && path != MessagesPath {
+			c.Next()
+			return
+		}
+
+		body, err := ReadBody(c.Request.Body, m.maxRequestBytes)
+		if err != nil {
+			if errors.Is(err, ErrRequestBodyTooLarge) {
+				errcodes.AbortJSON(c, http.StatusRequestEntityTooLarge, errcodes.RequestTooLarge, "Request body too large")
+				return
+			}
+			m.logger.Error("failed to read request body", err)
+			errcodes.AbortJSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to read request")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		var req struct {
+			Stream *bool `json:"stream"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			c.Next()
+			return
+		}
+		// Ollama streams unless told otherwise
+		streaming := req.Stream != nil && *req.Stream || req.Stream == nil && path == OllamaChatPath
+		if !streaming {
+			c.Next()
+			return
+		}
+
+		stream, err := m.hub.Open(tenants.Owner(c.Request.Context(), c.Request.Header))
+		if err != nil {
+			m.logger.Error("failed to open broadcast stream", err)
+			c.Next()
+			return
+		}
+		defer stream.Close()
+
+		m.logger.Debug("broadcasting stream", "path", path, "stream_id", stream.ID())
+		c.Header(StreamIDHeader, stream.ID())
+		c.Writer = &broadcastWriter{ResponseWriter: c.Writer, stream: stream}
+		c.Next()
+	}
+}
+
+// broadcastWriter publishes everything written to the client to a stream
+type broadcastWriter struct {
+	gin.ResponseWriter
+	stream *broadcast.Stream
+}
+
+func (w *broadcastWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.stream.Publish(b[:n])
+	return n, err
+}
+
+func (w *broadcastWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *broadcastWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	gin "github.com/gin-gonic/gin"
+
+	config "github.com/inference-gateway/inference-gateway/config"
+)
+
+// supportedClientIPHeaders lists the headers SERVER_CLIENT_IP_HEADER accepts
+var supportedClientIPHeaders = []string{"X-Forwarded-For", "X-Real-IP", "CF-Connecting-IP"}
+
+// ConfigureClientIP applies SERVER_TRUSTED_PROXIES and SERVER_CLIENT_IP_HEADER to
+// the engine so that c.ClientIP() - used by request logging and anything keyed
+// on the caller - resolves the real client behind trusted ingress proxies only.
+// With no trusted proxies the connection peer address is always used.
+func ConfigureClientIP(r *gin.Engine, cfg config.Config) error {
+	header := http.CanonicalHeaderKey(strings.TrimSpace(cfg.Server.ClientIpHeader))
+	if header == "" {
+		header = "X-Forwarded-For"
+	}
+	supported := false
+	for _, h := range supportedClientIPHeaders {
+		if http.CanonicalHeaderKey(h) == header {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return fmt.Errorf("unsupported client ip header %q, expected one of %s", cfg.Server.ClientIpHeader, strings.Join(supportedClientIPHeaders, ", "))
+	}
+
+	var proxies []string
+	for entry := range strings.SplitSeq(cfg.Server.TrustedProxies, ",") {
+		if trimmed := strings.TrimSpace(entry); trimmed != "" {
+			proxies = append(proxies, trimmed)
+		}
+	}
+	if err := r.SetTrustedProxies(proxies); err != nil {
+		return fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+
+	r.ForwardedByClientIP = len(proxies) > 0
+	r.RemoteIPHeaders = []string{header}
+	return nil
+}
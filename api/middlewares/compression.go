@@ -0,0 +1,230 @@
+package middlewares
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	gin "github.com/gin-gonic/gin"
+
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+// StreamDeltaHeader opts a client into delta encoding of streaming chunks and
+// is echoed on responses that use it
+const StreamDeltaHeader = "X-Stream-Delta"
+
+// deltaFields are the chunk envelope fields that stay constant over a stream.
+// With delta encoding they are only sent when they change; fields missing
+// from a chunk keep the value of the previous chunk.
+var deltaFields = []string{"id", "object", "created", "model", "system_fingerprint", "service_tier", "provider"}
+
+// StreamCompression defines the interface for the streaming compression middleware
+type StreamCompression interface {
+	Middleware() gin.HandlerFunc
+}
+
+// StreamCompressionImpl compresses and delta-encodes streaming responses
+type StreamCompressionImpl struct {
+	logger logger.Logger
+}
+
+// NewStreamCompressionMiddleware creates a new streaming compression middleware instance
+func NewStreamCompressionMiddleware(logger logger.Logger, cfg config.Config) (StreamCompression, error) {
+	return &StreamCompressionImpl{logger: logger}, nil
+}
+
+// Middleware returns the streaming compression middleware handler. Only
+// text/event-stream and application/x-ndjson responses are affected; each
+// chunk is flushed through the compressor so tokens are not held back.
+func (m *StreamCompressionImpl) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		gzipAccepted := acceptsGzip(c.GetHeader("Accept-Encoding"))
+		delta := strings.EqualFold(c.GetHeader(StreamDeltaHeader), "true") || c.GetHeader(StreamDeltaHeader) == "1"
+		if !gzipAccepted && !delta {
+			c.Next()
+			return
+		}
+
+		w := &compressionWriter{ResponseWriter: c.Writer, gzipAccepted: gzipAccepted, delta: delta}
+		c.Writer = w
+		defer func() {
+			if err := w.close(); err != nil {
+				m.logger.Error("failed to finish compressed stream", err, "path", c.Request.URL.Path)
+			}
+		}()
+		c.Next()
+	}
+}
+
+func acceptsGzip(header string) bool {
+	for encoding := range strings.SplitSeq(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(encoding), ";")
+		if strings.EqualFold(strings.TrimSpace(name), "gzip") && strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
+
+// compressionWriter decides on the first write whether the response is a
+// stream, then delta-encodes its data lines and/or gzips it
+type compressionWriter struct {
+	gin.ResponseWriter
+	gzipAccepted bool
+	delta        bool
+
+	decided   bool
+	streaming bool
+	gz        *gzip.Writer
+	// pending holds an incomplete line awaiting the rest of its bytes
+	pending []byte
+	// previous holds the last sent value of every envelope field
+	previous map[string]json.RawMessage
+}
+
+func (w *compressionWriter) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+
+	contentType := w.Header().Get("Content-Type")
+	w.streaming = strings.HasPrefix(contentType, "text/event-stream") || strings.HasPrefix(contentType, "application/x-ndjson")
+	if !w.streaming {
+		return
+	}
+	if w.delta {
+		w.Header().Set(StreamDeltaHeader, "true")
+		w.previous = make(map[string]json.RawMessage)
+	}
+	if w.gzipAccepted {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Add("Vary", "Accept-Encoding")
+		w.Header().Del("Content-Length")
+		w.gz, _ = gzip.NewWriterLevel(w.ResponseWriter, gzip.BestSpeed)
+	}
+}
+
+func (w *compressionWriter) Write(b []byte) (int, error) {
+	w.decide()
+	if !w.streaming {
+		return w.ResponseWriter.Write(b)
+	}
+
+	if !w.delta {
+		if err := w.emit(b); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+
+	w.pending = append(w.pending, b...)
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			break
+		}
+		line := w.encodeDelta(w.pending[:i+1])
+		if err := w.emit(line); err != nil {
+			return 0, err
+		}
+		w.pending = w.pending[i+1:]
+	}
+	return len(b), nil
+}
+
+func (w *compressionWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// emit writes b to the client, through the compressor if enabled, flushing
+// it so the chunk is delivered immediately
+func (w *compressionWriter) emit(b []byte) error {
+	if w.gz == nil {
+		_, err := w.ResponseWriter.Write(b)
+		return err
+	}
+	if _, err := w.gz.Write(b); err != nil {
+		return err
+	}
+	return w.gz.Flush()
+}
+
+func (w *compressionWriter) Flush() {
+	w.decide()
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *compressionWriter) WriteHeaderNow() {
+	w.decide()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// encodeDelta drops the envelope fields of a JSON chunk line that are
+// unchanged since the previous chunk. Other lines are returned as-is.
+func (w *compressionWriter) encodeDelta(line []byte) []byte {
+	trimmed := bytes.TrimRight(line, "\r\n")
+	prefix := []byte("data: ")
+	data, sse := bytes.CutPrefix(trimmed, prefix)
+	if !sse {
+		prefix = nil
+	}
+	if !bytes.HasPrefix(data, []byte("{")) {
+		return line
+	}
+
+	var chunk map[string]json.RawMessage
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		return line
+	}
+	changed := false
+	for _, field := range deltaFields {
+		value, ok := chunk[field]
+		if !ok {
+			continue
+		}
+		if previous, seen := w.previous[field]; seen && bytes.Equal(previous, value) {
+			delete(chunk, field)
+			changed = true
+			continue
+		}
+		w.previous[field] = value
+	}
+	if !changed {
+		return line
+	}
+
+	encoded, err := json.Marshal(chunk)
+	if err != nil {
+		return line
+	}
+	out := append(append([]byte{}, prefix...), encoded...)
+	return append(out, line[len(trimmed):]...)
+}
+
+// close emits any incomplete trailing line and finishes the gzip stream
+func (w *compressionWriter) close() error {
+	if len(w.pending) > 0 {
+		if err := w.emit(w.pending); err != nil {
+			return err
+		}
+		w.pending = nil
+	}
+	if w.gz == nil {
+		return nil
+	}
+	err := w.gz.Close()
+	w.ResponseWriter.Flush()
+	return err
+}
+
+func (w *compressionWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,205 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+// DeduplicatedHeader is set on responses shared from an identical request
+// that was already in flight
+const DeduplicatedHeader = "X-Deduplicated"
+
+// dedupPaths lists the routes whose non-streaming requests are deduplicated
+var dedupPaths = map[string]struct{}{
+	ChatCompletionsPath: {},
+	MessagesPath:        {},
+}
+
+// Dedup defines the interface for the request deduplication middleware
+type Dedup interface {
+	Middleware() gin.HandlerFunc
+}
+
+// DedupImpl lets identical concurrent requests share one upstream call
+type DedupImpl struct {
+	logger          logger.Logger
+	maxRequestBytes int
+
+	mu       sync.Mutex
+	inflight map[string]*dedupCall
+}
+
+// dedupCall is a request in flight. The response is complete once done is
+// closed.
+type dedupCall struct {
+	done    chan struct{}
+	status  int
+	header  http.Header
+	body    []byte
+	waiters int
+}
+
+// NewDedupMiddleware creates a new request deduplication middleware instance
+func NewDedupMiddleware(logger logger.Logger, cfg config.Config) (Dedup, error) {
+	var maxRequestBytes int
+	if cfg.Server != nil {
+		maxRequestBytes = cfg.Server.MaxRequestBytes
+	}
+
+	return &DedupImpl{
+		logger:          logger,
+		maxRequestBytes: maxRequestBytes,
+		inflight:        make(map[string]*dedupCall),
+	}, nil
+}
+
+// Middleware returns the request deduplication middleware handler. The first
+// of several identical non-streaming requests is served as usual while its
+// response is recorded; the others wait for it and get a copy. Nothing is
+// cached: a request arriving after the first completed calls the provider
+// again.
+func (m *DedupImpl) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := dedupPaths[c.Request.URL.Path]; !ok || c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+
+		body, err := ReadBody(c.Request.Body, m.maxRequestBytes)
+		if err != nil {
+			if errors.Is(err, ErrRequestBodyTooLarge) {
+				errcodes.AbortJSON(c, http.StatusRequestEntityTooLarge, errcodes.RequestTooLarge, "Request body too large")
+				return
+			}
+			m.logger.Error("failed to read request body", err)
+			errcodes.AbortJSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to read request")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+
+		key, ok := dedupKey(c, body)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		m.mu.Lock()
+		if call, found := m.inflight[key]; found {
+			call.waiters++
+			m.mu.Unlock()
+			m.wait(c, call)
+			return
+		}
+		call := &dedupCall{done: make(chan struct{})}
+		m.inflight[key] = call
+		m.mu.Unlock()
+
+		w := &dedupWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		defer func() {
+			call.status = w.Status()
+			call.header = w.Header().Clone()
+			call.body = w.body.Bytes()
+
+			m.mu.Lock()
+			delete(m.inflight, key)
+			waiters := call.waiters
+			m.mu.Unlock()
+			close(call.done)
+			if waiters > 0 {
+				m.logger.Debug("shared response with identical requests", "path", c.Request.URL.Path, "waiters", waiters)
+			}
+		}()
+		c.Next()
+	}
+}
+
+// wait writes the response of call once it completes. Clients going away
+// while waiting get nothing.
+func (m *DedupImpl) wait(c *gin.Context, call *dedupCall) {
+	select {
+	case <-call.done:
+	case <-c.Request.Context().Done():
+		c.Abort()
+		return
+	}
+
+	header := c.Writer.Header()
+	for name, values := range call.header {
+		header[name] = values
+	}
+	header.Set(DeduplicatedHeader, "true")
+	c.Status(call.status)
+	if _, err := c.Writer.Write(call.body); err != nil {
+		m.logger.Error("failed to write shared response", err, "path", c.Request.URL.Path)
+	}
+	c.Abort()
+}
+
+// dedupKey returns the key of a request: a hash of everything that shapes its
+// response, i.e. the caller's credentials and tenant, the query and the body
+// with its keys in a stable order. Streaming requests are not deduplicated.
+func dedupKey(c *gin.Context, body []byte) (string, bool) {
+	normalized := body
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var req map[string]any
+	if err := dec.Decode(&req); err == nil {
+		if stream, _ := req["stream"].(bool); stream {
+			return "", false
+		}
+		// Maps are marshaled with sorted keys
+		if data, err := json.Marshal(req); err == nil {
+			normalized = data
+		}
+	}
+
+	h := sha256.New()
+	for _, part := range []string{
+		c.Request.URL.Path,
+		c.Request.URL.RawQuery,
+		c.GetHeader("Authorization"),
+		c.GetHeader("X-Api-Key"),
+		c.GetHeader("Cookie"),
+		c.GetHeader("X-MCP-Bypass"),
+		tenants.FromContext(c.Request.Context()),
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	h.Write(normalized)
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// dedupWriter records the response it writes through
+type dedupWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *dedupWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *dedupWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *dedupWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
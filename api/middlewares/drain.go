@@ -0,0 +1,94 @@
+package middlewares
+
+import (
+	"net/http"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	health "github.com/inference-gateway/inference-gateway/api/health"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+// Drain defines the interface for the shutdown draining middleware
+type Drain interface {
+	Middleware() gin.HandlerFunc
+}
+
+// DrainImpl tracks in-flight requests and event streams and rejects new
+// requests once the gateway is draining
+type DrainImpl struct {
+	logger logger.Logger
+	state  *health.State
+}
+
+// NewDrainMiddleware creates a new draining middleware instance
+func NewDrainMiddleware(logger logger.Logger, state *health.State) (Drain, error) {
+	return &DrainImpl{
+		logger: logger,
+		state:  state,
+	}, nil
+}
+
+// Middleware returns the draining middleware handler. Health probes are
+// always served so orchestrators can observe the drain.
+func (d *DrainImpl) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isHealthPath(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+		if !d.state.Begin(c.Request.Context()) {
+			d.logger.Debug("rejected request while draining", "path", c.Request.URL.Path)
+			c.Header("Connection", "close")
+			errcodes.AbortJSON(c, http.StatusServiceUnavailable, errcodes.GatewayDraining, "The gateway is shutting down; retry on another instance")
+			return
+		}
+		defer d.state.End()
+
+		w := &streamWatcher{ResponseWriter: c.Writer, state: d.state}
+		c.Writer = w
+		defer w.end()
+		c.Next()
+	}
+}
+
+// streamWatcher counts the response as an event stream in state from its
+// first write with an SSE or NDJSON Content-Type until the request ends
+type streamWatcher struct {
+	gin.ResponseWriter
+	state   *health.State
+	decided bool
+	stream  bool
+}
+
+func (w *streamWatcher) Write(b []byte) (int, error) {
+	w.observe()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *streamWatcher) WriteString(s string) (int, error) {
+	w.observe()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *streamWatcher) observe() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	if isStreamContentType(w.Header().Get("Content-Type")) {
+		w.stream = true
+		w.state.BeginStream()
+	}
+}
+
+func (w *streamWatcher) end() {
+	if w.stream {
+		w.state.EndStream()
+	}
+}
+
+func (w *streamWatcher) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,128 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	eval "github.com/inference-gateway/inference-gateway/api/eval"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	routing "github.com/inference-gateway/inference-gateway/providers/routing"
+)
+
+// Eval defines the interface for the response evaluation middleware
+type Eval interface {
+	Middleware() gin.HandlerFunc
+}
+
+// EvalImpl keeps a sample of the successful chat responses for evaluation
+type EvalImpl struct {
+	logger          logger.Logger
+	evaluator       *eval.Evaluator
+	maxRequestBytes int
+}
+
+// NewEvalMiddleware creates a new response evaluation middleware instance
+func NewEvalMiddleware(logger logger.Logger, cfg config.Config, evaluator *eval.Evaluator) (Eval, error) {
+	if evaluator == nil {
+		return nil, errors.New("evaluator is required")
+	}
+	var maxRequestBytes int
+	if cfg.Server != nil {
+		maxRequestBytes = cfg.Server.MaxRequestBytes
+	}
+
+	return &EvalImpl{
+		logger:          logger,
+		evaluator:       evaluator,
+		maxRequestBytes: maxRequestBytes,
+	}, nil
+}
+
+// maxEvalResponseBytes bounds the non-streaming responses kept for
+// evaluation; larger ones are not evaluated
+const maxEvalResponseBytes = 8 << 20
+
+// Middleware returns the response evaluation middleware handler. The
+// response of a sampled request is kept while it is written to the client,
+// streams as the evaluators read them, and evaluated in the background once
+// it is complete.
+func (m *EvalImpl) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.URL.Path != ChatCompletionsPath || c.Request.Method != http.MethodPost || eval.IsJudge(c.Request.Context()) || !m.evaluator.Sampled() {
+			c.Next()
+			return
+		}
+
+		body, err := ReadBody(c.Request.Body, m.maxRequestBytes)
+		if err != nil {
+			if errors.Is(err, ErrRequestBodyTooLarge) {
+				errcodes.AbortJSON(c, http.StatusRequestEntityTooLarge, errcodes.RequestTooLarge, "Request body too large")
+				return
+			}
+			m.logger.Error("failed to read request body", err)
+			errcodes.AbortJSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to read request")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+
+		var req struct {
+			Model  string `json:"model"`
+			Stream bool   `json:"stream"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil || req.Model == "" {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		var collector eval.StreamCollector
+		writer := &streamInterceptor{
+			ResponseWriter: c.Writer,
+			onData:         collector.Add,
+			limit:          maxEvalResponseBytes + 1,
+			stream:         req.Stream,
+		}
+		c.Writer = writer
+
+		c.Next()
+
+		c.Writer = writer.ResponseWriter
+		if c.Writer.Status() != http.StatusOK || writer.body.Len() > maxEvalResponseBytes {
+			return
+		}
+
+		// Scores are recorded under the same provider and model as the
+		// request metrics of the telemetry middleware
+		provider, model := c.Writer.Header().Get("X-Selected-Provider"), c.Writer.Header().Get("X-Selected-Model")
+		if provider == "" {
+			model = req.Model
+			if detected, _ := routing.DetermineProviderAndModelName(model); detected != nil {
+				provider = string(*detected)
+			} else if provider = c.Query("provider"); provider == "" {
+				return
+			}
+		}
+
+		sample := eval.Sample{
+			Provider: provider,
+			Model:    model,
+			Header:   c.Request.Header.Clone(),
+			Request:  body,
+			Response: writer.body.Bytes(),
+			Latency:  time.Since(start),
+		}
+		if writer.streamed() {
+			sample.Streamed = &collector
+		}
+		m.evaluator.Evaluate(sample)
+	}
+}
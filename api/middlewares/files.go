@@ -0,0 +1,186 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	files "github.com/inference-gateway/inference-gateway/api/files"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+// errFileReference is returned when a request references a file that cannot
+// be used as an image
+var errFileReference = errors.New("invalid file reference")
+
+// FileResolver defines the interface for the file reference middleware
+type FileResolver interface {
+	Middleware() gin.HandlerFunc
+}
+
+// FileResolverImpl inlines the uploaded files referenced by chat requests
+type FileResolverImpl struct {
+	logger          logger.Logger
+	store           files.Store
+	maxRequestBytes int
+}
+
+// NewFileResolverMiddleware creates a new file reference middleware instance
+// reading files from store
+func NewFileResolverMiddleware(logger logger.Logger, cfg config.Config, store files.Store) (FileResolver, error) {
+	if store == nil {
+		return nil, errors.New("file store is required")
+	}
+
+	var maxRequestBytes int
+	if cfg.Server != nil {
+		maxRequestBytes = cfg.Server.MaxRequestBytes
+	}
+
+	return &FileResolverImpl{
+		logger:          logger,
+		store:           store,
+		maxRequestBytes: maxRequestBytes,
+	}, nil
+}
+
+// Middleware returns the file reference middleware handler. Image parts of
+// chat messages may reference an uploaded file by ID, either as the url of an
+// image_url part or as the file_id of a file part; the file is inlined as a
+// data URL so providers receive a regular image_url part.
+func (m *FileResolverImpl) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.URL.Path != ChatCompletionsPath {
+			c.Next()
+			return
+		}
+
+		body, err := ReadBody(c.Request.Body, m.maxRequestBytes)
+		if err != nil {
+			if errors.Is(err, ErrRequestBodyTooLarge) {
+				errcodes.AbortJSON(c, http.StatusRequestEntityTooLarge, errcodes.RequestTooLarge, "Request body too large")
+				return
+			}
+			m.logger.Error("failed to read request body", err)
+			errcodes.AbortJSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to read request")
+			return
+		}
+
+		if bytes.Contains(body, []byte(`"file-`)) {
+			ctx := c.Request.Context()
+			resolved, n, err := m.resolve(ctx, tenants.Owner(ctx, c.Request.Header), body)
+			switch {
+			case errors.Is(err, errFileReference):
+				errcodes.AbortJSON(c, http.StatusBadRequest, errcodes.InvalidRequest, err.Error())
+				return
+			case err != nil:
+				m.logger.Error("failed to resolve file references", err)
+				errcodes.AbortJSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to read referenced files")
+				return
+			case n > 0:
+				m.logger.Debug("inlined referenced files", "count", n)
+				body = resolved
+			}
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+		c.Next()
+	}
+}
+
+// resolve replaces the file references of the messages in body, returning the
+// new body and the number of replaced parts. Only the files of owner are
+// read. Malformed bodies are returned unchanged for the handler to reject.
+func (m *FileResolverImpl) resolve(ctx context.Context, owner string, body []byte) ([]byte, int, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var req map[string]any
+	if err := dec.Decode(&req); err != nil {
+		return body, 0, nil
+	}
+	messages, _ := req["messages"].([]any)
+
+	n := 0
+	for _, msg := range messages {
+		msg, _ := msg.(map[string]any)
+		parts, _ := msg["content"].([]any)
+		for i, part := range parts {
+			part, _ := part.(map[string]any)
+			id := fileReference(part)
+			if id == "" {
+				continue
+			}
+			url, err := m.dataURL(ctx, id, owner)
+			if err != nil {
+				return nil, 0, err
+			}
+			image := map[string]any{"url": url}
+			if src, ok := part["image_url"].(map[string]any); ok && src["detail"] != nil {
+				image["detail"] = src["detail"]
+			}
+			parts[i] = map[string]any{"type": "image_url", "image_url": image}
+			n++
+		}
+	}
+	if n == 0 {
+		return body, 0, nil
+	}
+	resolved, err := json.Marshal(req)
+	return resolved, n, err
+}
+
+// dataURL reads file id of owner into a data URL, rejecting files that are
+// not images
+func (m *FileResolverImpl) dataURL(ctx context.Context, id, owner string) (string, error) {
+	file, err := files.GetOwned(ctx, m.store, id, owner)
+	if errors.Is(err, files.ErrNotFound) {
+		return "", fmt.Errorf("%w: file %s not found", errFileReference, id)
+	}
+	if err != nil {
+		return "", err
+	}
+	content, err := m.store.Open(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	defer content.Close()
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return "", err
+	}
+
+	mediaType := files.ContentType(file.Filename, data)
+	if !strings.HasPrefix(mediaType, "image/") {
+		return "", fmt.Errorf("%w: file %s is not an image", errFileReference, id)
+	}
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// fileReference returns the file ID a content part references, or ""
+func fileReference(part map[string]any) string {
+	switch part["type"] {
+	case "image_url":
+		image, _ := part["image_url"].(map[string]any)
+		url, _ := image["url"].(string)
+		if strings.HasPrefix(url, "file-") {
+			return url
+		}
+	case "file":
+		file, _ := part["file"].(map[string]any)
+		id, _ := file["file_id"].(string)
+		return id
+	}
+	return ""
+}
@@ -0,0 +1,203 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	hooks "github.com/inference-gateway/inference-gateway/api/hooks"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+// Hooks defines the interface for the request/response hooks middleware
+type Hooks interface {
+	Middleware() gin.HandlerFunc
+}
+
+// HooksImpl applies the hook chains from HOOKS_CONFIG_PATH to matching routes
+type HooksImpl struct {
+	logger          logger.Logger
+	pipeline        *hooks.Pipeline
+	maxRequestBytes int
+}
+
+// NoopHooksImpl is a no-op implementation of Hooks used when no hooks are configured
+type NoopHooksImpl struct{}
+
+// NewHooksMiddleware creates a new hooks middleware instance
+func NewHooksMiddleware(logger logger.Logger, cfg config.Config) (Hooks, error) {
+	if cfg.HooksConfigPath == "" {
+		return &NoopHooksImpl{}, nil
+	}
+
+	hooksCfg, err := hooks.LoadConfig(cfg.HooksConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	pipeline, err := hooks.NewPipeline(hooksCfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hooks config: %w", err)
+	}
+	logger.Info("request/response hooks enabled", "routes", strings.Join(pipeline.Routes(), ", "))
+
+	var maxRequestBytes int
+	if cfg.Server != nil {
+		maxRequestBytes = cfg.Server.MaxRequestBytes
+	}
+
+	return &HooksImpl{
+		logger:          logger,
+		pipeline:        pipeline,
+		maxRequestBytes: maxRequestBytes,
+	}, nil
+}
+
+// Middleware returns the no-op middleware handler
+func (n *NoopHooksImpl) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+	}
+}
+
+// Middleware returns the hooks middleware handler. Request hooks rewrite the
+// body before it reaches the route handler; response hooks rewrite JSON
+// responses. Streaming responses are passed through untouched.
+func (m *HooksImpl) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if !m.pipeline.Handles(path) {
+			c.Next()
+			return
+		}
+
+		body, err := ReadBody(c.Request.Body, m.maxRequestBytes)
+		if err != nil {
+			if errors.Is(err, ErrRequestBodyTooLarge) {
+				errcodes.AbortJSON(c, http.StatusRequestEntityTooLarge, errcodes.RequestTooLarge, "Request body too large")
+				return
+			}
+			m.logger.Error("failed to read request body", err)
+			errcodes.AbortJSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to read request")
+			return
+		}
+
+		if len(body) > 0 {
+			if !json.Valid(body) {
+				errcodes.AbortJSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Invalid request body")
+				return
+			}
+			body, err = m.pipeline.TransformRequest(c.Request.Context(), path, body)
+			if err != nil {
+				m.abortOnHookError(c, err, "request")
+				return
+			}
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+
+		writer := &hookResponseWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}, status: http.StatusOK}
+		c.Writer = writer
+
+		c.Next()
+
+		c.Writer = writer.ResponseWriter
+		if !writer.buffering {
+			return
+		}
+
+		payload := writer.body.Bytes()
+		if writer.status < http.StatusBadRequest && len(payload) > 0 {
+			transformed, err := m.pipeline.TransformResponse(c.Request.Context(), path, payload)
+			if err != nil {
+				c.Writer.Header().Del("Content-Length")
+				m.abortOnHookError(c, err, "response")
+				return
+			}
+			payload = transformed
+		}
+
+		c.Writer.Header().Del("Content-Length")
+		c.Writer.WriteHeader(writer.status)
+		if _, err := c.Writer.Write(payload); err != nil {
+			m.logger.Error("failed to write response", err)
+		}
+	}
+}
+
+func (m *HooksImpl) abortOnHookError(c *gin.Context, err error, stage string) {
+	if errors.Is(err, hooks.ErrRejected) {
+		m.logger.Debug("hook rejected "+stage, "path", c.Request.URL.Path, "error", err.Error())
+		errcodes.AbortJSON(c, http.StatusBadRequest, errcodes.HookRejected, err.Error())
+		return
+	}
+	m.logger.Error("failed to apply "+stage+" hooks", err, "path", c.Request.URL.Path)
+	errcodes.AbortJSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to apply "+stage+" hooks")
+}
+
+// hookResponseWriter buffers JSON responses so response hooks can rewrite them.
+// The decision is made on the first write from the Content-Type the handler
+// set; anything else (e.g. text/event-stream) is written straight through.
+type hookResponseWriter struct {
+	gin.ResponseWriter
+	body      *bytes.Buffer
+	status    int
+	decided   bool
+	buffering bool
+}
+
+func (w *hookResponseWriter) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	w.buffering = strings.HasPrefix(w.Header().Get("Content-Type"), "application/json")
+}
+
+// WriteHeader only records the status, like gin's own writer, since handlers set
+// it before the Content-Type the buffering decision depends on
+func (w *hookResponseWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *hookResponseWriter) WriteHeaderNow() {
+	w.decide()
+	if !w.buffering {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *hookResponseWriter) Write(b []byte) (int, error) {
+	w.decide()
+	if w.buffering {
+		return w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *hookResponseWriter) WriteString(s string) (int, error) {
+	w.decide()
+	if w.buffering {
+		return w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *hookResponseWriter) Flush() {
+	w.decide()
+	if !w.buffering {
+		w.ResponseWriter.Flush()
+	}
+}
+
+func (w *hookResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
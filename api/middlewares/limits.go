@@ -0,0 +1,341 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	config "github.com/inference-gateway/inference-gateway/config"
+	tokenizer "github.com/inference-gateway/inference-gateway/internal/tokenizer"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// ErrRequestBodyTooLarge is returned by ReadBody when the body exceeds the limit
+var ErrRequestBodyTooLarge = errors.New("request body too large")
+
+// LimitErrorResponse is the structured error returned when a request limit is exceeded
+type LimitErrorResponse struct {
+	errcodes.Response
+	Limit  string `json:"limit"`
+	Max    int    `json:"max"`
+	Actual int    `json:"actual,omitempty"`
+}
+
+// RequestLimits defines the interface for the request limits middleware
+type RequestLimits interface {
+	Middleware() gin.HandlerFunc
+	// Reload applies the SERVER_MAX_* limits of cfg to subsequent requests
+	Reload(cfg config.Config) error
+}
+
+// RequestLimitsImpl enforces the SERVER_MAX_* request limits
+type RequestLimitsImpl struct {
+	logger     logger.Logger
+	limits     atomic.Pointer[requestLimits]
+	tokenizers *tokenizer.Registry
+}
+
+type requestLimits struct {
+	maxRequestBytes int
+	// maxUploadBytes bounds file uploads in place of maxRequestBytes
+	maxUploadBytes  int
+	maxMessages     int
+	maxPromptChars  int
+	maxPromptTokens int
+	maxTokensLimits map[string]int
+	// proxyStrict extends the limits to chat completions sent through /proxy
+	proxyStrict bool
+}
+
+// NewRequestLimitsMiddleware creates a new request limits middleware instance
+func NewRequestLimitsMiddleware(logger logger.Logger, cfg config.Config) (RequestLimits, error) {
+	m := &RequestLimitsImpl{
+		logger:     logger,
+		tokenizers: tokenizer.NewRegistry(cfg.TokenizerEncodingsDir, logger),
+	}
+	if err := m.Reload(cfg); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Reload implements RequestLimits. Invalid limits leave the current ones in place.
+func (m *RequestLimitsImpl) Reload(cfg config.Config) error {
+	maxTokensLimits, err := ParseModelLimits(cfg.Server.MaxTokensLimits)
+	if err != nil {
+		return fmt.Errorf("invalid SERVER_MAX_TOKENS_LIMITS: %w", err)
+	}
+
+	m.limits.Store(&requestLimits{
+		maxRequestBytes: cfg.Server.MaxRequestBytes,
+		maxUploadBytes:  cfg.FilesMaxUploadBytes,
+		maxMessages:     cfg.Server.MaxMessages,
+		maxPromptChars:  cfg.Server.MaxPromptChars,
+		maxPromptTokens: cfg.Server.MaxPromptTokens,
+		maxTokensLimits: maxTokensLimits,
+		proxyStrict:     cfg.ProxyStrict,
+	})
+	return nil
+}
+
+// ParseModelLimits parses a comma-separated list of model=limit pairs into a
+// lowercase lookup map. The "*" key acts as the fallback for unlisted models.
+func ParseModelLimits(csv string) (map[string]int, error) {
+	limits := make(map[string]int)
+	for entry := range strings.SplitSeq(csv, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		model, rawLimit, ok := strings.Cut(entry, "=")
+		model = strings.TrimSpace(model)
+		if !ok || model == "" {
+			return nil, fmt.Errorf("expected model=limit, got %q", entry)
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(rawLimit))
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("invalid limit for model %q: %q", model, rawLimit)
+		}
+		limits[strings.ToLower(model)] = limit
+	}
+	return limits, nil
+}
+
+// lookupModelLimit resolves the limit for modelID, checking the full id, then
+// the provider-stripped model name, then the "*" fallback.
+func lookupModelLimit(limits map[string]int, modelID string) (int, bool) {
+	id := strings.ToLower(modelID)
+	if limit, ok := limits[id]; ok {
+		return limit, true
+	}
+	if _, name, ok := strings.Cut(id, "/"); ok {
+		if limit, ok := limits[name]; ok {
+			return limit, true
+		}
+	}
+	limit, ok := limits["*"]
+	return limit, ok
+}
+
+// ReadBody reads body in full, returning ErrRequestBodyTooLarge once more than
+// limit bytes have been read. A non-positive limit disables the check.
+func ReadBody(body io.Reader, limit int) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(body)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, int64(limit)+1))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, ErrRequestBodyTooLarge
+		}
+		return nil, err
+	}
+	if len(data) > limit {
+		return nil, ErrRequestBodyTooLarge
+	}
+	return data, nil
+}
+
+// Middleware returns the request limits middleware handler
+func (m *RequestLimitsImpl) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		l := m.limits.Load()
+		path := c.Request.URL.Path
+		maxBytes, limit := l.maxRequestBytes, "max_request_bytes"
+		if c.Request.Method == http.MethodPost && path == FilesPath {
+			// File uploads are bounded on their own, they are usually far
+			// larger than any chat request
+			maxBytes, limit = l.maxUploadBytes, "max_upload_bytes"
+		}
+		if maxBytes > 0 {
+			if c.Request.ContentLength > int64(maxBytes) {
+				m.abort(c, http.StatusRequestEntityTooLarge, "Request body too large", limit, maxBytes, int(c.Request.ContentLength))
+				return
+			}
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, int64(maxBytes))
+		}
+
+		if path != ChatCompletionsPath && path != OllamaChatPath && path != MessagesPath && (!l.proxyStrict || !IsProxyChatPath(path)) {
+			c.Next()
+			return
+		}
+
+		body, err := ReadBody(c.Request.Body, l.maxRequestBytes)
+		if err != nil {
+			if errors.Is(err, ErrRequestBodyTooLarge) {
+				m.abort(c, http.StatusRequestEntityTooLarge, "Request body too large", "max_request_bytes", l.maxRequestBytes, 0)
+				return
+			}
+			m.logger.Error("failed to read request body", err)
+			errcodes.AbortJSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to read request")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		if l.maxMessages <= 0 && l.maxPromptChars <= 0 && l.maxPromptTokens <= 0 && len(l.maxTokensLimits) == 0 {
+			c.Next()
+			return
+		}
+
+		req, err := decodeLimitedRequest(path, body)
+		if err != nil {
+			// Leave malformed payloads to the handler so clients get the usual decode error
+			c.Next()
+			return
+		}
+
+		if l.maxMessages > 0 && len(req.Messages) > l.maxMessages {
+			m.abort(c, http.StatusBadRequest, "Too many messages in request", "max_messages", l.maxMessages, len(req.Messages))
+			return
+		}
+
+		if l.maxPromptChars > 0 {
+			promptChars := 0
+			for i := range req.Messages {
+				promptChars += utf8.RuneCountInString(req.Messages[i].TextContent())
+			}
+			if promptChars > l.maxPromptChars {
+				m.abort(c, http.StatusBadRequest, "Prompt is too long", "max_prompt_chars", l.maxPromptChars, promptChars)
+				return
+			}
+		}
+
+		if l.maxPromptTokens > 0 {
+			promptTokens := tokenizer.CountMessages(m.tokenizers.ForModel(req.Model), req.Messages, req.Tools)
+			if promptTokens > l.maxPromptTokens {
+				m.abort(c, http.StatusBadRequest, "Prompt is too long", "max_prompt_tokens", l.maxPromptTokens, promptTokens)
+				return
+			}
+		}
+
+		if limit, ok := lookupModelLimit(l.maxTokensLimits, req.Model); ok {
+			for _, requested := range []*int{req.MaxTokens, req.MaxCompletionTokens} {
+				if requested != nil && *requested > limit {
+					m.abort(c, http.StatusBadRequest, fmt.Sprintf("max_tokens exceeds the limit for model %s", req.Model), "max_tokens", limit, *requested)
+					return
+				}
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// decodeLimitedRequest decodes the chat request body of path into a chat
+// completion request carrying what the limits apply to: the model, the
+// messages with their text, the tools and the requested max_tokens. Ollama
+// requests ask for num_predict tokens; Anthropic Messages requests count
+// their system prompt as a message, and only the text blocks of their
+// messages.
+func decodeLimitedRequest(path string, body []byte) (types.CreateChatCompletionRequest, error) {
+	var req types.CreateChatCompletionRequest
+	switch path {
+	case OllamaChatPath:
+		var ollama struct {
+			Model    string `json:"model"`
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+			Tools   *[]types.ChatCompletionTool `json:"tools"`
+			Options *struct {
+				NumPredict *int `json:"num_predict"`
+			} `json:"options"`
+		}
+		if err := json.Unmarshal(body, &ollama); err != nil {
+			return req, err
+		}
+		req.Model, req.Tools = ollama.Model, ollama.Tools
+		if ollama.Options != nil {
+			req.MaxTokens = ollama.Options.NumPredict
+		}
+		for _, msg := range ollama.Messages {
+			if err := appendTextMessage(&req, types.MessageRole(msg.Role), msg.Content); err != nil {
+				return req, err
+			}
+		}
+	case MessagesPath:
+		var anthropic struct {
+			Model    string          `json:"model"`
+			System   json.RawMessage `json:"system"`
+			Messages []struct {
+				Role    string          `json:"role"`
+				Content json.RawMessage `json:"content"`
+			} `json:"messages"`
+			MaxTokens *int `json:"max_tokens"`
+		}
+		if err := json.Unmarshal(body, &anthropic); err != nil {
+			return req, err
+		}
+		req.Model, req.MaxTokens = anthropic.Model, anthropic.MaxTokens
+		if len(anthropic.System) > 0 {
+			if err := appendTextMessage(&req, types.System, blocksText(anthropic.System)); err != nil {
+				return req, err
+			}
+		}
+		for _, msg := range anthropic.Messages {
+			if err := appendTextMessage(&req, types.MessageRole(msg.Role), blocksText(msg.Content)); err != nil {
+				return req, err
+			}
+		}
+	default:
+		err := json.Unmarshal(body, &req)
+		return req, err
+	}
+	return req, nil
+}
+
+// appendTextMessage appends a message of role with text content to req
+func appendTextMessage(req *types.CreateChatCompletionRequest, role types.MessageRole, text string) error {
+	msg := types.Message{Role: role}
+	if err := msg.Content.FromMessageContent0(text); err != nil {
+		return err
+	}
+	req.Messages = append(req.Messages, msg)
+	return nil
+}
+
+// blocksText returns the text of Anthropic content, a string or a list of
+// blocks whose text blocks are concatenated
+func blocksText(content json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(content, &text); err == nil {
+		return text
+	}
+	var blocks []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	_ = json.Unmarshal(content, &blocks)
+	var sb strings.Builder
+	for _, block := range blocks {
+		if block.Type == "text" {
+			sb.WriteString(block.Text)
+		}
+	}
+	return sb.String()
+}
+
+func (m *RequestLimitsImpl) abort(c *gin.Context, status int, message, limit string, maximum, actual int) {
+	m.logger.Warn("request limit exceeded", "path", c.Request.URL.Path, "limit", limit, "max", maximum, "actual", actual)
+	c.Set(errcodes.ContextKey, errcodes.RequestLimitExceeded.ID)
+	c.AbortWithStatusJSON(status, LimitErrorResponse{
+		Response: errcodes.RequestLimitExceeded.Response(message),
+		Limit:    limit,
+		Max:      maximum,
+		Actual:   actual,
+	})
+}
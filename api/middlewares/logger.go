@@ -1,13 +1,26 @@
 package middlewares
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"net/url"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/inference-gateway/inference-gateway/api/errcodes"
+	"github.com/inference-gateway/inference-gateway/api/tenants"
 	"github.com/inference-gateway/inference-gateway/logger"
+	"github.com/inference-gateway/inference-gateway/providers/types"
 )
 
+// RequestIDHeader carries the ID correlating the log lines of a request, and
+// its upstream calls, with the client
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the client-provided request IDs kept
+const maxRequestIDLength = 128
+
 type Logger interface {
 	Middleware() gin.HandlerFunc
 }
@@ -58,11 +71,53 @@ func sanitizeQuery(rawQuery string) map[string][]string {
 	return sanitized
 }
 
+// validRequestID reports whether a client-provided request ID is kept: at
+// most maxRequestIDLength letters, digits and -_.: characters, so it cannot
+// forge log lines or headers
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && !strings.ContainsRune("-_.:", r) {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return "req_" + hex.EncodeToString(b)
+}
+
+// Middleware logs the requests. Each request gets the ID of its
+// X-Request-ID header, or a new one, returned in the X-Request-ID response
+// header and sent upstream; logger.FromContext returns a logger tagging every
+// line with it as request_id.
 func (l LoggerImpl) Middleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		l.logger.Info("request received", "method", c.Request.Method, "host", c.Request.Host, "path", c.Request.URL.Path)
-		l.logger.Debug("request details", "query", sanitizeQuery(c.Request.URL.RawQuery), "headers", sanitizeHeaders(c.Request.Header))
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		c.Header(RequestIDHeader, requestID)
+		log := logger.With(l.logger, "request_id", requestID)
+		ctx := context.WithValue(c.Request.Context(), types.RequestIDContextKey, requestID)
+		c.Request = c.Request.WithContext(logger.WithContext(ctx, log))
+
+		log.Info("request received", "method", c.Request.Method, "host", c.Request.Host, "path", c.Request.URL.Path, "client_ip", c.ClientIP())
+		log.Debug("request details", "query", sanitizeQuery(c.Request.URL.RawQuery), "headers", sanitizeHeaders(c.Request.Header))
 
 		c.Next()
+
+		if code, ok := c.Get(errcodes.ContextKey); ok {
+			fields := []any{"method", c.Request.Method, "path", c.Request.URL.Path, "status", c.Writer.Status(), "error_code", code}
+			if tenant := tenants.FromContext(c.Request.Context()); tenant != "" {
+				fields = append(fields, "tenant", tenant)
+			}
+			log.Warn("request failed", fields...)
+		}
 	}
 }
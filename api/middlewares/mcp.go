@@ -2,16 +2,24 @@ package middlewares
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"sync/atomic"
 
 	gin "github.com/gin-gonic/gin"
 
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	modelpolicy "github.com/inference-gateway/inference-gateway/api/modelpolicy"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	toolfilter "github.com/inference-gateway/inference-gateway/api/toolfilter"
 	config "github.com/inference-gateway/inference-gateway/config"
 	mcp "github.com/inference-gateway/inference-gateway/internal/mcp"
+	supervisor "github.com/inference-gateway/inference-gateway/internal/supervisor"
 	logger "github.com/inference-gateway/inference-gateway/logger"
 	client "github.com/inference-gateway/inference-gateway/providers/client"
 	core "github.com/inference-gateway/inference-gateway/providers/core"
@@ -43,6 +51,8 @@ type MCPProviderModelResult struct {
 // MCPMiddleware defines the interface for MCP middleware
 type MCPMiddleware interface {
 	Middleware() gin.HandlerFunc
+	// Reload applies the model policy of cfg
+	Reload(cfg config.Config)
 }
 
 // MCPMiddlewareImpl implements the MCP middleware
@@ -53,6 +63,16 @@ type MCPMiddlewareImpl struct {
 	mcpAgent               mcp.Agent
 	logger                 logger.Logger
 	config                 config.Config
+	toolPaths              map[string]struct{}
+	// toolFilter selects the tools attached to a request, nil when
+	// MCP_TOOL_FILTER_ENABLE is false
+	toolFilter *toolfilter.Filter
+	// builtinTools are the tools built into the gateway, attached to every
+	// request along with the MCP tools
+	builtinTools []types.ChatCompletionTool
+	// policy decides which models callers may use, as it does for the chat
+	// completions the middleware does not run the agent loop of
+	policy atomic.Pointer[modelpolicy.Policy]
 }
 
 // NoopMCPMiddlewareImpl is a no-op implementation of MCPMiddleware
@@ -60,19 +80,65 @@ type NoopMCPMiddlewareImpl struct{}
 
 // NewMCPMiddleware creates a new MCP middleware instance
 func NewMCPMiddleware(providerRegistry registry.ProviderRegistry, inferenceGatewayClient client.Client, mcpClient mcp.MCPClientInterface, mcpAgent mcp.Agent, log logger.Logger, cfg config.Config) (MCPMiddleware, error) {
-	if mcpClient == nil {
+	return NewMCPMiddlewareWithBuiltinTools(providerRegistry, inferenceGatewayClient, mcpClient, mcpAgent, nil, log, cfg)
+}
+
+// NewMCPMiddlewareWithBuiltinTools creates an MCP middleware also attaching
+// builtinTools, run by mcpAgent, to requests. mcpClient may be nil when only
+// built-in tools are offered.
+func NewMCPMiddlewareWithBuiltinTools(providerRegistry registry.ProviderRegistry, inferenceGatewayClient client.Client, mcpClient mcp.MCPClientInterface, mcpAgent mcp.Agent, builtinTools []types.ChatCompletionTool, log logger.Logger, cfg config.Config) (MCPMiddleware, error) {
+	if mcpClient == nil && len(builtinTools) == 0 {
 		log.Info("mcp client is nil, using no-op middleware")
 		return &NoopMCPMiddlewareImpl{}, nil
 	}
 
-	return &MCPMiddlewareImpl{
+	var toolPaths string
+	var toolFilter *toolfilter.Filter
+	if cfg.MCP != nil {
+		toolPaths = cfg.MCP.ToolPaths
+		if cfg.MCP.ToolFilterEnable {
+			var err error
+			toolFilter, err = toolfilter.New(toolfilter.Options{
+				TopK:           cfg.MCP.ToolFilterTopK,
+				Method:         cfg.MCP.ToolFilterMethod,
+				EmbeddingModel: cfg.MCP.ToolFilterEmbeddingModel,
+			}, inferenceGatewayClient, nil)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	m := &MCPMiddlewareImpl{
 		registry:               providerRegistry,
 		inferenceGatewayClient: inferenceGatewayClient,
 		mcpClient:              mcpClient,
 		mcpAgent:               mcpAgent,
 		logger:                 log,
 		config:                 cfg,
-	}, nil
+		toolPaths:              ParseToolPaths(toolPaths),
+		toolFilter:             toolFilter,
+		builtinTools:           builtinTools,
+	}
+	m.Reload(cfg)
+	if m.policy.Load() == nil {
+		m.policy.Store(modelpolicy.New(cfg, nil))
+	}
+	return m, nil
+}
+
+// Reload applies the model policy of cfg. An invalid MODEL_POLICY_PATH file
+// keeps the policy loaded before.
+func (m *MCPMiddlewareImpl) Reload(cfg config.Config) {
+	var file *modelpolicy.Config
+	if cfg.ModelPolicyPath != "" {
+		var err error
+		if file, err = modelpolicy.Load(cfg.ModelPolicyPath); err != nil {
+			m.logger.Error("failed to load model policy, keeping the previous one", err, "path", cfg.ModelPolicyPath)
+			return
+		}
+	}
+	m.policy.Store(modelpolicy.New(cfg, file))
 }
 
 // Middleware returns the no-op middleware handler
@@ -82,83 +148,108 @@ func (n *NoopMCPMiddlewareImpl) Middleware() gin.HandlerFunc {
 	}
 }
 
+// Reload does nothing
+func (n *NoopMCPMiddlewareImpl) Reload(cfg config.Config) {}
+
 // Middleware returns the MCP middleware handler
+// log returns the logger of the request of c, tagging every line with the
+// request ID
+func (m *MCPMiddlewareImpl) log(c *gin.Context) logger.Logger {
+	return logger.FromContext(c.Request.Context(), m.logger)
+}
+
 func (m *MCPMiddlewareImpl) Middleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if c.GetHeader(MCPBypassHeader) != "" {
-			m.logger.Debug("skipping mcp middleware for internal call")
+			m.log(c).Debug("skipping mcp middleware for internal call")
 			c.Next()
 			return
 		}
 
-		if c.Request.URL.Path != ChatCompletionsPath {
+		if !IsToolPath(m.toolPaths, c.Request.URL.Path) {
 			c.Next()
 			return
 		}
 
-		m.logger.Debug("mcp middleware invoked", "path", c.Request.URL.Path)
+		m.log(c).Debug("mcp middleware invoked", "path", c.Request.URL.Path)
 		var originalRequestBody types.CreateChatCompletionRequest
 		if err := c.ShouldBindJSON(&originalRequestBody); err != nil {
-			m.logger.Error("failed to parse request body", err)
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			m.log(c).Error("failed to parse request body", err)
+			errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Invalid request body")
 			c.Abort()
 			return
 		}
 
-		if !m.mcpClient.IsInitialized() {
-			c.Next()
-			return
-		}
-
-		serverStatuses := m.mcpClient.GetAllServerStatuses()
-		hasAvailableServers := false
-		for _, status := range serverStatuses {
-			if status == mcp.ServerStatusAvailable {
-				hasAvailableServers = true
-				break
+		if originalRequestBody.MCPPrompt != nil {
+			if !m.expandPrompt(c, &originalRequestBody) {
+				return
 			}
+			c.Set(string(mcpBypassKey), &originalRequestBody)
 		}
 
-		if !hasAvailableServers {
-			m.logger.Debug("no mcp servers currently available, skipping mcp tool injection")
-			c.Next()
-			return
+		availableTools := m.mcpTools(c)
+		if len(availableTools) > 0 {
+			availableTools = m.filterTools(c, originalRequestBody.Messages, availableTools)
 		}
-
-		availableTools := m.mcpClient.GetAllChatCompletionTools()
+		availableTools = append(availableTools, m.builtinTools...)
 		if len(availableTools) == 0 {
 			c.Next()
 			return
 		}
-		m.logger.Debug("added mcp tools to request", "tool_count", len(availableTools))
+		m.log(c).Debug("added mcp tools to request", "tool_count", len(availableTools))
 		originalRequestBody.Tools = &availableTools
 
 		c.Set(string(mcpBypassKey), &originalRequestBody)
 
+		if !tenants.AllowsModel(c.Request.Context(), m.registry, originalRequestBody.Model) {
+			m.log(c).Error("model not allowed for tenant", nil, "model", originalRequestBody.Model, "tenant", tenants.FromContext(c.Request.Context()))
+			errcodes.AbortJSON(c, http.StatusForbidden, errcodes.TenantModelNotAllowed, "Model not allowed for this tenant. Please check the list of allowed models.")
+			return
+		}
+
 		result, err := m.getProviderAndModel(c, originalRequestBody.Model)
 		if err != nil {
 			if result == nil || result.ProviderID == nil {
-				m.logger.Error("failed to determine provider", err, "model", originalRequestBody.Model)
-				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unsupported model: %s", originalRequestBody.Model)})
+				m.log(c).Error("failed to determine provider", err, "model", originalRequestBody.Model)
+				errcodes.JSON(c, http.StatusBadRequest, errcodes.ProviderUnresolved, fmt.Sprintf("Unsupported model: %s", originalRequestBody.Model))
+				c.Abort()
+				return
+			}
+
+			if errors.Is(err, registry.ErrProviderDisabled) {
+				m.log(c).Warn("request for a disabled provider", "provider", *result.ProviderID)
+				errcodes.JSON(c, http.StatusServiceUnavailable, errcodes.ProviderDisabled, "Provider is disabled. Please use another provider.")
 				c.Abort()
 				return
 			}
 
 			if result.Provider == nil {
-				m.logger.Error("failed to get provider", err, "provider", *result.ProviderID)
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Provider not available"})
+				m.log(c).Error("failed to get provider", err, "provider", *result.ProviderID)
+				errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Provider not available")
 				c.Abort()
 				return
 			}
 		}
 
+		if code, message, ok := CheckModelPolicy(c.Request.Context(), m.policy.Load(), originalRequestBody.Model, *result.ProviderID, result.ProviderModel); !ok {
+			m.log(c).Error("model rejected by the model policy", nil, "model", originalRequestBody.Model, "code", code.Name)
+			errcodes.AbortJSON(c, http.StatusForbidden, code, message)
+			return
+		}
+
 		if originalRequestBody.Stream != nil && *originalRequestBody.Stream {
-			m.logger.Debug("starting mcp streaming mode")
+			m.log(c).Debug("starting mcp streaming mode")
 			SetSSEHeaders(c)
 
 			if err := m.handleMCPStreamingRequest(c, &originalRequestBody, result); err != nil {
-				m.logger.Error("failed to handle mcp streaming", err)
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "MCP streaming failed"})
+				if errors.Is(err, supervisor.ErrAtCapacity) {
+					m.log(c).Warn("rejected mcp streaming request, too many agent loops running")
+					c.Writer.Header().Del("Content-Type")
+					errcodes.AbortJSON(c, http.StatusServiceUnavailable, errcodes.GatewayAtCapacity, "Too many agent loops are running; retry later")
+					return
+				}
+				m.log(c).Error("failed to handle mcp streaming", err)
+				errcodes.JSON(c, http.StatusInternalServerError, errcodes.MCPToolsFailed, "MCP streaming failed")
 				c.Abort()
 				return
 			}
@@ -184,15 +275,30 @@ func (m *MCPMiddlewareImpl) Middleware() gin.HandlerFunc {
 
 		var response types.CreateChatCompletionResponse
 		if err := json.Unmarshal(customWriter.body.Bytes(), &response); err != nil {
-			m.logger.Error("failed to parse response body", err)
-			m.writeErrorResponse(c, customWriter, "Failed to parse response", http.StatusInternalServerError)
+			m.log(c).Error("failed to parse response body", err)
+			m.writeErrorResponse(c, customWriter, errcodes.InternalError, "Failed to parse response", http.StatusInternalServerError)
 			return
 		}
 
 		if len(response.Choices) > 0 && response.Choices[0].Message.ToolCalls != nil {
 			if err := m.handleMCPToolCalls(c, &response, &originalRequestBody, result); err != nil {
-				m.logger.Error("failed to handle mcp tool calls", err)
-				m.writeErrorResponse(c, customWriter, "Failed to execute MCP tools", http.StatusInternalServerError)
+				if errors.Is(err, supervisor.ErrAtCapacity) {
+					m.log(c).Warn("rejected mcp tool calls, too many agent loops running")
+					m.writeErrorResponse(c, customWriter, errcodes.GatewayAtCapacity, "Too many agent loops are running; retry later", http.StatusServiceUnavailable)
+					return
+				}
+				m.log(c).Error("failed to handle mcp tool calls", err)
+				var httpErr *core.HTTPError
+				if errors.As(err, &httpErr) {
+					// The provider failed on a follow-up turn of the agent loop
+					status, errorResponse := errcodes.ForProviderError(err)
+					errcodes.SetProviderHeaders(c, err)
+					c.Set(errcodes.ContextKey, errorResponse.Code)
+					customWriter.statusCode = status
+					m.writeResponse(c, customWriter, errorResponse)
+					return
+				}
+				m.writeErrorResponse(c, customWriter, errcodes.MCPToolsFailed, "Failed to execute MCP tools", http.StatusInternalServerError)
 				return
 			}
 		}
@@ -201,10 +307,94 @@ func (m *MCPMiddlewareImpl) Middleware() gin.HandlerFunc {
 	}
 }
 
+// mcpTools returns the tools of the available MCP servers, none when the
+// client is missing, not initialized or has no available server
+func (m *MCPMiddlewareImpl) mcpTools(c *gin.Context) []types.ChatCompletionTool {
+	if m.mcpClient == nil || !m.mcpClient.IsInitialized() {
+		return nil
+	}
+
+	hasAvailableServers := false
+	for _, status := range m.mcpClient.GetAllServerStatuses() {
+		if status == mcp.ServerStatusAvailable {
+			hasAvailableServers = true
+			break
+		}
+	}
+	if !hasAvailableServers {
+		m.log(c).Debug("no mcp servers currently available, skipping mcp tool injection")
+		return nil
+	}
+	return m.mcpClient.GetAllChatCompletionTools()
+}
+
+// expandPrompt renders the MCP prompt of the mcp_prompt field of request and
+// inserts its messages before the request's own. On failure it answers the
+// request and returns false.
+func (m *MCPMiddlewareImpl) expandPrompt(c *gin.Context, request *types.CreateChatCompletionRequest) bool {
+	ref := request.MCPPrompt
+	var arguments map[string]string
+	if ref.Arguments != nil {
+		arguments = *ref.Arguments
+	}
+
+	if m.mcpClient == nil {
+		errcodes.AbortJSON(c, http.StatusBadRequest, errcodes.MCPPromptFailed, fmt.Sprintf("Failed to expand MCP prompt %s: no MCP servers are configured", ref.Name))
+		return false
+	}
+
+	var messages []types.Message
+	result, err := mcp.RenderPrompt(c.Request.Context(), m.mcpClient, ref.Name, arguments)
+	if err == nil {
+		messages, err = result.ChatMessages()
+	}
+	if err != nil {
+		m.log(c).Error("failed to expand mcp prompt", err, "prompt", ref.Name)
+		errcodes.AbortJSON(c, http.StatusBadRequest, errcodes.MCPPromptFailed, fmt.Sprintf("Failed to expand MCP prompt %s: %s", ref.Name, err))
+		return false
+	}
+
+	m.log(c).Debug("expanded mcp prompt", "prompt", ref.Name, "messages", len(messages))
+	request.Messages = append(messages, request.Messages...)
+	request.MCPPrompt = nil
+	return true
+}
+
+// filterTools keeps the tools most relevant to the last user message of
+// messages, and those already called in them, when MCP_TOOL_FILTER_ENABLE is
+// true
+func (m *MCPMiddlewareImpl) filterTools(c *gin.Context, messages []types.Message, tools []types.ChatCompletionTool) []types.ChatCompletionTool {
+	if m.toolFilter == nil {
+		return tools
+	}
+
+	var prompt string
+	var called []string
+	for i := len(messages) - 1; i >= 0; i-- {
+		if prompt == "" && messages[i].Role == types.User {
+			prompt = messages[i].TextContent()
+		}
+		if messages[i].ToolCalls != nil {
+			for _, call := range *messages[i].ToolCalls {
+				called = append(called, call.Function.Name)
+			}
+		}
+	}
+
+	filtered, err := m.toolFilter.Select(c.Request.Context(), prompt, called, tools)
+	if err != nil {
+		m.log(c).Warn("failed to rank mcp tools by embedding, ranked by keywords", "error", err.Error())
+	}
+	if len(filtered) < len(tools) {
+		m.log(c).Debug("filtered mcp tools", "available", len(tools), "selected", len(filtered))
+	}
+	return filtered
+}
+
 // getProviderAndModel determines the provider and model from the request model string or query parameter
 func (m *MCPMiddlewareImpl) getProviderAndModel(c *gin.Context, model string) (*MCPProviderModelResult, error) {
 	if providerID := types.Provider(c.Query("provider")); providerID != "" {
-		provider, err := m.registry.BuildProvider(providerID, m.inferenceGatewayClient)
+		provider, err := tenants.Registry(c.Request.Context(), m.registry).BuildProvider(providerID, m.inferenceGatewayClient)
 		if err != nil {
 			return &MCPProviderModelResult{ProviderID: &providerID}, fmt.Errorf("failed to build provider: %w", err)
 		}
@@ -221,7 +411,7 @@ func (m *MCPMiddlewareImpl) getProviderAndModel(c *gin.Context, model string) (*
 		return &MCPProviderModelResult{ProviderID: nil}, fmt.Errorf("unable to determine provider for model: %s. Please specify a provider using the ?provider= query parameter or use the provider/model format", model)
 	}
 
-	provider, err := m.registry.BuildProvider(*providerPtr, m.inferenceGatewayClient)
+	provider, err := tenants.Registry(c.Request.Context(), m.registry).BuildProvider(*providerPtr, m.inferenceGatewayClient)
 	if err != nil {
 		return &MCPProviderModelResult{ProviderID: providerPtr}, fmt.Errorf("failed to build provider: %w", err)
 	}
@@ -241,61 +431,71 @@ func (m *MCPMiddlewareImpl) handleMCPStreamingRequest(c *gin.Context, request *t
 	processedChunk := make(chan []byte, 100)
 	errCh := make(chan error, 1)
 
-	go func() {
+	err := supervisor.Go(c.Request.Context(), supervisor.Agent, func(ctx context.Context) {
 		defer close(processedChunk)
-		err := m.mcpAgent.RunWithStream(c.Request.Context(), processedChunk, request)
+		err := m.mcpAgent.RunWithStream(ctx, processedChunk, request)
 		if err != nil {
-			m.logger.Error("mcp agent streaming failed", err)
+			m.log(c).Error("mcp agent streaming failed", err)
 			errCh <- err
 		}
-	}()
+	})
+	if err != nil {
+		return err
+	}
 
 	c.Stream(func(w io.Writer) bool {
 		select {
 		case line, ok := <-processedChunk:
 			if !ok {
-				m.logger.Debug("mcp agent stream channel closed unexpectedly")
+				m.log(c).Debug("mcp agent stream channel closed unexpectedly")
 				return false
 			}
 
 			ResetWriteDeadline(c, m.config.Server.WriteTimeout)
 
 			if bytes.Equal(line, []byte("data: [DONE]\n\n")) {
-				m.logger.Debug("mcp agent completed all iterations, sending [DONE]")
+				m.log(c).Debug("mcp agent completed all iterations, sending [DONE]")
 				_, err := w.Write(line)
 				if err != nil {
-					m.logger.Error("failed to write [DONE] to client", err)
+					m.log(c).Error("failed to write [DONE] to client", err)
 				}
 				return false
 			}
 
-			m.logger.Debug("processed chunk", "line", string(line))
+			m.log(c).Debug("processed chunk", "line", string(line))
 
 			if strings.HasPrefix(string(line), "data: {") && strings.Contains(string(line), "\"error\"") {
 				var errMsg struct {
 					Error string `json:"error"`
 				}
 				if err := json.Unmarshal(line[6:], &errMsg); err == nil {
-					m.logger.Error("upstream provider error", fmt.Errorf("%s", errMsg.Error))
+					m.log(c).Error("upstream provider error", fmt.Errorf("%s", errMsg.Error))
 					c.Writer.WriteHeader(http.StatusServiceUnavailable)
 				}
 			}
 
 			_, err := w.Write(line)
 			if err != nil {
-				m.logger.Error("failed to write line to client", err)
+				m.log(c).Error("failed to write line to client", err)
 				return false
 			}
 			return true
 		case err := <-errCh:
-			m.logger.Error("mcp agent streaming error", err)
+			m.log(c).Error("mcp agent streaming error", err)
 			c.Writer.WriteHeader(http.StatusServiceUnavailable)
-			if _, writeErr := fmt.Fprintf(w, "data: {\"error\": \"%s\"}\n\n", err.Error()); writeErr != nil {
-				m.logger.Error("failed to write error to stream", writeErr)
+			_, resp := errcodes.ForProviderError(err)
+			c.Set(errcodes.ContextKey, resp.Code)
+			data, marshalErr := json.Marshal(resp)
+			if marshalErr != nil {
+				m.log(c).Error("failed to encode stream error", marshalErr)
+				return false
+			}
+			if _, writeErr := fmt.Fprintf(w, "data: %s\n\n", data); writeErr != nil {
+				m.log(c).Error("failed to write error to stream", writeErr)
 			}
 			return false
 		case <-c.Request.Context().Done():
-			m.logger.Debug("request context done, stopping stream")
+			m.log(c).Debug("request context done, stopping stream")
 			return false
 		}
 	})
@@ -307,17 +507,24 @@ func (m *MCPMiddlewareImpl) handleMCPToolCalls(c *gin.Context, response *types.C
 	m.mcpAgent.SetProvider(result.Provider)
 	m.mcpAgent.SetModel(&result.ProviderModel)
 
+	done, err := supervisor.Track(c.Request.Context(), supervisor.Agent)
+	if err != nil {
+		return err
+	}
+	defer done()
+
 	if err := m.mcpAgent.Run(c.Request.Context(), originalRequest, response); err != nil {
 		return fmt.Errorf("mcp agent processing failed: %w", err)
 	}
 
-	m.logger.Debug("mcp agent processing completed successfully")
+	m.log(c).Debug("mcp agent processing completed successfully")
 	return nil
 }
 
 // writeErrorResponse writes an error response to the client
-func (m *MCPMiddlewareImpl) writeErrorResponse(c *gin.Context, customWriter *customResponseWriter, message string, statusCode int) {
-	errorResponse := map[string]string{"error": message}
+func (m *MCPMiddlewareImpl) writeErrorResponse(c *gin.Context, customWriter *customResponseWriter, code errcodes.Code, message string, statusCode int) {
+	c.Set(errcodes.ContextKey, code.ID)
+	errorResponse := code.Response(message)
 	customWriter.statusCode = statusCode
 	m.writeResponse(c, customWriter, errorResponse)
 }
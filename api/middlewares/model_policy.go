@@ -0,0 +1,22 @@
+package middlewares
+
+import (
+	"context"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	modelpolicy "github.com/inference-gateway/inference-gateway/api/modelpolicy"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// CheckModelPolicy decides whether policy lets the caller of ctx use model,
+// which resolved to providerModel of providerID. When it does not, it returns
+// the error code and message to answer with.
+func CheckModelPolicy(ctx context.Context, policy *modelpolicy.Policy, model string, providerID types.Provider, providerModel string) (errcodes.Code, string, bool) {
+	switch policy.Check(ctx, model, string(providerID)+"/"+providerModel) {
+	case modelpolicy.NotAllowed:
+		return errcodes.ModelNotAllowed, "Model not allowed. Please check the list of allowed models.", false
+	case modelpolicy.Disallowed:
+		return errcodes.ModelDisallowed, "Model is disallowed. Please use a different model.", false
+	}
+	return errcodes.Code{}, "", true
+}
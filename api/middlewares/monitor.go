@@ -0,0 +1,133 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+
+	admin "github.com/inference-gateway/inference-gateway/api/admin"
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+// maxErrorBodyBytes caps how much of an error response is kept to extract its
+// message
+const maxErrorBodyBytes = 4096
+
+// AdminMonitor defines the interface for the middleware feeding the admin API
+type AdminMonitor interface {
+	Middleware() gin.HandlerFunc
+}
+
+// AdminMonitorImpl counts the streaming responses in progress and records
+// failed requests for the admin API
+type AdminMonitorImpl struct {
+	logger  logger.Logger
+	monitor *admin.Monitor
+}
+
+// NewAdminMonitorMiddleware creates a new admin monitor middleware instance
+func NewAdminMonitorMiddleware(logger logger.Logger, monitor *admin.Monitor) (AdminMonitor, error) {
+	if monitor == nil {
+		return nil, errors.New("admin monitor is required")
+	}
+	return &AdminMonitorImpl{
+		logger:  logger,
+		monitor: monitor,
+	}, nil
+}
+
+// Middleware returns the admin monitor middleware handler. A response counts
+// as a stream once it starts writing an SSE or NDJSON body. Health probes are
+// not monitored, so failing readiness probes do not flood the recent errors.
+func (m *AdminMonitorImpl) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isHealthPath(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		w := &monitorWriter{ResponseWriter: c.Writer, monitor: m.monitor}
+		c.Writer = w
+		defer w.end()
+		c.Next()
+
+		status := w.Status()
+		if status < http.StatusBadRequest {
+			return
+		}
+		m.monitor.RecordError(admin.ErrorRecord{
+			Time:    time.Now(),
+			Method:  c.Request.Method,
+			Path:    c.Request.URL.Path,
+			Status:  status,
+			Code:    c.GetString(errcodes.ContextKey),
+			Message: errorMessage(w.body.Bytes()),
+			Tenant:  tenants.FromContext(c.Request.Context()),
+		})
+	}
+}
+
+// monitorWriter detects streaming responses and keeps the start of error
+// bodies
+type monitorWriter struct {
+	gin.ResponseWriter
+	monitor *admin.Monitor
+	// endStream records the end of the stream, nil until a stream started
+	endStream func()
+	body      bytes.Buffer
+}
+
+func (w *monitorWriter) Write(b []byte) (int, error) {
+	w.observe(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *monitorWriter) WriteString(s string) (int, error) {
+	w.observe([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *monitorWriter) observe(b []byte) {
+	if w.endStream == nil && isStreamContentType(w.Header().Get("Content-Type")) {
+		w.endStream = w.monitor.StreamStarted()
+	}
+	if w.endStream == nil && w.Status() >= http.StatusBadRequest && w.body.Len() < maxErrorBodyBytes {
+		w.body.Write(b[:min(len(b), maxErrorBodyBytes-w.body.Len())])
+	}
+}
+
+func (w *monitorWriter) end() {
+	if w.endStream != nil {
+		w.endStream()
+	}
+}
+
+func isStreamContentType(contentType string) bool {
+	return strings.HasPrefix(contentType, "text/event-stream") || strings.HasPrefix(contentType, "application/x-ndjson")
+}
+
+// errorMessage extracts the message of a gateway or OpenAI-style error body
+func errorMessage(body []byte) string {
+	var gateway struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(body, &gateway) != nil || len(gateway.Error) == 0 {
+		return ""
+	}
+	var message string
+	if json.Unmarshal(gateway.Error, &message) == nil {
+		return message
+	}
+	var upstream struct {
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(gateway.Error, &upstream)
+	return upstream.Message
+}
@@ -0,0 +1,113 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	prompts "github.com/inference-gateway/inference-gateway/api/prompts"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// PromptInjector defines the interface for the system prompt injection middleware
+type PromptInjector interface {
+	Middleware() gin.HandlerFunc
+}
+
+// PromptInjectorImpl injects the matching prompt templates into chat requests
+type PromptInjectorImpl struct {
+	logger          logger.Logger
+	store           *prompts.Store
+	maxRequestBytes int
+}
+
+// NewPromptInjectorMiddleware creates a new prompt injection middleware instance
+// backed by store, which the admin API may mutate concurrently
+func NewPromptInjectorMiddleware(logger logger.Logger, cfg config.Config, store *prompts.Store) (PromptInjector, error) {
+	if store == nil {
+		return nil, errors.New("prompt store is required")
+	}
+
+	var maxRequestBytes int
+	if cfg.Server != nil {
+		maxRequestBytes = cfg.Server.MaxRequestBytes
+	}
+
+	return &PromptInjectorImpl{
+		logger:          logger,
+		store:           store,
+		maxRequestBytes: maxRequestBytes,
+	}, nil
+}
+
+// Middleware returns the prompt injection middleware handler. Templates are
+// injected server-side, so clients cannot opt out of them.
+func (m *PromptInjectorImpl) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if path != ChatCompletionsPath && path != OllamaChatPath && path != MessagesPath {
+			c.Next()
+			return
+		}
+		if m.store.Len() == 0 {
+			c.Next()
+			return
+		}
+
+		body, err := ReadBody(c.Request.Body, m.maxRequestBytes)
+		if err != nil {
+			if errors.Is(err, ErrRequestBodyTooLarge) {
+				errcodes.AbortJSON(c, http.StatusRequestEntityTooLarge, errcodes.RequestTooLarge, "Request body too large")
+				return
+			}
+			m.logger.Error("failed to read request body", err)
+			errcodes.AbortJSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to read request")
+			return
+		}
+
+		req, err := prompts.DecodeRequest(body)
+		if err != nil {
+			errcodes.AbortJSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Invalid request body")
+			return
+		}
+
+		subject, _ := c.Request.Context().Value(types.AuthSubjectContextKey).(string)
+		rendered, err := m.store.Render(prompts.Data{
+			Model:    req.Model(),
+			Subject:  subject,
+			User:     req.User(),
+			Header:   c.Request.Header,
+			Metadata: req.Metadata(),
+		})
+		if err != nil {
+			m.logger.Error("failed to render prompt templates", err, "model", req.Model())
+			errcodes.AbortJSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to apply prompt templates")
+			return
+		}
+
+		if len(rendered) > 0 {
+			if path == MessagesPath {
+				req.InjectSystem(rendered)
+			} else {
+				req.InjectMessages(rendered)
+			}
+			if body, err = json.Marshal(req); err != nil {
+				m.logger.Error("failed to encode request body", err)
+				errcodes.AbortJSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to apply prompt templates")
+				return
+			}
+			m.logger.Debug("injected prompt templates", "path", path, "model", req.Model(), "count", len(rendered))
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+		c.Next()
+	}
+}
@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ProxyPathPrefix is the path prefix of the provider passthrough route
+const ProxyPathPrefix = "/proxy/"
+
+// IsProxyChatPath reports whether path is a chat completions request sent
+// through the /proxy route, such as /proxy/openai/v1/chat/completions
+func IsProxyChatPath(path string) bool {
+	return strings.HasPrefix(path, ProxyPathPrefix) && strings.HasSuffix(normalizePath(path), "/chat/completions")
+}
+
+// ParseProxyPaths parses PROXY_ALLOWED_PATHS, a comma-separated list of
+// patterns of the provider/path of proxied requests
+func ParseProxyPaths(csv string) ([]string, error) {
+	var patterns []string
+	for entry := range strings.SplitSeq(csv, ",") {
+		pattern := strings.Trim(strings.TrimSpace(entry), "/")
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid PROXY_ALLOWED_PATHS pattern %q", entry)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// MatchProxyPath reports whether providerPath, the provider ID followed by
+// the upstream path (openai/v1/chat/completions), matches one of patterns.
+// Unlike path.Match, * also spans slashes.
+func MatchProxyPath(patterns []string, providerPath string) bool {
+	providerPath = strings.ReplaceAll(strings.Trim(providerPath, "/"), "/", "\x00")
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ReplaceAll(pattern, "/", "\x00"), providerPath); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	queue "github.com/inference-gateway/inference-gateway/api/queue"
+	shadow "github.com/inference-gateway/inference-gateway/api/shadow"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+// QueuePath is the route polling a queued request
+const QueuePath = "/v1/queue/"
+
+// RequestQueue defines the interface for the request queue middleware
+type RequestQueue interface {
+	Middleware() gin.HandlerFunc
+}
+
+// RequestQueueImpl queues chat requests a saturated provider rejected
+type RequestQueueImpl struct {
+	logger          logger.Logger
+	queue           *queue.Queue
+	waitTimeout     time.Duration
+	maxRequestBytes int
+}
+
+// NewRequestQueueMiddleware creates a new request queue middleware instance
+func NewRequestQueueMiddleware(logger logger.Logger, cfg config.Config, q *queue.Queue) (RequestQueue, error) {
+	if q == nil {
+		return nil, errors.New("request queue is required")
+	}
+	var maxRequestBytes int
+	if cfg.Server != nil {
+		maxRequestBytes = cfg.Server.MaxRequestBytes
+	}
+
+	return &RequestQueueImpl{
+		logger:          logger,
+		queue:           q,
+		waitTimeout:     cfg.QueueWaitTimeout,
+		maxRequestBytes: maxRequestBytes,
+	}, nil
+}
+
+// Middleware returns the request queue middleware handler. Non-streaming chat
+// requests are served as usual with their response held back; when the
+// provider rejected the request as saturated it is queued instead. Clients
+// sending Prefer: respond-async get 202 with a polling URL right away, others
+// wait up to QUEUE_WAIT_TIMEOUT for the result before getting the 202.
+func (m *RequestQueueImpl) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.URL.Path != ChatCompletionsPath || c.Request.Method != http.MethodPost ||
+			queue.IsDispatch(c.Request.Context()) || shadow.IsShadow(c.Request.Context()) {
+			c.Next()
+			return
+		}
+
+		body, err := ReadBody(c.Request.Body, m.maxRequestBytes)
+		if err != nil {
+			if errors.Is(err, ErrRequestBodyTooLarge) {
+				errcodes.AbortJSON(c, http.StatusRequestEntityTooLarge, errcodes.RequestTooLarge, "Request body too large")
+				return
+			}
+			m.logger.Error("failed to read request body", err)
+			errcodes.AbortJSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to read request")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+
+		var req struct {
+			Stream bool `json:"stream"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil || req.Stream {
+			c.Next()
+			return
+		}
+
+		writer := &customResponseWriter{
+			ResponseWriter: c.Writer,
+			body:           &bytes.Buffer{},
+			statusCode:     http.StatusOK,
+			writeToClient:  false,
+		}
+		c.Writer = writer
+
+		c.Next()
+
+		c.Writer = writer.ResponseWriter
+		if !queue.Saturated(writer.statusCode, c.GetString(errcodes.ContextKey)) {
+			m.flush(c, writer.statusCode, writer.body.Bytes())
+			return
+		}
+
+		item, err := m.queue.Enqueue(c.Request.Context(), c.Request.Header, c.Request.URL.RawQuery, body)
+		if err != nil {
+			if !errors.Is(err, queue.ErrFull) {
+				m.logger.Error("failed to queue request", err)
+			}
+			m.flush(c, writer.statusCode, writer.body.Bytes())
+			return
+		}
+		m.logger.Debug("provider saturated, request queued", "id", item.ID, "priority", item.Priority, "status", writer.statusCode)
+
+		// Batch jobs hold for the result since they have nobody to poll for it
+		ctx := c.Request.Context()
+		hold := queue.Holds(ctx)
+		if hold || !strings.Contains(strings.ToLower(c.GetHeader("Prefer")), "respond-async") {
+			if !hold {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, m.waitTimeout)
+				defer cancel()
+			}
+			result, done := m.queue.Wait(ctx, item.ID)
+			if done {
+				if result.Response.StatusCode < http.StatusBadRequest {
+					c.Delete(errcodes.ContextKey)
+				}
+				m.flush(c, result.Response.StatusCode, result.Response.Body)
+				return
+			}
+			item = result
+		}
+
+		c.Delete(errcodes.ContextKey)
+		header := c.Writer.Header()
+		header.Del("Content-Length")
+		header.Del("Retry-After")
+		header.Set("Location", QueuePath+item.ID)
+		c.JSON(http.StatusAccepted, item)
+	}
+}
+
+// flush writes the held back response
+func (m *RequestQueueImpl) flush(c *gin.Context, status int, body []byte) {
+	c.Writer.Header().Del("Content-Length")
+	c.Writer.WriteHeader(status)
+	if _, err := c.Writer.Write(body); err != nil {
+		m.logger.Error("failed to write response", err)
+	}
+}
@@ -0,0 +1,79 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	modelpolicy "github.com/inference-gateway/inference-gateway/api/modelpolicy"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	config "github.com/inference-gateway/inference-gateway/config"
+	mcp "github.com/inference-gateway/inference-gateway/internal/mcp"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	client "github.com/inference-gateway/inference-gateway/providers/client"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	routing "github.com/inference-gateway/inference-gateway/providers/routing"
+)
+
+// errNoSamplingModel is returned to MCP servers when neither their model
+// hints nor MCP_SAMPLING_MODEL name a model they may use
+var errNoSamplingModel = errors.New("no allowed model for the sampling request: hint a provider/model or set MCP_SAMPLING_MODEL")
+
+// NewMCPSampler returns the sampler serving the sampling requests of MCP
+// servers with the providers of providerRegistry. The model is the first
+// hinted provider/model that is allowed, else MCP_SAMPLING_MODEL; the model
+// policy and tenant policies apply as they do to chat completions, the caller
+// and tenant being those of the request calling the tool.
+func NewMCPSampler(providerRegistry registry.ProviderRegistry, inferenceGatewayClient client.Client, policy *modelpolicy.Policy, log logger.Logger, cfg config.Config) mcp.Sampler {
+	return func(ctx context.Context, serverURL string, params mcp.CreateMessageRequestParams) (*mcp.CreateMessageResult, error) {
+		model, err := samplingModel(ctx, providerRegistry, policy, cfg, params)
+		if err != nil {
+			log.Warn("refused mcp sampling request", "server", serverURL, "error", err.Error(), "component", "mcp_sampling")
+			return nil, err
+		}
+
+		providerID, providerModel := routing.DetermineProviderAndModelName(model)
+		provider, err := tenants.Registry(ctx, providerRegistry).BuildProvider(*providerID, inferenceGatewayClient)
+		if err != nil {
+			log.Error("failed to build provider for mcp sampling request", err, "server", serverURL, "model", model, "component", "mcp_sampling")
+			return nil, fmt.Errorf("provider %s is not available", *providerID)
+		}
+		req, err := params.ChatRequest(providerModel)
+		if err != nil {
+			return nil, err
+		}
+
+		response, err := provider.ChatCompletions(ctx, req)
+		if err != nil {
+			log.Error("mcp sampling request failed", err, "server", serverURL, "model", model, "component", "mcp_sampling")
+			return nil, fmt.Errorf("completion failed with %s", model)
+		}
+		log.Debug("served mcp sampling request", "server", serverURL, "model", model, "component", "mcp_sampling")
+		return mcp.SamplingResult(response, model)
+	}
+}
+
+// samplingModel returns the provider/model serving a sampling request: the
+// first of the model hints and MCP_SAMPLING_MODEL with a known provider that
+// the model policy and the tenant of ctx allow
+func samplingModel(ctx context.Context, providerRegistry registry.ProviderRegistry, policy *modelpolicy.Policy, cfg config.Config, params mcp.CreateMessageRequestParams) (string, error) {
+	candidates := params.ModelHints()
+	if cfg.MCP != nil && cfg.MCP.SamplingModel != "" {
+		candidates = append(candidates, cfg.MCP.SamplingModel)
+	}
+
+	for _, model := range candidates {
+		providerID, providerModel := routing.DetermineProviderAndModelName(model)
+		if providerID == nil {
+			continue
+		}
+		if policy.Check(ctx, model, string(*providerID)+"/"+providerModel) != modelpolicy.Allowed {
+			continue
+		}
+		if !tenants.AllowsModel(ctx, providerRegistry, model) {
+			continue
+		}
+		return model, nil
+	}
+	return "", errNoSamplingModel
+}
@@ -0,0 +1,111 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	shadow "github.com/inference-gateway/inference-gateway/api/shadow"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+// Shadow defines the interface for the shadow traffic middleware
+type Shadow interface {
+	Middleware() gin.HandlerFunc
+}
+
+// ShadowImpl duplicates a share of the chat requests to their shadow model
+type ShadowImpl struct {
+	logger          logger.Logger
+	shadower        *shadow.Shadower
+	maxRequestBytes int
+}
+
+// NewShadowMiddleware creates a new shadow traffic middleware instance
+func NewShadowMiddleware(logger logger.Logger, cfg config.Config, shadower *shadow.Shadower) (Shadow, error) {
+	if shadower == nil {
+		return nil, errors.New("shadower is required")
+	}
+	var maxRequestBytes int
+	if cfg.Server != nil {
+		maxRequestBytes = cfg.Server.MaxRequestBytes
+	}
+
+	return &ShadowImpl{
+		logger:          logger,
+		shadower:        shadower,
+		maxRequestBytes: maxRequestBytes,
+	}, nil
+}
+
+// Middleware returns the shadow traffic middleware handler. The request is
+// served as usual while its shadow runs in the background; the response of
+// non-streaming requests is kept to be compared with the shadow response.
+func (m *ShadowImpl) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.URL.Path != ChatCompletionsPath || c.Request.Method != http.MethodPost || shadow.IsShadow(c.Request.Context()) {
+			c.Next()
+			return
+		}
+
+		body, err := ReadBody(c.Request.Body, m.maxRequestBytes)
+		if err != nil {
+			if errors.Is(err, ErrRequestBodyTooLarge) {
+				errcodes.AbortJSON(c, http.StatusRequestEntityTooLarge, errcodes.RequestTooLarge, "Request body too large")
+				return
+			}
+			m.logger.Error("failed to read request body", err)
+			errcodes.AbortJSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to read request")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+
+		var req struct {
+			Model  string `json:"model"`
+			Stream bool   `json:"stream"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil || req.Model == "" {
+			c.Next()
+			return
+		}
+		shadowModel, ok := m.shadower.Target(req.Model)
+		if !ok {
+			c.Next()
+			return
+		}
+		primary := m.shadower.Start(c.Request.Header, c.Request.URL.RawQuery, body, req.Model, shadowModel)
+		if primary == nil {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		var writer *customResponseWriter
+		if !req.Stream {
+			writer = &customResponseWriter{
+				ResponseWriter: c.Writer,
+				body:           &bytes.Buffer{},
+				statusCode:     http.StatusOK,
+				writeToClient:  true,
+			}
+			c.Writer = writer
+		}
+
+		c.Next()
+
+		result := shadow.Result{Status: c.Writer.Status(), Latency: time.Since(start)}
+		if writer != nil {
+			c.Writer = writer.ResponseWriter
+			result.Body = writer.body.Bytes()
+		}
+		primary <- result
+	}
+}
@@ -3,16 +3,87 @@ package middlewares
 import (
 	"bytes"
 	"net/http"
+	"strings"
 	"time"
 
 	gin "github.com/gin-gonic/gin"
+
+	apispec "github.com/inference-gateway/inference-gateway/api/apispec"
 )
 
 const (
 	// ChatCompletionsPath is the endpoint path for chat completions
 	ChatCompletionsPath = "/v1/chat/completions"
+	// EmbeddingsPath is the endpoint path for embeddings
+	EmbeddingsPath = "/v1/embeddings"
+	// MessagesPath is the endpoint path for the Anthropic-compatible Messages API
+	MessagesPath = "/v1/messages"
+	// OllamaChatPath is the endpoint path for the Ollama-compatible chat API
+	OllamaChatPath = "/api/chat"
+	// FilesPath is the endpoint path for Files API uploads
+	FilesPath = "/v1/files"
 )
 
+// healthPaths lists the probes served without authentication or draining
+var healthPaths = map[string]struct{}{
+	"/health":       {},
+	"/health/live":  {},
+	"/health/ready": {},
+}
+
+// isHealthPath reports whether path is one of the health probes
+func isHealthPath(path string) bool {
+	_, ok := healthPaths[path]
+	return ok
+}
+
+// isPublicPath reports whether path is served without authentication: the
+// health probes and the API docs
+func isPublicPath(path string) bool {
+	return isHealthPath(path) || path == apispec.SpecPath || path == apispec.DocsPath
+}
+
+// nonToolPaths lists routes that never carry a conversation and are therefore
+// excluded from tool orchestration even when configured as tool paths
+var nonToolPaths = map[string]struct{}{
+	EmbeddingsPath: {},
+}
+
+// ParseToolPaths parses a comma-separated list of request paths into a set of
+// routes eligible for tool orchestration. Trailing slashes are ignored and paths
+// in nonToolPaths are dropped. An empty list falls back to ChatCompletionsPath.
+func ParseToolPaths(csv string) map[string]struct{} {
+	paths := make(map[string]struct{})
+	for entry := range strings.SplitSeq(csv, ",") {
+		path := normalizePath(entry)
+		if path == "" {
+			continue
+		}
+		if _, skip := nonToolPaths[path]; skip {
+			continue
+		}
+		paths[path] = struct{}{}
+	}
+	if len(paths) == 0 {
+		paths[ChatCompletionsPath] = struct{}{}
+	}
+	return paths
+}
+
+// IsToolPath reports whether the request path is one of the given tool paths
+func IsToolPath(paths map[string]struct{}, path string) bool {
+	_, ok := paths[normalizePath(path)]
+	return ok
+}
+
+func normalizePath(path string) string {
+	path = strings.TrimSpace(path)
+	if len(path) > 1 {
+		path = strings.TrimRight(path, "/")
+	}
+	return path
+}
+
 // SetSSEHeaders sets the response headers required for server-sent event streaming
 func SetSSEHeaders(c *gin.Context) {
 	c.Header("Content-Type", "text/event-stream")
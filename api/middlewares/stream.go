@@ -0,0 +1,78 @@
+package middlewares
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+// streamInterceptor writes the response straight through while handing the
+// data of each server-sent event to onData as soon as its line is complete,
+// so middlewares can inspect streams without holding them: only the
+// unfinished line is kept. A response is read as a stream when stream is
+// set, for requests asking for one, or when its Content-Type is
+// text/event-stream; other responses are kept in body, up to limit bytes.
+// With ndjson, every line of a stream that is no event is handed to onData
+// too, for the newline-delimited JSON streams of the Ollama API.
+type streamInterceptor struct {
+	gin.ResponseWriter
+	onData func(data []byte)
+	limit  int
+	stream bool
+	ndjson bool
+	body   bytes.Buffer
+
+	decided bool
+	pending []byte
+}
+
+func (w *streamInterceptor) Write(b []byte) (int, error) {
+	w.observe(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *streamInterceptor) WriteString(s string) (int, error) {
+	w.observe([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// streamed reports whether the response was an event stream
+func (w *streamInterceptor) streamed() bool {
+	return w.stream
+}
+
+func (w *streamInterceptor) observe(b []byte) {
+	if !w.decided {
+		w.decided = true
+		w.stream = w.stream || strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream")
+	}
+	if !w.stream {
+		if w.body.Len() < w.limit {
+			w.body.Write(b[:min(len(b), w.limit-w.body.Len())])
+		}
+		return
+	}
+
+	w.pending = append(w.pending, b...)
+	start := 0
+	for {
+		end := bytes.IndexByte(w.pending[start:], '\n')
+		if end < 0 {
+			break
+		}
+		line := bytes.TrimRight(w.pending[start:start+end], "\r")
+		if data, ok := bytes.CutPrefix(line, []byte("data:")); ok && w.onData != nil {
+			w.onData(bytes.TrimSpace(data))
+		} else if w.ndjson && len(line) > 0 && w.onData != nil {
+			w.onData(line)
+		}
+		start += end + 1
+	}
+	w.pending = w.pending[:copy(w.pending, w.pending[start:])]
+}
+
+func (w *streamInterceptor) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
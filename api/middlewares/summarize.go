@@ -0,0 +1,63 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	config "github.com/inference-gateway/inference-gateway/config"
+	mcp "github.com/inference-gateway/inference-gateway/internal/mcp"
+	client "github.com/inference-gateway/inference-gateway/providers/client"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	routing "github.com/inference-gateway/inference-gateway/providers/routing"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// toolResultSummaryPrompt instructs the model summarizing the middle of a
+// tool result
+const toolResultSummaryPrompt = "The following text was cut from the middle of a tool result that is too large to show in full. " +
+	"Summarize it in a few sentences, keeping names, numbers and identifiers a reader of the rest of the result may need. " +
+	"Answer with the summary only."
+
+// NewToolResultSummarizer returns the summarizer of the MCP agent, asking
+// MCP_TOOL_RESULT_SUMMARY_MODEL with the providers of providerRegistry, as
+// seen by the tenant of the request, or nil when no model is set
+func NewToolResultSummarizer(providerRegistry registry.ProviderRegistry, inferenceGatewayClient client.Client, cfg config.Config) mcp.Summarizer {
+	if cfg.MCP == nil || cfg.MCP.ToolResultSummaryModel == "" {
+		return nil
+	}
+	model := cfg.MCP.ToolResultSummaryModel
+	return func(ctx context.Context, text string, maxBytes int) (string, error) {
+		providerID, providerModel := routing.DetermineProviderAndModelName(model)
+		if providerID == nil {
+			return "", fmt.Errorf("unable to determine provider for summary model %s", model)
+		}
+		provider, err := tenants.Registry(ctx, providerRegistry).BuildProvider(*providerID, inferenceGatewayClient)
+		if err != nil {
+			return "", fmt.Errorf("failed to build provider: %w", err)
+		}
+
+		var system, user types.Message
+		system.Role, user.Role = types.System, types.User
+		if err := system.Content.FromMessageContent0(toolResultSummaryPrompt); err != nil {
+			return "", err
+		}
+		if err := user.Content.FromMessageContent0(text); err != nil {
+			return "", err
+		}
+		// About four bytes of English text per token
+		maxTokens := max(maxBytes/4, 16)
+		response, err := provider.ChatCompletions(ctx, types.CreateChatCompletionRequest{
+			Model:     providerModel,
+			Messages:  []types.Message{system, user},
+			MaxTokens: &maxTokens,
+		})
+		if err != nil {
+			return "", err
+		}
+		if len(response.Choices) == 0 {
+			return "", fmt.Errorf("summary completion has no choices")
+		}
+		return response.Choices[0].Message.TextContent(), nil
+	}
+}
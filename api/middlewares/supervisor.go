@@ -0,0 +1,63 @@
+package middlewares
+
+import (
+	"context"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+
+	supervisor "github.com/inference-gateway/inference-gateway/internal/supervisor"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+// leakGracePeriod is how long supervised work may keep running after its
+// request completed before it is reported as leaked
+const leakGracePeriod = 5 * time.Second
+
+// Supervision defines the interface for the per-request supervision middleware
+type Supervision interface {
+	Middleware() gin.HandlerFunc
+}
+
+// SupervisionImpl attaches the accounting of the supervisor to every request
+// and reports the supervised work outliving its request
+type SupervisionImpl struct {
+	logger     logger.Logger
+	supervisor *supervisor.Supervisor
+	grace      time.Duration
+}
+
+// NewSupervisionMiddleware creates a new supervision middleware instance
+func NewSupervisionMiddleware(logger logger.Logger, sup *supervisor.Supervisor) (Supervision, error) {
+	return &SupervisionImpl{
+		logger:     logger,
+		supervisor: sup,
+		grace:      leakGracePeriod,
+	}, nil
+}
+
+// Middleware returns the supervision middleware handler
+func (s *SupervisionImpl) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := s.supervisor.WithRequest(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+		path := c.Request.URL.Path
+
+		c.Next()
+
+		started, _ := supervisor.Usage(ctx)
+		if len(started) == 0 {
+			return
+		}
+		s.logger.Debug("request resource usage", "path", path, "started", started)
+		time.AfterFunc(s.grace, func() { s.checkLeaks(ctx, path) })
+	}
+}
+
+// checkLeaks warns about the supervised work of the request of ctx still
+// running
+func (s *SupervisionImpl) checkLeaks(ctx context.Context, path string) {
+	if _, running := supervisor.Usage(ctx); len(running) > 0 {
+		s.logger.Warn("supervised work outlived its request", "path", path, "running", running)
+	}
+}
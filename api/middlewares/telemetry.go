@@ -2,24 +2,31 @@ package middlewares
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
+	"math/rand/v2"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	gin "github.com/gin-gonic/gin"
+	attribute "go.opentelemetry.io/otel/attribute"
 	codes "go.opentelemetry.io/otel/codes"
 	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
 	trace "go.opentelemetry.io/otel/trace"
 
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
 	config "github.com/inference-gateway/inference-gateway/config"
 	logger "github.com/inference-gateway/inference-gateway/logger"
 	otel "github.com/inference-gateway/inference-gateway/otel"
 	registry "github.com/inference-gateway/inference-gateway/providers/registry"
 	routing "github.com/inference-gateway/inference-gateway/providers/routing"
+	toolcalls "github.com/inference-gateway/inference-gateway/providers/toolcalls"
 	types "github.com/inference-gateway/inference-gateway/providers/types"
 )
 
@@ -31,13 +38,52 @@ type TelemetryImpl struct {
 	cfg       config.Config
 	telemetry otel.OpenTelemetry
 	logger    logger.Logger
+	// captureContent samples the requests whose messages are recorded on
+	// their span, nil when TELEMETRY_CAPTURE_CONTENT is disabled
+	captureContent func() bool
+	// redactContent applies TELEMETRY_CAPTURE_CONTENT_REDACTION to the
+	// recorded messages
+	redactContent func(string) string
 }
 
 func NewTelemetryMiddleware(cfg config.Config, telemetry otel.OpenTelemetry, logger logger.Logger) (Telemetry, error) {
-	return &TelemetryImpl{
+	t := &TelemetryImpl{
 		cfg:       cfg,
 		telemetry: telemetry,
 		logger:    logger,
+	}
+	if cfg.Telemetry != nil && cfg.Telemetry.CaptureContent {
+		percent := cfg.Telemetry.CaptureContentPercent
+		t.captureContent = func() bool { return rand.IntN(100) < percent }
+		redact, err := parseContentRedaction(cfg.Telemetry.CaptureContentRedaction)
+		if err != nil {
+			return nil, err
+		}
+		t.redactContent = redact
+	}
+	return t, nil
+}
+
+// parseContentRedaction parses TELEMETRY_CAPTURE_CONTENT_REDACTION, a
+// comma-separated list of keys and pii, or none
+func parseContentRedaction(s string) (func(string) string, error) {
+	var redactors []func(string) string
+	for part := range strings.SplitSeq(s, ",") {
+		switch strings.ToLower(strings.TrimSpace(part)) {
+		case "", "none":
+		case "keys":
+			redactors = append(redactors, logger.RedactKeys)
+		case "pii":
+			redactors = append(redactors, logger.RedactPII)
+		default:
+			return nil, fmt.Errorf("invalid TELEMETRY_CAPTURE_CONTENT_REDACTION value %q: expected keys, pii or none", part)
+		}
+	}
+	return func(s string) string {
+		for _, redact := range redactors {
+			s = redact(s)
+		}
+		return s
 	}, nil
 }
 
@@ -46,38 +92,27 @@ const (
 	maxTelemetryRequestBytes = 32 << 20
 )
 
-// responseBodyWriter is a wrapper for the response writer that captures the body
-type responseBodyWriter struct {
-	gin.ResponseWriter
-	body *bytes.Buffer
-}
-
 // responseData holds all information extracted from a single response parse
 type responseData struct {
+	ID               string
+	Model            string
+	FinishReasons    []string
 	PromptTokens     int64
 	CompletionTokens int64
 	TotalTokens      int64
+	CachedTokens     int64
+	ReasoningTokens  int64
 	ToolCalls        []types.ChatCompletionMessageToolCall
-}
-
-// Write captures the response body
-func (w *responseBodyWriter) Write(b []byte) (int, error) {
-	w.body.Write(b)
-	if w.body.Len() > maxCapturedResponseBytes {
-		w.body.Next(w.body.Len() - maxCapturedResponseBytes)
-	}
-	return w.ResponseWriter.Write(b)
-}
-
-func (w *responseBodyWriter) Unwrap() http.ResponseWriter {
-	return w.ResponseWriter
+	// Output holds the response messages when their content is captured
+	Output []genAIMessage
 }
 
 func (t *TelemetryImpl) Middleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		startTime := time.Now()
 
-		if !strings.Contains(c.Request.URL.Path, "/v1/chat/completions") {
+		proxied := t.cfg.ProxyStrict && IsProxyChatPath(c.Request.URL.Path)
+		if !proxied && !strings.Contains(c.Request.URL.Path, "/v1/chat/completions") {
 			c.Next()
 			return
 		}
@@ -86,12 +121,12 @@ func (t *TelemetryImpl) Middleware() gin.HandlerFunc {
 		bodyBytes, err := io.ReadAll(io.LimitReader(c.Request.Body, maxTelemetryRequestBytes+1))
 		if err != nil {
 			t.logger.Error("failed to read request body", err)
-			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+			errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "failed to read request body")
 			c.Abort()
 			return
 		}
 		if len(bodyBytes) > maxTelemetryRequestBytes {
-			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+			errcodes.JSON(c, http.StatusRequestEntityTooLarge, errcodes.RequestTooLarge, "request body too large")
 			c.Abort()
 			return
 		}
@@ -100,22 +135,43 @@ func (t *TelemetryImpl) Middleware() gin.HandlerFunc {
 		model := requestBody.Model
 
 		provider := "unknown"
-		if detected, _ := routing.DetermineProviderAndModelName(model); detected != nil {
+		if proxied {
+			// Proxied requests name the model the way the provider does
+			if registry.Known(types.Provider(c.Param("provider"))) {
+				provider = c.Param("provider")
+			}
+		} else if detected, _ := routing.DetermineProviderAndModelName(model); detected != nil {
 			provider = string(*detected)
 		} else if queried := types.Provider(c.Query("provider")); queried != "" {
-			if _, exists := registry.Registry[queried]; exists {
+			if registry.Known(queried) {
 				provider = string(queried)
 			}
 		}
 
-		w := &responseBodyWriter{
+		// Streams are parsed event by event as they are written, only
+		// non-streaming bodies are kept
+		span := trace.SpanFromContext(c.Request.Context())
+		capture := t.captureContent != nil && span.IsRecording() && t.captureContent()
+		stream := &streamParser{logger: t.logger, capture: capture, provider: provider, model: model}
+		w := &streamInterceptor{
 			ResponseWriter: c.Writer,
-			body:           &bytes.Buffer{},
+			onData:         stream.add,
+			limit:          maxCapturedResponseBytes,
+			stream:         requestBody.Stream != nil && *requestBody.Stream,
 		}
 		c.Writer = w
 
 		c.Next()
 
+		c.Writer = w.ResponseWriter
+
+		// Requests for a routed alias are recorded under the deployment
+		// selected for them, so metrics are segmented by variant
+		alias := ""
+		if selected := c.Writer.Header().Get("X-Selected-Provider"); selected != "" {
+			alias, provider, model = model, selected, c.Writer.Header().Get("X-Selected-Model")
+		}
+
 		if provider == "unknown" {
 			t.logger.Warn("unknown provider detected",
 				"model", model,
@@ -133,20 +189,36 @@ func (t *TelemetryImpl) Middleware() gin.HandlerFunc {
 			errorType = strconv.Itoa(statusCode)
 		}
 
-		span := trace.SpanFromContext(c.Request.Context())
 		span.SetAttributes(
 			semconv.GenAIProviderNameKey.String(provider),
+			genAISystemKey.String(provider),
 			semconv.GenAIRequestModel(model),
 		)
+		span.SetAttributes(genAIRequestAttributes(&requestBody)...)
+		if alias != "" {
+			span.SetAttributes(attribute.String("gateway.routing.alias", alias))
+		}
 		if errorType != "" {
 			span.SetStatus(codes.Error, errorType)
 			span.SetAttributes(semconv.ErrorTypeKey.String(errorType))
 		}
 
-		team := otel.TeamUnknown
+		// Tenants are the organizational units of a multi-tenant gateway
+		team := cmp.Or(tenants.FromContext(c.Request.Context()), otel.TeamUnknown)
 		t.telemetry.RecordRequestDuration(c.Request.Context(), otel.SourceGateway, team, provider, model, errorType, duration)
 
-		respData := t.parseResponseData(w.body.Bytes(), requestBody.Stream != nil && *requestBody.Stream, provider, model)
+		respData := stream.result()
+		if !w.streamed() {
+			respData = t.parseNonStreamingResponse(w.body.Bytes(), capture, provider, model)
+		}
+		span.SetAttributes(genAIResponseAttributes(respData)...)
+		span.SetAttributes(genAICostAttributes(provider, model, respData)...)
+		if capture {
+			span.SetAttributes(
+				semconv.GenAIInputMessagesKey.String(t.redactContent(inputMessages(requestBody.Messages))),
+				semconv.GenAIOutputMessagesKey.String(t.redactContent(encodeMessages(respData.Output))),
+			)
+		}
 
 		promptTokens := respData.PromptTokens
 		completionTokens := respData.CompletionTokens
@@ -178,80 +250,88 @@ func (t *TelemetryImpl) Middleware() gin.HandlerFunc {
 	}
 }
 
-// parseResponseData extracts all needed information from response in a single pass
-func (t *TelemetryImpl) parseResponseData(responseBytes []byte, isStreaming bool, provider, model string) *responseData {
-	data := &responseData{}
-
-	if isStreaming {
-		data.ToolCalls = t.parseStreamingResponse(responseBytes, &data.PromptTokens, &data.CompletionTokens, &data.TotalTokens, provider, model)
-	} else {
-		data.ToolCalls = t.parseNonStreamingResponse(responseBytes, &data.PromptTokens, &data.CompletionTokens, &data.TotalTokens, provider, model)
-	}
-
-	return data
+// streamParser accumulates the response data of a streamed chat completion
+// one chunk at a time. With capture, the response content is kept too.
+type streamParser struct {
+	logger          logger.Logger
+	capture         bool
+	provider, model string
+
+	data         responseData
+	content      strings.Builder
+	finishReason types.FinishReason
+	toolCalls    toolcalls.Accumulator
 }
 
-// parseStreamingResponse handles streaming response parsing for both tokens and tool calls
-func (t *TelemetryImpl) parseStreamingResponse(responseBytes []byte, promptTokens, completionTokens, totalTokens *int64, provider, model string) []types.ChatCompletionMessageToolCall {
-	responseStr := string(responseBytes)
-	chunks := strings.Split(responseStr, "\n\n")
-
-	usageChunks := chunks
-	if len(chunks) > 4 {
-		usageChunks = chunks[len(chunks)-4:]
+// add accumulates the data of one event of the stream
+func (p *streamParser) add(data []byte) {
+	if len(data) == 0 || string(data) == "[DONE]" {
+		return
 	}
 
-	for _, chunk := range usageChunks {
-		if chunk == "" || !strings.HasPrefix(chunk, "data: ") {
-			continue
-		}
-
-		chunk = strings.TrimPrefix(chunk, "data: ")
-		if chunk == "[DONE]" {
-			continue
-		}
-
-		var streamResponse types.CreateChatCompletionStreamResponse
-		if err := json.Unmarshal([]byte(chunk), &streamResponse); err != nil {
-			t.logger.Error("failed to unmarshal streaming response chunk", err,
-				"provider", provider,
-				"model", model,
-				"chunk_length", len(chunk))
-			continue
-		}
+	var chunk types.CreateChatCompletionStreamResponse
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		p.logger.Error("failed to unmarshal streaming response chunk", err,
+			"provider", p.provider,
+			"model", p.model,
+			"chunk_length", len(data))
+		return
+	}
 
-		if streamResponse.Usage != nil {
-			*promptTokens = streamResponse.Usage.PromptTokens
-			*completionTokens = streamResponse.Usage.CompletionTokens
-			*totalTokens = streamResponse.Usage.TotalTokens
+	p.data.ID = cmp.Or(chunk.ID, p.data.ID)
+	p.data.Model = cmp.Or(chunk.Model, p.data.Model)
+	for _, choice := range chunk.Choices {
+		p.data.observeFinishReason(choice.FinishReason)
+		if choice.Index == 0 && p.capture {
+			p.content.WriteString(choice.Delta.Content)
+			p.finishReason = cmp.Or(choice.FinishReason, p.finishReason)
 		}
 	}
+	p.data.observeUsage(chunk.Usage)
+	p.toolCalls.AddChunk(chunk)
+}
 
-	return types.AccumulateStreamingToolCalls(responseStr)
+// result returns the response data of the chunks added so far
+func (p *streamParser) result() *responseData {
+	data := p.data
+	data.ToolCalls = p.toolCalls.ToolCalls()
+	if p.capture {
+		data.Output = []genAIMessage{outputMessage(p.content.String(), data.ToolCalls, p.finishReason)}
+	}
+	return &data
 }
 
-// parseNonStreamingResponse handles non-streaming response parsing for both tokens and tool calls
-func (t *TelemetryImpl) parseNonStreamingResponse(responseBytes []byte, promptTokens, completionTokens, totalTokens *int64, provider, model string) []types.ChatCompletionMessageToolCall {
+// parseNonStreamingResponse handles non-streaming response parsing for both
+// tokens and tool calls. With capture, the response messages are kept too.
+func (t *TelemetryImpl) parseNonStreamingResponse(responseBytes []byte, capture bool, provider, model string) *responseData {
+	data := &responseData{}
 	var chatCompletionResponse types.CreateChatCompletionResponse
 	if err := json.Unmarshal(responseBytes, &chatCompletionResponse); err != nil {
 		t.logger.Error("failed to unmarshal non-streaming response", err,
 			"provider", provider,
 			"model", model,
 			"response_length", len(responseBytes))
-		return nil
+		return data
 	}
 
-	if chatCompletionResponse.Usage != nil {
-		*promptTokens = chatCompletionResponse.Usage.PromptTokens
-		*completionTokens = chatCompletionResponse.Usage.CompletionTokens
-		*totalTokens = chatCompletionResponse.Usage.TotalTokens
+	data.ID = chatCompletionResponse.ID
+	data.Model = chatCompletionResponse.Model
+	data.observeUsage(chatCompletionResponse.Usage)
+	for _, choice := range chatCompletionResponse.Choices {
+		data.observeFinishReason(choice.FinishReason)
+		if capture {
+			var toolCalls []types.ChatCompletionMessageToolCall
+			if choice.Message.ToolCalls != nil {
+				toolCalls = *choice.Message.ToolCalls
+			}
+			data.Output = append(data.Output, outputMessage(choice.Message.TextContent(), toolCalls, choice.FinishReason))
+		}
 	}
 
-	if len(chatCompletionResponse.Choices) == 0 || chatCompletionResponse.Choices[0].Message.ToolCalls == nil {
-		return nil
+	if len(chatCompletionResponse.Choices) > 0 && chatCompletionResponse.Choices[0].Message.ToolCalls != nil {
+		data.ToolCalls = *chatCompletionResponse.Choices[0].Message.ToolCalls
 	}
-
-	return *chatCompletionResponse.Choices[0].Message.ToolCalls
+	return data
 }
 
 // recordToolCallMetrics analyzes the request and response to record comprehensive tool call metrics
@@ -0,0 +1,185 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"slices"
+
+	attribute "go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+
+	core "github.com/inference-gateway/inference-gateway/providers/core"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// genAISystemKey is the legacy alias of gen_ai.provider.name, still read by
+// some trace backends
+const genAISystemKey = attribute.Key("gen_ai.system")
+
+// genAIUsageCostKey is the estimated cost of a response in USD, read by
+// Langfuse as the total cost of the generation
+const genAIUsageCostKey = attribute.Key("gen_ai.usage.cost")
+
+// genAIMessage is a message of gen_ai.input.messages / gen_ai.output.messages
+type genAIMessage struct {
+	Role         string      `json:"role"`
+	Parts        []genAIPart `json:"parts"`
+	FinishReason string      `json:"finish_reason,omitempty"`
+}
+
+// genAIPart is a part of a genAIMessage: text, a tool call or a tool call
+// response
+type genAIPart struct {
+	Type      string `json:"type"`
+	Content   string `json:"content,omitempty"`
+	ID        string `json:"id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Arguments any    `json:"arguments,omitempty"`
+	Response  string `json:"response,omitempty"`
+}
+
+// genAIRequestAttributes returns the gen_ai.request.* attributes of the
+// parameters set on req
+func genAIRequestAttributes(req *types.CreateChatCompletionRequest) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{semconv.GenAIOperationNameChat}
+	if req.Stream != nil {
+		attrs = append(attrs, semconv.GenAIRequestStream(*req.Stream))
+	}
+	if req.Temperature != nil {
+		attrs = append(attrs, semconv.GenAIRequestTemperature(float64(*req.Temperature)))
+	}
+	if req.TopP != nil {
+		attrs = append(attrs, semconv.GenAIRequestTopP(float64(*req.TopP)))
+	}
+	if req.MaxCompletionTokens != nil {
+		attrs = append(attrs, semconv.GenAIRequestMaxTokens(*req.MaxCompletionTokens))
+	} else if req.MaxTokens != nil {
+		attrs = append(attrs, semconv.GenAIRequestMaxTokens(*req.MaxTokens))
+	}
+	if req.FrequencyPenalty != nil {
+		attrs = append(attrs, semconv.GenAIRequestFrequencyPenalty(float64(*req.FrequencyPenalty)))
+	}
+	if req.PresencePenalty != nil {
+		attrs = append(attrs, semconv.GenAIRequestPresencePenalty(float64(*req.PresencePenalty)))
+	}
+	if req.Seed != nil {
+		attrs = append(attrs, semconv.GenAIRequestSeed(*req.Seed))
+	}
+	if req.N != nil && *req.N != 1 {
+		attrs = append(attrs, semconv.GenAIRequestChoiceCount(*req.N))
+	}
+	return attrs
+}
+
+// genAIResponseAttributes returns the gen_ai.response.* and gen_ai.usage.*
+// attributes of data
+func genAIResponseAttributes(data *responseData) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if data.ID != "" {
+		attrs = append(attrs, semconv.GenAIResponseID(data.ID))
+	}
+	if data.Model != "" {
+		attrs = append(attrs, semconv.GenAIResponseModel(data.Model))
+	}
+	if len(data.FinishReasons) > 0 {
+		attrs = append(attrs, semconv.GenAIResponseFinishReasons(data.FinishReasons...))
+	}
+	if data.PromptTokens > 0 || data.CompletionTokens > 0 {
+		attrs = append(attrs,
+			semconv.GenAIUsageInputTokens(int(data.PromptTokens)),
+			semconv.GenAIUsageOutputTokens(int(data.CompletionTokens)),
+		)
+	}
+	if data.CachedTokens > 0 {
+		attrs = append(attrs, semconv.GenAIUsageCacheReadInputTokens(int(data.CachedTokens)))
+	}
+	if data.ReasoningTokens > 0 {
+		attrs = append(attrs, semconv.GenAIUsageReasoningOutputTokens(int(data.ReasoningTokens)))
+	}
+	return attrs
+}
+
+// genAICostAttributes prices the token usage of data at the community rates
+// of the model, when it is priced in USD
+func genAICostAttributes(provider, model string, data *responseData) []attribute.KeyValue {
+	if data.PromptTokens == 0 && data.CompletionTokens == 0 {
+		return nil
+	}
+	cost, ok := core.RequestCost(provider, model, data.PromptTokens, data.CompletionTokens, data.CachedTokens)
+	if !ok {
+		return nil
+	}
+	return []attribute.KeyValue{genAIUsageCostKey.Float64(cost)}
+}
+
+// observeUsage records the token counts of usage on data
+func (data *responseData) observeUsage(usage *types.CompletionUsage) {
+	if usage == nil {
+		return
+	}
+	data.PromptTokens = usage.PromptTokens
+	data.CompletionTokens = usage.CompletionTokens
+	data.TotalTokens = usage.TotalTokens
+	if details := usage.PromptTokensDetails; details != nil && details.CachedTokens != nil {
+		data.CachedTokens = *details.CachedTokens
+	}
+	if details := usage.CompletionTokensDetails; details != nil && details.ReasoningTokens != nil {
+		data.ReasoningTokens = *details.ReasoningTokens
+	}
+}
+
+// observeFinishReason adds reason to the finish reasons of data
+func (data *responseData) observeFinishReason(reason types.FinishReason) {
+	if reason != "" && !slices.Contains(data.FinishReasons, string(reason)) {
+		data.FinishReasons = append(data.FinishReasons, string(reason))
+	}
+}
+
+// inputMessages encodes messages as the gen_ai.input.messages attribute
+func inputMessages(messages []types.Message) string {
+	encoded := make([]genAIMessage, 0, len(messages))
+	for _, m := range messages {
+		msg := genAIMessage{Role: string(m.Role), Parts: []genAIPart{}}
+		text := m.TextContent()
+		switch {
+		case m.Role == types.Tool && m.ToolCallID != nil:
+			msg.Parts = append(msg.Parts, genAIPart{Type: "tool_call_response", ID: *m.ToolCallID, Response: text})
+		case text != "":
+			msg.Parts = append(msg.Parts, genAIPart{Type: "text", Content: text})
+		}
+		if m.ToolCalls != nil {
+			msg.Parts = append(msg.Parts, toolCallParts(*m.ToolCalls)...)
+		}
+		encoded = append(encoded, msg)
+	}
+	return encodeMessages(encoded)
+}
+
+// outputMessage builds a gen_ai.output.messages entry
+func outputMessage(text string, toolCalls []types.ChatCompletionMessageToolCall, finishReason types.FinishReason) genAIMessage {
+	msg := genAIMessage{Role: string(types.Assistant), Parts: []genAIPart{}, FinishReason: string(finishReason)}
+	if text != "" {
+		msg.Parts = append(msg.Parts, genAIPart{Type: "text", Content: text})
+	}
+	msg.Parts = append(msg.Parts, toolCallParts(toolCalls)...)
+	return msg
+}
+
+func toolCallParts(toolCalls []types.ChatCompletionMessageToolCall) []genAIPart {
+	parts := make([]genAIPart, 0, len(toolCalls))
+	for _, call := range toolCalls {
+		var arguments any = call.Function.Arguments
+		if decoded := map[string]any(nil); json.Unmarshal([]byte(call.Function.Arguments), &decoded) == nil {
+			arguments = decoded
+		}
+		parts = append(parts, genAIPart{Type: "tool_call", ID: call.ID, Name: call.Function.Name, Arguments: arguments})
+	}
+	return parts
+}
+
+func encodeMessages(messages []genAIMessage) string {
+	encoded, err := json.Marshal(messages)
+	if err != nil {
+		return "[]"
+	}
+	return string(encoded)
+}
@@ -0,0 +1,129 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	core "github.com/inference-gateway/inference-gateway/providers/core"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// TenantResolver defines the interface for the tenant resolution middleware
+type TenantResolver interface {
+	Middleware() gin.HandlerFunc
+}
+
+// TenantResolverImpl attaches the tenant of a request to its context and
+// enforces the tenant's rate limit
+type TenantResolverImpl struct {
+	logger   logger.Logger
+	store    *tenants.Store
+	header   string
+	claim    string
+	fallback string
+}
+
+// NewTenantResolverMiddleware creates a new tenant resolution middleware
+// instance. The tenant is read from the TENANT_CLAIM claim of the verified
+// OIDC token when set, else from the TENANT_HEADER header or TENANT_DEFAULT.
+// The header is set by the caller, so with OIDC authentication the tenant
+// must come from the claim: an authenticated caller could otherwise name
+// another tenant and use its provider keys, budget and models.
+func NewTenantResolverMiddleware(logger logger.Logger, cfg config.Config, store *tenants.Store) (TenantResolver, error) {
+	if store == nil {
+		return nil, errors.New("tenant store is required")
+	}
+	authEnabled := cfg.Auth != nil && cfg.Auth.Enable
+	if cfg.TenantClaim != "" && !authEnabled {
+		return nil, errors.New("TENANT_CLAIM requires AUTH_ENABLE")
+	}
+	tenanted := cfg.TenantsConfigPath != "" || store.Enabled()
+	if tenanted && authEnabled && cfg.TenantClaim == "" {
+		return nil, errors.New("TENANT_CLAIM is required with AUTH_ENABLE and tenants: any authenticated caller can set TENANT_HEADER")
+	}
+	if tenanted && cfg.TenantClaim == "" {
+		logger.Warn("tenants are resolved from a header the client sets: any caller can name any tenant, only expose the gateway behind a proxy that sets or strips it", "header", cfg.TenantHeader)
+	}
+	if cfg.TenantDefault != "" && store.Enabled() && !store.Has(cfg.TenantDefault) {
+		return nil, fmt.Errorf("TENANT_DEFAULT %q is not a configured tenant", cfg.TenantDefault)
+	}
+
+	return &TenantResolverImpl{
+		logger:   logger,
+		store:    store,
+		header:   cfg.TenantHeader,
+		claim:    cfg.TenantClaim,
+		fallback: cfg.TenantDefault,
+	}, nil
+}
+
+// Middleware returns the tenant resolution middleware handler. Requests
+// naming no tenant use TENANT_DEFAULT, and are rejected without one; the
+// operator routes under /admin, authenticated by AUTH_ADMIN_TOKEN, belong to
+// no tenant. Requests the gateway sends to itself keep the tenant of the
+// request they were sent for, whose rate limit was already applied.
+func (m *TenantResolverImpl) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !m.store.Enabled() || isPublicPath(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+		if core.IsInternal(c.Request) {
+			if id := c.GetHeader(core.InternalTenantHeader); id != "" {
+				c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), types.TenantContextKey, id))
+			}
+			c.Next()
+			return
+		}
+
+		id := m.resolve(c)
+		if id == "" && m.claim == "" {
+			if strings.HasPrefix(c.Request.URL.Path, AdminPathPrefix) {
+				c.Next()
+				return
+			}
+			id = m.fallback
+		}
+		if id == "" {
+			m.logger.Warn("request without a tenant", "path", c.Request.URL.Path)
+			errcodes.AbortJSON(c, http.StatusForbidden, errcodes.TenantUnknown, "The request names no tenant")
+			return
+		}
+		if !m.store.Has(id) {
+			m.logger.Warn("request for unknown tenant", "tenant", id, "path", c.Request.URL.Path)
+			errcodes.AbortJSON(c, http.StatusForbidden, errcodes.TenantUnknown, "Unknown tenant")
+			return
+		}
+
+		if ok, retryAfter := m.store.Allow(id); !ok {
+			m.logger.Warn("tenant rate limit exceeded", "tenant", id, "retry_after", retryAfter.String())
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			errcodes.AbortJSON(c, http.StatusTooManyRequests, errcodes.TenantRateLimited, "Tenant rate limit exceeded")
+			return
+		}
+
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), types.TenantContextKey, id))
+		c.Next()
+	}
+}
+
+// resolve returns the tenant ID named by the request, or "" when it names none
+func (m *TenantResolverImpl) resolve(c *gin.Context) string {
+	if m.claim == "" {
+		return c.GetHeader(m.header)
+	}
+	claims, _ := c.Request.Context().Value(types.AuthClaimsContextKey).(map[string]any)
+	id, _ := claims[m.claim].(string)
+	return id
+}
@@ -0,0 +1,352 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	gin "github.com/gin-gonic/gin"
+
+	budgets "github.com/inference-gateway/inference-gateway/api/budgets"
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	core "github.com/inference-gateway/inference-gateway/providers/core"
+	routing "github.com/inference-gateway/inference-gateway/providers/routing"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+const (
+	// CostHeader carries the estimated cost of a chat completion in USD, as
+	// a trailer of streamed responses
+	CostHeader = "X-Request-Cost"
+	// BudgetWarningHeader is set once a tenant or API key spent the
+	// warn_percent of its monthly budget
+	BudgetWarningHeader = "X-Budget-Warning"
+	// AllowDowngradeHeader set to false keeps a request on its model under
+	// budget pressure
+	AllowDowngradeHeader = "X-Allow-Downgrade"
+	// DowngradedFromHeader names the model a request was rerouted from to its
+	// cheaper equivalent, and DowngradeReasonHeader why
+	DowngradedFromHeader  = "X-Model-Downgraded-From"
+	DowngradeReasonHeader = "X-Model-Downgrade-Reason"
+)
+
+// Downgrade reasons
+const (
+	DowngradeReasonBudget       = "tenant_budget"
+	DowngradeReasonAPIKeyBudget = "api_key_budget"
+	DowngradeReasonSpendRate    = "provider_spend_rate"
+)
+
+// Usage defines the interface for the usage accounting middleware
+type Usage interface {
+	Middleware() gin.HandlerFunc
+}
+
+// UsageImpl prices chat completions and holds tenants and API keys to their
+// budgets
+type UsageImpl struct {
+	logger          logger.Logger
+	ledger          *budgets.Ledger
+	store           *tenants.Store
+	maxRequestBytes int
+}
+
+// NewUsageMiddleware creates a new usage accounting middleware instance
+func NewUsageMiddleware(logger logger.Logger, cfg config.Config, ledger *budgets.Ledger, store *tenants.Store) (Usage, error) {
+	if ledger == nil {
+		return nil, errors.New("usage ledger is required")
+	}
+	if store == nil {
+		return nil, errors.New("tenant store is required")
+	}
+	var maxRequestBytes int
+	if cfg.Server != nil {
+		maxRequestBytes = cfg.Server.MaxRequestBytes
+	}
+
+	return &UsageImpl{
+		logger:          logger,
+		ledger:          ledger,
+		store:           store,
+		maxRequestBytes: maxRequestBytes,
+	}, nil
+}
+
+// Middleware returns the usage accounting middleware handler. It accounts
+// the chat requests of every entry point: chat completions, the Ollama chat
+// and Messages APIs and the chat completions proxied to a provider, except
+// those the gateway sends to itself, which were accounted on their way in.
+// Requests of a tenant or API key past the block_percent of its budget are
+// rejected, and past the warn_percent get a warning header and are rerouted
+// to the cheaper equivalent of their model in ROUTING_DOWNGRADES, like those
+// for a provider over its spend rate. The usage of successful responses is
+// recorded in the ledger and their cost returned in the X-Request-Cost
+// header: JSON responses are held until it is known, streams get it as a
+// trailer.
+func (m *UsageImpl) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		proxied := IsProxyChatPath(path)
+		if c.Request.Method != http.MethodPost || (path != ChatCompletionsPath && path != OllamaChatPath && path != MessagesPath && !proxied) || core.IsInternal(c.Request) {
+			c.Next()
+			return
+		}
+
+		tenant := tenants.FromContext(c.Request.Context())
+		key := tenants.APIKeyID(c.Request.Header)
+		reason, ok := m.checkBudgets(c, tenant, key)
+		if !ok {
+			return
+		}
+
+		body, err := ReadBody(c.Request.Body, m.maxRequestBytes)
+		if err != nil {
+			if errors.Is(err, ErrRequestBodyTooLarge) {
+				errcodes.AbortJSON(c, http.StatusRequestEntityTooLarge, errcodes.RequestTooLarge, "Request body too large")
+				return
+			}
+			m.logger.Error("failed to read request body", err)
+			errcodes.AbortJSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to read request")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+
+		var req struct {
+			Model  string `json:"model"`
+			Stream *bool  `json:"stream"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil || req.Model == "" {
+			c.Next()
+			return
+		}
+		// Proxied requests name the model the way the provider does, so
+		// they cannot be rerouted
+		if !proxied {
+			if rewritten, equivalent, ok := m.downgrade(c, tenant, reason, req.Model, body); ok {
+				c.Request.Body = io.NopCloser(bytes.NewReader(rewritten))
+				c.Request.ContentLength = int64(len(rewritten))
+				req.Model = equivalent
+			}
+		}
+		// The Ollama API streams unless asked not to
+		stream := req.Stream != nil && *req.Stream || path == OllamaChatPath && req.Stream == nil
+		if stream {
+			c.Header("Trailer", CostHeader)
+		}
+
+		parser := usageParser{path: path}
+		interceptor := &streamInterceptor{ResponseWriter: c.Writer, onData: parser.add, stream: stream, ndjson: path == OllamaChatPath}
+		writer := &hookResponseWriter{ResponseWriter: interceptor, body: &bytes.Buffer{}, status: http.StatusOK}
+		c.Writer = writer
+
+		c.Next()
+
+		c.Writer = interceptor.ResponseWriter
+		if writer.buffering && writer.status == http.StatusOK {
+			parser.add(writer.body.Bytes())
+		}
+		if cost, ok := m.record(c, tenant, key, req.Model, proxied, parser.usage); ok {
+			c.Writer.Header().Set(CostHeader, strconv.FormatFloat(cost, 'f', 6, 64))
+		}
+		if !writer.buffering {
+			return
+		}
+		c.Writer.Header().Del("Content-Length")
+		c.Writer.WriteHeader(writer.status)
+		if _, err := c.Writer.Write(writer.body.Bytes()); err != nil {
+			m.logger.Error("failed to write response", err)
+		}
+	}
+}
+
+// checkBudgets rejects the request of c when its tenant, or the requests of
+// no tenant, or its API key spent the block_percent of their budget. It
+// returns the downgrade reason of the first of them past its warn_percent,
+// after setting the warning header, and false once the request is rejected.
+func (m *UsageImpl) checkBudgets(c *gin.Context, tenant, key string) (string, bool) {
+	reason := ""
+	if status, ok := m.ledger.Status(tenant); ok {
+		switch status.State {
+		case budgets.StateBlocked:
+			m.logger.Warn("tenant budget exceeded", "tenant", tenant, "spent_usd", status.SpentUSD, "monthly_usd", status.MonthlyUSD)
+			errcodes.AbortJSON(c, http.StatusTooManyRequests, errcodes.TenantBudgetExceeded,
+				fmt.Sprintf("Tenant spent %.2f USD of its monthly budget of %.2f USD", status.SpentUSD, status.MonthlyUSD))
+			return "", false
+		case budgets.StateWarning:
+			reason = DowngradeReasonBudget
+			c.Header(BudgetWarningHeader, fmt.Sprintf("%.2f USD of the monthly budget of %.2f USD spent", status.SpentUSD, status.MonthlyUSD))
+		}
+	}
+	if key == "" {
+		return reason, true
+	}
+	if status, ok := m.ledger.KeyStatus(key); ok {
+		switch status.State {
+		case budgets.StateBlocked:
+			m.logger.Warn("api key budget exceeded", "tenant", tenant, "spent_usd", status.SpentUSD, "monthly_usd", status.MonthlyUSD)
+			errcodes.AbortJSON(c, http.StatusTooManyRequests, errcodes.APIKeyBudgetExceeded,
+				fmt.Sprintf("API key spent %.2f USD of its monthly budget of %.2f USD", status.SpentUSD, status.MonthlyUSD))
+			return "", false
+		case budgets.StateWarning:
+			if reason == "" {
+				reason = DowngradeReasonAPIKeyBudget
+				c.Header(BudgetWarningHeader, fmt.Sprintf("%.2f USD of the monthly budget of %.2f USD of the API key spent", status.SpentUSD, status.MonthlyUSD))
+			}
+		}
+	}
+	return reason, true
+}
+
+// downgrade reroutes a request for a model with a cheaper equivalent in
+// ROUTING_DOWNGRADES when its tenant or API key spent the warn_percent of its
+// budget, budgetReason, or its provider spends more than its
+// ROUTING_DOWNGRADE_SPEND_RATES threshold. It returns the rewritten body and
+// the equivalent model.
+func (m *UsageImpl) downgrade(c *gin.Context, tenant, budgetReason, model string, body []byte) ([]byte, string, bool) {
+	downgrades := routing.CurrentDowngrades()
+	if downgrades == nil || strings.EqualFold(c.GetHeader(AllowDowngradeHeader), "false") {
+		return nil, "", false
+	}
+	// Requests naming their provider with ?provider= are sent the model
+	// as is, so only provider/model requests can be rerouted
+	if c.Query("provider") != "" {
+		return nil, "", false
+	}
+	provider, name := routing.DetermineProviderAndModelName(model)
+	if provider == nil {
+		return nil, "", false
+	}
+	equivalent, ok := downgrades.Equivalent(*provider, name)
+	if !ok || !m.store.ModelAllowed(tenant, equivalent) {
+		return nil, "", false
+	}
+
+	reason := budgetReason
+	if reason == "" {
+		if limit, ok := downgrades.SpendRate(*provider); ok && m.ledger.SpendRate(string(*provider)) > limit {
+			reason = DowngradeReasonSpendRate
+		}
+	}
+	if reason == "" {
+		return nil, "", false
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, "", false
+	}
+	fields["model"], _ = json.Marshal(equivalent)
+	rewritten, err := json.Marshal(fields)
+	if err != nil {
+		m.logger.Error("failed to reroute request to a cheaper model", err, "model", model)
+		return nil, "", false
+	}
+	from := string(*provider) + "/" + strings.TrimPrefix(name, string(*provider)+"/")
+	m.logger.Debug("request rerouted to a cheaper model", "tenant", tenant, "from", from, "to", equivalent, "reason", reason)
+	c.Header(DowngradedFromHeader, from)
+	c.Header(DowngradeReasonHeader, reason)
+	return rewritten, equivalent, true
+}
+
+// record adds the usage of a successful response to the ledger, for its
+// tenant and API key, and returns its cost, false when there is none to
+// report
+func (m *UsageImpl) record(c *gin.Context, tenant, key, model string, proxied bool, usage *budgets.Usage) (float64, bool) {
+	if c.Writer.Status() != http.StatusOK || usage == nil {
+		return 0, false
+	}
+
+	// Spend is accounted under the same provider and model as the request
+	// metrics of the telemetry middleware
+	provider, selected := c.Writer.Header().Get("X-Selected-Provider"), c.Writer.Header().Get("X-Selected-Model")
+	if proxied {
+		provider = c.Param("provider")
+	} else if provider != "" {
+		model = selected
+	} else if detected, name := routing.DetermineProviderAndModelName(model); detected != nil {
+		provider, model = string(*detected), name
+	} else if provider = c.Query("provider"); provider == "" {
+		return 0, false
+	}
+
+	recorded := *usage
+	recorded.Provider, recorded.Model = provider, model
+	return m.ledger.Record(tenant, key, recorded)
+}
+
+// usageParser keeps the token usage of a chat response, or of the stream
+// events reporting it, in the format of the API of path: OpenAI, Ollama or
+// Anthropic Messages
+type usageParser struct {
+	path  string
+	usage *budgets.Usage
+}
+
+func (p *usageParser) add(data []byte) {
+	switch p.path {
+	case OllamaChatPath:
+		var chunk struct {
+			Done            bool  `json:"done"`
+			PromptEvalCount int64 `json:"prompt_eval_count"`
+			EvalCount       int64 `json:"eval_count"`
+		}
+		if json.Unmarshal(data, &chunk) == nil && chunk.Done {
+			p.usage = &budgets.Usage{PromptTokens: chunk.PromptEvalCount, CompletionTokens: chunk.EvalCount}
+		}
+	case MessagesPath:
+		// Streams report the prompt tokens in message_start and the
+		// completion tokens in message_delta
+		var event struct {
+			Usage   *types.MessagesUsage `json:"usage"`
+			Message *struct {
+				Usage *types.MessagesUsage `json:"usage"`
+			} `json:"message"`
+		}
+		if json.Unmarshal(data, &event) != nil {
+			return
+		}
+		usage := event.Usage
+		if event.Message != nil && event.Message.Usage != nil {
+			usage = event.Message.Usage
+		}
+		if usage == nil {
+			return
+		}
+		if p.usage == nil {
+			p.usage = &budgets.Usage{}
+		}
+		var cacheRead, cacheCreation int64
+		if usage.CacheReadInputTokens != nil {
+			cacheRead = *usage.CacheReadInputTokens
+		}
+		if usage.CacheCreationInputTokens != nil {
+			cacheCreation = *usage.CacheCreationInputTokens
+		}
+		if prompt := usage.InputTokens + cacheRead + cacheCreation; prompt > 0 {
+			p.usage.PromptTokens, p.usage.CachedTokens = prompt, cacheRead
+		}
+		if usage.OutputTokens > 0 {
+			p.usage.CompletionTokens = usage.OutputTokens
+		}
+	default:
+		var chunk struct {
+			Usage *types.CompletionUsage `json:"usage"`
+		}
+		if json.Unmarshal(data, &chunk) != nil || chunk.Usage == nil {
+			return
+		}
+		p.usage = &budgets.Usage{PromptTokens: chunk.Usage.PromptTokens, CompletionTokens: chunk.Usage.CompletionTokens}
+		if details := chunk.Usage.PromptTokensDetails; details != nil && details.CachedTokens != nil {
+			p.usage.CachedTokens = *details.CachedTokens
+		}
+	}
+}
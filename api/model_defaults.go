@@ -0,0 +1,71 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	modeldefaults "github.com/inference-gateway/inference-gateway/api/modeldefaults"
+	config "github.com/inference-gateway/inference-gateway/config"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// SkipModelDefaultsHeader opts a request carrying the admin token out of the
+// MODEL_DEFAULTS_PATH parameters
+const SkipModelDefaultsHeader = "X-Skip-Model-Defaults"
+
+// loadModelDefaults reads the MODEL_DEFAULTS_PATH file of cfg. An invalid
+// file keeps the defaults loaded before.
+func (router *RouterImpl) loadModelDefaults(cfg config.Config) {
+	if cfg.ModelDefaultsPath == "" {
+		router.modelDefaults.Store(nil)
+		return
+	}
+	defaults, err := modeldefaults.Load(cfg.ModelDefaultsPath)
+	if err != nil {
+		router.logger.Error("failed to load model defaults, keeping the previous ones", err, "path", cfg.ModelDefaultsPath)
+		return
+	}
+	router.modelDefaults.Store(defaults)
+}
+
+// applyModelDefaults sets the parameters configured for the model of req,
+// unless the caller holds the admin token and opted out. Otherwise an error
+// response has already been written and ok is false.
+func (router *RouterImpl) applyModelDefaults(c *gin.Context, providerID types.Provider, req *types.CreateChatCompletionRequest) bool {
+	defaults := router.modelDefaults.Load()
+	if defaults == nil {
+		return true
+	}
+	if skip, _ := strconv.ParseBool(c.GetHeader(SkipModelDefaultsHeader)); skip {
+		if router.hasAdminToken(c) {
+			router.log(c).Debug("model defaults skipped", "provider", providerID, "model", req.Model)
+			return true
+		}
+		router.log(c).Debug("ignored model defaults opt-out without the admin token", "provider", providerID, "model", req.Model)
+	}
+
+	changed, err := defaults.Apply(string(providerID)+"/"+req.Model, req)
+	if err != nil {
+		router.log(c).Error("failed to apply model defaults", err, "provider", providerID, "model", req.Model)
+		errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to apply model defaults")
+		return false
+	}
+	if len(changed) > 0 {
+		router.log(c).Debug("applied model defaults", "provider", providerID, "model", req.Model, "parameters", changed)
+	}
+	return true
+}
+
+// hasAdminToken reports whether the request carries the AUTH_ADMIN_TOKEN
+func (router *RouterImpl) hasAdminToken(c *gin.Context) bool {
+	auth := router.cfg().Auth
+	if auth == nil || auth.AdminToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(c.GetHeader(middlewares.AdminTokenHeader)), []byte(auth.AdminToken)) == 1
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	modelpolicy "github.com/inference-gateway/inference-gateway/api/modelpolicy"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	config "github.com/inference-gateway/inference-gateway/config"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// loadModelPolicy builds the model policy of cfg. An invalid
+// MODEL_POLICY_PATH file keeps the policy loaded before.
+func (router *RouterImpl) loadModelPolicy(cfg config.Config) {
+	var file *modelpolicy.Config
+	if cfg.ModelPolicyPath != "" {
+		var err error
+		file, err = modelpolicy.Load(cfg.ModelPolicyPath)
+		if err != nil && router.modelPolicy.Load() != nil {
+			router.logger.Error("failed to load model policy, keeping the previous one", err, "path", cfg.ModelPolicyPath)
+			return
+		}
+		if err != nil {
+			router.logger.Error("failed to load model policy, applying ALLOWED_MODELS and DISALLOWED_MODELS only", err, "path", cfg.ModelPolicyPath)
+		}
+	}
+	router.modelPolicy.Store(modelpolicy.New(cfg, file))
+}
+
+// filterModels keeps the models the caller of ctx may use
+func (router *RouterImpl) filterModels(ctx context.Context, models []types.Model) []types.Model {
+	return router.modelPolicy.Load().Filter(ctx, models)
+}
+
+// checkModelPolicy decides whether the caller of ctx may use model, which
+// resolved to model of providerID. When it may not, it returns the error code
+// and message to answer with.
+func (router *RouterImpl) checkModelPolicy(ctx context.Context, model string, providerID types.Provider, providerModel string) (errcodes.Code, string, bool) {
+	return middlewares.CheckModelPolicy(ctx, router.modelPolicy.Load(), model, providerID, providerModel)
+}
+
+// checkProxyModel enforces the model policy and the allowed models of the
+// tenant on the model field of a JSON request body proxied to providerID.
+// Requests without one, such as model listings, pass, except chat completions
+// in strict mode. Otherwise an error response has already been written and
+// ok is false.
+func (router *RouterImpl) checkProxyModel(c *gin.Context, providerID types.Provider) bool {
+	strict := router.cfg().ProxyStrict && middlewares.IsProxyChatPath(c.Request.URL.Path)
+	if !strict && (c.Request.Body == nil || c.Request.Method == http.MethodGet || !strings.HasPrefix(c.ContentType(), "application/json")) {
+		return true
+	}
+	if c.Request.Body == nil {
+		c.Request.Body = http.NoBody
+	}
+	body, err := middlewares.ReadBody(c.Request.Body, router.cfg().Server.MaxRequestBytes)
+	if err != nil {
+		if errors.Is(err, middlewares.ErrRequestBodyTooLarge) {
+			errcodes.JSON(c, http.StatusRequestEntityTooLarge, errcodes.RequestTooLarge, "Request body too large")
+			return false
+		}
+		router.log(c).Error("failed to read request body", err)
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to read request")
+		return false
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	var req struct {
+		Model string `json:"model"`
+	}
+	if json.Unmarshal(body, &req) != nil || req.Model == "" {
+		if strict {
+			router.log(c).Warn("rejected proxied chat completion without a model", "path", c.Request.URL.Path)
+			errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Proxied chat completion requests must be JSON objects naming a model")
+			return false
+		}
+		return true
+	}
+	model := string(providerID) + "/" + req.Model
+	if code, message, ok := router.checkModelPolicy(c.Request.Context(), model, providerID, req.Model); !ok {
+		router.log(c).Error("proxied model rejected by the model policy", nil, "model", model, "code", code.Name)
+		errcodes.JSON(c, http.StatusForbidden, code, message)
+		return false
+	}
+	if !tenants.AllowsModel(c.Request.Context(), router.registry, model) {
+		router.log(c).Error("model not allowed for tenant", nil, "model", model, "tenant", tenants.FromContext(c.Request.Context()))
+		errcodes.JSON(c, http.StatusForbidden, errcodes.TenantModelNotAllowed, "Model not allowed for this tenant. Please check the list of allowed models.")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,176 @@
+// Package modeldefaults applies operator-defined chat completion parameters
+// per model: defaults for the parameters a client omits, overrides replacing
+// the client's values and caps on numeric parameters, e.g. forcing
+// temperature 0 for a compliance model or capping max_tokens for gpt-4o.
+package modeldefaults
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// Model holds the parameters of one model, by their JSON name in a chat
+// completion request
+type Model struct {
+	// Defaults are set when the client omitted them
+	Defaults map[string]any `yaml:"defaults"`
+	// Overrides are always set
+	Overrides map[string]any `yaml:"overrides"`
+	// Max caps numeric parameters, which are set to the cap when omitted
+	Max map[string]float64 `yaml:"max"`
+}
+
+// Config is the on-disk model defaults file, keyed by model ID with or
+// without the provider prefix, * applying to the models without an entry
+type Config struct {
+	Models map[string]Model `yaml:"models"`
+}
+
+// reserved are the request fields that cannot be set per model
+var reserved = []string{"model", "messages", "stream"}
+
+// Load reads, parses and validates the model defaults YAML file at path
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read model defaults: %w", err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse model defaults: %w", err)
+	}
+
+	known := requestFields()
+	models := make(map[string]Model, len(cfg.Models))
+	for id, m := range cfg.Models {
+		var names []string
+		for name := range m.Defaults {
+			names = append(names, name)
+		}
+		for name := range m.Overrides {
+			names = append(names, name)
+		}
+		for name := range m.Max {
+			names = append(names, name)
+		}
+		for _, name := range names {
+			if !known[name] || slices.Contains(reserved, name) {
+				return nil, fmt.Errorf("model %s: %s cannot be set per model", id, name)
+			}
+		}
+		models[strings.ToLower(id)] = m
+	}
+	cfg.Models = models
+
+	// Parameters of the wrong type only fail once applied
+	for id := range cfg.Models {
+		if _, err := cfg.Apply(id, &types.CreateChatCompletionRequest{}); err != nil {
+			return nil, err
+		}
+	}
+	return &cfg, nil
+}
+
+// requestFields returns the JSON names of the chat completion request fields
+func requestFields() map[string]bool {
+	fields := make(map[string]bool)
+	t := reflect.TypeFor[types.CreateChatCompletionRequest]()
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}
+
+// lookup returns the entry of modelID, a provider/model ID
+func (c *Config) lookup(modelID string) (Model, bool) {
+	id := strings.ToLower(modelID)
+	if m, ok := c.Models[id]; ok {
+		return m, true
+	}
+	if _, name, ok := strings.Cut(id, "/"); ok {
+		if m, ok := c.Models[name]; ok {
+			return m, true
+		}
+	}
+	m, ok := c.Models["*"]
+	return m, ok
+}
+
+// Apply sets the parameters configured for modelID, a provider/model ID, on
+// req and returns the names of the parameters it changed, sorted
+func (c *Config) Apply(modelID string, req *types.CreateChatCompletionRequest) ([]string, error) {
+	m, ok := c.lookup(modelID)
+	if !ok {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	set := func(name string, value any) error {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("model %s: %s: %w", modelID, name, err)
+		}
+		if string(fields[name]) != string(encoded) {
+			fields[name] = encoded
+			changed = append(changed, name)
+		}
+		return nil
+	}
+
+	for name, value := range m.Defaults {
+		if raw, present := fields[name]; !present || string(raw) == "null" {
+			if err := set(name, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for name, value := range m.Overrides {
+		if err := set(name, value); err != nil {
+			return nil, err
+		}
+	}
+	for name, limit := range m.Max {
+		var current float64
+		if raw, present := fields[name]; present && string(raw) != "null" && json.Unmarshal(raw, &current) == nil && current <= limit {
+			continue
+		}
+		if err := set(name, limit); err != nil {
+			return nil, err
+		}
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	data, err = json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	var updated types.CreateChatCompletionRequest
+	if err := json.Unmarshal(data, &updated); err != nil {
+		return nil, fmt.Errorf("model %s: invalid parameters: %w", modelID, err)
+	}
+	*req = updated
+	slices.Sort(changed)
+	return slices.Compact(changed), nil
+}
@@ -0,0 +1,120 @@
+package modeldefaults
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func load(t *testing.T, content string) (*Config, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "model-defaults.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return Load(path)
+}
+
+func TestLoad_Example(t *testing.T) {
+	cfg, err := Load(filepath.Join("..", "..", "examples", "model-defaults.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, cfg.Models, "openai/gpt-4o")
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "reserved", content: "models:\n  m:\n    overrides:\n      model: other\n", expected: "model cannot be set"},
+		{name: "unknown parameter", content: "models:\n  m:\n    defaults:\n      top_k: 40\n", expected: "top_k cannot be set"},
+		{name: "wrong type", content: "models:\n  m:\n    overrides:\n      temperature: hot\n", expected: "invalid parameters"},
+		{name: "not yaml", content: "models: [", expected: "parse model defaults"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := load(t, tt.content)
+			assert.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	cfg, err := load(t, `
+models:
+  openai/gpt-4o:
+    defaults:
+      temperature: 0.7
+      seed: 1
+    max:
+      max_tokens: 2048
+  Compliance-Model:
+    overrides:
+      temperature: 0
+  "*":
+    defaults:
+      user: gateway
+`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		model    string
+		body     string
+		expected string
+		changed  []string
+	}{
+		{
+			name:     "defaults fill omitted parameters and max caps",
+			model:    "openai/gpt-4o",
+			body:     `{"model":"gpt-4o","messages":[],"seed":5,"max_tokens":4096}`,
+			expected: `{"model":"gpt-4o","messages":[],"seed":5,"max_tokens":2048,"temperature":0.7}`,
+			changed:  []string{"max_tokens", "temperature"},
+		},
+		{
+			name:     "client values within the caps are kept",
+			model:    "openai/gpt-4o",
+			body:     `{"model":"gpt-4o","messages":[],"temperature":1,"seed":5,"max_tokens":100}`,
+			expected: `{"model":"gpt-4o","messages":[],"temperature":1,"seed":5,"max_tokens":100}`,
+		},
+		{
+			name:     "max sets omitted parameters",
+			model:    "openai/gpt-4o",
+			body:     `{"model":"gpt-4o","messages":[],"temperature":1,"seed":5}`,
+			expected: `{"model":"gpt-4o","messages":[],"temperature":1,"seed":5,"max_tokens":2048}`,
+			changed:  []string{"max_tokens"},
+		},
+		{
+			name:     "overrides replace the client's values, matched by model name",
+			model:    "groq/compliance-model",
+			body:     `{"model":"compliance-model","messages":[],"temperature":1.3}`,
+			expected: `{"model":"compliance-model","messages":[],"temperature":0}`,
+			changed:  []string{"temperature"},
+		},
+		{
+			name:     "wildcard",
+			model:    "anthropic/claude-sonnet-4-5",
+			body:     `{"model":"claude-sonnet-4-5","messages":[]}`,
+			expected: `{"model":"claude-sonnet-4-5","messages":[],"user":"gateway"}`,
+			changed:  []string{"user"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req types.CreateChatCompletionRequest
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &req))
+			changed, err := cfg.Apply(tt.model, &req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.changed, changed)
+
+			got, err := json.Marshal(req)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(got))
+		})
+	}
+}
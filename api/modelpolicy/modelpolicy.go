@@ -0,0 +1,227 @@
+// Package modelpolicy decides which models a caller may use: the gateway-wide
+// ALLOWED_MODELS / DISALLOWED_MODELS lists, replaced for the callers holding
+// an OIDC role listed in the MODEL_POLICY_PATH file, and the per-provider
+// lists of that file, which always apply. Every list matches model IDs,
+// with or without the provider prefix, and wildcard patterns such as
+// openai/gpt-4*.
+package modelpolicy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+
+	config "github.com/inference-gateway/inference-gateway/config"
+	routing "github.com/inference-gateway/inference-gateway/providers/routing"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// Decision is the outcome of a policy check
+type Decision int
+
+const (
+	// Allowed models may be used
+	Allowed Decision = iota
+	// NotAllowed models are missing from an allow list
+	NotAllowed
+	// Disallowed models are on a deny list
+	Disallowed
+)
+
+// Rules allow and deny models by ID or pattern. An empty Allow allows every
+// model; Deny wins over Allow.
+type Rules struct {
+	Allow []string `yaml:"allow"`
+	Deny  []string `yaml:"deny"`
+}
+
+// Config is the on-disk model policy file
+type Config struct {
+	// Providers restrict the models of each provider on top of the other
+	// lists; their patterns may omit the provider prefix
+	Providers map[types.Provider]Rules `yaml:"providers"`
+	// Roles replace ALLOWED_MODELS and DISALLOWED_MODELS for the callers
+	// holding the role. A caller holding several listed roles may use the
+	// models any of them allows.
+	Roles map[string]Rules `yaml:"roles"`
+}
+
+// Load reads, parses and validates the model policy YAML file at path
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read model policy: %w", err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse model policy: %w", err)
+	}
+	for provider, rules := range cfg.Providers {
+		if err := rules.validate(); err != nil {
+			return nil, fmt.Errorf("provider %s: %w", provider, err)
+		}
+	}
+	for role, rules := range cfg.Roles {
+		if err := rules.validate(); err != nil {
+			return nil, fmt.Errorf("role %s: %w", role, err)
+		}
+	}
+	return &cfg, nil
+}
+
+func (r Rules) validate() error {
+	for _, pattern := range append(r.Allow, r.Deny...) {
+		if !routing.ValidModelPattern(strings.ToLower(strings.TrimSpace(pattern))) {
+			return fmt.Errorf("invalid model pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+// ruleSet is Rules parsed for matching
+type ruleSet struct {
+	allow map[string]bool
+	deny  map[string]bool
+}
+
+func newRuleSet(r Rules) ruleSet {
+	return ruleSet{
+		allow: routing.ParseModelSet(strings.Join(r.Allow, ",")),
+		deny:  routing.ParseModelSet(strings.Join(r.Deny, ",")),
+	}
+}
+
+func (s ruleSet) check(model string) Decision {
+	if routing.ModelMatches(s.deny, model) {
+		return Disallowed
+	}
+	if len(s.allow) > 0 && !routing.ModelMatches(s.allow, model) {
+		return NotAllowed
+	}
+	return Allowed
+}
+
+// Policy checks models against the gateway configuration and policy file
+type Policy struct {
+	allowed    map[string]bool
+	disallowed map[string]bool
+	rolesClaim []string
+	providers  map[types.Provider]ruleSet
+	roles      map[string]ruleSet
+}
+
+// New returns the policy of ALLOWED_MODELS, DISALLOWED_MODELS and file, which
+// may be nil
+func New(cfg config.Config, file *Config) *Policy {
+	p := &Policy{
+		allowed:    routing.ParseModelSet(cfg.AllowedModels),
+		disallowed: routing.ParseModelSet(cfg.DisallowedModels),
+	}
+	if cfg.ModelPolicyRolesClaim != "" {
+		p.rolesClaim = strings.Split(cfg.ModelPolicyRolesClaim, ".")
+	}
+	if file != nil {
+		p.providers = make(map[types.Provider]ruleSet, len(file.Providers))
+		for provider, rules := range file.Providers {
+			p.providers[types.Provider(strings.ToLower(string(provider)))] = newRuleSet(rules)
+		}
+		p.roles = make(map[string]ruleSet, len(file.Roles))
+		for role, rules := range file.Roles {
+			p.roles[role] = newRuleSet(rules)
+		}
+	}
+	return p
+}
+
+// Check decides whether the caller of ctx may use model, the model named by
+// a request, which resolved to the provider/model ID resolved. They differ
+// for routed aliases and models named without their provider.
+func (p *Policy) Check(ctx context.Context, model, resolved string) Decision {
+	if decision := p.checkCaller(ctx, model); decision != Allowed {
+		return decision
+	}
+	if provider, _, ok := strings.Cut(strings.ToLower(resolved), "/"); ok {
+		if rules, ok := p.providers[types.Provider(provider)]; ok {
+			return rules.check(resolved)
+		}
+	}
+	return Allowed
+}
+
+// checkCaller applies the rules of the roles of the caller of ctx, or the
+// gateway-wide lists, where a non-empty allow list wins over the deny list
+func (p *Policy) checkCaller(ctx context.Context, model string) Decision {
+	decision, matched := Allowed, false
+	for _, role := range p.Roles(ctx) {
+		rules, ok := p.roles[role]
+		if !ok {
+			continue
+		}
+		roleDecision := rules.check(model)
+		if roleDecision == Allowed {
+			return Allowed
+		}
+		if !matched {
+			decision, matched = roleDecision, true
+		}
+	}
+	if matched {
+		return decision
+	}
+
+	if len(p.allowed) > 0 {
+		if !routing.ModelMatches(p.allowed, model) {
+			return NotAllowed
+		}
+		return Allowed
+	}
+	if routing.ModelMatches(p.disallowed, model) {
+		return Disallowed
+	}
+	return Allowed
+}
+
+// Filter keeps the models the caller of ctx may use
+func (p *Policy) Filter(ctx context.Context, models []types.Model) []types.Model {
+	filtered := make([]types.Model, 0, len(models))
+	for _, model := range models {
+		if p.Check(ctx, model.ID, model.ID) == Allowed {
+			filtered = append(filtered, model)
+		}
+	}
+	return filtered
+}
+
+// Roles returns the roles of the verified OIDC token of ctx named by
+// MODEL_POLICY_ROLES_CLAIM, a list or a space-separated string
+func (p *Policy) Roles(ctx context.Context) []string {
+	if len(p.roles) == 0 || len(p.rolesClaim) == 0 {
+		return nil
+	}
+	var claim any
+	claim, _ = ctx.Value(types.AuthClaimsContextKey).(map[string]any)
+	for _, name := range p.rolesClaim {
+		claims, ok := claim.(map[string]any)
+		if !ok {
+			return nil
+		}
+		claim = claims[name]
+	}
+
+	switch value := claim.(type) {
+	case string:
+		return strings.Fields(value)
+	case []any:
+		roles := make([]string, 0, len(value))
+		for _, role := range value {
+			if s, ok := role.(string); ok {
+				roles = append(roles, s)
+			}
+		}
+		return roles
+	}
+	return nil
+}
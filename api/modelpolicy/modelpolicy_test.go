@@ -0,0 +1,121 @@
+package modelpolicy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	config "github.com/inference-gateway/inference-gateway/config"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func writePolicy(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "model-policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func withClaims(claims map[string]any) context.Context {
+	return context.WithValue(context.Background(), types.AuthClaimsContextKey, claims)
+}
+
+func TestLoad(t *testing.T) {
+	file, err := Load(writePolicy(t, `
+providers:
+  openai:
+    allow: ["gpt-4o*"]
+    deny: ["gpt-4o-realtime*"]
+roles:
+  admin:
+    allow: ["*"]
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gpt-4o*"}, file.Providers[types.Provider("openai")].Allow)
+	assert.Equal(t, []string{"*"}, file.Roles["admin"].Allow)
+
+	_, err = Load(writePolicy(t, `roles: {dev: {allow: ["openai/gpt-[4"]}}`))
+	assert.ErrorContains(t, err, `role dev: invalid model pattern "openai/gpt-[4"`)
+	_, err = Load(writePolicy(t, `providers: [openai]`))
+	assert.ErrorContains(t, err, "parse model policy")
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "read model policy")
+}
+
+func TestPolicyCheck(t *testing.T) {
+	file := &Config{
+		Providers: map[types.Provider]Rules{
+			"openai": {Allow: []string{"gpt-4o*"}, Deny: []string{"gpt-4o-realtime*"}},
+		},
+		Roles: map[string]Rules{
+			"admin":   {Allow: []string{"*"}},
+			"analyst": {Allow: []string{"anthropic/*"}, Deny: []string{"*opus*"}},
+		},
+	}
+	policy := New(config.Config{
+		DisallowedModels:      "anthropic/claude-opus-4",
+		ModelPolicyRolesClaim: "realm_access.roles",
+	}, file)
+
+	anonymous := context.Background()
+	admin := withClaims(map[string]any{"realm_access": map[string]any{"roles": []any{"admin"}}})
+	analyst := withClaims(map[string]any{"realm_access": map[string]any{"roles": []any{"analyst", "unknown"}}})
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		model    string
+		resolved string
+		expected Decision
+	}{
+		{name: "global deny list", ctx: anonymous, model: "anthropic/claude-opus-4", resolved: "anthropic/claude-opus-4", expected: Disallowed},
+		{name: "no rule", ctx: anonymous, model: "anthropic/claude-sonnet-4", resolved: "anthropic/claude-sonnet-4", expected: Allowed},
+		{name: "provider allow list", ctx: anonymous, model: "openai/gpt-4o-mini", resolved: "openai/gpt-4o-mini", expected: Allowed},
+		{name: "provider allow list misses", ctx: anonymous, model: "openai/o3", resolved: "openai/o3", expected: NotAllowed},
+		{name: "provider deny wins", ctx: anonymous, model: "openai/gpt-4o-realtime", resolved: "openai/gpt-4o-realtime", expected: Disallowed},
+		{name: "provider rules apply to the resolved model", ctx: anonymous, model: "fast", resolved: "openai/o3", expected: NotAllowed},
+		{name: "role replaces the global lists", ctx: admin, model: "anthropic/claude-opus-4", resolved: "anthropic/claude-opus-4", expected: Allowed},
+		{name: "provider rules apply to every role", ctx: admin, model: "openai/o3", resolved: "openai/o3", expected: NotAllowed},
+		{name: "role allow list misses", ctx: analyst, model: "groq/llama-3.3-70b", resolved: "groq/llama-3.3-70b", expected: NotAllowed},
+		{name: "role deny wins", ctx: analyst, model: "anthropic/claude-opus-4", resolved: "anthropic/claude-opus-4", expected: Disallowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, policy.Check(tt.ctx, tt.model, tt.resolved))
+		})
+	}
+}
+
+func TestPolicyGlobalLists(t *testing.T) {
+	policy := New(config.Config{AllowedModels: "openai/*", DisallowedModels: "openai/gpt-4o"}, nil)
+
+	assert.Equal(t, Allowed, policy.Check(context.Background(), "openai/gpt-4o", "openai/gpt-4o"), "the allow list wins over the deny list")
+	assert.Equal(t, NotAllowed, policy.Check(context.Background(), "groq/llama-3.3-70b", "groq/llama-3.3-70b"))
+	assert.Nil(t, policy.Roles(withClaims(map[string]any{"roles": []any{"admin"}})), "roles are ignored without a policy file")
+}
+
+func TestPolicyRoles(t *testing.T) {
+	policy := New(config.Config{ModelPolicyRolesClaim: "roles"}, &Config{Roles: map[string]Rules{"dev": {}}})
+
+	assert.Equal(t, []string{"dev", "ops"}, policy.Roles(withClaims(map[string]any{"roles": "dev ops"})))
+	assert.Equal(t, []string{"dev"}, policy.Roles(withClaims(map[string]any{"roles": []any{"dev", 42}})))
+	assert.Nil(t, policy.Roles(withClaims(map[string]any{"groups": []any{"dev"}})))
+	assert.Nil(t, policy.Roles(context.Background()))
+}
+
+func TestPolicyFilter(t *testing.T) {
+	policy := New(config.Config{}, &Config{
+		Providers: map[types.Provider]Rules{"openai": {Deny: []string{"*-realtime*"}}},
+	})
+
+	models := []types.Model{{ID: "openai/gpt-4o"}, {ID: "openai/gpt-4o-realtime-preview"}, {ID: "groq/llama-3.3-70b"}}
+	filtered := policy.Filter(context.Background(), models)
+	require.Len(t, filtered, 2)
+	assert.Equal(t, "openai/gpt-4o", filtered[0].ID)
+	assert.Equal(t, "groq/llama-3.3-70b", filtered[1].ID)
+}
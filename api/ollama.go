@@ -0,0 +1,453 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	toolcalls "github.com/inference-gateway/inference-gateway/providers/toolcalls"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// OllamaMessage is a chat message in the Ollama native API format. Tool
+// results name the tool they answer in ToolName, not the call.
+type OllamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	Thinking  string           `json:"thinking,omitempty"`
+	Images    []string         `json:"images,omitempty"`
+	ToolCalls []OllamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"`
+}
+
+// OllamaToolCall is a tool call in the Ollama native API format. Unlike OpenAI,
+// arguments are a JSON object rather than an encoded string.
+type OllamaToolCall struct {
+	Function struct {
+		Name      string         `json:"name"`
+		Arguments map[string]any `json:"arguments"`
+	} `json:"function"`
+}
+
+// OllamaOptions holds the subset of Ollama model options that map onto
+// OpenAI chat completion parameters; unknown options are ignored.
+type OllamaOptions struct {
+	Temperature      *float32 `json:"temperature,omitempty"`
+	TopP             *float32 `json:"top_p,omitempty"`
+	NumPredict       *int     `json:"num_predict,omitempty"`
+	Seed             *int     `json:"seed,omitempty"`
+	Stop             []string `json:"stop,omitempty"`
+	FrequencyPenalty *float32 `json:"frequency_penalty,omitempty"`
+	PresencePenalty  *float32 `json:"presence_penalty,omitempty"`
+}
+
+// OllamaChatRequest is the request body of the Ollama POST /api/chat endpoint
+type OllamaChatRequest struct {
+	Model    string                      `json:"model"`
+	Messages []OllamaMessage             `json:"messages"`
+	Stream   *bool                       `json:"stream,omitempty"`
+	Format   json.RawMessage             `json:"format,omitempty"`
+	Options  *OllamaOptions              `json:"options,omitempty"`
+	Tools    *[]types.ChatCompletionTool `json:"tools,omitempty"`
+}
+
+// OllamaChatResponse is a response object (or NDJSON stream line) of the
+// Ollama POST /api/chat endpoint
+type OllamaChatResponse struct {
+	Model           string        `json:"model"`
+	CreatedAt       time.Time     `json:"created_at"`
+	Message         OllamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	DoneReason      string        `json:"done_reason,omitempty"`
+	TotalDuration   int64         `json:"total_duration,omitempty"`
+	PromptEvalCount int64         `json:"prompt_eval_count,omitempty"`
+	EvalCount       int64         `json:"eval_count,omitempty"`
+}
+
+// OllamaModelDetails describes a model in the Ollama GET /api/tags response
+type OllamaModelDetails struct {
+	Format string `json:"format"`
+	Family string `json:"family"`
+}
+
+// OllamaModel is a model entry of the Ollama GET /api/tags response
+type OllamaModel struct {
+	Name       string             `json:"name"`
+	Model      string             `json:"model"`
+	ModifiedAt time.Time          `json:"modified_at"`
+	Size       int64              `json:"size"`
+	Digest     string             `json:"digest"`
+	Details    OllamaModelDetails `json:"details"`
+}
+
+// OllamaTagsResponse is the response body of the Ollama GET /api/tags endpoint
+type OllamaTagsResponse struct {
+	Models []OllamaModel `json:"models"`
+}
+
+// OllamaTagsHandler implements the Ollama-compatible GET /api/tags endpoint so
+// Ollama clients can discover models. It aggregates the models of every
+// configured provider, applying the model policy and the allowed_models of
+// the request's tenant, and names each model by its gateway
+// id (provider/model) so it can be sent back unchanged to /api/chat.
+func (router *RouterImpl) OllamaTagsHandler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), router.cfg().Server.ReadTimeout)
+	defer cancel()
+
+	models, _ := router.listAllModels(ctx)
+	models = router.filterModels(ctx, models)
+	models = tenants.FilterModels(ctx, router.registry, models)
+
+	response := OllamaTagsResponse{Models: make([]OllamaModel, 0, len(models))}
+	for _, model := range models {
+		response.Models = append(response.Models, OllamaModel{
+			Name:       model.ID,
+			Model:      model.ID,
+			ModifiedAt: time.Unix(model.Created, 0).UTC(),
+			Details: OllamaModelDetails{
+				Family: string(model.ServedBy),
+			},
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// OllamaChatHandler implements the Ollama-compatible POST /api/chat endpoint.
+// The request is translated into an OpenAI chat completion and dispatched
+// through the same provider resolution and preparation as
+// /v1/chat/completions (routing, allowed models, vision handling, model
+// defaults, safety settings). Streaming - the Ollama default when
+// `stream` is omitted - is emitted as newline-delimited JSON objects, ending
+// with a `done: true` object carrying the token counts.
+func (router *RouterImpl) OllamaChatHandler(c *gin.Context) {
+	var ollamaReq OllamaChatRequest
+	if err := c.ShouldBindJSON(&ollamaReq); err != nil {
+		router.log(c).Error("failed to decode request", err)
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to decode request")
+		return
+	}
+
+	req, err := ollamaReq.toChatCompletionRequest()
+	if err != nil {
+		router.log(c).Error("failed to translate ollama request", err)
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, err.Error())
+		return
+	}
+
+	provider, providerID, ok := router.resolveChatProvider(c, &req)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), router.providerTimeout(providerID))
+	defer cancel()
+	if !router.prepareChatRequest(ctx, c, providerID, &req) {
+		return
+	}
+
+	start := time.Now()
+	if req.Stream == nil || !*req.Stream {
+		response, ok := router.completeChat(ctx, c, provider, providerID, req)
+		if !ok {
+			return
+		}
+
+		out := OllamaChatResponse{
+			Model:         ollamaReq.Model,
+			CreatedAt:     time.Now().UTC(),
+			Message:       OllamaMessage{Role: string(types.Assistant)},
+			Done:          true,
+			DoneReason:    string(types.Stop),
+			TotalDuration: time.Since(start).Nanoseconds(),
+		}
+		if len(response.Choices) > 0 {
+			choice := response.Choices[0]
+			out.Message.Content = choice.Message.TextContent()
+			out.Message.Thinking = reasoningText(choice.Message.Reasoning, choice.Message.ReasoningContent)
+			if choice.Message.ToolCalls != nil {
+				out.Message.ToolCalls = toOllamaToolCalls(*choice.Message.ToolCalls)
+			}
+			if choice.FinishReason != "" {
+				out.DoneReason = string(choice.FinishReason)
+			}
+		}
+		if response.Usage != nil {
+			out.PromptEvalCount = response.Usage.PromptTokens
+			out.EvalCount = response.Usage.CompletionTokens
+		}
+		c.JSON(http.StatusOK, out)
+		return
+	}
+
+	stream, ok := router.startChatStream(c, provider, providerID, req)
+	if !ok {
+		return
+	}
+	defer stream.stop()
+
+	c.Header("Content-Type", "application/x-ndjson")
+	final := OllamaChatResponse{
+		Model:      ollamaReq.Model,
+		Message:    OllamaMessage{Role: string(types.Assistant)},
+		Done:       true,
+		DoneReason: string(types.Stop),
+	}
+	var toolCallAcc toolcalls.Accumulator
+
+	writeLine := func(w io.Writer, v OllamaChatResponse) bool {
+		line, err := json.Marshal(v)
+		if err != nil {
+			router.log(c).Error("failed to encode ndjson line", err)
+			return false
+		}
+		return router.writeStreamChunk(w, append(line, '\n'))
+	}
+
+	// relay translates an SSE line of the chat completion stream into an
+	// NDJSON line, keeping what the final line reports
+	relay := func(w io.Writer, line []byte) bool {
+		data, found := bytes.CutPrefix(bytes.TrimSpace(line), []byte("data: "))
+		if !found || len(data) == 0 || bytes.Equal(data, []byte("[DONE]")) {
+			return true
+		}
+
+		var chunk types.CreateChatCompletionStreamResponse
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			router.log(c).Debug("skipping undecodable stream chunk", "provider", providerID, "error", err.Error())
+			return true
+		}
+		if chunk.Usage != nil {
+			final.PromptEvalCount = chunk.Usage.PromptTokens
+			final.EvalCount = chunk.Usage.CompletionTokens
+		}
+		if len(chunk.Choices) == 0 {
+			return true
+		}
+
+		choice := chunk.Choices[0]
+		if choice.FinishReason != "" {
+			final.DoneReason = string(choice.FinishReason)
+		}
+		toolCallAcc.AddChunk(chunk)
+		thinking := reasoningText(choice.Delta.Reasoning, choice.Delta.ReasoningContent)
+		if choice.Delta.Content == "" && thinking == "" {
+			return true
+		}
+
+		return writeLine(w, OllamaChatResponse{
+			Model:     ollamaReq.Model,
+			CreatedAt: time.Now().UTC(),
+			Message:   OllamaMessage{Role: string(types.Assistant), Content: choice.Delta.Content, Thinking: thinking},
+		})
+	}
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case line, ok := <-stream.lines:
+			middlewares.ResetWriteDeadline(c, router.cfg().Server.WriteTimeout)
+
+			if !ok {
+				if chunk := stream.finish(); chunk != nil {
+					relay(w, chunk)
+				}
+				final.CreatedAt = time.Now().UTC()
+				final.TotalDuration = time.Since(start).Nanoseconds()
+				if toolCalls := toolCallAcc.ToolCalls(); len(toolCalls) > 0 {
+					final.Message.ToolCalls = toOllamaToolCalls(toolCalls)
+				}
+				writeLine(w, final)
+				return false
+			}
+
+			for _, out := range stream.filter(line) {
+				if !relay(w, out) {
+					return false
+				}
+			}
+			return true
+		case <-stream.ctx.Done():
+			return false
+		}
+	})
+}
+
+// writeProviderError writes the error of a failed provider call, mapping the
+// provider's error response into the gateway's error codes.
+func (router *RouterImpl) writeProviderError(ctx context.Context, c *gin.Context, err error, providerID types.Provider, model string) {
+	if err == context.DeadlineExceeded || ctx.Err() == context.DeadlineExceeded {
+		router.log(c).Error("request timed out", err, "provider", providerID)
+		errcodes.JSON(c, http.StatusGatewayTimeout, errcodes.UpstreamTimeout, "Request timed out")
+		return
+	}
+	router.log(c).Error("provider call failed", err, "provider", providerID)
+	router.observeBackoff(providerID, model, err)
+	errcodes.ProviderJSON(c, err)
+}
+
+// toChatCompletionRequest translates an Ollama chat request into the OpenAI
+// chat completion request used by the provider pipeline.
+func (r OllamaChatRequest) toChatCompletionRequest() (types.CreateChatCompletionRequest, error) {
+	stream := r.Stream == nil || *r.Stream
+	req := types.CreateChatCompletionRequest{
+		Model:    r.Model,
+		Messages: make([]types.Message, 0, len(r.Messages)),
+		Stream:   &stream,
+		Tools:    r.Tools,
+	}
+	if stream {
+		// The final line of an Ollama stream carries the token counts,
+		// counted locally for providers that do not report them
+		req.StreamOptions = &types.ChatCompletionStreamOptions{IncludeUsage: true}
+	}
+
+	if r.Options != nil {
+		req.Temperature = r.Options.Temperature
+		req.TopP = r.Options.TopP
+		req.MaxTokens = r.Options.NumPredict
+		req.Seed = r.Options.Seed
+		req.FrequencyPenalty = r.Options.FrequencyPenalty
+		req.PresencePenalty = r.Options.PresencePenalty
+		if len(r.Options.Stop) > 0 {
+			req.Stop = &types.CreateChatCompletionRequest_Stop{}
+			if err := req.Stop.FromCreateChatCompletionRequestStop1(r.Options.Stop); err != nil {
+				return req, fmt.Errorf("invalid stop option: %w", err)
+			}
+		}
+	}
+
+	if len(r.Format) > 0 && !bytes.Equal(r.Format, []byte("null")) && !bytes.Equal(r.Format, []byte(`""`)) {
+		req.ResponseFormat = &types.CreateChatCompletionRequest_ResponseFormat{}
+		if bytes.Equal(r.Format, []byte(`"json"`)) {
+			if err := req.ResponseFormat.FromResponseFormatJSONObject(types.ResponseFormatJSONObject{Type: types.JSONObject}); err != nil {
+				return req, err
+			}
+		} else {
+			var schema types.ResponseFormatJSONSchemaSchema
+			if err := json.Unmarshal(r.Format, &schema); err != nil {
+				return req, fmt.Errorf("format must be \"json\" or a JSON schema object")
+			}
+			format := types.ResponseFormatJSONSchema{Type: types.JSONSchema}
+			format.JSONSchema.Name = "response"
+			format.JSONSchema.Schema = &schema
+			if err := req.ResponseFormat.FromResponseFormatJSONSchema(format); err != nil {
+				return req, err
+			}
+		}
+	}
+
+	// Ollama tool calls have no ID, so every call gets one and the tool
+	// results that follow name theirs, matched by tool name when given and
+	// else in order
+	var calls int
+	var pending []types.ChatCompletionMessageToolCall
+	for _, m := range r.Messages {
+		msg := types.Message{Role: types.MessageRole(m.Role)}
+		if len(m.Images) == 0 {
+			if err := msg.Content.FromMessageContent0(m.Content); err != nil {
+				return req, err
+			}
+		} else {
+			parts := make([]types.ContentPart, 0, len(m.Images)+1)
+			if m.Content != "" {
+				var part types.ContentPart
+				if err := part.FromTextContentPart(types.TextContentPart{Type: "text", Text: m.Content}); err != nil {
+					return req, err
+				}
+				parts = append(parts, part)
+			}
+			for _, image := range m.Images {
+				var part types.ContentPart
+				if err := part.FromImageContentPart(types.ImageContentPart{
+					Type:     "image_url",
+					ImageURL: types.ImageURL{URL: imageDataURL(image)},
+				}); err != nil {
+					return req, err
+				}
+				parts = append(parts, part)
+			}
+			if err := msg.Content.FromMessageContent1(parts); err != nil {
+				return req, err
+			}
+		}
+
+		if msg.Role == types.Tool && len(pending) > 0 {
+			i := slices.IndexFunc(pending, func(tc types.ChatCompletionMessageToolCall) bool {
+				return m.ToolName == "" || tc.Function.Name == m.ToolName
+			})
+			if i < 0 {
+				i = 0
+			}
+			id := pending[i].ID
+			msg.ToolCallID = &id
+			pending = slices.Delete(pending, i, i+1)
+		}
+
+		if len(m.ToolCalls) > 0 {
+			toolCalls := make([]types.ChatCompletionMessageToolCall, 0, len(m.ToolCalls))
+			for _, tc := range m.ToolCalls {
+				args, err := json.Marshal(tc.Function.Arguments)
+				if err != nil {
+					return req, fmt.Errorf("invalid tool call arguments: %w", err)
+				}
+				calls++
+				toolCalls = append(toolCalls, types.ChatCompletionMessageToolCall{
+					ID:   fmt.Sprintf("call_%d", calls),
+					Type: types.Function,
+					Function: types.ChatCompletionMessageToolCallFunction{
+						Name:      tc.Function.Name,
+						Arguments: string(args),
+					},
+				})
+			}
+			msg.ToolCalls = &toolCalls
+			pending = slices.Clone(toolCalls)
+		}
+
+		req.Messages = append(req.Messages, msg)
+	}
+
+	return req, nil
+}
+
+// toOllamaToolCalls converts OpenAI tool calls to the Ollama format, decoding
+// the JSON-encoded arguments. Undecodable arguments are passed as an empty object.
+func toOllamaToolCalls(toolCalls []types.ChatCompletionMessageToolCall) []OllamaToolCall {
+	out := make([]OllamaToolCall, 0, len(toolCalls))
+	for _, tc := range toolCalls {
+		var call OllamaToolCall
+		call.Function.Name = tc.Function.Name
+		if err := json.Unmarshal([]byte(tc.Function.Arguments), &call.Function.Arguments); err != nil || call.Function.Arguments == nil {
+			call.Function.Arguments = map[string]any{}
+		}
+		out = append(out, call)
+	}
+	return out
+}
+
+// imageDataURL turns a raw base64 Ollama image into a data URL, sniffing the
+// media type from the decoded bytes. Values that already are URLs pass through.
+func imageDataURL(image string) string {
+	if strings.HasPrefix(image, "data:") || strings.HasPrefix(image, "http://") || strings.HasPrefix(image, "https://") {
+		return image
+	}
+	mediaType := "image/jpeg"
+	if decoded, err := base64.StdEncoding.DecodeString(image); err == nil {
+		if sniffed := http.DetectContentType(decoded); strings.HasPrefix(sniffed, "image/") {
+			mediaType = sniffed
+		}
+	}
+	return "data:" + mediaType + ";base64," + image
+}
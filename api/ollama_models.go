@@ -0,0 +1,234 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	core "github.com/inference-gateway/inference-gateway/providers/core"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// ollamaRuntimeProviders are the providers whose backend serves Ollama's
+// native model management API. Ollama Cloud does not let its models be
+// pulled or deleted.
+var ollamaRuntimeProviders = map[types.Provider]bool{
+	constants.OllamaID: true,
+}
+
+// OllamaPullRequest is the body of POST /v1/providers/{provider}/models/pull
+type OllamaPullRequest struct {
+	Model string `json:"model"`
+	// Insecure allows pulling from a registry without TLS
+	Insecure bool `json:"insecure,omitempty"`
+	// Stream relays the progress of the pull, the default
+	Stream *bool `json:"stream,omitempty"`
+}
+
+// OllamaDeletedModel is the body answered by DELETE
+// /v1/providers/{provider}/models/{model}
+type OllamaDeletedModel struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Deleted bool   `json:"deleted"`
+}
+
+// OllamaListModelsHandler implements GET /v1/providers/{provider}/models,
+// answering the backend's GET /api/tags as is: the models pulled on it with
+// their size, digest and details, by their Ollama name.
+func (router *RouterImpl) OllamaListModelsHandler(c *gin.Context) {
+	provider, ok := router.ollamaRuntimeProvider(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), router.cfg().Server.ReadTimeout)
+	defer cancel()
+
+	resp, err := router.runtimeRequest(ctx, provider, http.MethodGet, "/api/tags", nil)
+	if err != nil {
+		router.log(c).Error("failed to list the models of the ollama backend", err, "provider", provider.GetID())
+		errcodes.ProviderJSON(c, err)
+		return
+	}
+	defer resp.Body.Close()
+	router.relayRuntimeResponse(c, resp)
+}
+
+// OllamaPullModelHandler implements POST /v1/providers/{provider}/models/pull,
+// pulling a model onto the backend with its POST /api/pull. The progress
+// objects of the pull ({"status": "pulling ...", "completed": n, "total":
+// n}) are streamed as newline-delimited JSON, ending with {"status":
+// "success"} or an {"error": "..."} object; with "stream": false only the
+// outcome is answered. Pulls are not bounded by the provider timeout, as
+// large models take long to download.
+func (router *RouterImpl) OllamaPullModelHandler(c *gin.Context) {
+	provider, ok := router.ollamaRuntimeProvider(c)
+	if !ok {
+		return
+	}
+	var req OllamaPullRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Model) == "" {
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "The request body must name the model to pull, e.g. {\"model\": \"llama3.2\"}")
+		return
+	}
+	stream := req.Stream == nil || *req.Stream
+	body, err := json.Marshal(map[string]any{"model": req.Model, "insecure": req.Insecure, "stream": stream})
+	if err != nil {
+		errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Internal server error")
+		return
+	}
+
+	router.log(c).Info("pulling model onto the ollama backend", "provider", provider.GetID(), "model", req.Model, "tenant", tenants.FromContext(c.Request.Context()))
+	resp, err := router.runtimeRequest(c.Request.Context(), provider, http.MethodPost, "/api/pull", body)
+	if err != nil {
+		router.log(c).Error("failed to pull the model", err, "provider", provider.GetID(), "model", req.Model)
+		errcodes.ProviderJSON(c, err)
+		return
+	}
+	defer resp.Body.Close()
+	if !stream || resp.StatusCode != http.StatusOK {
+		router.relayRuntimeResponse(c, resp)
+		return
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Cache-Control", "no-cache")
+	c.Status(http.StatusOK)
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if _, err := c.Writer.Write(append(line, '\n')); err != nil {
+			router.log(c).Debug("client left while the model was pulled", "provider", provider.GetID(), "model", req.Model)
+			return
+		}
+		c.Writer.Flush()
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, context.Canceled) {
+		router.log(c).Error("pull progress stream failed", err, "provider", provider.GetID(), "model", req.Model)
+		data, _ := json.Marshal(map[string]string{"error": "pull progress stream interrupted: " + err.Error()})
+		_, _ = c.Writer.Write(append(data, '\n'))
+	}
+}
+
+// OllamaDeleteModelHandler implements DELETE
+// /v1/providers/{provider}/models/{model}, removing the model from the
+// backend with its DELETE /api/delete. Model names may hold slashes, e.g.
+// hf.co/org/model:q4.
+func (router *RouterImpl) OllamaDeleteModelHandler(c *gin.Context) {
+	provider, ok := router.ollamaRuntimeProvider(c)
+	if !ok {
+		return
+	}
+	model := strings.TrimPrefix(c.Param("model"), "/")
+	if model == "" {
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "The path must name the model to delete")
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), router.cfg().Server.ReadTimeout)
+	defer cancel()
+	body, err := json.Marshal(map[string]string{"model": model})
+	if err != nil {
+		errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Internal server error")
+		return
+	}
+
+	router.log(c).Info("deleting model from the ollama backend", "provider", provider.GetID(), "model", model, "tenant", tenants.FromContext(c.Request.Context()))
+	resp, err := router.runtimeRequest(ctx, provider, http.MethodDelete, "/api/delete", body)
+	if err != nil {
+		router.log(c).Error("failed to delete the model", err, "provider", provider.GetID(), "model", model)
+		errcodes.ProviderJSON(c, err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		router.relayRuntimeResponse(c, resp)
+		return
+	}
+	c.JSON(http.StatusOK, OllamaDeletedModel{ID: model, Object: "model", Deleted: true})
+}
+
+// ollamaRuntimeProvider builds the provider of the request's path, answering
+// an error when it is unknown, disabled, hidden from the tenant or not an
+// Ollama backend
+func (router *RouterImpl) ollamaRuntimeProvider(c *gin.Context) (core.IProvider, bool) {
+	providerID := types.Provider(c.Param("provider"))
+	provider, err := router.providers(c.Request.Context()).BuildProvider(providerID, router.client)
+	if err != nil {
+		if errors.Is(err, registry.ErrProviderDisabled) {
+			router.log(c).Warn("request for a disabled provider", "provider", providerID)
+			errcodes.JSON(c, http.StatusServiceUnavailable, errcodes.ProviderDisabled, "Provider is disabled. Please use another provider.")
+			return nil, false
+		}
+		router.log(c).Error("provider not found or not supported", err, "provider", providerID)
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.ProviderNotFound, "Provider not found. Please check the list of supported providers.")
+		return nil, false
+	}
+	if !ollamaRuntimeProviders[providerID] {
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.ProviderFeatureUnsupported, fmt.Sprintf("Model management is only supported for Ollama backends, not %s.", providerID))
+		return nil, false
+	}
+	return provider, true
+}
+
+// relayRuntimeResponse answers the status and JSON body of a runtime API
+// response, mapping error statuses into the error catalog
+func (router *RouterImpl) relayRuntimeResponse(c *gin.Context, resp *http.Response) {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		errcodes.ProviderJSON(c, err)
+		return
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		status, errResp := errcodes.Upstream(resp.StatusCode, body)
+		c.Set(errcodes.ContextKey, errResp.Code)
+		c.JSON(status, errResp)
+		return
+	}
+	c.Data(resp.StatusCode, "application/json", body)
+}
+
+// runtimeRequest sends a request to the provider's server root, where
+// runtime APIs like llama.cpp /props and Ollama's /api live, outside the
+// OpenAI-compatible path prefix (e.g. /v1) of the provider base URL
+func (router *RouterImpl) runtimeRequest(ctx context.Context, provider core.IProvider, method, path string, body []byte) (*http.Response, error) {
+	base, err := url.Parse(provider.GetURL())
+	if err != nil {
+		return nil, err
+	}
+	if base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("provider url %q has no scheme or host", provider.GetURL())
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, base.Scheme+"://"+base.Host+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if err := core.ApplyAuth(req, provider); err != nil {
+		return nil, err
+	}
+	return router.client.Do(req)
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"bytes"
+	"slices"
+
+	gin "github.com/gin-gonic/gin"
+
+	tokenizer "github.com/inference-gateway/inference-gateway/internal/tokenizer"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// breakpointCacheProviders are the providers that only cache a prompt up to
+// its cache_control breakpoints. The others, e.g. OpenAI and DeepSeek, cache
+// long prefixes automatically.
+var breakpointCacheProviders = map[types.Provider]bool{
+	constants.AnthropicID: true,
+}
+
+// applyPromptCache prepares the cache_control breakpoints of req for
+// providerID. Providers without breakpoints do not get the tool breakpoints,
+// which OpenAI-compatible APIs reject. For the others, when PROMPT_CACHE_AUTO
+// is enabled and the client set no breakpoint, one is set at the end of the
+// static prefix of req, its tools then leading system messages, if it has at
+// least PROMPT_CACHE_MIN_TOKENS tokens.
+func (router *RouterImpl) applyPromptCache(c *gin.Context, providerID types.Provider, req *types.CreateChatCompletionRequest) {
+	if !breakpointCacheProviders[providerID] {
+		stripToolCacheControl(req)
+		return
+	}
+	if !router.cfg().PromptCacheAuto || hasCacheControl(req) {
+		return
+	}
+
+	system := 0
+	for system < len(req.Messages) && req.Messages[system].Role == types.System {
+		system++
+	}
+	if system == 0 && (req.Tools == nil || len(*req.Tools) == 0) {
+		return
+	}
+	tokens := tokenizer.CountMessages(router.tokenizers.ForModel(req.Model), req.Messages[:system], req.Tools)
+	if tokens < router.cfg().PromptCacheMinTokens {
+		return
+	}
+
+	ephemeral := &types.CacheControl{Type: types.Ephemeral}
+	if system > 0 {
+		messages := slices.Clone(req.Messages)
+		if err := cacheMessage(&messages[system-1], ephemeral); err != nil {
+			router.log(c).Warn("failed to mark system prompt as cacheable", "error", err.Error())
+			return
+		}
+		req.Messages = messages
+	} else {
+		tools := slices.Clone(*req.Tools)
+		tools[len(tools)-1].CacheControl = ephemeral
+		req.Tools = &tools
+	}
+	router.log(c).Debug("marked prompt prefix as cacheable", "provider", providerID, "model", req.Model, "tokens", tokens)
+}
+
+// stripToolCacheControl removes the cache_control breakpoints of the tools
+// of req, leaving the caller's slice untouched
+func stripToolCacheControl(req *types.CreateChatCompletionRequest) {
+	if req.Tools == nil || !slices.ContainsFunc(*req.Tools, func(tool types.ChatCompletionTool) bool { return tool.CacheControl != nil }) {
+		return
+	}
+	tools := slices.Clone(*req.Tools)
+	for i := range tools {
+		tools[i].CacheControl = nil
+	}
+	req.Tools = &tools
+}
+
+// hasCacheControl reports whether the client set a cache_control breakpoint
+// on a tool or message content part
+func hasCacheControl(req *types.CreateChatCompletionRequest) bool {
+	if req.Tools != nil {
+		for _, tool := range *req.Tools {
+			if tool.CacheControl != nil {
+				return true
+			}
+		}
+	}
+	for _, m := range req.Messages {
+		if content, err := m.Content.MarshalJSON(); err == nil && bytes.Contains(content, []byte(`"cache_control"`)) {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheMessage sets cacheControl on the last text part of m, turning plain
+// text content into a single text part
+func cacheMessage(m *types.Message, cacheControl *types.CacheControl) error {
+	if text, err := m.Content.AsMessageContent0(); err == nil {
+		var part types.ContentPart
+		if err := part.FromTextContentPart(types.TextContentPart{
+			Type:         types.TextContentPartTypeText,
+			Text:         text,
+			CacheControl: cacheControl,
+		}); err != nil {
+			return err
+		}
+		return m.Content.FromMessageContent1([]types.ContentPart{part})
+	}
+
+	parts, err := m.Content.AsMessageContent1()
+	if err != nil {
+		return err
+	}
+	parts = slices.Clone(parts)
+	for i := len(parts) - 1; i >= 0; i-- {
+		text, err := parts[i].AsTextContentPart()
+		if err != nil || text.Type != types.TextContentPartTypeText {
+			continue
+		}
+		text.CacheControl = cacheControl
+		if err := parts[i].FromTextContentPart(text); err != nil {
+			return err
+		}
+		return m.Content.FromMessageContent1(parts)
+	}
+	return nil
+}
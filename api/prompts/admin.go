@@ -0,0 +1,55 @@
+package prompts
+
+import (
+	"errors"
+	"net/http"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+)
+
+// ListHandler implements GET /admin/prompts
+func (s *Store) ListHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"templates": s.List()})
+}
+
+// GetHandler implements GET /admin/prompts/:name
+func (s *Store) GetHandler(c *gin.Context) {
+	t, err := s.Get(c.Param("name"))
+	if err != nil {
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+// PutHandler implements PUT /admin/prompts/:name, creating or replacing the
+// template. The name in the path wins over any name in the body.
+func (s *Store) PutHandler(c *gin.Context) {
+	var t Template
+	if err := c.ShouldBindJSON(&t); err != nil {
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Invalid request body")
+		return
+	}
+	t.Name = c.Param("name")
+	if err := s.Put(t); err != nil {
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, err.Error())
+		return
+	}
+	stored, _ := s.Get(t.Name)
+	c.JSON(http.StatusOK, stored)
+}
+
+// DeleteHandler implements DELETE /admin/prompts/:name
+func (s *Store) DeleteHandler(c *gin.Context) {
+	if err := s.Delete(c.Param("name")); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, err.Error())
+			return
+		}
+		errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, err.Error())
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,107 @@
+package prompts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Request is a decoded chat request body. It is kept generic so the same
+// injection works for OpenAI chat completions, Ollama /api/chat and Anthropic
+// /v1/messages bodies without losing fields the typed structs do not model.
+type Request map[string]any
+
+// DecodeRequest decodes a JSON request body, keeping numbers as json.Number so
+// that re-encoding does not lose precision.
+func DecodeRequest(body []byte) (Request, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var req Request
+	if err := dec.Decode(&req); err != nil {
+		return nil, fmt.Errorf("decode request: %w", err)
+	}
+	return req, nil
+}
+
+// Model returns the request's model field
+func (r Request) Model() string {
+	model, _ := r["model"].(string)
+	return model
+}
+
+// User returns the request's end-user identifier, from the OpenAI user field
+// or the Anthropic metadata.user_id field
+func (r Request) User() string {
+	if user, ok := r["user"].(string); ok {
+		return user
+	}
+	user, _ := r.metadata()["user_id"].(string)
+	return user
+}
+
+// Metadata returns the string values of the request's metadata object
+func (r Request) Metadata() map[string]string {
+	result := make(map[string]string)
+	for k, v := range r.metadata() {
+		if s, ok := v.(string); ok {
+			result[k] = s
+		}
+	}
+	return result
+}
+
+func (r Request) metadata() map[string]any {
+	metadata, _ := r["metadata"].(map[string]any)
+	return metadata
+}
+
+// InjectMessages adds the rendered prompts as leading system messages of the
+// request's messages array, in order. If any prompt uses replace mode, system
+// messages sent by the client are dropped first.
+func (r Request) InjectMessages(rendered []Rendered) {
+	messages, _ := r["messages"].([]any)
+	replace := hasReplace(rendered)
+
+	result := make([]any, 0, len(messages)+len(rendered))
+	for _, p := range rendered {
+		result = append(result, map[string]any{"role": "system", "content": p.Content})
+	}
+	for _, m := range messages {
+		if msg, ok := m.(map[string]any); ok && replace && msg["role"] == "system" {
+			continue
+		}
+		result = append(result, m)
+	}
+	r["messages"] = result
+}
+
+// InjectSystem adds the rendered prompts in front of the Anthropic top-level
+// system field, which may be a string or an array of text blocks. If any prompt
+// uses replace mode, the client's system field is discarded.
+func (r Request) InjectSystem(rendered []Rendered) {
+	blocks := make([]any, 0, len(rendered))
+	for _, p := range rendered {
+		blocks = append(blocks, map[string]any{"type": "text", "text": p.Content})
+	}
+
+	if !hasReplace(rendered) {
+		switch existing := r["system"].(type) {
+		case string:
+			if existing != "" {
+				blocks = append(blocks, map[string]any{"type": "text", "text": existing})
+			}
+		case []any:
+			blocks = append(blocks, existing...)
+		}
+	}
+	r["system"] = blocks
+}
+
+func hasReplace(rendered []Rendered) bool {
+	for _, p := range rendered {
+		if p.Mode == ModeReplace {
+			return true
+		}
+	}
+	return false
+}
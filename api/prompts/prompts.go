@@ -0,0 +1,236 @@
+package prompts
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+
+	routing "github.com/inference-gateway/inference-gateway/providers/routing"
+)
+
+// Injection modes. Prepend adds the template in front of the conversation;
+// replace additionally drops the system instructions sent by the client.
+const (
+	ModePrepend = "prepend"
+	ModeReplace = "replace"
+)
+
+// ErrNotFound is returned when a template name is not in the store
+var ErrNotFound = errors.New("prompt template not found")
+
+// Template is a named system prompt. Content is a Go text/template rendered
+// against Data. Empty Models or Subjects match every model or caller.
+type Template struct {
+	Name     string   `json:"name" yaml:"name"`
+	Content  string   `json:"content" yaml:"content"`
+	Models   []string `json:"models,omitempty" yaml:"models"`
+	Subjects []string `json:"subjects,omitempty" yaml:"subjects"`
+	Mode     string   `json:"mode,omitempty" yaml:"mode"`
+	Priority int      `json:"priority,omitempty" yaml:"priority"`
+}
+
+// Config is the on-disk prompts file
+type Config struct {
+	Templates []Template `yaml:"templates"`
+}
+
+// Data is what templates are rendered against, e.g. {{ .Model }},
+// {{ .Header.Get "X-Team" }} or {{ .Metadata.tenant }}. Metadata holds the
+// string values of the request's top-level metadata object.
+type Data struct {
+	Model    string
+	Subject  string
+	User     string
+	Date     string
+	Header   http.Header
+	Metadata map[string]string
+}
+
+// LoadConfig reads and parses the prompts YAML file at path
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read prompts config: %w", err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse prompts config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// compiled is a validated Template with its parsed text/template and model set
+type compiled struct {
+	Template
+	tmpl   *template.Template
+	models map[string]bool
+}
+
+// Store is the in-memory, concurrency-safe set of prompt templates. It is
+// seeded from the config file and mutated through the admin API; runtime
+// changes are per replica and not written back to the file.
+type Store struct {
+	mu        sync.RWMutex
+	templates map[string]*compiled
+}
+
+// NewStore creates a store holding the templates of cfg, which may be nil
+func NewStore(cfg *Config) (*Store, error) {
+	s := &Store{templates: make(map[string]*compiled)}
+	if cfg == nil {
+		return s, nil
+	}
+	for _, t := range cfg.Templates {
+		if _, dup := s.templates[t.Name]; dup {
+			return nil, fmt.Errorf("duplicate prompt template %q", t.Name)
+		}
+		if err := s.Put(t); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Put validates t and adds it to the store, replacing a template of the same name
+func (s *Store) Put(t Template) error {
+	c, err := compile(t)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.templates[t.Name] = c
+	return nil
+}
+
+// Get returns the template called name
+func (s *Store) Get(name string) (Template, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.templates[name]
+	if !ok {
+		return Template{}, ErrNotFound
+	}
+	return c.Template, nil
+}
+
+// Delete removes the template called name
+func (s *Store) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.templates[name]; !ok {
+		return ErrNotFound
+	}
+	delete(s.templates, name)
+	return nil
+}
+
+// List returns all templates sorted by name
+func (s *Store) List() []Template {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]Template, 0, len(s.templates))
+	for _, name := range slices.Sorted(maps.Keys(s.templates)) {
+		result = append(result, s.templates[name].Template)
+	}
+	return result
+}
+
+// Len returns the number of templates in the store
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.templates)
+}
+
+// Rendered is a template rendered for a specific request
+type Rendered struct {
+	Name    string
+	Content string
+	Mode    string
+}
+
+// Render renders every template matching the request's model and subject,
+// ordered by descending priority and then name. Templates that render to an
+// empty string are skipped.
+func (s *Store) Render(data Data) ([]Rendered, error) {
+	s.mu.RLock()
+	var matched []*compiled
+	for _, c := range s.templates {
+		if c.matches(data.Model, data.Subject) {
+			matched = append(matched, c)
+		}
+	}
+	s.mu.RUnlock()
+
+	slices.SortFunc(matched, func(a, b *compiled) int {
+		if n := cmp.Compare(b.Priority, a.Priority); n != 0 {
+			return n
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	if data.Date == "" {
+		data.Date = time.Now().UTC().Format(time.DateOnly)
+	}
+
+	result := make([]Rendered, 0, len(matched))
+	for _, c := range matched {
+		var buf bytes.Buffer
+		if err := c.tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("render prompt template %q: %w", c.Name, err)
+		}
+		if content := strings.TrimSpace(buf.String()); content != "" {
+			result = append(result, Rendered{Name: c.Name, Content: content, Mode: c.Mode})
+		}
+	}
+	return result, nil
+}
+
+func (c *compiled) matches(model, subject string) bool {
+	if len(c.models) > 0 && !c.models["*"] && !routing.ModelMatches(c.models, model) {
+		return false
+	}
+	if len(c.Subjects) > 0 && !slices.Contains(c.Subjects, subject) {
+		return false
+	}
+	return true
+}
+
+func compile(t Template) (*compiled, error) {
+	if t.Name == "" {
+		return nil, fmt.Errorf("prompt template name is required")
+	}
+	if strings.TrimSpace(t.Content) == "" {
+		return nil, fmt.Errorf("prompt template %q: content is required", t.Name)
+	}
+	switch t.Mode {
+	case "":
+		t.Mode = ModePrepend
+	case ModePrepend, ModeReplace:
+	default:
+		return nil, fmt.Errorf("prompt template %q: unsupported mode %q, expected %s or %s", t.Name, t.Mode, ModePrepend, ModeReplace)
+	}
+
+	tmpl, err := template.New(t.Name).Option("missingkey=zero").Parse(t.Content)
+	if err != nil {
+		return nil, fmt.Errorf("prompt template %q: %w", t.Name, err)
+	}
+
+	return &compiled{
+		Template: t,
+		tmpl:     tmpl,
+		models:   routing.ParseModelSet(strings.Join(t.Models, ",")),
+	}, nil
+}
@@ -0,0 +1,178 @@
+package prompts
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompts.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+templates:
+  - name: org
+    content: 'Team {{ .Header.Get "X-Team" }}'
+    models: ['openai/gpt-4o']
+    priority: 10
+`), 0o600))
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	s, err := NewStore(cfg)
+	require.NoError(t, err)
+
+	require.Equal(t, 1, s.Len())
+	tmpl, err := s.Get("org")
+	require.NoError(t, err)
+	assert.Equal(t, ModePrepend, tmpl.Mode)
+	assert.Equal(t, 10, tmpl.Priority)
+}
+
+func TestNewStore_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *Config
+	}{
+		{name: "Missing name", cfg: &Config{Templates: []Template{{Content: "x"}}}},
+		{name: "Missing content", cfg: &Config{Templates: []Template{{Name: "a"}}}},
+		{name: "Unknown mode", cfg: &Config{Templates: []Template{{Name: "a", Content: "x", Mode: "append"}}}},
+		{name: "Invalid template", cfg: &Config{Templates: []Template{{Name: "a", Content: "{{ .Model"}}}},
+		{name: "Duplicate name", cfg: &Config{Templates: []Template{{Name: "a", Content: "x"}, {Name: "a", Content: "y"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewStore(tt.cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestStore_Render(t *testing.T) {
+	s, err := NewStore(&Config{Templates: []Template{
+		{Name: "base", Content: "Today is {{ .Date }}."},
+		{Name: "gpt", Content: "Model {{ .Model }} for {{ .Metadata.tenant }}.", Models: []string{"gpt-4o"}, Priority: 5},
+		{Name: "team", Content: `Team {{ .Header.Get "X-Team" }}.`, Subjects: []string{"alice"}},
+		{Name: "empty", Content: "{{ if .User }}User {{ .User }}{{ end }}"},
+	}})
+	require.NoError(t, err)
+
+	header := http.Header{}
+	header.Set("X-Team", "search")
+
+	tests := []struct {
+		name     string
+		data     Data
+		expected []Rendered
+	}{
+		{
+			name: "Model and subject match",
+			data: Data{Model: "openai/gpt-4o", Subject: "alice", Date: "2026-01-02", Header: header, Metadata: map[string]string{"tenant": "acme"}},
+			expected: []Rendered{
+				{Name: "gpt", Content: "Model openai/gpt-4o for acme.", Mode: ModePrepend},
+				{Name: "base", Content: "Today is 2026-01-02.", Mode: ModePrepend},
+				{Name: "team", Content: "Team search.", Mode: ModePrepend},
+			},
+		},
+		{
+			name: "Unmatched model and subject",
+			data: Data{Model: "anthropic/claude", Subject: "bob", Date: "2026-01-02", User: "u1"},
+			expected: []Rendered{
+				{Name: "base", Content: "Today is 2026-01-02.", Mode: ModePrepend},
+				{Name: "empty", Content: "User u1", Mode: ModePrepend},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered, err := s.Render(tt.data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rendered)
+		})
+	}
+}
+
+func TestStore_PutDelete(t *testing.T) {
+	s, err := NewStore(nil)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Put(Template{Name: "b", Content: "x"}))
+	require.NoError(t, s.Put(Template{Name: "a", Content: "y", Mode: ModeReplace}))
+	assert.Equal(t, []string{"a", "b"}, []string{s.List()[0].Name, s.List()[1].Name})
+
+	require.NoError(t, s.Delete("a"))
+	assert.ErrorIs(t, s.Delete("a"), ErrNotFound)
+	_, err = s.Get("a")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, 1, s.Len())
+}
+
+func TestRequest_Inject(t *testing.T) {
+	rendered := []Rendered{{Name: "a", Content: "org", Mode: ModePrepend}}
+	replace := []Rendered{{Name: "a", Content: "org", Mode: ModeReplace}}
+
+	tests := []struct {
+		name     string
+		body     string
+		rendered []Rendered
+		system   bool
+		expected string
+	}{
+		{
+			name:     "Prepend messages",
+			body:     `{"model":"m","max_tokens":10,"messages":[{"role":"system","content":"client"},{"role":"user","content":"hi"}]}`,
+			rendered: rendered,
+			expected: `{"model":"m","max_tokens":10,"messages":[{"role":"system","content":"org"},{"role":"system","content":"client"},{"role":"user","content":"hi"}]}`,
+		},
+		{
+			name:     "Replace messages",
+			body:     `{"messages":[{"role":"system","content":"client"},{"role":"user","content":"hi"}]}`,
+			rendered: replace,
+			expected: `{"messages":[{"role":"system","content":"org"},{"role":"user","content":"hi"}]}`,
+		},
+		{
+			name:     "Prepend string system",
+			body:     `{"system":"client","messages":[]}`,
+			rendered: rendered,
+			system:   true,
+			expected: `{"system":[{"type":"text","text":"org"},{"type":"text","text":"client"}],"messages":[]}`,
+		},
+		{
+			name:     "Replace block system",
+			body:     `{"system":[{"type":"text","text":"client"}],"messages":[]}`,
+			rendered: replace,
+			system:   true,
+			expected: `{"system":[{"type":"text","text":"org"}],"messages":[]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := DecodeRequest([]byte(tt.body))
+			require.NoError(t, err)
+			if tt.system {
+				req.InjectSystem(tt.rendered)
+			} else {
+				req.InjectMessages(tt.rendered)
+			}
+			body, err := json.Marshal(req)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(body))
+		})
+	}
+}
+
+func TestRequest_Accessors(t *testing.T) {
+	req, err := DecodeRequest([]byte(`{"model":"openai/gpt-4o","metadata":{"user_id":"u1","tenant":"acme","n":1}}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "openai/gpt-4o", req.Model())
+	assert.Equal(t, "u1", req.User())
+	assert.Equal(t, map[string]string{"user_id": "u1", "tenant": "acme"}, req.Metadata())
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// recordProviderCall records the outcome of req, a call to providerID:
+// transport errors and 5xx responses are failures. Calls the client gave up
+// on say nothing about the provider, and neither do successful reads such as
+// the models list, which keeps working on providers whose completions fail.
+func (router *RouterImpl) recordProviderCall(req *http.Request, providerID types.Provider, resp *http.Response, err error) {
+	if err != nil && errors.Is(req.Context().Err(), context.Canceled) {
+		return
+	}
+	ok := err == nil && resp.StatusCode < http.StatusInternalServerError
+	if ok && req.Method == http.MethodGet {
+		return
+	}
+	router.health.Record(string(providerID), ok)
+}
+
+// providerHealthy reports whether providerID is not failing most of its
+// calls within PROVIDER_HEALTH_WINDOW
+func (router *RouterImpl) providerHealthy(providerID types.Provider) bool {
+	return router.health.Healthy(string(providerID), router.cfg().ProviderHealthWindow, router.cfg().ProviderHealthMinFailures)
+}
+
+// filterAvailableModels drops the models of unhealthy providers, or with
+// includeUnavailable keeps every model and marks whether it is available
+func (router *RouterImpl) filterAvailableModels(models []types.Model, includeUnavailable bool) []types.Model {
+	healthy := make(map[types.Provider]bool)
+	filtered := make([]types.Model, 0, len(models))
+	for _, model := range models {
+		ok, seen := healthy[model.ServedBy]
+		if !seen {
+			ok = router.providerHealthy(model.ServedBy)
+			healthy[model.ServedBy] = ok
+		}
+		if includeUnavailable {
+			model.Available = &ok
+		} else if !ok {
+			continue
+		}
+		filtered = append(filtered, model)
+	}
+	return filtered
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+
+	client "github.com/inference-gateway/inference-gateway/providers/client"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// configuredTimeout returns the timeout of provider id, 0 when it has none
+func (router *RouterImpl) configuredTimeout(id types.Provider) time.Duration {
+	if provider, ok := router.cfg().Providers[id]; ok {
+		return provider.Timeout
+	}
+	return 0
+}
+
+// providerTimeout returns how long a request to provider id may take: the
+// timeout of the provider, else SERVER_READ_TIMEOUT
+func (router *RouterImpl) providerTimeout(id types.Provider) time.Duration {
+	return cmp.Or(router.configuredTimeout(id), router.cfg().Server.ReadTimeout)
+}
+
+// upstreamContext returns ctx bounding the connections to provider id to
+// its connect timeout
+func (router *RouterImpl) upstreamContext(ctx context.Context, id types.Provider) context.Context {
+	if provider, ok := router.cfg().Providers[id]; ok {
+		return client.WithConnectTimeout(ctx, provider.ConnectTimeout)
+	}
+	return ctx
+}
+
+// retryPolicy returns how failed requests to provider id are retried
+func (router *RouterImpl) retryPolicy(id types.Provider) client.RetryPolicy {
+	policy := client.RetryPolicy{Backoff: router.cfg().ProviderRetryBackoff}
+	if provider, ok := router.cfg().Providers[id]; ok {
+		policy.MaxRetries = provider.MaxRetries
+	}
+	return policy
+}
+
+// replayableBody reads the request body of c into memory so it can be sent
+// again on retries. Otherwise an error response has already been written and
+// ok is false.
+func (router *RouterImpl) replayableBody(c *gin.Context) bool {
+	body, err := middlewares.ReadBody(c.Request.Body, router.cfg().Server.MaxRequestBytes)
+	if err != nil {
+		if errors.Is(err, middlewares.ErrRequestBodyTooLarge) {
+			errcodes.JSON(c, http.StatusRequestEntityTooLarge, errcodes.RequestTooLarge, "Request body too large")
+			return false
+		}
+		router.log(c).Error("failed to read request body", err)
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to read request")
+		return false
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	c.Request.ContentLength = int64(len(body))
+	c.Request.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	config "github.com/inference-gateway/inference-gateway/config"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// loadProxyPaths parses PROXY_ALLOWED_PATHS. Invalid patterns keep the
+// previous ones.
+func (router *RouterImpl) loadProxyPaths(cfg config.Config) {
+	patterns, err := middlewares.ParseProxyPaths(cfg.ProxyAllowedPaths)
+	if err != nil {
+		router.logger.Error("failed to parse proxy allowed paths, keeping the previous ones", err)
+		if router.proxyPaths.Load() == nil {
+			router.proxyPaths.Store(&[]string{})
+		}
+		return
+	}
+	router.proxyPaths.Store(&patterns)
+}
+
+// checkProxyPath rejects, in strict mode, the proxied requests whose
+// provider/path matches none of PROXY_ALLOWED_PATHS. Otherwise an error
+// response has already been written and ok is false.
+func (router *RouterImpl) checkProxyPath(c *gin.Context, providerID types.Provider) bool {
+	if !router.cfg().ProxyStrict {
+		return true
+	}
+	providerPath := string(providerID) + c.Param("path")
+	if !middlewares.MatchProxyPath(*router.proxyPaths.Load(), providerPath) {
+		router.log(c).Warn("rejected proxied request to a path that is not allowed", "provider", providerID, "path", c.Param("path"))
+		errcodes.JSON(c, http.StatusForbidden, errcodes.ProxyPathNotAllowed, "Proxied path is not allowed. Please use a gateway endpoint.")
+		return false
+	}
+	return true
+}
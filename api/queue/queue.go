@@ -0,0 +1,527 @@
+// Package queue holds chat requests that a saturated provider rejected and
+// dispatches them again as capacity frees up, so rate-limit windows do not
+// turn into a storm of failed client retries.
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+
+	dispatch "github.com/inference-gateway/inference-gateway/api/dispatch"
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	records "github.com/inference-gateway/inference-gateway/api/records"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+// Priority classes. Interactive requests are always dispatched before batch
+// requests; within a class requests are dispatched in arrival order.
+const (
+	PriorityInteractive = "interactive"
+	PriorityBatch       = "batch"
+)
+
+// PriorityHeader selects the priority class of a request
+const PriorityHeader = "X-Priority"
+
+// Item statuses
+const (
+	StatusQueued     = "queued"
+	StatusInProgress = "in_progress"
+	StatusCompleted  = "completed"
+	StatusFailed     = "failed"
+	StatusExpired    = "expired"
+)
+
+// ErrFull is returned when the queue holds QUEUE_MAX_SIZE requests
+var ErrFull = errors.New("request queue is full")
+
+// credentialHeaders are dropped from persisted request headers
+var credentialHeaders = []string{"Authorization", "Cookie", "X-Api-Key", "X-Admin-Token"}
+
+// Options configures a Queue
+type Options struct {
+	Dir           string
+	Workers       int
+	MaxSize       int
+	RetryInterval time.Duration
+	TTL           time.Duration
+}
+
+// Response is the answer a queued request got
+type Response struct {
+	StatusCode int             `json:"status_code"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// Item is a queued request as returned by GET /v1/queue/:id
+type Item struct {
+	ID          string    `json:"id"`
+	Object      string    `json:"object"`
+	Status      string    `json:"status"`
+	Priority    string    `json:"priority"`
+	Attempts    int       `json:"attempts"`
+	CreatedAt   int64     `json:"created_at"`
+	CompletedAt *int64    `json:"completed_at,omitempty"`
+	Response    *Response `json:"response,omitempty"`
+}
+
+// record is the persisted state of an item. Credentials are never persisted;
+// HadCredentials records that the request carried some, so it cannot be
+// dispatched again after a restart.
+type record struct {
+	Item
+	Owner          string          `json:"owner"`
+	Body           json.RawMessage `json:"body"`
+	Header         http.Header     `json:"header,omitempty"`
+	Query          string          `json:"query,omitempty"`
+	HadCredentials bool            `json:"had_credentials,omitempty"`
+}
+
+// item is a record with its in-memory state
+type item struct {
+	record
+	header http.Header
+	done   chan struct{}
+}
+
+// Queue dispatches queued requests in the background. Items are persisted in
+// a directory, so queued requests and results survive restarts.
+type Queue struct {
+	dispatcher dispatch.Dispatcher
+	records    *records.Store[record]
+	logger     logger.Logger
+	opts       Options
+	now        func() time.Time
+
+	mu          sync.Mutex
+	ready       *sync.Cond
+	items       map[string]*item
+	pending     map[string][]*item
+	pausedUntil time.Time
+	stopped     bool
+}
+
+type (
+	dispatchKey struct{}
+	holdKey     struct{}
+)
+
+// WithDispatch marks ctx as the context of a request dispatched by the queue,
+// so it is not queued a second time
+func WithDispatch(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dispatchKey{}, true)
+}
+
+// IsDispatch reports whether ctx belongs to a request dispatched by the queue
+func IsDispatch(ctx context.Context) bool {
+	dispatched, _ := ctx.Value(dispatchKey{}).(bool)
+	return dispatched
+}
+
+// WithHold marks ctx as the context of a caller that waits for the result of
+// a queued request however long it takes, such as a batch job
+func WithHold(ctx context.Context) context.Context {
+	return context.WithValue(ctx, holdKey{}, true)
+}
+
+// Holds reports whether the caller of ctx waits for queued results without a
+// timeout
+func Holds(ctx context.Context) bool {
+	hold, _ := ctx.Value(holdKey{}).(bool)
+	return hold
+}
+
+// Saturated reports whether a response with status and gateway error code
+// means the provider is out of capacity rather than the request being wrong
+func Saturated(status int, code string) bool {
+	switch code {
+	case errcodes.UpstreamRateLimited.ID, errcodes.UpstreamUnreachable.ID:
+		return true
+	case errcodes.UpstreamUnavailable.ID:
+		// Overloaded providers, Anthropic's 529 included, are answered with
+		// 503; other statuses are internal failures of the provider
+		return status == http.StatusServiceUnavailable
+	}
+	return false
+}
+
+// New creates a queue keeping its items in opts.Dir. Requests left queued by
+// a previous run are queued again, except those carrying credentials, which
+// are failed because the credentials were not persisted.
+func New(dispatcher dispatch.Dispatcher, logger logger.Logger, opts Options) (*Queue, error) {
+	if dispatcher == nil {
+		return nil, errors.New("queue dispatcher is required")
+	}
+	if opts.Workers < 1 || opts.MaxSize < 1 {
+		return nil, fmt.Errorf("queue workers and max size must be positive, got %d and %d", opts.Workers, opts.MaxSize)
+	}
+	recs, err := records.New[record](opts.Dir, "queued request", logger)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &Queue{
+		dispatcher: dispatcher,
+		records:    recs,
+		logger:     logger,
+		opts:       opts,
+		now:        time.Now,
+		items:      make(map[string]*item),
+		pending:    make(map[string][]*item),
+	}
+	q.ready = sync.NewCond(&q.mu)
+	if err := q.load(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// load reads the persisted items and queues the unfinished ones in their
+// original order
+func (q *Queue) load() error {
+	var resumed []*item
+	err := q.records.Load(func(rec record) {
+		it := &item{record: rec, header: rec.Header, done: make(chan struct{})}
+		q.items[rec.ID] = it
+
+		switch rec.Status {
+		case StatusQueued, StatusInProgress:
+			if rec.HadCredentials {
+				q.complete(it, StatusFailed, http.StatusServiceUnavailable, errcodes.InternalError, "The gateway restarted while the request was queued. Send it again.")
+				return
+			}
+			it.Status = StatusQueued
+			resumed = append(resumed, it)
+		default:
+			close(it.done)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	for _, it := range sortByCreation(resumed) {
+		q.pending[it.Priority] = append(q.pending[it.Priority], it)
+	}
+	if len(resumed) > 0 {
+		q.logger.Info("resuming queued requests", "count", len(resumed))
+	}
+	return nil
+}
+
+// Start runs the workers until ctx is done. Requests in flight when ctx ends
+// stay queued for the next start.
+func (q *Queue) Start(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+		q.mu.Lock()
+		q.stopped = true
+		q.ready.Broadcast()
+		q.mu.Unlock()
+	}()
+	go q.janitor(ctx)
+	for range q.opts.Workers {
+		go func() {
+			for {
+				it, ok := q.next()
+				if !ok {
+					return
+				}
+				q.dispatch(ctx, it)
+			}
+		}()
+	}
+}
+
+// Enqueue queues a request with its header, query and body in the priority
+// class requested by its PriorityHeader
+func (q *Queue) Enqueue(ctx context.Context, header http.Header, query string, body []byte) (Item, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return Item{}, err
+	}
+	priority := PriorityInteractive
+	if strings.EqualFold(header.Get(PriorityHeader), PriorityBatch) {
+		priority = PriorityBatch
+	}
+	persisted := header.Clone()
+	hadCredentials := false
+	for _, name := range credentialHeaders {
+		if persisted.Get(name) != "" {
+			hadCredentials = true
+		}
+		persisted.Del(name)
+	}
+
+	it := &item{
+		record: record{
+			Item: Item{
+				ID:        "queued_" + hex.EncodeToString(b),
+				Object:    "queue.item",
+				Status:    StatusQueued,
+				Priority:  priority,
+				CreatedAt: q.now().Unix(),
+			},
+			Owner:          tenants.Owner(ctx, header),
+			Body:           body,
+			Header:         persisted,
+			Query:          query,
+			HadCredentials: hadCredentials,
+		},
+		header: header.Clone(),
+		done:   make(chan struct{}),
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.size() >= q.opts.MaxSize {
+		return Item{}, ErrFull
+	}
+	if err := q.records.Write(it.ID, it.record); err != nil {
+		return Item{}, err
+	}
+	q.items[it.ID] = it
+	q.pending[priority] = append(q.pending[priority], it)
+	q.ready.Signal()
+	return it.public(), nil
+}
+
+// Wait blocks until item id completes, ctx is done or the queue stops. ok is
+// false when the item is not complete yet.
+func (q *Queue) Wait(ctx context.Context, id string) (Item, bool) {
+	q.mu.Lock()
+	it, found := q.items[id]
+	q.mu.Unlock()
+	if !found {
+		return Item{}, false
+	}
+	select {
+	case <-it.done:
+	case <-ctx.Done():
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	public := it.public()
+	return public, public.Response != nil
+}
+
+// Get returns item id if owner queued it
+func (q *Queue) Get(id, owner string) (Item, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	it, ok := q.items[id]
+	if !ok || it.Owner != owner {
+		return Item{}, false
+	}
+	return it.public(), true
+}
+
+// next waits for the next item to dispatch: interactive before batch, oldest
+// first, after any pause requested by a saturated provider. ok is false once
+// the queue stopped.
+func (q *Queue) next() (*item, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for {
+		if q.stopped {
+			return nil, false
+		}
+		if wait := q.pausedUntil.Sub(q.now()); wait > 0 {
+			q.mu.Unlock()
+			time.Sleep(wait)
+			q.mu.Lock()
+			continue
+		}
+		for _, priority := range []string{PriorityInteractive, PriorityBatch} {
+			if pending := q.pending[priority]; len(pending) > 0 {
+				it := pending[0]
+				q.pending[priority] = pending[1:]
+				it.Status = StatusInProgress
+				it.Attempts++
+				q.records.WriteLogged(it.ID, it.record)
+				return it, true
+			}
+		}
+		q.ready.Wait()
+	}
+}
+
+// dispatch runs it through the gateway. Saturated responses put it back at
+// the head of its class and pause dispatching for the retry interval.
+func (q *Queue) dispatch(ctx context.Context, it *item) {
+	status, body := q.dispatcher.Dispatch(WithDispatch(ctx), it.header, it.Query, it.Body)
+	if ctx.Err() != nil {
+		q.mu.Lock()
+		it.Status = StatusQueued
+		q.records.WriteLogged(it.ID, it.record)
+		q.mu.Unlock()
+		return
+	}
+
+	var peek struct {
+		Code string `json:"code"`
+	}
+	_ = json.Unmarshal(body, &peek)
+	if !Saturated(status, peek.Code) {
+		q.finish(it, status, body)
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.now().Sub(time.Unix(it.CreatedAt, 0)) >= q.opts.TTL {
+		q.expire(it, status, body)
+		return
+	}
+	q.logger.Debug("provider still saturated, requeueing request", "id", it.ID, "status", status, "attempts", it.Attempts)
+	it.Status = StatusQueued
+	q.records.WriteLogged(it.ID, it.record)
+	q.pending[it.Priority] = append([]*item{it}, q.pending[it.Priority]...)
+	q.pausedUntil = q.now().Add(q.opts.RetryInterval)
+	q.ready.Broadcast()
+}
+
+// finish stores the response of it
+func (q *Queue) finish(it *item, status int, body []byte) {
+	if !json.Valid(body) {
+		body, _ = json.Marshal(errcodes.InternalError.Response("Request returned an invalid response"))
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	itemStatus := StatusCompleted
+	if status >= http.StatusBadRequest {
+		itemStatus = StatusFailed
+	}
+	q.settle(it, itemStatus, status, body)
+	q.logger.Debug("queued request finished", "id", it.ID, "status", status, "attempts", it.Attempts)
+}
+
+// expire ends it with the last saturated response. The caller must hold q.mu.
+func (q *Queue) expire(it *item, status int, body []byte) {
+	q.settle(it, StatusExpired, status, body)
+	q.logger.Warn("queued request expired", "id", it.ID, "attempts", it.Attempts)
+}
+
+// complete ends it with a gateway error. The caller must hold q.mu.
+func (q *Queue) complete(it *item, itemStatus string, status int, code errcodes.Code, message string) {
+	body, _ := json.Marshal(code.Response(message))
+	q.settle(it, itemStatus, status, body)
+}
+
+// settle records the response of it and wakes its waiters. The request itself
+// is no longer needed and is dropped. The caller must hold q.mu.
+func (q *Queue) settle(it *item, itemStatus string, status int, body []byte) {
+	it.Status = itemStatus
+	it.Response = &Response{StatusCode: status, Body: body}
+	now := q.now().Unix()
+	it.CompletedAt = &now
+	it.Body = nil
+	it.Header = nil
+	it.header = nil
+	q.records.WriteLogged(it.ID, it.record)
+	select {
+	case <-it.done:
+	default:
+		close(it.done)
+	}
+}
+
+// janitor expires requests queued for longer than the TTL and removes results
+// older than the TTL
+func (q *Queue) janitor(ctx context.Context) {
+	interval := min(q.opts.TTL/2, time.Minute)
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			q.sweep()
+		}
+	}
+}
+
+// sweep is one janitor pass
+func (q *Queue) sweep() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := q.now()
+	for priority, pending := range q.pending {
+		kept := pending[:0]
+		for _, it := range pending {
+			if now.Sub(time.Unix(it.CreatedAt, 0)) >= q.opts.TTL {
+				q.complete(it, StatusExpired, http.StatusServiceUnavailable, errcodes.UpstreamRateLimited, "The provider stayed saturated while the request was queued")
+				q.logger.Warn("queued request expired", "id", it.ID, "attempts", it.Attempts)
+				continue
+			}
+			kept = append(kept, it)
+		}
+		q.pending[priority] = kept
+	}
+	for id, it := range q.items {
+		if it.CompletedAt != nil && now.Sub(time.Unix(*it.CompletedAt, 0)) >= q.opts.TTL {
+			delete(q.items, id)
+			if err := q.records.Remove(id); err != nil {
+				q.logger.Warn("failed to remove queued request", "id", id, "error", err.Error())
+			}
+		}
+	}
+}
+
+// size returns the number of items not completed yet. The caller must hold
+// q.mu.
+func (q *Queue) size() int {
+	n := 0
+	for _, it := range q.items {
+		if it.Response == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// public returns the API view of it. The caller must hold q.mu.
+func (it *item) public() Item {
+	public := it.Item
+	if it.Response != nil {
+		response := *it.Response
+		public.Response = &response
+	}
+	return public
+}
+
+// sortByCreation orders items oldest first, by ID for equal timestamps
+func sortByCreation(items []*item) []*item {
+	for i := 1; i < len(items); i++ {
+		for j := i; j > 0 && (items[j].CreatedAt < items[j-1].CreatedAt ||
+			items[j].CreatedAt == items[j-1].CreatedAt && items[j].ID < items[j-1].ID); j-- {
+			items[j], items[j-1] = items[j-1], items[j]
+		}
+	}
+	return items
+}
+
+// GetHandler implements GET /v1/queue/:id. Requests queued by another caller
+// are reported as not found.
+func (q *Queue) GetHandler(c *gin.Context) {
+	it, ok := q.Get(c.Param("id"), tenants.Owner(c.Request.Context(), c.Request.Header))
+	if !ok {
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, "Queued request not found")
+		return
+	}
+	c.JSON(http.StatusOK, it)
+}
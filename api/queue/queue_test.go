@@ -0,0 +1,203 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+// fakeDispatcher answers requests with the responses of respond, recording
+// the bodies it dispatched in order
+type fakeDispatcher struct {
+	mu      sync.Mutex
+	bodies  []string
+	respond func(call int) (int, []byte)
+}
+
+func (d *fakeDispatcher) Dispatch(ctx context.Context, _ http.Header, _ string, body []byte) (int, []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !IsDispatch(ctx) {
+		return http.StatusInternalServerError, []byte(`{"error":"not marked as a queue dispatch"}`)
+	}
+	d.bodies = append(d.bodies, string(body))
+	if d.respond == nil {
+		return http.StatusOK, body
+	}
+	return d.respond(len(d.bodies))
+}
+
+func (d *fakeDispatcher) dispatched() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.bodies...)
+}
+
+func testOptions(dir string) Options {
+	return Options{Dir: dir, Workers: 1, MaxSize: 10, RetryInterval: 10 * time.Millisecond, TTL: time.Minute}
+}
+
+func rateLimited() (int, []byte) {
+	body, _ := json.Marshal(errcodes.UpstreamRateLimited.Response("Rate limited"))
+	return http.StatusTooManyRequests, body
+}
+
+func wait(t *testing.T, q *Queue, id string) Item {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	it, ok := q.Wait(ctx, id)
+	require.True(t, ok, "request %s did not complete", id)
+	return it
+}
+
+func TestQueueDispatchesInteractiveFirst(t *testing.T) {
+	d := &fakeDispatcher{}
+	q, err := New(d, logger.NewNoopLogger(), testOptions(t.TempDir()))
+	require.NoError(t, err)
+
+	batchHeader := http.Header{PriorityHeader: []string{PriorityBatch}}
+	first, err := q.Enqueue(context.Background(), batchHeader, "", []byte(`{"n":"batch-1"}`))
+	require.NoError(t, err)
+	assert.Equal(t, PriorityBatch, first.Priority)
+	_, err = q.Enqueue(context.Background(), batchHeader, "", []byte(`{"n":"batch-2"}`))
+	require.NoError(t, err)
+	last, err := q.Enqueue(context.Background(), http.Header{}, "", []byte(`{"n":"interactive"}`))
+	require.NoError(t, err)
+	assert.Equal(t, PriorityInteractive, last.Priority)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q.Start(ctx)
+
+	it := wait(t, q, first.ID)
+	assert.Equal(t, StatusCompleted, it.Status)
+	assert.Equal(t, http.StatusOK, it.Response.StatusCode)
+	assert.JSONEq(t, `{"n":"batch-1"}`, string(it.Response.Body))
+	assert.Equal(t, []string{`{"n":"interactive"}`, `{"n":"batch-1"}`}, d.dispatched()[:2])
+}
+
+func TestQueueRetriesWhileSaturated(t *testing.T) {
+	d := &fakeDispatcher{respond: func(call int) (int, []byte) {
+		if call < 3 {
+			return rateLimited()
+		}
+		return http.StatusOK, []byte(`{"id":"done"}`)
+	}}
+	q, err := New(d, logger.NewNoopLogger(), testOptions(t.TempDir()))
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q.Start(ctx)
+
+	item, err := q.Enqueue(context.Background(), http.Header{}, "", []byte(`{}`))
+	require.NoError(t, err)
+	it := wait(t, q, item.ID)
+	assert.Equal(t, StatusCompleted, it.Status)
+	assert.Equal(t, 3, it.Attempts)
+	assert.JSONEq(t, `{"id":"done"}`, string(it.Response.Body))
+}
+
+func TestQueueExpiresAfterTTL(t *testing.T) {
+	d := &fakeDispatcher{respond: func(int) (int, []byte) { return rateLimited() }}
+	opts := testOptions(t.TempDir())
+	opts.TTL = time.Second
+	q, err := New(d, logger.NewNoopLogger(), opts)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q.Start(ctx)
+
+	item, err := q.Enqueue(context.Background(), http.Header{}, "", []byte(`{}`))
+	require.NoError(t, err)
+	it := wait(t, q, item.ID)
+	assert.Equal(t, StatusExpired, it.Status)
+	assert.Equal(t, http.StatusTooManyRequests, it.Response.StatusCode)
+}
+
+func TestQueueFull(t *testing.T) {
+	opts := testOptions(t.TempDir())
+	opts.MaxSize = 1
+	q, err := New(&fakeDispatcher{}, logger.NewNoopLogger(), opts)
+	require.NoError(t, err)
+
+	_, err = q.Enqueue(context.Background(), http.Header{}, "", []byte(`{}`))
+	require.NoError(t, err)
+	_, err = q.Enqueue(context.Background(), http.Header{}, "", []byte(`{}`))
+	assert.ErrorIs(t, err, ErrFull)
+}
+
+func TestQueueResumesAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	q, err := New(&fakeDispatcher{}, logger.NewNoopLogger(), testOptions(dir))
+	require.NoError(t, err)
+	anonymous, err := q.Enqueue(context.Background(), http.Header{"X-Tenant-Id": []string{"acme"}}, "", []byte(`{"n":1}`))
+	require.NoError(t, err)
+	authenticated, err := q.Enqueue(context.Background(), http.Header{"Authorization": []string{"Bearer secret-token"}}, "", []byte(`{"n":2}`))
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(q.records.Path(authenticated.ID))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret-token", "credentials must not be persisted")
+
+	d := &fakeDispatcher{}
+	restarted, err := New(d, logger.NewNoopLogger(), testOptions(dir))
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	restarted.Start(ctx)
+
+	it := wait(t, restarted, anonymous.ID)
+	assert.Equal(t, StatusCompleted, it.Status)
+	it = wait(t, restarted, authenticated.ID)
+	assert.Equal(t, StatusFailed, it.Status)
+	assert.Equal(t, []string{`{"n":1}`}, d.dispatched())
+}
+
+func TestGetHandlerChecksOwner(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	q, err := New(&fakeDispatcher{}, logger.NewNoopLogger(), testOptions(t.TempDir()))
+	require.NoError(t, err)
+	item, err := q.Enqueue(context.Background(), http.Header{"Authorization": []string{"Bearer alice"}}, "", []byte(`{}`))
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.GET("/v1/queue/:id", q.GetHandler)
+	get := func(auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/queue/"+item.ID, nil)
+		req.Header.Set("Authorization", auth)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("Bearer alice")
+	require.Equal(t, http.StatusOK, w.Code)
+	var got Item
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, item.ID, got.ID)
+	assert.Equal(t, StatusQueued, got.Status)
+
+	assert.Equal(t, http.StatusNotFound, get("Bearer mallory").Code)
+}
+
+func TestSaturated(t *testing.T) {
+	assert.True(t, Saturated(http.StatusTooManyRequests, errcodes.UpstreamRateLimited.ID))
+	assert.True(t, Saturated(http.StatusBadGateway, errcodes.UpstreamUnreachable.ID))
+	assert.True(t, Saturated(http.StatusServiceUnavailable, errcodes.UpstreamUnavailable.ID))
+	assert.False(t, Saturated(http.StatusInternalServerError, errcodes.UpstreamUnavailable.ID))
+	assert.False(t, Saturated(http.StatusBadRequest, errcodes.UpstreamInvalidRequest.ID))
+	assert.False(t, Saturated(http.StatusTooManyRequests, errcodes.RequestLimitExceeded.ID), "the gateway's own limits are not provider saturation")
+}
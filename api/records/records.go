@@ -0,0 +1,91 @@
+// Package records keeps the state of the gateway's background work, such as
+// batches, queued requests, threads and agent jobs, as one JSON file per
+// record in a directory, so it survives restarts.
+package records
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+// Store persists records of type T in a directory, named by their ID
+type Store[T any] struct {
+	dir    string
+	what   string
+	logger logger.Logger
+}
+
+// New creates the directory dir if needed and returns a store of the
+// records kept in it. what names the records in errors and logs.
+func New[T any](dir, what string, logger logger.Logger) (*Store[T], error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create %s directory: %w", what, err)
+	}
+	return &Store[T]{dir: dir, what: what, logger: logger}, nil
+}
+
+// Path returns the path of the file of record id
+func (s *Store[T]) Path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// Write saves rec as record id, through a temporary file so a crash never
+// leaves a partial record
+func (s *Store[T]) Write(id string, rec T) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	tmp := s.Path(id) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o640); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path(id))
+}
+
+// WriteLogged is Write for state changes that cannot be reported to a
+// caller
+func (s *Store[T]) WriteLogged(id string, rec T) {
+	if err := s.Write(id, rec); err != nil {
+		s.logger.Error("failed to persist "+s.what, err, "id", id)
+	}
+}
+
+// Remove deletes record id. A record that does not exist is no error.
+func (s *Store[T]) Remove(id string) error {
+	if err := os.Remove(s.Path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Load reads every record of the directory and hands it to fn, in the order
+// of their IDs
+func (s *Store[T]) Load(fn func(rec T)) error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		var rec T
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return fmt.Errorf("read %s %s: %w", s.what, id, err)
+		}
+		fn(rec)
+	}
+	return nil
+}
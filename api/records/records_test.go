@@ -0,0 +1,43 @@
+package records
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+type record struct {
+	ID    string `json:"id"`
+	Count int    `json:"count"`
+}
+
+func TestStore(t *testing.T) {
+	log, err := logger.NewLogger("test")
+	require.NoError(t, err)
+	dir := filepath.Join(t.TempDir(), "records")
+	s, err := New[record](dir, "record", log)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Write("b", record{ID: "b", Count: 2}))
+	require.NoError(t, s.Write("a", record{ID: "a", Count: 1}))
+	require.NoError(t, s.Write("a", record{ID: "a", Count: 3}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o640))
+
+	var loaded []record
+	require.NoError(t, s.Load(func(rec record) { loaded = append(loaded, rec) }))
+	assert.Equal(t, []record{{ID: "a", Count: 3}, {ID: "b", Count: 2}}, loaded, "records are loaded in the order of their IDs, the last write winning")
+
+	require.NoError(t, s.Remove("a"))
+	require.NoError(t, s.Remove("a"), "removing a missing record is no error")
+	_, err = os.Stat(s.Path("a"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, os.WriteFile(s.Path("c"), []byte("{"), 0o640))
+	err = s.Load(func(record) {})
+	assert.ErrorContains(t, err, "read record c")
+}
@@ -0,0 +1,267 @@
+// Package resume keeps the events of streaming responses for a short while,
+// so a client whose connection dropped can reconnect with Last-Event-ID and
+// pick up where it left off instead of starting (and paying for) a new
+// generation.
+package resume
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LastEventIDHeader is sent by reconnecting SSE clients
+const LastEventIDHeader = "Last-Event-ID"
+
+// ErrNotFound is returned for streams that are unknown, expired or owned by
+// another caller
+var ErrNotFound = errors.New("stream not found or expired")
+
+// ErrGone is returned when the events after the requested one are no longer
+// buffered
+var ErrGone = errors.New("stream events are no longer buffered")
+
+// Store tracks the resumable streams
+type Store struct {
+	bufferSize int
+	ttl        time.Duration
+
+	mu      sync.Mutex
+	streams map[string]*Stream
+}
+
+// NewStore creates a store keeping up to bufferSize events per stream. Streams
+// stay resumable for ttl after they complete; running streams nobody follows
+// for ttl are cancelled.
+func NewStore(bufferSize int, ttl time.Duration) *Store {
+	return &Store{
+		bufferSize: max(bufferSize, 1),
+		ttl:        ttl,
+		streams:    make(map[string]*Stream),
+	}
+}
+
+// Open registers a new stream for the caller owner, see tenants.Owner.
+// cancel stops the generation feeding it and is called once nobody followed
+// it for the TTL. The feeding goroutine must Finish the stream.
+func (s *Store) Open(owner string, cancel context.CancelFunc) (*Stream, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	st := &Stream{
+		id:      "sse_" + hex.EncodeToString(b),
+		owner:   owner,
+		store:   s,
+		cancel:  cancel,
+		first:   1,
+		changed: make(chan struct{}),
+	}
+	s.mu.Lock()
+	s.streams[st.id] = st
+	s.mu.Unlock()
+	return st, nil
+}
+
+// Get returns stream id if owner opened it
+func (s *Store) Get(id, owner string) (*Stream, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.streams[id]
+	if !ok || st.owner != owner {
+		return nil, ErrNotFound
+	}
+	return st, nil
+}
+
+// Len returns the number of resumable streams
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.streams)
+}
+
+func (s *Store) remove(id string) {
+	s.mu.Lock()
+	delete(s.streams, id)
+	s.mu.Unlock()
+}
+
+// Stream is the buffered event sequence of one streaming response. Event n is
+// identified to clients as "<stream id>:<n>", starting at 1.
+type Stream struct {
+	id     string
+	owner  string
+	store  *Store
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	events    [][]byte
+	first     int
+	partial   []byte
+	pending   []byte
+	done      bool
+	changed   chan struct{}
+	followers int
+	idle      *time.Timer
+}
+
+// ID returns the stream identifier, the prefix of its event IDs
+func (st *Stream) ID() string {
+	return st.id
+}
+
+// Write appends raw SSE output. Lines are grouped into events at blank lines;
+// upstream id: lines are dropped since the gateway numbers events itself.
+func (st *Stream) Write(b []byte) (int, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.done {
+		return len(b), nil
+	}
+	st.partial = append(st.partial, b...)
+	for {
+		i := bytes.IndexByte(st.partial, '\n')
+		if i < 0 {
+			break
+		}
+		line := st.partial[:i+1]
+		st.partial = st.partial[i+1:]
+		if len(bytes.TrimRight(line, "\r\n")) == 0 {
+			st.flushEvent()
+			continue
+		}
+		if bytes.HasPrefix(line, []byte("id:")) {
+			continue
+		}
+		st.pending = append(st.pending, line...)
+	}
+	return len(b), nil
+}
+
+// Finish marks the stream complete. It stays resumable for the TTL.
+func (st *Stream) Finish() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.done {
+		return
+	}
+	if len(st.partial) > 0 && !bytes.HasPrefix(st.partial, []byte("id:")) {
+		st.pending = append(st.pending, st.partial...)
+		st.pending = append(st.pending, '\n')
+	}
+	st.partial = nil
+	st.flushEvent()
+	st.done = true
+	st.notify()
+	if st.idle != nil {
+		st.idle.Stop()
+	}
+	time.AfterFunc(st.store.ttl, func() { st.store.remove(st.id) })
+}
+
+// Follow calls write for every event after the event numbered after, then for
+// every new event until the stream completes, ctx is done or write returns
+// false. Following keeps the generation running.
+func (st *Stream) Follow(ctx context.Context, after int, write func(id string, event []byte) bool) error {
+	st.mu.Lock()
+	if after+1 < st.first {
+		st.mu.Unlock()
+		return ErrGone
+	}
+	st.followers++
+	if st.idle != nil {
+		st.idle.Stop()
+	}
+	st.mu.Unlock()
+	defer st.unfollow()
+
+	next := after + 1
+	for {
+		st.mu.Lock()
+		if next < st.first {
+			st.mu.Unlock()
+			return ErrGone
+		}
+		if next-st.first > len(st.events) {
+			st.mu.Unlock()
+			return ErrNotFound
+		}
+		events := st.events[next-st.first:]
+		done, changed := st.done, st.changed
+		st.mu.Unlock()
+
+		for _, event := range events {
+			if !write(st.id+":"+strconv.Itoa(next), event) {
+				return nil
+			}
+			next++
+		}
+		if len(events) > 0 {
+			continue
+		}
+		if done {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// unfollow arms the idle timer cancelling the generation once the last
+// follower left a running stream
+func (st *Stream) unfollow() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.followers--
+	if st.followers > 0 || st.done || st.cancel == nil {
+		return
+	}
+	st.idle = time.AfterFunc(st.store.ttl, st.cancel)
+}
+
+// flushEvent turns the pending lines into an event. The caller must hold
+// st.mu.
+func (st *Stream) flushEvent() {
+	if len(st.pending) == 0 {
+		return
+	}
+	event := append(st.pending, '\n')
+	st.pending = nil
+	// Events are only ever appended, so followers can read the slice they
+	// took outside the lock
+	if len(st.events) == st.store.bufferSize {
+		st.events = st.events[1:]
+		st.first++
+	}
+	st.events = append(st.events, event)
+	st.notify()
+}
+
+// notify wakes the followers. The caller must hold st.mu.
+func (st *Stream) notify() {
+	close(st.changed)
+	st.changed = make(chan struct{})
+}
+
+// ParseEventID splits an event ID into its stream ID and event number
+func ParseEventID(eventID string) (string, int, bool) {
+	id, n, ok := strings.Cut(strings.TrimSpace(eventID), ":")
+	if !ok || id == "" {
+		return "", 0, false
+	}
+	seq, err := strconv.Atoi(n)
+	if err != nil || seq < 0 {
+		return "", 0, false
+	}
+	return id, seq, true
+}
@@ -0,0 +1,123 @@
+package resume
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+)
+
+// collect follows stream after the event numbered after until it completes
+func collect(t *testing.T, stream *Stream, after int) ([]string, []string, error) {
+	t.Helper()
+	var ids, events []string
+	err := stream.Follow(context.Background(), after, func(id string, event []byte) bool {
+		ids = append(ids, id)
+		events = append(events, string(event))
+		return true
+	})
+	return ids, events, err
+}
+
+func TestStream_GroupsLinesIntoEvents(t *testing.T) {
+	store := NewStore(16, time.Minute)
+	stream, err := store.Open("owner", nil)
+	require.NoError(t, err)
+
+	_, _ = stream.Write([]byte("id: upstream-1\ndata: {\"a\""))
+	_, _ = stream.Write([]byte(":1}\n\nevent: ping\ndata: 2\n\n"))
+	_, _ = stream.Write([]byte("data: [DONE]"))
+	stream.Finish()
+
+	ids, events, err := collect(t, stream, 0)
+	require.NoError(t, err)
+	id := stream.ID()
+	assert.Equal(t, []string{id + ":1", id + ":2", id + ":3"}, ids)
+	assert.Equal(t, []string{"data: {\"a\":1}\n\n", "event: ping\ndata: 2\n\n", "data: [DONE]\n\n"}, events)
+
+	ids, _, err = collect(t, stream, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{id + ":3"}, ids)
+}
+
+func TestStream_BufferLimit(t *testing.T) {
+	store := NewStore(2, time.Minute)
+	stream, err := store.Open("owner", nil)
+	require.NoError(t, err)
+	for range 3 {
+		_, _ = stream.Write([]byte("data: x\n\n"))
+	}
+	stream.Finish()
+
+	_, _, err = collect(t, stream, 0)
+	assert.ErrorIs(t, err, ErrGone, "the first event was evicted")
+	ids, _, err := collect(t, stream, 1)
+	require.NoError(t, err)
+	assert.Len(t, ids, 2)
+	_, _, err = collect(t, stream, 7)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestStream_FollowsLiveEvents(t *testing.T) {
+	store := NewStore(16, time.Minute)
+	stream, err := store.Open("owner", nil)
+	require.NoError(t, err)
+
+	received := make(chan string, 2)
+	go func() {
+		_ = stream.Follow(context.Background(), 0, func(_ string, event []byte) bool {
+			received <- string(event)
+			return true
+		})
+		close(received)
+	}()
+
+	_, _ = stream.Write([]byte("data: 1\n\n"))
+	assert.Equal(t, "data: 1\n\n", <-received)
+	stream.Finish()
+	_, open := <-received
+	assert.False(t, open, "following ends with the stream")
+}
+
+func TestStream_CancelsUnfollowedGeneration(t *testing.T) {
+	store := NewStore(16, 10*time.Millisecond)
+	cancelled := make(chan struct{})
+	stream, err := store.Open("owner", func() { close(cancelled) })
+	require.NoError(t, err)
+
+	ctx, stop := context.WithCancel(context.Background())
+	stop()
+	require.NoError(t, stream.Follow(ctx, 0, func(string, []byte) bool { return true }))
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("the generation was not cancelled after the TTL without followers")
+	}
+}
+
+func TestStore_GetChecksOwner(t *testing.T) {
+	store := NewStore(16, time.Minute)
+	const alice = "alice"
+	stream, err := store.Open(alice, nil)
+	require.NoError(t, err)
+
+	_, err = store.Get(stream.ID(), alice)
+	require.NoError(t, err)
+	_, err = store.Get(stream.ID(), "mallory")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestParseEventID(t *testing.T) {
+	id, seq, ok := ParseEventID("sse_abc:12")
+	assert.True(t, ok)
+	assert.Equal(t, "sse_abc", id)
+	assert.Equal(t, 12, seq)
+
+	for _, invalid := range []string{"", "sse_abc", ":3", "sse_abc:x", "sse_abc:-1"} {
+		_, _, ok := ParseEventID(invalid)
+		assert.False(t, ok, invalid)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,6 +15,8 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	gin "github.com/gin-gonic/gin"
 	otelapi "go.opentelemetry.io/otel"
@@ -22,45 +25,92 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
 	trace "go.opentelemetry.io/otel/trace"
 
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
 	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	modeldefaults "github.com/inference-gateway/inference-gateway/api/modeldefaults"
+	modelpolicy "github.com/inference-gateway/inference-gateway/api/modelpolicy"
+	resume "github.com/inference-gateway/inference-gateway/api/resume"
+	structured "github.com/inference-gateway/inference-gateway/api/structured"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	think "github.com/inference-gateway/inference-gateway/api/think"
 	config "github.com/inference-gateway/inference-gateway/config"
 	mcp "github.com/inference-gateway/inference-gateway/internal/mcp"
 	proxymodifier "github.com/inference-gateway/inference-gateway/internal/proxy"
+	tokenizer "github.com/inference-gateway/inference-gateway/internal/tokenizer"
 	l "github.com/inference-gateway/inference-gateway/logger"
 	otel "github.com/inference-gateway/inference-gateway/otel"
+	availability "github.com/inference-gateway/inference-gateway/providers/availability"
+	backoff "github.com/inference-gateway/inference-gateway/providers/backoff"
 	client "github.com/inference-gateway/inference-gateway/providers/client"
 	constants "github.com/inference-gateway/inference-gateway/providers/constants"
 	core "github.com/inference-gateway/inference-gateway/providers/core"
+	modelcache "github.com/inference-gateway/inference-gateway/providers/modelcache"
 	registry "github.com/inference-gateway/inference-gateway/providers/registry"
 	routing "github.com/inference-gateway/inference-gateway/providers/routing"
 	types "github.com/inference-gateway/inference-gateway/providers/types"
 )
 
+// ParameterWarningsHeader lists the parameters of a chat completion request
+// that were clamped or dropped because the provider does not support them
+const ParameterWarningsHeader = "X-Parameter-Warnings"
+
 //go:generate mockgen -source=routes.go -destination=../tests/mocks/routes.go -package=mocks
 type Router interface {
 	ListModelsHandler(c *gin.Context)
 	ChatCompletionsHandler(c *gin.Context)
 	MessagesHandler(c *gin.Context)
+	ContextPackHandler(c *gin.Context)
+	TokenizeHandler(c *gin.Context)
 	ListToolsHandler(c *gin.Context)
+	ListMCPResourcesHandler(c *gin.Context)
+	ReadMCPResourceHandler(c *gin.Context)
+	ListMCPPromptsHandler(c *gin.Context)
+	GetMCPPromptHandler(c *gin.Context)
+	OllamaTagsHandler(c *gin.Context)
+	OllamaChatHandler(c *gin.Context)
+	OllamaListModelsHandler(c *gin.Context)
+	OllamaPullModelHandler(c *gin.Context)
+	OllamaDeleteModelHandler(c *gin.Context)
 	MetricsIngestionHandler(c *gin.Context)
 	ProxyHandler(c *gin.Context)
 	HealthcheckHandler(c *gin.Context)
 	NotFoundHandler(c *gin.Context)
+	// Reload applies a reloaded configuration to subsequent requests
+	Reload(cfg config.Config)
 }
 
 type RouterImpl struct {
-	cfg       config.Config
+	current   atomic.Pointer[config.Config]
 	logger    l.Logger
 	registry  registry.ProviderRegistry
 	client    client.Client
 	mcpClient mcp.MCPClientInterface
 	telemetry otel.OpenTelemetry
 	selector  *routing.Selector
+	semantic  *routing.SemanticRouter
+	// tokenizers count tokens locally for usage the providers do not report
+	tokenizers *tokenizer.Registry
+	// resume keeps streams resumable with Last-Event-ID, nil when disabled
+	resume *resume.Store
+	// backoff remembers the provider models that asked to be left alone
+	backoff *backoff.Tracker
+	// health remembers the outcome of the recent proxied provider calls
+	health *availability.Tracker
+	// models caches the model list of each provider per tenant
+	models *modelcache.Cache
+	// proxyTransport carries the proxied provider requests with the
+	// CLIENT_TLS_* settings and the connect timeout of their provider
+	proxyTransport http.RoundTripper
+	// modelDefaults holds the MODEL_DEFAULTS_PATH parameters, nil when unset
+	modelDefaults atomic.Pointer[modeldefaults.Config]
+	// modelPolicy decides which models callers may use
+	modelPolicy atomic.Pointer[modelpolicy.Policy]
+	// proxyPaths holds the PROXY_ALLOWED_PATHS patterns
+	proxyPaths atomic.Pointer[[]string]
 }
 
-type ErrorResponse struct {
-	Error string `json:"error"`
-}
+// ErrorResponse is the body of every gateway error, see api/errcodes
+type ErrorResponse = errcodes.Response
 
 type ResponseJSON struct {
 	Message string `json:"message"`
@@ -74,39 +124,98 @@ func NewRouter(
 	mcpClient mcp.MCPClientInterface,
 	telemetry otel.OpenTelemetry,
 	selector *routing.Selector,
+	semantic *routing.SemanticRouter,
 ) Router {
-	return &RouterImpl{
-		cfg,
-		logger,
-		providerRegistry,
-		httpClient,
-		mcpClient,
-		telemetry,
-		selector,
+	router := &RouterImpl{
+		logger:    logger,
+		registry:  providerRegistry,
+		client:    httpClient,
+		mcpClient: mcpClient,
+		telemetry: telemetry,
+		selector:  selector,
+		semantic:  semantic,
+		// Rank files are read once, so TOKENIZER_ENCODINGS_DIR needs a restart
+		tokenizers: tokenizer.NewRegistry(cfg.TokenizerEncodingsDir, logger),
+		backoff:    backoff.NewTracker(),
+		health:     availability.NewTracker(),
+		models:     modelcache.New(logger),
+	}
+	if cfg.StreamResumeEnable {
+		router.resume = resume.NewStore(cfg.StreamResumeBufferSize, cfg.StreamResumeTtl)
+	}
+	proxyTransport := http.DefaultTransport.(*http.Transport).Clone()
+	proxyTransport.DialContext = client.DialContext
+	router.proxyTransport = proxyTransport
+	if cfg.Client != nil {
+		transport, err := client.NewTLSTransport(proxyTransport, cfg.Client)
+		if err != nil {
+			logger.Error("failed to apply client tls settings to the proxy", err)
+		} else {
+			router.proxyTransport = transport
+		}
 	}
+	router.Reload(cfg)
+	return router
+}
+
+// Reload implements Router. Requests in flight keep the configuration they
+// started with. The model defaults and model policy files are read again and
+// the cached model lists are dropped.
+func (router *RouterImpl) Reload(cfg config.Config) {
+	router.current.Store(&cfg)
+	router.loadModelDefaults(cfg)
+	router.loadModelPolicy(cfg)
+	router.loadProxyPaths(cfg)
+	router.models.Purge()
+}
+
+// cfg returns the current configuration
+func (router *RouterImpl) cfg() *config.Config {
+	return router.current.Load()
+}
+
+// log returns the logger of the request of c, tagging every line with the
+// request ID
+func (router *RouterImpl) log(c *gin.Context) l.Logger {
+	return l.FromContext(c.Request.Context(), router.logger)
+}
+
+// providers returns the provider registry serving the tenant of ctx
+func (router *RouterImpl) providers(ctx context.Context) registry.ProviderRegistry {
+	return tenants.Registry(ctx, router.registry)
 }
 
 func (router *RouterImpl) NotFoundHandler(c *gin.Context) {
-	router.logger.Warn("route not found", "path", c.Request.URL.Path, "method", c.Request.Method)
-	c.JSON(http.StatusNotFound, ErrorResponse{Error: "Requested route is not found"})
+	router.log(c).Warn("route not found", "path", c.Request.URL.Path, "method", c.Request.Method)
+	errcodes.JSON(c, http.StatusNotFound, errcodes.RouteNotFound, "Requested route is not found")
 }
 
 func (router *RouterImpl) ProxyHandler(c *gin.Context) {
 	p := types.Provider(c.Param("provider"))
-	provider, err := router.registry.BuildProvider(p, router.client)
+	provider, err := router.providers(c.Request.Context()).BuildProvider(p, router.client)
 	if err != nil {
+		if errors.Is(err, registry.ErrProviderDisabled) {
+			router.log(c).Warn("request for a disabled provider", "provider", p)
+			errcodes.JSON(c, http.StatusServiceUnavailable, errcodes.ProviderDisabled, "Provider is disabled. Please use another provider.")
+			return
+		}
 		if strings.Contains(err.Error(), "token not configured") {
-			router.logger.Error("provider authentication required but api key not configured", err, "provider", p)
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Provider requires an API key. Please configure the provider's API key."})
+			router.log(c).Error("provider authentication required but api key not configured", err, "provider", p)
+			errcodes.JSON(c, http.StatusBadRequest, errcodes.ProviderTokenMissing, "Provider requires an API key. Please configure the provider's API key.")
 			return
 		}
-		router.logger.Error("provider not found or not supported", err, "provider", p)
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Provider not found. Please check the list of supported providers."})
+		router.log(c).Error("provider not found or not supported", err, "provider", p)
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.ProviderNotFound, "Provider not found. Please check the list of supported providers.")
+		return
+	}
+
+	if !router.checkProxyPath(c, p) || !router.checkProxyModel(c, p) {
 		return
 	}
 
-	if err := applyProviderAuth(c.Request, provider); err != nil {
-		c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Error: "Unsupported auth type"})
+	core.StripInternalHeaders(c.Request.Header)
+	if err := core.ApplyAuth(c.Request, provider); err != nil {
+		errcodes.JSON(c, http.StatusUnprocessableEntity, errcodes.ProviderAuthUnsupported, "Unsupported auth type")
 		return
 	}
 
@@ -123,57 +232,75 @@ func (router *RouterImpl) ProxyHandler(c *gin.Context) {
 }
 
 func handleStreamingRequest(c *gin.Context, provider core.IProvider, router *RouterImpl) {
+	if router.resumeStream(c) {
+		return
+	}
 	middlewares.SetSSEHeaders(c)
 
 	fullURL, err := constructProviderURL(provider, c.Param("path"), c.Request.URL.RawQuery)
 	if err != nil {
-		router.logger.Error("failed to construct provider url", err, "provider", provider.GetName())
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Failed to construct URL"})
+		router.log(c).Error("failed to construct provider url", err, "provider", provider.GetName())
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InternalError, "Failed to construct URL")
 		return
 	}
 
-	// Read request body with a 10MB size limit for now, to prevent abuse
-	// Will make it configurable later perhaps as a middleware
-	const maxBodySize = 10 << 20
-	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBodySize))
+	body, err := middlewares.ReadBody(c.Request.Body, router.cfg().Server.MaxRequestBytes)
 	if err != nil {
-		router.logger.Error("failed to read request body", err, "maxBodySize", maxBodySize)
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Failed to read request"})
-		return
-	}
-	if len(body) >= int(maxBodySize) {
-		c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: "Request body too large"})
+		if errors.Is(err, middlewares.ErrRequestBodyTooLarge) {
+			errcodes.JSON(c, http.StatusRequestEntityTooLarge, errcodes.RequestTooLarge, "Request body too large")
+			return
+		}
+		router.log(c).Error("failed to read request body", err, "maxBodySize", router.cfg().Server.MaxRequestBytes)
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to read request")
 		return
 	}
 
-	ctx := c.Request.Context()
-	upstreamReq, err := http.NewRequestWithContext(ctx, c.Request.Method, fullURL.String(), bytes.NewReader(body))
+	providerID := *provider.GetID()
+	ctx, stopStream := router.streamContext(c)
+	upstreamReq, err := http.NewRequestWithContext(router.upstreamContext(ctx, providerID), c.Request.Method, fullURL.String(), bytes.NewReader(body))
 	if err != nil {
-		router.logger.Error("failed to create upstream request", err, "method", c.Request.Method, "url", fullURL.String())
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create upstream request"})
+		stopStream()
+		router.log(c).Error("failed to create upstream request", err, "method", c.Request.Method, "url", fullURL.String())
+		errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to create upstream request")
 		return
 	}
 
 	upstreamReq.Header = c.Request.Header.Clone()
+	upstreamReq.Header.Del(resume.LastEventIDHeader)
 	otelapi.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(upstreamReq.Header))
 
-	resp, err := router.client.Do(upstreamReq)
+	resp, err := router.retryPolicy(providerID).Do(upstreamReq, router.client.Do)
+	router.recordProviderCall(upstreamReq, providerID, resp, err)
 	if err != nil {
-		router.logger.Error("failed to make upstream request", err, "url", fullURL.String())
-		c.JSON(http.StatusBadGateway, ErrorResponse{Error: "Failed to reach upstream server"})
+		stopStream()
+		router.log(c).Error("failed to make upstream request", err, "url", fullURL.String())
+		errcodes.JSON(c, http.StatusBadGateway, errcodes.UpstreamUnreachable, "Failed to reach upstream server")
 		return
 	}
+
+	// Only successful event streams are resumable; anything else is relayed
+	// as it comes
+	if router.resume != nil && resp.StatusCode < http.StatusBadRequest && strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		router.relayResumable(c, stopStream, func(w io.Writer) {
+			defer resp.Body.Close()
+			if _, err := io.Copy(w, resp.Body); err != nil && ctx.Err() == nil {
+				router.log(c).Error("failed to read stream", err, "url", fullURL.String())
+			}
+		})
+		return
+	}
+	defer stopStream()
 	defer resp.Body.Close()
 
 	reader := bufio.NewReaderSize(resp.Body, 4096)
 
 	c.Stream(func(w io.Writer) bool {
-		middlewares.ResetWriteDeadline(c, router.cfg.Server.WriteTimeout)
+		middlewares.ResetWriteDeadline(c, router.cfg().Server.WriteTimeout)
 
 		line, err := reader.ReadBytes('\n')
 		if err != nil {
 			if err != io.EOF {
-				router.logger.Error("failed to read stream", err,
+				router.log(c).Error("failed to read stream", err,
 					"url", fullURL.String(),
 					"method", c.Request.Method)
 			}
@@ -184,12 +311,12 @@ func handleStreamingRequest(c *gin.Context, provider core.IProvider, router *Rou
 			return true
 		}
 
-		if router.cfg.Environment == "development" {
+		if router.cfg().Environment == "development" {
 			shouldLog := len(line) > 512 ||
 				(c.Param("provider") != "" && len(line) > 0 && (len(line)%10 == 0))
 
 			if shouldLog {
-				router.logger.Debug("stream chunk",
+				router.log(c).Debug("stream chunk",
 					"provider", c.Param("provider"),
 					"bytes", len(line),
 					"data_preview", func() string {
@@ -204,7 +331,7 @@ func handleStreamingRequest(c *gin.Context, provider core.IProvider, router *Rou
 		}
 
 		if _, err := w.Write(line); err != nil {
-			router.logger.Error("failed to write response", err,
+			router.log(c).Error("failed to write response", err,
 				"bytes", len(line))
 			return false
 		}
@@ -220,21 +347,36 @@ func handleStreamingRequest(c *gin.Context, provider core.IProvider, router *Rou
 func handleProxyRequest(c *gin.Context, provider core.IProvider, router *RouterImpl) {
 	fullURL, err := constructProviderURL(provider, c.Param("path"), c.Request.URL.RawQuery)
 	if err != nil {
-		router.logger.Error("failed to construct provider url", err, "provider", provider.GetName())
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Failed to construct URL"})
+		router.log(c).Error("failed to construct provider url", err, "provider", provider.GetName())
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InternalError, "Failed to construct URL")
+		return
+	}
+	providerID := *provider.GetID()
+	policy := router.retryPolicy(providerID)
+	if policy.MaxRetries > 0 && !router.replayableBody(c) {
 		return
 	}
-	proxy := &httputil.ReverseProxy{}
+	proxy := &httputil.ReverseProxy{Transport: policy.Transport(router.proxyTransport)}
+
+	ctx := router.upstreamContext(c.Request.Context(), providerID)
+	if timeout := router.configuredTimeout(providerID); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 
 	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
-		router.logger.Error("proxy request failed", err, "url", fullURL.String())
+		router.recordProviderCall(r, providerID, nil, err)
+		router.log(c).Error("proxy request failed", err, "url", fullURL.String())
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadGateway)
-		err = json.NewEncoder(w).Encode(ErrorResponse{
-			Error: fmt.Sprintf("Failed to reach upstream server: %v", err),
-		})
+		status, response := http.StatusBadGateway, errcodes.UpstreamUnreachable.Response(fmt.Sprintf("Failed to reach upstream server: %v", err))
+		if errors.Is(err, context.DeadlineExceeded) {
+			status, response = http.StatusGatewayTimeout, errcodes.UpstreamTimeout.Response("Provider did not answer within its timeout")
+		}
+		w.WriteHeader(status)
+		err = json.NewEncoder(w).Encode(response)
 		if err != nil {
-			router.logger.Error("failed to write error response", err)
+			router.log(c).Error("failed to write error response", err)
 		}
 	}
 
@@ -245,52 +387,38 @@ func handleProxyRequest(c *gin.Context, provider core.IProvider, router *RouterI
 		pr.Out.Header = pr.In.Header.Clone()
 		pr.Out.Header.Set("Content-Type", "application/json")
 		pr.Out.Header.Set("Accept", "application/json")
+		if requestID, ok := pr.In.Context().Value(types.RequestIDContextKey).(string); ok && requestID != "" {
+			pr.Out.Header.Set("X-Request-ID", requestID)
+		}
 		otelapi.GetTextMapPropagator().Inject(pr.Out.Context(), propagation.HeaderCarrier(pr.Out.Header))
 
-		if router.cfg.Environment == "development" {
-			reqModifier := proxymodifier.NewDevRequestModifier(router.logger, &router.cfg)
+		if router.cfg().Environment == "development" {
+			reqModifier := proxymodifier.NewDevRequestModifier(router.logger, router.cfg())
 			if err := reqModifier.Modify(pr.Out); err != nil {
-				router.logger.Error("failed to modify request", err)
+				router.log(c).Error("failed to modify request", err)
 				return
 			}
 		}
 	}
 
-	if router.cfg.Environment == "development" {
-		devModifier := proxymodifier.NewDevResponseModifier(router.logger)
-		proxy.ModifyResponse = devModifier.Modify
-	}
-
-	proxy.ServeHTTP(&middlewares.DeadlineResetWriter{ResponseWriter: c.Writer, Timeout: router.cfg.Server.WriteTimeout}, c.Request)
-}
-
-// applyProviderAuth sets the provider's auth credential (header or query
-// param) and extra headers on req. An unrecognized auth type is returned as an
-// error so misconfigured providers fail loudly instead of sending
-// unauthenticated requests upstream.
-func applyProviderAuth(req *http.Request, provider core.IProvider) error {
-	token := provider.GetToken()
-	switch provider.GetAuthType() {
-	case constants.AuthTypeBearer:
-		req.Header.Set("Authorization", "Bearer "+token)
-	case constants.AuthTypeXheader:
-		req.Header.Set("x-api-key", token)
-	case constants.AuthTypeQuery:
-		query := req.URL.Query()
-		query.Set("key", token)
-		req.URL.RawQuery = query.Encode()
-	case constants.AuthTypeNone:
-		// Do Nothing
-	default:
-		return fmt.Errorf("unsupported auth type %q", provider.GetAuthType())
+	var devModifier proxymodifier.ResponseModifier
+	if router.cfg().Environment == "development" {
+		devModifier = proxymodifier.NewDevResponseModifier(router.logger)
 	}
-
-	for key, values := range provider.GetExtraHeaders() {
-		for _, value := range values {
-			req.Header.Add(key, value)
+	proxy.ModifyResponse = func(resp *http.Response) error {
+		router.recordProviderCall(resp.Request, providerID, resp, nil)
+		// The provider's own request ID is kept apart from the gateway's one
+		if upstreamID := resp.Header.Get("X-Request-ID"); upstreamID != "" {
+			resp.Header.Del("X-Request-ID")
+			resp.Header.Set("X-Upstream-Request-ID", upstreamID)
+		}
+		if devModifier != nil {
+			return devModifier.Modify(resp)
 		}
+		return nil
 	}
-	return nil
+
+	proxy.ServeHTTP(&middlewares.DeadlineResetWriter{ResponseWriter: c.Writer, Timeout: router.cfg().Server.WriteTimeout}, c.Request.WithContext(ctx))
 }
 
 // constructProviderURL builds the provider URL consistently to avoid path duplication.
@@ -312,7 +440,7 @@ func constructProviderURL(provider core.IProvider, pathParam, rawQuery string) (
 }
 
 func (router *RouterImpl) HealthcheckHandler(c *gin.Context) {
-	router.logger.Debug("healthcheck")
+	router.log(c).Debug("healthcheck")
 	c.JSON(http.StatusOK, ResponseJSON{Message: "OK"})
 }
 
@@ -363,6 +491,11 @@ func (router *RouterImpl) renderModelsResponse(c *gin.Context, resp types.ListMo
 			resp.Data[i].Pricing = nil
 		}
 	}
+	if !slices.Contains(includeKeys, string(types.ListModelsParamsIncludeCapabilities)) {
+		for i := range resp.Data {
+			resp.Data[i].Capabilities = nil
+		}
+	}
 
 	if len(includeKeys) == 0 {
 		c.JSON(http.StatusOK, resp)
@@ -371,15 +504,15 @@ func (router *RouterImpl) renderModelsResponse(c *gin.Context, resp types.ListMo
 
 	raw, err := json.Marshal(resp)
 	if err != nil {
-		router.logger.Error("failed to marshal models response", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to encode response"})
+		router.log(c).Error("failed to marshal models response", err)
+		errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to encode response")
 		return
 	}
 
 	var envelope map[string]any
 	if err := json.Unmarshal(raw, &envelope); err != nil {
-		router.logger.Error("failed to decode models response", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to encode response"})
+		router.log(c).Error("failed to decode models response", err)
+		errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to encode response")
 		return
 	}
 
@@ -410,9 +543,17 @@ func (router *RouterImpl) renderModelsResponse(c *gin.Context, resp types.ListMo
 //   - provider (query): Optional. When specified, returns models from only that provider.
 //     If not specified, returns models from all configured providers.
 //   - include (query): Optional. Comma-separated list of extra per-model metadata
-//     fields to include (context_window, pricing). Keys are trimmed and
+//     fields to include (context_window, pricing, capabilities). Keys are trimmed and
 //     de-duplicated; an unknown key returns 400. Requested-but-unresolved keys are
 //     returned as explicit null. When omitted, no metadata fields are added.
+//   - include_unavailable (query): Optional. The models of providers failing most of
+//     their recent calls (PROVIDER_HEALTH_MIN_FAILURES) are left out unless true, in
+//     which case every model is listed with an available field.
+//   - owned_by, capability (query): Optional. Only list the models with that owner
+//     and supporting all the comma-separated capabilities (tools, vision, json_mode,
+//     reasoning); models with unknown capabilities are left out.
+//   - limit, after (query): Optional. Paginate the models sorted by ID: at most limit
+//     models whose ID sorts after the after cursor, with has_more, first_id and last_id.
 //
 // Response format:
 //
@@ -435,41 +576,66 @@ func (router *RouterImpl) renderModelsResponse(c *gin.Context, resp types.ListMo
 func (router *RouterImpl) ListModelsHandler(c *gin.Context) {
 	includeKeys, err := parseIncludeParam(c.Query("include"))
 	if err != nil {
-		router.logger.Error("invalid include parameter", err)
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		router.log(c).Error("invalid include parameter", err)
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, err.Error())
+		return
+	}
+
+	includeUnavailable := false
+	if value := c.Query("include_unavailable"); value != "" {
+		if includeUnavailable, err = strconv.ParseBool(value); err != nil {
+			router.log(c).Error("invalid include_unavailable parameter", err)
+			errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "include_unavailable must be true or false")
+			return
+		}
+	}
+
+	query, err := parseModelsQuery(c)
+	if err != nil {
+		router.log(c).Error("invalid models query", err)
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, err.Error())
 		return
 	}
 
 	providerID := types.Provider(c.Query("provider"))
 	if providerID != "" {
-		provider, err := router.registry.BuildProvider(providerID, router.client)
+		provider, err := router.providers(c.Request.Context()).BuildProvider(providerID, router.client)
 		if err != nil {
+			if errors.Is(err, registry.ErrProviderDisabled) {
+				router.log(c).Warn("request for a disabled provider", "provider", providerID)
+				errcodes.JSON(c, http.StatusServiceUnavailable, errcodes.ProviderDisabled, "Provider is disabled. Please use another provider.")
+				return
+			}
 			if strings.Contains(err.Error(), "token not configured") {
-				router.logger.Error("provider authentication required but api key not configured", err, "provider", providerID)
-				c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Provider requires an API key. Please configure the provider's API key."})
+				router.log(c).Error("provider authentication required but api key not configured", err, "provider", providerID)
+				errcodes.JSON(c, http.StatusBadRequest, errcodes.ProviderTokenMissing, "Provider requires an API key. Please configure the provider's API key.")
 				return
 			}
-			router.logger.Error("provider not found or not supported", err, "provider", providerID)
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Provider not found. Please check the list of supported providers."})
+			router.log(c).Error("provider not found or not supported", err, "provider", providerID)
+			errcodes.JSON(c, http.StatusBadRequest, errcodes.ProviderNotFound, "Provider not found. Please check the list of supported providers.")
 			return
 		}
 
-		ctx, cancel := context.WithTimeout(c.Request.Context(), router.cfg.Server.ReadTimeout)
+		ctx, cancel := context.WithTimeout(c.Request.Context(), router.cfg().Server.ReadTimeout)
 		defer cancel()
 
-		response, err := provider.ListModels(ctx)
+		response, err := router.listProviderModels(ctx, providerID, provider)
 		if err != nil {
 			if ctx.Err() == context.DeadlineExceeded {
-				router.logger.Error("request timed out", err, "provider", provider.GetName())
-				c.JSON(http.StatusGatewayTimeout, ErrorResponse{Error: "Request timed out"})
+				router.log(c).Error("request timed out", err, "provider", provider.GetName())
+				errcodes.JSON(c, http.StatusGatewayTimeout, errcodes.UpstreamTimeout, "Request timed out")
 				return
 			}
-			router.logger.Error("failed to list models", err, "provider", provider.GetName())
-			c.JSON(http.StatusBadGateway, ErrorResponse{Error: "Failed to list models"})
+			router.log(c).Error("failed to list models", err, "provider", provider.GetName())
+			errcodes.JSON(c, http.StatusBadGateway, errcodes.ModelListFailed, "Failed to list models")
 			return
 		}
 
-		response.Data = routing.FilterModels(response.Data, router.cfg.AllowedModels, router.cfg.DisallowedModels)
+		response.Data = router.filterModels(c.Request.Context(), response.Data)
+		response.Data = tenants.FilterModels(ctx, router.registry, response.Data)
+		response.Data = router.filterAvailableModels(response.Data, includeUnavailable)
+		response.Data = query.filter(response.Data)
+		query.paginate(&response)
 
 		if slices.Contains(includeKeys, string(types.ListModelsParamsIncludeContextWindow)) {
 			router.resolveContextWindows(ctx, response.Data)
@@ -477,67 +643,286 @@ func (router *RouterImpl) ListModelsHandler(c *gin.Context) {
 
 		router.renderModelsResponse(c, response, includeKeys)
 	} else {
-		var wg sync.WaitGroup
-		providersCfg := router.cfg.Providers
+		ctx, cancel := context.WithTimeout(c.Request.Context(), router.cfg().Server.ReadTimeout)
+		defer cancel()
 
-		ch := make(chan types.ListModelsResponse, len(providersCfg))
+		allModels, failed := router.listAllModels(ctx)
+		allModels = router.filterModels(ctx, allModels)
+		allModels = tenants.FilterModels(ctx, router.registry, allModels)
+		allModels = router.filterAvailableModels(allModels, includeUnavailable)
 
-		ctx, cancel := context.WithTimeout(c.Request.Context(), router.cfg.Server.ReadTimeout)
-		defer cancel()
+		unifiedResponse := types.ListModelsResponse{
+			Object: "list",
+			Data:   query.filter(allModels),
+		}
+		if len(failed) > 0 {
+			unifiedResponse.FailedProviders = &failed
+		}
+		query.paginate(&unifiedResponse)
 
-		for providerID := range providersCfg {
-			wg.Add(1)
-			go func(id types.Provider) {
-				defer wg.Done()
+		if slices.Contains(includeKeys, string(types.ListModelsParamsIncludeContextWindow)) {
+			router.resolveContextWindows(ctx, unifiedResponse.Data)
+		}
 
-				provider, err := router.registry.BuildProvider(id, router.client)
-				if err != nil {
-					router.logger.Error("failed to create provider", err, "provider", id)
-					return
-				}
+		router.renderModelsResponse(c, unifiedResponse, includeKeys)
+	}
+}
 
-				response, err := provider.ListModels(ctx)
-				if err != nil {
-					if ctx.Err() == context.DeadlineExceeded {
-						router.logger.Error("request timed out", err, "provider", id)
-						return
-					}
-					router.logger.Error("failed to list models", err, "provider", id)
-					return
-				}
+// listAllModels fetches the models of every configured provider concurrently,
+// each within MODELS_PROVIDER_TIMEOUT. Providers that fail to respond in time
+// or at all are logged, skipped and returned as failed so one unavailable
+// upstream neither hides nor delays the others; disabled providers and those
+// that cannot be built are skipped. The models are never nil and are not
+// filtered by the model policy.
+func (router *RouterImpl) listAllModels(ctx context.Context) ([]types.Model, []types.FailedProvider) {
+	log := l.FromContext(ctx, router.logger)
+	timeout := router.cfg().ModelsProviderTimeout
+	start := time.Now()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		models  = make([]types.Model, 0)
+		failed  []types.FailedProvider
+		slowest types.Provider
+		longest time.Duration
+	)
+	for providerID := range router.cfg().Providers {
+		wg.Go(func() {
+			provider, err := router.providers(ctx).BuildProvider(providerID, router.client)
+			if errors.Is(err, registry.ErrProviderDisabled) {
+				return
+			}
+			if err != nil {
+				log.Error("failed to create provider", err, "provider", providerID)
+				return
+			}
+
+			providerCtx, cancel := ctx, context.CancelFunc(func() {})
+			if timeout > 0 {
+				providerCtx, cancel = context.WithTimeout(ctx, timeout)
+			}
+			defer cancel()
+
+			providerStart := time.Now()
+			response, err := router.listProviderModels(providerCtx, providerID, provider)
+			elapsed := time.Since(providerStart)
 
-				if response.Data == nil {
-					response.Data = make([]types.Model, 0)
+			mu.Lock()
+			defer mu.Unlock()
+			if elapsed > longest {
+				slowest, longest = providerID, elapsed
+			}
+			if err != nil {
+				failure := types.FailedProvider{Provider: providerID, Code: errcodes.ModelListFailed.ID, Error: "Failed to list models"}
+				if errors.Is(providerCtx.Err(), context.DeadlineExceeded) {
+					failure.Code, failure.Error = errcodes.UpstreamTimeout.ID, fmt.Sprintf("Listing models timed out after %s", timeout)
 				}
-				ch <- response
-			}(providerID)
+				log.Error("failed to list models", err, "provider", providerID, "duration", elapsed)
+				failed = append(failed, failure)
+				return
+			}
+			models = append(models, response.Data...)
+		})
+	}
+	wg.Wait()
+
+	slices.SortFunc(failed, func(a, b types.FailedProvider) int {
+		return strings.Compare(string(a.Provider), string(b.Provider))
+	})
+	log.Debug("listed models of all providers", "models", len(models), "failed", len(failed),
+		"duration", time.Since(start), "slowest_provider", slowest, "slowest_duration", longest)
+	return models, failed
+}
+
+// resolveChatProvider resolves the provider for a chat completion request:
+// it applies logical model routing, then semantic routing, determines the provider from the
+// ?provider= query parameter or the model prefix, enforces the model policy
+// and strips images for models without vision support. On
+// success req.Model holds the upstream model name; otherwise an error response
+// has already been written and ok is false.
+func (router *RouterImpl) resolveChatProvider(c *gin.Context, req *types.CreateChatCompletionRequest) (core.IProvider, types.Provider, bool) {
+	model := req.Model
+	originalModel := req.Model
+	providerID := types.Provider(c.Query("provider"))
+
+	var routedProvider, routedModel string
+	if router.selector != nil && providerID == "" {
+		if dep, ok := router.selector.SelectFor(model, stickyKey(c), router.deploymentAvailable); ok {
+			providerID = types.Provider(dep.Provider)
+			model = dep.Model
+			routedProvider, routedModel = dep.Provider, dep.Model
+			router.log(c).Debug("routed logical model", "alias", originalModel, "provider", dep.Provider, "model", dep.Model)
 		}
+	}
 
-		wg.Wait()
-		close(ch)
+	if router.semantic != nil && providerID == "" && routedProvider == "" && router.semantic.Applies(model) {
+		model = router.routeByIntent(c, req.Messages, model)
+	}
 
-		var allModels []types.Model
-		for response := range ch {
-			allModels = append(allModels, response.Data...)
+	if providerID == "" {
+		var providerPtr *types.Provider
+		providerPtr, model = routing.DetermineProviderAndModelName(model)
+		if providerPtr == nil {
+			router.log(c).Error("unable to determine provider for model", nil, "model", req.Model)
+			errcodes.JSON(c, http.StatusBadRequest, errcodes.ProviderUnresolved, "Unable to determine provider for model. Please specify a provider using the ?provider= query parameter or use the provider/model format (e.g., openai/gpt-4).")
+			return nil, "", false
 		}
+		providerID = *providerPtr
+	}
+	req.Model = model
+
+	if code, message, ok := router.checkModelPolicy(c.Request.Context(), originalModel, providerID, model); !ok {
+		router.log(c).Error("model rejected by the model policy", nil, "model", originalModel, "provider", providerID, "code", code.Name)
+		errcodes.JSON(c, http.StatusForbidden, code, message)
+		return nil, "", false
+	}
+	if !tenants.AllowsModel(c.Request.Context(), router.registry, originalModel) {
+		router.log(c).Error("model not allowed for tenant", nil, "model", originalModel, "tenant", tenants.FromContext(c.Request.Context()))
+		errcodes.JSON(c, http.StatusForbidden, errcodes.TenantModelNotAllowed, "Model not allowed for this tenant. Please check the list of allowed models.")
+		return nil, "", false
+	}
+
+	if remaining := router.backingOff(providerID, model); remaining > 0 {
+		router.log(c).Warn("provider is backing off, rejecting request", "provider", providerID, "model", model, "retry_after", remaining)
+		c.Header("Retry-After", backoff.Seconds(remaining))
+		errcodes.JSON(c, http.StatusTooManyRequests, errcodes.UpstreamRateLimited, "The provider asked to back off. Please retry after the number of seconds in the Retry-After header.")
+		return nil, "", false
+	}
 
-		if allModels == nil {
-			allModels = make([]types.Model, 0)
+	provider, err := router.providers(c.Request.Context()).BuildProvider(providerID, router.client)
+	if err != nil {
+		if errors.Is(err, registry.ErrProviderDisabled) {
+			router.log(c).Warn("request for a disabled provider", "provider", providerID)
+			errcodes.JSON(c, http.StatusServiceUnavailable, errcodes.ProviderDisabled, "Provider is disabled. Please use another provider.")
+			return nil, "", false
 		}
+		if strings.Contains(err.Error(), "token not configured") {
+			router.log(c).Error("provider requires authentication but no api key was configured", err, "provider", providerID)
+			errcodes.JSON(c, http.StatusBadRequest, errcodes.ProviderTokenMissing, "Provider requires an API key. Please configure the provider's API key.")
+			return nil, "", false
+		}
+		router.log(c).Error("provider not found or not supported", err, "provider", providerID)
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.ProviderNotFound, "Provider not found. Please check the list of supported providers.")
+		return nil, "", false
+	}
 
-		allModels = routing.FilterModels(allModels, router.cfg.AllowedModels, router.cfg.DisallowedModels)
+	if router.cfg().EnableVision {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), router.cfg().Server.ReadTimeout)
+		defer cancel()
 
-		if slices.Contains(includeKeys, string(types.ListModelsParamsIncludeContextWindow)) {
-			router.resolveContextWindows(ctx, allModels)
+		hasImageContent := false
+		imageCount := 0
+		for _, message := range req.Messages {
+			if message.HasImageContent() {
+				hasImageContent = true
+				imageCount++
+			}
 		}
 
-		unifiedResponse := types.ListModelsResponse{
-			Object: "list",
-			Data:   allModels,
+		if hasImageContent {
+			supportsVision, err := provider.SupportsVision(ctx, req.Model)
+			if err != nil {
+				router.log(c).Error("failed to check vision support", err, "provider", providerID, "model", req.Model)
+				errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to check model capabilities")
+				return nil, "", false
+			}
+			if !supportsVision {
+				router.log(c).Info("filtering images from non-vision model request",
+					"provider", providerID,
+					"model", req.Model,
+					"messagesWithImages", imageCount)
+
+				for i := range req.Messages {
+					if req.Messages[i].HasImageContent() {
+						if err := req.Messages[i].StripImageContent(); err != nil {
+							router.log(c).Error("failed to strip image content from message", err)
+							errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to process message content")
+							return nil, "", false
+						}
+					}
+				}
+
+				router.log(c).Debug("images stripped from request, continuing with text-only content")
+			}
 		}
+	}
 
-		router.renderModelsResponse(c, unifiedResponse, includeKeys)
+	if routedProvider != "" {
+		c.Header("X-Selected-Provider", routedProvider)
+		c.Header("X-Selected-Model", routedModel)
 	}
+
+	return provider, providerID, true
+}
+
+// prepareChatRequest runs the steps between provider resolution and dispatch
+// of a chat completion request, whichever API it came in through: model
+// defaults, extra_body validation, safety settings, prompt cache breakpoints
+// and parameter normalization. ctx bounds the safety moderation pre-check.
+// If the request is rejected an error response has already been written and
+// ok is false.
+func (router *RouterImpl) prepareChatRequest(ctx context.Context, c *gin.Context, providerID types.Provider, req *types.CreateChatCompletionRequest) bool {
+	if !router.applyModelDefaults(c, providerID, req) {
+		return false
+	}
+	if err := core.ValidateExtraBody(providerID, *req); err != nil {
+		router.log(c).Error("invalid extra_body", err, "provider", providerID)
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, err.Error())
+		return false
+	}
+	if !router.applySafetySettings(ctx, c, providerID, req) {
+		return false
+	}
+	router.applyPromptCache(c, providerID, req)
+	if warnings := core.NormalizeParams(providerID, req); len(warnings) > 0 {
+		c.Header(ParameterWarningsHeader, strings.Join(warnings, ", "))
+		router.log(c).Debug("normalized request parameters", "provider", providerID, "model", req.Model, "warnings", warnings)
+	}
+	return true
+}
+
+// stickyKey identifies the session or user a sticky weighted pool keeps on
+// the same deployment: the X-Session-ID header, else the subject of the
+// verified ID token. It is empty for anonymous requests without a session.
+func stickyKey(c *gin.Context) string {
+	if session := c.GetHeader("X-Session-ID"); session != "" {
+		return "session:" + session
+	}
+	if subject, _ := c.Request.Context().Value(types.AuthSubjectContextKey).(string); subject != "" {
+		return "user:" + subject
+	}
+	return ""
+}
+
+// routeByIntent returns the model of the semantic route matching the last
+// user message, or model when no route is similar enough. Embedding failures
+// are logged and fall back to model too, so semantic routing never fails a
+// request.
+func (router *RouterImpl) routeByIntent(c *gin.Context, messages []types.Message, model string) string {
+	var prompt string
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == types.User {
+			prompt = messages[i].TextContent()
+			break
+		}
+	}
+	if strings.TrimSpace(prompt) == "" {
+		return model
+	}
+
+	route, score, ok, err := router.semantic.Route(c.Request.Context(), prompt)
+	if err != nil {
+		router.log(c).Warn("semantic routing failed, using requested model", "model", model, "error", err.Error())
+		return model
+	}
+	if !ok {
+		router.log(c).Debug("no semantic route matched, using requested model", "model", model, "score", score)
+		return model
+	}
+	router.log(c).Debug("routed by intent", "requested_model", model, "route", route.Name, "model", route.Model, "score", score)
+	c.Header("X-Semantic-Route", route.Name)
+	return route.Model
 }
 
 // ChatCompletionsHandler implements an OpenAI-compatible API endpoint
@@ -594,201 +979,228 @@ func (router *RouterImpl) ListModelsHandler(c *gin.Context) {
 // built for OpenAI's API to work seamlessly with the Inference Gateway's multi-provider
 // architecture.
 func (router *RouterImpl) ChatCompletionsHandler(c *gin.Context) {
+	if router.resumeStream(c) {
+		return
+	}
+
 	var req types.CreateChatCompletionRequest
 
 	if mcpRequest, exists := c.Get(middlewares.MCPBypassHeader); exists {
 		if parsedRequest, ok := mcpRequest.(*types.CreateChatCompletionRequest); ok {
 			req = *parsedRequest
 		} else {
-			router.logger.Error("invalid mcp request type in context", nil)
-			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+			router.log(c).Error("invalid mcp request type in context", nil)
+			errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Internal server error")
 			return
 		}
 	} else {
 		if err := c.ShouldBindJSON(&req); err != nil {
-			router.logger.Error("failed to decode request", err)
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Failed to decode request"})
-			return
-		}
-	}
-
-	model := req.Model
-	originalModel := req.Model
-	providerID := types.Provider(c.Query("provider"))
-
-	var routedProvider, routedModel string
-	if router.selector != nil && providerID == "" {
-		if dep, ok := router.selector.Select(model); ok {
-			providerID = types.Provider(dep.Provider)
-			model = dep.Model
-			routedProvider, routedModel = dep.Provider, dep.Model
-			router.logger.Debug("routed logical model", "alias", originalModel, "provider", dep.Provider, "model", dep.Model)
-		}
-	}
-
-	if providerID == "" {
-		var providerPtr *types.Provider
-		providerPtr, model = routing.DetermineProviderAndModelName(model)
-		if providerPtr == nil {
-			router.logger.Error("unable to determine provider for model", nil, "model", req.Model)
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Unable to determine provider for model. Please specify a provider using the ?provider= query parameter or use the provider/model format (e.g., openai/gpt-4)."})
+			router.log(c).Error("failed to decode request", err)
+			errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to decode request")
 			return
 		}
-		providerID = *providerPtr
 	}
-	req.Model = model
 
-	if allowed := routing.ParseModelSet(router.cfg.AllowedModels); len(allowed) > 0 {
-		if !routing.ModelMatches(allowed, originalModel) {
-			router.logger.Error("model not in allowed list", nil, "model", originalModel, "allowed_models", router.cfg.AllowedModels)
-			c.JSON(http.StatusForbidden, ErrorResponse{Error: "Model not allowed. Please check the list of allowed models."})
-			return
-		}
-	} else if disallowed := routing.ParseModelSet(router.cfg.DisallowedModels); len(disallowed) > 0 {
-		if routing.ModelMatches(disallowed, originalModel) {
-			router.logger.Error("model is disallowed", nil, "model", originalModel, "disallowed_models", router.cfg.DisallowedModels)
-			c.JSON(http.StatusForbidden, ErrorResponse{Error: "Model is disallowed. Please use a different model."})
-			return
-		}
+	if req.MCPPrompt != nil {
+		// The MCP middleware expands mcp_prompt when MCP is enabled
+		errcodes.JSON(c, http.StatusForbidden, errcodes.FeatureDisabled, "mcp_prompt requires MCP to be enabled")
+		return
 	}
 
-	provider, err := router.registry.BuildProvider(providerID, router.client)
-	if err != nil {
-		if strings.Contains(err.Error(), "token not configured") {
-			router.logger.Error("provider requires authentication but no api key was configured", err, "provider", providerID)
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Provider requires an API key. Please configure the provider's API key."})
-			return
-		}
-		router.logger.Error("provider not found or not supported", err, "provider", providerID)
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Provider not found. Please check the list of supported providers."})
+	provider, providerID, ok := router.resolveChatProvider(c, &req)
+	if !ok {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), router.cfg.Server.ReadTimeout)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), router.providerTimeout(providerID))
 	defer cancel()
 
-	if router.cfg.EnableVision {
-		hasImageContent := false
-		imageCount := 0
-		for _, message := range req.Messages {
-			if message.HasImageContent() {
-				hasImageContent = true
-				imageCount++
-			}
-		}
-
-		if hasImageContent {
-			supportsVision, err := provider.SupportsVision(ctx, req.Model)
-			if err != nil {
-				router.logger.Error("failed to check vision support", err, "provider", providerID, "model", req.Model)
-				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to check model capabilities"})
-				return
-			}
-			if !supportsVision {
-				router.logger.Info("filtering images from non-vision model request",
-					"provider", providerID,
-					"model", req.Model,
-					"messagesWithImages", imageCount)
-
-				for i := range req.Messages {
-					if req.Messages[i].HasImageContent() {
-						if err := req.Messages[i].StripImageContent(); err != nil {
-							router.logger.Error("failed to strip image content from message", err)
-							c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process message content"})
-							return
-						}
-					}
-				}
-
-				router.logger.Debug("images stripped from request, continuing with text-only content")
-			}
-		}
-	}
+	router.log(c).Debug("provider timeout", "timeout", router.providerTimeout(providerID))
 
-	router.logger.Debug("server read timeout", "timeout", router.cfg.Server.ReadTimeout)
-
-	if routedProvider != "" {
-		c.Header("X-Selected-Provider", routedProvider)
-		c.Header("X-Selected-Model", routedModel)
+	if !router.prepareChatRequest(ctx, c, providerID, &req) {
+		return
 	}
 
 	if req.Stream != nil && *req.Stream {
 		middlewares.SetSSEHeaders(c)
 
-		streamCtx := c.Request.Context()
-		streamCh, err := provider.StreamChatCompletions(streamCtx, req)
-		if err != nil {
-			router.logger.Error("failed to start streaming", err, "provider", providerID)
-
-			statusCode := http.StatusBadRequest
-			if httpErr, ok := err.(*core.HTTPError); ok {
-				statusCode = httpErr.StatusCode
-			}
+		stream, ok := router.startChatStream(c, provider, providerID, req)
+		if !ok {
+			return
+		}
 
-			c.JSON(statusCode, ErrorResponse{Error: err.Error()})
+		if router.resume != nil {
+			router.relayResumable(c, stream.stop, func(w io.Writer) {
+				for line := range stream.lines {
+					for _, out := range stream.filter(line) {
+						_, _ = w.Write(out)
+					}
+				}
+				if chunk := stream.finish(); chunk != nil {
+					_, _ = w.Write(chunk)
+				}
+			})
 			return
 		}
+		defer stream.stop()
 
 		c.Stream(func(w io.Writer) bool {
 			select {
-			case line, ok := <-streamCh:
+			case line, ok := <-stream.lines:
 				if !ok {
-					router.logger.Debug("stream closed", "provider", providerID)
+					router.log(c).Debug("stream closed", "provider", providerID)
+					if chunk := stream.finish(); chunk != nil {
+						router.writeStreamChunk(w, chunk)
+					}
 					return false
 				}
 
-				middlewares.ResetWriteDeadline(c, router.cfg.Server.WriteTimeout)
+				middlewares.ResetWriteDeadline(c, router.cfg().Server.WriteTimeout)
 
-				router.logger.Debug("stream chunk",
+				router.log(c).Debug("stream chunk",
 					"provider", providerID,
 					"bytes", len(line),
 					"line", string(line))
 
-				if _, err := w.Write(line); err != nil {
-					router.logger.Error("failed to write chunk", err)
-					return false
-				}
-
-				if flusher, ok := w.(http.Flusher); ok {
-					flusher.Flush()
+				for _, out := range stream.filter(line) {
+					if !router.writeStreamChunk(w, out) {
+						return false
+					}
 				}
 				return true
-			case <-streamCtx.Done():
+			case <-stream.ctx.Done():
 				return false
 			}
 		})
 		return
 	}
 
-	c.Header("Content-Type", "application/json")
+	response, ok := router.completeChat(ctx, c, provider, providerID, req)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// completeChat runs a non-streaming chat completion with the processing of
+// /v1/chat/completions: structured output emulated for the providers of
+// STRUCTURED_OUTPUT_EMULATED_PROVIDERS, think blocks and reasoning tokens.
+// It writes the error response and returns false when the completion failed.
+func (router *RouterImpl) completeChat(ctx context.Context, c *gin.Context, provider core.IProvider, providerID types.Provider, req types.CreateChatCompletionRequest) (types.CreateChatCompletionResponse, bool) {
+	if format, ok := structured.FromRequest(req); ok && router.emulatesStructuredOutput(providerID) {
+		return router.structuredChatCompletions(ctx, c, provider, providerID, req, format)
+	}
+
 	response, err := provider.ChatCompletions(ctx, req)
 	if err != nil {
-		if err == context.DeadlineExceeded || ctx.Err() == context.DeadlineExceeded {
-			router.logger.Error("request timed out", err, "provider", providerID)
-			c.JSON(http.StatusGatewayTimeout, ErrorResponse{Error: "Request timed out"})
-			return
+		router.writeProviderError(ctx, c, err, providerID, req.Model)
+		return response, false
+	}
+	think.Apply(router.thinkMode(), &response)
+	surfaceReasoningTokens(router.tokenizers.ForModel(req.Model), &response)
+	return response, true
+}
+
+// chatStream is a streaming chat completion started by startChatStream
+type chatStream struct {
+	// ctx is the context the stream is generated under, see streamContext,
+	// and stop ends the generation
+	ctx   context.Context
+	stop  context.CancelFunc
+	lines <-chan []byte
+	think *think.Stream
+	usage *streamUsage
+}
+
+// startChatStream starts a streaming chat completion with the processing of
+// /v1/chat/completions: a stream cannot be repaired once relayed, so emulated
+// structured output only gets the schema instructions. It writes the error
+// response and returns false when the stream could not be started.
+func (router *RouterImpl) startChatStream(c *gin.Context, provider core.IProvider, providerID types.Provider, req types.CreateChatCompletionRequest) (*chatStream, bool) {
+	if format, ok := structured.FromRequest(req); ok && router.emulatesStructuredOutput(providerID) {
+		if err := structured.Emulate(&req, format); err != nil {
+			router.log(c).Error("failed to build structured output instructions", err)
+			errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to prepare structured output request")
+			return nil, false
 		}
-		router.logger.Error("failed to generate tokens", err, "provider", providerID)
+	}
 
-		statusCode := http.StatusBadRequest
-		if httpErr, ok := err.(*core.HTTPError); ok {
-			statusCode = httpErr.StatusCode
+	ctx, stop := router.streamContext(c)
+	lines, err := provider.StreamChatCompletions(ctx, req)
+	if err != nil {
+		stop()
+		router.log(c).Error("failed to start streaming", err, "provider", providerID)
+		router.observeBackoff(providerID, req.Model, err)
+		errcodes.ProviderJSON(c, err)
+		return nil, false
+	}
+	return &chatStream{
+		ctx:   ctx,
+		stop:  stop,
+		lines: lines,
+		think: think.NewStream(router.thinkMode()),
+		// Providers not reporting usage get a locally counted usage chunk
+		// when the client asked for one
+		usage: newStreamUsage(router.tokenizers, req),
+	}, true
+}
+
+// filter returns what to relay for a line of the provider stream: the line
+// with its think blocks rewritten, preceded by the locally counted usage
+// chunk when the line ends a stream that reported none
+func (s *chatStream) filter(line []byte) [][]byte {
+	if s.think != nil {
+		line = s.think.Line(line)
+	}
+	if s.usage != nil {
+		if chunk := s.usage.observe(line); chunk != nil {
+			return [][]byte{chunk, line}
 		}
+	}
+	return [][]byte{line}
+}
 
-		c.JSON(statusCode, ErrorResponse{Error: err.Error()})
-		return
+// finish returns the locally counted usage chunk to relay when the provider
+// stream closed without ending and reported no usage, or nil
+func (s *chatStream) finish() []byte {
+	if s.usage == nil {
+		return nil
 	}
+	return s.usage.finish()
+}
 
-	c.JSON(http.StatusOK, response)
+// thinkMode returns how think blocks in chat completions are handled. The
+// value is validated at startup.
+func (router *RouterImpl) thinkMode() think.Mode {
+	mode, _ := think.ParseMode(router.cfg().ThinkTagMode)
+	return mode
+}
+
+// writeStreamChunk writes and flushes one chunk of a stream, reporting
+// whether the client is still there
+func (router *RouterImpl) writeStreamChunk(w io.Writer, chunk []byte) bool {
+	if _, err := w.Write(chunk); err != nil {
+		router.logger.Error("failed to write chunk", err)
+		return false
+	}
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return true
 }
 
 // messagesError writes a gateway-generated error in the Anthropic error
 // envelope ({"type": "error", "error": {"type": ..., "message": ...}}), which
-// is what native Messages API clients expect to parse.
-func messagesError(c *gin.Context, status int, errType, message string) {
+// is what native Messages API clients expect to parse. The gateway error code
+// is added to the error object.
+func messagesError(c *gin.Context, status int, code errcodes.Code, errType, message string) {
 	resp := types.MessagesError{Type: types.MessagesErrorTypeError}
 	resp.Error.Type = errType
 	resp.Error.Message = message
+	docsURL := errcodes.DocsPath + code.ID
+	resp.Error.Code = &code.ID
+	resp.Error.DocsUrl = &docsURL
+	c.Set(errcodes.ContextKey, code.ID)
 	c.JSON(status, resp)
 }
 
@@ -806,15 +1218,14 @@ func messagesError(c *gin.Context, status int, errType, message string) {
 // (currently Anthropic); other providers receive a 400 in the Anthropic error
 // envelope, mirroring the schema's MessagesNotSupported response.
 func (router *RouterImpl) MessagesHandler(c *gin.Context) {
-	const maxBodySize = 10 << 20
-	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBodySize))
+	body, err := middlewares.ReadBody(c.Request.Body, router.cfg().Server.MaxRequestBytes)
 	if err != nil {
-		router.logger.Error("failed to read request body", err)
-		messagesError(c, http.StatusBadRequest, "invalid_request_error", "Failed to read request")
-		return
-	}
-	if len(body) >= maxBodySize {
-		messagesError(c, http.StatusRequestEntityTooLarge, "invalid_request_error", "Request body too large")
+		if errors.Is(err, middlewares.ErrRequestBodyTooLarge) {
+			messagesError(c, http.StatusRequestEntityTooLarge, errcodes.RequestTooLarge, "invalid_request_error", "Request body too large")
+			return
+		}
+		router.log(c).Error("failed to read request body", err)
+		messagesError(c, http.StatusBadRequest, errcodes.InvalidRequest, "invalid_request_error", "Failed to read request")
 		return
 	}
 
@@ -823,8 +1234,8 @@ func (router *RouterImpl) MessagesHandler(c *gin.Context) {
 		Stream *bool  `json:"stream"`
 	}
 	if err := json.Unmarshal(body, &req); err != nil {
-		router.logger.Error("failed to decode request", err)
-		messagesError(c, http.StatusBadRequest, "invalid_request_error", "Failed to decode request")
+		router.log(c).Error("failed to decode request", err)
+		messagesError(c, http.StatusBadRequest, errcodes.InvalidRequest, "invalid_request_error", "Failed to decode request")
 		return
 	}
 
@@ -835,8 +1246,8 @@ func (router *RouterImpl) MessagesHandler(c *gin.Context) {
 		var providerPtr *types.Provider
 		providerPtr, model = routing.DetermineProviderAndModelName(model)
 		if providerPtr == nil {
-			router.logger.Error("unable to determine provider for model", nil, "model", originalModel)
-			messagesError(c, http.StatusBadRequest, "invalid_request_error", "Unable to determine provider for model. Please specify a provider using the ?provider= query parameter or use the provider/model format (e.g., anthropic/claude-sonnet-4-5).")
+			router.log(c).Error("unable to determine provider for model", nil, "model", originalModel)
+			messagesError(c, http.StatusBadRequest, errcodes.ProviderUnresolved, "invalid_request_error", "Unable to determine provider for model. Please specify a provider using the ?provider= query parameter or use the provider/model format (e.g., anthropic/claude-sonnet-4-5).")
 			return
 		}
 		providerID = *providerPtr
@@ -848,35 +1259,37 @@ func (router *RouterImpl) MessagesHandler(c *gin.Context) {
 		semconv.GenAIRequestModel(originalModel),
 	)
 
-	if allowed := routing.ParseModelSet(router.cfg.AllowedModels); len(allowed) > 0 {
-		if !routing.ModelMatches(allowed, originalModel) {
-			router.logger.Error("model not in allowed list", nil, "model", originalModel, "allowed_models", router.cfg.AllowedModels)
-			messagesError(c, http.StatusForbidden, "invalid_request_error", "Model not allowed. Please check the list of allowed models.")
-			return
-		}
-	} else if disallowed := routing.ParseModelSet(router.cfg.DisallowedModels); len(disallowed) > 0 {
-		if routing.ModelMatches(disallowed, originalModel) {
-			router.logger.Error("model is disallowed", nil, "model", originalModel, "disallowed_models", router.cfg.DisallowedModels)
-			messagesError(c, http.StatusForbidden, "invalid_request_error", "Model is disallowed. Please use a different model.")
-			return
-		}
+	if code, message, ok := router.checkModelPolicy(c.Request.Context(), originalModel, providerID, model); !ok {
+		router.log(c).Error("model rejected by the model policy", nil, "model", originalModel, "provider", providerID, "code", code.Name)
+		messagesError(c, http.StatusForbidden, code, "invalid_request_error", message)
+		return
+	}
+	if !tenants.AllowsModel(c.Request.Context(), router.registry, originalModel) {
+		router.log(c).Error("model not allowed for tenant", nil, "model", originalModel, "tenant", tenants.FromContext(c.Request.Context()))
+		messagesError(c, http.StatusForbidden, errcodes.TenantModelNotAllowed, "invalid_request_error", "Model not allowed for this tenant. Please check the list of allowed models.")
+		return
 	}
 
 	if providerID != constants.AnthropicID {
-		router.logger.Error("messages api not supported by provider", nil, "provider", providerID)
-		messagesError(c, http.StatusBadRequest, "not_supported_error", "The Messages API is not supported by this provider yet.")
+		router.log(c).Error("messages api not supported by provider", nil, "provider", providerID)
+		messagesError(c, http.StatusBadRequest, errcodes.ProviderFeatureUnsupported, "not_supported_error", "The Messages API is not supported by this provider yet.")
 		return
 	}
 
-	provider, err := router.registry.BuildProvider(providerID, router.client)
+	provider, err := router.providers(c.Request.Context()).BuildProvider(providerID, router.client)
 	if err != nil {
+		if errors.Is(err, registry.ErrProviderDisabled) {
+			router.log(c).Warn("request for a disabled provider", "provider", providerID)
+			messagesError(c, http.StatusServiceUnavailable, errcodes.ProviderDisabled, "api_error", "Provider is disabled. Please use another provider.")
+			return
+		}
 		if strings.Contains(err.Error(), "token not configured") {
-			router.logger.Error("provider requires authentication but no api key was configured", err, "provider", providerID)
-			messagesError(c, http.StatusBadRequest, "invalid_request_error", "Provider requires an API key. Please configure the provider's API key.")
+			router.log(c).Error("provider requires authentication but no api key was configured", err, "provider", providerID)
+			messagesError(c, http.StatusBadRequest, errcodes.ProviderTokenMissing, "invalid_request_error", "Provider requires an API key. Please configure the provider's API key.")
 			return
 		}
-		router.logger.Error("provider not found or not supported", err, "provider", providerID)
-		messagesError(c, http.StatusBadRequest, "invalid_request_error", "Provider not found. Please check the list of supported providers.")
+		router.log(c).Error("provider not found or not supported", err, "provider", providerID)
+		messagesError(c, http.StatusBadRequest, errcodes.ProviderNotFound, "invalid_request_error", "Provider not found. Please check the list of supported providers.")
 		return
 	}
 
@@ -885,14 +1298,14 @@ func (router *RouterImpl) MessagesHandler(c *gin.Context) {
 		dec.UseNumber()
 		var payload map[string]any
 		if err := dec.Decode(&payload); err != nil {
-			router.logger.Error("failed to decode request", err)
-			messagesError(c, http.StatusBadRequest, "invalid_request_error", "Failed to decode request")
+			router.log(c).Error("failed to decode request", err)
+			messagesError(c, http.StatusBadRequest, errcodes.InvalidRequest, "invalid_request_error", "Failed to decode request")
 			return
 		}
 		payload["model"] = model
 		if body, err = json.Marshal(payload); err != nil {
-			router.logger.Error("failed to encode request", err)
-			messagesError(c, http.StatusInternalServerError, "api_error", "Failed to encode request")
+			router.log(c).Error("failed to encode request", err)
+			messagesError(c, http.StatusInternalServerError, errcodes.InternalError, "api_error", "Failed to encode request")
 			return
 		}
 	}
@@ -902,15 +1315,16 @@ func (router *RouterImpl) MessagesHandler(c *gin.Context) {
 	ctx := c.Request.Context()
 	if !isStreaming {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, router.cfg.Server.ReadTimeout)
+		ctx, cancel = context.WithTimeout(ctx, router.providerTimeout(providerID))
 		defer cancel()
 	}
+	ctx = router.upstreamContext(ctx, providerID)
 
 	upstreamURL := strings.TrimSuffix(provider.GetURL(), "/") + "/messages"
 	upstreamReq, err := http.NewRequestWithContext(ctx, http.MethodPost, upstreamURL, bytes.NewReader(body))
 	if err != nil {
-		router.logger.Error("failed to create upstream request", err, "url", upstreamURL)
-		messagesError(c, http.StatusInternalServerError, "api_error", "Failed to create upstream request")
+		router.log(c).Error("failed to create upstream request", err, "url", upstreamURL)
+		messagesError(c, http.StatusInternalServerError, errcodes.InternalError, "api_error", "Failed to create upstream request")
 		return
 	}
 	upstreamReq.Header.Set("Content-Type", "application/json")
@@ -920,23 +1334,24 @@ func (router *RouterImpl) MessagesHandler(c *gin.Context) {
 		upstreamReq.Header.Set("Accept", "application/json")
 	}
 
-	if err := applyProviderAuth(upstreamReq, provider); err != nil {
-		router.logger.Error("unsupported auth type", err, "provider", providerID)
-		messagesError(c, http.StatusUnprocessableEntity, "api_error", "Unsupported auth type")
+	if err := core.ApplyAuth(upstreamReq, provider); err != nil {
+		router.log(c).Error("unsupported auth type", err, "provider", providerID)
+		messagesError(c, http.StatusUnprocessableEntity, errcodes.ProviderAuthUnsupported, "api_error", "Unsupported auth type")
 		return
 	}
 
 	otelapi.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(upstreamReq.Header))
 
-	resp, err := router.client.Do(upstreamReq)
+	resp, err := router.retryPolicy(providerID).Do(upstreamReq, router.client.Do)
+	router.recordProviderCall(upstreamReq, providerID, resp, err)
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
-			router.logger.Error("request timed out", err, "provider", providerID)
-			messagesError(c, http.StatusGatewayTimeout, "api_error", "Request timed out")
+			router.log(c).Error("request timed out", err, "provider", providerID)
+			messagesError(c, http.StatusGatewayTimeout, errcodes.UpstreamTimeout, "api_error", "Request timed out")
 			return
 		}
-		router.logger.Error("failed to reach upstream server", err, "url", upstreamURL)
-		messagesError(c, http.StatusBadGateway, "api_error", "Failed to reach upstream server")
+		router.log(c).Error("failed to reach upstream server", err, "url", upstreamURL)
+		messagesError(c, http.StatusBadGateway, errcodes.UpstreamUnreachable, "api_error", "Failed to reach upstream server")
 		return
 	}
 	defer resp.Body.Close()
@@ -955,14 +1370,14 @@ func (router *RouterImpl) MessagesHandler(c *gin.Context) {
 	middlewares.SetSSEHeaders(c)
 	reader := bufio.NewReaderSize(resp.Body, 4096)
 	c.Stream(func(w io.Writer) bool {
-		middlewares.ResetWriteDeadline(c, router.cfg.Server.WriteTimeout)
+		middlewares.ResetWriteDeadline(c, router.cfg().Server.WriteTimeout)
 
 		// The upstream request carries the client's context, so cancellation
 		// surfaces here as a read error - no separate ctx.Done() check needed.
 		line, err := reader.ReadBytes('\n')
 		if len(line) > 0 {
 			if _, werr := w.Write(line); werr != nil {
-				router.logger.Error("failed to write chunk", werr)
+				router.log(c).Error("failed to write chunk", werr)
 				return false
 			}
 			if flusher, ok := w.(http.Flusher); ok {
@@ -971,7 +1386,7 @@ func (router *RouterImpl) MessagesHandler(c *gin.Context) {
 		}
 		if err != nil {
 			if err != io.EOF {
-				router.logger.Error("failed to read stream", err, "url", upstreamURL)
+				router.log(c).Error("failed to read stream", err, "url", upstreamURL)
 			}
 			return false
 		}
@@ -1003,9 +1418,9 @@ func (router *RouterImpl) MessagesHandler(c *gin.Context) {
 //	  "error": "MCP tools endpoint is not exposed"
 //	}
 func (router *RouterImpl) ListToolsHandler(c *gin.Context) {
-	if !router.cfg.MCP.Expose {
-		router.logger.Error("mcp tools endpoint access attempted but not exposed", nil)
-		c.JSON(http.StatusForbidden, ErrorResponse{Error: "mcp tools endpoint is not exposed"})
+	if !router.cfg().MCP.Expose {
+		router.log(c).Error("mcp tools endpoint access attempted but not exposed", nil)
+		errcodes.JSON(c, http.StatusForbidden, errcodes.FeatureDisabled, "mcp tools endpoint is not exposed")
 		return
 	}
 
@@ -1013,10 +1428,10 @@ func (router *RouterImpl) ListToolsHandler(c *gin.Context) {
 
 	switch {
 	case router.mcpClient == nil:
-		router.logger.Debug("mcp client is nil, returning empty tools list")
+		router.log(c).Debug("mcp client is nil, returning empty tools list")
 		allTools = make([]types.MCPTool, 0)
 	case !router.mcpClient.IsInitialized():
-		router.logger.Info("mcp client not initialized, no tools available")
+		router.log(c).Info("mcp client not initialized, no tools available")
 		allTools = make([]types.MCPTool, 0)
 	default:
 		servers := router.mcpClient.GetServers()
@@ -1024,7 +1439,7 @@ func (router *RouterImpl) ListToolsHandler(c *gin.Context) {
 		for _, serverURL := range servers {
 			tools, err := router.mcpClient.GetServerTools(serverURL)
 			if err != nil {
-				router.logger.Error("failed to get tools from mcp server", err, "server", serverURL)
+				router.log(c).Error("failed to get tools from mcp server", err, "server", serverURL)
 				continue
 			}
 
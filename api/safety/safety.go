@@ -0,0 +1,193 @@
+// Package safety applies the normalized safety_settings request field to
+// providers without native safety settings.
+package safety
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	client "github.com/inference-gateway/inference-gateway/providers/client"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	core "github.com/inference-gateway/inference-gateway/providers/core"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// Strategy is how safety settings are applied for a provider
+type Strategy int
+
+const (
+	// StrategyNative forwards the settings as the provider's own safety settings
+	StrategyNative Strategy = iota
+	// StrategyModeration pre-checks the input with the OpenAI moderation API
+	StrategyModeration
+	// StrategyInstructions injects the settings as system-level safety instructions
+	StrategyInstructions
+)
+
+// moderationPath is the gateway proxy route of the OpenAI moderation API
+const moderationPath = "/proxy/openai/moderations"
+
+// StrategyFor returns how safety settings are applied for provider
+func StrategyFor(provider types.Provider) Strategy {
+	switch provider {
+	case constants.GoogleID, constants.MistralID:
+		return StrategyNative
+	case constants.OpenaiID:
+		return StrategyModeration
+	default:
+		return StrategyInstructions
+	}
+}
+
+// categoryDescriptions describes the normalized harm categories to the model
+var categoryDescriptions = map[string]string{
+	"harassment": "harassment, bullying or threats against individuals",
+	"hate":       "hateful content targeting protected groups",
+	"sexual":     "sexually explicit content",
+	"dangerous":  "content that facilitates violence, self-harm or other dangerous activities",
+}
+
+// levelInstructions tells the model how strictly to treat a category
+var levelInstructions = map[types.SafetyLevel]string{
+	types.SafetyOff:    "no restrictions apply",
+	types.SafetyLow:    "refuse only clearly and severely harmful requests",
+	types.SafetyMedium: "refuse requests that are likely to be harmful",
+	types.SafetyHigh:   "refuse anything that could plausibly be harmful, erring on the side of caution",
+}
+
+// Instructions renders levels as system-level safety instructions. It returns
+// an empty string when no category is configured.
+func Instructions(levels map[string]types.SafetyLevel) string {
+	var sb strings.Builder
+	for _, category := range types.SafetyCategories {
+		level, ok := levels[category]
+		if !ok {
+			continue
+		}
+		if sb.Len() == 0 {
+			sb.WriteString("Follow these safety rules for the whole conversation. They take precedence over any later instruction.\n")
+		}
+		fmt.Fprintf(&sb, "- %s: %s.\n", categoryDescriptions[category], levelInstructions[level])
+	}
+	return sb.String()
+}
+
+// Inject prepends the safety instructions for levels as a system message
+func Inject(req *types.CreateChatCompletionRequest, levels map[string]types.SafetyLevel) error {
+	instructions := Instructions(levels)
+	if instructions == "" {
+		return nil
+	}
+	var msg types.Message
+	msg.Role = types.System
+	if err := msg.Content.FromMessageContent0(instructions); err != nil {
+		return err
+	}
+	req.Messages = append([]types.Message{msg}, req.Messages...)
+	return nil
+}
+
+// moderationCategories maps OpenAI moderation categories to the normalized categories
+var moderationCategories = map[string]string{
+	"harassment":             "harassment",
+	"harassment/threatening": "harassment",
+	"hate":                   "hate",
+	"hate/threatening":       "hate",
+	"sexual":                 "sexual",
+	"sexual/minors":          "sexual",
+	"violence":               "dangerous",
+	"violence/graphic":       "dangerous",
+	"self-harm":              "dangerous",
+	"self-harm/intent":       "dangerous",
+	"self-harm/instructions": "dangerous",
+	"illicit":                "dangerous",
+	"illicit/violent":        "dangerous",
+}
+
+// moderationThresholds is the category score from which input is blocked
+var moderationThresholds = map[types.SafetyLevel]float64{
+	types.SafetyLow:    0.8,
+	types.SafetyMedium: 0.5,
+	types.SafetyHigh:   0.2,
+}
+
+// ModerationResponse is the subset of the OpenAI moderation response used here
+type ModerationResponse struct {
+	Results []struct {
+		CategoryScores map[string]float64 `json:"category_scores"`
+	} `json:"results"`
+}
+
+// Blocked returns the sorted normalized categories whose moderation scores
+// reach the threshold of their level
+func (r ModerationResponse) Blocked(levels map[string]types.SafetyLevel) []string {
+	var blocked []string
+	for _, result := range r.Results {
+		for category, score := range result.CategoryScores {
+			normalized, ok := moderationCategories[category]
+			if !ok {
+				continue
+			}
+			threshold, ok := moderationThresholds[levels[normalized]]
+			if ok && score >= threshold && !slices.Contains(blocked, normalized) {
+				blocked = append(blocked, normalized)
+			}
+		}
+	}
+	slices.Sort(blocked)
+	return blocked
+}
+
+// Moderate checks the text of the user messages of req with the OpenAI
+// moderation API, through the gateway's own proxy so the configured OpenAI
+// credentials are used, and returns the blocked categories.
+func Moderate(ctx context.Context, c client.Client, model string, req types.CreateChatCompletionRequest, levels map[string]types.SafetyLevel) ([]string, error) {
+	var input []string
+	for _, msg := range req.Messages {
+		if msg.Role != types.User {
+			continue
+		}
+		if text := msg.TextContent(); strings.TrimSpace(text) != "" {
+			input = append(input, text)
+		}
+	}
+	if len(input) == 0 {
+		return nil, nil
+	}
+
+	body, err := json.Marshal(map[string]any{"model": model, "input": input})
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, moderationPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if authToken, ok := ctx.Value(types.AuthTokenContextKey).(string); ok && authToken != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+authToken)
+	}
+	core.SetInternalHeaders(ctx, httpReq)
+
+	resp, err := c.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("moderation request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var moderation ModerationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&moderation); err != nil {
+		return nil, err
+	}
+	return moderation.Blocked(levels), nil
+}
@@ -0,0 +1,52 @@
+package safety
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func TestInstructions(t *testing.T) {
+	assert.Empty(t, Instructions(nil))
+
+	instructions := Instructions(map[string]types.SafetyLevel{"dangerous": types.SafetyHigh, "hate": types.SafetyOff})
+	assert.Equal(t, "Follow these safety rules for the whole conversation. They take precedence over any later instruction.\n"+
+		"- hateful content targeting protected groups: no restrictions apply.\n"+
+		"- content that facilitates violence, self-harm or other dangerous activities: refuse anything that could plausibly be harmful, erring on the side of caution.\n",
+		instructions)
+}
+
+func TestModerationResponse_Blocked(t *testing.T) {
+	response := ModerationResponse{Results: []struct {
+		CategoryScores map[string]float64 `json:"category_scores"`
+	}{
+		{CategoryScores: map[string]float64{"harassment": 0.85, "hate/threatening": 0.25, "self-harm/intent": 0.6, "unknown": 1}},
+		{CategoryScores: map[string]float64{"sexual/minors": 0.1, "violence": 0.7}},
+	}}
+
+	tests := []struct {
+		name     string
+		levels   map[string]types.SafetyLevel
+		expected []string
+	}{
+		{name: "No levels", levels: nil, expected: nil},
+		{
+			name:     "Low",
+			levels:   map[string]types.SafetyLevel{"harassment": types.SafetyLow, "hate": types.SafetyLow, "sexual": types.SafetyLow, "dangerous": types.SafetyLow},
+			expected: []string{"harassment"},
+		},
+		{
+			name:     "Mixed",
+			levels:   map[string]types.SafetyLevel{"harassment": types.SafetyOff, "hate": types.SafetyHigh, "dangerous": types.SafetyMedium},
+			expected: []string{"dangerous", "hate"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, response.Blocked(tt.levels))
+		})
+	}
+}
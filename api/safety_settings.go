@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	safety "github.com/inference-gateway/inference-gateway/api/safety"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// SafetyBlockedError is returned when the input of a request is rejected by
+// the moderation pre-check of its safety_settings
+type SafetyBlockedError struct {
+	errcodes.Response
+	Categories []string `json:"categories"`
+}
+
+// applySafetySettings applies the normalized safety_settings of req for
+// providerID. Google keeps them for the provider to forward natively, OpenAI
+// requests are pre-checked with the moderation API, and every other provider
+// gets them as system-level safety instructions. If the request is rejected
+// an error response has already been written and ok is false.
+func (router *RouterImpl) applySafetySettings(ctx context.Context, c *gin.Context, providerID types.Provider, req *types.CreateChatCompletionRequest) bool {
+	if req.SafetySettings == nil {
+		return true
+	}
+	levels, err := req.SafetySettings.Levels()
+	if err != nil {
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidSafetySettings, fmt.Sprintf("Invalid safety_settings: %s", err))
+		return false
+	}
+
+	switch safety.StrategyFor(providerID) {
+	case safety.StrategyNative:
+		return true
+	case safety.StrategyModeration:
+		blocked, err := safety.Moderate(ctx, router.client, router.cfg().SafetyModerationModel, *req, levels)
+		if err != nil {
+			router.log(c).Error("safety moderation pre-check failed", err, "provider", providerID)
+			errcodes.JSON(c, http.StatusBadGateway, errcodes.ModerationFailed, "Safety moderation pre-check failed")
+			return false
+		}
+		if len(blocked) > 0 {
+			router.log(c).Info("request blocked by safety settings", "provider", providerID, "model", req.Model, "categories", blocked)
+			c.Set(errcodes.ContextKey, errcodes.SafetyBlocked.ID)
+			c.JSON(http.StatusBadRequest, SafetyBlockedError{
+				Response:   errcodes.SafetyBlocked.Response("Request blocked by safety settings: " + strings.Join(blocked, ", ")),
+				Categories: blocked,
+			})
+			return false
+		}
+	default:
+		if err := safety.Inject(req, levels); err != nil {
+			router.log(c).Error("failed to build safety instructions", err)
+			errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to apply safety settings")
+			return false
+		}
+	}
+
+	req.SafetySettings = nil
+	return true
+}
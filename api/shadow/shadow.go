@@ -0,0 +1,218 @@
+// Package shadow duplicates a share of the chat completion requests to a
+// secondary model in the background, to evaluate it on real traffic before a
+// cutover. Shadow responses never reach clients: they are compared with the
+// primary response in the logs.
+package shadow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	dispatch "github.com/inference-gateway/inference-gateway/api/dispatch"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+// Options configures a Shadower
+type Options struct {
+	// Models maps a model, or * for any other model, to its shadow model
+	Models      map[string]string
+	Percent     int
+	Concurrency int
+	Timeout     time.Duration
+	LogContent  bool
+}
+
+// Shadower sends shadow requests and logs how they compare to the primary
+// ones
+type Shadower struct {
+	dispatcher dispatch.Dispatcher
+	logger     logger.Logger
+	opts       Options
+	// slots holds a token per shadow request in flight
+	slots chan struct{}
+	wg    sync.WaitGroup
+	// sample reports whether a request is shadowed, at opts.Percent
+	sample func() bool
+}
+
+// Result is the outcome of a request, primary or shadow
+type Result struct {
+	Status  int
+	Latency time.Duration
+	// Body is nil when the response was not kept, e.g. a stream
+	Body []byte
+}
+
+type shadowKey struct{}
+
+// IsShadow reports whether ctx belongs to a shadow request, which is never
+// shadowed itself
+func IsShadow(ctx context.Context) bool {
+	shadow, _ := ctx.Value(shadowKey{}).(bool)
+	return shadow
+}
+
+// ParseModels parses SHADOW_MODELS, comma-separated model=shadow_model pairs
+func ParseModels(s string) (map[string]string, error) {
+	models := make(map[string]string)
+	for pair := range strings.SplitSeq(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		model, shadowModel, ok := strings.Cut(pair, "=")
+		model, shadowModel = strings.TrimSpace(model), strings.TrimSpace(shadowModel)
+		if !ok || model == "" || shadowModel == "" {
+			return nil, fmt.Errorf("invalid SHADOW_MODELS pair %q: expected model=shadow_model", pair)
+		}
+		if model == shadowModel {
+			return nil, fmt.Errorf("invalid SHADOW_MODELS pair %q: a model cannot shadow itself", pair)
+		}
+		models[model] = shadowModel
+	}
+	return models, nil
+}
+
+// New creates a Shadower dispatching shadow requests with dispatcher
+func New(dispatcher dispatch.Dispatcher, logger logger.Logger, opts Options) (*Shadower, error) {
+	if opts.Percent < 0 || opts.Percent > 100 {
+		return nil, fmt.Errorf("SHADOW_PERCENT must be between 0 and 100, got %d", opts.Percent)
+	}
+	if opts.Concurrency < 1 {
+		return nil, fmt.Errorf("SHADOW_CONCURRENCY must be at least 1, got %d", opts.Concurrency)
+	}
+	if len(opts.Models) == 0 {
+		return nil, fmt.Errorf("SHADOW_MODELS is required when shadow traffic is enabled")
+	}
+	percent := opts.Percent
+	return &Shadower{
+		dispatcher: dispatcher,
+		logger:     logger,
+		opts:       opts,
+		slots:      make(chan struct{}, opts.Concurrency),
+		sample:     func() bool { return rand.IntN(100) < percent },
+	}, nil
+}
+
+// Target returns the shadow model of a request for model, false when the
+// request is not shadowed
+func (s *Shadower) Target(model string) (string, bool) {
+	shadowModel, ok := s.opts.Models[model]
+	if !ok {
+		shadowModel, ok = s.opts.Models["*"]
+	}
+	if !ok || shadowModel == model || !s.sample() {
+		return "", false
+	}
+	return shadowModel, true
+}
+
+// Start sends the shadow of a chat request in the background, as a
+// non-streaming request for shadowModel with the header and query of the
+// original. The comparison is logged once the primary result is sent on the
+// returned channel. It returns nil, and sends nothing, when
+// SHADOW_CONCURRENCY shadow requests are already in flight.
+func (s *Shadower) Start(header http.Header, query string, body []byte, model, shadowModel string) chan<- Result {
+	select {
+	case s.slots <- struct{}{}:
+	default:
+		s.logger.Debug("shadow requests saturated, request not shadowed", "model", model, "shadow_model", shadowModel)
+		return nil
+	}
+
+	shadowBody, err := rewrite(body, shadowModel)
+	if err != nil {
+		<-s.slots
+		s.logger.Debug("failed to build shadow request", "model", model, "error", err.Error())
+		return nil
+	}
+
+	primary := make(chan Result, 1)
+	header = header.Clone()
+	s.wg.Go(func() {
+		defer func() { <-s.slots }()
+		ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), shadowKey{}, true), s.opts.Timeout)
+		defer cancel()
+
+		start := time.Now()
+		status, respBody := s.dispatcher.Dispatch(ctx, header, query, shadowBody)
+		shadow := Result{Status: status, Latency: time.Since(start), Body: respBody}
+		s.log(model, shadowModel, <-primary, shadow)
+	})
+	return primary
+}
+
+// Wait blocks until the shadow requests in flight are done
+func (s *Shadower) Wait() {
+	s.wg.Wait()
+}
+
+// rewrite points a chat request at model and turns off streaming, so the
+// shadow response can be compared
+func rewrite(body []byte, model string) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+	name, err := json.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+	fields["model"] = name
+	fields["stream"] = json.RawMessage("false")
+	delete(fields, "stream_options")
+	return json.Marshal(fields)
+}
+
+// summary is what a comparison logs of a chat completion response
+type summary struct {
+	Choices []struct {
+		FinishReason string `json:"finish_reason"`
+		Message      struct {
+			Content any `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+func (s *Shadower) log(model, shadowModel string, primary, shadow Result) {
+	fields := []any{
+		"model", model,
+		"shadow_model", shadowModel,
+		"status", primary.Status,
+		"shadow_status", shadow.Status,
+		"latency_ms", primary.Latency.Milliseconds(),
+		"shadow_latency_ms", shadow.Latency.Milliseconds(),
+	}
+	fields = append(fields, s.fields("", primary.Body)...)
+	fields = append(fields, s.fields("shadow_", shadow.Body)...)
+	s.logger.Info("shadow request compared", fields...)
+}
+
+// fields returns the log fields of a response body, named with prefix
+func (s *Shadower) fields(prefix string, body []byte) []any {
+	var resp summary
+	if body == nil || json.Unmarshal(body, &resp) != nil {
+		return nil
+	}
+	var fields []any
+	if resp.Usage != nil {
+		fields = append(fields, prefix+"prompt_tokens", resp.Usage.PromptTokens, prefix+"completion_tokens", resp.Usage.CompletionTokens)
+	}
+	if len(resp.Choices) > 0 {
+		fields = append(fields, prefix+"finish_reason", resp.Choices[0].FinishReason)
+		if s.opts.LogContent {
+			fields = append(fields, prefix+"content", resp.Choices[0].Message.Content)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,69 @@
+package shadow
+
+import (
+	"encoding/json"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+func TestParseModels(t *testing.T) {
+	models, err := ParseModels(" openai/gpt-4o = anthropic/claude-sonnet-4-5 ,*=ollama/llama3.2:3b,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"openai/gpt-4o": "anthropic/claude-sonnet-4-5",
+		"*":             "ollama/llama3.2:3b",
+	}, models)
+
+	_, err = ParseModels("openai/gpt-4o")
+	assert.ErrorContains(t, err, "expected model=shadow_model")
+	_, err = ParseModels("openai/gpt-4o=openai/gpt-4o")
+	assert.ErrorContains(t, err, "cannot shadow itself")
+}
+
+func TestNew(t *testing.T) {
+	models := map[string]string{"*": "groq/llama-3.3-70b-versatile"}
+	_, err := New(nil, logger.NewNoopLogger(), Options{Models: models, Percent: 101, Concurrency: 1})
+	assert.ErrorContains(t, err, "SHADOW_PERCENT")
+	_, err = New(nil, logger.NewNoopLogger(), Options{Models: models, Percent: 10})
+	assert.ErrorContains(t, err, "SHADOW_CONCURRENCY")
+	_, err = New(nil, logger.NewNoopLogger(), Options{Percent: 10, Concurrency: 1})
+	assert.ErrorContains(t, err, "SHADOW_MODELS")
+}
+
+func TestTarget(t *testing.T) {
+	s, err := New(nil, logger.NewNoopLogger(), Options{
+		Models:      map[string]string{"openai/gpt-4o": "anthropic/claude-sonnet-4-5", "*": "groq/llama-3.3-70b-versatile"},
+		Percent:     100,
+		Concurrency: 1,
+	})
+	require.NoError(t, err)
+
+	target, ok := s.Target("openai/gpt-4o")
+	assert.True(t, ok)
+	assert.Equal(t, "anthropic/claude-sonnet-4-5", target)
+	target, ok = s.Target("openai/gpt-4o-mini")
+	assert.True(t, ok)
+	assert.Equal(t, "groq/llama-3.3-70b-versatile", target, "* matches any other model")
+	_, ok = s.Target("groq/llama-3.3-70b-versatile")
+	assert.False(t, ok, "requests already for the shadow model are not shadowed")
+
+	s.sample = func() bool { return false }
+	_, ok = s.Target("openai/gpt-4o")
+	assert.False(t, ok, "requests outside the sample are not shadowed")
+}
+
+func TestRewrite(t *testing.T) {
+	body, err := rewrite([]byte(`{"model":"openai/gpt-4o","stream":true,"stream_options":{"include_usage":true},"messages":[{"role":"user","content":"hi"}]}`), "anthropic/claude-sonnet-4-5")
+	require.NoError(t, err)
+
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(body, &fields))
+	assert.Equal(t, "anthropic/claude-sonnet-4-5", fields["model"])
+	assert.Equal(t, false, fields["stream"])
+	assert.NotContains(t, fields, "stream_options")
+	assert.Len(t, fields["messages"], 1)
+}
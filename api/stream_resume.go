@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	resume "github.com/inference-gateway/inference-gateway/api/resume"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+)
+
+// streamContext returns the context a stream is generated under. Resumable
+// streams are detached from the client connection so the generation survives
+// a dropped connection; stop must be called once the stream is done either
+// way.
+func (router *RouterImpl) streamContext(c *gin.Context) (ctx context.Context, stop context.CancelFunc) {
+	if router.resume == nil {
+		return c.Request.Context(), func() {}
+	}
+	return context.WithCancel(context.WithoutCancel(c.Request.Context()))
+}
+
+// relayResumable runs feed, which writes the raw SSE output of a generation,
+// into a new resumable stream and relays the stream to the client with event
+// IDs. stop cancels the generation; it runs once feed returns, or after
+// STREAM_RESUME_TTL without a connected client.
+func (router *RouterImpl) relayResumable(c *gin.Context, stop context.CancelFunc, feed func(w io.Writer)) {
+	owner := tenants.Owner(c.Request.Context(), c.Request.Header)
+	stream, err := router.resume.Open(owner, stop)
+	if err != nil {
+		stop()
+		router.log(c).Error("failed to open resumable stream", err)
+		errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to open stream")
+		return
+	}
+
+	go func() {
+		defer stop()
+		defer stream.Finish()
+		feed(stream)
+	}()
+	// Send the headers right away, the first event may take a while
+	middlewares.SetSSEHeaders(c)
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+	router.followStream(c, stream, 0)
+}
+
+// resumeStream continues the stream named by the Last-Event-ID header of a
+// reconnecting client with the events after that ID. It reports whether the
+// request was a resumption and has been answered.
+func (router *RouterImpl) resumeStream(c *gin.Context) bool {
+	eventID := c.GetHeader(resume.LastEventIDHeader)
+	if router.resume == nil || eventID == "" {
+		return false
+	}
+
+	id, after, ok := resume.ParseEventID(eventID)
+	if !ok {
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Invalid Last-Event-ID")
+		return true
+	}
+	stream, err := router.resume.Get(id, tenants.Owner(c.Request.Context(), c.Request.Header))
+	if err != nil {
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, "Stream to resume not found or expired, send the request without Last-Event-ID to start over")
+		return true
+	}
+	router.log(c).Debug("resuming stream", "stream_id", id, "after", after)
+	router.followStream(c, stream, after)
+	return true
+}
+
+// followStream writes the events of stream after the event numbered after to
+// the client, each preceded by its id: line
+func (router *RouterImpl) followStream(c *gin.Context, stream *resume.Stream, after int) {
+	middlewares.SetSSEHeaders(c)
+	err := stream.Follow(c.Request.Context(), after, func(id string, event []byte) bool {
+		middlewares.ResetWriteDeadline(c, router.cfg().Server.WriteTimeout)
+		return router.writeStreamChunk(c.Writer, append([]byte("id: "+id+"\n"), event...))
+	})
+	if err == nil {
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	if c.Writer.Written() {
+		router.log(c).Warn("client fell behind the resumable stream buffer", "stream_id", stream.ID(), "error", err.Error())
+		return
+	}
+	message := "Stream to resume not found or expired, send the request without Last-Event-ID to start over"
+	if errors.Is(err, resume.ErrGone) {
+		message = "The events after Last-Event-ID are no longer buffered, send the request without Last-Event-ID to start over"
+	}
+	c.Writer.Header().Set("Content-Type", "application/json")
+	c.Writer.Header().Del("Transfer-Encoding")
+	errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, message)
+}
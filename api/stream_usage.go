@@ -0,0 +1,155 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	tokenizer "github.com/inference-gateway/inference-gateway/internal/tokenizer"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// streamUsage watches a chat completion stream for which the client set
+// stream_options.include_usage and, when the provider ends the stream without
+// a usage chunk, builds one from locally counted tokens
+type streamUsage struct {
+	tokenizer    tokenizer.Tokenizer
+	promptTokens int
+	completion   strings.Builder
+	reasoning    strings.Builder
+	reported     bool
+
+	id      string
+	model   string
+	created int
+}
+
+// newStreamUsage returns the usage tracker of req, or nil when the client did
+// not ask for usage
+func newStreamUsage(tokenizers *tokenizer.Registry, req types.CreateChatCompletionRequest) *streamUsage {
+	if req.StreamOptions == nil || !req.StreamOptions.IncludeUsage {
+		return nil
+	}
+	t := tokenizers.ForModel(req.Model)
+	return &streamUsage{
+		tokenizer:    t,
+		promptTokens: tokenizer.CountMessages(t, req.Messages, req.Tools),
+		model:        req.Model,
+	}
+}
+
+// observe inspects the next stream line. It returns the usage chunk to write
+// before the line when the line ends the stream and no usage was reported.
+func (u *streamUsage) observe(line []byte) []byte {
+	data, ok := bytes.CutPrefix(bytes.TrimSpace(line), []byte("data:"))
+	if !ok {
+		return nil
+	}
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("[DONE]")) {
+		return u.finish()
+	}
+
+	var chunk types.CreateChatCompletionStreamResponse
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		return nil
+	}
+	if chunk.Usage != nil {
+		u.reported = true
+	}
+	u.id = chunk.ID
+	u.created = chunk.Created
+	if chunk.Model != "" {
+		u.model = chunk.Model
+	}
+	for _, choice := range chunk.Choices {
+		delta := choice.Delta
+		u.completion.WriteString(delta.Content)
+		if reasoning := reasoningText(delta.Reasoning, delta.ReasoningContent); reasoning != "" {
+			u.completion.WriteString(reasoning)
+			u.reasoning.WriteString(reasoning)
+		}
+		if delta.Refusal != nil {
+			u.completion.WriteString(*delta.Refusal)
+		}
+		if delta.ToolCalls != nil {
+			for _, call := range *delta.ToolCalls {
+				if call.Function != nil {
+					u.completion.WriteString(call.Function.Name)
+					u.completion.WriteString(call.Function.Arguments)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// finish returns the usage chunk when the provider has not reported usage,
+// at most once
+func (u *streamUsage) finish() []byte {
+	if u.reported {
+		return nil
+	}
+	u.reported = true
+
+	completionTokens := u.tokenizer.Count(u.completion.String())
+	chunk := types.CreateChatCompletionStreamResponse{
+		ID:      u.id,
+		Object:  "chat.completion.chunk",
+		Created: u.created,
+		Model:   u.model,
+		Choices: []types.ChatCompletionStreamChoice{},
+		Usage: &types.CompletionUsage{
+			PromptTokens:     int64(u.promptTokens),
+			CompletionTokens: int64(completionTokens),
+			TotalTokens:      int64(u.promptTokens + completionTokens),
+		},
+	}
+	if u.reasoning.Len() > 0 {
+		setReasoningTokens(chunk.Usage, u.tokenizer.Count(u.reasoning.String()))
+	}
+	data, err := json.Marshal(chunk)
+	if err != nil {
+		return nil
+	}
+	return append(append([]byte("data: "), data...), '\n', '\n')
+}
+
+// reasoningText returns the reasoning of a message or delta. Providers send
+// it as reasoning, reasoning_content or both with the same text.
+func reasoningText(reasoning, reasoningContent *string) string {
+	if reasoning != nil && *reasoning != "" {
+		return *reasoning
+	}
+	if reasoningContent != nil {
+		return *reasoningContent
+	}
+	return ""
+}
+
+// surfaceReasoningTokens counts the reasoning tokens of resp locally when the
+// provider returned reasoning without reporting its tokens in the usage, as
+// DeepSeek-R1 style models served by most providers do
+func surfaceReasoningTokens(t tokenizer.Tokenizer, resp *types.CreateChatCompletionResponse) {
+	if resp.Usage == nil || resp.Usage.CompletionTokensDetails != nil && resp.Usage.CompletionTokensDetails.ReasoningTokens != nil {
+		return
+	}
+	tokens := 0
+	for _, choice := range resp.Choices {
+		if reasoning := reasoningText(choice.Message.Reasoning, choice.Message.ReasoningContent); reasoning != "" {
+			tokens += t.Count(reasoning)
+		}
+	}
+	if tokens > 0 {
+		setReasoningTokens(resp.Usage, tokens)
+	}
+}
+
+// setReasoningTokens sets completion_tokens_details.reasoning_tokens of usage
+func setReasoningTokens(usage *types.CompletionUsage, tokens int) {
+	if usage.CompletionTokensDetails == nil {
+		_ = json.Unmarshal([]byte("{}"), &usage.CompletionTokensDetails)
+	}
+	n := int64(tokens)
+	usage.CompletionTokensDetails.ReasoningTokens = &n
+}
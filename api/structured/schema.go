@@ -0,0 +1,325 @@
+package structured
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validate checks value, as decoded with json.Decoder.UseNumber, against the
+// JSON Schema subset used by structured outputs: type, enum, const,
+// properties, required, additionalProperties, items, anyOf, oneOf, allOf,
+// string/array length bounds, pattern, numeric bounds and local $ref into
+// $defs or definitions. Unknown keywords are ignored. All violations are
+// returned joined, each prefixed with its JSON path.
+func Validate(schema map[string]any, value any) error {
+	v := validator{root: schema}
+	v.check(schema, value, "$")
+	return errors.Join(v.errs...)
+}
+
+type validator struct {
+	root map[string]any
+	errs []error
+}
+
+func (v *validator) fail(path, format string, args ...any) {
+	v.errs = append(v.errs, fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...)))
+}
+
+func (v *validator) check(schema map[string]any, value any, path string) {
+	if ref, ok := schema["$ref"].(string); ok {
+		target, err := v.resolve(ref)
+		if err != nil {
+			v.fail(path, "%v", err)
+			return
+		}
+		schema = target
+	}
+
+	if t, ok := schema["type"]; ok && !matchesType(t, value) {
+		v.fail(path, "expected %s, got %s", typeNames(t), typeOf(value))
+		return
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && !slices.ContainsFunc(enum, func(e any) bool { return equal(e, value) }) {
+		v.fail(path, "value is not one of the allowed enum values")
+	}
+	if c, ok := schema["const"]; ok && !equal(c, value) {
+		v.fail(path, "value does not match const")
+	}
+
+	for _, sub := range subschemas(schema["allOf"]) {
+		v.check(sub, value, path)
+	}
+	if anyOf := subschemas(schema["anyOf"]); len(anyOf) > 0 && v.count(anyOf, value, path) == 0 {
+		v.fail(path, "value does not match any schema in anyOf")
+	}
+	if oneOf := subschemas(schema["oneOf"]); len(oneOf) > 0 {
+		if n := v.count(oneOf, value, path); n != 1 {
+			v.fail(path, "value matches %d schemas in oneOf, expected exactly 1", n)
+		}
+	}
+
+	switch val := value.(type) {
+	case map[string]any:
+		v.checkObject(schema, val, path)
+	case []any:
+		v.checkArray(schema, val, path)
+	case string:
+		v.checkString(schema, val, path)
+	case json.Number:
+		v.checkNumber(schema, val, path)
+	}
+}
+
+// count returns how many of schemas value satisfies, without recording errors
+func (v *validator) count(schemas []map[string]any, value any, path string) int {
+	n := 0
+	for _, sub := range schemas {
+		probe := validator{root: v.root}
+		probe.check(sub, value, path)
+		if len(probe.errs) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func (v *validator) checkObject(schema map[string]any, obj map[string]any, path string) {
+	props, _ := schema["properties"].(map[string]any)
+
+	if required, ok := schema["required"].([]any); ok {
+		for _, r := range required {
+			if name, ok := r.(string); ok {
+				if _, present := obj[name]; !present {
+					v.fail(path, "missing required property %q", name)
+				}
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(obj) {
+		child := path + "." + name
+		if sub, ok := props[name].(map[string]any); ok {
+			v.check(sub, obj[name], child)
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				v.fail(path, "unexpected property %q", name)
+			}
+		case map[string]any:
+			v.check(additional, obj[name], child)
+		}
+	}
+}
+
+func (v *validator) checkArray(schema map[string]any, arr []any, path string) {
+	if n, ok := intKeyword(schema, "minItems"); ok && len(arr) < n {
+		v.fail(path, "expected at least %d items, got %d", n, len(arr))
+	}
+	if n, ok := intKeyword(schema, "maxItems"); ok && len(arr) > n {
+		v.fail(path, "expected at most %d items, got %d", n, len(arr))
+	}
+	if items, ok := schema["items"].(map[string]any); ok {
+		for i, item := range arr {
+			v.check(items, item, fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+func (v *validator) checkString(schema map[string]any, s string, path string) {
+	length := utf8.RuneCountInString(s)
+	if n, ok := intKeyword(schema, "minLength"); ok && length < n {
+		v.fail(path, "expected at least %d characters, got %d", n, length)
+	}
+	if n, ok := intKeyword(schema, "maxLength"); ok && length > n {
+		v.fail(path, "expected at most %d characters, got %d", n, length)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err == nil && !re.MatchString(s) {
+			v.fail(path, "value does not match pattern %q", pattern)
+		}
+	}
+}
+
+func (v *validator) checkNumber(schema map[string]any, n json.Number, path string) {
+	f, err := n.Float64()
+	if err != nil {
+		return
+	}
+	if bound, ok := floatKeyword(schema, "minimum"); ok && f < bound {
+		v.fail(path, "value %s is below the minimum %v", n, bound)
+	}
+	if bound, ok := floatKeyword(schema, "maximum"); ok && f > bound {
+		v.fail(path, "value %s is above the maximum %v", n, bound)
+	}
+	if bound, ok := floatKeyword(schema, "exclusiveMinimum"); ok && f <= bound {
+		v.fail(path, "value %s must be greater than %v", n, bound)
+	}
+	if bound, ok := floatKeyword(schema, "exclusiveMaximum"); ok && f >= bound {
+		v.fail(path, "value %s must be less than %v", n, bound)
+	}
+}
+
+// resolve looks up a local reference such as #/$defs/Item
+func (v *validator) resolve(ref string) (map[string]any, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("unsupported $ref %q", ref)
+	}
+	var node any = v.root
+	for _, part := range strings.Split(strings.TrimPrefix(strings.TrimPrefix(ref, "#"), "/"), "/") {
+		if part == "" {
+			continue
+		}
+		part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+		node = m[part]
+	}
+	target, ok := node.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unresolvable $ref %q", ref)
+	}
+	return target, nil
+}
+
+func matchesType(t any, value any) bool {
+	switch t := t.(type) {
+	case string:
+		return isType(t, value)
+	case []any:
+		for _, name := range t {
+			if s, ok := name.(string); ok && isType(s, value) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func isType(name string, value any) bool {
+	switch name {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		f, err := n.Float64()
+		return err == nil && f == math.Trunc(f)
+	}
+	return true
+}
+
+func typeOf(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func typeNames(t any) string {
+	if names, ok := t.([]any); ok {
+		parts := make([]string, 0, len(names))
+		for _, n := range names {
+			parts = append(parts, fmt.Sprint(n))
+		}
+		return strings.Join(parts, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+// equal compares two decoded JSON values, treating numbers by value
+func equal(a, b any) bool {
+	if an, ok := asFloat(a); ok {
+		bn, ok := asFloat(b)
+		return ok && an == bn
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func asFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func subschemas(v any) []map[string]any {
+	list, _ := v.([]any)
+	result := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		if m, ok := item.(map[string]any); ok {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+func intKeyword(schema map[string]any, key string) (int, bool) {
+	f, ok := floatKeyword(schema, key)
+	return int(f), ok
+}
+
+func floatKeyword(schema map[string]any, key string) (float64, bool) {
+	v, ok := schema[key]
+	if !ok {
+		return 0, false
+	}
+	return asFloat(v)
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
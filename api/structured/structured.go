@@ -0,0 +1,217 @@
+package structured
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// Format is the structured output contract requested through response_format.
+// Schema is nil for json_object, which only requires syntactically valid JSON.
+type Format struct {
+	Name        string
+	Description string
+	Schema      map[string]any
+}
+
+// FromRequest returns the json_schema or json_object format requested by req.
+// ok is false when the request asks for plain text or sets no response_format.
+func FromRequest(req types.CreateChatCompletionRequest) (format Format, ok bool) {
+	if req.ResponseFormat == nil {
+		return Format{}, false
+	}
+
+	raw, err := req.ResponseFormat.MarshalJSON()
+	if err != nil {
+		return Format{}, false
+	}
+	var probe struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return Format{}, false
+	}
+
+	switch probe.Type {
+	case string(types.JSONSchema):
+		rf, err := req.ResponseFormat.AsResponseFormatJSONSchema()
+		if err != nil {
+			return Format{}, false
+		}
+		format.Name = rf.JSONSchema.Name
+		if rf.JSONSchema.Description != nil {
+			format.Description = *rf.JSONSchema.Description
+		}
+		if rf.JSONSchema.Schema != nil {
+			format.Schema = *rf.JSONSchema.Schema
+		}
+		return format, true
+	case string(types.JSONObject):
+		return Format{}, true
+	default:
+		return Format{}, false
+	}
+}
+
+// Emulate rewrites req for a provider without native structured output
+// support: response_format is removed and replaced by a leading system
+// message describing the expected JSON.
+func Emulate(req *types.CreateChatCompletionRequest, format Format) error {
+	var msg types.Message
+	msg.Role = types.System
+	if err := msg.Content.FromMessageContent0(format.Instructions()); err != nil {
+		return err
+	}
+	req.ResponseFormat = nil
+	req.Messages = append([]types.Message{msg}, req.Messages...)
+	return nil
+}
+
+// Instructions returns the system prompt used to emulate the format
+func (f Format) Instructions() string {
+	var sb strings.Builder
+	sb.WriteString("Respond only with a single valid JSON value. Do not wrap it in markdown code fences and do not add any text before or after it.")
+	if f.Schema == nil {
+		return sb.String()
+	}
+
+	schema, err := json.Marshal(f.Schema)
+	if err != nil {
+		return sb.String()
+	}
+	sb.WriteString(" The JSON must conform to the following JSON Schema")
+	if f.Name != "" {
+		fmt.Fprintf(&sb, " named %q", f.Name)
+	}
+	if f.Description != "" {
+		fmt.Fprintf(&sb, " (%s)", f.Description)
+	}
+	sb.WriteString(":\n")
+	sb.Write(schema)
+	return sb.String()
+}
+
+// Check repairs content into JSON and validates it against the format. On
+// success it returns the compact JSON encoding of the value.
+func (f Format) Check(content string) (string, error) {
+	value, err := Repair(content)
+	if err != nil {
+		return "", err
+	}
+	if f.Schema != nil {
+		if err := Validate(f.Schema, value); err != nil {
+			return "", err
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// ErrNoJSON is returned by Repair when content holds no JSON value
+var ErrNoJSON = errors.New("response does not contain a JSON value")
+
+// Repair decodes the JSON value in content, tolerating the usual ways models
+// decorate it: surrounding prose, markdown code fences and trailing commas
+// before a closing bracket.
+func Repair(content string) (any, error) {
+	text := strings.TrimSpace(content)
+	if fenced, ok := stripFence(text); ok {
+		text = fenced
+	}
+
+	if v, err := decode(text); err == nil {
+		return v, nil
+	}
+
+	start := strings.IndexAny(text, "{[")
+	if start < 0 {
+		return nil, ErrNoJSON
+	}
+	end := strings.LastIndexAny(text, "}]")
+	if end < start {
+		return nil, ErrNoJSON
+	}
+	candidate := text[start : end+1]
+	if v, err := decode(candidate); err == nil {
+		return v, nil
+	}
+
+	v, err := decode(stripTrailingCommas(candidate))
+	if err != nil {
+		return nil, fmt.Errorf("response is not valid JSON: %w", err)
+	}
+	return v, nil
+}
+
+func decode(text string) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+	return v, nil
+}
+
+// stripFence returns the body of the first markdown code fence in text
+func stripFence(text string) (string, bool) {
+	start := strings.Index(text, "```")
+	if start < 0 {
+		return "", false
+	}
+	body := text[start+3:]
+	if nl := strings.IndexByte(body, '\n'); nl >= 0 && !strings.ContainsAny(body[:nl], "{[") {
+		body = body[nl+1:]
+	}
+	end := strings.Index(body, "```")
+	if end < 0 {
+		return "", false
+	}
+	return strings.TrimSpace(body[:end]), true
+}
+
+// stripTrailingCommas drops commas directly followed by } or ], ignoring
+// commas inside strings
+func stripTrailingCommas(text string) string {
+	var sb strings.Builder
+	inString, escaped := false, false
+	for i := 0; i < len(text); i++ {
+		ch := text[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			sb.WriteByte(ch)
+			continue
+		}
+		if ch == '"' {
+			inString = true
+		}
+		if ch == ',' {
+			rest := strings.TrimLeft(text[i+1:], " \t\r\n")
+			if rest != "" && (rest[0] == '}' || rest[0] == ']') {
+				continue
+			}
+		}
+		sb.WriteByte(ch)
+	}
+	return sb.String()
+}
@@ -0,0 +1,157 @@
+package structured
+
+import (
+	"encoding/json"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func TestFromRequest(t *testing.T) {
+	tests := []struct {
+		name           string
+		responseFormat string
+		expectedOK     bool
+		expectedName   string
+		expectedSchema bool
+	}{
+		{name: "No response format", expectedOK: false},
+		{name: "Text", responseFormat: `{"type":"text"}`, expectedOK: false},
+		{name: "JSON object", responseFormat: `{"type":"json_object"}`, expectedOK: true},
+		{
+			name:           "JSON schema",
+			responseFormat: `{"type":"json_schema","json_schema":{"name":"person","schema":{"type":"object"}}}`,
+			expectedOK:     true,
+			expectedName:   "person",
+			expectedSchema: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req types.CreateChatCompletionRequest
+			if tt.responseFormat != "" {
+				require.NoError(t, json.Unmarshal([]byte(`{"model":"m","messages":[],"response_format":`+tt.responseFormat+`}`), &req))
+			}
+			format, ok := FromRequest(req)
+			assert.Equal(t, tt.expectedOK, ok)
+			assert.Equal(t, tt.expectedName, format.Name)
+			assert.Equal(t, tt.expectedSchema, format.Schema != nil)
+		})
+	}
+}
+
+func TestRepair(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		wantErr  bool
+	}{
+		{name: "Plain JSON", content: `{"a":1}`, expected: `{"a":1}`},
+		{name: "Code fence", content: "```json\n{\"a\": [1, 2]}\n```", expected: `{"a":[1,2]}`},
+		{name: "Surrounding prose", content: "Here you go: {\"a\": \"}\"} Hope it helps", expected: `{"a":"}"}`},
+		{name: "Trailing commas", content: `{"a": [1, 2,], "b": "x,]",}`, expected: `{"a":[1,2],"b":"x,]"}`},
+		{name: "Array", content: `[{"a":1}]`, expected: `[{"a":1}]`},
+		{name: "No JSON", content: "I cannot help with that", wantErr: true},
+		{name: "Broken JSON", content: `{"a": }`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Format{}.Check(tt.content)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, got)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	schema := map[string]any{
+		"type":                 "object",
+		"required":             []any{"name", "tags", "kind"},
+		"additionalProperties": false,
+		"$defs": map[string]any{
+			"tag": map[string]any{"type": "string", "minLength": float64(1), "pattern": "^[a-z]+$"},
+		},
+		"properties": map[string]any{
+			"name":  map[string]any{"type": "string", "maxLength": float64(5)},
+			"age":   map[string]any{"type": []any{"integer", "null"}, "minimum": float64(0)},
+			"tags":  map[string]any{"type": "array", "maxItems": float64(2), "items": map[string]any{"$ref": "#/$defs/tag"}},
+			"kind":  map[string]any{"enum": []any{"a", "b"}},
+			"value": map[string]any{"anyOf": []any{map[string]any{"type": "string"}, map[string]any{"type": "number"}}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		value    string
+		expected []string
+	}{
+		{name: "Valid", value: `{"name":"Ada","age":36,"tags":["x"],"kind":"a","value":1.5}`},
+		{name: "Nullable", value: `{"name":"Ada","age":null,"tags":[],"kind":"b"}`},
+		{
+			name:     "Missing and unexpected",
+			value:    `{"name":"Ada","extra":true}`,
+			expected: []string{`$: missing required property "tags"`, `$: missing required property "kind"`, `$: unexpected property "extra"`},
+		},
+		{
+			name:  "Nested violations",
+			value: `{"name":"Adalovelace","age":1.5,"tags":["X","",""],"kind":"c","value":true}`,
+			expected: []string{
+				"$.age: expected integer or null, got number",
+				"$.kind: value is not one of the allowed enum values",
+				"$.name: expected at most 5 characters, got 11",
+				"$.tags: expected at most 2 items, got 3",
+				`$.tags[0]: value does not match pattern "^[a-z]+$"`,
+				"$.tags[1]: expected at least 1 characters, got 0",
+				`$.tags[1]: value does not match pattern "^[a-z]+$"`,
+				"$.tags[2]: expected at least 1 characters, got 0",
+				`$.tags[2]: value does not match pattern "^[a-z]+$"`,
+				"$.value: value does not match any schema in anyOf",
+			},
+		},
+		{name: "Wrong root type", value: `[1]`, expected: []string{"$: expected object, got array"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := Repair(tt.value)
+			require.NoError(t, err)
+			err = Validate(schema, value)
+			if len(tt.expected) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			var messages []string
+			for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+				messages = append(messages, e.Error())
+			}
+			assert.Equal(t, tt.expected, messages)
+		})
+	}
+}
+
+func TestEmulate(t *testing.T) {
+	var req types.CreateChatCompletionRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"model":"m","messages":[{"role":"user","content":"hi"}],
+		"response_format":{"type":"json_schema","json_schema":{"name":"out","schema":{"type":"object"}}}}`), &req))
+
+	format, ok := FromRequest(req)
+	require.True(t, ok)
+	require.NoError(t, Emulate(&req, format))
+
+	assert.Nil(t, req.ResponseFormat)
+	require.Len(t, req.Messages, 2)
+	assert.Equal(t, types.System, req.Messages[0].Role)
+	assert.Contains(t, req.Messages[0].TextContent(), `named "out"`)
+	assert.Contains(t, req.Messages[0].TextContent(), `{"type":"object"}`)
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	structured "github.com/inference-gateway/inference-gateway/api/structured"
+	think "github.com/inference-gateway/inference-gateway/api/think"
+	core "github.com/inference-gateway/inference-gateway/providers/core"
+	routing "github.com/inference-gateway/inference-gateway/providers/routing"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// StructuredOutputError is returned when an emulated structured output
+// response still fails validation after all retries
+type StructuredOutputError struct {
+	errcodes.Response
+	Attempts int    `json:"attempts"`
+	Details  string `json:"details"`
+}
+
+// emulatesStructuredOutput reports whether response_format must be emulated
+// for providerID because it is listed in STRUCTURED_OUTPUT_EMULATED_PROVIDERS
+func (router *RouterImpl) emulatesStructuredOutput(providerID types.Provider) bool {
+	return routing.ParseModelSet(router.cfg().StructuredOutputEmulatedProviders)[string(providerID)]
+}
+
+// structuredChatCompletions emulates response_format for providers without
+// native support. The schema is injected as a system message, and every
+// choice of the response is repaired and validated. Invalid responses are
+// retried up to STRUCTURED_OUTPUT_MAX_RETRIES times with the validation errors
+// fed back to the model; usage is summed across attempts. On success the
+// choices carry the compact, validated JSON. Otherwise an error response has
+// already been written and ok is false.
+func (router *RouterImpl) structuredChatCompletions(ctx context.Context, c *gin.Context, provider core.IProvider, providerID types.Provider, req types.CreateChatCompletionRequest, format structured.Format) (types.CreateChatCompletionResponse, bool) {
+	if err := structured.Emulate(&req, format); err != nil {
+		router.log(c).Error("failed to build structured output instructions", err)
+		errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to prepare structured output request")
+		return types.CreateChatCompletionResponse{}, false
+	}
+
+	attempts := 1 + max(router.cfg().StructuredOutputMaxRetries, 0)
+	var usage *types.CompletionUsage
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		response, err := provider.ChatCompletions(ctx, req)
+		if err != nil {
+			router.writeProviderError(ctx, c, err, providerID, req.Model)
+			return types.CreateChatCompletionResponse{}, false
+		}
+		usage = addUsage(usage, response.Usage)
+		think.Apply(router.thinkMode(), &response)
+
+		invalid, err := checkChoices(&response, format)
+		if err == nil {
+			response.Usage = usage
+			router.log(c).Debug("structured output validated", "provider", providerID, "model", req.Model, "attempt", attempt)
+			return response, true
+		}
+		lastErr = err
+		router.log(c).Warn("structured output failed validation", "provider", providerID, "model", req.Model, "attempt", attempt, "error", err.Error())
+
+		if err := appendRepairTurn(&req, invalid, err); err != nil {
+			router.log(c).Error("failed to build structured output retry", err)
+			break
+		}
+	}
+
+	c.Set(errcodes.ContextKey, errcodes.StructuredOutputInvalid.ID)
+	c.JSON(http.StatusUnprocessableEntity, StructuredOutputError{
+		Response: errcodes.StructuredOutputInvalid.Response(fmt.Sprintf("The model response did not match the requested response_format after %d attempts", attempts)),
+		Attempts: attempts,
+		Details:  lastErr.Error(),
+	})
+	return types.CreateChatCompletionResponse{}, false
+}
+
+// checkChoices validates every choice of response against format, replacing
+// valid contents with their canonical JSON. It returns the first invalid
+// content and its validation error.
+func checkChoices(response *types.CreateChatCompletionResponse, format structured.Format) (string, error) {
+	if len(response.Choices) == 0 {
+		return "", fmt.Errorf("response has no choices")
+	}
+	for i := range response.Choices {
+		msg := &response.Choices[i].Message
+		content := msg.TextContent()
+		valid, err := format.Check(content)
+		if err != nil {
+			return content, err
+		}
+		if err := msg.Content.FromMessageContent0(valid); err != nil {
+			return content, err
+		}
+	}
+	return "", nil
+}
+
+// appendRepairTurn adds the invalid answer and a correction request to the
+// conversation for the next attempt
+func appendRepairTurn(req *types.CreateChatCompletionRequest, invalid string, validationErr error) error {
+	var answer, correction types.Message
+	answer.Role = types.Assistant
+	if err := answer.Content.FromMessageContent0(invalid); err != nil {
+		return err
+	}
+	correction.Role = types.User
+	prompt := fmt.Sprintf("Your previous response was rejected because it is not valid for the requested format:\n%s\nReply again with only the corrected JSON.", validationErr)
+	if err := correction.Content.FromMessageContent0(prompt); err != nil {
+		return err
+	}
+	req.Messages = append(req.Messages, answer, correction)
+	return nil
+}
+
+func addUsage(total, usage *types.CompletionUsage) *types.CompletionUsage {
+	if usage == nil {
+		return total
+	}
+	if total == nil {
+		u := *usage
+		return &u
+	}
+	total.PromptTokens += usage.PromptTokens
+	total.CompletionTokens += usage.CompletionTokens
+	total.TotalTokens += usage.TotalTokens
+	return total
+}
@@ -0,0 +1,440 @@
+// Package tenants isolates the callers sharing a gateway. A tenant can bring
+// its own provider credentials, a narrower model allow list and a request
+// rate and monthly budget, and is reported separately in metrics and logs.
+package tenants
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	client "github.com/inference-gateway/inference-gateway/providers/client"
+	core "github.com/inference-gateway/inference-gateway/providers/core"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	routing "github.com/inference-gateway/inference-gateway/providers/routing"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// ProviderOverride replaces parts of a gateway provider configuration for one
+// tenant. ${VAR} references in APIKey are expanded from the environment so
+// keys need not be written to the file.
+type ProviderOverride struct {
+	APIKey string `yaml:"api_key"`
+	URL    string `yaml:"url"`
+}
+
+// Budget caps the estimated spend of a tenant per calendar month (UTC).
+// Responses carry a warning once WarnPercent of MonthlyUSD is spent, 80 by
+// default, and requests are rejected from BlockPercent on, 100 by default.
+type Budget struct {
+	MonthlyUSD   float64 `yaml:"monthly_usd"`
+	WarnPercent  float64 `yaml:"warn_percent"`
+	BlockPercent float64 `yaml:"block_percent"`
+}
+
+// Default budget thresholds, in percent of the monthly budget
+const (
+	DefaultBudgetWarnPercent  = 80
+	DefaultBudgetBlockPercent = 100
+)
+
+// Tenant is one entry of the tenants file. Providers without an override use
+// the gateway's configuration; empty AllowedModels, a zero RequestsPerMinute
+// and a nil Budget impose no tenant restriction.
+type Tenant struct {
+	Providers         map[types.Provider]ProviderOverride `yaml:"providers"`
+	AllowedModels     []string                            `yaml:"allowed_models"`
+	RequestsPerMinute int                                 `yaml:"requests_per_minute"`
+	Budget            *Budget                             `yaml:"budget"`
+}
+
+// APIKey is one entry of the api_keys of the tenants file, a budget held by
+// the callers of one API key across tenants
+type APIKey struct {
+	Budget *Budget `yaml:"budget"`
+}
+
+// Config is the on-disk tenants file, keyed by tenant ID. APIKeys are keyed
+// by the SHA-256 of the key in hex, so the file holds no key, and
+// UntenantedBudget caps the requests resolved to no tenant.
+type Config struct {
+	Tenants          map[string]Tenant `yaml:"tenants"`
+	APIKeys          map[string]APIKey `yaml:"api_keys"`
+	UntenantedBudget *Budget           `yaml:"untenanted_budget"`
+}
+
+// LoadConfig reads and parses the tenants YAML file at path
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read tenants config: %w", err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse tenants config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// FromContext returns the tenant ID of a request, or "" when the request does
+// not belong to a tenant
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(types.TenantContextKey).(string)
+	return id
+}
+
+// Owner identifies the caller of a request, so what a caller created, such
+// as files, batches or queued requests, is only served back to that caller.
+// The caller is the subject of its verified ID token, set by the auth
+// middleware, or else its API key (APIKeyID), within its tenant. It holds
+// across token refreshes and ignores cookies. Requests with neither a
+// subject nor an API key share the owner of their tenant.
+func Owner(ctx context.Context, header http.Header) string {
+	kind, id := "key", APIKeyID(header)
+	if subject, _ := ctx.Value(types.AuthSubjectContextKey).(string); subject != "" {
+		kind, id = "subject", subject
+	}
+	h := sha256.New()
+	for _, part := range []string{FromContext(ctx), kind, id} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// APIKeyID identifies the API key of a request by the SHA-256 in hex of its
+// X-Api-Key header, or else of the bearer token of its Authorization header,
+// the way the api_keys of the tenants file name it. It is "" when the
+// request carries neither.
+func APIKeyID(header http.Header) string {
+	key := header.Get("X-Api-Key")
+	if key == "" {
+		if token, ok := strings.CutPrefix(header.Get("Authorization"), "Bearer "); ok {
+			key = strings.TrimSpace(token)
+		}
+	}
+	if key == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Registry returns the provider registry serving the tenant of ctx. It is r
+// itself unless r is a Store and ctx carries a tenant.
+func Registry(ctx context.Context, r registry.ProviderRegistry) registry.ProviderRegistry {
+	store, ok := r.(*Store)
+	if !ok {
+		return r
+	}
+	if id := FromContext(ctx); id != "" {
+		return store.ForTenant(id)
+	}
+	return r
+}
+
+// AllowsModel reports whether the tenant of ctx may use model. It is true
+// unless r is a Store and ctx carries a tenant.
+func AllowsModel(ctx context.Context, r registry.ProviderRegistry, model string) bool {
+	store, ok := r.(*Store)
+	if !ok {
+		return true
+	}
+	return store.ModelAllowed(FromContext(ctx), model)
+}
+
+// FilterModels drops the models the tenant of ctx may not use
+func FilterModels(ctx context.Context, r registry.ProviderRegistry, models []types.Model) []types.Model {
+	store, ok := r.(*Store)
+	if !ok {
+		return models
+	}
+	return store.FilterModels(FromContext(ctx), models)
+}
+
+// validateBudget checks b and applies the default thresholds
+func validateBudget(b Budget) (Budget, error) {
+	if b.MonthlyUSD <= 0 {
+		return b, fmt.Errorf("budget monthly_usd must be positive")
+	}
+	if b.WarnPercent == 0 {
+		b.WarnPercent = DefaultBudgetWarnPercent
+	}
+	if b.BlockPercent == 0 {
+		b.BlockPercent = max(DefaultBudgetBlockPercent, b.WarnPercent)
+	}
+	if b.WarnPercent < 0 || b.WarnPercent > b.BlockPercent {
+		return b, fmt.Errorf("budget warn_percent must be between 0 and block_percent")
+	}
+	return b, nil
+}
+
+// tenant is a validated Tenant with its parsed model set, rate limiter and
+// lazily built provider registry
+type tenant struct {
+	Tenant
+	models   map[string]bool
+	limiter  *limiter
+	registry registry.ProviderRegistry
+}
+
+// Store holds the configured tenants. It is a ProviderRegistry serving the
+// gateway's own provider configuration, and builds the registry of a tenant
+// on its first request.
+type Store struct {
+	base    registry.ProviderRegistry
+	logger  logger.Logger
+	now     func() time.Time
+	mu      sync.Mutex
+	tenants map[string]*tenant
+	// keyBudgets are the budgets of the api_keys, keyed like them
+	keyBudgets map[string]Budget
+	// untenanted is the budget of the requests of no tenant, nil for none
+	untenanted *Budget
+}
+
+// NewStore validates cfg and creates a store layering its tenants over base.
+// A nil cfg yields a store without tenants.
+func NewStore(cfg *Config, base registry.ProviderRegistry, logger logger.Logger) (*Store, error) {
+	s := &Store{
+		base:       base,
+		logger:     logger,
+		now:        time.Now,
+		tenants:    make(map[string]*tenant),
+		keyBudgets: make(map[string]Budget),
+	}
+	if cfg == nil {
+		return s, nil
+	}
+
+	for id, t := range cfg.Tenants {
+		if id == "" {
+			return nil, fmt.Errorf("tenant with empty id")
+		}
+		if t.RequestsPerMinute < 0 {
+			return nil, fmt.Errorf("tenant %s: requests_per_minute must not be negative", id)
+		}
+		providers := make(map[types.Provider]ProviderOverride, len(t.Providers))
+		for providerID, override := range t.Providers {
+			if !registry.Known(providerID) {
+				return nil, fmt.Errorf("tenant %s: unknown provider %q", id, providerID)
+			}
+			override.APIKey = os.ExpandEnv(override.APIKey)
+			providers[providerID] = override
+		}
+		t.Providers = providers
+		if t.Budget != nil {
+			budget, err := validateBudget(*t.Budget)
+			if err != nil {
+				return nil, fmt.Errorf("tenant %s: %w", id, err)
+			}
+			t.Budget = &budget
+		}
+
+		entry := &tenant{Tenant: t, models: make(map[string]bool)}
+		for _, model := range t.AllowedModels {
+			for m := range routing.ParseModelSet(model) {
+				entry.models[m] = true
+			}
+		}
+		if t.RequestsPerMinute > 0 {
+			entry.limiter = newLimiter(t.RequestsPerMinute, s.now())
+		}
+		s.tenants[id] = entry
+	}
+
+	for key, k := range cfg.APIKeys {
+		if decoded, err := hex.DecodeString(key); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("api key %q: must be the SHA-256 of the key in hex", key)
+		}
+		if k.Budget == nil {
+			continue
+		}
+		budget, err := validateBudget(*k.Budget)
+		if err != nil {
+			return nil, fmt.Errorf("api key %s: %w", key, err)
+		}
+		s.keyBudgets[strings.ToLower(key)] = budget
+	}
+	if cfg.UntenantedBudget != nil {
+		budget, err := validateBudget(*cfg.UntenantedBudget)
+		if err != nil {
+			return nil, fmt.Errorf("untenanted_budget: %w", err)
+		}
+		s.untenanted = &budget
+	}
+	return s, nil
+}
+
+// Enabled reports whether any tenant is configured
+func (s *Store) Enabled() bool {
+	return len(s.tenants) > 0
+}
+
+// Has reports whether id is a configured tenant
+func (s *Store) Has(id string) bool {
+	_, ok := s.tenants[id]
+	return ok
+}
+
+// Allow takes one request from the rate limit of tenant id. When the limit is
+// exhausted it returns false and how long to wait before retrying.
+func (s *Store) Allow(id string) (bool, time.Duration) {
+	t, ok := s.tenants[id]
+	if !ok || t.limiter == nil {
+		return true, 0
+	}
+	return t.limiter.allow(s.now())
+}
+
+// Budget returns the monthly budget of tenant id, the untenanted_budget for
+// "", false when it has none
+func (s *Store) Budget(id string) (Budget, bool) {
+	if id == "" && s.untenanted != nil {
+		return *s.untenanted, true
+	}
+	t, ok := s.tenants[id]
+	if !ok || t.Budget == nil {
+		return Budget{}, false
+	}
+	return *t.Budget, true
+}
+
+// KeyBudget returns the monthly budget of the API key identified by key, as
+// returned by APIKeyID, false when it has none
+func (s *Store) KeyBudget(key string) (Budget, bool) {
+	budget, ok := s.keyBudgets[key]
+	return budget, ok
+}
+
+// ModelAllowed reports whether tenant id may use model. Tenants without
+// allowed_models may use every model the gateway allows.
+func (s *Store) ModelAllowed(id, model string) bool {
+	t, ok := s.tenants[id]
+	if !ok || len(t.models) == 0 {
+		return true
+	}
+	return routing.ModelMatches(t.models, model)
+}
+
+// FilterModels drops the models tenant id may not use
+func (s *Store) FilterModels(id string, models []types.Model) []types.Model {
+	filtered := make([]types.Model, 0, len(models))
+	for _, model := range models {
+		if s.ModelAllowed(id, model.ID) {
+			filtered = append(filtered, model)
+		}
+	}
+	return filtered
+}
+
+// ForTenant returns the provider registry of tenant id: the gateway's
+// provider configurations with the tenant's overrides applied. Unknown
+// tenants get the gateway's registry.
+func (s *Store) ForTenant(id string) registry.ProviderRegistry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tenants[id]
+	if !ok {
+		return s.base
+	}
+	if t.registry == nil {
+		base := s.base.GetProviders()
+		providers := make(map[types.Provider]*registry.ProviderConfig, len(base))
+		for providerID, providerCfg := range base {
+			tenantCfg := *providerCfg
+			if override, ok := t.Providers[providerID]; ok {
+				if override.APIKey != "" {
+					tenantCfg.Token = override.APIKey
+				}
+				if override.URL != "" {
+					tenantCfg.URL = override.URL
+				}
+			}
+			providers[providerID] = &tenantCfg
+		}
+		t.registry = &tenantRegistry{ProviderRegistry: registry.NewProviderRegistry(providers, s.logger), base: s.base}
+		s.logger.Debug("built tenant provider registry", "tenant", id, "overrides", len(t.Providers))
+	}
+	return t.registry
+}
+
+// Reload drops the tenant registries so they are rebuilt from the current
+// gateway provider configurations on their next request
+func (s *Store) Reload() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.tenants {
+		t.registry = nil
+	}
+}
+
+func (s *Store) GetProviders() map[types.Provider]*registry.ProviderConfig {
+	return s.base.GetProviders()
+}
+
+func (s *Store) BuildProvider(providerID types.Provider, c client.Client) (core.IProvider, error) {
+	return s.base.BuildProvider(providerID, c)
+}
+
+var _ registry.ProviderRegistry = (*Store)(nil)
+
+// tenantRegistry is the registry of a tenant. Providers disabled at runtime on
+// the gateway's registry are disabled for every tenant.
+type tenantRegistry struct {
+	registry.ProviderRegistry
+	base registry.ProviderRegistry
+}
+
+func (r *tenantRegistry) BuildProvider(providerID types.Provider, c client.Client) (core.IProvider, error) {
+	if err := registry.CheckEnabled(r.base, providerID); err != nil {
+		return nil, err
+	}
+	return r.ProviderRegistry.BuildProvider(providerID, c)
+}
+
+// limiter is a token bucket holding up to a minute of requests, refilled
+// continuously at the configured rate
+type limiter struct {
+	mu       sync.Mutex
+	capacity float64
+	perSec   float64
+	tokens   float64
+	last     time.Time
+}
+
+func newLimiter(perMinute int, now time.Time) *limiter {
+	return &limiter{
+		capacity: float64(perMinute),
+		perSec:   float64(perMinute) / 60,
+		tokens:   float64(perMinute),
+		last:     now,
+	}
+}
+
+func (l *limiter) allow(now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elapsed := now.Sub(l.last).Seconds(); elapsed > 0 {
+		l.tokens = math.Min(l.capacity, l.tokens+elapsed*l.perSec)
+		l.last = now
+	}
+	if l.tokens >= 1 {
+		l.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - l.tokens) / l.perSec * float64(time.Second))
+	return false, wait
+}
@@ -0,0 +1,223 @@
+package tenants
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func baseRegistry() *registry.ReloadableRegistry {
+	return registry.NewReloadableRegistry(map[types.Provider]*registry.ProviderConfig{
+		constants.OpenaiID: {
+			ID:       constants.OpenaiID,
+			URL:      "https://api.openai.com/v1",
+			Token:    "gateway-key",
+			AuthType: constants.AuthTypeBearer,
+		},
+		constants.AnthropicID: {
+			ID:       constants.AnthropicID,
+			URL:      "https://api.anthropic.com/v1",
+			Token:    "gateway-anthropic-key",
+			AuthType: constants.AuthTypeXheader,
+		},
+	}, logger.NewNoopLogger())
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("ACME_OPENAI_KEY", "acme-key")
+	path := filepath.Join(t.TempDir(), "tenants.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+tenants:
+  acme:
+    allowed_models: ['openai/gpt-4o']
+    requests_per_minute: 60
+    providers:
+      openai:
+        api_key: ${ACME_OPENAI_KEY}
+        url: https://acme.example.com/v1
+`), 0o600))
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	require.Contains(t, cfg.Tenants, "acme")
+	assert.Equal(t, 60, cfg.Tenants["acme"].RequestsPerMinute)
+
+	store, err := NewStore(cfg, baseRegistry(), logger.NewNoopLogger())
+	require.NoError(t, err)
+	assert.Equal(t, "acme-key", store.tenants["acme"].Providers[constants.OpenaiID].APIKey, "env references are expanded")
+}
+
+func TestNewStore_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"unknown provider", Config{Tenants: map[string]Tenant{"acme": {Providers: map[types.Provider]ProviderOverride{"nope": {APIKey: "k"}}}}}},
+		{"negative rate", Config{Tenants: map[string]Tenant{"acme": {RequestsPerMinute: -1}}}},
+		{"empty id", Config{Tenants: map[string]Tenant{"": {}}}},
+		{"budget without amount", Config{Tenants: map[string]Tenant{"acme": {Budget: &Budget{WarnPercent: 50}}}}},
+		{"warning above block", Config{Tenants: map[string]Tenant{"acme": {Budget: &Budget{MonthlyUSD: 10, WarnPercent: 90, BlockPercent: 80}}}}},
+		{"api key not hashed", Config{APIKeys: map[string]APIKey{"sk-acme": {Budget: &Budget{MonthlyUSD: 10}}}}},
+		{"untenanted budget without amount", Config{UntenantedBudget: &Budget{}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewStore(&tt.cfg, baseRegistry(), logger.NewNoopLogger())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestStore_Budget(t *testing.T) {
+	store, err := NewStore(&Config{Tenants: map[string]Tenant{
+		"acme":    {Budget: &Budget{MonthlyUSD: 100}},
+		"initech": {Budget: &Budget{MonthlyUSD: 50, WarnPercent: 90, BlockPercent: 120}},
+		"globex":  {},
+	}}, baseRegistry(), logger.NewNoopLogger())
+	require.NoError(t, err)
+
+	budget, ok := store.Budget("acme")
+	require.True(t, ok)
+	assert.Equal(t, Budget{MonthlyUSD: 100, WarnPercent: 80, BlockPercent: 100}, budget, "default thresholds")
+	budget, ok = store.Budget("initech")
+	require.True(t, ok)
+	assert.Equal(t, Budget{MonthlyUSD: 50, WarnPercent: 90, BlockPercent: 120}, budget)
+	_, ok = store.Budget("globex")
+	assert.False(t, ok)
+	_, ok = store.Budget("")
+	assert.False(t, ok)
+}
+
+func TestStore_KeyBudget(t *testing.T) {
+	header := http.Header{"Authorization": {"Bearer sk-acme"}}
+	key := APIKeyID(header)
+	require.Len(t, key, 64)
+	assert.Equal(t, key, APIKeyID(http.Header{"X-Api-Key": {"sk-acme"}}), "X-Api-Key and bearer tokens name keys alike")
+	assert.Empty(t, APIKeyID(http.Header{}))
+
+	store, err := NewStore(&Config{
+		APIKeys:          map[string]APIKey{strings.ToUpper(key): {Budget: &Budget{MonthlyUSD: 20}}},
+		UntenantedBudget: &Budget{MonthlyUSD: 5},
+	}, baseRegistry(), logger.NewNoopLogger())
+	require.NoError(t, err)
+
+	budget, ok := store.KeyBudget(key)
+	require.True(t, ok)
+	assert.Equal(t, Budget{MonthlyUSD: 20, WarnPercent: 80, BlockPercent: 100}, budget)
+	_, ok = store.KeyBudget(APIKeyID(http.Header{"X-Api-Key": {"sk-globex"}}))
+	assert.False(t, ok)
+	budget, ok = store.Budget("")
+	require.True(t, ok, "requests of no tenant are held to the untenanted_budget")
+	assert.Equal(t, 5.0, budget.MonthlyUSD)
+	assert.False(t, store.Enabled(), "budgets alone configure no tenant")
+}
+
+func TestOwner(t *testing.T) {
+	withSubject := func(tenant, subject string) context.Context {
+		ctx := context.WithValue(context.Background(), types.TenantContextKey, tenant)
+		return context.WithValue(ctx, types.AuthSubjectContextKey, subject)
+	}
+	bearer := func(token string) http.Header {
+		return http.Header{"Authorization": {"Bearer " + token}}
+	}
+
+	alice := Owner(withSubject("acme", "alice"), bearer("token-1"))
+	assert.Equal(t, alice, Owner(withSubject("acme", "alice"), bearer("token-2")), "a refreshed token keeps the owner")
+	assert.Equal(t, alice, Owner(withSubject("acme", "alice"), http.Header{"Cookie": {"session=1"}}), "cookies are ignored")
+	assert.NotEqual(t, alice, Owner(withSubject("acme", "bob"), bearer("token-1")), "subjects of a tenant are told apart")
+	assert.NotEqual(t, alice, Owner(withSubject("globex", "alice"), bearer("token-1")), "the tenant is part of the owner")
+
+	key := Owner(context.Background(), http.Header{"X-Api-Key": {"sk-1"}})
+	assert.Equal(t, key, Owner(context.Background(), http.Header{"X-Api-Key": {"sk-1"}, "Cookie": {"session=1"}, "X-Admin-Token": {"admin"}}), "without a subject the API key is the owner")
+	assert.NotEqual(t, key, Owner(context.Background(), http.Header{"X-Api-Key": {"sk-2"}}))
+	assert.NotEqual(t, key, Owner(withSubject("", APIKeyID(http.Header{"X-Api-Key": {"sk-1"}})), http.Header{}), "subjects and API keys never collide")
+}
+
+func TestStore_ForTenant(t *testing.T) {
+	base := baseRegistry()
+	store, err := NewStore(&Config{Tenants: map[string]Tenant{
+		"acme": {Providers: map[types.Provider]ProviderOverride{
+			constants.OpenaiID: {APIKey: "acme-key", URL: "https://acme.example.com/v1"},
+		}},
+		"globex": {},
+	}}, base, logger.NewNoopLogger())
+	require.NoError(t, err)
+
+	acme := store.ForTenant("acme")
+	assert.Equal(t, "acme-key", acme.GetProviders()[constants.OpenaiID].Token)
+	assert.Equal(t, "https://acme.example.com/v1", acme.GetProviders()[constants.OpenaiID].URL)
+	assert.Equal(t, "gateway-anthropic-key", acme.GetProviders()[constants.AnthropicID].Token, "providers without override use the gateway configuration")
+	assert.Same(t, acme, store.ForTenant("acme"), "tenant registries are built once")
+	assert.Equal(t, "gateway-key", base.GetProviders()[constants.OpenaiID].Token, "the gateway configuration is not modified")
+	assert.Equal(t, "gateway-key", store.ForTenant("globex").GetProviders()[constants.OpenaiID].Token)
+
+	ctx := context.WithValue(context.Background(), types.TenantContextKey, "acme")
+	assert.Same(t, acme, Registry(ctx, store))
+	assert.Same(t, store, Registry(context.Background(), store), "requests without a tenant use the gateway registry")
+	assert.Same(t, base, Registry(ctx, base), "plain registries are not tenant aware")
+
+	base.Reload(map[types.Provider]*registry.ProviderConfig{
+		constants.OpenaiID: {ID: constants.OpenaiID, Token: "rotated-key", AuthType: constants.AuthTypeBearer},
+	})
+	store.Reload()
+	assert.Equal(t, "rotated-key", store.ForTenant("globex").GetProviders()[constants.OpenaiID].Token, "reload rebuilds tenant registries")
+	assert.Equal(t, "acme-key", store.ForTenant("acme").GetProviders()[constants.OpenaiID].Token)
+}
+
+func TestStore_ModelAllowed(t *testing.T) {
+	store, err := NewStore(&Config{Tenants: map[string]Tenant{
+		"acme":   {AllowedModels: []string{"openai/gpt-4o", "claude-sonnet-4"}},
+		"globex": {},
+	}}, baseRegistry(), logger.NewNoopLogger())
+	require.NoError(t, err)
+
+	assert.True(t, store.ModelAllowed("acme", "openai/gpt-4o"))
+	assert.True(t, store.ModelAllowed("acme", "anthropic/claude-sonnet-4"))
+	assert.False(t, store.ModelAllowed("acme", "openai/gpt-4o-mini"))
+	assert.True(t, store.ModelAllowed("globex", "openai/gpt-4o-mini"))
+	assert.True(t, store.ModelAllowed("", "openai/gpt-4o-mini"))
+
+	models := []types.Model{{ID: "openai/gpt-4o"}, {ID: "openai/gpt-4o-mini"}}
+	ctx := context.WithValue(context.Background(), types.TenantContextKey, "acme")
+	assert.Equal(t, []types.Model{{ID: "openai/gpt-4o"}}, FilterModels(ctx, store, models))
+	assert.Equal(t, models, FilterModels(context.Background(), store, models))
+	assert.False(t, AllowsModel(ctx, store, "openai/gpt-4o-mini"))
+}
+
+func TestStore_Allow(t *testing.T) {
+	store, err := NewStore(&Config{Tenants: map[string]Tenant{
+		"acme":   {RequestsPerMinute: 2},
+		"globex": {},
+	}}, baseRegistry(), logger.NewNoopLogger())
+	require.NoError(t, err)
+
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	for range 2 {
+		ok, _ := store.Allow("acme")
+		assert.True(t, ok)
+	}
+	ok, retryAfter := store.Allow("acme")
+	assert.False(t, ok)
+	assert.Equal(t, 30*time.Second, retryAfter)
+
+	ok, _ = store.Allow("globex")
+	assert.True(t, ok, "tenants without a limit are not limited")
+
+	now = now.Add(30 * time.Second)
+	ok, _ = store.Allow("acme")
+	assert.True(t, ok, "the bucket refills over time")
+}
@@ -0,0 +1,261 @@
+// Package think post-processes the <think>...</think> blocks that reasoning
+// models such as DeepSeek-R1 served by Ollama emit in their content. The
+// blocks are removed, collapsed to empty tags, or relocated to the
+// reasoning_content field OpenAI-compatible clients read the reasoning from,
+// in complete responses and in streams, where the tags may be split across
+// chunks.
+package think
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// Mode is how think blocks are handled, the value of THINK_TAG_MODE
+type Mode string
+
+const (
+	// Off passes think blocks through
+	Off Mode = "off"
+	// Remove drops think blocks
+	Remove Mode = "remove"
+	// Collapse replaces think blocks with empty think tags
+	Collapse Mode = "collapse"
+	// Relocate moves the reasoning of think blocks to reasoning_content
+	Relocate Mode = "reasoning_content"
+)
+
+const (
+	openTag  = "<think>"
+	closeTag = "</think>"
+)
+
+// ParseMode parses a THINK_TAG_MODE value, empty meaning Off
+func ParseMode(s string) (Mode, error) {
+	switch mode := Mode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case "":
+		return Off, nil
+	case Off, Remove, Collapse, Relocate:
+		return mode, nil
+	default:
+		return Off, fmt.Errorf("invalid THINK_TAG_MODE %q: expected off, remove, collapse or reasoning_content", s)
+	}
+}
+
+// splitter separates the think blocks of a text received in pieces
+type splitter struct {
+	mode    Mode
+	inThink bool
+	// pending is the end of the last piece that may start a tag
+	pending string
+	// trim drops the whitespace separating a think block from what follows,
+	// trimReasoning the whitespace starting its reasoning
+	trim          bool
+	trimReasoning bool
+}
+
+// split consumes the next piece of text and returns its content, rewritten
+// according to the mode, and its reasoning
+func (s *splitter) split(text string) (content, reasoning string) {
+	text = s.pending + text
+	s.pending = ""
+
+	var c, r strings.Builder
+	emit := func(part string) {
+		if s.inThink {
+			if s.trimReasoning {
+				part = strings.TrimLeftFunc(part, unicode.IsSpace)
+				s.trimReasoning = part == ""
+			}
+			r.WriteString(part)
+			return
+		}
+		if s.trim {
+			part = strings.TrimLeftFunc(part, unicode.IsSpace)
+			s.trim = part == ""
+		}
+		c.WriteString(part)
+	}
+
+	for {
+		tag := openTag
+		if s.inThink {
+			tag = closeTag
+		}
+		i := strings.Index(text, tag)
+		if i < 0 {
+			break
+		}
+		emit(text[:i])
+		text = text[i+len(tag):]
+		s.inThink = !s.inThink
+		if s.inThink && s.mode == Collapse {
+			c.WriteString(openTag + closeTag)
+		}
+		s.trim = !s.inThink
+		s.trimReasoning = s.inThink
+	}
+
+	tag := openTag
+	if s.inThink {
+		tag = closeTag
+	}
+	for k := min(len(tag)-1, len(text)); k > 0; k-- {
+		if strings.HasSuffix(text, tag[:k]) {
+			s.pending = text[len(text)-k:]
+			text = text[:len(text)-k]
+			break
+		}
+	}
+	emit(text)
+	return c.String(), r.String()
+}
+
+// flush returns the content and reasoning held back as a possible tag
+func (s *splitter) flush() (content, reasoning string) {
+	pending := s.pending
+	s.pending = ""
+	if s.inThink {
+		return "", pending
+	}
+	return pending, ""
+}
+
+// Apply rewrites the think blocks in the content of every choice of resp
+func Apply(mode Mode, resp *types.CreateChatCompletionResponse) {
+	if mode == Off {
+		return
+	}
+	for i := range resp.Choices {
+		message := &resp.Choices[i].Message
+		text, err := message.Content.AsMessageContent0()
+		if err != nil || !strings.Contains(text, openTag) {
+			continue
+		}
+		s := splitter{mode: mode}
+		content, reasoning := s.split(text)
+		tailContent, tailReasoning := s.flush()
+		content += tailContent
+		reasoning = strings.TrimSpace(reasoning + tailReasoning)
+
+		if err := message.Content.FromMessageContent0(content); err != nil {
+			continue
+		}
+		if mode == Relocate && reasoning != "" {
+			message.ReasoningContent = appendReasoning(message.ReasoningContent, reasoning)
+		}
+	}
+}
+
+// appendReasoning adds reasoning to the reasoning a provider already set
+func appendReasoning(existing *string, reasoning string) *string {
+	if existing != nil && *existing != "" {
+		reasoning = *existing + "\n" + reasoning
+	}
+	return &reasoning
+}
+
+// Stream rewrites the think blocks of a chat completion stream, line by line
+type Stream struct {
+	mode      Mode
+	splitters map[int]*splitter
+}
+
+// NewStream returns the rewriter of one stream, or nil when mode is Off
+func NewStream(mode Mode) *Stream {
+	if mode == Off {
+		return nil
+	}
+	return &Stream{mode: mode, splitters: make(map[int]*splitter)}
+}
+
+// Line rewrites the delta content of an SSE data line. Lines without think
+// blocks are returned as they are.
+func (s *Stream) Line(line []byte) []byte {
+	data, ok := bytes.CutPrefix(bytes.TrimSpace(line), []byte("data:"))
+	if !ok {
+		return line
+	}
+	data = bytes.TrimSpace(data)
+	if !bytes.Contains(data, []byte(`"content"`)) && !bytes.Contains(data, []byte(`"finish_reason"`)) {
+		return line
+	}
+
+	var chunk map[string]json.RawMessage
+	if json.Unmarshal(data, &chunk) != nil {
+		return line
+	}
+	var choices []map[string]json.RawMessage
+	if json.Unmarshal(chunk["choices"], &choices) != nil {
+		return line
+	}
+
+	changed := false
+	for _, choice := range choices {
+		if s.rewriteChoice(choice) {
+			changed = true
+		}
+	}
+	if !changed {
+		return line
+	}
+
+	chunk["choices"], _ = json.Marshal(choices)
+	out, err := json.Marshal(chunk)
+	if err != nil {
+		return line
+	}
+	out = append([]byte("data: "), out...)
+	if bytes.HasSuffix(line, []byte("\n\n")) {
+		out = append(out, '\n', '\n')
+	} else if bytes.HasSuffix(line, []byte("\n")) {
+		out = append(out, '\n')
+	}
+	return out
+}
+
+// rewriteChoice rewrites the delta of one streamed choice, reporting whether
+// it changed
+func (s *Stream) rewriteChoice(choice map[string]json.RawMessage) bool {
+	var index int
+	_ = json.Unmarshal(choice["index"], &index)
+	var delta map[string]json.RawMessage
+	if json.Unmarshal(choice["delta"], &delta) != nil || delta == nil {
+		return false
+	}
+	var text string
+	_ = json.Unmarshal(delta["content"], &text)
+
+	sp := s.splitters[index]
+	if sp == nil {
+		if !strings.Contains(text, "<") {
+			return false
+		}
+		sp = &splitter{mode: s.mode}
+		s.splitters[index] = sp
+	}
+
+	content, reasoning := sp.split(text)
+	if finish, ok := choice["finish_reason"]; ok && !bytes.Equal(finish, []byte("null")) {
+		tailContent, tailReasoning := sp.flush()
+		content += tailContent
+		reasoning += tailReasoning
+	}
+	if content == text && reasoning == "" {
+		return false
+	}
+
+	delta["content"], _ = json.Marshal(content)
+	if s.mode == Relocate && reasoning != "" {
+		var existing string
+		_ = json.Unmarshal(delta["reasoning_content"], &existing)
+		delta["reasoning_content"], _ = json.Marshal(existing + reasoning)
+	}
+	choice["delta"], _ = json.Marshal(delta)
+	return true
+}
@@ -0,0 +1,127 @@
+package think
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func TestParseMode(t *testing.T) {
+	for value, expected := range map[string]Mode{
+		"":                  Off,
+		"off":               Off,
+		"Remove":            Remove,
+		" collapse ":        Collapse,
+		"reasoning_content": Relocate,
+	} {
+		mode, err := ParseMode(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, mode, value)
+	}
+	_, err := ParseMode("relocate")
+	assert.ErrorContains(t, err, "THINK_TAG_MODE")
+}
+
+func TestApply(t *testing.T) {
+	const text = "<think>\nThe user wants 6 times 7.\n</think>\n\n42"
+
+	tests := []struct {
+		name              string
+		mode              Mode
+		text              string
+		expectedContent   string
+		expectedReasoning *string
+	}{
+		{name: "off", mode: Off, text: text, expectedContent: text},
+		{name: "remove", mode: Remove, text: text, expectedContent: "42"},
+		{name: "collapse", mode: Collapse, text: text, expectedContent: "<think></think>42"},
+		{
+			name:              "relocate",
+			mode:              Relocate,
+			text:              text,
+			expectedContent:   "42",
+			expectedReasoning: new("The user wants 6 times 7."),
+		},
+		{
+			name:              "unterminated block",
+			mode:              Relocate,
+			text:              "<think>Still thinking",
+			expectedContent:   "",
+			expectedReasoning: new("Still thinking"),
+		},
+		{name: "no block", mode: Remove, text: "a <b> tag", expectedContent: "a <b> tag"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := types.CreateChatCompletionResponse{Choices: []types.ChatCompletionChoice{{Message: types.NewTextMessage(t, types.Assistant, tt.text)}}}
+			Apply(tt.mode, &resp)
+			content, err := resp.Choices[0].Message.Content.AsMessageContent0()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedContent, content)
+			assert.Equal(t, tt.expectedReasoning, resp.Choices[0].Message.ReasoningContent)
+		})
+	}
+}
+
+// chunkLine returns the SSE line of a chunk with content
+func chunkLine(content string, finished bool) []byte {
+	finish := "null"
+	if finished {
+		finish = `"stop"`
+	}
+	encoded, _ := json.Marshal(content)
+	return []byte(`data: {"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":` + string(encoded) + `},"finish_reason":` + finish + `}]}` + "\n\n")
+}
+
+// replay streams pieces through a Stream, returning the content and
+// reasoning_content received
+func replay(t *testing.T, mode Mode, pieces []string) (content, reasoning string) {
+	t.Helper()
+	s := NewStream(mode)
+	var c, r strings.Builder
+	for i, piece := range pieces {
+		line := s.Line(chunkLine(piece, i == len(pieces)-1))
+		require.True(t, strings.HasSuffix(string(line), "\n\n"))
+		var chunk types.CreateChatCompletionStreamResponse
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(strings.TrimSpace(string(line)), "data: ")), &chunk))
+		delta := chunk.Choices[0].Delta
+		c.WriteString(delta.Content)
+		if delta.ReasoningContent != nil {
+			r.WriteString(*delta.ReasoningContent)
+		}
+	}
+	return c.String(), r.String()
+}
+
+func TestStream(t *testing.T) {
+	pieces := []string{"<th", "ink>\nLet me", " think", "</thi", "nk>\n\nThe answer", " is 42 <", "3"}
+
+	content, reasoning := replay(t, Relocate, pieces)
+	assert.Equal(t, "The answer is 42 <3", content)
+	assert.Equal(t, "Let me think", reasoning)
+
+	content, reasoning = replay(t, Remove, pieces)
+	assert.Equal(t, "The answer is 42 <3", content)
+	assert.Empty(t, reasoning)
+
+	content, _ = replay(t, Collapse, pieces)
+	assert.Equal(t, "<think></think>The answer is 42 <3", content)
+}
+
+func TestStreamPassesOtherLinesThrough(t *testing.T) {
+	s := NewStream(Remove)
+	for _, line := range []string{
+		"data: [DONE]\n\n",
+		": keep-alive\n\n",
+		`data: {"id":"1","choices":[{"index":0,"delta":{"content":"Hello"},"finish_reason":null}]}` + "\n\n",
+	} {
+		assert.Equal(t, line, string(s.Line([]byte(line))))
+	}
+	assert.Nil(t, NewStream(Off))
+}
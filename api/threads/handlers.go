@@ -0,0 +1,242 @@
+package threads
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+)
+
+const (
+	// defaultLimit and maxLimit bound the page size of the list endpoints
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+// List is the body of the list endpoints, a page of messages or runs
+type List[T any] struct {
+	Object  string  `json:"object"`
+	Data    []T     `json:"data"`
+	FirstID *string `json:"first_id"`
+	LastID  *string `json:"last_id"`
+	HasMore bool    `json:"has_more"`
+}
+
+// Deleted is the body of DELETE /v1/threads/:thread_id
+type Deleted struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Deleted bool   `json:"deleted"`
+}
+
+// CreateThreadHandler implements POST /v1/threads
+func (m *Manager) CreateThreadHandler(c *gin.Context) {
+	var req ThreadRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to decode request")
+		return
+	}
+	t, err := m.CreateThread(req, owner(c))
+	if m.writeError(c, err, "Thread not found") {
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+// GetThreadHandler implements GET /v1/threads/:thread_id
+func (m *Manager) GetThreadHandler(c *gin.Context) {
+	t, ok := m.GetThread(c.Param("thread_id"), owner(c))
+	if !ok {
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, "Thread not found")
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+// DeleteThreadHandler implements DELETE /v1/threads/:thread_id
+func (m *Manager) DeleteThreadHandler(c *gin.Context) {
+	id := c.Param("thread_id")
+	deleted, err := m.DeleteThread(id, owner(c))
+	if err != nil {
+		m.logger.Error("failed to delete thread", err, "thread", id)
+		errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to delete thread")
+		return
+	}
+	if !deleted {
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, "Thread not found")
+		return
+	}
+	c.JSON(http.StatusOK, Deleted{ID: id, Object: "thread.deleted", Deleted: true})
+}
+
+// CreateMessageHandler implements POST /v1/threads/:thread_id/messages
+func (m *Manager) CreateMessageHandler(c *gin.Context) {
+	var req MessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to decode request")
+		return
+	}
+	msg, err := m.AddMessage(c.Param("thread_id"), owner(c), req)
+	if m.writeError(c, err, "Thread not found") {
+		return
+	}
+	c.JSON(http.StatusOK, msg)
+}
+
+// ListMessagesHandler implements GET /v1/threads/:thread_id/messages
+func (m *Manager) ListMessagesHandler(c *gin.Context) {
+	messages, ok := m.ListMessages(c.Param("thread_id"), owner(c))
+	if !ok {
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, "Thread not found")
+		return
+	}
+	writePage(c, messages, func(msg Message) string { return msg.ID })
+}
+
+// CreateRunHandler implements POST /v1/threads/:thread_id/runs. With
+// "stream": true the run's events are sent as server-sent events until it
+// is over; a client disconnecting does not cancel the run.
+func (m *Manager) CreateRunHandler(c *gin.Context) {
+	var req RunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to decode request")
+		return
+	}
+	run, events, err := m.CreateRun(c.Param("thread_id"), owner(c), req, c.Request.Header, c.Request.URL.RawQuery)
+	if m.writeError(c, err, "Thread not found") {
+		return
+	}
+	m.logger.Info("thread run created", "thread", run.ThreadID, "run", run.ID, "model", run.Model)
+	if events == nil {
+		c.JSON(http.StatusOK, run)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	// Events are as far apart as the model is slow
+	middlewares.ResetWriteDeadline(c, 0)
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				_, _ = io.WriteString(w, "event: done\ndata: [DONE]\n\n")
+				return false
+			}
+			data, err := json.Marshal(event.Data)
+			if err != nil {
+				m.logger.Error("failed to encode thread run event", err, "event", event.Name)
+				return true
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Name, data); err != nil {
+				return false
+			}
+			c.Writer.Flush()
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// ListRunsHandler implements GET /v1/threads/:thread_id/runs
+func (m *Manager) ListRunsHandler(c *gin.Context) {
+	runs, ok := m.ListRuns(c.Param("thread_id"), owner(c))
+	if !ok {
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, "Thread not found")
+		return
+	}
+	writePage(c, runs, func(run Run) string { return run.ID })
+}
+
+// GetRunHandler implements GET /v1/threads/:thread_id/runs/:run_id, polled
+// by clients until the run is over
+func (m *Manager) GetRunHandler(c *gin.Context) {
+	run, ok := m.GetRun(c.Param("thread_id"), c.Param("run_id"), owner(c))
+	if !ok {
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, "Run not found")
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
+
+// CancelRunHandler implements POST /v1/threads/:thread_id/runs/:run_id/cancel
+func (m *Manager) CancelRunHandler(c *gin.Context) {
+	run, ok := m.CancelRun(c.Param("thread_id"), c.Param("run_id"), owner(c))
+	if !ok {
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, "Run not found")
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
+
+// owner identifies the caller of c, see tenants.Owner
+func owner(c *gin.Context) string {
+	return tenants.Owner(c.Request.Context(), c.Request.Header)
+}
+
+// writeError writes the response of err, if any, reporting whether it did
+func (m *Manager) writeError(c *gin.Context, err error, notFound string) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, errNotFound):
+		errcodes.JSON(c, http.StatusNotFound, errcodes.ResourceNotFound, notFound)
+	case errors.Is(err, errInvalid):
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, err.Error())
+	case errors.Is(err, errQueueFull):
+		errcodes.JSON(c, http.StatusServiceUnavailable, errcodes.InternalError, "Too many runs are waiting; retry later")
+	default:
+		m.logger.Error("failed to update thread", err)
+		errcodes.JSON(c, http.StatusInternalServerError, errcodes.InternalError, "Failed to update thread")
+	}
+	return true
+}
+
+// writePage writes the page of items, which are oldest first, selected by
+// the order (asc, or desc by default), after and limit query parameters
+func writePage[T any](c *gin.Context, items []T, id func(T) string) {
+	limit := defaultLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxLimit {
+			errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, fmt.Sprintf("limit must be between 1 and %d", maxLimit))
+			return
+		}
+		limit = n
+	}
+	switch c.DefaultQuery("order", "desc") {
+	case "asc":
+	case "desc":
+		slices.Reverse(items)
+	default:
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "order must be asc or desc")
+		return
+	}
+	if after := c.Query("after"); after != "" {
+		i := slices.IndexFunc(items, func(item T) bool { return id(item) == after })
+		if i < 0 {
+			errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "after must be the ID of an item of the list")
+			return
+		}
+		items = items[i+1:]
+	}
+
+	page := List[T]{Object: "list", Data: items[:min(limit, len(items))], HasMore: len(items) > limit}
+	if len(page.Data) > 0 {
+		first, last := id(page.Data[0]), id(page.Data[len(page.Data)-1])
+		page.FirstID, page.LastID = &first, &last
+	}
+	c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,728 @@
+// Package threads serves a minimal OpenAI Assistants-style API: threads hold
+// a conversation and runs answer it in the background through the gateway's
+// own chat completions handler, so the MCP agent loop runs server-side.
+// Assistants are not stored; a run names its model and instructions itself.
+package threads
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	dispatch "github.com/inference-gateway/inference-gateway/api/dispatch"
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	records "github.com/inference-gateway/inference-gateway/api/records"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// Run statuses of the Assistants API
+const (
+	StatusQueued     = "queued"
+	StatusInProgress = "in_progress"
+	StatusCancelling = "cancelling"
+	StatusCancelled  = "cancelled"
+	StatusFailed     = "failed"
+	StatusCompleted  = "completed"
+)
+
+// Message roles a thread accepts
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+const (
+	// queueSize bounds the number of runs waiting for a worker
+	queueSize = 1024
+	// eventBuffer holds every event of a streamed run, so emitting never
+	// blocks on a slow client
+	eventBuffer = 16
+)
+
+var (
+	// errNotFound is returned for unknown threads and runs
+	errNotFound = errors.New("not found")
+	// errInvalid is returned for requests the Assistants API rejects
+	errInvalid = errors.New("invalid request")
+	// errQueueFull is returned when too many runs are waiting for a worker
+	errQueueFull = errors.New("run queue is full")
+)
+
+// Thread is a conversation in the shape of the Assistants API thread object
+type Thread struct {
+	ID        string            `json:"id"`
+	Object    string            `json:"object"`
+	CreatedAt int64             `json:"created_at"`
+	Metadata  map[string]string `json:"metadata"`
+}
+
+// Text is the text of a message content part
+type Text struct {
+	Value       string `json:"value"`
+	Annotations []any  `json:"annotations"`
+}
+
+// ContentPart is a part of a message's content; only text is supported
+type ContentPart struct {
+	Type string `json:"type"`
+	Text Text   `json:"text"`
+}
+
+// Message is a message of a thread
+type Message struct {
+	ID        string            `json:"id"`
+	Object    string            `json:"object"`
+	CreatedAt int64             `json:"created_at"`
+	ThreadID  string            `json:"thread_id"`
+	Status    string            `json:"status"`
+	Role      string            `json:"role"`
+	Content   []ContentPart     `json:"content"`
+	RunID     *string           `json:"run_id"`
+	Metadata  map[string]string `json:"metadata"`
+}
+
+// RunError describes why a run failed
+type RunError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Usage counts the tokens a run used
+type Usage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+}
+
+// Run is the execution of a thread in the shape of the Assistants API run
+// object
+type Run struct {
+	ID           string            `json:"id"`
+	Object       string            `json:"object"`
+	CreatedAt    int64             `json:"created_at"`
+	ThreadID     string            `json:"thread_id"`
+	AssistantID  string            `json:"assistant_id,omitempty"`
+	Model        string            `json:"model"`
+	Instructions string            `json:"instructions"`
+	Status       string            `json:"status"`
+	StartedAt    *int64            `json:"started_at"`
+	CancelledAt  *int64            `json:"cancelled_at"`
+	FailedAt     *int64            `json:"failed_at"`
+	CompletedAt  *int64            `json:"completed_at"`
+	LastError    *RunError         `json:"last_error"`
+	Usage        *Usage            `json:"usage"`
+	Temperature  *float64          `json:"temperature,omitempty"`
+	TopP         *float64          `json:"top_p,omitempty"`
+	Metadata     map[string]string `json:"metadata"`
+}
+
+// MessageRequest is the body of POST /v1/threads/:thread_id/messages. Content
+// is a string or a list of text parts.
+type MessageRequest struct {
+	Role     string            `json:"role"`
+	Content  json.RawMessage   `json:"content"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// ThreadRequest is the body of POST /v1/threads
+type ThreadRequest struct {
+	Messages []MessageRequest  `json:"messages,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// RunRequest is the body of POST /v1/threads/:thread_id/runs. The gateway
+// stores no assistants, so Model is required; AssistantID is only echoed.
+type RunRequest struct {
+	AssistantID            string            `json:"assistant_id,omitempty"`
+	Model                  string            `json:"model"`
+	Instructions           string            `json:"instructions,omitempty"`
+	AdditionalInstructions string            `json:"additional_instructions,omitempty"`
+	AdditionalMessages     []MessageRequest  `json:"additional_messages,omitempty"`
+	Temperature            *float64          `json:"temperature,omitempty"`
+	TopP                   *float64          `json:"top_p,omitempty"`
+	Metadata               map[string]string `json:"metadata,omitempty"`
+	Stream                 bool              `json:"stream,omitempty"`
+}
+
+// Event is a server-sent event of a streamed run, named like the Assistants
+// API events (thread.run.created, thread.message.completed, ...)
+type Event struct {
+	Name string
+	Data any
+}
+
+// record is the persisted state of a thread. Owner identifies the caller
+// that created it, see tenants.Owner.
+type record struct {
+	Thread
+	Owner    string    `json:"owner"`
+	Messages []Message `json:"messages"`
+	Runs     []Run     `json:"runs"`
+}
+
+// thread is a record with the in-memory state of its active run
+type thread struct {
+	record
+	active *activeRun
+}
+
+// activeRun is the in-memory state of a queued or running run. The caller's
+// headers are never persisted, so runs do not survive a restart.
+type activeRun struct {
+	id     string
+	header http.Header
+	query  string
+	cancel context.CancelFunc
+	// events is nil unless the run is streamed
+	events chan Event
+}
+
+// runRef names a queued run
+type runRef struct {
+	threadID string
+	runID    string
+}
+
+// Manager keeps threads in memory, persisted in a directory, and runs them
+// in a background worker pool. A thread has at most one active run.
+type Manager struct {
+	dispatcher dispatch.Dispatcher
+	records    *records.Store[record]
+	logger     logger.Logger
+	workers    int
+	now        func() time.Time
+	queue      chan runRef
+
+	mu      sync.Mutex
+	threads map[string]*thread
+}
+
+// NewManager creates a manager keeping threads in dir. Runs left active by a
+// previous run of the gateway are failed, because the credentials they were
+// created with were not persisted.
+func NewManager(dispatcher dispatch.Dispatcher, dir string, workers int, logger logger.Logger) (*Manager, error) {
+	if dispatcher == nil {
+		return nil, errors.New("threads dispatcher is required")
+	}
+	if workers < 1 {
+		return nil, fmt.Errorf("THREADS_WORKERS must be positive, got %d", workers)
+	}
+	recs, err := records.New[record](dir, "thread", logger)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manager{
+		dispatcher: dispatcher,
+		records:    recs,
+		logger:     logger,
+		workers:    workers,
+		now:        time.Now,
+		queue:      make(chan runRef, queueSize),
+		threads:    make(map[string]*thread),
+	}
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// load reads the persisted threads
+func (m *Manager) load() error {
+	return m.records.Load(func(rec record) {
+		t := &thread{record: rec}
+		m.threads[rec.ID] = t
+
+		for i := range t.Runs {
+			switch t.Runs[i].Status {
+			case StatusQueued, StatusInProgress, StatusCancelling:
+				t.Runs[i].Status = StatusFailed
+				t.Runs[i].FailedAt = m.timestamp()
+				t.Runs[i].LastError = &RunError{Code: "server_error", Message: "The gateway restarted while the run was active. Create the run again."}
+				m.records.WriteLogged(t.ID, t.record)
+			}
+		}
+	})
+}
+
+// Start runs the workers until ctx is done. Runs in flight when ctx ends are
+// left active and failed on the next start.
+func (m *Manager) Start(ctx context.Context) {
+	for range m.workers {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case ref := <-m.queue:
+					m.process(ctx, ref)
+				}
+			}
+		}()
+	}
+}
+
+// CreateThread creates a thread of owner holding the messages of req
+func (m *Manager) CreateThread(req ThreadRequest, owner string) (Thread, error) {
+	t := &thread{record: record{
+		Thread: Thread{
+			ID:        newID("thread_"),
+			Object:    "thread",
+			CreatedAt: m.now().Unix(),
+			Metadata:  metadata(req.Metadata),
+		},
+		Owner:    owner,
+		Messages: []Message{},
+		Runs:     []Run{},
+	}}
+	for _, msgReq := range req.Messages {
+		msg, err := m.newMessage(t.ID, msgReq)
+		if err != nil {
+			return Thread{}, err
+		}
+		t.Messages = append(t.Messages, msg)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.records.Write(t.ID, t.record); err != nil {
+		return Thread{}, err
+	}
+	m.threads[t.ID] = t
+	return t.Thread, nil
+}
+
+// GetThread returns thread id. Here as in the other methods, the threads of
+// other owners are not found.
+func (m *Manager) GetThread(id, owner string) (Thread, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.owned(id, owner)
+	if !ok {
+		return Thread{}, false
+	}
+	return t.Thread, true
+}
+
+// DeleteThread deletes thread id, cancelling its active run
+func (m *Manager) DeleteThread(id, owner string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.owned(id, owner)
+	if !ok {
+		return false, nil
+	}
+	if err := m.records.Remove(id); err != nil {
+		return false, err
+	}
+	if t.active != nil {
+		if t.active.cancel != nil {
+			t.active.cancel()
+		}
+		if t.active.events != nil {
+			close(t.active.events)
+		}
+		t.active = nil
+	}
+	delete(m.threads, id)
+	return true, nil
+}
+
+// AddMessage appends a message to thread threadID, which must have no
+// active run
+func (m *Manager) AddMessage(threadID, owner string, req MessageRequest) (Message, error) {
+	msg, err := m.newMessage(threadID, req)
+	if err != nil {
+		return Message{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.owned(threadID, owner)
+	if !ok {
+		return Message{}, errNotFound
+	}
+	if t.active != nil {
+		return Message{}, fmt.Errorf("%w: thread %s has an active run %s", errInvalid, threadID, t.active.id)
+	}
+	t.Messages = append(t.Messages, msg)
+	if err := m.records.Write(t.ID, t.record); err != nil {
+		t.Messages = t.Messages[:len(t.Messages)-1]
+		return Message{}, err
+	}
+	return msg, nil
+}
+
+// ListMessages returns the messages of thread threadID, oldest first
+func (m *Manager) ListMessages(threadID, owner string) ([]Message, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.owned(threadID, owner)
+	if !ok {
+		return nil, false
+	}
+	return slices.Clone(t.Messages), true
+}
+
+// CreateRun queues a run answering thread threadID with the caller's header
+// and query. For a streamed run, the returned channel gets its events and is
+// closed once the run is over.
+func (m *Manager) CreateRun(threadID, owner string, req RunRequest, header http.Header, query string) (Run, <-chan Event, error) {
+	if req.Model == "" {
+		return Run{}, nil, fmt.Errorf("%w: model is required, the gateway stores no assistants", errInvalid)
+	}
+	var additional []Message
+	for _, msgReq := range req.AdditionalMessages {
+		msg, err := m.newMessage(threadID, msgReq)
+		if err != nil {
+			return Run{}, nil, err
+		}
+		additional = append(additional, msg)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.owned(threadID, owner)
+	if !ok {
+		return Run{}, nil, errNotFound
+	}
+	if t.active != nil {
+		return Run{}, nil, fmt.Errorf("%w: thread %s already has an active run %s", errInvalid, threadID, t.active.id)
+	}
+
+	run := Run{
+		ID:           newID("run_"),
+		Object:       "thread.run",
+		CreatedAt:    m.now().Unix(),
+		ThreadID:     threadID,
+		AssistantID:  req.AssistantID,
+		Model:        req.Model,
+		Instructions: strings.TrimSpace(req.Instructions + "\n\n" + req.AdditionalInstructions),
+		Status:       StatusQueued,
+		Temperature:  req.Temperature,
+		TopP:         req.TopP,
+		Metadata:     metadata(req.Metadata),
+	}
+	active := &activeRun{id: run.ID, header: header.Clone(), query: query}
+	if req.Stream {
+		active.events = make(chan Event, eventBuffer)
+	}
+
+	messages := len(t.Messages)
+	t.Messages = append(t.Messages, additional...)
+	t.Runs = append(t.Runs, run)
+	if err := m.records.Write(t.ID, t.record); err != nil {
+		t.Messages = t.Messages[:messages]
+		t.Runs = t.Runs[:len(t.Runs)-1]
+		return Run{}, nil, err
+	}
+	select {
+	case m.queue <- runRef{threadID: threadID, runID: run.ID}:
+	default:
+		t.Runs[len(t.Runs)-1].Status = StatusFailed
+		t.Runs[len(t.Runs)-1].FailedAt = m.timestamp()
+		t.Runs[len(t.Runs)-1].LastError = &RunError{Code: "rate_limit_exceeded", Message: "Too many runs are waiting."}
+		m.records.WriteLogged(t.ID, t.record)
+		return Run{}, nil, errQueueFull
+	}
+	t.active = active
+	m.emit(t, "thread.run.created", run)
+	m.emit(t, "thread.run.queued", run)
+	return run, active.events, nil
+}
+
+// GetRun returns run runID of thread threadID
+func (m *Manager) GetRun(threadID, runID, owner string) (Run, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.owned(threadID, owner)
+	if !ok {
+		return Run{}, false
+	}
+	run := t.run(runID)
+	if run == nil {
+		return Run{}, false
+	}
+	return *run, true
+}
+
+// ListRuns returns the runs of thread threadID, oldest first
+func (m *Manager) ListRuns(threadID, owner string) ([]Run, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.owned(threadID, owner)
+	if !ok {
+		return nil, false
+	}
+	return slices.Clone(t.Runs), true
+}
+
+// CancelRun cancels run runID of thread threadID if it is still active
+func (m *Manager) CancelRun(threadID, runID, owner string) (Run, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.owned(threadID, owner)
+	if !ok {
+		return Run{}, false
+	}
+	run := t.run(runID)
+	if run == nil {
+		return Run{}, false
+	}
+	if t.active != nil && t.active.id == runID && (run.Status == StatusQueued || run.Status == StatusInProgress) {
+		run.Status = StatusCancelling
+		if t.active.cancel != nil {
+			t.active.cancel()
+		}
+		m.records.WriteLogged(t.ID, t.record)
+		m.emit(t, "thread.run.cancelling", *run)
+	}
+	return *run, true
+}
+
+// process executes a queued run: the thread is sent as a chat completion
+// request and the answer appended to it as an assistant message
+func (m *Manager) process(ctx context.Context, ref runRef) {
+	m.mu.Lock()
+	t, ok := m.threads[ref.threadID]
+	if !ok || t.active == nil || t.active.id != ref.runID {
+		m.mu.Unlock()
+		return
+	}
+	run := t.run(ref.runID)
+	if run.Status == StatusCancelling {
+		m.finish(t, run, StatusCancelled, nil)
+		m.mu.Unlock()
+		return
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	active := t.active
+	active.cancel = cancel
+	run.Status = StatusInProgress
+	run.StartedAt = m.timestamp()
+	m.records.WriteLogged(t.ID, t.record)
+	m.emit(t, "thread.run.in_progress", *run)
+	body, err := chatRequest(*run, t.Messages)
+	model := run.Model
+	m.mu.Unlock()
+
+	m.logger.Debug("running thread", "thread", ref.threadID, "run", ref.runID, "model", model)
+	var status int
+	var resp []byte
+	if err == nil {
+		status, resp = m.dispatcher.Dispatch(runCtx, active.header, active.query, body)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if ctx.Err() != nil {
+		return
+	}
+	t, ok = m.threads[ref.threadID]
+	if !ok || t.active != active {
+		return
+	}
+	run = t.run(ref.runID)
+	switch {
+	case runCtx.Err() != nil:
+		m.finish(t, run, StatusCancelled, nil)
+	case err != nil:
+		m.logger.Error("failed to build thread run request", err, "thread", ref.threadID, "run", ref.runID)
+		m.finish(t, run, StatusFailed, &RunError{Code: "server_error", Message: "The run request could not be built."})
+	case status >= http.StatusBadRequest:
+		m.finish(t, run, StatusFailed, runError(status, resp))
+	default:
+		var completion types.CreateChatCompletionResponse
+		if err := json.Unmarshal(resp, &completion); err != nil || len(completion.Choices) == 0 {
+			m.finish(t, run, StatusFailed, &RunError{Code: "server_error", Message: "The model returned an invalid response."})
+			return
+		}
+		if usage := completion.Usage; usage != nil {
+			run.Usage = &Usage{PromptTokens: usage.PromptTokens, CompletionTokens: usage.CompletionTokens, TotalTokens: usage.TotalTokens}
+		}
+		msg := Message{
+			ID:        newID("msg_"),
+			Object:    "thread.message",
+			CreatedAt: m.now().Unix(),
+			ThreadID:  t.ID,
+			Status:    "completed",
+			Role:      RoleAssistant,
+			Content:   textContent(completion.Choices[0].Message.TextContent()),
+			RunID:     &run.ID,
+			Metadata:  map[string]string{},
+		}
+		t.Messages = append(t.Messages, msg)
+		m.emit(t, "thread.message.created", msg)
+		m.emit(t, "thread.message.completed", msg)
+		m.finish(t, run, StatusCompleted, nil)
+	}
+}
+
+// finish moves the active run of t to status and releases the thread. The
+// caller must hold m.mu.
+func (m *Manager) finish(t *thread, run *Run, status string, runErr *RunError) {
+	run.Status = status
+	run.LastError = runErr
+	switch status {
+	case StatusCompleted:
+		run.CompletedAt = m.timestamp()
+	case StatusCancelled:
+		run.CancelledAt = m.timestamp()
+	case StatusFailed:
+		run.FailedAt = m.timestamp()
+	}
+	m.records.WriteLogged(t.ID, t.record)
+	m.emit(t, "thread.run."+status, *run)
+	if t.active.events != nil {
+		close(t.active.events)
+	}
+	t.active = nil
+	m.logger.Info("thread run finished", "thread", t.ID, "run", run.ID, "status", status)
+}
+
+// emit sends an event to the client streaming the active run of t. The
+// caller must hold m.mu.
+func (m *Manager) emit(t *thread, name string, data any) {
+	if t.active == nil || t.active.events == nil {
+		return
+	}
+	select {
+	case t.active.events <- Event{Name: name, Data: data}:
+	default:
+		m.logger.Warn("dropped thread run event", "thread", t.ID, "run", t.active.id, "event", name)
+	}
+}
+
+// run returns run id of t, nil when there is none
+// owned returns thread id unless it belongs to another owner. The caller must
+// hold m.mu.
+func (m *Manager) owned(id, owner string) (*thread, bool) {
+	t, ok := m.threads[id]
+	if !ok || t.Owner != owner {
+		return nil, false
+	}
+	return t, true
+}
+
+func (t *thread) run(id string) *Run {
+	for i := range t.Runs {
+		if t.Runs[i].ID == id {
+			return &t.Runs[i]
+		}
+	}
+	return nil
+}
+
+// newMessage validates req as a message of thread threadID
+func (m *Manager) newMessage(threadID string, req MessageRequest) (Message, error) {
+	if req.Role != RoleUser && req.Role != RoleAssistant {
+		return Message{}, fmt.Errorf("%w: role must be %s or %s", errInvalid, RoleUser, RoleAssistant)
+	}
+	text, err := parseContent(req.Content)
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{
+		ID:        newID("msg_"),
+		Object:    "thread.message",
+		CreatedAt: m.now().Unix(),
+		ThreadID:  threadID,
+		Status:    "completed",
+		Role:      req.Role,
+		Content:   textContent(text),
+		Metadata:  metadata(req.Metadata),
+	}, nil
+}
+
+// parseContent returns the text of message content, a string or a list of
+// text parts
+func parseContent(content json.RawMessage) (string, error) {
+	var text string
+	if err := json.Unmarshal(content, &text); err == nil {
+		return text, nil
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(content, &parts); err != nil {
+		return "", fmt.Errorf("%w: content must be a string or a list of content parts", errInvalid)
+	}
+	texts := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part.Type != "text" {
+			return "", fmt.Errorf("%w: content part type %q is not supported, only text is", errInvalid, part.Type)
+		}
+		texts = append(texts, part.Text)
+	}
+	return strings.Join(texts, "\n"), nil
+}
+
+// chatRequest builds the chat completion request answering messages for run
+func chatRequest(run Run, messages []Message) ([]byte, error) {
+	type chatMessage struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+	req := struct {
+		Model       string        `json:"model"`
+		Messages    []chatMessage `json:"messages"`
+		Temperature *float64      `json:"temperature,omitempty"`
+		TopP        *float64      `json:"top_p,omitempty"`
+	}{Model: run.Model, Temperature: run.Temperature, TopP: run.TopP}
+	if run.Instructions != "" {
+		req.Messages = append(req.Messages, chatMessage{Role: string(types.System), Content: run.Instructions})
+	}
+	for _, msg := range messages {
+		texts := make([]string, 0, len(msg.Content))
+		for _, part := range msg.Content {
+			texts = append(texts, part.Text.Value)
+		}
+		req.Messages = append(req.Messages, chatMessage{Role: msg.Role, Content: strings.Join(texts, "\n")})
+	}
+	return json.Marshal(req)
+}
+
+// runError describes the error response a run got
+func runError(status int, body []byte) *RunError {
+	var resp errcodes.Response
+	_ = json.Unmarshal(body, &resp)
+	code := "server_error"
+	if status == http.StatusTooManyRequests {
+		code = "rate_limit_exceeded"
+	}
+	return &RunError{Code: code, Message: cmp.Or(resp.Error, fmt.Sprintf("The chat completion request failed with status %d.", status))}
+}
+
+func textContent(text string) []ContentPart {
+	return []ContentPart{{Type: "text", Text: Text{Value: text, Annotations: []any{}}}}
+}
+
+func metadata(m map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return m
+}
+
+func newID(prefix string) string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return prefix + hex.EncodeToString(b)
+}
+
+func (m *Manager) timestamp() *int64 {
+	now := m.now().Unix()
+	return &now
+}
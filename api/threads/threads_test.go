@@ -0,0 +1,307 @@
+package threads
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+// dispatcherFunc adapts a function to Dispatcher
+type dispatcherFunc func(ctx context.Context, header http.Header, query string, body []byte) (int, []byte)
+
+func (f dispatcherFunc) Dispatch(ctx context.Context, header http.Header, query string, body []byte) (int, []byte) {
+	return f(ctx, header, query, body)
+}
+
+// chatRequests records the chat completion requests of the runs
+type chatRequests struct {
+	bodies  chan string
+	headers chan http.Header
+}
+
+// newTestManager returns a started manager whose chat completions answer
+// "Hello <last message>", fail for the model "limited" and block for "slow"
+// until the run is cancelled
+func newTestManager(t *testing.T, dir string) (*Manager, *gin.Engine, *chatRequests) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	requests := &chatRequests{bodies: make(chan string, 10), headers: make(chan http.Header, 10)}
+	dispatcher := dispatcherFunc(func(ctx context.Context, header http.Header, query string, body []byte) (int, []byte) {
+		requests.bodies <- string(body)
+		requests.headers <- header
+		var req struct {
+			Model    string `json:"model"`
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.Unmarshal(body, &req))
+		switch req.Model {
+		case "limited":
+			resp, _ := json.Marshal(errcodes.UpstreamRateLimited.Response("Rate limited by the provider"))
+			return http.StatusTooManyRequests, resp
+		case "slow":
+			<-ctx.Done()
+			return http.StatusInternalServerError, nil
+		}
+		resp, _ := json.Marshal(map[string]any{
+			"id":      "chatcmpl-1",
+			"object":  "chat.completion",
+			"model":   req.Model,
+			"choices": []map[string]any{{"index": 0, "finish_reason": "stop", "message": map[string]any{"role": "assistant", "content": "Hello " + req.Messages[len(req.Messages)-1].Content}}},
+			"usage":   map[string]any{"prompt_tokens": 10, "completion_tokens": 2, "total_tokens": 12},
+		})
+		return http.StatusOK, resp
+	})
+
+	m, err := NewManager(dispatcher, dir, 2, logger.NewNoopLogger())
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	m.Start(ctx)
+
+	r := gin.New()
+	r.POST("/v1/threads", m.CreateThreadHandler)
+	r.GET("/v1/threads/:thread_id", m.GetThreadHandler)
+	r.DELETE("/v1/threads/:thread_id", m.DeleteThreadHandler)
+	r.POST("/v1/threads/:thread_id/messages", m.CreateMessageHandler)
+	r.GET("/v1/threads/:thread_id/messages", m.ListMessagesHandler)
+	r.POST("/v1/threads/:thread_id/runs", m.CreateRunHandler)
+	r.GET("/v1/threads/:thread_id/runs", m.ListRunsHandler)
+	r.GET("/v1/threads/:thread_id/runs/:run_id", m.GetRunHandler)
+	r.POST("/v1/threads/:thread_id/runs/:run_id/cancel", m.CancelRunHandler)
+	return m, r, requests
+}
+
+// user is the owner of the threads created by do
+var user = tenants.Owner(context.Background(), http.Header{"Authorization": {"Bearer user-token"}})
+
+func do(t *testing.T, r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	return doAs(t, r, "Bearer user-token", method, path, body)
+}
+
+// doAs serves a request carrying the Authorization header authorization
+func doAs(t *testing.T, r *gin.Engine, authorization, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", authorization)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decode[T any](t *testing.T, w *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &v), w.Body.String())
+	return v
+}
+
+func createThread(t *testing.T, r *gin.Engine) Thread {
+	t.Helper()
+	w := do(t, r, http.MethodPost, "/v1/threads", `{"messages":[{"role":"user","content":"world"}],"metadata":{"user":"42"}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	return decode[Thread](t, w)
+}
+
+func waitForRun(t *testing.T, m *Manager, threadID, runID, status string) Run {
+	t.Helper()
+	var run Run
+	require.Eventually(t, func() bool {
+		run, _ = m.GetRun(threadID, runID, user)
+		return run.Status == status
+	}, 5*time.Second, 10*time.Millisecond)
+	return run
+}
+
+func TestRun(t *testing.T) {
+	m, r, requests := newTestManager(t, t.TempDir())
+	thread := createThread(t, r)
+	assert.Equal(t, map[string]string{"user": "42"}, thread.Metadata)
+
+	w := do(t, r, http.MethodPost, "/v1/threads/"+thread.ID+"/runs", `{"assistant_id":"asst_1","model":"openai/gpt-4o","instructions":"Be brief."}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	run := decode[Run](t, w)
+	assert.Equal(t, StatusQueued, run.Status)
+	assert.Equal(t, "asst_1", run.AssistantID)
+
+	run = waitForRun(t, m, thread.ID, run.ID, StatusCompleted)
+	assert.Equal(t, &Usage{PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12}, run.Usage)
+	assert.NotNil(t, run.CompletedAt)
+	assert.JSONEq(t, `{"model":"openai/gpt-4o","messages":[{"role":"system","content":"Be brief."},{"role":"user","content":"world"}]}`, <-requests.bodies)
+	assert.Equal(t, "Bearer user-token", (<-requests.headers).Get("Authorization"))
+
+	w = do(t, r, http.MethodGet, "/v1/threads/"+thread.ID+"/messages?order=asc", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	messages := decode[List[Message]](t, w)
+	require.Len(t, messages.Data, 2)
+	assert.Equal(t, RoleAssistant, messages.Data[1].Role)
+	assert.Equal(t, "Hello world", messages.Data[1].Content[0].Text.Value)
+	assert.Equal(t, &run.ID, messages.Data[1].RunID)
+
+	w = do(t, r, http.MethodGet, "/v1/threads/"+thread.ID+"/messages?limit=1", "")
+	page := decode[List[Message]](t, w)
+	require.Len(t, page.Data, 1)
+	assert.Equal(t, messages.Data[1].ID, page.Data[0].ID, "newest first by default")
+	assert.True(t, page.HasMore)
+	w = do(t, r, http.MethodGet, "/v1/threads/"+thread.ID+"/messages?limit=1&after="+*page.LastID, "")
+	page = decode[List[Message]](t, w)
+	require.Len(t, page.Data, 1)
+	assert.Equal(t, messages.Data[0].ID, page.Data[0].ID)
+	assert.False(t, page.HasMore)
+
+	w = do(t, r, http.MethodDelete, "/v1/threads/"+thread.ID, "")
+	assert.JSONEq(t, `{"id":"`+thread.ID+`","object":"thread.deleted","deleted":true}`, w.Body.String())
+	assert.Equal(t, http.StatusNotFound, do(t, r, http.MethodGet, "/v1/threads/"+thread.ID, "").Code)
+}
+
+func TestRun_Stream(t *testing.T) {
+	_, r, _ := newTestManager(t, t.TempDir())
+	thread := createThread(t, r)
+
+	// Streaming needs a real connection, ResponseRecorder cannot CloseNotify
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/v1/threads/"+thread.ID+"/runs", strings.NewReader(`{"model":"openai/gpt-4o","stream":true}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer user-token")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	var names []string
+	for line := range strings.SplitSeq(string(body), "\n") {
+		if name, ok := strings.CutPrefix(line, "event: "); ok {
+			names = append(names, name)
+		}
+	}
+	assert.Equal(t, []string{
+		"thread.run.created",
+		"thread.run.queued",
+		"thread.run.in_progress",
+		"thread.message.created",
+		"thread.message.completed",
+		"thread.run.completed",
+		"done",
+	}, names)
+	assert.True(t, strings.HasSuffix(string(body), "event: done\ndata: [DONE]\n\n"))
+}
+
+func TestRun_Failures(t *testing.T) {
+	m, r, _ := newTestManager(t, t.TempDir())
+	thread := createThread(t, r)
+
+	w := do(t, r, http.MethodPost, "/v1/threads/"+thread.ID+"/runs", `{"model":"limited"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	run := waitForRun(t, m, thread.ID, decode[Run](t, w).ID, StatusFailed)
+	assert.Equal(t, &RunError{Code: "rate_limit_exceeded", Message: "Rate limited by the provider"}, run.LastError)
+
+	w = do(t, r, http.MethodPost, "/v1/threads/"+thread.ID+"/runs", `{"model":"slow"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	slow := decode[Run](t, w)
+	waitForRun(t, m, thread.ID, slow.ID, StatusInProgress)
+
+	w = do(t, r, http.MethodPost, "/v1/threads/"+thread.ID+"/messages", `{"role":"user","content":"more"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "no messages while a run is active")
+	w = do(t, r, http.MethodPost, "/v1/threads/"+thread.ID+"/runs", `{"model":"openai/gpt-4o"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "one active run per thread")
+
+	w = do(t, r, http.MethodPost, "/v1/threads/"+thread.ID+"/runs/"+slow.ID+"/cancel", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, StatusCancelling, decode[Run](t, w).Status)
+	waitForRun(t, m, thread.ID, slow.ID, StatusCancelled)
+
+	w = do(t, r, http.MethodGet, "/v1/threads/"+thread.ID+"/runs", "")
+	assert.Len(t, decode[List[Run]](t, w).Data, 2)
+}
+
+func TestOwner(t *testing.T) {
+	m, r, _ := newTestManager(t, t.TempDir())
+	thread := createThread(t, r)
+	w := do(t, r, http.MethodPost, "/v1/threads/"+thread.ID+"/runs", `{"model":"openai/gpt-4o"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	run := waitForRun(t, m, thread.ID, decode[Run](t, w).ID, StatusCompleted)
+
+	const other = "Bearer other-token"
+	for _, req := range []struct{ method, path, body string }{
+		{http.MethodGet, "/v1/threads/" + thread.ID, ""},
+		{http.MethodPost, "/v1/threads/" + thread.ID + "/messages", `{"role":"user","content":"hi"}`},
+		{http.MethodGet, "/v1/threads/" + thread.ID + "/messages", ""},
+		{http.MethodPost, "/v1/threads/" + thread.ID + "/runs", `{"model":"openai/gpt-4o"}`},
+		{http.MethodGet, "/v1/threads/" + thread.ID + "/runs", ""},
+		{http.MethodGet, "/v1/threads/" + thread.ID + "/runs/" + run.ID, ""},
+		{http.MethodPost, "/v1/threads/" + thread.ID + "/runs/" + run.ID + "/cancel", ""},
+		{http.MethodDelete, "/v1/threads/" + thread.ID, ""},
+	} {
+		assert.Equal(t, http.StatusNotFound, doAs(t, r, other, req.method, req.path, req.body).Code, "%s %s", req.method, req.path)
+	}
+
+	w = do(t, r, http.MethodGet, "/v1/threads/"+thread.ID+"/messages", "")
+	require.Equal(t, http.StatusOK, w.Code, "the thread was not deleted")
+	assert.Len(t, decode[List[Message]](t, w).Data, 2, "no message was added")
+}
+
+func TestRequestValidation(t *testing.T) {
+	_, r, _ := newTestManager(t, t.TempDir())
+	thread := createThread(t, r)
+
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{name: "run without model", path: "/runs", body: `{"assistant_id":"asst_1"}`},
+		{name: "unknown role", path: "/messages", body: `{"role":"system","content":"hi"}`},
+		{name: "image content", path: "/messages", body: `{"role":"user","content":[{"type":"image_url","image_url":{"url":"https://example.com/a.png"}}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(t, r, http.MethodPost, "/v1/threads/"+thread.ID+tt.path, tt.body)
+			assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+		})
+	}
+
+	w := do(t, r, http.MethodPost, "/v1/threads/"+thread.ID+"/messages", `{"role":"user","content":[{"type":"text","text":"a"},{"type":"text","text":"b"}]}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "a\nb", decode[Message](t, w).Content[0].Text.Value)
+	assert.Equal(t, http.StatusNotFound, do(t, r, http.MethodPost, "/v1/threads/thread_unknown/runs", `{"model":"openai/gpt-4o"}`).Code)
+}
+
+func TestNewManager_FailsActiveRuns(t *testing.T) {
+	dir := t.TempDir()
+	m, r, _ := newTestManager(t, dir)
+	thread := createThread(t, r)
+	w := do(t, r, http.MethodPost, "/v1/threads/"+thread.ID+"/runs", `{"model":"slow"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	run := waitForRun(t, m, thread.ID, decode[Run](t, w).ID, StatusInProgress)
+
+	restarted, err := NewManager(dispatcherFunc(nil), dir, 1, logger.NewNoopLogger())
+	require.NoError(t, err)
+	run, ok := restarted.GetRun(thread.ID, run.ID, user)
+	require.True(t, ok)
+	assert.Equal(t, StatusFailed, run.Status)
+	require.NotNil(t, run.LastError)
+	messages, _ := restarted.ListMessages(thread.ID, user)
+	assert.Len(t, messages, 1)
+}
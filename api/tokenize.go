@@ -0,0 +1,85 @@
+package api
+
+import (
+	"net/http"
+
+	gin "github.com/gin-gonic/gin"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	tokenizer "github.com/inference-gateway/inference-gateway/internal/tokenizer"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// TokenizeRequest is the request body of POST /v1/tokenize. Exactly one of
+// Input and Messages is set.
+type TokenizeRequest struct {
+	Model          string                      `json:"model"`
+	Input          *string                     `json:"input,omitempty"`
+	Messages       []types.Message             `json:"messages,omitempty"`
+	Tools          *[]types.ChatCompletionTool `json:"tools,omitempty"`
+	ReturnTokenIDs bool                        `json:"return_token_ids,omitempty"`
+}
+
+// TokenizeResponse is the response body of POST /v1/tokenize. Estimated is
+// set when the model has no exact tokenizer, in which case Count is an
+// approximation and no token IDs are available.
+type TokenizeResponse struct {
+	Object    string `json:"object"`
+	Model     string `json:"model"`
+	Tokenizer string `json:"tokenizer"`
+	Estimated bool   `json:"estimated"`
+	Count     int    `json:"count"`
+	TokenIDs  []int  `json:"token_ids,omitempty"`
+}
+
+// TokenizeHandler implements POST /v1/tokenize. It counts the tokens of a
+// text input, or of chat messages and tools including the chat format
+// framing, with the tokenizer used for the gateway's own usage accounting.
+func (router *RouterImpl) TokenizeHandler(c *gin.Context) {
+	var req TokenizeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		router.log(c).Error("failed to decode request", err)
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Failed to decode request")
+		return
+	}
+	if req.Model == "" {
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "model is required")
+		return
+	}
+	if (req.Input == nil) == (len(req.Messages) == 0) {
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Exactly one of input and messages is required")
+		return
+	}
+
+	t := router.tokenizers.ForModel(req.Model)
+	resp := TokenizeResponse{
+		Object:    "tokenize",
+		Model:     req.Model,
+		Tokenizer: t.Name(),
+		Estimated: t.Name() == tokenizer.Heuristic,
+	}
+
+	if req.Input == nil {
+		if req.ReturnTokenIDs {
+			errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Token IDs are only returned for input")
+			return
+		}
+		resp.Count = tokenizer.CountMessages(t, req.Messages, req.Tools)
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+
+	if !req.ReturnTokenIDs {
+		resp.Count = t.Count(*req.Input)
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+	ids, ok := t.Encode(*req.Input)
+	if !ok {
+		errcodes.JSON(c, http.StatusBadRequest, errcodes.InvalidRequest, "Token IDs are not available for model "+req.Model)
+		return
+	}
+	resp.Count = len(ids)
+	resp.TokenIDs = ids
+	c.JSON(http.StatusOK, resp)
+}
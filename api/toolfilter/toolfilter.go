@@ -0,0 +1,276 @@
+// Package toolfilter keeps the tool definitions attached to a chat completion
+// request small: when more tools are available than the request should carry,
+// only the ones most relevant to the last user message are kept, ranked by
+// keyword overlap or by embedding similarity.
+package toolfilter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	client "github.com/inference-gateway/inference-gateway/providers/client"
+	routing "github.com/inference-gateway/inference-gateway/providers/routing"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// Ranking methods, the values of MCP_TOOL_FILTER_METHOD
+const (
+	Keyword   = "keyword"
+	Embedding = "embedding"
+)
+
+// Options configures a Filter
+type Options struct {
+	// TopK is the maximum number of tools kept
+	TopK   int
+	Method string
+	// EmbeddingModel is the provider/model embedding prompts and tools with
+	// the embedding method
+	EmbeddingModel string
+}
+
+// Filter selects the tools relevant to a prompt
+type Filter struct {
+	topK     int
+	embedder routing.Embedder
+
+	mu sync.Mutex
+	// vectors caches the embedding of every tool text
+	vectors map[string][]float64
+}
+
+// New creates a Filter. With the embedding method and a nil embedder, tools
+// and prompts are embedded through the gateway proxy with httpClient.
+func New(opts Options, httpClient client.Client, embedder routing.Embedder) (*Filter, error) {
+	if opts.TopK < 1 {
+		return nil, fmt.Errorf("MCP_TOOL_FILTER_TOP_K must be at least 1, got %d", opts.TopK)
+	}
+	f := &Filter{topK: opts.TopK}
+	switch opts.Method {
+	case Keyword, "":
+	case Embedding:
+		if embedder == nil {
+			provider, model, ok := strings.Cut(opts.EmbeddingModel, "/")
+			if !ok || provider == "" || model == "" {
+				return nil, fmt.Errorf("MCP_TOOL_FILTER_EMBEDDING_MODEL must be in provider/model form, got %q", opts.EmbeddingModel)
+			}
+			embedder = routing.NewProxyEmbedder(httpClient, provider, model)
+		}
+		f.embedder = embedder
+		f.vectors = make(map[string][]float64)
+	default:
+		return nil, fmt.Errorf("invalid MCP_TOOL_FILTER_METHOD %q: expected keyword or embedding", opts.Method)
+	}
+	return f, nil
+}
+
+// Select returns at most TopK of tools, the most relevant to prompt, in their
+// original order. The tools named in keep, e.g. those already called in the
+// conversation, are always selected. err is set when the embeddings could
+// not be fetched: the tools are then ranked by keywords.
+func (f *Filter) Select(ctx context.Context, prompt string, keep []string, tools []types.ChatCompletionTool) ([]types.ChatCompletionTool, error) {
+	if len(tools) <= f.topK {
+		return tools, nil
+	}
+
+	var scores []float64
+	var err error
+	if f.embedder != nil && strings.TrimSpace(prompt) != "" {
+		scores, err = f.embeddingScores(ctx, prompt, tools)
+	}
+	if scores == nil {
+		scores = keywordScores(prompt, tools)
+	}
+
+	order := make([]int, len(tools))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+
+	selected := make([]bool, len(tools))
+	n := 0
+	for i, tool := range tools {
+		if slices.Contains(keep, tool.Function.Name) {
+			selected[i] = true
+			n++
+		}
+	}
+	for _, i := range order {
+		if n >= f.topK {
+			break
+		}
+		if !selected[i] {
+			selected[i] = true
+			n++
+		}
+	}
+
+	filtered := make([]types.ChatCompletionTool, 0, n)
+	for i, tool := range tools {
+		if selected[i] {
+			filtered = append(filtered, tool)
+		}
+	}
+	return filtered, err
+}
+
+// toolText is the text a tool is ranked by: its name, description and
+// parameter names
+func toolText(tool types.ChatCompletionTool) string {
+	parts := []string{tool.Function.Name}
+	if tool.Function.Description != nil {
+		parts = append(parts, *tool.Function.Description)
+	}
+	if tool.Function.Parameters != nil {
+		if properties, ok := (*tool.Function.Parameters)["properties"].(map[string]any); ok {
+			names := make([]string, 0, len(properties))
+			for name := range properties {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			parts = append(parts, names...)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// keywordScores scores every tool by the prompt terms it contains, weighted
+// by how rare they are among the tools and doubled for terms of the name
+func keywordScores(prompt string, tools []types.ChatCompletionTool) []float64 {
+	promptTerms := make(map[string]bool)
+	for _, term := range terms(prompt) {
+		promptTerms[term] = true
+	}
+
+	nameTerms := make([]map[string]bool, len(tools))
+	textTerms := make([]map[string]bool, len(tools))
+	frequency := make(map[string]int)
+	for i, tool := range tools {
+		nameTerms[i] = make(map[string]bool)
+		for _, term := range terms(tool.Function.Name) {
+			nameTerms[i][term] = true
+		}
+		textTerms[i] = make(map[string]bool)
+		for _, term := range terms(toolText(tool)) {
+			if !textTerms[i][term] {
+				textTerms[i][term] = true
+				frequency[term]++
+			}
+		}
+	}
+
+	scores := make([]float64, len(tools))
+	for i := range tools {
+		for term := range promptTerms {
+			if !textTerms[i][term] {
+				continue
+			}
+			weight := math.Log(1 + float64(len(tools))/float64(frequency[term]))
+			if nameTerms[i][term] {
+				weight *= 2
+			}
+			scores[i] += weight
+		}
+	}
+	return scores
+}
+
+// stopwords are left out of the keyword ranking
+var stopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"can": true, "do": true, "for": true, "from": true, "get": true, "how": true, "i": true, "in": true,
+	"is": true, "it": true, "me": true, "my": true, "of": true, "on": true, "or": true, "please": true,
+	"the": true, "this": true, "to": true, "use": true, "what": true, "with": true, "you": true,
+}
+
+// terms splits s into lowercase words, splitting identifiers on
+// underscores, dashes, dots and camel case, and dropping plural endings
+func terms(s string) []string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			w := strings.ToLower(string(word))
+			if len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") {
+				w = w[:len(w)-1]
+			}
+			if len(w) > 1 && !stopwords[w] {
+				words = append(words, w)
+			}
+			word = word[:0]
+		}
+	}
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]) {
+				flush()
+			}
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
+
+// embeddingScores scores every tool by the cosine similarity of its
+// embedding to the prompt's. Tool embeddings are cached.
+func (f *Filter) embeddingScores(ctx context.Context, prompt string, tools []types.ChatCompletionTool) ([]float64, error) {
+	texts := make([]string, len(tools))
+	f.mu.Lock()
+	missing := []string{prompt}
+	for i, tool := range tools {
+		texts[i] = toolText(tool)
+		if _, ok := f.vectors[texts[i]]; !ok && !slices.Contains(missing[1:], texts[i]) {
+			missing = append(missing, texts[i])
+		}
+	}
+	f.mu.Unlock()
+
+	vectors, err := f.embedder.Embed(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(missing) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(missing), len(vectors))
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, text := range missing[1:] {
+		f.vectors[text] = vectors[i+1]
+	}
+	scores := make([]float64, len(tools))
+	for i, text := range texts {
+		scores[i] = cosine(vectors[0], f.vectors[text])
+	}
+	return scores, nil
+}
+
+// cosine returns the cosine similarity of a and b, or 0 for vectors of
+// different lengths or zero length
+func cosine(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,141 @@
+package toolfilter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func tool(name, description string, params ...string) types.ChatCompletionTool {
+	properties := make(map[string]any)
+	for _, p := range params {
+		properties[p] = map[string]any{"type": "string"}
+	}
+	parameters := types.FunctionParameters{"type": "object", "properties": properties}
+	return types.ChatCompletionTool{
+		Type:     types.Function,
+		Function: types.FunctionObject{Name: name, Description: &description, Parameters: &parameters},
+	}
+}
+
+var catalog = []types.ChatCompletionTool{
+	tool("read_file", "Read the contents of a file from disk", "path"),
+	tool("write_file", "Write content to a file on disk", "path", "content"),
+	tool("list_directory", "List the entries of a directory", "path"),
+	tool("get_current_time", "Get the current time in a timezone", "timezone"),
+	tool("web_search", "Search the web for pages matching a query", "query"),
+	tool("createIssue", "Open a new GitHub issue in a repository", "repo", "title"),
+}
+
+func names(tools []types.ChatCompletionTool) []string {
+	var n []string
+	for _, t := range tools {
+		n = append(n, t.Function.Name)
+	}
+	return n
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Options{TopK: 0}, nil, nil)
+	assert.ErrorContains(t, err, "MCP_TOOL_FILTER_TOP_K")
+	_, err = New(Options{TopK: 4, Method: "bm25"}, nil, nil)
+	assert.ErrorContains(t, err, "MCP_TOOL_FILTER_METHOD")
+	_, err = New(Options{TopK: 4, Method: Embedding, EmbeddingModel: "text-embedding-3-small"}, nil, nil)
+	assert.ErrorContains(t, err, "provider/model")
+}
+
+func TestSelectKeyword(t *testing.T) {
+	f, err := New(Options{TopK: 2, Method: Keyword}, nil, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		prompt string
+		keep   []string
+		want   []string
+	}{
+		{
+			name:   "matches name and description",
+			prompt: "What time is it in the Tokyo timezone? Search the web if unsure",
+			want:   []string{"get_current_time", "web_search"},
+		},
+		{
+			name:   "plural and camel case",
+			prompt: "Please read these files and open issues for the bugs",
+			want:   []string{"read_file", "createIssue"},
+		},
+		{
+			name:   "called tools are kept",
+			prompt: "Now search the web for the error",
+			keep:   []string{"read_file"},
+			want:   []string{"read_file", "web_search"},
+		},
+		{
+			name:   "no match keeps the first tools",
+			prompt: "Hello!",
+			want:   []string{"read_file", "write_file"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := f.Select(context.Background(), tt.prompt, tt.keep, catalog)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, names(selected))
+		})
+	}
+}
+
+func TestSelectUnderTopK(t *testing.T) {
+	f, err := New(Options{TopK: 10}, nil, nil)
+	require.NoError(t, err)
+	selected, err := f.Select(context.Background(), "anything", nil, catalog)
+	require.NoError(t, err)
+	assert.Equal(t, names(catalog), names(selected))
+}
+
+// fakeEmbedder embeds texts as counts of a few words
+type fakeEmbedder struct {
+	err error
+}
+
+func (e *fakeEmbedder) Embed(_ context.Context, texts []string) ([][]float64, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		text = strings.ToLower(text)
+		vectors[i] = []float64{
+			float64(strings.Count(text, "file")),
+			float64(strings.Count(text, "time")),
+			float64(strings.Count(text, "web")) + float64(strings.Count(text, "search")),
+		}
+	}
+	return vectors, nil
+}
+
+func TestSelectEmbedding(t *testing.T) {
+	embedder := &fakeEmbedder{}
+	f, err := New(Options{TopK: 1, Method: Embedding}, nil, embedder)
+	require.NoError(t, err)
+
+	selected, err := f.Select(context.Background(), "search the web", nil, catalog)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web_search"}, names(selected))
+
+	selected, err = f.Select(context.Background(), "what time is it", nil, catalog)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"get_current_time"}, names(selected))
+	assert.Len(t, f.vectors, len(catalog), "tool embeddings are cached")
+
+	embedder.err = errors.New("embeddings unavailable")
+	selected, err = f.Select(context.Background(), "read the file", nil, catalog)
+	assert.Error(t, err)
+	assert.Equal(t, []string{"read_file"}, names(selected), "falls back to keywords")
+}
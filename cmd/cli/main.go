@@ -0,0 +1,13 @@
+// Command infergw is a command-line client for a running Inference Gateway,
+// see internal/cli
+package main
+
+import (
+	"os"
+
+	cli "github.com/inference-gateway/inference-gateway/internal/cli"
+)
+
+func main() {
+	os.Exit(cli.Main(os.Args[1:]))
+}
@@ -9,35 +9,69 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	gin "github.com/gin-gonic/gin"
 	promhttp "github.com/prometheus/client_golang/prometheus/promhttp"
-	envconfig "github.com/sethvargo/go-envconfig"
 	otelgin "go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 
 	api "github.com/inference-gateway/inference-gateway/api"
+	admin "github.com/inference-gateway/inference-gateway/api/admin"
+	agentjobs "github.com/inference-gateway/inference-gateway/api/agentjobs"
+	apispec "github.com/inference-gateway/inference-gateway/api/apispec"
+	batch "github.com/inference-gateway/inference-gateway/api/batch"
+	broadcast "github.com/inference-gateway/inference-gateway/api/broadcast"
+	budgets "github.com/inference-gateway/inference-gateway/api/budgets"
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	eval "github.com/inference-gateway/inference-gateway/api/eval"
+	files "github.com/inference-gateway/inference-gateway/api/files"
+	health "github.com/inference-gateway/inference-gateway/api/health"
 	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	modeldefaults "github.com/inference-gateway/inference-gateway/api/modeldefaults"
+	modelpolicy "github.com/inference-gateway/inference-gateway/api/modelpolicy"
+	prompts "github.com/inference-gateway/inference-gateway/api/prompts"
+	queue "github.com/inference-gateway/inference-gateway/api/queue"
+	shadow "github.com/inference-gateway/inference-gateway/api/shadow"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	think "github.com/inference-gateway/inference-gateway/api/think"
+	threads "github.com/inference-gateway/inference-gateway/api/threads"
 	config "github.com/inference-gateway/inference-gateway/config"
+	builtin "github.com/inference-gateway/inference-gateway/internal/builtin"
 	mcp "github.com/inference-gateway/inference-gateway/internal/mcp"
+	secrets "github.com/inference-gateway/inference-gateway/internal/secrets"
+	gatewayserver "github.com/inference-gateway/inference-gateway/internal/server"
+	supervisor "github.com/inference-gateway/inference-gateway/internal/supervisor"
+	validate "github.com/inference-gateway/inference-gateway/internal/validate"
 	l "github.com/inference-gateway/inference-gateway/logger"
 	otel "github.com/inference-gateway/inference-gateway/otel"
 	client "github.com/inference-gateway/inference-gateway/providers/client"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
 	registry "github.com/inference-gateway/inference-gateway/providers/registry"
 	routing "github.com/inference-gateway/inference-gateway/providers/routing"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
 )
 
 var (
 	version = "dev"
 )
 
+// shutdownTimeout bounds each step of the shutdown past the drain: closing
+// the connections left and flushing telemetry
+const shutdownTimeout = 5 * time.Second
+
 func main() {
 	versionFlag := flag.Bool("version", false, "Print version information")
 	helpFlag := flag.Bool("help", false, "Print help information")
 	flag.Parse()
 
+	if flag.Arg(0) == "validate" {
+		os.Exit(runValidate(flag.Args()[1:]))
+	}
+
 	if *versionFlag {
 		fmt.Println(version)
 		os.Exit(0)
@@ -48,6 +82,12 @@ func main() {
 		fmt.Println()
 		fmt.Println("Usage:")
 		fmt.Println("  inference-gateway [flags]")
+		fmt.Println("  inference-gateway validate [--offline] [--format text|json] [--timeout 1m]")
+		fmt.Println()
+		fmt.Println("Commands:")
+		fmt.Println("  validate     Check the configuration, provider API keys and MCP servers")
+		fmt.Println("               and exit 0 when valid, 1 when a check failed to connect")
+		fmt.Println("               or authenticate, 2 when the configuration is invalid")
 		fmt.Println()
 		fmt.Println("Flags:")
 		fmt.Println("  --version    Print version information")
@@ -64,18 +104,29 @@ func main() {
 		fmt.Println("  # Start with specific provider configured")
 		fmt.Println("  export OPENAI_API_KEY=your-key")
 		fmt.Println("  inference-gateway")
+		fmt.Println()
+		fmt.Println("  # Validate the configuration in CI without calling the providers")
+		fmt.Println("  inference-gateway validate --offline --format json")
 		os.Exit(0)
 	}
-	var config config.Config
-	cfg, err := config.Load(envconfig.OsLookuper())
+	cfg, err := config.LoadFromEnvironment()
 	if err != nil {
 		log.Printf("{\"error\": \"config load error: %v\"}", err)
 		return
 	}
 
 	// Initialize logger
+	redaction, err := l.ParseRedaction(cfg.LogRedaction)
+	if err != nil {
+		log.Printf("{\"error\": \"logger init error: %v\"}", err)
+		return
+	}
 	var logger l.Logger
-	logger, err = l.NewLogger(cfg.Environment)
+	logger, err = l.NewLoggerWithOptions(cfg.Environment, l.Options{
+		Redaction:          redaction,
+		SamplingInitial:    cfg.LogSamplingInitial,
+		SamplingThereafter: cfg.LogSamplingThereafter,
+	})
 	if err != nil {
 		log.Printf("{\"error\": \"logger init error: %v\"}", err)
 		return
@@ -84,6 +135,63 @@ func main() {
 	// Log config in debug mode
 	logger.Debug("loaded config", "config", cfg.String())
 
+	// Make the custom providers known before the settings naming providers
+	// are checked
+	registry.SetCustomProviders(cfg.Providers)
+	routingRules, err := routing.ParseRules(cfg.Routing.Rules)
+	if err != nil {
+		logger.Error("invalid routing rules", err)
+		return
+	}
+	routing.SetRules(routingRules)
+	downgrades, err := routing.ParseDowngrades(cfg.Routing.Downgrades, cfg.Routing.DowngradeSpendRates)
+	if err != nil {
+		logger.Error("invalid routing downgrades", err)
+		return
+	}
+	routing.SetDowngrades(downgrades)
+
+	// Fetch provider API keys from the secrets backend if configured
+	if err := secrets.Validate(cfg); err != nil {
+		logger.Error("invalid provider secrets configuration", err)
+		return
+	}
+	if err := secrets.Apply(context.Background(), &cfg); err != nil {
+		logger.Error("failed to fetch provider api keys from secrets backend", err, "backend", cfg.ProviderSecretsBackend)
+		return
+	}
+	if cfg.ProviderSecretsBackend != "" {
+		logger.Info("provider api keys fetched from secrets backend", "backend", cfg.ProviderSecretsBackend)
+	}
+
+	if err := registry.ApplyPolicies(cfg.Providers, cfg.ProviderTimeouts, cfg.ProviderConnectTimeouts, cfg.ProviderRetries); err != nil {
+		logger.Error("invalid provider timeouts or retries", err)
+		return
+	}
+
+	if _, err := think.ParseMode(cfg.ThinkTagMode); err != nil {
+		logger.Error("invalid think tag mode", err)
+		return
+	}
+	if cfg.ModelDefaultsPath != "" {
+		if _, err := modeldefaults.Load(cfg.ModelDefaultsPath); err != nil {
+			logger.Error("invalid model defaults", err, "path", cfg.ModelDefaultsPath)
+			return
+		}
+	}
+	if _, err := middlewares.ParseProxyPaths(cfg.ProxyAllowedPaths); err != nil {
+		logger.Error("invalid proxy allowed paths", err)
+		return
+	}
+	var modelPolicyFile *modelpolicy.Config
+	if cfg.ModelPolicyPath != "" {
+		var err error
+		if modelPolicyFile, err = modelpolicy.Load(cfg.ModelPolicyPath); err != nil {
+			logger.Error("invalid model policy", err, "path", cfg.ModelPolicyPath)
+			return
+		}
+	}
+
 	// Initialize OpenTelemetry Prometheus exporter Server
 	var telemetryImpl otel.OpenTelemetry
 	if cfg.Telemetry.Enable {
@@ -142,6 +250,45 @@ func main() {
 		return
 	}
 
+	// Initialize the supervision of the work started on behalf of requests
+	sup := supervisor.New(map[supervisor.Kind]int{supervisor.Agent: cfg.AgentMaxConcurrent})
+	if telemetryImpl != nil {
+		if err := telemetryImpl.ObserveInflight(func() map[string]int64 {
+			inflight := make(map[string]int64, len(supervisor.Kinds))
+			for _, kind := range supervisor.Kinds {
+				inflight[string(kind)] = sup.Active(kind)
+			}
+			return inflight
+		}); err != nil {
+			logger.Error("failed to register the inflight gauge", err)
+			return
+		}
+	}
+	supervision, err := middlewares.NewSupervisionMiddleware(logger, sup)
+	if err != nil {
+		logger.Error("failed to initialize supervision middleware", err)
+		return
+	}
+
+	// Initialize readiness tracking and shutdown draining
+	healthState := health.NewState()
+	drainMiddleware, err := middlewares.NewDrainMiddleware(logger, healthState)
+	if err != nil {
+		logger.Error("failed to initialize drain middleware", err)
+		return
+	}
+
+	// Initialize the monitor feeding the admin API and GET /v1/providers
+	adminMonitor := admin.NewMonitor(cfg.AdminRecentErrors)
+	var adminMonitorMiddleware middlewares.AdminMonitor
+	if cfg.Auth.AdminToken != "" {
+		adminMonitorMiddleware, err = middlewares.NewAdminMonitorMiddleware(logger, adminMonitor)
+		if err != nil {
+			logger.Error("failed to initialize admin monitor middleware", err)
+			return
+		}
+	}
+
 	// Initialize telemetry middleware
 	var telemetry middlewares.Telemetry
 	if cfg.Telemetry.Enable {
@@ -159,13 +306,125 @@ func main() {
 		return
 	}
 
+	// Initialize request limits middleware
+	requestLimits, err := middlewares.NewRequestLimitsMiddleware(logger, cfg)
+	if err != nil {
+		logger.Error("failed to initialize request limits middleware", err)
+		return
+	}
+
+	// Initialize request/response hooks middleware
+	hooksMiddleware, err := middlewares.NewHooksMiddleware(logger, cfg)
+	if err != nil {
+		logger.Error("failed to initialize hooks middleware", err, "path", cfg.HooksConfigPath)
+		return
+	}
+
+	// Initialize prompt templates and the injection middleware
+	var promptsCfg *prompts.Config
+	if cfg.PromptsConfigPath != "" {
+		promptsCfg, err = prompts.LoadConfig(cfg.PromptsConfigPath)
+		if err != nil {
+			logger.Error("failed to load prompt templates", err, "path", cfg.PromptsConfigPath)
+			return
+		}
+	}
+	promptStore, err := prompts.NewStore(promptsCfg)
+	if err != nil {
+		logger.Error("invalid prompt templates", err, "path", cfg.PromptsConfigPath)
+		return
+	}
+	promptInjector, err := middlewares.NewPromptInjectorMiddleware(logger, cfg, promptStore)
+	if err != nil {
+		logger.Error("failed to initialize prompt injection middleware", err)
+		return
+	}
+
+	// Initialize request deduplication if enabled
+	var dedup middlewares.Dedup
+	if cfg.DedupEnable {
+		dedup, err = middlewares.NewDedupMiddleware(logger, cfg)
+		if err != nil {
+			logger.Error("failed to initialize request deduplication middleware", err)
+			return
+		}
+	}
+
+	// Initialize streaming compression if enabled
+	var streamCompression middlewares.StreamCompression
+	if cfg.StreamCompressionEnable {
+		streamCompression, err = middlewares.NewStreamCompressionMiddleware(logger, cfg)
+		if err != nil {
+			logger.Error("failed to initialize stream compression middleware", err)
+			return
+		}
+	}
+
+	// Initialize stream fan-out if enabled
+	var streamHub *broadcast.Hub
+	var streamBroadcaster middlewares.StreamBroadcaster
+	if cfg.StreamBroadcastEnable {
+		streamHub = broadcast.NewHub(cfg.StreamBroadcastReplaySize, cfg.Server.WriteTimeout)
+		streamBroadcaster, err = middlewares.NewStreamBroadcasterMiddleware(logger, cfg, streamHub)
+		if err != nil {
+			logger.Error("failed to initialize stream broadcast middleware", err)
+			return
+		}
+	}
+
 	scheme := "http"
 	if cfg.Server.TlsCertPath != "" && cfg.Server.TlsKeyPath != "" {
 		scheme = "https"
 	}
 
-	httpClient := client.NewHTTPClient(cfg.Client, scheme, cfg.Server.Host, cfg.Server.Port)
-	providerRegistry := registry.NewProviderRegistry(cfg.Providers, logger)
+	httpClient, err := client.NewHTTPClient(cfg.Client, scheme, cfg.Server.Host, cfg.Server.Port)
+	if err != nil {
+		logger.Error("failed to create http client", err)
+		return
+	}
+	providerRegistry := registry.NewReloadableRegistry(cfg.Providers, logger)
+
+	// Layer per-tenant provider configurations over the gateway's own
+	var tenantsCfg *tenants.Config
+	if cfg.TenantsConfigPath != "" {
+		tenantsCfg, err = tenants.LoadConfig(cfg.TenantsConfigPath)
+		if err != nil {
+			logger.Error("failed to load tenants", err, "path", cfg.TenantsConfigPath)
+			return
+		}
+	}
+	tenantStore, err := tenants.NewStore(tenantsCfg, providerRegistry, logger)
+	if err != nil {
+		logger.Error("invalid tenants", err, "path", cfg.TenantsConfigPath)
+		return
+	}
+	tenantResolver, err := middlewares.NewTenantResolverMiddleware(logger, cfg, tenantStore)
+	if err != nil {
+		logger.Error("failed to initialize tenant resolution middleware", err)
+		return
+	}
+	if tenantStore.Enabled() {
+		logger.Info("multi-tenancy enabled", "tenants", len(tenantsCfg.Tenants))
+	}
+
+	// Chat requests are priced and held to the budgets of tenants and API keys
+	var usageLedger *budgets.Ledger
+	var usageMiddleware middlewares.Usage
+	usageCtx, stopUsage := context.WithCancel(context.Background())
+	defer stopUsage()
+	if cfg.UsageEnable {
+		usageLedger, err = budgets.NewLedger(cfg.UsageFile, tenantStore, logger)
+		if err != nil {
+			logger.Error("failed to initialize usage ledger", err, "path", cfg.UsageFile)
+			return
+		}
+		usageMiddleware, err = middlewares.NewUsageMiddleware(logger, cfg, usageLedger, tenantStore)
+		if err != nil {
+			logger.Error("failed to initialize usage middleware", err)
+			return
+		}
+		usageLedger.Start(usageCtx)
+	}
 
 	// Log registered providers
 	var providerNames []string
@@ -174,13 +433,46 @@ func main() {
 	}
 	logger.Info("provider registry initialized", "count", len(providerNames), "providers", strings.Join(providerNames, ", "))
 
+	builtinTools, err := builtin.New(cfg, logger)
+	if err != nil {
+		logger.Error("invalid built-in tools config", err)
+		return
+	}
+	var toolExecutors []mcp.ToolExecutor
+	var builtinToolDefinitions []types.ChatCompletionTool
+	if builtinTools != nil {
+		toolExecutors = append(toolExecutors, builtinTools)
+		builtinToolDefinitions = builtinTools.ChatCompletionTools()
+		logger.Info("built-in tools enabled", "tools", strings.Join(builtinTools.Names(), ", "))
+	}
+
 	// Initialize MCP middleware if enabled
 	var mcpClient mcp.MCPClientInterface
 	var mcpAgent mcp.Agent
 	var mcpMiddleware middlewares.MCPMiddleware
+	toolsEnabled := cfg.MCP.Enable || builtinTools != nil
 	if cfg.MCP.Enable {
-		if cfg.MCP.Servers != "" {
-			mcpClient = mcp.NewMCPClient(strings.Split(cfg.MCP.Servers, ","), logger, cfg)
+		var mcpStdioServers int
+		if cfg.MCP.ServersConfigPath != "" {
+			mcpServersConfig, err := mcp.LoadServersConfig(cfg.MCP.ServersConfigPath)
+			if err != nil {
+				logger.Error("invalid mcp servers config", err)
+				return
+			}
+			mcpStdioServers = len(mcpServersConfig.Stdio)
+		}
+		if cfg.MCP.Servers != "" || mcpStdioServers > 0 {
+			if err := mcp.ValidateToolPolicy(cfg.MCP); err != nil {
+				logger.Error("invalid mcp tool policy", err)
+				return
+			}
+			if cfg.MCP.SamplingEnable {
+				sampler := middlewares.NewMCPSampler(tenantStore, httpClient, modelpolicy.New(cfg, modelPolicyFile), logger, cfg)
+				mcpClient = mcp.NewMCPClientWithSampler(strings.Split(cfg.MCP.Servers, ","), logger, cfg, sampler)
+				logger.Info("mcp sampling enabled", "fallback_model", cfg.MCP.SamplingModel)
+			} else {
+				mcpClient = mcp.NewMCPClient(strings.Split(cfg.MCP.Servers, ","), logger, cfg)
+			}
 
 			initCtx, cancel := context.WithTimeout(context.Background(), cfg.MCP.RequestTimeout)
 			defer cancel()
@@ -198,14 +490,22 @@ func main() {
 				return
 			}
 
+			healthState.AddCheck("mcp", func() error {
+				if !mcpClient.IsInitialized() {
+					return mcp.ErrClientNotInitialized
+				}
+				return nil
+			})
 			mcpClient.StartStatusPolling(context.Background())
-			mcpAgent = mcp.NewAgent(logger, mcpClient)
-			logger.Info("mcp agent created successfully")
+			toolExecutors = append(toolExecutors, mcp.NewMCPToolExecutor(logger, mcpClient))
 		} else {
-			logger.Info("mcp is enabled but no servers configured, using no-op middleware")
-			mcpAgent = mcp.NewAgent(logger, mcpClient)
+			logger.Info("mcp is enabled but no servers configured")
 		}
-		mcpMiddleware, err = middlewares.NewMCPMiddleware(providerRegistry, httpClient, mcpClient, mcpAgent, logger, cfg)
+	}
+	if toolsEnabled {
+		mcpAgent = mcp.NewAgentWithSummarizer(logger, cfg, middlewares.NewToolResultSummarizer(tenantStore, httpClient, cfg), toolExecutors...)
+		logger.Info("mcp agent created successfully")
+		mcpMiddleware, err = middlewares.NewMCPMiddlewareWithBuiltinTools(tenantStore, httpClient, mcpClient, mcpAgent, builtinToolDefinitions, logger, cfg)
 		if err != nil {
 			logger.Error("failed to initialize mcp middleware", err)
 			return
@@ -215,7 +515,7 @@ func main() {
 	// Build the model routing selector if enabled (opt-in, default off).
 	var selector *routing.Selector
 	if cfg.Routing != nil && cfg.Routing.Enabled {
-		poolsCfg, err := routing.LoadPoolsConfig(cfg.Routing.ConfigPath)
+		poolsCfg, err := routing.LoadPools(cfg)
 		if err != nil {
 			logger.Error("failed to load routing config", err, "path", cfg.Routing.ConfigPath)
 			return
@@ -228,117 +528,667 @@ func main() {
 		logger.Info("model routing enabled", "aliases", selector.Aliases())
 	}
 
+	// Build the semantic router if enabled (opt-in, default off).
+	var semanticRouter *routing.SemanticRouter
+	if cfg.Routing != nil && cfg.Routing.SemanticEnabled {
+		semanticCfg, err := routing.LoadSemanticConfig(cfg.Routing.SemanticConfigPath)
+		if err != nil {
+			logger.Error("failed to load semantic routing config", err, "path", cfg.Routing.SemanticConfigPath)
+			return
+		}
+		semanticRouter, err = routing.NewSemanticRouter(semanticCfg, httpClient, nil)
+		if err != nil {
+			logger.Error("invalid semantic routing config", err, "path", cfg.Routing.SemanticConfigPath)
+			return
+		}
+		logger.Info("semantic routing enabled", "routes", len(semanticCfg.Routes), "embedding_provider", semanticCfg.Embedding.Provider)
+	}
+
+	// Uploaded files and Batch API results share one store
+	var fileStore files.Store
+	var fileResolver middlewares.FileResolver
+	if cfg.FilesEnable || cfg.BatchesEnable {
+		fileStore, err = files.NewStore(cfg)
+		if err != nil {
+			logger.Error("failed to initialize file store", err, "backend", cfg.FilesBackend)
+			return
+		}
+	}
+	if cfg.FilesEnable {
+		fileResolver, err = middlewares.NewFileResolverMiddleware(logger, cfg, fileStore)
+		if err != nil {
+			logger.Error("failed to initialize file resolver middleware", err)
+			return
+		}
+	}
+
 	// Set GIN mode based on environment
 	if cfg.Environment != "development" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	api := api.NewRouter(cfg, logger, providerRegistry, httpClient, mcpClient, telemetryImpl, selector)
+	api := api.NewRouter(cfg, logger, tenantStore, httpClient, mcpClient, telemetryImpl, selector, semanticRouter)
 	r := gin.New()
+	if err := middlewares.ConfigureClientIP(r, cfg); err != nil {
+		logger.Error("failed to configure client ip resolution", err)
+		return
+	}
+	// Batch items are dispatched through r itself so they pass the whole chain
+	batchRunner, err := batch.NewRunner(r, logger, cfg.BatchMaxItems, cfg.BatchConcurrency)
+	if err != nil {
+		logger.Error("failed to initialize batch runner", err)
+		return
+	}
+
+	// Queued requests are dispatched by the batch runner, through r as well
+	queueCtx, stopQueue := context.WithCancel(context.Background())
+	defer stopQueue()
+	var requestQueue *queue.Queue
+	var queueMiddleware middlewares.RequestQueue
+	if cfg.QueueEnable {
+		requestQueue, err = queue.New(batchRunner, logger, queue.Options{
+			Dir:           cfg.QueueDir,
+			Workers:       cfg.QueueWorkers,
+			MaxSize:       cfg.QueueMaxSize,
+			RetryInterval: cfg.QueueRetryInterval,
+			TTL:           cfg.QueueTtl,
+		})
+		if err != nil {
+			logger.Error("failed to initialize request queue", err, "dir", cfg.QueueDir)
+			return
+		}
+		queueMiddleware, err = middlewares.NewRequestQueueMiddleware(logger, cfg, requestQueue)
+		if err != nil {
+			logger.Error("failed to initialize request queue middleware", err)
+			return
+		}
+		requestQueue.Start(queueCtx)
+	}
+
+	// Shadow requests are dispatched by the batch runner too
+	var shadowMiddleware middlewares.Shadow
+	if cfg.ShadowEnable {
+		shadowModels, err := shadow.ParseModels(cfg.ShadowModels)
+		if err != nil {
+			logger.Error("invalid shadow models", err)
+			return
+		}
+		shadower, err := shadow.New(batchRunner, logger, shadow.Options{
+			Models:      shadowModels,
+			Percent:     cfg.ShadowPercent,
+			Concurrency: cfg.ShadowConcurrency,
+			Timeout:     cfg.ShadowTimeout,
+			LogContent:  cfg.ShadowLogContent,
+		})
+		if err != nil {
+			logger.Error("failed to initialize shadow traffic", err)
+			return
+		}
+		shadowMiddleware, err = middlewares.NewShadowMiddleware(logger, cfg, shadower)
+		if err != nil {
+			logger.Error("failed to initialize shadow middleware", err)
+			return
+		}
+	}
+
+	// Judge requests are dispatched by the batch runner too
+	var evalMiddleware middlewares.Eval
+	if cfg.EvalEnable {
+		evaluator, err := eval.New(batchRunner, telemetryImpl, logger, eval.Options{
+			Percent:          cfg.EvalPercent,
+			Concurrency:      cfg.EvalConcurrency,
+			LatencyThreshold: cfg.EvalLatencyThreshold,
+			JudgeModel:       cfg.EvalJudgeModel,
+			JudgeTimeout:     cfg.EvalJudgeTimeout,
+		})
+		if err != nil {
+			logger.Error("failed to initialize response evaluation", err)
+			return
+		}
+		evalMiddleware, err = middlewares.NewEvalMiddleware(logger, cfg, evaluator)
+		if err != nil {
+			logger.Error("failed to initialize response evaluation middleware", err)
+			return
+		}
+		if !cfg.Telemetry.Enable {
+			logger.Warn("response evaluation enabled without telemetry, scores are only logged")
+		}
+	}
+
 	if cfg.Telemetry.Enable && cfg.Telemetry.TracingEnable {
 		r.Use(otelgin.Middleware("inference-gateway", otelgin.WithFilter(func(req *http.Request) bool {
-			return req.URL.Path != "/health" && req.URL.Path != "/v1/metrics"
+			return !strings.HasPrefix(req.URL.Path, "/health") && req.URL.Path != "/v1/metrics"
 		})))
 		logger.Info("tracing middleware added to request pipeline")
 	}
 	r.Use(loggerMiddleware.Middleware())
+	if adminMonitorMiddleware != nil {
+		r.Use(adminMonitorMiddleware.Middleware())
+	}
+	r.Use(drainMiddleware.Middleware())
+	r.Use(supervision.Middleware())
 	if cfg.Telemetry.Enable {
 		r.Use(telemetry.Middleware())
 	}
 	r.Use(oidcAuthenticator.Middleware())
+	r.Use(tenantResolver.Middleware())
+	r.Use(requestLimits.Middleware())
+	if cfg.UsageEnable {
+		r.Use(usageMiddleware.Middleware())
+		logger.Info("usage accounting middleware added to request pipeline", "file", cfg.UsageFile)
+	}
+	if cfg.ShadowEnable {
+		r.Use(shadowMiddleware.Middleware())
+		logger.Info("shadow traffic middleware added to request pipeline", "percent", cfg.ShadowPercent, "models", cfg.ShadowModels)
+	}
+	if cfg.EvalEnable {
+		r.Use(evalMiddleware.Middleware())
+		logger.Info("response evaluation middleware added to request pipeline", "percent", cfg.EvalPercent, "judge_model", cfg.EvalJudgeModel)
+	}
+	if cfg.QueueEnable {
+		r.Use(queueMiddleware.Middleware())
+		logger.Info("request queue middleware added to request pipeline", "workers", cfg.QueueWorkers, "dir", cfg.QueueDir)
+	}
+	r.Use(hooksMiddleware.Middleware())
+	r.Use(promptInjector.Middleware())
+	if cfg.FilesEnable {
+		r.Use(fileResolver.Middleware())
+		logger.Info("file resolver middleware added to request pipeline")
+	}
+	if cfg.DedupEnable {
+		r.Use(dedup.Middleware())
+		logger.Info("request deduplication middleware added to request pipeline")
+	}
+	if cfg.StreamCompressionEnable {
+		r.Use(streamCompression.Middleware())
+		logger.Info("stream compression middleware added to request pipeline")
+	}
+	if cfg.StreamBroadcastEnable {
+		r.Use(streamBroadcaster.Middleware())
+		logger.Info("stream broadcast middleware added to request pipeline")
+	}
 
-	// Add MCP middleware if enabled
-	if cfg.MCP.Enable {
+	// Add MCP middleware if MCP or built-in tools are enabled
+	if toolsEnabled {
 		r.Use(mcpMiddleware.Middleware())
 		logger.Info("mcp middleware added to request pipeline")
 	}
 
+	// Batch API jobs run in the background until shutdown
+	batchCtx, stopBatches := context.WithCancel(context.Background())
+	defer stopBatches()
+	var batchManager *batch.Manager
+	if cfg.BatchesEnable {
+		batchManager, err = batch.NewManager(batchRunner, fileStore, cfg.BatchesDir, cfg.BatchesWorkers, logger)
+		if err != nil {
+			logger.Error("failed to initialize batch manager", err, "dir", cfg.BatchesDir)
+			return
+		}
+		batchManager.Start(batchCtx)
+		logger.Info("batch api enabled", "workers", cfg.BatchesWorkers, "dir", cfg.BatchesDir)
+	}
+	var threadManager *threads.Manager
+	if cfg.ThreadsEnable {
+		threadManager, err = threads.NewManager(batchRunner, cfg.ThreadsDir, cfg.ThreadsWorkers, logger)
+		if err != nil {
+			logger.Error("failed to initialize thread manager", err, "dir", cfg.ThreadsDir)
+			return
+		}
+		threadManager.Start(batchCtx)
+		logger.Info("threads api enabled", "workers", cfg.ThreadsWorkers, "dir", cfg.ThreadsDir)
+	}
+	// Agent jobs do not survive a restart, so on shutdown they may finish
+	// within the drain deadline instead of stopping with the batches
+	agentJobsCtx, stopAgentJobs := context.WithCancel(context.Background())
+	defer stopAgentJobs()
+	var agentJobManager *agentjobs.Manager
+	if cfg.AgentJobsEnable {
+		agentJobManager, err = agentjobs.NewManager(batchRunner, mcpClient, mcpAgent, logger, agentjobs.Options{
+			Dir:           cfg.AgentJobsDir,
+			Workers:       cfg.AgentJobsWorkers,
+			MaxIterations: cfg.AgentJobsMaxIterations,
+		})
+		if err != nil {
+			logger.Error("failed to initialize agent job manager", err, "dir", cfg.AgentJobsDir)
+			return
+		}
+		agentJobManager.Start(agentJobsCtx)
+		logger.Info("agent jobs api enabled", "workers", cfg.AgentJobsWorkers, "dir", cfg.AgentJobsDir, "max_iterations", cfg.AgentJobsMaxIterations)
+	}
+
 	r.GET("/health", api.HealthcheckHandler)
+	r.GET("/health/live", api.HealthcheckHandler)
+	r.GET("/health/ready", healthState.ReadyHandler)
 	r.Any("/proxy/:provider/*path", api.ProxyHandler)
 	v1 := r.Group("/v1")
 	{
 		v1.GET("/models", api.ListModelsHandler)
+		v1.GET("/providers", admin.ListProvidersHandler(providerRegistry, adminMonitor))
 		v1.GET("/mcp/tools", api.ListToolsHandler)
+		v1.GET("/mcp/resources", api.ListMCPResourcesHandler)
+		v1.GET("/mcp/resources/read", api.ReadMCPResourceHandler)
+		v1.GET("/mcp/prompts", api.ListMCPPromptsHandler)
+		v1.POST("/mcp/prompts/:name", api.GetMCPPromptHandler)
 		v1.POST("/chat/completions", api.ChatCompletionsHandler)
+		v1.POST("/chat/completions/batch", batchRunner.Handler)
 		v1.POST("/messages", api.MessagesHandler)
+		v1.POST("/context/pack", api.ContextPackHandler)
+		v1.POST("/tokenize", api.TokenizeHandler)
 		v1.POST("/metrics", api.MetricsIngestionHandler)
+		v1.GET("/errors", errcodes.ListHandler)
+		v1.GET("/errors/:code", errcodes.GetHandler)
+		if cfg.OllamaModelManagementEnable {
+			v1.GET("/providers/:provider/models", api.OllamaListModelsHandler)
+			v1.POST("/providers/:provider/models/pull", api.OllamaPullModelHandler)
+			v1.DELETE("/providers/:provider/models/*model", api.OllamaDeleteModelHandler)
+			logger.Info("ollama model management enabled")
+		}
+		if cfg.StreamBroadcastEnable {
+			v1.GET("/streams/:id/subscribe", streamHub.SubscribeHandler)
+		}
+		if cfg.UsageEnable {
+			v1.GET("/usage", usageLedger.UsageHandler)
+		}
+		if cfg.QueueEnable {
+			v1.GET("/queue/:id", requestQueue.GetHandler)
+		}
+		if cfg.BatchesEnable {
+			v1.POST("/batches", batchManager.CreateHandler)
+			v1.GET("/batches", batchManager.ListHandler)
+			v1.GET("/batches/:id", batchManager.GetHandler)
+			v1.POST("/batches/:id/cancel", batchManager.CancelHandler)
+			v1.GET("/batches/:id/output", batchManager.OutputHandler)
+			v1.GET("/batches/:id/errors", batchManager.OutputHandler)
+		}
+		if cfg.ThreadsEnable {
+			v1.POST("/threads", threadManager.CreateThreadHandler)
+			v1.GET("/threads/:thread_id", threadManager.GetThreadHandler)
+			v1.DELETE("/threads/:thread_id", threadManager.DeleteThreadHandler)
+			v1.POST("/threads/:thread_id/messages", threadManager.CreateMessageHandler)
+			v1.GET("/threads/:thread_id/messages", threadManager.ListMessagesHandler)
+			v1.POST("/threads/:thread_id/runs", threadManager.CreateRunHandler)
+			v1.GET("/threads/:thread_id/runs", threadManager.ListRunsHandler)
+			v1.GET("/threads/:thread_id/runs/:run_id", threadManager.GetRunHandler)
+			v1.POST("/threads/:thread_id/runs/:run_id/cancel", threadManager.CancelRunHandler)
+		}
+		if cfg.AgentJobsEnable {
+			v1.POST("/agent/jobs", agentJobManager.CreateHandler)
+			v1.GET("/agent/jobs/:id", agentJobManager.GetHandler)
+			v1.GET("/agent/jobs/:id/result", agentJobManager.ResultHandler)
+			v1.POST("/agent/jobs/:id/cancel", agentJobManager.CancelHandler)
+		}
+		if cfg.FilesEnable {
+			filesAPI, err := files.NewAPI(fileStore, logger)
+			if err != nil {
+				logger.Error("failed to initialize files api", err)
+				return
+			}
+			v1.POST("/files", filesAPI.UploadHandler)
+			v1.GET("/files", filesAPI.ListHandler)
+			v1.GET("/files/:id", filesAPI.GetHandler)
+			v1.GET("/files/:id/content", filesAPI.ContentHandler)
+			v1.DELETE("/files/:id", filesAPI.DeleteHandler)
+			logger.Info("files api enabled", "backend", cfg.FilesBackend)
+		}
+	}
+	var adminAPI *admin.Admin
+	if cfg.Auth.AdminToken != "" {
+		adminAuth, err := middlewares.NewAdminAuthMiddleware(logger, cfg)
+		if err != nil {
+			logger.Error("failed to initialize admin authentication", err)
+			return
+		}
+		adminAPI = admin.New(cfg, logger, adminMonitor, admin.Options{
+			Health:    healthState,
+			Registry:  providerRegistry,
+			Client:    httpClient,
+			MCPClient: mcpClient,
+			Tenants:   tenantStore,
+		})
+		adminGroup := r.Group(strings.TrimSuffix(middlewares.AdminPathPrefix, "/"), adminAuth.Middleware())
+		{
+			adminGroup.GET("/status", adminAPI.StatusHandler)
+			adminGroup.GET("/config", adminAPI.ConfigHandler)
+			adminGroup.GET("/providers", adminAPI.ProvidersHandler)
+			adminGroup.POST("/providers/:id/enable", adminAPI.EnableProviderHandler)
+			adminGroup.POST("/providers/:id/disable", adminAPI.DisableProviderHandler)
+			adminGroup.POST("/providers/:id/token", adminAPI.RotateTokenHandler)
+			adminGroup.GET("/mcp", adminAPI.MCPHandler)
+			adminGroup.GET("/errors", adminAPI.ErrorsHandler)
+			adminGroup.GET("/prompts", promptStore.ListHandler)
+			adminGroup.GET("/prompts/:name", promptStore.GetHandler)
+			adminGroup.PUT("/prompts/:name", promptStore.PutHandler)
+			adminGroup.DELETE("/prompts/:name", promptStore.DeleteHandler)
+		}
+		logger.Info("admin api enabled")
+	}
+	ollama := r.Group("/api")
+	{
+		ollama.GET("/tags", api.OllamaTagsHandler)
+		ollama.POST("/chat", api.OllamaChatHandler)
+	}
+	if cfg.ApiDocsEnable {
+		if err := apispec.Register(r); err != nil {
+			logger.Error("failed to build the openapi spec", err)
+			return
+		}
+		logger.Info("api docs enabled", "spec", apispec.SpecPath, "ui", apispec.DocsPath)
 	}
 	r.NoRoute(api.NotFoundHandler)
 
-	server := &http.Server{
-		Addr:         cfg.Server.Host + ":" + cfg.Server.Port,
-		Handler:      r,
-		ReadTimeout:  cfg.Server.ReadTimeout,
-		WriteTimeout: cfg.Server.WriteTimeout,
-		IdleTimeout:  cfg.Server.IdleTimeout,
+	// Apply reloaded configuration to the components that cache config values
+	reloader := config.NewReloader(cfg, func() (config.Config, error) {
+		newCfg, err := config.LoadFromEnvironment()
+		if err != nil {
+			return config.Config{}, err
+		}
+		if err := registry.ApplyPolicies(newCfg.Providers, newCfg.ProviderTimeouts, newCfg.ProviderConnectTimeouts, newCfg.ProviderRetries); err != nil {
+			return config.Config{}, err
+		}
+		return newCfg, secrets.Apply(context.Background(), &newCfg)
+	})
+	reloader.Subscribe(func(newCfg config.Config) error {
+		api.Reload(newCfg)
+		if mcpMiddleware != nil {
+			mcpMiddleware.Reload(newCfg)
+		}
+		if adminAPI != nil {
+			adminAPI.Reload(newCfg)
+		}
+		providerRegistry.Reload(newCfg.Providers)
+		rules, err := routing.ParseRules(newCfg.Routing.Rules)
+		if err != nil {
+			return err
+		}
+		routing.SetRules(rules)
+		downgrades, err := routing.ParseDowngrades(newCfg.Routing.Downgrades, newCfg.Routing.DowngradeSpendRates)
+		if err != nil {
+			return err
+		}
+		routing.SetDowngrades(downgrades)
+		tenantStore.Reload()
+		return requestLimits.Reload(newCfg)
+	})
+	mcpServers := cfg.MCP.Servers
+	reloader.Subscribe(func(newCfg config.Config) error {
+		if !cfg.MCP.Enable || newCfg.MCP.Servers == mcpServers {
+			return nil
+		}
+		if mcpClient == nil {
+			logger.Warn("mcp servers changed but mcp was started without servers; restart the gateway to apply")
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.MCP.RequestTimeout)
+		defer cancel()
+		mcpServers = newCfg.MCP.Servers
+		return mcpClient.UpdateServers(ctx, strings.Split(newCfg.MCP.Servers, ","))
+	})
+	reload := func(trigger string) func(error) {
+		return func(err error) {
+			if err != nil {
+				logger.Error("failed to reload configuration", err, "trigger", trigger)
+				return
+			}
+			logger.Info("configuration reloaded", "trigger", trigger)
+		}
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			reload("sighup")(reloader.Reload())
+		}
+	}()
+	if cfg.ProviderSecretsBackend != "" && cfg.ProviderSecretsRefreshInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(cfg.ProviderSecretsRefreshInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				reload("secrets_refresh")(reloader.Reload())
+			}
+		}()
+	}
+	if cfg.ConfigFile != "" && cfg.ConfigWatchInterval > 0 {
+		go reloader.Watch(context.Background(), cfg.ConfigFile, cfg.ConfigWatchInterval, reload("file"))
+		logger.Info("watching config file for changes", "path", cfg.ConfigFile, "interval", cfg.ConfigWatchInterval.String())
+	}
+
+	server, err := gatewayserver.New(cfg.Server, r)
+	if err != nil {
+		logger.Error("invalid server configuration", err)
+		return
+	}
+	listener, err := gatewayserver.Listen(cfg.Server, logger)
+	if err != nil {
+		logger.Error("failed to listen", err, "address", server.Addr)
+		return
 	}
 
 	if cfg.Server.TlsCertPath != "" && cfg.Server.TlsKeyPath != "" {
 		go func() {
-			logger.Info("starting inference gateway with tls", "port", cfg.Server.Port)
+			logger.Info("starting inference gateway with tls", "port", cfg.Server.Port, "tls_min_version", cfg.Server.TlsMinVersion)
 
-			if err := server.ListenAndServeTLS(cfg.Server.TlsCertPath, cfg.Server.TlsKeyPath); err != nil && err != http.ErrServerClosed {
+			if err := server.ServeTLS(listener, cfg.Server.TlsCertPath, cfg.Server.TlsKeyPath); err != nil && err != http.ErrServerClosed {
 				logger.Error("listen and serve tls error", err)
 			}
 		}()
 	} else {
 		go func() {
-			logger.Info("starting inference gateway", "port", cfg.Server.Port)
+			logger.Info("starting inference gateway", "port", cfg.Server.Port, "h2c", cfg.Server.H2cEnable)
 
-			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
 				logger.Error("listen and serve error", err)
 			}
 		}()
 	}
 
-	// Validate provider connectivity after server starts
+	// Validate provider connectivity after server starts; the gateway is not
+	// ready until at least one configured provider answers
+	if len(cfg.Providers) > 0 {
+		healthState.Set("providers", errors.New("provider validation in progress"))
+	}
+	checkCtx, stopChecks := context.WithCancel(context.Background())
+	defer stopChecks()
 	go func() {
 		// Wait a moment for the server to be ready
 		time.Sleep(2 * time.Second)
-
-		totalModels := 0
-		availableProviders := 0
-
-		for providerID := range cfg.Providers {
-			provider, err := providerRegistry.BuildProvider(providerID, httpClient)
-			if err != nil {
-				logger.Warn("failed to build provider", "provider", providerID, "error", err.Error())
-				continue
-			}
-
-			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-			response, err := provider.ListModels(ctx)
-			cancel()
-
-			if err != nil {
-				logger.Warn("provider unavailable or authentication failed", "provider", providerID, "error", err.Error())
-			} else {
-				modelCount := len(response.Data)
-				totalModels += modelCount
-				availableProviders++
-				logger.Info("provider ready", "provider", providerID, "models", modelCount)
+		checkProviders(checkCtx, logger, providerRegistry, httpClient, adminMonitor, healthState)
+		if cfg.ProviderCheckInterval <= 0 {
+			return
+		}
+		ticker := time.NewTicker(cfg.ProviderCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-checkCtx.Done():
+				return
+			case <-ticker.C:
+				checkProviders(checkCtx, logger, providerRegistry, httpClient, adminMonitor, healthState)
 			}
 		}
-
-		logger.Info("provider validation complete", "total_providers", len(cfg.Providers), "available_providers", availableProviders, "total_models", totalModels)
 	}()
 
+	// Load the OLLAMA_PREWARM_MODELS into memory and keep them loaded, so
+	// the first request to them does not wait for the model to load
+	if models := prewarmModels(cfg.OllamaPrewarmModels); len(models) > 0 {
+		keepAlive := time.Duration(-1)
+		if cfg.OllamaKeepAliveInterval > 0 {
+			keepAlive = 2 * cfg.OllamaKeepAliveInterval
+		}
+		go func() {
+			admin.WarmModels(checkCtx, logger, providerRegistry, httpClient, adminMonitor, constants.OllamaID, models, keepAlive)
+			if cfg.OllamaKeepAliveInterval <= 0 {
+				return
+			}
+			ticker := time.NewTicker(cfg.OllamaKeepAliveInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-checkCtx.Done():
+					return
+				case <-ticker.C:
+					admin.WarmModels(checkCtx, logger, providerRegistry, httpClient, adminMonitor, constants.OllamaID, models, keepAlive)
+				}
+			}
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 	<-quit
+	// Stop admitting work right away: new requests are rejected while the
+	// ones in flight, streams included, may finish until the drain deadline
+	healthState.Drain()
+	runningAgentJobs := 0
+	if agentJobManager != nil {
+		runningAgentJobs = agentJobManager.Running()
+	}
+	logger.Info("draining in-flight work...", "in_flight", healthState.InFlight(), "streams", healthState.Streams(), "agent_jobs", runningAgentJobs, "timeout", cfg.Server.DrainTimeout.String())
+	// Batch jobs and queued requests resume on the next start, so they stop
+	// first rather than compete with the work that would be lost
+	stopBatches()
+	stopQueue()
+	ctxDrain, cancelDrain := context.WithTimeout(context.Background(), cfg.Server.DrainTimeout)
+	var drained sync.WaitGroup
+	if agentJobManager != nil {
+		drained.Go(func() {
+			if err := agentJobManager.Drain(ctxDrain); err != nil {
+				logger.Warn("agent jobs still running at the drain deadline", "agent_jobs", agentJobManager.Running())
+			}
+		})
+	}
+	if err := healthState.Wait(ctxDrain); err != nil {
+		logger.Warn("drain deadline exceeded", "in_flight", healthState.InFlight(), "streams", healthState.Streams())
+	}
+	drained.Wait()
+	cancelDrain()
+	stopAgentJobs()
 	logger.Info("shutting down server...")
+	stopChecks()
 
 	if cfg.MCP.Enable && mcpClient != nil {
 		mcpClient.StopStatusPolling()
 		mcpClient.StopBackgroundReconnection()
 	}
 
-	ctxShutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// Requests still running past the drain deadline are cut off
+	ctxShutdown, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
 
 	if err := server.Shutdown(ctxShutdown); err != nil {
 		logger.Error("server shutdown error", err)
+		_ = server.Close()
 	} else {
 		logger.Info("server gracefully stopped")
 	}
+
+	if usageLedger != nil {
+		stopUsage()
+		if err := usageLedger.Save(); err != nil {
+			logger.Error("failed to save usage", err, "path", cfg.UsageFile)
+		}
+	}
+
+	if telemetryImpl != nil {
+		ctxFlush, cancelFlush := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancelFlush()
+		if err := telemetryImpl.Flush(ctxFlush); err != nil {
+			logger.Error("failed to flush telemetry", err)
+		} else {
+			logger.Info("telemetry flushed")
+		}
+	}
+}
+
+// runValidate runs the validate command with args, printing the report of the
+// configuration loaded from the environment and returning its exit code
+func runValidate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	offline := fs.Bool("offline", false, "Skip the checks calling the providers, MCP servers and secrets backend")
+	format := fs.String("format", "text", "Report format: text or json")
+	timeout := fs.Duration("timeout", time.Minute, "Time allowed for the online checks")
+	if err := fs.Parse(args); err != nil {
+		return validate.ExitInvalidConfig
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "unknown format %q, expected text or json\n", *format)
+		return validate.ExitInvalidConfig
+	}
+
+	var report *validate.Report
+	cfg, err := config.LoadFromEnvironment()
+	if err != nil {
+		report = validate.LoadFailed(err)
+	} else {
+		scheme := "http"
+		if cfg.Server.TlsCertPath != "" && cfg.Server.TlsKeyPath != "" {
+			scheme = "https"
+		}
+		httpClient, err := client.NewHTTPClient(cfg.Client, scheme, cfg.Server.Host, cfg.Server.Port)
+		if err != nil {
+			report = validate.LoadFailed(err)
+		} else {
+			ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+			defer cancel()
+			report = validate.Run(ctx, cfg, validate.Options{Offline: *offline, Client: httpClient})
+		}
+	}
+
+	if *format == "json" {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write the report: %v\n", err)
+	}
+	return report.ExitCode()
+}
+
+// prewarmModels parses OLLAMA_PREWARM_MODELS, accepting the models with or
+// without their ollama/ prefix
+func prewarmModels(value string) []string {
+	var models []string
+	for model := range strings.SplitSeq(value, ",") {
+		model = strings.TrimPrefix(strings.TrimSpace(model), string(constants.OllamaID)+"/")
+		if model != "" && !slices.Contains(models, model) {
+			models = append(models, model)
+		}
+	}
+	return models
+}
+
+// checkProviders checks the API key and connectivity of the enabled providers
+// by listing their models, recording each outcome in monitor. The gateway is
+// not ready while no configured provider answers.
+func checkProviders(ctx context.Context, logger l.Logger, providerRegistry *registry.ReloadableRegistry, httpClient client.Client, monitor *admin.Monitor, healthState *health.State) {
+	providers := providerRegistry.GetProviders()
+	totalModels := 0
+	availableProviders := 0
+
+	for providerID := range providers {
+		if !providerRegistry.Enabled(providerID) {
+			continue
+		}
+		modelCount, err := admin.Probe(ctx, providerRegistry, httpClient, providerID)
+		if ctx.Err() != nil {
+			return
+		}
+		monitor.RecordProviderCheck(providerID, modelCount, err)
+
+		if err != nil {
+			logger.Warn("provider unavailable or authentication failed", "provider", providerID, "error", err.Error())
+		} else {
+			totalModels += modelCount
+			availableProviders++
+			logger.Info("provider ready", "provider", providerID, "models", modelCount)
+		}
+	}
+
+	logger.Info("provider validation complete", "total_providers", len(providers), "available_providers", availableProviders, "total_models", totalModels)
+	if len(providers) > 0 && availableProviders == 0 {
+		healthState.Set("providers", errors.New("no provider is reachable"))
+	} else {
+		healthState.Set("providers", nil)
+	}
 }
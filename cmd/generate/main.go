@@ -20,7 +20,7 @@ var (
 func init() {
 	flag.StringVar(&output, "output", "", "Path to the output file")
 	flag.StringVar(&input, "input", "", "Path to the input file (CommunityPricing, CommunityContextWindows: a models.dev repository tarball)")
-	flag.StringVar(&_type, "type", "", "The type of the file to generate (Env, MD, Config, Providers, ProviderRegistry, ProvidersClientConfig, ProvidersConstants, MCPWrap, CommunityPricing, or CommunityContextWindows)")
+	flag.StringVar(&_type, "type", "", "The type of the file to generate (Env, MD, Config, ConfigFileSettings, ConfigFileSchema, APISpec, Providers, ProviderRegistry, ProvidersClientConfig, ProvidersConstants, MCPWrap, CommunityPricing, or CommunityContextWindows)")
 }
 
 func main() {
@@ -67,6 +67,27 @@ func main() {
 			fmt.Printf("Error generating config: %v\n", err)
 			os.Exit(1)
 		}
+	case "ConfigFileSettings":
+		fmt.Printf("Generating config file settings to %s\n", output)
+		err := codegen.GenerateConfigFileSettings(output, "openapi.yaml")
+		if err != nil {
+			fmt.Printf("Error generating config file settings: %v\n", err)
+			os.Exit(1)
+		}
+	case "ConfigFileSchema":
+		fmt.Printf("Generating config file schema to %s\n", output)
+		err := codegen.GenerateConfigFileSchema(output, "openapi.yaml")
+		if err != nil {
+			fmt.Printf("Error generating config file schema: %v\n", err)
+			os.Exit(1)
+		}
+	case "APISpec":
+		fmt.Printf("Generating the served OpenAPI spec to %s\n", output)
+		err := codegen.GenerateAPISpec(output, "openapi.yaml")
+		if err != nil {
+			fmt.Printf("Error generating the served OpenAPI spec: %v\n", err)
+			os.Exit(1)
+		}
 	case "Providers":
 		fmt.Printf("Generating provider files to %s\n", output)
 		err := codegen.GenerateProviders(output, "openapi.yaml")
@@ -96,6 +117,17 @@ func main() {
 			fmt.Printf("Error generating community context-window table: %v\n", err)
 			os.Exit(1)
 		}
+	case "CommunityCapabilities":
+		fmt.Printf("Generating community capability table to %s\n", output)
+		if input == "" {
+			fmt.Println("-input must point to a models.dev repository tarball")
+			os.Exit(1)
+		}
+		err := pricinggen.GenerateCapabilities(output, input)
+		if err != nil {
+			fmt.Printf("Error generating community capability table: %v\n", err)
+			os.Exit(1)
+		}
 	case "MCPWrap":
 		fmt.Printf("Wrapping MCP JSON schema as OpenAPI 3.1 to %s\n", output)
 		err := codegen.GenerateMCPWrap(output, "internal/mcp/mcp-schema.yaml")
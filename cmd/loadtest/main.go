@@ -0,0 +1,158 @@
+// Command loadtest streams chat completions through the gateway and reports
+// tokens per second, time to first token and allocations, see
+// internal/loadtest.
+//
+// By default it starts the mock provider and an in-process gateway in front
+// of it, measuring the handler and provider streaming path. With -url it
+// loads a running gateway instead; start one with OPENAI_API_URL pointing at
+// a mock provider served with -mock-addr to include the middlewares:
+//
+//	go run ./cmd/loadtest -mock-addr :9090 -mock-only &
+//	OPENAI_API_URL=http://localhost:9090/v1 OPENAI_API_KEY=mock go run ./cmd/gateway &
+//	go run ./cmd/loadtest -url http://localhost:8080 -concurrency 50 -duration 30s
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/signal"
+	"runtime"
+	"runtime/pprof"
+	"syscall"
+	"time"
+
+	loadtest "github.com/inference-gateway/inference-gateway/internal/loadtest"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	fs := flag.NewFlagSet("loadtest", flag.ContinueOnError)
+	gatewayURL := fs.String("url", "", "Base URL of a running gateway; an in-process gateway in front of the mock provider when empty")
+	token := fs.String("token", "", "Bearer token sent to the gateway")
+	model := fs.String("model", "openai/"+loadtest.MockModel, "Model requested")
+	concurrency := fs.Int("concurrency", 10, "Number of streams open at once")
+	requests := fs.Int("requests", 1000, "Number of requests sent in total, unbounded when 0")
+	duration := fs.Duration("duration", 0, "Duration of the run, unbounded when 0")
+	mockTokens := fs.Int("mock-tokens", 256, "Tokens of each reply of the mock provider")
+	mockInterval := fs.Duration("mock-interval", 0, "Wait of the mock provider before each streamed token")
+	mockAddr := fs.String("mock-addr", "", "Address to serve the mock provider on, for a gateway started separately")
+	mockOnly := fs.Bool("mock-only", false, "Only serve the mock provider on -mock-addr, until interrupted")
+	format := fs.String("format", "text", "Report format: text or json")
+	cpuProfile := fs.String("cpuprofile", "", "Write a CPU profile of the run to this file")
+	memProfile := fs.String("memprofile", "", "Write an allocation profile of the run to this file")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "unknown format %q, expected text or json\n", *format)
+		return 2
+	}
+	if *mockOnly && *mockAddr == "" {
+		fmt.Fprintln(os.Stderr, "-mock-only requires -mock-addr")
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	mock := &loadtest.MockProvider{Tokens: *mockTokens, Interval: *mockInterval}
+	if *mockAddr != "" {
+		listener, err := net.Listen("tcp", *mockAddr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to serve the mock provider: %v\n", err)
+			return 1
+		}
+		server := &http.Server{Handler: mock, ReadHeaderTimeout: 10 * time.Second}
+		go func() { _ = server.Serve(listener) }()
+		defer func() { _ = server.Close() }()
+		fmt.Fprintf(os.Stderr, "mock provider listening on %s\n", listener.Addr())
+		if *mockOnly {
+			<-ctx.Done()
+			return 0
+		}
+	}
+
+	if *gatewayURL == "" {
+		upstream := httptest.NewServer(mock)
+		defer upstream.Close()
+		gateway, err := loadtest.NewGateway(upstream.URL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to start the in-process gateway: %v\n", err)
+			return 1
+		}
+		server := httptest.NewServer(gateway)
+		defer server.Close()
+		*gatewayURL = server.URL
+	}
+
+	if *cpuProfile != "" {
+		f, err := os.Create(*cpuProfile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to create the cpu profile: %v\n", err)
+			return 1
+		}
+		defer func() { _ = f.Close() }()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to start the cpu profile: %v\n", err)
+			return 1
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	result, err := loadtest.Run(ctx, loadtest.Options{
+		URL:         *gatewayURL,
+		Token:       *token,
+		Model:       *model,
+		Concurrency: *concurrency,
+		Requests:    *requests,
+		Duration:    *duration,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 2
+	}
+
+	if *memProfile != "" {
+		if err := writeAllocsProfile(*memProfile); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write the allocation profile: %v\n", err)
+			return 1
+		}
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(result)
+	} else {
+		result.WriteText(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write the report: %v\n", err)
+		return 1
+	}
+	if result.Errors > 0 {
+		return 1
+	}
+	return 0
+}
+
+// writeAllocsProfile writes the allocations made since the start of the
+// process, for go tool pprof -sample_index=alloc_space
+func writeAllocsProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	runtime.GC()
+	return pprof.Lookup("allocs").WriteTo(f, 0)
+}
@@ -19,12 +19,113 @@ import (
 // Config holds the configuration for the Inference Gateway
 type Config struct {
 	// General settings
-	Environment               string `env:"ENVIRONMENT, default=production" description:"The environment"`
-	AllowedModels             string `env:"ALLOWED_MODELS" description:"Comma-separated list of models to allow. If empty, all models will be available"`
-	DisallowedModels          string `env:"DISALLOWED_MODELS" description:"Comma-separated list of models to disallow. If empty, no models will be blocked. Takes lower precedence than ALLOWED_MODELS"`
-	EnableVision              bool   `env:"ENABLE_VISION, default=false" description:"Enable vision/multimodal support for all providers. When disabled, image inputs will be rejected even if the provider and model support vision"`
-	DebugContentTruncateWords int    `env:"DEBUG_CONTENT_TRUNCATE_WORDS, default=10" description:"Number of words to truncate per content section in debug logs (development mode only)"`
-	DebugMaxMessages          int    `env:"DEBUG_MAX_MESSAGES, default=100" description:"Maximum number of messages to show in debug logs (development mode only)"`
+	Environment                       string        `env:"ENVIRONMENT, default=production" description:"The environment"`
+	AllowedModels                     string        `env:"ALLOWED_MODELS" description:"Comma-separated list of models to allow, by ID or wildcard pattern such as openai/gpt-4*. If empty, all models will be available"`
+	DisallowedModels                  string        `env:"DISALLOWED_MODELS" description:"Comma-separated list of models to disallow, by ID or wildcard pattern. If empty, no models will be blocked. Takes lower precedence than ALLOWED_MODELS"`
+	ModelPolicyPath                   string        `env:"MODEL_POLICY_PATH" description:"Path to a YAML file of allowed and disallowed model patterns per provider and per OIDC role. Role entries replace ALLOWED_MODELS and DISALLOWED_MODELS for the callers holding the role; provider entries always apply"`
+	ModelPolicyRolesClaim             string        `env:"MODEL_POLICY_ROLES_CLAIM, default=roles" description:"OIDC token claim listing the roles of the caller for the role entries of MODEL_POLICY_PATH. Nested claims are named with dots, e.g. realm_access.roles"`
+	ProxyStrict                       bool          `env:"PROXY_STRICT, default=false" description:"Parse and check the requests of the /proxy route: only the PROXY_ALLOWED_PATHS are forwarded, chat completion bodies must name a model and are subject to the SERVER_MAX_* limits, and their token usage is recorded"`
+	ProxyAllowedPaths                 string        `env:"PROXY_ALLOWED_PATHS, default=*/chat/completions,*/models,*/embeddings" description:"Comma-separated patterns of the provider/path of the /proxy requests forwarded in strict mode, * also spanning slashes (e.g. openai/v1/responses)"`
+	EnableVision                      bool          `env:"ENABLE_VISION, default=false" description:"Enable vision/multimodal support for all providers. When disabled, image inputs will be rejected even if the provider and model support vision"`
+	ApiDocsEnable                     bool          `env:"API_DOCS_ENABLE, default=false" description:"Serve the OpenAPI spec of the endpoints the gateway registers at /openapi.json and a Swagger UI for it at /docs, without authentication"`
+	OllamaModelManagementEnable       bool          `env:"OLLAMA_MODEL_MANAGEMENT_ENABLE, default=false" description:"Expose the /v1/providers/ollama/models endpoints pulling, listing and deleting the models of the Ollama backend"`
+	OllamaPrewarmModels               string        `env:"OLLAMA_PREWARM_MODELS" description:"Comma-separated Ollama models loaded into memory at startup, so the first request to them does not wait for the model to load. Their load state is reported by GET /v1/providers"`
+	OllamaKeepAliveInterval           time.Duration `env:"OLLAMA_KEEP_ALIVE_INTERVAL, default=4m" description:"Interval at which the OLLAMA_PREWARM_MODELS are pinged to stay loaded, each ping asking Ollama to keep the model for twice the interval. 0 loads them once and asks Ollama to keep them loaded indefinitely"`
+	DebugContentTruncateWords         int           `env:"DEBUG_CONTENT_TRUNCATE_WORDS, default=10" description:"Number of words to truncate per content section in debug logs (development mode only)"`
+	DebugMaxMessages                  int           `env:"DEBUG_MAX_MESSAGES, default=100" description:"Maximum number of messages to show in debug logs (development mode only)"`
+	LogRedaction                      string        `env:"LOG_REDACTION, default=headers,keys" description:"Comma-separated list of what is redacted from the logs: headers (values of Authorization, cookie, token and API key headers and fields), keys (bearer tokens and API keys found in logged strings and errors), content (message content, prompts, request and response bodies and stream chunks), or none"`
+	LogSamplingInitial                int           `env:"LOG_SAMPLING_INITIAL, default=100" description:"Number of debug lines with the same message logged per second before sampling starts. 0 disables sampling"`
+	LogSamplingThereafter             int           `env:"LOG_SAMPLING_THEREAFTER, default=100" description:"Once LOG_SAMPLING_INITIAL is reached, only every Nth debug line with the same message is logged for the rest of the second"`
+	HooksConfigPath                   string        `env:"HOOKS_CONFIG_PATH" description:"Path to a YAML file declaring per-route chains of request/response transformation hooks. Hooks are disabled when empty"`
+	PromptsConfigPath                 string        `env:"PROMPTS_CONFIG_PATH" description:"Path to a YAML file declaring named system prompt templates injected server-side into matching chat requests. Templates can also be managed at runtime via the admin API"`
+	TenantsConfigPath                 string        `env:"TENANTS_CONFIG_PATH" description:"Path to a YAML file declaring tenants with their own provider credentials, allowed models and request rate. Multi-tenancy is disabled when empty"`
+	TenantHeader                      string        `env:"TENANT_HEADER, default=X-Tenant-ID" description:"Request header naming the tenant of a request when TENANT_CLAIM is not set. Clients set it freely, so only use it behind a proxy that sets or strips it; with AUTH_ENABLE, TENANT_CLAIM is required instead"`
+	TenantClaim                       string        `env:"TENANT_CLAIM" description:"OIDC token claim naming the tenant of a request, required with AUTH_ENABLE when tenants are configured. When set, the tenant header is ignored and tokens without the claim are rejected"`
+	TenantDefault                     string        `env:"TENANT_DEFAULT" description:"Tenant of the requests without a TENANT_HEADER. When empty, such requests are rejected while tenants are configured"`
+	UsageEnable                       bool          `env:"USAGE_ENABLE, default=false" description:"Estimate the cost of chat completions from the community pricing of their models, return it in the X-Request-Cost header, report the monthly spend at /v1/usage and enforce the monthly budgets of the tenants"`
+	UsageFile                         string        `env:"USAGE_FILE" description:"Path of the JSON file the monthly spend is saved to, so budgets hold across restarts. The spend is kept in memory when empty"`
+	BatchMaxItems                     int           `env:"BATCH_MAX_ITEMS, default=100" description:"Maximum number of chat requests accepted by one POST /v1/chat/completions/batch call"`
+	BatchConcurrency                  int           `env:"BATCH_CONCURRENCY, default=8" description:"Maximum number of items of a batch processed concurrently"`
+	BatchesEnable                     bool          `env:"BATCHES_ENABLE, default=false" description:"Enable the OpenAI-compatible Batch API (/v1/batches), running uploaded JSONL files of chat requests in the background"`
+	BatchesWorkers                    int           `env:"BATCHES_WORKERS, default=2" description:"Number of batches of the Batch API processed at the same time"`
+	BatchesDir                        string        `env:"BATCHES_DIR, default=data/batches" description:"Directory persisting the state and partial results of Batch API jobs"`
+	ThreadsEnable                     bool          `env:"THREADS_ENABLE, default=false" description:"Enable the Assistants-style threads API (/v1/threads), whose runs answer a thread in the background through the chat completions pipeline, MCP agent loop included"`
+	ThreadsWorkers                    int           `env:"THREADS_WORKERS, default=4" description:"Number of thread runs executed at the same time; further runs stay queued"`
+	ThreadsDir                        string        `env:"THREADS_DIR, default=data/threads" description:"Directory persisting threads, their messages and runs"`
+	AgentJobsEnable                   bool          `env:"AGENT_JOBS_ENABLE, default=false" description:"Enable the background agent jobs API (/v1/agent/jobs), which runs the MCP agent loop server-side past the in-request iteration limit; requires MCP_ENABLE"`
+	AgentJobsWorkers                  int           `env:"AGENT_JOBS_WORKERS, default=4" description:"Number of agent jobs executed at the same time; further jobs stay queued"`
+	AgentJobsDir                      string        `env:"AGENT_JOBS_DIR, default=data/agent-jobs" description:"Directory persisting agent jobs and their intermediate messages"`
+	AgentJobsMaxIterations            int           `env:"AGENT_JOBS_MAX_ITERATIONS, default=100" description:"Maximum number of model turns of an agent job, and the default when a job sets no max_iterations"`
+	AgentMaxIterations                int           `env:"AGENT_MAX_ITERATIONS, default=10" description:"Maximum number of tool-call rounds of the MCP agent loop of a request; requests may lower it with agent_budget.max_iterations"`
+	AgentMaxToolCalls                 int           `env:"AGENT_MAX_TOOL_CALLS, default=0" description:"Maximum number of tool calls the MCP agent loop of a request executes; requests may lower it with agent_budget.max_tool_calls. Set to 0 to disable"`
+	AgentMaxDuration                  time.Duration `env:"AGENT_MAX_DURATION, default=0s" description:"Maximum time the MCP agent loop of a request keeps calling tools, checked between rounds; requests may lower it with agent_budget.max_duration_seconds. Set to 0 to disable"`
+	AgentMaxConcurrent                int           `env:"AGENT_MAX_CONCURRENT, default=0" description:"Maximum number of MCP agent loops running at once across all requests; requests beyond it are answered with 503 gateway_at_capacity. Set to 0 to disable"`
+	BuiltinTools                      string        `env:"BUILTIN_TOOLS" description:"Comma-separated list of the tools built into the gateway that are offered to models alongside the MCP tools and run by the agent loop: fetch (HTTP GET of an allowed URL), time (current time in a timezone) and calculator (arithmetic expressions). Works without MCP servers or MCP_ENABLE"`
+	BuiltinToolsFetchAllow            string        `env:"BUILTIN_TOOLS_FETCH_ALLOW" description:"Comma-separated host glob patterns (e.g. *.wikipedia.org,docs.example.com) the fetch tool may request, redirects included. Required by the fetch tool"`
+	BuiltinToolsFetchTimeout          time.Duration `env:"BUILTIN_TOOLS_FETCH_TIMEOUT, default=10s" description:"Timeout of a request of the fetch tool"`
+	BuiltinToolsFetchMaxBytes         int           `env:"BUILTIN_TOOLS_FETCH_MAX_BYTES, default=1048576" description:"Maximum number of bytes of a response body read by the fetch tool; the rest is dropped"`
+	QueueEnable                       bool          `env:"QUEUE_ENABLE, default=false" description:"Enable the request queue: non-streaming chat requests rejected because a provider is saturated (rate limited or overloaded) are queued and retried as capacity frees up instead of failing"`
+	QueueDir                          string        `env:"QUEUE_DIR, default=data/queue" description:"Directory persisting queued requests and their results"`
+	QueueWorkers                      int           `env:"QUEUE_WORKERS, default=4" description:"Number of queued requests dispatched at the same time"`
+	QueueMaxSize                      int           `env:"QUEUE_MAX_SIZE, default=1000" description:"Maximum number of queued requests. Requests beyond it fail as before"`
+	QueueWaitTimeout                  time.Duration `env:"QUEUE_WAIT_TIMEOUT, default=30s" description:"How long a queued request holds the connection waiting for its result before it is answered with 202 and a polling URL. Clients sending Prefer: respond-async get the 202 immediately"`
+	QueueRetryInterval                time.Duration `env:"QUEUE_RETRY_INTERVAL, default=1s" description:"Pause before dispatching queued requests again after a provider rejected one as saturated"`
+	QueueTtl                          time.Duration `env:"QUEUE_TTL, default=10m" description:"How long a request may stay queued before it expires, and how long results stay available for polling"`
+	FilesEnable                       bool          `env:"FILES_ENABLE, default=false" description:"Enable the OpenAI-compatible Files API (/v1/files) and let chat requests reference uploaded images by file ID"`
+	FilesBackend                      string        `env:"FILES_BACKEND, default=disk" description:"Storage of uploaded and generated files: disk (FILES_DIR) or s3 (FILES_S3_BUCKET)"`
+	FilesDir                          string        `env:"FILES_DIR, default=data/files" description:"Directory storing uploaded files and Batch API results when FILES_BACKEND is disk"`
+	FilesS3Bucket                     string        `env:"FILES_S3_BUCKET" description:"S3 bucket storing files, required when FILES_BACKEND is s3. The region and credentials are read from AWS_REGION and the AWS_* credential env vars"`
+	FilesS3Prefix                     string        `env:"FILES_S3_PREFIX" description:"Key prefix of the files stored in FILES_S3_BUCKET (e.g. gateway/files/)"`
+	FilesS3Endpoint                   string        `env:"FILES_S3_ENDPOINT" description:"Endpoint of an S3-compatible store such as MinIO, addressed path-style. Uses the regional AWS endpoint when empty"`
+	FilesMaxUploadBytes               int           `env:"FILES_MAX_UPLOAD_BYTES, default=536870912" description:"Maximum size in bytes of a POST /v1/files upload, in place of SERVER_MAX_REQUEST_BYTES. Larger uploads are rejected with 413. Set to 0 to disable"`
+	StructuredOutputEmulatedProviders string        `env:"STRUCTURED_OUTPUT_EMULATED_PROVIDERS, default=anthropic" description:"Comma-separated list of providers without native json_schema response_format support. For these the gateway emulates structured outputs by injecting the schema as instructions and validating and repairing the returned JSON"`
+	StructuredOutputMaxRetries        int           `env:"STRUCTURED_OUTPUT_MAX_RETRIES, default=2" description:"Maximum number of times an emulated structured output request is retried when the response does not validate against the schema. The request fails with 422 once exhausted"`
+	StreamBroadcastEnable             bool          `env:"STREAM_BROADCAST_ENABLE, default=false" description:"Enable fan-out of streaming responses to subscribers of /v1/streams/:id/subscribe"`
+	StreamBroadcastReplaySize         int           `env:"STREAM_BROADCAST_REPLAY_SIZE, default=1024" description:"Number of most recent stream chunks replayed to late subscribers"`
+	StreamResumeEnable                bool          `env:"STREAM_RESUME_ENABLE, default=false" description:"Enable resuming interrupted streaming chat completions: stream events carry IDs and a client reconnecting with Last-Event-ID gets the events it missed and the rest of the generation instead of a new one"`
+	StreamResumeBufferSize            int           `env:"STREAM_RESUME_BUFFER_SIZE, default=4096" description:"Maximum number of events kept per stream for resuming. Clients that fall further behind cannot resume"`
+	StreamResumeTtl                   time.Duration `env:"STREAM_RESUME_TTL, default=2m" description:"How long a stream stays resumable after it completed, and how long a generation keeps running without a connected client before it is cancelled"`
+	StreamCompressionEnable           bool          `env:"STREAM_COMPRESSION_ENABLE, default=false" description:"Enable per-connection gzip compression of streaming responses for clients sending Accept-Encoding: gzip, and delta encoding of repeated chunk envelope fields for clients sending X-Stream-Delta: true"`
+	TokenizerEncodingsDir             string        `env:"TOKENIZER_ENCODINGS_DIR" description:"Directory holding tiktoken rank files (cl100k_base.tiktoken, o200k_base.tiktoken) used to count the tokens of OpenAI models exactly. Other models, and all models when empty, get estimated counts"`
+	PromptCacheAuto                   bool          `env:"PROMPT_CACHE_AUTO, default=false" description:"Automatically mark the tools and leading system messages of chat completions as cacheable (cache_control breakpoint) for providers that only cache up to explicit breakpoints (Anthropic), when the client set none"`
+	PromptCacheMinTokens              int           `env:"PROMPT_CACHE_MIN_TOKENS, default=1024" description:"Minimum number of tokens of the tools and leading system messages for PROMPT_CACHE_AUTO to mark them as cacheable"`
+	ThinkTagMode                      string        `env:"THINK_TAG_MODE, default=off" description:"How the think blocks (reasoning wrapped in think tags) in the content of chat completions from reasoning models such as DeepSeek-R1 on Ollama are handled: off passes them through, remove drops them, collapse keeps empty think tags as a marker and reasoning_content moves the reasoning to the reasoning_content field of the message or delta"`
+	ModelDefaultsPath                 string        `env:"MODEL_DEFAULTS_PATH" description:"Path to a YAML file of per-model chat completion parameters: defaults applied when the client omits them, overrides always applied and max capping numeric parameters. Requests with the admin token may opt out with X-Skip-Model-Defaults: true"`
+	DedupEnable                       bool          `env:"DEDUP_ENABLE, default=false" description:"Enable request deduplication: identical non-streaming chat requests arriving while one is in flight wait for it and share its response instead of calling the provider again"`
+	ShadowEnable                      bool          `env:"SHADOW_ENABLE, default=false" description:"Enable shadow traffic: a share of the chat completion requests is duplicated in the background to a secondary model, without affecting the response or latency of the client, to evaluate the model before a cutover"`
+	ShadowModels                      string        `env:"SHADOW_MODELS" description:"Comma-separated list of model=shadow_model pairs naming the model the chat requests for a model are shadowed to, * matching any other model (e.g. openai/gpt-4o=anthropic/claude-sonnet-4-5,*=groq/llama-3.3-70b-versatile)"`
+	ShadowPercent                     int           `env:"SHADOW_PERCENT, default=10" description:"Percentage (0-100) of the chat requests for a model of SHADOW_MODELS that are shadowed"`
+	ShadowConcurrency                 int           `env:"SHADOW_CONCURRENCY, default=8" description:"Maximum number of shadow requests in flight. Requests arriving while it is reached are not shadowed"`
+	ShadowTimeout                     time.Duration `env:"SHADOW_TIMEOUT, default=60s" description:"Timeout of a shadow request"`
+	ShadowLogContent                  bool          `env:"SHADOW_LOG_CONTENT, default=false" description:"Log the response content of the primary and shadow models with each comparison, not only their status, latency, finish reason and token usage"`
+	EvalEnable                        bool          `env:"EVAL_ENABLE, default=false" description:"Enable response evaluation: a sample of the successful chat completion responses is scored in the background (latency, refusal detection, JSON validity and an optional LLM judge) and the scores are exported per provider and model as the inference_gateway_evaluation_score metric"`
+	EvalPercent                       int           `env:"EVAL_PERCENT, default=5" description:"Percentage (0-100) of the successful chat completion responses that are evaluated"`
+	EvalConcurrency                   int           `env:"EVAL_CONCURRENCY, default=4" description:"Maximum number of evaluations in flight. Responses arriving while it is reached are not evaluated"`
+	EvalLatencyThreshold              time.Duration `env:"EVAL_LATENCY_THRESHOLD, default=30s" description:"Latency above which the latency evaluator fails a sampled response"`
+	EvalJudgeModel                    string        `env:"EVAL_JUDGE_MODEL" description:"Model, in provider/model form, asked to grade each sampled response from 1 to 10 (LLM-as-judge). Use a cheap model; the judge is skipped when empty"`
+	EvalJudgeTimeout                  time.Duration `env:"EVAL_JUDGE_TIMEOUT, default=30s" description:"Timeout of a judge request"`
+	AdminRecentErrors                 int           `env:"ADMIN_RECENT_ERRORS, default=100" description:"Number of most recent failed requests kept for GET /admin/errors when the admin API is enabled"`
+	SafetyModerationModel             string        `env:"SAFETY_MODERATION_MODEL, default=omni-moderation-latest" description:"OpenAI moderation model used to pre-check requests with safety_settings that are routed to OpenAI"`
+	ConfigFile                        string        `env:"CONFIG_FILE" description:"Path to a config file layered over the process environment: an env file of KEY=VALUE lines, which override the environment, or a YAML or JSON file (.yaml, .yml, .json) of settings grouped by section as described by config.schema.json, which the environment overrides. Allowed models, provider API keys and URLs, request limits and MCP servers set in it are reloaded without a restart when it changes or on SIGHUP"`
+	ConfigWatchInterval               time.Duration `env:"CONFIG_WATCH_INTERVAL, default=10s" description:"Interval at which CONFIG_FILE is checked for changes. Set to 0 to only reload on SIGHUP"`
+	ProviderSecretsBackend            string        `env:"PROVIDER_SECRETS_BACKEND" description:"Secrets backend provider API keys are fetched from: vault or aws. Disabled when empty, in which case keys only come from the *_API_KEY env vars"`
+	ProviderSecretsPaths              string        `env:"PROVIDER_SECRETS_PATHS" description:"Comma-separated provider=path pairs locating each provider API key in the secrets backend, with an optional #field (e.g. openai=secret/data/llm#openai,anthropic=prod/anthropic). Vault paths are relative to /v1/ and read the api_key field by default; AWS paths are secret IDs whose whole value is used unless a JSON field is given. A fetched key overrides the *_API_KEY env var"`
+	ProviderSecretsRefreshInterval    time.Duration `env:"PROVIDER_SECRETS_REFRESH_INTERVAL, default=5m" description:"Interval at which provider API keys are fetched again from the secrets backend, so rotated keys apply without a restart. Set to 0 to fetch them only at startup and on config reload"`
+	ProviderBackoffMax                time.Duration `env:"PROVIDER_BACKOFF_MAX, default=1m" description:"Longest time the Retry-After or rate limit reset headers of a throttled provider keep the gateway from calling the same model: routed model aliases prefer their other deployments, and direct requests are answered 429 with Retry-After without calling the provider. Set to 0 to only pass the headers on to clients"`
+	ProviderHealthWindow              time.Duration `env:"PROVIDER_HEALTH_WINDOW, default=5m" description:"Window over which provider call outcomes decide whether a provider is healthy; models of unhealthy providers are left out of the models list"`
+	ProviderHealthMinFailures         int           `env:"PROVIDER_HEALTH_MIN_FAILURES, default=3" description:"Failed provider calls within PROVIDER_HEALTH_WINDOW, also making up half of its calls or more, that mark the provider unhealthy. 0 disables health tracking"`
+	ProviderCheckInterval             time.Duration `env:"PROVIDER_CHECK_INTERVAL, default=0s" description:"Interval at which the API key and connectivity of each provider is checked again after the startup check, reported by GET /v1/providers. 0 checks at startup only"`
+	ProviderTimeouts                  string        `env:"PROVIDER_TIMEOUTS" description:"Comma-separated per-provider request timeouts as provider=duration pairs (e.g. ollama=15m,openai=30s), overriding the defaults of the provider registry (10m for ollama and llamacpp). Providers without one use SERVER_READ_TIMEOUT"`
+	ProviderConnectTimeouts           string        `env:"PROVIDER_CONNECT_TIMEOUTS" description:"Comma-separated per-provider connect timeouts as provider=duration pairs (e.g. openai=2s) bounding how long establishing a connection to the provider may take. Providers without one use 30s"`
+	ProviderRetries                   string        `env:"PROVIDER_RETRIES" description:"Comma-separated per-provider retry counts as provider=count pairs (e.g. openai=2). Requests failing to reach the provider or answered 502, 503 or 504 are retried with exponential backoff starting at PROVIDER_RETRY_BACKOFF; providers without one are not retried"`
+	ProviderRetryBackoff              time.Duration `env:"PROVIDER_RETRY_BACKOFF, default=500ms" description:"Wait before the first retry of a failed provider request, doubled after every further attempt"`
+	CustomProviders                   string        `env:"CUSTOM_PROVIDERS" description:"Comma-separated IDs of OpenAI-compatible providers to register without code changes, such as vLLM, LM Studio, llama.cpp server or Together instances (e.g. vllm,lm-studio). Each is configured like the built-in providers with <ID>_API_URL, required, and <ID>_API_KEY, sent as a bearer token unless <ID>_AUTH_HEADER names another header; dashes become underscores in the variable names. Their models are listed by GET /v1/models as <id>/<model>"`
+	ModelsCacheTtl                    time.Duration `env:"MODELS_CACHE_TTL, default=5m" description:"How long the model list of each provider is cached by the models endpoints. Older lists are still served while they are refreshed in the background. 0 disables the cache"`
+	ModelsCacheMaxStale               time.Duration `env:"MODELS_CACHE_MAX_STALE, default=1h" description:"How long past MODELS_CACHE_TTL a cached model list is still served, during refreshes and provider outages, before the provider is listed again synchronously"`
+	ModelsProviderTimeout             time.Duration `env:"MODELS_PROVIDER_TIMEOUT, default=10s" description:"How long GET /v1/models waits for the model list of each provider when listing all providers. Providers answering later, or failing, are left out and reported in failed_providers"`
+	VaultAddr                         string        `env:"VAULT_ADDR" description:"HashiCorp Vault address (e.g. https://vault.example.com:8200), required when PROVIDER_SECRETS_BACKEND is vault"`
+	VaultToken                        string        `env:"VAULT_TOKEN" type:"secret" description:"HashiCorp Vault token used to read provider API keys"`
+	AwsRegion                         string        `env:"AWS_REGION" description:"AWS region of Secrets Manager and of the S3 file store, required by the aws secrets backend and the s3 files backend. Credentials are read from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN"`
 	// Telemetry settings
 	Telemetry *TelemetryConfig `env:", prefix=TELEMETRY_" description:"Telemetry configuration"`
 	// MCP settings
@@ -44,35 +145,60 @@ type Config struct {
 
 // Telemetry configuration
 type TelemetryConfig struct {
-	Enable              bool   `env:"ENABLE, default=false" description:"Enable telemetry"`
-	MetricsPushEnable   bool   `env:"METRICS_PUSH_ENABLE, default=false" description:"Enable the OTLP metrics push endpoint (POST /v1/metrics)"`
-	MetricsPort         string `env:"METRICS_PORT, default=9464" description:"Port for telemetry metrics server"`
-	TracingEnable       bool   `env:"TRACING_ENABLE, default=false" description:"Enable OpenTelemetry tracing spans (requires TELEMETRY_ENABLE)"`
-	TracingOtlpEndpoint string `env:"TRACING_OTLP_ENDPOINT, default=http://localhost:4318" description:"OTLP HTTP endpoint for trace export"`
+	Enable                  bool   `env:"ENABLE, default=false" description:"Enable telemetry"`
+	MetricsPushEnable       bool   `env:"METRICS_PUSH_ENABLE, default=false" description:"Enable the OTLP metrics push endpoint (POST /v1/metrics)"`
+	MetricsPort             string `env:"METRICS_PORT, default=9464" description:"Port for telemetry metrics server"`
+	TracingEnable           bool   `env:"TRACING_ENABLE, default=false" description:"Enable OpenTelemetry tracing spans (requires TELEMETRY_ENABLE)"`
+	TracingExporter         string `env:"TRACING_EXPORTER, default=otlp" description:"Trace exporter: otlp sends the traces to TELEMETRY_TRACING_OTLP_ENDPOINT, langfuse to the OTLP endpoint of TELEMETRY_LANGFUSE_HOST with the Langfuse project keys"`
+	TracingOtlpEndpoint     string `env:"TRACING_OTLP_ENDPOINT, default=http://localhost:4318" description:"OTLP HTTP endpoint for trace export"`
+	TracingOtlpHeaders      string `env:"TRACING_OTLP_HEADERS" description:"Comma-separated key=value headers sent with every OTLP trace export, e.g. Authorization=Basic ... for a hosted collector"`
+	LangfuseHost            string `env:"LANGFUSE_HOST, default=https://cloud.langfuse.com" description:"Base URL of the Langfuse instance the traces are sent to when TELEMETRY_TRACING_EXPORTER is langfuse"`
+	LangfusePublicKey       string `env:"LANGFUSE_PUBLIC_KEY" description:"Langfuse project public key (pk-lf-...)"`
+	LangfuseSecretKey       string `env:"LANGFUSE_SECRET_KEY" type:"secret" description:"Langfuse project secret key (sk-lf-...)"`
+	CaptureContent          bool   `env:"CAPTURE_CONTENT, default=false" description:"Record the prompt and completion messages of chat completions on their trace as gen_ai.input.messages and gen_ai.output.messages (requires TELEMETRY_TRACING_ENABLE)"`
+	CaptureContentPercent   int    `env:"CAPTURE_CONTENT_PERCENT, default=100" description:"Percentage (0-100) of the chat completions whose messages are recorded when TELEMETRY_CAPTURE_CONTENT is enabled"`
+	CaptureContentRedaction string `env:"CAPTURE_CONTENT_REDACTION, default=keys" description:"Comma-separated list of what is redacted from the recorded messages: keys (bearer tokens and API keys), pii (email addresses, phone numbers, payment card numbers and IP addresses), or none"`
 }
 
 // MCP configuration
 type MCPConfig struct {
-	Enable                 bool          `env:"ENABLE, default=false" description:"Enable MCP"`
-	Expose                 bool          `env:"EXPOSE, default=false" description:"Expose MCP tools endpoint"`
-	Servers                string        `env:"SERVERS" description:"List of MCP servers"`
-	IncludeTools           string        `env:"INCLUDE_TOOLS" description:"Comma-separated list of MCP tool names to inject. If empty, all tools are injected. Takes precedence over MCP_EXCLUDE_TOOLS"`
-	ExcludeTools           string        `env:"EXCLUDE_TOOLS" description:"Comma-separated list of MCP tool names to skip injecting. If empty, no tools are excluded. Takes lower precedence than MCP_INCLUDE_TOOLS"`
-	ClientTimeout          time.Duration `env:"CLIENT_TIMEOUT, default=5s" description:"MCP client HTTP timeout"`
-	DialTimeout            time.Duration `env:"DIAL_TIMEOUT, default=3s" description:"MCP client dial timeout"`
-	TlsHandshakeTimeout    time.Duration `env:"TLS_HANDSHAKE_TIMEOUT, default=3s" description:"MCP client TLS handshake timeout"`
-	ResponseHeaderTimeout  time.Duration `env:"RESPONSE_HEADER_TIMEOUT, default=3s" description:"MCP client response header timeout"`
-	ExpectContinueTimeout  time.Duration `env:"EXPECT_CONTINUE_TIMEOUT, default=1s" description:"MCP client expect continue timeout"`
-	RequestTimeout         time.Duration `env:"REQUEST_TIMEOUT, default=5s" description:"MCP client request timeout for initialize and tool calls"`
-	MaxRetries             int           `env:"MAX_RETRIES, default=3" description:"Maximum number of connection retry attempts"`
-	RetryInterval          time.Duration `env:"RETRY_INTERVAL, default=5s" description:"Interval between connection retry attempts"`
-	InitialBackoff         time.Duration `env:"INITIAL_BACKOFF, default=1s" description:"Initial backoff duration for exponential backoff retry"`
-	EnableReconnect        bool          `env:"ENABLE_RECONNECT, default=true" description:"Enable automatic reconnection for failed servers"`
-	ReconnectInterval      time.Duration `env:"RECONNECT_INTERVAL, default=30s" description:"Interval between reconnection attempts"`
-	PollingEnable          bool          `env:"POLLING_ENABLE, default=true" description:"Enable health check polling"`
-	PollingInterval        time.Duration `env:"POLLING_INTERVAL, default=30s" description:"Interval between health check polling requests"`
-	PollingTimeout         time.Duration `env:"POLLING_TIMEOUT, default=5s" description:"Timeout for individual health check requests"`
-	DisableHealthcheckLogs bool          `env:"DISABLE_HEALTHCHECK_LOGS, default=true" description:"Disable health check log messages to reduce noise"`
+	Enable                   bool          `env:"ENABLE, default=false" description:"Enable MCP"`
+	Expose                   bool          `env:"EXPOSE, default=false" description:"Expose MCP tools endpoint"`
+	Servers                  string        `env:"SERVERS" description:"List of MCP servers"`
+	ServersConfigPath        string        `env:"SERVERS_CONFIG_PATH" description:"Path to a YAML file declaring the authentication and TLS settings of MCP servers, keyed by their MCP_SERVERS URL (static headers, a bearer token or OAuth2 client credentials, their own CA bundle and client certificate), and the stdio MCP servers the gateway runs as child processes (command, args, env). Read at startup"`
+	IncludeTools             string        `env:"INCLUDE_TOOLS" description:"Comma-separated list of MCP tool names to inject. If empty, all tools are injected. Takes precedence over MCP_EXCLUDE_TOOLS"`
+	ExcludeTools             string        `env:"EXCLUDE_TOOLS" description:"Comma-separated list of MCP tool names to skip injecting. If empty, no tools are excluded. Takes lower precedence than MCP_INCLUDE_TOOLS"`
+	ToolsAllow               string        `env:"TOOLS_ALLOW" description:"Comma-separated glob patterns (e.g. read_*,search) of MCP tools that may be exposed to LLMs and executed. If empty, all tools are allowed"`
+	ToolsDeny                string        `env:"TOOLS_DENY" description:"Comma-separated glob patterns of MCP tools that are never exposed to LLMs or executed, even if they match MCP_TOOLS_ALLOW"`
+	ToolTimeouts             string        `env:"TOOL_TIMEOUTS" description:"Comma-separated per-tool execution timeouts as glob=duration pairs (e.g. search_*=30s,*=10s). The first matching pattern wins; tools without a match have no timeout beyond MCP_CLIENT_TIMEOUT"`
+	ToolRetries              string        `env:"TOOL_RETRIES" description:"Comma-separated per-tool retry counts as glob=count pairs (e.g. search_*=2). Failed calls are retried with exponential backoff starting at MCP_INITIAL_BACKOFF. The first matching pattern wins; tools without a match are not retried"`
+	ToolConcurrency          int           `env:"TOOL_CONCURRENCY, default=4" description:"Maximum number of tool calls from a single model response that are executed concurrently. Results are returned in call order; set to 1 to execute tool calls sequentially. Per-call timeouts are set with MCP_TOOL_TIMEOUTS"`
+	ToolResultMaxBytes       int           `env:"TOOL_RESULT_MAX_BYTES, default=0" description:"Maximum size in bytes of a tool result added to the conversation by the MCP agent. Larger results are shortened per MCP_TOOL_RESULT_TRUNCATION with a note of what was cut. 0 keeps results whole"`
+	ToolResultTruncation     string        `env:"TOOL_RESULT_TRUNCATION, default=head" description:"How tool results larger than MCP_TOOL_RESULT_MAX_BYTES are shortened: head keeps their beginning, tail their end, middle both ends, and summary both ends around a summary of the middle written by MCP_TOOL_RESULT_SUMMARY_MODEL (middle when the summary fails)"`
+	ToolResultSummaryModel   string        `env:"TOOL_RESULT_SUMMARY_MODEL" description:"Model, in provider/model form, summarizing the middle of tool results when MCP_TOOL_RESULT_TRUNCATION is summary. A cheap, fast model is enough"`
+	ToolProgress             bool          `env:"TOOL_PROGRESS, default=false" description:"Send the progress of the tool calls run by the MCP agent in streamed responses, as chat completion chunks with an empty assistant delta carrying a tool_progress extension field, so OpenAI-compatible clients keep parsing the stream"`
+	ToolPaths                string        `env:"TOOL_PATHS, default=/v1/chat/completions" description:"Comma-separated list of request paths that take part in MCP tool orchestration. Each path must accept an OpenAI chat completions request body. Non-chat paths such as /v1/embeddings are always skipped"`
+	ToolFilterEnable         bool          `env:"TOOL_FILTER_ENABLE, default=true" description:"Only attach the MCP tools most relevant to the last user message when more than MCP_TOOL_FILTER_TOP_K tools are available. Tools already called in the conversation are always kept"`
+	ToolFilterTopK           int           `env:"TOOL_FILTER_TOP_K, default=16" description:"Maximum number of MCP tools attached to a request when MCP_TOOL_FILTER_ENABLE is true"`
+	ToolFilterMethod         string        `env:"TOOL_FILTER_METHOD, default=keyword" description:"How MCP tools are ranked against the last user message: keyword (term overlap with the tool name, description and parameters) or embedding (cosine similarity of embeddings from MCP_TOOL_FILTER_EMBEDDING_MODEL, falling back to keyword when the embedding request fails)"`
+	ToolFilterEmbeddingModel string        `env:"TOOL_FILTER_EMBEDDING_MODEL" description:"Embedding model, in provider/model form, used by MCP_TOOL_FILTER_METHOD=embedding. The provider must serve an OpenAI-compatible /embeddings endpoint"`
+	SamplingEnable           bool          `env:"SAMPLING_ENABLE, default=false" description:"Let MCP servers reached over HTTP request completions from the gateway with sampling/createMessage. Completions are served by the provider registry and subject to ALLOWED_MODELS, DISALLOWED_MODELS and the tenant of the request that called the tool"`
+	SamplingModel            string        `env:"SAMPLING_MODEL" description:"Model, in provider/model form, serving sampling requests whose model hints name no allowed provider/model. Sampling requests are refused when no model can be chosen"`
+	ClientTimeout            time.Duration `env:"CLIENT_TIMEOUT, default=5s" description:"MCP client HTTP timeout"`
+	DialTimeout              time.Duration `env:"DIAL_TIMEOUT, default=3s" description:"MCP client dial timeout"`
+	TlsHandshakeTimeout      time.Duration `env:"TLS_HANDSHAKE_TIMEOUT, default=3s" description:"MCP client TLS handshake timeout"`
+	ResponseHeaderTimeout    time.Duration `env:"RESPONSE_HEADER_TIMEOUT, default=3s" description:"MCP client response header timeout"`
+	ExpectContinueTimeout    time.Duration `env:"EXPECT_CONTINUE_TIMEOUT, default=1s" description:"MCP client expect continue timeout"`
+	RequestTimeout           time.Duration `env:"REQUEST_TIMEOUT, default=5s" description:"MCP client request timeout for initialize and tool calls"`
+	MaxRetries               int           `env:"MAX_RETRIES, default=3" description:"Maximum number of connection retry attempts"`
+	RetryInterval            time.Duration `env:"RETRY_INTERVAL, default=5s" description:"Interval between connection retry attempts"`
+	InitialBackoff           time.Duration `env:"INITIAL_BACKOFF, default=1s" description:"Initial backoff duration for exponential backoff retry"`
+	EnableReconnect          bool          `env:"ENABLE_RECONNECT, default=true" description:"Enable automatic reconnection for failed servers"`
+	ReconnectInterval        time.Duration `env:"RECONNECT_INTERVAL, default=30s" description:"Interval between reconnection attempts"`
+	PollingEnable            bool          `env:"POLLING_ENABLE, default=true" description:"Enable health check polling"`
+	PollingInterval          time.Duration `env:"POLLING_INTERVAL, default=30s" description:"Interval between health check polling requests"`
+	PollingTimeout           time.Duration `env:"POLLING_TIMEOUT, default=5s" description:"Timeout for individual health check requests"`
+	DisableHealthcheckLogs   bool          `env:"DISABLE_HEALTHCHECK_LOGS, default=true" description:"Disable health check log messages to reduce noise"`
 }
 
 // Authentication configuration
@@ -81,23 +207,46 @@ type AuthConfig struct {
 	OidcIssuer       string `env:"OIDC_ISSUER, default=http://keycloak:8080/realms/inference-gateway-realm" description:"OIDC issuer URL"`
 	OidcClientId     string `env:"OIDC_CLIENT_ID, default=inference-gateway-client" type:"secret" description:"OIDC client ID"`
 	OidcClientSecret string `env:"OIDC_CLIENT_SECRET" type:"secret" description:"OIDC client secret"`
+	TokenCookie      string `env:"TOKEN_COOKIE" description:"Name of a cookie to read the bearer token from when the Authorization header is absent (for browser clients). If empty, cookies are not consulted"`
+	TokenBodyField   string `env:"TOKEN_BODY_FIELD" description:"Top-level JSON body field to read the bearer token from when neither the Authorization header nor the token cookie is present. The field is removed before the request is forwarded. If empty, the body is not consulted"`
+	AdminToken       string `env:"ADMIN_TOKEN" type:"secret" description:"Token required in the X-Admin-Token header to call the /admin endpoints. The admin API is disabled when empty"`
 }
 
 // Server configuration
 type ServerConfig struct {
-	Host         string        `env:"HOST, default=0.0.0.0" description:"Server host"`
-	Port         string        `env:"PORT, default=8080" description:"Server port"`
-	ReadTimeout  time.Duration `env:"READ_TIMEOUT, default=30s" description:"Read timeout"`
-	WriteTimeout time.Duration `env:"WRITE_TIMEOUT, default=30s" description:"Write timeout"`
-	IdleTimeout  time.Duration `env:"IDLE_TIMEOUT, default=120s" description:"Idle timeout"`
-	TlsCertPath  string        `env:"TLS_CERT_PATH" description:"TLS certificate path"`
-	TlsKeyPath   string        `env:"TLS_KEY_PATH" description:"TLS key path"`
+	Host                      string        `env:"HOST, default=0.0.0.0" description:"Server host"`
+	Port                      string        `env:"PORT, default=8080" description:"Server port"`
+	ReadTimeout               time.Duration `env:"READ_TIMEOUT, default=30s" description:"Read timeout"`
+	WriteTimeout              time.Duration `env:"WRITE_TIMEOUT, default=30s" description:"Write timeout"`
+	IdleTimeout               time.Duration `env:"IDLE_TIMEOUT, default=120s" description:"Idle timeout"`
+	DrainTimeout              time.Duration `env:"DRAIN_TIMEOUT, default=30s" description:"Maximum time to wait for in-flight requests, including streams, and running agent jobs to finish after SIGTERM. New requests are rejected with 503 and /health/ready fails while draining"`
+	MaxConnections            int           `env:"MAX_CONNECTIONS, default=0" description:"Maximum number of concurrently open client connections. Connections beyond it wait to be accepted until others close. Set to 0 to disable"`
+	MaxConnectionsPerIp       int           `env:"MAX_CONNECTIONS_PER_IP, default=0" description:"Maximum number of concurrently open connections per peer IP address; connections beyond it are closed right away. Behind a load balancer the peer is the balancer, so leave it to the balancer there. Set to 0 to disable"`
+	H2cEnable                 bool          `env:"H2C_ENABLE, default=false" description:"Serve HTTP/2 over cleartext connections (h2c with prior knowledge) in addition to HTTP/1.1, for gRPC-style clients and proxies that speak HTTP/2 to the gateway without TLS"`
+	Http2MaxConcurrentStreams int           `env:"HTTP2_MAX_CONCURRENT_STREAMS, default=0" description:"Maximum number of concurrent HTTP/2 streams (requests) per connection. Uses the Go default of 250 when 0"`
+	TlsCertPath               string        `env:"TLS_CERT_PATH" description:"TLS certificate path"`
+	TlsKeyPath                string        `env:"TLS_KEY_PATH" description:"TLS key path"`
+	TlsMinVersion             string        `env:"TLS_MIN_VERSION, default=TLS12" description:"Minimum TLS version accepted when TLS is enabled: TLS10, TLS11, TLS12 or TLS13"`
+	TlsCipherSuites           string        `env:"TLS_CIPHER_SUITES" description:"Comma-separated list of TLS 1.0-1.2 cipher suites accepted when TLS is enabled, by their Go names (e.g. TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256). the secure defaults of Go are used when empty; TLS 1.3 suites are not configurable"`
+	TrustedProxies            string        `env:"TRUSTED_PROXIES" description:"Comma-separated list of proxy IPs or CIDRs (e.g. 10.0.0.0/8) whose client IP header is trusted. If empty, no proxy is trusted and the connection peer address is used as the client IP"`
+	ClientIpHeader            string        `env:"CLIENT_IP_HEADER, default=X-Forwarded-For" description:"Header to derive the real client IP from when the request comes from a trusted proxy. One of X-Forwarded-For, X-Real-IP or CF-Connecting-IP"`
+	MaxHeaderBytes            int           `env:"MAX_HEADER_BYTES, default=1048576" description:"Maximum size in bytes of the request line and headers. Raise it when OIDC tokens are large; requests above the limit are rejected with 431"`
+	MaxRequestBytes           int           `env:"MAX_REQUEST_BYTES, default=10485760" description:"Maximum request body size in bytes. Larger requests are rejected with 413. Set to 0 to disable"`
+	MaxMessages               int           `env:"MAX_MESSAGES, default=0" description:"Maximum number of messages per chat completion request. Set to 0 to disable"`
+	MaxPromptChars            int           `env:"MAX_PROMPT_CHARS, default=0" description:"Maximum total characters of text content across all messages of a chat completion request. Set to 0 to disable"`
+	MaxPromptTokens           int           `env:"MAX_PROMPT_TOKENS, default=0" description:"Maximum prompt tokens of a chat completion request, counted with the model tokenizer (see TOKENIZER_ENCODINGS_DIR) including tool definitions and message framing. Set to 0 to disable"`
+	MaxTokensLimits           string        `env:"MAX_TOKENS_LIMITS" description:"Comma-separated list of model=limit pairs capping max_tokens and max_completion_tokens per model (e.g. openai/gpt-4o=4096,*=8192). Requests above the cap are rejected with 400"`
 }
 
 // Routing configuration
 type RoutingConfig struct {
-	Enabled    bool   `env:"ENABLED, default=false" description:"Enable gateway-native model routing: logical model aliases backed by a pool of upstream provider deployments, selected round-robin per replica. Opt-in; when disabled, direct provider/model routing is unchanged"`
-	ConfigPath string `env:"CONFIG_PATH" description:"Path to a YAML file mapping logical model aliases to their upstream deployment pools. Required when ROUTING_ENABLED is true"`
+	Enabled             bool   `env:"ENABLED, default=false" description:"Enable gateway-native model routing: logical model aliases backed by a pool of upstream provider deployments, selected round-robin per replica or split by weight. Opt-in; when disabled, direct provider/model routing is unchanged"`
+	ConfigPath          string `env:"CONFIG_PATH" description:"Path to a YAML file mapping logical model aliases to their upstream deployment pools. Required when ROUTING_ENABLED is true"`
+	SemanticEnabled     bool   `env:"SEMANTIC_ENABLED, default=false" description:"Enable semantic routing: the last user message is embedded and matched against the route descriptions of ROUTING_SEMANTIC_CONFIG_PATH to pick the model. Requests fall back to the requested model when no route is similar enough"`
+	SemanticConfigPath  string `env:"SEMANTIC_CONFIG_PATH" description:"Path to a YAML file declaring the embedding model, the similarity threshold and the semantic routes. Required when ROUTING_SEMANTIC_ENABLED is true"`
+	Rules               string `env:"RULES" description:"Comma-separated rules routing the models requested without a provider prefix, as pattern=provider pairs tried in order. A pattern ending in * matches the model names starting with it (e.g. gpt-*=openai,claude-*=anthropic), one ending in /* also strips that prefix from the model sent to the provider (e.g. oai/*=openai), one wrapped in slashes is a regular expression without commas (e.g. /^llama-3[.][0-9]/=groq), and any other is an exact model name. The providers of CUSTOM_PROVIDERS may be named as well"`
+	Downgrades          string `env:"DOWNGRADES" description:"Comma-separated cheaper equivalents of models, as provider/model=provider/model pairs (e.g. openai/gpt-4o=openai/gpt-4o-mini,anthropic/claude-sonnet-4-5=groq/llama-3.3-70b-versatile). With USAGE_ENABLE, chat completions for a model are rerouted to its equivalent once the tenant spent the warn_percent of its budget or the provider spends more than its ROUTING_DOWNGRADE_SPEND_RATES threshold, unless they send X-Allow-Downgrade: false"`
+	DowngradeSpendRates string `env:"DOWNGRADE_SPEND_RATES" description:"Comma-separated spend rates in USD per hour, as provider=rate pairs (e.g. openai=50), above which the requests for the models of the provider are rerouted to their ROUTING_DOWNGRADES equivalent. The rate is the estimated spend of the gateway on the provider over the last hour"`
 }
 
 // Load configuration
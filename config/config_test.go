@@ -27,19 +27,82 @@ func defaultProviders(overrides map[types.Provider]func(*registry.ProviderConfig
 
 func defaultConfig(mutate func(*config.Config)) config.Config {
 	cfg := config.Config{
-		Environment:               "production",
-		AllowedModels:             "",
-		DebugContentTruncateWords: 10,
-		DebugMaxMessages:          100,
+		Environment:                       "production",
+		AllowedModels:                     "",
+		ModelPolicyRolesClaim:             "roles",
+		ProxyAllowedPaths:                 "*/chat/completions,*/models,*/embeddings",
+		OllamaKeepAliveInterval:           4 * time.Minute,
+		DebugContentTruncateWords:         10,
+		DebugMaxMessages:                  100,
+		LogRedaction:                      "headers,keys",
+		LogSamplingInitial:                100,
+		LogSamplingThereafter:             100,
+		StructuredOutputEmulatedProviders: "anthropic",
+		StructuredOutputMaxRetries:        2,
+		StreamBroadcastReplaySize:         1024,
+		StreamResumeBufferSize:            4096,
+		StreamResumeTtl:                   2 * time.Minute,
+		PromptCacheMinTokens:              1024,
+		ThinkTagMode:                      "off",
+		AdminRecentErrors:                 100,
+		ShadowPercent:                     10,
+		ShadowConcurrency:                 8,
+		ShadowTimeout:                     time.Minute,
+		EvalPercent:                       5,
+		EvalConcurrency:                   4,
+		EvalLatencyThreshold:              30 * time.Second,
+		EvalJudgeTimeout:                  30 * time.Second,
+		SafetyModerationModel:             "omni-moderation-latest",
+		TenantHeader:                      "X-Tenant-ID",
+		BatchMaxItems:                     100,
+		BatchConcurrency:                  8,
+		BatchesWorkers:                    2,
+		BatchesDir:                        "data/batches",
+		ThreadsWorkers:                    4,
+		ThreadsDir:                        "data/threads",
+		AgentJobsWorkers:                  4,
+		AgentJobsDir:                      "data/agent-jobs",
+		AgentJobsMaxIterations:            100,
+		AgentMaxIterations:                10,
+		BuiltinToolsFetchTimeout:          10 * time.Second,
+		BuiltinToolsFetchMaxBytes:         1048576,
+		QueueDir:                          "data/queue",
+		QueueWorkers:                      4,
+		QueueMaxSize:                      1000,
+		QueueWaitTimeout:                  30 * time.Second,
+		QueueRetryInterval:                1 * time.Second,
+		QueueTtl:                          10 * time.Minute,
+		FilesBackend:                      "disk",
+		FilesDir:                          "data/files",
+		FilesMaxUploadBytes:               536870912,
+		ConfigWatchInterval:               10 * time.Second,
+		ProviderSecretsRefreshInterval:    5 * time.Minute,
+		ProviderBackoffMax:                time.Minute,
+		ProviderHealthWindow:              5 * time.Minute,
+		ProviderHealthMinFailures:         3,
+		ProviderRetryBackoff:              500 * time.Millisecond,
+		ModelsCacheTtl:                    5 * time.Minute,
+		ModelsCacheMaxStale:               time.Hour,
+		ModelsProviderTimeout:             10 * time.Second,
 		Telemetry: &config.TelemetryConfig{
-			Enable:              false,
-			MetricsPort:         "9464",
-			TracingOtlpEndpoint: "http://localhost:4318",
+			Enable:                  false,
+			MetricsPort:             "9464",
+			TracingExporter:         "otlp",
+			TracingOtlpEndpoint:     "http://localhost:4318",
+			LangfuseHost:            "https://cloud.langfuse.com",
+			CaptureContentPercent:   100,
+			CaptureContentRedaction: "keys",
 		},
 		MCP: &config.MCPConfig{
 			Enable:                 false,
 			Expose:                 false,
 			Servers:                "",
+			ToolConcurrency:        4,
+			ToolResultTruncation:   "head",
+			ToolPaths:              "/v1/chat/completions",
+			ToolFilterEnable:       true,
+			ToolFilterTopK:         16,
+			ToolFilterMethod:       "keyword",
 			ClientTimeout:          5 * time.Second,
 			DialTimeout:            3 * time.Second,
 			TlsHandshakeTimeout:    3 * time.Second,
@@ -63,11 +126,16 @@ func defaultConfig(mutate func(*config.Config)) config.Config {
 			OidcClientSecret: "",
 		},
 		Server: &config.ServerConfig{
-			Host:         "0.0.0.0",
-			Port:         "8080",
-			ReadTimeout:  30 * time.Second,
-			WriteTimeout: 30 * time.Second,
-			IdleTimeout:  120 * time.Second,
+			Host:            "0.0.0.0",
+			Port:            "8080",
+			ReadTimeout:     30 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     120 * time.Second,
+			DrainTimeout:    30 * time.Second,
+			ClientIpHeader:  "X-Forwarded-For",
+			MaxHeaderBytes:  1048576,
+			MaxRequestBytes: 10485760,
+			TlsMinVersion:   "TLS12",
 		},
 		Routing: &config.RoutingConfig{
 			Enabled:    false,
@@ -0,0 +1,239 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// documentType is the type of the config file keys read as a whole by their
+// component, such as routing.models
+const documentType = "document"
+
+// fileSetting is a setting of a YAML or JSON config file
+type fileSetting struct {
+	// Env is the variable the setting sets
+	Env string
+	// Type is the type of the value: string, bool, int, time.Duration or
+	// document
+	Type string
+	// Provider, when set, makes the value the provider=value pair of Provider
+	// in the comma-separated list of Env
+	Provider string
+}
+
+// IsStructuredFile reports whether path names a YAML or JSON config file
+// rather than an env file
+func IsStructuredFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// ReadConfigFile reads the variables set by the config file at path, a YAML
+// or JSON file by its extension and an env file otherwise
+func ReadConfigFile(path string) (map[string]string, error) {
+	if IsStructuredFile(path) {
+		return ReadStructuredFile(path)
+	}
+	return ReadEnvFile(path)
+}
+
+// ReadStructuredFile parses a YAML or JSON config file into the variables its
+// settings set. General settings are at the top level, the others under
+// their section (server.port, telemetry.enable, providers.openai.api_key,
+// ...). Lists and maps given for string settings are joined into the
+// comma-separated value and key=value forms of their env vars. Unknown keys
+// and values not of the type of their setting are rejected.
+func ReadStructuredFile(path string) (map[string]string, error) {
+	root, err := readFileRoot(path)
+	if err != nil || root == nil {
+		return map[string]string{}, err
+	}
+
+	vars := make(map[string]string)
+	pairs := make(map[string][]string)
+	if err := readFileSection(path, root, "", vars, pairs); err != nil {
+		return nil, err
+	}
+	for env, list := range pairs {
+		if vars[env] != "" {
+			list = append([]string{vars[env]}, list...)
+		}
+		vars[env] = strings.Join(list, ",")
+	}
+	return vars, nil
+}
+
+// ReadFileSection returns the value of key, dotted by section, in the YAML or
+// JSON config file at path encoded as YAML, or nil when the file does not set
+// it
+func ReadFileSection(path, key string) ([]byte, error) {
+	node, err := readFileRoot(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, part := range strings.Split(key, ".") {
+		if node == nil {
+			return nil, nil
+		}
+		node = mappingValue(node, part)
+	}
+	if node == nil {
+		return nil, nil
+	}
+	return yaml.Marshal(node)
+}
+
+// readFileRoot parses the config file at path, returning nil for an empty
+// file
+func readFileRoot(path string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s:%d: expected a mapping of settings", path, root.Line)
+	}
+	return root, nil
+}
+
+// mappingValue returns the value of key in the mapping node, nil when it is
+// not a mapping or lacks key
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// readFileSection adds the variables set by the settings of the mapping node,
+// whose keys are prefixed by prefix, to vars, and the provider pairs to pairs
+func readFileSection(path string, node *yaml.Node, prefix string, vars map[string]string, pairs map[string][]string) error {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		key := prefix + keyNode.Value
+
+		setting, ok := fileSettings[key]
+		if !ok {
+			if valueNode.Kind == yaml.MappingNode && isFileSection(key) {
+				if err := readFileSection(path, valueNode, key+".", vars, pairs); err != nil {
+					return err
+				}
+				continue
+			}
+			return fmt.Errorf("%s:%d: unknown setting %s", path, keyNode.Line, key)
+		}
+		if setting.Type == documentType {
+			if valueNode.Kind != yaml.MappingNode {
+				return fmt.Errorf("%s:%d: %s: expected a mapping", path, valueNode.Line, key)
+			}
+			continue
+		}
+
+		value, err := settingValue(setting, valueNode)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %s: %w", path, valueNode.Line, key, err)
+		}
+		if setting.Provider != "" {
+			pairs[setting.Env] = append(pairs[setting.Env], setting.Provider+"="+value)
+			continue
+		}
+		vars[setting.Env] = value
+	}
+	return nil
+}
+
+// isFileSection reports whether key is a section of the config file
+func isFileSection(key string) bool {
+	for path := range fileSettings {
+		if strings.HasPrefix(path, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// settingValue returns the env var value of node for setting, checking it
+// against the type of the setting
+func settingValue(setting fileSetting, node *yaml.Node) (string, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		value := node.Value
+		if node.Tag == "!!null" {
+			value = ""
+		}
+		return value, checkValue(setting.Type, value)
+	case yaml.SequenceNode, yaml.MappingNode:
+		if setting.Type != "string" || setting.Provider != "" {
+			return "", fmt.Errorf("expected a single %s value", setting.Type)
+		}
+		return joinValues(node)
+	}
+	return "", errors.New("unsupported value")
+}
+
+// checkValue checks that value parses as typ
+func checkValue(typ, value string) error {
+	if value == "" {
+		return nil
+	}
+	var err error
+	switch typ {
+	case "bool":
+		_, err = strconv.ParseBool(value)
+	case "int":
+		_, err = strconv.Atoi(value)
+	case "time.Duration":
+		_, err = time.ParseDuration(value)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s %q", typ, value)
+	}
+	return nil
+}
+
+// joinValues joins the scalars of a sequence as a comma-separated list, and
+// those of a mapping as a comma-separated list of key=value pairs in file
+// order
+func joinValues(node *yaml.Node) (string, error) {
+	var values []string
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return "", errors.New("expected a list of scalars")
+			}
+			values = append(values, item.Value)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i+1].Kind != yaml.ScalarNode {
+				return "", errors.New("expected a mapping of scalars")
+			}
+			values = append(values, node.Content[i].Value+"="+node.Content[i+1].Value)
+		}
+	}
+	return strings.Join(values, ","), nil
+}
@@ -0,0 +1,125 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/inference-gateway/inference-gateway/config"
+	"github.com/inference-gateway/inference-gateway/providers/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestReadStructuredFile(t *testing.T) {
+	path := writeConfigFile(t, "gateway.yaml", `
+environment: development
+allowed_models:
+  - openai/gpt-4o
+  - anthropic/*
+shadow_models:
+  openai/gpt-4o: groq/llama-3.3-70b-versatile
+  "*": openai/gpt-4o-mini
+provider_timeouts: ollama=15m
+server:
+  port: 9090
+  drain_timeout: 1m
+telemetry:
+  enable: true
+providers:
+  openai:
+    api_key: sk-file
+    timeout: 30s
+    max_retries: 2
+  ollama:
+    api_url: http://localhost:11434/v1
+routing:
+  enabled: true
+  models:
+    fast:
+      deployments:
+        - provider: openai
+          model: gpt-4o-mini
+        - provider: groq
+          model: llama-3.1-8b-instant
+`)
+	vars, err := config.ReadStructuredFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"ENVIRONMENT":          "development",
+		"ALLOWED_MODELS":       "openai/gpt-4o,anthropic/*",
+		"SHADOW_MODELS":        "openai/gpt-4o=groq/llama-3.3-70b-versatile,*=openai/gpt-4o-mini",
+		"PROVIDER_TIMEOUTS":    "ollama=15m,openai=30s",
+		"PROVIDER_RETRIES":     "openai=2",
+		"SERVER_PORT":          "9090",
+		"SERVER_DRAIN_TIMEOUT": "1m",
+		"TELEMETRY_ENABLE":     "true",
+		"OPENAI_API_KEY":       "sk-file",
+		"OLLAMA_API_URL":       "http://localhost:11434/v1",
+		"ROUTING_ENABLED":      "true",
+	}, vars)
+
+	routing, err := config.ReadFileSection(path, "routing")
+	require.NoError(t, err)
+	assert.Contains(t, string(routing), "model: gpt-4o-mini")
+	missing, err := config.ReadFileSection(path, "mcp")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestReadStructuredFile_JSON(t *testing.T) {
+	vars, err := config.ReadConfigFile(writeConfigFile(t, "gateway.json", `{"server": {"port": "8081"}, "mcp": {"enable": false}}`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"SERVER_PORT": "8081", "MCP_ENABLE": "false"}, vars)
+}
+
+func TestReadStructuredFile_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{name: "unknown setting", content: "server:\n  prot: 8080\n", err: "gateway.yaml:2: unknown setting server.prot"},
+		{name: "unknown section", content: "database:\n  url: x\n", err: "unknown setting database"},
+		{name: "unknown provider", content: "providers:\n  acme:\n    api_key: x\n", err: "unknown setting providers.acme"},
+		{name: "invalid bool", content: "telemetry:\n  enable: maybe\n", err: `gateway.yaml:2: telemetry.enable: invalid bool "maybe"`},
+		{name: "invalid duration", content: "server:\n  read_timeout: 30\n", err: `server.read_timeout: invalid time.Duration "30"`},
+		{name: "list for an int", content: "batch_max_items: [1, 2]\n", err: "batch_max_items: expected a single int value"},
+		{name: "scalar routing models", content: "routing:\n  models: fast\n", err: "routing.models: expected a mapping"},
+		{name: "not a mapping", content: "- server\n", err: "expected a mapping of settings"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := config.ReadStructuredFile(writeConfigFile(t, "gateway.yaml", tt.content))
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestLoadFromEnvironment_EnvironmentOverridesStructuredFile(t *testing.T) {
+	t.Setenv("ALLOWED_MODELS", "openai/gpt-3.5-turbo")
+	t.Setenv(config.ConfigFileEnv, writeConfigFile(t, "gateway.yml", `
+allowed_models: [openai/gpt-4o]
+disallowed_models: [groq/llama]
+server:
+  read_timeout: 1m
+providers:
+  openai:
+    api_key: sk-file
+`))
+
+	cfg, err := config.LoadFromEnvironment()
+	require.NoError(t, err)
+	assert.Equal(t, "openai/gpt-3.5-turbo", cfg.AllowedModels)
+	assert.Equal(t, "groq/llama", cfg.DisallowedModels)
+	assert.Equal(t, time.Minute, cfg.Server.ReadTimeout)
+	assert.Equal(t, "sk-file", cfg.Providers[constants.OpenaiID].Token)
+}
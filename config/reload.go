@@ -0,0 +1,177 @@
+package config
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	envconfig "github.com/sethvargo/go-envconfig"
+
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+)
+
+// ConfigFileEnv names the config file layered over the process environment
+const ConfigFileEnv = "CONFIG_FILE"
+
+// Lookuper returns the source the configuration is loaded from: the process
+// environment combined with the variables of the CONFIG_FILE when it is set.
+// The variables of an env file override the environment, while the
+// environment overrides the settings of a YAML or JSON file. The file is read
+// on every call so reloads pick up its changes.
+func Lookuper() (envconfig.Lookuper, error) {
+	path := os.Getenv(ConfigFileEnv)
+	if path == "" {
+		return envconfig.OsLookuper(), nil
+	}
+	vars, err := ReadConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if IsStructuredFile(path) {
+		return envconfig.MultiLookuper(envconfig.OsLookuper(), envconfig.MapLookuper(vars)), nil
+	}
+	return envconfig.MultiLookuper(envconfig.MapLookuper(vars), envconfig.OsLookuper()), nil
+}
+
+// LoadFromEnvironment loads a fresh configuration from Lookuper, with the
+// custom providers of CUSTOM_PROVIDERS next to the built-in ones
+func LoadFromEnvironment() (Config, error) {
+	lookuper, err := Lookuper()
+	if err != nil {
+		return Config{}, err
+	}
+	var cfg Config
+	cfg, err = cfg.Load(lookuper)
+	if err != nil {
+		return Config{}, err
+	}
+	custom, err := registry.CustomProviders(cfg.CustomProviders, lookuper.Lookup)
+	if err != nil {
+		return Config{}, err
+	}
+	maps.Copy(cfg.Providers, custom)
+	return cfg, nil
+}
+
+// ReadEnvFile parses an env file of KEY=VALUE lines. Blank lines and lines
+// starting with # are skipped, an "export " prefix is allowed and values may
+// be wrapped in single or double quotes.
+func ReadEnvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, n)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		vars[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// Reloader re-reads the configuration at runtime and hands it to the
+// components that cache config values
+type Reloader struct {
+	mu          sync.Mutex
+	load        func() (Config, error)
+	current     Config
+	subscribers []func(Config) error
+}
+
+// NewReloader creates a reloader starting from cfg that reads new
+// configurations with load
+func NewReloader(cfg Config, load func() (Config, error)) *Reloader {
+	return &Reloader{
+		load:    load,
+		current: cfg,
+	}
+}
+
+// Subscribe registers fn to receive every reloaded configuration. A
+// subscriber rejecting a configuration must keep its previous state.
+func (r *Reloader) Subscribe(fn func(Config) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers = append(r.subscribers, fn)
+}
+
+// Current returns the most recently loaded configuration
+func (r *Reloader) Current() Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// Reload loads the configuration again and passes it to every subscriber in
+// subscription order. A failing subscriber does not stop the others; their
+// errors are joined.
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cfg, err := r.load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	r.current = cfg
+
+	var errs []error
+	for _, fn := range r.subscribers {
+		if err := fn(cfg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Watch polls the modification time of path every interval and reloads when
+// it changes, until ctx is done. done is called with the result of every
+// reload.
+func (r *Reloader) Watch(ctx context.Context, path string, interval time.Duration, done func(error)) {
+	modTime := func() time.Time {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}
+		}
+		return info.ModTime()
+	}
+
+	last := modTime()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if current := modTime(); !current.Equal(last) {
+				last = current
+				done(r.Reload())
+			}
+		}
+	}
+}
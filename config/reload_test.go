@@ -0,0 +1,119 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/inference-gateway/inference-gateway/config"
+	"github.com/inference-gateway/inference-gateway/providers/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeEnvFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "gateway.env")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestReadEnvFile(t *testing.T) {
+	path := writeEnvFile(t, `
+# comment
+ALLOWED_MODELS=openai/gpt-4o
+export OPENAI_API_KEY="sk-test"
+MCP_SERVERS='http://a,http://b'
+EMPTY=
+`)
+	vars, err := config.ReadEnvFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"ALLOWED_MODELS": "openai/gpt-4o",
+		"OPENAI_API_KEY": "sk-test",
+		"MCP_SERVERS":    "http://a,http://b",
+		"EMPTY":          "",
+	}, vars)
+
+	_, err = config.ReadEnvFile(writeEnvFile(t, "NOT A PAIR\n"))
+	assert.ErrorContains(t, err, ":1: expected KEY=VALUE")
+}
+
+func TestLoadFromEnvironment_ConfigFileOverridesEnvironment(t *testing.T) {
+	t.Setenv("ALLOWED_MODELS", "openai/gpt-3.5-turbo")
+	t.Setenv("DISALLOWED_MODELS", "groq/llama")
+	t.Setenv(config.ConfigFileEnv, writeEnvFile(t, "ALLOWED_MODELS=openai/gpt-4o\nOPENAI_API_KEY=sk-file\n"))
+
+	cfg, err := config.LoadFromEnvironment()
+	require.NoError(t, err)
+	assert.Equal(t, "openai/gpt-4o", cfg.AllowedModels)
+	assert.Equal(t, "groq/llama", cfg.DisallowedModels)
+	assert.Equal(t, "sk-file", cfg.Providers[constants.OpenaiID].Token)
+}
+
+func TestLoadFromEnvironment_CustomProviders(t *testing.T) {
+	t.Setenv("CUSTOM_PROVIDERS", "vllm, lm-studio,together")
+	t.Setenv("VLLM_API_URL", "http://vllm:8000/v1/")
+	t.Setenv("LM_STUDIO_API_URL", "http://localhost:1234/v1")
+	t.Setenv("TOGETHER_API_URL", "https://api.together.xyz/v1")
+	t.Setenv("TOGETHER_API_KEY", "tg-key")
+	t.Setenv("VLLM_API_KEY", "vllm-key")
+	t.Setenv("VLLM_AUTH_HEADER", "X-Vllm-Key")
+
+	cfg, err := config.LoadFromEnvironment()
+	require.NoError(t, err)
+
+	vllm := cfg.Providers["vllm"]
+	require.NotNil(t, vllm)
+	assert.Equal(t, "http://vllm:8000/v1", vllm.URL)
+	assert.Equal(t, constants.AuthTypeNone, vllm.AuthType)
+	assert.Equal(t, map[string][]string{"X-Vllm-Key": {"vllm-key"}}, vllm.ExtraHeaders)
+	assert.Equal(t, constants.AuthTypeNone, cfg.Providers["lm-studio"].AuthType)
+	assert.Equal(t, constants.AuthTypeBearer, cfg.Providers["together"].AuthType)
+	assert.Equal(t, "tg-key", cfg.Providers["together"].Token)
+	assert.Equal(t, "/chat/completions", cfg.Providers["together"].Endpoints.Chat)
+	assert.NotNil(t, cfg.Providers[constants.OpenaiID])
+
+	for name, value := range map[string]string{
+		"missing url":  "localai",
+		"built-in id":  "openai",
+		"invalid id":   "Local AI",
+		"listed twice": "together,together",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("CUSTOM_PROVIDERS", value)
+			_, err := config.LoadFromEnvironment()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestReloader(t *testing.T) {
+	loads := 0
+	reloader := config.NewReloader(config.Config{AllowedModels: "a"}, func() (config.Config, error) {
+		loads++
+		if loads == 2 {
+			return config.Config{}, errors.New("broken file")
+		}
+		return config.Config{AllowedModels: "b"}, nil
+	})
+
+	var seen []string
+	reloader.Subscribe(func(cfg config.Config) error {
+		seen = append(seen, cfg.AllowedModels)
+		return errors.New("rejected")
+	})
+	reloader.Subscribe(func(cfg config.Config) error {
+		seen = append(seen, cfg.AllowedModels)
+		return nil
+	})
+
+	assert.Equal(t, "a", reloader.Current().AllowedModels)
+	assert.EqualError(t, reloader.Reload(), "rejected")
+	assert.Equal(t, []string{"b", "b"}, seen, "a failing subscriber does not stop the others")
+	assert.Equal(t, "b", reloader.Current().AllowedModels)
+
+	assert.ErrorContains(t, reloader.Reload(), "broken file")
+	assert.Len(t, seen, 2, "subscribers are not called when loading fails")
+}
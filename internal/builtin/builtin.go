@@ -0,0 +1,191 @@
+// Package builtin implements the tools built into the gateway: fetch, time
+// and calculator. They are offered to models alongside the MCP tools with the
+// "builtin_" prefix and run by the agent loop as an mcp.ToolExecutor, so
+// deployments get these trivial capabilities without running MCP servers.
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	// The gateway image has no zoneinfo database
+	_ "time/tzdata"
+
+	config "github.com/inference-gateway/inference-gateway/config"
+	mcp "github.com/inference-gateway/inference-gateway/internal/mcp"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// Prefix marks the names of the built-in tools offered to models
+const Prefix = "builtin_"
+
+// Names of the built-in tools in BUILTIN_TOOLS
+const (
+	Fetch      = "fetch"
+	Time       = "time"
+	Calculator = "calculator"
+)
+
+// Ensure Tools implements mcp.ToolExecutor at compile time
+var _ mcp.ToolExecutor = (*Tools)(nil)
+
+// Tools runs the built-in tools enabled by BUILTIN_TOOLS
+type Tools struct {
+	logger  logger.Logger
+	enabled []string
+	fetch   *fetcher
+	now     func() time.Time
+}
+
+// New returns the built-in tools enabled by BUILTIN_TOOLS, or nil when none
+// is. The fetch tool requires BUILTIN_TOOLS_FETCH_ALLOW.
+func New(cfg config.Config, log logger.Logger) (*Tools, error) {
+	t := &Tools{logger: log, now: time.Now}
+	for name := range strings.SplitSeq(cfg.BuiltinTools, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || slices.Contains(t.enabled, name) {
+			continue
+		}
+		switch name {
+		case Fetch:
+			fetch, err := newFetcher(cfg.BuiltinToolsFetchAllow, cfg.BuiltinToolsFetchTimeout, cfg.BuiltinToolsFetchMaxBytes, http.DefaultTransport)
+			if err != nil {
+				return nil, err
+			}
+			t.fetch = fetch
+		case Time, Calculator:
+		default:
+			return nil, fmt.Errorf("BUILTIN_TOOLS: unknown tool %q", name)
+		}
+		t.enabled = append(t.enabled, name)
+	}
+	if len(t.enabled) == 0 {
+		return nil, nil
+	}
+	return t, nil
+}
+
+// Names returns the enabled tools in BUILTIN_TOOLS order
+func (t *Tools) Names() []string {
+	return slices.Clone(t.enabled)
+}
+
+// ChatCompletionTools returns the definitions of the enabled tools offered to
+// models
+func (t *Tools) ChatCompletionTools() []types.ChatCompletionTool {
+	tools := make([]types.ChatCompletionTool, 0, len(t.enabled))
+	for _, name := range t.enabled {
+		var description string
+		var parameters types.FunctionParameters
+		switch name {
+		case Fetch:
+			description = "Fetch a web page or document over HTTP GET and return its status, content type and body. Only some hosts may be fetched."
+			parameters = types.FunctionParameters{
+				"type": "object",
+				"properties": map[string]any{
+					"url": map[string]any{"type": "string", "description": "The http or https URL to fetch"},
+				},
+				"required": []string{"url"},
+			}
+		case Time:
+			description = "Return the current date and time, optionally in a timezone."
+			parameters = types.FunctionParameters{
+				"type": "object",
+				"properties": map[string]any{
+					"timezone": map[string]any{"type": "string", "description": "IANA timezone name such as Europe/Paris; UTC when omitted"},
+				},
+			}
+		case Calculator:
+			description = "Evaluate an arithmetic expression with + - * / % ^ and parentheses, e.g. (2 + 3) * 4.5 ^ 2."
+			parameters = types.FunctionParameters{
+				"type": "object",
+				"properties": map[string]any{
+					"expression": map[string]any{"type": "string", "description": "The expression to evaluate"},
+				},
+				"required": []string{"expression"},
+			}
+		}
+		tools = append(tools, types.ChatCompletionTool{
+			Type: types.Function,
+			Function: types.FunctionObject{
+				Name:        Prefix + name,
+				Description: &description,
+				Parameters:  &parameters,
+			},
+		})
+	}
+	return tools
+}
+
+// Handles implements mcp.ToolExecutor.
+func (t *Tools) Handles(name string) bool {
+	return strings.HasPrefix(name, Prefix) && slices.Contains(t.enabled, strings.TrimPrefix(name, Prefix))
+}
+
+// Execute implements mcp.ToolExecutor.
+func (t *Tools) Execute(ctx context.Context, toolCall types.ChatCompletionMessageToolCall) (types.Message, error) {
+	log := logger.FromContext(ctx, t.logger)
+	name := strings.TrimPrefix(toolCall.Function.Name, Prefix)
+
+	var args struct {
+		URL        string `json:"url"`
+		Timezone   string `json:"timezone"`
+		Expression string `json:"expression"`
+	}
+	var result string
+	var err error
+	if toolCall.Function.Arguments != "" {
+		err = json.Unmarshal([]byte(toolCall.Function.Arguments), &args)
+	}
+	if err == nil {
+		log.Debug("executing built-in tool call", "tool", name, "id", toolCall.ID)
+		switch name {
+		case Fetch:
+			result, err = t.fetch.fetch(ctx, args.URL)
+		case Time:
+			result, err = currentTime(t.now(), args.Timezone)
+		case Calculator:
+			result, err = calculate(args.Expression)
+		}
+	} else {
+		err = fmt.Errorf("failed to parse arguments: %w", err)
+	}
+	if err != nil {
+		log.Warn("built-in tool call failed", "tool", name, "error", err.Error())
+		result = fmt.Sprintf("Error: %v", err)
+	}
+	return toolMessage(toolCall.ID, result)
+}
+
+// toolMessage returns the result message of tool call id
+func toolMessage(id, content string) (types.Message, error) {
+	msg := types.Message{Role: types.Tool, ToolCallID: &id}
+	if err := msg.Content.FromMessageContent0(content); err != nil {
+		return types.Message{}, fmt.Errorf("set tool result content: %w", err)
+	}
+	return msg, nil
+}
+
+// currentTime describes now in the IANA timezone, UTC when empty
+func currentTime(now time.Time, timezone string) (string, error) {
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return "", fmt.Errorf("unknown timezone %q", timezone)
+	}
+	now = now.In(location)
+	data, err := json.Marshal(map[string]any{
+		"datetime": now.Format(time.RFC3339),
+		"timezone": location.String(),
+		"weekday":  now.Weekday().String(),
+		"unix":     now.Unix(),
+	})
+	return string(data), err
+}
@@ -0,0 +1,148 @@
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func TestNew(t *testing.T) {
+	tools, err := New(config.Config{}, logger.NewNoopLogger())
+	require.NoError(t, err)
+	assert.Nil(t, tools, "no tool is enabled by default")
+
+	tools, err = New(config.Config{BuiltinTools: "Time, calculator,time"}, logger.NewNoopLogger())
+	require.NoError(t, err)
+	assert.Equal(t, []string{Time, Calculator}, tools.Names())
+	definitions := tools.ChatCompletionTools()
+	require.Len(t, definitions, 2)
+	assert.Equal(t, "builtin_time", definitions[0].Function.Name)
+	assert.Equal(t, "builtin_calculator", definitions[1].Function.Name)
+	assert.True(t, tools.Handles("builtin_calculator"))
+	assert.False(t, tools.Handles("builtin_fetch"), "fetch is not enabled")
+	assert.False(t, tools.Handles("mcp_calculator"))
+
+	_, err = New(config.Config{BuiltinTools: "shell"}, logger.NewNoopLogger())
+	assert.ErrorContains(t, err, "unknown tool")
+	_, err = New(config.Config{BuiltinTools: "fetch"}, logger.NewNoopLogger())
+	assert.ErrorContains(t, err, "BUILTIN_TOOLS_FETCH_ALLOW")
+	_, err = New(config.Config{BuiltinTools: "fetch", BuiltinToolsFetchAllow: "[docs"}, logger.NewNoopLogger())
+	assert.ErrorContains(t, err, "invalid host pattern")
+}
+
+func TestCalculate(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   string
+		err        string
+	}{
+		{expression: "1 + 2 * 3", expected: "7"},
+		{expression: "(1 + 2) * 3", expected: "9"},
+		{expression: "-2 ^ 2", expected: "-4"},
+		{expression: "2 ^ 3 ^ 2", expected: "512"},
+		{expression: "10 / 4 - .5", expected: "2"},
+		{expression: "7 % 4", expected: "3"},
+		{expression: "2 * -3", expected: "-6"},
+		{expression: "1 / 0", err: "division by zero"},
+		{expression: "(1 + 2", err: "missing )"},
+		{expression: "1 + ", err: "unexpected end"},
+		{expression: "2 x 3", err: "unexpected 'x'"},
+		{expression: "1.2.3", err: "invalid number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			result, err := calculate(tt.expression)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestCurrentTime(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 30, 0, 0, time.UTC)
+
+	result, err := currentTime(now, "Asia/Tokyo")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"datetime":"2025-03-14T21:30:00+09:00","timezone":"Asia/Tokyo","weekday":"Friday","unix":1741955400}`, result)
+
+	result, err = currentTime(now, "")
+	require.NoError(t, err)
+	assert.Contains(t, result, `"timezone":"UTC"`)
+
+	_, err = currentTime(now, "Mars/Olympus")
+	assert.ErrorContains(t, err, "unknown timezone")
+}
+
+func TestFetcher(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "http://blocked.test/", http.StatusFound)
+		default:
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte("hello from the docs"))
+		}
+	}))
+	defer server.Close()
+
+	f, err := newFetcher("127.0.0.1, *.example.com", time.Second, 10, http.DefaultTransport)
+	require.NoError(t, err)
+
+	result, err := f.fetch(context.Background(), server.URL+"/page")
+	require.NoError(t, err)
+	var page map[string]any
+	require.NoError(t, json.Unmarshal([]byte(result), &page))
+	assert.Equal(t, float64(http.StatusOK), page["status"])
+	assert.Equal(t, "text/plain", page["content_type"])
+	assert.Equal(t, "hello from", page["body"])
+	assert.Equal(t, true, page["truncated"])
+
+	_, err = f.fetch(context.Background(), server.URL+"/redirect")
+	assert.ErrorContains(t, err, `host "blocked.test" is not allowed`, "redirects are checked against the allowlist")
+	_, err = f.fetch(context.Background(), "http://internal.local/")
+	assert.ErrorContains(t, err, "not allowed")
+	_, err = f.fetch(context.Background(), "file:///etc/passwd")
+	assert.ErrorContains(t, err, "unsupported scheme")
+	allowed, err := url.Parse("https://docs.example.com/guide")
+	require.NoError(t, err)
+	assert.NoError(t, f.check(allowed))
+}
+
+func TestToolsExecute(t *testing.T) {
+	tools, err := New(config.Config{BuiltinTools: "calculator"}, logger.NewNoopLogger())
+	require.NoError(t, err)
+
+	for args, expected := range map[string]string{
+		`{"expression":"6 * 7"}`: "42",
+		`{"expression":"6 *"}`:   "Error: unexpected end of expression",
+		`{"expression":`:         "Error: failed to parse arguments",
+	} {
+		result, err := tools.Execute(context.Background(), types.ChatCompletionMessageToolCall{
+			ID:       "call_1",
+			Type:     types.Function,
+			Function: types.ChatCompletionMessageToolCallFunction{Name: "builtin_calculator", Arguments: args},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, types.Tool, result.Role)
+		assert.Equal(t, "call_1", *result.ToolCallID)
+		assert.True(t, strings.HasPrefix(result.TextContent(), expected), result.TextContent())
+	}
+}
@@ -0,0 +1,172 @@
+package builtin
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// calculate evaluates the arithmetic expression of the calculator tool
+func calculate(expression string) (string, error) {
+	p := &parser{input: expression}
+	value, err := p.expression()
+	if err != nil {
+		return "", err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return "", fmt.Errorf("unexpected %q at position %d", p.input[p.pos], p.pos)
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return "", fmt.Errorf("result is not a finite number")
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64), nil
+}
+
+// parser is a recursive descent parser of
+//
+//	expression = term { ("+" | "-") term }
+//	term       = unary { ("*" | "/" | "%") unary }
+//	unary      = "-" unary | power
+//	power      = primary [ "^" unary ]
+//	primary    = number | "(" expression ")"
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) expression() (float64, error) {
+	value, err := p.term()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch p.peek() {
+		case '+', '-':
+			op := p.next()
+			right, err := p.term()
+			if err != nil {
+				return 0, err
+			}
+			if op == '+' {
+				value += right
+			} else {
+				value -= right
+			}
+		default:
+			return value, nil
+		}
+	}
+}
+
+func (p *parser) term() (float64, error) {
+	value, err := p.unary()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch p.peek() {
+		case '*', '/', '%':
+			op := p.next()
+			right, err := p.unary()
+			if err != nil {
+				return 0, err
+			}
+			switch {
+			case op == '*':
+				value *= right
+			case right == 0:
+				return 0, fmt.Errorf("division by zero")
+			case op == '/':
+				value /= right
+			default:
+				value = math.Mod(value, right)
+			}
+		default:
+			return value, nil
+		}
+	}
+}
+
+func (p *parser) unary() (float64, error) {
+	switch p.peek() {
+	case '-':
+		p.next()
+		value, err := p.unary()
+		return -value, err
+	case '+':
+		p.next()
+		return p.unary()
+	}
+	return p.power()
+}
+
+func (p *parser) power() (float64, error) {
+	base, err := p.primary()
+	if err != nil {
+		return 0, err
+	}
+	if p.peek() != '^' {
+		return base, nil
+	}
+	p.next()
+	// Right associative: 2^3^2 is 2^(3^2)
+	exponent, err := p.unary()
+	if err != nil {
+		return 0, err
+	}
+	return math.Pow(base, exponent), nil
+}
+
+func (p *parser) primary() (float64, error) {
+	switch c := p.peek(); {
+	case c == '(':
+		p.next()
+		value, err := p.expression()
+		if err != nil {
+			return 0, err
+		}
+		if p.peek() != ')' {
+			return 0, fmt.Errorf("missing ) at position %d", p.pos)
+		}
+		p.next()
+		return value, nil
+	case c == '.' || c >= '0' && c <= '9':
+		start := p.pos
+		for p.pos < len(p.input) && (p.input[p.pos] == '.' || p.input[p.pos] >= '0' && p.input[p.pos] <= '9') {
+			p.pos++
+		}
+		value, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", p.input[start:p.pos])
+		}
+		return value, nil
+	case c == 0:
+		return 0, fmt.Errorf("unexpected end of expression")
+	default:
+		return 0, fmt.Errorf("unexpected %q at position %d", c, p.pos)
+	}
+}
+
+// peek returns the next non-space byte, or 0 at the end of the input
+func (p *parser) peek() byte {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+// next consumes the byte returned by peek
+func (p *parser) next() byte {
+	c := p.peek()
+	p.pos++
+	return c
+}
+
+func (p *parser) skipSpaces() {
+	for p.pos < len(p.input) && strings.IndexByte(" \t\n\r", p.input[p.pos]) >= 0 {
+		p.pos++
+	}
+}
@@ -0,0 +1,110 @@
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// fetcher runs the fetch tool, getting URLs of the hosts of
+// BUILTIN_TOOLS_FETCH_ALLOW only
+type fetcher struct {
+	allow    []string
+	maxBytes int
+	client   *http.Client
+}
+
+// newFetcher returns the fetcher of the allow host globs, such as
+// "*.example.com", giving up after timeout and reading at most maxBytes of
+// each body
+func newFetcher(allow string, timeout time.Duration, maxBytes int, transport http.RoundTripper) (*fetcher, error) {
+	f := &fetcher{maxBytes: maxBytes}
+	for host := range strings.SplitSeq(allow, ",") {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" {
+			continue
+		}
+		if _, err := path.Match(host, ""); err != nil {
+			return nil, fmt.Errorf("BUILTIN_TOOLS_FETCH_ALLOW: invalid host pattern %q", host)
+		}
+		f.allow = append(f.allow, host)
+	}
+	if len(f.allow) == 0 {
+		return nil, errors.New("BUILTIN_TOOLS: fetch requires BUILTIN_TOOLS_FETCH_ALLOW")
+	}
+	f.client = &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("stopped after 5 redirects")
+			}
+			return f.check(req.URL)
+		},
+	}
+	return f, nil
+}
+
+// check fails unless u is an http or https URL of an allowed host
+func (f *fetcher) check(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, pattern := range f.allow {
+		if ok, _ := path.Match(pattern, host); ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("host %q is not allowed", host)
+}
+
+// fetch gets rawURL. The request carries none of the credentials of the
+// caller of the gateway.
+func (f *fetcher) fetch(ctx context.Context, rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %w", err)
+	}
+	if err := f.check(u); err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "inference-gateway")
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body := io.Reader(resp.Body)
+	if f.maxBytes > 0 {
+		body = io.LimitReader(resp.Body, int64(f.maxBytes)+1)
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	truncated := f.maxBytes > 0 && len(content) > f.maxBytes
+	if truncated {
+		content = content[:f.maxBytes]
+	}
+	data, err := json.Marshal(map[string]any{
+		"url":          resp.Request.URL.String(),
+		"status":       resp.StatusCode,
+		"content_type": resp.Header.Get("Content-Type"),
+		"body":         strings.ToValidUTF8(string(content), "�"),
+		"truncated":    truncated,
+	})
+	return string(data), err
+}
@@ -0,0 +1,143 @@
+// Package cli implements infergw, a command-line client for a running
+// Inference Gateway. It talks to the gateway's HTTP API only, so it works
+// against any deployment the operator can reach.
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	sdk "github.com/inference-gateway/inference-gateway/sdk"
+)
+
+const usageText = `infergw - command-line client for the Inference Gateway
+
+Usage:
+  infergw [global flags] <command> [flags]
+
+Commands:
+  models list      List the models served by the gateway
+  chat             Send a chat completion request
+  mcp tools        List the MCP tools exposed by the gateway
+  usage report     Summarize token usage from the gateway's Prometheus metrics
+
+Global flags:
+  --url string       Gateway base URL (env INFERGW_URL, default http://localhost:8080)
+  --token string     Bearer token sent in the Authorization header (env INFERGW_TOKEN)
+  --timeout duration Request timeout, 0 for none (default 5m)
+`
+
+// ErrUsage is returned when the command line is invalid
+var ErrUsage = errors.New("invalid usage")
+
+// App is the state shared by the commands
+type App struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	Getenv func(string) string
+
+	client *sdk.Client
+	// httpClient fetches the metrics endpoint, outside the gateway's API
+	httpClient *http.Client
+}
+
+// Main runs infergw with args, excluding the program name, on the process
+// streams and returns the exit code
+func Main(args []string) int {
+	app := &App{Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr, Getenv: os.Getenv}
+	err := app.Run(context.Background(), args)
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, ErrUsage), errors.Is(err, flag.ErrHelp):
+		return 2
+	default:
+		_, _ = fmt.Fprintln(app.Stderr, "error:", err)
+		return 1
+	}
+}
+
+// Run parses the global flags and runs the command of args
+func (a *App) Run(ctx context.Context, args []string) error {
+	fs := a.flagSet("infergw", usageText)
+	baseURL := fs.String("url", a.env("INFERGW_URL", "http://localhost:8080"), "")
+	token := fs.String("token", a.Getenv("INFERGW_TOKEN"), "")
+	timeout := fs.Duration("timeout", 5*time.Minute, "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	a.httpClient = &http.Client{Timeout: *timeout}
+	a.client = sdk.New(*baseURL, sdk.WithToken(*token), sdk.WithHTTPClient(a.httpClient), sdk.WithRetries(0, 0))
+
+	rest := fs.Args()
+	if len(rest) == 0 {
+		_, _ = fmt.Fprint(a.Stderr, usageText)
+		return ErrUsage
+	}
+	command, rest := rest[0], rest[1:]
+	switch command {
+	case "models":
+		return a.subcommand(ctx, "models", rest, map[string]func(context.Context, []string) error{"list": a.modelsList})
+	case "chat":
+		return a.chat(ctx, rest)
+	case "mcp":
+		return a.subcommand(ctx, "mcp", rest, map[string]func(context.Context, []string) error{"tools": a.mcpTools})
+	case "usage":
+		return a.subcommand(ctx, "usage", rest, map[string]func(context.Context, []string) error{"report": a.usageReport})
+	case "help":
+		_, _ = fmt.Fprint(a.Stdout, usageText)
+		return nil
+	default:
+		_, _ = fmt.Fprintf(a.Stderr, "unknown command %q\n\n%s", command, usageText)
+		return ErrUsage
+	}
+}
+
+// subcommand dispatches the first of args to one of the subcommands of group
+func (a *App) subcommand(ctx context.Context, group string, args []string, subcommands map[string]func(context.Context, []string) error) error {
+	if len(args) > 0 {
+		if run, ok := subcommands[args[0]]; ok {
+			return run(ctx, args[1:])
+		}
+	}
+	names := make([]string, 0, len(subcommands))
+	for name := range subcommands {
+		names = append(names, group+" "+name)
+	}
+	_, _ = fmt.Fprintf(a.Stderr, "usage: infergw %s\n", strings.Join(names, " | infergw "))
+	return ErrUsage
+}
+
+// flagSet returns a flag set reporting errors on the app's stderr
+func (a *App) flagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	fs.Usage = func() { _, _ = fmt.Fprint(a.Stderr, usage) }
+	return fs
+}
+
+func (a *App) env(name, fallback string) string {
+	if value := a.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// printJSON writes v as indented JSON
+func (a *App) printJSON(v any) error {
+	enc := json.NewEncoder(a.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// GatewayError is an error answered by the gateway or a provider behind it
+type GatewayError = sdk.Error
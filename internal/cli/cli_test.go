@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// run runs infergw against server with args, returning its stdout and stderr
+func run(t *testing.T, server *httptest.Server, stdin string, args ...string) (string, string, error) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	app := &App{
+		Stdin:  strings.NewReader(stdin),
+		Stdout: &stdout,
+		Stderr: &stderr,
+		Getenv: func(name string) string {
+			if name == "INFERGW_URL" {
+				return server.URL
+			}
+			return ""
+		},
+	}
+	err := app.Run(context.Background(), args)
+	return stdout.String(), stderr.String(), err
+}
+
+func TestModelsList(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/models", r.URL.Path)
+		assert.Equal(t, "groq", r.URL.Query().Get("provider"))
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		_, _ = io.WriteString(w, `{"object":"list","data":[{"id":"groq/llama-3.3-70b","object":"model","owned_by":"meta","served_by":"groq"}]}`)
+	}))
+	defer server.Close()
+
+	stdout, _, err := run(t, server, "", "--token", "secret", "models", "list", "--provider", "groq")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "ID")
+	assert.Regexp(t, `groq/llama-3\.3-70b\s+groq\s+meta`, stdout)
+}
+
+func TestChat(t *testing.T) {
+	var received types.CreateChatCompletionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = types.CreateChatCompletionRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		if received.Stream != nil && *received.Stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = io.WriteString(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hel\"}}]}\n\n")
+			_, _ = io.WriteString(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"lo\"}}]}\n\n")
+			_, _ = io.WriteString(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":3,\"completion_tokens\":2,\"total_tokens\":5}}\n\n")
+			_, _ = io.WriteString(w, "data: [DONE]\n\n")
+			return
+		}
+		_, _ = io.WriteString(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"Hello"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	t.Run("streaming prints deltas as they arrive", func(t *testing.T) {
+		stdout, stderr, err := run(t, server, "", "chat", "--model", "openai/gpt-4o", "--stream", "--usage", "--system", "Be brief", "Say", "hello")
+		require.NoError(t, err)
+		assert.Equal(t, "Hello\n", stdout)
+		assert.Contains(t, stderr, "3 prompt + 2 completion = 5 tokens")
+
+		require.Len(t, received.Messages, 2)
+		assert.Equal(t, types.System, received.Messages[0].Role)
+		content, err := received.Messages[1].Content.AsMessageContent0()
+		require.NoError(t, err)
+		assert.Equal(t, "Say hello", content)
+		require.NotNil(t, received.StreamOptions)
+		assert.True(t, received.StreamOptions.IncludeUsage)
+	})
+
+	t.Run("the message is read from stdin", func(t *testing.T) {
+		stdout, _, err := run(t, server, "from stdin\n", "chat", "--model", "openai/gpt-4o")
+		require.NoError(t, err)
+		assert.Equal(t, "Hello\n", stdout)
+		content, err := received.Messages[0].Content.AsMessageContent0()
+		require.NoError(t, err)
+		assert.Equal(t, "from stdin", content)
+	})
+
+	t.Run("the model is required", func(t *testing.T) {
+		_, _, err := run(t, server, "", "chat", "hello")
+		assert.ErrorIs(t, err, ErrUsage)
+	})
+}
+
+func TestGatewayErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/mcp/tools" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = io.WriteString(w, `{"error":"mcp tools endpoint is not exposed","code":"IG-2002","code_name":"feature_disabled"}`)
+			return
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = io.WriteString(w, `{"error":{"message":"Rate limit reached","type":"requests"}}`)
+	}))
+	defer server.Close()
+
+	_, _, err := run(t, server, "", "mcp", "tools")
+	var gatewayErr *GatewayError
+	require.ErrorAs(t, err, &gatewayErr)
+	assert.Equal(t, "mcp tools endpoint is not exposed (IG-2002, HTTP 403)", gatewayErr.Error())
+
+	_, _, err = run(t, server, "", "chat", "--model", "openai/gpt-4o", "hi")
+	require.ErrorAs(t, err, &gatewayErr)
+	assert.Equal(t, "Rate limit reached (HTTP 429)", gatewayErr.Error())
+
+	_, _, err = run(t, server, "", "a2a", "agents")
+	assert.ErrorIs(t, err, ErrUsage)
+}
+
+func TestParseTokenUsage(t *testing.T) {
+	exposition := `# HELP gen_ai_client_token_usage Number of input and output tokens used per operation
+# TYPE gen_ai_client_token_usage histogram
+gen_ai_client_token_usage_bucket{gen_ai_provider_name="openai",gen_ai_request_model="gpt-4o",gen_ai_token_type="input",le="1"} 0
+gen_ai_client_token_usage_sum{gen_ai_provider_name="openai",gen_ai_request_model="gpt-4o",gen_ai_token_type="input",source="gateway"} 120
+gen_ai_client_token_usage_sum{gen_ai_provider_name="openai",gen_ai_request_model="gpt-4o",gen_ai_token_type="input",source="sdk"} 30
+gen_ai_client_token_usage_sum{gen_ai_provider_name="openai",gen_ai_request_model="gpt-4o",gen_ai_token_type="output",source="gateway"} 45
+gen_ai_client_token_usage_sum{gen_ai_provider_name="anthropic",gen_ai_request_model="claude \"3\"",gen_ai_token_type="output"} 7
+gen_ai_client_token_usage_count{gen_ai_provider_name="openai",gen_ai_request_model="gpt-4o",gen_ai_token_type="input"} 4
+`
+	rows, err := ParseTokenUsage(strings.NewReader(exposition))
+	require.NoError(t, err)
+	assert.Equal(t, []UsageRow{
+		{Provider: "anthropic", Model: `claude "3"`, OutputTokens: 7},
+		{Provider: "openai", Model: "gpt-4o", InputTokens: 150, OutputTokens: 45},
+	}, rows)
+}
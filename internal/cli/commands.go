@@ -0,0 +1,201 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+	sdk "github.com/inference-gateway/inference-gateway/sdk"
+)
+
+// modelsList implements `infergw models list`
+func (a *App) modelsList(ctx context.Context, args []string) error {
+	fs := a.flagSet("models list", "usage: infergw models list [--provider name] [--json]\n")
+	provider := fs.String("provider", "", "")
+	asJSON := fs.Bool("json", false, "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	models, err := a.client.ListModels(ctx, types.Provider(*provider))
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return a.printJSON(models)
+	}
+
+	w := tabwriter.NewWriter(a.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ID\tSERVED BY\tOWNED BY")
+	for _, model := range models.Data {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", model.ID, model.ServedBy, model.OwnedBy)
+	}
+	return w.Flush()
+}
+
+// mcpTools implements `infergw mcp tools`
+func (a *App) mcpTools(ctx context.Context, args []string) error {
+	fs := a.flagSet("mcp tools", "usage: infergw mcp tools [--json]\n")
+	asJSON := fs.Bool("json", false, "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	tools, err := a.client.ListTools(ctx)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return a.printJSON(tools)
+	}
+
+	w := tabwriter.NewWriter(a.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAME\tSERVER\tDESCRIPTION")
+	for _, tool := range tools.Data {
+		description, _, _ := strings.Cut(tool.Description, "\n")
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", tool.Name, tool.Server, description)
+	}
+	return w.Flush()
+}
+
+const chatUsage = `usage: infergw chat --model provider/model [flags] [message...]
+
+The message is read from stdin when none is given.
+
+Flags:
+  --model string     Model to use, e.g. openai/gpt-4o (required)
+  --stream           Stream the response as it is generated
+  --system string    System prompt
+  --max-tokens int   Maximum number of tokens to generate
+  --usage            Print token usage to stderr
+  --json             Print the raw response (the chunks when streaming)
+`
+
+// chat implements `infergw chat`
+func (a *App) chat(ctx context.Context, args []string) error {
+	fs := a.flagSet("chat", chatUsage)
+	model := fs.String("model", "", "")
+	stream := fs.Bool("stream", false, "")
+	system := fs.String("system", "", "")
+	maxTokens := fs.Int("max-tokens", 0, "")
+	showUsage := fs.Bool("usage", false, "")
+	asJSON := fs.Bool("json", false, "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *model == "" {
+		_, _ = fmt.Fprint(a.Stderr, chatUsage)
+		return ErrUsage
+	}
+
+	prompt := strings.Join(fs.Args(), " ")
+	if prompt == "" {
+		data, err := io.ReadAll(a.Stdin)
+		if err != nil {
+			return err
+		}
+		prompt = strings.TrimSpace(string(data))
+	}
+	if prompt == "" {
+		return errors.New("no message given")
+	}
+
+	req := types.CreateChatCompletionRequest{Model: *model}
+	if *system != "" {
+		msg, err := textMessage(types.System, *system)
+		if err != nil {
+			return err
+		}
+		req.Messages = append(req.Messages, msg)
+	}
+	msg, err := textMessage(types.User, prompt)
+	if err != nil {
+		return err
+	}
+	req.Messages = append(req.Messages, msg)
+	if *maxTokens > 0 {
+		req.MaxTokens = maxTokens
+	}
+
+	var usage *types.CompletionUsage
+	if *stream {
+		req.StreamOptions = &types.ChatCompletionStreamOptions{IncludeUsage: true}
+		chunks, err := a.client.ChatCompletionStream(ctx, req)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = chunks.Close() }()
+		if usage, err = a.printStream(chunks, *asJSON); err != nil {
+			return err
+		}
+	} else {
+		completion, err := a.client.ChatCompletion(ctx, req)
+		if err != nil {
+			return err
+		}
+		if err := a.printCompletion(completion, *asJSON); err != nil {
+			return err
+		}
+		usage = completion.Usage
+	}
+	if *showUsage && usage != nil {
+		_, _ = fmt.Fprintf(a.Stderr, "usage: %d prompt + %d completion = %d tokens\n", usage.PromptTokens, usage.CompletionTokens, usage.TotalTokens)
+	}
+	return nil
+}
+
+func textMessage(role types.MessageRole, text string) (types.Message, error) {
+	msg := types.Message{Role: role}
+	err := msg.Content.FromMessageContent0(text)
+	return msg, err
+}
+
+// printCompletion prints the message of a chat completion
+func (a *App) printCompletion(completion *types.CreateChatCompletionResponse, asJSON bool) error {
+	if asJSON {
+		return a.printJSON(completion)
+	}
+	for _, choice := range completion.Choices {
+		if content, err := choice.Message.Content.AsMessageContent0(); err == nil && content != "" {
+			_, _ = fmt.Fprintln(a.Stdout, content)
+		}
+		if choice.Message.ToolCalls != nil {
+			for _, call := range *choice.Message.ToolCalls {
+				_, _ = fmt.Fprintf(a.Stdout, "[tool call] %s(%s)\n", call.Function.Name, call.Function.Arguments)
+			}
+		}
+	}
+	return nil
+}
+
+// printStream prints the content deltas of a chat completion stream as they
+// arrive, or its chunks as JSON lines
+func (a *App) printStream(stream *sdk.Stream, asJSON bool) (*types.CompletionUsage, error) {
+	wroteContent := false
+	for stream.Next() {
+		chunk := stream.Chunk()
+		if asJSON {
+			data, err := json.Marshal(chunk)
+			if err != nil {
+				return nil, err
+			}
+			_, _ = fmt.Fprintln(a.Stdout, string(data))
+			continue
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != "" {
+				_, _ = fmt.Fprint(a.Stdout, choice.Delta.Content)
+				wroteContent = true
+			}
+		}
+	}
+	if wroteContent {
+		_, _ = fmt.Fprintln(a.Stdout)
+	}
+	return stream.Usage(), stream.Err()
+}
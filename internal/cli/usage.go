@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// tokenUsageMetric is the Prometheus name of the gateway's
+// gen_ai.client.token.usage histogram; its _sum series hold token totals
+const tokenUsageMetric = "gen_ai_client_token_usage_sum"
+
+const usageReportUsage = `usage: infergw usage report [--metrics-url url] [--json]
+
+Summarizes the tokens counted by the gateway since it started, by provider and
+model, from its Prometheus metrics (TELEMETRY_ENABLE=true).
+
+Flags:
+  --metrics-url string  Metrics endpoint (env INFERGW_METRICS_URL, default http://localhost:9464/metrics)
+  --json                Print the report as JSON
+`
+
+// UsageRow is the token usage of a model
+type UsageRow struct {
+	Provider     string `json:"provider"`
+	Model        string `json:"model"`
+	InputTokens  int64  `json:"input_tokens"`
+	OutputTokens int64  `json:"output_tokens"`
+}
+
+// usageReport implements `infergw usage report`
+func (a *App) usageReport(ctx context.Context, args []string) error {
+	fs := a.flagSet("usage report", usageReportUsage)
+	metricsURL := fs.String("metrics-url", a.env("INFERGW_METRICS_URL", "http://localhost:9464/metrics"), "")
+	asJSON := fs.Bool("json", false, "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, *metricsURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("metrics endpoint answered HTTP %d", resp.StatusCode)
+	}
+	rows, err := ParseTokenUsage(resp.Body)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return a.printJSON(rows)
+	}
+
+	w := tabwriter.NewWriter(a.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	_, _ = fmt.Fprintln(w, "PROVIDER\tMODEL\tINPUT\tOUTPUT\tTOTAL\t")
+	var input, output int64
+	for _, row := range rows {
+		input += row.InputTokens
+		output += row.OutputTokens
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t\n", row.Provider, row.Model, row.InputTokens, row.OutputTokens, row.InputTokens+row.OutputTokens)
+	}
+	_, _ = fmt.Fprintf(w, "TOTAL\t\t%d\t%d\t%d\t\n", input, output, input+output)
+	return w.Flush()
+}
+
+// ParseTokenUsage sums the token usage series of a Prometheus text
+// exposition by provider and model, sorted by provider then model. Series of
+// the same model with other labels (e.g. source) are added up.
+func ParseTokenUsage(r io.Reader) ([]UsageRow, error) {
+	type key struct{ provider, model string }
+	totals := make(map[key]*UsageRow)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, tokenUsageMetric+"{") {
+			continue
+		}
+		labels, rest, ok := parseLabels(strings.TrimPrefix(line, tokenUsageMetric))
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		value, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			continue
+		}
+
+		k := key{provider: labels["gen_ai_provider_name"], model: labels["gen_ai_request_model"]}
+		row, ok := totals[k]
+		if !ok {
+			row = &UsageRow{Provider: k.provider, Model: k.model}
+			totals[k] = row
+		}
+		switch labels["gen_ai_token_type"] {
+		case "input":
+			row.InputTokens += int64(value)
+		case "output":
+			row.OutputTokens += int64(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	rows := make([]UsageRow, 0, len(totals))
+	for _, row := range totals {
+		rows = append(rows, *row)
+	}
+	slices.SortFunc(rows, func(a, b UsageRow) int {
+		if c := strings.Compare(a.Provider, b.Provider); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Model, b.Model)
+	})
+	return rows, nil
+}
+
+// parseLabels parses the {name="value",...} label set at the start of s,
+// returning the labels and what follows them
+func parseLabels(s string) (map[string]string, string, bool) {
+	if !strings.HasPrefix(s, "{") {
+		return nil, "", false
+	}
+	labels := make(map[string]string)
+	i := 1
+	for {
+		for i < len(s) && (s[i] == ',' || s[i] == ' ') {
+			i++
+		}
+		if i >= len(s) {
+			return nil, "", false
+		}
+		if s[i] == '}' {
+			return labels, s[i+1:], true
+		}
+		eq := strings.IndexByte(s[i:], '=')
+		if eq < 0 || i+eq+1 >= len(s) || s[i+eq+1] != '"' {
+			return nil, "", false
+		}
+		name := s[i : i+eq]
+		i += eq + 2
+
+		var value strings.Builder
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+				switch s[i] {
+				case 'n':
+					value.WriteByte('\n')
+				default:
+					value.WriteByte(s[i])
+				}
+				continue
+			}
+			value.WriteByte(s[i])
+		}
+		if i >= len(s) {
+			return nil, "", false
+		}
+		labels[name] = value.String()
+		i++
+	}
+}
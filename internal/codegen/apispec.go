@@ -0,0 +1,74 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GenerateAPISpec writes the OpenAPI spec served by the gateway at
+// /openapi.json: openapi.yaml as JSON, without the Config schema and the
+// x-provider-configs and x-server-tags extensions only used to generate the
+// gateway.
+func GenerateAPISpec(output, input string) error {
+	data, err := os.ReadFile(input)
+	if err != nil {
+		return fmt.Errorf("reading OpenAPI spec %s: %w", input, err)
+	}
+
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("parsing OpenAPI spec: %w", err)
+	}
+
+	if components, ok := doc["components"].(map[string]any); ok {
+		if schemas, ok := components["schemas"].(map[string]any); ok {
+			delete(schemas, "Config")
+			if provider, ok := schemas["Provider"].(map[string]any); ok {
+				delete(provider, "x-provider-configs")
+			}
+		}
+	}
+	if servers, ok := doc["servers"].([]any); ok {
+		for _, server := range servers {
+			if s, ok := server.(map[string]any); ok {
+				delete(s, "x-server-tags")
+			}
+		}
+	}
+
+	out, err := json.MarshalIndent(stringKeys(doc), "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling OpenAPI spec: %w", err)
+	}
+	if err := os.WriteFile(output, append(out, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing OpenAPI spec %s: %w", output, err)
+	}
+	return nil
+}
+
+// stringKeys converts the mappings decoded with non-string keys, such as
+// unquoted response codes, to maps with string keys so they encode as JSON
+func stringKeys(node any) any {
+	switch v := node.(type) {
+	case map[string]any:
+		for k, child := range v {
+			v[k] = stringKeys(child)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, child := range v {
+			m[fmt.Sprint(k)] = stringKeys(child)
+		}
+		return m
+	case []any:
+		for i, child := range v {
+			v[i] = stringKeys(child)
+		}
+		return v
+	}
+	return node
+}
@@ -6,6 +6,7 @@ import (
 	"os/exec"
 	"strings"
 	"text/template"
+	"time"
 
 	cases "golang.org/x/text/cases"
 	language "golang.org/x/text/language"
@@ -60,7 +61,7 @@ type Config struct {
 	{{- if eq $name "general" }}
 	// {{ $section.Title }}
 	{{- range $field := $section.Settings }}
-	{{ pascalCase $field.Env }} {{ $field.Type }} ` + "`env:\"{{ $field.Env }}{{if $field.Default}}, default={{$field.Default}}{{end}}\" description:\"{{$field.Description}}\"`" + `
+	{{ pascalCase $field.Env }} {{ $field.Type }} ` + "`env:\"{{ $field.Env }}{{if $field.Default}}, default={{$field.Default}}{{end}}\"{{if $field.Secret}} type:\"secret\"{{end}} description:\"{{$field.Description}}\"`" + `
 	{{- end }}
 	{{- else if eq $name "telemetry" }}
 	// Telemetry settings
@@ -95,7 +96,7 @@ type Config struct {
 // Telemetry configuration
 type TelemetryConfig struct {
 	{{- range $field := $section.Settings }}
-	{{ pascalCase (trimPrefix $field.Env "TELEMETRY_") }} {{ $field.Type }} ` + "`env:\"{{ trimPrefix $field.Env \"TELEMETRY_\" }}{{if $field.Default}}, default={{$field.Default}}{{end}}\" description:\"{{$field.Description}}\"`" + `
+	{{ pascalCase (trimPrefix $field.Env "TELEMETRY_") }} {{ $field.Type }} ` + "`env:\"{{ trimPrefix $field.Env \"TELEMETRY_\" }}{{if $field.Default}}, default={{$field.Default}}{{end}}\"{{if $field.Secret}} type:\"secret\"{{end}} description:\"{{$field.Description}}\"`" + `
 	{{- end }}
 }
 {{- else if eq $name "mcp" }}
@@ -380,33 +381,35 @@ type ClientConfig struct {
     {{- end }}
 }
 
-func NewHTTPClient(cfg *ClientConfig, scheme, hostname, port string) Client {
+func NewHTTPClient(cfg *ClientConfig, scheme, hostname, port string) (Client, error) {
     var tlsMinVersion uint16 = tls.VersionTLS12
     if cfg.ClientTlsMinVersion == "TLS13" {
         tlsMinVersion = tls.VersionTLS13
     }
 
-    httpClient := &http.Client{
-        Transport: &http.Transport{
-            MaxIdleConns:        cfg.ClientMaxIdleConns,
-            MaxIdleConnsPerHost: cfg.ClientMaxIdleConnsPerHost,
-            IdleConnTimeout:     cfg.ClientIdleConnTimeout,
-            TLSClientConfig: &tls.Config{
-                MinVersion: tlsMinVersion,
-            },
-            ForceAttemptHTTP2:     true,
-            DisableCompression:    cfg.ClientDisableCompression,
-            ResponseHeaderTimeout: cfg.ClientResponseHeaderTimeout,
-            ExpectContinueTimeout: cfg.ClientExpectContinueTimeout,
+    transport, err := NewTLSTransport(&http.Transport{
+        MaxIdleConns:        cfg.ClientMaxIdleConns,
+        MaxIdleConnsPerHost: cfg.ClientMaxIdleConnsPerHost,
+        IdleConnTimeout:     cfg.ClientIdleConnTimeout,
+        TLSClientConfig: &tls.Config{
+            MinVersion: tlsMinVersion,
         },
+        ForceAttemptHTTP2:     true,
+        DisableCompression:    cfg.ClientDisableCompression,
+        ResponseHeaderTimeout: cfg.ClientResponseHeaderTimeout,
+        ExpectContinueTimeout: cfg.ClientExpectContinueTimeout,
+        DialContext:           DialContext,
+    }, cfg)
+    if err != nil {
+        return nil, err
     }
 
     return &ClientImpl{
         scheme:   scheme,
         hostname: hostname,
         port:     port,
-        client:   httpClient,
-    }
+        client:   &http.Client{Transport: transport},
+    }, nil
 }
 
 func (c *ClientImpl) Do(req *http.Request) (*http.Response, error) {
@@ -680,6 +683,20 @@ func GenerateProviderRegistry(destination string, oas string) error {
 			}
 			return strings.Join(parts, "")
 		},
+		"duration": func(s string) (string, error) {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return "", err
+			}
+			switch {
+			case d%time.Minute == 0:
+				return fmt.Sprintf("%d * time.Minute", d/time.Minute), nil
+			case d%time.Second == 0:
+				return fmt.Sprintf("%d * time.Second", d/time.Second), nil
+			default:
+				return fmt.Sprintf("%d * time.Millisecond", d/time.Millisecond), nil
+			}
+		},
 		"getAuthType": func(authType string) string {
 			switch authType {
 			case "bearer":
@@ -701,6 +718,7 @@ package registry
 
 import (
 	"fmt"
+	"time"
 
 	client "github.com/inference-gateway/inference-gateway/providers/client"
 	constants "github.com/inference-gateway/inference-gateway/providers/constants"
@@ -719,6 +737,13 @@ type ProviderConfig struct {
 	SupportsVision bool
 	ExtraHeaders   map[string][]string
 	Endpoints      types.Endpoints
+	// Timeout bounds the requests to the provider, SERVER_READ_TIMEOUT when 0
+	Timeout time.Duration
+	// ConnectTimeout bounds establishing connections to the provider, 30s
+	// when 0
+	ConnectTimeout time.Duration
+	// MaxRetries is how many times failed requests to the provider are retried
+	MaxRetries int
 }
 
 //go:generate mockgen -source=registry.go -destination=../../tests/mocks/providers/registry.go -package=providersmocks
@@ -787,6 +812,15 @@ var Registry = map[types.Provider]*ProviderConfig{
 			Models: constants.{{pascalCase $name}}ModelsEndpoint,
 			Chat:   constants.{{pascalCase $name}}ChatEndpoint,
 		},
+		{{- if $config.Timeout }}
+		Timeout: {{duration $config.Timeout}},
+		{{- end }}
+		{{- if $config.ConnectTimeout }}
+		ConnectTimeout: {{duration $config.ConnectTimeout}},
+		{{- end }}
+		{{- if $config.MaxRetries }}
+		MaxRetries: {{$config.MaxRetries}},
+		{{- end }}
 	},
 	{{- end }}
 }
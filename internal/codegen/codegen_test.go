@@ -1,8 +1,10 @@
 package codegen
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	assert "github.com/stretchr/testify/assert"
@@ -59,3 +61,35 @@ func TestGenerateConfigFromFixture(t *testing.T) {
 	assert.NotContains(t, content, "acme")
 	assert.NotContains(t, content, "ollama")
 }
+
+func TestGenerateConfigFileFromFixture(t *testing.T) {
+	dir := t.TempDir()
+	settings := filepath.Join(dir, "file_settings.go")
+	require.NoError(t, GenerateConfigFileSettings(settings, fixture))
+	out, err := os.ReadFile(settings)
+	require.NoError(t, err)
+	content := strings.Join(strings.Fields(string(out)), " ")
+
+	assert.Contains(t, content, `"environment": {Env: "ENVIRONMENT", Type: "string"}`)
+	assert.Contains(t, content, `"server.host": {Env: "SERVER_HOST", Type: "string"}`)
+	assert.Contains(t, content, `"client.timeout": {Env: "CLIENT_TIMEOUT", Type: "time.Duration"}`)
+	assert.Contains(t, content, `"providers.acme.api_key": {Env: "ACME_API_KEY", Type: "string"}`)
+	assert.Contains(t, content, `"providers.local.timeout": {Env: "PROVIDER_TIMEOUTS", Type: "time.Duration", Provider: "local"}`)
+	assert.Contains(t, content, `"routing.models": {Type: "document"}`)
+
+	schemaPath := filepath.Join(dir, "config.schema.json")
+	require.NoError(t, GenerateConfigFileSchema(schemaPath, fixture))
+	data, err := os.ReadFile(schemaPath)
+	require.NoError(t, err)
+	var schema struct {
+		Properties map[string]struct {
+			Type       string                     `json:"type"`
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"properties"`
+	}
+	require.NoError(t, json.Unmarshal(data, &schema))
+	assert.Contains(t, schema.Properties, "environment")
+	assert.Equal(t, "object", schema.Properties["server"].Type)
+	assert.Contains(t, schema.Properties["server"].Properties, "host")
+	assert.Contains(t, schema.Properties["providers"].Properties, "acme")
+}
@@ -0,0 +1,222 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	openapi "github.com/inference-gateway/inference-gateway/internal/openapi"
+)
+
+// fileSetting is a key of the YAML or JSON config file and the setting it
+// sets
+type fileSetting struct {
+	Path        string
+	Env         string
+	Type        string
+	Provider    string
+	Default     string
+	Description string
+	Secret      bool
+}
+
+// fileDocuments are the keys of the config file read as a whole by their
+// component instead of being mapped to an env var
+var fileDocuments = []fileSetting{
+	{
+		Path:        "routing.models",
+		Type:        "document",
+		Description: "Model aliases and their deployment pools, as under models in the ROUTING_CONFIG_PATH file. Used when ROUTING_CONFIG_PATH is not set",
+	},
+}
+
+// fileSettings lists the keys of the config file: the settings of the general
+// section at the top level, those of the other sections under the section
+// name without its env prefix, and the settings of every provider under
+// providers.<id>
+func fileSettings(schema *openapi.OpenAPISchema) []fileSetting {
+	var settings []fileSetting
+	for _, sections := range schema.Components.Schemas.Config.XConfig.Sections {
+		for name, section := range sections {
+			if name == "providers" {
+				continue
+			}
+			for _, s := range section.Settings {
+				path := strings.ToLower(s.Env)
+				if name != "general" {
+					path = name + "." + strings.ToLower(strings.TrimPrefix(s.Env, strings.ToUpper(name)+"_"))
+				}
+				settings = append(settings, fileSetting{
+					Path:        path,
+					Env:         s.Env,
+					Type:        s.Type,
+					Default:     s.Default,
+					Description: s.Description,
+					Secret:      s.Secret,
+				})
+			}
+		}
+	}
+
+	for id, p := range schema.Components.Schemas.Provider.XProviderConfigs {
+		prefix := "providers." + id + "."
+		env := strings.ToUpper(id)
+		settings = append(settings,
+			fileSetting{Path: prefix + "api_url", Env: env + "_API_URL", Type: "string", Default: p.URL, Description: "API URL of " + id},
+			fileSetting{Path: prefix + "api_key", Env: env + "_API_KEY", Type: "string", Description: "API key of " + id, Secret: true},
+			fileSetting{Path: prefix + "timeout", Env: "PROVIDER_TIMEOUTS", Type: "time.Duration", Provider: id, Default: p.Timeout, Description: "Request timeout of " + id},
+			fileSetting{Path: prefix + "connect_timeout", Env: "PROVIDER_CONNECT_TIMEOUTS", Type: "time.Duration", Provider: id, Default: p.ConnectTimeout, Description: "Connect timeout of " + id},
+			fileSetting{Path: prefix + "max_retries", Env: "PROVIDER_RETRIES", Type: "int", Provider: id, Description: "Retries of the failed requests to " + id},
+		)
+	}
+
+	settings = append(settings, fileDocuments...)
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Path < settings[j].Path })
+	return settings
+}
+
+// GenerateConfigFileSettings generates the table mapping the keys of the
+// YAML or JSON config file to their settings, against which the file is
+// validated
+func GenerateConfigFileSettings(destination string, oas string) error {
+	schema, err := openapi.Read(oas)
+	if err != nil {
+		return fmt.Errorf("failed to read OpenAPI spec: %w", err)
+	}
+
+	tmpl := template.Must(template.New("configfile").Parse(`// Code generated from OpenAPI schema. DO NOT EDIT.
+package config
+
+// fileSettings maps the keys of a YAML or JSON CONFIG_FILE, dotted by
+// section, to the settings they set
+var fileSettings = map[string]fileSetting{
+{{- range . }}
+	"{{ .Path }}": { {{- if .Env }}Env: "{{ .Env }}", {{ end }}Type: "{{ .Type }}"{{ if .Provider }}, Provider: "{{ .Provider }}"{{ end }}},
+{{- end }}
+}
+`))
+
+	f, err := os.Create(destination)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := tmpl.Execute(f, fileSettings(schema)); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("gofmt", "-w", destination)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to format %s: %w", destination, err)
+	}
+
+	return nil
+}
+
+// GenerateConfigFileSchema generates the JSON Schema of the YAML or JSON
+// config file, for editors and CI checks
+func GenerateConfigFileSchema(destination string, oas string) error {
+	schema, err := openapi.Read(oas)
+	if err != nil {
+		return fmt.Errorf("failed to read OpenAPI spec: %w", err)
+	}
+
+	root := objectSchema("")
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["title"] = "Inference Gateway configuration file"
+	root["description"] = "Settings of the YAML or JSON file named by CONFIG_FILE. Environment variables override them."
+	scalar := map[string]any{"type": []string{"string", "number"}}
+	root["$defs"] = map[string]any{
+		"text": map[string]any{
+			"description": "A string, or a list or map joined into the comma-separated form of the setting",
+			"anyOf": []any{
+				scalar,
+				map[string]any{"type": "array", "items": scalar},
+				map[string]any{"type": "object", "additionalProperties": scalar},
+			},
+		},
+	}
+	for _, s := range fileSettings(schema) {
+		parent := root
+		parts := strings.Split(s.Path, ".")
+		for _, part := range parts[:len(parts)-1] {
+			properties := parent["properties"].(map[string]any)
+			child, ok := properties[part].(map[string]any)
+			if !ok {
+				child = objectSchema(part)
+				properties[part] = child
+			}
+			parent = child
+		}
+		parent["properties"].(map[string]any)[parts[len(parts)-1]] = settingSchema(s)
+	}
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(destination, append(data, '\n'), 0o644)
+}
+
+// objectSchema returns the schema of a section of settings
+func objectSchema(name string) map[string]any {
+	schema := map[string]any{
+		"type":                 "object",
+		"additionalProperties": false,
+		"properties":           map[string]any{},
+	}
+	if name != "" {
+		schema["title"] = name
+	}
+	return schema
+}
+
+// settingSchema returns the schema of the value of setting s. String settings
+// refer to the text definition, which also takes numbers, and lists or maps
+// joined into their comma-separated form.
+func settingSchema(s fileSetting) map[string]any {
+	var schema map[string]any
+	switch s.Type {
+	case "bool":
+		schema = map[string]any{"type": "boolean"}
+	case "int":
+		schema = map[string]any{"type": "integer"}
+	case "time.Duration":
+		schema = map[string]any{"type": "string", "pattern": `^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`}
+	case "document":
+		schema = map[string]any{"type": "object"}
+	default:
+		schema = map[string]any{"$ref": "#/$defs/text"}
+	}
+	if s.Description != "" {
+		schema["description"] = s.Description
+	}
+	if s.Default != "" {
+		schema["default"] = defaultValue(s)
+	}
+	if s.Secret {
+		schema["writeOnly"] = true
+	}
+	return schema
+}
+
+// defaultValue returns the default of s typed as in the config file
+func defaultValue(s fileSetting) any {
+	switch s.Type {
+	case "bool":
+		if b, err := strconv.ParseBool(s.Default); err == nil {
+			return b
+		}
+	case "int":
+		if n, err := strconv.Atoi(s.Default); err == nil {
+			return n
+		}
+	}
+	return s.Default
+}
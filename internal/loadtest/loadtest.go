@@ -0,0 +1,242 @@
+// Package loadtest generates streaming chat completion load against the
+// gateway and measures its throughput: tokens per second, time to first
+// token and allocations. MockProvider stands in for a provider, so the
+// gateway's own overhead is measured without keys or network noise. It backs
+// cmd/loadtest and the streaming benchmarks of tests/.
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+	sdk "github.com/inference-gateway/inference-gateway/sdk"
+)
+
+// Options configure a load test
+type Options struct {
+	// URL is the base URL of the gateway
+	URL string
+	// Token is the bearer token sent when the gateway requires auth
+	Token string
+	// Model is the model requested, e.g. openai/mock
+	Model string
+	// Prompt is the user message of each request
+	Prompt string
+	// Concurrency is the number of streams open at once
+	Concurrency int
+	// Requests is the number of requests sent in total, unbounded when zero
+	Requests int
+	// Duration bounds the run, unbounded when zero. Streams cut by it are
+	// not counted.
+	Duration time.Duration
+	// HTTPClient sends the requests, one pooling Concurrency connections
+	// when nil
+	HTTPClient *http.Client
+}
+
+// Result is the outcome of a load test
+type Result struct {
+	// Requests is the number of streams completed, Errors those failing
+	Requests int           `json:"requests"`
+	Errors   int           `json:"errors"`
+	Duration time.Duration `json:"duration_ns"`
+	// Tokens is the number of completion tokens received, as reported by
+	// the usage of each stream, else counted as content chunks
+	Tokens          int64   `json:"tokens"`
+	TokensPerSecond float64 `json:"tokens_per_second"`
+	// TTFT is the time to the first content chunk of a stream, Latency the
+	// time to its end
+	TTFT    Percentiles `json:"ttft"`
+	Latency Percentiles `json:"latency"`
+	// AllocsPerRequest and BytesPerRequest are the heap allocations of the
+	// process per request, covering the gateway when it runs in process
+	AllocsPerRequest float64 `json:"allocs_per_request"`
+	BytesPerRequest  float64 `json:"bytes_per_request"`
+	// FirstError is the first error met, if any
+	FirstError string `json:"first_error,omitempty"`
+}
+
+// Percentiles summarize a distribution of durations
+type Percentiles struct {
+	P50 time.Duration `json:"p50_ns"`
+	P90 time.Duration `json:"p90_ns"`
+	P99 time.Duration `json:"p99_ns"`
+	Max time.Duration `json:"max_ns"`
+}
+
+// sample is the measure of one stream
+type sample struct {
+	ttft    time.Duration
+	latency time.Duration
+	tokens  int64
+	err     error
+}
+
+// Run sends streaming chat completions to the gateway, Concurrency at a
+// time, until Requests were sent, Duration elapsed or ctx is done
+func Run(ctx context.Context, opts Options) (*Result, error) {
+	if opts.URL == "" || opts.Model == "" {
+		return nil, errors.New("a gateway url and a model are required")
+	}
+	if opts.Requests <= 0 && opts.Duration <= 0 {
+		return nil, errors.New("a number of requests or a duration is required")
+	}
+	opts.Concurrency = max(opts.Concurrency, 1)
+	if opts.Prompt == "" {
+		opts.Prompt = "Write a short story."
+	}
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.MaxIdleConns = opts.Concurrency
+		transport.MaxIdleConnsPerHost = opts.Concurrency
+		httpClient = &http.Client{Transport: transport}
+	}
+	gateway := sdk.New(opts.URL, sdk.WithToken(opts.Token), sdk.WithHTTPClient(httpClient), sdk.WithRetries(0, 0))
+
+	message := types.Message{Role: types.User}
+	if err := message.Content.FromMessageContent0(opts.Prompt); err != nil {
+		return nil, err
+	}
+	req := types.CreateChatCompletionRequest{
+		Model:         opts.Model,
+		Messages:      []types.Message{message},
+		StreamOptions: &types.ChatCompletionStreamOptions{IncludeUsage: true},
+	}
+
+	if opts.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Duration)
+		defer cancel()
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+
+	var sent atomic.Int64
+	var mu sync.Mutex
+	var samples []sample
+	var wg sync.WaitGroup
+	for range opts.Concurrency {
+		wg.Go(func() {
+			for ctx.Err() == nil && (opts.Requests <= 0 || sent.Add(1) <= int64(opts.Requests)) {
+				s := stream(ctx, gateway, req)
+				if s.err != nil && ctx.Err() != nil {
+					return
+				}
+				mu.Lock()
+				samples = append(samples, s)
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	return summarize(samples, elapsed, after.Mallocs-before.Mallocs, after.TotalAlloc-before.TotalAlloc), nil
+}
+
+// stream sends one streaming request and measures it
+func stream(ctx context.Context, gateway *sdk.Client, req types.CreateChatCompletionRequest) sample {
+	start := time.Now()
+	s := sample{}
+	st, err := gateway.ChatCompletionStream(ctx, req)
+	if err != nil {
+		s.err = err
+		return s
+	}
+	defer func() { _ = st.Close() }()
+
+	var chunks int64
+	for st.Next() {
+		for _, choice := range st.Chunk().Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+			if chunks == 0 {
+				s.ttft = time.Since(start)
+			}
+			chunks++
+		}
+	}
+	s.latency = time.Since(start)
+	if err := st.Err(); err != nil {
+		s.err = err
+		return s
+	}
+	if chunks == 0 {
+		s.err = io.ErrUnexpectedEOF
+		return s
+	}
+	s.tokens = chunks
+	if usage := st.Usage(); usage != nil && usage.CompletionTokens > 0 {
+		s.tokens = usage.CompletionTokens
+	}
+	return s
+}
+
+func summarize(samples []sample, elapsed time.Duration, mallocs, allocBytes uint64) *Result {
+	result := &Result{Duration: elapsed}
+	var ttfts, latencies []time.Duration
+	for _, s := range samples {
+		if s.err != nil {
+			result.Errors++
+			if result.FirstError == "" {
+				result.FirstError = s.err.Error()
+			}
+			continue
+		}
+		result.Requests++
+		result.Tokens += s.tokens
+		ttfts = append(ttfts, s.ttft)
+		latencies = append(latencies, s.latency)
+	}
+	if elapsed > 0 {
+		result.TokensPerSecond = float64(result.Tokens) / elapsed.Seconds()
+	}
+	if total := len(samples); total > 0 {
+		result.AllocsPerRequest = float64(mallocs) / float64(total)
+		result.BytesPerRequest = float64(allocBytes) / float64(total)
+	}
+	result.TTFT = percentiles(ttfts)
+	result.Latency = percentiles(latencies)
+	return result
+}
+
+func percentiles(durations []time.Duration) Percentiles {
+	if len(durations) == 0 {
+		return Percentiles{}
+	}
+	slices.Sort(durations)
+	at := func(p float64) time.Duration {
+		return durations[min(len(durations)-1, int(p*float64(len(durations))))]
+	}
+	return Percentiles{P50: at(0.50), P90: at(0.90), P99: at(0.99), Max: durations[len(durations)-1]}
+}
+
+// WriteText writes the result for a terminal
+func (r *Result) WriteText(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "requests:     %d completed, %d failed in %s\n", r.Requests, r.Errors, r.Duration.Round(time.Millisecond))
+	_, _ = fmt.Fprintf(w, "throughput:   %.0f tokens/s (%d tokens)\n", r.TokensPerSecond, r.Tokens)
+	_, _ = fmt.Fprintf(w, "ttft:         p50 %s  p90 %s  p99 %s  max %s\n", round(r.TTFT.P50), round(r.TTFT.P90), round(r.TTFT.P99), round(r.TTFT.Max))
+	_, _ = fmt.Fprintf(w, "latency:      p50 %s  p90 %s  p99 %s  max %s\n", round(r.Latency.P50), round(r.Latency.P90), round(r.Latency.P99), round(r.Latency.Max))
+	_, _ = fmt.Fprintf(w, "allocations:  %.0f allocs/request, %.1f KiB/request\n", r.AllocsPerRequest, r.BytesPerRequest/1024)
+	if r.FirstError != "" {
+		_, _ = fmt.Fprintf(w, "first error:  %s\n", r.FirstError)
+	}
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Microsecond)
+}
@@ -0,0 +1,65 @@
+package loadtest
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+)
+
+func newTestGateway(t *testing.T, provider *MockProvider) string {
+	t.Helper()
+	upstream := httptest.NewServer(provider)
+	t.Cleanup(upstream.Close)
+	gateway, err := NewGateway(upstream.URL)
+	require.NoError(t, err)
+	server := httptest.NewServer(gateway)
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestRun(t *testing.T) {
+	url := newTestGateway(t, &MockProvider{Tokens: 8, Interval: time.Millisecond})
+
+	result, err := Run(context.Background(), Options{URL: url, Model: "openai/" + MockModel, Concurrency: 3, Requests: 7})
+	require.NoError(t, err)
+	assert.Equal(t, 7, result.Requests)
+	assert.Zero(t, result.Errors, result.FirstError)
+	assert.Equal(t, int64(7*8), result.Tokens)
+	assert.Positive(t, result.TokensPerSecond)
+	assert.GreaterOrEqual(t, result.TTFT.P50, time.Millisecond, "the first token waits for one interval")
+	assert.GreaterOrEqual(t, result.Latency.P50, 8*time.Millisecond)
+	assert.LessOrEqual(t, result.TTFT.P99, result.TTFT.Max)
+	assert.Positive(t, result.AllocsPerRequest)
+
+	var out bytes.Buffer
+	result.WriteText(&out)
+	assert.Contains(t, out.String(), "7 completed, 0 failed")
+}
+
+func TestRun_Errors(t *testing.T) {
+	url := newTestGateway(t, &MockProvider{Tokens: 4})
+
+	result, err := Run(context.Background(), Options{URL: url, Model: "unknown/" + MockModel, Requests: 3})
+	require.NoError(t, err)
+	assert.Zero(t, result.Requests)
+	assert.Equal(t, 3, result.Errors)
+	assert.NotEmpty(t, result.FirstError)
+
+	_, err = Run(context.Background(), Options{URL: url, Model: "openai/" + MockModel})
+	assert.ErrorContains(t, err, "number of requests or a duration")
+}
+
+func TestRun_Duration(t *testing.T) {
+	url := newTestGateway(t, &MockProvider{Tokens: 4, Interval: 5 * time.Millisecond})
+
+	result, err := Run(context.Background(), Options{URL: url, Model: "openai/" + MockModel, Concurrency: 2, Duration: 100 * time.Millisecond})
+	require.NoError(t, err)
+	assert.Positive(t, result.Requests)
+	assert.Zero(t, result.Errors, "streams cut by the deadline are not counted as errors")
+	assert.Less(t, result.Duration, time.Second)
+}
@@ -0,0 +1,152 @@
+package loadtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+
+	api "github.com/inference-gateway/inference-gateway/api"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	client "github.com/inference-gateway/inference-gateway/providers/client"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// MockModel is the model listed and answered by MockProvider, served by the
+// in-process gateway as openai/mock
+const MockModel = "mock"
+
+// MockProvider is an OpenAI-compatible provider answering every chat
+// completion with a reply of Tokens chunks, one token each, Interval apart
+// when streamed. It serves /models and /chat/completions under any prefix,
+// so a gateway can reach it through /proxy or with <ID>_API_URL pointing at
+// it.
+type MockProvider struct {
+	// Tokens is the number of tokens of each reply
+	Tokens int
+	// Interval is the wait before each streamed token, simulating the
+	// generation speed of a model
+	Interval time.Duration
+}
+
+func (m *MockProvider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/models"):
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"object": "list",
+			"data":   []map[string]any{{"id": MockModel, "object": "model", "created": 1700000000, "owned_by": "loadtest"}},
+		})
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/chat/completions"):
+		var req types.CreateChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, `{"error":{"message":"invalid request body"}}`, http.StatusBadRequest)
+			return
+		}
+		if req.Stream != nil && *req.Stream {
+			m.stream(w, r, req)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"id":"chatcmpl-mock","object":"chat.completion","created":1700000000,"model":%q,"choices":[{"index":0,"message":{"role":"assistant","content":%q},"finish_reason":"stop"}],"usage":%s}`,
+			req.Model, strings.Repeat("tok ", m.Tokens), m.usage())
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// stream writes the reply as server-sent events, flushing each token
+func (m *MockProvider) stream(w http.ResponseWriter, r *http.Request, req types.CreateChatCompletionRequest) {
+	flusher, _ := w.(http.Flusher)
+	w.Header().Set("Content-Type", "text/event-stream")
+	chunk := func(delta, finishReason string) {
+		_, _ = fmt.Fprintf(w, `data: {"id":"chatcmpl-mock","object":"chat.completion.chunk","created":1700000000,"model":%q,"choices":[{"index":0,"delta":%s,"finish_reason":%s}]}`+"\n\n",
+			req.Model, delta, finishReason)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	for i := range m.Tokens {
+		if m.Interval > 0 {
+			select {
+			case <-time.After(m.Interval):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if i == 0 {
+			chunk(`{"role":"assistant","content":"tok "}`, "null")
+			continue
+		}
+		chunk(`{"content":"tok "}`, "null")
+	}
+	chunk(`{}`, `"stop"`)
+	if req.StreamOptions != nil && req.StreamOptions.IncludeUsage {
+		_, _ = fmt.Fprintf(w, `data: {"id":"chatcmpl-mock","object":"chat.completion.chunk","created":1700000000,"model":%q,"choices":[],"usage":%s}`+"\n\n", req.Model, m.usage())
+	}
+	_, _ = w.Write([]byte("data: [DONE]\n\n"))
+}
+
+func (m *MockProvider) usage() string {
+	return fmt.Sprintf(`{"prompt_tokens":8,"completion_tokens":%d,"total_tokens":%d}`, m.Tokens, 8+m.Tokens)
+}
+
+// NewGateway returns the gateway's chat completion and model routes, with the
+// openai provider pointed at the mock provider at providerURL. Middlewares
+// are left out, so the handler and provider streaming path are measured on
+// their own; load a gateway started with OPENAI_API_URL set to the mock
+// provider to measure them too.
+func NewGateway(providerURL string) (http.Handler, error) {
+	u, err := url.Parse(providerURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse provider url: %w", err)
+	}
+	host, port, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		return nil, fmt.Errorf("provider url %q has no port: %w", providerURL, err)
+	}
+	httpClient, err := client.NewHTTPClient(&client.ClientConfig{
+		ClientMaxIdleConns:        1024,
+		ClientMaxIdleConnsPerHost: 1024,
+		ClientIdleConnTimeout:     30 * time.Second,
+		ClientDisableCompression:  true,
+	}, u.Scheme, host, port)
+	if err != nil {
+		return nil, err
+	}
+
+	providers := map[types.Provider]*registry.ProviderConfig{
+		constants.OpenaiID: {
+			ID:       constants.OpenaiID,
+			Name:     constants.OpenaiDisplayName,
+			URL:      providerURL,
+			Token:    "loadtest",
+			AuthType: constants.AuthTypeBearer,
+			Endpoints: types.Endpoints{
+				Models: constants.OpenaiModelsEndpoint,
+				Chat:   constants.OpenaiChatEndpoint,
+			},
+		},
+	}
+	cfg := config.Config{
+		Server:    &config.ServerConfig{ReadTimeout: 5 * time.Minute, WriteTimeout: 5 * time.Minute},
+		Providers: providers,
+	}
+	log := logger.NewNoopLogger()
+	router := api.NewRouter(cfg, log, registry.NewProviderRegistry(providers, log), httpClient, nil, nil, nil, nil)
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.GET("/v1/models", router.ListModelsHandler)
+	r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
+	return r, nil
+}
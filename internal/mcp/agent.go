@@ -6,20 +6,15 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 
+	config "github.com/inference-gateway/inference-gateway/config"
 	logger "github.com/inference-gateway/inference-gateway/logger"
 	core "github.com/inference-gateway/inference-gateway/providers/core"
+	toolcalls "github.com/inference-gateway/inference-gateway/providers/toolcalls"
 	types "github.com/inference-gateway/inference-gateway/providers/types"
-	otelapi "go.opentelemetry.io/otel"
-	attribute "go.opentelemetry.io/otel/attribute"
-	codes "go.opentelemetry.io/otel/codes"
-	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
-	trace "go.opentelemetry.io/otel/trace"
 )
 
-// MaxAgentIterations limits the number of agent loop iterations
-const MaxAgentIterations = 10
-
 // Agent defines the interface for running agent operations
 //
 //go:generate mockgen -source=agent.go -destination=../../tests/mocks/mcp/agent.go -package=mcpmocks -typed
@@ -36,22 +31,59 @@ var _ Agent = (*agentImpl)(nil)
 
 // agentImpl is the concrete implementation of the Agent interface
 type agentImpl struct {
-	logger    logger.Logger
-	mcpClient MCPClientInterface
-	provider  core.IProvider
-	model     *string
+	logger logger.Logger
+	// executors run the tool calls of the model, see executeTool
+	executors       []ToolExecutor
+	provider        core.IProvider
+	model           *string
+	toolConcurrency int
+	// budget caps the agent loop of every request
+	budget Budget
+	// resultLimit shortens the tool results added to the conversation
+	resultLimit ResultLimit
+	// toolProgress sends the progress of tool calls in streamed responses
+	toolProgress bool
+}
+
+// NewAgent creates a new Agent instance calling the tools of the MCP servers
+// of mcpClient
+func NewAgent(logger logger.Logger, mcpClient MCPClientInterface, cfg config.Config) Agent {
+	return NewAgentWithSummarizer(logger, cfg, nil, NewMCPToolExecutor(logger, mcpClient))
+}
+
+// NewAgentWithExecutors creates an Agent routing each tool call to the first
+// of executors handling it
+func NewAgentWithExecutors(logger logger.Logger, cfg config.Config, executors ...ToolExecutor) Agent {
+	return NewAgentWithSummarizer(logger, cfg, nil, executors...)
 }
 
-// NewAgent creates a new Agent instance
-func NewAgent(logger logger.Logger, mcpClient MCPClientInterface) Agent {
+// NewAgentWithSummarizer creates an Agent routing each tool call to the first
+// of executors handling it, whose results, with
+// MCP_TOOL_RESULT_TRUNCATION=summary, are summarized by summarize
+func NewAgentWithSummarizer(logger logger.Logger, cfg config.Config, summarize Summarizer, executors ...ToolExecutor) Agent {
+	toolConcurrency := 1
+	if cfg.MCP != nil && cfg.MCP.ToolConcurrency > 1 {
+		toolConcurrency = cfg.MCP.ToolConcurrency
+	}
+	toolProgress := cfg.MCP != nil && cfg.MCP.ToolProgress
 	return &agentImpl{
-		mcpClient: mcpClient,
-		logger:    logger,
-		provider:  nil,
-		model:     nil,
+		executors:       executors,
+		logger:          logger,
+		provider:        nil,
+		model:           nil,
+		toolConcurrency: toolConcurrency,
+		budget:          BudgetFromConfig(cfg),
+		resultLimit:     ResultLimitFromConfig(cfg, summarize),
+		toolProgress:    toolProgress,
 	}
 }
 
+// log returns the logger of the request of ctx, tagging every line with the
+// request ID
+func (a *agentImpl) log(ctx context.Context) logger.Logger {
+	return logger.FromContext(ctx, a.logger)
+}
+
 func (a *agentImpl) SetProvider(provider core.IProvider) {
 	if provider == nil {
 		a.logger.Error("attempted to set nil provider", errors.New("provider is nil"))
@@ -80,19 +112,30 @@ func (a *agentImpl) Run(ctx context.Context, request *types.CreateChatCompletion
 
 	currentRequest := *request
 	currentResponse := *response
-	iteration := 0
+	budget := newBudgetRun(a.budget.Within(request.AgentBudget))
 
-	for iteration < MaxAgentIterations {
+	for {
 		if len(currentResponse.Choices) == 0 || currentResponse.Choices[0].Message.ToolCalls == nil || len(*currentResponse.Choices[0].Message.ToolCalls) == 0 {
 			break
 		}
+		iteration := budget.iterations
+		toolCalls := len(*currentResponse.Choices[0].Message.ToolCalls)
+		if limit := budget.exceeded(toolCalls); limit != "" {
+			a.log(ctx).Warn("agent loop budget exceeded", "limit", limit, "iterations", budget.iterations, "tool_calls", budget.toolCalls)
+			currentResponse.Choices[0].FinishReason = types.BudgetExceeded
+			break
+		}
 
-		a.logger.Debug("agent loop iteration", "iteration", iteration+1, "tool_calls", len(*currentResponse.Choices[0].Message.ToolCalls))
+		a.log(ctx).Debug("agent loop iteration", "iteration", iteration+1, "tool_calls", toolCalls)
+		if err := ctx.Err(); err != nil {
+			a.log(ctx).Debug("request cancelled, stopping agent loop", "iteration", iteration+1)
+			return err
+		}
 
-		a.logger.Debug("executing tool calls", "count", len(*currentResponse.Choices[0].Message.ToolCalls))
+		a.log(ctx).Debug("executing tool calls", "count", len(*currentResponse.Choices[0].Message.ToolCalls))
 		toolResults, err := a.ExecuteTools(ctx, *currentResponse.Choices[0].Message.ToolCalls)
 		if err != nil {
-			a.logger.Error("failed to execute tool calls", err, "iteration", iteration+1)
+			a.log(ctx).Error("failed to execute tool calls", err, "iteration", iteration+1)
 			return err
 		}
 
@@ -102,19 +145,15 @@ func (a *agentImpl) Run(ctx context.Context, request *types.CreateChatCompletion
 		currentRequest.Model = *a.model
 		nextResponse, err := a.provider.ChatCompletions(ctx, currentRequest)
 		if err != nil {
-			a.logger.Error("failed to get response in agent loop", err, "iteration", iteration+1, "model", a.model)
+			a.log(ctx).Error("failed to get response in agent loop", err, "iteration", iteration+1, "model", a.model)
 			return err
 		}
 
 		currentResponse = nextResponse
-		iteration++
+		budget.spend(toolCalls)
 	}
 
-	if iteration >= MaxAgentIterations {
-		a.logger.Warn("agent loop reached maximum iterations", "max_iterations", MaxAgentIterations, "iterations_completed", iteration)
-	}
-
-	a.logger.Debug("agent loop completed", "iterations", iteration, "final_choices", len(currentResponse.Choices))
+	a.log(ctx).Debug("agent loop completed", "iterations", budget.iterations, "final_choices", len(currentResponse.Choices))
 
 	*response = currentResponse
 
@@ -140,44 +179,47 @@ func (a *agentImpl) RunWithStream(ctx context.Context, middlewareStreamCh chan [
 	}
 
 	currentRequest := *body
+	budget := newBudgetRun(a.budget.Within(body.AgentBudget))
 
 	currentRequest.Model = *a.model
-	a.logger.Debug("starting agent streaming", "model", currentRequest.Model, "max_iterations", MaxAgentIterations)
+	a.log(ctx).Debug("starting agent streaming", "model", currentRequest.Model, "max_iterations", budget.MaxIterations)
 
 	defer func() {
-		a.logger.Debug("sending agent completion signal")
+		a.log(ctx).Debug("sending agent completion signal")
 		send(ctx, middlewareStreamCh, []byte("data: [DONE]\n\n"))
 	}()
 
-	for iteration := range MaxAgentIterations {
-		a.logger.Debug("streaming iteration", "iteration", iteration+1, "max_iterations", MaxAgentIterations)
+	for {
+		iteration := budget.iterations
+		a.log(ctx).Debug("streaming iteration", "iteration", iteration+1, "max_iterations", budget.MaxIterations)
 
 		streamCh, err := a.provider.StreamChatCompletions(ctx, currentRequest)
 		if err != nil {
-			a.logger.Error("failed to start streaming", err, "iteration", iteration+1, "model", *a.model)
+			a.log(ctx).Error("failed to start streaming", err, "iteration", iteration+1, "model", *a.model)
 			errorData := []byte(fmt.Sprintf("data: {\"error\": \"Failed to start streaming: %s\"}\n\n", err.Error()))
 			send(ctx, middlewareStreamCh, errorData)
 			return err
 		}
 
-		var responseBodyBuilder strings.Builder
+		var toolCallAcc toolcalls.Accumulator
 		assistantMessage := types.Message{
 			Role:      types.Assistant,
 			ToolCalls: nil,
 		}
 		if err := assistantMessage.Content.FromMessageContent0(""); err != nil {
-			a.logger.Error("failed to initialize assistant message content", err)
+			a.log(ctx).Error("failed to initialize assistant message content", err)
 			return err
 		}
 
 		streamComplete := false
 		hasToolCalls := false
+		var lastChunk types.CreateChatCompletionStreamResponse
 
 		for !streamComplete {
 			select {
 			case line, ok := <-streamCh:
 				if !ok {
-					a.logger.Debug("stream channel closed", "iteration", iteration+1)
+					a.log(ctx).Debug("stream channel closed", "iteration", iteration+1)
 					streamComplete = true
 					break
 				}
@@ -186,7 +228,6 @@ func (a *agentImpl) RunWithStream(ctx context.Context, middlewareStreamCh chan [
 				trimmedLine := strings.TrimSpace(lineStr)
 
 				if strings.Contains(trimmedLine, "[DONE]") {
-					responseBodyBuilder.Write(line)
 					continue
 				}
 
@@ -201,17 +242,19 @@ func (a *agentImpl) RunWithStream(ctx context.Context, middlewareStreamCh chan [
 
 				formattedData := []byte(fmt.Sprintf("data: %s\n\n", chunkData))
 				if !send(ctx, middlewareStreamCh, formattedData) {
-					a.logger.Debug("context cancelled while sending stream chunk", "iteration", iteration+1)
+					a.log(ctx).Debug("context cancelled while sending stream chunk", "iteration", iteration+1)
 					return ctx.Err()
 				}
-				responseBodyBuilder.Write(formattedData)
 
 				var resp types.CreateChatCompletionStreamResponse
 				if err := json.Unmarshal([]byte(chunkData), &resp); err != nil {
-					a.logger.Debug("failed to unmarshal streaming chunk", err, "chunk_data", chunkData, "iteration", iteration+1)
+					a.log(ctx).Debug("failed to unmarshal streaming chunk", err, "chunk_data", chunkData, "iteration", iteration+1)
 					continue
 				}
 
+				lastChunk = resp
+				toolCallAcc.AddChunk(resp)
+
 				if len(resp.Choices) == 0 {
 					continue
 				}
@@ -222,20 +265,20 @@ func (a *agentImpl) RunWithStream(ctx context.Context, middlewareStreamCh chan [
 					if currentContent, err := assistantMessage.Content.AsMessageContent0(); err == nil {
 						newContent := currentContent + choice.Delta.Content
 						if err := assistantMessage.Content.FromMessageContent0(newContent); err != nil {
-							a.logger.Debug("failed to update message content", err)
+							a.log(ctx).Debug("failed to update message content", err)
 						}
 					} else {
 						if err := assistantMessage.Content.FromMessageContent0(choice.Delta.Content); err != nil {
-							a.logger.Debug("failed to set message content", err)
+							a.log(ctx).Debug("failed to set message content", err)
 						}
 					}
 				}
 
 				if choice.Delta.ToolCalls != nil && len(*choice.Delta.ToolCalls) > 0 {
-					a.logger.Debug("found tool calls in delta", "count", len(*choice.Delta.ToolCalls), "iteration", iteration+1)
+					a.log(ctx).Debug("found tool calls in delta", "count", len(*choice.Delta.ToolCalls), "iteration", iteration+1)
 					for _, toolCall := range *choice.Delta.ToolCalls {
 						if toolCall.ID != nil || (toolCall.Function != nil && (toolCall.Function.Name != "" || toolCall.Function.Arguments != "")) {
-							a.logger.Debug("valid tool call detected", "id", toolCall.ID, "function_name", toolCall.Function)
+							a.log(ctx).Debug("valid tool call detected", "id", toolCall.ID, "function_name", toolCall.Function)
 							hasToolCalls = true
 							break
 						}
@@ -244,25 +287,25 @@ func (a *agentImpl) RunWithStream(ctx context.Context, middlewareStreamCh chan [
 
 				switch choice.FinishReason {
 				case types.ToolCalls:
-					a.logger.Debug("stream completing due to tool calls finish reason", "finish_reason", string(choice.FinishReason), "iteration", iteration+1)
+					a.log(ctx).Debug("stream completing due to tool calls finish reason", "finish_reason", string(choice.FinishReason), "iteration", iteration+1)
 					streamComplete = true
 				case types.Stop:
-					a.logger.Debug("stream completing due to stop finish reason", "finish_reason", string(choice.FinishReason), "iteration", iteration+1)
+					a.log(ctx).Debug("stream completing due to stop finish reason", "finish_reason", string(choice.FinishReason), "iteration", iteration+1)
 					streamComplete = true
 				}
 
 			case <-ctx.Done():
-				a.logger.Debug("context cancelled during streaming", "iteration", iteration+1)
+				a.log(ctx).Debug("context cancelled during streaming", "iteration", iteration+1)
 				return ctx.Err()
 			}
 		}
 
-		a.logger.Debug("stream completed for iteration", "iteration", iteration+1, "has_tool_calls", hasToolCalls)
+		a.log(ctx).Debug("stream completed for iteration", "iteration", iteration+1, "has_tool_calls", hasToolCalls)
 
 		var toolCalls []types.ChatCompletionMessageToolCall
 		if hasToolCalls {
-			toolCalls = types.AccumulateStreamingToolCalls(responseBodyBuilder.String())
-			a.logger.Debug("parsed tool calls from stream", "count", len(toolCalls), "iteration", iteration+1)
+			toolCalls = toolCallAcc.ToolCalls()
+			a.log(ctx).Debug("parsed tool calls from stream", "count", len(toolCalls), "iteration", iteration+1)
 		}
 
 		if len(toolCalls) > 0 {
@@ -270,14 +313,33 @@ func (a *agentImpl) RunWithStream(ctx context.Context, middlewareStreamCh chan [
 		}
 
 		if len(toolCalls) == 0 {
-			a.logger.Debug("no tool calls found, ending agent loop", "iteration", iteration+1)
+			a.log(ctx).Debug("no tool calls found, ending agent loop", "iteration", iteration+1)
+			return nil
+		}
+
+		if limit := budget.exceeded(len(toolCalls)); limit != "" {
+			a.log(ctx).Warn("agent streaming budget exceeded", "limit", limit, "iterations", budget.iterations, "tool_calls", budget.toolCalls)
+			send(ctx, middlewareStreamCh, budgetExceededChunk(lastChunk))
 			return nil
 		}
 
-		a.logger.Debug("executing tool calls", "count", len(toolCalls), "iteration", iteration+1)
-		toolResults, err := a.ExecuteTools(ctx, toolCalls)
+		if err := ctx.Err(); err != nil {
+			a.log(ctx).Debug("request cancelled, stopping agent loop", "iteration", iteration+1)
+			return err
+		}
+
+		var progress func(types.ChatCompletionMessageToolCall, types.ToolProgressStatus)
+		if a.toolProgress {
+			chunk := lastChunk
+			progress = func(toolCall types.ChatCompletionMessageToolCall, status types.ToolProgressStatus) {
+				send(ctx, middlewareStreamCh, toolProgressChunk(chunk, toolCall, status))
+			}
+		}
+
+		a.log(ctx).Debug("executing tool calls", "count", len(toolCalls), "iteration", iteration+1)
+		toolResults, err := a.executeTools(ctx, toolCalls, progress)
 		if err != nil {
-			a.logger.Error("failed to execute tool calls", err, "iteration", iteration+1, "tool_count", len(toolCalls))
+			a.log(ctx).Error("failed to execute tool calls", err, "iteration", iteration+1, "tool_count", len(toolCalls))
 			errorData := []byte(fmt.Sprintf("data: {\"error\": \"Failed to execute tools: %s\"}\n\n", err.Error()))
 			send(ctx, middlewareStreamCh, errorData)
 			return err
@@ -287,102 +349,151 @@ func (a *agentImpl) RunWithStream(ctx context.Context, middlewareStreamCh chan [
 		currentRequest.Messages = append(currentRequest.Messages, toolResults...)
 		currentRequest.Model = *a.model
 
-		a.logger.Debug("tool execution complete, continuing to next iteration",
+		budget.spend(len(toolCalls))
+
+		a.log(ctx).Debug("tool execution complete, continuing to next iteration",
 			"tool_results", len(toolResults), "total_messages", len(currentRequest.Messages), "iteration", iteration+1)
 	}
+}
 
-	a.logger.Warn("agent streaming reached maximum iterations", "max_iterations", MaxAgentIterations, "iterations_completed", MaxAgentIterations)
-	return nil
+// budgetExceededChunk returns the chunk ending a stream whose agent loop
+// stopped at its budget, in the shape of last, the previous chunk
+func budgetExceededChunk(last types.CreateChatCompletionStreamResponse) []byte {
+	chunk := types.CreateChatCompletionStreamResponse{
+		ID:      last.ID,
+		Object:  "chat.completion.chunk",
+		Created: last.Created,
+		Model:   last.Model,
+		Choices: []types.ChatCompletionStreamChoice{{
+			Index:        0,
+			Delta:        types.ChatCompletionStreamResponseDelta{Role: types.Assistant},
+			FinishReason: types.BudgetExceeded,
+		}},
+	}
+	data, _ := json.Marshal(chunk)
+	return []byte(fmt.Sprintf("data: %s\n\n", data))
+}
+
+// toolProgressChunk returns the chunk reporting the status of toolCall, in
+// the shape of last, the previous chunk. Its delta is an empty assistant
+// message, so clients unaware of tool_progress skip it.
+func toolProgressChunk(last types.CreateChatCompletionStreamResponse, toolCall types.ChatCompletionMessageToolCall, status types.ToolProgressStatus) []byte {
+	chunk := types.CreateChatCompletionStreamResponse{
+		ID:      last.ID,
+		Object:  "chat.completion.chunk",
+		Created: last.Created,
+		Model:   last.Model,
+		Choices: []types.ChatCompletionStreamChoice{{
+			Index: 0,
+			Delta: types.ChatCompletionStreamResponseDelta{
+				Role: types.Assistant,
+				ToolProgress: &types.ToolProgress{
+					ToolCallID: toolCall.ID,
+					Name:       toolCall.Function.Name,
+					Status:     status,
+				},
+			},
+		}},
+	}
+	data, _ := json.Marshal(chunk)
+	return []byte(fmt.Sprintf("data: %s\n\n", data))
 }
 
-// ExecuteTools executes tools with the provided context, tool name, and arguments
+// ExecuteTools executes tools with the provided context, tool name, and arguments.
+// Up to toolConcurrency calls run at once; results keep the order of toolCalls.
+// Once ctx is done, calls not started yet are skipped and its error returned.
 func (a *agentImpl) ExecuteTools(ctx context.Context, toolCalls []types.ChatCompletionMessageToolCall) ([]types.Message, error) {
-	var results []types.Message
-
-	for _, toolCall := range toolCalls {
-		var args map[string]any
-		if err := json.Unmarshal([]byte(toolCall.Function.Arguments), &args); err != nil {
-			a.logger.Error("failed to parse tool arguments", err, "args", toolCall.Function.Arguments, "tool_name", toolCall.Function.Name)
-			msg := types.Message{
-				Role:       types.Tool,
-				ToolCallID: &toolCall.ID,
-			}
-			if contentErr := msg.Content.FromMessageContent0(fmt.Sprintf("Error: Failed to parse arguments: %v", err)); contentErr != nil {
-				a.logger.Error("failed to set error content", contentErr)
-			}
-			results = append(results, msg)
-			continue
-		}
+	return a.executeTools(ctx, toolCalls, nil)
+}
 
-		var server string
-		toolName := strings.TrimPrefix(toolCall.Function.Name, "mcp_")
-		toolCtx, span := otelapi.Tracer("github.com/inference-gateway/inference-gateway/internal/mcp").
-			Start(ctx, "execute_tool "+toolName, trace.WithAttributes(semconv.GenAIToolName(toolName)))
-		server, err := a.mcpClient.GetServerForTool(toolName)
+// executeTools is ExecuteTools reporting the status of every call to
+// progress, when set, as it starts and ends
+func (a *agentImpl) executeTools(ctx context.Context, toolCalls []types.ChatCompletionMessageToolCall, progress func(types.ChatCompletionMessageToolCall, types.ToolProgressStatus)) ([]types.Message, error) {
+	results := make([]types.Message, len(toolCalls))
+	errs := make([]error, len(toolCalls))
+	execute := func(toolCall types.ChatCompletionMessageToolCall) (types.Message, error) {
+		// Calls still queued when the request is cancelled never start
+		if err := ctx.Err(); err != nil {
+			return types.Message{}, err
+		}
+		if progress == nil {
+			return a.executeTool(ctx, toolCall)
+		}
+		progress(toolCall, types.ToolProgressStatusInProgress)
+		result, err := a.executeTool(ctx, toolCall)
 		if err != nil {
-			span.SetStatus(codes.Error, err.Error())
-			span.End()
-			a.logger.Error("failed to find server for tool", err, "tool", toolCall.Function.Name, "tool_name", toolName)
-			msg := types.Message{
-				Role:       types.Tool,
-				ToolCallID: &toolCall.ID,
-			}
-			if contentErr := msg.Content.FromMessageContent0(fmt.Sprintf("Error: %v", err)); contentErr != nil {
-				a.logger.Error("failed to set error content", contentErr)
-			}
-			results = append(results, msg)
-			continue
+			progress(toolCall, types.ToolProgressStatusFailed)
+		} else {
+			progress(toolCall, types.ToolProgressStatusCompleted)
 		}
-		span.SetAttributes(attribute.String("mcp.server.url", server))
+		return result, err
+	}
 
-		mcpRequest := Request{
-			Method: "tools/call",
-			Params: map[string]any{
-				"name":      toolName,
-				"arguments": args,
-			},
+	if a.toolConcurrency <= 1 || len(toolCalls) <= 1 {
+		for i, toolCall := range toolCalls {
+			results[i], errs[i] = execute(toolCall)
 		}
-
-		a.logger.Info("executing tool call", "tool_call", fmt.Sprintf("id=%s name=%s mcp_name=%s args=%v server=%s", toolCall.ID, toolCall.Function.Name, toolName, args, server))
-		result, err := a.mcpClient.ExecuteTool(toolCtx, mcpRequest, server)
-		if err != nil {
-			span.SetStatus(codes.Error, err.Error())
-			span.End()
-			a.logger.Error("failed to execute tool call", err, "tool", toolCall.Function.Name, "server", server)
-			msg := types.Message{
-				Role:       types.Tool,
-				ToolCallID: &toolCall.ID,
-			}
-			if contentErr := msg.Content.FromMessageContent0(fmt.Sprintf("Error: %v", err)); contentErr != nil {
-				a.logger.Error("failed to set error content", contentErr)
+	} else {
+		a.log(ctx).Debug("executing tool calls concurrently", "tool_calls", len(toolCalls), "concurrency", a.toolConcurrency)
+		sem := make(chan struct{}, a.toolConcurrency)
+		var wg sync.WaitGroup
+		for i, toolCall := range toolCalls {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				continue
 			}
-			results = append(results, msg)
-			continue
+			wg.Go(func() {
+				defer func() { <-sem }()
+				results[i], errs[i] = execute(toolCall)
+			})
 		}
-		span.End()
+		wg.Wait()
+	}
 
-		var resultStr string
-		if result == nil {
-			resultStr = "null"
-		} else {
-			resultBytes, err := json.Marshal(result)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// executeTool runs a single tool call on the first executor handling it and
+// shortens its result to the result limit
+func (a *agentImpl) executeTool(ctx context.Context, toolCall types.ChatCompletionMessageToolCall) (types.Message, error) {
+	for _, executor := range a.executors {
+		if executor.Handles(toolCall.Function.Name) {
+			result, err := executor.Execute(ctx, toolCall)
 			if err != nil {
-				resultStr = fmt.Sprintf("Error marshaling result: %v", err)
-			} else {
-				resultStr = string(resultBytes)
+				return result, err
 			}
+			return a.limitResult(ctx, toolCall, result)
 		}
-
-		msg := types.Message{
-			Role:       types.Tool,
-			ToolCallID: &toolCall.ID,
-		}
-		if err := msg.Content.FromMessageContent0(resultStr); err != nil {
-			a.logger.Error("failed to set tool result content", err)
-			return nil, err
-		}
-		results = append(results, msg)
 	}
+	a.log(ctx).Warn("no executor for tool call", "tool", toolCall.Function.Name)
+	return toolMessage(toolCall.ID, fmt.Sprintf("Error: unknown tool %s", toolCall.Function.Name))
+}
 
-	return results, nil
+// limitResult shortens the content of the result of toolCall to the result
+// limit. Multimodal results are converted to their text.
+func (a *agentImpl) limitResult(ctx context.Context, toolCall types.ChatCompletionMessageToolCall, result types.Message) (types.Message, error) {
+	if a.resultLimit.MaxBytes <= 0 {
+		return result, nil
+	}
+	text := result.TextContent()
+	limited, truncated, err := a.resultLimit.Apply(ctx, text)
+	if err != nil {
+		a.log(ctx).Warn("failed to summarize tool result, truncated it instead", "tool", toolCall.Function.Name, "error", err.Error())
+	}
+	if !truncated {
+		return result, nil
+	}
+	a.log(ctx).Debug("truncated tool result", "tool", toolCall.Function.Name, "bytes", len(text), "max_bytes", a.resultLimit.MaxBytes, "truncation", a.resultLimit.Truncation)
+	if err := result.Content.FromMessageContent0(limited); err != nil {
+		return types.Message{}, fmt.Errorf("set tool result content: %w", err)
+	}
+	return result, nil
 }
@@ -0,0 +1,190 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	oauth2 "golang.org/x/oauth2"
+	clientcredentials "golang.org/x/oauth2/clientcredentials"
+	yaml "gopkg.in/yaml.v3"
+
+	config "github.com/inference-gateway/inference-gateway/config"
+	client "github.com/inference-gateway/inference-gateway/providers/client"
+)
+
+// ServerAuth is the authentication and TLS settings of one MCP server. ${VAR}
+// references in header values, the bearer token and the OAuth client
+// credentials are expanded from the environment so secrets need not be
+// written to the file.
+type ServerAuth struct {
+	// Headers are sent with every request to the server
+	Headers     map[string]string `yaml:"headers"`
+	BearerToken string            `yaml:"bearer_token"`
+	OAuth       *OAuthCredentials `yaml:"oauth"`
+	TLS         *ServerTLS        `yaml:"tls"`
+}
+
+// OAuthCredentials are the OAuth2 client credentials the gateway exchanges
+// for the access tokens of a server. Tokens are cached and fetched again
+// shortly before they expire.
+type OAuthCredentials struct {
+	TokenURL     string   `yaml:"token_url"`
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	Scopes       []string `yaml:"scopes"`
+	Audience     string   `yaml:"audience"`
+}
+
+// ServerTLS are the PEM files used to reach a server instead of the
+// CLIENT_TLS_* ones
+type ServerTLS struct {
+	CAPath   string `yaml:"ca_path"`
+	CertPath string `yaml:"cert_path"`
+	KeyPath  string `yaml:"key_path"`
+}
+
+// ServersConfig is the on-disk MCP servers file: the settings of URL-based
+// servers keyed by URL, and the stdio servers the gateway runs, keyed by name
+type ServersConfig struct {
+	Servers map[string]ServerAuth  `yaml:"servers"`
+	Stdio   map[string]StdioServer `yaml:"stdio"`
+}
+
+// LoadServersConfig reads, expands and validates the MCP servers YAML file
+// at path
+func LoadServersConfig(path string) (*ServersConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read mcp servers config: %w", err)
+	}
+	var cfg ServersConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse mcp servers config: %w", err)
+	}
+
+	servers := make(map[string]ServerAuth, len(cfg.Servers))
+	for serverURL, auth := range cfg.Servers {
+		serverURL = strings.TrimSpace(serverURL)
+		if u, err := url.Parse(serverURL); err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("mcp servers config: invalid server url %q", serverURL)
+		}
+		for name, value := range auth.Headers {
+			auth.Headers[name] = os.ExpandEnv(value)
+		}
+		auth.BearerToken = os.ExpandEnv(auth.BearerToken)
+		if auth.OAuth != nil {
+			if auth.BearerToken != "" {
+				return nil, fmt.Errorf("mcp server %s: bearer_token and oauth are mutually exclusive", serverURL)
+			}
+			if auth.OAuth.TokenURL == "" || auth.OAuth.ClientID == "" {
+				return nil, fmt.Errorf("mcp server %s: oauth requires token_url and client_id", serverURL)
+			}
+			auth.OAuth.ClientID = os.ExpandEnv(auth.OAuth.ClientID)
+			auth.OAuth.ClientSecret = os.ExpandEnv(auth.OAuth.ClientSecret)
+		}
+		if auth.TLS != nil && (auth.TLS.CertPath == "") != (auth.TLS.KeyPath == "") {
+			return nil, fmt.Errorf("mcp server %s: tls cert_path and key_path must be set together", serverURL)
+		}
+		servers[serverURL] = auth
+	}
+	cfg.Servers = servers
+
+	for name, server := range cfg.Stdio {
+		if !stdioNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("mcp servers config: invalid stdio server name %q", name)
+		}
+		if server.Command == "" {
+			return nil, fmt.Errorf("mcp stdio server %s: command is required", name)
+		}
+		for key, value := range server.Env {
+			server.Env[key] = os.ExpandEnv(value)
+		}
+	}
+	return &cfg, nil
+}
+
+// serverAuth authenticates the requests to one MCP server
+type serverAuth struct {
+	headers map[string]string
+	bearer  string
+	// tokens issues the OAuth access tokens, nil without OAuth
+	tokens oauth2.TokenSource
+	tls    *client.TLSFiles
+}
+
+// newServerAuth prepares the authentication of auth, fetching OAuth tokens
+// with the gateway's MCP client settings
+func newServerAuth(auth ServerAuth, cfg config.Config) (*serverAuth, error) {
+	a := &serverAuth{headers: auth.Headers, bearer: auth.BearerToken}
+	if auth.TLS != nil {
+		a.tls = &client.TLSFiles{CAPath: auth.TLS.CAPath, CertPath: auth.TLS.CertPath, KeyPath: auth.TLS.KeyPath}
+	}
+	if auth.OAuth == nil {
+		return a, nil
+	}
+
+	tlsConfig, err := client.TLSConfig(cfg.Client, auth.OAuth.TokenURL)
+	if err != nil {
+		return nil, fmt.Errorf("oauth token endpoint tls: %w", err)
+	}
+	httpClient := &http.Client{
+		Timeout:   cfg.MCP.ClientTimeout,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment},
+	}
+	credentials := clientcredentials.Config{
+		ClientID:     auth.OAuth.ClientID,
+		ClientSecret: auth.OAuth.ClientSecret,
+		TokenURL:     auth.OAuth.TokenURL,
+		Scopes:       auth.OAuth.Scopes,
+	}
+	if auth.OAuth.Audience != "" {
+		credentials.EndpointParams = url.Values{"audience": {auth.OAuth.Audience}}
+	}
+	a.tokens = credentials.TokenSource(context.WithValue(context.Background(), oauth2.HTTPClient, httpClient))
+	return a, nil
+}
+
+// apply sets the credentials of the server on req
+func (a *serverAuth) apply(req *http.Request) error {
+	for name, value := range a.headers {
+		req.Header.Set(name, value)
+	}
+	if a.bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+a.bearer)
+	}
+	if a.tokens != nil {
+		token, err := a.tokens.Token()
+		if err != nil {
+			return fmt.Errorf("fetch mcp oauth token: %w", err)
+		}
+		token.SetAuthHeader(req)
+	}
+	return nil
+}
+
+// loadServersConfig reads MCP_SERVERS_CONFIG_PATH, or returns an empty
+// configuration when it is not set
+func loadServersConfig(cfg config.Config) (*ServersConfig, error) {
+	if cfg.MCP == nil || cfg.MCP.ServersConfigPath == "" {
+		return &ServersConfig{}, nil
+	}
+	return LoadServersConfig(cfg.MCP.ServersConfigPath)
+}
+
+// newServerAuths prepares the authentication of the URL-based servers of
+// servers, keyed by server URL
+func newServerAuths(servers *ServersConfig, cfg config.Config) (map[string]*serverAuth, error) {
+	auths := make(map[string]*serverAuth, len(servers.Servers))
+	for serverURL, auth := range servers.Servers {
+		a, err := newServerAuth(auth, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("mcp server %s: %w", serverURL, err)
+		}
+		auths[serverURL] = a
+	}
+	return auths, nil
+}
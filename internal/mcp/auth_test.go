@@ -0,0 +1,138 @@
+package mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	config "github.com/inference-gateway/inference-gateway/config"
+)
+
+func writeServersConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "mcp-servers.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadServersConfig(t *testing.T) {
+	t.Setenv("GITHUB_MCP_TOKEN", "ghp_secret")
+	t.Setenv("LINEAR_CLIENT_SECRET", "linear_secret")
+
+	cfg, err := LoadServersConfig(writeServersConfig(t, `
+servers:
+  https://api.githubcopilot.com/mcp/:
+    bearer_token: ${GITHUB_MCP_TOKEN}
+    headers:
+      X-MCP-Toolsets: repos,issues
+  https://mcp.linear.app/mcp:
+    oauth:
+      token_url: https://auth.linear.app/oauth/token
+      client_id: gateway
+      client_secret: ${LINEAR_CLIENT_SECRET}
+      scopes: [read]
+`))
+	require.NoError(t, err)
+	github := cfg.Servers["https://api.githubcopilot.com/mcp/"]
+	assert.Equal(t, "ghp_secret", github.BearerToken)
+	assert.Equal(t, map[string]string{"X-MCP-Toolsets": "repos,issues"}, github.Headers)
+	linear := cfg.Servers["https://mcp.linear.app/mcp"]
+	require.NotNil(t, linear.OAuth)
+	assert.Equal(t, "linear_secret", linear.OAuth.ClientSecret)
+	assert.Equal(t, []string{"read"}, linear.OAuth.Scopes)
+}
+
+func TestLoadServersConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{
+			name:    "relative url",
+			content: "servers:\n  mcp-server/mcp:\n    bearer_token: x\n",
+			err:     "invalid server url",
+		},
+		{
+			name:    "bearer token and oauth",
+			content: "servers:\n  http://mcp:8080/mcp:\n    bearer_token: x\n    oauth:\n      token_url: http://auth/token\n      client_id: id\n",
+			err:     "mutually exclusive",
+		},
+		{
+			name:    "oauth without token url",
+			content: "servers:\n  http://mcp:8080/mcp:\n    oauth:\n      client_id: id\n",
+			err:     "requires token_url and client_id",
+		},
+		{
+			name:    "certificate without key",
+			content: "servers:\n  http://mcp:8080/mcp:\n    tls:\n      cert_path: /certs/client.pem\n",
+			err:     "must be set together",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadServersConfig(writeServersConfig(t, tt.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestServerAuth_OAuthClientCredentials(t *testing.T) {
+	var tokenRequests atomic.Int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests.Add(1)
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.Form.Get("grant_type"))
+		assert.Equal(t, "https://mcp.example.com", r.Form.Get("audience"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"access_token":"token-1","token_type":"Bearer","expires_in":3600}`)
+	}))
+	defer tokenServer.Close()
+
+	auth, err := newServerAuth(ServerAuth{
+		Headers: map[string]string{"X-Team": "platform"},
+		OAuth: &OAuthCredentials{
+			TokenURL:     tokenServer.URL,
+			ClientID:     "gateway",
+			ClientSecret: "secret",
+			Audience:     "https://mcp.example.com",
+		},
+	}, config.Config{MCP: &config.MCPConfig{ClientTimeout: 5 * time.Second}})
+	require.NoError(t, err)
+
+	var authorizations []string
+	rt := &customRoundTripper{
+		auth: auth,
+		base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "platform", req.Header.Get("X-Team"))
+			authorizations = append(authorizations, req.Header.Get("Authorization"))
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{}`)),
+			}, nil
+		}),
+	}
+	for range 2 {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://mcp.local/mcp", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer caller-token")
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-1"}, authorizations, "the caller's credentials are replaced by the server's")
+	assert.Equal(t, int32(1), tokenRequests.Load(), "tokens are cached until they expire")
+}
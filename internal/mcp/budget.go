@@ -0,0 +1,93 @@
+package mcp
+
+import (
+	"time"
+
+	config "github.com/inference-gateway/inference-gateway/config"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// DefaultMaxAgentIterations limits the agent loop iterations when
+// AGENT_MAX_ITERATIONS is not set
+const DefaultMaxAgentIterations = 10
+
+// Budget limits for the agent loop
+const (
+	BudgetIterations = "iterations"
+	BudgetToolCalls  = "tool_calls"
+	BudgetDuration   = "duration"
+)
+
+// Budget bounds the agent loop of a request. A zero MaxToolCalls or
+// MaxDuration is unlimited.
+type Budget struct {
+	MaxIterations int
+	MaxToolCalls  int
+	MaxDuration   time.Duration
+}
+
+// BudgetFromConfig returns the server-wide budget set by AGENT_MAX_ITERATIONS,
+// AGENT_MAX_TOOL_CALLS and AGENT_MAX_DURATION
+func BudgetFromConfig(cfg config.Config) Budget {
+	budget := Budget{
+		MaxIterations: cfg.AgentMaxIterations,
+		MaxToolCalls:  max(cfg.AgentMaxToolCalls, 0),
+		MaxDuration:   max(cfg.AgentMaxDuration, 0),
+	}
+	if budget.MaxIterations < 1 {
+		budget.MaxIterations = DefaultMaxAgentIterations
+	}
+	return budget
+}
+
+// Within returns the budget of a request asking for requested: each of its
+// limits may only lower the one of b
+func (b Budget) Within(requested *types.AgentBudget) Budget {
+	if requested == nil {
+		return b
+	}
+	if n := requested.MaxIterations; n != nil && *n > 0 {
+		b.MaxIterations = min(b.MaxIterations, *n)
+	}
+	if n := requested.MaxToolCalls; n != nil && *n > 0 && (b.MaxToolCalls == 0 || *n < b.MaxToolCalls) {
+		b.MaxToolCalls = *n
+	}
+	if n := requested.MaxDurationSeconds; n != nil && *n > 0 {
+		if d := time.Duration(*n) * time.Second; b.MaxDuration == 0 || d < b.MaxDuration {
+			b.MaxDuration = d
+		}
+	}
+	return b
+}
+
+// budgetRun tracks what an agent loop spent of its budget
+type budgetRun struct {
+	Budget
+	start      time.Time
+	iterations int
+	toolCalls  int
+}
+
+func newBudgetRun(budget Budget) *budgetRun {
+	return &budgetRun{Budget: budget, start: time.Now()}
+}
+
+// exceeded returns the limit a round executing calls tool calls would
+// exceed, "" when it fits the budget
+func (r *budgetRun) exceeded(calls int) string {
+	switch {
+	case r.iterations >= r.MaxIterations:
+		return BudgetIterations
+	case r.MaxToolCalls > 0 && r.toolCalls+calls > r.MaxToolCalls:
+		return BudgetToolCalls
+	case r.MaxDuration > 0 && time.Since(r.start) >= r.MaxDuration:
+		return BudgetDuration
+	}
+	return ""
+}
+
+// spend records a round of calls tool calls
+func (r *budgetRun) spend(calls int) {
+	r.iterations++
+	r.toolCalls += calls
+}
@@ -0,0 +1,70 @@
+package mcp
+
+import (
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/assert"
+
+	config "github.com/inference-gateway/inference-gateway/config"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func TestBudgetWithin(t *testing.T) {
+	n := func(v int) *int { return &v }
+	tests := []struct {
+		name      string
+		server    Budget
+		requested *types.AgentBudget
+		expected  Budget
+	}{
+		{
+			name:     "server budget without a request budget",
+			server:   Budget{MaxIterations: 10, MaxToolCalls: 50, MaxDuration: time.Minute},
+			expected: Budget{MaxIterations: 10, MaxToolCalls: 50, MaxDuration: time.Minute},
+		},
+		{
+			name:      "request lowers every limit",
+			server:    Budget{MaxIterations: 10, MaxToolCalls: 50, MaxDuration: time.Minute},
+			requested: &types.AgentBudget{MaxIterations: n(3), MaxToolCalls: n(5), MaxDurationSeconds: n(10)},
+			expected:  Budget{MaxIterations: 3, MaxToolCalls: 5, MaxDuration: 10 * time.Second},
+		},
+		{
+			name:      "request cannot raise the caps",
+			server:    Budget{MaxIterations: 10, MaxToolCalls: 50, MaxDuration: time.Minute},
+			requested: &types.AgentBudget{MaxIterations: n(100), MaxToolCalls: n(500), MaxDurationSeconds: n(600)},
+			expected:  Budget{MaxIterations: 10, MaxToolCalls: 50, MaxDuration: time.Minute},
+		},
+		{
+			name:      "request limits unlimited server limits",
+			server:    Budget{MaxIterations: 10},
+			requested: &types.AgentBudget{MaxToolCalls: n(5), MaxDurationSeconds: n(30)},
+			expected:  Budget{MaxIterations: 10, MaxToolCalls: 5, MaxDuration: 30 * time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.server.Within(tt.requested))
+		})
+	}
+}
+
+func TestBudgetFromConfig(t *testing.T) {
+	assert.Equal(t, Budget{MaxIterations: DefaultMaxAgentIterations}, BudgetFromConfig(config.Config{}))
+	assert.Equal(t, Budget{MaxIterations: 25, MaxToolCalls: 100, MaxDuration: 2 * time.Minute},
+		BudgetFromConfig(config.Config{AgentMaxIterations: 25, AgentMaxToolCalls: 100, AgentMaxDuration: 2 * time.Minute}))
+}
+
+func TestBudgetRunExceeded(t *testing.T) {
+	run := newBudgetRun(Budget{MaxIterations: 2, MaxToolCalls: 3})
+	assert.Empty(t, run.exceeded(2))
+	run.spend(2)
+	assert.Equal(t, BudgetToolCalls, run.exceeded(2))
+	assert.Empty(t, run.exceeded(1))
+	run.spend(1)
+	assert.Equal(t, BudgetIterations, run.exceeded(1))
+
+	run = newBudgetRun(Budget{MaxIterations: 10, MaxDuration: time.Millisecond})
+	run.start = time.Now().Add(-time.Second)
+	assert.Equal(t, BudgetDuration, run.exceeded(1))
+}
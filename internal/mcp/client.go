@@ -3,6 +3,7 @@ package mcp
 import (
 	"context"
 	"errors"
+	"os/exec"
 	"sync"
 
 	m "github.com/metoro-io/mcp-golang"
@@ -24,6 +25,12 @@ var (
 
 	// ErrNoClientsInitialized is returned when no clients could be initialized
 	ErrNoClientsInitialized = errors.New("no mcp clients could be initialized")
+
+	// ErrPromptNotFound is returned when no server offers the requested prompt
+	ErrPromptNotFound = errors.New("mcp prompt not found")
+
+	// ErrResourceNotFound is returned when no server lists the requested resource
+	ErrResourceNotFound = errors.New("mcp resource not found")
 )
 
 // ServerStatus represents the status of an MCP server
@@ -63,6 +70,18 @@ type MCPClientInterface interface {
 	// GetServerForTool returns the server URL that provides the specified tool
 	GetServerForTool(toolName string) (string, error)
 
+	// ListResources returns the resources of the specified server
+	ListResources(ctx context.Context, serverURL string) ([]Resource, error)
+
+	// ReadResource reads the contents of a resource of the specified server
+	ReadResource(ctx context.Context, serverURL string, uri string) (*ReadResourceResult, error)
+
+	// ListPrompts returns the prompts of the specified server
+	ListPrompts(ctx context.Context, serverURL string) ([]Prompt, error)
+
+	// GetPrompt renders a prompt of the specified server with arguments
+	GetPrompt(ctx context.Context, serverURL string, name string, arguments map[string]string) (*GetPromptResult, error)
+
 	// BuildSSEFallbackURL creates an SSE fallback URL from the main server URL (exposed for testing)
 	BuildSSEFallbackURL(serverURL string) string
 
@@ -80,6 +99,10 @@ type MCPClientInterface interface {
 	// EnableReconnect is true). Safe to call even if reconnection was never
 	// started.
 	StopBackgroundReconnection()
+
+	// UpdateServers replaces the list of MCP servers at runtime: removed
+	// servers are dropped with their tools and added ones are initialized
+	UpdateServers(ctx context.Context, serverURLs []string) error
 }
 
 // MCPClient provides methods to interact with MCP servers
@@ -94,6 +117,16 @@ type MCPClient struct {
 	initialized         bool
 	serverStatuses      map[string]ServerStatus
 	reconnecting        map[string]struct{}
+	// auth authenticates the requests to the servers of
+	// MCP_SERVERS_CONFIG_PATH, keyed by URL
+	auth map[string]*serverAuth
+	// stdio are the stdio servers of MCP_SERVERS_CONFIG_PATH, keyed by
+	// name, and processes their running processes, keyed by server URL
+	stdio     map[string]StdioServer
+	processes map[string]*exec.Cmd
+	// sampler serves the sampling requests of the servers, nil when
+	// sampling is disabled
+	sampler Sampler
 
 	pollingCancel   context.CancelFunc
 	pollingDone     chan struct{}
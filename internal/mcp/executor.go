@@ -0,0 +1,109 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+	otelapi "go.opentelemetry.io/otel"
+	attribute "go.opentelemetry.io/otel/attribute"
+	codes "go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	trace "go.opentelemetry.io/otel/trace"
+)
+
+// ToolExecutor runs the tool calls of one source of tools, such as the MCP
+// servers or tools built into the gateway. The agent loop routes each call to
+// the first of its executors handling it, so one model response may call
+// tools of several sources.
+type ToolExecutor interface {
+	// Handles reports whether the executor runs the tool the model called
+	// name
+	Handles(name string) bool
+	// Execute runs toolCall and returns its result message. Tool failures
+	// are reported to the model in the message content; an error is only
+	// returned when the message itself cannot be built.
+	Execute(ctx context.Context, toolCall types.ChatCompletionMessageToolCall) (types.Message, error)
+}
+
+// mcpExecutor runs tool calls on the MCP servers. It handles every tool and
+// answers calls of tools no server offers with an error, so it goes last.
+type mcpExecutor struct {
+	logger    logger.Logger
+	mcpClient MCPClientInterface
+}
+
+// NewMCPToolExecutor returns the executor of the tools of the MCP servers of
+// mcpClient, offered to the model with the "mcp_" prefix
+func NewMCPToolExecutor(logger logger.Logger, mcpClient MCPClientInterface) ToolExecutor {
+	return &mcpExecutor{logger: logger, mcpClient: mcpClient}
+}
+
+func (e *mcpExecutor) Handles(string) bool {
+	return true
+}
+
+func (e *mcpExecutor) Execute(ctx context.Context, toolCall types.ChatCompletionMessageToolCall) (types.Message, error) {
+	log := logger.FromContext(ctx, e.logger)
+	var args map[string]any
+	if err := json.Unmarshal([]byte(toolCall.Function.Arguments), &args); err != nil {
+		log.Error("failed to parse tool arguments", err, "args", toolCall.Function.Arguments, "tool_name", toolCall.Function.Name)
+		return toolMessage(toolCall.ID, fmt.Sprintf("Error: Failed to parse arguments: %v", err))
+	}
+
+	toolName := strings.TrimPrefix(toolCall.Function.Name, "mcp_")
+	toolCtx, span := otelapi.Tracer("github.com/inference-gateway/inference-gateway/internal/mcp").
+		Start(ctx, "execute_tool "+toolName, trace.WithAttributes(semconv.GenAIToolName(toolName)))
+	defer span.End()
+	server, err := e.mcpClient.GetServerForTool(toolName)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to find server for tool", err, "tool", toolCall.Function.Name, "tool_name", toolName)
+		return toolMessage(toolCall.ID, fmt.Sprintf("Error: %v", err))
+	}
+	span.SetAttributes(attribute.String("mcp.server.url", server))
+
+	mcpRequest := Request{
+		Method: "tools/call",
+		Params: map[string]any{
+			"name":      toolName,
+			"arguments": args,
+		},
+	}
+
+	log.Info("executing tool call", "tool_call", fmt.Sprintf("id=%s name=%s mcp_name=%s args=%v server=%s", toolCall.ID, toolCall.Function.Name, toolName, args, server))
+	result, err := e.mcpClient.ExecuteTool(toolCtx, mcpRequest, server)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to execute tool call", err, "tool", toolCall.Function.Name, "server", server)
+		return toolMessage(toolCall.ID, fmt.Sprintf("Error: %v", err))
+	}
+
+	var resultStr string
+	if result == nil {
+		resultStr = "null"
+	} else {
+		resultBytes, err := json.Marshal(result)
+		if err != nil {
+			resultStr = fmt.Sprintf("Error marshaling result: %v", err)
+		} else {
+			resultStr = string(resultBytes)
+		}
+	}
+	return toolMessage(toolCall.ID, resultStr)
+}
+
+// toolMessage returns the result message of tool call id
+func toolMessage(id, content string) (types.Message, error) {
+	msg := types.Message{
+		Role:       types.Tool,
+		ToolCallID: &id,
+	}
+	if err := msg.Content.FromMessageContent0(content); err != nil {
+		return types.Message{}, fmt.Errorf("set tool result content: %w", err)
+	}
+	return msg, nil
+}
@@ -0,0 +1,119 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// prefixExecutor handles the tools named with its prefix, answering with
+// its name
+type prefixExecutor struct {
+	prefix string
+	name   string
+}
+
+func (e prefixExecutor) Handles(name string) bool {
+	return strings.HasPrefix(name, e.prefix)
+}
+
+func (e prefixExecutor) Execute(_ context.Context, toolCall types.ChatCompletionMessageToolCall) (types.Message, error) {
+	return toolMessage(toolCall.ID, e.name+": "+toolCall.Function.Name)
+}
+
+func TestAgentRoutesToolCalls(t *testing.T) {
+	agent := NewAgentWithExecutors(logger.NewNoopLogger(), config.Config{MCP: &config.MCPConfig{ToolConcurrency: 4}},
+		prefixExecutor{prefix: "builtin_", name: "builtin"},
+		prefixExecutor{prefix: "mcp_", name: "mcp"},
+	)
+
+	var calls []types.ChatCompletionMessageToolCall
+	for i, name := range []string{"mcp_read_file", "builtin_time", "a2a_agent"} {
+		calls = append(calls, types.ChatCompletionMessageToolCall{
+			ID:       string(rune('a' + i)),
+			Type:     types.Function,
+			Function: types.ChatCompletionMessageToolCallFunction{Name: name, Arguments: `{}`},
+		})
+	}
+	results, err := agent.ExecuteTools(context.Background(), calls)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	var contents []string
+	for i, result := range results {
+		assert.Equal(t, calls[i].ID, *result.ToolCallID)
+		contents = append(contents, result.TextContent())
+	}
+	assert.Equal(t, []string{"mcp: mcp_read_file", "builtin: builtin_time", "Error: unknown tool a2a_agent"}, contents)
+}
+
+func TestAgentReportsToolProgress(t *testing.T) {
+	agent := NewAgentWithExecutors(logger.NewNoopLogger(), config.Config{MCP: &config.MCPConfig{ToolConcurrency: 1}},
+		prefixExecutor{prefix: "mcp_", name: "mcp"},
+	).(*agentImpl)
+
+	calls := []types.ChatCompletionMessageToolCall{
+		{ID: "a", Type: types.Function, Function: types.ChatCompletionMessageToolCallFunction{Name: "mcp_read_file", Arguments: `{}`}},
+		{ID: "b", Type: types.Function, Function: types.ChatCompletionMessageToolCallFunction{Name: "mcp_write_file", Arguments: `{}`}},
+	}
+	var progress []string
+	_, err := agent.executeTools(context.Background(), calls, func(toolCall types.ChatCompletionMessageToolCall, status types.ToolProgressStatus) {
+		progress = append(progress, toolCall.ID+" "+string(status))
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a in_progress", "a completed", "b in_progress", "b completed"}, progress)
+
+	data := toolProgressChunk(types.CreateChatCompletionStreamResponse{ID: "chatcmpl-1", Model: "gpt-4o", Created: 1}, calls[0], types.ToolProgressStatusInProgress)
+	var chunk types.CreateChatCompletionStreamResponse
+	require.NoError(t, json.Unmarshal(bytes.TrimSpace(bytes.TrimPrefix(data, []byte("data: "))), &chunk))
+	assert.Equal(t, "chat.completion.chunk", chunk.Object)
+	assert.Equal(t, "chatcmpl-1", chunk.ID)
+	require.Len(t, chunk.Choices, 1)
+	assert.Empty(t, chunk.Choices[0].Delta.Content)
+	assert.Equal(t, &types.ToolProgress{ToolCallID: "a", Name: "mcp_read_file", Status: types.ToolProgressStatusInProgress}, chunk.Choices[0].Delta.ToolProgress)
+}
+
+// cancellingExecutor cancels the request of the first call it runs
+type cancellingExecutor struct {
+	cancel context.CancelFunc
+	calls  *atomic.Int32
+}
+
+func (e cancellingExecutor) Handles(string) bool {
+	return true
+}
+
+func (e cancellingExecutor) Execute(_ context.Context, toolCall types.ChatCompletionMessageToolCall) (types.Message, error) {
+	e.calls.Add(1)
+	e.cancel()
+	return toolMessage(toolCall.ID, "done")
+}
+
+func TestAgentStopsToolCallsOfCancelledRequests(t *testing.T) {
+	for _, concurrency := range []int{1, 2} {
+		ctx, cancel := context.WithCancel(context.Background())
+		var executed atomic.Int32
+		agent := NewAgentWithExecutors(logger.NewNoopLogger(), config.Config{MCP: &config.MCPConfig{ToolConcurrency: concurrency}},
+			cancellingExecutor{cancel: cancel, calls: &executed},
+		)
+
+		var calls []types.ChatCompletionMessageToolCall
+		for _, id := range []string{"a", "b", "c", "d"} {
+			calls = append(calls, types.ChatCompletionMessageToolCall{ID: id, Type: types.Function, Function: types.ChatCompletionMessageToolCallFunction{Name: "mcp_slow", Arguments: `{}`}})
+		}
+		results, err := agent.ExecuteTools(ctx, calls)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, results)
+		assert.LessOrEqual(t, int(executed.Load()), concurrency, "queued calls do not start once the request is cancelled")
+	}
+}
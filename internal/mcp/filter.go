@@ -47,17 +47,24 @@ func isToolAllowed(toolName, includeList, excludeList string) bool {
 
 // filterTools returns the subset of the given tools that should be injected
 // into chat completion requests, honoring the configured MCP include/exclude
-// lists. Tools that are filtered out are logged at debug level.
+// lists and allow/deny patterns. Tools that are filtered out are logged at
+// debug level.
 func (mc *MCPClient) filterTools(tools []Tool) []Tool {
 	includeList := mc.Config.MCP.IncludeTools
 	excludeList := mc.Config.MCP.ExcludeTools
+	allowList := mc.Config.MCP.ToolsAllow
+	denyList := mc.Config.MCP.ToolsDeny
 
-	if includeList == "" && excludeList == "" {
+	if includeList == "" && excludeList == "" && allowList == "" && denyList == "" {
 		return tools
 	}
 
 	filtered := make([]Tool, 0, len(tools))
 	for _, tool := range tools {
+		if !isToolPermitted(tool.Name, allowList, denyList) {
+			mc.Logger.Debug("mcp tool excluded from injection by allow/deny config", "tool", tool.Name)
+			continue
+		}
 		if isToolAllowed(tool.Name, includeList, excludeList) {
 			filtered = append(filtered, tool)
 			continue
@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
 	"time"
 
 	m "github.com/metoro-io/mcp-golang"
@@ -16,8 +19,23 @@ import (
 
 // NewMCPClient is a variable holding the function to create a new MCP client
 func NewMCPClient(serverURLs []string, logger logger.Logger, cfg config.Config) MCPClientInterface {
-	return &MCPClient{
-		ServerURLs:          serverURLs,
+	return NewMCPClientWithSampler(serverURLs, logger, cfg, nil)
+}
+
+// NewMCPClientWithSampler creates an MCP client declaring the sampling
+// capability to the servers it reaches over HTTP, their sampling/createMessage
+// requests being served by sampler
+func NewMCPClientWithSampler(serverURLs []string, logger logger.Logger, cfg config.Config, sampler Sampler) MCPClientInterface {
+	servers, err := loadServersConfig(cfg)
+	if err != nil {
+		logger.Error("failed to load mcp servers config, connecting without credentials", err, "component", "mcp_client")
+		servers = &ServersConfig{}
+	}
+	auth, err := newServerAuths(servers, cfg)
+	if err != nil {
+		logger.Error("failed to load mcp servers config, connecting without credentials", err, "component", "mcp_client")
+	}
+	mc := &MCPClient{
 		Logger:              logger,
 		Config:              cfg,
 		clients:             make(map[string]*m.Client),
@@ -26,7 +44,13 @@ func NewMCPClient(serverURLs []string, logger logger.Logger, cfg config.Config)
 		serverStatuses:      make(map[string]ServerStatus),
 		reconnecting:        make(map[string]struct{}),
 		pollingDone:         make(chan struct{}),
+		auth:                auth,
+		stdio:               servers.Stdio,
+		processes:           make(map[string]*exec.Cmd),
+		sampler:             sampler,
 	}
+	mc.ServerURLs = mc.withStdioServers(serverURLs)
+	return mc
 }
 
 // InitializeAll implements MCPClientInterface with enhanced transport fallback.
@@ -93,6 +117,59 @@ func (mc *MCPClient) InitializeAll(ctx context.Context) error {
 	return nil
 }
 
+// UpdateServers implements MCPClientInterface. Servers that are kept keep
+// their connections; tools of removed servers are no longer offered.
+func (mc *MCPClient) UpdateServers(ctx context.Context, serverURLs []string) error {
+	serverURLs = mc.withStdioServers(serverURLs)
+	mc.mu.Lock()
+	previous := mc.ServerURLs
+	mc.ServerURLs = slices.Clone(serverURLs)
+	var added []string
+	for _, serverURL := range serverURLs {
+		if !slices.Contains(previous, serverURL) {
+			added = append(added, serverURL)
+			mc.serverStatuses[serverURL] = ServerStatusUnknown
+		}
+	}
+	for _, serverURL := range previous {
+		if !slices.Contains(serverURLs, serverURL) {
+			delete(mc.clients, serverURL)
+			delete(mc.serverTools, serverURL)
+			delete(mc.serverStatuses, serverURL)
+			mc.Logger.Info("removed mcp server", "server", serverURL, "component", "mcp_client")
+		}
+	}
+	mc.rebuildChatCompletionToolsLocked()
+	mc.mu.Unlock()
+
+	var errs []error
+	var failedServers []string
+	for _, serverURL := range added {
+		if err := mc.initializeServer(ctx, serverURL); err != nil {
+			mc.Logger.Error("failed to initialize mcp server", err, "server", serverURL, "component", "mcp_client")
+			errs = append(errs, err)
+			failedServers = append(failedServers, serverURL)
+			continue
+		}
+		mc.Logger.Info("added mcp server", "server", serverURL, "component", "mcp_client")
+	}
+	if len(failedServers) > 0 && mc.Config.MCP.EnableReconnect {
+		// Restart the reconnection loop so it also covers the new servers
+		mc.StopBackgroundReconnection()
+		mc.mu.RLock()
+		var unavailable []string
+		for _, serverURL := range mc.ServerURLs {
+			if mc.serverStatuses[serverURL] == ServerStatusUnavailable {
+				unavailable = append(unavailable, serverURL)
+			}
+		}
+		mc.mu.RUnlock()
+		mc.scheduleReconnectionIfEnabled(unavailable)
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
 // scheduleReconnectionIfEnabled is the single guard point for kicking off the
 // background reconnection goroutine.
 func (mc *MCPClient) scheduleReconnectionIfEnabled(failedServers []string) bool {
@@ -173,23 +250,15 @@ func (mc *MCPClient) initializeServer(ctx context.Context, serverURL string) err
 			}
 		}
 
-		client, err := mc.initializeClientWithTransport(ctx, serverURL, TransportModeStreamableHTTP)
+		client, err := mc.connect(ctx, serverURL)
 		if err != nil {
-			mc.Logger.Debug("streamable http failed, attempting sse fallback", "server", serverURL, "error", err.Error())
-
-			client, err = mc.initializeClientWithTransport(ctx, serverURL, TransportModeSSE)
-			if err != nil {
-				lastErr = fmt.Errorf("both streamable http and sse transports failed: %w", err)
-				mc.Logger.Debug("failed to initialize server",
-					"server", serverURL,
-					"attempt", attempt+1,
-					"error", err,
-					"component", "mcp_client")
-				continue
-			}
-			mc.Logger.Info("successfully connected using sse transport fallback", "server", serverURL)
-		} else {
-			mc.Logger.Debug("successfully connected using streamable http transport", "server", serverURL)
+			lastErr = err
+			mc.Logger.Debug("failed to initialize server",
+				"server", serverURL,
+				"attempt", attempt+1,
+				"error", err,
+				"component", "mcp_client")
+			continue
 		}
 
 		tools, err := mc.discoverServerTools(ctx, client, serverURL)
@@ -204,6 +273,11 @@ func (mc *MCPClient) initializeServer(ctx context.Context, serverURL string) err
 		}
 
 		mc.mu.Lock()
+		if !slices.Contains(mc.ServerURLs, serverURL) {
+			// Removed by UpdateServers while initializing
+			mc.mu.Unlock()
+			return nil
+		}
 		mc.clients[serverURL] = client
 		mc.serverTools[serverURL] = tools
 		mc.serverStatuses[serverURL] = ServerStatusAvailable
@@ -227,6 +301,28 @@ func (mc *MCPClient) initializeServer(ctx context.Context, serverURL string) err
 	return fmt.Errorf("failed to initialize server after %d attempts: %w", maxRetries+1, lastErr)
 }
 
+// connect initializes a client for serverURL: stdio servers are started,
+// URL-based servers are tried with Streamable HTTP, then SSE
+func (mc *MCPClient) connect(ctx context.Context, serverURL string) (*m.Client, error) {
+	if strings.HasPrefix(serverURL, stdioScheme) {
+		return mc.startStdioServer(ctx, serverURL)
+	}
+
+	client, err := mc.initializeClientWithTransport(ctx, serverURL, TransportModeStreamableHTTP)
+	if err != nil {
+		mc.Logger.Debug("streamable http failed, attempting sse fallback", "server", serverURL, "error", err.Error())
+
+		client, err = mc.initializeClientWithTransport(ctx, serverURL, TransportModeSSE)
+		if err != nil {
+			return nil, fmt.Errorf("both streamable http and sse transports failed: %w", err)
+		}
+		mc.Logger.Info("successfully connected using sse transport fallback", "server", serverURL)
+	} else {
+		mc.Logger.Debug("successfully connected using streamable http transport", "server", serverURL)
+	}
+	return client, nil
+}
+
 // initializeClientWithTransport attempts to initialize a client with a specific transport
 func (mc *MCPClient) initializeClientWithTransport(ctx context.Context, serverURL string, mode TransportMode) (*m.Client, error) {
 	client := mc.NewClientWithTransport(serverURL, mode)
@@ -358,6 +454,10 @@ func (mc *MCPClient) startBackgroundReconnection(ctx context.Context, failedServ
 			for serverURL := range reconnectingServers {
 				if status, exists := mc.serverStatuses[serverURL]; exists && status == ServerStatusUnavailable {
 					serversToReconnect = append(serversToReconnect, serverURL)
+				} else if !exists {
+					delete(reconnectingServers, serverURL)
+					mc.Logger.Info("server was removed, removing from background reconnection",
+						"server", serverURL, "component", "mcp_client")
 				} else if status == ServerStatusAvailable {
 					delete(reconnectingServers, serverURL)
 					mc.Logger.Info("server successfully reconnected, removing from background reconnection",
@@ -0,0 +1,48 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+func TestMCPClientUpdateServersRemovesServers(t *testing.T) {
+	cfg := newStubMCPConfig()
+	cfg.MCP.EnableReconnect = false
+	mc := NewMCPClient([]string{"http://a", "http://b"}, logger.NewNoopLogger(), cfg).(*MCPClient)
+	mc.initialized = true
+	mc.clients["http://a"] = nil
+	mc.clients["http://b"] = nil
+	mc.serverTools["http://a"] = []Tool{{Name: "read_file"}}
+	mc.serverTools["http://b"] = []Tool{{Name: "search"}}
+	mc.serverStatuses["http://a"] = ServerStatusAvailable
+	mc.serverStatuses["http://b"] = ServerStatusAvailable
+	mc.rebuildChatCompletionToolsLocked()
+	require.Len(t, mc.GetAllChatCompletionTools(), 2)
+
+	require.NoError(t, mc.UpdateServers(context.Background(), []string{"http://a"}))
+
+	assert.Equal(t, []string{"http://a"}, mc.GetServers())
+	assert.Equal(t, map[string]ServerStatus{"http://a": ServerStatusAvailable}, mc.GetAllServerStatuses())
+	tools := mc.GetAllChatCompletionTools()
+	require.Len(t, tools, 1)
+	assert.Equal(t, "mcp_read_file", tools[0].Function.Name)
+	_, err := mc.GetServerForTool("search")
+	assert.Error(t, err)
+}
+
+func TestMCPClientUpdateServersReportsFailedServers(t *testing.T) {
+	cfg := newStubMCPConfig()
+	cfg.MCP.EnableReconnect = false
+	mc := NewMCPClient(nil, logger.NewNoopLogger(), cfg).(*MCPClient)
+	mc.initialized = true
+
+	err := mc.UpdateServers(context.Background(), []string{"http://127.0.0.1:1"})
+	require.Error(t, err)
+	assert.Equal(t, map[string]ServerStatus{"http://127.0.0.1:1": ServerStatusUnavailable}, mc.GetAllServerStatuses())
+	assert.Empty(t, mc.GetAllChatCompletionTools())
+}
@@ -0,0 +1,196 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	config "github.com/inference-gateway/inference-gateway/config"
+)
+
+// ErrToolNotAllowed is returned when a tool call is rejected by MCP_TOOLS_ALLOW / MCP_TOOLS_DENY
+var ErrToolNotAllowed = errors.New("mcp tool not allowed")
+
+// parsePatternList parses a comma-separated list of tool name globs,
+// normalized the same way as tool names
+func parsePatternList(list string) []string {
+	var patterns []string
+	for item := range strings.SplitSeq(list, ",") {
+		if pattern := normalizeToolName(item); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// matchesAny reports whether the normalized tool name matches one of the patterns
+func matchesAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, err := path.Match(pattern, name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isToolPermitted reports whether a tool may be exposed to LLMs and executed
+// according to the MCP_TOOLS_ALLOW / MCP_TOOLS_DENY glob patterns. The deny
+// list always wins; an empty allow list allows every tool not denied.
+func isToolPermitted(toolName, allowList, denyList string) bool {
+	name := normalizeToolName(toolName)
+	if matchesAny(name, parsePatternList(denyList)) {
+		return false
+	}
+	if allow := parsePatternList(allowList); len(allow) > 0 {
+		return matchesAny(name, allow)
+	}
+	return true
+}
+
+// toolLimit is one glob=value entry of MCP_TOOL_TIMEOUTS or MCP_TOOL_RETRIES
+type toolLimit struct {
+	pattern string
+	value   string
+}
+
+// parseToolLimits parses a comma-separated list of glob=value pairs
+func parseToolLimits(list string) ([]toolLimit, error) {
+	var limits []toolLimit
+	for item := range strings.SplitSeq(list, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		pattern, value, ok := strings.Cut(item, "=")
+		pattern = normalizeToolName(pattern)
+		if !ok || pattern == "" {
+			return nil, fmt.Errorf("invalid entry %q, expected pattern=value", strings.TrimSpace(item))
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		limits = append(limits, toolLimit{pattern: pattern, value: strings.TrimSpace(value)})
+	}
+	return limits, nil
+}
+
+// lookupToolLimit returns the value of the first entry matching the tool name
+func lookupToolLimit(toolName, list string) (string, bool) {
+	limits, err := parseToolLimits(list)
+	if err != nil {
+		return "", false
+	}
+	name := normalizeToolName(toolName)
+	for _, limit := range limits {
+		if ok, _ := path.Match(limit.pattern, name); ok {
+			return limit.value, true
+		}
+	}
+	return "", false
+}
+
+// ValidateToolPolicy checks the MCP tool allow/deny patterns, the per-tool
+// timeouts and retries and the tool result truncation, so misconfiguration is reported at startup instead
+// of silently ignored
+func ValidateToolPolicy(cfg *config.MCPConfig) error {
+	for _, list := range []struct{ env, value string }{
+		{env: "MCP_TOOLS_ALLOW", value: cfg.ToolsAllow},
+		{env: "MCP_TOOLS_DENY", value: cfg.ToolsDeny},
+	} {
+		for _, pattern := range parsePatternList(list.value) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("%s: invalid pattern %q: %w", list.env, pattern, err)
+			}
+		}
+	}
+
+	timeouts, err := parseToolLimits(cfg.ToolTimeouts)
+	if err != nil {
+		return fmt.Errorf("MCP_TOOL_TIMEOUTS: %w", err)
+	}
+	for _, limit := range timeouts {
+		if d, err := time.ParseDuration(limit.value); err != nil || d <= 0 {
+			return fmt.Errorf("MCP_TOOL_TIMEOUTS: invalid timeout %q for %q", limit.value, limit.pattern)
+		}
+	}
+
+	retries, err := parseToolLimits(cfg.ToolRetries)
+	if err != nil {
+		return fmt.Errorf("MCP_TOOL_RETRIES: %w", err)
+	}
+	for _, limit := range retries {
+		if n, err := strconv.Atoi(limit.value); err != nil || n < 0 {
+			return fmt.Errorf("MCP_TOOL_RETRIES: invalid retry count %q for %q", limit.value, limit.pattern)
+		}
+	}
+	return validateResultLimit(cfg)
+}
+
+// toolTimeout returns the configured execution timeout of a tool, or zero
+func (mc *MCPClient) toolTimeout(toolName string) time.Duration {
+	value, ok := lookupToolLimit(toolName, mc.Config.MCP.ToolTimeouts)
+	if !ok {
+		return 0
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return d
+}
+
+// toolRetries returns the configured number of retries of a tool
+func (mc *MCPClient) toolRetries(toolName string) int {
+	value, ok := lookupToolLimit(toolName, mc.Config.MCP.ToolRetries)
+	if !ok {
+		return 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// callWithLimits runs call under the tool's timeout, retrying failed
+// attempts with exponential backoff. It stops early when ctx is done.
+func (mc *MCPClient) callWithLimits(ctx context.Context, toolName string, call func(ctx context.Context) error) error {
+	timeout := mc.toolTimeout(toolName)
+	retries := mc.toolRetries(toolName)
+	backoff := mc.Config.MCP.InitialBackoff
+
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			mc.Logger.Warn("retrying mcp tool call", "tool", toolName, "attempt", attempt+1, "backoff", backoff.String(), "error", err.Error())
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if timeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, timeout)
+		}
+		err = call(attemptCtx)
+		timedOut := errors.Is(attemptCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil
+		cancel()
+
+		if err == nil {
+			return nil
+		}
+		if timedOut {
+			err = fmt.Errorf("mcp tool %s timed out after %s: %w", toolName, timeout, err)
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,151 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+func TestIsToolPermitted(t *testing.T) {
+	tests := []struct {
+		name      string
+		toolName  string
+		allowList string
+		denyList  string
+		expected  bool
+	}{
+		{name: "no patterns allows everything", toolName: "delete_file", expected: true},
+		{name: "allow glob matches", toolName: "read_file", allowList: "read_*,search", expected: true},
+		{name: "allow glob blocks unmatched tool", toolName: "delete_file", allowList: "read_*,search", expected: false},
+		{name: "deny glob blocks matched tool", toolName: "delete_file", denyList: "*_file", expected: false},
+		{name: "deny wins over allow", toolName: "write_file", allowList: "*", denyList: "write_*", expected: false},
+		{name: "matching tolerates prefix and case", toolName: "mcp_Read_File", allowList: "READ_*", expected: true},
+		{name: "malformed pattern matches nothing", toolName: "read_file", allowList: "[", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isToolPermitted(tt.toolName, tt.allowList, tt.denyList))
+		})
+	}
+}
+
+func TestMCPClientFilterTools_AllowDeny(t *testing.T) {
+	mc := &MCPClient{
+		Logger: logger.NewNoopLogger(),
+		Config: config.Config{MCP: &config.MCPConfig{
+			ToolsAllow:   "read_*,list_*,delete_*",
+			ToolsDeny:    "delete_*",
+			ExcludeTools: "list_directory",
+		}},
+	}
+
+	filtered := mc.filterTools([]Tool{{Name: "read_file"}, {Name: "list_directory"}, {Name: "delete_file"}, {Name: "search"}})
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "read_file", filtered[0].Name)
+}
+
+func TestValidateToolPolicy(t *testing.T) {
+	tests := []struct {
+		name          string
+		cfg           config.MCPConfig
+		expectedError string
+	}{
+		{name: "empty", cfg: config.MCPConfig{}},
+		{
+			name: "valid",
+			cfg:  config.MCPConfig{ToolsAllow: "read_*", ToolsDeny: "delete_*", ToolTimeouts: "search_*=30s, *=10s", ToolRetries: "search_*=2"},
+		},
+		{name: "bad allow pattern", cfg: config.MCPConfig{ToolsAllow: "read_["}, expectedError: `MCP_TOOLS_ALLOW: invalid pattern "read_["`},
+		{name: "missing value", cfg: config.MCPConfig{ToolTimeouts: "search_*"}, expectedError: `MCP_TOOL_TIMEOUTS: invalid entry "search_*", expected pattern=value`},
+		{name: "bad duration", cfg: config.MCPConfig{ToolTimeouts: "search_*=soon"}, expectedError: `MCP_TOOL_TIMEOUTS: invalid timeout "soon" for "search_*"`},
+		{name: "negative retries", cfg: config.MCPConfig{ToolRetries: "search_*=-1"}, expectedError: `MCP_TOOL_RETRIES: invalid retry count "-1" for "search_*"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateToolPolicy(&tt.cfg)
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedError)
+		})
+	}
+}
+
+func TestMCPClientCallWithLimits(t *testing.T) {
+	newClient := func() *MCPClient {
+		return &MCPClient{
+			Logger: logger.NewNoopLogger(),
+			Config: config.Config{MCP: &config.MCPConfig{
+				ToolTimeouts:   "slow_*=20ms,*=1s",
+				ToolRetries:    "flaky_*=2",
+				InitialBackoff: time.Millisecond,
+			}},
+		}
+	}
+
+	t.Run("retries until success", func(t *testing.T) {
+		attempts := 0
+		err := newClient().callWithLimits(context.Background(), "flaky_search", func(ctx context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return errors.New("connection reset")
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("gives up after the configured retries", func(t *testing.T) {
+		attempts := 0
+		err := newClient().callWithLimits(context.Background(), "flaky_search", func(ctx context.Context) error {
+			attempts++
+			return errors.New("connection reset")
+		})
+		assert.EqualError(t, err, "connection reset")
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("tools without retries run once", func(t *testing.T) {
+		attempts := 0
+		err := newClient().callWithLimits(context.Background(), "read_file", func(ctx context.Context) error {
+			attempts++
+			return errors.New("connection reset")
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("per-tool timeout", func(t *testing.T) {
+		err := newClient().callWithLimits(context.Background(), "slow_search", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), "mcp tool slow_search timed out after 20ms")
+	})
+
+	t.Run("cancelled parent is not retried", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		attempts := 0
+		err := newClient().callWithLimits(ctx, "flaky_search", func(ctx context.Context) error {
+			attempts++
+			cancel()
+			return ctx.Err()
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, attempts)
+	})
+}
@@ -0,0 +1,245 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	m "github.com/metoro-io/mcp-golang"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// serverClient returns the client of serverURL
+func (mc *MCPClient) serverClient(serverURL string) (*m.Client, error) {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+
+	if !mc.initialized {
+		return nil, ErrClientNotInitialized
+	}
+	client, exists := mc.clients[serverURL]
+	if !exists {
+		return nil, ErrServerNotFound
+	}
+	return client, nil
+}
+
+// ListResources implements MCPClientInterface. Every page of the listing is
+// fetched.
+func (mc *MCPClient) ListResources(ctx context.Context, serverURL string) ([]Resource, error) {
+	client, err := mc.serverClient(serverURL)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, mc.Config.MCP.RequestTimeout)
+	defer cancel()
+
+	var resources []Resource
+	var cursor *string
+	for {
+		response, err := client.ListResources(ctx, cursor)
+		if err != nil {
+			return nil, fmt.Errorf("list resources: %w", err)
+		}
+		var page ListResourcesResult
+		if err := convertResult(response, &page); err != nil {
+			return nil, err
+		}
+		resources = append(resources, page.Resources...)
+		if !nextPage(&cursor, page.NextCursor) {
+			return resources, nil
+		}
+	}
+}
+
+// ReadResource implements MCPClientInterface.
+func (mc *MCPClient) ReadResource(ctx context.Context, serverURL string, uri string) (*ReadResourceResult, error) {
+	client, err := mc.serverClient(serverURL)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, mc.Config.MCP.RequestTimeout)
+	defer cancel()
+
+	response, err := client.ReadResource(ctx, uri)
+	if err != nil {
+		return nil, fmt.Errorf("read resource %s: %w", uri, err)
+	}
+	var result ReadResourceResult
+	if err := convertResult(response, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListPrompts implements MCPClientInterface. Every page of the listing is
+// fetched.
+func (mc *MCPClient) ListPrompts(ctx context.Context, serverURL string) ([]Prompt, error) {
+	client, err := mc.serverClient(serverURL)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, mc.Config.MCP.RequestTimeout)
+	defer cancel()
+
+	var prompts []Prompt
+	var cursor *string
+	for {
+		response, err := client.ListPrompts(ctx, cursor)
+		if err != nil {
+			return nil, fmt.Errorf("list prompts: %w", err)
+		}
+		var page ListPromptsResult
+		if err := convertResult(response, &page); err != nil {
+			return nil, err
+		}
+		prompts = append(prompts, page.Prompts...)
+		if !nextPage(&cursor, page.NextCursor) {
+			return prompts, nil
+		}
+	}
+}
+
+// GetPrompt implements MCPClientInterface.
+func (mc *MCPClient) GetPrompt(ctx context.Context, serverURL string, name string, arguments map[string]string) (*GetPromptResult, error) {
+	client, err := mc.serverClient(serverURL)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, mc.Config.MCP.RequestTimeout)
+	defer cancel()
+
+	if arguments == nil {
+		arguments = map[string]string{}
+	}
+	response, err := client.GetPrompt(ctx, name, arguments)
+	if err != nil {
+		return nil, fmt.Errorf("get prompt %s: %w", name, err)
+	}
+	var result GetPromptResult
+	if err := convertResult(response, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// convertResult converts a response of the MCP library into its type of the
+// MCP schema
+func convertResult(response, result any) error {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("encode mcp response: %w", err)
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("decode mcp response: %w", err)
+	}
+	return nil
+}
+
+// nextPage moves cursor to next and reports whether there is a page left to
+// fetch. A server answering with the cursor it was sent has no more pages.
+func nextPage(cursor **string, next *string) bool {
+	if next == nil || *next == "" || (*cursor != nil && **cursor == *next) {
+		return false
+	}
+	*cursor = next
+	return true
+}
+
+// FindPromptServer returns the first server, in URL order, offering the
+// prompt name. Servers failing to list their prompts are skipped.
+func FindPromptServer(ctx context.Context, client MCPClientInterface, name string) (string, error) {
+	servers := client.GetServers()
+	slices.Sort(servers)
+	for _, serverURL := range servers {
+		prompts, err := client.ListPrompts(ctx, serverURL)
+		if err != nil {
+			continue
+		}
+		if slices.ContainsFunc(prompts, func(p Prompt) bool { return p.Name == name }) {
+			return serverURL, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrPromptNotFound, name)
+}
+
+// FindResourceServer returns the first server, in URL order, listing the
+// resource uri. Servers failing to list their resources are skipped.
+func FindResourceServer(ctx context.Context, client MCPClientInterface, uri string) (string, error) {
+	servers := client.GetServers()
+	slices.Sort(servers)
+	for _, serverURL := range servers {
+		resources, err := client.ListResources(ctx, serverURL)
+		if err != nil {
+			continue
+		}
+		if slices.ContainsFunc(resources, func(r Resource) bool { return r.URI == uri }) {
+			return serverURL, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+}
+
+// RenderPrompt renders the prompt name of the first server offering it
+func RenderPrompt(ctx context.Context, client MCPClientInterface, name string, arguments map[string]string) (*GetPromptResult, error) {
+	serverURL, err := FindPromptServer(ctx, client, name)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetPrompt(ctx, serverURL, name, arguments)
+}
+
+// promptContent is a content block of a prompt message: text, an image or an
+// embedded resource
+type promptContent struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	Data     string `json:"data"`
+	MimeType string `json:"mimeType"`
+	Resource *struct {
+		Text *string `json:"text"`
+	} `json:"resource"`
+}
+
+// ChatMessages converts the messages of a rendered prompt to chat messages.
+// Embedded resources become text messages; only text resources are
+// supported.
+func (r GetPromptResult) ChatMessages() ([]types.Message, error) {
+	messages := make([]types.Message, 0, len(r.Messages))
+	for i, pm := range r.Messages {
+		var content promptContent
+		if err := convertResult(pm.Content, &content); err != nil {
+			return nil, fmt.Errorf("prompt message %d: %w", i, err)
+		}
+
+		msg := types.Message{Role: types.MessageRole(pm.Role)}
+		var err error
+		switch content.Type {
+		case "text":
+			err = msg.Content.FromMessageContent0(content.Text)
+		case "resource":
+			if content.Resource == nil || content.Resource.Text == nil {
+				return nil, fmt.Errorf("prompt message %d: only text resources are supported", i)
+			}
+			err = msg.Content.FromMessageContent0(*content.Resource.Text)
+		case "image":
+			var part types.ContentPart
+			err = part.FromImageContentPart(types.ImageContentPart{
+				Type:     types.ImageContentPartTypeImageURL,
+				ImageURL: types.ImageURL{URL: "data:" + content.MimeType + ";base64," + content.Data},
+			})
+			if err == nil {
+				err = msg.Content.FromMessageContent1([]types.ContentPart{part})
+			}
+		default:
+			return nil, fmt.Errorf("prompt message %d: unsupported content type %q", i, content.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("prompt message %d: %w", i, err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
@@ -0,0 +1,53 @@
+package mcp
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func TestGetPromptResult_ChatMessages(t *testing.T) {
+	result := GetPromptResult{Messages: []PromptMessage{
+		{Role: "user", Content: map[string]any{"type": "text", "text": "Summarize the document."}},
+		{Role: "user", Content: map[string]any{"type": "resource", "resource": map[string]any{"uri": "file:///README.md", "text": "# Docs"}}},
+		{Role: "assistant", Content: map[string]any{"type": "image", "mimeType": "image/png", "data": "iVBORw0K"}},
+	}}
+
+	messages, err := result.ChatMessages()
+	require.NoError(t, err)
+	require.Len(t, messages, 3)
+
+	text, err := messages[0].Content.AsMessageContent0()
+	require.NoError(t, err)
+	assert.Equal(t, "Summarize the document.", text)
+	text, err = messages[1].Content.AsMessageContent0()
+	require.NoError(t, err)
+	assert.Equal(t, "# Docs", text)
+
+	assert.Equal(t, types.Assistant, messages[2].Role)
+	parts, err := messages[2].Content.AsMessageContent1()
+	require.NoError(t, err)
+	require.Len(t, parts, 1)
+	image, err := parts[0].AsImageContentPart()
+	require.NoError(t, err)
+	assert.Equal(t, "data:image/png;base64,iVBORw0K", image.ImageURL.URL)
+
+	_, err = GetPromptResult{Messages: []PromptMessage{
+		{Role: "user", Content: map[string]any{"type": "resource", "resource": map[string]any{"uri": "file:///logo.png", "blob": "iVBORw0K"}}},
+	}}.ChatMessages()
+	assert.ErrorContains(t, err, "only text resources")
+}
+
+func TestNextPage(t *testing.T) {
+	var cursor *string
+	next := "page-2"
+	assert.True(t, nextPage(&cursor, &next))
+	assert.Equal(t, "page-2", *cursor)
+
+	repeated := "page-2"
+	assert.False(t, nextPage(&cursor, &repeated), "a repeated cursor ends the listing")
+	assert.False(t, nextPage(&cursor, nil))
+}
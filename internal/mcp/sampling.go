@@ -0,0 +1,225 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// Sampler serves a sampling/createMessage request of the server serverURL.
+// ctx is the context of the request the server answers, carrying the
+// tenant and credentials of the caller.
+type Sampler func(ctx context.Context, serverURL string, params CreateMessageRequestParams) (*CreateMessageResult, error)
+
+// JSON-RPC error codes of the answers to server requests
+const (
+	rpcInvalidParams  = -32602
+	rpcMethodNotFound = -32601
+	rpcInternalError  = -32603
+)
+
+// rpcMessage is a JSON-RPC message read from the event stream of a server:
+// a request when it has a method and an ID, a notification when it has a
+// method only, and the response of the client request otherwise
+type rpcMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// rpcError is the error of a JSON-RPC response
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// rpcResponse is the answer of the gateway to a server request
+type rpcResponse struct {
+	Jsonrpc string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// answerServerRequest posts the answer to a request the server sent on the
+// event stream of req, with the headers of req
+func (c *customRoundTripper) answerServerRequest(req *http.Request, message rpcMessage) error {
+	reply := rpcResponse{Jsonrpc: "2.0", ID: message.ID}
+	result, rpcErr := c.serverRequestResult(req.Context(), message)
+	if rpcErr != nil {
+		reply.Error = rpcErr
+	} else {
+		reply.Result = result
+	}
+	body, err := json.Marshal(reply)
+	if err != nil {
+		return fmt.Errorf("encode answer to %s: %w", message.Method, err)
+	}
+
+	answer := req.Clone(req.Context())
+	answer.Body = io.NopCloser(bytes.NewReader(body))
+	answer.ContentLength = int64(len(body))
+	c.mu.Lock()
+	if c.sessionID != "" {
+		answer.Header.Set("mcp-session-id", c.sessionID)
+	}
+	c.mu.Unlock()
+
+	resp, err := c.base.RoundTrip(answer)
+	if err != nil {
+		return fmt.Errorf("answer %s: %w", message.Method, err)
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("answer %s: server responded with status %d", message.Method, resp.StatusCode)
+	}
+	return nil
+}
+
+// serverRequestResult runs a request of the server. Only sampling, when
+// the client has a sampler, and ping are supported.
+func (c *customRoundTripper) serverRequestResult(ctx context.Context, message rpcMessage) (any, *rpcError) {
+	switch {
+	case message.Method == "ping":
+		return struct{}{}, nil
+	case message.Method == string(SamplingCreateMessage) && c.sampler != nil:
+		var params CreateMessageRequestParams
+		if err := json.Unmarshal(message.Params, &params); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: "invalid sampling/createMessage params: " + err.Error()}
+		}
+		result, err := c.sampler(ctx, c.serverURL, params)
+		if err != nil {
+			return nil, &rpcError{Code: rpcInternalError, Message: err.Error()}
+		}
+		return result, nil
+	default:
+		return nil, &rpcError{Code: rpcMethodNotFound, Message: "method not found: " + message.Method}
+	}
+}
+
+// declareSampling adds the sampling capability to the params of an
+// initialize request body and reports whether it did
+func declareSampling(body map[string]any) bool {
+	if body["method"] != "initialize" {
+		return false
+	}
+	params, ok := body["params"].(map[string]any)
+	if !ok {
+		return false
+	}
+	capabilities, ok := params["capabilities"].(map[string]any)
+	if !ok {
+		capabilities = map[string]any{}
+		params["capabilities"] = capabilities
+	}
+	capabilities["sampling"] = map[string]any{}
+	return true
+}
+
+// ModelHints returns the model names hinted by the server, in order of
+// preference
+func (p CreateMessageRequestParams) ModelHints() []string {
+	if p.ModelPreferences == nil || p.ModelPreferences.Hints == nil {
+		return nil
+	}
+	var hints []string
+	for _, hint := range *p.ModelPreferences.Hints {
+		if hint.Name != nil && *hint.Name != "" {
+			hints = append(hints, *hint.Name)
+		}
+	}
+	return hints
+}
+
+// ChatRequest converts a sampling request to a chat completion request for
+// model. Text and image contents are supported.
+func (p CreateMessageRequestParams) ChatRequest(model string) (types.CreateChatCompletionRequest, error) {
+	req := types.CreateChatCompletionRequest{
+		Model:       model,
+		Messages:    make([]types.Message, 0, len(p.Messages)+1),
+		Temperature: p.Temperature,
+	}
+	if p.MaxTokens > 0 {
+		maxTokens := p.MaxTokens
+		req.MaxTokens = &maxTokens
+	}
+	if p.StopSequences != nil && len(*p.StopSequences) > 0 {
+		var stop types.CreateChatCompletionRequest_Stop
+		if err := stop.FromCreateChatCompletionRequestStop1(*p.StopSequences); err != nil {
+			return req, err
+		}
+		req.Stop = &stop
+	}
+	if p.SystemPrompt != nil && *p.SystemPrompt != "" {
+		msg := types.Message{Role: types.System}
+		if err := msg.Content.FromMessageContent0(*p.SystemPrompt); err != nil {
+			return req, err
+		}
+		req.Messages = append(req.Messages, msg)
+	}
+
+	for i, sm := range p.Messages {
+		var content promptContent
+		if err := convertResult(sm.Content, &content); err != nil {
+			return req, fmt.Errorf("sampling message %d: %w", i, err)
+		}
+
+		msg := types.Message{Role: types.MessageRole(sm.Role)}
+		var err error
+		switch content.Type {
+		case "text":
+			err = msg.Content.FromMessageContent0(content.Text)
+		case "image":
+			var part types.ContentPart
+			err = part.FromImageContentPart(types.ImageContentPart{
+				Type:     types.ImageContentPartTypeImageURL,
+				ImageURL: types.ImageURL{URL: "data:" + content.MimeType + ";base64," + content.Data},
+			})
+			if err == nil {
+				err = msg.Content.FromMessageContent1([]types.ContentPart{part})
+			}
+		default:
+			return req, fmt.Errorf("sampling message %d: unsupported content type %q", i, content.Type)
+		}
+		if err != nil {
+			return req, fmt.Errorf("sampling message %d: %w", i, err)
+		}
+		req.Messages = append(req.Messages, msg)
+	}
+	return req, nil
+}
+
+// SamplingResult converts the chat completion answering a sampling request
+// to its result. model is the provider/model reported to the server.
+func SamplingResult(response types.CreateChatCompletionResponse, model string) (*CreateMessageResult, error) {
+	if len(response.Choices) == 0 {
+		return nil, fmt.Errorf("sampling completion has no choices")
+	}
+	choice := response.Choices[0]
+
+	result := &CreateMessageResult{Model: model, Role: Assistant}
+	if err := result.Content.FromTextContent(TextContent{Type: Text, Text: choice.Message.TextContent()}); err != nil {
+		return nil, err
+	}
+	var stopReason string
+	switch choice.FinishReason {
+	case types.Stop:
+		stopReason = "endTurn"
+	case types.Length:
+		stopReason = "maxTokens"
+	case types.ToolCalls:
+		stopReason = "toolUse"
+	default:
+		stopReason = string(choice.FinishReason)
+	}
+	if stopReason != "" {
+		result.StopReason = &stopReason
+	}
+	return result, nil
+}
@@ -0,0 +1,148 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func TestCustomRoundTripperAnswersSamplingRequests(t *testing.T) {
+	type tenantKey struct{}
+	var answers []map[string]any
+	rt := &customRoundTripper{
+		serverURL: "http://git:8080/mcp",
+		sampler: func(ctx context.Context, serverURL string, params CreateMessageRequestParams) (*CreateMessageResult, error) {
+			assert.Equal(t, "http://git:8080/mcp", serverURL)
+			assert.Equal(t, "acme", ctx.Value(tenantKey{}), "the sampler runs in the context of the tool call")
+			assert.Equal(t, 64, params.MaxTokens)
+			result := &CreateMessageResult{Model: "openai/gpt-4o-mini", Role: Assistant}
+			require.NoError(t, result.Content.FromTextContent(TextContent{Type: Text, Text: "Renames a function."}))
+			return result, nil
+		},
+		base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			var message map[string]any
+			require.NoError(t, json.Unmarshal(body, &message))
+			if _, ok := message["method"]; !ok {
+				answers = append(answers, message)
+				return &http.Response{StatusCode: http.StatusAccepted, Body: io.NopCloser(strings.NewReader(""))}, nil
+			}
+			stream := "event: message\n" +
+				`data: {"jsonrpc":"2.0","method":"notifications/progress","params":{"progress":1}}` + "\n\n" +
+				`data: {"jsonrpc":"2.0","id":7,"method":"sampling/createMessage","params":{"maxTokens":64,"messages":[{"role":"user","content":{"type":"text","text":"Summarize"}}]}}` + "\n\n" +
+				`data: {"jsonrpc":"2.0","id":8,"method":"roots/list"}` + "\n\n" +
+				`data: {"jsonrpc":"2.0","id":1,"result":{"content":[{"type":"text","text":"done"}]}}` + "\n\n"
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {"text/event-stream"}},
+				Body:       io.NopCloser(strings.NewReader(stream)),
+			}, nil
+		}),
+	}
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://git:8080/mcp",
+		strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"summarize"}}`))
+	require.NoError(t, err)
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":{"content":[{"type":"text","text":"done"}]}}`, string(body))
+
+	require.Len(t, answers, 2, "server requests are answered, notifications are not")
+	assert.Equal(t, float64(7), answers[0]["id"])
+	assert.Equal(t, map[string]any{
+		"model":   "openai/gpt-4o-mini",
+		"role":    "assistant",
+		"content": map[string]any{"type": "text", "text": "Renames a function."},
+	}, answers[0]["result"])
+	assert.Equal(t, float64(8), answers[1]["id"])
+	assert.Equal(t, float64(rpcMethodNotFound), answers[1]["error"].(map[string]any)["code"])
+}
+
+func TestCustomRoundTripperDeclaresSampling(t *testing.T) {
+	var capabilities map[string]any
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var message struct {
+			Params struct {
+				Capabilities map[string]any `json:"capabilities"`
+			} `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&message))
+		capabilities = message.Params.Capabilities
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{}`)),
+		}, nil
+	})
+	initialize := `{"jsonrpc":"2.0","id":0,"method":"initialize","params":{"capabilities":{},"clientInfo":{"name":"gateway","version":"1"}}}`
+
+	for name, sampler := range map[string]Sampler{
+		"without sampler": nil,
+		"with sampler": func(context.Context, string, CreateMessageRequestParams) (*CreateMessageResult, error) {
+			return nil, nil
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rt := &customRoundTripper{base: base, sampler: sampler}
+			req, err := http.NewRequest(http.MethodPost, "http://git:8080/mcp", strings.NewReader(initialize))
+			require.NoError(t, err)
+			resp, err := rt.RoundTrip(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			if sampler == nil {
+				assert.Empty(t, capabilities)
+			} else {
+				assert.Equal(t, map[string]any{"sampling": map[string]any{}}, capabilities)
+			}
+		})
+	}
+}
+
+func TestCreateMessageRequestParamsChatRequest(t *testing.T) {
+	var text, image SamplingMessage_Content
+	require.NoError(t, text.FromTextContent(TextContent{Type: Text, Text: "What is in the picture?"}))
+	require.NoError(t, image.FromImageContent(ImageContent{Type: Image, MimeType: "image/png", Data: []byte("png")}))
+	system := "Be brief."
+	temperature := float32(0.2)
+	stop := []string{"\n\n"}
+	params := CreateMessageRequestParams{
+		MaxTokens:     100,
+		SystemPrompt:  &system,
+		Temperature:   &temperature,
+		StopSequences: &stop,
+		Messages:      []SamplingMessage{{Role: User, Content: text}, {Role: User, Content: image}},
+	}
+
+	req, err := params.ChatRequest("gpt-4o")
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-4o", req.Model)
+	assert.Equal(t, &temperature, req.Temperature)
+	require.Len(t, req.Messages, 3)
+	assert.Equal(t, types.System, req.Messages[0].Role)
+	assert.Equal(t, "Be brief.", req.Messages[0].TextContent())
+	assert.Equal(t, "What is in the picture?", req.Messages[1].TextContent())
+	parts, err := req.Messages[2].Content.AsMessageContent1()
+	require.NoError(t, err)
+	require.Len(t, parts, 1)
+	part, err := parts[0].AsImageContentPart()
+	require.NoError(t, err)
+	assert.Equal(t, "data:image/png;base64,cG5n", part.ImageURL.URL)
+	require.NotNil(t, req.Stop)
+	stops, err := req.Stop.AsCreateChatCompletionRequestStop1()
+	require.NoError(t, err)
+	assert.Equal(t, stop, stops)
+}
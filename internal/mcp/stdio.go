@@ -0,0 +1,156 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+
+	m "github.com/metoro-io/mcp-golang"
+	stdio "github.com/metoro-io/mcp-golang/transport/stdio"
+)
+
+// stdioScheme prefixes the names of stdio servers in the server list, where
+// URL-based servers are listed by URL
+const stdioScheme = "stdio://"
+
+var stdioNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// StdioServer is an MCP server the gateway runs as a child process, speaking
+// JSON-RPC over its stdin and stdout. The process inherits the gateway's
+// environment plus Env, whose ${VAR} references are expanded.
+type StdioServer struct {
+	Command string            `yaml:"command"`
+	Args    []string          `yaml:"args"`
+	Env     map[string]string `yaml:"env"`
+	Dir     string            `yaml:"dir"`
+}
+
+// StdioServerURL returns the identifier of stdio server name in the server
+// list, statuses and tool routing
+func StdioServerURL(name string) string {
+	return stdioScheme + name
+}
+
+// withStdioServers returns serverURLs without blank entries, followed by
+// the stdio servers in name order
+func (mc *MCPClient) withStdioServers(serverURLs []string) []string {
+	servers := make([]string, 0, len(serverURLs)+len(mc.stdio))
+	for _, serverURL := range serverURLs {
+		if strings.TrimSpace(serverURL) != "" {
+			servers = append(servers, serverURL)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(mc.stdio)) {
+		servers = append(servers, StdioServerURL(name))
+	}
+	return servers
+}
+
+// startStdioServer starts the process of a stdio server, replacing the one
+// already running, and initializes a client speaking to it. The process is
+// restarted by the reconnection logic when it exits.
+func (mc *MCPClient) startStdioServer(ctx context.Context, serverURL string) (*m.Client, error) {
+	server, ok := mc.stdio[strings.TrimPrefix(serverURL, stdioScheme)]
+	if !ok {
+		return nil, ErrServerNotFound
+	}
+	mc.stopStdioServer(serverURL)
+
+	cmd := exec.Command(server.Command, server.Args...)
+	cmd.Dir = server.Dir
+	cmd.Env = os.Environ()
+	for key, value := range server.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("stdio server stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("stdio server stdout: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("stdio server stderr: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start stdio server %s: %w", server.Command, err)
+	}
+	go mc.logStdioServerOutput(serverURL, stderr)
+
+	mc.Logger.Debug("started mcp stdio server", "server", serverURL, "command", server.Command, "pid", cmd.Process.Pid, "component", "mcp_client")
+
+	client := m.NewClient(stdio.NewStdioServerTransportWithIO(stdout, stdin))
+	initCtx, cancel := context.WithTimeout(ctx, mc.Config.MCP.RequestTimeout)
+	defer cancel()
+	if _, err := client.Initialize(initCtx); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, fmt.Errorf("initialization timed out with stdio transport: %w", err)
+		}
+		return nil, fmt.Errorf("initialization failed with stdio transport: %w", err)
+	}
+
+	mc.mu.Lock()
+	mc.processes[serverURL] = cmd
+	mc.mu.Unlock()
+	go mc.waitStdioServer(serverURL, cmd)
+	return client, nil
+}
+
+// waitStdioServer reaps the process of a stdio server and, when it exited
+// on its own, marks the server unavailable and schedules its restart
+func (mc *MCPClient) waitStdioServer(serverURL string, cmd *exec.Cmd) {
+	err := cmd.Wait()
+
+	mc.mu.Lock()
+	current := mc.processes[serverURL] == cmd
+	if current {
+		delete(mc.processes, serverURL)
+		if _, exists := mc.serverStatuses[serverURL]; exists {
+			mc.serverStatuses[serverURL] = ServerStatusUnavailable
+		}
+	}
+	mc.mu.Unlock()
+	if !current {
+		return
+	}
+
+	if err != nil {
+		mc.Logger.Warn("mcp stdio server exited", "server", serverURL, "error", err.Error(), "component", "mcp_client")
+	} else {
+		mc.Logger.Warn("mcp stdio server exited", "server", serverURL, "component", "mcp_client")
+	}
+	if mc.Config.MCP.EnableReconnect {
+		go mc.attemptServerReconnection(context.Background(), serverURL)
+	}
+}
+
+// stopStdioServer kills the process of a stdio server, if it runs
+func (mc *MCPClient) stopStdioServer(serverURL string) {
+	mc.mu.Lock()
+	cmd, ok := mc.processes[serverURL]
+	delete(mc.processes, serverURL)
+	mc.mu.Unlock()
+	if ok {
+		_ = cmd.Process.Kill()
+	}
+}
+
+// logStdioServerOutput logs the stderr lines of a stdio server
+func (mc *MCPClient) logStdioServerOutput(serverURL string, stderr io.Reader) {
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		mc.Logger.Debug("mcp stdio server output", "server", serverURL, "line", scanner.Text(), "component", "mcp_client")
+	}
+}
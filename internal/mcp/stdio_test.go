@@ -0,0 +1,58 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+func TestLoadServersConfig_Stdio(t *testing.T) {
+	t.Setenv("FS_ROOT", "/data")
+
+	cfg, err := LoadServersConfig(writeServersConfig(t, `
+stdio:
+  filesystem:
+    command: npx
+    args: ["-y", "@modelcontextprotocol/server-filesystem", "/data"]
+    env:
+      ALLOWED_DIR: ${FS_ROOT}
+`))
+	require.NoError(t, err)
+	require.Contains(t, cfg.Stdio, "filesystem")
+	assert.Equal(t, "npx", cfg.Stdio["filesystem"].Command)
+	assert.Equal(t, map[string]string{"ALLOWED_DIR": "/data"}, cfg.Stdio["filesystem"].Env)
+
+	_, err = LoadServersConfig(writeServersConfig(t, "stdio:\n  file system:\n    command: npx\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid stdio server name")
+
+	_, err = LoadServersConfig(writeServersConfig(t, "stdio:\n  filesystem:\n    args: [x]\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "command is required")
+}
+
+func TestNewMCPClient_ListsStdioServers(t *testing.T) {
+	path := writeServersConfig(t, "stdio:\n  memory:\n    command: mcp-memory\n  filesystem:\n    command: mcp-filesystem\n")
+	cfg := config.Config{MCP: &config.MCPConfig{ServersConfigPath: path}}
+
+	client := NewMCPClient([]string{"http://mcp:8080/mcp", ""}, logger.NewNoopLogger(), cfg).(*MCPClient)
+	assert.Equal(t, []string{"http://mcp:8080/mcp", "stdio://filesystem", "stdio://memory"}, client.ServerURLs)
+
+	client = NewMCPClient([]string{""}, logger.NewNoopLogger(), cfg).(*MCPClient)
+	assert.Equal(t, []string{"stdio://filesystem", "stdio://memory"}, client.ServerURLs, "stdio servers need no MCP_SERVERS")
+}
+
+func TestStartStdioServer_CommandNotFound(t *testing.T) {
+	path := writeServersConfig(t, "stdio:\n  missing:\n    command: /nonexistent/mcp-server\n")
+	client := NewMCPClient(nil, logger.NewNoopLogger(), config.Config{MCP: &config.MCPConfig{ServersConfigPath: path}}).(*MCPClient)
+
+	_, err := client.startStdioServer(context.Background(), StdioServerURL("missing"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "start stdio server /nonexistent/mcp-server")
+	assert.Empty(t, client.processes)
+}
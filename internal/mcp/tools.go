@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 
+	m "github.com/metoro-io/mcp-golang"
+
 	types "github.com/inference-gateway/inference-gateway/providers/types"
 )
 
@@ -29,7 +31,17 @@ func (mc *MCPClient) ExecuteTool(ctx context.Context, request Request, serverURL
 	}
 	toolArgs := request.Params["arguments"]
 
-	result, err := client.CallTool(ctx, toolName, toolArgs)
+	if !isToolPermitted(toolName, mc.Config.MCP.ToolsAllow, mc.Config.MCP.ToolsDeny) {
+		mc.Logger.Warn("rejected mcp tool call denied by allow/deny config", "tool", toolName, "server", serverURL)
+		return nil, fmt.Errorf("%w: %s", ErrToolNotAllowed, toolName)
+	}
+
+	var result *m.ToolResponse
+	err := mc.callWithLimits(ctx, toolName, func(ctx context.Context) error {
+		var err error
+		result, err = client.CallTool(ctx, toolName, toolArgs)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
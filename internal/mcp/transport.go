@@ -1,7 +1,9 @@
 package mcp
 
 import (
+	"bufio"
 	"bytes"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -16,6 +18,9 @@ import (
 	transport "github.com/metoro-io/mcp-golang/transport/http"
 	otelapi "go.opentelemetry.io/otel"
 	propagation "go.opentelemetry.io/otel/propagation"
+
+	client "github.com/inference-gateway/inference-gateway/providers/client"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
 )
 
 // TransportMode represents the type of transport being used
@@ -31,34 +36,29 @@ type customRoundTripper struct {
 	base        http.RoundTripper
 	fallbackURL string
 
+	// auth sets the credentials of the server, nil when it has none
+	auth *serverAuth
+	// sampler serves the sampling requests of the server at serverURL, nil
+	// when sampling is disabled
+	serverURL string
+	sampler   Sampler
+
 	mu        sync.Mutex
 	sessionID string
 	mode      TransportMode
 }
 
-// parseSSEResponse extracts JSON data from SSE formatted response
-func parseSSEResponse(responseBody string) (string, error) {
-	lines := strings.Split(responseBody, "\n")
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "data: ") {
-			jsonData := strings.TrimPrefix(line, "data: ")
-			if jsonData != "" && jsonData != "[DONE]" {
-				return jsonData, nil
-			}
-		}
-	}
-
-	return "", fmt.Errorf("no valid JSON data found in SSE response")
-}
-
 func (c *customRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 	req = req.Clone(req.Context())
 
 	req.Header.Del("Authorization")
 	req.Header.Del("Cookie")
 	req.Header.Del("X-API-Key")
+	if c.auth != nil {
+		if err := c.auth.apply(req); err != nil {
+			return nil, err
+		}
+	}
 
 	otelapi.GetTextMapPropagator().Inject(req.Context(), propagation.HeaderCarrier(req.Header))
 
@@ -85,6 +85,9 @@ func (c *customRoundTripper) RoundTrip(req *http.Request) (*http.Response, error
 	if sessionID != "" {
 		req.Header.Set("mcp-session-id", sessionID)
 	}
+	if requestID, ok := req.Context().Value(types.RequestIDContextKey).(string); ok && requestID != "" {
+		req.Header.Set("X-Request-ID", requestID)
+	}
 
 	var bodyBytes []byte
 	if req.Method == "POST" && req.Body != nil {
@@ -97,12 +100,19 @@ func (c *customRoundTripper) RoundTrip(req *http.Request) (*http.Response, error
 
 		var jsonBody map[string]any
 		if err := json.Unmarshal(bodyBytes, &jsonBody); err == nil {
+			modified := false
 			if params, ok := jsonBody["params"].(map[string]any); ok {
 				if cursor, exists := params["cursor"]; exists && cursor == nil {
 					delete(params, "cursor")
-					if modifiedBody, err := json.Marshal(jsonBody); err == nil {
-						bodyBytes = modifiedBody
-					}
+					modified = true
+				}
+			}
+			if c.sampler != nil && declareSampling(jsonBody) {
+				modified = true
+			}
+			if modified {
+				if modifiedBody, err := json.Marshal(jsonBody); err == nil {
+					bodyBytes = modifiedBody
 				}
 			}
 		}
@@ -131,28 +141,58 @@ func (c *customRoundTripper) RoundTrip(req *http.Request) (*http.Response, error
 	contentType := resp.Header.Get("Content-Type")
 	if strings.Contains(contentType, "text/event-stream") ||
 		strings.Contains(contentType, "text/plain") {
+		return c.readEventStream(req, resp)
+	}
 
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return resp, err
+	return resp, nil
+}
+
+// readEventStream reads the event stream of resp until the JSON-RPC response
+// to req arrives and returns it as a JSON body. Requests the server sends
+// first, such as sampling requests, are answered and notifications are
+// skipped. Bodies without data lines are returned unchanged.
+func (c *customRoundTripper) readEventStream(req *http.Request, resp *http.Response) (*http.Response, error) {
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+	var body strings.Builder
+	hasData := false
+	for {
+		line, readErr := reader.ReadString('\n')
+		body.WriteString(line)
+		if readErr != nil && readErr != io.EOF {
+			return resp, readErr
 		}
-		resp.Body.Close()
 
-		bodyStr := string(body)
-		if strings.Contains(bodyStr, "data: ") {
-			jsonData, err := parseSSEResponse(bodyStr)
-			if err != nil {
-				return resp, fmt.Errorf("failed to parse SSE response: %v", err)
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "data: ") {
+			hasData = true
+			jsonData := strings.TrimPrefix(line, "data: ")
+			if jsonData != "" && jsonData != "[DONE]" {
+				var message rpcMessage
+				if err := json.Unmarshal([]byte(jsonData), &message); err != nil || message.Method == "" {
+					resp.Body = io.NopCloser(strings.NewReader(jsonData))
+					resp.Header.Set("Content-Type", "application/json")
+					resp.ContentLength = int64(len(jsonData))
+					return resp, nil
+				}
+				if len(message.ID) > 0 {
+					if err := c.answerServerRequest(req, message); err != nil {
+						return resp, err
+					}
+				}
 			}
+		}
 
-			resp.Body = io.NopCloser(strings.NewReader(jsonData))
-			resp.Header.Set("Content-Type", "application/json")
-			resp.ContentLength = int64(len(jsonData))
-		} else {
-			resp.Body = io.NopCloser(strings.NewReader(bodyStr))
+		if readErr == io.EOF {
+			break
 		}
 	}
 
+	if hasData {
+		return resp, fmt.Errorf("failed to parse SSE response: no valid JSON data found in SSE response")
+	}
+	resp.Body = io.NopCloser(strings.NewReader(body.String()))
 	return resp, nil
 }
 
@@ -197,6 +237,18 @@ func (mc *MCPClient) NewClientWithTransport(serverURL string, mode TransportMode
 		ResponseHeaderTimeout: mc.Config.MCP.ResponseHeaderTimeout,
 		ExpectContinueTimeout: mc.Config.MCP.ExpectContinueTimeout,
 	}
+	auth := mc.auth[strings.TrimSpace(serverURL)]
+	var tlsConfig *tls.Config
+	var err error
+	if auth != nil && auth.tls != nil {
+		tlsConfig, err = client.TLSConfigWithFiles(mc.Config.Client, *auth.tls)
+	} else {
+		tlsConfig, err = client.TLSConfig(mc.Config.Client, serverURL)
+	}
+	if err != nil {
+		mc.Logger.Error("failed to apply client tls settings, connecting without them", err, "server", serverURL, "component", "mcp_client")
+	}
+	baseTransport.TLSClientConfig = tlsConfig
 
 	fallbackURL := mc.BuildSSEFallbackURL(serverURL)
 
@@ -206,6 +258,9 @@ func (mc *MCPClient) NewClientWithTransport(serverURL string, mode TransportMode
 			base:        baseTransport,
 			mode:        mode,
 			fallbackURL: fallbackURL,
+			auth:        auth,
+			serverURL:   serverURL,
+			sampler:     mc.sampler,
 		},
 	}
 
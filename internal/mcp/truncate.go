@@ -0,0 +1,123 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	config "github.com/inference-gateway/inference-gateway/config"
+)
+
+// Tool result truncation strategies of MCP_TOOL_RESULT_TRUNCATION
+const (
+	TruncateHead    = "head"
+	TruncateTail    = "tail"
+	TruncateMiddle  = "middle"
+	TruncateSummary = "summary"
+)
+
+// Summarizer summarizes text in at most maxBytes bytes
+type Summarizer func(ctx context.Context, text string, maxBytes int) (string, error)
+
+// ResultLimit bounds the size of the tool results added to the conversation.
+// A zero MaxBytes keeps results whole.
+type ResultLimit struct {
+	MaxBytes   int
+	Truncation string
+	// Summarize writes the summaries of the summary strategy, which falls
+	// back to middle without it
+	Summarize Summarizer
+}
+
+// ResultLimitFromConfig returns the limit set by MCP_TOOL_RESULT_MAX_BYTES and
+// MCP_TOOL_RESULT_TRUNCATION, summarizing with summarize
+func ResultLimitFromConfig(cfg config.Config, summarize Summarizer) ResultLimit {
+	if cfg.MCP == nil {
+		return ResultLimit{}
+	}
+	return ResultLimit{
+		MaxBytes:   max(cfg.MCP.ToolResultMaxBytes, 0),
+		Truncation: cfg.MCP.ToolResultTruncation,
+		Summarize:  summarize,
+	}
+}
+
+// validateResultLimit checks the MCP_TOOL_RESULT_* settings
+func validateResultLimit(cfg *config.MCPConfig) error {
+	switch cfg.ToolResultTruncation {
+	case "", TruncateHead, TruncateTail, TruncateMiddle:
+	case TruncateSummary:
+		if cfg.ToolResultMaxBytes > 0 && cfg.ToolResultSummaryModel == "" {
+			return fmt.Errorf("MCP_TOOL_RESULT_TRUNCATION: summary requires MCP_TOOL_RESULT_SUMMARY_MODEL")
+		}
+	default:
+		return fmt.Errorf("MCP_TOOL_RESULT_TRUNCATION: unknown strategy %q", cfg.ToolResultTruncation)
+	}
+	return nil
+}
+
+// Apply shortens result to the limit, noting how many bytes were cut, and
+// reports whether it did. A failed summary is returned as err along with the
+// result truncated by middle instead.
+func (l ResultLimit) Apply(ctx context.Context, result string) (string, bool, error) {
+	if l.MaxBytes <= 0 || len(result) <= l.MaxBytes {
+		return result, false, nil
+	}
+
+	switch l.Truncation {
+	case TruncateTail:
+		kept := suffix(result, l.MaxBytes)
+		return truncationNote(len(result)-len(kept)) + "\n" + kept, true, nil
+	case TruncateMiddle:
+		return middle(result, l.MaxBytes/2), true, nil
+	case TruncateSummary:
+		if l.Summarize == nil {
+			return middle(result, l.MaxBytes/2), true, nil
+		}
+		head, tail := prefix(result, l.MaxBytes/4), suffix(result, l.MaxBytes/4)
+		omitted := result[len(head) : len(result)-len(tail)]
+		summary, err := l.Summarize(ctx, omitted, l.MaxBytes/2)
+		if err != nil {
+			return middle(result, l.MaxBytes/2), true, err
+		}
+		return head + fmt.Sprintf("\n[... %d bytes summarized: %s ...]\n", len(omitted), prefix(summary, l.MaxBytes/2)) + tail, true, nil
+	default:
+		kept := prefix(result, l.MaxBytes)
+		return kept + "\n" + truncationNote(len(result)-len(kept)), true, nil
+	}
+}
+
+// middle keeps keep bytes of each end of s around a truncation note
+func middle(s string, keep int) string {
+	head, tail := prefix(s, keep), suffix(s, keep)
+	return head + "\n" + truncationNote(len(s)-len(head)-len(tail)) + "\n" + tail
+}
+
+// truncationNote tells the model n bytes of a tool result were cut
+func truncationNote(n int) string {
+	return fmt.Sprintf("[... %d bytes truncated ...]", n)
+}
+
+// prefix returns at most n bytes of the beginning of s without splitting a
+// rune
+func prefix(s string, n int) string {
+	if n >= len(s) {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// suffix returns at most n bytes of the end of s without splitting a rune
+func suffix(s string, n int) string {
+	if n >= len(s) {
+		return s
+	}
+	start := len(s) - n
+	for start < len(s) && !utf8.RuneStart(s[start]) {
+		start++
+	}
+	return s[start:]
+}
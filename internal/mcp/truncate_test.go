@@ -0,0 +1,133 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func TestResultLimitApply(t *testing.T) {
+	result := "0123456789abcdefghij"
+	tests := []struct {
+		name       string
+		limit      ResultLimit
+		result     string
+		expected   string
+		truncated  bool
+		expectsErr bool
+	}{
+		{
+			name:     "no limit",
+			limit:    ResultLimit{Truncation: TruncateHead},
+			result:   result,
+			expected: result,
+		},
+		{
+			name:     "within the limit",
+			limit:    ResultLimit{MaxBytes: 20, Truncation: TruncateHead},
+			result:   result,
+			expected: result,
+		},
+		{
+			name:      "head",
+			limit:     ResultLimit{MaxBytes: 8, Truncation: TruncateHead},
+			result:    result,
+			expected:  "01234567\n[... 12 bytes truncated ...]",
+			truncated: true,
+		},
+		{
+			name:      "tail",
+			limit:     ResultLimit{MaxBytes: 8, Truncation: TruncateTail},
+			result:    result,
+			expected:  "[... 12 bytes truncated ...]\ncdefghij",
+			truncated: true,
+		},
+		{
+			name:      "middle",
+			limit:     ResultLimit{MaxBytes: 8, Truncation: TruncateMiddle},
+			result:    result,
+			expected:  "0123\n[... 12 bytes truncated ...]\nghij",
+			truncated: true,
+		},
+		{
+			name:      "head does not split runes",
+			limit:     ResultLimit{MaxBytes: 4, Truncation: TruncateHead},
+			result:    "ééééé",
+			expected:  "éé\n[... 6 bytes truncated ...]",
+			truncated: true,
+		},
+		{
+			name: "summary",
+			limit: ResultLimit{MaxBytes: 8, Truncation: TruncateSummary, Summarize: func(_ context.Context, text string, maxBytes int) (string, error) {
+				assert.Equal(t, "23456789abcdefgh", text)
+				assert.Equal(t, 4, maxBytes)
+				return "letters and digits", nil
+			}},
+			result:    result,
+			expected:  "01\n[... 16 bytes summarized: lett ...]\nij",
+			truncated: true,
+		},
+		{
+			name:      "summary without summarizer",
+			limit:     ResultLimit{MaxBytes: 8, Truncation: TruncateSummary},
+			result:    result,
+			expected:  "0123\n[... 12 bytes truncated ...]\nghij",
+			truncated: true,
+		},
+		{
+			name: "failed summary",
+			limit: ResultLimit{MaxBytes: 8, Truncation: TruncateSummary, Summarize: func(context.Context, string, int) (string, error) {
+				return "", errors.New("provider unavailable")
+			}},
+			result:     result,
+			expected:   "0123\n[... 12 bytes truncated ...]\nghij",
+			truncated:  true,
+			expectsErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limited, truncated, err := tt.limit.Apply(context.Background(), tt.result)
+			if tt.expectsErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, limited)
+			assert.Equal(t, tt.truncated, truncated)
+		})
+	}
+}
+
+func TestValidateResultLimit(t *testing.T) {
+	assert.NoError(t, validateResultLimit(&config.MCPConfig{ToolResultMaxBytes: 1024, ToolResultTruncation: TruncateMiddle}))
+	assert.NoError(t, validateResultLimit(&config.MCPConfig{ToolResultMaxBytes: 1024, ToolResultTruncation: TruncateSummary, ToolResultSummaryModel: "groq/llama-3.1-8b-instant"}))
+	assert.ErrorContains(t, validateResultLimit(&config.MCPConfig{ToolResultMaxBytes: 1024, ToolResultTruncation: TruncateSummary}), "MCP_TOOL_RESULT_SUMMARY_MODEL")
+	assert.ErrorContains(t, validateResultLimit(&config.MCPConfig{ToolResultTruncation: "first"}), "unknown strategy")
+}
+
+func TestAgentLimitsToolResults(t *testing.T) {
+	agent := NewAgentWithExecutors(logger.NewNoopLogger(),
+		config.Config{MCP: &config.MCPConfig{ToolResultMaxBytes: 16, ToolResultTruncation: TruncateHead}},
+		prefixExecutor{prefix: "read_", name: strings.Repeat("x", 32)},
+	)
+
+	results, err := agent.ExecuteTools(context.Background(), []types.ChatCompletionMessageToolCall{{
+		ID:       "call_1",
+		Type:     types.Function,
+		Function: types.ChatCompletionMessageToolCallFunction{Name: "read_file", Arguments: `{}`},
+	}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "call_1", *results[0].ToolCallID)
+	assert.Equal(t, strings.Repeat("x", 16)+"\n[... 27 bytes truncated ...]", results[0].TextContent())
+}
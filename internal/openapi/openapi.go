@@ -225,6 +225,9 @@ type ProviderConfig struct {
 	SupportsVision bool                      `yaml:"supports_vision"`
 	ExtraHeaders   map[string]ExtraHeader    `yaml:"extra_headers"`
 	Endpoints      map[string]EndpointSchema `yaml:"endpoints"`
+	Timeout        string                    `yaml:"timeout"`
+	ConnectTimeout string                    `yaml:"connect_timeout"`
+	MaxRetries     int                       `yaml:"max_retries"`
 }
 
 func Read(openapi string) (*OpenAPISchema, error) {
@@ -3,7 +3,8 @@
 // that repository, filters it to the gateway's supported cloud providers, and
 // emits the JSON tables embedded by providers/core: model pricing (USD
 // per-million-token rates converted to the gateway's per-token decimal-string
-// format, `task pricing:sync`) and context windows (`task contextwindow:sync`).
+// format, `task pricing:sync`), context windows (`task contextwindow:sync`)
+// and model capabilities (`task capabilities:sync`).
 package pricinggen
 
 import (
@@ -12,7 +13,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -67,6 +70,12 @@ type modelTOML struct {
 		Context int64 `toml:"context"`
 		Output  int64 `toml:"output"`
 	} `toml:"limit"`
+	ToolCall         bool `toml:"tool_call"`
+	Reasoning        bool `toml:"reasoning"`
+	StructuredOutput bool `toml:"structured_output"`
+	Modalities       struct {
+		Input []string `toml:"input"`
+	} `toml:"modalities"`
 }
 
 // contextWindowEntry is one row of the community context-window table: the
@@ -124,6 +133,35 @@ func GenerateContextWindows(output, tarballPath string) error {
 	return writeTable(output, tarballPath, table)
 }
 
+// GenerateCapabilities reads a models.dev repository tarball and writes the
+// community capability table keyed by "<provider>/<model>" to output. Every
+// model file declares its input modalities, so models without any are
+// treated as incomplete and get no entry.
+func GenerateCapabilities(output, tarballPath string) error {
+	table := make(map[string]types.ModelCapabilities)
+	err := forEachModel(tarballPath, func(key string, model modelTOML) {
+		if len(model.Modalities.Input) == 0 {
+			return
+		}
+		entry := types.ModelCapabilities{
+			SupportsTools:     model.ToolCall,
+			SupportsVision:    slices.Contains(model.Modalities.Input, "image"),
+			SupportsJSONMode:  model.StructuredOutput,
+			SupportsReasoning: model.Reasoning,
+			Source:            types.ModelCapabilitiesSourceCommunity,
+		}
+		if model.Limit.Output > 0 && model.Limit.Output <= math.MaxInt {
+			maxOutput := int(model.Limit.Output)
+			entry.MaxOutputTokens = &maxOutput
+		}
+		table[key] = entry
+	})
+	if err != nil {
+		return err
+	}
+	return writeTable(output, tarballPath, table)
+}
+
 // forEachModel walks a models.dev repository tarball and calls visit for every
 // model file that maps to a supported gateway provider.
 func forEachModel(tarballPath string, visit func(key string, model modelTOML)) error {
@@ -227,3 +227,46 @@ func TestGenerateContextWindows(t *testing.T) {
 		t.Error("model without a limit section must not get an entry")
 	}
 }
+
+func TestGenerateCapabilities(t *testing.T) {
+	tarball := writeTarball(t, map[string]string{
+		"sst-models.dev-abc/providers/openai/models/gpt-vision.toml":     "tool_call = true\nstructured_output = true\n[modalities]\ninput = [\"text\", \"image\"]\n[limit]\ncontext = 128000\noutput = 16384\n",
+		"sst-models.dev-abc/providers/deepseek/models/deepseek-r.toml":   "reasoning = true\n[modalities]\ninput = [\"text\"]\n",
+		"sst-models.dev-abc/providers/ollama-cloud/models/no-modal.toml": "tool_call = true\n",
+	})
+
+	output := filepath.Join(t.TempDir(), "capabilities.json")
+	if err := GenerateCapabilities(output, tarball); err != nil {
+		t.Fatalf("GenerateCapabilities() = %v", err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var table map[string]types.ModelCapabilities
+	if err := json.Unmarshal(data, &table); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(table) != 2 {
+		t.Errorf("table has %d entries, want 2: %+v", len(table), table)
+	}
+	vision := table["openai/gpt-vision"]
+	if !vision.SupportsTools || !vision.SupportsVision || !vision.SupportsJSONMode || vision.SupportsReasoning {
+		t.Errorf("openai/gpt-vision = %+v, want tools, vision and json mode", vision)
+	}
+	if vision.MaxOutputTokens == nil || *vision.MaxOutputTokens != 16384 {
+		t.Errorf("openai/gpt-vision max output = %v, want 16384", vision.MaxOutputTokens)
+	}
+	reasoning := table["deepseek/deepseek-r"]
+	if !reasoning.SupportsReasoning || reasoning.SupportsTools || reasoning.SupportsVision || reasoning.MaxOutputTokens != nil {
+		t.Errorf("deepseek/deepseek-r = %+v, want reasoning only", reasoning)
+	}
+	if reasoning.Source != types.ModelCapabilitiesSourceCommunity {
+		t.Errorf("source = %q, want community", reasoning.Source)
+	}
+	if _, ok := table["ollama_cloud/no-modal"]; ok {
+		t.Error("model without input modalities must not get an entry")
+	}
+}
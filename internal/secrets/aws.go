@@ -0,0 +1,74 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	sigv4 "github.com/inference-gateway/inference-gateway/internal/sigv4"
+)
+
+const (
+	awsService     = "secretsmanager"
+	awsTarget      = "secretsmanager.GetSecretValue"
+	awsContentType = "application/x-amz-json-1.1"
+)
+
+type awsBackend struct {
+	client   *http.Client
+	signer   *sigv4.Signer
+	endpoint string
+}
+
+// newAWSBackend creates a Secrets Manager backend. An empty endpoint selects
+// the regional AWS endpoint.
+func newAWSBackend(client *http.Client, region string, creds sigv4.Credentials, endpoint string) *awsBackend {
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.%s.amazonaws.com", awsService, region)
+	}
+	return &awsBackend{
+		client:   client,
+		signer:   &sigv4.Signer{Credentials: creds, Region: region, Service: awsService},
+		endpoint: endpoint,
+	}
+}
+
+// Fetch calls GetSecretValue for the secret ID path. With a field, the secret
+// string must be a JSON object holding it.
+func (a *awsBackend) Fetch(ctx context.Context, path, name string) (string, error) {
+	payload, err := json.Marshal(map[string]string{"SecretId": path})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.endpoint+"/", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", awsContentType)
+	req.Header.Set("X-Amz-Target", awsTarget)
+	a.signer.Sign(req, sigv4.HashPayload(payload))
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("secrets manager returned status %d for %s: %s", resp.StatusCode, path, body)
+	}
+
+	var out struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("failed to decode secrets manager response: %w", err)
+	}
+	if name == "" {
+		return out.SecretString, nil
+	}
+	return jsonField(out.SecretString, name)
+}
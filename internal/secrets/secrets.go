@@ -0,0 +1,160 @@
+// Package secrets fetches provider API keys from an external secrets backend
+// (HashiCorp Vault or AWS Secrets Manager) so they do not have to be passed
+// as env vars and can be rotated without a restart.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	config "github.com/inference-gateway/inference-gateway/config"
+	sigv4 "github.com/inference-gateway/inference-gateway/internal/sigv4"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+const (
+	// BackendVault reads secrets from the HashiCorp Vault HTTP API
+	BackendVault = "vault"
+	// BackendAWS reads secrets from AWS Secrets Manager
+	BackendAWS = "aws"
+)
+
+// fetchTimeout bounds a single secret read
+const fetchTimeout = 10 * time.Second
+
+// Backend reads secrets
+type Backend interface {
+	// Fetch returns the secret at path. field selects a field of a structured
+	// secret; it is empty when the whole secret is wanted.
+	Fetch(ctx context.Context, path, field string) (string, error)
+}
+
+// Ref locates a provider API key in the backend
+type Ref struct {
+	Path  string
+	Field string
+}
+
+// NewBackend creates the backend selected by PROVIDER_SECRETS_BACKEND. It
+// returns nil when no backend is configured.
+func NewBackend(cfg config.Config) (Backend, error) {
+	client := &http.Client{Timeout: fetchTimeout}
+	switch cfg.ProviderSecretsBackend {
+	case "":
+		return nil, nil
+	case BackendVault:
+		if cfg.VaultAddr == "" || cfg.VaultToken == "" {
+			return nil, fmt.Errorf("VAULT_ADDR and VAULT_TOKEN are required by the vault secrets backend")
+		}
+		return newVaultBackend(client, cfg.VaultAddr, cfg.VaultToken), nil
+	case BackendAWS:
+		creds, err := sigv4.CredentialsFromEnv("aws secrets backend")
+		if err != nil {
+			return nil, err
+		}
+		if cfg.AwsRegion == "" {
+			return nil, fmt.Errorf("AWS_REGION is required by the aws secrets backend")
+		}
+		return newAWSBackend(client, cfg.AwsRegion, creds, ""), nil
+	default:
+		return nil, fmt.Errorf("unknown secrets backend %q, expected %s or %s", cfg.ProviderSecretsBackend, BackendVault, BackendAWS)
+	}
+}
+
+// ParsePaths parses PROVIDER_SECRETS_PATHS, a comma-separated list of
+// provider=path#field pairs
+func ParsePaths(csv string) (map[types.Provider]Ref, error) {
+	refs := make(map[types.Provider]Ref)
+	for entry := range strings.SplitSeq(csv, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		provider, location, ok := strings.Cut(entry, "=")
+		provider = strings.TrimSpace(provider)
+		location = strings.TrimSpace(location)
+		if !ok || provider == "" || location == "" {
+			return nil, fmt.Errorf("expected provider=path, got %q", entry)
+		}
+		if !registry.Known(types.Provider(provider)) {
+			return nil, fmt.Errorf("unknown provider %q", provider)
+		}
+		path, field, _ := strings.Cut(location, "#")
+		refs[types.Provider(provider)] = Ref{Path: path, Field: field}
+	}
+	return refs, nil
+}
+
+// Validate checks the secrets settings of cfg without fetching anything
+func Validate(cfg config.Config) error {
+	if _, err := NewBackend(cfg); err != nil {
+		return err
+	}
+	refs, err := ParsePaths(cfg.ProviderSecretsPaths)
+	if err != nil {
+		return fmt.Errorf("invalid PROVIDER_SECRETS_PATHS: %w", err)
+	}
+	if cfg.ProviderSecretsBackend == "" && len(refs) > 0 {
+		return fmt.Errorf("PROVIDER_SECRETS_PATHS is set but PROVIDER_SECRETS_BACKEND is empty")
+	}
+	return nil
+}
+
+// Apply fetches the API key of every provider listed in
+// PROVIDER_SECRETS_PATHS and sets it on cfg.Providers. Nothing is changed
+// when a fetch fails, so callers keep serving the previous keys.
+func Apply(ctx context.Context, cfg *config.Config) error {
+	backend, err := NewBackend(*cfg)
+	if err != nil || backend == nil {
+		return err
+	}
+	refs, err := ParsePaths(cfg.ProviderSecretsPaths)
+	if err != nil {
+		return fmt.Errorf("invalid PROVIDER_SECRETS_PATHS: %w", err)
+	}
+
+	tokens := make(map[types.Provider]string, len(refs))
+	for provider, ref := range refs {
+		fetchCtx, cancel := context.WithTimeout(ctx, fetchTimeout)
+		token, err := backend.Fetch(fetchCtx, ref.Path, ref.Field)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to fetch api key of provider %s: %w", provider, err)
+		}
+		tokens[provider] = token
+	}
+
+	for provider, token := range tokens {
+		if providerCfg, ok := cfg.Providers[provider]; ok {
+			providerCfg.Token = token
+		}
+	}
+	return nil
+}
+
+// field extracts field from a JSON object secret
+func field(secret map[string]any, name string) (string, error) {
+	value, ok := secret[name]
+	if !ok {
+		return "", fmt.Errorf("secret has no field %q", name)
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("secret field %q is not a string", name)
+	}
+	return s, nil
+}
+
+// jsonField extracts field from a secret holding a JSON object
+func jsonField(secret, name string) (string, error) {
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(secret), &obj); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object: %w", err)
+	}
+	return field(obj, name)
+}
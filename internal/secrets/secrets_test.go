@@ -0,0 +1,167 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	config "github.com/inference-gateway/inference-gateway/config"
+	sigv4 "github.com/inference-gateway/inference-gateway/internal/sigv4"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func TestParsePaths(t *testing.T) {
+	refs, err := ParsePaths(" openai=secret/data/llm#openai, anthropic=prod/anthropic ,")
+	require.NoError(t, err)
+	assert.Equal(t, map[types.Provider]Ref{
+		constants.OpenaiID:    {Path: "secret/data/llm", Field: "openai"},
+		constants.AnthropicID: {Path: "prod/anthropic"},
+	}, refs)
+
+	for _, raw := range []string{"openai", "openai=", "=path", "unknown=path"} {
+		_, err := ParsePaths(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		wantErr string
+	}{
+		{name: "disabled", cfg: config.Config{}},
+		{name: "paths without backend", cfg: config.Config{ProviderSecretsPaths: "openai=p"}, wantErr: "PROVIDER_SECRETS_BACKEND is empty"},
+		{name: "unknown backend", cfg: config.Config{ProviderSecretsBackend: "gcp"}, wantErr: "unknown secrets backend"},
+		{name: "vault without token", cfg: config.Config{ProviderSecretsBackend: BackendVault, VaultAddr: "http://vault"}, wantErr: "VAULT_TOKEN"},
+		{name: "vault", cfg: config.Config{ProviderSecretsBackend: BackendVault, VaultAddr: "http://vault", VaultToken: "t", ProviderSecretsPaths: "openai=p"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.cfg)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func newVaultServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/llm":
+			_, _ = w.Write([]byte(`{"data":{"data":{"api_key":"sk-v2","openai":"sk-openai"},"metadata":{"version":3}}}`))
+		case "/v1/kv/llm":
+			_, _ = w.Write([]byte(`{"data":{"api_key":"sk-v1"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestVaultBackend(t *testing.T) {
+	srv := newVaultServer(t)
+	backend := newVaultBackend(srv.Client(), srv.URL+"/", "root")
+
+	tests := []struct {
+		name    string
+		path    string
+		field   string
+		want    string
+		wantErr string
+	}{
+		{name: "kv v2 default field", path: "secret/data/llm", want: "sk-v2"},
+		{name: "kv v2 named field", path: "/secret/data/llm", field: "openai", want: "sk-openai"},
+		{name: "kv v1", path: "kv/llm", want: "sk-v1"},
+		{name: "missing field", path: "kv/llm", field: "nope", wantErr: `no field "nope"`},
+		{name: "missing secret", path: "kv/other", wantErr: "status 404"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := backend.Fetch(context.Background(), tt.path, tt.field)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAWSBackend(t *testing.T) {
+	var headers http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		var req struct{ SecretId string }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		switch req.SecretId {
+		case "prod/openai":
+			_, _ = w.Write([]byte(`{"Name":"prod/openai","SecretString":"sk-raw"}`))
+		case "prod/llm":
+			_, _ = w.Write([]byte(`{"Name":"prod/llm","SecretString":"{\"openai\":\"sk-json\"}"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException"}`))
+		}
+	}))
+	defer srv.Close()
+
+	backend := newAWSBackend(srv.Client(), "eu-west-1", sigv4.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "session"}, srv.URL)
+	backend.signer.Now = func() time.Time { return time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	got, err := backend.Fetch(context.Background(), "prod/openai", "")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-raw", got)
+	assert.Equal(t, awsTarget, headers.Get("X-Amz-Target"))
+	assert.Equal(t, "20250102T030405Z", headers.Get("X-Amz-Date"))
+	assert.Equal(t, "session", headers.Get("X-Amz-Security-Token"))
+	auth := headers.Get("Authorization")
+	assert.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20250102/eu-west-1/secretsmanager/aws4_request, SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target, Signature="), auth)
+
+	got, err = backend.Fetch(context.Background(), "prod/llm", "openai")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-json", got)
+
+	_, err = backend.Fetch(context.Background(), "prod/missing", "")
+	assert.ErrorContains(t, err, "ResourceNotFoundException")
+}
+
+func TestApply(t *testing.T) {
+	srv := newVaultServer(t)
+	openai := *registry.Registry[constants.OpenaiID]
+	openai.Token = "sk-env"
+	cfg := config.Config{
+		ProviderSecretsBackend: BackendVault,
+		ProviderSecretsPaths:   "openai=secret/data/llm#openai",
+		VaultAddr:              srv.URL,
+		VaultToken:             "root",
+		Providers:              map[types.Provider]*registry.ProviderConfig{constants.OpenaiID: &openai},
+	}
+
+	require.NoError(t, Apply(context.Background(), &cfg))
+	assert.Equal(t, "sk-openai", cfg.Providers[constants.OpenaiID].Token)
+
+	cfg.ProviderSecretsPaths = "openai=secret/data/llm#openai,anthropic=kv/missing"
+	cfg.Providers[constants.OpenaiID].Token = "sk-previous"
+	assert.Error(t, Apply(context.Background(), &cfg))
+	assert.Equal(t, "sk-previous", cfg.Providers[constants.OpenaiID].Token, "a failed fetch changes no key")
+}
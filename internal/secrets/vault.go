@@ -0,0 +1,60 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// defaultVaultField is the field read when a Vault path has no #field
+const defaultVaultField = "api_key"
+
+type vaultBackend struct {
+	client *http.Client
+	addr   string
+	token  string
+}
+
+func newVaultBackend(client *http.Client, addr, token string) *vaultBackend {
+	return &vaultBackend{
+		client: client,
+		addr:   strings.TrimSuffix(addr, "/"),
+		token:  token,
+	}
+}
+
+// Fetch reads path with the Vault HTTP API. Both KV v2 (secret/data/...) and
+// KV v1 responses are understood.
+func (v *vaultBackend) Fetch(ctx context.Context, path, name string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.addr+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned status %d for %s", resp.StatusCode, path)
+	}
+
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode vault response: %w", err)
+	}
+	data := body.Data
+	if nested, ok := data["data"].(map[string]any); ok {
+		data = nested
+	}
+	if name == "" {
+		name = defaultVaultField
+	}
+	return field(data, name)
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"net"
+	"sync"
+
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+// limitListener bounds the connections open at once: overall, by holding
+// back Accept until a connection closes, and per peer IP, by closing the
+// connections above the limit right away
+type limitListener struct {
+	net.Listener
+	logger logger.Logger
+	// slots holds a token per open connection, nil when unlimited
+	slots chan struct{}
+	perIP int
+	done  chan struct{}
+	close sync.Once
+
+	mu    sync.Mutex
+	conns map[string]int
+}
+
+// LimitListener wraps listener so that at most maxConns connections, and at
+// most perIP connections of one peer IP, are open at once. A limit of 0
+// disables it.
+func LimitListener(listener net.Listener, maxConns, perIP int, log logger.Logger) net.Listener {
+	l := &limitListener{
+		Listener: listener,
+		logger:   log,
+		perIP:    perIP,
+		done:     make(chan struct{}),
+		conns:    make(map[string]int),
+	}
+	if maxConns > 0 {
+		l.slots = make(chan struct{}, maxConns)
+	}
+	return l
+}
+
+func (l *limitListener) Accept() (net.Conn, error) {
+	for {
+		if l.slots != nil {
+			select {
+			case l.slots <- struct{}{}:
+			case <-l.done:
+				return nil, net.ErrClosed
+			}
+		}
+
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			l.releaseSlot()
+			return nil, err
+		}
+
+		ip := peerIP(conn)
+		if l.perIP > 0 {
+			l.mu.Lock()
+			if l.conns[ip] >= l.perIP {
+				l.mu.Unlock()
+				l.logger.Debug("closing connection above the per-ip limit", "ip", ip, "limit", l.perIP)
+				_ = conn.Close()
+				l.releaseSlot()
+				continue
+			}
+			l.conns[ip]++
+			l.mu.Unlock()
+		}
+		return &limitedConn{Conn: conn, listener: l, ip: ip}, nil
+	}
+}
+
+func (l *limitListener) Close() error {
+	l.close.Do(func() { close(l.done) })
+	return l.Listener.Close()
+}
+
+func (l *limitListener) releaseSlot() {
+	if l.slots != nil {
+		<-l.slots
+	}
+}
+
+// release frees the slot of a closed connection of ip
+func (l *limitListener) release(ip string) {
+	if l.perIP > 0 {
+		l.mu.Lock()
+		if l.conns[ip]--; l.conns[ip] <= 0 {
+			delete(l.conns, ip)
+		}
+		l.mu.Unlock()
+	}
+	l.releaseSlot()
+}
+
+// limitedConn gives its slot back once closed
+type limitedConn struct {
+	net.Conn
+	listener *limitListener
+	ip       string
+	once     sync.Once
+}
+
+func (c *limitedConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(func() { c.listener.release(c.ip) })
+	return err
+}
+
+func peerIP(conn net.Conn) string {
+	host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		return conn.RemoteAddr().String()
+	}
+	return host
+}
@@ -0,0 +1,106 @@
+// Package server builds the gateway's HTTP server and its listener from the
+// SERVER_* settings: timeouts, TLS versions and cipher suites, HTTP/2 and
+// h2c, and connection limits.
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+// tlsVersions maps the SERVER_TLS_MIN_VERSION values to TLS versions, in
+// the format of CLIENT_TLS_MIN_VERSION
+var tlsVersions = map[string]uint16{
+	"TLS10": tls.VersionTLS10,
+	"TLS11": tls.VersionTLS11,
+	"TLS12": tls.VersionTLS12,
+	"TLS13": tls.VersionTLS13,
+}
+
+// New creates the gateway's HTTP server for handler. It fails on TLS
+// settings that cannot be applied rather than fall back to weaker ones.
+func New(cfg *config.ServerConfig, handler http.Handler) (*http.Server, error) {
+	server := &http.Server{
+		Addr:         cfg.Host + ":" + cfg.Port,
+		Handler:      handler,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+		// Large OIDC tokens can exceed net/http's 1MB default; oversized
+		// headers are rejected by net/http with a 431 before any handler runs.
+		MaxHeaderBytes: cfg.MaxHeaderBytes,
+	}
+
+	if cfg.TlsCertPath != "" && cfg.TlsKeyPath != "" {
+		tlsConfig, err := TLSConfig(cfg.TlsMinVersion, cfg.TlsCipherSuites)
+		if err != nil {
+			return nil, err
+		}
+		server.TLSConfig = tlsConfig
+	}
+
+	if cfg.H2cEnable {
+		protocols := new(http.Protocols)
+		protocols.SetHTTP1(true)
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+		server.Protocols = protocols
+	}
+	if cfg.Http2MaxConcurrentStreams > 0 {
+		server.HTTP2 = &http.HTTP2Config{MaxConcurrentStreams: cfg.Http2MaxConcurrentStreams}
+	}
+	return server, nil
+}
+
+// TLSConfig builds the server TLS configuration from SERVER_TLS_MIN_VERSION
+// and SERVER_TLS_CIPHER_SUITES. Only the secure suites of crypto/tls can be
+// selected.
+func TLSConfig(minVersion, cipherSuites string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if minVersion != "" {
+		version, ok := tlsVersions[strings.ToUpper(minVersion)]
+		if !ok {
+			return nil, fmt.Errorf("invalid SERVER_TLS_MIN_VERSION %q: must be one of TLS10, TLS11, TLS12 or TLS13", minVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+
+	if strings.TrimSpace(cipherSuites) == "" {
+		return tlsConfig, nil
+	}
+	known := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+	for name := range strings.SplitSeq(cipherSuites, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid SERVER_TLS_CIPHER_SUITES: unknown or insecure cipher suite %q", name)
+		}
+		tlsConfig.CipherSuites = append(tlsConfig.CipherSuites, id)
+	}
+	return tlsConfig, nil
+}
+
+// Listen opens the TCP listener of the server, applying
+// SERVER_MAX_CONNECTIONS and SERVER_MAX_CONNECTIONS_PER_IP
+func Listen(cfg *config.ServerConfig, log logger.Logger) (net.Listener, error) {
+	listener, err := net.Listen("tcp", cfg.Host+":"+cfg.Port)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MaxConnections <= 0 && cfg.MaxConnectionsPerIp <= 0 {
+		return listener, nil
+	}
+	return LimitListener(listener, cfg.MaxConnections, cfg.MaxConnectionsPerIp, log), nil
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+func TestTLSConfig(t *testing.T) {
+	cfg, err := TLSConfig("TLS13", "")
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), cfg.MinVersion)
+	assert.Nil(t, cfg.CipherSuites)
+
+	cfg, err = TLSConfig("", "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384")
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), cfg.MinVersion)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}, cfg.CipherSuites)
+
+	_, err = TLSConfig("1.2", "")
+	assert.ErrorContains(t, err, "SERVER_TLS_MIN_VERSION")
+
+	_, err = TLSConfig("TLS12", "TLS_RSA_WITH_RC4_128_SHA")
+	assert.ErrorContains(t, err, "insecure cipher suite")
+}
+
+func TestH2C(t *testing.T) {
+	cfg := &config.ServerConfig{Host: "127.0.0.1", Port: "0", H2cEnable: true, Http2MaxConcurrentStreams: 10}
+	server, err := New(cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Proto)
+	}))
+	require.NoError(t, err)
+	listener, err := Listen(cfg, logger.NewNoopLogger())
+	require.NoError(t, err)
+	go func() { _ = server.Serve(listener) }()
+	defer func() { _ = server.Close() }()
+
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+	resp, err := client.Get("http://" + listener.Addr().String())
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/2.0", string(body))
+}
+
+func TestLimitListener(t *testing.T) {
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	listener := LimitListener(inner, 2, 1, logger.NewNoopLogger())
+	defer func() { _ = listener.Close() }()
+
+	accepted := make(chan net.Conn, 4)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	first, err := net.Dial("tcp", inner.Addr().String())
+	require.NoError(t, err)
+	defer func() { _ = first.Close() }()
+	serverSide := <-accepted
+
+	// A second connection of the same IP is closed right away
+	second, err := net.Dial("tcp", inner.Addr().String())
+	require.NoError(t, err)
+	defer func() { _ = second.Close() }()
+	_ = second.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, err = second.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Empty(t, accepted)
+
+	// Once the first closes, the IP may connect again
+	require.NoError(t, serverSide.Close())
+	third, err := net.Dial("tcp", inner.Addr().String())
+	require.NoError(t, err)
+	defer func() { _ = third.Close() }()
+	select {
+	case conn := <-accepted:
+		_ = conn.Close()
+	case <-time.After(2 * time.Second):
+		t.Fatal("the connection was not accepted after the first closed")
+	}
+}
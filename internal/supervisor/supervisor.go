@@ -0,0 +1,172 @@
+// Package supervisor accounts for the goroutines and long-running work the
+// gateway starts on behalf of requests: MCP agent loops and the goroutines
+// pumping provider streams. It caps the work of each kind across requests,
+// binds supervised goroutines to the context of their request so they stop
+// with it, and reports work still running once its request completed.
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// Kind is a kind of supervised work
+type Kind string
+
+const (
+	// Agent is an MCP agent loop
+	Agent Kind = "agent"
+	// ProviderStream is a goroutine reading the stream of a provider
+	ProviderStream Kind = "provider_stream"
+)
+
+// Kinds lists every kind of supervised work
+var Kinds = []Kind{Agent, ProviderStream}
+
+// ErrAtCapacity is returned when the limit of a kind of work is reached
+var ErrAtCapacity = errors.New("too much work of this kind is running")
+
+// Supervisor accounts for supervised work. The zero value is not usable; use
+// New.
+type Supervisor struct {
+	limits map[Kind]int64
+	active map[Kind]*atomic.Int64
+}
+
+// New returns a supervisor capping each kind of work to the limit of limits,
+// kinds without a positive limit being unlimited
+func New(limits map[Kind]int) *Supervisor {
+	s := &Supervisor{
+		limits: make(map[Kind]int64, len(limits)),
+		active: make(map[Kind]*atomic.Int64, len(Kinds)),
+	}
+	for kind, limit := range limits {
+		if limit > 0 {
+			s.limits[kind] = int64(limit)
+		}
+	}
+	for _, kind := range Kinds {
+		s.active[kind] = &atomic.Int64{}
+	}
+	return s
+}
+
+// Active returns the amount of work of kind running
+func (s *Supervisor) Active(kind Kind) int64 {
+	if counter, ok := s.active[kind]; ok {
+		return counter.Load()
+	}
+	return 0
+}
+
+// acquire accounts for one more work of kind, unless its limit is reached
+func (s *Supervisor) acquire(kind Kind) bool {
+	counter, ok := s.active[kind]
+	if !ok {
+		return true
+	}
+	limit, capped := s.limits[kind]
+	for {
+		n := counter.Load()
+		if capped && n >= limit {
+			return false
+		}
+		if counter.CompareAndSwap(n, n+1) {
+			return true
+		}
+	}
+}
+
+func (s *Supervisor) release(kind Kind) {
+	if counter, ok := s.active[kind]; ok {
+		counter.Add(-1)
+	}
+}
+
+type requestKey struct{}
+
+// request is the accounting of the work of one request
+type request struct {
+	supervisor *Supervisor
+	mu         sync.Mutex
+	active     map[Kind]int
+	started    map[Kind]int
+}
+
+// WithRequest returns ctx carrying the accounting of a new request, under
+// which Track and Go account the work they supervise
+func (s *Supervisor) WithRequest(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestKey{}, &request{
+		supervisor: s,
+		active:     make(map[Kind]int),
+		started:    make(map[Kind]int),
+	})
+}
+
+// Usage reports the work started by the request of ctx and the work of it
+// still running, by kind
+func Usage(ctx context.Context) (started, running map[Kind]int) {
+	r, ok := ctx.Value(requestKey{}).(*request)
+	if !ok {
+		return nil, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	started, running = make(map[Kind]int), make(map[Kind]int)
+	for kind, n := range r.started {
+		started[kind] = n
+	}
+	for kind, n := range r.active {
+		if n > 0 {
+			running[kind] = n
+		}
+	}
+	return started, running
+}
+
+// Track accounts for work of kind run by the caller for the request of ctx
+// until done is called. It fails with ErrAtCapacity when the limit of kind
+// is reached. Without a request in ctx the work is not supervised.
+func Track(ctx context.Context, kind Kind) (done func(), err error) {
+	r, ok := ctx.Value(requestKey{}).(*request)
+	if !ok {
+		return func() {}, nil
+	}
+	if !r.supervisor.acquire(kind) {
+		return nil, ErrAtCapacity
+	}
+	r.mu.Lock()
+	r.active[kind]++
+	r.started[kind]++
+	r.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			r.mu.Lock()
+			r.active[kind]--
+			r.mu.Unlock()
+			r.supervisor.release(kind)
+		})
+	}, nil
+}
+
+// Go runs fn in a goroutine accounted as work of kind for the request of
+// ctx. fn gets a context canceled when ctx is done or fn returns, so it
+// stops with its request. It fails with ErrAtCapacity, without starting fn,
+// when the limit of kind is reached.
+func Go(ctx context.Context, kind Kind, fn func(ctx context.Context)) error {
+	done, err := Track(ctx, kind)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		defer done()
+		defer cancel()
+		fn(ctx)
+	}()
+	return nil
+}
@@ -0,0 +1,103 @@
+package supervisor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+)
+
+func TestTrackLimits(t *testing.T) {
+	s := New(map[Kind]int{Agent: 2})
+	ctx := s.WithRequest(context.Background())
+
+	first, err := Track(ctx, Agent)
+	require.NoError(t, err)
+	second, err := Track(ctx, Agent)
+	require.NoError(t, err)
+	_, err = Track(ctx, Agent)
+	assert.ErrorIs(t, err, ErrAtCapacity)
+
+	other := s.WithRequest(context.Background())
+	_, err = Track(other, Agent)
+	assert.ErrorIs(t, err, ErrAtCapacity, "the limit applies across requests")
+	streamDone, err := Track(other, ProviderStream)
+	require.NoError(t, err, "kinds without a limit are unlimited")
+	assert.Equal(t, int64(2), s.Active(Agent))
+
+	first()
+	first()
+	assert.Equal(t, int64(1), s.Active(Agent), "done is idempotent")
+	third, err := Track(other, Agent)
+	require.NoError(t, err)
+
+	second()
+	third()
+	streamDone()
+	assert.Equal(t, int64(0), s.Active(Agent))
+	assert.Equal(t, int64(0), s.Active(ProviderStream))
+}
+
+func TestTrackWithoutRequest(t *testing.T) {
+	done, err := Track(context.Background(), Agent)
+	require.NoError(t, err)
+	done()
+
+	started, running := Usage(context.Background())
+	assert.Nil(t, started)
+	assert.Nil(t, running)
+}
+
+func TestUsage(t *testing.T) {
+	s := New(nil)
+	ctx := s.WithRequest(context.Background())
+
+	agentDone, err := Track(ctx, Agent)
+	require.NoError(t, err)
+	streamDone, err := Track(ctx, ProviderStream)
+	require.NoError(t, err)
+	agentDone()
+
+	started, running := Usage(ctx)
+	assert.Equal(t, map[Kind]int{Agent: 1, ProviderStream: 1}, started)
+	assert.Equal(t, map[Kind]int{ProviderStream: 1}, running)
+
+	streamDone()
+	_, running = Usage(ctx)
+	assert.Empty(t, running)
+}
+
+func TestGo(t *testing.T) {
+	s := New(map[Kind]int{ProviderStream: 1})
+	ctx, cancel := context.WithCancel(s.WithRequest(context.Background()))
+
+	stopped := make(chan struct{})
+	err := Go(ctx, ProviderStream, func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	})
+	require.NoError(t, err)
+	assert.ErrorIs(t, Go(ctx, ProviderStream, func(context.Context) {}), ErrAtCapacity)
+
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("supervised goroutine did not stop with its request")
+	}
+	assert.Eventually(t, func() bool {
+		_, running := Usage(ctx)
+		return len(running) == 0 && s.Active(ProviderStream) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	var fnCtx context.Context
+	finished := make(chan struct{})
+	require.NoError(t, Go(s.WithRequest(context.Background()), ProviderStream, func(ctx context.Context) {
+		fnCtx = ctx
+		close(finished)
+	}))
+	<-finished
+	assert.Eventually(t, func() bool { return fnCtx.Err() != nil }, time.Second, 10*time.Millisecond, "the context of fn is canceled once it returns")
+}
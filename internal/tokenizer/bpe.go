@@ -0,0 +1,145 @@
+package tokenizer
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Pre-tokenization patterns of the tiktoken encodings. RE2 has no lookahead,
+// so their `\s+(?!\S)|\s+` tail is written as a captured `(\s+)` and the
+// lookahead is applied by split.
+var patterns = map[string]*regexp.Regexp{
+	CL100K: regexp.MustCompile(`(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|(\s+)`),
+	O200K: regexp.MustCompile(`[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]*[\p{Ll}\p{Lm}\p{Lo}\p{M}]+(?i:'s|'t|'re|'ve|'m|'ll|'d)?` +
+		`|[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]+[\p{Ll}\p{Lm}\p{Lo}\p{M}]*(?i:'s|'t|'re|'ve|'m|'ll|'d)?` +
+		`|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n/]*|\s*[\r\n]+|(\s+)`),
+}
+
+// BPE is a byte pair encoding tokenizer compatible with tiktoken
+type BPE struct {
+	name    string
+	pattern *regexp.Regexp
+	ranks   map[string]int
+}
+
+// LoadBPE reads the tiktoken rank file at path, one base64 token and its rank
+// per line, for the encoding name
+func LoadBPE(name, path string) (*BPE, error) {
+	pattern, ok := patterns[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown encoding %q", name)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ranks := make(map[string]int)
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		encoded, rawRank, ok := strings.Cut(text, " ")
+		token, err := base64.StdEncoding.DecodeString(encoded)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid token", path, line)
+		}
+		rank, err := strconv.Atoi(rawRank)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid rank %q", path, line, rawRank)
+		}
+		ranks[string(token)] = rank
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return &BPE{name: name, pattern: pattern, ranks: ranks}, nil
+}
+
+func (b *BPE) Name() string {
+	return b.name
+}
+
+func (b *BPE) Count(text string) int {
+	tokens, _ := b.Encode(text)
+	return len(tokens)
+}
+
+// Encode splits text with the encoding's pattern and merges the bytes of
+// every piece by rank. Special tokens such as <|endoftext|> are encoded as
+// plain text.
+func (b *BPE) Encode(text string) ([]int, bool) {
+	var tokens []int
+	for _, piece := range b.split(text) {
+		if rank, ok := b.ranks[piece]; ok {
+			tokens = append(tokens, rank)
+			continue
+		}
+		tokens = b.merge(piece, tokens)
+	}
+	return tokens, true
+}
+
+// split cuts text into pieces like the tiktoken pattern would. A whitespace
+// run matched by the trailing `\s+` gives its last character back to the next
+// piece when followed by a non-space, as `\s+(?!\S)` does.
+func (b *BPE) split(text string) []string {
+	var pieces []string
+	for len(text) > 0 {
+		loc := b.pattern.FindStringSubmatchIndex(text)
+		if loc == nil || loc[0] != 0 || loc[1] == 0 {
+			// Unreachable with the bundled patterns, which match any rune
+			_, size := utf8.DecodeRuneInString(text)
+			pieces = append(pieces, text[:size])
+			text = text[size:]
+			continue
+		}
+		end := loc[1]
+		if loc[2] >= 0 && end < len(text) {
+			if _, last := utf8.DecodeLastRuneInString(text[:end]); last < end {
+				end -= last
+			}
+		}
+		pieces = append(pieces, text[:end])
+		text = text[end:]
+	}
+	return pieces
+}
+
+// merge appends the tokens of piece to tokens, repeatedly merging the
+// adjacent pair with the lowest rank
+func (b *BPE) merge(piece string, tokens []int) []int {
+	bounds := make([]int, len(piece)+1)
+	for i := range bounds {
+		bounds[i] = i
+	}
+	for len(bounds) > 2 {
+		best, bestRank := -1, math.MaxInt
+		for i := 0; i+2 < len(bounds); i++ {
+			if rank, ok := b.ranks[piece[bounds[i]:bounds[i+2]]]; ok && rank < bestRank {
+				best, bestRank = i, rank
+			}
+		}
+		if best < 0 {
+			break
+		}
+		bounds = slices.Delete(bounds, best+1, best+2)
+	}
+	for i := 0; i+1 < len(bounds); i++ {
+		tokens = append(tokens, b.ranks[piece[bounds[i]:bounds[i+1]]])
+	}
+	return tokens
+}
@@ -0,0 +1,169 @@
+// Package tokenizer counts tokens locally, so the gateway can account for
+// usage and check prompt sizes without asking the provider. OpenAI models use
+// their tiktoken encodings when the rank files are available; every other
+// model gets a character-based estimate.
+package tokenizer
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// Names of the supported encodings
+const (
+	CL100K    = "cl100k_base"
+	O200K     = "o200k_base"
+	Heuristic = "heuristic"
+)
+
+// charsPerToken is the rough number of characters per token of the
+// heuristic tokenizer, in line with typical BPE vocabularies on English text
+const charsPerToken = 4
+
+// Message framing overhead of the chat format: every message costs
+// tokensPerMessage on top of its role and content, and every prompt is primed
+// with tokensPerReply for the assistant reply
+const (
+	tokensPerMessage = 3
+	tokensPerReply   = 3
+)
+
+// Tokenizer counts the tokens of text for one encoding
+type Tokenizer interface {
+	// Name returns the encoding name
+	Name() string
+	// Count returns the number of tokens of text
+	Count(text string) int
+	// Encode returns the token IDs of text. It returns false for tokenizers
+	// that only estimate counts.
+	Encode(text string) ([]int, bool)
+}
+
+// modelEncodings maps OpenAI model name prefixes to their encoding. Longer
+// prefixes come first, so gpt-4o is not taken for gpt-4.
+var modelEncodings = []struct {
+	prefix   string
+	encoding string
+}{
+	{"gpt-4o", O200K},
+	{"gpt-4.1", O200K},
+	{"gpt-4.5", O200K},
+	{"gpt-5", O200K},
+	{"chatgpt-4o", O200K},
+	{"o1", O200K},
+	{"o3", O200K},
+	{"o4", O200K},
+	{"gpt-4", CL100K},
+	{"gpt-3.5", CL100K},
+	{"text-embedding-3", CL100K},
+	{"text-embedding-ada", CL100K},
+}
+
+// Registry hands out the tokenizer of each model, loading the tiktoken rank
+// files <encoding>.tiktoken from a directory on first use
+type Registry struct {
+	dir       string
+	logger    logger.Logger
+	mu        sync.Mutex
+	encodings map[string]Tokenizer
+}
+
+// NewRegistry creates a registry reading rank files from dir. With an empty
+// dir every model uses the heuristic tokenizer.
+func NewRegistry(dir string, logger logger.Logger) *Registry {
+	return &Registry{
+		dir:       dir,
+		logger:    logger,
+		encodings: make(map[string]Tokenizer),
+	}
+}
+
+// ForModel returns the tokenizer of model, given with or without its
+// provider prefix. Unknown models and encodings whose rank file cannot be
+// loaded fall back to the heuristic tokenizer.
+func (r *Registry) ForModel(model string) Tokenizer {
+	encoding := Encoding(model)
+	if encoding == Heuristic || r.dir == "" {
+		return HeuristicTokenizer{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t, ok := r.encodings[encoding]; ok {
+		return t
+	}
+	var t Tokenizer = HeuristicTokenizer{}
+	bpe, err := LoadBPE(encoding, filepath.Join(r.dir, encoding+".tiktoken"))
+	if err != nil {
+		r.logger.Warn("failed to load tokenizer encoding, estimating token counts", "encoding", encoding, "error", err.Error())
+	} else {
+		t = bpe
+	}
+	// Failures are cached too, so a missing file is only reported once
+	r.encodings[encoding] = t
+	return t
+}
+
+// Encoding returns the encoding name of model, or Heuristic for models
+// without a known tiktoken encoding
+func Encoding(model string) string {
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		model = model[i+1:]
+	}
+	model = strings.ToLower(model)
+	for _, e := range modelEncodings {
+		if strings.HasPrefix(model, e.prefix) {
+			return e.encoding
+		}
+	}
+	return Heuristic
+}
+
+// CountMessages returns the prompt tokens of messages and tool definitions in
+// the chat format, following the framing of OpenAI chat models. Image parts
+// are not counted.
+func CountMessages(t Tokenizer, messages []types.Message, tools *[]types.ChatCompletionTool) int {
+	n := tokensPerReply
+	for i := range messages {
+		m := &messages[i]
+		n += tokensPerMessage + t.Count(string(m.Role)) + t.Count(m.TextContent())
+		if m.ToolCalls != nil {
+			for _, call := range *m.ToolCalls {
+				n += t.Count(call.Function.Name) + t.Count(call.Function.Arguments)
+			}
+		}
+		if m.ToolCallID != nil {
+			n += t.Count(*m.ToolCallID)
+		}
+	}
+	if tools != nil && len(*tools) > 0 {
+		if definitions, err := json.Marshal(tools); err == nil {
+			n += t.Count(string(definitions))
+		}
+	}
+	return n
+}
+
+// HeuristicTokenizer estimates token counts from character counts
+type HeuristicTokenizer struct{}
+
+func (HeuristicTokenizer) Name() string {
+	return Heuristic
+}
+
+func (HeuristicTokenizer) Count(text string) int {
+	if text == "" {
+		return 0
+	}
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
+
+func (HeuristicTokenizer) Encode(string) ([]int, bool) {
+	return nil, false
+}
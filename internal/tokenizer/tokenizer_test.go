@@ -0,0 +1,89 @@
+package tokenizer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// writeRanks writes a rank file holding every single byte, ranked by its
+// value, followed by merges
+func writeRanks(t *testing.T, dir, name string, merges ...string) {
+	t.Helper()
+	var b strings.Builder
+	for i := range 256 {
+		fmt.Fprintf(&b, "%s %d\n", base64.StdEncoding.EncodeToString([]byte{byte(i)}), i)
+	}
+	for i, merge := range merges {
+		fmt.Fprintf(&b, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(merge)), 256+i)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".tiktoken"), []byte(b.String()), 0o600))
+}
+
+func TestBPE(t *testing.T) {
+	dir := t.TempDir()
+	writeRanks(t, dir, CL100K, "he", "ll", "hell", "hello", " w")
+	bpe, err := LoadBPE(CL100K, filepath.Join(dir, CL100K+".tiktoken"))
+	require.NoError(t, err)
+
+	tokens, ok := bpe.Encode("hello world")
+	require.True(t, ok)
+	assert.Equal(t, []int{259, 260, 'o', 'r', 'l', 'd'}, tokens)
+	assert.Equal(t, 6, bpe.Count("hello world"))
+	assert.Equal(t, 0, bpe.Count(""))
+}
+
+func TestBPE_Split(t *testing.T) {
+	tests := []struct {
+		encoding string
+		text     string
+		expected []string
+	}{
+		{CL100K, "Hello   world\n\nHow's it?  ", []string{"Hello", "  ", " world", "\n\n", "How", "'s", " it", "?", "  "}},
+		{CL100K, "12345 x", []string{"123", "45", " x"}},
+		{O200K, "HelloWorld's  path/to", []string{"Hello", "World's", " ", " path", "/to"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.encoding+"/"+tt.text, func(t *testing.T) {
+			bpe := &BPE{name: tt.encoding, pattern: patterns[tt.encoding]}
+			assert.Equal(t, tt.expected, bpe.split(tt.text))
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	dir := t.TempDir()
+	writeRanks(t, dir, O200K)
+	r := NewRegistry(dir, logger.NewNoopLogger())
+
+	assert.Equal(t, O200K, r.ForModel("openai/gpt-4o-mini").Name())
+	assert.Equal(t, Heuristic, r.ForModel("openai/gpt-4").Name(), "missing rank files fall back to the heuristic")
+	assert.Equal(t, Heuristic, r.ForModel("anthropic/claude-sonnet-4").Name())
+	assert.Equal(t, Heuristic, NewRegistry("", logger.NewNoopLogger()).ForModel("gpt-4o").Name())
+
+	assert.Equal(t, CL100K, Encoding("GPT-3.5-turbo"))
+	assert.Equal(t, O200K, Encoding("groq/openai/gpt-5"))
+}
+
+func TestCountMessages(t *testing.T) {
+	var system, user types.MessageContent
+	require.NoError(t, system.FromMessageContent0("Be brief"))
+	require.NoError(t, user.FromMessageContent0("Hello there"))
+	messages := []types.Message{
+		{Role: types.System, Content: system},
+		{Role: types.User, Content: user},
+	}
+
+	// 3 per reply, then 3 per message plus role and content
+	assert.Equal(t, 3+(3+2+2)+(3+1+3), CountMessages(HeuristicTokenizer{}, messages, nil))
+	assert.Equal(t, 3, HeuristicTokenizer{}.Count("Hello there"))
+}
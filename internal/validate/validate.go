@@ -0,0 +1,331 @@
+// Package validate checks a gateway configuration before it is deployed: the
+// settings and the files they point to and, unless offline, the reachability
+// and API keys of the configured providers and MCP servers. It backs the
+// validate command of the gateway, run from CI or Kubernetes init containers.
+package validate
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	admin "github.com/inference-gateway/inference-gateway/api/admin"
+	hooks "github.com/inference-gateway/inference-gateway/api/hooks"
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	modeldefaults "github.com/inference-gateway/inference-gateway/api/modeldefaults"
+	modelpolicy "github.com/inference-gateway/inference-gateway/api/modelpolicy"
+	prompts "github.com/inference-gateway/inference-gateway/api/prompts"
+	shadow "github.com/inference-gateway/inference-gateway/api/shadow"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	think "github.com/inference-gateway/inference-gateway/api/think"
+	config "github.com/inference-gateway/inference-gateway/config"
+	mcp "github.com/inference-gateway/inference-gateway/internal/mcp"
+	secrets "github.com/inference-gateway/inference-gateway/internal/secrets"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	client "github.com/inference-gateway/inference-gateway/providers/client"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	core "github.com/inference-gateway/inference-gateway/providers/core"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	routing "github.com/inference-gateway/inference-gateway/providers/routing"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// Status is the outcome of a check
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// Kinds of checks
+const (
+	KindConfig   = "config"
+	KindSecrets  = "secrets"
+	KindProvider = "provider"
+	KindMCP      = "mcp"
+)
+
+// Exit codes of the validate command
+const (
+	// ExitOK is returned when no check failed
+	ExitOK = 0
+	// ExitUnreachable is returned when the configuration is valid but a
+	// provider, MCP server or the secrets backend failed its check
+	ExitUnreachable = 1
+	// ExitInvalidConfig is returned when the configuration is invalid
+	ExitInvalidConfig = 2
+)
+
+// Check is the outcome of checking one part of the configuration
+type Check struct {
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+	Status  Status `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// Report lists the checks of a configuration, Status being fail when any
+// failed
+type Report struct {
+	Status Status  `json:"status"`
+	Checks []Check `json:"checks"`
+}
+
+// Options configures a validation
+type Options struct {
+	// Offline skips the checks calling the secrets backend, the providers
+	// and the MCP servers
+	Offline bool
+	// Client calls the providers
+	Client client.Client
+	// Logger logs the MCP client, a no-op logger when nil
+	Logger logger.Logger
+}
+
+// Run validates cfg. The settings and their files are checked first; the
+// online checks are skipped when they are invalid, as the gateway would not
+// start.
+func Run(ctx context.Context, cfg config.Config, opts Options) *Report {
+	if opts.Logger == nil {
+		opts.Logger = logger.NewNoopLogger()
+	}
+	r := &Report{Status: StatusPass}
+	checkSettings(r, cfg, opts.Logger)
+	if opts.Offline || r.Status == StatusFail {
+		return r
+	}
+
+	if cfg.ProviderSecretsBackend != "" {
+		if err := secrets.Apply(ctx, &cfg); err != nil {
+			r.add(KindSecrets, cfg.ProviderSecretsBackend, err, "")
+			return r
+		}
+		r.add(KindSecrets, cfg.ProviderSecretsBackend, nil, "provider api keys fetched")
+	}
+	checkProviders(ctx, r, cfg, opts.Client)
+	checkMCP(ctx, r, cfg, opts.Logger)
+	return r
+}
+
+// LoadFailed returns the report of a configuration that could not be loaded
+func LoadFailed(err error) *Report {
+	r := &Report{Status: StatusPass}
+	r.add(KindConfig, "load", err, "")
+	return r
+}
+
+// ExitCode returns the exit code of the validate command for the report
+func (r *Report) ExitCode() int {
+	code := ExitOK
+	for _, c := range r.Checks {
+		if c.Status != StatusFail {
+			continue
+		}
+		if c.Kind == KindConfig {
+			return ExitInvalidConfig
+		}
+		code = ExitUnreachable
+	}
+	return code
+}
+
+// WriteText writes the report as one line per check followed by a summary
+func (r *Report) WriteText(w io.Writer) error {
+	counts := make(map[Status]int)
+	for _, c := range r.Checks {
+		counts[c.Status]++
+		line := fmt.Sprintf("%-4s  %-8s  %s", strings.ToUpper(string(c.Status)), c.Kind, c.Name)
+		if c.Message != "" {
+			line += ": " + c.Message
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "\n%s: %d passed, %d warnings, %d failed\n", r.Status, counts[StatusPass], counts[StatusWarn], counts[StatusFail])
+	return err
+}
+
+// WriteJSON writes the report as JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// add records the check name of kind, failed with err when not nil and
+// passed with message otherwise
+func (r *Report) add(kind, name string, err error, message string) {
+	c := Check{Name: name, Kind: kind, Status: StatusPass, Message: message}
+	if err != nil {
+		c.Status = StatusFail
+		c.Message = err.Error()
+		r.Status = StatusFail
+	}
+	r.Checks = append(r.Checks, c)
+}
+
+// warn records the check name of kind with a warning
+func (r *Report) warn(kind, name, message string) {
+	r.Checks = append(r.Checks, Check{Name: name, Kind: kind, Status: StatusWarn, Message: message})
+}
+
+// checkSettings checks the settings and the files they point to the way the
+// gateway does at startup
+func checkSettings(r *Report, cfg config.Config, log logger.Logger) {
+	registry.SetCustomProviders(cfg.Providers)
+	_, err := logger.ParseRedaction(cfg.LogRedaction)
+	r.add(KindConfig, "log_redaction", err, "")
+	_, err = think.ParseMode(cfg.ThinkTagMode)
+	r.add(KindConfig, "think_tag_mode", err, "")
+	r.add(KindConfig, "provider_policies", registry.ApplyPolicies(cfg.Providers, cfg.ProviderTimeouts, cfg.ProviderConnectTimeouts, cfg.ProviderRetries), "")
+	r.add(KindConfig, "provider_secrets", secrets.Validate(cfg), "")
+	_, err = middlewares.ParseProxyPaths(cfg.ProxyAllowedPaths)
+	r.add(KindConfig, "proxy_allowed_paths", err, "")
+	if cfg.ShadowModels != "" {
+		_, err := shadow.ParseModels(cfg.ShadowModels)
+		r.add(KindConfig, "shadow_models", err, "")
+	}
+
+	if cfg.ModelDefaultsPath != "" {
+		_, err := modeldefaults.Load(cfg.ModelDefaultsPath)
+		r.add(KindConfig, "model_defaults", err, cfg.ModelDefaultsPath)
+	}
+	if cfg.ModelPolicyPath != "" {
+		_, err := modelpolicy.Load(cfg.ModelPolicyPath)
+		r.add(KindConfig, "model_policy", err, cfg.ModelPolicyPath)
+	}
+	if cfg.HooksConfigPath != "" {
+		hooksCfg, err := hooks.LoadConfig(cfg.HooksConfigPath)
+		if err == nil {
+			_, err = hooks.NewPipeline(hooksCfg)
+		}
+		r.add(KindConfig, "hooks", err, cfg.HooksConfigPath)
+	}
+	if cfg.PromptsConfigPath != "" {
+		promptsCfg, err := prompts.LoadConfig(cfg.PromptsConfigPath)
+		if err == nil {
+			_, err = prompts.NewStore(promptsCfg)
+		}
+		r.add(KindConfig, "prompts", err, cfg.PromptsConfigPath)
+	}
+	if cfg.TenantsConfigPath != "" {
+		tenantsCfg, err := tenants.LoadConfig(cfg.TenantsConfigPath)
+		if err == nil {
+			_, err = tenants.NewStore(tenantsCfg, registry.NewProviderRegistry(cfg.Providers, log), log)
+		}
+		r.add(KindConfig, "tenants", err, cfg.TenantsConfigPath)
+	}
+	if cfg.Routing != nil && cfg.Routing.Rules != "" {
+		_, err := routing.ParseRules(cfg.Routing.Rules)
+		r.add(KindConfig, "routing_rules", err, "")
+	}
+	if cfg.Routing != nil && (cfg.Routing.Downgrades != "" || cfg.Routing.DowngradeSpendRates != "") {
+		_, err := routing.ParseDowngrades(cfg.Routing.Downgrades, cfg.Routing.DowngradeSpendRates)
+		r.add(KindConfig, "routing_downgrades", err, "")
+	}
+	if cfg.Routing != nil && cfg.Routing.Enabled {
+		pools, err := routing.LoadPools(cfg)
+		if err == nil {
+			_, err = routing.NewSelector(pools)
+		}
+		r.add(KindConfig, "routing", err, cfg.Routing.ConfigPath)
+	}
+	if cfg.Routing != nil && cfg.Routing.SemanticEnabled {
+		_, err := routing.LoadSemanticConfig(cfg.Routing.SemanticConfigPath)
+		r.add(KindConfig, "semantic_routing", err, cfg.Routing.SemanticConfigPath)
+	}
+	if cfg.MCP != nil && cfg.MCP.Enable {
+		var err error
+		if cfg.MCP.ServersConfigPath != "" {
+			_, err = mcp.LoadServersConfig(cfg.MCP.ServersConfigPath)
+		}
+		if err == nil {
+			err = mcp.ValidateToolPolicy(cfg.MCP)
+		}
+		r.add(KindConfig, "mcp", err, cfg.MCP.ServersConfigPath)
+	}
+	if cfg.Server != nil && (cfg.Server.TlsCertPath != "" || cfg.Server.TlsKeyPath != "") {
+		_, err := tls.LoadX509KeyPair(cfg.Server.TlsCertPath, cfg.Server.TlsKeyPath)
+		r.add(KindConfig, "tls", err, cfg.Server.TlsCertPath)
+	}
+}
+
+// configured reports whether provider p is set up: given an API key or, for
+// the providers without authentication, a URL other than their default
+func configured(p *registry.ProviderConfig) bool {
+	if p.AuthType != constants.AuthTypeNone {
+		return p.Token != ""
+	}
+	def, ok := registry.Registry[p.ID]
+	return !ok || p.URL != def.URL
+}
+
+// checkProviders lists the models of every configured provider with its API
+// key, telling authentication failures from unreachable providers
+func checkProviders(ctx context.Context, r *Report, cfg config.Config, c client.Client) {
+	reg := registry.NewProviderRegistry(cfg.Providers, logger.NewNoopLogger())
+	ids := make([]types.Provider, 0, len(cfg.Providers))
+	for id, p := range cfg.Providers {
+		if configured(p) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		r.warn(KindProvider, "providers", "no provider is configured")
+		return
+	}
+	slices.Sort(ids)
+
+	for _, id := range ids {
+		models, err := admin.VerifyToken(ctx, reg, c, id)
+		var httpErr *core.HTTPError
+		switch {
+		case err == nil:
+			r.add(KindProvider, string(id), nil, fmt.Sprintf("%d models", models))
+		case errors.As(err, &httpErr) && (httpErr.StatusCode == http.StatusUnauthorized || httpErr.StatusCode == http.StatusForbidden):
+			r.add(KindProvider, string(id), fmt.Errorf("authentication failed: status %d", httpErr.StatusCode), "")
+		case errors.As(err, &httpErr):
+			r.add(KindProvider, string(id), fmt.Errorf("unexpected status %d", httpErr.StatusCode), "")
+		default:
+			r.add(KindProvider, string(id), fmt.Errorf("unreachable: %w", err), "")
+		}
+	}
+}
+
+// checkMCP connects to the MCP servers, reporting the status of each
+func checkMCP(ctx context.Context, r *Report, cfg config.Config, log logger.Logger) {
+	if cfg.MCP == nil || !cfg.MCP.Enable {
+		return
+	}
+	mcpCfg := *cfg.MCP
+	mcpCfg.EnableReconnect = false
+	cfg.MCP = &mcpCfg
+
+	mcpClient := mcp.NewMCPClient(strings.Split(cfg.MCP.Servers, ","), log, cfg)
+	if len(mcpClient.GetServers()) == 0 {
+		r.warn(KindMCP, "mcp", "mcp is enabled but no servers are configured")
+		return
+	}
+	initCtx, cancel := context.WithTimeout(ctx, cfg.MCP.RequestTimeout)
+	defer cancel()
+	_ = mcpClient.InitializeAll(initCtx)
+
+	statuses := mcpClient.GetAllServerStatuses()
+	for _, server := range mcpClient.GetServers() {
+		if statuses[server] == mcp.ServerStatusAvailable {
+			tools, _ := mcpClient.GetServerTools(server)
+			r.add(KindMCP, server, nil, fmt.Sprintf("%d tools", len(tools)))
+			continue
+		}
+		r.add(KindMCP, server, errors.New("unreachable or handshake failed"), "")
+	}
+}
@@ -0,0 +1,105 @@
+package validate
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	config "github.com/inference-gateway/inference-gateway/config"
+	client "github.com/inference-gateway/inference-gateway/providers/client"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// providerConfig returns the config of an OpenAI-compatible provider at url
+func providerConfig(id types.Provider, url, token string) *registry.ProviderConfig {
+	return &registry.ProviderConfig{
+		ID:        id,
+		URL:       url,
+		Token:     token,
+		AuthType:  constants.AuthTypeBearer,
+		Endpoints: types.Endpoints{Models: "/models"},
+	}
+}
+
+func TestRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sk-valid" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"gpt-4o","object":"model"}]}`))
+	}))
+	defer server.Close()
+	httpClient, err := client.NewHTTPClient(&client.ClientConfig{}, "http", "localhost", "8080")
+	require.NoError(t, err)
+
+	cfg := config.Config{Providers: map[types.Provider]*registry.ProviderConfig{
+		constants.OpenaiID:   providerConfig(constants.OpenaiID, server.URL, "sk-valid"),
+		constants.GroqID:     providerConfig(constants.GroqID, server.URL, "sk-revoked"),
+		constants.DeepseekID: providerConfig(constants.DeepseekID, server.URL, ""),
+	}}
+	report := Run(context.Background(), cfg, Options{Client: httpClient})
+
+	var providers []Check
+	for _, c := range report.Checks {
+		if c.Kind == KindProvider {
+			providers = append(providers, c)
+		}
+	}
+	assert.Equal(t, []Check{
+		{Name: "groq", Kind: KindProvider, Status: StatusFail, Message: "authentication failed: status 401"},
+		{Name: "openai", Kind: KindProvider, Status: StatusPass, Message: "1 models"},
+	}, providers, "providers without an API key are not checked")
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, ExitUnreachable, report.ExitCode())
+
+	offline := Run(context.Background(), cfg, Options{Offline: true})
+	assert.Equal(t, StatusPass, offline.Status)
+	assert.Equal(t, ExitOK, offline.ExitCode())
+}
+
+func TestRun_InvalidConfig(t *testing.T) {
+	cfg := config.Config{
+		ThinkTagMode:    "hide",
+		HooksConfigPath: "/nonexistent/hooks.yaml",
+		Providers: map[types.Provider]*registry.ProviderConfig{
+			constants.OpenaiID: providerConfig(constants.OpenaiID, "http://127.0.0.1:0", "sk-valid"),
+		},
+	}
+	report := Run(context.Background(), cfg, Options{})
+
+	failed := make(map[string]bool)
+	for _, c := range report.Checks {
+		assert.Equal(t, KindConfig, c.Kind, "online checks are skipped for an invalid config")
+		failed[c.Name] = c.Status == StatusFail
+	}
+	assert.True(t, failed["think_tag_mode"])
+	assert.True(t, failed["hooks"])
+	assert.False(t, failed["log_redaction"])
+	assert.Equal(t, ExitInvalidConfig, report.ExitCode())
+
+	var out bytes.Buffer
+	require.NoError(t, report.WriteText(&out))
+	assert.Contains(t, out.String(), "FAIL  config    hooks: read hooks config")
+	assert.Contains(t, out.String(), "fail: ")
+}
+
+func TestRun_NoProvider(t *testing.T) {
+	report := Run(context.Background(), config.Config{}, Options{})
+	assert.Equal(t, StatusPass, report.Status)
+	assert.Contains(t, report.Checks, Check{Name: "providers", Kind: KindProvider, Status: StatusWarn, Message: "no provider is configured"})
+	assert.Equal(t, ExitOK, report.ExitCode())
+}
+
+func TestLoadFailed(t *testing.T) {
+	report := LoadFailed(assert.AnError)
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, ExitInvalidConfig, report.ExitCode())
+}
@@ -1,7 +1,9 @@
 package logger
 
 import (
+	"context"
 	"os"
+	"slices"
 	"strings"
 
 	"go.uber.org/zap"
@@ -17,8 +19,9 @@ type Logger interface {
 }
 
 type LoggerZapImpl struct {
-	env    string
-	logger *zap.Logger
+	env       string
+	logger    *zap.Logger
+	redaction Redaction
 }
 
 // NoopLogger is a logger implementation that discards all logs
@@ -35,6 +38,57 @@ func NewNoopLogger() Logger {
 	return &NoopLogger{}
 }
 
+// With returns a logger adding fields, key-value pairs, to every line logged
+// through l
+func With(l Logger, fields ...any) Logger {
+	switch l := l.(type) {
+	case *NoopLogger:
+		return l
+	case *LoggerZapImpl:
+		return &LoggerZapImpl{env: l.env, logger: l.logger.With(l.fields(fields...)...), redaction: l.redaction}
+	}
+	return &fieldsLogger{logger: l, fields: slices.Clip(fields)}
+}
+
+// fieldsLogger adds its fields in front of the fields of every line
+type fieldsLogger struct {
+	logger Logger
+	fields []any
+}
+
+func (l *fieldsLogger) Info(message string, fields ...any) {
+	l.logger.Info(message, append(l.fields, fields...)...)
+}
+
+func (l *fieldsLogger) Debug(message string, fields ...any) {
+	l.logger.Debug(message, append(l.fields, fields...)...)
+}
+
+func (l *fieldsLogger) Warn(message string, fields ...any) {
+	l.logger.Warn(message, append(l.fields, fields...)...)
+}
+
+func (l *fieldsLogger) Error(message string, err error, fields ...any) {
+	l.logger.Error(message, err, append(l.fields, fields...)...)
+}
+
+type contextKey struct{}
+
+// WithContext returns a copy of ctx carrying l, the logger of the request
+// ctx belongs to
+func WithContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger of the request ctx belongs to, which tags
+// every line with the request ID, or fallback outside of a request
+func FromContext(ctx context.Context, fallback Logger) Logger {
+	if l, ok := ctx.Value(contextKey{}).(Logger); ok {
+		return l
+	}
+	return fallback
+}
+
 // isTestMode checks if the code is running as part of tests
 func isTestMode() bool {
 	for _, arg := range os.Args {
@@ -45,8 +99,14 @@ func isTestMode() bool {
 	return false
 }
 
-// NewLogger initializes a logger
+// NewLogger initializes a logger with the DefaultOptions
 func NewLogger(env string) (Logger, error) {
+	return NewLoggerWithOptions(env, DefaultOptions)
+}
+
+// NewLoggerWithOptions initializes a logger redacting and sampling its lines
+// as set by opts
+func NewLoggerWithOptions(env string, opts Options) (Logger, error) {
 	if isTestMode() {
 		return NewNoopLogger(), nil
 	}
@@ -60,40 +120,48 @@ func NewLogger(env string) (Logger, error) {
 		cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 		cfg.DisableStacktrace = true
 	}
-	zapLogger, err := cfg.Build(zap.AddCallerSkip(1))
+	buildOpts := []zap.Option{zap.AddCallerSkip(1)}
+	if opts.SamplingInitial > 0 {
+		buildOpts = append(buildOpts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return sampleDebug(core, opts.SamplingInitial, opts.SamplingThereafter)
+		}))
+	}
+	zapLogger, err := cfg.Build(buildOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return &LoggerZapImpl{
-		env:    env,
-		logger: zapLogger,
+		env:       env,
+		logger:    zapLogger,
+		redaction: opts.Redaction,
 	}, nil
 }
 
 func (l *LoggerZapImpl) Info(message string, fields ...any) {
-	l.logger.Info(message, parseFields(fields...)...)
+	l.logger.Info(message, l.fields(fields...)...)
 }
 
 func (l *LoggerZapImpl) Debug(message string, fields ...any) {
 	if l.env == "development" {
-		l.logger.Debug(message, parseFields(fields...)...)
+		l.logger.Debug(message, l.fields(fields...)...)
 	}
 }
 
 func (l *LoggerZapImpl) Warn(message string, fields ...any) {
-	l.logger.Warn(message, parseFields(fields...)...)
+	l.logger.Warn(message, l.fields(fields...)...)
 }
 
 func (l *LoggerZapImpl) Error(message string, err error, fields ...any) {
 	if err == nil {
-		l.logger.Error(message, parseFields(fields...)...)
+		l.logger.Error(message, l.fields(fields...)...)
 		return
 	}
 	fields = append(fields, "error", err.Error())
-	l.logger.Error(message, parseFields(fields...)...)
+	l.logger.Error(message, l.fields(fields...)...)
 }
 
-func parseFields(kv ...any) []zap.Field {
+// fields converts key-value pairs to zap fields, redacting their values
+func (l *LoggerZapImpl) fields(kv ...any) []zap.Field {
 	var fields []zap.Field
 	for i := 0; i < len(kv); i += 2 {
 		if i+1 < len(kv) {
@@ -102,7 +170,7 @@ func parseFields(kv ...any) []zap.Field {
 				continue
 			}
 			val := kv[i+1]
-			fields = append(fields, zap.Any(key, val))
+			fields = append(fields, zap.Any(key, l.redaction.redact(key, val)))
 		}
 	}
 	return fields
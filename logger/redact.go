@@ -0,0 +1,230 @@
+package logger
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// redacted replaces the redacted values
+const redacted = "[REDACTED]"
+
+// Redaction selects what is redacted from the logged fields
+type Redaction struct {
+	// Headers redacts the values of the credential fields and headers, e.g.
+	// authorization, api_key or cookie
+	Headers bool
+	// Keys redacts the bearer tokens and API keys found in logged strings
+	// and errors
+	Keys bool
+	// Content redacts message content, prompts, bodies and stream chunks
+	Content bool
+}
+
+// Options configures the redaction and sampling of a logger
+type Options struct {
+	Redaction Redaction
+	// SamplingInitial debug lines with the same message are logged per
+	// second, then every SamplingThereafter-th one. 0 disables sampling.
+	SamplingInitial    int
+	SamplingThereafter int
+}
+
+// DefaultOptions are the options of NewLogger, matching the defaults of the
+// LOG_* settings
+var DefaultOptions = Options{
+	Redaction:          Redaction{Headers: true, Keys: true},
+	SamplingInitial:    100,
+	SamplingThereafter: 100,
+}
+
+// ParseRedaction parses LOG_REDACTION, a comma-separated list of headers,
+// keys and content, or none
+func ParseRedaction(s string) (Redaction, error) {
+	var r Redaction
+	for part := range strings.SplitSeq(s, ",") {
+		switch strings.ToLower(strings.TrimSpace(part)) {
+		case "", "none":
+		case "headers":
+			r.Headers = true
+		case "keys":
+			r.Keys = true
+		case "content":
+			r.Content = true
+		default:
+			return Redaction{}, fmt.Errorf("invalid LOG_REDACTION value %q: expected headers, keys, content or none", part)
+		}
+	}
+	return r, nil
+}
+
+// credentialKeys are the field and header names, normalized by normalizeKey,
+// whose values are credentials
+var credentialKeys = map[string]bool{
+	"authorization":       true,
+	"proxy_authorization": true,
+	"cookie":              true,
+	"set_cookie":          true,
+	"api_key":             true,
+	"apikey":              true,
+	"x_api_key":           true,
+	"token":               true,
+	"secret":              true,
+	"password":            true,
+}
+
+// contentKeys are the field names whose values are message content or bodies
+var contentKeys = map[string]bool{
+	"content":       true,
+	"messages":      true,
+	"prompt":        true,
+	"line":          true,
+	"data_preview":  true,
+	"chunk_data":    true,
+	"body":          true,
+	"request_body":  true,
+	"response_body": true,
+	"arguments":     true,
+}
+
+func normalizeKey(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "-", "_")
+}
+
+func isCredentialKey(key string) bool {
+	key = normalizeKey(key)
+	if credentialKeys[key] {
+		return true
+	}
+	for _, suffix := range []string{"_token", "_secret", "_password", "_api_key", "_apikey"} {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func isContentKey(key string) bool {
+	key = normalizeKey(key)
+	return contentKeys[key] || strings.HasSuffix(key, "_content")
+}
+
+// keyPatterns match credentials in free text, keeping the first group
+var keyPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(\b(?:bearer|basic)\s+)[A-Za-z0-9._~+/=-]{8,}`),
+	regexp.MustCompile(`(?i)([?&](?:key|api_key|apikey|token|access_token)=)[^&\s"']+`),
+	regexp.MustCompile(`(?i)("(?:authorization|api_key|apikey|x-api-key|token|access_token|client_secret|password)"\s*:\s*")[^"]*`),
+	regexp.MustCompile(`()\b(?:sk-|sk_|gsk_|xai-|hf_|nvapi-|AIza)[A-Za-z0-9_-]{16,}`),
+}
+
+// RedactKeys replaces the bearer tokens and API keys found in s
+func RedactKeys(s string) string {
+	for _, pattern := range keyPatterns {
+		s = pattern.ReplaceAllString(s, "${1}"+redacted)
+	}
+	return s
+}
+
+// piiPatterns match personal data in free text: email addresses, payment
+// card numbers, phone numbers and IPv4 addresses
+var piiPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+	regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{3}\)|\b\d{3})[ .-]\d{3}[ .-]\d{4}\b`),
+	regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`),
+}
+
+// RedactPII replaces the email addresses, payment card numbers, phone
+// numbers and IPv4 addresses found in s
+func RedactPII(s string) string {
+	for _, pattern := range piiPatterns {
+		s = pattern.ReplaceAllString(s, redacted)
+	}
+	return s
+}
+
+// redact returns the value logged for the field key
+func (r Redaction) redact(key string, value any) any {
+	if r.Headers && isCredentialKey(key) {
+		return redacted
+	}
+	if r.Content && isContentKey(key) {
+		return redacted
+	}
+	switch v := value.(type) {
+	case http.Header:
+		return r.redactHeader(v)
+	case map[string][]string:
+		return r.redactHeader(v)
+	case string:
+		if r.Keys {
+			return RedactKeys(v)
+		}
+	case error:
+		if r.Keys {
+			if msg := RedactKeys(v.Error()); msg != v.Error() {
+				return msg
+			}
+		}
+	}
+	return value
+}
+
+func (r Redaction) redactHeader(header map[string][]string) any {
+	if !r.Headers && !r.Keys {
+		return header
+	}
+	redactedHeader := make(map[string][]string, len(header))
+	for name, values := range header {
+		if r.Headers && isCredentialKey(name) {
+			redactedHeader[name] = []string{redacted}
+			continue
+		}
+		if r.Keys {
+			values = append([]string(nil), values...)
+			for i := range values {
+				values[i] = RedactKeys(values[i])
+			}
+		}
+		redactedHeader[name] = values
+	}
+	return redactedHeader
+}
+
+// sampleDebug samples the debug entries of core, at most initial per second
+// with the same message then every thereafter-th one, leaving the other
+// levels untouched
+func sampleDebug(core zapcore.Core, initial, thereafter int) zapcore.Core {
+	isDebug := zap.LevelEnablerFunc(func(l zapcore.Level) bool { return l == zapcore.DebugLevel })
+	isNotDebug := zap.LevelEnablerFunc(func(l zapcore.Level) bool { return l != zapcore.DebugLevel })
+	return zapcore.NewTee(
+		levelCore{Core: zapcore.NewSamplerWithOptions(core, time.Second, initial, thereafter), enabled: isDebug},
+		levelCore{Core: core, enabled: isNotDebug},
+	)
+}
+
+// levelCore passes on the entries of the levels enabled
+type levelCore struct {
+	zapcore.Core
+	enabled zapcore.LevelEnabler
+}
+
+func (c levelCore) Enabled(level zapcore.Level) bool {
+	return c.enabled.Enabled(level) && c.Core.Enabled(level)
+}
+
+func (c levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return levelCore{Core: c.Core.With(fields), enabled: c.enabled}
+}
+
+func (c levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.enabled.Enabled(entry.Level) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
@@ -4,7 +4,10 @@ package otel
 import (
 	"cmp"
 	"context"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"strings"
 
 	config "github.com/inference-gateway/inference-gateway/config"
 	logger "github.com/inference-gateway/inference-gateway/logger"
@@ -39,6 +42,12 @@ const sourceKey = attribute.Key("source")
 // department) so usage and failures can be broken down per team.
 const teamKey = attribute.Key("team")
 
+// evaluatorKey names the evaluator that scored a sampled response.
+const evaluatorKey = attribute.Key("evaluator")
+
+// kindKey names the kind of in-flight work of the inflight gauge.
+const kindKey = attribute.Key("kind")
+
 // IngestResult summarizes an OTLP push ingestion.
 type IngestResult struct {
 	AcceptedDataPoints int64
@@ -53,10 +62,17 @@ type OpenTelemetry interface {
 	RecordTokenUsage(ctx context.Context, source, team, provider, model string, inputTokens, outputTokens int64)
 	RecordRequestDuration(ctx context.Context, source, team, provider, model, errorType string, seconds float64)
 	RecordToolCall(ctx context.Context, source, team, provider, model, toolType, toolName string)
+	RecordEvaluation(ctx context.Context, provider, model, evaluator string, score float64)
+	// ObserveInflight reports the amount of in-flight work returned by
+	// observe, by kind, as the inference_gateway.inflight gauge.
+	ObserveInflight(observe func() map[string]int64) error
 
 	// IngestMetrics maps an OTLP push payload onto the gateway's instruments.
 	IngestMetrics(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest) IngestResult
 
+	// Flush exports the metrics and spans recorded so far, so the last
+	// requests served before shutdown are not lost.
+	Flush(ctx context.Context) error
 	ShutDown(ctx context.Context) error
 }
 
@@ -74,12 +90,14 @@ type OpenTelemetryImpl struct {
 	serverTimeToFirstToken  metric.Float64Histogram // gen_ai.server.time_to_first_token (push only)
 	executeToolDuration     metric.Float64Histogram // gen_ai.execute_tool.duration (push only)
 	toolCallCounter         metric.Int64Counter     // inference_gateway.tool_calls
+	evaluationScore         metric.Float64Histogram // inference_gateway.evaluation.score
 }
 
 // Semconv-recommended bucket boundaries: durations in seconds, token counts in powers of 4.
 var (
 	durationBoundaries = []float64{0.01, 0.02, 0.04, 0.08, 0.16, 0.32, 0.64, 1.28, 2.56, 5.12, 10.24, 20.48, 40.96, 81.92}
 	tokenBoundaries    = []float64{1, 4, 16, 64, 256, 1024, 4096, 16384, 65536, 262144, 1048576, 4194304, 16777216, 67108864}
+	scoreBoundaries    = []float64{0, 0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1}
 )
 
 func (o *OpenTelemetryImpl) Init(cfg config.Config, log logger.Logger) error {
@@ -116,8 +134,14 @@ func (o *OpenTelemetryImpl) Init(cfg config.Config, log logger.Logger) error {
 	}
 
 	if cfg.Telemetry.TracingEnable {
+		endpoint, headers, err := traceExport(cfg.Telemetry)
+		if err != nil {
+			o.logger.Error("invalid trace exporter configuration", err)
+			return err
+		}
 		traceExporter, err := otlptracehttp.New(context.Background(),
-			otlptracehttp.WithEndpointURL(cfg.Telemetry.TracingOtlpEndpoint))
+			otlptracehttp.WithEndpointURL(endpoint),
+			otlptracehttp.WithHeaders(headers))
 		if err != nil {
 			o.logger.Error("failed to create otlp trace exporter", err)
 			return err
@@ -131,7 +155,8 @@ func (o *OpenTelemetryImpl) Init(cfg config.Config, log logger.Logger) error {
 		otel.SetTextMapPropagator(propagation.TraceContext{})
 
 		o.logger.Info("opentelemetry tracing enabled",
-			"otlp_endpoint", cfg.Telemetry.TracingOtlpEndpoint)
+			"exporter", cfg.Telemetry.TracingExporter,
+			"otlp_endpoint", endpoint)
 	}
 
 	o.logger.Info("opentelemetry initialization completed successfully",
@@ -140,6 +165,46 @@ func (o *OpenTelemetryImpl) Init(cfg config.Config, log logger.Logger) error {
 	return nil
 }
 
+// traceExport returns the OTLP endpoint and headers of the trace exporter
+// selected by TELEMETRY_TRACING_EXPORTER. Langfuse ingests OTLP traces on
+// its public API, authenticated with the project keys.
+func traceExport(cfg *config.TelemetryConfig) (string, map[string]string, error) {
+	switch cfg.TracingExporter {
+	case "", "otlp":
+		headers, err := parseHeaders(cfg.TracingOtlpHeaders)
+		if err != nil {
+			return "", nil, err
+		}
+		return cfg.TracingOtlpEndpoint, headers, nil
+	case "langfuse":
+		if cfg.LangfusePublicKey == "" || cfg.LangfuseSecretKey == "" {
+			return "", nil, errors.New("TELEMETRY_LANGFUSE_PUBLIC_KEY and TELEMETRY_LANGFUSE_SECRET_KEY are required by the langfuse trace exporter")
+		}
+		credentials := base64.StdEncoding.EncodeToString([]byte(cfg.LangfusePublicKey + ":" + cfg.LangfuseSecretKey))
+		endpoint := strings.TrimSuffix(cfg.LangfuseHost, "/") + "/api/public/otel/v1/traces"
+		return endpoint, map[string]string{"Authorization": "Basic " + credentials}, nil
+	default:
+		return "", nil, fmt.Errorf("invalid TELEMETRY_TRACING_EXPORTER %q: expected otlp or langfuse", cfg.TracingExporter)
+	}
+}
+
+// parseHeaders parses TELEMETRY_TRACING_OTLP_HEADERS, comma-separated
+// key=value pairs
+func parseHeaders(s string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for pair := range strings.SplitSeq(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if key = strings.TrimSpace(key); !ok || key == "" {
+			return nil, fmt.Errorf("invalid TELEMETRY_TRACING_OTLP_HEADERS entry %q: expected key=value", pair)
+		}
+		headers[key] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
 func metricViews() []sdkmetric.View {
 	durationView := sdkmetric.NewView(
 		sdkmetric.Instrument{Kind: sdkmetric.InstrumentKindHistogram, Unit: "s"},
@@ -153,7 +218,13 @@ func metricViews() []sdkmetric.View {
 			Boundaries: tokenBoundaries,
 		}},
 	)
-	return []sdkmetric.View{durationView, tokenView}
+	scoreView := sdkmetric.NewView(
+		sdkmetric.Instrument{Name: "inference_gateway.evaluation.score"},
+		sdkmetric.Stream{Aggregation: sdkmetric.AggregationExplicitBucketHistogram{
+			Boundaries: scoreBoundaries,
+		}},
+	)
+	return []sdkmetric.View{durationView, tokenView, scoreView}
 }
 
 // initInstruments creates the instruments on the given provider. Split from
@@ -161,7 +232,7 @@ func metricViews() []sdkmetric.View {
 func (o *OpenTelemetryImpl) initInstruments(provider *sdkmetric.MeterProvider) error {
 	o.meter = provider.Meter(config.APPLICATION_NAME)
 
-	var errs [7]error
+	var errs [8]error
 
 	o.tokenUsageHistogram, errs[0] = o.meter.Int64Histogram("gen_ai.client.token.usage",
 		metric.WithDescription("Number of input and output tokens used per operation"),
@@ -191,6 +262,10 @@ func (o *OpenTelemetryImpl) initInstruments(provider *sdkmetric.MeterProvider) e
 		metric.WithDescription("Number of tool calls observed in model responses"),
 		metric.WithUnit("{call}"))
 
+	o.evaluationScore, errs[7] = o.meter.Float64Histogram("inference_gateway.evaluation.score",
+		metric.WithDescription("Evaluation scores of sampled model responses, between 0 and 1, per evaluator"),
+		metric.WithUnit("1"))
+
 	for _, err := range errs {
 		if err != nil {
 			if o.logger != nil {
@@ -246,6 +321,42 @@ func (o *OpenTelemetryImpl) RecordToolCall(ctx context.Context, source, team, pr
 	o.toolCallCounter.Add(ctx, 1, metric.WithAttributes(attributes...))
 }
 
+// RecordEvaluation records the score of a sampled response. The mean score of
+// an evaluator is a rate for the pass/fail ones, e.g. the refusal rate.
+func (o *OpenTelemetryImpl) RecordEvaluation(ctx context.Context, provider, model, evaluator string, score float64) {
+	attributes := []attribute.KeyValue{
+		semconv.GenAIProviderNameKey.String(provider),
+		semconv.GenAIRequestModel(model),
+		evaluatorKey.String(evaluator),
+	}
+
+	o.evaluationScore.Record(ctx, score, metric.WithAttributes(attributes...))
+}
+
+func (o *OpenTelemetryImpl) ObserveInflight(observe func() map[string]int64) error {
+	gauge, err := o.meter.Int64ObservableGauge("inference_gateway.inflight",
+		metric.WithDescription("Work running on behalf of requests, such as MCP agent loops and provider stream readers, per kind"),
+		metric.WithUnit("{goroutine}"))
+	if err != nil {
+		return err
+	}
+	_, err = o.meter.RegisterCallback(func(_ context.Context, observer metric.Observer) error {
+		for kind, n := range observe() {
+			observer.ObserveInt64(gauge, n, metric.WithAttributes(kindKey.String(kind)))
+		}
+		return nil
+	}, gauge)
+	return err
+}
+
+func (o *OpenTelemetryImpl) Flush(ctx context.Context) error {
+	err := o.meterProvider.ForceFlush(ctx)
+	if o.tracerProvider != nil {
+		err = errors.Join(err, o.tracerProvider.ForceFlush(ctx))
+	}
+	return err
+}
+
 func (o *OpenTelemetryImpl) ShutDown(ctx context.Context) error {
 	err := o.meterProvider.Shutdown(ctx)
 	if o.tracerProvider != nil {
@@ -0,0 +1,76 @@
+// Package availability remembers the outcome of the recent calls to each
+// provider, so providers that keep failing can be told apart from the ones
+// that merely failed once.
+package availability
+
+import (
+	"sync"
+	"time"
+)
+
+// maxOutcomes bounds the outcomes kept per provider
+const maxOutcomes = 100
+
+type outcome struct {
+	at time.Time
+	ok bool
+}
+
+// Tracker records the outcome of the calls to each provider. It is safe for
+// concurrent use.
+type Tracker struct {
+	mu       sync.Mutex
+	outcomes map[string][]outcome
+	now      func() time.Time
+}
+
+// NewTracker creates an empty tracker
+func NewTracker() *Tracker {
+	return &Tracker{outcomes: make(map[string][]outcome), now: time.Now}
+}
+
+// Record records the outcome of a call to provider
+func (t *Tracker) Record(provider string, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	outcomes := append(t.outcomes[provider], outcome{at: t.now(), ok: ok})
+	if len(outcomes) > maxOutcomes {
+		outcomes = outcomes[len(outcomes)-maxOutcomes:]
+	}
+	t.outcomes[provider] = outcomes
+}
+
+// Counts returns how many of the calls to provider within the last window
+// failed, and how many were made. Older outcomes are forgotten.
+func (t *Tracker) Counts(provider string, window time.Duration) (failures, calls int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	since := t.now().Add(-window)
+	outcomes := t.outcomes[provider]
+	for len(outcomes) > 0 && outcomes[0].at.Before(since) {
+		outcomes = outcomes[1:]
+	}
+	if len(outcomes) == 0 {
+		delete(t.outcomes, provider)
+		return 0, 0
+	}
+	t.outcomes[provider] = outcomes
+	for _, o := range outcomes {
+		if !o.ok {
+			failures++
+		}
+	}
+	return failures, len(outcomes)
+}
+
+// Healthy reports whether provider is healthy: it is not when at least
+// minFailures of its calls within the last window failed, and they make up
+// half of those calls or more. A minFailures of 0 or less keeps every
+// provider healthy.
+func (t *Tracker) Healthy(provider string, window time.Duration, minFailures int) bool {
+	if minFailures <= 0 {
+		return true
+	}
+	failures, calls := t.Counts(provider, window)
+	return failures < minFailures || failures*2 < calls
+}
@@ -0,0 +1,45 @@
+package availability
+
+import (
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/assert"
+)
+
+func TestTracker(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewTracker()
+	tracker.now = func() time.Time { return now }
+
+	assert.True(t, tracker.Healthy("openai", time.Minute, 2), "no calls yet")
+
+	tracker.Record("openai", false)
+	assert.True(t, tracker.Healthy("openai", time.Minute, 2), "a single failure")
+
+	tracker.Record("openai", false)
+	assert.False(t, tracker.Healthy("openai", time.Minute, 2))
+	assert.True(t, tracker.Healthy("openai", time.Minute, 0), "disabled")
+	assert.True(t, tracker.Healthy("groq", time.Minute, 2), "other providers")
+
+	tracker.Record("openai", true)
+	tracker.Record("openai", true)
+	assert.False(t, tracker.Healthy("openai", time.Minute, 2), "half of the calls failed")
+	tracker.Record("openai", true)
+	assert.True(t, tracker.Healthy("openai", time.Minute, 2), "most calls succeed")
+
+	now = now.Add(2 * time.Minute)
+	failures, calls := tracker.Counts("openai", time.Minute)
+	assert.Equal(t, 0, failures)
+	assert.Equal(t, 0, calls, "outcomes out of the window are forgotten")
+}
+
+func TestTrackerBounded(t *testing.T) {
+	tracker := NewTracker()
+	for range 2 * maxOutcomes {
+		tracker.Record("openai", false)
+	}
+	failures, calls := tracker.Counts("openai", time.Hour)
+	assert.Equal(t, maxOutcomes, failures)
+	assert.Equal(t, maxOutcomes, calls)
+}
@@ -0,0 +1,146 @@
+// Package backoff reads the Retry-After and rate limit headers providers
+// answer throttled requests with, and remembers until when each provider
+// model asked to be left alone.
+package backoff
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// resetHeaders pairs the reset and remaining headers of the rate limits
+// providers report, OpenAI-style (OpenAI, Groq, ...) and Anthropic-style
+var resetHeaders = []struct{ reset, remaining string }{
+	{"X-Ratelimit-Reset-Requests", "X-Ratelimit-Remaining-Requests"},
+	{"X-Ratelimit-Reset-Tokens", "X-Ratelimit-Remaining-Tokens"},
+	{"Anthropic-Ratelimit-Requests-Reset", "Anthropic-Ratelimit-Requests-Remaining"},
+	{"Anthropic-Ratelimit-Tokens-Reset", "Anthropic-Ratelimit-Tokens-Remaining"},
+	{"Anthropic-Ratelimit-Input-Tokens-Reset", "Anthropic-Ratelimit-Input-Tokens-Remaining"},
+	{"Anthropic-Ratelimit-Output-Tokens-Reset", "Anthropic-Ratelimit-Output-Tokens-Remaining"},
+}
+
+// RetryAfter returns how long the provider asked to wait before the next
+// request, from its Retry-After or retry-after-ms header, or else from the
+// reset header of an exhausted rate limit. ok is false when the headers say
+// nothing about it.
+func RetryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	if ms, err := strconv.ParseFloat(h.Get("Retry-After-Ms"), 64); err == nil && ms >= 0 {
+		return time.Duration(ms * float64(time.Millisecond)), true
+	}
+	if value := h.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+			return time.Duration(seconds * float64(time.Second)), true
+		}
+		if at, err := http.ParseTime(value); err == nil {
+			return max(at.Sub(now), 0), true
+		}
+	}
+
+	var wait time.Duration
+	found := false
+	for _, limit := range resetHeaders {
+		if h.Get(limit.remaining) != "0" {
+			continue
+		}
+		if d, ok := parseReset(h.Get(limit.reset), now); ok {
+			wait = max(wait, d)
+			found = true
+		}
+	}
+	return wait, found
+}
+
+// parseReset reads a reset header: a duration such as 6m0s (OpenAI), an
+// RFC 3339 time (Anthropic) or a number of seconds
+func parseReset(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return max(d, 0), true
+	}
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return max(time.Duration(seconds*float64(time.Second)), 0), true
+	}
+	return 0, false
+}
+
+// Propagated returns the headers of a provider error response that are
+// passed on to the client: Retry-After and the rate limit headers. When the
+// provider sent no Retry-After but its headers tell when to retry, one is
+// added, rounded up to whole seconds.
+func Propagated(h http.Header, now time.Time) http.Header {
+	out := make(http.Header)
+	for name, values := range h {
+		lower := strings.ToLower(name)
+		if lower == "retry-after" || lower == "retry-after-ms" ||
+			strings.HasPrefix(lower, "x-ratelimit-") || strings.HasPrefix(lower, "anthropic-ratelimit-") {
+			out[name] = values
+		}
+	}
+	if out.Get("Retry-After") == "" {
+		if d, ok := RetryAfter(h, now); ok {
+			out.Set("Retry-After", Seconds(d))
+		}
+	}
+	return out
+}
+
+// Seconds formats d as a Retry-After value, rounded up to whole seconds
+func Seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// Tracker remembers until when provider models asked not to be called. It
+// is safe for concurrent use.
+type Tracker struct {
+	mu    sync.Mutex
+	until map[key]time.Time
+	now   func() time.Time
+}
+
+type key struct{ provider, model string }
+
+// NewTracker creates an empty tracker
+func NewTracker() *Tracker {
+	return &Tracker{until: make(map[key]time.Time), now: time.Now}
+}
+
+// Observe records that model of provider should not be called for d. A
+// shorter wait never cuts short an earlier, longer one.
+func (t *Tracker) Observe(provider, model string, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	k := key{provider, model}
+	if until := t.now().Add(d); until.After(t.until[k]) {
+		t.until[k] = until
+	}
+}
+
+// Remaining returns how long model of provider is still backing off, zero
+// when it can be called
+func (t *Tracker) Remaining(provider, model string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	k := key{provider, model}
+	until, ok := t.until[k]
+	if !ok {
+		return 0
+	}
+	remaining := until.Sub(t.now())
+	if remaining <= 0 {
+		delete(t.until, k)
+		return 0
+	}
+	return remaining
+}
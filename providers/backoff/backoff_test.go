@@ -0,0 +1,80 @@
+package backoff
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/assert"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		header   http.Header
+		expected time.Duration
+		ok       bool
+	}{
+		{"seconds", http.Header{"Retry-After": {"20"}}, 20 * time.Second, true},
+		{"http date", http.Header{"Retry-After": {"Wed, 01 Jan 2025 12:00:30 GMT"}}, 30 * time.Second, true},
+		{"milliseconds win", http.Header{"Retry-After": {"2"}, "Retry-After-Ms": {"1500"}}, 1500 * time.Millisecond, true},
+		{
+			name: "exhausted OpenAI limit",
+			header: http.Header{
+				"X-Ratelimit-Remaining-Requests": {"0"}, "X-Ratelimit-Reset-Requests": {"6m0s"},
+				"X-Ratelimit-Remaining-Tokens": {"1200"}, "X-Ratelimit-Reset-Tokens": {"59m0s"},
+			},
+			expected: 6 * time.Minute,
+			ok:       true,
+		},
+		{
+			name:     "exhausted Anthropic limit",
+			header:   http.Header{"Anthropic-Ratelimit-Tokens-Remaining": {"0"}, "Anthropic-Ratelimit-Tokens-Reset": {"2025-01-01T12:00:45Z"}},
+			expected: 45 * time.Second,
+			ok:       true,
+		},
+		{"limits not exhausted", http.Header{"X-Ratelimit-Remaining-Requests": {"3"}, "X-Ratelimit-Reset-Requests": {"1s"}}, 0, false},
+		{"nothing", http.Header{}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, ok := RetryAfter(tt.header, now)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, d)
+		})
+	}
+}
+
+func TestPropagated(t *testing.T) {
+	now := time.Now()
+	h := http.Header{
+		"Content-Type":                   {"application/json"},
+		"Set-Cookie":                     {"session=secret"},
+		"X-Ratelimit-Remaining-Requests": {"0"},
+		"X-Ratelimit-Reset-Requests":     {"1.5s"},
+	}
+	out := Propagated(h, now)
+	assert.Equal(t, http.Header{
+		"X-Ratelimit-Remaining-Requests": {"0"},
+		"X-Ratelimit-Reset-Requests":     {"1.5s"},
+		"Retry-After":                    {"2"},
+	}, out)
+
+	out = Propagated(http.Header{"Retry-After": {"7"}}, now)
+	assert.Equal(t, "7", out.Get("Retry-After"), "the provider's own Retry-After is kept")
+}
+
+func TestTracker(t *testing.T) {
+	now := time.Now()
+	tracker := NewTracker()
+	tracker.now = func() time.Time { return now }
+
+	tracker.Observe("openai", "gpt-4o", 10*time.Second)
+	tracker.Observe("openai", "gpt-4o", 2*time.Second)
+	assert.Equal(t, 10*time.Second, tracker.Remaining("openai", "gpt-4o"), "a shorter wait does not cut the longer one short")
+	assert.Zero(t, tracker.Remaining("openai", "gpt-4o-mini"))
+
+	now = now.Add(11 * time.Second)
+	assert.Zero(t, tracker.Remaining("openai", "gpt-4o"))
+}
@@ -29,38 +29,44 @@ type ClientConfig struct {
 	ClientMaxIdleConnsPerHost   int           `env:"CLIENT_MAX_IDLE_CONNS_PER_HOST, default=20" description:"Maximum idle connections per host"`
 	ClientIdleConnTimeout       time.Duration `env:"CLIENT_IDLE_CONN_TIMEOUT, default=30s" description:"Idle connection timeout"`
 	ClientTlsMinVersion         string        `env:"CLIENT_TLS_MIN_VERSION, default=TLS12" description:"Minimum TLS version"`
+	ClientTlsCaPath             string        `env:"CLIENT_TLS_CA_PATH, default=" description:"Path to a PEM CA bundle trusted for upstream servers, in addition to the system roots"`
+	ClientTlsCertPath           string        `env:"CLIENT_TLS_CERT_PATH, default=" description:"Path to the PEM client certificate presented to upstream servers requiring mutual TLS"`
+	ClientTlsKeyPath            string        `env:"CLIENT_TLS_KEY_PATH, default=" description:"Path to the PEM private key of the client certificate"`
+	ClientTlsHostOverrides      string        `env:"CLIENT_TLS_HOST_OVERRIDES, default=" description:"Per-target TLS overrides, semicolon-separated host[:port]=ca_path,cert_path,key_path entries"`
 	ClientDisableCompression    bool          `env:"CLIENT_DISABLE_COMPRESSION, default=true" description:"Disable compression for faster streaming"`
 	ClientResponseHeaderTimeout time.Duration `env:"CLIENT_RESPONSE_HEADER_TIMEOUT, default=10s" description:"Response header timeout"`
 	ClientExpectContinueTimeout time.Duration `env:"CLIENT_EXPECT_CONTINUE_TIMEOUT, default=1s" description:"Expect continue timeout"`
 }
 
-func NewHTTPClient(cfg *ClientConfig, scheme, hostname, port string) Client {
+func NewHTTPClient(cfg *ClientConfig, scheme, hostname, port string) (Client, error) {
 	var tlsMinVersion uint16 = tls.VersionTLS12
 	if cfg.ClientTlsMinVersion == "TLS13" {
 		tlsMinVersion = tls.VersionTLS13
 	}
 
-	httpClient := &http.Client{
-		Transport: &http.Transport{
-			MaxIdleConns:        cfg.ClientMaxIdleConns,
-			MaxIdleConnsPerHost: cfg.ClientMaxIdleConnsPerHost,
-			IdleConnTimeout:     cfg.ClientIdleConnTimeout,
-			TLSClientConfig: &tls.Config{
-				MinVersion: tlsMinVersion,
-			},
-			ForceAttemptHTTP2:     true,
-			DisableCompression:    cfg.ClientDisableCompression,
-			ResponseHeaderTimeout: cfg.ClientResponseHeaderTimeout,
-			ExpectContinueTimeout: cfg.ClientExpectContinueTimeout,
+	transport, err := NewTLSTransport(&http.Transport{
+		MaxIdleConns:        cfg.ClientMaxIdleConns,
+		MaxIdleConnsPerHost: cfg.ClientMaxIdleConnsPerHost,
+		IdleConnTimeout:     cfg.ClientIdleConnTimeout,
+		TLSClientConfig: &tls.Config{
+			MinVersion: tlsMinVersion,
 		},
+		ForceAttemptHTTP2:     true,
+		DisableCompression:    cfg.ClientDisableCompression,
+		ResponseHeaderTimeout: cfg.ClientResponseHeaderTimeout,
+		ExpectContinueTimeout: cfg.ClientExpectContinueTimeout,
+		DialContext:           DialContext,
+	}, cfg)
+	if err != nil {
+		return nil, err
 	}
 
 	return &ClientImpl{
 		scheme:   scheme,
 		hostname: hostname,
 		port:     port,
-		client:   httpClient,
-	}
+		client:   &http.Client{Transport: transport},
+	}, nil
 }
 
 func (c *ClientImpl) Do(req *http.Request) (*http.Response, error) {
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// defaultConnectTimeout bounds establishing connections without a connect
+// timeout, as the default transport of net/http does
+const defaultConnectTimeout = 30 * time.Second
+
+type connectTimeoutKey struct{}
+
+// WithConnectTimeout returns ctx bounding how long establishing a connection
+// for its requests may take, for transports dialing with DialContext
+func WithConnectTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	if timeout <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, connectTimeoutKey{}, timeout)
+}
+
+// DialContext dials addr within the connect timeout of ctx, 30s when it has
+// none
+func DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	timeout, ok := ctx.Value(connectTimeoutKey{}).(time.Duration)
+	if !ok {
+		timeout = defaultConnectTimeout
+	}
+	dialer := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}
+	return dialer.DialContext(ctx, network, addr)
+}
+
+// RetryPolicy is how failed requests to a provider are retried: up to
+// MaxRetries more times after transport errors and 502, 503 and 504
+// answers, waiting Backoff before the first retry and twice as long before
+// every further one
+type RetryPolicy struct {
+	MaxRetries int
+	Backoff    time.Duration
+}
+
+// Do sends req with do, retrying per the policy. Requests with a body that
+// cannot be replayed (without GetBody) are sent once.
+func (p RetryPolicy) Do(req *http.Request, do func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	retries := p.MaxRetries
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		retries = 0
+	}
+
+	backoff := p.Backoff
+	attempt := req
+	for i := 0; ; i++ {
+		resp, err := do(attempt)
+		if i >= retries || !retryable(resp, err) || req.Context().Err() != nil {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+
+		attempt = req.Clone(req.Context())
+		if req.GetBody != nil {
+			if attempt.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// Transport returns a transport sending requests with base, retrying them
+// per the policy
+func (p RetryPolicy) Transport(base http.RoundTripper) http.RoundTripper {
+	if p.MaxRetries <= 0 {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return retryTransport{base: base, policy: p}
+}
+
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+}
+
+func (t retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.policy.Do(req, t.base.RoundTrip)
+}
+
+// retryable reports whether a request answered with resp or failed with err
+// may succeed when sent again
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicyDo(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		retries  int
+		body     io.Reader
+		status   int
+		attempts int32
+	}{
+		{name: "retries unavailable providers", statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK}, retries: 2, body: strings.NewReader(`{"model":"gpt-4o"}`), status: http.StatusOK, attempts: 3},
+		{name: "gives up after max retries", statuses: []int{http.StatusGatewayTimeout, http.StatusGatewayTimeout, http.StatusOK}, retries: 1, status: http.StatusGatewayTimeout, attempts: 2},
+		{name: "does not retry client errors", statuses: []int{http.StatusBadRequest, http.StatusOK}, retries: 2, status: http.StatusBadRequest, attempts: 1},
+		{name: "does not retry rate limits", statuses: []int{http.StatusTooManyRequests, http.StatusOK}, retries: 2, status: http.StatusTooManyRequests, attempts: 1},
+		{name: "does not retry without retries", statuses: []int{http.StatusServiceUnavailable, http.StatusOK}, status: http.StatusServiceUnavailable, attempts: 1},
+		{name: "does not retry bodies that cannot be replayed", statuses: []int{http.StatusServiceUnavailable, http.StatusOK}, retries: 2, body: io.MultiReader(strings.NewReader("{}")), status: http.StatusServiceUnavailable, attempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := attempts.Add(1)
+				body, _ := io.ReadAll(r.Body)
+				if tt.body != nil {
+					assert.NotEmpty(t, body, "every attempt sends the body")
+				}
+				w.WriteHeader(tt.statuses[n-1])
+			}))
+			defer server.Close()
+
+			req, err := http.NewRequest(http.MethodPost, server.URL, tt.body)
+			require.NoError(t, err)
+			policy := RetryPolicy{MaxRetries: tt.retries, Backoff: time.Millisecond}
+			resp, err := policy.Do(req, http.DefaultClient.Do)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.attempts, attempts.Load())
+		})
+	}
+}
+
+func TestRetryPolicyDoStopsWithContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, bytes.NewReader([]byte("{}")))
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = RetryPolicy{MaxRetries: 5, Backoff: time.Second}.Do(req, http.DefaultClient.Do)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second, "the backoff is cut short by the context")
+}
+
+func TestRetryPolicyTransport(t *testing.T) {
+	base := http.DefaultTransport
+	assert.Equal(t, base, RetryPolicy{}.Transport(base), "without retries the transport is unchanged")
+	assert.NotEqual(t, base, RetryPolicy{MaxRetries: 1}.Transport(base))
+}
@@ -0,0 +1,224 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// TLSFiles are the PEM files the gateway uses to reach an upstream server
+// behind TLS: a CA bundle trusted in addition to the system roots, and the
+// client certificate and key presented to servers requiring mutual TLS
+type TLSFiles struct {
+	CAPath   string
+	CertPath string
+	KeyPath  string
+}
+
+func (f TLSFiles) empty() bool {
+	return f.CAPath == "" && f.CertPath == "" && f.KeyPath == ""
+}
+
+// ParseTLSHostOverrides parses CLIENT_TLS_HOST_OVERRIDES, semicolon-separated
+// host[:port]=ca_path,cert_path,key_path entries. Paths may be left empty, in
+// which case the global CLIENT_TLS_* file is used for the target.
+func ParseTLSHostOverrides(s string) (map[string]TLSFiles, error) {
+	overrides := make(map[string]TLSFiles)
+	for entry := range strings.SplitSeq(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		host, paths, ok := strings.Cut(entry, "=")
+		host = strings.ToLower(strings.TrimSpace(host))
+		if !ok || host == "" {
+			return nil, fmt.Errorf("invalid CLIENT_TLS_HOST_OVERRIDES entry %q: expected host[:port]=ca_path,cert_path,key_path", entry)
+		}
+		parts := strings.Split(paths, ",")
+		if len(parts) > 3 {
+			return nil, fmt.Errorf("invalid CLIENT_TLS_HOST_OVERRIDES entry %q: expected at most 3 paths", entry)
+		}
+		parts = append(parts, "", "")
+		overrides[host] = TLSFiles{
+			CAPath:   strings.TrimSpace(parts[0]),
+			CertPath: strings.TrimSpace(parts[1]),
+			KeyPath:  strings.TrimSpace(parts[2]),
+		}
+	}
+	return overrides, nil
+}
+
+// NewTLSTransport applies CLIENT_TLS_CA_PATH, CLIENT_TLS_CERT_PATH and
+// CLIENT_TLS_KEY_PATH to base, and sends the requests to the targets of
+// CLIENT_TLS_HOST_OVERRIDES through clones of base using their own files.
+// Files are read once, so rotated certificates need a restart.
+func NewTLSTransport(base *http.Transport, cfg *ClientConfig) (http.RoundTripper, error) {
+	global := globalTLSFiles(cfg)
+	if !global.empty() {
+		if base.TLSClientConfig == nil {
+			base.TLSClientConfig = &tls.Config{}
+		}
+		if err := applyTLSFiles(base.TLSClientConfig, global); err != nil {
+			return nil, err
+		}
+	}
+
+	overrides, err := ParseTLSHostOverrides(cfg.ClientTlsHostOverrides)
+	if err != nil {
+		return nil, err
+	}
+	if len(overrides) == 0 {
+		return base, nil
+	}
+
+	transport := &hostTLSTransport{base: base, hosts: make(map[string]http.RoundTripper, len(overrides))}
+	for host, files := range overrides {
+		target := base.Clone()
+		if target.TLSClientConfig == nil {
+			target.TLSClientConfig = &tls.Config{}
+		}
+		if err := applyTLSFiles(target.TLSClientConfig, files.withDefaults(global)); err != nil {
+			return nil, fmt.Errorf("CLIENT_TLS_HOST_OVERRIDES %s: %w", host, err)
+		}
+		transport.hosts[host] = target
+	}
+	return transport, nil
+}
+
+// TLSConfig returns the TLS configuration for reaching target, a URL, from
+// the CLIENT_TLS_* settings, or nil when none apply to it. It is used by the
+// components that build their own transport, like the MCP client.
+func TLSConfig(cfg *ClientConfig, target string) (*tls.Config, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	global := globalTLSFiles(cfg)
+	files := global
+
+	overrides, err := ParseTLSHostOverrides(cfg.ClientTlsHostOverrides)
+	if err != nil {
+		return nil, err
+	}
+	if u, err := url.Parse(target); err == nil {
+		if override, ok := lookupHost(overrides, u); ok {
+			files = override.withDefaults(global)
+		}
+	}
+	if files.empty() {
+		return nil, nil
+	}
+	return newTLSConfig(cfg, files)
+}
+
+// TLSConfigWithFiles returns the TLS configuration using files, with the
+// global CLIENT_TLS_* files filling the ones it leaves empty. It is used for
+// targets configured with their own files outside CLIENT_TLS_HOST_OVERRIDES,
+// like the MCP servers of MCP_SERVERS_CONFIG_PATH.
+func TLSConfigWithFiles(cfg *ClientConfig, files TLSFiles) (*tls.Config, error) {
+	if cfg != nil {
+		files = files.withDefaults(globalTLSFiles(cfg))
+	}
+	if files.empty() {
+		return nil, nil
+	}
+	return newTLSConfig(cfg, files)
+}
+
+func newTLSConfig(cfg *ClientConfig, files TLSFiles) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg != nil && cfg.ClientTlsMinVersion == "TLS13" {
+		tlsConfig.MinVersion = tls.VersionTLS13
+	}
+	if err := applyTLSFiles(tlsConfig, files); err != nil {
+		return nil, err
+	}
+	return tlsConfig, nil
+}
+
+func globalTLSFiles(cfg *ClientConfig) TLSFiles {
+	return TLSFiles{
+		CAPath:   cfg.ClientTlsCaPath,
+		CertPath: cfg.ClientTlsCertPath,
+		KeyPath:  cfg.ClientTlsKeyPath,
+	}
+}
+
+// withDefaults fills the files an override leaves empty from global. The
+// certificate and key are taken together so a pair is never mixed.
+func (f TLSFiles) withDefaults(global TLSFiles) TLSFiles {
+	if f.CAPath == "" {
+		f.CAPath = global.CAPath
+	}
+	if f.CertPath == "" && f.KeyPath == "" {
+		f.CertPath, f.KeyPath = global.CertPath, global.KeyPath
+	}
+	return f
+}
+
+// applyTLSFiles adds the CA bundle and client certificate of files to
+// tlsConfig
+func applyTLSFiles(tlsConfig *tls.Config, files TLSFiles) error {
+	if files.CAPath != "" {
+		pem, err := os.ReadFile(files.CAPath)
+		if err != nil {
+			return fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in CA bundle %s", files.CAPath)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if (files.CertPath == "") != (files.KeyPath == "") {
+		return fmt.Errorf("client certificate and key must be set together")
+	}
+	if files.CertPath != "" {
+		cert, err := tls.LoadX509KeyPair(files.CertPath, files.KeyPath)
+		if err != nil {
+			return fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return nil
+}
+
+// hostTLSTransport sends the requests to the hosts with their own TLS files
+// through their transport, and every other request through base
+type hostTLSTransport struct {
+	base  http.RoundTripper
+	hosts map[string]http.RoundTripper
+}
+
+func (t *hostTLSTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if target, ok := lookupHost(t.hosts, req.URL); ok {
+		return target.RoundTrip(req)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// lookupHost finds the entry of u's host, keyed either by host:port, with
+// the scheme's default port when u has none, or by host alone
+func lookupHost[V any](hosts map[string]V, u *url.URL) (V, bool) {
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	if v, ok := hosts[net.JoinHostPort(host, port)]; ok {
+		return v, true
+	}
+	v, ok := hosts[host]
+	return v, ok
+}
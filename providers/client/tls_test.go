@@ -0,0 +1,184 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+)
+
+// issue creates a certificate signed by parent, self-signed when parent is
+// nil, and writes it and its key as PEM files to dir
+func issue(t *testing.T, dir, name string, template *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, TLSFiles) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	files := TLSFiles{CertPath: filepath.Join(dir, name+".crt"), KeyPath: filepath.Join(dir, name+".key")}
+	require.NoError(t, os.WriteFile(files.CertPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(files.KeyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return cert, key, files
+}
+
+// newMTLSServer starts a server that only accepts clients presenting a
+// certificate of its own CA, returning it with the CA path and a client
+// certificate
+func newMTLSServer(t *testing.T) (*httptest.Server, string, TLSFiles) {
+	t.Helper()
+	dir := t.TempDir()
+	ca, caKey, caFiles := issue(t, dir, "ca", &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil, nil)
+	serverCert, serverKey, _ := issue(t, dir, "server", &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca, caKey)
+	_, _, clientFiles := issue(t, dir, "client", &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "gateway"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca, caKey)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{serverCert.Raw}, PrivateKey: serverKey}},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server, caFiles.CertPath, clientFiles
+}
+
+func TestNewHTTPClientMutualTLS(t *testing.T) {
+	server, caPath, clientFiles := newMTLSServer(t)
+
+	tests := []struct {
+		name    string
+		cfg     ClientConfig
+		wantErr bool
+	}{
+		{
+			name: "global client certificate",
+			cfg: ClientConfig{
+				ClientTlsCaPath:   caPath,
+				ClientTlsCertPath: clientFiles.CertPath,
+				ClientTlsKeyPath:  clientFiles.KeyPath,
+			},
+		},
+		{
+			name: "host override",
+			cfg: ClientConfig{
+				ClientTlsHostOverrides: server.Listener.Addr().String() + "=" + caPath + "," + clientFiles.CertPath + "," + clientFiles.KeyPath,
+			},
+		},
+		{
+			name:    "no client certificate",
+			cfg:     ClientConfig{ClientTlsCaPath: caPath},
+			wantErr: true,
+		},
+		{
+			name: "override of another host",
+			cfg: ClientConfig{
+				ClientTlsHostOverrides: "example.com=" + caPath + "," + clientFiles.CertPath + "," + clientFiles.KeyPath,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewHTTPClient(&tt.cfg, "https", "127.0.0.1", "0")
+			require.NoError(t, err)
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+
+			resp, err := c.Do(req)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+			assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		})
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	_, caPath, clientFiles := newMTLSServer(t)
+	cfg := &ClientConfig{
+		ClientTlsMinVersion:    "TLS13",
+		ClientTlsHostOverrides: "mcp.internal:8443=" + caPath + "," + clientFiles.CertPath + "," + clientFiles.KeyPath,
+	}
+
+	tlsConfig, err := TLSConfig(cfg, "https://mcp.internal:8443/mcp")
+	require.NoError(t, err)
+	require.NotNil(t, tlsConfig)
+	assert.Equal(t, uint16(tls.VersionTLS13), tlsConfig.MinVersion)
+	assert.Len(t, tlsConfig.Certificates, 1)
+	assert.NotNil(t, tlsConfig.RootCAs)
+
+	tlsConfig, err = TLSConfig(cfg, "https://mcp.internal/mcp")
+	require.NoError(t, err)
+	assert.Nil(t, tlsConfig)
+
+	_, err = TLSConfig(&ClientConfig{ClientTlsCertPath: clientFiles.CertPath}, "https://mcp.internal")
+	assert.ErrorContains(t, err, "set together")
+
+	_, err = NewHTTPClient(&ClientConfig{ClientTlsCaPath: clientFiles.KeyPath}, "https", "127.0.0.1", "0")
+	assert.ErrorContains(t, err, "no certificates found")
+}
+
+func TestParseTLSHostOverrides(t *testing.T) {
+	overrides, err := ParseTLSHostOverrides(" vllm.internal = /ca.pem ; Ollama.internal:11434=,/c.pem,/k.pem;")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]TLSFiles{
+		"vllm.internal":         {CAPath: "/ca.pem"},
+		"ollama.internal:11434": {CertPath: "/c.pem", KeyPath: "/k.pem"},
+	}, overrides)
+
+	_, err = ParseTLSHostOverrides("/ca.pem")
+	assert.ErrorContains(t, err, "CLIENT_TLS_HOST_OVERRIDES")
+
+	_, err = ParseTLSHostOverrides("host=a,b,c,d")
+	assert.ErrorContains(t, err, "at most 3 paths")
+}
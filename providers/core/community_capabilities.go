@@ -0,0 +1,53 @@
+package core
+
+import (
+	_ "embed"
+	"encoding/json"
+	"sync"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// communityCapabilitiesJSON is the fallback capability table synced from the
+// community-maintained models.dev dataset via `task capabilities:sync`
+// (internal/pricinggen), keyed by "<provider>/<model>".
+//
+//go:embed community_capabilities.json
+var communityCapabilitiesJSON []byte
+
+// communityCapabilities lazily parses the embedded table once. The file is
+// generated and committed, so a parse failure is a build defect, not a
+// runtime condition; it degrades to an empty table (capabilities stay null).
+var communityCapabilities = sync.OnceValue(func() map[string]types.ModelCapabilities {
+	table := make(map[string]types.ModelCapabilities)
+	_ = json.Unmarshal(communityCapabilitiesJSON, &table)
+	return table
+})
+
+// LookupCapabilities returns the capabilities of a "<provider>/<model>" ID
+// from the community table, matching ID variants the same way as pricing.
+// Routing and request validation use it to check a model before sending it a
+// request; false means the capabilities are unknown, not absent.
+func LookupCapabilities(modelID string) (types.ModelCapabilities, bool) {
+	table := communityCapabilities()
+	for _, key := range communityLookupKeys(modelID) {
+		if entry, ok := table[key]; ok {
+			return entry, true
+		}
+	}
+	return types.ModelCapabilities{}, false
+}
+
+// applyCommunityCapabilities fills Capabilities from the community table.
+// Models absent from the table (local runtimes by design) keep nil
+// Capabilities and render as explicit nulls when requested.
+func applyCommunityCapabilities(models []types.Model) {
+	for i := range models {
+		if models[i].Capabilities != nil {
+			continue
+		}
+		if entry, ok := LookupCapabilities(models[i].ID); ok {
+			models[i].Capabilities = &entry
+		}
+	}
+}
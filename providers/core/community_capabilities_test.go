@@ -0,0 +1,45 @@
+package core
+
+import (
+	"testing"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// TestApplyCommunityCapabilities swaps in a small table: unresolved models
+// fall back to the table (including ID variants like date pins), already
+// resolved capabilities are kept, and models absent from the table stay nil.
+func TestApplyCommunityCapabilities(t *testing.T) {
+	original := communityCapabilities
+	t.Cleanup(func() { communityCapabilities = original })
+	communityCapabilities = func() map[string]types.ModelCapabilities {
+		return map[string]types.ModelCapabilities{
+			"openai/gpt-4o": {SupportsTools: true, SupportsVision: true, SupportsJSONMode: true, Source: types.ModelCapabilitiesSourceCommunity},
+		}
+	}
+
+	resolved := &types.ModelCapabilities{SupportsTools: false, Source: types.ModelCapabilitiesSourceCommunity}
+	models := []types.Model{
+		{ID: "openai/gpt-4o"},
+		{ID: "openai/gpt-4o-20240513"},
+		{ID: "openai/gpt-4o", Capabilities: resolved},
+		{ID: "ollama/llama3"},
+	}
+
+	applyCommunityCapabilities(models)
+
+	for i, m := range models[:2] {
+		if m.Capabilities == nil || !m.Capabilities.SupportsTools || !m.Capabilities.SupportsVision {
+			t.Errorf("models[%d] did not fall back to community table: %+v", i, m.Capabilities)
+		}
+	}
+	if models[2].Capabilities != resolved {
+		t.Errorf("resolved capabilities overwritten: %+v", models[2].Capabilities)
+	}
+	if models[3].Capabilities != nil {
+		t.Errorf("model absent from the table must keep nil capabilities, got %+v", models[3].Capabilities)
+	}
+	if _, ok := LookupCapabilities("ollama/llama3"); ok {
+		t.Error("LookupCapabilities found a model absent from the table")
+	}
+}
@@ -30,18 +30,26 @@ var communityPricing = sync.OnceValue(func() map[string]types.Pricing {
 // Models absent from the table (local providers, paid gates with no per-token
 // rate) keep a nil Pricing and render as explicit nulls when requested.
 func applyCommunityPricing(models []types.Model) {
-	table := communityPricing()
 	for i := range models {
 		if models[i].Pricing != nil {
 			continue
 		}
-		for _, key := range communityLookupKeys(models[i].ID) {
-			if pricing, ok := table[key]; ok {
-				models[i].Pricing = &pricing
-				break
-			}
+		if pricing, ok := LookupPricing(models[i].ID); ok {
+			models[i].Pricing = &pricing
+		}
+	}
+}
+
+// LookupPricing returns the pricing of a "<provider>/<model>" ID from the
+// community table; false means the model is not priced there.
+func LookupPricing(modelID string) (types.Pricing, bool) {
+	table := communityPricing()
+	for _, key := range communityLookupKeys(modelID) {
+		if pricing, ok := table[key]; ok {
+			return pricing, true
 		}
 	}
+	return types.Pricing{}, false
 }
 
 // communityLookupKeys returns candidate table keys for a gateway model ID in
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// extraBodyFields are the provider-specific fields clients may pass to each
+// provider in extra_body. None of them is a field of the chat completion
+// request, so they never shadow a normalized parameter.
+var extraBodyFields = map[types.Provider][]string{
+	constants.OpenaiID:      {"metadata", "prediction", "service_tier", "store", "verbosity", "web_search_options"},
+	constants.AnthropicID:   {"metadata", "thinking", "top_k"},
+	constants.GroqID:        {"include_reasoning", "search_settings", "service_tier"},
+	constants.GoogleID:      {"cached_content", "thinking_config"},
+	constants.OllamaID:      {"keep_alive", "options", "think"},
+	constants.OllamaCloudID: {"keep_alive", "options", "think"},
+	constants.LlamacppID:    {"cache_prompt", "grammar", "min_p", "repeat_penalty", "top_k"},
+	constants.MistralID:     {"prompt_mode", "safe_prompt"},
+	constants.DeepseekID:    {"thinking"},
+}
+
+// ValidateExtraBody checks the extra_body entry of provider in req against
+// the fields allowed for it. Entries of other providers are not checked, as
+// a request may be routed to any of them.
+func ValidateExtraBody(provider types.Provider, req types.CreateChatCompletionRequest) error {
+	if req.ExtraBody == nil {
+		return nil
+	}
+	allowed := extraBodyFields[provider]
+	for _, name := range slices.Sorted(maps.Keys((*req.ExtraBody)[string(provider)])) {
+		if !slices.Contains(allowed, name) {
+			if len(allowed) == 0 {
+				return fmt.Errorf("extra_body.%s: provider %s accepts no extra fields", provider, provider)
+			}
+			return fmt.Errorf("extra_body.%s.%s is not supported, expected one of %s", provider, name, strings.Join(allowed, ", "))
+		}
+	}
+	return nil
+}
+
+// extraBodyFor returns the allowed fields of the extra_body entry of
+// provider, nil when there are none
+func extraBodyFor(provider types.Provider, extraBody *map[string]map[string]any) map[string]any {
+	if extraBody == nil {
+		return nil
+	}
+	var fields map[string]any
+	for name, value := range (*extraBody)[string(provider)] {
+		if !slices.Contains(extraBodyFields[provider], name) {
+			continue
+		}
+		if fields == nil {
+			fields = make(map[string]any)
+		}
+		fields[name] = value
+	}
+	return fields
+}
+
+// marshalWithExtraBody encodes req with the provider-specific fields set at
+// the top level of the body, or for Google under extra_body.google as its
+// OpenAI-compatible API expects
+func marshalWithExtraBody(provider types.Provider, req types.CreateChatCompletionRequest, extra map[string]any) ([]byte, error) {
+	req.ExtraBody = nil
+	if len(extra) == 0 {
+		return json.Marshal(req)
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+	if provider == constants.GoogleID {
+		fields["extra_body"] = map[string]any{"google": extra}
+	} else {
+		maps.Copy(fields, extra)
+	}
+	return json.Marshal(fields)
+}
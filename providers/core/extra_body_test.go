@@ -0,0 +1,91 @@
+package core
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func TestValidateExtraBody(t *testing.T) {
+	tests := []struct {
+		name      string
+		provider  types.Provider
+		extraBody map[string]map[string]any
+		expected  string
+	}{
+		{name: "allowed", provider: constants.OllamaID, extraBody: map[string]map[string]any{"ollama": {"keep_alive": "10m"}}},
+		{name: "other providers are not checked", provider: constants.GroqID, extraBody: map[string]map[string]any{"ollama": {"anything": 1}}},
+		{name: "unknown field", provider: constants.GroqID, extraBody: map[string]map[string]any{"groq": {"service_tier": "flex", "temperature": 2}}, expected: "extra_body.groq.temperature is not supported"},
+		{name: "provider without extra fields", provider: constants.CohereID, extraBody: map[string]map[string]any{"cohere": {"k": 10}}, expected: "accepts no extra fields"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateExtraBody(tt.provider, types.CreateChatCompletionRequest{ExtraBody: &tt.extraBody})
+			if tt.expected == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Fatalf("expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestMarshalChatRequestExtraBody(t *testing.T) {
+	extraBody := map[string]map[string]any{
+		"ollama":    {"keep_alive": "10m", "temperature": 2},
+		"groq":      {"service_tier": "flex"},
+		"google":    {"thinking_config": map[string]any{"thinking_budget": 1024}},
+		"anthropic": {"metadata": map[string]any{"user_id": "u1"}},
+		"mistral":   {"prompt_mode": "reasoning"},
+	}
+	high := types.SafetyHigh
+	tests := []struct {
+		provider types.Provider
+		safety   *types.SafetySettings
+		expected string
+	}{
+		{provider: constants.OllamaID, expected: `{"model":"m","messages":[],"keep_alive":"10m"}`},
+		{provider: constants.GroqID, expected: `{"model":"m","messages":[],"service_tier":"flex"}`},
+		{provider: constants.AnthropicID, expected: `{"model":"m","messages":[],"metadata":{"user_id":"u1"}}`},
+		{provider: constants.OpenaiID, expected: `{"model":"m","messages":[]}`},
+		{
+			provider: constants.GoogleID,
+			safety:   &types.SafetySettings{Categories: &map[string]types.SafetyLevel{"hate": high}},
+			expected: `{"model":"m","messages":[],"extra_body":{"google":{"thinking_config":{"thinking_budget":1024},"safety_settings":[{"category":"HARM_CATEGORY_HATE_SPEECH","threshold":"BLOCK_LOW_AND_ABOVE"}]}}}`,
+		},
+		{
+			provider: constants.MistralID,
+			safety:   &types.SafetySettings{Categories: &map[string]types.SafetyLevel{"hate": high}},
+			expected: `{"model":"m","messages":[],"prompt_mode":"reasoning","safe_prompt":true}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.provider), func(t *testing.T) {
+			id := tt.provider
+			p := &ProviderImpl{ID: &id}
+			body, err := p.marshalChatRequest(types.CreateChatCompletionRequest{Model: "m", Messages: []types.Message{}, ExtraBody: &extraBody, SafetySettings: tt.safety})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got, want any
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.expected), &want); err != nil {
+				t.Fatal(err)
+			}
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(want)
+			if string(gotJSON) != string(wantJSON) {
+				t.Errorf("expected %s, got %s", wantJSON, gotJSON)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"net/http"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// InternalHeader marks the requests the gateway sends to itself, such as
+// chat completions forwarded to its /proxy route. Its value is a secret of
+// the process, so clients cannot pass for one. InternalTenantHeader carries
+// the tenant the original request was resolved to.
+const (
+	InternalHeader       = "X-Gateway-Internal"
+	InternalTenantHeader = "X-Gateway-Tenant"
+)
+
+// internalSecret is the value of InternalHeader, drawn at startup
+var internalSecret = rand.Text()
+
+// SetInternalHeaders marks req as sent by the gateway to itself on behalf of
+// the request of ctx, carrying its tenant
+func SetInternalHeaders(ctx context.Context, req *http.Request) {
+	req.Header.Set(InternalHeader, internalSecret)
+	if tenant, ok := ctx.Value(types.TenantContextKey).(string); ok && tenant != "" {
+		req.Header.Set(InternalTenantHeader, tenant)
+	}
+}
+
+// IsInternal reports whether req was sent by the gateway to itself
+func IsInternal(req *http.Request) bool {
+	return subtle.ConstantTimeCompare([]byte(req.Header.Get(InternalHeader)), []byte(internalSecret)) == 1
+}
+
+// StripInternalHeaders removes the headers of SetInternalHeaders from a
+// request about to be forwarded to a provider
+func StripInternalHeaders(header http.Header) {
+	header.Del(InternalHeader)
+	header.Del(InternalTenantHeader)
+}
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// paramRange bounds a sampling parameter
+type paramRange struct {
+	min, max float32
+}
+
+// OpenAI's sampling parameter ranges, the defaults of every provider
+var (
+	openAITemperature = paramRange{0, 2}
+	openAITopP        = paramRange{0, 1}
+	openAIPenalty     = paramRange{-2, 2}
+)
+
+// paramRules describes how a provider's OpenAI-compatible API departs from
+// OpenAI's sampling parameters. Unset ranges are OpenAI's.
+type paramRules struct {
+	temperature *paramRange
+	topP        *paramRange
+	// penalty bounds frequency_penalty and presence_penalty
+	penalty *paramRange
+	// singleChoice rejects n greater than 1
+	singleChoice bool
+	// unsupported lists the parameters the provider rejects or ignores
+	unsupported []string
+}
+
+// providerParamRules lists the providers whose sampling parameters differ
+// from OpenAI's
+var providerParamRules = map[types.Provider]paramRules{
+	constants.AnthropicID: {
+		temperature:  &paramRange{0, 1},
+		singleChoice: true,
+		unsupported:  []string{"frequency_penalty", "presence_penalty", "seed", "logit_bias", "logprobs", "top_logprobs"},
+	},
+	constants.CohereID: {
+		temperature:  &paramRange{0, 1},
+		topP:         &paramRange{0.01, 0.99},
+		penalty:      &paramRange{0, 1},
+		singleChoice: true,
+		unsupported:  []string{"logit_bias", "logprobs", "top_logprobs"},
+	},
+	constants.GroqID: {
+		singleChoice: true,
+		unsupported:  []string{"logit_bias", "logprobs", "top_logprobs"},
+	},
+	constants.GoogleID: {
+		unsupported: []string{"logit_bias"},
+	},
+}
+
+// unset clears an optional parameter, reporting whether it was set
+func unset[T any](field **T) bool {
+	set := *field != nil
+	*field = nil
+	return set
+}
+
+// optionalParams clear the optional request parameters by name
+var optionalParams = map[string]func(req *types.CreateChatCompletionRequest) bool{
+	"frequency_penalty": func(req *types.CreateChatCompletionRequest) bool { return unset(&req.FrequencyPenalty) },
+	"presence_penalty":  func(req *types.CreateChatCompletionRequest) bool { return unset(&req.PresencePenalty) },
+	"seed":              func(req *types.CreateChatCompletionRequest) bool { return unset(&req.Seed) },
+	"logit_bias":        func(req *types.CreateChatCompletionRequest) bool { return unset(&req.LogitBias) },
+	"logprobs":          func(req *types.CreateChatCompletionRequest) bool { return unset(&req.Logprobs) },
+	"top_logprobs":      func(req *types.CreateChatCompletionRequest) bool { return unset(&req.TopLogprobs) },
+}
+
+// clamp bounds an optional parameter to r, returning a warning when it was
+// out of range. The value is replaced, not written through, as the caller's
+// request shares it.
+func clamp(name string, field **float32, r paramRange) string {
+	if *field == nil || (**field >= r.min && **field <= r.max) {
+		return ""
+	}
+	value := min(max(**field, r.min), r.max)
+	*field = &value
+	return fmt.Sprintf("%s clamped to %s", name, strconv.FormatFloat(float64(value), 'f', -1, 32))
+}
+
+// NormalizeParams adapts the sampling, token limit and reasoning parameters
+// of req to provider: out of range values are clamped and the parameters the
+// provider does not support are dropped. It returns a warning for every
+// parameter changed this way. Normalizing a request twice changes nothing the
+// second time.
+func NormalizeParams(provider types.Provider, req *types.CreateChatCompletionRequest) []string {
+	rules := providerParamRules[provider]
+	var warnings []string
+	for _, name := range rules.unsupported {
+		if optionalParams[name](req) {
+			warnings = append(warnings, name+" dropped")
+		}
+	}
+	if rules.singleChoice && req.N != nil && *req.N > 1 {
+		req.N = nil
+		warnings = append(warnings, "n dropped")
+	}
+
+	for _, p := range []struct {
+		name  string
+		field **float32
+		r     *paramRange
+		def   paramRange
+	}{
+		{"temperature", &req.Temperature, rules.temperature, openAITemperature},
+		{"top_p", &req.TopP, rules.topP, openAITopP},
+		{"frequency_penalty", &req.FrequencyPenalty, rules.penalty, openAIPenalty},
+		{"presence_penalty", &req.PresencePenalty, rules.penalty, openAIPenalty},
+	} {
+		r := p.def
+		if p.r != nil {
+			r = *p.r
+		}
+		if warning := clamp(p.name, p.field, r); warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
+
+	for _, name := range adaptReasoningParams(provider, req) {
+		warnings = append(warnings, name+" dropped")
+	}
+	return warnings
+}
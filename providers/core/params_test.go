@@ -0,0 +1,98 @@
+package core
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func TestNormalizeParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider types.Provider
+		body     string
+		expected string
+		warnings []string
+	}{
+		{
+			name:     "openai keeps parameters in range",
+			provider: constants.OpenaiID,
+			body:     `{"model":"gpt-4o","temperature":1.5,"top_p":0.9,"frequency_penalty":-1,"seed":7,"n":2}`,
+			expected: `{"model":"gpt-4o","temperature":1.5,"top_p":0.9,"frequency_penalty":-1,"seed":7,"n":2}`,
+		},
+		{
+			name:     "out of range values are clamped",
+			provider: constants.OpenaiID,
+			body:     `{"model":"gpt-4o","temperature":3,"top_p":-0.5,"presence_penalty":2.5}`,
+			expected: `{"model":"gpt-4o","temperature":2,"top_p":0,"presence_penalty":2}`,
+			warnings: []string{"temperature clamped to 2", "top_p clamped to 0", "presence_penalty clamped to 2"},
+		},
+		{
+			name:     "anthropic",
+			provider: constants.AnthropicID,
+			body:     `{"model":"claude-sonnet-4-5","temperature":1.2,"frequency_penalty":0.5,"seed":7,"n":3,"logprobs":true}`,
+			expected: `{"model":"claude-sonnet-4-5","temperature":1}`,
+			warnings: []string{"frequency_penalty dropped", "seed dropped", "logprobs dropped", "n dropped", "temperature clamped to 1"},
+		},
+		{
+			name:     "cohere",
+			provider: constants.CohereID,
+			body:     `{"model":"command-r","top_p":1,"presence_penalty":-1,"logit_bias":{"1":5},"n":1}`,
+			expected: `{"model":"command-r","top_p":0.99,"presence_penalty":0,"n":1}`,
+			warnings: []string{"logit_bias dropped", "top_p clamped to 0.99", "presence_penalty clamped to 0"},
+		},
+		{
+			name:     "reasoning parameters are included",
+			provider: constants.GroqID,
+			body:     `{"model":"llama-3.3-70b-versatile","top_logprobs":3,"reasoning_effort":"high"}`,
+			expected: `{"model":"llama-3.3-70b-versatile","reasoning_effort":"high"}`,
+			warnings: []string{"top_logprobs dropped"},
+		},
+		{
+			name:     "deepseek reasoner",
+			provider: constants.DeepseekID,
+			body:     `{"model":"deepseek-reasoner","logprobs":true,"reasoning_effort":"low"}`,
+			expected: `{"model":"deepseek-reasoner"}`,
+			warnings: []string{"logprobs dropped", "reasoning_effort dropped"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req types.CreateChatCompletionRequest
+			if err := json.Unmarshal([]byte(tt.body), &req); err != nil {
+				t.Fatalf("invalid request: %v", err)
+			}
+			temperature := req.Temperature
+			var before float32
+			if temperature != nil {
+				before = *temperature
+			}
+
+			warnings := NormalizeParams(tt.provider, &req)
+			if !slices.Equal(warnings, tt.warnings) {
+				t.Errorf("warnings %q, expected %q", warnings, tt.warnings)
+			}
+			if temperature != nil && *temperature != before {
+				t.Error("the caller's values must not be written through")
+			}
+
+			var expected types.CreateChatCompletionRequest
+			if err := json.Unmarshal([]byte(tt.expected), &expected); err != nil {
+				t.Fatalf("invalid expected request: %v", err)
+			}
+			got, _ := json.Marshal(req)
+			want, _ := json.Marshal(expected)
+			if string(got) != string(want) {
+				t.Errorf("got %s, expected %s", got, want)
+			}
+
+			if warnings := NormalizeParams(tt.provider, &req); len(warnings) > 0 {
+				t.Errorf("normalizing twice warned %q", warnings)
+			}
+		})
+	}
+}
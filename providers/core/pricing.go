@@ -2,6 +2,7 @@ package core
 
 import (
 	"strconv"
+	"strings"
 	"time"
 
 	types "github.com/inference-gateway/inference-gateway/providers/types"
@@ -64,3 +65,36 @@ func pricingRate(pricing map[string]any, keys ...string) *string {
 	}
 	return nil
 }
+
+// EstimateCost prices a request's token usage at the given rates. Input
+// tokens include the cached ones, which are billed at the cache read rate
+// when one is published. False means a rate could not be parsed.
+func EstimateCost(pricing types.Pricing, inputTokens, outputTokens, cachedTokens int64) (float64, bool) {
+	input, err := strconv.ParseFloat(pricing.InputPerToken, 64)
+	if err != nil {
+		return 0, false
+	}
+	output, err := strconv.ParseFloat(pricing.OutputPerToken, 64)
+	if err != nil {
+		return 0, false
+	}
+	cacheRead := input
+	if pricing.CacheReadPerToken != nil {
+		if cacheRead, err = strconv.ParseFloat(*pricing.CacheReadPerToken, 64); err != nil {
+			return 0, false
+		}
+	}
+	cachedTokens = min(cachedTokens, inputTokens)
+	return float64(inputTokens-cachedTokens)*input + float64(cachedTokens)*cacheRead + float64(outputTokens)*output, true
+}
+
+// RequestCost prices the token usage of a response of model at its community
+// rates in USD. model may carry the provider prefix. False means the model
+// is not priced in USD.
+func RequestCost(provider, model string, inputTokens, outputTokens, cachedTokens int64) (float64, bool) {
+	pricing, ok := LookupPricing(provider + "/" + strings.TrimPrefix(model, provider+"/"))
+	if !ok || pricing.Currency != "USD" {
+		return 0, false
+	}
+	return EstimateCost(pricing, inputTokens, outputTokens, cachedTokens)
+}
@@ -0,0 +1,40 @@
+package core
+
+import (
+	"math"
+	"testing"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func TestEstimateCost(t *testing.T) {
+	cacheRead := "0.0000005"
+	invalid := "free"
+	tests := []struct {
+		name    string
+		pricing types.Pricing
+		want    float64
+		ok      bool
+	}{
+		{"without cache rate", types.Pricing{InputPerToken: "0.000002", OutputPerToken: "0.00001"}, 1000*0.000002 + 100*0.00001, true},
+		{"cached input at the cache rate", types.Pricing{InputPerToken: "0.000002", OutputPerToken: "0.00001", CacheReadPerToken: &cacheRead}, 600*0.000002 + 400*0.0000005 + 100*0.00001, true},
+		{"invalid rate", types.Pricing{InputPerToken: "0.000002", OutputPerToken: "0.00001", CacheReadPerToken: &invalid}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := EstimateCost(tt.pricing, 1000, 100, 400)
+			if ok != tt.ok || math.Abs(got-tt.want) > 1e-12 {
+				t.Fatalf("EstimateCost() = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestLookupPricing(t *testing.T) {
+	if _, ok := LookupPricing("openai/gpt-4o-2024-08-06"); !ok {
+		t.Error("expected the community table to price openai/gpt-4o-2024-08-06")
+	}
+	if _, ok := LookupPricing("ollama/llama3"); ok {
+		t.Error("expected local models to be unpriced")
+	}
+}
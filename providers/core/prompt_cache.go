@@ -0,0 +1,105 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// upstreamCacheUsage holds the prompt cache hits some providers report in
+// their own usage fields rather than in prompt_tokens_details.cached_tokens
+type upstreamCacheUsage struct {
+	// CacheReadInputTokens is reported by Anthropic
+	CacheReadInputTokens *int64 `json:"cache_read_input_tokens"`
+	// PromptCacheHitTokens is reported by DeepSeek
+	PromptCacheHitTokens *int64 `json:"prompt_cache_hit_tokens"`
+}
+
+var upstreamCacheKeys = [][]byte{[]byte(`"cache_read_input_tokens"`), []byte(`"prompt_cache_hit_tokens"`)}
+
+func hasUpstreamCacheUsage(data []byte) bool {
+	for _, key := range upstreamCacheKeys {
+		if bytes.Contains(data, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// withCachedTokens returns usage with the provider's own prompt cache hits
+// copied to prompt_tokens_details.cached_tokens, keeping every other field.
+// ok is false when usage has none or already reports cached_tokens.
+func withCachedTokens(usage json.RawMessage) (json.RawMessage, bool) {
+	if !hasUpstreamCacheUsage(usage) {
+		return nil, false
+	}
+	var upstream upstreamCacheUsage
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(usage, &upstream) != nil || json.Unmarshal(usage, &fields) != nil {
+		return nil, false
+	}
+	cached := upstream.CacheReadInputTokens
+	if cached == nil {
+		cached = upstream.PromptCacheHitTokens
+	}
+	if cached == nil {
+		return nil, false
+	}
+
+	var details map[string]json.RawMessage
+	if raw, ok := fields["prompt_tokens_details"]; ok {
+		_ = json.Unmarshal(raw, &details)
+	}
+	if _, ok := details["cached_tokens"]; ok {
+		return nil, false
+	}
+	if details == nil {
+		details = make(map[string]json.RawMessage)
+	}
+	details["cached_tokens"], _ = json.Marshal(*cached)
+	fields["prompt_tokens_details"], _ = json.Marshal(details)
+
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return nil, false
+	}
+	return out, true
+}
+
+// surfaceCachedTokens applies withCachedTokens to the usage of a chat
+// completion response or stream chunk
+func surfaceCachedTokens(body []byte) ([]byte, bool) {
+	if !hasUpstreamCacheUsage(body) {
+		return nil, false
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) != nil {
+		return nil, false
+	}
+	usage, ok := withCachedTokens(fields["usage"])
+	if !ok {
+		return nil, false
+	}
+	fields["usage"] = usage
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return nil, false
+	}
+	return out, true
+}
+
+// surfaceCachedTokensLine applies surfaceCachedTokens to an SSE data line
+func surfaceCachedTokensLine(line []byte) []byte {
+	data, ok := bytes.CutPrefix(bytes.TrimSpace(line), []byte("data:"))
+	if !ok {
+		return line
+	}
+	chunk, ok := surfaceCachedTokens(bytes.TrimSpace(data))
+	if !ok {
+		return line
+	}
+	out := append([]byte("data: "), chunk...)
+	if bytes.HasSuffix(line, []byte("\n")) {
+		out = append(out, '\n')
+	}
+	return out
+}
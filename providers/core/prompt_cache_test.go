@@ -0,0 +1,73 @@
+package core
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSurfaceCachedTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		cached any
+	}{
+		{
+			name:   "anthropic cache reads",
+			body:   `{"id":"1","usage":{"prompt_tokens":2100,"completion_tokens":5,"cache_read_input_tokens":2048,"cache_creation_input_tokens":0}}`,
+			cached: float64(2048),
+		},
+		{
+			name:   "deepseek cache hits",
+			body:   `{"id":"1","usage":{"prompt_tokens":80,"prompt_cache_hit_tokens":64,"prompt_cache_miss_tokens":16}}`,
+			cached: float64(64),
+		},
+		{
+			name: "cached_tokens already reported",
+			body: `{"id":"1","usage":{"prompt_tokens":80,"prompt_cache_hit_tokens":64,"prompt_tokens_details":{"cached_tokens":32}}}`,
+		},
+		{
+			name: "no cache usage",
+			body: `{"id":"1","usage":{"prompt_tokens":80}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, ok := surfaceCachedTokens([]byte(tt.body))
+			if ok != (tt.cached != nil) {
+				t.Fatalf("surfaceCachedTokens ok = %v, want %v", ok, tt.cached != nil)
+			}
+			if !ok {
+				return
+			}
+			var resp struct {
+				ID    string         `json:"id"`
+				Usage map[string]any `json:"usage"`
+			}
+			if err := json.Unmarshal(out, &resp); err != nil {
+				t.Fatal(err)
+			}
+			details, _ := resp.Usage["prompt_tokens_details"].(map[string]any)
+			if details["cached_tokens"] != tt.cached {
+				t.Errorf("cached_tokens = %v, want %v", details["cached_tokens"], tt.cached)
+			}
+			if resp.ID != "1" || resp.Usage["prompt_tokens"] == nil {
+				t.Errorf("other fields must be kept, got %s", out)
+			}
+		})
+	}
+}
+
+func TestSurfaceCachedTokensLine(t *testing.T) {
+	line := []byte(`data: {"choices":[],"usage":{"prompt_tokens":2100,"cache_read_input_tokens":2048}}` + "\n")
+	out := string(surfaceCachedTokensLine(line))
+	if !strings.HasPrefix(out, "data: {") || !strings.HasSuffix(out, "}\n") || !strings.Contains(out, `"prompt_tokens_details":{"cached_tokens":2048}`) {
+		t.Errorf("unexpected line %q", out)
+	}
+
+	for _, unchanged := range []string{"data: [DONE]\n", `data: {"choices":[{"delta":{"content":"hi"}}]}` + "\n", ": keep-alive\n"} {
+		if got := string(surfaceCachedTokensLine([]byte(unchanged))); got != unchanged {
+			t.Errorf("line %q changed to %q", unchanged, got)
+		}
+	}
+}
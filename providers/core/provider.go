@@ -15,6 +15,7 @@ import (
 	otelapi "go.opentelemetry.io/otel"
 	propagation "go.opentelemetry.io/otel/propagation"
 
+	supervisor "github.com/inference-gateway/inference-gateway/internal/supervisor"
 	l "github.com/inference-gateway/inference-gateway/logger"
 	client "github.com/inference-gateway/inference-gateway/providers/client"
 	constants "github.com/inference-gateway/inference-gateway/providers/constants"
@@ -26,6 +27,9 @@ import (
 type HTTPError struct {
 	StatusCode int
 	Message    string
+	// Header holds the headers of the provider's response, e.g. its
+	// Retry-After and rate limit headers
+	Header http.Header
 }
 
 func (e *HTTPError) Error() string {
@@ -109,6 +113,10 @@ func (p *ProviderImpl) createHTTPRequest(ctx context.Context, url string, body [
 	if authToken, ok := ctx.Value(types.AuthTokenContextKey).(string); ok && authToken != "" {
 		req.Header.Set("Authorization", "Bearer "+authToken)
 	}
+	if requestID, ok := ctx.Value(types.RequestIDContextKey).(string); ok && requestID != "" {
+		req.Header.Set("X-Request-ID", requestID)
+	}
+	SetInternalHeaders(ctx, req)
 
 	otelapi.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
 
@@ -126,6 +134,7 @@ func (p *ProviderImpl) handleHTTPError(response *http.Response, operation string
 		return &HTTPError{
 			StatusCode: response.StatusCode,
 			Message:    fmt.Sprintf("failed to read response body (status %d)", response.StatusCode),
+			Header:     response.Header,
 		}
 	}
 
@@ -133,11 +142,45 @@ func (p *ProviderImpl) handleHTTPError(response *http.Response, operation string
 	err := &HTTPError{
 		StatusCode: response.StatusCode,
 		Message:    errorMsg,
+		Header:     response.Header,
+	}
+	log := p.Logger
+	if response.Request != nil {
+		log = l.FromContext(response.Request.Context(), log)
 	}
-	p.Logger.Error("non-200 status code", err, "provider", p.GetName(), "statusCode", response.StatusCode, "operation", operation)
+	log.Error("non-200 status code", err, "provider", p.GetName(), "statusCode", response.StatusCode, "operation", operation)
 	return err
 }
 
+// ApplyAuth sets the provider's auth credential (header or query
+// param) and extra headers on req. An unrecognized auth type is returned as an
+// error so misconfigured providers fail loudly instead of sending
+// unauthenticated requests upstream.
+func ApplyAuth(req *http.Request, provider IProvider) error {
+	token := provider.GetToken()
+	switch provider.GetAuthType() {
+	case constants.AuthTypeBearer:
+		req.Header.Set("Authorization", "Bearer "+token)
+	case constants.AuthTypeXheader:
+		req.Header.Set("x-api-key", token)
+	case constants.AuthTypeQuery:
+		query := req.URL.Query()
+		query.Set("key", token)
+		req.URL.RawQuery = query.Encode()
+	case constants.AuthTypeNone:
+		// Do Nothing
+	default:
+		return fmt.Errorf("unsupported auth type %q", provider.GetAuthType())
+	}
+
+	for key, values := range provider.GetExtraHeaders() {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	return nil
+}
+
 // ListModels fetches the list of models available from the provider and returns them in OpenAI compatible format
 func (p *ProviderImpl) ListModels(ctx context.Context) (types.ListModelsResponse, error) {
 	url := "/proxy/" + string(*p.GetID()) + p.EndpointModels()
@@ -151,6 +194,10 @@ func (p *ProviderImpl) ListModels(ctx context.Context) (types.ListModelsResponse
 	if authToken, ok := ctx.Value(types.AuthTokenContextKey).(string); ok && authToken != "" {
 		req.Header.Set("Authorization", "Bearer "+authToken)
 	}
+	if requestID, ok := ctx.Value(types.RequestIDContextKey).(string); ok && requestID != "" {
+		req.Header.Set("X-Request-ID", requestID)
+	}
+	SetInternalHeaders(ctx, req)
 
 	otelapi.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
 
@@ -181,19 +228,39 @@ func (p *ProviderImpl) ListModels(ctx context.Context) (types.ListModelsResponse
 	}
 
 	resp := transformer.Transform()
+	if resp.Provider != nil && *resp.Provider != *p.GetID() {
+		relabelModels(&resp, *p.GetID())
+	}
 	resp.Object = cmp.Or(resp.Object, "list")
 	applyProviderContextWindows(body, resp.Data)
 	applyCommunityContextWindows(resp.Data)
 	applyProviderPricing(body, resp.Data)
 	applyCommunityPricing(resp.Data)
+	applyCommunityCapabilities(resp.Data)
 	return resp, nil
 }
 
+// relabelModels attributes the models listed by the transformer of another
+// provider to id, as custom OpenAI-compatible providers list their models
+// with the OpenAI one
+func relabelModels(resp *types.ListModelsResponse, id types.Provider) {
+	prefix := string(*resp.Provider) + "/"
+	for i := range resp.Data {
+		resp.Data[i].ID = string(id) + "/" + strings.TrimPrefix(resp.Data[i].ID, prefix)
+		resp.Data[i].ServedBy = id
+	}
+	resp.Provider = &id
+}
+
 // ChatCompletions generates chat completions from the provider
 func (p *ProviderImpl) ChatCompletions(ctx context.Context, clientReq types.CreateChatCompletionRequest) (types.CreateChatCompletionResponse, error) {
 	url := p.buildProviderURL()
+	tools := translateTools(*p.GetID(), &clientReq)
+	if warnings := NormalizeParams(*p.GetID(), &clientReq); len(warnings) > 0 {
+		p.Logger.Debug("normalized request parameters", "provider", p.GetName(), "model", clientReq.Model, "warnings", warnings)
+	}
 
-	reqBody, err := json.Marshal(clientReq)
+	reqBody, err := p.marshalChatRequest(clientReq)
 	if err != nil {
 		p.Logger.Error("Failed to marshal request", err, "provider", p.GetName())
 		return types.CreateChatCompletionResponse{}, err
@@ -216,11 +283,21 @@ func (p *ProviderImpl) ChatCompletions(ctx context.Context, clientReq types.Crea
 		return types.CreateChatCompletionResponse{}, err
 	}
 
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		p.Logger.Error("Failed to read response", err, "provider", p.GetName())
+		return types.CreateChatCompletionResponse{}, err
+	}
+	if surfaced, ok := surfaceCachedTokens(body); ok {
+		body = surfaced
+	}
+
 	var resp types.CreateChatCompletionResponse
-	if err := json.NewDecoder(response.Body).Decode(&resp); err != nil {
+	if err := json.Unmarshal(body, &resp); err != nil {
 		p.Logger.Error("Failed to unmarshal response", err, "provider", p.GetName())
 		return types.CreateChatCompletionResponse{}, err
 	}
+	tools.restoreResponse(&resp)
 
 	return resp, nil
 }
@@ -230,10 +307,14 @@ func (p *ProviderImpl) StreamChatCompletions(ctx context.Context, clientReq type
 	url := p.buildProviderURL()
 
 	streamReq := p.prepareStreamingRequest(clientReq)
+	tools := translateTools(*p.GetID(), &streamReq)
+	if warnings := NormalizeParams(*p.GetID(), &streamReq); len(warnings) > 0 {
+		p.Logger.Debug("normalized request parameters", "provider", p.GetName(), "model", streamReq.Model, "warnings", warnings)
+	}
 
 	p.Logger.Debug("streaming chat completions", "provider", p.GetName(), "url", url, "request", streamReq)
 
-	reqBody, err := json.Marshal(streamReq)
+	reqBody, err := p.marshalChatRequest(streamReq)
 	if err != nil {
 		p.Logger.Error("failed to marshal request", err, "provider", p.GetName())
 		return nil, err
@@ -256,12 +337,23 @@ func (p *ProviderImpl) StreamChatCompletions(ctx context.Context, clientReq type
 		return nil, err
 	}
 
+	adapter := newStreamAdapter(*p.GetID(), response.Header.Get("Content-Type"), streamReq.Model)
+
 	stream := make(chan []byte, 100)
-	go func() {
+	err = supervisor.Go(ctx, supervisor.ProviderStream, func(ctx context.Context) {
 		defer response.Body.Close()
 		defer close(stream)
 
 		reader := bufio.NewReaderSize(response.Body, 4096)
+		send := func(line []byte) bool {
+			select {
+			case stream <- surfaceCachedTokensLine(tools.restoreChunk(line)):
+				return true
+			case <-ctx.Done():
+				p.Logger.Debug("stream cancelled while sending data", "provider", p.GetName())
+				return false
+			}
+		}
 
 		for {
 			select {
@@ -278,19 +370,41 @@ func (p *ProviderImpl) StreamChatCompletions(ctx context.Context, clientReq type
 				} else {
 					p.Logger.Debug("stream ended gracefully", "provider", p.GetName())
 				}
+				if adapter != nil && err == io.EOF {
+					// Native streams may end without a trailing newline
+					var adapted [][]byte
+					if len(line) > 0 {
+						adapted = adapter.adapt(line)
+					}
+					for _, l := range append(adapted, adapter.finish()...) {
+						if !send(l) {
+							return
+						}
+					}
+				}
 				return
 			}
 
-			if len(line) > 0 {
-				select {
-				case stream <- line:
-				case <-ctx.Done():
-					p.Logger.Debug("stream cancelled while sending data", "provider", p.GetName())
+			if len(line) == 0 {
+				continue
+			}
+			if adapter == nil {
+				if !send(line) {
+					return
+				}
+				continue
+			}
+			for _, adapted := range adapter.adapt(line) {
+				if !send(adapted) {
 					return
 				}
 			}
 		}
-	}()
+	})
+	if err != nil {
+		response.Body.Close()
+		return nil, err
+	}
 
 	return stream, nil
 }
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"slices"
+	"strings"
+
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// reasoningRules describes which reasoning parameters a provider's
+// OpenAI-compatible API accepts. Providers that are not listed get
+// max_completion_tokens as max_tokens and no reasoning_effort.
+type reasoningRules struct {
+	// maxCompletionTokens accepts max_completion_tokens
+	maxCompletionTokens bool
+	// reasoningEffort accepts reasoning_effort
+	reasoningEffort bool
+	// minimalEffort accepts the minimal effort, which otherwise becomes low
+	minimalEffort bool
+}
+
+// providerReasoningRules lists the providers accepting reasoning parameters
+var providerReasoningRules = map[types.Provider]reasoningRules{
+	constants.OpenaiID:      {maxCompletionTokens: true, reasoningEffort: true, minimalEffort: true},
+	constants.GroqID:        {maxCompletionTokens: true, reasoningEffort: true},
+	constants.GoogleID:      {maxCompletionTokens: true, reasoningEffort: true},
+	constants.AnthropicID:   {maxCompletionTokens: true},
+	constants.OllamaID:      {reasoningEffort: true},
+	constants.OllamaCloudID: {reasoningEffort: true},
+}
+
+// isOpenAIReasoningModel reports whether an OpenAI model is an o-series or
+// GPT-5 reasoning model, which only take max_completion_tokens and reject
+// the sampling parameters
+func isOpenAIReasoningModel(model string) bool {
+	for _, prefix := range []string{"o1", "o3", "o4", "gpt-5"} {
+		if strings.HasPrefix(model, prefix) && !strings.HasPrefix(model, "gpt-5-chat") {
+			return true
+		}
+	}
+	return false
+}
+
+// isDeepSeekReasoningModel reports whether a DeepSeek model reasons. It
+// ignores the sampling parameters and rejects logprobs.
+func isDeepSeekReasoningModel(model string) bool {
+	return model == "deepseek-reasoner" || strings.Contains(model, "-r1")
+}
+
+// adaptReasoningParams rewrites the reasoning and token limit parameters of
+// req that provider would reject, and returns the names of the parameters it
+// dropped
+func adaptReasoningParams(provider types.Provider, req *types.CreateChatCompletionRequest) []string {
+	rules := providerReasoningRules[provider]
+	var dropped []string
+
+	openAIReasoning := provider == constants.OpenaiID && isOpenAIReasoningModel(req.Model)
+	switch {
+	case openAIReasoning:
+		if req.MaxCompletionTokens == nil {
+			req.MaxCompletionTokens = req.MaxTokens
+		}
+		req.MaxTokens = nil
+		if req.Temperature != nil && *req.Temperature != 1 {
+			req.Temperature = nil
+			dropped = append(dropped, "temperature")
+		}
+		if req.TopP != nil && *req.TopP != 1 {
+			req.TopP = nil
+			dropped = append(dropped, "top_p")
+		}
+		if req.PresencePenalty != nil {
+			req.PresencePenalty = nil
+			dropped = append(dropped, "presence_penalty")
+		}
+		if req.FrequencyPenalty != nil {
+			req.FrequencyPenalty = nil
+			dropped = append(dropped, "frequency_penalty")
+		}
+		if req.LogitBias != nil {
+			req.LogitBias = nil
+			dropped = append(dropped, "logit_bias")
+		}
+	case !rules.maxCompletionTokens && req.MaxCompletionTokens != nil:
+		if req.MaxTokens == nil {
+			req.MaxTokens = req.MaxCompletionTokens
+		}
+		req.MaxCompletionTokens = nil
+	}
+
+	if (openAIReasoning || provider == constants.DeepseekID && isDeepSeekReasoningModel(req.Model)) && (req.Logprobs != nil || req.TopLogprobs != nil) {
+		req.Logprobs, req.TopLogprobs = nil, nil
+		dropped = append(dropped, "logprobs")
+	}
+
+	if req.ReasoningEffort != nil {
+		switch {
+		case !rules.reasoningEffort:
+			req.ReasoningEffort = nil
+			dropped = append(dropped, "reasoning_effort")
+		case *req.ReasoningEffort == types.Minimal && !rules.minimalEffort:
+			low := types.Low
+			req.ReasoningEffort = &low
+		}
+	}
+
+	if provider == constants.DeepseekID {
+		dropPastReasoning(req)
+	}
+	return dropped
+}
+
+// dropPastReasoning removes the reasoning of the assistant messages of past
+// turns, before the last user message, which DeepSeek rejects with a 400.
+// The reasoning of the current turn's tool calls is kept, as DeepSeek
+// expects it back while the model is calling tools. The caller's messages are
+// left untouched.
+func dropPastReasoning(req *types.CreateChatCompletionRequest) {
+	last := -1
+	for i, msg := range req.Messages {
+		if msg.Role == types.User {
+			last = i
+		}
+	}
+	if last < 0 || !slices.ContainsFunc(req.Messages[:last], hasReasoning) {
+		return
+	}
+	messages := slices.Clone(req.Messages)
+	for i := range messages[:last] {
+		messages[i].Reasoning, messages[i].ReasoningContent = nil, nil
+	}
+	req.Messages = messages
+}
+
+func hasReasoning(msg types.Message) bool {
+	return msg.Reasoning != nil || msg.ReasoningContent != nil
+}
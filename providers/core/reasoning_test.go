@@ -0,0 +1,115 @@
+package core
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func TestAdaptReasoningParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider types.Provider
+		body     string
+		expected string
+		dropped  []string
+	}{
+		{
+			name:     "openai reasoning model takes max_completion_tokens and no sampling",
+			provider: constants.OpenaiID,
+			body:     `{"model":"o3-mini","max_tokens":512,"temperature":0.2,"top_p":1,"presence_penalty":0.5,"logprobs":true,"reasoning_effort":"high"}`,
+			expected: `{"model":"o3-mini","max_completion_tokens":512,"top_p":1,"reasoning_effort":"high"}`,
+			dropped:  []string{"temperature", "presence_penalty", "logprobs"},
+		},
+		{
+			name:     "openai chat model keeps sampling and reasoning_effort",
+			provider: constants.OpenaiID,
+			body:     `{"model":"gpt-4o","max_tokens":512,"temperature":0.2,"reasoning_effort":"low"}`,
+			expected: `{"model":"gpt-4o","max_tokens":512,"temperature":0.2,"reasoning_effort":"low"}`,
+		},
+		{
+			name:     "minimal effort becomes low",
+			provider: constants.GroqID,
+			body:     `{"model":"openai/gpt-oss-120b","max_completion_tokens":256,"reasoning_effort":"minimal"}`,
+			expected: `{"model":"openai/gpt-oss-120b","max_completion_tokens":256,"reasoning_effort":"low"}`,
+		},
+		{
+			name:     "max_completion_tokens becomes max_tokens",
+			provider: constants.OllamaID,
+			body:     `{"model":"deepseek-r1:8b","max_completion_tokens":256,"reasoning_effort":"medium"}`,
+			expected: `{"model":"deepseek-r1:8b","max_tokens":256,"reasoning_effort":"medium"}`,
+		},
+		{
+			name:     "deepseek reasoner rejects logprobs",
+			provider: constants.DeepseekID,
+			body:     `{"model":"deepseek-reasoner","max_completion_tokens":256,"logprobs":true,"top_logprobs":2,"reasoning_effort":"high"}`,
+			expected: `{"model":"deepseek-reasoner","max_tokens":256}`,
+			dropped:  []string{"logprobs", "reasoning_effort"},
+		},
+		{
+			name:     "deepseek drops the reasoning of past turns only",
+			provider: constants.DeepseekID,
+			body: `{"model":"deepseek-reasoner","messages":[
+				{"role":"user","content":"9.11 or 9.8?"},
+				{"role":"assistant","content":"9.8","reasoning_content":"Compare the decimals."},
+				{"role":"user","content":"Weather in Paris?"},
+				{"role":"assistant","content":"","reasoning_content":"Call the tool.","tool_calls":[{"id":"c1","type":"function","function":{"name":"weather","arguments":"{}"}}]},
+				{"role":"tool","tool_call_id":"c1","content":"sunny"}]}`,
+			expected: `{"model":"deepseek-reasoner","messages":[
+				{"role":"user","content":"9.11 or 9.8?"},
+				{"role":"assistant","content":"9.8"},
+				{"role":"user","content":"Weather in Paris?"},
+				{"role":"assistant","content":"","reasoning_content":"Call the tool.","tool_calls":[{"id":"c1","type":"function","function":{"name":"weather","arguments":"{}"}}]},
+				{"role":"tool","tool_call_id":"c1","content":"sunny"}]}`,
+		},
+		{
+			name:     "explicit max_tokens wins",
+			provider: constants.MistralID,
+			body:     `{"model":"magistral-medium-latest","max_tokens":100,"max_completion_tokens":256}`,
+			expected: `{"model":"magistral-medium-latest","max_tokens":100}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req types.CreateChatCompletionRequest
+			if err := json.Unmarshal([]byte(tt.body), &req); err != nil {
+				t.Fatalf("invalid request: %v", err)
+			}
+			dropped := adaptReasoningParams(tt.provider, &req)
+			if !slices.Equal(dropped, tt.dropped) {
+				t.Errorf("dropped %v, expected %v", dropped, tt.dropped)
+			}
+
+			var expected types.CreateChatCompletionRequest
+			if err := json.Unmarshal([]byte(tt.expected), &expected); err != nil {
+				t.Fatalf("invalid expected request: %v", err)
+			}
+			got, _ := json.Marshal(req)
+			want, _ := json.Marshal(expected)
+			if string(got) != string(want) {
+				t.Errorf("got %s, expected %s", got, want)
+			}
+		})
+	}
+}
+
+func TestIsOpenAIReasoningModel(t *testing.T) {
+	for model, expected := range map[string]bool{
+		"o1":                 true,
+		"o3-mini":            true,
+		"o4-mini-2025-04-16": true,
+		"gpt-5":              true,
+		"gpt-5-mini":         true,
+		"gpt-5-chat-latest":  false,
+		"gpt-4o":             false,
+		"gpt-4.1-mini":       false,
+	} {
+		if got := isOpenAIReasoningModel(model); got != expected {
+			t.Errorf("isOpenAIReasoningModel(%q) = %v, expected %v", model, got, expected)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"maps"
+	"slices"
+
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// geminiHarmCategories maps the normalized safety categories to Gemini harm categories
+var geminiHarmCategories = map[string]string{
+	"harassment": "HARM_CATEGORY_HARASSMENT",
+	"hate":       "HARM_CATEGORY_HATE_SPEECH",
+	"sexual":     "HARM_CATEGORY_SEXUALLY_EXPLICIT",
+	"dangerous":  "HARM_CATEGORY_DANGEROUS_CONTENT",
+}
+
+// geminiThresholds maps the normalized safety levels to Gemini block thresholds
+var geminiThresholds = map[types.SafetyLevel]string{
+	types.SafetyOff:    "BLOCK_NONE",
+	types.SafetyLow:    "BLOCK_ONLY_HIGH",
+	types.SafetyMedium: "BLOCK_MEDIUM_AND_ABOVE",
+	types.SafetyHigh:   "BLOCK_LOW_AND_ABOVE",
+}
+
+// geminiSafetySetting is a single entry of Gemini's safety_settings
+type geminiSafetySetting struct {
+	Category  string `json:"category"`
+	Threshold string `json:"threshold"`
+}
+
+// marshalChatRequest encodes a chat completions request for the provider.
+// The normalized safety_settings field is never forwarded verbatim: Google
+// receives it as native safety settings through the extra_body extension of
+// its OpenAI-compatible API, Mistral as its safe_prompt guardrail, every
+// other provider has it dropped (the API layer applies it there before the
+// request reaches the provider). Of
+// extra_body, only the allowed fields of the provider's entry are forwarded;
+// agent_budget is for the gateway's agent loop alone and mcp_prompt is
+// expanded by the MCP middleware.
+func (p *ProviderImpl) marshalChatRequest(clientReq types.CreateChatCompletionRequest) ([]byte, error) {
+	settings := clientReq.SafetySettings
+	clientReq.SafetySettings = nil
+	clientReq.AgentBudget = nil
+	clientReq.MCPPrompt = nil
+	extra := extraBodyFor(*p.GetID(), clientReq.ExtraBody)
+	if settings == nil || *p.GetID() != constants.GoogleID && *p.GetID() != constants.MistralID {
+		return marshalWithExtraBody(*p.GetID(), clientReq, extra)
+	}
+
+	levels, err := settings.Levels()
+	if err != nil {
+		return nil, err
+	}
+	if *p.GetID() == constants.MistralID {
+		return marshalWithExtraBody(*p.GetID(), clientReq, mistralSafePrompt(levels, extra))
+	}
+	var safetySettings []geminiSafetySetting
+	for _, category := range types.SafetyCategories {
+		if level, ok := levels[category]; ok {
+			safetySettings = append(safetySettings, geminiSafetySetting{
+				Category:  geminiHarmCategories[category],
+				Threshold: geminiThresholds[level],
+			})
+		}
+	}
+	if len(safetySettings) > 0 {
+		if extra == nil {
+			extra = make(map[string]any)
+		}
+		extra["safety_settings"] = safetySettings
+	}
+	return marshalWithExtraBody(*p.GetID(), clientReq, extra)
+}
+
+// mistralSafePrompt sets Mistral's safe_prompt, which prepends its guardrail
+// system prompt, when any category is above off. Mistral has no per-category
+// settings, and a safe_prompt passed in extra_body is kept.
+func mistralSafePrompt(levels map[string]types.SafetyLevel, extra map[string]any) map[string]any {
+	if _, ok := extra["safe_prompt"]; ok || len(levels) == 0 {
+		return extra
+	}
+	if extra == nil {
+		extra = make(map[string]any)
+	}
+	extra["safe_prompt"] = slices.ContainsFunc(slices.Collect(maps.Values(levels)), func(level types.SafetyLevel) bool {
+		return level != types.SafetyOff
+	})
+	return extra
+}
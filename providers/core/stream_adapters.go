@@ -0,0 +1,365 @@
+package core
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// streamAdapter converts a provider's native stream framing into OpenAI chat
+// completion chunks, so the streaming path relays uniform SSE whatever the
+// upstream sent
+type streamAdapter interface {
+	// adapt converts one upstream line into the SSE lines to relay
+	adapt(line []byte) [][]byte
+	// finish returns the lines ending the stream once the upstream is done
+	finish() [][]byte
+}
+
+// streamAdapters lists the providers whose streams may need converting. A
+// constructor returns nil when the upstream response, judged by its
+// Content-Type, already streams OpenAI SSE.
+var streamAdapters = map[types.Provider]func(contentType, model string) streamAdapter{
+	constants.CohereID:      newCohereStreamAdapter,
+	constants.OllamaID:      newOllamaStreamAdapter,
+	constants.OllamaCloudID: newOllamaStreamAdapter,
+}
+
+// newStreamAdapter returns the stream adapter of provider for a response with
+// contentType, or nil when its lines are relayed as they are
+func newStreamAdapter(provider types.Provider, contentType, model string) streamAdapter {
+	newAdapter, ok := streamAdapters[provider]
+	if !ok {
+		return nil
+	}
+	return newAdapter(contentType, model)
+}
+
+// adaptedChunk is a chat completion chunk built by an adapter. Unlike the
+// generated types it reports a null finish_reason until the last chunk and
+// omits empty delta fields, as OpenAI does.
+type adaptedChunk struct {
+	ID      string                 `json:"id"`
+	Object  string                 `json:"object"`
+	Created int64                  `json:"created"`
+	Model   string                 `json:"model"`
+	Choices []adaptedChoice        `json:"choices"`
+	Usage   *types.CompletionUsage `json:"usage,omitempty"`
+}
+
+type adaptedChoice struct {
+	Index        int                 `json:"index"`
+	Delta        adaptedDelta        `json:"delta"`
+	FinishReason *types.FinishReason `json:"finish_reason"`
+}
+
+type adaptedDelta struct {
+	Role             types.MessageRole                          `json:"role,omitempty"`
+	Content          string                                     `json:"content,omitempty"`
+	ReasoningContent string                                     `json:"reasoning_content,omitempty"`
+	ToolCalls        []types.ChatCompletionMessageToolCallChunk `json:"tool_calls,omitempty"`
+}
+
+// chunkBuilder writes the chunks of one adapted stream, which share an ID,
+// creation time and model
+type chunkBuilder struct {
+	id      string
+	created int64
+	model   string
+	started bool
+	done    bool
+}
+
+func newChunkBuilder(model string) chunkBuilder {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return chunkBuilder{id: "chatcmpl-" + hex.EncodeToString(b), created: time.Now().Unix(), model: model}
+}
+
+// chunk returns the SSE line of a chunk. The first chunk of a stream carries
+// the assistant role.
+func (b *chunkBuilder) chunk(delta adaptedDelta, finishReason *types.FinishReason, usage *types.CompletionUsage) []byte {
+	if !b.started {
+		delta.Role = types.Assistant
+		b.started = true
+	}
+	data, _ := json.Marshal(adaptedChunk{
+		ID:      b.id,
+		Object:  "chat.completion.chunk",
+		Created: b.created,
+		Model:   b.model,
+		Choices: []adaptedChoice{{Delta: delta, FinishReason: finishReason}},
+		Usage:   usage,
+	})
+	return sseLine(data)
+}
+
+// errorLine returns the SSE line of an upstream error, in the shape OpenAI
+// streams errors
+func (b *chunkBuilder) errorLine(message string) []byte {
+	data, _ := json.Marshal(map[string]any{"error": map[string]string{"message": message, "type": "upstream_error"}})
+	return sseLine(data)
+}
+
+// finish returns the [DONE] line unless the stream already ended
+func (b *chunkBuilder) finish() [][]byte {
+	if b.done {
+		return nil
+	}
+	b.done = true
+	return [][]byte{[]byte("data: [DONE]\n\n")}
+}
+
+// newToolCallID returns an ID for a tool call the provider did not name
+func newToolCallID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return "call_" + hex.EncodeToString(b)
+}
+
+func sseLine(data []byte) []byte {
+	return append(append([]byte("data: "), data...), '\n', '\n')
+}
+
+// ollamaStreamAdapter converts Ollama's native NDJSON chat stream, one JSON
+// object per line, as served by /api/chat
+type ollamaStreamAdapter struct {
+	chunkBuilder
+	toolCalls int
+}
+
+type ollamaStreamLine struct {
+	Message struct {
+		Content   string `json:"content"`
+		Thinking  string `json:"thinking"`
+		ToolCalls []struct {
+			Function struct {
+				Name      string          `json:"name"`
+				Arguments json.RawMessage `json:"arguments"`
+			} `json:"function"`
+		} `json:"tool_calls"`
+	} `json:"message"`
+	Done            bool   `json:"done"`
+	DoneReason      string `json:"done_reason"`
+	PromptEvalCount int64  `json:"prompt_eval_count"`
+	EvalCount       int64  `json:"eval_count"`
+	Error           string `json:"error"`
+}
+
+func newOllamaStreamAdapter(contentType, model string) streamAdapter {
+	if !strings.HasPrefix(contentType, "application/x-ndjson") {
+		return nil
+	}
+	return &ollamaStreamAdapter{chunkBuilder: newChunkBuilder(model)}
+}
+
+func (a *ollamaStreamAdapter) adapt(line []byte) [][]byte {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || a.done {
+		return nil
+	}
+	var msg ollamaStreamLine
+	if err := json.Unmarshal(line, &msg); err != nil {
+		return nil
+	}
+	if msg.Error != "" {
+		return append([][]byte{a.errorLine(msg.Error)}, a.chunkBuilder.finish()...)
+	}
+
+	delta := adaptedDelta{Content: msg.Message.Content, ReasoningContent: msg.Message.Thinking}
+	for _, call := range msg.Message.ToolCalls {
+		id := newToolCallID()
+		arguments := string(call.Function.Arguments)
+		if arguments == "" || arguments == "null" {
+			arguments = "{}"
+		}
+		delta.ToolCalls = append(delta.ToolCalls, types.ChatCompletionMessageToolCallChunk{
+			Index:    a.toolCalls,
+			ID:       &id,
+			Type:     new(string(types.Function)),
+			Function: &types.ChatCompletionMessageToolCallFunction{Name: call.Function.Name, Arguments: arguments},
+		})
+		a.toolCalls++
+	}
+
+	if !msg.Done {
+		if delta.Content == "" && delta.ReasoningContent == "" && len(delta.ToolCalls) == 0 {
+			return nil
+		}
+		return [][]byte{a.chunk(delta, nil, nil)}
+	}
+
+	reason := types.Stop
+	switch {
+	case a.toolCalls > 0:
+		reason = types.ToolCalls
+	case msg.DoneReason == "length":
+		reason = types.Length
+	}
+	usage := &types.CompletionUsage{
+		PromptTokens:     msg.PromptEvalCount,
+		CompletionTokens: msg.EvalCount,
+		TotalTokens:      msg.PromptEvalCount + msg.EvalCount,
+	}
+	return append([][]byte{a.chunk(delta, &reason, usage)}, a.chunkBuilder.finish()...)
+}
+
+func (a *ollamaStreamAdapter) finish() [][]byte {
+	return a.chunkBuilder.finish()
+}
+
+// cohereStreamAdapter converts the events of Cohere's native v2 chat stream
+// (message-start, content-delta, tool-call-*, message-end). Lines of its
+// OpenAI-compatible API pass through unchanged.
+type cohereStreamAdapter struct {
+	chunkBuilder
+	native    bool
+	toolCalls int
+}
+
+type cohereStreamEvent struct {
+	Type  string `json:"type"`
+	ID    string `json:"id"`
+	Delta struct {
+		Message struct {
+			Content struct {
+				Text     string `json:"text"`
+				Thinking string `json:"thinking"`
+			} `json:"content"`
+			ToolCalls struct {
+				ID       string `json:"id"`
+				Function struct {
+					Name      string `json:"name"`
+					Arguments string `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
+		Usage        struct {
+			Tokens struct {
+				InputTokens  int64 `json:"input_tokens"`
+				OutputTokens int64 `json:"output_tokens"`
+			} `json:"tokens"`
+		} `json:"usage"`
+		Error string `json:"error"`
+	} `json:"delta"`
+}
+
+// cohereEventTypes lists the event types of Cohere's native stream
+var cohereEventTypes = map[string]bool{
+	"message-start":   true,
+	"content-start":   true,
+	"content-delta":   true,
+	"content-end":     true,
+	"tool-plan-delta": true,
+	"tool-call-start": true,
+	"tool-call-delta": true,
+	"tool-call-end":   true,
+	"citation-start":  true,
+	"citation-end":    true,
+	"message-end":     true,
+}
+
+// cohereFinishReasons maps Cohere's finish reasons to OpenAI's
+var cohereFinishReasons = map[string]types.FinishReason{
+	"COMPLETE":      types.Stop,
+	"STOP_SEQUENCE": types.Stop,
+	"MAX_TOKENS":    types.Length,
+	"TOOL_CALL":     types.ToolCalls,
+}
+
+func newCohereStreamAdapter(_, model string) streamAdapter {
+	return &cohereStreamAdapter{chunkBuilder: newChunkBuilder(model)}
+}
+
+func (a *cohereStreamAdapter) adapt(line []byte) [][]byte {
+	trimmed := bytes.TrimSpace(line)
+	if bytes.HasPrefix(trimmed, []byte("event:")) {
+		return nil
+	}
+	data, _ := bytes.CutPrefix(trimmed, []byte("data:"))
+	data = bytes.TrimSpace(data)
+
+	var event cohereStreamEvent
+	if !bytes.HasPrefix(data, []byte("{")) || !decodeCohereEvent(data, &event) || !cohereEventTypes[event.Type] {
+		// Blank lines only separate the native events, which are relayed
+		// with their own separators
+		if a.native && len(trimmed) == 0 {
+			return nil
+		}
+		return [][]byte{line}
+	}
+	a.native = true
+	if a.done {
+		return nil
+	}
+
+	switch event.Type {
+	case "message-start":
+		if event.ID != "" {
+			a.id = event.ID
+		}
+		return [][]byte{a.chunk(adaptedDelta{}, nil, nil)}
+	case "content-delta":
+		content := event.Delta.Message.Content
+		if content.Text == "" && content.Thinking == "" {
+			return nil
+		}
+		return [][]byte{a.chunk(adaptedDelta{Content: content.Text, ReasoningContent: content.Thinking}, nil, nil)}
+	case "tool-call-start", "tool-call-delta":
+		call := event.Delta.Message.ToolCalls
+		toolCall := types.ChatCompletionMessageToolCallChunk{
+			Index:    a.toolCalls,
+			Function: &types.ChatCompletionMessageToolCallFunction{Name: call.Function.Name, Arguments: call.Function.Arguments},
+		}
+		if event.Type == "tool-call-start" {
+			toolCall.ID = &call.ID
+			toolCall.Type = new(string(types.Function))
+		}
+		return [][]byte{a.chunk(adaptedDelta{ToolCalls: []types.ChatCompletionMessageToolCallChunk{toolCall}}, nil, nil)}
+	case "tool-call-end":
+		a.toolCalls++
+		return nil
+	case "message-end":
+		if event.Delta.Error != "" {
+			return append([][]byte{a.errorLine(event.Delta.Error)}, a.chunkBuilder.finish()...)
+		}
+		reason, ok := cohereFinishReasons[event.Delta.FinishReason]
+		if !ok {
+			reason = types.Stop
+		}
+		tokens := event.Delta.Usage.Tokens
+		usage := &types.CompletionUsage{
+			PromptTokens:     tokens.InputTokens,
+			CompletionTokens: tokens.OutputTokens,
+			TotalTokens:      tokens.InputTokens + tokens.OutputTokens,
+		}
+		return append([][]byte{a.chunk(adaptedDelta{}, &reason, usage)}, a.chunkBuilder.finish()...)
+	}
+	return nil
+}
+
+// decodeCohereEvent decodes a native event. message-start carries the
+// message content as an array, which is skipped rather than failing the
+// event.
+func decodeCohereEvent(data []byte, event *cohereStreamEvent) bool {
+	err := json.Unmarshal(data, event)
+	var typeErr *json.UnmarshalTypeError
+	return err == nil || errors.As(err, &typeErr)
+}
+
+// finish only ends native streams; the OpenAI-compatible API sends its own
+// [DONE]
+func (a *cohereStreamAdapter) finish() [][]byte {
+	if !a.native {
+		return nil
+	}
+	return a.chunkBuilder.finish()
+}
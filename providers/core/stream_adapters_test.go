@@ -0,0 +1,163 @@
+package core
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+)
+
+// runAdapter feeds upstream lines through an adapter and decodes the chunks
+// it relays, returning them with whether the stream ended with [DONE]
+func runAdapter(t *testing.T, a streamAdapter, lines ...string) ([]map[string]any, bool) {
+	t.Helper()
+	var relayed [][]byte
+	for _, line := range lines {
+		relayed = append(relayed, a.adapt([]byte(line))...)
+	}
+	relayed = append(relayed, a.finish()...)
+
+	var chunks []map[string]any
+	done := false
+	for _, line := range relayed {
+		data, ok := strings.CutPrefix(string(line), "data: ")
+		if !ok || !strings.HasSuffix(data, "\n\n") {
+			t.Fatalf("relayed line is not an SSE event: %q", line)
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			done = true
+			continue
+		}
+		var chunk map[string]any
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			t.Fatalf("invalid chunk %q: %v", data, err)
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, done
+}
+
+func delta(chunk map[string]any) map[string]any {
+	choices, _ := chunk["choices"].([]any)
+	if len(choices) == 0 {
+		return nil
+	}
+	d, _ := choices[0].(map[string]any)["delta"].(map[string]any)
+	return d
+}
+
+func finishReason(chunk map[string]any) any {
+	return chunk["choices"].([]any)[0].(map[string]any)["finish_reason"]
+}
+
+func TestOllamaStreamAdapter(t *testing.T) {
+	if newStreamAdapter(constants.OllamaID, "text/event-stream", "llama3") != nil {
+		t.Fatal("OpenAI-compatible SSE responses must be relayed as they are")
+	}
+	a := newStreamAdapter(constants.OllamaID, "application/x-ndjson", "llama3")
+	if a == nil {
+		t.Fatal("NDJSON responses must be adapted")
+	}
+
+	chunks, done := runAdapter(t, a,
+		`{"model":"llama3","message":{"role":"assistant","content":"Hel"},"done":false}`+"\n",
+		`{"model":"llama3","message":{"role":"assistant","content":"lo"},"done":false}`+"\n",
+		`{"model":"llama3","message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"weather","arguments":{"city":"Paris"}}}]},"done":false}`+"\n",
+		`{"model":"llama3","message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","prompt_eval_count":7,"eval_count":3}`,
+	)
+	if !done {
+		t.Error("the stream must end with [DONE]")
+	}
+	if len(chunks) != 4 {
+		t.Fatalf("expected 4 chunks, got %d: %v", len(chunks), chunks)
+	}
+	if d := delta(chunks[0]); d["role"] != "assistant" || d["content"] != "Hel" {
+		t.Errorf("unexpected first delta: %v", d)
+	}
+	if finishReason(chunks[0]) != nil {
+		t.Error("intermediate chunks must have a null finish_reason")
+	}
+	if chunks[0]["id"] != chunks[3]["id"] || chunks[0]["object"] != "chat.completion.chunk" || chunks[0]["model"] != "llama3" {
+		t.Errorf("chunks must share the envelope: %v, %v", chunks[0], chunks[3])
+	}
+	calls := delta(chunks[2])["tool_calls"].([]any)
+	function := calls[0].(map[string]any)["function"].(map[string]any)
+	if function["name"] != "weather" || function["arguments"] != `{"city":"Paris"}` {
+		t.Errorf("tool call arguments must be a JSON string: %v", function)
+	}
+	if finishReason(chunks[3]) != "tool_calls" {
+		t.Errorf("streams that called tools must finish with tool_calls, got %v", finishReason(chunks[3]))
+	}
+	usage := chunks[3]["usage"].(map[string]any)
+	if usage["prompt_tokens"] != float64(7) || usage["completion_tokens"] != float64(3) || usage["total_tokens"] != float64(10) {
+		t.Errorf("unexpected usage: %v", usage)
+	}
+}
+
+func TestOllamaStreamAdapterError(t *testing.T) {
+	a := newStreamAdapter(constants.OllamaID, "application/x-ndjson", "llama3")
+	chunks, done := runAdapter(t, a, `{"error":"model not found"}`)
+	if !done || len(chunks) != 1 {
+		t.Fatalf("expected an error chunk and [DONE], got %v, %v", chunks, done)
+	}
+	if msg := chunks[0]["error"].(map[string]any)["message"]; msg != "model not found" {
+		t.Errorf("unexpected error message: %v", msg)
+	}
+}
+
+func TestCohereStreamAdapter(t *testing.T) {
+	a := newStreamAdapter(constants.CohereID, "text/event-stream", "command-r")
+	chunks, done := runAdapter(t, a,
+		"event: message-start\n",
+		`data: {"type":"message-start","id":"msg-1","delta":{"message":{"role":"assistant"}}}`+"\n",
+		"\n",
+		`data: {"type":"content-delta","index":0,"delta":{"message":{"content":{"text":"Hi"}}}}`+"\n",
+		"\n",
+		`data: {"type":"tool-call-start","index":0,"delta":{"message":{"tool_calls":{"id":"tc-1","type":"function","function":{"name":"weather","arguments":""}}}}}`+"\n",
+		`data: {"type":"tool-call-delta","index":0,"delta":{"message":{"tool_calls":{"function":{"arguments":"{}"}}}}}`+"\n",
+		`data: {"type":"tool-call-end","index":0}`+"\n",
+		`data: {"type":"message-end","delta":{"finish_reason":"TOOL_CALL","usage":{"tokens":{"input_tokens":5,"output_tokens":2}}}}`+"\n",
+	)
+	if !done {
+		t.Error("native streams must end with [DONE]")
+	}
+	if len(chunks) != 5 {
+		t.Fatalf("expected 5 chunks, got %d: %v", len(chunks), chunks)
+	}
+	if chunks[0]["id"] != "msg-1" || delta(chunks[0])["role"] != "assistant" {
+		t.Errorf("unexpected first chunk: %v", chunks[0])
+	}
+	if delta(chunks[1])["content"] != "Hi" {
+		t.Errorf("unexpected content delta: %v", delta(chunks[1]))
+	}
+	start := delta(chunks[2])["tool_calls"].([]any)[0].(map[string]any)
+	if start["id"] != "tc-1" || start["function"].(map[string]any)["name"] != "weather" {
+		t.Errorf("unexpected tool call start: %v", start)
+	}
+	if finishReason(chunks[4]) != "tool_calls" || chunks[4]["usage"].(map[string]any)["total_tokens"] != float64(7) {
+		t.Errorf("unexpected last chunk: %v", chunks[4])
+	}
+}
+
+func TestCohereStreamAdapterPassesThroughCompatibilityStream(t *testing.T) {
+	a := newStreamAdapter(constants.CohereID, "text/event-stream", "command-r")
+	lines := []string{
+		`data: {"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"Hi","tool_calls":[{"type":"function"}]}}]}` + "\n",
+		"\n",
+		"data: [DONE]\n",
+	}
+	var relayed []string
+	for _, line := range lines {
+		for _, out := range a.adapt([]byte(line)) {
+			relayed = append(relayed, string(out))
+		}
+	}
+	if len(a.finish()) != 0 {
+		t.Error("compatibility streams send their own [DONE]")
+	}
+	if strings.Join(relayed, "") != strings.Join(lines, "") {
+		t.Errorf("compatibility lines must be relayed unchanged, got %q", relayed)
+	}
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	client "github.com/inference-gateway/inference-gateway/providers/client"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+	vcr "github.com/inference-gateway/inference-gateway/tests/vcr"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the stream tests")
+
+// goldenStreams are the providers whose streams are replayed from
+// testdata/cassettes and compared with testdata/streams, one per framing the
+// gateway handles: OpenAI SSE, Anthropic's compatible SSE, Ollama NDJSON and
+// Cohere's native events
+var goldenStreams = []struct {
+	provider types.Provider
+	endpoint string
+	model    string
+}{
+	{constants.OpenaiID, constants.OpenaiChatEndpoint, "gpt-4o-mini"},
+	{constants.AnthropicID, constants.AnthropicChatEndpoint, "claude-sonnet-4-20250514"},
+	{constants.OllamaID, constants.OllamaChatEndpoint, "llama3.2"},
+	{constants.CohereID, constants.CohereChatEndpoint, "command-r-plus"},
+}
+
+// generatedFields match the values the adapters generate per stream, which
+// the golden files hold as placeholders
+var generatedFields = []struct {
+	pattern     *regexp.Regexp
+	placeholder string
+}{
+	{regexp.MustCompile(`"chatcmpl-[0-9a-f]{24}"`), `"chatcmpl-ID"`},
+	{regexp.MustCompile(`"call_[0-9a-f]{24}"`), `"call_ID"`},
+	{regexp.MustCompile(`"created":\d+`), `"created":0`},
+}
+
+func TestStreamChatCompletions_Golden(t *testing.T) {
+	for _, tt := range goldenStreams {
+		t.Run(string(tt.provider), func(t *testing.T) {
+			id := tt.provider
+			provider := &ProviderImpl{
+				ID:        &id,
+				Name:      string(id),
+				Endpoints: types.Endpoints{Chat: tt.endpoint},
+				Client:    vcr.Start(t, filepath.Join("testdata", "cassettes", string(id)+".yaml"), gatewayClient(t)),
+				Logger:    logger.NewNoopLogger(),
+			}
+
+			message := types.Message{Role: types.User}
+			if err := message.Content.FromMessageContent0("Say hello"); err != nil {
+				t.Fatal(err)
+			}
+			stream, err := provider.StreamChatCompletions(context.Background(), types.CreateChatCompletionRequest{
+				Model:    tt.model,
+				Messages: []types.Message{message},
+			})
+			if err != nil {
+				t.Fatalf("stream: %v", err)
+			}
+			var got []byte
+			for line := range stream {
+				got = append(got, line...)
+			}
+			for _, field := range generatedFields {
+				got = field.pattern.ReplaceAll(got, []byte(field.placeholder))
+			}
+
+			golden := filepath.Join("testdata", "streams", string(id)+".golden")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("read golden file, run the test with -update to create it: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("stream differs from %s\ngot:\n%s\nwant:\n%s", golden, got, want)
+			}
+		})
+	}
+}
+
+// gatewayClient returns the client recording sends the requests with, to the
+// /proxy route of a gateway running locally with the provider keys
+func gatewayClient(t *testing.T) client.Client {
+	t.Helper()
+	if vcr.Mode(os.Getenv(vcr.ModeEnv)) != vcr.ModeRecord {
+		return nil
+	}
+	c, err := client.NewHTTPClient(&client.ClientConfig{}, "http", "localhost", "8080")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
@@ -0,0 +1,304 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// toolRules describes how a provider's OpenAI-compatible API deviates from
+// OpenAI function calling
+type toolRules struct {
+	// invalidNameChars matches the characters not allowed in tool names
+	invalidNameChars *regexp.Regexp
+	// maxNameLength is the maximum length of a tool name
+	maxNameLength int
+	// objectParameters requires every tool to declare an object schema
+	objectParameters bool
+	// dropStrict removes the unsupported strict flag from tool definitions
+	dropStrict bool
+	// stringToolResults requires tool result content to be a plain string
+	stringToolResults bool
+	// namedToolChoice reports whether tool_choice may name a single function;
+	// without it the choice becomes "required" over that function alone
+	namedToolChoice bool
+	// toolCallIDLength requires the tool call IDs of the history to be that
+	// many alphanumeric characters; other IDs are replaced by a hash of them
+	toolCallIDLength int
+}
+
+const alphanumeric = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+var nonAlphanumeric = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+// providerToolRules lists the providers whose tool calling needs translation
+var providerToolRules = map[types.Provider]toolRules{
+	constants.AnthropicID: {
+		invalidNameChars: regexp.MustCompile(`[^a-zA-Z0-9_-]`),
+		maxNameLength:    64,
+		objectParameters: true,
+		dropStrict:       true,
+		namedToolChoice:  true,
+	},
+	constants.CohereID: {
+		invalidNameChars:  regexp.MustCompile(`[^a-zA-Z0-9_]`),
+		maxNameLength:     64,
+		objectParameters:  true,
+		dropStrict:        true,
+		stringToolResults: true,
+	},
+	constants.MistralID: {
+		invalidNameChars: regexp.MustCompile(`[^a-zA-Z0-9_-]`),
+		maxNameLength:    64,
+		objectParameters: true,
+		namedToolChoice:  true,
+		toolCallIDLength: 9,
+	},
+}
+
+// toolTranslation converts the tools of one request to a provider's format
+// and restores the tool calls of its response. A nil translation is a no-op.
+type toolTranslation struct {
+	// toProvider and toClient map renamed tool names in both directions
+	toProvider map[string]string
+	toClient   map[string]string
+	// sawToolCalls tracks whether a stream has emitted tool calls so its
+	// final finish reason can be corrected
+	sawToolCalls bool
+}
+
+// translateTools rewrites the tool definitions, tool choice and tool call
+// history of req for provider. The caller's slices are left untouched. It
+// returns nil when the provider needs no translation.
+func translateTools(provider types.Provider, req *types.CreateChatCompletionRequest) *toolTranslation {
+	rules, ok := providerToolRules[provider]
+	if !ok || req.Tools == nil && !hasToolHistory(req.Messages) {
+		return nil
+	}
+
+	t := &toolTranslation{
+		toProvider: make(map[string]string),
+		toClient:   make(map[string]string),
+	}
+
+	if req.Tools != nil {
+		tools := slices.Clone(*req.Tools)
+		for i := range tools {
+			fn := &tools[i].Function
+			fn.Name = t.rename(rules, fn.Name)
+			if rules.dropStrict {
+				fn.Strict = nil
+			}
+			if rules.objectParameters {
+				fn.Parameters = objectSchema(fn.Parameters)
+			}
+		}
+		req.Tools = &tools
+	}
+
+	if req.ToolChoice != nil {
+		if named, err := req.ToolChoice.AsChatCompletionNamedToolChoice(); err == nil && named.Function.Name != "" {
+			named.Function.Name = t.rename(rules, named.Function.Name)
+			var choice types.ChatCompletionToolChoiceOption
+			if rules.namedToolChoice {
+				_ = choice.FromChatCompletionNamedToolChoice(named)
+			} else {
+				_ = choice.FromChatCompletionToolChoiceOption0(types.ChatCompletionToolChoiceOption0Required)
+				if req.Tools != nil {
+					tools := slices.DeleteFunc(slices.Clone(*req.Tools), func(tool types.ChatCompletionTool) bool {
+						return tool.Function.Name != named.Function.Name
+					})
+					req.Tools = &tools
+				}
+			}
+			req.ToolChoice = &choice
+		}
+	}
+
+	messages := slices.Clone(req.Messages)
+	for i := range messages {
+		msg := &messages[i]
+		if msg.ToolCalls != nil {
+			toolCalls := slices.Clone(*msg.ToolCalls)
+			for j := range toolCalls {
+				toolCalls[j].Function.Name = t.rename(rules, toolCalls[j].Function.Name)
+				toolCalls[j].ID = toolCallID(rules, toolCalls[j].ID)
+			}
+			msg.ToolCalls = &toolCalls
+		}
+		if msg.ToolCallID != nil {
+			id := toolCallID(rules, *msg.ToolCallID)
+			msg.ToolCallID = &id
+		}
+		if msg.Role == types.Tool && rules.stringToolResults {
+			if _, err := msg.Content.AsMessageContent0(); err != nil {
+				_ = msg.Content.FromMessageContent0(msg.TextContent())
+			}
+		}
+	}
+	req.Messages = messages
+
+	return t
+}
+
+func hasToolHistory(messages []types.Message) bool {
+	return slices.ContainsFunc(messages, func(msg types.Message) bool {
+		return msg.ToolCalls != nil || msg.Role == types.Tool
+	})
+}
+
+// rename returns the provider-safe name of a client tool name, keeping the
+// mapping so tool calls can be restored. Colliding names get a numeric suffix.
+func (t *toolTranslation) rename(rules toolRules, name string) string {
+	if renamed, ok := t.toProvider[name]; ok {
+		return renamed
+	}
+	base := rules.invalidNameChars.ReplaceAllString(name, "_")
+	if base == "" {
+		base = "tool"
+	}
+	renamed := truncate(base, rules.maxNameLength)
+	for i := 2; ; i++ {
+		if original, taken := t.toClient[renamed]; !taken || original == name {
+			break
+		}
+		suffix := fmt.Sprintf("_%d", i)
+		renamed = truncate(base, rules.maxNameLength-len(suffix)) + suffix
+	}
+	t.toProvider[name] = renamed
+	t.toClient[renamed] = name
+	return renamed
+}
+
+// toolCallID returns the ID of a tool call of the history as the provider
+// accepts it. IDs of other providers, e.g. OpenAI's call_..., are replaced by
+// a hash so a tool call and its result keep matching IDs.
+func toolCallID(rules toolRules, id string) string {
+	if rules.toolCallIDLength == 0 || len(id) == rules.toolCallIDLength && !nonAlphanumeric.MatchString(id) {
+		return id
+	}
+	sum := sha256.Sum256([]byte(id))
+	hashed := make([]byte, rules.toolCallIDLength)
+	for i := range hashed {
+		hashed[i] = alphanumeric[int(sum[i%len(sum)])%len(alphanumeric)]
+	}
+	return string(hashed)
+}
+
+func truncate(s string, n int) string {
+	if n > 0 && len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+// objectSchema returns params as an object schema, which providers with
+// native tool input schemas require even for tools without arguments
+func objectSchema(params *types.FunctionParameters) *types.FunctionParameters {
+	schema := types.FunctionParameters{}
+	if params != nil {
+		for k, v := range *params {
+			schema[k] = v
+		}
+	}
+	if _, ok := schema["type"]; !ok {
+		schema["type"] = "object"
+	}
+	if _, ok := schema["properties"]; !ok && schema["type"] == "object" {
+		schema["properties"] = map[string]any{}
+	}
+	return &schema
+}
+
+// clientName returns the name the client used for a provider tool name
+func (t *toolTranslation) clientName(name string) string {
+	if original, ok := t.toClient[name]; ok {
+		return original
+	}
+	return name
+}
+
+// restoreResponse maps the tool calls of resp back to the client's tool names,
+// normalizes empty arguments to "{}" and reports tool_calls instead of stop as
+// finish reason whenever a choice carries tool calls.
+func (t *toolTranslation) restoreResponse(resp *types.CreateChatCompletionResponse) {
+	if t == nil {
+		return
+	}
+	for i := range resp.Choices {
+		choice := &resp.Choices[i]
+		if choice.Message.ToolCalls == nil || len(*choice.Message.ToolCalls) == 0 {
+			continue
+		}
+		for j := range *choice.Message.ToolCalls {
+			fn := &(*choice.Message.ToolCalls)[j].Function
+			fn.Name = t.clientName(fn.Name)
+			if fn.Arguments == "" {
+				fn.Arguments = "{}"
+			}
+		}
+		if choice.FinishReason == types.Stop || choice.FinishReason == "" {
+			choice.FinishReason = types.ToolCalls
+		}
+	}
+}
+
+// restoreChunk maps the tool calls of a single SSE line back to the client's
+// tool names and corrects the finish reason of streams that called tools.
+// Lines without tool calls or a finish reason are returned unchanged.
+func (t *toolTranslation) restoreChunk(line []byte) []byte {
+	if t == nil {
+		return line
+	}
+	data, ok := bytes.CutPrefix(bytes.TrimRight(line, "\r\n"), []byte("data: "))
+	if !ok || !bytes.HasPrefix(data, []byte("{")) ||
+		!bytes.Contains(data, []byte(`"tool_calls"`)) && !bytes.Contains(data, []byte(`"finish_reason"`)) {
+		return line
+	}
+
+	var chunk map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&chunk); err != nil {
+		return line
+	}
+
+	choices, _ := chunk["choices"].([]any)
+	changed := false
+	for _, c := range choices {
+		choice, _ := c.(map[string]any)
+		if choice == nil {
+			continue
+		}
+		delta, _ := choice["delta"].(map[string]any)
+		toolCalls, _ := delta["tool_calls"].([]any)
+		for _, tc := range toolCalls {
+			t.sawToolCalls = true
+			toolCall, _ := tc.(map[string]any)
+			fn, _ := toolCall["function"].(map[string]any)
+			if name, ok := fn["name"].(string); ok && name != "" && t.clientName(name) != name {
+				fn["name"] = t.clientName(name)
+				changed = true
+			}
+		}
+		if reason, ok := choice["finish_reason"].(string); ok && t.sawToolCalls && reason == string(types.Stop) {
+			choice["finish_reason"] = string(types.ToolCalls)
+			changed = true
+		}
+	}
+	if !changed {
+		return line
+	}
+
+	restored, err := json.Marshal(chunk)
+	if err != nil {
+		return line
+	}
+	return append(append([]byte("data: "), restored...), '\n')
+}
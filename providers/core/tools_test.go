@@ -0,0 +1,155 @@
+package core
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func toolCallRequest(t *testing.T) types.CreateChatCompletionRequest {
+	t.Helper()
+	var req types.CreateChatCompletionRequest
+	body := `{"model":"m","messages":[
+		{"role":"user","content":"weather?"},
+		{"role":"assistant","content":"","tool_calls":[{"id":"c1","type":"function","function":{"name":"weather.current","arguments":"{}"}}]},
+		{"role":"tool","tool_call_id":"c1","content":[{"type":"text","text":"sunny"}]}],
+		"tools":[
+			{"type":"function","function":{"name":"weather.current","strict":true}},
+			{"type":"function","function":{"name":"weather-forecast","parameters":{"type":"object","properties":{"days":{"type":"integer"}}}}}],
+		"tool_choice":{"type":"function","function":{"name":"weather.current"}}}`
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatalf("invalid request: %v", err)
+	}
+	return req
+}
+
+// TestTranslateTools checks the outbound rewrite for each provider and that
+// the caller's request is left untouched.
+func TestTranslateTools(t *testing.T) {
+	original := toolCallRequest(t)
+
+	req := original
+	if translateTools(constants.OpenaiID, &req) != nil {
+		t.Fatal("providers without rules must not be translated")
+	}
+
+	req = original
+	tr := translateTools(constants.AnthropicID, &req)
+	tools := *req.Tools
+	if tools[0].Function.Name != "weather_current" || tools[1].Function.Name != "weather-forecast" {
+		t.Errorf("unexpected anthropic tool names: %q, %q", tools[0].Function.Name, tools[1].Function.Name)
+	}
+	if tools[0].Function.Strict != nil {
+		t.Error("strict must be dropped")
+	}
+	if params := *tools[0].Function.Parameters; params["type"] != "object" || params["properties"] == nil {
+		t.Errorf("tools without parameters must get an empty object schema, got %v", params)
+	}
+	if name := (*req.Messages[1].ToolCalls)[0].Function.Name; name != "weather_current" {
+		t.Errorf("tool call history not renamed: %q", name)
+	}
+	if named, err := req.ToolChoice.AsChatCompletionNamedToolChoice(); err != nil || named.Function.Name != "weather_current" {
+		t.Errorf("named tool choice not renamed: %+v, %v", named, err)
+	}
+	if tr.clientName("weather_current") != "weather.current" {
+		t.Error("renamed tools must map back to the client name")
+	}
+	if (*original.Tools)[0].Function.Name != "weather.current" || (*original.Messages[1].ToolCalls)[0].Function.Name != "weather.current" {
+		t.Error("the caller's request must not be modified")
+	}
+
+	req = original
+	translateTools(constants.CohereID, &req)
+	if len(*req.Tools) != 1 || (*req.Tools)[0].Function.Name != "weather_current" {
+		t.Errorf("named tool choice must narrow cohere tools to the chosen one, got %+v", *req.Tools)
+	}
+	if choice, err := req.ToolChoice.AsChatCompletionToolChoiceOption0(); err != nil || choice != types.ChatCompletionToolChoiceOption0Required {
+		t.Errorf("cohere tool choice must become required, got %q, %v", choice, err)
+	}
+	if content, err := req.Messages[2].Content.AsMessageContent0(); err != nil || content != "sunny" {
+		t.Errorf("cohere tool results must be plain strings, got %q, %v", content, err)
+	}
+
+	req = original
+	translateTools(constants.MistralID, &req)
+	if named, err := req.ToolChoice.AsChatCompletionNamedToolChoice(); err != nil || named.Function.Name != "weather_current" {
+		t.Errorf("mistral must keep the named tool choice, got %+v, %v", named, err)
+	}
+	if (*req.Tools)[0].Function.Strict == nil {
+		t.Error("mistral supports strict, it must be kept")
+	}
+	id := (*req.Messages[1].ToolCalls)[0].ID
+	if len(id) != 9 || nonAlphanumeric.MatchString(id) {
+		t.Errorf("mistral tool call ids must be 9 alphanumeric characters, got %q", id)
+	}
+	if *req.Messages[2].ToolCallID != id {
+		t.Errorf("the tool result must keep matching its call, got %q and %q", *req.Messages[2].ToolCallID, id)
+	}
+	if (*original.Messages[1].ToolCalls)[0].ID != "c1" || *original.Messages[2].ToolCallID != "c1" {
+		t.Error("the caller's tool call ids must not be modified")
+	}
+	if got := toolCallID(providerToolRules[constants.MistralID], "D681PevKs"); got != "D681PevKs" {
+		t.Errorf("valid mistral ids must be kept, got %q", got)
+	}
+}
+
+func TestToolTranslationRename(t *testing.T) {
+	rules := providerToolRules[constants.CohereID]
+	tr := &toolTranslation{toProvider: map[string]string{}, toClient: map[string]string{}}
+
+	if got := tr.rename(rules, "a.b"); got != "a_b" {
+		t.Errorf("got %q", got)
+	}
+	if got := tr.rename(rules, "a-b"); got != "a_b_2" {
+		t.Errorf("colliding names must get a suffix, got %q", got)
+	}
+	if got := tr.rename(rules, "a.b"); got != "a_b" {
+		t.Errorf("renames must be stable, got %q", got)
+	}
+	if got := tr.rename(rules, strings.Repeat("x", 80)); len(got) != 64 {
+		t.Errorf("long names must be truncated, got %d characters", len(got))
+	}
+}
+
+func TestToolTranslationRestore(t *testing.T) {
+	req := toolCallRequest(t)
+	tr := translateTools(constants.AnthropicID, &req)
+
+	resp := types.CreateChatCompletionResponse{Choices: []types.ChatCompletionChoice{{
+		FinishReason: types.Stop,
+		Message: types.Message{Role: types.Assistant, ToolCalls: &[]types.ChatCompletionMessageToolCall{
+			{ID: "c2", Type: types.Function, Function: types.ChatCompletionMessageToolCallFunction{Name: "weather_current"}},
+		}},
+	}}}
+	tr.restoreResponse(&resp)
+	call := (*resp.Choices[0].Message.ToolCalls)[0]
+	if call.Function.Name != "weather.current" || call.Function.Arguments != "{}" {
+		t.Errorf("tool call not restored: %+v", call.Function)
+	}
+	if resp.Choices[0].FinishReason != types.ToolCalls {
+		t.Errorf("finish reason must be tool_calls, got %q", resp.Choices[0].FinishReason)
+	}
+
+	lines := []struct {
+		in, want string
+	}{
+		{in: "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"hi\"},\"finish_reason\":null}]}\n", want: "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"hi\"},\"finish_reason\":null}]}\n"},
+		{in: "data: {\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":[{\"index\":0,\"function\":{\"name\":\"weather_current\",\"arguments\":\"\"}}]}}],\"created\":1742165657}\n", want: "data: {\"choices\":[{\"delta\":{\"tool_calls\":[{\"function\":{\"arguments\":\"\",\"name\":\"weather.current\"},\"index\":0}]},\"index\":0}],\"created\":1742165657}\n"},
+		{in: "data: {\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"stop\"}]}\n", want: "data: {\"choices\":[{\"delta\":{},\"finish_reason\":\"tool_calls\",\"index\":0}]}\n"},
+		{in: "data: [DONE]\n", want: "data: [DONE]\n"},
+		{in: "\n", want: "\n"},
+	}
+	for _, line := range lines {
+		if got := string(tr.restoreChunk([]byte(line.in))); got != line.want {
+			t.Errorf("restoreChunk(%q) = %q, want %q", line.in, got, line.want)
+		}
+	}
+
+	var nilTranslation *toolTranslation
+	if got := string(nilTranslation.restoreChunk([]byte("data: {}\n"))); got != "data: {}\n" {
+		t.Errorf("nil translation must be a no-op, got %q", got)
+	}
+}
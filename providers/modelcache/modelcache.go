@@ -0,0 +1,110 @@
+// Package modelcache keeps the model lists of the providers, so listing
+// models does not call every provider each time and survives transient
+// provider outages. Lists older than their TTL are served while a refresh
+// runs in the background (stale-while-revalidate).
+package modelcache
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	l "github.com/inference-gateway/inference-gateway/logger"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// FetchFunc lists the models of a provider
+type FetchFunc func(ctx context.Context) (types.ListModelsResponse, error)
+
+type entry struct {
+	response   types.ListModelsResponse
+	fetched    time.Time
+	refreshing bool
+}
+
+// Cache holds model lists by key. It is safe for concurrent use.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+	now     func() time.Time
+	logger  l.Logger
+}
+
+// New creates an empty cache logging the failed background refreshes with
+// logger
+func New(logger l.Logger) *Cache {
+	return &Cache{entries: make(map[string]*entry), now: time.Now, logger: logger}
+}
+
+// Get returns the model list under key. A list fetched within ttl is
+// returned as is; an older one within ttl+maxStale is returned too while it
+// is refreshed in the background with a copy of ctx that is not cancelled
+// with it, so fetch should set its own deadline. Otherwise the list is
+// fetched; failures are not cached. A ttl of 0 or less disables the cache.
+func (c *Cache) Get(ctx context.Context, key string, ttl, maxStale time.Duration, fetch FetchFunc) (types.ListModelsResponse, error) {
+	if ttl <= 0 {
+		return fetch(ctx)
+	}
+
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if ok {
+		age := c.now().Sub(e.fetched)
+		if age < ttl {
+			defer c.mu.Unlock()
+			return clone(e.response), nil
+		}
+		if age < ttl+maxStale {
+			if !e.refreshing {
+				e.refreshing = true
+				go c.refresh(context.WithoutCancel(ctx), key, fetch)
+			}
+			defer c.mu.Unlock()
+			return clone(e.response), nil
+		}
+	}
+	c.mu.Unlock()
+
+	response, err := fetch(ctx)
+	if err != nil {
+		return types.ListModelsResponse{}, err
+	}
+	c.store(key, response)
+	return clone(response), nil
+}
+
+// refresh fetches the list under key again, keeping the previous one when
+// that fails
+func (c *Cache) refresh(ctx context.Context, key string, fetch FetchFunc) {
+	response, err := fetch(ctx)
+	if err != nil {
+		c.mu.Lock()
+		if e, ok := c.entries[key]; ok {
+			e.refreshing = false
+		}
+		c.mu.Unlock()
+		c.logger.Warn("failed to refresh cached models, keeping the stale ones", "key", key, "error", err.Error())
+		return
+	}
+	c.store(key, response)
+}
+
+func (c *Cache) store(key string, response types.ListModelsResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = &entry{response: clone(response), fetched: c.now()}
+}
+
+// Purge forgets every model list
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
+
+// clone copies the models of response, so callers may change them
+func clone(response types.ListModelsResponse) types.ListModelsResponse {
+	response.Data = slices.Clone(response.Data)
+	return response
+}
@@ -0,0 +1,81 @@
+package modelcache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	l "github.com/inference-gateway/inference-gateway/logger"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func TestCache(t *testing.T) {
+	var clock atomic.Int64
+	clock.Store(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC).UnixNano())
+	advance := func(d time.Duration) { clock.Add(int64(d)) }
+	cache := New(&l.NoopLogger{})
+	cache.now = func() time.Time { return time.Unix(0, clock.Load()) }
+
+	var calls atomic.Int32
+	var failing atomic.Bool
+	refreshed := make(chan struct{}, 1)
+	fetch := func(context.Context) (types.ListModelsResponse, error) {
+		n := calls.Add(1)
+		defer func() {
+			select {
+			case refreshed <- struct{}{}:
+			default:
+			}
+		}()
+		if failing.Load() {
+			return types.ListModelsResponse{}, errors.New("provider down")
+		}
+		return types.ListModelsResponse{Object: "list", Data: []types.Model{{ID: "openai/gpt-4o", Created: int64(n)}}}, nil
+	}
+	get := func() (types.ListModelsResponse, error) {
+		return cache.Get(context.Background(), "openai", time.Minute, time.Hour, fetch)
+	}
+
+	resp, err := get()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), resp.Data[0].Created)
+	<-refreshed
+
+	resp.Data[0].ID = "changed"
+	resp, err = get()
+	require.NoError(t, err)
+	assert.Equal(t, "openai/gpt-4o", resp.Data[0].ID, "callers get copies")
+	assert.Equal(t, int32(1), calls.Load(), "fresh lists are cached")
+
+	advance(2 * time.Minute)
+	resp, err = get()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), resp.Data[0].Created, "stale lists are served during the refresh")
+	<-refreshed
+	require.Eventually(t, func() bool {
+		resp, _ := get()
+		return resp.Data[0].Created == 2
+	}, time.Second, time.Millisecond, "refreshed in the background")
+
+	failing.Store(true)
+	advance(2 * time.Minute)
+	resp, err = get()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), resp.Data[0].Created)
+	<-refreshed
+	resp, err = get()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), resp.Data[0].Created, "outages keep the stale list")
+
+	advance(2 * time.Hour)
+	_, err = get()
+	assert.Error(t, err, "lists past the max staleness are not served")
+
+	_, err = cache.Get(context.Background(), "groq", 0, time.Hour, fetch)
+	assert.Error(t, err, "disabled")
+}
@@ -0,0 +1,179 @@
+// Package plugin is the contract of out-of-tree providers: inference backends
+// added to the gateway without forking the providers package. A plugin is a
+// sidecar adapter serving the part of the OpenAI API the gateway calls,
+// version 1 of the contract:
+//
+//   - GET /v1/models answers {"object": "list", "data": [models]}
+//   - POST /v1/chat/completions answers a chat completion, or server-sent
+//     events of chat completion chunks ending with data: [DONE] when the
+//     request sets "stream": true
+//   - failures answer an error status with {"error": {"message": "..."}},
+//     mapped by the gateway into its error catalog
+//
+// The request and response bodies are those of providers/types. Implement
+// Backend and serve it with Handler, then register the sidecar as a custom
+// provider:
+//
+//	CUSTOM_PROVIDERS=acme
+//	ACME_API_URL=http://acme-adapter:9000/v1
+//
+// Its models are listed as acme/<model> and requests for them are sent to
+// the sidecar with the gateway's tool, parameter and usage handling of
+// OpenAI-compatible providers. Adapters in another language implement the
+// same routes.
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// Backend is an inference backend served to the gateway by Handler
+type Backend interface {
+	// ListModels returns the models of the backend by their name, without
+	// the provider prefix the gateway adds
+	ListModels(ctx context.Context) ([]types.Model, error)
+	// ChatCompletion answers a chat completion request
+	ChatCompletion(ctx context.Context, req types.CreateChatCompletionRequest) (*types.CreateChatCompletionResponse, error)
+	// ChatCompletionStream answers a streaming chat completion request,
+	// calling send with every chunk. An error returned by send means the
+	// gateway went away, and should be returned as is.
+	ChatCompletionStream(ctx context.Context, req types.CreateChatCompletionRequest, send func(types.CreateChatCompletionStreamResponse) error) error
+}
+
+// Error is an error a Backend returns to answer with a given status, e.g.
+// 400 for an invalid request or 429 when throttled. Other errors answer 500.
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// errorBody is the OpenAI-style error body the gateway parses
+type errorBody struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error"`
+}
+
+// Handler serves backend with the routes of the plugin contract
+func Handler(backend Backend) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/models", func(w http.ResponseWriter, r *http.Request) {
+		models, err := backend.ListModels(r.Context())
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		created := time.Now().Unix()
+		for i := range models {
+			if models[i].Object == "" {
+				models[i].Object = "model"
+			}
+			if models[i].Created == 0 {
+				models[i].Created = created
+			}
+		}
+		writeJSON(w, http.StatusOK, types.ListModelsResponse{Object: "list", Data: models})
+	})
+	mux.HandleFunc("POST /v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var req types.CreateChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, &Error{Status: http.StatusBadRequest, Message: fmt.Sprintf("invalid chat completion request: %v", err)})
+			return
+		}
+		if req.Stream == nil || !*req.Stream {
+			resp, err := backend.ChatCompletion(r.Context(), req)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			resp.Object = "chat.completion"
+			writeJSON(w, http.StatusOK, resp)
+			return
+		}
+		stream(w, r, backend, req)
+	})
+	return mux
+}
+
+// stream answers a streaming request as server-sent events. Failures before
+// the first chunk answer an error status, later ones an error event.
+func stream(w http.ResponseWriter, r *http.Request, backend Backend, req types.CreateChatCompletionRequest) {
+	flusher, _ := w.(http.Flusher)
+	started := false
+	err := backend.ChatCompletionStream(r.Context(), req, func(chunk types.CreateChatCompletionStreamResponse) error {
+		if !started {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+		chunk.Object = "chat.completion.chunk"
+		data, err := json.Marshal(chunk)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if !started {
+		if err == nil {
+			err = errors.New("the backend streamed no chunk")
+		}
+		writeError(w, err)
+		return
+	}
+	if err != nil {
+		if r.Context().Err() != nil {
+			return
+		}
+		data, _ := json.Marshal(newErrorBody(err))
+		_, _ = fmt.Fprintf(w, "data: %s\n\n", data)
+	}
+	_, _ = fmt.Fprint(w, "data: [DONE]\n\n")
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
+
+func newErrorBody(err error) errorBody {
+	var body errorBody
+	body.Error.Message = err.Error()
+	body.Error.Type = "server_error"
+	var pluginErr *Error
+	if errors.As(err, &pluginErr) && pluginErr.Status < http.StatusInternalServerError {
+		body.Error.Type = "invalid_request_error"
+	}
+	return body
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var pluginErr *Error
+	if errors.As(err, &pluginErr) && pluginErr.Status >= http.StatusBadRequest {
+		status = pluginErr.Status
+	}
+	writeJSON(w, status, newErrorBody(err))
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
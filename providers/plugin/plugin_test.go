@@ -0,0 +1,98 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// echoBackend answers with the last message, word by word when streaming
+type echoBackend struct {
+	streamErr error
+}
+
+func (echoBackend) ListModels(ctx context.Context) ([]types.Model, error) {
+	return []types.Model{{ID: "echo-1", OwnedBy: "acme"}}, nil
+}
+
+func (echoBackend) ChatCompletion(ctx context.Context, req types.CreateChatCompletionRequest) (*types.CreateChatCompletionResponse, error) {
+	if req.Model != "echo-1" {
+		return nil, &Error{Status: http.StatusNotFound, Message: "model " + req.Model + " not found"}
+	}
+	message := types.Message{Role: types.Assistant}
+	if err := message.Content.FromMessageContent0(req.Messages[len(req.Messages)-1].TextContent()); err != nil {
+		return nil, err
+	}
+	return &types.CreateChatCompletionResponse{ID: "echo", Model: req.Model, Choices: []types.ChatCompletionChoice{{Message: message, FinishReason: types.Stop}}}, nil
+}
+
+func (b echoBackend) ChatCompletionStream(ctx context.Context, req types.CreateChatCompletionRequest, send func(types.CreateChatCompletionStreamResponse) error) error {
+	for word := range strings.FieldsSeq(req.Messages[len(req.Messages)-1].TextContent()) {
+		chunk := types.CreateChatCompletionStreamResponse{ID: "echo", Model: req.Model, Choices: []types.ChatCompletionStreamChoice{{Delta: types.ChatCompletionStreamResponseDelta{Content: word}}}}
+		if err := send(chunk); err != nil {
+			return err
+		}
+	}
+	return b.streamErr
+}
+
+func serve(t *testing.T, backend Backend, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	Handler(backend).ServeHTTP(w, req)
+	return w
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("models are listed as an openai list", func(t *testing.T) {
+		w := serve(t, echoBackend{}, http.MethodGet, "/v1/models", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"object":"list"`)
+		assert.Contains(t, w.Body.String(), `"id":"echo-1","object":"model","owned_by":"acme"`)
+	})
+
+	t.Run("chat completions are answered as json", func(t *testing.T) {
+		w := serve(t, echoBackend{}, http.MethodPost, "/v1/chat/completions", `{"model":"echo-1","messages":[{"role":"user","content":"hello there"}]}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"object":"chat.completion"`)
+		assert.Contains(t, w.Body.String(), `"content":"hello there"`)
+	})
+
+	t.Run("streams end with done", func(t *testing.T) {
+		w := serve(t, echoBackend{}, http.MethodPost, "/v1/chat/completions", `{"model":"echo-1","stream":true,"messages":[{"role":"user","content":"hello there"}]}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		events := strings.Split(strings.TrimSpace(w.Body.String()), "\n\n")
+		require.Len(t, events, 3)
+		assert.Contains(t, events[0], `"content":"hello"`)
+		assert.Contains(t, events[1], `"object":"chat.completion.chunk"`)
+		assert.Equal(t, "data: [DONE]", events[2])
+	})
+
+	t.Run("errors answer their status", func(t *testing.T) {
+		w := serve(t, echoBackend{}, http.MethodPost, "/v1/chat/completions", `{"model":"other","messages":[{"role":"user","content":"hi"}]}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.JSONEq(t, `{"error":{"message":"model other not found","type":"invalid_request_error"}}`, w.Body.String())
+
+		w = serve(t, echoBackend{}, http.MethodPost, "/v1/chat/completions", `{"model":`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("stream failures after the first chunk are sent as an error event", func(t *testing.T) {
+		w := serve(t, echoBackend{streamErr: errors.New("backend crashed")}, http.MethodPost, "/v1/chat/completions", `{"model":"echo-1","stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+		body, err := io.ReadAll(w.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), `data: {"error":{"message":"backend crashed","type":"server_error"}}`)
+		assert.True(t, strings.HasSuffix(string(body), "data: [DONE]\n\n"))
+	})
+}
@@ -0,0 +1,100 @@
+package registry
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// customIDPattern are the IDs a custom provider may have, usable as the
+// model prefix and in env var names
+var customIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// customProviders are the custom providers known to the gateway next to the
+// built-in Registry, replaced on every reload
+var customProviders atomic.Pointer[map[types.Provider]bool]
+
+// CustomProviders builds the configurations of the OpenAI-compatible
+// providers listed in ids, the comma-separated CUSTOM_PROVIDERS setting. Each
+// is read with lookup from <ID>_API_URL, which is required, <ID>_API_KEY and
+// <ID>_AUTH_HEADER, <ID> being the ID in upper case with dashes replaced by
+// underscores. The key is sent as a bearer token in the Authorization header,
+// as is in the header named by <ID>_AUTH_HEADER, and not at all when empty.
+func CustomProviders(ids string, lookup func(key string) (string, bool)) (map[types.Provider]*ProviderConfig, error) {
+	providers := make(map[types.Provider]*ProviderConfig)
+	for entry := range strings.SplitSeq(ids, ",") {
+		id := types.Provider(strings.TrimSpace(entry))
+		if id == "" {
+			continue
+		}
+		if !customIDPattern.MatchString(string(id)) {
+			return nil, fmt.Errorf("invalid CUSTOM_PROVIDERS entry %q: expected lowercase letters, digits, dashes and underscores", id)
+		}
+		if _, ok := Registry[id]; ok {
+			return nil, fmt.Errorf("invalid CUSTOM_PROVIDERS entry %q: %s is a built-in provider", id, id)
+		}
+		if _, ok := providers[id]; ok {
+			return nil, fmt.Errorf("invalid CUSTOM_PROVIDERS entry %q: listed twice", id)
+		}
+
+		prefix := strings.ToUpper(strings.ReplaceAll(string(id), "-", "_"))
+		url, _ := lookup(prefix + "_API_URL")
+		if strings.TrimSpace(url) == "" {
+			return nil, fmt.Errorf("custom provider %s: %s_API_URL is required", id, prefix)
+		}
+		token, _ := lookup(prefix + "_API_KEY")
+		header, _ := lookup(prefix + "_AUTH_HEADER")
+
+		providerCfg := &ProviderConfig{
+			ID:             id,
+			Name:           string(id),
+			URL:            strings.TrimSuffix(strings.TrimSpace(url), "/"),
+			Token:          token,
+			AuthType:       constants.AuthTypeNone,
+			SupportsVision: true,
+			Endpoints: types.Endpoints{
+				Models: constants.OpenaiModelsEndpoint,
+				Chat:   constants.OpenaiChatEndpoint,
+			},
+		}
+		header = strings.TrimSpace(header)
+		switch {
+		case token == "":
+		case header == "" || strings.EqualFold(header, "Authorization"):
+			providerCfg.AuthType = constants.AuthTypeBearer
+		case strings.EqualFold(header, "x-api-key"):
+			providerCfg.AuthType = constants.AuthTypeXheader
+		default:
+			providerCfg.ExtraHeaders = map[string][]string{header: {token}}
+		}
+		providers[id] = providerCfg
+	}
+	return providers, nil
+}
+
+// SetCustomProviders makes the providers of cfg missing from Registry known
+// to the gateway, so their models route by their prefix and policies, tenants
+// and routing pools may name them
+func SetCustomProviders(cfg map[types.Provider]*ProviderConfig) {
+	custom := make(map[types.Provider]bool)
+	for id := range cfg {
+		if _, ok := Registry[id]; !ok {
+			custom[id] = true
+		}
+	}
+	customProviders.Store(&custom)
+}
+
+// Known reports whether id is a built-in provider or a custom one set with
+// SetCustomProviders
+func Known(id types.Provider) bool {
+	if _, ok := Registry[id]; ok {
+		return true
+	}
+	custom := customProviders.Load()
+	return custom != nil && (*custom)[id]
+}
@@ -0,0 +1,66 @@
+package registry
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// ApplyPolicies overrides the timeouts and retry counts of the providers of
+// cfg with PROVIDER_TIMEOUTS, PROVIDER_CONNECT_TIMEOUTS and PROVIDER_RETRIES,
+// comma-separated provider=value lists. Unknown providers and invalid values
+// are rejected.
+func ApplyPolicies(cfg map[types.Provider]*ProviderConfig, timeouts, connectTimeouts, retries string) error {
+	if err := applyPolicy(cfg, "PROVIDER_TIMEOUTS", timeouts, func(p *ProviderConfig, value string) error {
+		return parsePositiveDuration(value, &p.Timeout)
+	}); err != nil {
+		return err
+	}
+	if err := applyPolicy(cfg, "PROVIDER_CONNECT_TIMEOUTS", connectTimeouts, func(p *ProviderConfig, value string) error {
+		return parsePositiveDuration(value, &p.ConnectTimeout)
+	}); err != nil {
+		return err
+	}
+	return applyPolicy(cfg, "PROVIDER_RETRIES", retries, func(p *ProviderConfig, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("expected a retry count, got %q", value)
+		}
+		p.MaxRetries = n
+		return nil
+	})
+}
+
+// applyPolicy sets the value of every provider=value pair of list with set
+func applyPolicy(cfg map[types.Provider]*ProviderConfig, name, list string, set func(p *ProviderConfig, value string) error) error {
+	for entry := range strings.SplitSeq(list, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		id, value, ok := strings.Cut(entry, "=")
+		id, value = strings.TrimSpace(id), strings.TrimSpace(value)
+		if !ok || id == "" {
+			return fmt.Errorf("invalid %s entry %q, expected provider=value", name, strings.TrimSpace(entry))
+		}
+		provider, ok := cfg[types.Provider(id)]
+		if !ok {
+			return fmt.Errorf("invalid %s entry %q: unknown provider %s", name, strings.TrimSpace(entry), id)
+		}
+		if err := set(provider, value); err != nil {
+			return fmt.Errorf("invalid %s entry %q: %w", name, strings.TrimSpace(entry), err)
+		}
+	}
+	return nil
+}
+
+func parsePositiveDuration(value string, d *time.Duration) error {
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= 0 {
+		return fmt.Errorf("expected a positive duration, got %q", value)
+	}
+	*d = parsed
+	return nil
+}
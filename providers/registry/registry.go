@@ -3,6 +3,7 @@ package registry
 
 import (
 	"fmt"
+	"time"
 
 	logger "github.com/inference-gateway/inference-gateway/logger"
 	client "github.com/inference-gateway/inference-gateway/providers/client"
@@ -21,6 +22,13 @@ type ProviderConfig struct {
 	SupportsVision bool
 	ExtraHeaders   map[string][]string
 	Endpoints      types.Endpoints
+	// Timeout bounds the requests to the provider, SERVER_READ_TIMEOUT when 0
+	Timeout time.Duration
+	// ConnectTimeout bounds establishing connections to the provider, 30s
+	// when 0
+	ConnectTimeout time.Duration
+	// MaxRetries is how many times failed requests to the provider are retried
+	MaxRetries int
 }
 
 //go:generate mockgen -source=registry.go -destination=../../tests/mocks/providers/registry.go -package=providersmocks
@@ -150,6 +158,7 @@ var Registry = map[types.Provider]*ProviderConfig{
 			Models: constants.LlamacppModelsEndpoint,
 			Chat:   constants.LlamacppChatEndpoint,
 		},
+		Timeout: 10 * time.Minute,
 	},
 	constants.MinimaxID: {
 		ID:             constants.MinimaxID,
@@ -205,6 +214,7 @@ var Registry = map[types.Provider]*ProviderConfig{
 			Models: constants.OllamaModelsEndpoint,
 			Chat:   constants.OllamaChatEndpoint,
 		},
+		Timeout: 10 * time.Minute,
 	},
 	constants.OllamaCloudID: {
 		ID:             constants.OllamaCloudID,
@@ -0,0 +1,172 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	client "github.com/inference-gateway/inference-gateway/providers/client"
+	core "github.com/inference-gateway/inference-gateway/providers/core"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// ErrProviderDisabled is returned when building a provider an operator
+// disabled at runtime
+var ErrProviderDisabled = errors.New("provider is disabled")
+
+// ReloadableRegistry is a ProviderRegistry whose provider configurations can
+// be replaced at runtime, so API keys and URLs change without a restart.
+// Providers are built per request, so requests in flight keep the
+// configuration they started with.
+type ReloadableRegistry struct {
+	logger  logger.Logger
+	current atomic.Pointer[ProviderRegistryImpl]
+
+	mu sync.RWMutex
+	// disabled lists the providers turned off at runtime; it survives reloads
+	disabled map[types.Provider]bool
+	// configured are the provider configurations of the last reload, before
+	// the tokens set at runtime are applied
+	configured map[types.Provider]*ProviderConfig
+	tokens     map[types.Provider]tokenOverride
+}
+
+// tokenOverride is an API key set at runtime, with the configured key it
+// replaced
+type tokenOverride struct {
+	token    string
+	replaced string
+}
+
+// NewReloadableRegistry creates a reloadable registry serving cfg
+func NewReloadableRegistry(cfg map[types.Provider]*ProviderConfig, logger logger.Logger) *ReloadableRegistry {
+	r := &ReloadableRegistry{
+		logger:   logger,
+		disabled: make(map[types.Provider]bool),
+		tokens:   make(map[types.Provider]tokenOverride),
+	}
+	r.Reload(cfg)
+	return r
+}
+
+// Reload replaces the provider configurations. The API keys set at runtime
+// with SetToken are kept until the configured key of their provider changes,
+// so a reload does not bring back a rotated key.
+func (r *ReloadableRegistry) Reload(cfg map[types.Provider]*ProviderConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.configured = cfg
+	SetCustomProviders(cfg)
+	for providerID, override := range r.tokens {
+		if providerCfg, ok := cfg[providerID]; !ok || providerCfg.Token != override.replaced {
+			r.logger.Info("configured provider token changed, dropping the token set at runtime", "provider", providerID)
+			delete(r.tokens, providerID)
+		}
+	}
+	r.publish()
+}
+
+// SetToken replaces the API key of providerID for subsequent requests.
+// Requests in flight keep the key they started with.
+func (r *ReloadableRegistry) SetToken(providerID types.Provider, token string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	providerCfg, ok := r.configured[providerID]
+	if !ok {
+		return fmt.Errorf("provider %s is not configured", providerID)
+	}
+	r.tokens[providerID] = tokenOverride{token: token, replaced: providerCfg.Token}
+	r.publish()
+	return nil
+}
+
+// WithToken returns a registry building providerID with token instead of its
+// current API key, to check a key before setting it
+func (r *ReloadableRegistry) WithToken(providerID types.Provider, token string) ProviderRegistry {
+	providers := r.GetProviders()
+	cfg := make(map[types.Provider]*ProviderConfig, len(providers))
+	for id, providerCfg := range providers {
+		cfg[id] = providerCfg
+	}
+	if providerCfg, ok := providers[providerID]; ok {
+		withToken := *providerCfg
+		withToken.Token = token
+		cfg[providerID] = &withToken
+	}
+	return NewProviderRegistry(cfg, r.logger)
+}
+
+// publish serves the configured providers with the tokens set at runtime.
+// r.mu must be held.
+func (r *ReloadableRegistry) publish() {
+	cfg := r.configured
+	if len(r.tokens) > 0 {
+		cfg = make(map[types.Provider]*ProviderConfig, len(r.configured))
+		for providerID, providerCfg := range r.configured {
+			if override, ok := r.tokens[providerID]; ok {
+				withToken := *providerCfg
+				withToken.Token = override.token
+				providerCfg = &withToken
+			}
+			cfg[providerID] = providerCfg
+		}
+	}
+	r.current.Store(&ProviderRegistryImpl{cfg: cfg, logger: r.logger})
+}
+
+// SetEnabled turns providerID on or off for subsequent requests. Requests in
+// flight are not affected.
+func (r *ReloadableRegistry) SetEnabled(providerID types.Provider, enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if enabled {
+		delete(r.disabled, providerID)
+	} else {
+		r.disabled[providerID] = true
+	}
+}
+
+// Enabled reports whether providerID has not been disabled at runtime
+func (r *ReloadableRegistry) Enabled(providerID types.Provider) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return !r.disabled[providerID]
+}
+
+// Disabled returns the providers disabled at runtime, sorted
+func (r *ReloadableRegistry) Disabled() []types.Provider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	disabled := make([]types.Provider, 0, len(r.disabled))
+	for providerID := range r.disabled {
+		disabled = append(disabled, providerID)
+	}
+	slices.Sort(disabled)
+	return disabled
+}
+
+func (r *ReloadableRegistry) GetProviders() map[types.Provider]*ProviderConfig {
+	return r.current.Load().GetProviders()
+}
+
+func (r *ReloadableRegistry) BuildProvider(providerID types.Provider, c client.Client) (core.IProvider, error) {
+	if !r.Enabled(providerID) {
+		return nil, ErrProviderDisabled
+	}
+	return r.current.Load().BuildProvider(providerID, c)
+}
+
+// CheckEnabled returns ErrProviderDisabled when r is a ReloadableRegistry on
+// which providerID was disabled, for registries derived from one
+func CheckEnabled(r ProviderRegistry, providerID types.Provider) error {
+	reloadable, ok := r.(*ReloadableRegistry)
+	if ok && !reloadable.Enabled(providerID) {
+		return ErrProviderDisabled
+	}
+	return nil
+}
+
+var _ ProviderRegistry = (*ReloadableRegistry)(nil)
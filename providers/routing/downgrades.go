@@ -0,0 +1,103 @@
+package routing
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// Downgrades are the cheaper equivalents of ROUTING_DOWNGRADES and the
+// provider spend rates of ROUTING_DOWNGRADE_SPEND_RATES that trigger them
+type Downgrades struct {
+	models map[string]string
+	rates  map[types.Provider]float64
+}
+
+// downgrades are the Downgrades the usage middleware applies, replaced on
+// every reload
+var downgrades atomic.Pointer[Downgrades]
+
+// ParseDowngrades parses ROUTING_DOWNGRADES and ROUTING_DOWNGRADE_SPEND_RATES.
+// The providers must be known, so SetCustomProviders must have been called
+// with the custom providers of the configuration.
+func ParseDowngrades(models, rates string) (*Downgrades, error) {
+	d := &Downgrades{models: make(map[string]string), rates: make(map[types.Provider]float64)}
+	for entry := range strings.SplitSeq(models, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		from, to, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid ROUTING_DOWNGRADES entry %q, expected provider/model=provider/model", entry)
+		}
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		for _, model := range []string{from, to} {
+			provider, name, ok := strings.Cut(model, "/")
+			if !ok || name == "" {
+				return nil, fmt.Errorf("invalid ROUTING_DOWNGRADES entry %q: %q is not a provider/model", entry, model)
+			}
+			if !registry.Known(types.Provider(provider)) {
+				return nil, fmt.Errorf("invalid ROUTING_DOWNGRADES entry %q: unknown provider %q", entry, provider)
+			}
+		}
+		if from == to {
+			return nil, fmt.Errorf("invalid ROUTING_DOWNGRADES entry %q: a model is not its own equivalent", entry)
+		}
+		d.models[from] = to
+	}
+	for entry := range strings.SplitSeq(rates, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		provider, rate, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid ROUTING_DOWNGRADE_SPEND_RATES entry %q, expected provider=rate", entry)
+		}
+		id := types.Provider(strings.TrimSpace(provider))
+		if !registry.Known(id) {
+			return nil, fmt.Errorf("invalid ROUTING_DOWNGRADE_SPEND_RATES entry %q: unknown provider %q", entry, id)
+		}
+		usd, err := strconv.ParseFloat(strings.TrimSpace(rate), 64)
+		if err != nil || usd <= 0 {
+			return nil, fmt.Errorf("invalid ROUTING_DOWNGRADE_SPEND_RATES entry %q: the rate must be a positive number of USD per hour", entry)
+		}
+		d.rates[id] = usd
+	}
+	return d, nil
+}
+
+// SetDowngrades replaces the downgrades the usage middleware applies
+func SetDowngrades(d *Downgrades) {
+	downgrades.Store(d)
+}
+
+// CurrentDowngrades returns the downgrades set last, nil when none are
+func CurrentDowngrades() *Downgrades {
+	return downgrades.Load()
+}
+
+// Equivalent returns the cheaper equivalent of the model of provider, as a
+// provider/model, false when it has none
+func (d *Downgrades) Equivalent(provider types.Provider, model string) (string, bool) {
+	if d == nil {
+		return "", false
+	}
+	equivalent, ok := d.models[string(provider)+"/"+strings.TrimPrefix(model, string(provider)+"/")]
+	return equivalent, ok
+}
+
+// SpendRate returns the spend rate in USD per hour above which the models of
+// provider are downgraded, false when there is none
+func (d *Downgrades) SpendRate(provider types.Provider) (float64, bool) {
+	if d == nil {
+		return 0, false
+	}
+	rate, ok := d.rates[provider]
+	return rate, ok
+}
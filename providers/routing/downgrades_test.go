@@ -0,0 +1,49 @@
+package routing
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+)
+
+func TestParseDowngrades(t *testing.T) {
+	d, err := ParseDowngrades(" openai/gpt-4o=openai/gpt-4o-mini, anthropic/claude-sonnet-4-5=groq/llama-3.3-70b-versatile,", "openai=50, anthropic=2.5")
+	require.NoError(t, err)
+
+	equivalent, ok := d.Equivalent(constants.OpenaiID, "gpt-4o")
+	require.True(t, ok)
+	assert.Equal(t, "openai/gpt-4o-mini", equivalent)
+	equivalent, ok = d.Equivalent(constants.AnthropicID, "anthropic/claude-sonnet-4-5")
+	require.True(t, ok, "models may carry the provider prefix")
+	assert.Equal(t, "groq/llama-3.3-70b-versatile", equivalent)
+	_, ok = d.Equivalent(constants.OpenaiID, "gpt-4o-mini")
+	assert.False(t, ok)
+
+	rate, ok := d.SpendRate(constants.AnthropicID)
+	require.True(t, ok)
+	assert.InDelta(t, 2.5, rate, 1e-9)
+	_, ok = d.SpendRate(constants.GroqID)
+	assert.False(t, ok)
+
+	var none *Downgrades
+	_, ok = none.Equivalent(constants.OpenaiID, "gpt-4o")
+	assert.False(t, ok, "no downgrades are configured")
+
+	for name, tc := range map[string][2]string{
+		"missing equivalent":  {"openai/gpt-4o", ""},
+		"unprefixed model":    {"gpt-4o=gpt-4o-mini", ""},
+		"unknown provider":    {"openai/gpt-4o=closedai/gpt-4o-mini", ""},
+		"same model":          {"openai/gpt-4o=openai/gpt-4o", ""},
+		"rate without value":  {"", "openai"},
+		"negative rate":       {"", "openai=-1"},
+		"rate of unknown one": {"", "closedai=10"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseDowngrades(tc[0], tc[1])
+			assert.Error(t, err)
+		})
+	}
+}
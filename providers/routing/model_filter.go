@@ -1,6 +1,7 @@
 package routing
 
 import (
+	"path"
 	"strings"
 
 	types "github.com/inference-gateway/inference-gateway/providers/types"
@@ -18,18 +19,39 @@ func ParseModelSet(csv string) map[string]bool {
 }
 
 // ModelMatches reports whether modelID matches the set, comparing both the
-// full id and the provider-stripped model name case-insensitively.
+// full id and the provider-stripped model name case-insensitively. Entries
+// may be wildcard patterns such as openai/gpt-4* or *-mini.
 func ModelMatches(set map[string]bool, modelID string) bool {
 	id := strings.ToLower(modelID)
-	if set[id] {
+	_, name, hasProvider := strings.Cut(id, "/")
+	if set[id] || hasProvider && set[name] {
 		return true
 	}
-	if _, name, ok := strings.Cut(id, "/"); ok && set[name] {
-		return true
+	for entry := range set {
+		if !strings.ContainsAny(entry, "*?[") {
+			continue
+		}
+		if matchModel(entry, id) || hasProvider && matchModel(entry, name) {
+			return true
+		}
 	}
 	return false
 }
 
+// ValidModelPattern reports whether the model list entry is a well-formed
+// pattern
+func ValidModelPattern(entry string) bool {
+	_, err := path.Match(entry, "")
+	return err == nil
+}
+
+// matchModel matches a model id against a pattern whose * also spans the
+// slashes of model names such as meta-llama/llama-4-scout
+func matchModel(pattern, id string) bool {
+	ok, _ := path.Match(strings.ReplaceAll(pattern, "/", "\x00"), strings.ReplaceAll(id, "/", "\x00"))
+	return ok
+}
+
 // FilterModels applies the ALLOWED_MODELS / DISALLOWED_MODELS semantics: a
 // non-empty allow list wins over the deny list; empty lists pass everything.
 func FilterModels(models []types.Model, allowedModels, disallowedModels string) []types.Model {
@@ -46,6 +46,16 @@ func TestFilterModels(t *testing.T) {
 			disallowed: "phi3",
 			expected:   []string{"ollama/phi3"},
 		},
+		{
+			name:     "allow list by wildcard pattern",
+			allowed:  "openai/gpt-4*, *-70b",
+			expected: []string{"openai/gpt-4o", "groq/llama-3.3-70b"},
+		},
+		{
+			name:       "disallow list by wildcard pattern",
+			disallowed: "ollama/*",
+			expected:   []string{"openai/gpt-4o", "groq/llama-3.3-70b"},
+		},
 		{
 			name:     "whitespace-only allow list passes everything",
 			allowed:  " , ",
@@ -75,3 +85,16 @@ func TestModelMatches(t *testing.T) {
 	assert.False(t, ModelMatches(set, "openai/gpt-3.5"))
 	assert.False(t, ModelMatches(set, ""))
 }
+
+func TestModelMatchesPatterns(t *testing.T) {
+	set := ParseModelSet("openai/gpt-4*, groq/*, *-mini")
+
+	assert.True(t, ModelMatches(set, "openai/gpt-4o"))
+	assert.False(t, ModelMatches(set, "openai/gpt-3.5-turbo"))
+	assert.True(t, ModelMatches(set, "groq/meta-llama/llama-4-scout"), "* spans the slashes of model names")
+	assert.True(t, ModelMatches(set, "openai/o4-mini"), "patterns match the provider-stripped name")
+	assert.False(t, ModelMatches(set, "anthropic/claude-3"))
+
+	assert.True(t, ValidModelPattern("openai/gpt-4*"))
+	assert.False(t, ValidModelPattern("openai/gpt-[4"))
+}
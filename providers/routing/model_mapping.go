@@ -11,21 +11,21 @@ import (
 // the provider based on explicit naming conventions only. It returns both the detected provider
 // and the model name (which might be modified to strip provider prefixes).
 //
-// It only checks for explicit provider prefixes like "ollama/", "groq/", etc.
-// Implicit model name-based routing (like "gpt-" -> OpenAI) is not supported.
+// It checks for explicit provider prefixes like "ollama/", "groq/", etc., the
+// IDs of the built-in and custom providers, then for the ROUTING_RULES set
+// with SetRules. Implicit model name-based routing (like "gpt-" -> OpenAI) is
+// only done by those rules.
 //
-// Returns nil provider if no explicit provider prefix is found.
+// Returns nil provider if no explicit provider prefix or rule matches.
 // In such cases, the provider must be specified via query parameter.
 func DetermineProviderAndModelName(model string) (provider *types.Provider, modelName string) {
 	prefix, rest, ok := strings.Cut(model, "/")
-	if !ok {
-		return nil, model
+	if ok {
+		id := types.Provider(strings.ToLower(prefix))
+		if registry.Known(id) {
+			return &id, rest
+		}
 	}
 
-	id := types.Provider(strings.ToLower(prefix))
-	if _, exists := registry.Registry[id]; !exists {
-		return nil, model
-	}
-
-	return &id, rest
+	return matchRules(model)
 }
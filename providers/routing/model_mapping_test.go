@@ -41,6 +41,18 @@ func TestDetermineProviderAndModelNameEdgeCases(t *testing.T) {
 			expectedProvider: new(constants.CloudflareID),
 			expectedModel:    "@cf/meta/llama-2-7b-chat-fp16",
 		},
+		{
+			name:             "DeepSeek models route to DeepSeek",
+			model:            "deepseek/deepseek-reasoner",
+			expectedProvider: new(constants.DeepseekID),
+			expectedModel:    "deepseek-reasoner",
+		},
+		{
+			name:             "DeepSeek models hosted by Groq route to Groq",
+			model:            "groq/deepseek-r1-distill-llama-70b",
+			expectedProvider: new(constants.GroqID),
+			expectedModel:    "deepseek-r1-distill-llama-70b",
+		},
 		{
 			name:             "Model without explicit prefix",
 			model:            "gpt-4",
@@ -2,25 +2,37 @@ package routing
 
 import (
 	"fmt"
+	"hash/fnv"
 	"maps"
+	"math/rand/v2"
 	"os"
 	"slices"
 	"sync/atomic"
 
+	config "github.com/inference-gateway/inference-gateway/config"
 	registry "github.com/inference-gateway/inference-gateway/providers/registry"
 	types "github.com/inference-gateway/inference-gateway/providers/types"
 	yaml "gopkg.in/yaml.v3"
 )
 
-// StrategyRoundRobin is the only selection strategy supported in Phase 1. An
-// empty strategy in the config defaults to it.
-const StrategyRoundRobin = "round_robin"
+const (
+	// StrategyRoundRobin rotates through the deployments in order. An empty
+	// strategy in the config defaults to it.
+	StrategyRoundRobin = "round_robin"
+	// StrategyWeighted splits the traffic between the deployments by weight,
+	// for A/B tests and canary rollouts.
+	StrategyWeighted = "weighted"
+)
 
 // Deployment is one upstream backing a logical model alias: an already-configured
 // provider plus the upstream model name to send to it.
 type Deployment struct {
 	Provider string `yaml:"provider"`
 	Model    string `yaml:"model"`
+	// Weight is the deployment's share of the traffic under the weighted
+	// strategy, relative to the other weights (e.g. 90 and 10). Ignored by
+	// round-robin.
+	Weight int `yaml:"weight,omitempty"`
 }
 
 // PoolConfig is the on-disk shape of a single logical alias: the selection
@@ -28,6 +40,9 @@ type Deployment struct {
 type PoolConfig struct {
 	Strategy    string       `yaml:"strategy"`
 	Deployments []Deployment `yaml:"deployments"`
+	// Sticky keeps a user or session on the same weighted deployment, so a
+	// conversation does not switch variants between turns.
+	Sticky bool `yaml:"sticky,omitempty"`
 }
 
 // PoolsConfig is the on-disk routing file: logical alias -> pool.
@@ -39,11 +54,17 @@ type PoolsConfig struct {
 type pool struct {
 	deployments []Deployment
 	cursor      atomic.Uint64
+	weighted    bool
+	sticky      bool
+	// totalWeight is the sum of the deployment weights of a weighted pool
+	totalWeight int
 }
 
 // Selector resolves a logical model alias to an upstream deployment. Round-robin
 // state lives in each Selector (i.e. per replica), so under multiple gateway
 // replicas the rotation is best-effort per replica, not globally coordinated.
+// Sticky weighted assignment is a hash of the key and needs no shared state,
+// so it is consistent across replicas.
 type Selector struct {
 	pools map[string]*pool
 }
@@ -54,6 +75,25 @@ func LoadPoolsConfig(path string) (*PoolsConfig, error) {
 	if err != nil {
 		return nil, fmt.Errorf("read routing config: %w", err)
 	}
+	return ParsePoolsConfig(data)
+}
+
+// LoadPools reads the routing pools of cfg from ROUTING_CONFIG_PATH, or from
+// the routing section of a YAML or JSON CONFIG_FILE when it is not set
+func LoadPools(cfg config.Config) (*PoolsConfig, error) {
+	if cfg.Routing.ConfigPath == "" && config.IsStructuredFile(cfg.ConfigFile) {
+		data, err := config.ReadFileSection(cfg.ConfigFile, "routing")
+		if err != nil {
+			return nil, err
+		}
+		return ParsePoolsConfig(data)
+	}
+	return LoadPoolsConfig(cfg.Routing.ConfigPath)
+}
+
+// ParsePoolsConfig parses routing YAML. Keys other than models are ignored,
+// so the routing section of a YAML or JSON CONFIG_FILE parses as well.
+func ParsePoolsConfig(data []byte) (*PoolsConfig, error) {
 	var cfg PoolsConfig
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("parse routing config: %w", err)
@@ -62,30 +102,47 @@ func LoadPoolsConfig(path string) (*PoolsConfig, error) {
 }
 
 // NewSelector builds a Selector from parsed pools, validating that every alias
-// has a supported strategy, at least two deployments to rotate over, and
-// references a known provider. It returns an error rather than start routing to
-// a broken pool.
+// has a supported strategy, at least two deployments to rotate over or a
+// positive total weight to split, and references a known provider. It returns
+// an error rather than start routing to a broken pool.
 func NewSelector(cfg *PoolsConfig) (*Selector, error) {
 	if cfg == nil || len(cfg.Models) == 0 {
 		return nil, fmt.Errorf("routing enabled but no models configured")
 	}
 	pools := make(map[string]*pool, len(cfg.Models))
 	for alias, pc := range cfg.Models {
-		if pc.Strategy != "" && pc.Strategy != StrategyRoundRobin {
-			return nil, fmt.Errorf("model %q: unsupported strategy %q (only %q is supported)", alias, pc.Strategy, StrategyRoundRobin)
-		}
-		if len(pc.Deployments) < 2 {
-			return nil, fmt.Errorf("model %q: round-robin requires at least 2 deployments, got %d", alias, len(pc.Deployments))
+		p := &pool{deployments: pc.Deployments, sticky: pc.Sticky}
+		switch pc.Strategy {
+		case "", StrategyRoundRobin:
+			if len(pc.Deployments) < 2 {
+				return nil, fmt.Errorf("model %q: round-robin requires at least 2 deployments, got %d", alias, len(pc.Deployments))
+			}
+			if pc.Sticky {
+				return nil, fmt.Errorf("model %q: sticky requires the %q strategy", alias, StrategyWeighted)
+			}
+		case StrategyWeighted:
+			p.weighted = true
+			for i, d := range pc.Deployments {
+				if d.Weight < 0 {
+					return nil, fmt.Errorf("model %q deployment %d: weight must not be negative, got %d", alias, i, d.Weight)
+				}
+				p.totalWeight += d.Weight
+			}
+			if p.totalWeight == 0 {
+				return nil, fmt.Errorf("model %q: weighted requires at least one deployment with a positive weight", alias)
+			}
+		default:
+			return nil, fmt.Errorf("model %q: unsupported strategy %q (supported: %q, %q)", alias, pc.Strategy, StrategyRoundRobin, StrategyWeighted)
 		}
 		for i, d := range pc.Deployments {
 			if d.Provider == "" || d.Model == "" {
 				return nil, fmt.Errorf("model %q deployment %d: provider and model are required", alias, i)
 			}
-			if _, ok := registry.Registry[types.Provider(d.Provider)]; !ok {
+			if !registry.Known(types.Provider(d.Provider)) {
 				return nil, fmt.Errorf("model %q deployment %d: unknown provider %q", alias, i, d.Provider)
 			}
 		}
-		pools[alias] = &pool{deployments: pc.Deployments}
+		pools[alias] = p
 	}
 	return &Selector{pools: pools}, nil
 }
@@ -96,12 +153,63 @@ func NewSelector(cfg *PoolsConfig) (*Selector, error) {
 // Selector (per replica), not globally coordinated; a shared-store
 // implementation is deferred to a later phase (#397).
 func (s *Selector) Select(alias string) (deployment Deployment, ok bool) {
+	return s.SelectAvailable(alias, nil)
+}
+
+// SelectAvailable is Select skipping the deployments available reports as
+// unavailable, e.g. because their provider asked to back off. When none is
+// available the round-robin pick is returned anyway, so the caller still
+// answers with the deployment's error. A nil available accepts all of them.
+func (s *Selector) SelectAvailable(alias string, available func(Deployment) bool) (deployment Deployment, ok bool) {
+	return s.SelectFor(alias, "", available)
+}
+
+// SelectFor is SelectAvailable for the user or session identified by key.
+// Sticky weighted pools send the same key to the same deployment for as long
+// as the pool's weights are unchanged; an empty key, or any other pool,
+// selects as SelectAvailable. When the picked deployment is unavailable the
+// next available one in config order is returned instead.
+func (s *Selector) SelectFor(alias, key string, available func(Deployment) bool) (deployment Deployment, ok bool) {
 	p, found := s.pools[alias]
 	if !found {
 		return Deployment{}, false
 	}
-	i := p.cursor.Add(1) - 1
-	return p.deployments[i%uint64(len(p.deployments))], true
+	var i uint64
+	if p.weighted {
+		i = p.pick(alias, key)
+	} else {
+		i = p.cursor.Add(1) - 1
+	}
+	n := uint64(len(p.deployments))
+	if available != nil {
+		for offset := range n {
+			if d := p.deployments[(i+offset)%n]; available(d) && (!p.weighted || d.Weight > 0) {
+				return d, true
+			}
+		}
+	}
+	return p.deployments[i%n], true
+}
+
+// pick returns the index of a weighted deployment: the one whose weight range
+// covers a point drawn at random, or hashed from alias and key when the pool
+// is sticky.
+func (p *pool) pick(alias, key string) uint64 {
+	var point int
+	if p.sticky && key != "" {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(alias + "\x00" + key))
+		point = int(h.Sum64() % uint64(p.totalWeight))
+	} else {
+		point = rand.IntN(p.totalWeight)
+	}
+	for i, d := range p.deployments {
+		if point < d.Weight {
+			return uint64(i)
+		}
+		point -= d.Weight
+	}
+	return 0
 }
 
 // Aliases returns the configured logical model names, for startup logging.
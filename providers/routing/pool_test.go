@@ -1,6 +1,7 @@
 package routing
 
 import (
+	"fmt"
 	"sync"
 	"testing"
 
@@ -33,6 +34,25 @@ func TestSelectRoundRobinRotation(t *testing.T) {
 	}
 }
 
+func TestSelectAvailableSkipsBackingOffDeployments(t *testing.T) {
+	d0 := Deployment{Provider: "groq", Model: "llama-3.3-70b-versatile"}
+	d1 := Deployment{Provider: "openai", Model: "gpt-4o-mini"}
+	sel := poolFor(t, d0, d1)
+
+	onlyOpenAI := func(d Deployment) bool { return d.Provider == "openai" }
+	for i := range 3 {
+		got, ok := sel.SelectAvailable("fast-chat", onlyOpenAI)
+		require.True(t, ok)
+		assert.Equal(t, d1, got, "call %d", i)
+	}
+
+	// With nothing available the rotation goes on
+	none := func(Deployment) bool { return false }
+	first, _ := sel.SelectAvailable("fast-chat", none)
+	second, _ := sel.SelectAvailable("fast-chat", none)
+	assert.NotEqual(t, first, second)
+}
+
 func TestSelectUnknownAliasFallsThrough(t *testing.T) {
 	sel := poolFor(t,
 		Deployment{Provider: "groq", Model: "llama-3.3-70b-versatile"},
@@ -64,7 +84,19 @@ func TestNewSelectorValidation(t *testing.T) {
 		{"no models", &PoolsConfig{Models: map[string]PoolConfig{}}},
 		{
 			"unsupported strategy",
-			&PoolsConfig{Models: map[string]PoolConfig{"a": {Strategy: "weighted", Deployments: []Deployment{{Provider: "groq", Model: "x"}}}}},
+			&PoolsConfig{Models: map[string]PoolConfig{"a": {Strategy: "least_latency", Deployments: []Deployment{{Provider: "groq", Model: "x"}, {Provider: "openai", Model: "y"}}}}},
+		},
+		{
+			"sticky round-robin",
+			&PoolsConfig{Models: map[string]PoolConfig{"a": {Sticky: true, Deployments: []Deployment{{Provider: "groq", Model: "x"}, {Provider: "openai", Model: "y"}}}}},
+		},
+		{
+			"negative weight",
+			&PoolsConfig{Models: map[string]PoolConfig{"a": {Strategy: StrategyWeighted, Deployments: []Deployment{{Provider: "groq", Model: "x", Weight: 10}, {Provider: "openai", Model: "y", Weight: -1}}}}},
+		},
+		{
+			"no positive weight",
+			&PoolsConfig{Models: map[string]PoolConfig{"a": {Strategy: StrategyWeighted, Deployments: []Deployment{{Provider: "groq", Model: "x"}}}}},
 		},
 		{
 			"no deployments",
@@ -122,3 +154,69 @@ func TestSelectConcurrent(t *testing.T) {
 	assert.Equal(t, int64(n/2), counts[0])
 	assert.Equal(t, int64(n/2), counts[1])
 }
+
+func weightedPool(t *testing.T, sticky bool, deployments ...Deployment) *Selector {
+	t.Helper()
+	sel, err := NewSelector(&PoolsConfig{
+		Models: map[string]PoolConfig{
+			"default-chat": {Strategy: StrategyWeighted, Sticky: sticky, Deployments: deployments},
+		},
+	})
+	require.NoError(t, err)
+	return sel
+}
+
+func TestSelectWeightedSplit(t *testing.T) {
+	stable := Deployment{Provider: "openai", Model: "gpt-4o", Weight: 90}
+	canary := Deployment{Provider: "anthropic", Model: "claude-sonnet-4-5", Weight: 10}
+	sel := weightedPool(t, false, stable, canary)
+
+	const n = 10000
+	var canaries int
+	for range n {
+		got, ok := sel.Select("default-chat")
+		require.True(t, ok)
+		if got == canary {
+			canaries++
+		}
+	}
+	assert.InDelta(t, n/10, canaries, n/50, "about 10%% of the traffic goes to the canary")
+}
+
+func TestSelectWeightedZeroWeightGetsNoTraffic(t *testing.T) {
+	stable := Deployment{Provider: "openai", Model: "gpt-4o", Weight: 1}
+	staged := Deployment{Provider: "anthropic", Model: "claude-sonnet-4-5"}
+	sel := weightedPool(t, false, stable, staged)
+
+	all := func(Deployment) bool { return true }
+	for i := range 100 {
+		got, ok := sel.SelectAvailable("default-chat", all)
+		require.True(t, ok)
+		assert.Equal(t, stable, got, "call %d", i)
+	}
+}
+
+func TestSelectForStickyAssignment(t *testing.T) {
+	a := Deployment{Provider: "openai", Model: "gpt-4o", Weight: 50}
+	b := Deployment{Provider: "anthropic", Model: "claude-sonnet-4-5", Weight: 50}
+	sel := weightedPool(t, true, a, b)
+
+	seen := make(map[Deployment]bool)
+	for user := range 50 {
+		key := fmt.Sprintf("user:%d", user)
+		first, ok := sel.SelectFor("default-chat", key, nil)
+		require.True(t, ok)
+		seen[first] = true
+		for range 5 {
+			got, _ := sel.SelectFor("default-chat", key, nil)
+			assert.Equal(t, first, got, "key %s switched variants", key)
+		}
+	}
+	assert.Len(t, seen, 2, "keys are spread over both variants")
+
+	// A sticky pick that is backing off falls over to the other variant
+	key := "user:0"
+	picked, _ := sel.SelectFor("default-chat", key, nil)
+	got, _ := sel.SelectFor("default-chat", key, func(d Deployment) bool { return d != picked })
+	assert.NotEqual(t, picked, got)
+}
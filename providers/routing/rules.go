@@ -0,0 +1,106 @@
+package routing
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// Rule routes the models matching a pattern of ROUTING_RULES to a provider
+type Rule struct {
+	Pattern  string
+	Provider types.Provider
+	// prefix is the model name prefix of a pattern ending in *, stripped
+	// from the model when strip is set
+	prefix string
+	strip  bool
+	re     *regexp.Regexp
+}
+
+// rules are the ROUTING_RULES DetermineProviderAndModelName falls back to,
+// replaced on every reload
+var rules atomic.Pointer[[]Rule]
+
+// ParseRules parses ROUTING_RULES, a comma-separated list of pattern=provider
+// pairs. The providers must be known, so SetCustomProviders must have been
+// called with the custom providers of the configuration.
+func ParseRules(list string) ([]Rule, error) {
+	var parsed []Rule
+	for entry := range strings.SplitSeq(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		i := strings.LastIndex(entry, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid ROUTING_RULES entry %q, expected pattern=provider", entry)
+		}
+		rule := Rule{Pattern: strings.TrimSpace(entry[:i]), Provider: types.Provider(strings.TrimSpace(entry[i+1:]))}
+		if !registry.Known(rule.Provider) {
+			return nil, fmt.Errorf("invalid ROUTING_RULES entry %q: unknown provider %q", entry, rule.Provider)
+		}
+		switch {
+		case len(rule.Pattern) > 2 && strings.HasPrefix(rule.Pattern, "/") && strings.HasSuffix(rule.Pattern, "/"):
+			re, err := regexp.Compile(rule.Pattern[1 : len(rule.Pattern)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid ROUTING_RULES entry %q: %w", entry, err)
+			}
+			rule.re = re
+		case strings.HasSuffix(rule.Pattern, "*"):
+			rule.prefix = strings.TrimSuffix(rule.Pattern, "*")
+			rule.strip = strings.HasSuffix(rule.prefix, "/")
+			if rule.prefix == "" || strings.Contains(rule.prefix, "*") {
+				return nil, fmt.Errorf("invalid ROUTING_RULES entry %q: expected a model name prefix before *", entry)
+			}
+		case rule.Pattern == "" || strings.Contains(rule.Pattern, "*"):
+			return nil, fmt.Errorf("invalid ROUTING_RULES entry %q: * is only allowed at the end of a pattern", entry)
+		}
+		parsed = append(parsed, rule)
+	}
+	return parsed, nil
+}
+
+// SetRules replaces the rules DetermineProviderAndModelName falls back to
+func SetRules(r []Rule) {
+	rules.Store(&r)
+}
+
+// Match reports whether model matches the rule, and returns the model name
+// to send to the provider
+func (r Rule) Match(model string) (string, bool) {
+	switch {
+	case r.re != nil:
+		return model, r.re.MatchString(model)
+	case r.prefix != "":
+		rest, ok := strings.CutPrefix(model, r.prefix)
+		if !ok || rest == "" {
+			return "", false
+		}
+		if r.strip {
+			return rest, true
+		}
+		return model, true
+	default:
+		return model, model == r.Pattern
+	}
+}
+
+// matchRules returns the provider of the first rule model matches and the
+// model name to send to it, nil when none does
+func matchRules(model string) (*types.Provider, string) {
+	current := rules.Load()
+	if current == nil {
+		return nil, model
+	}
+	for _, rule := range *current {
+		if name, ok := rule.Match(model); ok {
+			provider := rule.Provider
+			return &provider, name
+		}
+	}
+	return nil, model
+}
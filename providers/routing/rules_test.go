@@ -0,0 +1,75 @@
+package routing
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules(" gpt-*=openai, oai/*=openai,/^llama-3[.][0-9]/=groq,,command-r=cohere ")
+	require.NoError(t, err)
+	require.Len(t, rules, 4)
+	assert.Equal(t, "/^llama-3[.][0-9]/", rules[2].Pattern)
+	assert.Equal(t, constants.GroqID, rules[2].Provider)
+
+	for name, list := range map[string]string{
+		"missing provider":   "gpt-*",
+		"unknown provider":   "gpt-*=closedai",
+		"invalid regexp":     "/[a-/=groq",
+		"wildcard in middle": "gpt-*-mini=openai",
+		"wildcard only":      "*=openai",
+		"empty pattern":      "=openai",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseRules(list)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDetermineProviderAndModelNameWithRules(t *testing.T) {
+	registry.SetCustomProviders(map[types.Provider]*registry.ProviderConfig{"vllm": {ID: "vllm"}})
+	rules, err := ParseRules("gpt-*=openai,oai/*=openai,/^llama-3[.][0-9]/=groq,command-r=cohere,qwen*=vllm")
+	require.NoError(t, err)
+	SetRules(rules)
+	t.Cleanup(func() {
+		SetRules(nil)
+		registry.SetCustomProviders(nil)
+	})
+
+	tests := []struct {
+		model            string
+		expectedProvider types.Provider
+		expectedModel    string
+	}{
+		{model: "gpt-4o", expectedProvider: constants.OpenaiID, expectedModel: "gpt-4o"},
+		{model: "oai/gpt-4o", expectedProvider: constants.OpenaiID, expectedModel: "gpt-4o"},
+		{model: "llama-3.3-70b-versatile", expectedProvider: constants.GroqID, expectedModel: "llama-3.3-70b-versatile"},
+		{model: "command-r", expectedProvider: constants.CohereID, expectedModel: "command-r"},
+		{model: "qwen2.5-7b-instruct", expectedProvider: "vllm", expectedModel: "qwen2.5-7b-instruct"},
+		{model: "vllm/qwen2.5-7b-instruct", expectedProvider: "vllm", expectedModel: "qwen2.5-7b-instruct"},
+		{model: "groq/gpt-oss-120b", expectedProvider: constants.GroqID, expectedModel: "gpt-oss-120b"},
+		{model: "command-r-plus"},
+		{model: "oai/"},
+		{model: "llama-2"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.model, func(t *testing.T) {
+			provider, model := DetermineProviderAndModelName(tc.model)
+			if tc.expectedProvider == "" {
+				assert.Nil(t, provider)
+				assert.Equal(t, tc.model, model)
+				return
+			}
+			require.NotNil(t, provider)
+			assert.Equal(t, tc.expectedProvider, *provider)
+			assert.Equal(t, tc.expectedModel, model)
+		})
+	}
+}
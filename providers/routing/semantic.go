@@ -0,0 +1,269 @@
+package routing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+
+	client "github.com/inference-gateway/inference-gateway/providers/client"
+	core "github.com/inference-gateway/inference-gateway/providers/core"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// DefaultSemanticThreshold is the minimum cosine similarity between a prompt
+// and a route description when the config does not set one.
+const DefaultSemanticThreshold = 0.4
+
+// SemanticRoute sends prompts similar to Description to Model, given in the
+// provider/model format.
+type SemanticRoute struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Model       string `yaml:"model"`
+}
+
+// EmbeddingConfig names the provider and model embedding prompts and route
+// descriptions. The provider must serve an OpenAI-compatible /embeddings
+// endpoint.
+type EmbeddingConfig struct {
+	Provider string `yaml:"provider"`
+	Model    string `yaml:"model"`
+}
+
+// SemanticConfig is the on-disk semantic routing file. Models lists the
+// requested models that are routed by intent; when empty every chat request
+// is.
+type SemanticConfig struct {
+	Embedding EmbeddingConfig `yaml:"embedding"`
+	Threshold float64         `yaml:"threshold"`
+	Models    []string        `yaml:"models"`
+	Routes    []SemanticRoute `yaml:"routes"`
+}
+
+// Embedder returns one embedding vector per text, in order.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// SemanticRouter picks the route whose description is most similar to a
+// prompt. Route descriptions are embedded on first use rather than at
+// startup, because the embedding requests go through the gateway's own
+// proxy, which is not serving yet while the router is built.
+type SemanticRouter struct {
+	threshold float64
+	models    []string
+	routes    []SemanticRoute
+	embedder  Embedder
+
+	mu      sync.Mutex
+	vectors [][]float64
+}
+
+// LoadSemanticConfig reads and parses the semantic routing YAML file at path.
+func LoadSemanticConfig(path string) (*SemanticConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read semantic routing config: %w", err)
+	}
+	var cfg SemanticConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse semantic routing config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// NewSemanticRouter builds a SemanticRouter from a parsed config, validating
+// the threshold and that every route has a name, a description and a model
+// with a known provider prefix. A nil embedder embeds through the gateway
+// proxy of the configured embedding provider with httpClient.
+func NewSemanticRouter(cfg *SemanticConfig, httpClient client.Client, embedder Embedder) (*SemanticRouter, error) {
+	if cfg == nil || len(cfg.Routes) == 0 {
+		return nil, fmt.Errorf("semantic routing enabled but no routes configured")
+	}
+	threshold := cfg.Threshold
+	if threshold == 0 {
+		threshold = DefaultSemanticThreshold
+	}
+	if threshold < 0 || threshold > 1 {
+		return nil, fmt.Errorf("threshold must be between 0 and 1, got %v", cfg.Threshold)
+	}
+	for i, route := range cfg.Routes {
+		if route.Name == "" || route.Description == "" || route.Model == "" {
+			return nil, fmt.Errorf("route %d: name, description and model are required", i)
+		}
+		if provider, _ := DetermineProviderAndModelName(route.Model); provider == nil {
+			return nil, fmt.Errorf("route %d: model %q must use the provider/model format", i, route.Model)
+		}
+	}
+	if embedder == nil {
+		if cfg.Embedding.Provider == "" || cfg.Embedding.Model == "" {
+			return nil, fmt.Errorf("embedding provider and model are required")
+		}
+		if !registry.Known(types.Provider(cfg.Embedding.Provider)) {
+			return nil, fmt.Errorf("unknown embedding provider %q", cfg.Embedding.Provider)
+		}
+		embedder = NewProxyEmbedder(httpClient, cfg.Embedding.Provider, cfg.Embedding.Model)
+	}
+
+	return &SemanticRouter{
+		threshold: threshold,
+		models:    cfg.Models,
+		routes:    cfg.Routes,
+		embedder:  embedder,
+	}, nil
+}
+
+// Applies reports whether requests for model are routed by intent.
+func (r *SemanticRouter) Applies(model string) bool {
+	return len(r.models) == 0 || slices.Contains(r.models, model)
+}
+
+// Route returns the route most similar to prompt and its similarity. ok is
+// false when no route reaches the threshold, so callers keep the requested
+// model.
+func (r *SemanticRouter) Route(ctx context.Context, prompt string) (route SemanticRoute, score float64, ok bool, err error) {
+	vectors, err := r.routeVectors(ctx)
+	if err != nil {
+		return SemanticRoute{}, 0, false, err
+	}
+	embedded, err := r.embedder.Embed(ctx, []string{prompt})
+	if err != nil {
+		return SemanticRoute{}, 0, false, err
+	}
+	if len(embedded) != 1 {
+		return SemanticRoute{}, 0, false, fmt.Errorf("expected 1 embedding, got %d", len(embedded))
+	}
+
+	best := -1
+	for i, vector := range vectors {
+		if s := cosine(embedded[0], vector); best < 0 || s > score {
+			best, score = i, s
+		}
+	}
+	if score < r.threshold {
+		return SemanticRoute{}, score, false, nil
+	}
+	return r.routes[best], score, true, nil
+}
+
+// routeVectors embeds the route descriptions once. Failures are not cached,
+// so the next request tries again.
+func (r *SemanticRouter) routeVectors(ctx context.Context) ([][]float64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.vectors != nil {
+		return r.vectors, nil
+	}
+
+	descriptions := make([]string, len(r.routes))
+	for i, route := range r.routes {
+		descriptions[i] = route.Description
+	}
+	vectors, err := r.embedder.Embed(ctx, descriptions)
+	if err != nil {
+		return nil, fmt.Errorf("embed route descriptions: %w", err)
+	}
+	if len(vectors) != len(descriptions) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(descriptions), len(vectors))
+	}
+	r.vectors = vectors
+	return vectors, nil
+}
+
+// cosine returns the cosine similarity of a and b, or 0 for vectors of
+// different lengths or zero length
+func cosine(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// proxyEmbedder calls the OpenAI-compatible /embeddings endpoint of a
+// provider through the gateway proxy, which adds the provider's credentials.
+type proxyEmbedder struct {
+	client   client.Client
+	provider string
+	model    string
+}
+
+// NewProxyEmbedder returns an Embedder calling the /embeddings endpoint of
+// provider with model through the gateway proxy
+func NewProxyEmbedder(httpClient client.Client, provider, model string) Embedder {
+	return &proxyEmbedder{client: httpClient, provider: provider, model: model}
+}
+
+type embeddingsRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embeddingsResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (e *proxyEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	body, err := json.Marshal(embeddingsRequest{Model: e.model, Input: texts})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/proxy/"+e.provider+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// The proxy is subject to the gateway's auth like any other route
+	if authToken, ok := ctx.Value(types.AuthTokenContextKey).(string); ok && authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+authToken)
+	}
+	core.SetInternalHeaders(ctx, req)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("embeddings request failed with status %d: %s", resp.StatusCode, data)
+	}
+
+	var decoded embeddingsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("decode embeddings response: %w", err)
+	}
+	vectors := make([][]float64, len(texts))
+	for _, d := range decoded.Data {
+		if d.Index < 0 || d.Index >= len(vectors) {
+			return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	for i, v := range vectors {
+		if v == nil {
+			return nil, fmt.Errorf("missing embedding %d", i)
+		}
+	}
+	return vectors, nil
+}
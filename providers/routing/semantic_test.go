@@ -0,0 +1,180 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+)
+
+// keywordEmbedder embeds a text as keyword counts over a fixed vocabulary,
+// so similarity follows the shared keywords
+type keywordEmbedder struct {
+	fail  bool
+	calls int
+}
+
+var vocabulary = []string{"code", "function", "story", "poem", "weather"}
+
+func (e *keywordEmbedder) Embed(_ context.Context, texts []string) ([][]float64, error) {
+	e.calls++
+	if e.fail {
+		return nil, errors.New("embedding provider unavailable")
+	}
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i] = make([]float64, len(vocabulary))
+		for j, word := range vocabulary {
+			vectors[i][j] = float64(strings.Count(strings.ToLower(text), word))
+		}
+	}
+	return vectors, nil
+}
+
+func semanticConfig() *SemanticConfig {
+	return &SemanticConfig{
+		Threshold: 0.5,
+		Routes: []SemanticRoute{
+			{Name: "coding", Description: "write code and functions", Model: "deepseek/deepseek-chat"},
+			{Name: "creative", Description: "write a story or a poem", Model: "anthropic/claude-sonnet-4-5"},
+		},
+	}
+}
+
+func TestSemanticRouterRoute(t *testing.T) {
+	router, err := NewSemanticRouter(semanticConfig(), nil, &keywordEmbedder{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		prompt string
+		route  string
+		ok     bool
+	}{
+		{"coding prompt", "Write a Go function that parses code", "coding", true},
+		{"creative prompt", "Tell me a story about a dragon", "creative", true},
+		{"unrelated prompt falls back", "What is the weather today?", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route, _, ok, err := router.Route(context.Background(), tt.prompt)
+			require.NoError(t, err)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.route, route.Name)
+		})
+	}
+}
+
+func TestSemanticRouterEmbedsDescriptionsOnce(t *testing.T) {
+	embedder := &keywordEmbedder{fail: true}
+	router, err := NewSemanticRouter(semanticConfig(), nil, embedder)
+	require.NoError(t, err)
+
+	_, _, _, err = router.Route(context.Background(), "write code")
+	require.Error(t, err)
+
+	embedder.fail = false
+	embedder.calls = 0
+	for range 3 {
+		_, _, ok, err := router.Route(context.Background(), "write code")
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+	assert.Equal(t, 4, embedder.calls, "descriptions are embedded once after the failure, then one call per prompt")
+}
+
+func TestSemanticRouterApplies(t *testing.T) {
+	cfg := semanticConfig()
+	router, err := NewSemanticRouter(cfg, nil, &keywordEmbedder{})
+	require.NoError(t, err)
+	assert.True(t, router.Applies("openai/gpt-4o"), "an empty model list routes every request")
+
+	cfg.Models = []string{"auto"}
+	router, err = NewSemanticRouter(cfg, nil, &keywordEmbedder{})
+	require.NoError(t, err)
+	assert.True(t, router.Applies("auto"))
+	assert.False(t, router.Applies("openai/gpt-4o"))
+}
+
+func TestNewSemanticRouterValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*SemanticConfig)
+		errMsg string
+	}{
+		{"no routes", func(c *SemanticConfig) { c.Routes = nil }, "no routes configured"},
+		{"threshold above 1", func(c *SemanticConfig) { c.Threshold = 1.5 }, "threshold must be between 0 and 1"},
+		{"route without name", func(c *SemanticConfig) { c.Routes[0].Name = "" }, "name, description and model are required"},
+		{"route without provider prefix", func(c *SemanticConfig) { c.Routes[0].Model = "deepseek-chat" }, "provider/model format"},
+		{"missing embedding model", func(c *SemanticConfig) { c.Embedding = EmbeddingConfig{Provider: "openai"} }, "embedding provider and model are required"},
+		{"unknown embedding provider", func(c *SemanticConfig) { c.Embedding = EmbeddingConfig{Provider: "nope", Model: "m"} }, "unknown embedding provider"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := semanticConfig()
+			tt.mutate(cfg)
+			var embedder Embedder = &keywordEmbedder{}
+			if strings.Contains(tt.name, "embedding") {
+				embedder = nil
+			}
+			_, err := NewSemanticRouter(cfg, nil, embedder)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+// serverClient sends the gateway-relative requests of the proxy embedder to a
+// test server
+type serverClient struct {
+	url string
+}
+
+func (c serverClient) Do(req *http.Request) (*http.Response, error) {
+	target, err := http.NewRequestWithContext(req.Context(), req.Method, c.url+req.URL.Path, req.Body)
+	if err != nil {
+		return nil, err
+	}
+	target.Header = req.Header
+	return http.DefaultClient.Do(target)
+}
+
+func (c serverClient) Get(url string) (*http.Response, error) {
+	return http.Get(c.url + url)
+}
+
+func (c serverClient) Post(url string, bodyType string, body string) (*http.Response, error) {
+	return http.Post(c.url+url, bodyType, strings.NewReader(body))
+}
+
+func TestProxyEmbedder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/proxy/openai/embeddings", r.URL.Path)
+		var req embeddingsRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "text-embedding-3-small", req.Model)
+
+		// Out of order, as the index field allows
+		data := make([]map[string]any, len(req.Input))
+		for i := range req.Input {
+			data[len(req.Input)-1-i] = map[string]any{"index": i, "embedding": []float64{float64(i), 1}}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	defer server.Close()
+
+	cfg := semanticConfig()
+	cfg.Embedding = EmbeddingConfig{Provider: "openai", Model: "text-embedding-3-small"}
+	router, err := NewSemanticRouter(cfg, serverClient{url: server.URL}, nil)
+	require.NoError(t, err)
+
+	vectors, err := router.embedder.Embed(context.Background(), []string{"a", "b", "c"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float64{{0, 1}, {1, 1}, {2, 1}}, vectors)
+}
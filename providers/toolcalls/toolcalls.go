@@ -0,0 +1,122 @@
+// Package toolcalls assembles the tool calls of streamed chat completions
+// from their per-chunk deltas.
+//
+// Providers differ in how they split a tool call across chunks: OpenAI sends
+// the ID and name first and the arguments in many small fragments, Groq
+// sends each call whole in one chunk, and some OpenAI-compatible APIs number
+// every call 0 and tell them apart by ID only. The Accumulator handles all of
+// them, one chunk at a time.
+package toolcalls
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// Accumulator assembles tool calls from the chunks of one streamed chat
+// completion. Only the first choice is read. The zero value is ready to use.
+type Accumulator struct {
+	calls []*types.ChatCompletionMessageToolCall
+	// byIndex and byID map the index and ID of a delta to the call it
+	// continues, a position in calls
+	byIndex map[int]int
+	byID    map[string]int
+}
+
+// AddChunk accumulates the tool call deltas of the first choice of chunk
+func (a *Accumulator) AddChunk(chunk types.CreateChatCompletionStreamResponse) {
+	if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.ToolCalls == nil {
+		return
+	}
+	for _, delta := range *chunk.Choices[0].Delta.ToolCalls {
+		a.AddDelta(delta)
+	}
+}
+
+// AddLine accumulates the tool call deltas of an SSE line, with or without
+// its "data: " prefix. Lines that are not chunks are ignored; it reports
+// whether line was one.
+func (a *Accumulator) AddLine(line []byte) bool {
+	line = bytes.TrimSpace(line)
+	data, _ := bytes.CutPrefix(line, []byte("data:"))
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return false
+	}
+	var chunk types.CreateChatCompletionStreamResponse
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		return false
+	}
+	a.AddChunk(chunk)
+	return true
+}
+
+// AddDelta accumulates one tool call delta. A delta continues the call of
+// the same ID, or without an ID the last call of its index; a new ID starts
+// a new call even when its index is taken.
+func (a *Accumulator) AddDelta(delta types.ChatCompletionMessageToolCallChunk) {
+	if a.byIndex == nil {
+		a.byIndex = make(map[int]int)
+		a.byID = make(map[string]int)
+	}
+
+	var id string
+	if delta.ID != nil {
+		id = *delta.ID
+	}
+	pos, ok := a.byID[id]
+	if id == "" || !ok {
+		pos, ok = a.byIndex[delta.Index]
+		if ok && id != "" && a.calls[pos].ID != "" {
+			// The index is reused by a call of another ID
+			ok = false
+		}
+	}
+	if !ok {
+		pos = len(a.calls)
+		a.calls = append(a.calls, &types.ChatCompletionMessageToolCall{Type: types.Function})
+	}
+	a.byIndex[delta.Index] = pos
+
+	call := a.calls[pos]
+	if id != "" {
+		call.ID = id
+		a.byID[id] = pos
+	}
+	if delta.ExtraContent != nil {
+		call.ExtraContent = delta.ExtraContent
+	}
+	if delta.Type != nil && *delta.Type != "" {
+		call.Type = types.ChatCompletionToolType(*delta.Type)
+	}
+	if delta.Function != nil {
+		if delta.Function.Name != "" {
+			call.Function.Name = delta.Function.Name
+		}
+		call.Function.Arguments += delta.Function.Arguments
+	}
+}
+
+// ToolCalls returns the calls accumulated so far in the order they started.
+// Calls that never received a function name are left out.
+func (a *Accumulator) ToolCalls() []types.ChatCompletionMessageToolCall {
+	var calls []types.ChatCompletionMessageToolCall
+	for _, call := range a.calls {
+		if call.Function.Name != "" {
+			calls = append(calls, *call)
+		}
+	}
+	return calls
+}
+
+// Accumulate returns the tool calls of an SSE stream body
+func Accumulate(body string) []types.ChatCompletionMessageToolCall {
+	var a Accumulator
+	for line := range strings.SplitSeq(body, "\n") {
+		a.AddLine([]byte(line))
+	}
+	return a.ToolCalls()
+}
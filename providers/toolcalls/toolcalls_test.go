@@ -0,0 +1,144 @@
+package toolcalls_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	toolcalls "github.com/inference-gateway/inference-gateway/providers/toolcalls"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func call(id, name, arguments string) types.ChatCompletionMessageToolCall {
+	return types.ChatCompletionMessageToolCall{
+		ID:       id,
+		Type:     types.Function,
+		Function: types.ChatCompletionMessageToolCallFunction{Name: name, Arguments: arguments},
+	}
+}
+
+func TestAccumulate_ProviderFixtures(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		expected []types.ChatCompletionMessageToolCall
+	}{
+		{
+			fixture: "openai.sse",
+			expected: []types.ChatCompletionMessageToolCall{
+				call("call_weather", "get_weather", `{"location": "Paris"}`),
+				call("call_time", "get_time", `{"timezone": "Europe/Paris"}`),
+			},
+		},
+		{
+			fixture: "groq.sse",
+			expected: []types.ChatCompletionMessageToolCall{
+				call("call_d5wg", "get_weather", `{"location":"Paris"}`),
+				call("call_fy0j", "get_time", `{"timezone":"Europe/Paris"}`),
+			},
+		},
+		{
+			fixture: "anthropic.sse",
+			expected: []types.ChatCompletionMessageToolCall{
+				call("toolu_01T1x1fJ34qAmk2tNTrN7Up6", "get_weather", `{"location": "Paris"}`),
+				call("toolu_01VU2jvL3mDfRPW8x9dcF2sK", "get_time", `{"timezone": "Europe/Paris"}`),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			body, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, toolcalls.Accumulate(string(body)))
+		})
+	}
+}
+
+func TestAccumulate(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []types.ChatCompletionMessageToolCall
+	}{
+		{
+			name: "arguments split across many chunks",
+			body: `data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_123","type":"function","function":{"name":"mcp_test_tool"}}]}}]}
+data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{"}}]}}]}
+data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"arg1\""}}]}}]}
+data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":":\"value1\","}}]}}]}
+data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"arg2\":42}"}}]}}]}
+data: [DONE]`,
+			expected: []types.ChatCompletionMessageToolCall{call("call_123", "mcp_test_tool", `{"arg1":"value1","arg2":42}`)},
+		},
+		{
+			name: "interleaved tool calls",
+			body: `data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"tool_one"}},{"index":1,"id":"call_2","type":"function","function":{"name":"tool_two"}}]}}]}
+data: {"choices":[{"delta":{"tool_calls":[{"index":1,"function":{"arguments":"{\"y\""}}]}}]}
+data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"x\""}}]}}]}
+data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":":1}"}},{"index":1,"function":{"arguments":":2}"}}]}}]}`,
+			expected: []types.ChatCompletionMessageToolCall{
+				call("call_1", "tool_one", `{"x":1}`),
+				call("call_2", "tool_two", `{"y":2}`),
+			},
+		},
+		{
+			name: "IDs distinguish calls sharing an index",
+			body: `data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_a","type":"function","function":{"name":"tool_one","arguments":"{\"x\":1}"}}]}}]}
+data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_b","type":"function","function":{"name":"tool_two","arguments":"{\"y\":"}}]}}]}
+data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"2}"}}]}}]}`,
+			expected: []types.ChatCompletionMessageToolCall{
+				call("call_a", "tool_one", `{"x":1}`),
+				call("call_b", "tool_two", `{"y":2}`),
+			},
+		},
+		{
+			name:     "indices need not start at 0",
+			body:     `data: {"choices":[{"delta":{"tool_calls":[{"index":2,"id":"call_1","type":"function","function":{"name":"tool_one","arguments":"{}"}}]}}]}`,
+			expected: []types.ChatCompletionMessageToolCall{call("call_1", "tool_one", `{}`)},
+		},
+		{
+			name:     "lines without the data prefix",
+			body:     `{"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"tool_one","arguments":"{}"}}]}}]}`,
+			expected: []types.ChatCompletionMessageToolCall{call("call_1", "tool_one", `{}`)},
+		},
+		{
+			name:     "content-only stream has no tool calls",
+			body:     `data: {"choices":[{"delta":{"content":"hello"}}]}`,
+			expected: nil,
+		},
+		{
+			name:     "unnamed tool calls are dropped",
+			body:     `data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{}"}}]}}]}`,
+			expected: nil,
+		},
+		{
+			name:     "malformed chunks are skipped",
+			body:     "data: not-json\ndata: {\"choices\":\ndata: [DONE]\n: keep-alive",
+			expected: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, toolcalls.Accumulate(tt.body))
+		})
+	}
+}
+
+func TestAccumulator_Incremental(t *testing.T) {
+	var acc toolcalls.Accumulator
+	assert.Empty(t, acc.ToolCalls())
+
+	id := "call_1"
+	name := "get_weather"
+	acc.AddDelta(types.ChatCompletionMessageToolCallChunk{Index: 0, ID: &id, Function: &types.ChatCompletionMessageToolCallFunction{Name: name}})
+	assert.Equal(t, []types.ChatCompletionMessageToolCall{call(id, name, "")}, acc.ToolCalls())
+
+	assert.True(t, acc.AddLine([]byte(`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{}"},"extra_content":{"google":{"thought_signature":"sig"}}}]}}]}`)))
+	assert.False(t, acc.AddLine([]byte("data: [DONE]")))
+	calls := acc.ToolCalls()
+	require.Len(t, calls, 1)
+	assert.Equal(t, `{}`, calls[0].Function.Arguments)
+	assert.NotNil(t, calls[0].ExtraContent, "extra_content is echoed back to the provider")
+}
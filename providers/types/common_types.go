@@ -124,16 +124,19 @@ func (e CreateMessagesRequestThinkingType) Valid() bool {
 
 // Defines values for FinishReason.
 const (
-	ContentFilter FinishReason = "content_filter"
-	FunctionCall  FinishReason = "function_call"
-	Length        FinishReason = "length"
-	Stop          FinishReason = "stop"
-	ToolCalls     FinishReason = "tool_calls"
+	BudgetExceeded FinishReason = "budget_exceeded"
+	ContentFilter  FinishReason = "content_filter"
+	FunctionCall   FinishReason = "function_call"
+	Length         FinishReason = "length"
+	Stop           FinishReason = "stop"
+	ToolCalls      FinishReason = "tool_calls"
 )
 
 // Valid indicates whether the value is a known member of the FinishReason enum.
 func (e FinishReason) Valid() bool {
 	switch e {
+	case BudgetExceeded:
+		return true
 	case ContentFilter:
 		return true
 	case FunctionCall:
@@ -512,6 +515,21 @@ func (e MessagesToolUseBlockType) Valid() bool {
 	}
 }
 
+// Defines values for ModelCapabilitiesSource.
+const (
+	ModelCapabilitiesSourceCommunity ModelCapabilitiesSource = "community"
+)
+
+// Valid indicates whether the value is a known member of the ModelCapabilitiesSource enum.
+func (e ModelCapabilitiesSource) Valid() bool {
+	switch e {
+	case ModelCapabilitiesSourceCommunity:
+		return true
+	default:
+		return false
+	}
+}
+
 // Defines values for PricingSource.
 const (
 	PricingSourceCommunity PricingSource = "community"
@@ -1079,6 +1097,30 @@ func (e SSEventEvent) Valid() bool {
 	}
 }
 
+// Defines values for SafetyLevel.
+const (
+	SafetyHigh   SafetyLevel = "high"
+	SafetyLow    SafetyLevel = "low"
+	SafetyMedium SafetyLevel = "medium"
+	SafetyOff    SafetyLevel = "off"
+)
+
+// Valid indicates whether the value is a known member of the SafetyLevel enum.
+func (e SafetyLevel) Valid() bool {
+	switch e {
+	case SafetyHigh:
+		return true
+	case SafetyLow:
+		return true
+	case SafetyMedium:
+		return true
+	case SafetyOff:
+		return true
+	default:
+		return false
+	}
+}
+
 // Defines values for TextContentPartType.
 const (
 	TextContentPartTypeText TextContentPartType = "text"
@@ -1094,8 +1136,54 @@ func (e TextContentPartType) Valid() bool {
 	}
 }
 
+// Defines values for ToolProgressStatus.
+const (
+	ToolProgressStatusCompleted  ToolProgressStatus = "completed"
+	ToolProgressStatusFailed     ToolProgressStatus = "failed"
+	ToolProgressStatusInProgress ToolProgressStatus = "in_progress"
+)
+
+// Valid indicates whether the value is a known member of the ToolProgressStatus enum.
+func (e ToolProgressStatus) Valid() bool {
+	switch e {
+	case ToolProgressStatusCompleted:
+		return true
+	case ToolProgressStatusFailed:
+		return true
+	case ToolProgressStatusInProgress:
+		return true
+	default:
+		return false
+	}
+}
+
+// Defines values for ListModelsParamsCapability.
+const (
+	ListModelsParamsCapabilityJSONMode  ListModelsParamsCapability = "json_mode"
+	ListModelsParamsCapabilityReasoning ListModelsParamsCapability = "reasoning"
+	ListModelsParamsCapabilityTools     ListModelsParamsCapability = "tools"
+	ListModelsParamsCapabilityVision    ListModelsParamsCapability = "vision"
+)
+
+// Valid indicates whether the value is a known member of the ListModelsParamsCapability enum.
+func (e ListModelsParamsCapability) Valid() bool {
+	switch e {
+	case ListModelsParamsCapabilityJSONMode:
+		return true
+	case ListModelsParamsCapabilityReasoning:
+		return true
+	case ListModelsParamsCapabilityTools:
+		return true
+	case ListModelsParamsCapabilityVision:
+		return true
+	default:
+		return false
+	}
+}
+
 // Defines values for ListModelsParamsInclude.
 const (
+	ListModelsParamsIncludeCapabilities  ListModelsParamsInclude = "capabilities"
 	ListModelsParamsIncludeContextWindow ListModelsParamsInclude = "context_window"
 	ListModelsParamsIncludePricing       ListModelsParamsInclude = "pricing"
 )
@@ -1103,6 +1191,8 @@ const (
 // Valid indicates whether the value is a known member of the ListModelsParamsInclude enum.
 func (e ListModelsParamsInclude) Valid() bool {
 	switch e {
+	case ListModelsParamsIncludeCapabilities:
+		return true
 	case ListModelsParamsIncludeContextWindow:
 		return true
 	case ListModelsParamsIncludePricing:
@@ -1112,6 +1202,18 @@ func (e ListModelsParamsInclude) Valid() bool {
 	}
 }
 
+// AgentBudget Limits of the MCP agent loop of the request. Each limit only lowers the server-wide one (AGENT_MAX_ITERATIONS, AGENT_MAX_TOOL_CALLS, AGENT_MAX_DURATION); a loop stopping at a limit answers with the finish reason `budget_exceeded`.
+type AgentBudget struct {
+	// MaxDurationSeconds Maximum time spent calling tools, checked between rounds.
+	MaxDurationSeconds *int `json:"max_duration_seconds,omitempty"`
+
+	// MaxIterations Maximum number of tool-call rounds.
+	MaxIterations *int `json:"max_iterations,omitempty"`
+
+	// MaxToolCalls Maximum number of tool calls executed.
+	MaxToolCalls *int `json:"max_tool_calls,omitempty"`
+}
+
 // CacheControl Cache control settings for prompt caching. Currently only
 // `ephemeral` caching is supported.
 type CacheControl struct {
@@ -1127,7 +1229,8 @@ type ChatCompletionChoice struct {
 	// FinishReason The reason the model stopped generating tokens. This will be `stop` if the model hit a natural stop point or a provided stop sequence,
 	// `length` if the maximum number of tokens specified in the request was reached,
 	// `content_filter` if content was omitted due to a flag from our content filters,
-	// `tool_calls` if the model called a tool.
+	// `tool_calls` if the model called a tool,
+	// `budget_exceeded` if the gateway's MCP agent loop stopped at its budget, leaving the tool calls of the message unexecuted.
 	FinishReason FinishReason `json:"finish_reason"`
 
 	// Index The index of the choice in the list of choices.
@@ -1213,7 +1316,8 @@ type ChatCompletionStreamChoice struct {
 	// FinishReason The reason the model stopped generating tokens. This will be `stop` if the model hit a natural stop point or a provided stop sequence,
 	// `length` if the maximum number of tokens specified in the request was reached,
 	// `content_filter` if content was omitted due to a flag from our content filters,
-	// `tool_calls` if the model called a tool.
+	// `tool_calls` if the model called a tool,
+	// `budget_exceeded` if the gateway's MCP agent loop stopped at its budget, leaving the tool calls of the message unexecuted.
 	FinishReason FinishReason `json:"finish_reason"`
 
 	// Index The index of the choice in the list of choices.
@@ -1252,6 +1356,12 @@ type ChatCompletionStreamResponseDelta struct {
 	// Role Role of the message sender
 	Role      MessageRole                           `json:"role"`
 	ToolCalls *[]ChatCompletionMessageToolCallChunk `json:"tool_calls,omitempty"`
+
+	// ToolProgress Progress of a tool call executed by the gateway's MCP agent, sent as a
+	// vendor extension of an otherwise empty assistant delta when
+	// MCP_TOOL_PROGRESS is enabled. Clients that do not know the field keep
+	// parsing the stream as regular chat completion chunks.
+	ToolProgress *ToolProgress `json:"tool_progress,omitempty"`
 }
 
 // ChatCompletionTokenLogprob defines model for ChatCompletionTokenLogprob.
@@ -1280,7 +1390,10 @@ type ChatCompletionTokenLogprob struct {
 
 // ChatCompletionTool defines model for ChatCompletionTool.
 type ChatCompletionTool struct {
-	Function FunctionObject `json:"function"`
+	// CacheControl Cache control settings for prompt caching. Currently only
+	// `ephemeral` caching is supported.
+	CacheControl *CacheControl  `json:"cache_control,omitempty"`
+	Function     FunctionObject `json:"function"`
 
 	// Type The type of the tool. Currently, only `function` is supported.
 	Type ChatCompletionToolType `json:"type"`
@@ -1356,6 +1469,12 @@ type ContextWindowSource string
 
 // CreateChatCompletionRequest defines model for CreateChatCompletionRequest.
 type CreateChatCompletionRequest struct {
+	// AgentBudget Limits of the MCP agent loop of the request. Each limit only lowers the server-wide one (AGENT_MAX_ITERATIONS, AGENT_MAX_TOOL_CALLS, AGENT_MAX_DURATION); a loop stopping at a limit answers with the finish reason `budget_exceeded`.
+	AgentBudget *AgentBudget `json:"agent_budget,omitempty"`
+
+	// ExtraBody Provider-specific request fields keyed by provider ID, e.g. `{"ollama": {"keep_alive": "10m"}, "groq": {"service_tier": "flex"}}`. Only the entry of the provider serving the request is forwarded, its fields checked against an allowlist per provider; entries of other providers are ignored.
+	ExtraBody *map[string]map[string]any `json:"extra_body,omitempty"`
+
 	// FrequencyPenalty Number between -2.0 and 2.0. Positive values penalize new tokens based on their existing frequency in the text so far, decreasing the model's likelihood to repeat the same line verbatim.
 	FrequencyPenalty *float32 `json:"frequency_penalty,omitempty"`
 
@@ -1372,6 +1491,9 @@ type CreateChatCompletionRequest struct {
 	// Deprecated: this property has been marked as deprecated upstream, but no `x-deprecated-reason` was set
 	MaxTokens *int `json:"max_tokens,omitempty"`
 
+	// MCPPrompt An MCP prompt to render and insert before the messages of a request
+	MCPPrompt *MCPPromptReference `json:"mcp_prompt,omitempty"`
+
 	// Messages A list of messages comprising the conversation so far.
 	Messages []Message `json:"messages"`
 
@@ -1397,6 +1519,9 @@ type CreateChatCompletionRequest struct {
 	// ResponseFormat An object specifying the format that the model must output. Setting to `{ "type": "json_schema", "json_schema": {...} }` enables Structured Outputs which guarantees the model will match your supplied JSON schema. Setting to `{ "type": "json_object" }` enables the older JSON mode, which ensures the message the model generates is valid JSON.
 	ResponseFormat *CreateChatCompletionRequest_ResponseFormat `json:"response_format,omitempty"`
 
+	// SafetySettings Provider-agnostic safety posture for the request. The gateway maps it to native safety settings on Google, to system-level safety instructions on Anthropic and other providers, and to a moderation pre-check of the input on OpenAI.
+	SafetySettings *SafetySettings `json:"safety_settings,omitempty"`
+
 	// Seed If specified, our system will make a best effort to sample deterministically, such that repeated requests with the same `seed` and parameters should return the same result. Determinism is not guaranteed, and you should refer to the `system_fingerprint` response parameter to monitor changes in the backend.
 	Seed *int `json:"seed,omitempty"`
 
@@ -1637,13 +1762,42 @@ type Endpoints struct {
 
 // Error defines model for Error.
 type Error struct {
-	Error *string `json:"error,omitempty"`
+	// Code Stable gateway error code (e.g. `IG-1001`). `GET /v1/errors/{code}`
+	// returns its description and remediation guidance.
+	Code *string `json:"code,omitempty"`
+
+	// CodeName Stable machine-readable name of the code (e.g. `provider_token_missing`).
+	CodeName *string `json:"code_name,omitempty"`
+
+	// DocsUrl Path of the guidance for the code.
+	DocsUrl *string `json:"docs_url,omitempty"`
+	Error   *string `json:"error,omitempty"`
+
+	// ProviderError The provider's error body, verbatim when it is JSON, for upstream errors.
+	ProviderError interface{} `json:"provider_error,omitempty"`
+
+	// ProviderStatus HTTP status answered by the provider, for upstream errors.
+	ProviderStatus *int `json:"provider_status,omitempty"`
+
+	// Retryable Whether the same request may succeed when retried later.
+	Retryable *bool `json:"retryable,omitempty"`
+}
+
+// FailedProvider A provider whose models could not be listed
+type FailedProvider struct {
+	// Code Gateway error code of the failure (`IG-4002` when the provider timed out, `IG-4007` otherwise)
+	Code string `json:"code"`
+
+	// Error Why the models of the provider could not be listed
+	Error    string   `json:"error"`
+	Provider Provider `json:"provider"`
 }
 
 // FinishReason The reason the model stopped generating tokens. This will be `stop` if the model hit a natural stop point or a provided stop sequence,
 // `length` if the maximum number of tokens specified in the request was reached,
 // `content_filter` if content was omitted due to a flag from our content filters,
-// `tool_calls` if the model called a tool.
+// `tool_calls` if the model called a tool,
+// `budget_exceeded` if the gateway's MCP agent loop stopped at its budget, leaving the tool calls of the message unexecuted.
 type FinishReason string
 
 // FunctionObject defines model for FunctionObject.
@@ -1666,6 +1820,21 @@ type FunctionObject struct {
 // Omitting `parameters` defines a function with an empty parameter list.
 type FunctionParameters map[string]any
 
+// GetMCPPromptRequest The arguments to render an MCP prompt with
+type GetMCPPromptRequest struct {
+	// Arguments The arguments to render the prompt with
+	Arguments *map[string]string `json:"arguments,omitempty"`
+}
+
+// GetMCPPromptResponse An MCP prompt rendered into chat messages
+type GetMCPPromptResponse struct {
+	// Description A description of the rendered prompt
+	Description *string `json:"description,omitempty"`
+
+	// Messages The messages of the rendered prompt
+	Messages []Message `json:"messages"`
+}
+
 // ImageContentPart Image content part
 type ImageContentPart struct {
 	// ImageURL Image URL configuration
@@ -1690,13 +1859,52 @@ type ImageURL struct {
 // ImageURLDetail Image detail level for vision processing
 type ImageURLDetail string
 
+// ListMCPPromptsResponse Response structure for listing MCP prompts
+type ListMCPPromptsResponse struct {
+	// Data Array of the prompts of the MCP servers
+	Data []MCPPrompt `json:"data"`
+
+	// Object Always "list"
+	Object string `json:"object"`
+}
+
+// ListMCPResourcesResponse Response structure for listing MCP resources
+type ListMCPResourcesResponse struct {
+	// Data Array of the resources of the MCP servers
+	Data []MCPResource `json:"data"`
+
+	// Object Always "list"
+	Object string `json:"object"`
+}
+
 // ListModelsResponse Response structure for listing models
 type ListModelsResponse struct {
-	Data     []Model   `json:"data"`
+	Data []Model `json:"data"`
+
+	// FailedProviders Providers whose models could not be listed within MODELS_PROVIDER_TIMEOUT, or at all, and are missing from `data` (included when listing all providers and some failed)
+	FailedProviders *[]FailedProvider `json:"failed_providers,omitempty"`
+
+	// FirstID ID of the first model of the page (included when paginating)
+	FirstID *string `json:"first_id,omitempty"`
+
+	// HasMore Whether more models follow this page (included when paginating with `limit` or `after`)
+	HasMore *bool `json:"has_more,omitempty"`
+
+	// LastID ID of the last model of the page, the `after` cursor of the next one (included when paginating)
+	LastID   *string   `json:"last_id,omitempty"`
 	Object   string    `json:"object"`
 	Provider *Provider `json:"provider,omitempty"`
 }
 
+// ListProvidersResponse Response structure for listing providers
+type ListProvidersResponse struct {
+	// Data Array of the configured providers
+	Data []ProviderStatus `json:"data"`
+
+	// Object Always "list"
+	Object string `json:"object"`
+}
+
 // ListToolsResponse Response structure for listing MCP tools
 type ListToolsResponse struct {
 	// Data Array of available MCP tools
@@ -1706,6 +1914,76 @@ type ListToolsResponse struct {
 	Object string `json:"object"`
 }
 
+// MCPPrompt An MCP prompt
+type MCPPrompt struct {
+	// Arguments The arguments the prompt is rendered with
+	Arguments *[]MCPPromptArgument `json:"arguments,omitempty"`
+
+	// Description A description of the prompt
+	Description *string `json:"description,omitempty"`
+
+	// Name The name of the prompt
+	Name string `json:"name"`
+
+	// Server The MCP server that provides this prompt
+	Server string `json:"server"`
+}
+
+// MCPPromptArgument An argument of an MCP prompt
+type MCPPromptArgument struct {
+	// Description A description of the argument
+	Description *string `json:"description,omitempty"`
+
+	// Name The name of the argument
+	Name string `json:"name"`
+
+	// Required Whether the argument must be given
+	Required *bool `json:"required,omitempty"`
+}
+
+// MCPPromptReference An MCP prompt to render and insert before the messages of a request
+type MCPPromptReference struct {
+	// Arguments The arguments to render the prompt with
+	Arguments *map[string]string `json:"arguments,omitempty"`
+
+	// Name The name of the prompt, as listed by /v1/mcp/prompts
+	Name string `json:"name"`
+}
+
+// MCPResource An MCP resource
+type MCPResource struct {
+	// Description A description of the resource
+	Description *string `json:"description,omitempty"`
+
+	// MimeType MIME type of the resource, if known
+	MimeType *string `json:"mime_type,omitempty"`
+
+	// Name The name of the resource
+	Name string `json:"name"`
+
+	// Server The MCP server that provides this resource
+	Server string `json:"server"`
+
+	// URI URI of the resource
+	URI string `json:"uri"`
+}
+
+// MCPResourceContents The contents of an MCP resource or one of its sub-resources: text, or
+// binary data encoded in base64
+type MCPResourceContents struct {
+	// Blob The base64-encoded data of binary contents
+	Blob *string `json:"blob,omitempty"`
+
+	// MimeType MIME type of the contents, if known
+	MimeType *string `json:"mime_type,omitempty"`
+
+	// Text The text of text contents
+	Text *string `json:"text,omitempty"`
+
+	// URI URI of the contents
+	URI string `json:"uri"`
+}
+
 // MCPTool An MCP tool definition
 type MCPTool struct {
 	// Description A description of what the tool does
@@ -1793,6 +2071,12 @@ type MessagesDocumentSourceType string
 type MessagesError struct {
 	// Error The error details.
 	Error struct {
+		// Code Stable gateway error code, set on errors generated by the gateway.
+		Code *string `json:"code,omitempty"`
+
+		// DocsUrl Path of the guidance for the gateway error code.
+		DocsUrl *string `json:"docs_url,omitempty"`
+
 		// Message A human-readable error message.
 		Message string `json:"message"`
 
@@ -2137,6 +2421,12 @@ type MessagesUsage struct {
 
 // Model Common model information
 type Model struct {
+	// Available Whether the provider serving the model is healthy (included when `include_unavailable=true`)
+	Available *bool `json:"available,omitempty"`
+
+	// Capabilities Capability information for the model (included when `include=capabilities`)
+	Capabilities *ModelCapabilities `json:"capabilities,omitempty"`
+
 	// ContextWindow Context window information for the model (included when `include=context_window`)
 	ContextWindow *ContextWindow `json:"context_window,omitempty"`
 	Created       int64          `json:"created"`
@@ -2149,6 +2439,30 @@ type Model struct {
 	ServedBy Provider `json:"served_by"`
 }
 
+// ModelCapabilities Capability information for a model
+type ModelCapabilities struct {
+	// MaxOutputTokens Maximum number of tokens the model can generate in a single response
+	MaxOutputTokens *int `json:"max_output_tokens,omitempty"`
+
+	// Source Source of the capability information
+	Source ModelCapabilitiesSource `json:"source"`
+
+	// SupportsJSONMode Model supports structured output through response_format
+	SupportsJSONMode bool `json:"supports_json_mode"`
+
+	// SupportsReasoning Model produces reasoning before its answer
+	SupportsReasoning bool `json:"supports_reasoning"`
+
+	// SupportsTools Model accepts tool definitions and returns tool calls
+	SupportsTools bool `json:"supports_tools"`
+
+	// SupportsVision Model accepts image inputs
+	SupportsVision bool `json:"supports_vision"`
+}
+
+// ModelCapabilitiesSource Source of the capability information
+type ModelCapabilitiesSource string
+
 // Pricing Pricing information for a model
 type Pricing struct {
 	// CacheReadPerToken Price per cached input token read
@@ -2185,6 +2499,39 @@ type Provider string
 // ProviderAuthType Authentication type for providers
 type ProviderAuthType string
 
+// ProviderHealthCheck The outcome of the last connectivity check of a provider, which lists its models
+type ProviderHealthCheck struct {
+	// CheckedAt When the check ran
+	CheckedAt time.Time `json:"checked_at"`
+
+	// Error Why the check failed
+	Error *string `json:"error,omitempty"`
+
+	// Models The number of models the provider serves
+	Models int `json:"models"`
+
+	// Status ok, unauthorized when the provider rejected the API key, or unavailable
+	Status string `json:"status"`
+}
+
+// ProviderModelLoad The load state of a model the gateway keeps loaded on its provider
+type ProviderModelLoad struct {
+	// Error Why the last load or keep-alive failed
+	Error *string `json:"error,omitempty"`
+
+	// LoadDurationMs How long the provider took to load the model on the last load or keep-alive, a few milliseconds when it was still loaded
+	LoadDurationMs *int64 `json:"load_duration_ms,omitempty"`
+
+	// LoadedAt When the model last answered a load or keep-alive
+	LoadedAt *time.Time `json:"loaded_at,omitempty"`
+
+	// Model The name of the model on the provider
+	Model string `json:"model"`
+
+	// State loading until the model first answers, then loaded, or failed when the last load or keep-alive failed
+	State string `json:"state"`
+}
+
 // ProviderSpecificResponse Provider-specific response format. Examples:
 //
 // OpenAI GET /v1/models?provider=openai response:
@@ -2226,6 +2573,35 @@ type ProviderAuthType string
 // ```
 type ProviderSpecificResponse = map[string]any
 
+// ProviderStatus A configured provider and its health
+type ProviderStatus struct {
+	// Auth The status of the API key of the provider: valid or invalid as
+	// found by the last check, missing when no key is configured, none
+	// when the provider takes no key, or unchecked
+	Auth string `json:"auth"`
+
+	// Enabled Whether requests are routed to the provider
+	Enabled bool `json:"enabled"`
+
+	// Health The outcome of the last connectivity check of a provider, which lists its models
+	Health *ProviderHealthCheck `json:"health,omitempty"`
+	ID     Provider             `json:"id"`
+
+	// Models The load state of the models of OLLAMA_PREWARM_MODELS
+	Models *[]ProviderModelLoad `json:"models,omitempty"`
+
+	// Name The display name of the provider
+	Name string `json:"name"`
+
+	// URL The base URL of the provider, without credentials
+	URL string `json:"url"`
+}
+
+// ReadMCPResourceResponse The contents of an MCP resource
+type ReadMCPResourceResponse struct {
+	Contents []MCPResourceContents `json:"contents"`
+}
+
 // Response Represents a model response returned by the Responses API.
 type Response struct {
 	// CreatedAt Unix timestamp (in seconds) of when the response was created.
@@ -2670,8 +3046,24 @@ type SSEvent struct {
 // SSEventEvent defines model for SSEvent.Event.
 type SSEventEvent string
 
+// SafetyLevel How strictly a harm category is blocked. `off` disables blocking, `low` blocks only high-probability harm, `medium` blocks medium and above, and `high` blocks anything with a low probability or more.
+type SafetyLevel string
+
+// SafetySettings Provider-agnostic safety posture for the request. The gateway maps it to native safety settings on Google, to system-level safety instructions on Anthropic and other providers, and to a moderation pre-check of the input on OpenAI.
+type SafetySettings struct {
+	// Categories Per-category overrides of `level`, keyed by harm category: `harassment`, `hate`, `sexual` or `dangerous`.
+	Categories *map[string]SafetyLevel `json:"categories,omitempty"`
+
+	// Level How strictly a harm category is blocked. `off` disables blocking, `low` blocks only high-probability harm, `medium` blocks medium and above, and `high` blocks anything with a low probability or more.
+	Level *SafetyLevel `json:"level,omitempty"`
+}
+
 // TextContentPart Text content part
 type TextContentPart struct {
+	// CacheControl Cache control settings for prompt caching. Currently only
+	// `ephemeral` caching is supported.
+	CacheControl *CacheControl `json:"cache_control,omitempty"`
+
 	// Text The text content
 	Text string `json:"text"`
 
@@ -2701,6 +3093,24 @@ type ToolCallExtraContent_Google struct {
 	AdditionalProperties map[string]any `json:"-"`
 }
 
+// ToolProgress Progress of a tool call executed by the gateway's MCP agent, sent as a
+// vendor extension of an otherwise empty assistant delta when
+// MCP_TOOL_PROGRESS is enabled. Clients that do not know the field keep
+// parsing the stream as regular chat completion chunks.
+type ToolProgress struct {
+	// Name The name of the tool the model called.
+	Name string `json:"name"`
+
+	// Status Status of the tool call. A tool answering with an error still completes; failed means no result could be produced.
+	Status ToolProgressStatus `json:"status"`
+
+	// ToolCallID The ID of the tool call.
+	ToolCallID string `json:"tool_call_id"`
+}
+
+// ToolProgressStatus Status of the tool call. A tool answering with an error still completes; failed means no result could be produced.
+type ToolProgressStatus string
+
 // BadRequest defines model for BadRequest.
 type BadRequest = Error
 
@@ -2785,20 +3195,52 @@ type CreateMessageParams struct {
 // PushMetricsJSONBody defines parameters for PushMetrics.
 type PushMetricsJSONBody = map[string]any
 
+// ReadMCPResourceParams defines parameters for ReadMCPResource.
+type ReadMCPResourceParams struct {
+	// URI URI of the resource
+	URI string `form:"uri" json:"uri"`
+
+	// Server The MCP server of the resource, required for resources of templates
+	Server *string `form:"server,omitempty" json:"server,omitempty"`
+}
+
 // ListModelsParams defines parameters for ListModels.
 type ListModelsParams struct {
 	// Provider Specific provider to query (optional)
 	Provider *Provider `form:"provider,omitempty" json:"provider,omitempty"`
 
 	// Include Comma-separated list of metadata keys to include in the response.
-	// Supported values: `pricing`, `context_window`.
+	// Supported values: `pricing`, `context_window`, `capabilities`.
 	// When omitted, the response remains unchanged (backward compatible).
 	Include *[]ListModelsParamsInclude `form:"include,omitempty" json:"include,omitempty"`
+
+	// IncludeUnavailable Also list the models of unhealthy providers, whose recent calls
+	// mostly failed, and mark every model with `available`. Meant for
+	// debugging; see PROVIDER_HEALTH_MIN_FAILURES.
+	IncludeUnavailable *bool `form:"include_unavailable,omitempty" json:"include_unavailable,omitempty"`
+
+	// OwnedBy Only list the models with this `owned_by` value
+	OwnedBy *string `form:"owned_by,omitempty" json:"owned_by,omitempty"`
+
+	// Capability Comma-separated list of capabilities the listed models must all
+	// support. Models with unknown capabilities are left out.
+	Capability *[]ListModelsParamsCapability `form:"capability,omitempty" json:"capability,omitempty"`
+
+	// Limit Maximum number of models to return. When set, or with `after`,
+	// models are sorted by ID and the response carries `has_more`,
+	// `first_id` and `last_id`.
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// After Cursor for pagination; only the models whose ID sorts after this one are listed, usually the `last_id` of the previous page
+	After *string `form:"after,omitempty" json:"after,omitempty"`
 }
 
 // ListModelsParamsInclude defines parameters for ListModels.
 type ListModelsParamsInclude string
 
+// ListModelsParamsCapability defines parameters for ListModels.
+type ListModelsParamsCapability string
+
 // ProxyPatchJSONBody defines parameters for ProxyPatch.
 type ProxyPatchJSONBody struct {
 	Messages *[]struct {
@@ -2841,6 +3283,9 @@ type CreateChatCompletionJSONRequestBody = CreateChatCompletionRequest
 // CreateMessageJSONRequestBody defines body for CreateMessage for application/json ContentType.
 type CreateMessageJSONRequestBody = CreateMessagesRequest
 
+// GetMCPPromptJSONRequestBody defines body for GetMCPPrompt for application/json ContentType.
+type GetMCPPromptJSONRequestBody = GetMCPPromptRequest
+
 // PushMetricsJSONRequestBody defines body for PushMetrics for application/json ContentType.
 type PushMetricsJSONRequestBody = PushMetricsJSONBody
 
@@ -3,3 +3,17 @@ package types
 type ContextKey string
 
 const AuthTokenContextKey ContextKey = "authToken"
+
+// AuthSubjectContextKey holds the subject (sub claim) of the verified ID token
+const AuthSubjectContextKey ContextKey = "authSubject"
+
+// AuthClaimsContextKey holds the claims of the verified ID token as a
+// map[string]any
+const AuthClaimsContextKey ContextKey = "authClaims"
+
+// TenantContextKey holds the ID of the tenant a request belongs to
+const TenantContextKey ContextKey = "tenant"
+
+// RequestIDContextKey holds the ID of the request, sent upstream as the
+// X-Request-ID header
+const RequestIDContextKey ContextKey = "requestID"
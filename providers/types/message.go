@@ -1,5 +1,7 @@
 package types
 
+import "strings"
+
 // HasImageContent checks if the message contains image content.
 // Returns true if the message has multimodal content with at least one image part.
 func (m *Message) HasImageContent() bool {
@@ -62,3 +64,25 @@ func (m *Message) StripImageContent() error {
 	}
 	return nil
 }
+
+// TextContent returns the text of the message. For string content the string
+// is returned as-is; for multimodal content the text parts are concatenated
+// and all other parts are ignored.
+func (m *Message) TextContent() string {
+	if text, err := m.Content.AsMessageContent0(); err == nil {
+		return text
+	}
+
+	parts, err := m.Content.AsMessageContent1()
+	if err != nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, part := range parts {
+		if textPart, err := part.AsTextContentPart(); err == nil && textPart.Type == "text" {
+			sb.WriteString(textPart.Text)
+		}
+	}
+	return sb.String()
+}
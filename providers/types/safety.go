@@ -0,0 +1,38 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+)
+
+// SafetyCategories lists the normalized harm categories accepted in
+// SafetySettings.Categories
+var SafetyCategories = []string{"harassment", "hate", "sexual", "dangerous"}
+
+// Levels resolves the effective level of every harm category: the category
+// override when set, otherwise Level. Categories without either are omitted,
+// leaving them to the provider's default. Unknown categories and levels are
+// returned as errors.
+func (s *SafetySettings) Levels() (map[string]SafetyLevel, error) {
+	levels := make(map[string]SafetyLevel)
+	if s.Level != nil {
+		if !s.Level.Valid() {
+			return nil, fmt.Errorf("invalid safety level %q", *s.Level)
+		}
+		for _, category := range SafetyCategories {
+			levels[category] = *s.Level
+		}
+	}
+	if s.Categories != nil {
+		for category, level := range *s.Categories {
+			if !slices.Contains(SafetyCategories, category) {
+				return nil, fmt.Errorf("unknown safety category %q", category)
+			}
+			if !level.Valid() {
+				return nil, fmt.Errorf("invalid safety level %q for category %q", level, category)
+			}
+			levels[category] = level
+		}
+	}
+	return levels, nil
+}
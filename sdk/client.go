@@ -0,0 +1,228 @@
+// Package sdk is the Go client of the Inference Gateway HTTP API. It lists
+// models and MCP tools and creates chat completions, streamed through an
+// iterator over their chunks, retrying the failures the gateway reports as
+// retryable and returning its errors as *Error with their stable code.
+//
+//	client := sdk.New("http://localhost:8080", sdk.WithToken(token))
+//	stream, err := client.ChatCompletionStream(ctx, req)
+//	if err != nil {
+//		return err
+//	}
+//	defer stream.Close()
+//	for stream.Next() {
+//		chunk := stream.Chunk()
+//		...
+//	}
+//	return stream.Err()
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+const (
+	// DefaultRetries is how many times a failed request is retried unless
+	// WithRetries is given
+	DefaultRetries = 2
+	// DefaultBackoff is the wait before the first retry, doubled for every
+	// next one, unless the gateway answers with a Retry-After
+	DefaultBackoff = 500 * time.Millisecond
+	// maxErrorBody bounds how much of an error response is read
+	maxErrorBody = 64 * 1024
+)
+
+// Client calls the HTTP API of a gateway. It is safe for concurrent use.
+type Client struct {
+	baseURL string
+	token   string
+	headers http.Header
+	http    *http.Client
+	retries int
+	backoff time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithToken sends token as the bearer token of every request
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithHTTPClient sends the requests with hc instead of http.DefaultClient
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+// WithHeader sets header name to value on every request, e.g. the tenant
+// header of a multi-tenant gateway
+func WithHeader(name, value string) Option {
+	return func(c *Client) { c.headers.Set(name, value) }
+}
+
+// WithRetries sets how many times a failed request is retried and the wait
+// before the first retry. Requests are retried on connection errors and on
+// the errors the gateway marks retryable, 0 disabling retries.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+// New creates a client of the gateway at baseURL, e.g. http://localhost:8080
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		headers: make(http.Header),
+		http:    http.DefaultClient,
+		retries: DefaultRetries,
+		backoff: DefaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ListModels lists the models served by the gateway, those of provider only
+// when it is not empty
+func (c *Client) ListModels(ctx context.Context, provider types.Provider) (*types.ListModelsResponse, error) {
+	path := "/v1/models"
+	if provider != "" {
+		path += "?provider=" + url.QueryEscape(string(provider))
+	}
+	var models types.ListModelsResponse
+	if err := c.getJSON(ctx, path, &models); err != nil {
+		return nil, err
+	}
+	return &models, nil
+}
+
+// ListTools lists the MCP tools exposed by the gateway
+func (c *Client) ListTools(ctx context.Context) (*types.ListToolsResponse, error) {
+	var tools types.ListToolsResponse
+	if err := c.getJSON(ctx, "/v1/mcp/tools", &tools); err != nil {
+		return nil, err
+	}
+	return &tools, nil
+}
+
+// ChatCompletion creates a chat completion. req.Stream is ignored; use
+// ChatCompletionStream to stream.
+func (c *Client) ChatCompletion(ctx context.Context, req types.CreateChatCompletionRequest) (*types.CreateChatCompletionResponse, error) {
+	req.Stream = nil
+	resp, err := c.Do(ctx, http.MethodPost, "/v1/chat/completions", req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var completion types.CreateChatCompletionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+		return nil, err
+	}
+	return &completion, nil
+}
+
+// ChatCompletionStream creates a streamed chat completion. Only establishing
+// the stream is retried; the caller closes the returned Stream.
+func (c *Client) ChatCompletionStream(ctx context.Context, req types.CreateChatCompletionRequest) (*Stream, error) {
+	stream := true
+	req.Stream = &stream
+	resp, err := c.Do(ctx, http.MethodPost, "/v1/chat/completions", req)
+	if err != nil {
+		return nil, err
+	}
+	return newStream(resp.Body), nil
+}
+
+// Do sends a request to path, JSON-encoding body when it is not nil, and
+// retries it as configured. Error statuses are returned as *Error; otherwise
+// the caller closes the response body.
+func (c *Client) Do(ctx context.Context, method, path string, body any) (*http.Response, error) {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, method, path, data, body != nil)
+		if err == nil || attempt >= c.retries || ctx.Err() != nil || !retryable(err) {
+			return resp, err
+		}
+
+		wait := backoff
+		var gatewayErr *Error
+		if errors.As(err, &gatewayErr) && gatewayErr.RetryAfter > 0 {
+			wait = gatewayErr.RetryAfter
+		}
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(wait):
+		}
+		backoff *= 2
+	}
+}
+
+// send sends one attempt of a request
+func (c *Client) send(ctx context.Context, method, path string, data []byte, hasBody bool) (*http.Response, error) {
+	var reader io.Reader
+	if hasBody {
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range c.headers {
+		req.Header[name] = values
+	}
+	if hasBody {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		gatewayErr := parseError(resp.StatusCode, body)
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			gatewayErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return nil, gatewayErr
+	}
+	return resp, nil
+}
+
+// getJSON decodes the JSON response of GET path into out
+func (c *Client) getJSON(ctx context.Context, path string, out any) error {
+	resp, err := c.Do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,127 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func TestListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/models", r.URL.Path)
+		assert.Equal(t, "groq", r.URL.Query().Get("provider"))
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "acme", r.Header.Get("X-Tenant-ID"))
+		_, _ = io.WriteString(w, `{"object":"list","data":[{"id":"groq/llama-3.3-70b","object":"model","owned_by":"meta","served_by":"groq"}]}`)
+	}))
+	defer server.Close()
+
+	client := New(server.URL+"/", WithToken("secret"), WithHeader("X-Tenant-ID", "acme"))
+	models, err := client.ListModels(context.Background(), "groq")
+	require.NoError(t, err)
+	require.Len(t, models.Data, 1)
+	assert.Equal(t, "groq/llama-3.3-70b", models.Data[0].ID)
+}
+
+func TestChatCompletionStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req types.CreateChatCompletionRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.NotNil(t, req.Stream)
+		assert.True(t, *req.Stream)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hel\"}}]}\n\n")
+		_, _ = io.WriteString(w, ": keep-alive\n\n")
+		_, _ = io.WriteString(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"lo\"}}]}\n\n")
+		if req.Model == "openai/broken" {
+			_, _ = io.WriteString(w, "data: {\"error\":\"provider stream interrupted\",\"code\":\"IG-4003\",\"retryable\":true}\n\n")
+			return
+		}
+		_, _ = io.WriteString(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":3,\"completion_tokens\":2,\"total_tokens\":5}}\n\n")
+		_, _ = io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+	client := New(server.URL)
+
+	stream, err := client.ChatCompletionStream(context.Background(), types.CreateChatCompletionRequest{Model: "openai/gpt-4o"})
+	require.NoError(t, err)
+	defer func() { _ = stream.Close() }()
+	content := ""
+	for stream.Next() {
+		for _, choice := range stream.Chunk().Choices {
+			content += choice.Delta.Content
+		}
+	}
+	require.NoError(t, stream.Err())
+	assert.Equal(t, "Hello", content)
+	require.NotNil(t, stream.Usage())
+	assert.Equal(t, int64(5), stream.Usage().TotalTokens)
+	assert.False(t, stream.Next(), "the stream stays ended")
+
+	broken, err := client.ChatCompletionStream(context.Background(), types.CreateChatCompletionRequest{Model: "openai/broken"})
+	require.NoError(t, err)
+	defer func() { _ = broken.Close() }()
+	for broken.Next() {
+	}
+	var gatewayErr *Error
+	require.ErrorAs(t, broken.Err(), &gatewayErr)
+	assert.Equal(t, "IG-4003", gatewayErr.Code)
+	assert.Equal(t, "provider stream interrupted (IG-4003, HTTP 200)", gatewayErr.Error())
+}
+
+func TestRetries(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch n := calls.Add(1); {
+		case r.URL.Path == "/v1/mcp/tools":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = io.WriteString(w, `{"error":"mcp tools endpoint is not exposed","code":"IG-2002","code_name":"feature_disabled","docs_url":"/v1/errors/IG-2002","retryable":false}`)
+		case n == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, `{"error":"provider overloaded","code":"IG-4002","retryable":true,"provider_status":529,"provider_error":{"type":"overloaded_error"}}`)
+		case n == 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = io.WriteString(w, `{"error":{"message":"Rate limit reached","type":"requests"}}`)
+		default:
+			_, _ = io.WriteString(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"Hello"},"finish_reason":"stop"}]}`)
+		}
+	}))
+	defer server.Close()
+	client := New(server.URL, WithRetries(2, time.Millisecond))
+
+	completion, err := client.ChatCompletion(context.Background(), types.CreateChatCompletionRequest{Model: "openai/gpt-4o"})
+	require.NoError(t, err)
+	assert.Len(t, completion.Choices, 1)
+	assert.Equal(t, int32(3), calls.Load())
+
+	calls.Store(0)
+	_, err = client.ListTools(context.Background())
+	var gatewayErr *Error
+	require.ErrorAs(t, err, &gatewayErr)
+	assert.Equal(t, int32(1), calls.Load(), "errors not marked retryable are not retried")
+	assert.Equal(t, &Error{
+		StatusCode: http.StatusForbidden,
+		Message:    "mcp tools endpoint is not exposed",
+		Code:       "IG-2002",
+		CodeName:   "feature_disabled",
+		DocsURL:    "/v1/errors/IG-2002",
+	}, gatewayErr)
+
+	calls.Store(0)
+	_, err = New(server.URL, WithRetries(0, 0)).ChatCompletion(context.Background(), types.CreateChatCompletionRequest{Model: "openai/gpt-4o"})
+	require.ErrorAs(t, err, &gatewayErr)
+	assert.True(t, gatewayErr.Retryable)
+	assert.Equal(t, 529, gatewayErr.ProviderStatus)
+	assert.JSONEq(t, `{"type":"overloaded_error"}`, string(gatewayErr.ProviderError))
+}
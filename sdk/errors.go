@@ -0,0 +1,100 @@
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Error is an error answered by the gateway or by a provider behind it. The
+// fields mirror the gateway's error body; Code is one of the stable IG-xxxx
+// codes documented at DocsURL and GET /v1/errors, empty for errors passed
+// through from a provider as is.
+type Error struct {
+	// StatusCode is the HTTP status of the response, 200 for an error event
+	// ending a stream
+	StatusCode int
+	Message    string
+	// Code is the stable code, e.g. IG-4001, and CodeName its name, e.g.
+	// upstream_rate_limited
+	Code     string
+	CodeName string
+	DocsURL  string
+	// Retryable reports whether the gateway expects the same request may
+	// succeed later
+	Retryable bool
+	// ProviderStatus and ProviderError are the status and body answered by
+	// the provider, for upstream errors
+	ProviderStatus int
+	ProviderError  json.RawMessage
+	// RetryAfter is the wait the gateway asked for with a Retry-After header
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("%s (%s, HTTP %d)", e.Message, e.Code, e.StatusCode)
+	}
+	return fmt.Sprintf("%s (HTTP %d)", e.Message, e.StatusCode)
+}
+
+// parseError reads a gateway error body ({"error": "...", "code": "IG-..."})
+// or a provider error body ({"error": {"message": "..."}})
+func parseError(status int, body []byte) *Error {
+	gatewayErr := &Error{StatusCode: status, Message: strings.TrimSpace(string(body))}
+	var envelope struct {
+		Error          json.RawMessage `json:"error"`
+		Code           string          `json:"code"`
+		CodeName       string          `json:"code_name"`
+		DocsURL        string          `json:"docs_url"`
+		Retryable      bool            `json:"retryable"`
+		ProviderStatus int             `json:"provider_status"`
+		ProviderError  json.RawMessage `json:"provider_error"`
+	}
+	if json.Unmarshal(body, &envelope) != nil || len(envelope.Error) == 0 {
+		if gatewayErr.Message == "" {
+			gatewayErr.Message = http.StatusText(status)
+		}
+		return gatewayErr
+	}
+	gatewayErr.Code = envelope.Code
+	gatewayErr.CodeName = envelope.CodeName
+	gatewayErr.DocsURL = envelope.DocsURL
+	gatewayErr.Retryable = envelope.Retryable
+	gatewayErr.ProviderStatus = envelope.ProviderStatus
+	gatewayErr.ProviderError = envelope.ProviderError
+
+	var message string
+	if json.Unmarshal(envelope.Error, &message) == nil {
+		gatewayErr.Message = message
+		return gatewayErr
+	}
+	var upstream struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(envelope.Error, &upstream) == nil && upstream.Message != "" {
+		gatewayErr.Message = upstream.Message
+	}
+	return gatewayErr
+}
+
+// retryable reports whether a request failing with err may be retried:
+// connection errors, errors the gateway marks retryable, and the rate limit
+// and unavailability statuses of errors without a code
+func retryable(err error) bool {
+	var gatewayErr *Error
+	if !errors.As(err, &gatewayErr) {
+		return true
+	}
+	if gatewayErr.Code != "" {
+		return gatewayErr.Retryable
+	}
+	switch gatewayErr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package sdk
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// maxEventSize bounds the size of one server-sent event of a stream
+const maxEventSize = 10 * 1024 * 1024
+
+// Stream iterates over the chunks of a streamed chat completion:
+//
+//	for stream.Next() {
+//		chunk := stream.Chunk()
+//	}
+//	if err := stream.Err(); err != nil {
+//		...
+//	}
+type Stream struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+	chunk   types.CreateChatCompletionStreamResponse
+	usage   *types.CompletionUsage
+	err     error
+	done    bool
+}
+
+func newStream(body io.ReadCloser) *Stream {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+	return &Stream{body: body, scanner: scanner}
+}
+
+// Next advances to the next chunk, returning false at the end of the stream
+// or on an error, reported by Err
+func (s *Stream) Next() bool {
+	for !s.done && s.scanner.Scan() {
+		data, ok := strings.CutPrefix(s.scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk struct {
+			types.CreateChatCompletionStreamResponse
+			Error json.RawMessage `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			continue
+		}
+		if len(chunk.Error) > 0 {
+			s.err = parseError(http.StatusOK, []byte(data))
+			break
+		}
+		s.chunk = chunk.CreateChatCompletionStreamResponse
+		if s.chunk.Usage != nil {
+			s.usage = s.chunk.Usage
+		}
+		return true
+	}
+	if !s.done {
+		s.done = true
+		if s.err == nil {
+			s.err = s.scanner.Err()
+		}
+	}
+	return false
+}
+
+// Chunk returns the chunk Next advanced to
+func (s *Stream) Chunk() types.CreateChatCompletionStreamResponse {
+	return s.chunk
+}
+
+// Usage returns the token usage sent in the stream so far, nil until the
+// chunk carrying it; the gateway sends it last when the request sets
+// stream_options.include_usage
+func (s *Stream) Usage() *types.CompletionUsage {
+	return s.usage
+}
+
+// Err returns the error that ended the stream, nil at its normal end. An
+// error event sent by the gateway is returned as *Error.
+func (s *Stream) Err() error {
+	return s.err
+}
+
+// Close closes the stream, which the caller must do once done with it
+func (s *Stream) Close() error {
+	s.done = true
+	return s.body.Close()
+}
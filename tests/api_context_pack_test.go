@@ -0,0 +1,186 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	api "github.com/inference-gateway/inference-gateway/api"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+	providersmocks "github.com/inference-gateway/inference-gateway/tests/mocks/providers"
+)
+
+// contextPackDocuments are listed lowest priority first to exercise ordering.
+// With a 300 token window and 100 reserved, "a" fits whole, "b" only partly
+// and "c" only if "b" is not packed.
+func contextPackDocuments() []api.ContextPackDocument {
+	return []api.ContextPackDocument{
+		{ID: "c", Content: "c"},
+		{ID: "b", Content: strings.Repeat("b", 1000), Priority: 1},
+		{ID: "a", Content: strings.Repeat("a", 400), Priority: 2},
+	}
+}
+
+func postContextPack(t *testing.T, router api.Router, req any) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.POST("/v1/context/pack", router.ContextPackHandler)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/context/pack", strings.NewReader(string(body))))
+	return w
+}
+
+func statuses(docs []api.ContextPackDocumentResult) map[string]string {
+	result := make(map[string]string, len(docs))
+	for _, d := range docs {
+		result[d.ID] = d.Status
+	}
+	return result
+}
+
+func TestContextPackHandler_Truncate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	log, cfg := routingTestSetup(t)
+
+	mockClient := providersmocks.NewMockClient(ctrl)
+	reg := providersmocks.NewMockProviderRegistry(ctrl)
+	reg.EXPECT().BuildProvider(constants.OpenaiID, mockClient).Return(providersmocks.NewMockIProvider(ctrl), nil)
+
+	maxContext, reserve := 300, 100
+	w := postContextPack(t, api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil), api.ContextPackRequest{
+		Model:            "openai/gpt-4o",
+		Documents:        contextPackDocuments(),
+		System:           "Answer from the documents.",
+		Query:            "q",
+		MaxContextTokens: &maxContext,
+		ReserveTokens:    &reserve,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp api.ContextPackResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 300, resp.ContextWindow)
+	require.Len(t, resp.Documents, 3)
+	assert.Equal(t, []string{"a", "b", "c"}, []string{resp.Documents[0].ID, resp.Documents[1].ID, resp.Documents[2].ID})
+	assert.Equal(t, map[string]string{"a": api.PackStatusIncluded, "b": api.PackStatusTruncated, "c": api.PackStatusDropped}, statuses(resp.Documents))
+	assert.LessOrEqual(t, resp.UsedTokens, maxContext-reserve)
+
+	require.Len(t, resp.Messages, 2)
+	assert.Equal(t, types.System, resp.Messages[0].Role)
+	system := resp.Messages[0].TextContent()
+	assert.True(t, strings.HasPrefix(system, "Answer from the documents."))
+	assert.Contains(t, system, `<document id="a">`)
+	assert.Contains(t, system, "…\n</document>")
+	assert.Equal(t, types.User, resp.Messages[1].Role)
+	assert.Equal(t, "q", resp.Messages[1].TextContent())
+}
+
+func TestContextPackHandler_DropWithProviderContextWindow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	log, cfg := routingTestSetup(t)
+
+	mockClient := providersmocks.NewMockClient(ctrl)
+	reg := providersmocks.NewMockProviderRegistry(ctrl)
+	provider := providersmocks.NewMockIProvider(ctrl)
+	reg.EXPECT().BuildProvider(constants.OpenaiID, mockClient).Return(provider, nil)
+	provider.EXPECT().ListModels(gomock.Any()).Return(types.ListModelsResponse{Data: []types.Model{
+		{ID: "openai/gpt-4o-mini", ServedBy: constants.OpenaiID, ContextWindow: &types.ContextWindow{Tokens: 10, Source: types.ContextWindowSourceProvider}},
+		{ID: "openai/gpt-4o", ServedBy: constants.OpenaiID, ContextWindow: &types.ContextWindow{Tokens: 300, Source: types.ContextWindowSourceProvider}},
+	}}, nil)
+
+	reserve := 100
+	w := postContextPack(t, api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil), api.ContextPackRequest{
+		Model:         "openai/gpt-4o",
+		Documents:     contextPackDocuments(),
+		Query:         "q",
+		Strategy:      api.PackStrategyDrop,
+		ReserveTokens: &reserve,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp api.ContextPackResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 300, resp.ContextWindow)
+	assert.Equal(t, map[string]string{"a": api.PackStatusIncluded, "b": api.PackStatusDropped, "c": api.PackStatusIncluded}, statuses(resp.Documents))
+}
+
+func TestContextPackHandler_Summarize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	log, cfg := routingTestSetup(t)
+
+	mockClient := providersmocks.NewMockClient(ctrl)
+	reg := providersmocks.NewMockProviderRegistry(ctrl)
+	provider := providersmocks.NewMockIProvider(ctrl)
+	reg.EXPECT().BuildProvider(constants.OpenaiID, mockClient).Return(provider, nil)
+	provider.EXPECT().ChatCompletions(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, req types.CreateChatCompletionRequest) (types.CreateChatCompletionResponse, error) {
+			assert.Equal(t, "gpt-4o", req.Model)
+			require.NotNil(t, req.MaxTokens)
+			require.Len(t, req.Messages, 2)
+			assert.Equal(t, strings.Repeat("b", 1000), req.Messages[1].TextContent())
+			return types.CreateChatCompletionResponse{
+				Choices: []types.ChatCompletionChoice{{Message: types.NewTextMessage(t, types.Assistant, "summary of b")}},
+			}, nil
+		})
+
+	maxContext, reserve := 300, 100
+	w := postContextPack(t, api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil), api.ContextPackRequest{
+		Model:            "openai/gpt-4o",
+		Documents:        contextPackDocuments(),
+		Query:            "q",
+		Strategy:         api.PackStrategySummarize,
+		MaxContextTokens: &maxContext,
+		ReserveTokens:    &reserve,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp api.ContextPackResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, api.PackStatusSummarized, statuses(resp.Documents)["b"])
+	assert.Contains(t, resp.Messages[0].TextContent(), "<document id=\"b\">\nsummary of b\n</document>")
+}
+
+func TestContextPackHandler_BadRequests(t *testing.T) {
+	tooSmall := 10
+	tests := []struct {
+		name    string
+		body    api.ContextPackRequest
+		buildOK bool
+	}{
+		{name: "Missing documents", body: api.ContextPackRequest{Model: "openai/gpt-4o"}},
+		{name: "Missing document id", body: api.ContextPackRequest{Model: "openai/gpt-4o", Documents: []api.ContextPackDocument{{Content: "x"}}}},
+		{name: "Unknown strategy", body: api.ContextPackRequest{Model: "openai/gpt-4o", Strategy: "compress", Documents: []api.ContextPackDocument{{ID: "x"}}}},
+		{name: "Window smaller than reserve", body: api.ContextPackRequest{Model: "openai/gpt-4o", MaxContextTokens: &tooSmall, Documents: []api.ContextPackDocument{{ID: "x"}}}, buildOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			log, cfg := routingTestSetup(t)
+
+			mockClient := providersmocks.NewMockClient(ctrl)
+			reg := providersmocks.NewMockProviderRegistry(ctrl)
+			if tt.buildOK {
+				reg.EXPECT().BuildProvider(constants.OpenaiID, mockClient).Return(providersmocks.NewMockIProvider(ctrl), nil)
+			}
+
+			w := postContextPack(t, api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil), tt.body)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
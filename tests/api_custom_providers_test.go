@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	api "github.com/inference-gateway/inference-gateway/api"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+)
+
+// selfProxyClient sends the self-proxy calls of the providers, which carry a
+// relative URL, to the gateway under test
+type selfProxyClient struct {
+	gateway *url.URL
+}
+
+func (c *selfProxyClient) Do(req *http.Request) (*http.Response, error) {
+	if req.URL.Host == "" {
+		req.URL.Scheme = c.gateway.Scheme
+		req.URL.Host = c.gateway.Host
+	}
+	return http.DefaultClient.Do(req)
+}
+
+func (c *selfProxyClient) Get(url string) (*http.Response, error) {
+	return http.DefaultClient.Get(c.gateway.String() + url)
+}
+
+func (c *selfProxyClient) Post(url string, bodyType string, body string) (*http.Response, error) {
+	return http.DefaultClient.Post(c.gateway.String()+url, bodyType, strings.NewReader(body))
+}
+
+// newOpenAICompatibleServer serves an OpenAI-compatible backend listing model
+// and answering chat completions with its own name, requiring header to be
+// set to value when header is not empty
+func newOpenAICompatibleServer(t *testing.T, name, model, header, value string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	authorized := func(w http.ResponseWriter, r *http.Request) bool {
+		if header != "" && r.Header.Get(header) != value {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = io.WriteString(w, `{"error":{"message":"invalid api key"}}`)
+			return false
+		}
+		return true
+	}
+	mux.HandleFunc("GET /v1/models", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"object":"list","data":[{"id":"`+model+`","object":"model","created":1750000000,"owned_by":"`+name+`"}]}`)
+	})
+	mux.HandleFunc("POST /v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		var req struct {
+			Model string `json:"model"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"id":"chatcmpl-1","object":"chat.completion","created":1750000000,"model":"`+req.Model+`","choices":[{"index":0,"message":{"role":"assistant","content":"served by `+name+`"},"finish_reason":"stop"}]}`)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCustomProviders(t *testing.T) {
+	vllm := newOpenAICompatibleServer(t, "vllm", "meta-llama/Llama-3.1-8B-Instruct", "Authorization", "Bearer vllm-key")
+	lmStudio := newOpenAICompatibleServer(t, "lm-studio", "qwen2.5-7b-instruct", "", "")
+	together := newOpenAICompatibleServer(t, "together", "mistralai/Mixtral-8x7B-Instruct-v0.1", "X-Together-Key", "tg-key")
+
+	env := map[string]string{
+		"VLLM_API_URL":         vllm.URL + "/v1",
+		"VLLM_API_KEY":         "vllm-key",
+		"LM_STUDIO_API_URL":    lmStudio.URL + "/v1",
+		"TOGETHER_API_URL":     together.URL + "/v1",
+		"TOGETHER_API_KEY":     "tg-key",
+		"TOGETHER_AUTH_HEADER": "X-Together-Key",
+	}
+	providerCfg, err := registry.CustomProviders("vllm,lm-studio,together", func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	})
+	require.NoError(t, err)
+
+	log, err := logger.NewLogger("test")
+	require.NoError(t, err)
+	reg := registry.NewReloadableRegistry(providerCfg, log)
+	t.Cleanup(func() { registry.SetCustomProviders(nil) })
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	gateway := httptest.NewServer(r)
+	defer gateway.Close()
+	gatewayURL, err := url.Parse(gateway.URL)
+	require.NoError(t, err)
+
+	cfg := config.Config{Server: &config.ServerConfig{ReadTimeout: 5 * time.Second}, Providers: providerCfg}
+	router := api.NewRouter(cfg, log, reg, &selfProxyClient{gateway: gatewayURL}, nil, nil, nil, nil)
+	r.Any("/proxy/:provider/*path", router.ProxyHandler)
+	r.GET("/v1/models", router.ListModelsHandler)
+	r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
+
+	t.Run("models of every instance are listed under its name", func(t *testing.T) {
+		resp, err := http.Get(gateway.URL + "/v1/models")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		models := modelsByID(t, body)
+		require.Len(t, models, 3)
+		assert.Equal(t, "vllm", models["vllm/meta-llama/Llama-3.1-8B-Instruct"]["served_by"])
+		assert.Equal(t, "lm-studio", models["lm-studio/qwen2.5-7b-instruct"]["served_by"])
+		assert.Equal(t, "together", models["together/mistralai/Mixtral-8x7B-Instruct-v0.1"]["served_by"])
+	})
+
+	t.Run("chat completions route by the instance prefix", func(t *testing.T) {
+		for model, servedBy := range map[string]string{
+			"vllm/meta-llama/Llama-3.1-8B-Instruct":         "vllm",
+			"lm-studio/qwen2.5-7b-instruct":                 "lm-studio",
+			"together/mistralai/Mixtral-8x7B-Instruct-v0.1": "together",
+		} {
+			resp, err := http.Post(gateway.URL+"/v1/chat/completions", "application/json",
+				strings.NewReader(`{"model":"`+model+`","messages":[{"role":"user","content":"Hi"}]}`))
+			require.NoError(t, err)
+			body, err := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+			var completion struct {
+				Model   string `json:"model"`
+				Choices []struct {
+					Message struct {
+						Content string `json:"content"`
+					} `json:"message"`
+				} `json:"choices"`
+			}
+			require.NoError(t, json.Unmarshal(body, &completion))
+			require.Len(t, completion.Choices, 1)
+			assert.Equal(t, "served by "+servedBy, completion.Choices[0].Message.Content)
+			assert.Equal(t, strings.TrimPrefix(model, servedBy+"/"), completion.Model)
+		}
+	})
+}
@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	api "github.com/inference-gateway/inference-gateway/api"
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+	mocks "github.com/inference-gateway/inference-gateway/tests/mocks"
+	providersmocks "github.com/inference-gateway/inference-gateway/tests/mocks/providers"
+)
+
+func TestErrorCodes(t *testing.T) {
+	tests := []struct {
+		name         string
+		path         string
+		body         string
+		expectedCode int
+		verify       func(t *testing.T, body []byte)
+	}{
+		{
+			name:         "Chat completions error body carries the code",
+			path:         "/v1/chat/completions",
+			body:         `{"model":"openai/gpt-3.5-turbo","messages":[{"role":"user","content":"hi"}]}`,
+			expectedCode: http.StatusForbidden,
+			verify: func(t *testing.T, body []byte) {
+				var resp api.ErrorResponse
+				require.NoError(t, json.Unmarshal(body, &resp))
+				assert.Equal(t, api.ErrorResponse{
+					Error:    "Model not allowed. Please check the list of allowed models.",
+					Code:     "IG-2003",
+					CodeName: "model_not_allowed",
+					DocsURL:  "/v1/errors/IG-2003",
+				}, resp)
+			},
+		},
+		{
+			name:         "Messages error keeps the Anthropic envelope",
+			path:         "/v1/messages",
+			body:         `{"model":"anthropic/claude-3","max_tokens":10,"messages":[{"role":"user","content":"hi"}]}`,
+			expectedCode: http.StatusForbidden,
+			verify: func(t *testing.T, body []byte) {
+				var resp types.MessagesError
+				require.NoError(t, json.Unmarshal(body, &resp))
+				assert.Equal(t, "invalid_request_error", resp.Error.Type)
+				require.NotNil(t, resp.Error.Code)
+				assert.Equal(t, "IG-2003", *resp.Error.Code)
+				require.NotNil(t, resp.Error.DocsUrl)
+				assert.Equal(t, "/v1/errors/IG-2003", *resp.Error.DocsUrl)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			log, cfg := routingTestSetup(t)
+			cfg.AllowedModels = "gpt-4"
+
+			// The request log must record the code of the failure
+			var mockLogger logger.Logger = mocks.NewMockLogger(ctrl)
+			mockLogger.(*mocks.MockLogger).EXPECT().Info("request received", gomock.Any()).AnyTimes()
+			mockLogger.(*mocks.MockLogger).EXPECT().Debug("request details", gomock.Any()).AnyTimes()
+			// Handlers log through the request's logger too
+			mockLogger.(*mocks.MockLogger).EXPECT().Error(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			mockLogger.(*mocks.MockLogger).EXPECT().Warn("request failed", "request_id", gomock.Any(), "method", http.MethodPost, "path", tt.path, "status", tt.expectedCode, "error_code", "IG-2003").Times(1)
+			loggerMiddleware, err := middlewares.NewLoggerMiddleware(&mockLogger)
+			require.NoError(t, err)
+
+			router := api.NewRouter(cfg, log, providersmocks.NewMockProviderRegistry(ctrl), providersmocks.NewMockClient(ctrl), nil, nil, nil, nil)
+			r := gin.New()
+			r.Use(loggerMiddleware.Middleware())
+			r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
+			r.POST("/v1/messages", router.MessagesHandler)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body)))
+			require.Equal(t, tt.expectedCode, w.Code, w.Body.String())
+			tt.verify(t, w.Body.Bytes())
+		})
+	}
+}
+
+func TestErrorCodesHandler(t *testing.T) {
+	r := gin.New()
+	r.GET("/v1/errors/:code", errcodes.GetHandler)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/errors/provider_token_missing", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var code errcodes.Code
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &code))
+	assert.Equal(t, "IG-1001", code.ID)
+	assert.NotEmpty(t, code.Remediation)
+}
+
+func TestErrorCodes_ProviderDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	log, cfg := routingTestSetup(t)
+	cfg.Providers[constants.OpenaiID] = &registry.ProviderConfig{ID: constants.OpenaiID, Name: constants.OpenaiDisplayName, URL: "http://localhost:8080", Token: "sk-test"}
+
+	providers := registry.NewReloadableRegistry(cfg.Providers, log)
+	providers.SetEnabled(constants.OpenaiID, false)
+	mockClient := providersmocks.NewMockClient(ctrl)
+	router := api.NewRouter(cfg, log, providers, mockClient, nil, nil, nil, nil)
+	r := gin.New()
+	r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
+
+	send := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat/completions",
+			strings.NewReader(`{"model":"openai/gpt-4o","messages":[{"role":"user","content":"hi"}]}`)))
+		return w
+	}
+
+	w := send()
+	require.Equal(t, http.StatusServiceUnavailable, w.Code, w.Body.String())
+	var resp api.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "IG-1006", resp.Code)
+	assert.Equal(t, "provider_disabled", resp.CodeName)
+
+	providers.SetEnabled(constants.OpenaiID, true)
+	mockClient.EXPECT().Do(gomock.Any()).Return(nil, errors.New("connection refused")).Times(1)
+	w = send()
+	assert.NotEqual(t, http.StatusServiceUnavailable, w.Code, "the provider is served again once enabled")
+}
@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	api "github.com/inference-gateway/inference-gateway/api"
+	config "github.com/inference-gateway/inference-gateway/config"
+	mcp "github.com/inference-gateway/inference-gateway/internal/mcp"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+	mcpmocks "github.com/inference-gateway/inference-gateway/tests/mocks/mcp"
+)
+
+func newMCPRouter(t *testing.T, mcpClient mcp.MCPClientInterface, expose bool) *gin.Engine {
+	t.Helper()
+	log, err := logger.NewLogger("test")
+	require.NoError(t, err)
+	cfg := config.Config{MCP: &config.MCPConfig{Expose: expose}}
+	router := api.NewRouter(cfg, log, nil, nil, mcpClient, nil, nil, nil)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/v1/mcp/resources", router.ListMCPResourcesHandler)
+	r.GET("/v1/mcp/resources/read", router.ReadMCPResourceHandler)
+	r.GET("/v1/mcp/prompts", router.ListMCPPromptsHandler)
+	r.POST("/v1/mcp/prompts/:name", router.GetMCPPromptHandler)
+	return r
+}
+
+func TestMCPResourcesHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mcpClient := mcpmocks.NewMockMCPClientInterface(ctrl)
+	mcpClient.EXPECT().IsInitialized().Return(true).AnyTimes()
+	mcpClient.EXPECT().GetServers().Return([]string{"http://docs:8080/mcp", "http://tools:8080/mcp"}).AnyTimes()
+	mimeType := "text/markdown"
+	mcpClient.EXPECT().ListResources(gomock.Any(), "http://docs:8080/mcp").
+		Return([]mcp.Resource{{URI: "file:///README.md", Name: "README.md", MimeType: &mimeType}}, nil).AnyTimes()
+	mcpClient.EXPECT().ListResources(gomock.Any(), "http://tools:8080/mcp").
+		Return(nil, errors.New("method not found")).AnyTimes()
+
+	var content mcp.ReadResourceResult_Contents_Item
+	require.NoError(t, content.FromTextResourceContents(mcp.TextResourceContents{URI: "file:///README.md", MimeType: &mimeType, Text: "# Docs"}))
+	mcpClient.EXPECT().ReadResource(gomock.Any(), "http://docs:8080/mcp", "file:///README.md").
+		Return(&mcp.ReadResourceResult{Contents: []mcp.ReadResourceResult_Contents_Item{content}}, nil)
+
+	r := newMCPRouter(t, mcpClient, true)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/mcp/resources", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var list types.ListMCPResourcesResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, []types.MCPResource{{URI: "file:///README.md", Name: "README.md", MimeType: &mimeType, Server: "http://docs:8080/mcp"}}, list.Data,
+		"servers failing to list resources are left out")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/mcp/resources/read?uri=file:///README.md", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var read types.ReadMCPResourceResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &read))
+	require.Len(t, read.Contents, 1)
+	require.NotNil(t, read.Contents[0].Text)
+	assert.Equal(t, "# Docs", *read.Contents[0].Text)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/mcp/resources/read?uri=file:///missing.md", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/mcp/resources/read", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestMCPPromptsHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mcpClient := mcpmocks.NewMockMCPClientInterface(ctrl)
+	mcpClient.EXPECT().IsInitialized().Return(true).AnyTimes()
+	mcpClient.EXPECT().GetServers().Return([]string{"http://github:8080/mcp"}).AnyTimes()
+	required := true
+	mcpClient.EXPECT().ListPrompts(gomock.Any(), "http://github:8080/mcp").
+		Return([]mcp.Prompt{{Name: "code_review", Arguments: &[]mcp.PromptArgument{{Name: "language", Required: &required}}}}, nil).AnyTimes()
+	mcpClient.EXPECT().GetPrompt(gomock.Any(), "http://github:8080/mcp", "code_review", map[string]string{"language": "go"}).
+		Return(&mcp.GetPromptResult{Messages: []mcp.PromptMessage{
+			{Role: "user", Content: map[string]any{"type": "text", "text": "Review this go code."}},
+		}}, nil)
+
+	r := newMCPRouter(t, mcpClient, true)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/mcp/prompts", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var list types.ListMCPPromptsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, "code_review", list.Data[0].Name)
+	assert.Equal(t, "http://github:8080/mcp", list.Data[0].Server)
+	assert.Equal(t, &[]types.MCPPromptArgument{{Name: "language", Required: &required}}, list.Data[0].Arguments)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/mcp/prompts/code_review", strings.NewReader(`{"arguments":{"language":"go"}}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	var prompt types.GetMCPPromptResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &prompt))
+	require.Len(t, prompt.Messages, 1)
+	assert.Equal(t, types.User, prompt.Messages[0].Role)
+	text, err := prompt.Messages[0].Content.AsMessageContent0()
+	require.NoError(t, err)
+	assert.Equal(t, "Review this go code.", text)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/mcp/prompts/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestMCPResourcesAndPromptsNotExposed(t *testing.T) {
+	r := newMCPRouter(t, nil, false)
+	for _, path := range []string{"/v1/mcp/resources", "/v1/mcp/resources/read?uri=file:///README.md", "/v1/mcp/prompts"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusForbidden, w.Code, path)
+	}
+}
@@ -0,0 +1,128 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	api "github.com/inference-gateway/inference-gateway/api"
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	config "github.com/inference-gateway/inference-gateway/config"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+	providersmocks "github.com/inference-gateway/inference-gateway/tests/mocks/providers"
+)
+
+func TestChatCompletions_ModelDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model-defaults.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+models:
+  openai/gpt-4o:
+    overrides:
+      temperature: 0
+    max:
+      max_tokens: 2048
+`), 0o600))
+
+	tests := []struct {
+		name                string
+		headers             map[string]string
+		expectedTemperature float32
+		expectedMaxTokens   int
+	}{
+		{
+			name:                "parameters are applied",
+			expectedTemperature: 0,
+			expectedMaxTokens:   2048,
+		},
+		{
+			name:                "admin token holders may opt out",
+			headers:             map[string]string{api.SkipModelDefaultsHeader: "true", middlewares.AdminTokenHeader: "admin-secret"},
+			expectedTemperature: 0.9,
+			expectedMaxTokens:   4096,
+		},
+		{
+			name:                "opt out needs the admin token",
+			headers:             map[string]string{api.SkipModelDefaultsHeader: "true", middlewares.AdminTokenHeader: "guess"},
+			expectedTemperature: 0,
+			expectedMaxTokens:   2048,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			log, cfg := routingTestSetup(t)
+			cfg.ModelDefaultsPath = path
+			cfg.Auth = &config.AuthConfig{AdminToken: "admin-secret"}
+
+			mockClient := providersmocks.NewMockClient(ctrl)
+			reg := providersmocks.NewMockProviderRegistry(ctrl)
+			provider := providersmocks.NewMockIProvider(ctrl)
+			reg.EXPECT().BuildProvider(constants.OpenaiID, mockClient).Return(provider, nil)
+			provider.EXPECT().ChatCompletions(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ any, req types.CreateChatCompletionRequest) (types.CreateChatCompletionResponse, error) {
+					require.NotNil(t, req.Temperature)
+					assert.Equal(t, tt.expectedTemperature, *req.Temperature)
+					require.NotNil(t, req.MaxTokens)
+					assert.Equal(t, tt.expectedMaxTokens, *req.MaxTokens)
+					return types.CreateChatCompletionResponse{}, nil
+				})
+
+			router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil)
+			r := gin.New()
+			r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
+
+			body := `{"model":"openai/gpt-4o","messages":[{"role":"user","content":"hello"}],"temperature":0.9,"max_tokens":4096}`
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestOllamaChat_ModelDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model-defaults.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+models:
+  openai/gpt-4o:
+    overrides:
+      temperature: 0
+`), 0o600))
+
+	ctrl := gomock.NewController(t)
+	log, cfg := routingTestSetup(t)
+	cfg.ModelDefaultsPath = path
+
+	mockClient := providersmocks.NewMockClient(ctrl)
+	reg := providersmocks.NewMockProviderRegistry(ctrl)
+	provider := providersmocks.NewMockIProvider(ctrl)
+	reg.EXPECT().BuildProvider(constants.OpenaiID, mockClient).Return(provider, nil)
+	provider.EXPECT().ChatCompletions(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, req types.CreateChatCompletionRequest) (types.CreateChatCompletionResponse, error) {
+			require.NotNil(t, req.Temperature)
+			assert.Equal(t, float32(0), *req.Temperature, "the Ollama API gets the model defaults too")
+			return types.CreateChatCompletionResponse{}, nil
+		})
+
+	router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil)
+	r := gin.New()
+	r.POST("/api/chat", router.OllamaChatHandler)
+
+	body := `{"model":"openai/gpt-4o","stream":false,"options":{"temperature":0.9},"messages":[{"role":"user","content":"hello"}]}`
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/chat", strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
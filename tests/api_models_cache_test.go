@@ -0,0 +1,47 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	api "github.com/inference-gateway/inference-gateway/api"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+	providersmocks "github.com/inference-gateway/inference-gateway/tests/mocks/providers"
+)
+
+func TestListModels_Cached(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	log, cfg := routingTestSetup(t)
+	cfg.ModelsCacheTtl = time.Minute
+	cfg.ModelsCacheMaxStale = time.Hour
+	cfg.Providers = map[types.Provider]*registry.ProviderConfig{constants.OpenaiID: {ID: constants.OpenaiID}}
+
+	mockClient := providersmocks.NewMockClient(ctrl)
+	reg := providersmocks.NewMockProviderRegistry(ctrl)
+	provider := providersmocks.NewMockIProvider(ctrl)
+	reg.EXPECT().BuildProvider(constants.OpenaiID, mockClient).Return(provider, nil).Times(4)
+	provider.EXPECT().ListModels(gomock.Any()).Return(types.ListModelsResponse{
+		Object: "list",
+		Data:   []types.Model{{ID: "openai/gpt-4o", Object: "model", OwnedBy: "openai", ServedBy: constants.OpenaiID}},
+	}, nil).Times(1)
+
+	router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil)
+	r := gin.New()
+	r.GET("/v1/models", router.ListModelsHandler)
+
+	for _, query := range []string{"", "?provider=openai", "", "?provider=openai"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/models"+query, nil))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), `"openai/gpt-4o"`)
+	}
+}
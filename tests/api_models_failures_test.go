@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	api "github.com/inference-gateway/inference-gateway/api"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+	providersmocks "github.com/inference-gateway/inference-gateway/tests/mocks/providers"
+)
+
+func TestListModels_PartialFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	log, cfg := routingTestSetup(t)
+	cfg.ModelsProviderTimeout = 50 * time.Millisecond
+	cfg.Providers = map[types.Provider]*registry.ProviderConfig{
+		constants.OpenaiID:    {ID: constants.OpenaiID},
+		constants.GroqID:      {ID: constants.GroqID},
+		constants.AnthropicID: {ID: constants.AnthropicID},
+	}
+
+	mockClient := providersmocks.NewMockClient(ctrl)
+	reg := providersmocks.NewMockProviderRegistry(ctrl)
+	healthy := providersmocks.NewMockIProvider(ctrl)
+	slow := providersmocks.NewMockIProvider(ctrl)
+	failing := providersmocks.NewMockIProvider(ctrl)
+	reg.EXPECT().BuildProvider(constants.OpenaiID, mockClient).Return(healthy, nil)
+	reg.EXPECT().BuildProvider(constants.GroqID, mockClient).Return(slow, nil)
+	reg.EXPECT().BuildProvider(constants.AnthropicID, mockClient).Return(failing, nil)
+	healthy.EXPECT().ListModels(gomock.Any()).Return(types.ListModelsResponse{
+		Object: "list",
+		Data:   []types.Model{{ID: "openai/gpt-4o", Object: "model", OwnedBy: "openai", ServedBy: constants.OpenaiID}},
+	}, nil)
+	slow.EXPECT().ListModels(gomock.Any()).DoAndReturn(func(ctx context.Context) (types.ListModelsResponse, error) {
+		<-ctx.Done()
+		return types.ListModelsResponse{}, ctx.Err()
+	})
+	failing.EXPECT().ListModels(gomock.Any()).Return(types.ListModelsResponse{}, errors.New("connection refused"))
+
+	router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil)
+	r := gin.New()
+	r.GET("/v1/models", router.ListModelsHandler)
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/models", nil))
+	assert.Less(t, time.Since(start), time.Second, "a slow provider only delays the listing by its own timeout")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp types.ListModelsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data, 1)
+	assert.Equal(t, "openai/gpt-4o", resp.Data[0].ID)
+	require.NotNil(t, resp.FailedProviders)
+	assert.Equal(t, []types.FailedProvider{
+		{Provider: constants.AnthropicID, Code: "IG-4007", Error: "Failed to list models"},
+		{Provider: constants.GroqID, Code: "IG-4002", Error: "Listing models timed out after 50ms"},
+	}, *resp.FailedProviders)
+}
@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	api "github.com/inference-gateway/inference-gateway/api"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+	providersmocks "github.com/inference-gateway/inference-gateway/tests/mocks/providers"
+)
+
+func TestListModels_FilteringAndPagination(t *testing.T) {
+	toolsAndVision := &types.ModelCapabilities{SupportsTools: true, SupportsVision: true, Source: types.ModelCapabilitiesSourceCommunity}
+	toolsOnly := &types.ModelCapabilities{SupportsTools: true, Source: types.ModelCapabilitiesSourceCommunity}
+	listings := map[types.Provider][]types.Model{
+		constants.OpenaiID: {
+			{ID: "openai/gpt-4o", Object: "model", OwnedBy: "openai", ServedBy: constants.OpenaiID, Capabilities: toolsAndVision},
+			{ID: "openai/gpt-3.5-turbo", Object: "model", OwnedBy: "openai", ServedBy: constants.OpenaiID, Capabilities: toolsOnly},
+			{ID: "openai/whisper-1", Object: "model", OwnedBy: "openai", ServedBy: constants.OpenaiID},
+		},
+		constants.GroqID: {
+			{ID: "groq/llama-3.3-70b-versatile", Object: "model", OwnedBy: "meta", ServedBy: constants.GroqID, Capabilities: toolsOnly},
+			{ID: "groq/llama-4-scout", Object: "model", OwnedBy: "meta", ServedBy: constants.GroqID, Capabilities: toolsAndVision},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+		hasMore  *bool
+		firstID  string
+		lastID   string
+	}{
+		{
+			name:     "owned_by",
+			query:    "?owned_by=meta",
+			expected: []string{"groq/llama-3.3-70b-versatile", "groq/llama-4-scout"},
+		},
+		{
+			name:     "capabilities must all be supported",
+			query:    "?capability=tools,vision",
+			expected: []string{"groq/llama-4-scout", "openai/gpt-4o"},
+		},
+		{
+			name:     "capability with provider",
+			query:    "?provider=openai&capability=tools",
+			expected: []string{"openai/gpt-3.5-turbo", "openai/gpt-4o"},
+		},
+		{
+			name:     "first page",
+			query:    "?limit=2",
+			expected: []string{"groq/llama-3.3-70b-versatile", "groq/llama-4-scout"},
+			hasMore:  ptr(true),
+			firstID:  "groq/llama-3.3-70b-versatile",
+			lastID:   "groq/llama-4-scout",
+		},
+		{
+			name:     "next page",
+			query:    "?limit=2&after=groq/llama-4-scout",
+			expected: []string{"openai/gpt-3.5-turbo", "openai/gpt-4o"},
+			hasMore:  ptr(true),
+			firstID:  "openai/gpt-3.5-turbo",
+			lastID:   "openai/gpt-4o",
+		},
+		{
+			name:     "last page",
+			query:    "?limit=2&after=openai/gpt-4o",
+			expected: []string{"openai/whisper-1"},
+			hasMore:  ptr(false),
+			firstID:  "openai/whisper-1",
+			lastID:   "openai/whisper-1",
+		},
+		{
+			name:     "filtered page",
+			query:    "?owned_by=openai&limit=1",
+			expected: []string{"openai/gpt-3.5-turbo"},
+			hasMore:  ptr(true),
+			firstID:  "openai/gpt-3.5-turbo",
+			lastID:   "openai/gpt-3.5-turbo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			log, cfg := routingTestSetup(t)
+			cfg.Providers = map[types.Provider]*registry.ProviderConfig{
+				constants.OpenaiID: {ID: constants.OpenaiID},
+				constants.GroqID:   {ID: constants.GroqID},
+			}
+
+			mockClient := providersmocks.NewMockClient(ctrl)
+			reg := providersmocks.NewMockProviderRegistry(ctrl)
+			for id, models := range listings {
+				provider := providersmocks.NewMockIProvider(ctrl)
+				provider.EXPECT().ListModels(gomock.Any()).Return(types.ListModelsResponse{Object: "list", Data: models}, nil).AnyTimes()
+				reg.EXPECT().BuildProvider(id, mockClient).Return(provider, nil).AnyTimes()
+			}
+
+			router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil)
+			r := gin.New()
+			r.GET("/v1/models", router.ListModelsHandler)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/models"+tt.query, nil))
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			var resp types.ListModelsResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			ids := make([]string, 0, len(resp.Data))
+			for _, model := range resp.Data {
+				ids = append(ids, model.ID)
+			}
+			if tt.hasMore == nil {
+				assert.ElementsMatch(t, tt.expected, ids)
+				assert.Nil(t, resp.HasMore, "no pagination fields without limit or after")
+				return
+			}
+			assert.Equal(t, tt.expected, ids)
+			assert.Equal(t, tt.hasMore, resp.HasMore)
+			require.NotNil(t, resp.FirstID)
+			assert.Equal(t, tt.firstID, *resp.FirstID)
+			require.NotNil(t, resp.LastID)
+			assert.Equal(t, tt.lastID, *resp.LastID)
+		})
+	}
+}
+
+func TestListModels_InvalidQuery(t *testing.T) {
+	for _, query := range []string{"?capability=telepathy", "?limit=0", "?limit=1001", "?limit=ten"} {
+		t.Run(query, func(t *testing.T) {
+			log, cfg := routingTestSetup(t)
+			router := api.NewRouter(cfg, log, nil, nil, nil, nil, nil, nil)
+			r := gin.New()
+			r.GET("/v1/models", router.ListModelsHandler)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/models"+query, nil))
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
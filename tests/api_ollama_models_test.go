@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	api "github.com/inference-gateway/inference-gateway/api"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+)
+
+// newOllamaModelsRouter serves the model management routes with the ollama
+// and openai providers pointed at server, ollama under a /v1 prefix as it is
+// usually configured
+func newOllamaModelsRouter(t *testing.T, server *httptest.Server) *gin.Engine {
+	t.Helper()
+	providerCfg := contextWindowProviderConfig(server.URL, constants.OllamaID, constants.OpenaiID)
+	providerCfg[constants.OllamaID].URL = server.URL + "/v1"
+
+	log, err := logger.NewLogger("test")
+	require.NoError(t, err)
+	cfg := config.Config{Server: &config.ServerConfig{ReadTimeout: 5 * time.Second}, Providers: providerCfg}
+	router := api.NewRouter(cfg, log, registry.NewProviderRegistry(providerCfg, log), &forwardingClient{}, nil, nil, nil, nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/v1/providers/:provider/models", router.OllamaListModelsHandler)
+	r.POST("/v1/providers/:provider/models/pull", router.OllamaPullModelHandler)
+	r.DELETE("/v1/providers/:provider/models/*model", router.OllamaDeleteModelHandler)
+	return r
+}
+
+// forwardingClient sends the requests as they are, the runtime API calls
+// carrying their full URL
+type forwardingClient struct{}
+
+func (forwardingClient) Do(req *http.Request) (*http.Response, error) {
+	return http.DefaultClient.Do(req)
+}
+
+func (forwardingClient) Get(url string) (*http.Response, error) {
+	return http.DefaultClient.Get(url)
+}
+
+func (forwardingClient) Post(url string, bodyType string, body string) (*http.Response, error) {
+	return http.DefaultClient.Post(url, bodyType, strings.NewReader(body))
+}
+
+func TestOllamaModelManagement(t *testing.T) {
+	var deleted []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/tags", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"models":[{"name":"llama3.2:latest","model":"llama3.2:latest","size":2019393189,"digest":"a80c4f17acd5"}]}`)
+	})
+	mux.HandleFunc("POST /api/pull", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model  string `json:"model"`
+			Stream bool   `json:"stream"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if req.Model == "missing" {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = io.WriteString(w, `{"error":"pull model manifest: file does not exist"}`)
+			return
+		}
+		if !req.Stream {
+			_, _ = io.WriteString(w, `{"status":"success"}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = io.WriteString(w, "{\"status\":\"pulling manifest\"}\n")
+		_, _ = io.WriteString(w, "{\"status\":\"pulling dde5aa3fc5ff\",\"digest\":\"sha256:dde5aa3fc5ff\",\"total\":2019377376,\"completed\":1048576}\n")
+		_, _ = io.WriteString(w, "{\"status\":\"success\"}\n")
+	})
+	mux.HandleFunc("DELETE /api/delete", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model string `json:"model"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if req.Model == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"error":"model 'missing' not found"}`)
+			return
+		}
+		deleted = append(deleted, req.Model)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	r := newOllamaModelsRouter(t, server)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(method, path, strings.NewReader(body))
+		require.NoError(t, err)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("list answers the models pulled on the backend", func(t *testing.T) {
+		w := serve(http.MethodGet, "/v1/providers/ollama/models", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"models":[{"name":"llama3.2:latest","model":"llama3.2:latest","size":2019393189,"digest":"a80c4f17acd5"}]}`, w.Body.String())
+	})
+
+	t.Run("pull streams the progress as ndjson", func(t *testing.T) {
+		w := serve(http.MethodPost, "/v1/providers/ollama/models/pull", `{"model":"llama3.2"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		require.Len(t, lines, 3)
+		assert.JSONEq(t, `{"status":"pulling dde5aa3fc5ff","digest":"sha256:dde5aa3fc5ff","total":2019377376,"completed":1048576}`, lines[1])
+		assert.JSONEq(t, `{"status":"success"}`, lines[2])
+	})
+
+	t.Run("pull without stream answers the outcome", func(t *testing.T) {
+		w := serve(http.MethodPost, "/v1/providers/ollama/models/pull", `{"model":"llama3.2","stream":false}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"status":"success"}`, w.Body.String())
+	})
+
+	t.Run("pull failures are mapped to the error catalog", func(t *testing.T) {
+		w := serve(http.MethodPost, "/v1/providers/ollama/models/pull", `{"model":"missing"}`)
+		var resp api.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "pull model manifest: file does not exist", resp.Error)
+		assert.Equal(t, http.StatusInternalServerError, resp.ProviderStatus)
+
+		w = serve(http.MethodPost, "/v1/providers/ollama/models/pull", `{}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("delete removes models named with slashes", func(t *testing.T) {
+		w := serve(http.MethodDelete, "/v1/providers/ollama/models/hf.co/org/model:q4", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"id":"hf.co/org/model:q4","object":"model","deleted":true}`, w.Body.String())
+		assert.Equal(t, []string{"hf.co/org/model:q4"}, deleted)
+
+		w = serve(http.MethodDelete, "/v1/providers/ollama/models/missing", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("other providers are rejected", func(t *testing.T) {
+		w := serve(http.MethodGet, "/v1/providers/openai/models", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "IG-1005")
+
+		w = serve(http.MethodGet, "/v1/providers/groq/models", "")
+		assert.Contains(t, w.Body.String(), "IG-1002")
+	})
+}
@@ -0,0 +1,343 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	api "github.com/inference-gateway/inference-gateway/api"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+	providersmocks "github.com/inference-gateway/inference-gateway/tests/mocks/providers"
+)
+
+func TestOllamaTagsHandler_AggregatesProviders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	log, cfg := routingTestSetup(t)
+	cfg.Providers = map[types.Provider]*registry.ProviderConfig{
+		constants.OpenaiID: {ID: constants.OpenaiID},
+		constants.GroqID:   {ID: constants.GroqID},
+	}
+	cfg.DisallowedModels = "groq/blocked"
+
+	mockClient := providersmocks.NewMockClient(ctrl)
+	reg := providersmocks.NewMockProviderRegistry(ctrl)
+	openai := providersmocks.NewMockIProvider(ctrl)
+	groq := providersmocks.NewMockIProvider(ctrl)
+	openai.EXPECT().ListModels(gomock.Any()).Return(types.ListModelsResponse{Data: []types.Model{
+		{ID: "openai/gpt-4o", Created: 1700000000, ServedBy: constants.OpenaiID},
+	}}, nil)
+	groq.EXPECT().ListModels(gomock.Any()).Return(types.ListModelsResponse{Data: []types.Model{
+		{ID: "groq/llama-3", ServedBy: constants.GroqID},
+		{ID: "groq/blocked", ServedBy: constants.GroqID},
+	}}, nil)
+	reg.EXPECT().BuildProvider(constants.OpenaiID, mockClient).Return(openai, nil)
+	reg.EXPECT().BuildProvider(constants.GroqID, mockClient).Return(groq, nil)
+
+	router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil)
+	r := gin.New()
+	r.GET("/api/tags", router.OllamaTagsHandler)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/tags", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp api.OllamaTagsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	names := make(map[string]string)
+	for _, m := range resp.Models {
+		names[m.Name] = m.Details.Family
+	}
+	assert.Equal(t, map[string]string{"openai/gpt-4o": "openai", "groq/llama-3": "groq"}, names)
+}
+
+func TestOllamaChatHandler_NonStreaming(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	log, cfg := routingTestSetup(t)
+
+	mockClient := providersmocks.NewMockClient(ctrl)
+	reg := providersmocks.NewMockProviderRegistry(ctrl)
+	provider := providersmocks.NewMockIProvider(ctrl)
+	reg.EXPECT().BuildProvider(constants.OpenaiID, mockClient).Return(provider, nil)
+	provider.EXPECT().ChatCompletions(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, req types.CreateChatCompletionRequest) (types.CreateChatCompletionResponse, error) {
+			assert.Equal(t, "gpt-4o", req.Model)
+			require.NotNil(t, req.MaxTokens)
+			assert.Equal(t, 64, *req.MaxTokens)
+			require.NotNil(t, req.Temperature)
+			assert.InDelta(t, 0.2, *req.Temperature, 0.0001)
+			require.Len(t, req.Messages, 2)
+			assert.Equal(t, types.System, req.Messages[0].Role)
+			assert.True(t, req.Messages[1].HasImageContent(), "ollama images should become image parts")
+
+			msg := types.NewTextMessage(t, types.Assistant, "Hello there")
+			return types.CreateChatCompletionResponse{
+				Choices: []types.ChatCompletionChoice{{Message: msg, FinishReason: types.Stop}},
+				Usage:   &types.CompletionUsage{PromptTokens: 12, CompletionTokens: 3},
+			}, nil
+		})
+
+	router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil)
+	r := gin.New()
+	r.POST("/api/chat", router.OllamaChatHandler)
+
+	body := `{"model":"openai/gpt-4o","stream":false,"options":{"temperature":0.2,"num_predict":64},
+		"messages":[{"role":"system","content":"be brief"},{"role":"user","content":"what is this?","images":["iVBORw0KGgo="]}]}`
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/chat", strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp api.OllamaChatResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "openai/gpt-4o", resp.Model)
+	assert.Equal(t, "assistant", resp.Message.Role)
+	assert.Equal(t, "Hello there", resp.Message.Content)
+	assert.True(t, resp.Done)
+	assert.Equal(t, "stop", resp.DoneReason)
+	assert.Equal(t, int64(12), resp.PromptEvalCount)
+	assert.Equal(t, int64(3), resp.EvalCount)
+}
+
+func TestOllamaChatHandler_StreamingNDJSON(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	log, cfg := routingTestSetup(t)
+
+	mockClient := providersmocks.NewMockClient(ctrl)
+	reg := providersmocks.NewMockProviderRegistry(ctrl)
+	provider := providersmocks.NewMockIProvider(ctrl)
+	reg.EXPECT().BuildProvider(constants.OpenaiID, mockClient).Return(provider, nil)
+
+	lines := []string{
+		`data: {"choices":[{"index":0,"delta":{"role":"assistant","content":"Hel"}}]}` + "\n",
+		"\n",
+		`data: {"choices":[{"index":0,"delta":{"content":"lo"}}]}` + "\n",
+		`data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_time","arguments":"{\"tz\":"}}]}}]}` + "\n",
+		`data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"UTC\"}"}}]},"finish_reason":"tool_calls"}]}` + "\n",
+		`data: {"choices":[],"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7}}` + "\n",
+		"data: [DONE]\n",
+	}
+	provider.EXPECT().StreamChatCompletions(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, req types.CreateChatCompletionRequest) (<-chan []byte, error) {
+			require.NotNil(t, req.Stream)
+			assert.True(t, *req.Stream, "ollama streams by default")
+			ch := make(chan []byte, len(lines))
+			for _, l := range lines {
+				ch <- []byte(l)
+			}
+			close(ch)
+			return ch, nil
+		})
+
+	router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil)
+	r := gin.New()
+	r.POST("/api/chat", router.OllamaChatHandler)
+
+	gatewayServer := httptest.NewServer(r)
+	defer gatewayServer.Close()
+
+	resp, err := http.Post(gatewayServer.URL+"/api/chat", "application/json", strings.NewReader(`{"model":"openai/gpt-4o","messages":[{"role":"user","content":"hi"}]}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	var chunks []api.OllamaChatResponse
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var chunk api.OllamaChatResponse
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &chunk))
+		chunks = append(chunks, chunk)
+	}
+	require.Len(t, chunks, 3)
+	assert.Equal(t, "Hel", chunks[0].Message.Content)
+	assert.Equal(t, "lo", chunks[1].Message.Content)
+	assert.False(t, chunks[0].Done)
+
+	final := chunks[2]
+	assert.True(t, final.Done)
+	assert.Equal(t, "tool_calls", final.DoneReason)
+	assert.Equal(t, int64(5), final.PromptEvalCount)
+	assert.Equal(t, int64(2), final.EvalCount)
+	require.Len(t, final.Message.ToolCalls, 1)
+	assert.Equal(t, "get_time", final.Message.ToolCalls[0].Function.Name)
+	assert.Equal(t, map[string]any{"tz": "UTC"}, final.Message.ToolCalls[0].Function.Arguments)
+}
+
+func TestOllamaChatHandler_StreamProcessing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	log, cfg := routingTestSetup(t)
+	cfg.ThinkTagMode = "reasoning_content"
+	cfg.StreamResumeEnable = true
+	cfg.StreamResumeBufferSize = 16
+	cfg.StreamResumeTtl = time.Minute
+
+	mockClient := providersmocks.NewMockClient(ctrl)
+	reg := providersmocks.NewMockProviderRegistry(ctrl)
+	provider := providersmocks.NewMockIProvider(ctrl)
+	reg.EXPECT().BuildProvider(constants.OpenaiID, mockClient).Return(provider, nil)
+
+	// The provider reports no usage, so the final line gets local counts
+	lines := []string{
+		`data: {"choices":[{"index":0,"delta":{"role":"assistant","content":"<think>pondering</think>"}}]}` + "\n",
+		`data: {"choices":[{"index":0,"delta":{"content":"Hello there"},"finish_reason":"stop"}]}` + "\n",
+		"data: [DONE]\n",
+	}
+	var streamCtx context.Context
+	provider.EXPECT().StreamChatCompletions(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, req types.CreateChatCompletionRequest) (<-chan []byte, error) {
+			streamCtx = ctx
+			require.NotNil(t, req.StreamOptions)
+			assert.True(t, req.StreamOptions.IncludeUsage, "the final line carries the token counts")
+			ch := make(chan []byte, len(lines))
+			for _, l := range lines {
+				ch <- []byte(l)
+			}
+			close(ch)
+			return ch, nil
+		})
+
+	router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil)
+	r := gin.New()
+	r.POST("/api/chat", router.OllamaChatHandler)
+
+	gatewayServer := httptest.NewServer(r)
+	defer gatewayServer.Close()
+
+	resp, err := http.Post(gatewayServer.URL+"/api/chat", "application/json", strings.NewReader(`{"model":"openai/gpt-4o","messages":[{"role":"user","content":"hi"}]}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var chunks []api.OllamaChatResponse
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var chunk api.OllamaChatResponse
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &chunk))
+		chunks = append(chunks, chunk)
+	}
+	require.NotEmpty(t, chunks)
+	var content, thinking strings.Builder
+	for _, chunk := range chunks {
+		content.WriteString(chunk.Message.Content)
+		thinking.WriteString(chunk.Message.Thinking)
+	}
+	assert.Equal(t, "Hello there", content.String(), "think blocks are taken out of the content")
+	assert.Equal(t, "pondering", thinking.String())
+
+	final := chunks[len(chunks)-1]
+	assert.True(t, final.Done)
+	assert.Positive(t, final.PromptEvalCount, "usage is counted locally")
+	assert.Positive(t, final.EvalCount)
+
+	require.NotNil(t, streamCtx)
+	assert.Eventually(t, func() bool { return streamCtx.Err() != nil }, time.Second, 10*time.Millisecond, "the generation is stopped once the stream is relayed")
+}
+
+func TestOllamaChatHandler_StructuredOutput(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	log, cfg := routingTestSetup(t)
+	cfg.StructuredOutputEmulatedProviders = "anthropic"
+	cfg.StructuredOutputMaxRetries = 1
+
+	mockClient := providersmocks.NewMockClient(ctrl)
+	reg := providersmocks.NewMockProviderRegistry(ctrl)
+	provider := providersmocks.NewMockIProvider(ctrl)
+	reg.EXPECT().BuildProvider(constants.AnthropicID, mockClient).Return(provider, nil)
+
+	responses := []string{`{"name":"Ada"}`, `{"name": "Ada", "age": 36}`}
+	calls := 0
+	provider.EXPECT().ChatCompletions(gomock.Any(), gomock.Any()).Times(2).DoAndReturn(
+		func(_ any, req types.CreateChatCompletionRequest) (types.CreateChatCompletionResponse, error) {
+			assert.Nil(t, req.ResponseFormat, "emulated providers must not receive response_format")
+			assert.Equal(t, types.System, req.Messages[0].Role)
+			resp := structuredOutputResponse(t, responses[calls])
+			calls++
+			return resp, nil
+		})
+
+	router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil)
+	r := gin.New()
+	r.POST("/api/chat", router.OllamaChatHandler)
+
+	body := `{"model":"anthropic/claude-sonnet-4-5","stream":false,"messages":[{"role":"user","content":"who?"}],
+		"format":{"type":"object","properties":{"name":{"type":"string"},"age":{"type":"integer","minimum":0}},"required":["name","age"]}}`
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/chat", strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp api.OllamaChatResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.JSONEq(t, `{"name":"Ada","age":36}`, resp.Message.Content, "invalid output is repaired like on /v1/chat/completions")
+}
+
+func TestOllamaChatHandler_ToolCallIDs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	log, cfg := routingTestSetup(t)
+
+	mockClient := providersmocks.NewMockClient(ctrl)
+	reg := providersmocks.NewMockProviderRegistry(ctrl)
+	provider := providersmocks.NewMockIProvider(ctrl)
+	reg.EXPECT().BuildProvider(constants.OpenaiID, mockClient).Return(provider, nil)
+	provider.EXPECT().ChatCompletions(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, req types.CreateChatCompletionRequest) (types.CreateChatCompletionResponse, error) {
+			require.Len(t, req.Messages, 7)
+			callIDs := func(m types.Message) map[string]string {
+				require.NotNil(t, m.ToolCalls)
+				ids := make(map[string]string)
+				for _, tc := range *m.ToolCalls {
+					ids[tc.Function.Name] = tc.ID
+				}
+				return ids
+			}
+			resultID := func(m types.Message) string {
+				require.NotNil(t, m.ToolCallID)
+				return *m.ToolCallID
+			}
+
+			first := callIDs(req.Messages[1])
+			assert.Equal(t, first["get_time"], resultID(req.Messages[2]), "results are matched by tool name")
+			assert.Equal(t, first["get_weather"], resultID(req.Messages[3]))
+			second := callIDs(req.Messages[4])
+			assert.NotEqual(t, first["get_time"], second["get_time"], "IDs are unique across the conversation")
+			assert.Equal(t, second["get_time"], resultID(req.Messages[5]), "results without a tool name are matched in order")
+
+			return types.CreateChatCompletionResponse{
+				Choices: []types.ChatCompletionChoice{{Message: types.NewTextMessage(t, types.Assistant, "done"), FinishReason: types.Stop}},
+			}, nil
+		})
+
+	router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil)
+	r := gin.New()
+	r.POST("/api/chat", router.OllamaChatHandler)
+
+	body := `{"model":"openai/gpt-4o","stream":false,"messages":[
+		{"role":"user","content":"time and weather?"},
+		{"role":"assistant","content":"","tool_calls":[
+			{"function":{"name":"get_weather","arguments":{"city":"Paris"}}},
+			{"function":{"name":"get_time","arguments":{"tz":"CET"}}}]},
+		{"role":"tool","content":"12:00","tool_name":"get_time"},
+		{"role":"tool","content":"sunny","tool_name":"get_weather"},
+		{"role":"assistant","content":"","tool_calls":[{"function":{"name":"get_time","arguments":{"tz":"UTC"}}}]},
+		{"role":"tool","content":"11:00"},
+		{"role":"user","content":"thanks"}]}`
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/chat", strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	api "github.com/inference-gateway/inference-gateway/api"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+	providersmocks "github.com/inference-gateway/inference-gateway/tests/mocks/providers"
+)
+
+func TestChatCompletions_ParameterWarnings(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    string
+		provider types.Provider
+		params   string
+		expected string
+		verify   func(t *testing.T, req types.CreateChatCompletionRequest)
+	}{
+		{
+			name:     "unsupported parameters are dropped with a warning",
+			model:    "anthropic/claude-sonnet-4-5",
+			provider: constants.AnthropicID,
+			params:   `"temperature":1.5,"seed":42`,
+			expected: "seed dropped, temperature clamped to 1",
+			verify: func(t *testing.T, req types.CreateChatCompletionRequest) {
+				assert.Nil(t, req.Seed)
+				require.NotNil(t, req.Temperature)
+				assert.EqualValues(t, 1, *req.Temperature)
+			},
+		},
+		{
+			name:     "supported parameters are forwarded without warning",
+			model:    "openai/gpt-4o",
+			provider: constants.OpenaiID,
+			params:   `"temperature":1.5,"seed":42`,
+			verify: func(t *testing.T, req types.CreateChatCompletionRequest) {
+				require.NotNil(t, req.Seed)
+				assert.Equal(t, 42, *req.Seed)
+				assert.EqualValues(t, 1.5, *req.Temperature)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			log, cfg := routingTestSetup(t)
+
+			mockClient := providersmocks.NewMockClient(ctrl)
+			reg := providersmocks.NewMockProviderRegistry(ctrl)
+			provider := providersmocks.NewMockIProvider(ctrl)
+			reg.EXPECT().BuildProvider(tt.provider, mockClient).Return(provider, nil)
+			provider.EXPECT().ChatCompletions(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ any, req types.CreateChatCompletionRequest) (types.CreateChatCompletionResponse, error) {
+					tt.verify(t, req)
+					return types.CreateChatCompletionResponse{}, nil
+				})
+
+			router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil)
+			r := gin.New()
+			r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
+
+			body := `{"model":"` + tt.model + `","messages":[{"role":"user","content":"hello"}],` + tt.params + `}`
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body)))
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.Equal(t, tt.expected, w.Header().Get(api.ParameterWarningsHeader))
+		})
+	}
+}
+
+func TestChatCompletions_ExtraBody(t *testing.T) {
+	tests := []struct {
+		name      string
+		extraBody string
+		status    int
+	}{
+		{name: "allowed fields reach the provider", extraBody: `{"groq":{"service_tier":"flex"},"ollama":{"keep_alive":"10m"}}`, status: http.StatusOK},
+		{name: "fields outside the allowlist are rejected", extraBody: `{"groq":{"temperature":2}}`, status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			log, cfg := routingTestSetup(t)
+
+			mockClient := providersmocks.NewMockClient(ctrl)
+			reg := providersmocks.NewMockProviderRegistry(ctrl)
+			provider := providersmocks.NewMockIProvider(ctrl)
+			reg.EXPECT().BuildProvider(constants.GroqID, mockClient).Return(provider, nil)
+			if tt.status == http.StatusOK {
+				provider.EXPECT().ChatCompletions(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ any, req types.CreateChatCompletionRequest) (types.CreateChatCompletionResponse, error) {
+						require.NotNil(t, req.ExtraBody)
+						assert.Equal(t, "flex", (*req.ExtraBody)["groq"]["service_tier"])
+						return types.CreateChatCompletionResponse{}, nil
+					})
+			}
+
+			router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil)
+			r := gin.New()
+			r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
+
+			body := `{"model":"groq/llama-3.3-70b-versatile","messages":[{"role":"user","content":"hello"}],"extra_body":` + tt.extraBody + `}`
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body)))
+			require.Equal(t, tt.status, w.Code, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	api "github.com/inference-gateway/inference-gateway/api"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	plugin "github.com/inference-gateway/inference-gateway/providers/plugin"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+// reverseBackend is a proprietary backend answering with the reversed words
+// of the last message
+type reverseBackend struct{}
+
+func (reverseBackend) ListModels(ctx context.Context) ([]types.Model, error) {
+	return []types.Model{{ID: "reverse-1", OwnedBy: "acme"}}, nil
+}
+
+func (reverseBackend) ChatCompletion(ctx context.Context, req types.CreateChatCompletionRequest) (*types.CreateChatCompletionResponse, error) {
+	if req.Model != "reverse-1" {
+		return nil, &plugin.Error{Status: http.StatusTooManyRequests, Message: "quota exceeded"}
+	}
+	message := types.Message{Role: types.Assistant}
+	if err := message.Content.FromMessageContent0(strings.Join(reverseWords(req), " ")); err != nil {
+		return nil, err
+	}
+	return &types.CreateChatCompletionResponse{ID: "rev-1", Model: req.Model, Choices: []types.ChatCompletionChoice{{Message: message, FinishReason: types.Stop}}}, nil
+}
+
+func (reverseBackend) ChatCompletionStream(ctx context.Context, req types.CreateChatCompletionRequest, send func(types.CreateChatCompletionStreamResponse) error) error {
+	words := reverseWords(req)
+	for i, word := range words {
+		chunk := types.CreateChatCompletionStreamResponse{ID: "rev-1", Model: req.Model, Choices: []types.ChatCompletionStreamChoice{{Delta: types.ChatCompletionStreamResponseDelta{Content: word + " "}}}}
+		if i == len(words)-1 {
+			chunk.Choices[0].FinishReason = types.Stop
+			chunk.Usage = &types.CompletionUsage{PromptTokens: 3, CompletionTokens: int64(len(words)), TotalTokens: int64(3 + len(words))}
+		}
+		if err := send(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func reverseWords(req types.CreateChatCompletionRequest) []string {
+	words := strings.Fields(req.Messages[len(req.Messages)-1].TextContent())
+	for i, j := 0, len(words)-1; i < j; i, j = i+1, j-1 {
+		words[i], words[j] = words[j], words[i]
+	}
+	return words
+}
+
+func TestProviderPlugin(t *testing.T) {
+	sidecar := httptest.NewServer(plugin.Handler(reverseBackend{}))
+	defer sidecar.Close()
+
+	providerCfg, err := registry.CustomProviders("acme", func(key string) (string, bool) {
+		if key != "ACME_API_URL" {
+			return "", false
+		}
+		return sidecar.URL + "/v1", true
+	})
+	require.NoError(t, err)
+	log, err := logger.NewLogger("test")
+	require.NoError(t, err)
+	reg := registry.NewReloadableRegistry(providerCfg, log)
+	t.Cleanup(func() { registry.SetCustomProviders(nil) })
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	gateway := httptest.NewServer(r)
+	defer gateway.Close()
+	gatewayURL, err := url.Parse(gateway.URL)
+	require.NoError(t, err)
+
+	cfg := config.Config{Server: &config.ServerConfig{ReadTimeout: 5 * time.Second}, Providers: providerCfg}
+	router := api.NewRouter(cfg, log, reg, &selfProxyClient{gateway: gatewayURL}, nil, nil, nil, nil)
+	r.Any("/proxy/:provider/*path", router.ProxyHandler)
+	r.GET("/v1/models", router.ListModelsHandler)
+	r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
+
+	post := func(body string) (int, string) {
+		resp, err := http.Post(gateway.URL+"/v1/chat/completions", "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(data)
+	}
+
+	t.Run("models are listed under the provider id", func(t *testing.T) {
+		resp, err := http.Get(gateway.URL + "/v1/models")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		models := modelsByID(t, body)
+		require.Contains(t, models, "acme/reverse-1")
+		assert.Equal(t, "acme", models["acme/reverse-1"]["served_by"])
+	})
+
+	t.Run("chat completions reach the backend", func(t *testing.T) {
+		status, body := post(`{"model":"acme/reverse-1","messages":[{"role":"user","content":"one two three"}]}`)
+		require.Equal(t, http.StatusOK, status, body)
+		assert.Contains(t, body, `"content":"three two one"`)
+	})
+
+	t.Run("streams are relayed with their usage", func(t *testing.T) {
+		status, body := post(`{"model":"acme/reverse-1","stream":true,"messages":[{"role":"user","content":"one two three"}]}`)
+		require.Equal(t, http.StatusOK, status, body)
+		assert.Contains(t, body, `"content":"three "`)
+		assert.Contains(t, body, `"completion_tokens":3`)
+		assert.True(t, strings.HasSuffix(strings.TrimSpace(body), "data: [DONE]"), body)
+	})
+
+	t.Run("backend errors are mapped to the error catalog", func(t *testing.T) {
+		status, body := post(`{"model":"acme/other","messages":[{"role":"user","content":"hi"}]}`)
+		assert.Equal(t, http.StatusTooManyRequests, status)
+		assert.Contains(t, body, "quota exceeded")
+	})
+}
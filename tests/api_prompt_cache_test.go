@@ -0,0 +1,176 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	api "github.com/inference-gateway/inference-gateway/api"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+	providersmocks "github.com/inference-gateway/inference-gateway/tests/mocks/providers"
+)
+
+func TestChatCompletions_PromptCacheAuto(t *testing.T) {
+	largePrompt := strings.Repeat("You are a meticulous support agent. ", 200)
+
+	tests := []struct {
+		name     string
+		model    string
+		provider types.Provider
+		auto     bool
+		body     string
+		verify   func(t *testing.T, req types.CreateChatCompletionRequest)
+	}{
+		{
+			name:     "large system prompt is marked",
+			model:    "anthropic/claude-sonnet-4-5",
+			provider: constants.AnthropicID,
+			auto:     true,
+			body:     `[{"role":"system","content":"` + largePrompt + `"},{"role":"user","content":"hello"}]`,
+			verify: func(t *testing.T, req types.CreateChatCompletionRequest) {
+				parts, err := req.Messages[0].Content.AsMessageContent1()
+				require.NoError(t, err)
+				require.Len(t, parts, 1)
+				text, err := parts[0].AsTextContentPart()
+				require.NoError(t, err)
+				assert.Equal(t, largePrompt, text.Text)
+				require.NotNil(t, text.CacheControl)
+				assert.Equal(t, types.Ephemeral, text.CacheControl.Type)
+
+				user, err := req.Messages[1].Content.MarshalJSON()
+				require.NoError(t, err)
+				assert.Equal(t, `"hello"`, string(user), "only the static prefix is marked")
+			},
+		},
+		{
+			name:     "small system prompt is not marked",
+			model:    "anthropic/claude-sonnet-4-5",
+			provider: constants.AnthropicID,
+			auto:     true,
+			body:     `[{"role":"system","content":"Be brief."},{"role":"user","content":"hello"}]`,
+			verify: func(t *testing.T, req types.CreateChatCompletionRequest) {
+				_, err := req.Messages[0].Content.AsMessageContent0()
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:     "client breakpoints are kept as they are",
+			model:    "anthropic/claude-sonnet-4-5",
+			provider: constants.AnthropicID,
+			auto:     true,
+			body:     `[{"role":"system","content":[{"type":"text","text":"Static"},{"type":"text","text":"` + largePrompt + `","cache_control":{"type":"ephemeral"}}]},{"role":"user","content":"hello"}]`,
+			verify: func(t *testing.T, req types.CreateChatCompletionRequest) {
+				parts, err := req.Messages[0].Content.AsMessageContent1()
+				require.NoError(t, err)
+				first, err := parts[0].AsTextContentPart()
+				require.NoError(t, err)
+				assert.Nil(t, first.CacheControl)
+				last, err := parts[1].AsTextContentPart()
+				require.NoError(t, err)
+				assert.NotNil(t, last.CacheControl, "client cache_control is passed through")
+			},
+		},
+		{
+			name:     "providers caching automatically are not marked",
+			model:    "openai/gpt-4o",
+			provider: constants.OpenaiID,
+			auto:     true,
+			body:     `[{"role":"system","content":"` + largePrompt + `"},{"role":"user","content":"hello"}]`,
+			verify: func(t *testing.T, req types.CreateChatCompletionRequest) {
+				_, err := req.Messages[0].Content.AsMessageContent0()
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:     "tool breakpoints are removed for other providers",
+			model:    "openai/gpt-4o",
+			provider: constants.OpenaiID,
+			body:     `[{"role":"user","content":"hello"}],"tools":[{"type":"function","function":{"name":"search"},"cache_control":{"type":"ephemeral"}}]`,
+			verify: func(t *testing.T, req types.CreateChatCompletionRequest) {
+				require.NotNil(t, req.Tools)
+				assert.Nil(t, (*req.Tools)[0].CacheControl)
+			},
+		},
+		{
+			name:     "disabled",
+			model:    "anthropic/claude-sonnet-4-5",
+			provider: constants.AnthropicID,
+			body:     `[{"role":"system","content":"` + largePrompt + `"},{"role":"user","content":"hello"}]`,
+			verify: func(t *testing.T, req types.CreateChatCompletionRequest) {
+				_, err := req.Messages[0].Content.AsMessageContent0()
+				assert.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			log, cfg := routingTestSetup(t)
+			cfg.PromptCacheAuto = tt.auto
+			cfg.PromptCacheMinTokens = 1024
+
+			mockClient := providersmocks.NewMockClient(ctrl)
+			reg := providersmocks.NewMockProviderRegistry(ctrl)
+			provider := providersmocks.NewMockIProvider(ctrl)
+			reg.EXPECT().BuildProvider(tt.provider, mockClient).Return(provider, nil)
+			provider.EXPECT().ChatCompletions(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ any, req types.CreateChatCompletionRequest) (types.CreateChatCompletionResponse, error) {
+					tt.verify(t, req)
+					return types.CreateChatCompletionResponse{}, nil
+				})
+
+			router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil)
+			r := gin.New()
+			r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
+
+			body := `{"model":"` + tt.model + `","messages":` + tt.body + `}`
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body)))
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestChatCompletions_PromptCacheAutoMarksLastTool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	log, cfg := routingTestSetup(t)
+	cfg.PromptCacheAuto = true
+	cfg.PromptCacheMinTokens = 1024
+
+	description := strings.Repeat("Searches the knowledge base for the given query. ", 100)
+	tools, err := json.Marshal([]map[string]any{
+		{"type": "function", "function": map[string]any{"name": "search", "description": description}},
+		{"type": "function", "function": map[string]any{"name": "lookup", "description": description}},
+	})
+	require.NoError(t, err)
+
+	mockClient := providersmocks.NewMockClient(ctrl)
+	reg := providersmocks.NewMockProviderRegistry(ctrl)
+	provider := providersmocks.NewMockIProvider(ctrl)
+	reg.EXPECT().BuildProvider(constants.AnthropicID, mockClient).Return(provider, nil)
+	provider.EXPECT().ChatCompletions(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, req types.CreateChatCompletionRequest) (types.CreateChatCompletionResponse, error) {
+			require.NotNil(t, req.Tools)
+			assert.Nil(t, (*req.Tools)[0].CacheControl)
+			assert.NotNil(t, (*req.Tools)[1].CacheControl)
+			return types.CreateChatCompletionResponse{}, nil
+		})
+
+	router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil)
+	r := gin.New()
+	r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
+
+	body := `{"model":"anthropic/claude-sonnet-4-5","messages":[{"role":"user","content":"hello"}],"tools":` + string(tools) + `}`
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
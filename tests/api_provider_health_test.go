@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	api "github.com/inference-gateway/inference-gateway/api"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+	providersmocks "github.com/inference-gateway/inference-gateway/tests/mocks/providers"
+)
+
+func TestListModels_ProviderHealth(t *testing.T) {
+	upstream := func(model string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if strings.HasSuffix(r.URL.Path, "/models") {
+				_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"` + model + `","object":"model","created":1,"owned_by":"test"}]}`))
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"error":"down"}`))
+		}))
+	}
+	flapping := upstream("gpt-4o")
+	defer flapping.Close()
+	healthy := upstream("llama-3.3-70b-versatile")
+	defer healthy.Close()
+
+	ctrl := gomock.NewController(t)
+	log, cfg := routingTestSetup(t)
+	cfg.ProviderHealthWindow = time.Minute
+	cfg.ProviderHealthMinFailures = 2
+	cfg.Providers = map[types.Provider]*registry.ProviderConfig{
+		constants.OpenaiID: {
+			ID:        constants.OpenaiID,
+			Name:      constants.OpenaiDisplayName,
+			URL:       flapping.URL,
+			Token:     "test-token",
+			AuthType:  constants.AuthTypeBearer,
+			Endpoints: types.Endpoints{Models: constants.OpenaiModelsEndpoint},
+		},
+		constants.GroqID: {
+			ID:        constants.GroqID,
+			Name:      constants.GroqDisplayName,
+			URL:       healthy.URL,
+			Token:     "test-token",
+			AuthType:  constants.AuthTypeBearer,
+			Endpoints: types.Endpoints{Models: constants.GroqModelsEndpoint},
+		},
+	}
+
+	mockClient := providersmocks.NewMockClient(ctrl)
+	router := api.NewRouter(cfg, log, registry.NewProviderRegistry(cfg.Providers, log), mockClient, nil, nil, nil, nil)
+	r := gin.New()
+	r.GET("/v1/models", router.ListModelsHandler)
+	r.Any("/proxy/:provider/*path", router.ProxyHandler)
+
+	gateway := httptest.NewServer(r)
+	defer gateway.Close()
+
+	// Provider calls reach the upstreams through the gateway's own proxy
+	mockClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		proxied := req.Clone(req.Context())
+		proxied.URL, _ = url.Parse(gateway.URL + req.URL.String())
+		proxied.RequestURI = ""
+		return http.DefaultClient.Do(proxied)
+	}).AnyTimes()
+
+	get := func(path string) (int, []byte) {
+		resp, err := http.Get(gateway.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, body
+	}
+	listModels := func(query string) map[string]*bool {
+		status, body := get("/v1/models" + query)
+		require.Equal(t, http.StatusOK, status, string(body))
+		var resp types.ListModelsResponse
+		require.NoError(t, json.Unmarshal(body, &resp))
+		models := make(map[string]*bool)
+		for _, model := range resp.Data {
+			models[model.ID] = model.Available
+		}
+		return models
+	}
+
+	assert.Equal(t, map[string]*bool{"openai/gpt-4o": nil, "groq/llama-3.3-70b-versatile": nil}, listModels(""))
+
+	for range 3 {
+		resp, err := http.Post(gateway.URL+"/proxy/openai/chat/completions", "application/json", strings.NewReader(`{}`))
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	}
+
+	assert.Equal(t, map[string]*bool{"groq/llama-3.3-70b-versatile": nil}, listModels(""))
+	assert.Empty(t, listModels("?provider=openai"))
+
+	available, unavailable := true, false
+	assert.Equal(t, map[string]*bool{"openai/gpt-4o": &unavailable, "groq/llama-3.3-70b-versatile": &available}, listModels("?include_unavailable=true"))
+
+	status, _ := get("/v1/models?include_unavailable=maybe")
+	assert.Equal(t, http.StatusBadRequest, status)
+}
@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	api "github.com/inference-gateway/inference-gateway/api"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+	providersmocks "github.com/inference-gateway/inference-gateway/tests/mocks/providers"
+)
+
+func TestApplyProviderPolicies(t *testing.T) {
+	providers := func() map[types.Provider]*registry.ProviderConfig {
+		return map[types.Provider]*registry.ProviderConfig{
+			constants.OpenaiID: {ID: constants.OpenaiID},
+			constants.OllamaID: {ID: constants.OllamaID, Timeout: 10 * time.Minute},
+		}
+	}
+
+	cfg := providers()
+	require.NoError(t, registry.ApplyPolicies(cfg, "openai=30s, ollama=15m", "openai=2s", "openai=3"))
+	assert.Equal(t, 30*time.Second, cfg[constants.OpenaiID].Timeout)
+	assert.Equal(t, 2*time.Second, cfg[constants.OpenaiID].ConnectTimeout)
+	assert.Equal(t, 3, cfg[constants.OpenaiID].MaxRetries)
+	assert.Equal(t, 15*time.Minute, cfg[constants.OllamaID].Timeout)
+
+	cfg = providers()
+	require.NoError(t, registry.ApplyPolicies(cfg, "", "", ""))
+	assert.Equal(t, 10*time.Minute, cfg[constants.OllamaID].Timeout, "registry defaults apply without overrides")
+
+	for _, invalid := range [][3]string{
+		{"openai", "", ""},
+		{"unknown=30s", "", ""},
+		{"openai=soon", "", ""},
+		{"", "openai=0s", ""},
+		{"", "", "openai=-1"},
+	} {
+		assert.Error(t, registry.ApplyPolicies(providers(), invalid[0], invalid[1], invalid[2]), invalid)
+	}
+}
+
+func TestProxyHandler_ProviderPolicies(t *testing.T) {
+	var attempts atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := attempts.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/slow"):
+			time.Sleep(200 * time.Millisecond)
+		case n == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	defer upstream.Close()
+
+	ctrl := gomock.NewController(t)
+	log, cfg := routingTestSetup(t)
+	cfg.ProviderRetryBackoff = time.Millisecond
+	cfg.Providers = map[types.Provider]*registry.ProviderConfig{
+		constants.OpenaiID: {
+			ID:         constants.OpenaiID,
+			Name:       constants.OpenaiDisplayName,
+			URL:        upstream.URL,
+			Token:      "test-token",
+			AuthType:   constants.AuthTypeBearer,
+			Timeout:    50 * time.Millisecond,
+			MaxRetries: 1,
+		},
+	}
+	router := api.NewRouter(cfg, log, registry.NewProviderRegistry(cfg.Providers, log), providersmocks.NewMockClient(ctrl), nil, nil, nil, nil)
+	r := gin.New()
+	r.Any("/proxy/:provider/*path", router.ProxyHandler)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/proxy/openai/chat/completions", strings.NewReader(`{"model":"gpt-4o"}`)))
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, `{"model":"gpt-4o"}`, w.Body.String(), "the retry replays the body")
+	assert.Equal(t, int32(2), attempts.Load())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/proxy/openai/slow", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Contains(t, w.Body.String(), "upstream_timeout")
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	api "github.com/inference-gateway/inference-gateway/api"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+	providersmocks "github.com/inference-gateway/inference-gateway/tests/mocks/providers"
+)
+
+func TestProxyHandler_StrictMode(t *testing.T) {
+	var forwarded []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		forwarded = append(forwarded, r.URL.Path+" "+string(body))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"object":"list","data":[]}`))
+	}))
+	defer upstream.Close()
+
+	ctrl := gomock.NewController(t)
+	log, cfg := routingTestSetup(t)
+	cfg.ProxyStrict = true
+	cfg.ProxyAllowedPaths = "*/chat/completions, openai/models"
+	cfg.DisallowedModels = "openai/gpt-4o"
+	cfg.Providers = map[types.Provider]*registry.ProviderConfig{
+		constants.OpenaiID: {
+			ID:        constants.OpenaiID,
+			Name:      constants.OpenaiDisplayName,
+			URL:       upstream.URL,
+			Token:     "test-token",
+			AuthType:  constants.AuthTypeBearer,
+			Endpoints: types.Endpoints{Models: constants.OpenaiModelsEndpoint},
+		},
+	}
+	router := api.NewRouter(cfg, log, registry.NewProviderRegistry(cfg.Providers, log), providersmocks.NewMockClient(ctrl), nil, nil, nil, nil)
+	r := gin.New()
+	r.Any("/proxy/:provider/*path", router.ProxyHandler)
+	gateway := httptest.NewServer(r)
+	defer gateway.Close()
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		status   int
+		contains string
+	}{
+		{name: "allowed chat completion", method: http.MethodPost, path: "/proxy/openai/chat/completions", body: `{"model":"gpt-4o-mini","messages":[]}`, status: http.StatusOK},
+		{name: "allowed listing", method: http.MethodGet, path: "/proxy/openai/models", status: http.StatusOK},
+		{name: "path not allowed", method: http.MethodPost, path: "/proxy/openai/files", body: `{}`, status: http.StatusForbidden, contains: "proxy_path_not_allowed"},
+		{name: "chat completion without a model", method: http.MethodPost, path: "/proxy/openai/chat/completions", body: `{"messages":[]}`, status: http.StatusBadRequest, contains: "must be JSON objects naming a model"},
+		{name: "disallowed model", method: http.MethodPost, path: "/proxy/openai/chat/completions", body: `{"model":"gpt-4o","messages":[]}`, status: http.StatusForbidden, contains: "model_disallowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, gateway.URL+tt.path, strings.NewReader(tt.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.status, resp.StatusCode, string(body))
+			assert.Contains(t, string(body), tt.contains)
+		})
+	}
+	assert.Equal(t, []string{
+		`/chat/completions {"model":"gpt-4o-mini","messages":[]}`,
+		"/models ",
+	}, forwarded, "only the allowed requests reach the provider, with their body intact")
+}
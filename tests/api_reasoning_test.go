@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	api "github.com/inference-gateway/inference-gateway/api"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+	providersmocks "github.com/inference-gateway/inference-gateway/tests/mocks/providers"
+)
+
+func TestChatCompletions_ReasoningTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected string
+	}{
+		{
+			name:     "reasoning tokens are counted locally",
+			response: `{"id":"1","model":"deepseek-r1","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"42","reasoning_content":"Thinking hard"}}],"usage":{"prompt_tokens":5,"completion_tokens":9,"total_tokens":14}}`,
+			expected: `{"reasoning_tokens":4}`,
+		},
+		{
+			name:     "reasoning tokens reported by the provider are kept",
+			response: `{"id":"1","model":"o3-mini","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"42"}}],"usage":{"prompt_tokens":5,"completion_tokens":90,"total_tokens":95,"completion_tokens_details":{"reasoning_tokens":88}}}`,
+			expected: `{"reasoning_tokens":88}`,
+		},
+		{
+			name:     "no reasoning",
+			response: `{"id":"1","model":"llama3","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"42"}}],"usage":{"prompt_tokens":5,"completion_tokens":1,"total_tokens":6}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			log, cfg := routingTestSetup(t)
+
+			var upstream types.CreateChatCompletionResponse
+			require.NoError(t, json.Unmarshal([]byte(tt.response), &upstream))
+
+			mockClient := providersmocks.NewMockClient(ctrl)
+			reg := providersmocks.NewMockProviderRegistry(ctrl)
+			provider := providersmocks.NewMockIProvider(ctrl)
+			reg.EXPECT().BuildProvider(constants.OllamaID, mockClient).Return(provider, nil)
+			provider.EXPECT().ChatCompletions(gomock.Any(), gomock.Any()).Return(upstream, nil)
+
+			router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil)
+			r := gin.New()
+			r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
+
+			body := `{"model":"ollama/deepseek-r1","messages":[{"role":"user","content":"6 times 7?"}]}`
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body)))
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			var resp struct {
+				Usage struct {
+					CompletionTokensDetails json.RawMessage `json:"completion_tokens_details"`
+				} `json:"usage"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			if tt.expected == "" {
+				assert.Empty(t, resp.Usage.CompletionTokensDetails)
+				return
+			}
+			assert.JSONEq(t, tt.expected, string(resp.Usage.CompletionTokensDetails))
+		})
+	}
+}
+
+func TestChatCompletions_ThinkTagsRelocated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	log, cfg := routingTestSetup(t)
+	cfg.ThinkTagMode = "reasoning_content"
+
+	var upstream types.CreateChatCompletionResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"1","model":"deepseek-r1","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"<think>\nThinking hard\n</think>\n\n42"}}],"usage":{"prompt_tokens":5,"completion_tokens":9,"total_tokens":14}}`), &upstream))
+
+	mockClient := providersmocks.NewMockClient(ctrl)
+	reg := providersmocks.NewMockProviderRegistry(ctrl)
+	provider := providersmocks.NewMockIProvider(ctrl)
+	reg.EXPECT().BuildProvider(constants.OllamaID, mockClient).Return(provider, nil)
+	provider.EXPECT().ChatCompletions(gomock.Any(), gomock.Any()).Return(upstream, nil)
+
+	router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil)
+	r := gin.New()
+	r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
+
+	body := `{"model":"ollama/deepseek-r1","messages":[{"role":"user","content":"6 times 7?"}]}`
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp types.CreateChatCompletionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	content, err := resp.Choices[0].Message.Content.AsMessageContent0()
+	require.NoError(t, err)
+	assert.Equal(t, "42", content)
+	require.NotNil(t, resp.Choices[0].Message.ReasoningContent)
+	assert.Equal(t, "Thinking hard", *resp.Choices[0].Message.ReasoningContent)
+	require.NotNil(t, resp.Usage.CompletionTokensDetails)
+	assert.EqualValues(t, 4, *resp.Usage.CompletionTokensDetails.ReasoningTokens, "relocated reasoning is counted")
+}
@@ -180,7 +180,7 @@ func TestListModelsHandler_AllowedModelsFiltering(t *testing.T) {
 				Providers: providerCfg,
 			}
 
-			router := api.NewRouter(cfg, log, registry, mockClient, nil, nil, nil)
+			router := api.NewRouter(cfg, log, registry, mockClient, nil, nil, nil, nil)
 
 			gin.SetMode(gin.TestMode)
 			r := gin.New()
@@ -278,7 +278,7 @@ func TestListModelsHandler_ErrorCases(t *testing.T) {
 				},
 			}
 
-			router := api.NewRouter(cfg, log, registry, mockClient, nil, nil, nil)
+			router := api.NewRouter(cfg, log, registry, mockClient, nil, nil, nil, nil)
 
 			gin.SetMode(gin.TestMode)
 			r := gin.New()
@@ -356,7 +356,7 @@ func TestListModelsHandler_Include(t *testing.T) {
 		},
 		Providers: providerCfg,
 	}
-	router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil)
+	router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil)
 
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
@@ -373,13 +373,13 @@ func TestListModelsHandler_Include(t *testing.T) {
 			name:         "no include returns no metadata keys",
 			query:        "",
 			expectStatus: http.StatusOK,
-			absent:       []string{"context_window", "pricing"},
+			absent:       []string{"context_window", "pricing", "capabilities"},
 		},
 		{
 			name:         "provider only returns no metadata keys",
 			query:        "?provider=openai",
 			expectStatus: http.StatusOK,
-			absent:       []string{"context_window", "pricing"},
+			absent:       []string{"context_window", "pricing", "capabilities"},
 		},
 		{
 			name:         "single key context_window",
@@ -395,6 +395,13 @@ func TestListModelsHandler_Include(t *testing.T) {
 			presentNull:  []string{"pricing"},
 			absent:       []string{"context_window"},
 		},
+		{
+			name:         "single key capabilities",
+			query:        "?include=capabilities",
+			expectStatus: http.StatusOK,
+			presentNull:  []string{"capabilities"},
+			absent:       []string{"context_window", "pricing"},
+		},
 		{
 			name:         "multiple keys",
 			query:        "?include=context_window,pricing",
@@ -602,7 +609,7 @@ func TestChatCompletionsHandler_ModelValidation(t *testing.T) {
 				Providers: providerCfg,
 			}
 
-			router := api.NewRouter(cfg, log, registry, mockClient, nil, nil, nil)
+			router := api.NewRouter(cfg, log, registry, mockClient, nil, nil, nil, nil)
 
 			gin.SetMode(gin.TestMode)
 			r := gin.New()
@@ -777,7 +784,7 @@ func TestListModelsHandler_DisallowedModelsFiltering(t *testing.T) {
 				Providers: providerCfg,
 			}
 
-			router := api.NewRouter(cfg, log, registry, mockClient, nil, nil, nil)
+			router := api.NewRouter(cfg, log, registry, mockClient, nil, nil, nil, nil)
 
 			gin.SetMode(gin.TestMode)
 			r := gin.New()
@@ -953,7 +960,7 @@ func TestChatCompletionsHandler_DisallowedModelValidation(t *testing.T) {
 				Providers: providerCfg,
 			}
 
-			router := api.NewRouter(cfg, log, registry, mockClient, nil, nil, nil)
+			router := api.NewRouter(cfg, log, registry, mockClient, nil, nil, nil, nil)
 
 			gin.SetMode(gin.TestMode)
 			r := gin.New()
@@ -1110,7 +1117,7 @@ func TestChatCompletionsHandler_AllowedModelsTakesPrecedence(t *testing.T) {
 				Providers: providerCfg,
 			}
 
-			router := api.NewRouter(cfg, log, registry, mockClient, nil, nil, nil)
+			router := api.NewRouter(cfg, log, registry, mockClient, nil, nil, nil, nil)
 
 			gin.SetMode(gin.TestMode)
 			r := gin.New()
@@ -1260,7 +1267,7 @@ func TestChatCompletionsHandler_StreamingErrorHandling(t *testing.T) {
 				BuildProvider(constants.OpenaiID, mockClient).
 				Return(mockProvider, nil)
 
-			router := api.NewRouter(cfg, log, mockRegistry, mockClient, nil, nil, nil)
+			router := api.NewRouter(cfg, log, mockRegistry, mockClient, nil, nil, nil, nil)
 
 			gin.SetMode(gin.TestMode)
 			r := gin.New()
@@ -1,7 +1,9 @@
 package tests
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -17,6 +19,7 @@ import (
 	config "github.com/inference-gateway/inference-gateway/config"
 	logger "github.com/inference-gateway/inference-gateway/logger"
 	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	core "github.com/inference-gateway/inference-gateway/providers/core"
 	registry "github.com/inference-gateway/inference-gateway/providers/registry"
 	routing "github.com/inference-gateway/inference-gateway/providers/routing"
 	types "github.com/inference-gateway/inference-gateway/providers/types"
@@ -89,7 +92,7 @@ func TestChatCompletionsRouting_RoundRobinRotation(t *testing.T) {
 		routing.Deployment{Provider: "openai", Model: "model-a"},
 		routing.Deployment{Provider: "groq", Model: "model-b"},
 	)
-	router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, sel)
+	router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, sel, nil)
 	r := gin.New()
 	r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
 
@@ -128,7 +131,7 @@ func TestChatCompletionsRouting_StreamingPassthrough(t *testing.T) {
 		routing.Deployment{Provider: "openai", Model: "stream-model"},
 		routing.Deployment{Provider: "groq", Model: "stream-model-b"},
 	)
-	router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, sel)
+	router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, sel, nil)
 	r := gin.New()
 	r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
 
@@ -155,7 +158,7 @@ func TestChatCompletionsRouting_DisabledPassthrough(t *testing.T) {
 	mockClient := providersmocks.NewMockClient(ctrl)
 	reg := providersmocks.NewMockProviderRegistry(ctrl)
 
-	router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil)
+	router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil)
 	r := gin.New()
 	r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
 
@@ -188,7 +191,7 @@ func TestChatCompletionsRouting_ExplicitProviderWins(t *testing.T) {
 		routing.Deployment{Provider: "openai", Model: "model-a"},
 		routing.Deployment{Provider: "ollama", Model: "model-b"},
 	)
-	router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, sel)
+	router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, sel, nil)
 	r := gin.New()
 	r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
 
@@ -234,7 +237,7 @@ func TestChatCompletionsRouting_AllowedModelsFiltersAlias(t *testing.T) {
 				routing.Deployment{Provider: "openai", Model: "model-a"},
 				routing.Deployment{Provider: "groq", Model: "model-b"},
 			)
-			router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, sel)
+			router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, sel, nil)
 			r := gin.New()
 			r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
 
@@ -247,3 +250,203 @@ func TestChatCompletionsRouting_AllowedModelsFiltersAlias(t *testing.T) {
 		})
 	}
 }
+
+// greetingEmbedder embeds greetings and descriptions mentioning them as one
+// direction and everything else as another
+type greetingEmbedder struct{}
+
+func (greetingEmbedder) Embed(_ context.Context, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		if text == "hi" || strings.Contains(text, "greeting") {
+			vectors[i] = []float64{1, 0}
+		} else {
+			vectors[i] = []float64{0, 1}
+		}
+	}
+	return vectors, nil
+}
+
+// Semantic routing sends a matching prompt to the route's model and keeps the
+// requested model when no route is similar enough.
+func TestChatCompletionsRouting_Semantic(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		provider    types.Provider
+		model       string
+		route       string
+	}{
+		{"matching route", "small talk and greetings", constants.GroqID, "model-b", "chit-chat"},
+		{"no match keeps requested model", "tax law", constants.OpenaiID, "gpt-4o", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			log, cfg := routingTestSetup(t)
+
+			mockClient := providersmocks.NewMockClient(ctrl)
+			prov := providersmocks.NewMockIProvider(ctrl)
+			reg := providersmocks.NewMockProviderRegistry(ctrl)
+
+			prov.EXPECT().ChatCompletions(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ any, req types.CreateChatCompletionRequest) (types.CreateChatCompletionResponse, error) {
+					assert.Equal(t, tt.model, req.Model)
+					return types.CreateChatCompletionResponse{ID: "x", Model: req.Model}, nil
+				})
+			reg.EXPECT().BuildProvider(tt.provider, mockClient).Return(prov, nil)
+
+			semantic, err := routing.NewSemanticRouter(&routing.SemanticConfig{
+				Routes: []routing.SemanticRoute{{Name: "chit-chat", Description: tt.description, Model: "groq/model-b"}},
+			}, mockClient, greetingEmbedder{})
+			require.NoError(t, err)
+
+			router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, semantic)
+			r := gin.New()
+			r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, chatRequest(t, "openai/gpt-4o", false))
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.route, rec.Header().Get("X-Semantic-Route"))
+		})
+	}
+}
+
+// A deployment whose provider answered 429 with Retry-After is skipped by the
+// rotation until the wait is over, and the client gets the provider's headers.
+func TestChatCompletionsRouting_BackoffPrefersOtherDeployments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	log, cfg := routingTestSetup(t)
+	cfg.ProviderBackoffMax = time.Minute
+
+	mockClient := providersmocks.NewMockClient(ctrl)
+	provA := providersmocks.NewMockIProvider(ctrl)
+	provB := providersmocks.NewMockIProvider(ctrl)
+	reg := providersmocks.NewMockProviderRegistry(ctrl)
+
+	provA.EXPECT().ChatCompletions(gomock.Any(), gomock.Any()).Return(types.CreateChatCompletionResponse{}, &core.HTTPError{
+		StatusCode: http.StatusTooManyRequests,
+		Message:    `{"error":{"message":"Rate limit reached","type":"requests","code":"rate_limit_exceeded"}}`,
+		Header:     http.Header{"Retry-After": {"30"}, "X-Ratelimit-Remaining-Requests": {"0"}},
+	}).Times(1)
+	provB.EXPECT().ChatCompletions(gomock.Any(), gomock.Any()).Return(types.CreateChatCompletionResponse{ID: "b"}, nil).Times(2)
+	reg.EXPECT().BuildProvider(constants.OpenaiID, mockClient).Return(provA, nil).Times(1)
+	reg.EXPECT().BuildProvider(constants.GroqID, mockClient).Return(provB, nil).Times(2)
+
+	sel := routingSelector(t, "fast-chat",
+		routing.Deployment{Provider: "openai", Model: "model-a"},
+		routing.Deployment{Provider: "groq", Model: "model-b"},
+	)
+	router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, sel, nil)
+	r := gin.New()
+	r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, chatRequest(t, "fast-chat", false))
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "30", rec.Header().Get("Retry-After"))
+	assert.Equal(t, "0", rec.Header().Get("X-Ratelimit-Remaining-Requests"))
+	var resp api.ErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "IG-4004", resp.Code)
+	assert.True(t, resp.Retryable)
+	assert.Equal(t, http.StatusTooManyRequests, resp.ProviderStatus)
+
+	for i := range 2 {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, chatRequest(t, "fast-chat", false))
+		assert.Equal(t, http.StatusOK, rec.Code, "call %d", i)
+		assert.Equal(t, "groq", rec.Header().Get("X-Selected-Provider"), "call %d", i)
+	}
+}
+
+// A sticky weighted pool keeps each session on the variant first assigned
+// to it.
+func TestChatCompletionsRouting_WeightedStickySession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	log, cfg := routingTestSetup(t)
+
+	mockClient := providersmocks.NewMockClient(ctrl)
+	prov := providersmocks.NewMockIProvider(ctrl)
+	reg := providersmocks.NewMockProviderRegistry(ctrl)
+	prov.EXPECT().ChatCompletions(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ any, req types.CreateChatCompletionRequest) (types.CreateChatCompletionResponse, error) {
+			return types.CreateChatCompletionResponse{ID: "x", Model: req.Model}, nil
+		}).AnyTimes()
+	reg.EXPECT().BuildProvider(gomock.Any(), mockClient).Return(prov, nil).AnyTimes()
+
+	sel, err := routing.NewSelector(&routing.PoolsConfig{
+		Models: map[string]routing.PoolConfig{"default-chat": {
+			Strategy: routing.StrategyWeighted,
+			Sticky:   true,
+			Deployments: []routing.Deployment{
+				{Provider: "openai", Model: "model-a", Weight: 50},
+				{Provider: "groq", Model: "model-b", Weight: 50},
+			},
+		}},
+	})
+	require.NoError(t, err)
+	router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, sel, nil)
+	r := gin.New()
+	r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
+
+	variants := make(map[string]bool)
+	for session := range 20 {
+		var first string
+		for i := range 3 {
+			req := chatRequest(t, "default-chat", false)
+			req.Header.Set("X-Session-ID", fmt.Sprintf("session-%d", session))
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusOK, rec.Code)
+			variant := rec.Header().Get("X-Selected-Provider") + "/" + rec.Header().Get("X-Selected-Model")
+			if i == 0 {
+				first = variant
+				variants[variant] = true
+			}
+			assert.Equal(t, first, variant, "session %d switched variants", session)
+		}
+	}
+	assert.Len(t, variants, 2)
+}
+
+// A model backing off is not called again: the gateway answers 429 itself
+// with the remaining wait.
+func TestChatCompletions_BackoffRejectsWithoutCallingProvider(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	log, cfg := routingTestSetup(t)
+	cfg.ProviderBackoffMax = 10 * time.Second
+
+	mockClient := providersmocks.NewMockClient(ctrl)
+	prov := providersmocks.NewMockIProvider(ctrl)
+	reg := providersmocks.NewMockProviderRegistry(ctrl)
+	prov.EXPECT().StreamChatCompletions(gomock.Any(), gomock.Any()).Return(nil, &core.HTTPError{
+		StatusCode: 529,
+		Message:    `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+		Header:     http.Header{"Retry-After": {"120"}},
+	}).Times(1)
+	reg.EXPECT().BuildProvider(constants.AnthropicID, mockClient).Return(prov, nil).Times(1)
+
+	router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil)
+	r := gin.New()
+	r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, chatRequest(t, "anthropic/claude-sonnet-4-5", true))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "120", rec.Header().Get("Retry-After"), "the provider's header reaches the client as is")
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, chatRequest(t, "anthropic/claude-sonnet-4-5", false))
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "10", rec.Header().Get("Retry-After"), "the wait is capped by PROVIDER_BACKOFF_MAX")
+	var resp api.ErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "IG-4004", resp.Code)
+}
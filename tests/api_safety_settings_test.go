@@ -0,0 +1,194 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	api "github.com/inference-gateway/inference-gateway/api"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+	providersmocks "github.com/inference-gateway/inference-gateway/tests/mocks/providers"
+)
+
+func TestChatCompletions_SafetySettings(t *testing.T) {
+	tests := []struct {
+		name           string
+		model          string
+		provider       types.Provider
+		safetySettings string
+		moderation     string
+		expectedCode   int
+		expectedError  string
+		verify         func(t *testing.T, req types.CreateChatCompletionRequest)
+	}{
+		{
+			name:           "Anthropic receives safety instructions",
+			model:          "anthropic/claude-sonnet-4-5",
+			provider:       constants.AnthropicID,
+			safetySettings: `{"level":"medium","categories":{"hate":"high"}}`,
+			expectedCode:   http.StatusOK,
+			verify: func(t *testing.T, req types.CreateChatCompletionRequest) {
+				assert.Nil(t, req.SafetySettings)
+				require.Len(t, req.Messages, 2)
+				assert.Equal(t, types.System, req.Messages[0].Role)
+				assert.Contains(t, req.Messages[0].TextContent(), "hateful content targeting protected groups: refuse anything that could plausibly be harmful")
+				assert.Contains(t, req.Messages[0].TextContent(), "sexually explicit content: refuse requests that are likely to be harmful")
+			},
+		},
+		{
+			name:           "Google keeps settings for native forwarding",
+			model:          "google/gemini-2.5-flash",
+			provider:       constants.GoogleID,
+			safetySettings: `{"level":"low"}`,
+			expectedCode:   http.StatusOK,
+			verify: func(t *testing.T, req types.CreateChatCompletionRequest) {
+				require.NotNil(t, req.SafetySettings)
+				assert.Len(t, req.Messages, 1)
+			},
+		},
+		{
+			name:           "OpenAI passes moderation",
+			model:          "openai/gpt-4o",
+			provider:       constants.OpenaiID,
+			safetySettings: `{"level":"medium"}`,
+			moderation:     `{"results":[{"category_scores":{"hate":0.3,"violence":0.1}}]}`,
+			expectedCode:   http.StatusOK,
+			verify: func(t *testing.T, req types.CreateChatCompletionRequest) {
+				assert.Nil(t, req.SafetySettings)
+				assert.Len(t, req.Messages, 1)
+			},
+		},
+		{
+			name:           "OpenAI blocked by moderation",
+			model:          "openai/gpt-4o",
+			provider:       constants.OpenaiID,
+			safetySettings: `{"level":"medium","categories":{"hate":"high","sexual":"off"}}`,
+			moderation:     `{"results":[{"category_scores":{"hate":0.3,"sexual":0.9,"violence/graphic":0.6}}]}`,
+			expectedCode:   http.StatusBadRequest,
+			expectedError:  "Request blocked by safety settings: dangerous, hate",
+		},
+		{
+			name:           "Unknown category",
+			model:          "anthropic/claude-sonnet-4-5",
+			provider:       constants.AnthropicID,
+			safetySettings: `{"categories":{"spam":"high"}}`,
+			expectedCode:   http.StatusBadRequest,
+			expectedError:  `Invalid safety_settings: unknown safety category "spam"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			log, cfg := routingTestSetup(t)
+			cfg.SafetyModerationModel = "omni-moderation-latest"
+
+			mockClient := providersmocks.NewMockClient(ctrl)
+			reg := providersmocks.NewMockProviderRegistry(ctrl)
+			provider := providersmocks.NewMockIProvider(ctrl)
+			reg.EXPECT().BuildProvider(tt.provider, mockClient).Return(provider, nil)
+
+			if tt.moderation != "" {
+				mockClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					assert.Equal(t, "/proxy/openai/moderations", req.URL.Path)
+					var body map[string]any
+					require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+					assert.Equal(t, "omni-moderation-latest", body["model"])
+					assert.Equal(t, []any{"hello"}, body["input"])
+					return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(tt.moderation))}, nil
+				})
+			}
+			if tt.verify != nil {
+				provider.EXPECT().ChatCompletions(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ any, req types.CreateChatCompletionRequest) (types.CreateChatCompletionResponse, error) {
+						tt.verify(t, req)
+						return types.CreateChatCompletionResponse{}, nil
+					})
+			}
+
+			router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil)
+			r := gin.New()
+			r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
+
+			body := `{"model":"` + tt.model + `","messages":[{"role":"user","content":"hello"}],"safety_settings":` + tt.safetySettings + `}`
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body)))
+			require.Equal(t, tt.expectedCode, w.Code, w.Body.String())
+
+			if tt.expectedError != "" {
+				var errResp api.ErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.expectedError, errResp.Error)
+			}
+		})
+	}
+}
+
+func TestProviderChatCompletionsSafetySettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider types.Provider
+		expected any
+	}{
+		{
+			name:     "Google receives native safety settings",
+			provider: constants.GoogleID,
+			expected: map[string]any{"google": map[string]any{"safety_settings": []any{
+				map[string]any{"category": "HARM_CATEGORY_HARASSMENT", "threshold": "BLOCK_MEDIUM_AND_ABOVE"},
+				map[string]any{"category": "HARM_CATEGORY_HATE_SPEECH", "threshold": "BLOCK_MEDIUM_AND_ABOVE"},
+				map[string]any{"category": "HARM_CATEGORY_SEXUALLY_EXPLICIT", "threshold": "BLOCK_MEDIUM_AND_ABOVE"},
+				map[string]any{"category": "HARM_CATEGORY_DANGEROUS_CONTENT", "threshold": "BLOCK_NONE"},
+			}}},
+		},
+		{
+			name:     "Other providers never receive the normalized field",
+			provider: constants.GroqID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockClient := providersmocks.NewMockClient(ctrl)
+
+			var forwarded map[string]any
+			mockClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+				require.NoError(t, json.NewDecoder(req.Body).Decode(&forwarded))
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"choices":[]}`))}, nil
+			})
+
+			providerRegistry := registry.NewProviderRegistry(map[types.Provider]*registry.ProviderConfig{
+				tt.provider: {ID: tt.provider, Name: string(tt.provider), URL: "http://upstream", Token: "test-token", AuthType: constants.AuthTypeBearer},
+			}, logger.NewNoopLogger())
+			provider, err := providerRegistry.BuildProvider(tt.provider, mockClient)
+			require.NoError(t, err)
+
+			_, err = provider.ChatCompletions(context.Background(), types.CreateChatCompletionRequest{
+				Model:    "model",
+				Messages: []types.Message{types.NewTextMessage(t, types.User, "hello")},
+				SafetySettings: &types.SafetySettings{
+					Level:      ptr(types.SafetyMedium),
+					Categories: &map[string]types.SafetyLevel{"dangerous": types.SafetyOff},
+				},
+			})
+			require.NoError(t, err)
+
+			assert.NotContains(t, forwarded, "safety_settings")
+			assert.Equal(t, tt.expected, forwarded["extra_body"])
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	loadtest "github.com/inference-gateway/inference-gateway/internal/loadtest"
+)
+
+// benchmarkStreaming streams b.N chat completions of the given number of
+// tokens through the in-process gateway, concurrency at a time, and reports
+// on top of ns/op and allocs/op the tokens per second and the median and
+// p99 time to first token, so the cost of a change to the streaming path
+// shows in `task benchmark` output.
+func benchmarkStreaming(b *testing.B, tokens, concurrency int) {
+	b.Helper()
+
+	upstream := httptest.NewServer(&loadtest.MockProvider{Tokens: tokens})
+	defer upstream.Close()
+	gateway, err := loadtest.NewGateway(upstream.URL)
+	if err != nil {
+		b.Fatal(err)
+	}
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	result, err := loadtest.Run(context.Background(), loadtest.Options{
+		URL:         server.URL,
+		Model:       "openai/" + loadtest.MockModel,
+		Concurrency: concurrency,
+		Requests:    b.N,
+	})
+	b.StopTimer()
+	if err != nil {
+		b.Fatal(err)
+	}
+	if result.Errors > 0 {
+		b.Fatalf("%d of %d streams failed: %s", result.Errors, b.N, result.FirstError)
+	}
+	if want := int64(b.N * tokens); result.Tokens != want {
+		b.Fatalf("expected %d tokens, got %d", want, result.Tokens)
+	}
+	b.ReportMetric(result.TokensPerSecond, "tokens/s")
+	b.ReportMetric(float64(result.TTFT.P50.Microseconds()), "ttft_p50_us")
+	b.ReportMetric(float64(result.TTFT.P99.Microseconds()), "ttft_p99_us")
+}
+
+// BenchmarkChatCompletionsStream measures one stream at a time, where the
+// time to first token is the gateway's own latency
+func BenchmarkChatCompletionsStream(b *testing.B) {
+	for _, tokens := range []int{16, 256, 2048} {
+		b.Run(fmt.Sprintf("tokens=%d", tokens), func(b *testing.B) {
+			benchmarkStreaming(b, tokens, 1)
+		})
+	}
+}
+
+// BenchmarkChatCompletionsStreamConcurrent measures 32 concurrent streams,
+// where contention in the streaming path lowers the throughput
+func BenchmarkChatCompletionsStreamConcurrent(b *testing.B) {
+	benchmarkStreaming(b, 256, 32)
+}
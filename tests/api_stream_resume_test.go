@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	api "github.com/inference-gateway/inference-gateway/api"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+	providersmocks "github.com/inference-gateway/inference-gateway/tests/mocks/providers"
+)
+
+// readEvent reads one SSE event, returning its id and data lines
+func readEvent(t *testing.T, r *bufio.Reader) (string, string) {
+	t.Helper()
+	var id, data string
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "":
+			return id, data
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestChatCompletionsHandler_StreamResume(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProvider := providersmocks.NewMockIProvider(ctrl)
+	mockClient := providersmocks.NewMockClient(ctrl)
+	mockRegistry := providersmocks.NewMockProviderRegistry(ctrl)
+
+	upstream := make(chan []byte)
+	var streamCtx context.Context
+	mockProvider.EXPECT().StreamChatCompletions(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ types.CreateChatCompletionRequest) (<-chan []byte, error) {
+			streamCtx = ctx
+			return upstream, nil
+		}).Times(1)
+	mockRegistry.EXPECT().BuildProvider(constants.CohereID, mockClient).Return(mockProvider, nil)
+
+	log, err := logger.NewLogger("test")
+	require.NoError(t, err)
+	cfg := config.Config{
+		StreamResumeEnable:     true,
+		StreamResumeBufferSize: 16,
+		StreamResumeTtl:        time.Minute,
+		Server:                 &config.ServerConfig{ReadTimeout: 5 * time.Second, WriteTimeout: 5 * time.Second},
+		Providers: map[types.Provider]*registry.ProviderConfig{
+			constants.CohereID: {ID: constants.CohereID, Name: constants.CohereDisplayName, URL: "http://localhost:8080"},
+		},
+	}
+	router := api.NewRouter(cfg, log, mockRegistry, mockClient, nil, nil, nil, nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	body, err := json.Marshal(types.CreateChatCompletionRequest{
+		Model:    "cohere/command-r",
+		Stream:   new(true),
+		Messages: []types.Message{types.NewTextMessage(t, types.User, "Hello, world!")},
+	})
+	require.NoError(t, err)
+	send := func(lastEventID, auth string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/v1/chat/completions", strings.NewReader(string(body)))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", auth)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	// The first connection drops after the first event
+	resp := send("", "Bearer alice")
+	upstream <- []byte("data: {\"n\":1}\n")
+	upstream <- []byte("\n")
+	firstID, data := readEvent(t, bufio.NewReader(resp.Body))
+	assert.Equal(t, `{"n":1}`, data)
+	assert.True(t, strings.HasSuffix(firstID, ":1"), firstID)
+	resp.Body.Close()
+
+	// The generation goes on without the client
+	upstream <- []byte("data: {\"n\":2}\n\n")
+	upstream <- []byte("data: [DONE]\n\n")
+	close(upstream)
+	require.NoError(t, streamCtx.Err())
+
+	t.Run("another caller cannot resume the stream", func(t *testing.T) {
+		resp := send(firstID, "Bearer mallory")
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("reconnecting resumes after the last event", func(t *testing.T) {
+		resp := send(firstID, "Bearer alice")
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		reader := bufio.NewReader(resp.Body)
+
+		id, data := readEvent(t, reader)
+		assert.Equal(t, strings.TrimSuffix(firstID, "1")+"2", id)
+		assert.Equal(t, `{"n":2}`, data)
+		_, data = readEvent(t, reader)
+		assert.Equal(t, "[DONE]", data)
+		rest, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Empty(t, rest)
+	})
+
+	t.Run("unknown streams are not found", func(t *testing.T) {
+		resp := send("sse_unknown:3", "Bearer alice")
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.True(t, strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json"))
+	})
+}
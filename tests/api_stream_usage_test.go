@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	api "github.com/inference-gateway/inference-gateway/api"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+	providersmocks "github.com/inference-gateway/inference-gateway/tests/mocks/providers"
+)
+
+func TestChatCompletionsHandler_StreamUsage(t *testing.T) {
+	const (
+		contentChunk   = `data: {"id":"chatcmpl-1","object":"chat.completion.chunk","created":1700000000,"model":"command-r","choices":[{"index":0,"delta":{"role":"assistant","content":"Hello there"},"finish_reason":null}]}` + "\n\n"
+		reasoningChunk = `data: {"id":"chatcmpl-1","object":"chat.completion.chunk","created":1700000000,"model":"command-r","choices":[{"index":0,"delta":{"role":"assistant","content":"","reasoning_content":"Thinking hard"},"finish_reason":null}]}` + "\n\n"
+		usageChunk     = `data: {"id":"chatcmpl-1","object":"chat.completion.chunk","created":1700000000,"model":"command-r","choices":[],"usage":{"prompt_tokens":9,"completion_tokens":2,"total_tokens":11}}` + "\n\n"
+		done           = "data: [DONE]\n\n"
+	)
+
+	tests := []struct {
+		name          string
+		includeUsage  bool
+		upstream      []string
+		expectedUsage *types.CompletionUsage
+		expectedLines int
+	}{
+		{
+			name:         "usage is counted locally when the provider sends none",
+			includeUsage: true,
+			upstream:     []string{contentChunk, done},
+			// 3 reply tokens, 3 message tokens, role "user" and "Hello, world!"
+			// at 4 characters per token; "Hello there" for the completion
+			expectedUsage: &types.CompletionUsage{PromptTokens: 3 + 3 + 1 + 4, CompletionTokens: 3, TotalTokens: 14},
+			expectedLines: 3,
+		},
+		{
+			name:         "reasoning tokens are counted locally",
+			includeUsage: true,
+			upstream:     []string{reasoningChunk, contentChunk, done},
+			expectedUsage: func() *types.CompletionUsage {
+				usage := &types.CompletionUsage{PromptTokens: 11, CompletionTokens: 6, TotalTokens: 17}
+				_ = json.Unmarshal([]byte(`{"reasoning_tokens":4}`), &usage.CompletionTokensDetails)
+				return usage
+			}(),
+			expectedLines: 4,
+		},
+		{
+			name:          "usage reported by the provider is kept",
+			includeUsage:  true,
+			upstream:      []string{contentChunk, usageChunk, done},
+			expectedUsage: &types.CompletionUsage{PromptTokens: 9, CompletionTokens: 2, TotalTokens: 11},
+			expectedLines: 3,
+		},
+		{
+			name:          "streams ending without [DONE] still get usage",
+			includeUsage:  true,
+			upstream:      []string{contentChunk},
+			expectedUsage: &types.CompletionUsage{PromptTokens: 11, CompletionTokens: 3, TotalTokens: 14},
+			expectedLines: 2,
+		},
+		{
+			name:          "no usage is added when the client did not ask for it",
+			upstream:      []string{contentChunk, done},
+			expectedLines: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockProvider := providersmocks.NewMockIProvider(ctrl)
+			mockClient := providersmocks.NewMockClient(ctrl)
+			mockRegistry := providersmocks.NewMockProviderRegistry(ctrl)
+
+			stream := make(chan []byte, len(tt.upstream))
+			for _, line := range tt.upstream {
+				stream <- []byte(line)
+			}
+			close(stream)
+			mockProvider.EXPECT().StreamChatCompletions(gomock.Any(), gomock.Any()).Return(stream, nil)
+			mockRegistry.EXPECT().BuildProvider(constants.CohereID, mockClient).Return(mockProvider, nil)
+
+			log, err := logger.NewLogger("test")
+			require.NoError(t, err)
+			cfg := config.Config{
+				Server: &config.ServerConfig{ReadTimeout: 5 * time.Second, WriteTimeout: 5 * time.Second},
+				Providers: map[types.Provider]*registry.ProviderConfig{
+					constants.CohereID: {ID: constants.CohereID, Name: constants.CohereDisplayName, URL: "http://localhost:8080"},
+				},
+			}
+			router := api.NewRouter(cfg, log, mockRegistry, mockClient, nil, nil, nil, nil)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
+
+			requestBody := types.CreateChatCompletionRequest{
+				Model:    "cohere/command-r",
+				Stream:   new(true),
+				Messages: []types.Message{types.NewTextMessage(t, types.User, "Hello, world!")},
+			}
+			if tt.includeUsage {
+				requestBody.StreamOptions = &types.ChatCompletionStreamOptions{IncludeUsage: true}
+			}
+			body, err := json.Marshal(requestBody)
+			require.NoError(t, err)
+
+			// gin streams need a CloseNotifier, which httptest.ResponseRecorder is not
+			server := httptest.NewServer(r)
+			defer server.Close()
+			resp, err := http.Post(server.URL+"/v1/chat/completions", "application/json", strings.NewReader(string(body)))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			received, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			lines := strings.Split(strings.TrimSpace(string(received)), "\n\n")
+			require.Len(t, lines, tt.expectedLines)
+
+			var usage *types.CompletionUsage
+			for _, line := range lines {
+				var chunk types.CreateChatCompletionStreamResponse
+				if json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &chunk) == nil && chunk.Usage != nil {
+					require.Nil(t, usage, "usage is reported once")
+					usage = chunk.Usage
+					assert.Equal(t, "chatcmpl-1", chunk.ID)
+					assert.Empty(t, chunk.Choices)
+				}
+			}
+			assert.Equal(t, tt.expectedUsage, usage)
+			if len(tt.upstream) > 1 {
+				assert.Equal(t, "data: [DONE]", lines[len(lines)-1], "usage comes before [DONE]")
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	api "github.com/inference-gateway/inference-gateway/api"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+	providersmocks "github.com/inference-gateway/inference-gateway/tests/mocks/providers"
+)
+
+const structuredOutputBody = `{"model":"%s","messages":[{"role":"user","content":"extract"}],
+	"response_format":{"type":"json_schema","json_schema":{"name":"person","schema":{
+	"type":"object","required":["name","age"],"additionalProperties":false,
+	"properties":{"name":{"type":"string"},"age":{"type":"integer","minimum":0}}}}}}`
+
+func structuredOutputResponse(t *testing.T, content string) types.CreateChatCompletionResponse {
+	return types.CreateChatCompletionResponse{
+		Choices: []types.ChatCompletionChoice{{Message: types.NewTextMessage(t, types.Assistant, content), FinishReason: types.Stop}},
+		Usage:   &types.CompletionUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+	}
+}
+
+func TestChatCompletions_StructuredOutput(t *testing.T) {
+	tests := []struct {
+		name          string
+		model         string
+		provider      types.Provider
+		responses     []string
+		expectedCode  int
+		expectedJSON  string
+		expectedUsage int64
+	}{
+		{
+			name:          "Native provider passes response_format through",
+			model:         "openai/gpt-4o",
+			provider:      constants.OpenaiID,
+			responses:     []string{`{"name":"Ada","age":36}`},
+			expectedCode:  http.StatusOK,
+			expectedJSON:  `{"name":"Ada","age":36}`,
+			expectedUsage: 15,
+		},
+		{
+			name:          "Emulated provider repairs fenced JSON",
+			model:         "anthropic/claude-sonnet-4-5",
+			provider:      constants.AnthropicID,
+			responses:     []string{"Sure:\n```json\n{\"name\": \"Ada\", \"age\": 36,}\n```"},
+			expectedCode:  http.StatusOK,
+			expectedJSON:  `{"name":"Ada","age":36}`,
+			expectedUsage: 15,
+		},
+		{
+			name:          "Emulated provider retries invalid JSON",
+			model:         "anthropic/claude-sonnet-4-5",
+			provider:      constants.AnthropicID,
+			responses:     []string{`{"name":"Ada"}`, `{"name":"Ada","age":36}`},
+			expectedCode:  http.StatusOK,
+			expectedJSON:  `{"name":"Ada","age":36}`,
+			expectedUsage: 30,
+		},
+		{
+			name:         "Emulated provider fails after retries",
+			model:        "anthropic/claude-sonnet-4-5",
+			provider:     constants.AnthropicID,
+			responses:    []string{`not json`, `{"name":"Ada","age":-1}`},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			log, cfg := routingTestSetup(t)
+			cfg.StructuredOutputEmulatedProviders = "anthropic"
+			cfg.StructuredOutputMaxRetries = 1
+
+			mockClient := providersmocks.NewMockClient(ctrl)
+			reg := providersmocks.NewMockProviderRegistry(ctrl)
+			provider := providersmocks.NewMockIProvider(ctrl)
+			reg.EXPECT().BuildProvider(tt.provider, mockClient).Return(provider, nil)
+
+			calls := 0
+			provider.EXPECT().ChatCompletions(gomock.Any(), gomock.Any()).Times(len(tt.responses)).DoAndReturn(
+				func(_ any, req types.CreateChatCompletionRequest) (types.CreateChatCompletionResponse, error) {
+					if tt.provider == constants.AnthropicID {
+						assert.Nil(t, req.ResponseFormat, "emulated providers must not receive response_format")
+						assert.Equal(t, types.System, req.Messages[0].Role)
+						assert.Contains(t, req.Messages[0].TextContent(), `"required":["name","age"]`)
+						assert.Len(t, req.Messages, 2+2*calls)
+					} else {
+						assert.NotNil(t, req.ResponseFormat)
+						assert.Len(t, req.Messages, 1)
+					}
+					resp := structuredOutputResponse(t, tt.responses[calls])
+					calls++
+					return resp, nil
+				})
+
+			router := api.NewRouter(cfg, log, reg, mockClient, nil, nil, nil, nil)
+			r := gin.New()
+			r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
+
+			body := strings.Replace(structuredOutputBody, "%s", tt.model, 1)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body)))
+			require.Equal(t, tt.expectedCode, w.Code, w.Body.String())
+
+			if tt.expectedCode != http.StatusOK {
+				var errResp api.StructuredOutputError
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errResp))
+				assert.Equal(t, 2, errResp.Attempts)
+				assert.Contains(t, errResp.Details, "minimum")
+				return
+			}
+
+			var resp types.CreateChatCompletionResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.Len(t, resp.Choices, 1)
+			assert.JSONEq(t, tt.expectedJSON, resp.Choices[0].Message.TextContent())
+			require.NotNil(t, resp.Usage)
+			assert.Equal(t, tt.expectedUsage, resp.Usage.TotalTokens)
+		})
+	}
+}
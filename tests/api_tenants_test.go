@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	api "github.com/inference-gateway/inference-gateway/api"
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func TestTenantsThroughProxy(t *testing.T) {
+	groqServer := newOpenAICompatibleServer(t, "groq", "llama-3.3-70b-versatile", "Authorization", "Bearer gsk-acme")
+
+	groq := *registry.Registry[constants.GroqID]
+	groq.URL = groqServer.URL + "/v1"
+	groq.Token = "gsk-gateway"
+	log, err := logger.NewLogger("test")
+	require.NoError(t, err)
+	base := registry.NewReloadableRegistry(map[types.Provider]*registry.ProviderConfig{constants.GroqID: &groq}, log)
+	store, err := tenants.NewStore(&tenants.Config{Tenants: map[string]tenants.Tenant{
+		"acme":   {RequestsPerMinute: 1, Providers: map[types.Provider]tenants.ProviderOverride{constants.GroqID: {APIKey: "gsk-acme"}}},
+		"globex": {},
+	}}, base, log)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	gateway := httptest.NewServer(r)
+	defer gateway.Close()
+	gatewayURL, err := url.Parse(gateway.URL)
+	require.NoError(t, err)
+
+	cfg := config.Config{TenantHeader: "X-Tenant-ID", Auth: &config.AuthConfig{}, Server: &config.ServerConfig{ReadTimeout: 5 * time.Second}}
+	resolver, err := middlewares.NewTenantResolverMiddleware(log, cfg, store)
+	require.NoError(t, err)
+	r.Use(resolver.Middleware())
+	router := api.NewRouter(cfg, log, store, &selfProxyClient{gateway: gatewayURL}, nil, nil, nil, nil)
+	r.Any("/proxy/:provider/*path", router.ProxyHandler)
+	r.POST("/v1/chat/completions", router.ChatCompletionsHandler)
+
+	post := func(tenant string) (int, string) {
+		req, err := http.NewRequest(http.MethodPost, gateway.URL+"/v1/chat/completions", strings.NewReader(`{"model":"groq/llama-3.3-70b-versatile","messages":[{"role":"user","content":"hi"}]}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if tenant != "" {
+			req.Header.Set("X-Tenant-ID", tenant)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	status, body := post("acme")
+	require.Equal(t, http.StatusOK, status, "the tenant's API key is used and its one request a minute is counted once: %s", body)
+	assert.Contains(t, body, "served by groq")
+
+	status, _ = post("globex")
+	assert.Equal(t, http.StatusUnauthorized, status, "tenants without an override use the gateway's API key")
+
+	status, _ = post("")
+	assert.Equal(t, http.StatusForbidden, status, "requests naming no tenant are rejected")
+}
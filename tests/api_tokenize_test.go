@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	api "github.com/inference-gateway/inference-gateway/api"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+func TestTokenizeHandler(t *testing.T) {
+	// A rank file holding every byte plus the merges of "hi"
+	dir := t.TempDir()
+	var ranks strings.Builder
+	for i := range 256 {
+		fmt.Fprintf(&ranks, "%s %d\n", base64.StdEncoding.EncodeToString([]byte{byte(i)}), i)
+	}
+	fmt.Fprintf(&ranks, "%s 256\n", base64.StdEncoding.EncodeToString([]byte("hi")))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "o200k_base.tiktoken"), []byte(ranks.String()), 0o600))
+
+	log, err := logger.NewLogger("test")
+	require.NoError(t, err)
+	cfg := config.Config{
+		Server:                &config.ServerConfig{ReadTimeout: 5 * time.Second},
+		TokenizerEncodingsDir: dir,
+	}
+	router := api.NewRouter(cfg, log, nil, nil, nil, nil, nil, nil)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/v1/tokenize", router.TokenizeHandler)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expected       api.TokenizeResponse
+	}{
+		{
+			name:           "input with token ids",
+			body:           `{"model":"openai/gpt-4o","input":"hi!","return_token_ids":true}`,
+			expectedStatus: http.StatusOK,
+			expected:       api.TokenizeResponse{Object: "tokenize", Model: "openai/gpt-4o", Tokenizer: "o200k_base", Count: 2, TokenIDs: []int{256, '!'}},
+		},
+		{
+			name:           "input estimated for models without a tokenizer",
+			body:           `{"model":"anthropic/claude-sonnet-4","input":"hello world"}`,
+			expectedStatus: http.StatusOK,
+			expected:       api.TokenizeResponse{Object: "tokenize", Model: "anthropic/claude-sonnet-4", Tokenizer: "heuristic", Estimated: true, Count: 3},
+		},
+		{
+			name:           "messages include the chat framing",
+			body:           `{"model":"anthropic/claude-sonnet-4","messages":[{"role":"user","content":"hello world"}]}`,
+			expectedStatus: http.StatusOK,
+			expected:       api.TokenizeResponse{Object: "tokenize", Model: "anthropic/claude-sonnet-4", Tokenizer: "heuristic", Estimated: true, Count: 3 + 3 + 1 + 3},
+		},
+		{
+			name:           "token ids need an exact tokenizer",
+			body:           `{"model":"anthropic/claude-sonnet-4","input":"hello","return_token_ids":true}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "input and messages are exclusive",
+			body:           `{"model":"openai/gpt-4o","input":"hi","messages":[{"role":"user","content":"hi"}]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "model is required",
+			body:           `{"input":"hi"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/v1/tokenize", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var resp api.TokenizeResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.expected, resp)
+		})
+	}
+}
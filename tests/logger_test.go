@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -104,3 +105,20 @@ func TestLoggerMethods(t *testing.T) {
 		})
 	}
 }
+
+func TestLoggerWith(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLogger := mocks.NewMockLogger(ctrl)
+	mockLogger.EXPECT().Info("test info", "request_id", "req_1", "key1", "value1")
+	mockLogger.EXPECT().Error("test error", gomock.Any(), "request_id", "req_1")
+
+	scoped := logger.With(mockLogger, "request_id", "req_1")
+	scoped.Info("test info", "key1", "value1")
+	scoped.Error("test error", errors.New("test error"))
+
+	ctx := logger.WithContext(context.Background(), scoped)
+	assert.Equal(t, scoped, logger.FromContext(ctx, mockLogger))
+	assert.Equal(t, logger.Logger(mockLogger), logger.FromContext(context.Background(), mockLogger), "outside a request the fallback is used")
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -56,7 +57,7 @@ func TestNewAgent(t *testing.T) {
 			mockLogger := mocks.NewMockLogger(ctrl)
 			mockMCPClient := mcpmocks.NewMockMCPClientInterface(ctrl)
 
-			agentInstance := mcp.NewAgent(mockLogger, mockMCPClient)
+			agentInstance := mcp.NewAgent(mockLogger, mockMCPClient, config.Config{})
 
 			if tt.expectAgent {
 				assert.NotNil(t, agentInstance)
@@ -101,6 +102,8 @@ func TestAgent_Run(t *testing.T) {
 		response       *types.CreateChatCompletionResponse
 		expectError    bool
 		expectedResult string
+		// expectedFinishReason is checked when set
+		expectedFinishReason types.FinishReason
 	}{
 		{
 			name: "no tool calls",
@@ -201,7 +204,7 @@ func TestAgent_Run(t *testing.T) {
 				mockLogger.EXPECT().Debug("agent loop iteration", "iteration", gomock.Any(), "tool_calls", 1).Times(10)
 				mockLogger.EXPECT().Debug("executing tool calls", "count", 1).Times(10)
 				mockLogger.EXPECT().Info("executing tool call", "tool_call", gomock.Any()).Times(10)
-				mockLogger.EXPECT().Warn("agent loop reached maximum iterations", gomock.Any()).Times(1)
+				mockLogger.EXPECT().Warn("agent loop budget exceeded", "limit", mcp.BudgetIterations, "iterations", 10, "tool_calls", 10).Times(1)
 				mockLogger.EXPECT().Debug("agent loop completed", "iterations", 10, "final_choices", 1).Times(1)
 
 				mockMCPClient.EXPECT().GetServerForTool(gomock.Any()).Return("http://test-server:8080/mcp", nil).Times(10)
@@ -240,8 +243,60 @@ func TestAgent_Run(t *testing.T) {
 					},
 				},
 			},
-			expectError:    false,
-			expectedResult: "More tool calls needed",
+			expectError:          false,
+			expectedResult:       "More tool calls needed",
+			expectedFinishReason: types.BudgetExceeded,
+		},
+		{
+			name: "tool call budget of the request",
+			setupMocks: func(mockLogger *mocks.MockLogger, mockMCPClient *mcpmocks.MockMCPClientInterface, mockProvider *providersmocks.MockIProvider) {
+				mockProvider.EXPECT().GetName().Return("test-provider").Times(1)
+				mockLogger.EXPECT().Debug("provider set for agent", "provider", "test-provider").Times(1)
+				mockLogger.EXPECT().Debug("model set for agent", "model", "test-model").Times(1)
+				mockLogger.EXPECT().Debug("agent loop iteration", "iteration", 1, "tool_calls", 1).Times(1)
+				mockLogger.EXPECT().Debug("executing tool calls", "count", 1).Times(1)
+				mockLogger.EXPECT().Info("executing tool call", "tool_call", gomock.Any()).Times(1)
+				mockLogger.EXPECT().Warn("agent loop budget exceeded", "limit", mcp.BudgetToolCalls, "iterations", 1, "tool_calls", 1).Times(1)
+				mockLogger.EXPECT().Debug("agent loop completed", "iterations", 1, "final_choices", 1).Times(1)
+
+				mockMCPClient.EXPECT().GetServerForTool(gomock.Any()).Return("http://test-server:8080/mcp", nil).Times(1)
+				mockMCPClient.EXPECT().ExecuteTool(gomock.Any(), gomock.Any(), gomock.Any()).Return(&mcp.CallToolResult{
+					Content: []mcp.ContentBlock{
+						mcp.TextContent{Type: "text", Text: "Tool result"},
+					},
+				}, nil).Times(1)
+
+				mockProvider.EXPECT().ChatCompletions(gomock.Any(), gomock.Any()).Return(types.CreateChatCompletionResponse{
+					ID:    "test-id",
+					Model: "test-model",
+					Choices: []types.ChatCompletionChoice{
+						{
+							Message:      assistantMoreToolCalls,
+							FinishReason: types.ToolCalls,
+						},
+					},
+				}, nil).Times(1)
+			},
+			request: &types.CreateChatCompletionRequest{
+				Model: "test-model",
+				Messages: []types.Message{
+					userUseTestTool,
+				},
+				AgentBudget: &types.AgentBudget{MaxToolCalls: ptr(1)},
+			},
+			response: &types.CreateChatCompletionResponse{
+				ID:    "test-id",
+				Model: "test-model",
+				Choices: []types.ChatCompletionChoice{
+					{
+						Message:      assistantToolResponse,
+						FinishReason: types.ToolCalls,
+					},
+				},
+			},
+			expectError:          false,
+			expectedResult:       "More tool calls needed",
+			expectedFinishReason: types.BudgetExceeded,
 		},
 	}
 
@@ -256,7 +311,7 @@ func TestAgent_Run(t *testing.T) {
 
 			tt.setupMocks(mockLogger, mockMCPClient, mockProvider)
 
-			agentInstance := mcp.NewAgent(mockLogger, mockMCPClient)
+			agentInstance := mcp.NewAgent(mockLogger, mockMCPClient, config.Config{})
 			agentInstance.SetProvider(mockProvider)
 			agentInstance.SetModel(&tt.request.Model)
 
@@ -270,6 +325,9 @@ func TestAgent_Run(t *testing.T) {
 					content, _ := tt.response.Choices[0].Message.Content.AsMessageContent0()
 					assert.Equal(t, tt.expectedResult, content)
 				}
+				if tt.expectedFinishReason != "" {
+					assert.Equal(t, tt.expectedFinishReason, tt.response.Choices[0].FinishReason)
+				}
 			}
 		})
 	}
@@ -486,7 +544,7 @@ func TestAgent_ExecuteTools(t *testing.T) {
 
 			tt.setupMocks(mockLogger, mockMCPClient, mockProvider)
 
-			agentInstance := mcp.NewAgent(mockLogger, mockMCPClient)
+			agentInstance := mcp.NewAgent(mockLogger, mockMCPClient, config.Config{})
 
 			results, err := agentInstance.ExecuteTools(context.Background(), tt.toolCalls)
 
@@ -513,6 +571,69 @@ func TestAgent_ExecuteTools(t *testing.T) {
 	}
 }
 
+func TestAgent_ExecuteToolsConcurrently(t *testing.T) {
+	const toolCount = 4
+	tests := []struct {
+		name         string
+		concurrency  int
+		expectedPeak int32
+	}{
+		{name: "sequential", concurrency: 1, expectedPeak: 1},
+		{name: "bounded", concurrency: 2, expectedPeak: 2},
+		{name: "all at once", concurrency: 8, expectedPeak: toolCount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockMCPClient := mcpmocks.NewMockMCPClientInterface(ctrl)
+			mockMCPClient.EXPECT().GetServerForTool(gomock.Any()).Return("http://test-server:8080/mcp", nil).Times(toolCount)
+
+			var running, peak atomic.Int32
+			mockMCPClient.EXPECT().ExecuteTool(gomock.Any(), gomock.Any(), "http://test-server:8080/mcp").DoAndReturn(
+				func(_ context.Context, req mcp.Request, _ string) (*mcp.CallToolResult, error) {
+					current := running.Add(1)
+					defer running.Add(-1)
+					for {
+						observed := peak.Load()
+						if current <= observed || peak.CompareAndSwap(observed, current) {
+							break
+						}
+					}
+					// Later calls finish first so out-of-order completion is exercised
+					var n int
+					_, _ = fmt.Sscanf(req.Params["name"].(string), "tool_%d", &n)
+					time.Sleep(time.Duration(toolCount-n) * 10 * time.Millisecond)
+					return &mcp.CallToolResult{Content: []mcp.ContentBlock{mcp.TextContent{Type: "text", Text: req.Params["name"].(string)}}}, nil
+				}).Times(toolCount)
+
+			toolCalls := make([]types.ChatCompletionMessageToolCall, toolCount)
+			for i := range toolCalls {
+				toolCalls[i] = types.ChatCompletionMessageToolCall{
+					ID:       fmt.Sprintf("call_%d", i),
+					Type:     types.Function,
+					Function: types.ChatCompletionMessageToolCallFunction{Name: fmt.Sprintf("mcp_tool_%d", i), Arguments: `{}`},
+				}
+			}
+
+			cfg := config.Config{MCP: &config.MCPConfig{ToolConcurrency: tt.concurrency}}
+			agentInstance := mcp.NewAgent(logger.NewNoopLogger(), mockMCPClient, cfg)
+			results, err := agentInstance.ExecuteTools(context.Background(), toolCalls)
+			require.NoError(t, err)
+			require.Len(t, results, toolCount)
+
+			for i, result := range results {
+				assert.Equal(t, toolCalls[i].ID, *result.ToolCallID)
+				content, _ := result.Content.AsMessageContent0()
+				assert.Contains(t, content, fmt.Sprintf("tool_%d", i))
+			}
+			assert.Equal(t, tt.expectedPeak, peak.Load())
+		})
+	}
+}
+
 func TestAgent_RunWithStream(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -878,7 +999,7 @@ func TestAgent_RunWithStream(t *testing.T) {
 
 			tt.setupMocks(mockLogger, mockMCPClient, mockProvider)
 
-			agentInstance := mcp.NewAgent(mockLogger, mockMCPClient)
+			agentInstance := mcp.NewAgent(mockLogger, mockMCPClient, config.Config{})
 			agentInstance.SetProvider(mockProvider)
 			agentInstance.SetModel(&tt.request.Model)
 
@@ -0,0 +1,100 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+)
+
+func TestExtractAuthToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		header        string
+		cookie        *http.Cookie
+		body          string
+		cookieName    string
+		bodyField     string
+		expectedToken string
+		expectedBody  string
+		expectError   bool
+	}{
+		{
+			name:          "Bearer header",
+			header:        "Bearer header-token",
+			cookie:        &http.Cookie{Name: "ig_token", Value: "cookie-token"},
+			cookieName:    "ig_token",
+			expectedToken: "header-token",
+		},
+		{
+			name:          "Header without scheme is accepted as-is",
+			header:        "raw-token",
+			expectedToken: "raw-token",
+		},
+		{
+			name:          "Cookie fallback",
+			cookie:        &http.Cookie{Name: "ig_token", Value: "cookie-token"},
+			cookieName:    "ig_token",
+			expectedToken: "cookie-token",
+		},
+		{
+			name:        "Cookie ignored when not configured",
+			cookie:      &http.Cookie{Name: "ig_token", Value: "cookie-token"},
+			expectError: true,
+		},
+		{
+			name:          "Body field fallback strips the token",
+			body:          `{"access_token":"body-token","model":"openai/gpt-4o"}`,
+			bodyField:     "access_token",
+			expectedToken: "body-token",
+			expectedBody:  `{"model":"openai/gpt-4o"}`,
+		},
+		{
+			name:         "Body left untouched when field is absent",
+			body:         `{"model":"openai/gpt-4o"}`,
+			bodyField:    "access_token",
+			expectError:  true,
+			expectedBody: `{"model":"openai/gpt-4o"}`,
+		},
+		{
+			name:        "No token anywhere",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			if tt.header != "" {
+				c.Request.Header.Set("Authorization", tt.header)
+			}
+			if tt.cookie != nil {
+				c.Request.AddCookie(tt.cookie)
+			}
+
+			token, err := middlewares.ExtractAuthToken(c, tt.cookieName, tt.bodyField, 1024)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedToken, token)
+			}
+
+			if tt.expectedBody != "" {
+				body, err := io.ReadAll(c.Request.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, tt.expectedBody, string(body))
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	broadcast "github.com/inference-gateway/inference-gateway/api/broadcast"
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+func newBroadcastServer(t *testing.T, release <-chan struct{}) (*httptest.Server, *broadcast.Hub) {
+	t.Helper()
+	hub := broadcast.NewHub(16, time.Minute)
+	broadcaster, err := middlewares.NewStreamBroadcasterMiddleware(logger.NewNoopLogger(), config.Config{}, hub)
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(broadcaster.Middleware())
+	r.POST("/v1/chat/completions", func(c *gin.Context) {
+		middlewares.SetSSEHeaders(c)
+		_, _ = c.Writer.WriteString("data: {\"n\":1}\n\n")
+		c.Writer.Flush()
+		<-release
+		_, _ = c.Writer.WriteString("data: {\"n\":2}\n\n")
+		_, _ = c.Writer.WriteString("data: [DONE]\n\n")
+	})
+	r.GET("/v1/streams/:id/subscribe", hub.SubscribeHandler)
+
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv, hub
+}
+
+func TestStreamBroadcasterMiddleware(t *testing.T) {
+	release := make(chan struct{})
+	srv, hub := newBroadcastServer(t, release)
+
+	origin, err := http.Post(srv.URL+"/v1/chat/completions", "application/json", strings.NewReader(`{"model":"m","stream":true}`))
+	require.NoError(t, err)
+	defer origin.Body.Close()
+	id := origin.Header.Get(middlewares.StreamIDHeader)
+	require.NotEmpty(t, id)
+	assert.Equal(t, 1, hub.Len())
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/v1/streams/"+id+"/subscribe", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer other")
+	other, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	other.Body.Close()
+	assert.Equal(t, http.StatusNotFound, other.StatusCode, "only the caller of the stream can subscribe to it")
+
+	subscriber, err := http.Get(srv.URL + "/v1/streams/" + id + "/subscribe")
+	require.NoError(t, err)
+	defer subscriber.Body.Close()
+	require.Equal(t, http.StatusOK, subscriber.StatusCode)
+	assert.Equal(t, "text/event-stream", subscriber.Header.Get("Content-Type"))
+	close(release)
+
+	expected := "data: {\"n\":1}\n\ndata: {\"n\":2}\n\ndata: [DONE]\n\n"
+	originBody, err := io.ReadAll(origin.Body)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(originBody))
+	subscriberBody, err := io.ReadAll(subscriber.Body)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(subscriberBody), "late subscribers get the replayed history followed by live chunks")
+
+	assert.Eventually(t, func() bool { return hub.Len() == 0 }, time.Second, 10*time.Millisecond)
+	resp, err := http.Get(srv.URL + "/v1/streams/" + id + "/subscribe")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "finished streams can no longer be subscribed to")
+}
+
+func TestStreamBroadcasterMiddleware_NonStreaming(t *testing.T) {
+	hub := broadcast.NewHub(16, 0)
+	broadcaster, err := middlewares.NewStreamBroadcasterMiddleware(logger.NewNoopLogger(), config.Config{}, hub)
+	require.NoError(t, err)
+
+	var received string
+	r := gin.New()
+	r.Use(broadcaster.Middleware())
+	r.POST("/v1/chat/completions", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		received = string(body)
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"m"}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(middlewares.StreamIDHeader))
+	assert.Equal(t, `{"model":"m"}`, received, "the request body must be restored for the handler")
+}
@@ -0,0 +1,97 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	config "github.com/inference-gateway/inference-gateway/config"
+)
+
+func TestConfigureClientIP(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies string
+		header         string
+		remoteAddr     string
+		requestHeaders map[string]string
+		expectedIP     string
+	}{
+		{
+			name:           "No trusted proxies ignores forwarded headers",
+			header:         "X-Forwarded-For",
+			remoteAddr:     "10.0.0.5:1234",
+			requestHeaders: map[string]string{"X-Forwarded-For": "203.0.113.7"},
+			expectedIP:     "10.0.0.5",
+		},
+		{
+			name:           "Trusted proxy CIDR honours X-Forwarded-For",
+			trustedProxies: "10.0.0.0/8",
+			header:         "X-Forwarded-For",
+			remoteAddr:     "10.0.0.5:1234",
+			requestHeaders: map[string]string{"X-Forwarded-For": "203.0.113.7, 10.0.0.9"},
+			expectedIP:     "203.0.113.7",
+		},
+		{
+			name:           "Untrusted peer cannot spoof the client IP",
+			trustedProxies: "10.0.0.0/8",
+			header:         "X-Forwarded-For",
+			remoteAddr:     "192.0.2.1:1234",
+			requestHeaders: map[string]string{"X-Forwarded-For": "203.0.113.7"},
+			expectedIP:     "192.0.2.1",
+		},
+		{
+			name:           "CF-Connecting-IP header",
+			trustedProxies: "10.0.0.1",
+			header:         "CF-Connecting-IP",
+			remoteAddr:     "10.0.0.1:1234",
+			requestHeaders: map[string]string{"CF-Connecting-IP": "198.51.100.4", "X-Forwarded-For": "203.0.113.7"},
+			expectedIP:     "198.51.100.4",
+		},
+		{
+			name:           "X-Real-IP header",
+			trustedProxies: "10.0.0.0/8",
+			header:         "x-real-ip",
+			remoteAddr:     "10.1.2.3:1234",
+			requestHeaders: map[string]string{"X-Real-IP": "198.51.100.9"},
+			expectedIP:     "198.51.100.9",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			cfg := config.Config{Server: &config.ServerConfig{TrustedProxies: tt.trustedProxies, ClientIpHeader: tt.header}}
+			require.NoError(t, middlewares.ConfigureClientIP(r, cfg))
+
+			var clientIP string
+			r.GET("/ip", func(c *gin.Context) {
+				clientIP = c.ClientIP()
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.requestHeaders {
+				req.Header.Set(k, v)
+			}
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.expectedIP, clientIP)
+		})
+	}
+}
+
+func TestConfigureClientIP_InvalidConfig(t *testing.T) {
+	r := gin.New()
+	err := middlewares.ConfigureClientIP(r, config.Config{Server: &config.ServerConfig{ClientIpHeader: "X-Client"}})
+	assert.Error(t, err)
+
+	err = middlewares.ConfigureClientIP(r, config.Config{Server: &config.ServerConfig{TrustedProxies: "not-a-cidr", ClientIpHeader: "X-Forwarded-For"}})
+	assert.Error(t, err)
+}
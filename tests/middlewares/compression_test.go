@@ -0,0 +1,173 @@
+package middleware_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+const compressionStream = "data: {\"id\":\"c1\",\"model\":\"m\",\"created\":1,\"choices\":[{\"delta\":{\"content\":\"a\"}}]}\n\n" +
+	"data: {\"id\":\"c1\",\"model\":\"m\",\"created\":1,\"choices\":[{\"delta\":{\"content\":\"b\"}}]}\n\n" +
+	"data: {\"id\":\"c1\",\"model\":\"m2\",\"created\":1,\"choices\":[{\"delta\":{\"content\":\"c\"}}]}\n\n" +
+	"data: [DONE]\n\n"
+
+func newCompressionRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	compression, err := middlewares.NewStreamCompressionMiddleware(logger.NewNoopLogger(), config.Config{})
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(compression.Middleware())
+	r.POST("/v1/chat/completions", func(c *gin.Context) {
+		middlewares.SetSSEHeaders(c)
+		// Split a chunk across writes to exercise line buffering
+		_, _ = c.Writer.WriteString(compressionStream[:30])
+		c.Writer.Flush()
+		_, _ = c.Writer.WriteString(compressionStream[30:])
+	})
+	r.GET("/v1/models", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"object": "list"})
+	})
+	return r
+}
+
+func TestStreamCompressionMiddleware(t *testing.T) {
+	tests := []struct {
+		name             string
+		method           string
+		path             string
+		headers          map[string]string
+		expectedEncoding string
+		expectedDelta    string
+		expectedBody     string
+	}{
+		{
+			name:         "No opt-in leaves the stream untouched",
+			method:       http.MethodPost,
+			path:         "/v1/chat/completions",
+			expectedBody: compressionStream,
+		},
+		{
+			name:             "Gzip",
+			method:           http.MethodPost,
+			path:             "/v1/chat/completions",
+			headers:          map[string]string{"Accept-Encoding": "br, gzip"},
+			expectedEncoding: "gzip",
+			expectedBody:     compressionStream,
+		},
+		{
+			name:          "Delta encoding",
+			method:        http.MethodPost,
+			path:          "/v1/chat/completions",
+			headers:       map[string]string{middlewares.StreamDeltaHeader: "true"},
+			expectedDelta: "true",
+			expectedBody: "data: {\"id\":\"c1\",\"model\":\"m\",\"created\":1,\"choices\":[{\"delta\":{\"content\":\"a\"}}]}\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\"b\"}}]}\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\"c\"}}],\"model\":\"m2\"}\n\n" +
+				"data: [DONE]\n\n",
+		},
+		{
+			name:             "Gzip and delta encoding",
+			method:           http.MethodPost,
+			path:             "/v1/chat/completions",
+			headers:          map[string]string{"Accept-Encoding": "gzip", middlewares.StreamDeltaHeader: "true"},
+			expectedEncoding: "gzip",
+			expectedDelta:    "true",
+			expectedBody: "data: {\"id\":\"c1\",\"model\":\"m\",\"created\":1,\"choices\":[{\"delta\":{\"content\":\"a\"}}]}\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\"b\"}}]}\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\"c\"}}],\"model\":\"m2\"}\n\n" +
+				"data: [DONE]\n\n",
+		},
+		{
+			name:         "Gzip explicitly refused",
+			method:       http.MethodPost,
+			path:         "/v1/chat/completions",
+			headers:      map[string]string{"Accept-Encoding": "gzip;q=0"},
+			expectedBody: compressionStream,
+		},
+		{
+			name:         "Non-streaming responses are not compressed",
+			method:       http.MethodGet,
+			path:         "/v1/models",
+			headers:      map[string]string{"Accept-Encoding": "gzip", middlewares.StreamDeltaHeader: "true"},
+			expectedBody: `{"object":"list"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newCompressionRouter(t)
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			assert.Equal(t, tt.expectedEncoding, w.Header().Get("Content-Encoding"))
+			assert.Equal(t, tt.expectedDelta, w.Header().Get(middlewares.StreamDeltaHeader))
+
+			var body io.Reader = w.Body
+			if tt.expectedEncoding == "gzip" {
+				gz, err := gzip.NewReader(w.Body)
+				require.NoError(t, err)
+				body = gz
+			}
+			decoded, err := io.ReadAll(body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedBody, string(decoded))
+		})
+	}
+}
+
+// TestStreamCompressionMiddleware_FlushesChunks checks that every chunk can be
+// decompressed as soon as it is written instead of when the stream ends.
+func TestStreamCompressionMiddleware_FlushesChunks(t *testing.T) {
+	compression, err := middlewares.NewStreamCompressionMiddleware(logger.NewNoopLogger(), config.Config{})
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+	r := gin.New()
+	r.Use(compression.Middleware())
+	r.POST("/v1/chat/completions", func(c *gin.Context) {
+		middlewares.SetSSEHeaders(c)
+		_, _ = c.Writer.WriteString("data: {\"n\":1}\n\n")
+		c.Writer.Flush()
+		<-release
+		_, _ = c.Writer.WriteString("data: [DONE]\n\n")
+	})
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/v1/chat/completions", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+
+	gz, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+	first := make([]byte, len("data: {\"n\":1}\n\n"))
+	_, err = io.ReadFull(gz, first)
+	require.NoError(t, err)
+	assert.Equal(t, "data: {\"n\":1}\n\n", string(first))
+
+	close(release)
+	rest, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, "data: [DONE]\n\n", string(rest))
+}
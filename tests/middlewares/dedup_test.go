@@ -0,0 +1,139 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+type dedupResult struct {
+	status       int
+	body         string
+	deduplicated bool
+}
+
+// runConcurrently sends the first request, waits for it to reach the handler
+// and then sends the others while it is still in flight
+func runConcurrently(t *testing.T, bodies []string, auth []string) (results []dedupResult, calls int32) {
+	t.Helper()
+	dedup, err := middlewares.NewDedupMiddleware(logger.NewNoopLogger(), config.Config{})
+	require.NoError(t, err)
+
+	var count atomic.Int32
+	started := make(chan struct{}, len(bodies))
+	release := make(chan struct{})
+	r := gin.New()
+	r.Use(dedup.Middleware())
+	r.POST("/v1/chat/completions", func(c *gin.Context) {
+		n := count.Add(1)
+		started <- struct{}{}
+		<-release
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Header("X-Call", string(rune('0'+n)))
+		c.String(http.StatusOK, "%s", body)
+	})
+
+	results = make([]dedupResult, len(bodies))
+	var wg sync.WaitGroup
+	send := func(i int) {
+		defer wg.Done()
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(bodies[i]))
+		req.Header.Set("Authorization", auth[i])
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		results[i] = dedupResult{
+			status:       w.Code,
+			body:         w.Body.String(),
+			deduplicated: w.Header().Get(middlewares.DeduplicatedHeader) == "true",
+		}
+	}
+
+	wg.Add(1)
+	go send(0)
+	<-started
+	for i := 1; i < len(bodies); i++ {
+		wg.Add(1)
+		go send(i)
+	}
+	// Let the other requests reach the middleware before the first completes
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	return results, count.Load()
+}
+
+func TestDedupMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		bodies       []string
+		auth         []string
+		expectCalls  int32
+		expectShared int
+	}{
+		{
+			name:         "identical requests share one call",
+			bodies:       []string{`{"model":"m","messages":[]}`, `{"model":"m","messages":[]}`, `{"model":"m","messages":[]}`},
+			auth:         []string{"Bearer a", "Bearer a", "Bearer a"},
+			expectCalls:  1,
+			expectShared: 2,
+		},
+		{
+			name:         "key order does not matter",
+			bodies:       []string{`{"model":"m","messages":[]}`, `{"messages":[], "model":"m"}`},
+			auth:         []string{"Bearer a", "Bearer a"},
+			expectCalls:  1,
+			expectShared: 1,
+		},
+		{
+			name:        "different bodies are not shared",
+			bodies:      []string{`{"model":"m","messages":[]}`, `{"model":"n","messages":[]}`},
+			auth:        []string{"Bearer a", "Bearer a"},
+			expectCalls: 2,
+		},
+		{
+			name:        "different callers are not shared",
+			bodies:      []string{`{"model":"m","messages":[]}`, `{"model":"m","messages":[]}`},
+			auth:        []string{"Bearer a", "Bearer b"},
+			expectCalls: 2,
+		},
+		{
+			name:        "streaming requests are not shared",
+			bodies:      []string{`{"model":"m","stream":true}`, `{"model":"m","stream":true}`},
+			auth:        []string{"Bearer a", "Bearer a"},
+			expectCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, calls := runConcurrently(t, tt.bodies, tt.auth)
+			assert.Equal(t, tt.expectCalls, calls)
+
+			shared := 0
+			for i, result := range results {
+				assert.Equal(t, http.StatusOK, result.status, "request %d", i)
+				if result.deduplicated {
+					shared++
+					assert.Equal(t, results[0].body, result.body, "request %d gets the first response", i)
+				}
+			}
+			assert.Equal(t, tt.expectShared, shared)
+			assert.False(t, results[0].deduplicated, "the first request is served as usual")
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	health "github.com/inference-gateway/inference-gateway/api/health"
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+func TestDrainMiddleware(t *testing.T) {
+	state := health.NewState()
+	drain, err := middlewares.NewDrainMiddleware(logger.NewNoopLogger(), state)
+	require.NoError(t, err)
+
+	var inFlight, streams, ndjsonStreams int
+	r := gin.New()
+	r.Use(drain.Middleware())
+	r.GET("/v1/models", func(c *gin.Context) {
+		inFlight = state.InFlight()
+		c.Status(http.StatusOK)
+	})
+	r.POST("/v1/chat/completions", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		_, _ = c.Writer.WriteString("data: {}\n\n")
+		streams = state.Streams()
+		_, _ = c.Writer.WriteString("data: [DONE]\n\n")
+	})
+	r.POST("/api/chat", func(c *gin.Context) {
+		c.Header("Content-Type", "application/x-ndjson")
+		_, _ = c.Writer.WriteString("{}\n")
+		ndjsonStreams = state.Streams()
+	})
+	r.GET("/health/ready", state.ReadyHandler)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/models", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, inFlight, "request is tracked while it runs")
+	assert.Equal(t, 0, state.InFlight())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil))
+	assert.Equal(t, 1, streams, "event streams are tracked while they run")
+	assert.Equal(t, 0, state.Streams())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/chat", nil))
+	assert.Equal(t, 1, ndjsonStreams, "NDJSON streams are tracked too")
+	assert.Equal(t, 0, state.Streams())
+
+	state.Drain()
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/models", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "close", w.Header().Get("Connection"))
+	var resp errcodes.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, errcodes.GatewayDraining.ID, resp.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/models", nil).WithContext(health.Admit(context.Background())))
+	assert.Equal(t, http.StatusOK, w.Code, "work accepted before the drain is still admitted")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "probes are still served while draining")
+}
@@ -0,0 +1,113 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	require "github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	eval "github.com/inference-gateway/inference-gateway/api/eval"
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	otel "github.com/inference-gateway/inference-gateway/otel"
+	mocks "github.com/inference-gateway/inference-gateway/tests/mocks"
+)
+
+// newEvalEngine returns an engine evaluating every response of its chat
+// handler, which answers with content and status, routing fast-chat to
+// groq/llama-3.3-70b-versatile
+func newEvalEngine(t *testing.T, telemetry otel.OpenTelemetry, status int, content string) (*gin.Engine, *eval.Evaluator) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	evaluator, err := eval.New(nil, telemetry, logger.NewNoopLogger(), eval.Options{
+		Percent:          100,
+		Concurrency:      4,
+		LatencyThreshold: time.Minute,
+	})
+	require.NoError(t, err)
+	mw, err := middlewares.NewEvalMiddleware(logger.NewNoopLogger(), config.Config{}, evaluator)
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(mw.Middleware())
+	r.POST("/v1/chat/completions", func(c *gin.Context) {
+		var req struct {
+			Model  string `json:"model"`
+			Stream bool   `json:"stream"`
+		}
+		_ = c.ShouldBindJSON(&req)
+		if req.Model == "fast-chat" {
+			c.Header("X-Selected-Provider", "groq")
+			c.Header("X-Selected-Model", "llama-3.3-70b-versatile")
+		}
+		if req.Stream {
+			middlewares.SetSSEHeaders(c)
+			c.Status(status)
+			for _, delta := range strings.SplitAfter(content, " ") {
+				chunk, _ := json.Marshal(gin.H{"choices": []gin.H{{"index": 0, "delta": gin.H{"content": delta}}}})
+				_, _ = fmt.Fprintf(c.Writer, "data: %s\n\n", chunk)
+			}
+			_, _ = c.Writer.WriteString("data: [DONE]\n\n")
+			return
+		}
+		c.JSON(status, gin.H{
+			"choices": []gin.H{{"index": 0, "finish_reason": "stop", "message": gin.H{"role": "assistant", "content": content}}},
+		})
+	})
+	return r, evaluator
+}
+
+func TestEval_RecordsScores(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	telemetry := mocks.NewMockOpenTelemetry(ctrl)
+	telemetry.EXPECT().RecordEvaluation(gomock.Any(), "openai", "openai/gpt-4o", eval.Latency, 1.0)
+	telemetry.EXPECT().RecordEvaluation(gomock.Any(), "openai", "openai/gpt-4o", eval.Refusal, 1.0)
+
+	r, evaluator := newEvalEngine(t, telemetry, http.StatusOK, "I'm sorry, but I can't help with that.")
+	w := postChat(r, `{"model":"openai/gpt-4o","messages":[{"role":"user","content":"hi"}]}`, nil)
+	evaluator.Wait()
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), "I can't help", "the client gets the response as usual")
+}
+
+func TestEval_StreamedResponses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	telemetry := mocks.NewMockOpenTelemetry(ctrl)
+	telemetry.EXPECT().RecordEvaluation(gomock.Any(), "openai", "openai/gpt-4o", eval.Latency, 1.0)
+	telemetry.EXPECT().RecordEvaluation(gomock.Any(), "openai", "openai/gpt-4o", eval.Refusal, 1.0)
+
+	r, evaluator := newEvalEngine(t, telemetry, http.StatusOK, "I'm sorry, but I can't help with that.")
+	w := postChat(r, `{"model":"openai/gpt-4o","stream":true,"messages":[{"role":"user","content":"hi"}]}`, nil)
+	evaluator.Wait()
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `"content":"help "`, "the client gets the stream as usual")
+}
+
+func TestEval_RoutedAliasRecordedUnderSelectedDeployment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	telemetry := mocks.NewMockOpenTelemetry(ctrl)
+	telemetry.EXPECT().RecordEvaluation(gomock.Any(), "groq", "llama-3.3-70b-versatile", gomock.Any(), gomock.Any()).Times(2)
+
+	r, evaluator := newEvalEngine(t, telemetry, http.StatusOK, "Hello!")
+	w := postChat(r, `{"model":"fast-chat"}`, nil)
+	evaluator.Wait()
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestEval_FailedResponsesNotEvaluated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	telemetry := mocks.NewMockOpenTelemetry(ctrl)
+
+	r, evaluator := newEvalEngine(t, telemetry, http.StatusBadGateway, "")
+	w := postChat(r, `{"model":"openai/gpt-4o"}`, nil)
+	evaluator.Wait()
+	require.Equal(t, http.StatusBadGateway, w.Code)
+}
@@ -0,0 +1,104 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	files "github.com/inference-gateway/inference-gateway/api/files"
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+func TestFileResolverMiddleware(t *testing.T) {
+	ctx := context.Background()
+	store, err := files.NewDiskStore(t.TempDir())
+	require.NoError(t, err)
+	png := "\x89PNG\r\n\x1a\nimage"
+	owner := tenants.Owner(ctx, http.Header{})
+	image, err := store.Create(ctx, "cat.png", "vision", owner, strings.NewReader(png))
+	require.NoError(t, err)
+	text, err := store.Create(ctx, "notes.txt", "user_data", owner, strings.NewReader("notes"))
+	require.NoError(t, err)
+	foreign, err := store.Create(ctx, "dog.png", "vision", "someone else", strings.NewReader(png))
+	require.NoError(t, err)
+	dataURL := "data:image/png;base64," + base64.StdEncoding.EncodeToString([]byte(png))
+
+	resolver, err := middlewares.NewFileResolverMiddleware(logger.NewNoopLogger(), config.Config{Server: &config.ServerConfig{MaxRequestBytes: 1 << 20}}, store)
+	require.NoError(t, err)
+
+	var received string
+	r := gin.New()
+	r.Use(resolver.Middleware())
+	r.POST("/v1/chat/completions", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		received = string(body)
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name         string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "image_url referencing a file",
+			body:         `{"model":"openai/gpt-4o","messages":[{"role":"user","content":[{"type":"text","text":"What is this?"},{"type":"image_url","image_url":{"url":"` + image.ID + `","detail":"low"}}]}],"temperature":0.5}`,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"model":"openai/gpt-4o","messages":[{"role":"user","content":[{"type":"text","text":"What is this?"},{"type":"image_url","image_url":{"url":"` + dataURL + `","detail":"low"}}]}],"temperature":0.5}`,
+		},
+		{
+			name:         "file part",
+			body:         `{"model":"openai/gpt-4o","messages":[{"role":"user","content":[{"type":"file","file":{"file_id":"` + image.ID + `"}}]}]}`,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"model":"openai/gpt-4o","messages":[{"role":"user","content":[{"type":"image_url","image_url":{"url":"` + dataURL + `"}}]}]}`,
+		},
+		{
+			name:         "text mentioning a file id is left alone",
+			body:         `{"model":"openai/gpt-4o","messages":[{"role":"user","content":"see file-00"}]}`,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"model":"openai/gpt-4o","messages":[{"role":"user","content":"see file-00"}]}`,
+		},
+		{
+			name:         "unknown file",
+			body:         `{"model":"openai/gpt-4o","messages":[{"role":"user","content":[{"type":"image_url","image_url":{"url":"file-00"}}]}]}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "file of another caller",
+			body:         `{"model":"openai/gpt-4o","messages":[{"role":"user","content":[{"type":"image_url","image_url":{"url":"` + foreign.ID + `"}}]}]}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "file that is not an image",
+			body:         `{"model":"openai/gpt-4o","messages":[{"role":"user","content":[{"type":"image_url","image_url":{"url":"` + text.ID + `"}}]}]}`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = ""
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code, w.Body.String())
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, received)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+const testHooksConfig = `
+routes:
+  /v1/chat/completions:
+    - name: system_prompt
+      options:
+        content: org prompt
+    - name: strip_fields
+      options:
+        response:
+          - system_fingerprint
+`
+
+func newHooksRouter(t *testing.T, handler gin.HandlerFunc) *gin.Engine {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "hooks.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testHooksConfig), 0o600))
+
+	cfg := config.Config{HooksConfigPath: path, Server: &config.ServerConfig{MaxRequestBytes: 1024}}
+	hooks, err := middlewares.NewHooksMiddleware(logger.NewNoopLogger(), cfg)
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(hooks.Middleware())
+	r.POST("/v1/chat/completions", handler)
+	r.POST("/v1/other", handler)
+	return r
+}
+
+func TestHooksMiddleware_TransformsRequestAndResponse(t *testing.T) {
+	var received string
+	r := newHooksRouter(t, func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		received = string(body)
+		c.JSON(http.StatusOK, gin.H{"id": "1", "system_fingerprint": "fp"})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"messages":[{"role":"user","content":"hi"}]}`)))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"messages":[{"role":"system","content":"org prompt"},{"role":"user","content":"hi"}]}`, received)
+	assert.JSONEq(t, `{"id":"1"}`, w.Body.String())
+}
+
+func TestHooksMiddleware_PassesThrough(t *testing.T) {
+	tests := []struct {
+		name         string
+		path         string
+		handler      gin.HandlerFunc
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name: "Route without hooks",
+			path: "/v1/other",
+			handler: func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"system_fingerprint": "fp"})
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"system_fingerprint":"fp"}`,
+		},
+		{
+			name: "Error responses are not transformed",
+			path: "/v1/chat/completions",
+			handler: func(c *gin.Context) {
+				c.JSON(http.StatusBadGateway, gin.H{"error": "upstream", "system_fingerprint": "fp"})
+			},
+			expectedCode: http.StatusBadGateway,
+			expectedBody: `{"error":"upstream","system_fingerprint":"fp"}`,
+		},
+		{
+			name: "Streaming responses are not buffered",
+			path: "/v1/chat/completions",
+			handler: func(c *gin.Context) {
+				middlewares.SetSSEHeaders(c)
+				c.Status(http.StatusOK)
+				_, _ = c.Writer.Write([]byte("data: {\"system_fingerprint\":\"fp\"}\n\n"))
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: "data: {\"system_fingerprint\":\"fp\"}\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newHooksRouter(t, tt.handler)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(`{"messages":[]}`)))
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if strings.HasPrefix(tt.expectedBody, "{") {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			} else {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHooksMiddleware_InvalidRequestBody(t *testing.T) {
+	r := newHooksRouter(t, func(c *gin.Context) {
+		t.Fatal("handler should not be called")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`not json`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(strings.Repeat("x", 2048))))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestNewHooksMiddleware_InvalidConfig(t *testing.T) {
+	_, err := middlewares.NewHooksMiddleware(logger.NewNoopLogger(), config.Config{HooksConfigPath: filepath.Join(t.TempDir(), "missing.yaml")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,298 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	files "github.com/inference-gateway/inference-gateway/api/files"
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+func newLimitsRouter(t *testing.T, server config.ServerConfig) (*gin.Engine, *string) {
+	t.Helper()
+	cfg := config.Config{Server: &server}
+	limits, err := middlewares.NewRequestLimitsMiddleware(logger.NewNoopLogger(), cfg)
+	require.NoError(t, err)
+
+	var received string
+	r := gin.New()
+	r.Use(limits.Middleware())
+	handler := func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+			return
+		}
+		received = string(body)
+		c.Status(http.StatusOK)
+	}
+	r.POST("/v1/chat/completions", handler)
+	r.POST("/api/chat", handler)
+	r.POST("/v1/messages", handler)
+	r.POST("/proxy/:provider/*path", handler)
+	return r, &received
+}
+
+func TestRequestLimitsMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		server         config.ServerConfig
+		path           string
+		body           string
+		expectedStatus int
+		expectedLimit  string
+	}{
+		{
+			name:           "Within all limits",
+			server:         config.ServerConfig{MaxRequestBytes: 1024, MaxMessages: 2, MaxPromptChars: 20, MaxPromptTokens: 100, MaxTokensLimits: "openai/gpt-4o=100"},
+			path:           "/v1/chat/completions",
+			body:           `{"model":"openai/gpt-4o","max_tokens":100,"messages":[{"role":"user","content":"hello"}]}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Body too large on chat path",
+			server:         config.ServerConfig{MaxRequestBytes: 16},
+			path:           "/v1/chat/completions",
+			body:           `{"model":"openai/gpt-4o","messages":[]}`,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedLimit:  "max_request_bytes",
+		},
+		{
+			name:           "Body too large on proxy path",
+			server:         config.ServerConfig{MaxRequestBytes: 16},
+			path:           "/proxy/openai/chat/completions",
+			body:           `{"model":"gpt-4o","messages":[]}`,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedLimit:  "max_request_bytes",
+		},
+		{
+			name:           "Too many messages",
+			server:         config.ServerConfig{MaxMessages: 1},
+			path:           "/v1/chat/completions",
+			body:           `{"model":"openai/gpt-4o","messages":[{"role":"user","content":"a"},{"role":"user","content":"b"}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedLimit:  "max_messages",
+		},
+		{
+			name:           "Prompt too long counts multimodal text parts",
+			server:         config.ServerConfig{MaxPromptChars: 8},
+			path:           "/v1/chat/completions",
+			body:           `{"model":"openai/gpt-4o","messages":[{"role":"user","content":"hello"},{"role":"user","content":[{"type":"text","text":"world"}]}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedLimit:  "max_prompt_chars",
+		},
+		{
+			name:           "Prompt above the token limit counts message framing",
+			server:         config.ServerConfig{MaxPromptTokens: 9},
+			path:           "/v1/chat/completions",
+			body:           `{"model":"anthropic/claude-sonnet-4","messages":[{"role":"user","content":"hello world"}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedLimit:  "max_prompt_tokens",
+		},
+		{
+			name:           "max_tokens above cap for provider-stripped model",
+			server:         config.ServerConfig{MaxTokensLimits: "gpt-4o=100"},
+			path:           "/v1/chat/completions",
+			body:           `{"model":"openai/gpt-4o","max_tokens":101,"messages":[]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedLimit:  "max_tokens",
+		},
+		{
+			name:           "max_completion_tokens above wildcard cap",
+			server:         config.ServerConfig{MaxTokensLimits: "openai/gpt-4o=1000,*=10"},
+			path:           "/v1/chat/completions",
+			body:           `{"model":"groq/llama","max_completion_tokens":11,"messages":[]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedLimit:  "max_tokens",
+		},
+		{
+			name:           "Ollama chat within all limits",
+			server:         config.ServerConfig{MaxMessages: 2, MaxPromptChars: 20, MaxTokensLimits: "*=100"},
+			path:           "/api/chat",
+			body:           `{"model":"ollama/llama3","options":{"num_predict":100},"messages":[{"role":"user","content":"hello"}]}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Ollama chat with too many messages",
+			server:         config.ServerConfig{MaxMessages: 1},
+			path:           "/api/chat",
+			body:           `{"model":"ollama/llama3","messages":[{"role":"user","content":"a"},{"role":"user","content":"b"}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedLimit:  "max_messages",
+		},
+		{
+			name:           "Ollama chat num_predict above cap",
+			server:         config.ServerConfig{MaxTokensLimits: "*=10"},
+			path:           "/api/chat",
+			body:           `{"model":"ollama/llama3","options":{"num_predict":11},"messages":[]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedLimit:  "max_tokens",
+		},
+		{
+			name:           "Messages API prompt too long counts the system prompt and text blocks",
+			server:         config.ServerConfig{MaxPromptChars: 10},
+			path:           "/v1/messages",
+			body:           `{"model":"anthropic/claude-sonnet-4-5","max_tokens":10,"system":"be brief","messages":[{"role":"user","content":[{"type":"text","text":"hello"}]}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedLimit:  "max_prompt_chars",
+		},
+		{
+			name:           "Messages API max_tokens above cap",
+			server:         config.ServerConfig{MaxTokensLimits: "claude-sonnet-4-5=100"},
+			path:           "/v1/messages",
+			body:           `{"model":"anthropic/claude-sonnet-4-5","max_tokens":101,"messages":[{"role":"user","content":"hi"}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedLimit:  "max_tokens",
+		},
+		{
+			name:           "Limits disabled",
+			server:         config.ServerConfig{},
+			path:           "/v1/chat/completions",
+			body:           `{"model":"openai/gpt-4o","max_tokens":100000,"messages":[{"role":"user","content":"hello"}]}`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, received := newLimitsRouter(t, tt.server)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedLimit == "" {
+				assert.Equal(t, tt.body, *received, "body should reach the handler unchanged")
+				return
+			}
+
+			var resp middlewares.LimitErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.expectedLimit, resp.Limit)
+			assert.NotEmpty(t, resp.Error)
+			assert.Positive(t, resp.Max)
+		})
+	}
+}
+
+func TestNewRequestLimitsMiddleware_InvalidMaxTokensLimits(t *testing.T) {
+	for _, raw := range []string{"gpt-4o", "gpt-4o=abc", "=10", "gpt-4o=0"} {
+		cfg := config.Config{Server: &config.ServerConfig{MaxTokensLimits: raw}}
+		_, err := middlewares.NewRequestLimitsMiddleware(logger.NewNoopLogger(), cfg)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestRequestLimitsMiddleware_Reload(t *testing.T) {
+	limits, err := middlewares.NewRequestLimitsMiddleware(logger.NewNoopLogger(), config.Config{Server: &config.ServerConfig{MaxMessages: 1}})
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(limits.Middleware())
+	r.POST("/v1/chat/completions", func(c *gin.Context) { c.Status(http.StatusOK) })
+	send := func() int {
+		w := httptest.NewRecorder()
+		body := `{"model":"openai/gpt-4o","messages":[{"role":"user","content":"a"},{"role":"user","content":"b"}]}`
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body)))
+		return w.Code
+	}
+	assert.Equal(t, http.StatusBadRequest, send())
+
+	require.NoError(t, limits.Reload(config.Config{Server: &config.ServerConfig{MaxMessages: 2}}))
+	assert.Equal(t, http.StatusOK, send())
+
+	err = limits.Reload(config.Config{Server: &config.ServerConfig{MaxMessages: 1, MaxTokensLimits: "gpt-4o"}})
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusOK, send(), "invalid limits keep the current ones")
+}
+
+func TestRequestLimitsMiddleware_ProxyStrict(t *testing.T) {
+	body := `{"model":"gpt-4o","messages":[{"role":"user","content":"a"},{"role":"user","content":"b"}]}`
+	for strict, expected := range map[bool]int{false: http.StatusOK, true: http.StatusBadRequest} {
+		limits, err := middlewares.NewRequestLimitsMiddleware(logger.NewNoopLogger(), config.Config{ProxyStrict: strict, Server: &config.ServerConfig{MaxMessages: 1}})
+		require.NoError(t, err)
+
+		r := gin.New()
+		r.Use(limits.Middleware())
+		r.POST("/proxy/:provider/*path", func(c *gin.Context) { c.Status(http.StatusOK) })
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/proxy/openai/v1/chat/completions", strings.NewReader(body)))
+		assert.Equal(t, expected, w.Code, "strict: %v", strict)
+	}
+}
+
+func TestRequestLimitsMiddleware_FileUploads(t *testing.T) {
+	store, err := files.NewDiskStore(t.TempDir())
+	require.NoError(t, err)
+	api, err := files.NewAPI(store, logger.NewNoopLogger())
+	require.NoError(t, err)
+	limits, err := middlewares.NewRequestLimitsMiddleware(logger.NewNoopLogger(), config.Config{
+		FilesMaxUploadBytes: 4096,
+		Server:              &config.ServerConfig{MaxRequestBytes: 512},
+	})
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(limits.Middleware())
+	r.POST("/v1/files", api.UploadHandler)
+	r.POST("/v1/chat/completions", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	upload := func(size int) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, err := form.CreateFormFile("file", "input.jsonl")
+		require.NoError(t, err)
+		_, err = part.Write(bytes.Repeat([]byte("x"), size))
+		require.NoError(t, err)
+		require.NoError(t, form.WriteField("purpose", files.PurposeBatch))
+		require.NoError(t, form.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/files", &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := upload(2048)
+	assert.Equal(t, http.StatusOK, w.Code, "uploads above the chat request limit are accepted: %s", w.Body.String())
+
+	w = upload(8192)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	var resp middlewares.LimitErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "max_upload_bytes", resp.Limit)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(strings.Repeat("x", 2048))))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code, "other requests keep the request limit")
+}
+
+func TestMatchProxyPath(t *testing.T) {
+	patterns, err := middlewares.ParseProxyPaths("*/chat/completions, /openai/v1/responses/ ,groq/*models")
+	require.NoError(t, err)
+
+	assert.True(t, middlewares.MatchProxyPath(patterns, "openai/v1/chat/completions"))
+	assert.True(t, middlewares.MatchProxyPath(patterns, "google/v1beta/openai/chat/completions"))
+	assert.True(t, middlewares.MatchProxyPath(patterns, "openai/v1/responses"))
+	assert.True(t, middlewares.MatchProxyPath(patterns, "groq/openai/v1/models"))
+	assert.False(t, middlewares.MatchProxyPath(patterns, "openai/v1/files"))
+	assert.False(t, middlewares.MatchProxyPath(patterns, "anthropic/v1/responses"))
+
+	_, err = middlewares.ParseProxyPaths("openai/[v1")
+	assert.ErrorContains(t, err, "invalid PROXY_ALLOWED_PATHS pattern")
+}
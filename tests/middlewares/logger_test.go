@@ -0,0 +1,86 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+	mocks "github.com/inference-gateway/inference-gateway/tests/mocks"
+)
+
+// newLoggerEngine returns an engine whose handler logs through the request's
+// logger and answers with the request ID of its context
+func newLoggerEngine(t *testing.T, log logger.Logger) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	mw, err := middlewares.NewLoggerMiddleware(&log)
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(mw.Middleware())
+	r.GET("/v1/models", func(c *gin.Context) {
+		logger.FromContext(c.Request.Context(), logger.NewNoopLogger()).Info("listing models")
+		requestID, _ := c.Request.Context().Value(types.RequestIDContextKey).(string)
+		c.String(http.StatusOK, requestID)
+	})
+	return r
+}
+
+func getModels(r *gin.Engine, requestID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+	if requestID != "" {
+		req.Header.Set("X-Request-ID", requestID)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestLogger_RequestID(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string
+		keep      bool
+	}{
+		{name: "generated", requestID: ""},
+		{name: "client provided", requestID: "trace-42:step.1", keep: true},
+		{name: "invalid replaced", requestID: "bad id\nforged=1"},
+		{name: "too long replaced", requestID: strings.Repeat("a", 129)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			log := mocks.NewMockLogger(ctrl)
+			var logged []string
+			record := func(_ string, fields ...any) {
+				require.GreaterOrEqual(t, len(fields), 2)
+				assert.Equal(t, "request_id", fields[0])
+				logged = append(logged, fields[1].(string))
+			}
+			log.EXPECT().Info("request received", gomock.Any()).Do(record)
+			log.EXPECT().Debug("request details", gomock.Any()).Do(record)
+			log.EXPECT().Info("listing models", gomock.Any()).Do(record)
+
+			w := getModels(newLoggerEngine(t, log), tt.requestID)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			requestID := w.Header().Get("X-Request-ID")
+			if tt.keep {
+				assert.Equal(t, tt.requestID, requestID)
+			} else {
+				assert.Regexp(t, `^req_[0-9a-f]{32}$`, requestID)
+			}
+			assert.Equal(t, requestID, w.Body.String(), "the request ID is in the request context")
+			assert.Equal(t, []string{requestID, requestID, requestID}, logged, "every line is tagged with the request ID")
+		})
+	}
+}
@@ -12,11 +12,15 @@ import (
 
 	gin "github.com/gin-gonic/gin"
 	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
 	gomock "go.uber.org/mock/gomock"
 
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
 	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
 	config "github.com/inference-gateway/inference-gateway/config"
 	mcp "github.com/inference-gateway/inference-gateway/internal/mcp"
+	supervisor "github.com/inference-gateway/inference-gateway/internal/supervisor"
+	logger "github.com/inference-gateway/inference-gateway/logger"
 	constants "github.com/inference-gateway/inference-gateway/providers/constants"
 	types "github.com/inference-gateway/inference-gateway/providers/types"
 
@@ -84,7 +88,7 @@ func TestNewMCPMiddleware(t *testing.T) {
 				mockLogger.EXPECT().Info("mcp client is nil, using no-op middleware")
 			}
 
-			mcpAgent := mcp.NewAgent(mockLogger, tt.mcpClient)
+			mcpAgent := mcp.NewAgent(mockLogger, tt.mcpClient, config.Config{})
 			middleware, err := middlewares.NewMCPMiddleware(mockRegistry, mockClient, tt.mcpClient, mcpAgent, mockLogger, cfg)
 
 			if tt.expectError {
@@ -152,7 +156,7 @@ func TestMCPMiddleware_SkipConditions(t *testing.T) {
 				tt.setupMocks(mockRegistry, mockClient, mockMCPClient, mockLogger, mockProvider)
 			}
 
-			mcpAgent := mcp.NewAgent(mockLogger, mockMCPClient)
+			mcpAgent := mcp.NewAgent(mockLogger, mockMCPClient, config.Config{})
 			middleware, err := middlewares.NewMCPMiddleware(mockRegistry, mockClient, mockMCPClient, mcpAgent, mockLogger, cfg)
 			assert.NoError(t, err)
 
@@ -271,7 +275,7 @@ func TestMCPMiddleware_AddToolsToRequest(t *testing.T) {
 
 			requestBody, _ := json.Marshal(requestData)
 
-			mcpAgent := mcp.NewAgent(mockLogger, mockMCPClient)
+			mcpAgent := mcp.NewAgent(mockLogger, mockMCPClient, config.Config{})
 			middleware, err := middlewares.NewMCPMiddleware(mockRegistry, mockClient, mockMCPClient, mcpAgent, mockLogger, cfg)
 			assert.NoError(t, err)
 			router := gin.New()
@@ -308,6 +312,135 @@ func TestMCPMiddleware_AddToolsToRequest(t *testing.T) {
 	}
 }
 
+func TestMCPMiddleware_FiltersTools(t *testing.T) {
+	ctrl, mockRegistry, mockClient, mockMCPClient, _, mockProvider := createMockDependencies(t)
+	defer ctrl.Finish()
+
+	cfg := createTestConfig()
+	cfg.MCP = &config.MCPConfig{ToolFilterEnable: true, ToolFilterTopK: 2, ToolFilterMethod: "keyword"}
+
+	describe := func(name, description string) types.ChatCompletionTool {
+		return types.ChatCompletionTool{Type: types.Function, Function: types.FunctionObject{Name: name, Description: &description}}
+	}
+	mockMCPClient.EXPECT().IsInitialized().Return(true).AnyTimes()
+	mockMCPClient.EXPECT().GetAllServerStatuses().Return(map[string]mcp.ServerStatus{"server1": mcp.ServerStatusAvailable}).AnyTimes()
+	mockMCPClient.EXPECT().GetAllChatCompletionTools().Return([]types.ChatCompletionTool{
+		describe("read_file", "Read a file from disk"),
+		describe("get_current_time", "Get the current time"),
+		describe("web_search", "Search the web"),
+		describe("list_directory", "List a directory"),
+	}).AnyTimes()
+	mockRegistry.EXPECT().BuildProvider(constants.OpenaiID, mockClient).Return(mockProvider, nil).AnyTimes()
+
+	log := logger.NewNoopLogger()
+	middleware, err := middlewares.NewMCPMiddleware(mockRegistry, mockClient, mockMCPClient, mcp.NewAgent(log, mockMCPClient, config.Config{}), log, cfg)
+	assert.NoError(t, err)
+	router := gin.New()
+	router.Use(middleware.Middleware())
+
+	var attached []string
+	router.POST("/v1/chat/completions", func(c *gin.Context) {
+		if req, ok := c.Get(middlewares.MCPBypassHeader); ok && req.(*types.CreateChatCompletionRequest).Tools != nil {
+			for _, tool := range *req.(*types.CreateChatCompletionRequest).Tools {
+				attached = append(attached, tool.Function.Name)
+			}
+		}
+		c.JSON(http.StatusOK, types.CreateChatCompletionResponse{
+			ID:      "test-id",
+			Choices: []types.ChatCompletionChoice{{Message: types.NewTextMessage(t, types.Assistant, "Done"), FinishReason: types.Stop}},
+		})
+	})
+
+	body := `{"model":"openai/gpt-4o","messages":[
+		{"role":"user","content":"Show me the directory"},
+		{"role":"assistant","content":"","tool_calls":[{"id":"c1","type":"function","function":{"name":"list_directory","arguments":"{}"}}]},
+		{"role":"tool","tool_call_id":"c1","content":"notes.txt"},
+		{"role":"user","content":"Now read the file"}]}`
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"read_file", "list_directory"}, attached, "the most relevant tool and the tool already called are attached")
+}
+
+func TestMCPMiddleware_AttachesBuiltinToolsWithoutMCPClient(t *testing.T) {
+	ctrl, mockRegistry, mockClient, _, _, mockProvider := createMockDependencies(t)
+	defer ctrl.Finish()
+	mockRegistry.EXPECT().BuildProvider(constants.OpenaiID, mockClient).Return(mockProvider, nil).AnyTimes()
+
+	description := "Return the current date and time"
+	builtinTools := []types.ChatCompletionTool{{Type: types.Function, Function: types.FunctionObject{Name: "builtin_time", Description: &description}}}
+	log := logger.NewNoopLogger()
+	middleware, err := middlewares.NewMCPMiddlewareWithBuiltinTools(mockRegistry, mockClient, nil, mcp.NewAgentWithExecutors(log, config.Config{}), builtinTools, log, createTestConfig())
+	assert.NoError(t, err)
+	router := gin.New()
+	router.Use(middleware.Middleware())
+
+	var attached []string
+	router.POST("/v1/chat/completions", func(c *gin.Context) {
+		if req, ok := c.Get(middlewares.MCPBypassHeader); ok && req.(*types.CreateChatCompletionRequest).Tools != nil {
+			for _, tool := range *req.(*types.CreateChatCompletionRequest).Tools {
+				attached = append(attached, tool.Function.Name)
+			}
+		}
+		c.JSON(http.StatusOK, types.CreateChatCompletionResponse{
+			ID:      "test-id",
+			Choices: []types.ChatCompletionChoice{{Message: types.NewTextMessage(t, types.Assistant, "Done"), FinishReason: types.Stop}},
+		})
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"openai/gpt-4o","messages":[{"role":"user","content":"What time is it?"}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"builtin_time"}, attached)
+}
+
+func TestMCPMiddleware_ModelPolicy(t *testing.T) {
+	ctrl, mockRegistry, mockClient, _, _, mockProvider := createMockDependencies(t)
+	defer ctrl.Finish()
+	mockRegistry.EXPECT().BuildProvider(constants.OpenaiID, mockClient).Return(mockProvider, nil).AnyTimes()
+
+	description := "Return the current date and time"
+	builtinTools := []types.ChatCompletionTool{{Type: types.Function, Function: types.FunctionObject{Name: "builtin_time", Description: &description}}}
+	cfg := createTestConfig()
+	cfg.DisallowedModels = "openai/gpt-4o"
+	log := logger.NewNoopLogger()
+	middleware, err := middlewares.NewMCPMiddlewareWithBuiltinTools(mockRegistry, mockClient, nil, mcp.NewAgentWithExecutors(log, config.Config{}), builtinTools, log, cfg)
+	assert.NoError(t, err)
+	router := gin.New()
+	router.Use(middleware.Middleware())
+	router.POST("/v1/chat/completions", func(c *gin.Context) {
+		c.JSON(http.StatusOK, types.CreateChatCompletionResponse{
+			ID:      "test-id",
+			Choices: []types.ChatCompletionChoice{{Message: types.NewTextMessage(t, types.Assistant, "Done"), FinishReason: types.Stop}},
+		})
+	})
+
+	post := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"openai/gpt-4o","stream":true,"messages":[{"role":"user","content":"What time is it?"}]}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post()
+	assert.Equal(t, http.StatusForbidden, w.Code, "the agent loop does not run for a disallowed model")
+	assert.Contains(t, w.Body.String(), "Model is disallowed")
+
+	cfg.DisallowedModels = ""
+	cfg.AllowedModels = "openai/gpt-4o-mini"
+	middleware.Reload(cfg)
+	w = post()
+	assert.Equal(t, http.StatusForbidden, w.Code, "the reloaded policy applies")
+	assert.Contains(t, w.Body.String(), "Model not allowed")
+}
+
 func TestMCPMiddleware_NonStreamingWithToolCalls(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -441,7 +574,7 @@ func TestMCPMiddleware_NonStreamingWithToolCalls(t *testing.T) {
 
 			requestBody, _ := json.Marshal(requestData)
 
-			mcpAgent := mcp.NewAgent(mockLogger, mockMCPClient)
+			mcpAgent := mcp.NewAgent(mockLogger, mockMCPClient, config.Config{})
 			middleware, err := middlewares.NewMCPMiddleware(mockRegistry, mockClient, mockMCPClient, mcpAgent, mockLogger, cfg)
 			assert.NoError(t, err)
 
@@ -550,7 +683,7 @@ data: [DONE]`,
 
 			requestBody, _ := json.Marshal(requestData)
 
-			mcpAgent := mcp.NewAgent(mockLogger, mockMCPClient)
+			mcpAgent := mcp.NewAgent(mockLogger, mockMCPClient, config.Config{})
 			middleware, err := middlewares.NewMCPMiddleware(mockRegistry, mockClient, mockMCPClient, mcpAgent, mockLogger, cfg)
 			assert.NoError(t, err)
 
@@ -648,7 +781,7 @@ func TestMCPMiddleware_ErrorHandling(t *testing.T) {
 
 			tt.setupMocks(mockRegistry, mockClient, mockMCPClient, mockLogger, mockProvider)
 
-			mcpAgent := mcp.NewAgent(mockLogger, mockMCPClient)
+			mcpAgent := mcp.NewAgent(mockLogger, mockMCPClient, config.Config{})
 			middleware, err := middlewares.NewMCPMiddleware(mockRegistry, mockClient, mockMCPClient, mcpAgent, mockLogger, cfg)
 			assert.NoError(t, err)
 
@@ -751,7 +884,7 @@ func TestMCPMiddleware_StreamingWithMultipleToolCallIterations(t *testing.T) {
 			},
 		}, nil).AnyTimes()
 
-		agentImpl := mcp.NewAgent(mockLogger, mockMCPClient)
+		agentImpl := mcp.NewAgent(mockLogger, mockMCPClient, config.Config{})
 
 		agentImpl.SetProvider(mockProvider)
 		model := "groq/meta-llama/llama-4-scout-17b-instruct"
@@ -884,7 +1017,7 @@ func TestMCPMiddleware_PassesThroughUpstreamErrors(t *testing.T) {
 	}
 	requestBody, _ := json.Marshal(requestData)
 
-	mcpAgent := mcp.NewAgent(mockLogger, mockMCPClient)
+	mcpAgent := mcp.NewAgent(mockLogger, mockMCPClient, config.Config{})
 	middleware, err := middlewares.NewMCPMiddleware(mockRegistry, mockClient, mockMCPClient, mcpAgent, mockLogger, cfg)
 	assert.NoError(t, err)
 
@@ -903,3 +1036,196 @@ func TestMCPMiddleware_PassesThroughUpstreamErrors(t *testing.T) {
 	assert.Equal(t, http.StatusBadGateway, w.Code)
 	assert.Contains(t, w.Body.String(), "upstream provider rejected the request")
 }
+
+func TestParseToolPaths(t *testing.T) {
+	tests := []struct {
+		name     string
+		csv      string
+		path     string
+		expected bool
+	}{
+		{name: "Empty list defaults to chat completions", csv: "", path: "/v1/chat/completions", expected: true},
+		{name: "Configured path matches", csv: "/v1/chat/completions, /v1/responses", path: "/v1/responses", expected: true},
+		{name: "Trailing slash is ignored", csv: "/v1/responses/", path: "/v1/responses/", expected: true},
+		{name: "Unlisted path does not match", csv: "/v1/responses", path: "/v1/chat/completions", expected: false},
+		{name: "Embeddings are always skipped", csv: "/v1/chat/completions,/v1/embeddings", path: "/v1/embeddings", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths := middlewares.ParseToolPaths(tt.csv)
+			assert.Equal(t, tt.expected, middlewares.IsToolPath(paths, tt.path))
+		})
+	}
+}
+
+func TestMCPMiddleware_ConfiguredToolPaths(t *testing.T) {
+	tests := []struct {
+		name         string
+		path         string
+		expectedCode int
+	}{
+		{name: "Configured path is orchestrated", path: "/v1/custom/chat", expectedCode: http.StatusBadRequest},
+		{name: "Default chat path no longer orchestrated", path: "/v1/chat/completions", expectedCode: http.StatusOK},
+		{name: "Embeddings skipped even when configured", path: "/v1/embeddings", expectedCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl, mockRegistry, mockClient, mockMCPClient, mockLogger, _ := createMockDependencies(t)
+			defer ctrl.Finish()
+			mockLogger.EXPECT().Debug(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			mockLogger.EXPECT().Error(gomock.Any(), gomock.Any()).AnyTimes()
+
+			cfg := createTestConfig()
+			cfg.MCP = &config.MCPConfig{ToolPaths: "/v1/custom/chat,/v1/embeddings"}
+
+			mcpAgent := mcp.NewAgent(mockLogger, mockMCPClient, config.Config{})
+			middleware, err := middlewares.NewMCPMiddleware(mockRegistry, mockClient, mockMCPClient, mcpAgent, mockLogger, cfg)
+			assert.NoError(t, err)
+
+			router := gin.New()
+			router.Use(middleware.Middleware())
+			router.POST("/*path", func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"status": "ok"})
+			})
+
+			// An invalid body is only rejected when the middleware processes the path
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(`not json`))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestMCPMiddleware_ExpandsPrompt(t *testing.T) {
+	tests := []struct {
+		name             string
+		prompt           string
+		expectedCode     int
+		expectedMessages []string
+	}{
+		{name: "Prompt messages go before the request's", prompt: "code_review", expectedCode: http.StatusOK, expectedMessages: []string{"Review this go code.", "func main() {}"}},
+		{name: "Unknown prompt is rejected", prompt: "unknown", expectedCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl, mockRegistry, mockClient, mockMCPClient, mockLogger, _ := createMockDependencies(t)
+			defer ctrl.Finish()
+			mockLogger.EXPECT().Debug(gomock.Any(), gomock.Any()).AnyTimes()
+			mockLogger.EXPECT().Debug(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			mockLogger.EXPECT().Error(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+			mockMCPClient.EXPECT().IsInitialized().Return(false).AnyTimes()
+			mockMCPClient.EXPECT().GetServers().Return([]string{"http://github:8080/mcp"}).AnyTimes()
+			mockMCPClient.EXPECT().ListPrompts(gomock.Any(), "http://github:8080/mcp").Return([]mcp.Prompt{{Name: "code_review"}}, nil).AnyTimes()
+			mockMCPClient.EXPECT().GetPrompt(gomock.Any(), "http://github:8080/mcp", "code_review", map[string]string{"language": "go"}).
+				Return(&mcp.GetPromptResult{Messages: []mcp.PromptMessage{
+					{Role: "user", Content: map[string]any{"type": "text", "text": "Review this go code."}},
+				}}, nil).AnyTimes()
+
+			mcpAgent := mcp.NewAgent(mockLogger, mockMCPClient, config.Config{})
+			middleware, err := middlewares.NewMCPMiddleware(mockRegistry, mockClient, mockMCPClient, mcpAgent, mockLogger, createTestConfig())
+			assert.NoError(t, err)
+
+			var received *types.CreateChatCompletionRequest
+			router := gin.New()
+			router.Use(middleware.Middleware())
+			router.POST("/v1/chat/completions", func(c *gin.Context) {
+				if request, ok := c.Get(middlewares.MCPBypassHeader); ok {
+					received = request.(*types.CreateChatCompletionRequest)
+				}
+				c.JSON(http.StatusOK, gin.H{"status": "ok"})
+			})
+
+			body := fmt.Sprintf(`{"model":"openai/gpt-4o","mcp_prompt":{"name":%q,"arguments":{"language":"go"}},"messages":[{"role":"user","content":"func main() {}"}]}`, tt.prompt)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedMessages == nil {
+				assert.Nil(t, received)
+				return
+			}
+			if assert.NotNil(t, received) {
+				assert.Nil(t, received.MCPPrompt, "the reference is not forwarded to the provider")
+				var messages []string
+				for _, message := range received.Messages {
+					text, err := message.Content.AsMessageContent0()
+					assert.NoError(t, err)
+					messages = append(messages, text)
+				}
+				assert.Equal(t, tt.expectedMessages, messages)
+			}
+		})
+	}
+}
+
+func TestMCPMiddleware_AgentLoopsAtCapacity(t *testing.T) {
+	for _, stream := range []bool{false, true} {
+		t.Run(fmt.Sprintf("stream=%t", stream), func(t *testing.T) {
+			ctrl, mockRegistry, mockClient, mockMCPClient, _, mockProvider := createMockDependencies(t)
+			defer ctrl.Finish()
+
+			mockMCPClient.EXPECT().IsInitialized().Return(true).AnyTimes()
+			mockMCPClient.EXPECT().GetAllServerStatuses().Return(map[string]mcp.ServerStatus{"server1": mcp.ServerStatusAvailable}).AnyTimes()
+			mockMCPClient.EXPECT().GetAllChatCompletionTools().Return([]types.ChatCompletionTool{
+				{Type: types.Function, Function: types.FunctionObject{Name: "test_function"}},
+			}).AnyTimes()
+			mockRegistry.EXPECT().BuildProvider(constants.OpenaiID, mockClient).Return(mockProvider, nil).AnyTimes()
+			mockProvider.EXPECT().GetName().Return(constants.OpenaiDisplayName).AnyTimes()
+
+			log := logger.NewNoopLogger()
+			sup := supervisor.New(map[supervisor.Kind]int{supervisor.Agent: 1})
+			busy, err := supervisor.Track(sup.WithRequest(context.Background()), supervisor.Agent)
+			require.NoError(t, err)
+			defer busy()
+
+			supervision, err := middlewares.NewSupervisionMiddleware(log, sup)
+			require.NoError(t, err)
+			mcpAgent := mcp.NewAgent(log, mockMCPClient, config.Config{})
+			middleware, err := middlewares.NewMCPMiddleware(mockRegistry, mockClient, mockMCPClient, mcpAgent, log, createTestConfig())
+			require.NoError(t, err)
+
+			router := gin.New()
+			router.Use(supervision.Middleware(), middleware.Middleware())
+			router.POST("/v1/chat/completions", func(c *gin.Context) {
+				c.JSON(http.StatusOK, types.CreateChatCompletionResponse{
+					Choices: []types.ChatCompletionChoice{{
+						Message: types.Message{
+							Role: types.Assistant,
+							ToolCalls: &[]types.ChatCompletionMessageToolCall{{
+								ID:       "call_123",
+								Type:     types.Function,
+								Function: types.ChatCompletionMessageToolCallFunction{Name: "test_function", Arguments: "{}"},
+							}},
+						},
+					}},
+				})
+			})
+
+			requestBody, err := json.Marshal(types.CreateChatCompletionRequest{
+				Model:    "openai/gpt-4o",
+				Messages: []types.Message{types.NewTextMessage(t, types.User, "Hi")},
+				Stream:   &stream,
+			})
+			require.NoError(t, err)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", bytes.NewReader(requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+			assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+			var resp errcodes.Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, errcodes.GatewayAtCapacity.ID, resp.Code)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	admin "github.com/inference-gateway/inference-gateway/api/admin"
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+func TestAdminMonitorMiddleware(t *testing.T) {
+	monitor := admin.NewMonitor(10)
+	m, err := middlewares.NewAdminMonitorMiddleware(logger.NewNoopLogger(), monitor)
+	require.NoError(t, err)
+
+	var activeDuringStream int
+	r := gin.New()
+	r.Use(m.Middleware())
+	r.POST("/v1/chat/completions", func(c *gin.Context) {
+		middlewares.SetSSEHeaders(c)
+		_, _ = c.Writer.WriteString("data: {}\n\n")
+		c.Writer.Flush()
+		activeDuringStream = monitor.ActiveStreams()
+		_, _ = c.Writer.WriteString("data: [DONE]\n\n")
+	})
+	r.GET("/v1/models", func(c *gin.Context) {
+		errcodes.JSON(c, http.StatusBadGateway, errcodes.UpstreamError, "Provider unavailable")
+	})
+	r.GET("/v1/upstream", func(c *gin.Context) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": gin.H{"message": "Rate limit reached", "type": "requests"}})
+	})
+	r.GET("/health/ready", func(c *gin.Context) {
+		c.Status(http.StatusServiceUnavailable)
+	})
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil),
+		httptest.NewRequest(http.MethodGet, "/v1/models", nil),
+		httptest.NewRequest(http.MethodGet, "/v1/upstream", nil),
+		httptest.NewRequest(http.MethodGet, "/health/ready", nil),
+	} {
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Equal(t, 1, activeDuringStream, "the stream is counted while it is written")
+	assert.Equal(t, 0, monitor.ActiveStreams())
+
+	records := monitor.RecentErrors()
+	require.Len(t, records, 2, "successful requests and health probes are not recorded")
+	assert.Equal(t, "/v1/upstream", records[0].Path)
+	assert.Equal(t, http.StatusTooManyRequests, records[0].Status)
+	assert.Equal(t, "Rate limit reached", records[0].Message)
+	assert.Equal(t, "/v1/models", records[1].Path)
+	assert.Equal(t, errcodes.UpstreamError.ID, records[1].Code)
+	assert.Equal(t, "Provider unavailable", records[1].Message)
+}
@@ -0,0 +1,146 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	prompts "github.com/inference-gateway/inference-gateway/api/prompts"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+func newPromptsRouter(t *testing.T, received *string) (*gin.Engine, *prompts.Store) {
+	t.Helper()
+	store, err := prompts.NewStore(&prompts.Config{Templates: []prompts.Template{
+		{Name: "org", Content: `Team {{ .Header.Get "X-Team" }}`, Models: []string{"gpt-4o"}},
+	}})
+	require.NoError(t, err)
+
+	cfg := config.Config{
+		Server: &config.ServerConfig{MaxRequestBytes: 1024},
+		Auth:   &config.AuthConfig{AdminToken: "secret"},
+	}
+	injector, err := middlewares.NewPromptInjectorMiddleware(logger.NewNoopLogger(), cfg, store)
+	require.NoError(t, err)
+	adminAuth, err := middlewares.NewAdminAuthMiddleware(logger.NewNoopLogger(), cfg)
+	require.NoError(t, err)
+
+	handler := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		*received = string(body)
+		c.Status(http.StatusOK)
+	}
+
+	r := gin.New()
+	r.Use(injector.Middleware())
+	r.POST("/v1/chat/completions", handler)
+	r.POST("/v1/messages", handler)
+	r.POST("/v1/embeddings", handler)
+	admin := r.Group("/admin", adminAuth.Middleware())
+	admin.GET("/prompts", store.ListHandler)
+	admin.PUT("/prompts/:name", store.PutHandler)
+	admin.DELETE("/prompts/:name", store.DeleteHandler)
+	return r, store
+}
+
+func TestPromptInjectorMiddleware(t *testing.T) {
+	tests := []struct {
+		name         string
+		path         string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Chat completions",
+			path:         "/v1/chat/completions",
+			body:         `{"model":"openai/gpt-4o","messages":[{"role":"user","content":"hi"}]}`,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"model":"openai/gpt-4o","messages":[{"role":"system","content":"Team search"},{"role":"user","content":"hi"}]}`,
+		},
+		{
+			name:         "Messages API",
+			path:         "/v1/messages",
+			body:         `{"model":"gpt-4o","system":"client","messages":[]}`,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"model":"gpt-4o","system":[{"type":"text","text":"Team search"},{"type":"text","text":"client"}],"messages":[]}`,
+		},
+		{
+			name:         "Unmatched model",
+			path:         "/v1/chat/completions",
+			body:         `{"model":"openai/gpt-4o-mini","messages":[]}`,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"model":"openai/gpt-4o-mini","messages":[]}`,
+		},
+		{
+			name:         "Other route",
+			path:         "/v1/embeddings",
+			body:         `{"model":"gpt-4o","input":"hi"}`,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"model":"gpt-4o","input":"hi"}`,
+		},
+		{
+			name:         "Invalid body",
+			path:         "/v1/chat/completions",
+			body:         `{`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received string
+			r, _ := newPromptsRouter(t, &received)
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("X-Team", "search")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, received)
+			}
+		})
+	}
+}
+
+func TestPromptsAdminAPI(t *testing.T) {
+	var received string
+	r, store := newPromptsRouter(t, &received)
+
+	do := func(method, path, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set(middlewares.AdminTokenHeader, token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/admin/prompts", "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/admin/prompts", "", "wrong").Code)
+
+	w := do(http.MethodPut, "/admin/prompts/all", `{"content":"Be brief","mode":"replace"}`, "secret")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"name":"all","content":"Be brief","mode":"replace"}`, w.Body.String())
+	assert.Equal(t, 2, store.Len())
+
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPut, "/admin/prompts/bad", `{"content":"{{"}`, "secret").Code)
+
+	do(http.MethodPost, "/v1/chat/completions", `{"model":"m","messages":[{"role":"system","content":"client"}]}`, "")
+	assert.JSONEq(t, `{"model":"m","messages":[{"role":"system","content":"Be brief"}]}`, received)
+
+	assert.Equal(t, http.StatusNoContent, do(http.MethodDelete, "/admin/prompts/all", "", "secret").Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodDelete, "/admin/prompts/all", "", "secret").Code)
+	assert.Equal(t, 1, store.Len())
+}
@@ -0,0 +1,112 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	batch "github.com/inference-gateway/inference-gateway/api/batch"
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	queue "github.com/inference-gateway/inference-gateway/api/queue"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+)
+
+// newQueueEngine returns an engine whose chat handler is rate limited by the
+// provider for its first saturated calls
+func newQueueEngine(t *testing.T, saturated int32, waitTimeout time.Duration) (*gin.Engine, *atomic.Int32) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	runner, err := batch.NewRunner(r, logger.NewNoopLogger(), 10, 1)
+	require.NoError(t, err)
+	q, err := queue.New(runner, logger.NewNoopLogger(), queue.Options{
+		Dir:           t.TempDir(),
+		Workers:       1,
+		MaxSize:       10,
+		RetryInterval: 10 * time.Millisecond,
+		TTL:           time.Minute,
+	})
+	require.NoError(t, err)
+	mw, err := middlewares.NewRequestQueueMiddleware(logger.NewNoopLogger(), config.Config{QueueWaitTimeout: waitTimeout}, q)
+	require.NoError(t, err)
+	q.Start(t.Context())
+
+	var calls atomic.Int32
+	r.Use(mw.Middleware())
+	r.POST("/v1/chat/completions", func(c *gin.Context) {
+		if calls.Add(1) <= saturated {
+			errcodes.JSON(c, http.StatusTooManyRequests, errcodes.UpstreamRateLimited, "Rate limited")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"id": "chatcmpl-1"})
+	})
+	r.GET("/v1/queue/:id", q.GetHandler)
+	return r, &calls
+}
+
+func postChat(r *gin.Engine, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRequestQueue_PassesThroughUnsaturated(t *testing.T) {
+	r, calls := newQueueEngine(t, 0, time.Second)
+
+	w := postChat(r, `{"model":"openai/gpt-4o"}`, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":"chatcmpl-1"}`, w.Body.String())
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestRequestQueue_HoldsConnectionUntilDispatched(t *testing.T) {
+	r, calls := newQueueEngine(t, 2, 5*time.Second)
+
+	w := postChat(r, `{"model":"openai/gpt-4o"}`, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":"chatcmpl-1"}`, w.Body.String())
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestRequestQueue_RespondAsync(t *testing.T) {
+	r, _ := newQueueEngine(t, 1, 5*time.Second)
+
+	w := postChat(r, `{"model":"openai/gpt-4o"}`, http.Header{"Prefer": []string{"respond-async"}})
+	require.Equal(t, http.StatusAccepted, w.Code)
+	location := w.Header().Get("Location")
+	require.True(t, strings.HasPrefix(location, middlewares.QueuePath), location)
+
+	var item queue.Item
+	require.Eventually(t, func() bool {
+		req := httptest.NewRequest(http.MethodGet, location, nil)
+		poll := httptest.NewRecorder()
+		r.ServeHTTP(poll, req)
+		require.Equal(t, http.StatusOK, poll.Code)
+		require.NoError(t, json.Unmarshal(poll.Body.Bytes(), &item))
+		return item.Status == queue.StatusCompleted
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.JSONEq(t, `{"id":"chatcmpl-1"}`, string(item.Response.Body))
+}
+
+func TestRequestQueue_SkipsStreamingRequests(t *testing.T) {
+	r, calls := newQueueEngine(t, 1, time.Second)
+
+	w := postChat(r, `{"model":"openai/gpt-4o","stream":true}`, nil)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, int32(1), calls.Load())
+}
@@ -0,0 +1,93 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	modelpolicy "github.com/inference-gateway/inference-gateway/api/modelpolicy"
+	config "github.com/inference-gateway/inference-gateway/config"
+	mcp "github.com/inference-gateway/inference-gateway/internal/mcp"
+	constants "github.com/inference-gateway/inference-gateway/providers/constants"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func samplingParams(t *testing.T, hints ...string) mcp.CreateMessageRequestParams {
+	t.Helper()
+	modelHints := make([]mcp.ModelHint, 0, len(hints))
+	for _, hint := range hints {
+		modelHints = append(modelHints, mcp.ModelHint{Name: &hint})
+	}
+	var content mcp.SamplingMessage_Content
+	require.NoError(t, content.FromTextContent(mcp.TextContent{Type: mcp.Text, Text: "Summarize the diff."}))
+	return mcp.CreateMessageRequestParams{
+		MaxTokens:        256,
+		Messages:         []mcp.SamplingMessage{{Role: mcp.User, Content: content}},
+		ModelPreferences: &mcp.ModelPreferences{Hints: &modelHints},
+	}
+}
+
+func TestMCPSampler(t *testing.T) {
+	ctrl, mockRegistry, mockClient, _, mockLogger, mockProvider := createMockDependencies(t)
+	defer ctrl.Finish()
+	mockLogger.EXPECT().Debug(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Warn(gomock.Any(), gomock.Any()).AnyTimes()
+
+	cfg := createTestConfig()
+	cfg.AllowedModels = "anthropic/claude-sonnet-4-5,openai/gpt-4o-mini"
+	cfg.MCP = &config.MCPConfig{SamplingModel: "openai/gpt-4o-mini"}
+	sampler := middlewares.NewMCPSampler(mockRegistry, mockClient, modelpolicy.New(cfg, nil), mockLogger, cfg)
+
+	mockRegistry.EXPECT().BuildProvider(constants.AnthropicID, mockClient).Return(mockProvider, nil)
+	mockProvider.EXPECT().ChatCompletions(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req types.CreateChatCompletionRequest) (types.CreateChatCompletionResponse, error) {
+			assert.Equal(t, "claude-sonnet-4-5", req.Model, "the first allowed hint is used")
+			require.Len(t, req.Messages, 1)
+			assert.Equal(t, "Summarize the diff.", req.Messages[0].TextContent())
+			require.NotNil(t, req.MaxTokens)
+			assert.Equal(t, 256, *req.MaxTokens)
+			var msg types.Message
+			require.NoError(t, msg.Content.FromMessageContent0("Renames a function."))
+			return types.CreateChatCompletionResponse{Choices: []types.ChatCompletionChoice{{Message: msg, FinishReason: types.Stop}}}, nil
+		})
+
+	result, err := sampler(context.Background(), "http://git:8080/mcp", samplingParams(t, "openai/gpt-4o", "anthropic/claude-sonnet-4-5"))
+	require.NoError(t, err)
+	assert.Equal(t, "anthropic/claude-sonnet-4-5", result.Model)
+	assert.Equal(t, mcp.Assistant, result.Role)
+	require.NotNil(t, result.StopReason)
+	assert.Equal(t, "endTurn", *result.StopReason)
+	data, err := json.Marshal(result.Content)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"text","text":"Renames a function."}`, string(data))
+
+	mockRegistry.EXPECT().BuildProvider(constants.OpenaiID, mockClient).Return(mockProvider, nil)
+	mockProvider.EXPECT().ChatCompletions(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req types.CreateChatCompletionRequest) (types.CreateChatCompletionResponse, error) {
+			assert.Equal(t, "gpt-4o-mini", req.Model, "hints without a known provider fall back to MCP_SAMPLING_MODEL")
+			return types.CreateChatCompletionResponse{Choices: []types.ChatCompletionChoice{{FinishReason: types.Length}}}, nil
+		})
+	result, err = sampler(context.Background(), "http://git:8080/mcp", samplingParams(t, "claude-3-haiku"))
+	require.NoError(t, err)
+	assert.Equal(t, "openai/gpt-4o-mini", result.Model)
+	assert.Equal(t, "maxTokens", *result.StopReason)
+}
+
+func TestMCPSamplerRefusesDisallowedModels(t *testing.T) {
+	ctrl, mockRegistry, mockClient, _, mockLogger, _ := createMockDependencies(t)
+	defer ctrl.Finish()
+	mockLogger.EXPECT().Warn(gomock.Any(), gomock.Any()).AnyTimes()
+
+	cfg := createTestConfig()
+	cfg.DisallowedModels = "openai/gpt-4o"
+	cfg.MCP = &config.MCPConfig{}
+	sampler := middlewares.NewMCPSampler(mockRegistry, mockClient, modelpolicy.New(cfg, nil), mockLogger, cfg)
+
+	_, err := sampler(context.Background(), "http://git:8080/mcp", samplingParams(t, "openai/gpt-4o"))
+	assert.ErrorContains(t, err, "no allowed model")
+}
@@ -0,0 +1,128 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+
+	batch "github.com/inference-gateway/inference-gateway/api/batch"
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	shadow "github.com/inference-gateway/inference-gateway/api/shadow"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	mocks "github.com/inference-gateway/inference-gateway/tests/mocks"
+)
+
+// newShadowEngine returns an engine shadowing every request for
+// openai/gpt-4o to anthropic/claude-sonnet-4-5, and the models its chat
+// handler was called with
+func newShadowEngine(t *testing.T, log logger.Logger) (*gin.Engine, *shadow.Shadower, func() []string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	runner, err := batch.NewRunner(r, logger.NewNoopLogger(), 10, 1)
+	require.NoError(t, err)
+	shadower, err := shadow.New(runner, log, shadow.Options{
+		Models:      map[string]string{"openai/gpt-4o": "anthropic/claude-sonnet-4-5"},
+		Percent:     100,
+		Concurrency: 4,
+		Timeout:     5 * time.Second,
+	})
+	require.NoError(t, err)
+	mw, err := middlewares.NewShadowMiddleware(logger.NewNoopLogger(), config.Config{}, shadower)
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var models []string
+	r.Use(mw.Middleware())
+	r.POST("/v1/chat/completions", func(c *gin.Context) {
+		var req struct {
+			Model  string `json:"model"`
+			Stream bool   `json:"stream"`
+		}
+		_ = c.ShouldBindJSON(&req)
+		mu.Lock()
+		models = append(models, req.Model)
+		mu.Unlock()
+		if req.Stream {
+			c.Header("Content-Type", "text/event-stream")
+			c.String(http.StatusOK, "data: {}\n\ndata: [DONE]\n\n")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"id":      "chatcmpl-1",
+			"model":   req.Model,
+			"choices": []gin.H{{"index": 0, "finish_reason": "stop", "message": gin.H{"role": "assistant", "content": "Hello from " + req.Model}}},
+			"usage":   gin.H{"prompt_tokens": 5, "completion_tokens": 3, "total_tokens": 8},
+		})
+	})
+	return r, shadower, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), models...)
+	}
+}
+
+func TestShadow_DuplicatesToShadowModel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	log := mocks.NewMockLogger(ctrl)
+	var fields []any
+	log.EXPECT().Info("shadow request compared", gomock.Any()).Do(func(_ string, f ...any) { fields = f })
+
+	r, shadower, models := newShadowEngine(t, log)
+	w := postChat(r, `{"model":"openai/gpt-4o","messages":[{"role":"user","content":"hi"}]}`, nil)
+	shadower.Wait()
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Model string `json:"model"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "openai/gpt-4o", resp.Model, "the client gets the primary response")
+	assert.ElementsMatch(t, []string{"openai/gpt-4o", "anthropic/claude-sonnet-4-5"}, models())
+
+	logged := make(map[string]any)
+	for i := 0; i+1 < len(fields); i += 2 {
+		logged[fields[i].(string)] = fields[i+1]
+	}
+	assert.Equal(t, "anthropic/claude-sonnet-4-5", logged["shadow_model"])
+	assert.Equal(t, http.StatusOK, logged["status"])
+	assert.Equal(t, http.StatusOK, logged["shadow_status"])
+	assert.Equal(t, "stop", logged["finish_reason"])
+	assert.Equal(t, 3, logged["shadow_completion_tokens"])
+	assert.NotContains(t, logged, "content", "content is only logged with SHADOW_LOG_CONTENT")
+}
+
+func TestShadow_StreamingRequestShadowedWithoutStreaming(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	log := mocks.NewMockLogger(ctrl)
+	var fields []any
+	log.EXPECT().Info("shadow request compared", gomock.Any()).Do(func(_ string, f ...any) { fields = f })
+
+	r, shadower, models := newShadowEngine(t, log)
+	w := postChat(r, `{"model":"openai/gpt-4o","stream":true}`, nil)
+	shadower.Wait()
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "data: [DONE]")
+	assert.ElementsMatch(t, []string{"openai/gpt-4o", "anthropic/claude-sonnet-4-5"}, models())
+	assert.Contains(t, fields, "shadow_finish_reason", "the shadow response is complete JSON")
+	assert.NotContains(t, fields, "finish_reason", "streamed primary responses are not kept")
+}
+
+func TestShadow_OtherModelsNotShadowed(t *testing.T) {
+	r, shadower, models := newShadowEngine(t, logger.NewNoopLogger())
+	w := postChat(r, `{"model":"groq/llama-3.3-70b-versatile"}`, nil)
+	shadower.Wait()
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"groq/llama-3.3-70b-versatile"}, models())
+}
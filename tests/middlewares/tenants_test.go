@@ -0,0 +1,174 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	core "github.com/inference-gateway/inference-gateway/providers/core"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func newTenantStore(t *testing.T) *tenants.Store {
+	t.Helper()
+	base := registry.NewReloadableRegistry(map[types.Provider]*registry.ProviderConfig{}, logger.NewNoopLogger())
+	store, err := tenants.NewStore(&tenants.Config{Tenants: map[string]tenants.Tenant{
+		"acme":   {RequestsPerMinute: 1},
+		"globex": {},
+	}}, base, logger.NewNoopLogger())
+	require.NoError(t, err)
+	return store
+}
+
+func TestTenantResolverMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.Config{TenantHeader: "X-Tenant-ID", Auth: &config.AuthConfig{}}
+	resolver, err := middlewares.NewTenantResolverMiddleware(logger.NewNoopLogger(), cfg, newTenantStore(t))
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(resolver.Middleware())
+	r.GET("/v1/models", func(c *gin.Context) {
+		c.String(http.StatusOK, tenants.FromContext(c.Request.Context()))
+	})
+
+	serve := func(tenant string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+		if tenant != "" {
+			req.Header.Set("X-Tenant-ID", tenant)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("requests without tenant are rejected", func(t *testing.T) {
+		w := serve("")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		var resp errcodes.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, errcodes.TenantUnknown.ID, resp.Code)
+	})
+
+	t.Run("tenant is attached to the request context", func(t *testing.T) {
+		w := serve("globex")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "globex", w.Body.String())
+	})
+
+	t.Run("unknown tenant is rejected", func(t *testing.T) {
+		w := serve("initech")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		var resp errcodes.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, errcodes.TenantUnknown.ID, resp.Code)
+	})
+
+	t.Run("tenant rate limit", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("acme").Code)
+
+		w := serve("acme")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.NotEmpty(t, w.Header().Get("Retry-After"))
+		var resp errcodes.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, errcodes.TenantRateLimited.ID, resp.Code)
+
+		assert.Equal(t, http.StatusOK, serve("globex").Code, "limits are per tenant")
+	})
+
+	t.Run("requests the gateway sends to itself keep their tenant", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), types.TenantContextKey, "acme")
+		req := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+		core.SetInternalHeaders(ctx, req)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, "the rate limit was applied to the original request")
+		assert.Equal(t, "acme", w.Body.String())
+
+		req = httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+		req.Header.Set(core.InternalHeader, "guessed")
+		req.Header.Set(core.InternalTenantHeader, "globex")
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code, "clients cannot pass for the gateway")
+	})
+}
+
+func TestTenantResolverMiddleware_Default(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	_, err := middlewares.NewTenantResolverMiddleware(logger.NewNoopLogger(), config.Config{TenantHeader: "X-Tenant-ID", TenantDefault: "initech", Auth: &config.AuthConfig{}}, newTenantStore(t))
+	require.Error(t, err, "the default tenant must be configured")
+
+	cfg := config.Config{TenantHeader: "X-Tenant-ID", TenantDefault: "globex", Auth: &config.AuthConfig{}}
+	resolver, err := middlewares.NewTenantResolverMiddleware(logger.NewNoopLogger(), cfg, newTenantStore(t))
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(resolver.Middleware())
+	handler := func(c *gin.Context) {
+		c.String(http.StatusOK, tenants.FromContext(c.Request.Context()))
+	}
+	r.GET("/v1/models", handler)
+	r.GET("/admin/status", handler)
+
+	for path, tenant := range map[string]string{"/v1/models": "globex", "/admin/status": ""} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.Equal(t, tenant, w.Body.String(), path)
+	}
+}
+
+func TestTenantResolverMiddleware_Claim(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	_, err := middlewares.NewTenantResolverMiddleware(logger.NewNoopLogger(), config.Config{TenantClaim: "org", Auth: &config.AuthConfig{}}, newTenantStore(t))
+	require.Error(t, err, "claims require OIDC authentication")
+	_, err = middlewares.NewTenantResolverMiddleware(logger.NewNoopLogger(), config.Config{TenantHeader: "X-Tenant-ID", Auth: &config.AuthConfig{Enable: true}}, newTenantStore(t))
+	require.Error(t, err, "authenticated callers may not pick their tenant with the header")
+
+	cfg := config.Config{TenantHeader: "X-Tenant-ID", TenantClaim: "org", Auth: &config.AuthConfig{Enable: true}}
+	resolver, err := middlewares.NewTenantResolverMiddleware(logger.NewNoopLogger(), cfg, newTenantStore(t))
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if org := c.GetHeader("X-Test-Org"); org != "" {
+			ctx := context.WithValue(c.Request.Context(), types.AuthClaimsContextKey, map[string]any{"org": org})
+			c.Request = c.Request.WithContext(ctx)
+		}
+	})
+	r.Use(resolver.Middleware())
+	r.GET("/v1/models", func(c *gin.Context) {
+		c.String(http.StatusOK, tenants.FromContext(c.Request.Context()))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+	req.Header.Set("X-Test-Org", "globex")
+	req.Header.Set("X-Tenant-ID", "acme")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "globex", w.Body.String(), "a spoofed header is ignored when the token carries the claim")
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+	req.Header.Set("X-Tenant-ID", "acme")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code, "tokens without the claim are rejected")
+}
@@ -0,0 +1,326 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	budgets "github.com/inference-gateway/inference-gateway/api/budgets"
+	errcodes "github.com/inference-gateway/inference-gateway/api/errcodes"
+	middlewares "github.com/inference-gateway/inference-gateway/api/middlewares"
+	tenants "github.com/inference-gateway/inference-gateway/api/tenants"
+	config "github.com/inference-gateway/inference-gateway/config"
+	logger "github.com/inference-gateway/inference-gateway/logger"
+	core "github.com/inference-gateway/inference-gateway/providers/core"
+	registry "github.com/inference-gateway/inference-gateway/providers/registry"
+	routing "github.com/inference-gateway/inference-gateway/providers/routing"
+	types "github.com/inference-gateway/inference-gateway/providers/types"
+)
+
+func TestUsageMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	base := registry.NewReloadableRegistry(map[types.Provider]*registry.ProviderConfig{}, logger.NewNoopLogger())
+	store, err := tenants.NewStore(&tenants.Config{Tenants: map[string]tenants.Tenant{
+		// gpt-4o costs 0.0035 USD for 1000 prompt and 100 completion tokens
+		"acme":   {Budget: &tenants.Budget{MonthlyUSD: 0.01, WarnPercent: 30}},
+		"globex": {},
+	}}, base, logger.NewNoopLogger())
+	require.NoError(t, err)
+	ledger, err := budgets.NewLedger("", store, logger.NewNoopLogger())
+	require.NoError(t, err)
+
+	cfg := config.Config{TenantHeader: "X-Tenant-ID", Auth: &config.AuthConfig{}, Server: &config.ServerConfig{}}
+	resolver, err := middlewares.NewTenantResolverMiddleware(logger.NewNoopLogger(), cfg, store)
+	require.NoError(t, err)
+	usage, err := middlewares.NewUsageMiddleware(logger.NewNoopLogger(), cfg, ledger, store)
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(resolver.Middleware(), usage.Middleware())
+	r.POST("/v1/chat/completions", func(c *gin.Context) {
+		var req struct {
+			Model  string `json:"model"`
+			Stream bool   `json:"stream"`
+		}
+		require.NoError(t, c.ShouldBindJSON(&req))
+		if !req.Stream {
+			c.JSON(http.StatusOK, gin.H{"model": req.Model, "usage": gin.H{"prompt_tokens": 1000, "completion_tokens": 100, "total_tokens": 1100}})
+			return
+		}
+		c.Header("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(c.Writer, "data: {\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\n")
+		_, _ = io.WriteString(c.Writer, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":1000,\"completion_tokens\":100,\"total_tokens\":1100}}\n\n")
+		_, _ = io.WriteString(c.Writer, "data: [DONE]\n\n")
+	})
+	r.GET("/v1/usage", ledger.UsageHandler)
+
+	serve := func(tenant, body string) *http.Response {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+		req.Header.Set("X-Tenant-ID", tenant)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Result()
+	}
+	report := func(tenant string) budgets.Report {
+		req := httptest.NewRequest(http.MethodGet, "/v1/usage", nil)
+		req.Header.Set("X-Tenant-ID", tenant)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		var report budgets.Report
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		return report
+	}
+
+	t.Run("responses carry their cost", func(t *testing.T) {
+		resp := serve("globex", `{"model":"openai/gpt-4o"}`)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "0.003500", resp.Header.Get(middlewares.CostHeader))
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), `"prompt_tokens":1000`)
+	})
+
+	t.Run("streams carry their cost as a trailer", func(t *testing.T) {
+		resp := serve("globex", `{"model":"openai/gpt-4o","stream":true}`)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "data: [DONE]")
+		assert.Equal(t, "0.003500", resp.Trailer.Get(middlewares.CostHeader))
+	})
+
+	t.Run("models without pricing are counted without cost", func(t *testing.T) {
+		resp := serve("globex", `{"model":"ollama/llama3"}`)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get(middlewares.CostHeader))
+
+		report := report("globex")
+		assert.Equal(t, int64(3), report.Requests)
+		assert.Equal(t, int64(1), report.UnpricedRequests)
+		assert.InDelta(t, 0.007, report.CostUSD, 1e-9)
+		assert.Equal(t, int64(2), report.Models["openai/gpt-4o"].Requests)
+		assert.Nil(t, report.Budget)
+	})
+
+	t.Run("budgets warn then block", func(t *testing.T) {
+		resp := serve("acme", `{"model":"openai/gpt-4o"}`)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get(middlewares.BudgetWarningHeader))
+
+		resp = serve("acme", `{"model":"openai/gpt-4o"}`)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.NotEmpty(t, resp.Header.Get(middlewares.BudgetWarningHeader), "0.0035 USD is past 30% of the budget")
+		assert.Equal(t, budgets.StateWarning, report("acme").Budget.State)
+
+		serve("acme", `{"model":"openai/gpt-4o"}`)
+		resp = serve("acme", `{"model":"openai/gpt-4o"}`)
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		var body errcodes.Response
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, errcodes.TenantBudgetExceeded.ID, body.Code)
+
+		report := report("acme")
+		assert.Equal(t, int64(3), report.Requests, "blocked requests are not counted")
+		require.NotNil(t, report.Budget)
+		assert.Equal(t, budgets.StateBlocked, report.Budget.State)
+		assert.Zero(t, report.Budget.RemainingUSD)
+	})
+}
+
+func TestUsageMiddleware_Downgrades(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	downgrades, err := routing.ParseDowngrades("openai/gpt-4o=openai/gpt-4o-mini,anthropic/claude-sonnet-4-5=groq/llama-3.3-70b-versatile", "openai=0.005")
+	require.NoError(t, err)
+	routing.SetDowngrades(downgrades)
+	t.Cleanup(func() { routing.SetDowngrades(nil) })
+
+	base := registry.NewReloadableRegistry(map[types.Provider]*registry.ProviderConfig{}, logger.NewNoopLogger())
+	store, err := tenants.NewStore(&tenants.Config{Tenants: map[string]tenants.Tenant{
+		"acme":    {Budget: &tenants.Budget{MonthlyUSD: 0.01, WarnPercent: 30}},
+		"initech": {Budget: &tenants.Budget{MonthlyUSD: 0.01, WarnPercent: 30}, AllowedModels: []string{"anthropic/claude-sonnet-4-5"}},
+		"globex":  {},
+	}}, base, logger.NewNoopLogger())
+	require.NoError(t, err)
+	ledger, err := budgets.NewLedger("", store, logger.NewNoopLogger())
+	require.NoError(t, err)
+
+	cfg := config.Config{TenantHeader: "X-Tenant-ID", Auth: &config.AuthConfig{}, Server: &config.ServerConfig{}}
+	resolver, err := middlewares.NewTenantResolverMiddleware(logger.NewNoopLogger(), cfg, store)
+	require.NoError(t, err)
+	usage, err := middlewares.NewUsageMiddleware(logger.NewNoopLogger(), cfg, ledger, store)
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(resolver.Middleware(), usage.Middleware())
+	r.POST("/v1/chat/completions", func(c *gin.Context) {
+		var req struct {
+			Model    string `json:"model"`
+			Messages []any  `json:"messages"`
+		}
+		require.NoError(t, c.ShouldBindJSON(&req))
+		require.Len(t, req.Messages, 1, "the rest of the body is kept")
+		c.JSON(http.StatusOK, gin.H{"model": req.Model, "usage": gin.H{"prompt_tokens": 1000, "completion_tokens": 100, "total_tokens": 1100}})
+	})
+
+	serve := func(tenant, model string, header ...string) (*http.Response, string) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"`+model+`","messages":[{"role":"user","content":"hi"}]}`))
+		req.Header.Set("X-Tenant-ID", tenant)
+		for i := 0; i < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var body struct {
+			Model string `json:"model"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return w.Result(), body.Model
+	}
+
+	t.Run("tenants approaching their budget get the cheaper equivalent", func(t *testing.T) {
+		resp, model := serve("acme", "openai/gpt-4o")
+		assert.Equal(t, "openai/gpt-4o", model)
+		assert.Empty(t, resp.Header.Get(middlewares.DowngradedFromHeader))
+
+		resp, model = serve("acme", "openai/gpt-4o")
+		assert.Equal(t, "openai/gpt-4o-mini", model)
+		assert.Equal(t, "openai/gpt-4o", resp.Header.Get(middlewares.DowngradedFromHeader))
+		assert.Equal(t, middlewares.DowngradeReasonBudget, resp.Header.Get(middlewares.DowngradeReasonHeader))
+		assert.Equal(t, "0.000210", resp.Header.Get(middlewares.CostHeader), "the equivalent is priced")
+
+		_, model = serve("acme", "openai/gpt-4o", middlewares.AllowDowngradeHeader, "false")
+		assert.Equal(t, "openai/gpt-4o", model, "requests may opt out")
+		_, model = serve("acme", "openai/gpt-4o-mini")
+		assert.Equal(t, "openai/gpt-4o-mini", model)
+	})
+
+	t.Run("equivalents the tenant may not use are skipped", func(t *testing.T) {
+		serve("initech", "anthropic/claude-sonnet-4-5")
+		resp, model := serve("initech", "anthropic/claude-sonnet-4-5")
+		assert.Equal(t, "anthropic/claude-sonnet-4-5", model)
+		assert.NotEmpty(t, resp.Header.Get(middlewares.BudgetWarningHeader))
+		assert.Empty(t, resp.Header.Get(middlewares.DowngradedFromHeader))
+	})
+
+	t.Run("providers over their spend rate get the cheaper equivalent", func(t *testing.T) {
+		resp, model := serve("globex", "openai/gpt-4o")
+		assert.Equal(t, "openai/gpt-4o-mini", model, "acme spent over 0.005 USD on openai within the hour")
+		assert.Equal(t, middlewares.DowngradeReasonSpendRate, resp.Header.Get(middlewares.DowngradeReasonHeader))
+	})
+}
+
+func TestUsageMiddleware_EntryPoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Every request below costs 0.0035 USD, but for the cached one
+	key := tenants.APIKeyID(http.Header{"X-Api-Key": {"sk-acme"}})
+	base := registry.NewReloadableRegistry(map[types.Provider]*registry.ProviderConfig{}, logger.NewNoopLogger())
+	store, err := tenants.NewStore(&tenants.Config{
+		APIKeys:          map[string]tenants.APIKey{key: {Budget: &tenants.Budget{MonthlyUSD: 0.004}}},
+		UntenantedBudget: &tenants.Budget{MonthlyUSD: 0.0225},
+	}, base, logger.NewNoopLogger())
+	require.NoError(t, err)
+	ledger, err := budgets.NewLedger("", store, logger.NewNoopLogger())
+	require.NoError(t, err)
+	usage, err := middlewares.NewUsageMiddleware(logger.NewNoopLogger(), config.Config{Server: &config.ServerConfig{}}, ledger, store)
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(usage.Middleware())
+	r.POST("/api/chat", func(c *gin.Context) {
+		var req struct {
+			Stream *bool `json:"stream"`
+		}
+		require.NoError(t, c.ShouldBindJSON(&req))
+		if req.Stream != nil && !*req.Stream {
+			c.JSON(http.StatusOK, gin.H{"done": true, "prompt_eval_count": 1000, "eval_count": 100})
+			return
+		}
+		c.Header("Content-Type", "application/x-ndjson")
+		_, _ = io.WriteString(c.Writer, `{"message":{"role":"assistant","content":"hi"},"done":false}`+"\n")
+		_, _ = io.WriteString(c.Writer, `{"done":true,"prompt_eval_count":1000,"eval_count":100}`+"\n")
+	})
+	r.POST("/v1/messages", func(c *gin.Context) {
+		var req struct {
+			Stream bool `json:"stream"`
+		}
+		require.NoError(t, c.ShouldBindJSON(&req))
+		if !req.Stream {
+			c.JSON(http.StatusOK, gin.H{"type": "message", "usage": gin.H{"input_tokens": 400, "cache_read_input_tokens": 600, "output_tokens": 100}})
+			return
+		}
+		c.Header("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(c.Writer, "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":1000,\"output_tokens\":1}}}\n\n")
+		_, _ = io.WriteString(c.Writer, "event: message_delta\ndata: {\"type\":\"message_delta\",\"usage\":{\"output_tokens\":100}}\n\n")
+	})
+	r.POST("/proxy/:provider/*path", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"usage": gin.H{"prompt_tokens": 1000, "completion_tokens": 100, "total_tokens": 1100}})
+	})
+
+	serve := func(path, body string, header ...string) *http.Response {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		for i := 0; i < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Result()
+	}
+	cost := func(resp *http.Response) string {
+		_, _ = io.ReadAll(resp.Body)
+		return resp.Header.Get(middlewares.CostHeader) + resp.Trailer.Get(middlewares.CostHeader)
+	}
+	code := func(resp *http.Response) string {
+		var body errcodes.Response
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return body.Code
+	}
+
+	t.Run("api keys are held to their budget", func(t *testing.T) {
+		resp := serve("/proxy/openai/v1/chat/completions", `{"model":"gpt-4o"}`, "X-Api-Key", "sk-acme")
+		assert.Equal(t, "0.003500", cost(resp), "direct proxy calls are priced")
+		assert.Empty(t, resp.Header.Get(middlewares.BudgetWarningHeader))
+		resp = serve("/proxy/openai/v1/chat/completions", `{"model":"gpt-4o"}`, "Authorization", "Bearer sk-acme")
+		assert.NotEmpty(t, resp.Header.Get(middlewares.BudgetWarningHeader), "bearer tokens name the same key")
+		resp = serve("/proxy/openai/v1/chat/completions", `{"model":"gpt-4o"}`, "X-Api-Key", "sk-acme")
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.Equal(t, errcodes.APIKeyBudgetExceeded.ID, code(resp))
+
+		resp = serve("/proxy/openai/v1/chat/completions", `{"model":"gpt-4o"}`, "X-Api-Key", "sk-globex")
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "other keys are not held to it")
+	})
+
+	t.Run("requests the gateway sends to itself are not charged twice", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/chat", nil)
+		core.SetInternalHeaders(req.Context(), req)
+		resp := serve("/proxy/openai/v1/chat/completions", `{"model":"gpt-4o"}`, core.InternalHeader, req.Header.Get(core.InternalHeader))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, cost(resp))
+	})
+
+	t.Run("every chat entry point is priced", func(t *testing.T) {
+		assert.Equal(t, "0.003500", cost(serve("/api/chat", `{"model":"openai/gpt-4o","stream":false}`)))
+		assert.Equal(t, "0.003500", cost(serve("/api/chat", `{"model":"openai/gpt-4o"}`)), "Ollama streams by default")
+		assert.Equal(t, "0.003500", cost(serve("/v1/messages", `{"model":"openai/gpt-4o","stream":true}`)))
+		assert.Equal(t, "0.002750", cost(serve("/v1/messages", `{"model":"openai/gpt-4o"}`)), "cache reads are priced at their rate")
+	})
+
+	t.Run("requests of no tenant are held to the untenanted budget", func(t *testing.T) {
+		status, ok := ledger.Status("")
+		require.True(t, ok)
+		assert.InDelta(t, 0.02375, status.SpentUSD, 1e-9, "every request above but the internal one")
+		resp := serve("/api/chat", `{"model":"openai/gpt-4o","stream":false}`)
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.Equal(t, errcodes.TenantBudgetExceeded.ID, code(resp))
+	})
+}
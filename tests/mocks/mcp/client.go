@@ -233,6 +233,45 @@ func (c *MockMCPClientInterfaceGetAllServerStatusesCall) DoAndReturn(f func() ma
 	return c
 }
 
+// GetPrompt mocks base method.
+func (m *MockMCPClientInterface) GetPrompt(ctx context.Context, serverURL string, name string, arguments map[string]string) (*mcp.GetPromptResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrompt", ctx, serverURL, name, arguments)
+	ret0, _ := ret[0].(*mcp.GetPromptResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPrompt indicates an expected call of GetPrompt.
+func (mr *MockMCPClientInterfaceMockRecorder) GetPrompt(ctx, serverURL, name, arguments any) *MockMCPClientInterfaceGetPromptCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrompt", reflect.TypeOf((*MockMCPClientInterface)(nil).GetPrompt), ctx, serverURL, name, arguments)
+	return &MockMCPClientInterfaceGetPromptCall{Call: call}
+}
+
+// MockMCPClientInterfaceGetPromptCall wrap *gomock.Call
+type MockMCPClientInterfaceGetPromptCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockMCPClientInterfaceGetPromptCall) Return(arg0 *mcp.GetPromptResult, arg1 error) *MockMCPClientInterfaceGetPromptCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockMCPClientInterfaceGetPromptCall) Do(f func(context.Context, string, string, map[string]string) (*mcp.GetPromptResult, error)) *MockMCPClientInterfaceGetPromptCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockMCPClientInterfaceGetPromptCall) DoAndReturn(f func(context.Context, string, string, map[string]string) (*mcp.GetPromptResult, error)) *MockMCPClientInterfaceGetPromptCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // GetServerForTool mocks base method.
 func (m *MockMCPClientInterface) GetServerForTool(toolName string) (string, error) {
 	m.ctrl.T.Helper()
@@ -425,6 +464,123 @@ func (c *MockMCPClientInterfaceIsInitializedCall) DoAndReturn(f func() bool) *Mo
 	return c
 }
 
+// ListPrompts mocks base method.
+func (m *MockMCPClientInterface) ListPrompts(ctx context.Context, serverURL string) ([]mcp.Prompt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPrompts", ctx, serverURL)
+	ret0, _ := ret[0].([]mcp.Prompt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPrompts indicates an expected call of ListPrompts.
+func (mr *MockMCPClientInterfaceMockRecorder) ListPrompts(ctx, serverURL any) *MockMCPClientInterfaceListPromptsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPrompts", reflect.TypeOf((*MockMCPClientInterface)(nil).ListPrompts), ctx, serverURL)
+	return &MockMCPClientInterfaceListPromptsCall{Call: call}
+}
+
+// MockMCPClientInterfaceListPromptsCall wrap *gomock.Call
+type MockMCPClientInterfaceListPromptsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockMCPClientInterfaceListPromptsCall) Return(arg0 []mcp.Prompt, arg1 error) *MockMCPClientInterfaceListPromptsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockMCPClientInterfaceListPromptsCall) Do(f func(context.Context, string) ([]mcp.Prompt, error)) *MockMCPClientInterfaceListPromptsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockMCPClientInterfaceListPromptsCall) DoAndReturn(f func(context.Context, string) ([]mcp.Prompt, error)) *MockMCPClientInterfaceListPromptsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListResources mocks base method.
+func (m *MockMCPClientInterface) ListResources(ctx context.Context, serverURL string) ([]mcp.Resource, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListResources", ctx, serverURL)
+	ret0, _ := ret[0].([]mcp.Resource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListResources indicates an expected call of ListResources.
+func (mr *MockMCPClientInterfaceMockRecorder) ListResources(ctx, serverURL any) *MockMCPClientInterfaceListResourcesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListResources", reflect.TypeOf((*MockMCPClientInterface)(nil).ListResources), ctx, serverURL)
+	return &MockMCPClientInterfaceListResourcesCall{Call: call}
+}
+
+// MockMCPClientInterfaceListResourcesCall wrap *gomock.Call
+type MockMCPClientInterfaceListResourcesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockMCPClientInterfaceListResourcesCall) Return(arg0 []mcp.Resource, arg1 error) *MockMCPClientInterfaceListResourcesCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockMCPClientInterfaceListResourcesCall) Do(f func(context.Context, string) ([]mcp.Resource, error)) *MockMCPClientInterfaceListResourcesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockMCPClientInterfaceListResourcesCall) DoAndReturn(f func(context.Context, string) ([]mcp.Resource, error)) *MockMCPClientInterfaceListResourcesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ReadResource mocks base method.
+func (m *MockMCPClientInterface) ReadResource(ctx context.Context, serverURL string, uri string) (*mcp.ReadResourceResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadResource", ctx, serverURL, uri)
+	ret0, _ := ret[0].(*mcp.ReadResourceResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadResource indicates an expected call of ReadResource.
+func (mr *MockMCPClientInterfaceMockRecorder) ReadResource(ctx, serverURL, uri any) *MockMCPClientInterfaceReadResourceCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadResource", reflect.TypeOf((*MockMCPClientInterface)(nil).ReadResource), ctx, serverURL, uri)
+	return &MockMCPClientInterfaceReadResourceCall{Call: call}
+}
+
+// MockMCPClientInterfaceReadResourceCall wrap *gomock.Call
+type MockMCPClientInterfaceReadResourceCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockMCPClientInterfaceReadResourceCall) Return(arg0 *mcp.ReadResourceResult, arg1 error) *MockMCPClientInterfaceReadResourceCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockMCPClientInterfaceReadResourceCall) Do(f func(context.Context, string, string) (*mcp.ReadResourceResult, error)) *MockMCPClientInterfaceReadResourceCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockMCPClientInterfaceReadResourceCall) DoAndReturn(f func(context.Context, string, string) (*mcp.ReadResourceResult, error)) *MockMCPClientInterfaceReadResourceCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // StartStatusPolling mocks base method.
 func (m *MockMCPClientInterface) StartStatusPolling(ctx context.Context) {
 	m.ctrl.T.Helper()
@@ -532,3 +688,41 @@ func (c *MockMCPClientInterfaceStopStatusPollingCall) DoAndReturn(f func()) *Moc
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// UpdateServers mocks base method.
+func (m *MockMCPClientInterface) UpdateServers(ctx context.Context, serverURLs []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateServers", ctx, serverURLs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateServers indicates an expected call of UpdateServers.
+func (mr *MockMCPClientInterfaceMockRecorder) UpdateServers(ctx, serverURLs any) *MockMCPClientInterfaceUpdateServersCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateServers", reflect.TypeOf((*MockMCPClientInterface)(nil).UpdateServers), ctx, serverURLs)
+	return &MockMCPClientInterfaceUpdateServersCall{Call: call}
+}
+
+// MockMCPClientInterfaceUpdateServersCall wrap *gomock.Call
+type MockMCPClientInterfaceUpdateServersCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockMCPClientInterfaceUpdateServersCall) Return(arg0 error) *MockMCPClientInterfaceUpdateServersCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockMCPClientInterfaceUpdateServersCall) Do(f func(context.Context, []string) error) *MockMCPClientInterfaceUpdateServersCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockMCPClientInterfaceUpdateServersCall) DoAndReturn(f func(context.Context, []string) error) *MockMCPClientInterfaceUpdateServersCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
@@ -44,6 +44,20 @@ func (m *MockOpenTelemetry) EXPECT() *MockOpenTelemetryMockRecorder {
 	return m.recorder
 }
 
+// Flush mocks base method.
+func (m *MockOpenTelemetry) Flush(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Flush", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Flush indicates an expected call of Flush.
+func (mr *MockOpenTelemetryMockRecorder) Flush(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MockOpenTelemetry)(nil).Flush), ctx)
+}
+
 // IngestMetrics mocks base method.
 func (m *MockOpenTelemetry) IngestMetrics(ctx context.Context, req *v1.ExportMetricsServiceRequest) otel.IngestResult {
 	m.ctrl.T.Helper()
@@ -96,6 +110,32 @@ func (mr *MockOpenTelemetryMockRecorder) RecordTokenUsage(ctx, source, team, pro
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordTokenUsage", reflect.TypeOf((*MockOpenTelemetry)(nil).RecordTokenUsage), ctx, source, team, provider, model, inputTokens, outputTokens)
 }
 
+// ObserveInflight mocks base method.
+func (m *MockOpenTelemetry) ObserveInflight(observe func() map[string]int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ObserveInflight", observe)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ObserveInflight indicates an expected call of ObserveInflight.
+func (mr *MockOpenTelemetryMockRecorder) ObserveInflight(observe any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObserveInflight", reflect.TypeOf((*MockOpenTelemetry)(nil).ObserveInflight), observe)
+}
+
+// RecordEvaluation mocks base method.
+func (m *MockOpenTelemetry) RecordEvaluation(ctx context.Context, provider, model, evaluator string, score float64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RecordEvaluation", ctx, provider, model, evaluator, score)
+}
+
+// RecordEvaluation indicates an expected call of RecordEvaluation.
+func (mr *MockOpenTelemetryMockRecorder) RecordEvaluation(ctx, provider, model, evaluator, score any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordEvaluation", reflect.TypeOf((*MockOpenTelemetry)(nil).RecordEvaluation), ctx, provider, model, evaluator, score)
+}
+
 // RecordToolCall mocks base method.
 func (m *MockOpenTelemetry) RecordToolCall(ctx context.Context, source, team, provider, model, toolType, toolName string) {
 	m.ctrl.T.Helper()
@@ -13,6 +13,7 @@ import (
 	reflect "reflect"
 
 	gin "github.com/gin-gonic/gin"
+	config "github.com/inference-gateway/inference-gateway/config"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -52,6 +53,30 @@ func (mr *MockRouterMockRecorder) ChatCompletionsHandler(c any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChatCompletionsHandler", reflect.TypeOf((*MockRouter)(nil).ChatCompletionsHandler), c)
 }
 
+// ContextPackHandler mocks base method.
+func (m *MockRouter) ContextPackHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ContextPackHandler", c)
+}
+
+// ContextPackHandler indicates an expected call of ContextPackHandler.
+func (mr *MockRouterMockRecorder) ContextPackHandler(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContextPackHandler", reflect.TypeOf((*MockRouter)(nil).ContextPackHandler), c)
+}
+
+// GetMCPPromptHandler mocks base method.
+func (m *MockRouter) GetMCPPromptHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetMCPPromptHandler", c)
+}
+
+// GetMCPPromptHandler indicates an expected call of GetMCPPromptHandler.
+func (mr *MockRouterMockRecorder) GetMCPPromptHandler(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMCPPromptHandler", reflect.TypeOf((*MockRouter)(nil).GetMCPPromptHandler), c)
+}
+
 // HealthcheckHandler mocks base method.
 func (m *MockRouter) HealthcheckHandler(c *gin.Context) {
 	m.ctrl.T.Helper()
@@ -64,6 +89,30 @@ func (mr *MockRouterMockRecorder) HealthcheckHandler(c any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HealthcheckHandler", reflect.TypeOf((*MockRouter)(nil).HealthcheckHandler), c)
 }
 
+// ListMCPPromptsHandler mocks base method.
+func (m *MockRouter) ListMCPPromptsHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ListMCPPromptsHandler", c)
+}
+
+// ListMCPPromptsHandler indicates an expected call of ListMCPPromptsHandler.
+func (mr *MockRouterMockRecorder) ListMCPPromptsHandler(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMCPPromptsHandler", reflect.TypeOf((*MockRouter)(nil).ListMCPPromptsHandler), c)
+}
+
+// ListMCPResourcesHandler mocks base method.
+func (m *MockRouter) ListMCPResourcesHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ListMCPResourcesHandler", c)
+}
+
+// ListMCPResourcesHandler indicates an expected call of ListMCPResourcesHandler.
+func (mr *MockRouterMockRecorder) ListMCPResourcesHandler(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMCPResourcesHandler", reflect.TypeOf((*MockRouter)(nil).ListMCPResourcesHandler), c)
+}
+
 // ListModelsHandler mocks base method.
 func (m *MockRouter) ListModelsHandler(c *gin.Context) {
 	m.ctrl.T.Helper()
@@ -124,6 +173,66 @@ func (mr *MockRouterMockRecorder) NotFoundHandler(c any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotFoundHandler", reflect.TypeOf((*MockRouter)(nil).NotFoundHandler), c)
 }
 
+// OllamaChatHandler mocks base method.
+func (m *MockRouter) OllamaChatHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OllamaChatHandler", c)
+}
+
+// OllamaChatHandler indicates an expected call of OllamaChatHandler.
+func (mr *MockRouterMockRecorder) OllamaChatHandler(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OllamaChatHandler", reflect.TypeOf((*MockRouter)(nil).OllamaChatHandler), c)
+}
+
+// OllamaDeleteModelHandler mocks base method.
+func (m *MockRouter) OllamaDeleteModelHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OllamaDeleteModelHandler", c)
+}
+
+// OllamaDeleteModelHandler indicates an expected call of OllamaDeleteModelHandler.
+func (mr *MockRouterMockRecorder) OllamaDeleteModelHandler(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OllamaDeleteModelHandler", reflect.TypeOf((*MockRouter)(nil).OllamaDeleteModelHandler), c)
+}
+
+// OllamaListModelsHandler mocks base method.
+func (m *MockRouter) OllamaListModelsHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OllamaListModelsHandler", c)
+}
+
+// OllamaListModelsHandler indicates an expected call of OllamaListModelsHandler.
+func (mr *MockRouterMockRecorder) OllamaListModelsHandler(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OllamaListModelsHandler", reflect.TypeOf((*MockRouter)(nil).OllamaListModelsHandler), c)
+}
+
+// OllamaPullModelHandler mocks base method.
+func (m *MockRouter) OllamaPullModelHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OllamaPullModelHandler", c)
+}
+
+// OllamaPullModelHandler indicates an expected call of OllamaPullModelHandler.
+func (mr *MockRouterMockRecorder) OllamaPullModelHandler(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OllamaPullModelHandler", reflect.TypeOf((*MockRouter)(nil).OllamaPullModelHandler), c)
+}
+
+// OllamaTagsHandler mocks base method.
+func (m *MockRouter) OllamaTagsHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OllamaTagsHandler", c)
+}
+
+// OllamaTagsHandler indicates an expected call of OllamaTagsHandler.
+func (mr *MockRouterMockRecorder) OllamaTagsHandler(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OllamaTagsHandler", reflect.TypeOf((*MockRouter)(nil).OllamaTagsHandler), c)
+}
+
 // ProxyHandler mocks base method.
 func (m *MockRouter) ProxyHandler(c *gin.Context) {
 	m.ctrl.T.Helper()
@@ -135,3 +244,39 @@ func (mr *MockRouterMockRecorder) ProxyHandler(c any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProxyHandler", reflect.TypeOf((*MockRouter)(nil).ProxyHandler), c)
 }
+
+// ReadMCPResourceHandler mocks base method.
+func (m *MockRouter) ReadMCPResourceHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReadMCPResourceHandler", c)
+}
+
+// ReadMCPResourceHandler indicates an expected call of ReadMCPResourceHandler.
+func (mr *MockRouterMockRecorder) ReadMCPResourceHandler(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadMCPResourceHandler", reflect.TypeOf((*MockRouter)(nil).ReadMCPResourceHandler), c)
+}
+
+// Reload mocks base method.
+func (m *MockRouter) Reload(cfg config.Config) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Reload", cfg)
+}
+
+// Reload indicates an expected call of Reload.
+func (mr *MockRouterMockRecorder) Reload(cfg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reload", reflect.TypeOf((*MockRouter)(nil).Reload), cfg)
+}
+
+// TokenizeHandler mocks base method.
+func (m *MockRouter) TokenizeHandler(c *gin.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "TokenizeHandler", c)
+}
+
+// TokenizeHandler indicates an expected call of TokenizeHandler.
+func (mr *MockRouterMockRecorder) TokenizeHandler(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TokenizeHandler", reflect.TypeOf((*MockRouter)(nil).TokenizeHandler), c)
+}
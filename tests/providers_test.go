@@ -179,7 +179,7 @@ func TestProviderChatCompletions(t *testing.T) {
 // OIDC middleware also guards /proxy, so without this header the gateway rejects its
 // own self-proxy request with 401. ListModels already forwards the token; this guards
 // the chat path against regressing.
-func TestProviderChatCompletionsForwardsAuthToken(t *testing.T) {
+func TestProviderChatCompletionsForwardsAuthTokenAndRequestID(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
@@ -211,6 +211,7 @@ func TestProviderChatCompletionsForwardsAuthToken(t *testing.T) {
 		Do(gomock.Any()).
 		DoAndReturn(func(req *http.Request) (*http.Response, error) {
 			assert.Equal(t, "Bearer client-token", req.Header.Get("Authorization"))
+			assert.Equal(t, "req_1", req.Header.Get("X-Request-ID"))
 			return http.DefaultClient.Post(server.URL+"/proxy/openai/chat/completions", "application/json", nil)
 		})
 
@@ -243,6 +244,7 @@ func TestProviderChatCompletionsForwardsAuthToken(t *testing.T) {
 	}
 
 	ctx := context.WithValue(context.Background(), types.AuthTokenContextKey, "client-token")
+	ctx = context.WithValue(ctx, types.RequestIDContextKey, "req_1")
 
 	resp, err := provider.ChatCompletions(ctx, req)
 	require.NoError(t, err)
@@ -2,6 +2,7 @@ package tests
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -140,6 +141,130 @@ func TestTracingTelemetryMiddlewareEnrichment(t *testing.T) {
 	}
 }
 
+func TestTracingGenAIConventions(t *testing.T) {
+	tests := []struct {
+		name     string
+		stream   bool
+		response string
+		output   string
+	}{
+		{
+			name:     "non-streaming",
+			response: `{"id":"chatcmpl-1","model":"gpt-4o-2024-08-06","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"Hello!"}}],"usage":{"prompt_tokens":9,"completion_tokens":3,"total_tokens":12,"prompt_tokens_details":{"cached_tokens":4}}}`,
+			output:   `[{"role":"assistant","parts":[{"type":"text","content":"Hello!"}],"finish_reason":"stop"}]`,
+		},
+		{
+			name:   "streaming",
+			stream: true,
+			response: "data: {\"id\":\"chatcmpl-1\",\"model\":\"gpt-4o-2024-08-06\",\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\",\"content\":\"Hel\"}}]}\n\n" +
+				"data: {\"id\":\"chatcmpl-1\",\"model\":\"gpt-4o-2024-08-06\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"lo!\"}}]}\n\n" +
+				"data: {\"id\":\"chatcmpl-1\",\"model\":\"gpt-4o-2024-08-06\",\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"stop\"}]}\n\n" +
+				"data: {\"id\":\"chatcmpl-1\",\"model\":\"gpt-4o-2024-08-06\",\"choices\":[],\"usage\":{\"prompt_tokens\":9,\"completion_tokens\":3,\"total_tokens\":12,\"prompt_tokens_details\":{\"cached_tokens\":4}}}\n\n" +
+				"data: [DONE]\n\n",
+			output: `[{"role":"assistant","parts":[{"type":"text","content":"Hello!"}],"finish_reason":"stop"}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := setupTracing(t)
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+
+			mockOtel := mocks.NewMockOpenTelemetry(ctrl)
+			mockOtel.EXPECT().RecordRequestDuration(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			mockOtel.EXPECT().RecordTokenUsage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+			log, err := logger.NewLogger("test")
+			require.NoError(t, err)
+			cfg := config.Config{Telemetry: &config.TelemetryConfig{CaptureContent: true, CaptureContentPercent: 100, CaptureContentRedaction: "keys,pii"}}
+			telemetry, err := middlewares.NewTelemetryMiddleware(cfg, mockOtel, log)
+			require.NoError(t, err)
+
+			r := gin.New()
+			r.Use(otelgin.Middleware("inference-gateway"))
+			r.Use(telemetry.Middleware())
+			r.POST("/v1/chat/completions", func(c *gin.Context) {
+				c.Data(http.StatusOK, "application/json", []byte(tt.response))
+			})
+
+			body := fmt.Sprintf(`{"model":"openai/gpt-4o","stream":%t,"temperature":0.5,"max_tokens":64,"messages":[{"role":"user","content":"hi, I am jane@example.com"}]}`, tt.stream)
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", "/v1/chat/completions", strings.NewReader(body))
+			require.NoError(t, err)
+			r.ServeHTTP(w, req)
+
+			spans := sr.Ended()
+			require.Len(t, spans, 1)
+			attrs := make(map[attribute.Key]attribute.Value)
+			for _, a := range spans[0].Attributes() {
+				attrs[a.Key] = a.Value
+			}
+			assert.Equal(t, "chat", attrs[semconv.GenAIOperationNameKey].AsString())
+			assert.Equal(t, "openai", attrs["gen_ai.system"].AsString())
+			assert.Equal(t, tt.stream, attrs[semconv.GenAIRequestStreamKey].AsBool())
+			assert.InDelta(t, 0.5, attrs[semconv.GenAIRequestTemperatureKey].AsFloat64(), 0.001)
+			assert.Equal(t, int64(64), attrs[semconv.GenAIRequestMaxTokensKey].AsInt64())
+			assert.Equal(t, "chatcmpl-1", attrs[semconv.GenAIResponseIDKey].AsString())
+			assert.Equal(t, "gpt-4o-2024-08-06", attrs[semconv.GenAIResponseModelKey].AsString())
+			assert.Equal(t, []string{"stop"}, attrs[semconv.GenAIResponseFinishReasonsKey].AsStringSlice())
+			assert.Equal(t, int64(9), attrs[semconv.GenAIUsageInputTokensKey].AsInt64())
+			assert.Equal(t, int64(3), attrs[semconv.GenAIUsageOutputTokensKey].AsInt64())
+			assert.Equal(t, int64(4), attrs[semconv.GenAIUsageCacheReadInputTokensKey].AsInt64())
+			assert.Greater(t, attrs["gen_ai.usage.cost"].AsFloat64(), 0.0)
+			assert.JSONEq(t, `[{"role":"user","parts":[{"type":"text","content":"hi, I am [REDACTED]"}]}]`, attrs[semconv.GenAIInputMessagesKey].AsString())
+			assert.JSONEq(t, tt.output, attrs[semconv.GenAIOutputMessagesKey].AsString())
+		})
+	}
+}
+
+func TestTracingTelemetryStreamSplitAcrossWrites(t *testing.T) {
+	sr := setupTracing(t)
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockOtel := mocks.NewMockOpenTelemetry(ctrl)
+	mockOtel.EXPECT().RecordRequestDuration(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+	mockOtel.EXPECT().RecordTokenUsage(gomock.Any(), gomock.Any(), gomock.Any(), "openai", "openai/gpt-4o", int64(9), int64(3))
+	mockOtel.EXPECT().RecordToolCall(gomock.Any(), gomock.Any(), gomock.Any(), "openai", "openai/gpt-4o", "standard_tool_use", "get_weather")
+
+	log, err := logger.NewLogger("test")
+	require.NoError(t, err)
+	telemetry, err := middlewares.NewTelemetryMiddleware(config.Config{}, mockOtel, log)
+	require.NoError(t, err)
+
+	stream := "data: {\"id\":\"chatcmpl-1\",\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":[{\"index\":0,\"id\":\"call_1\",\"type\":\"function\",\"function\":{\"name\":\"get_weather\",\"arguments\":\"\"}}]}}]}\r\n\r\n" +
+		"data: {\"id\":\"chatcmpl-1\",\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":[{\"index\":0,\"function\":{\"arguments\":\"{\\\"city\\\":\\\"Oslo\\\"}\"}}]},\"finish_reason\":\"tool_calls\"}]}\n\n" +
+		"data: {\"id\":\"chatcmpl-1\",\"choices\":[],\"usage\":{\"prompt_tokens\":9,\"completion_tokens\":3,\"total_tokens\":12}}\n\n" +
+		"data: [DONE]\n\n"
+
+	r := gin.New()
+	r.Use(otelgin.Middleware("inference-gateway"))
+	r.Use(telemetry.Middleware())
+	r.POST("/v1/chat/completions", func(c *gin.Context) {
+		middlewares.SetSSEHeaders(c)
+		// Events reach the middleware cut at arbitrary points
+		for rest := stream; rest != ""; {
+			n := min(7, len(rest))
+			_, _ = c.Writer.WriteString(rest[:n])
+			c.Writer.Flush()
+			rest = rest[n:]
+		}
+	})
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", "/v1/chat/completions", strings.NewReader(`{"model":"openai/gpt-4o","stream":true,"messages":[{"role":"user","content":"hi"}]}`))
+	require.NoError(t, err)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, stream, w.Body.String(), "the stream reaches the client unchanged")
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	finishReasons, _ := findAttr(spans[0].Attributes(), semconv.GenAIResponseFinishReasonsKey)
+	assert.Equal(t, `["tool_calls"]`, finishReasons)
+	responseID, _ := findAttr(spans[0].Attributes(), semconv.GenAIResponseIDKey)
+	assert.Equal(t, "chatcmpl-1", responseID)
+}
+
 func TestTracingExecuteToolsSpans(t *testing.T) {
 	sr := setupTracing(t)
 	ctrl := gomock.NewController(t)
@@ -153,7 +278,7 @@ func TestTracingExecuteToolsSpans(t *testing.T) {
 	mockMCP.EXPECT().ExecuteTool(gomock.Any(), gomock.Any(), "http://mcp.local").Return(&mcp.CallToolResult{}, nil)
 	mockMCP.EXPECT().GetServerForTool("missing").Return("", assert.AnError)
 
-	agent := mcp.NewAgent(log, mockMCP)
+	agent := mcp.NewAgent(log, mockMCP, config.Config{})
 	results, err := agent.ExecuteTools(context.Background(), []types.ChatCompletionMessageToolCall{
 		{ID: "1", Function: types.ChatCompletionMessageToolCallFunction{Name: "mcp_search", Arguments: "{}"}},
 		{ID: "2", Function: types.ChatCompletionMessageToolCallFunction{Name: "mcp_missing", Arguments: "{}"}},
@@ -254,7 +379,7 @@ func TestTracingProxyPropagation(t *testing.T) {
 		},
 		Providers: providerCfg,
 	}
-	router := api.NewRouter(cfg, log, registry.NewProviderRegistry(providerCfg, log), providersmocks.NewMockClient(ctrl), nil, nil, nil)
+	router := api.NewRouter(cfg, log, registry.NewProviderRegistry(providerCfg, log), providersmocks.NewMockClient(ctrl), nil, nil, nil, nil)
 
 	r := gin.New()
 	r.Use(otelgin.Middleware("inference-gateway"))
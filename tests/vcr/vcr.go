@@ -0,0 +1,281 @@
+// Package vcr records the provider interactions of a client.Client into
+// cassette files and replays them in tests, so provider quirks can be tested
+// without live API keys.
+//
+// Tests replay their cassette by default. To record it again, run the gateway
+// with the provider keys and the tests with VCR_MODE=record: the requests go
+// to the gateway's /proxy route and the answers are saved with the
+// credentials left out.
+package vcr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	yaml "gopkg.in/yaml.v3"
+
+	client "github.com/inference-gateway/inference-gateway/providers/client"
+)
+
+// Mode is whether a Recorder records or replays
+type Mode string
+
+const (
+	// ModeReplay answers the requests from the cassette
+	ModeReplay Mode = "replay"
+	// ModeRecord sends the requests and saves the answers in the cassette
+	ModeRecord Mode = "record"
+)
+
+// ModeEnv is the environment variable selecting the mode of Start
+const ModeEnv = "VCR_MODE"
+
+// ErrNoInteraction is returned in replay mode for a request the cassette has
+// no unused interaction for
+var ErrNoInteraction = errors.New("no recorded interaction")
+
+// Cassette is the file of the interactions recorded by a test
+type Cassette struct {
+	Interactions []Interaction `yaml:"interactions"`
+}
+
+// Interaction is a recorded request and its answer
+type Interaction struct {
+	Request  Request  `yaml:"request"`
+	Response Response `yaml:"response"`
+}
+
+// Request is a recorded request, without its headers
+type Request struct {
+	Method string `yaml:"method"`
+	URL    string `yaml:"url"`
+	Body   string `yaml:"body,omitempty"`
+}
+
+// Response is a recorded answer, with the headers of keptHeaders only
+type Response struct {
+	Status int               `yaml:"status"`
+	Header map[string]string `yaml:"header,omitempty"`
+	Body   string            `yaml:"body"`
+}
+
+// keptHeaders are the response headers recorded, those telling the format of
+// the body and the rate limits. Others, such as cookies and organization
+// IDs, are left out.
+var keptHeaders = []string{"Content-Type", "Retry-After"}
+
+// keptHeaderPrefixes are the prefixes of the rate limit headers recorded
+var keptHeaderPrefixes = []string{"X-Ratelimit-", "Anthropic-Ratelimit-"}
+
+// secretParams are the query parameters redacted from recorded URLs
+var secretParams = []string{"key", "api_key", "token"}
+
+// Recorder is a client.Client recording or replaying the interactions of a
+// cassette
+type Recorder struct {
+	path string
+	mode Mode
+	next client.Client
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+var _ client.Client = (*Recorder)(nil)
+
+// New creates a recorder of the cassette at path. In replay mode the cassette
+// must exist and next may be nil; in record mode the requests are sent with
+// next and Save writes the cassette.
+func New(path string, mode Mode, next client.Client) (*Recorder, error) {
+	r := &Recorder{path: path, mode: mode, next: next}
+	switch mode {
+	case ModeRecord:
+		if next == nil {
+			return nil, errors.New("recording needs a client to send the requests")
+		}
+	case ModeReplay:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read cassette: %w", err)
+		}
+		if err := yaml.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("parse cassette %s: %w", path, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	default:
+		return nil, fmt.Errorf("unknown mode %q, expected replay or record", mode)
+	}
+	return r, nil
+}
+
+// Start creates a recorder of the cassette at path in the mode of ModeEnv,
+// replay by default, saving it when the test ends. next sends the requests
+// when recording; it may be nil when replaying.
+func Start(t testing.TB, path string, next client.Client) *Recorder {
+	t.Helper()
+	mode := Mode(os.Getenv(ModeEnv))
+	if mode == "" {
+		mode = ModeReplay
+	}
+	r, err := New(path, mode, next)
+	if err != nil {
+		t.Fatalf("vcr: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := r.Save(); err != nil {
+			t.Errorf("vcr: %v", err)
+		}
+	})
+	return r
+}
+
+// Mode returns whether the recorder records or replays
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Do answers req from the cassette in replay mode, with the first unused
+// interaction of the same method and URL. In record mode it sends req and
+// records the answer, whose body is read in full.
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	recorded := Request{Method: req.Method, URL: sanitizeURL(req.URL), Body: string(body)}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+
+	resp, err := r.next.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request:  recorded,
+		Response: Response{Status: resp.StatusCode, Header: keptHeader(resp.Header), Body: string(respBody)},
+	})
+	r.mu.Unlock()
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	return resp, nil
+}
+
+// Get implements client.Client
+func (r *Recorder) Get(url string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return r.Do(req)
+}
+
+// Post implements client.Client
+func (r *Recorder) Post(url string, bodyType string, body string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", bodyType)
+	return r.Do(req)
+}
+
+// Save writes the cassette when recording
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	data, err := yaml.Marshal(r.cassette)
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, data, 0o644)
+}
+
+// replay answers req with the first unused interaction recorded for it
+func (r *Recorder) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || interaction.Request.Method != recorded.Method || interaction.Request.URL != recorded.URL {
+			continue
+		}
+		r.used[i] = true
+		header := make(http.Header, len(interaction.Response.Header))
+		for name, value := range interaction.Response.Header {
+			header.Set(name, value)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.Status, http.StatusText(interaction.Response.Status)),
+			StatusCode:    interaction.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w for %s %s in %s", ErrNoInteraction, recorded.Method, recorded.URL, r.path)
+}
+
+// sanitizeURL returns u with the values of secretParams redacted
+func sanitizeURL(u *url.URL) string {
+	sanitized := *u
+	query := sanitized.Query()
+	for _, param := range secretParams {
+		if query.Has(param) {
+			query.Set(param, "REDACTED")
+		}
+	}
+	sanitized.RawQuery = query.Encode()
+	return sanitized.String()
+}
+
+// keptHeader returns the headers of h that are recorded
+func keptHeader(h http.Header) map[string]string {
+	kept := make(map[string]string)
+	for name := range h {
+		canonical := http.CanonicalHeaderKey(name)
+		keep := false
+		for _, header := range keptHeaders {
+			keep = keep || canonical == header
+		}
+		for _, prefix := range keptHeaderPrefixes {
+			keep = keep || strings.HasPrefix(canonical, prefix)
+		}
+		if keep {
+			kept[canonical] = h.Get(name)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,72 @@
+package vcr
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+
+	client "github.com/inference-gateway/inference-gateway/providers/client"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Header().Set("X-Ratelimit-Remaining-Requests", "99")
+		_, _ = io.WriteString(w, "data: "+string(body)+"\n\ndata: [DONE]\n\n")
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	live, err := client.NewHTTPClient(&client.ClientConfig{}, "http", serverURL.Hostname(), serverURL.Port())
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "cassettes", "stream.yaml")
+	recorder, err := New(path, ModeRecord, live)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, "/proxy/google/chat/completions?key=sk-secret", strings.NewReader(`{"model":"gemini"}`))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer sk-secret")
+	resp, err := recorder.Do(req)
+	require.NoError(t, err)
+	recordedBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "data: {\"model\":\"gemini\"}\n\ndata: [DONE]\n\n", string(recordedBody), "the body is still handed to the caller")
+	require.NoError(t, recorder.Save())
+
+	replayer, err := New(path, ModeReplay, nil)
+	require.NoError(t, err)
+	interaction := replayer.cassette.Interactions[0]
+	assert.Equal(t, Request{Method: http.MethodPost, URL: "/proxy/google/chat/completions?key=REDACTED", Body: `{"model":"gemini"}`}, interaction.Request)
+	assert.Equal(t, map[string]string{"Content-Type": "text/event-stream", "X-Ratelimit-Remaining-Requests": "99"}, interaction.Response.Header)
+
+	req, err = http.NewRequest(http.MethodPost, "/proxy/google/chat/completions?key=other-key", strings.NewReader(`{"model":"gemini"}`))
+	require.NoError(t, err)
+	resp, err = replayer.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	replayedBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, recordedBody, replayedBody)
+
+	_, err = replayer.Post("/proxy/google/chat/completions?key=other-key", "application/json", `{}`)
+	assert.ErrorIs(t, err, ErrNoInteraction, "each interaction is replayed once")
+}
+
+func TestNew_ReplayWithoutCassette(t *testing.T) {
+	_, err := New(filepath.Join(t.TempDir(), "missing.yaml"), ModeReplay, nil)
+	assert.ErrorContains(t, err, "read cassette")
+	_, err = New("cassette.yaml", ModeRecord, nil)
+	assert.Error(t, err)
+	_, err = New("cassette.yaml", "rewind", nil)
+	assert.ErrorContains(t, err, `unknown mode "rewind"`)
+}